@@ -0,0 +1,27 @@
+package influxdb
+
+import "fmt"
+
+// AlertingQuota limits the alerting resources of every organization, so that the organizations sharing
+// an instance cannot flood it with notifications.
+// A zero limit is not enforced.
+type AlertingQuota struct {
+	// MaxTaskWebhooks is the maximum number of task webhooks, the endpoints notified of task runs, in an organization.
+	MaxTaskWebhooks int
+
+	// MaxExpectedReporters is the maximum number of expected reporters, the sources alerted on when
+	// they stop writing, in an organization.
+	MaxExpectedReporters int
+}
+
+// CheckQuota returns an EForbidden error if an organization that has count resources of a kind
+// cannot create one more of them under a quota of max. A zero max is not enforced.
+func CheckQuota(orgID ID, kind string, count, max int) error {
+	if max > 0 && count >= max {
+		return &Error{
+			Code: EForbidden,
+			Msg:  fmt.Sprintf("organization %s has reached its quota of %d %s", orgID, max, kind),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrAnnouncementNotFound is the error msg for a missing announcement.
+const ErrAnnouncementNotFound = "announcement not found"
+
+// ops for announcement error
+const (
+	OpFindAnnouncements  = "FindAnnouncements"
+	OpCreateAnnouncement = "CreateAnnouncement"
+	OpUpdateAnnouncement = "UpdateAnnouncement"
+	OpDeleteAnnouncement = "DeleteAnnouncement"
+)
+
+// AnnouncementService represents a service for managing the announcements shown to every user,
+// e.g. to warn of upcoming maintenance.
+type AnnouncementService interface {
+	// FindAnnouncements returns the announcements that match a filter.
+	FindAnnouncements(ctx context.Context, filter AnnouncementFilter) ([]*Announcement, error)
+
+	// CreateAnnouncement creates a new announcement and sets a.ID with the new identifier.
+	CreateAnnouncement(ctx context.Context, a *Announcement) error
+
+	// UpdateAnnouncement updates a single announcement with changeset.
+	// Returns the new announcement state after update.
+	UpdateAnnouncement(ctx context.Context, id ID, upd AnnouncementUpdate) (*Announcement, error)
+
+	// DeleteAnnouncement removes an announcement by ID.
+	DeleteAnnouncement(ctx context.Context, id ID) error
+}
+
+// AnnouncementSeverity tells the UI how prominently an announcement is rendered.
+type AnnouncementSeverity string
+
+// Announcement severities.
+const (
+	AnnouncementInfo     AnnouncementSeverity = "info"
+	AnnouncementWarning  AnnouncementSeverity = "warning"
+	AnnouncementCritical AnnouncementSeverity = "critical"
+)
+
+// Valid returns an error if s is not a known severity.
+func (s AnnouncementSeverity) Valid() error {
+	switch s {
+	case AnnouncementInfo, AnnouncementWarning, AnnouncementCritical:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("unknown announcement severity %q", s),
+	}
+}
+
+// Announcement is a message shown to every user between StartsAt and EndsAt.
+type Announcement struct {
+	ID       ID                   `json:"id,omitempty"`
+	Message  string               `json:"message"`
+	Severity AnnouncementSeverity `json:"severity"`
+	StartsAt time.Time            `json:"startsAt"`
+	EndsAt   time.Time            `json:"endsAt"`
+}
+
+// Validate returns an error if the announcement is invalid.
+func (a *Announcement) Validate() error {
+	if a.Message == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "announcement message is required",
+		}
+	}
+	if err := a.Severity.Valid(); err != nil {
+		return err
+	}
+	if a.StartsAt.IsZero() || a.EndsAt.IsZero() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "announcement time window requires startsAt and endsAt",
+		}
+	}
+	if !a.EndsAt.After(a.StartsAt) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "announcement endsAt must be after startsAt",
+		}
+	}
+	return nil
+}
+
+// Active returns true if the announcement is shown at t.
+func (a *Announcement) Active(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
+
+// AnnouncementFilter represents a set of filters that restrict the returned announcements.
+type AnnouncementFilter struct {
+	ID *ID
+
+	// ActiveAt, if set, only matches the announcements shown at that time.
+	ActiveAt *time.Time
+}
+
+// Matches returns true if a passes the filter.
+func (f AnnouncementFilter) Matches(a *Announcement) bool {
+	if f.ID != nil && *f.ID != a.ID {
+		return false
+	}
+	if f.ActiveAt != nil && !a.Active(*f.ActiveAt) {
+		return false
+	}
+	return true
+}
+
+// AnnouncementUpdate is the set of changes that can be applied to an announcement.
+type AnnouncementUpdate struct {
+	Message  *string               `json:"message,omitempty"`
+	Severity *AnnouncementSeverity `json:"severity,omitempty"`
+	StartsAt *time.Time            `json:"startsAt,omitempty"`
+	EndsAt   *time.Time            `json:"endsAt,omitempty"`
+}
+
+// Apply applies the update to a and validates the result.
+func (u AnnouncementUpdate) Apply(a *Announcement) error {
+	if u.Message != nil {
+		a.Message = *u.Message
+	}
+	if u.Severity != nil {
+		a.Severity = *u.Severity
+	}
+	if u.StartsAt != nil {
+		a.StartsAt = *u.StartsAt
+	}
+	if u.EndsAt != nil {
+		a.EndsAt = *u.EndsAt
+	}
+	return a.Validate()
+}
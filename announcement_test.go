@@ -0,0 +1,95 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestAnnouncementValidate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		announcement influxdb.Announcement
+		wantErr      bool
+	}{
+		{
+			name: "valid announcement",
+			announcement: influxdb.Announcement{
+				Message:  "maintenance tonight",
+				Severity: influxdb.AnnouncementWarning,
+				StartsAt: now,
+				EndsAt:   now.Add(time.Hour),
+			},
+		},
+		{
+			name: "missing message",
+			announcement: influxdb.Announcement{
+				Severity: influxdb.AnnouncementWarning,
+				StartsAt: now,
+				EndsAt:   now.Add(time.Hour),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown severity",
+			announcement: influxdb.Announcement{
+				Message:  "maintenance tonight",
+				Severity: influxdb.AnnouncementSeverity("urgent"),
+				StartsAt: now,
+				EndsAt:   now.Add(time.Hour),
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing window",
+			announcement: influxdb.Announcement{
+				Message:  "maintenance tonight",
+				Severity: influxdb.AnnouncementWarning,
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty window",
+			announcement: influxdb.Announcement{
+				Message:  "maintenance tonight",
+				Severity: influxdb.AnnouncementWarning,
+				StartsAt: now,
+				EndsAt:   now,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.announcement.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnnouncementActive(t *testing.T) {
+	now := time.Now()
+	a := &influxdb.Announcement{StartsAt: now, EndsAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at: now.Add(-time.Second), want: false},
+		{at: now, want: true},
+		{at: now.Add(30 * time.Minute), want: true},
+		{at: now.Add(time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := a.Active(tt.at); got != tt.want {
+			t.Errorf("Active(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
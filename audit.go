@@ -0,0 +1,87 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ops for audit log errors.
+const (
+	OpFindAuditEvents = "FindAuditEvents"
+)
+
+// The actions of audit events.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEvent records a call to the API that created, updated or deleted a resource:
+// who made it, what it changed, when and from where.
+type AuditEvent struct {
+	Time  time.Time `json:"time"`
+	OrgID ID        `json:"orgID,omitempty"`
+
+	// AuthorizerKind and AuthorizerID are the authorization or session the call was made with,
+	// and UserID its user. They are empty for the calls made without one, such as the setup.
+	AuthorizerKind string `json:"authorizerKind,omitempty"`
+	AuthorizerID   ID     `json:"authorizerID,omitempty"`
+	UserID         ID     `json:"userID,omitempty"`
+
+	// Action is one of AuditActionCreate, AuditActionUpdate or AuditActionDelete.
+	Action       string `json:"action"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   ID     `json:"resourceID,omitempty"`
+	// Changes are the names of the properties the call set. Their values are not recorded,
+	// as they may be secrets.
+	Changes []string `json:"changes,omitempty"`
+
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	RemoteAddr string `json:"remoteAddr"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// AuditRecorder records audit events.
+type AuditRecorder interface {
+	// RecordAuditEvent records e. It must not block the call e records.
+	RecordAuditEvent(e *AuditEvent)
+}
+
+// AuditEventFilter selects the audit events of an organization recorded between Start and Stop.
+type AuditEventFilter struct {
+	OrgID ID
+	Start time.Time
+	Stop  time.Time
+
+	ResourceType string
+	ResourceID   *ID
+	Action       string
+
+	// Limit is the number of events returned at most, or 0 for all of them.
+	Limit int
+}
+
+// Validate returns an error if the filter has no organization or an empty time range.
+func (f AuditEventFilter) Validate() error {
+	if !f.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if !f.Start.Before(f.Stop) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "start must be before stop",
+		}
+	}
+	return nil
+}
+
+// AuditLogService reads the audit events recorded for the organizations.
+type AuditLogService interface {
+	// FindAuditEvents returns the events matching filter, oldest first.
+	FindAuditEvents(ctx context.Context, filter AuditEventFilter) ([]*AuditEvent, error)
+}
@@ -0,0 +1,158 @@
+// Package audit records the calls to the API that create, update or delete resources
+// to the audit bucket of their organization, from which they are read back and exported.
+package audit
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// bucketID is the ID of the audit bucket of every organization.
+var bucketID = influxdb.ID(influxdb.BucketTypeAudit)
+
+// measurement is the measurement of the points of the audit events.
+const measurement = "audit"
+
+// The tags and fields of the points of the audit events.
+const (
+	actionTag           = "action"
+	resourceTypeTag     = "resourceType"
+	resourceIDField     = "resourceID"
+	authorizerKindField = "authorizerKind"
+	authorizerIDField   = "authorizerID"
+	userIDField         = "userID"
+	changesField        = "changes"
+	methodField         = "method"
+	pathField           = "path"
+	remoteAddrField     = "remoteAddr"
+	statusCodeField     = "statusCode"
+)
+
+// PointsWriter writes points to storage. It is a copy of storage.PointsWriter,
+// so that the audit log does not depend on storage.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// writerBuffer is the number of audit events waiting to be written at most.
+const writerBuffer = 1000
+
+// Writer records audit events as points of the audit bucket of their organization,
+// influxdb.BucketTypeAudit, where they can be queried with Flux.
+// The events of no organization, such as the setup, are not written.
+// The points are written in the background, and dropped if the writer falls behind.
+type Writer struct {
+	pointsWriter PointsWriter
+	logger       *zap.Logger
+
+	mu     sync.Mutex
+	closed bool
+	events chan *influxdb.AuditEvent
+	done   chan struct{}
+}
+
+// NewWriter returns a Writer writing the audit events with pw, which must be closed once done.
+func NewWriter(pw PointsWriter, logger *zap.Logger) *Writer {
+	w := &Writer{
+		pointsWriter: pw,
+		logger:       logger,
+		events:       make(chan *influxdb.AuditEvent, writerBuffer),
+		done:         make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// RecordAuditEvent queues e to be written, or drops it if too many events are waiting already.
+func (w *Writer) RecordAuditEvent(e *influxdb.AuditEvent) {
+	if !e.OrgID.Valid() {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.events <- e:
+	default:
+		w.logger.Info("Dropped audit event, too many waiting to be written", zap.Stringer("org_id", e.OrgID), zap.String("path", e.Path))
+	}
+}
+
+// Close writes the audit events waiting to be written, and stops the writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	for e := range w.events {
+		if err := w.write(e); err != nil {
+			w.logger.Info("Failed to write audit event", zap.Stringer("org_id", e.OrgID), zap.String("path", e.Path), zap.Error(err))
+		}
+	}
+}
+
+func (w *Writer) write(e *influxdb.AuditEvent) error {
+	pt, err := newPoint(e)
+	if err != nil {
+		return err
+	}
+	exploded, err := tsdb.ExplodePoints(e.OrgID, bucketID, []models.Point{pt})
+	if err != nil {
+		return err
+	}
+	return w.pointsWriter.WritePoints(context.Background(), exploded)
+}
+
+// newPoint returns the point of e. The action and resource type are its tags, as the events are filtered by them.
+func newPoint(e *influxdb.AuditEvent) (models.Point, error) {
+	tags := models.NewTags(map[string]string{
+		actionTag:       e.Action,
+		resourceTypeTag: e.ResourceType,
+	})
+	fields := map[string]interface{}{
+		methodField:     e.Method,
+		pathField:       e.Path,
+		statusCodeField: int64(e.StatusCode),
+	}
+	if e.RemoteAddr != "" {
+		fields[remoteAddrField] = e.RemoteAddr
+	}
+	if e.AuthorizerKind != "" {
+		fields[authorizerKindField] = e.AuthorizerKind
+	}
+	for k, id := range map[string]influxdb.ID{
+		resourceIDField:   e.ResourceID,
+		authorizerIDField: e.AuthorizerID,
+		userIDField:       e.UserID,
+	} {
+		if id.Valid() {
+			fields[k] = id.String()
+		}
+	}
+	if len(e.Changes) > 0 {
+		changes := append([]string(nil), e.Changes...)
+		sort.Strings(changes)
+		fields[changesField] = strings.Join(changes, ",")
+	}
+	return models.NewPoint(measurement, tags, fields, e.Time.UTC())
+}
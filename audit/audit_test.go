@@ -0,0 +1,195 @@
+package audit_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type pointsWriter struct {
+	mu     sync.Mutex
+	points []models.Point
+}
+
+func (w *pointsWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.points = append(w.points, points...)
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	pw := &pointsWriter{}
+	w := audit.NewWriter(pw, zaptest.NewLogger(t))
+
+	tm := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	w.RecordAuditEvent(&influxdb.AuditEvent{
+		Time:         tm,
+		OrgID:        1,
+		AuthorizerID: 2,
+		Action:       influxdb.AuditActionUpdate,
+		ResourceType: "buckets",
+		ResourceID:   3,
+		Changes:      []string{"retentionRules", "name"},
+		Method:       "PATCH",
+		Path:         "/api/v2/buckets/0000000000000003",
+		StatusCode:   200,
+	})
+	// The events of no organization are only logged.
+	w.RecordAuditEvent(&influxdb.AuditEvent{Time: tm, Action: influxdb.AuditActionCreate, ResourceType: "setup"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]interface{}{}
+	for _, pt := range pw.points {
+		var name [16]byte
+		copy(name[:], pt.Name())
+		orgID, bucketID := tsdb.DecodeName(name)
+		if orgID != 1 || bucketID != influxdb.ID(influxdb.BucketTypeAudit) {
+			t.Fatalf("point written to org %s and bucket %s", orgID, bucketID)
+		}
+		if !pt.Time().Equal(tm) {
+			t.Errorf("got point time %s, want %s", pt.Time(), tm)
+		}
+		if action, resourceType := pt.Tags().GetString("action"), pt.Tags().GetString("resourceType"); action != "update" || resourceType != "buckets" {
+			t.Errorf("got tags action=%q resourceType=%q", action, resourceType)
+		}
+		fs, err := pt.Fields()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range fs {
+			fields[k] = v
+		}
+	}
+	want := map[string]interface{}{
+		"authorizerID": "0000000000000002",
+		"resourceID":   "0000000000000003",
+		"changes":      "name,retentionRules",
+		"method":       "PATCH",
+		"path":         "/api/v2/buckets/0000000000000003",
+		"statusCode":   int64(200),
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("got fields %v, want %v", fields, want)
+	}
+}
+
+func TestService_FindAuditEvents(t *testing.T) {
+	tm := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	var script string
+	qs := &querymock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.Authorization == nil || req.Authorization.OrgID != 1 || req.OrganizationID != 1 {
+				t.Fatalf("unexpected query request: %+v", req)
+			}
+			script = req.Compiler.(lang.FluxCompiler).Query
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "action", Type: flux.TString},
+						{Label: "resourceType", Type: flux.TString},
+						{Label: "resourceID", Type: flux.TString},
+						{Label: "changes", Type: flux.TString},
+						{Label: "method", Type: flux.TString},
+						{Label: "path", Type: flux.TString},
+						{Label: "statusCode", Type: flux.TInt},
+					},
+					Data: [][]interface{}{
+						{execute.Time(tm.UnixNano()), "audit", "update", "buckets", "0000000000000003", "name,retentionRules", "PATCH", "/api/v2/buckets/0000000000000003", int64(200)},
+					},
+				}},
+			}}), nil
+		},
+	}
+
+	s := audit.NewService(zaptest.NewLogger(t), 0, mock.NewOrganizationService(), qs, mock.NewDeleteService())
+	events, err := s.FindAuditEvents(context.Background(), influxdb.AuditEventFilter{
+		OrgID:        1,
+		Start:        tm.Add(-time.Hour),
+		Stop:         tm.Add(time.Hour),
+		ResourceType: "buckets",
+		Limit:        10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		`from(bucketID: "000000000000000c")`,
+		`range(start: 2019-06-10T11:00:00Z, stop: 2019-06-10T13:00:00Z)`,
+		`r.resourceType == "buckets"`,
+		`limit(n: 10)`,
+	} {
+		if !strings.Contains(script, s) {
+			t.Errorf("expected %s in script:\n%s", s, script)
+		}
+	}
+
+	want := []*influxdb.AuditEvent{{
+		Time:         tm,
+		OrgID:        1,
+		Action:       influxdb.AuditActionUpdate,
+		ResourceType: "buckets",
+		ResourceID:   3,
+		Changes:      []string{"name", "retentionRules"},
+		Method:       "PATCH",
+		Path:         "/api/v2/buckets/0000000000000003",
+		StatusCode:   200,
+	}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %+v, want %+v", events, want)
+	}
+}
+
+func TestService_EnforceRetention(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationsF = func(ctx context.Context, filter influxdb.OrganizationFilter, opts ...influxdb.FindOptions) ([]*influxdb.Organization, int, error) {
+		return []*influxdb.Organization{{ID: 1}, {ID: 2}}, 2, nil
+	}
+	var deletes []influxdb.DeleteRequest
+	ds := mock.NewDeleteService()
+	ds.DeleteBucketRangePredicateFn = func(ctx context.Context, req influxdb.DeleteRequest) error {
+		deletes = append(deletes, req)
+		return nil
+	}
+
+	s := audit.NewService(zaptest.NewLogger(t), 24*time.Hour, orgs, &querymock.QueryService{}, ds)
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	if err := s.EnforceRetention(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deletes) != 2 {
+		t.Fatalf("expected a delete per organization, got %+v", deletes)
+	}
+	for i, d := range deletes {
+		if d.OrgID != influxdb.ID(i+1) || d.BucketID != influxdb.ID(influxdb.BucketTypeAudit) || !d.Stop.Equal(now.Add(-24*time.Hour)) {
+			t.Errorf("unexpected delete request: %+v", d)
+		}
+		if d.Predicate != `_measurement = 'audit'` {
+			t.Errorf("unexpected delete predicate %q", d.Predicate)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRetention is how long audit events are kept by default.
+	DefaultRetention = 90 * 24 * time.Hour
+
+	// DefaultInterval is the default time between two deletions of the audit events older than the retention.
+	DefaultInterval = time.Hour
+)
+
+var _ influxdb.AuditLogService = (*Service)(nil)
+
+// Service reads the audit events from the audit buckets of the organizations,
+// and deletes the events older than its retention from them.
+type Service struct {
+	OrganizationService influxdb.OrganizationService
+	QueryService        query.QueryService
+	DeleteService       influxdb.DeleteService
+
+	// Retention is how long audit events are kept.
+	Retention time.Duration
+	// Interval is the time between two deletions of the audit events older than the retention.
+	Interval time.Duration
+
+	Logger *zap.Logger
+}
+
+// NewService returns a Service reading the audit buckets through qs and deleting from them with ds.
+func NewService(logger *zap.Logger, retention time.Duration, os influxdb.OrganizationService, qs query.QueryService, ds influxdb.DeleteService) *Service {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Service{
+		OrganizationService: os,
+		QueryService:        qs,
+		DeleteService:       ds,
+		Retention:           retention,
+		Interval:            DefaultInterval,
+		Logger:              logger,
+	}
+}
+
+// FindAuditEvents returns the events matching filter, oldest first.
+func (s *Service) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter) ([]*influxdb.AuditEvent, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	auth, err := bucketAuthorization(filter.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: filter.OrgID,
+		Compiler:       lang.FluxCompiler{Query: eventsQuery(filter)},
+	}
+	itr, err := s.QueryService.Query(ctx, req)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindAuditEvents,
+			Err: err,
+		}
+	}
+	defer itr.Release()
+
+	var events []*influxdb.AuditEvent
+	for itr.More() {
+		err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				events = append(events, readEvents(filter.OrgID, cr)...)
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindAuditEvents,
+			Err: err,
+		}
+	}
+	return events, nil
+}
+
+// eventsQuery returns the script reading the audit events matching filter.
+func eventsQuery(filter influxdb.AuditEventFilter) string {
+	predicates := []string{fmt.Sprintf("r._measurement == %q", measurement)}
+	if filter.ResourceType != "" {
+		predicates = append(predicates, fmt.Sprintf("r.%s == %q", resourceTypeTag, filter.ResourceType))
+	}
+	if filter.Action != "" {
+		predicates = append(predicates, fmt.Sprintf("r.%s == %q", actionTag, filter.Action))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `from(bucketID: %q)
+  |> range(start: %s, stop: %s)
+  |> filter(fn: (r) => %s)
+  |> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")`,
+		bucketID.String(), filter.Start.UTC().Format(time.RFC3339Nano), filter.Stop.UTC().Format(time.RFC3339Nano),
+		strings.Join(predicates, " and "))
+	if filter.ResourceID != nil {
+		fmt.Fprintf(&b, "\n  |> filter(fn: (r) => r.%s == %q)", resourceIDField, filter.ResourceID.String())
+	}
+	b.WriteString("\n  |> group()\n  |> sort(columns: [\"_time\"])")
+	if filter.Limit > 0 {
+		fmt.Fprintf(&b, "\n  |> limit(n: %d)", filter.Limit)
+	}
+	return b.String()
+}
+
+// readEvents returns the events of the rows of cr, whose columns are the tags and fields of the audit points.
+func readEvents(orgID influxdb.ID, cr flux.ColReader) []*influxdb.AuditEvent {
+	events := make([]*influxdb.AuditEvent, cr.Len())
+	for i := range events {
+		events[i] = &influxdb.AuditEvent{OrgID: orgID}
+	}
+
+	for j, col := range cr.Cols() {
+		switch col.Type {
+		case flux.TTime:
+			if col.Label != "_time" {
+				continue
+			}
+			vs := cr.Times(j)
+			for i, e := range events {
+				if !vs.IsNull(i) {
+					e.Time = values.Time(vs.Value(i)).Time().UTC()
+				}
+			}
+		case flux.TInt:
+			if col.Label != statusCodeField {
+				continue
+			}
+			vs := cr.Ints(j)
+			for i, e := range events {
+				if !vs.IsNull(i) {
+					e.StatusCode = int(vs.Value(i))
+				}
+			}
+		case flux.TString:
+			vs := cr.Strings(j)
+			for i, e := range events {
+				if !vs.IsNull(i) {
+					setStringColumn(e, col.Label, vs.ValueString(i))
+				}
+			}
+		}
+	}
+	return events
+}
+
+// setStringColumn sets the property of e stored in the column label to v.
+func setStringColumn(e *influxdb.AuditEvent, label, v string) {
+	decodeID := func(id *influxdb.ID) {
+		// The IDs are written by the Writer, and an invalid one is left unset.
+		_ = id.DecodeFromString(v)
+	}
+
+	switch label {
+	case actionTag:
+		e.Action = v
+	case resourceTypeTag:
+		e.ResourceType = v
+	case resourceIDField:
+		decodeID(&e.ResourceID)
+	case authorizerKindField:
+		e.AuthorizerKind = v
+	case authorizerIDField:
+		decodeID(&e.AuthorizerID)
+	case userIDField:
+		decodeID(&e.UserID)
+	case changesField:
+		if v != "" {
+			e.Changes = strings.Split(v, ",")
+		}
+	case methodField:
+		e.Method = v
+	case pathField:
+		e.Path = v
+	case remoteAddrField:
+		e.RemoteAddr = v
+	}
+}
+
+// Run deletes the audit events older than the retention every interval until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	s.Logger.Info("Starting", zap.Duration("interval", s.Interval), zap.Duration("retention", s.Retention))
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.EnforceRetention(ctx, time.Now()); err != nil {
+				s.Logger.Info("Failed to delete expired audit events", zap.Error(err))
+			}
+		case <-ctx.Done():
+			s.Logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// EnforceRetention deletes the audit events recorded before now minus the retention.
+// The failure of an organization is logged, and does not prevent the others from being cleaned up.
+func (s *Service) EnforceRetention(ctx context.Context, now time.Time) error {
+	orgs, _, err := s.OrganizationService.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-s.Retention).UTC()
+	for _, o := range orgs {
+		err := s.DeleteService.DeleteBucketRangePredicate(ctx, influxdb.DeleteRequest{
+			OrgID:     o.ID,
+			BucketID:  bucketID,
+			Start:     time.Unix(0, 0).UTC(),
+			Stop:      cutoff,
+			Predicate: fmt.Sprintf("_measurement = '%s'", measurement),
+		})
+		if err != nil {
+			s.Logger.Info("Failed to delete expired audit events of organization", zap.String("org_id", o.ID.String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// bucketAuthorization returns the authorization the service reads the audit bucket of orgID with.
+func bucketAuthorization(orgID influxdb.ID) (*influxdb.Authorization, error) {
+	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &influxdb.Authorization{
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: []influxdb.Permission{*p},
+	}, nil
+}
@@ -0,0 +1,90 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AnnouncementService = (*AnnouncementService)(nil)
+
+// AnnouncementService wraps a influxdb.AnnouncementService and authorizes actions
+// against it appropriately.
+type AnnouncementService struct {
+	s influxdb.AnnouncementService
+}
+
+// NewAnnouncementService constructs an instance of an authorizing announcement service.
+func NewAnnouncementService(s influxdb.AnnouncementService) *AnnouncementService {
+	return &AnnouncementService{
+		s: s,
+	}
+}
+
+func authorizeAnnouncementAction(ctx context.Context, action influxdb.Action) error {
+	p, err := influxdb.NewGlobalPermission(action, influxdb.AnnouncementsResourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindAnnouncements retrieves all announcements that match the provided filter.
+// Active announcements are shown to everyone, unauthenticated users included,
+// so the list is only filtered down to the active ones if the authorizer on context,
+// if any, does not have read access to announcements.
+func (s *AnnouncementService) FindAnnouncements(ctx context.Context, filter influxdb.AnnouncementFilter) ([]*influxdb.Announcement, error) {
+	as, err := s.s.FindAnnouncements(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeAnnouncementAction(ctx, influxdb.ReadAction); err == nil {
+		return as, nil
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	now := time.Now()
+	announcements := as[:0]
+	for _, a := range as {
+		if a.Active(now) {
+			announcements = append(announcements, a)
+		}
+	}
+
+	return announcements, nil
+}
+
+// CreateAnnouncement checks to see if the authorizer on context has write access to announcements.
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, a *influxdb.Announcement) error {
+	if err := authorizeAnnouncementAction(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.CreateAnnouncement(ctx, a)
+}
+
+// UpdateAnnouncement checks to see if the authorizer on context has write access to announcements.
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, id influxdb.ID, upd influxdb.AnnouncementUpdate) (*influxdb.Announcement, error) {
+	if err := authorizeAnnouncementAction(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateAnnouncement(ctx, id, upd)
+}
+
+// DeleteAnnouncement checks to see if the authorizer on context has write access to announcements.
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id influxdb.ID) error {
+	if err := authorizeAnnouncementAction(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.DeleteAnnouncement(ctx, id)
+}
@@ -0,0 +1,169 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func announcementFixtures(now time.Time) []*influxdb.Announcement {
+	return []*influxdb.Announcement{
+		{
+			ID:       1,
+			Message:  "maintenance in progress",
+			Severity: influxdb.AnnouncementWarning,
+			StartsAt: now.Add(-time.Hour),
+			EndsAt:   now.Add(time.Hour),
+		},
+		{
+			ID:       2,
+			Message:  "upgrade tomorrow",
+			Severity: influxdb.AnnouncementInfo,
+			StartsAt: now.Add(24 * time.Hour),
+			EndsAt:   now.Add(25 * time.Hour),
+		},
+	}
+}
+
+func TestAnnouncementService_FindAnnouncements(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		authorizer    influxdb.Authorizer
+		announcements []*influxdb.Announcement
+	}{
+		{
+			name: "authorized to see all announcements",
+			authorizer: &Authorizer{[]influxdb.Permission{
+				{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.AnnouncementsResourceType,
+					},
+				},
+			}},
+			announcements: announcementFixtures(now),
+		},
+		{
+			name: "only active announcements without read permission",
+			authorizer: &Authorizer{[]influxdb.Permission{
+				{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+					},
+				},
+			}},
+			announcements: announcementFixtures(now)[:1],
+		},
+		{
+			name:          "only active announcements without authorizer",
+			announcements: announcementFixtures(now)[:1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewAnnouncementService()
+			m.FindAnnouncementsFn = func(ctx context.Context, filter influxdb.AnnouncementFilter) ([]*influxdb.Announcement, error) {
+				return announcementFixtures(now), nil
+			}
+			s := authorizer.NewAnnouncementService(m)
+
+			ctx := context.Background()
+			if tt.authorizer != nil {
+				ctx = influxdbcontext.SetAuthorizer(ctx, tt.authorizer)
+			}
+
+			announcements, err := s.FindAnnouncements(ctx, influxdb.AnnouncementFilter{})
+			if err != nil {
+				t.Fatalf("failed to find announcements: %v", err)
+			}
+
+			if diff := cmp.Diff(announcements, tt.announcements); diff != "" {
+				t.Errorf("announcements are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestAnnouncementService_CreateAnnouncement(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+	}
+	type wants struct {
+		err error
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to create an announcement",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.AnnouncementsResourceType,
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to create an announcement",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.AnnouncementsResourceType,
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:announcements is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewAnnouncementService(mock.NewAnnouncementService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
+
+			err := s.CreateAnnouncement(ctx, announcementFixtures(time.Now())[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
+
+func TestAnnouncementService_DeleteAnnouncement(t *testing.T) {
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.AnnouncementsResourceType,
+			},
+		},
+	}})
+
+	s := authorizer.NewAnnouncementService(mock.NewAnnouncementService())
+	err := s.DeleteAnnouncement(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:announcements is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
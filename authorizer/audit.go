@@ -0,0 +1,32 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService wraps a influxdb.AuditLogService and authorizes actions
+// against it appropriately.
+// The audit log of an organization is read by those who administer it, with write access to the organization.
+type AuditLogService struct {
+	s influxdb.AuditLogService
+}
+
+// NewAuditLogService constructs an instance of an authorizing audit log service.
+func NewAuditLogService(s influxdb.AuditLogService) *AuditLogService {
+	return &AuditLogService{
+		s: s,
+	}
+}
+
+// FindAuditEvents checks to see if the authorizer on context has write access to the organization of the filter.
+func (s *AuditLogService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter) ([]*influxdb.AuditEvent, error) {
+	if err := authorizeWriteOrg(ctx, filter.OrgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindAuditEvents(ctx, filter)
+}
@@ -0,0 +1,69 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.BucketBatchService = (*BucketBatchService)(nil)
+
+// BucketBatchService wraps a influxdb.BucketBatchService and authorizes actions
+// against it appropriately.
+type BucketBatchService struct {
+	s       influxdb.BucketBatchService
+	buckets influxdb.BucketService
+}
+
+// NewBucketBatchService constructs an instance of an authorizing bucket batch service.
+// The buckets updated and deleted by a batch are looked up in bs to authorize them.
+func NewBucketBatchService(s influxdb.BucketBatchService, bs influxdb.BucketService) *BucketBatchService {
+	return &BucketBatchService{
+		s:       s,
+		buckets: bs,
+	}
+}
+
+// ApplyBucketBatch checks to see if the authorizer on context has write access to the buckets of the organizations
+// of the buckets created, and to the buckets updated and deleted. No operation is applied unless all of them are authorized.
+func (s *BucketBatchService) ApplyBucketBatch(ctx context.Context, ops []influxdb.BucketBatchOperation) ([]influxdb.BucketBatchResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	results := make([]influxdb.BucketBatchResult, len(ops))
+	failed := 0
+	for i, op := range ops {
+		if err := s.authorizeOperation(ctx, op); err != nil {
+			results[i].Err = err
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, &influxdb.BucketBatchError{Failed: failed}
+	}
+
+	return s.s.ApplyBucketBatch(ctx, ops)
+}
+
+func (s *BucketBatchService) authorizeOperation(ctx context.Context, op influxdb.BucketBatchOperation) error {
+	switch op.Op {
+	case influxdb.BucketBatchCreate:
+		if op.Bucket == nil {
+			// The invalid operations are reported by the service.
+			return nil
+		}
+		p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.BucketsResourceType, op.Bucket.OrganizationID)
+		if err != nil {
+			return err
+		}
+		return IsAllowed(ctx, *p)
+	case influxdb.BucketBatchUpdate, influxdb.BucketBatchDelete:
+		b, err := s.buckets.FindBucketByID(ctx, op.ID)
+		if err != nil {
+			return err
+		}
+		return authorizeWriteBucket(ctx, b.OrganizationID, op.ID)
+	}
+	return nil
+}
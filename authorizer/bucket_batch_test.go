@@ -0,0 +1,91 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBucketBatchService_ApplyBucketBatch(t *testing.T) {
+	writeOrgBuckets := func(orgID influxdb.ID) influxdb.Permission {
+		return influxdb.Permission{
+			Action: "write",
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(orgID),
+			},
+		}
+	}
+	ops := []influxdb.BucketBatchOperation{
+		{Op: influxdb.BucketBatchCreate, Bucket: &influxdb.Bucket{OrganizationID: 10, Name: "a"}},
+		{Op: influxdb.BucketBatchUpdate, ID: 1, Update: &influxdb.BucketUpdate{}},
+		{Op: influxdb.BucketBatchDelete, ID: 2},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		applied     bool
+		errs        []error
+	}{
+		{
+			name:        "authorized to write the buckets of both organizations",
+			permissions: []influxdb.Permission{writeOrgBuckets(10), writeOrgBuckets(20)},
+			applied:     true,
+			errs:        []error{nil, nil, nil},
+		},
+		{
+			name:        "unauthorized to write the buckets of one organization",
+			permissions: []influxdb.Permission{writeOrgBuckets(10)},
+			errs: []error{
+				nil,
+				nil,
+				&influxdb.Error{
+					Msg:  "write:orgs/0000000000000014/buckets/0000000000000002 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				if id == 2 {
+					return &influxdb.Bucket{ID: id, OrganizationID: 20}, nil
+				}
+				return &influxdb.Bucket{ID: id, OrganizationID: 10}, nil
+			}
+			batches := mock.NewBucketBatchService()
+			applied := false
+			batches.ApplyBucketBatchFn = func(ctx context.Context, ops []influxdb.BucketBatchOperation) ([]influxdb.BucketBatchResult, error) {
+				applied = true
+				return make([]influxdb.BucketBatchResult, len(ops)), nil
+			}
+			s := authorizer.NewBucketBatchService(batches, buckets)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			results, err := s.ApplyBucketBatch(ctx, ops)
+			if applied != tt.applied {
+				t.Errorf("expected the batch applied to be %v", tt.applied)
+			}
+			if !tt.applied {
+				if e, ok := err.(*influxdb.BucketBatchError); !ok || e.Failed != 1 {
+					t.Errorf("unexpected error %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			for i := range results {
+				influxdbtesting.ErrorsEqual(t, results[i].Err, tt.errs[i])
+			}
+		})
+	}
+}
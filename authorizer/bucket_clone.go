@@ -0,0 +1,81 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BucketCloneService = (*BucketCloneService)(nil)
+
+// BucketCloneService wraps a influxdb.BucketCloneService and authorizes actions
+// against it appropriately.
+type BucketCloneService struct {
+	s influxdb.BucketCloneService
+}
+
+// NewBucketCloneService constructs an instance of an authorizing bucket clone service.
+func NewBucketCloneService(s influxdb.BucketCloneService) *BucketCloneService {
+	return &BucketCloneService{
+		s: s,
+	}
+}
+
+// CreateBucketCloneJob checks to see if the authorizer on context has read access to the bucket cloned,
+// and write access to the buckets of the organization the clone is created in.
+func (s *BucketCloneService) CreateBucketCloneJob(ctx context.Context, req influxdb.BucketCloneRequest) (*influxdb.BucketCloneJob, error) {
+	if err := authorizeReadBucket(ctx, req.OrgID, req.BucketID); err != nil {
+		return nil, err
+	}
+
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.BucketsResourceType, req.DestOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := IsAllowed(ctx, *p); err != nil {
+		return nil, err
+	}
+
+	return s.s.CreateBucketCloneJob(ctx, req)
+}
+
+// FindBucketCloneJobByID checks to see if the authorizer on context has read access to the bucket cloned by the job.
+func (s *BucketCloneService) FindBucketCloneJobByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketCloneJob, error) {
+	job, err := s.s.FindBucketCloneJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, job.Request.OrgID, job.Request.BucketID); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// FindBucketCloneJobs retrieves all bucket clone jobs that match the provided filter and then filters the list down
+// to only the jobs of the buckets cloned that are authorized.
+func (s *BucketCloneService) FindBucketCloneJobs(ctx context.Context, filter influxdb.BucketCloneJobFilter) ([]*influxdb.BucketCloneJob, error) {
+	js, err := s.s.FindBucketCloneJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	jobs := js[:0]
+	for _, j := range js {
+		err := authorizeReadBucket(ctx, j.Request.OrgID, j.Request.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,120 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBucketCloneService_CreateBucketCloneJob(t *testing.T) {
+	readBucket := influxdb.Permission{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type: influxdb.BucketsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+	writeDestBuckets := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(20),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to read the bucket and write the buckets of the destination",
+			permissions: []influxdb.Permission{readBucket, writeDestBuckets},
+		},
+		{
+			name:        "unauthorized to read the bucket",
+			permissions: []influxdb.Permission{writeDestBuckets},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to write the buckets of the destination",
+			permissions: []influxdb.Permission{readBucket},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/0000000000000014/buckets is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewBucketCloneService(mock.NewBucketCloneService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			_, err := s.CreateBucketCloneJob(ctx, influxdb.BucketCloneRequest{
+				OrgID:          10,
+				BucketID:       1,
+				DestOrgID:      20,
+				DestBucketName: "staging",
+				Days:           7,
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestBucketCloneService_FindBucketCloneJobs(t *testing.T) {
+	jobs := []*influxdb.BucketCloneJob{
+		{ID: 100, Request: influxdb.BucketCloneRequest{OrgID: 10, BucketID: 1}},
+		{ID: 200, Request: influxdb.BucketCloneRequest{OrgID: 10, BucketID: 2}},
+	}
+	m := mock.NewBucketCloneService()
+	m.FindBucketCloneJobByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.BucketCloneJob, error) {
+		for _, j := range jobs {
+			if j.ID == id {
+				return j, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	m.FindBucketCloneJobsFn = func(ctx context.Context, filter influxdb.BucketCloneJobFilter) ([]*influxdb.BucketCloneJob, error) {
+		return append([]*influxdb.BucketCloneJob(nil), jobs...), nil
+	}
+	s := authorizer.NewBucketCloneService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	found, err := s.FindBucketCloneJobs(ctx, influxdb.BucketCloneJobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != 100 {
+		t.Errorf("expected only the clone job of the readable bucket, got %+v", found)
+	}
+
+	if _, err := s.FindBucketCloneJobByID(ctx, 100); err != nil {
+		t.Errorf("expected the clone job of the readable bucket, got %v", err)
+	}
+	_, err = s.FindBucketCloneJobByID(ctx, 200)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -0,0 +1,94 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BucketLifecycleService = (*BucketLifecycleService)(nil)
+
+// BucketLifecycleService wraps a influxdb.BucketLifecycleService and authorizes actions
+// against it appropriately.
+type BucketLifecycleService struct {
+	s influxdb.BucketLifecycleService
+}
+
+// NewBucketLifecycleService constructs an instance of an authorizing bucket lifecycle service.
+func NewBucketLifecycleService(s influxdb.BucketLifecycleService) *BucketLifecycleService {
+	return &BucketLifecycleService{
+		s: s,
+	}
+}
+
+// authorizeWriteLifecycle checks to see if the authorizer on context has write access to a bucket,
+// and to the buckets and tasks of its organization the lifecycle policy of the bucket materializes.
+func authorizeWriteLifecycle(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	if err := authorizeWriteBucket(ctx, orgID, bucketID); err != nil {
+		return err
+	}
+
+	for _, rt := range []influxdb.ResourceType{influxdb.BucketsResourceType, influxdb.TasksResourceType} {
+		p, err := influxdb.NewPermission(influxdb.WriteAction, rt, orgID)
+		if err != nil {
+			return err
+		}
+		if err := IsAllowed(ctx, *p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindBucketLifecyclePolicy checks to see if the authorizer on context has read access to the bucket of the policy.
+func (s *BucketLifecycleService) FindBucketLifecyclePolicy(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketLifecyclePolicy, error) {
+	p, err := s.s.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, p.OrgID, p.BucketID); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// PutBucketLifecyclePolicy checks to see if the authorizer on context has write access to the bucket of the policy,
+// and to the buckets and tasks of its organization.
+func (s *BucketLifecycleService) PutBucketLifecyclePolicy(ctx context.Context, p *influxdb.BucketLifecyclePolicy) error {
+	if err := authorizeWriteLifecycle(ctx, p.OrgID, p.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.PutBucketLifecyclePolicy(ctx, p)
+}
+
+// DeleteBucketLifecyclePolicy checks to see if the authorizer on context has write access to the bucket of the policy,
+// and to the buckets and tasks of its organization.
+func (s *BucketLifecycleService) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID influxdb.ID) error {
+	p, err := s.s.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteLifecycle(ctx, p.OrgID, p.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteBucketLifecyclePolicy(ctx, bucketID)
+}
+
+// FindBucketLifecycleStatus checks to see if the authorizer on context has read access to the bucket of the policy.
+func (s *BucketLifecycleService) FindBucketLifecycleStatus(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketLifecycleStatus, error) {
+	st, err := s.s.FindBucketLifecycleStatus(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, st.OrgID, st.BucketID); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
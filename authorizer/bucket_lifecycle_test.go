@@ -0,0 +1,111 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBucketLifecycleService_PutBucketLifecyclePolicy(t *testing.T) {
+	writeBucket := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type: influxdb.BucketsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+	writeBuckets := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	writeTasks := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.TasksResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to write the buckets and tasks of the organization",
+			permissions: []influxdb.Permission{writeBuckets, writeTasks},
+		},
+		{
+			name:        "unauthorized to write the bucket",
+			permissions: []influxdb.Permission{writeTasks},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to create the buckets of the tiers",
+			permissions: []influxdb.Permission{writeBucket, writeTasks},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to create the tasks of the tiers",
+			permissions: []influxdb.Permission{writeBuckets},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/tasks is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewBucketLifecycleService(mock.NewBucketLifecycleService())
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.PutBucketLifecyclePolicy(ctx, &influxdb.BucketLifecyclePolicy{
+				OrgID:    10,
+				BucketID: 1,
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestBucketLifecycleService_FindBucketLifecycleStatus(t *testing.T) {
+	m := mock.NewBucketLifecycleService()
+	m.FindBucketLifecycleStatusFn = func(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketLifecycleStatus, error) {
+		return &influxdb.BucketLifecycleStatus{OrgID: 10, BucketID: bucketID}, nil
+	}
+	s := authorizer.NewBucketLifecycleService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	if _, err := s.FindBucketLifecycleStatus(ctx, 1); err != nil {
+		t.Errorf("expected the status of the readable bucket, got %v", err)
+	}
+	_, err := s.FindBucketLifecycleStatus(ctx, 2)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -0,0 +1,40 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CompactionService = (*CompactionService)(nil)
+
+// CompactionService wraps a influxdb.CompactionService and authorizes actions
+// against it appropriately.
+type CompactionService struct {
+	s influxdb.CompactionService
+}
+
+// NewCompactionService constructs an instance of an authorizing compaction service.
+func NewCompactionService(s influxdb.CompactionService) *CompactionService {
+	return &CompactionService{
+		s: s,
+	}
+}
+
+// FindCompactionSettings checks to see if the authorizer on context has read access to ops.
+func (s *CompactionService) FindCompactionSettings(ctx context.Context) (*influxdb.CompactionSettings, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindCompactionSettings(ctx)
+}
+
+// UpdateCompactionSettings checks to see if the authorizer on context has write access to ops.
+func (s *CompactionService) UpdateCompactionSettings(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateCompactionSettings(ctx, upd)
+}
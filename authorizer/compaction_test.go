@@ -0,0 +1,87 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestCompactionService_FindCompactionSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to view compaction settings",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+		},
+		{
+			name: "unauthorized to view compaction settings",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewCompactionService(mock.NewCompactionService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			_, err := s.FindCompactionSettings(ctx)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestCompactionService_UpdateCompactionSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to change compaction settings",
+			permission: influxdb.Permission{
+				Action:   "write",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+		},
+		{
+			name: "unauthorized to change compaction settings",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewCompactionService(mock.NewCompactionService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			n := 2
+			_, err := s.UpdateCompactionSettings(ctx, influxdb.CompactionSettingsUpdate{MaxConcurrent: &n})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CORSPolicyService = (*CORSPolicyService)(nil)
+
+// CORSPolicyService wraps a influxdb.CORSPolicyService and authorizes actions
+// against it appropriately.
+type CORSPolicyService struct {
+	s influxdb.CORSPolicyService
+}
+
+// NewCORSPolicyService constructs an instance of an authorizing CORS policy service.
+func NewCORSPolicyService(s influxdb.CORSPolicyService) *CORSPolicyService {
+	return &CORSPolicyService{
+		s: s,
+	}
+}
+
+// FindCORSPolicy checks to see if the authorizer on context has read access to ops.
+func (s *CORSPolicyService) FindCORSPolicy(ctx context.Context) (*influxdb.CORSPolicy, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindCORSPolicy(ctx)
+}
+
+// UpdateCORSPolicy checks to see if the authorizer on context has write access to ops.
+func (s *CORSPolicyService) UpdateCORSPolicy(ctx context.Context, p influxdb.CORSPolicy) (*influxdb.CORSPolicy, error) {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateCORSPolicy(ctx, p)
+}
+
+// DeleteCORSPolicy checks to see if the authorizer on context has write access to ops.
+func (s *CORSPolicyService) DeleteCORSPolicy(ctx context.Context) error {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.DeleteCORSPolicy(ctx)
+}
@@ -0,0 +1,60 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestCORSPolicyService(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		readErr    error
+		writeErr   error
+	}{
+		{
+			name: "authorized to write ops",
+			permission: influxdb.Permission{
+				Action:   "write",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+			readErr: &influxdb.Error{
+				Msg:  "read:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name: "authorized to read ops",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+			writeErr: &influxdb.Error{
+				Msg:  "write:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewCORSPolicyService(mock.NewCORSPolicyService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			_, err := s.FindCORSPolicy(ctx)
+			influxdbtesting.ErrorsEqual(t, err, tt.readErr)
+
+			_, err = s.UpdateCORSPolicy(ctx, influxdb.DefaultCORSPolicy())
+			influxdbtesting.ErrorsEqual(t, err, tt.writeErr)
+
+			err = s.DeleteCORSPolicy(ctx)
+			influxdbtesting.ErrorsEqual(t, err, tt.writeErr)
+		})
+	}
+}
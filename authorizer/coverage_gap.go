@@ -0,0 +1,31 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CoverageGapService = (*CoverageGapService)(nil)
+
+// CoverageGapService wraps a influxdb.CoverageGapService and authorizes actions
+// against it appropriately.
+type CoverageGapService struct {
+	s influxdb.CoverageGapService
+}
+
+// NewCoverageGapService constructs an instance of an authorizing coverage gap service.
+func NewCoverageGapService(s influxdb.CoverageGapService) *CoverageGapService {
+	return &CoverageGapService{
+		s: s,
+	}
+}
+
+// FindCoverageGaps checks to see if the authorizer on context has read access to the bucket of the request.
+func (s *CoverageGapService) FindCoverageGaps(ctx context.Context, req influxdb.CoverageGapRequest) ([]*influxdb.CoverageGap, error) {
+	if err := authorizeReadBucket(ctx, req.OrgID, req.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindCoverageGaps(ctx, req)
+}
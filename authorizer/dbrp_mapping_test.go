@@ -0,0 +1,155 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func dbrpMappingFixtures() []*influxdb.DBRPMapping {
+	return []*influxdb.DBRPMapping{
+		{
+			Cluster:         "cluster",
+			Database:        "telegraf",
+			RetentionPolicy: "autogen",
+			Default:         true,
+			OrganizationID:  10,
+			BucketID:        1,
+		},
+		{
+			Cluster:         "cluster",
+			Database:        "inventory",
+			RetentionPolicy: "autogen",
+			Default:         true,
+			OrganizationID:  11,
+			BucketID:        2,
+		},
+	}
+}
+
+func TestDBRPMappingService_FindMany(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		mappings   []*influxdb.DBRPMapping
+	}{
+		{
+			name: "authorized to see all dbrp mappings",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+				},
+			},
+			mappings: dbrpMappingFixtures(),
+		},
+		{
+			name: "authorized to see the dbrp mappings of one bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			mappings: dbrpMappingFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewDBRPMappingService()
+			m.FindManyFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+				return dbrpMappingFixtures(), 2, nil
+			}
+			s := authorizer.NewDBRPMappingService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			mappings, n, err := s.FindMany(ctx, influxdb.DBRPMappingFilter{})
+			if err != nil {
+				t.Fatalf("failed to find dbrp mappings: %v", err)
+			}
+			if diff := cmp.Diff(mappings, tt.mappings); diff != "" {
+				t.Errorf("dbrp mappings are different -got/+want\ndiff %s", diff)
+			}
+			if n != len(tt.mappings) {
+				t.Errorf("got %d dbrp mappings, want %d", n, len(tt.mappings))
+			}
+		})
+	}
+}
+
+func TestDBRPMappingService_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to create a dbrp mapping",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to create a dbrp mapping",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDBRPMappingService(mock.NewDBRPMappingService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.Create(ctx, dbrpMappingFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDBRPMappingService_Delete(t *testing.T) {
+	m := mock.NewDBRPMappingService()
+	m.FindByFn = func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+		return dbrpMappingFixtures()[0], nil
+	}
+	s := authorizer.NewDBRPMappingService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+			},
+		},
+	}})
+
+	err := s.Delete(ctx, "cluster", "telegraf", "autogen")
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -0,0 +1,96 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.DeleteService = (*DeleteService)(nil)
+
+// DeleteService wraps a influxdb.DeleteService and authorizes actions
+// against it appropriately.
+type DeleteService struct {
+	s influxdb.DeleteService
+}
+
+// NewDeleteService constructs an instance of an authorizing delete service.
+func NewDeleteService(s influxdb.DeleteService) *DeleteService {
+	return &DeleteService{
+		s: s,
+	}
+}
+
+// DeleteBucketRangePredicate checks to see if the authorizer on context has write access to the bucket of the request.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, req influxdb.DeleteRequest) error {
+	if err := authorizeWriteBucket(ctx, req.OrgID, req.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteBucketRangePredicate(ctx, req)
+}
+
+var _ influxdb.DeleteJobService = (*DeleteJobService)(nil)
+
+// DeleteJobService wraps a influxdb.DeleteJobService and authorizes actions
+// against it appropriately.
+type DeleteJobService struct {
+	s influxdb.DeleteJobService
+}
+
+// NewDeleteJobService constructs an instance of an authorizing delete job service.
+func NewDeleteJobService(s influxdb.DeleteJobService) *DeleteJobService {
+	return &DeleteJobService{
+		s: s,
+	}
+}
+
+// CreateDeleteJob checks to see if the authorizer on context has write access to the bucket of the request.
+func (s *DeleteJobService) CreateDeleteJob(ctx context.Context, req influxdb.DeleteRequest) (*influxdb.DeleteJob, error) {
+	if err := authorizeWriteBucket(ctx, req.OrgID, req.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.CreateDeleteJob(ctx, req)
+}
+
+// FindDeleteJobByID checks to see if the authorizer on context has read access to the bucket of the job.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	job, err := s.s.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, job.Request.OrgID, job.Request.BucketID); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// FindDeleteJobs retrieves all delete jobs that match the provided filter and then filters the list down to only the jobs
+// of the buckets that are authorized.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	js, err := s.s.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	jobs := js[:0]
+	for _, j := range js {
+		err := authorizeReadBucket(ctx, j.Request.OrgID, j.Request.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
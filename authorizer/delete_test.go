@@ -0,0 +1,160 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestDeleteService_DeleteBucketRangePredicate(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to write the bucket",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "unauthorized to write the bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDeleteService(mock.NewDeleteService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.DeleteBucketRangePredicate(ctx, influxdb.DeleteRequest{
+				OrgID:     10,
+				BucketID:  1,
+				Start:     time.Unix(0, 0),
+				Stop:      time.Unix(3600, 0),
+				Predicate: `host="a"`,
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestDeleteJobService_CreateDeleteJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to write the bucket",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "unauthorized to write the bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDeleteJobService(mock.NewDeleteJobService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.CreateDeleteJob(ctx, influxdb.DeleteRequest{
+				OrgID:     10,
+				BucketID:  1,
+				Start:     time.Unix(0, 0),
+				Stop:      time.Unix(3600, 0),
+				Predicate: `host="a"`,
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestDeleteJobService_FindDeleteJobs(t *testing.T) {
+	jobs := []*influxdb.DeleteJob{
+		{ID: 100, Request: influxdb.DeleteRequest{OrgID: 10, BucketID: 1}},
+		{ID: 200, Request: influxdb.DeleteRequest{OrgID: 10, BucketID: 2}},
+	}
+	m := mock.NewDeleteJobService()
+	m.FindDeleteJobByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+		for _, j := range jobs {
+			if j.ID == id {
+				return j, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	m.FindDeleteJobsFn = func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+		return append([]*influxdb.DeleteJob(nil), jobs...), nil
+	}
+	s := authorizer.NewDeleteJobService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	found, err := s.FindDeleteJobs(ctx, influxdb.DeleteJobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != 100 {
+		t.Errorf("expected only the delete job of the readable bucket, got %+v", found)
+	}
+
+	if _, err := s.FindDeleteJobByID(ctx, 100); err != nil {
+		t.Errorf("expected the delete job of the readable bucket, got %v", err)
+	}
+	_, err = s.FindDeleteJobByID(ctx, 200)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
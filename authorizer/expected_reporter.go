@@ -0,0 +1,104 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ExpectedReporterService = (*ExpectedReporterService)(nil)
+
+// ExpectedReporterService wraps a influxdb.ExpectedReporterService and authorizes actions
+// against it appropriately.
+// Expected reporters belong to their bucket, so reading them requires read access to the bucket
+// and managing them requires write access to the bucket.
+type ExpectedReporterService struct {
+	s influxdb.ExpectedReporterService
+}
+
+// NewExpectedReporterService constructs an instance of an authorizing expected reporter service.
+func NewExpectedReporterService(s influxdb.ExpectedReporterService) *ExpectedReporterService {
+	return &ExpectedReporterService{
+		s: s,
+	}
+}
+
+// FindExpectedReporterByID checks to see if the authorizer on context has read access to the bucket of the expected reporter.
+func (s *ExpectedReporterService) FindExpectedReporterByID(ctx context.Context, id influxdb.ID) (*influxdb.ExpectedReporter, error) {
+	r, err := s.s.FindExpectedReporterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, r.OrgID, r.BucketID); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// FindExpectedReporters retrieves all expected reporters that match the provided filter
+// and then filters the list down to only the reporters of buckets that are authorized.
+func (s *ExpectedReporterService) FindExpectedReporters(ctx context.Context, filter influxdb.ExpectedReporterFilter) ([]*influxdb.ExpectedReporter, error) {
+	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
+	// will likely be expensive.
+	rs, err := s.s.FindExpectedReporters(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	reporters := rs[:0]
+	for _, r := range rs {
+		err := authorizeReadBucket(ctx, r.OrgID, r.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		reporters = append(reporters, r)
+	}
+
+	return reporters, nil
+}
+
+// CreateExpectedReporter checks to see if the authorizer on context has write access to the bucket of the expected reporter.
+func (s *ExpectedReporterService) CreateExpectedReporter(ctx context.Context, r *influxdb.ExpectedReporter) error {
+	if err := authorizeWriteBucket(ctx, r.OrgID, r.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.CreateExpectedReporter(ctx, r)
+}
+
+// UpdateExpectedReporter checks to see if the authorizer on context has write access to the bucket of the expected reporter.
+func (s *ExpectedReporterService) UpdateExpectedReporter(ctx context.Context, id influxdb.ID, upd influxdb.ExpectedReporterUpdate) (*influxdb.ExpectedReporter, error) {
+	r, err := s.s.FindExpectedReporterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, r.OrgID, r.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateExpectedReporter(ctx, id, upd)
+}
+
+// DeleteExpectedReporter checks to see if the authorizer on context has write access to the bucket of the expected reporter.
+func (s *ExpectedReporterService) DeleteExpectedReporter(ctx context.Context, id influxdb.ID) error {
+	r, err := s.s.FindExpectedReporterByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, r.OrgID, r.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteExpectedReporter(ctx, id)
+}
@@ -0,0 +1,191 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func expectedReporterFixtures() []*influxdb.ExpectedReporter {
+	return []*influxdb.ExpectedReporter{
+		{
+			ID:       1,
+			OrgID:    10,
+			BucketID: 1,
+			TagKey:   "host",
+			TagValue: "server01",
+			Interval: time.Minute,
+		},
+		{
+			ID:       2,
+			OrgID:    10,
+			BucketID: 2,
+			TagKey:   "host",
+			TagValue: "server02",
+			Interval: time.Minute,
+		},
+	}
+}
+
+func TestExpectedReporterService_FindExpectedReporters(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		reporters  []*influxdb.ExpectedReporter
+	}{
+		{
+			name: "authorized to see all expected reporters",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+				},
+			},
+			reporters: expectedReporterFixtures(),
+		},
+		{
+			name: "authorized to see the expected reporters of one bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			reporters: expectedReporterFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewExpectedReporterService()
+			m.FindExpectedReportersFn = func(ctx context.Context, filter influxdb.ExpectedReporterFilter) ([]*influxdb.ExpectedReporter, error) {
+				return expectedReporterFixtures(), nil
+			}
+			s := authorizer.NewExpectedReporterService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			reporters, err := s.FindExpectedReporters(ctx, influxdb.ExpectedReporterFilter{})
+			if err != nil {
+				t.Fatalf("failed to find expected reporters: %v", err)
+			}
+
+			if diff := cmp.Diff(reporters, tt.reporters); diff != "" {
+				t.Errorf("expected reporters are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestExpectedReporterService_FindExpectedReporterByID(t *testing.T) {
+	m := mock.NewExpectedReporterService()
+	m.FindExpectedReporterByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.ExpectedReporter, error) {
+		return expectedReporterFixtures()[0], nil
+	}
+	s := authorizer.NewExpectedReporterService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(2),
+			},
+		},
+	}})
+
+	_, err := s.FindExpectedReporterByID(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
+
+func TestExpectedReporterService_CreateExpectedReporter(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+	}
+	type wants struct {
+		err error
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to create an expected reporter",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to create an expected reporter",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewExpectedReporterService(mock.NewExpectedReporterService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
+
+			err := s.CreateExpectedReporter(ctx, expectedReporterFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
+
+func TestExpectedReporterService_DeleteExpectedReporter(t *testing.T) {
+	m := mock.NewExpectedReporterService()
+	m.FindExpectedReporterByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.ExpectedReporter, error) {
+		return expectedReporterFixtures()[0], nil
+	}
+	s := authorizer.NewExpectedReporterService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	err := s.DeleteExpectedReporter(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -0,0 +1,40 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.IndexCheckService = (*IndexCheckService)(nil)
+
+// IndexCheckService wraps a influxdb.IndexCheckService and authorizes actions
+// against it appropriately.
+type IndexCheckService struct {
+	s influxdb.IndexCheckService
+}
+
+// NewIndexCheckService constructs an instance of an authorizing index check service.
+func NewIndexCheckService(s influxdb.IndexCheckService) *IndexCheckService {
+	return &IndexCheckService{
+		s: s,
+	}
+}
+
+// CheckIndex checks to see if the authorizer on context has write access to ops.
+func (s *IndexCheckService) CheckIndex(ctx context.Context, opts influxdb.IndexCheckOptions) (*influxdb.IndexCheck, error) {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.CheckIndex(ctx, opts)
+}
+
+// FindIndexCheck checks to see if the authorizer on context has read access to ops.
+func (s *IndexCheckService) FindIndexCheck(ctx context.Context) (*influxdb.IndexCheck, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindIndexCheck(ctx)
+}
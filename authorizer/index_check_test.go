@@ -0,0 +1,86 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestIndexCheckService_FindIndexCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to view the index check",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+		},
+		{
+			name: "unauthorized to view the index check",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewIndexCheckService(mock.NewIndexCheckService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			_, err := s.FindIndexCheck(ctx)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestIndexCheckService_CheckIndex(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to check the index",
+			permission: influxdb.Permission{
+				Action:   "write",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+		},
+		{
+			name: "unauthorized to check the index",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewIndexCheckService(mock.NewIndexCheckService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			_, err := s.CheckIndex(ctx, influxdb.IndexCheckOptions{Repair: true})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
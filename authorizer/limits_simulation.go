@@ -0,0 +1,31 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.LimitsSimulationService = (*LimitsSimulationService)(nil)
+
+// LimitsSimulationService wraps a influxdb.LimitsSimulationService and authorizes actions
+// against it appropriately.
+type LimitsSimulationService struct {
+	s influxdb.LimitsSimulationService
+}
+
+// NewLimitsSimulationService constructs an instance of an authorizing limits simulation service.
+func NewLimitsSimulationService(s influxdb.LimitsSimulationService) *LimitsSimulationService {
+	return &LimitsSimulationService{
+		s: s,
+	}
+}
+
+// SimulateLimits checks to see if the authorizer on context has read access to ops.
+func (s *LimitsSimulationService) SimulateLimits(ctx context.Context, limits influxdb.LimitsProposal) (*influxdb.LimitsSimulation, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.SimulateLimits(ctx, limits)
+}
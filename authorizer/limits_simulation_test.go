@@ -0,0 +1,49 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestLimitsSimulationService_SimulateLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to simulate limits",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OpsResourceType},
+			},
+		},
+		{
+			name: "unauthorized to simulate limits",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:ops is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewLimitsSimulationService(mock.NewLimitsSimulationService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			_, err := s.SimulateLimits(ctx, influxdb.LimitsProposal{QueryOrgConcurrency: 2})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.LineageService = (*LineageService)(nil)
+
+// LineageService wraps a influxdb.LineageService and authorizes actions
+// against it appropriately.
+// Lineage records belong to the task of their run, so reading them requires read access to the task
+// and recording them requires write access to the task.
+// The lineage of a bucket requires read access to the bucket, and only shows the edges between readable buckets.
+type LineageService struct {
+	s influxdb.LineageService
+}
+
+// NewLineageService constructs an instance of an authorizing lineage service.
+func NewLineageService(s influxdb.LineageService) *LineageService {
+	return &LineageService{
+		s: s,
+	}
+}
+
+// AddLineageRecord checks to see if the authorizer on context has write access to the task of the record.
+func (s *LineageService) AddLineageRecord(ctx context.Context, r *influxdb.LineageRecord) error {
+	if err := authorizeTask(ctx, influxdb.WriteAction, r.OrgID, r.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.AddLineageRecord(ctx, r)
+}
+
+// FindLineageRecords retrieves all lineage records that match the provided filter
+// and then filters the list down to only the records of tasks that are authorized.
+func (s *LineageService) FindLineageRecords(ctx context.Context, filter influxdb.LineageRecordFilter) ([]*influxdb.LineageRecord, error) {
+	rs, err := s.s.FindLineageRecords(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	records := rs[:0]
+	for _, r := range rs {
+		err := authorizeTask(ctx, influxdb.ReadAction, r.OrgID, r.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// FindLineage checks to see if the authorizer on context has read access to the bucket
+// and then filters the edges down to only the ones between buckets that are authorized.
+func (s *LineageService) FindLineage(ctx context.Context, filter influxdb.LineageFilter) (*influxdb.Lineage, error) {
+	if err := authorizeReadBucket(ctx, filter.OrgID, filter.BucketID); err != nil {
+		return nil, err
+	}
+
+	l, err := s.s.FindLineage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := l.Edges[:0]
+	for _, e := range l.Edges {
+		err := authorizeReadBucket(ctx, filter.OrgID, e.Source)
+		if err == nil {
+			err = authorizeReadBucket(ctx, filter.OrgID, e.Destination)
+		}
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		edges = append(edges, e)
+	}
+	l.Edges = edges
+
+	return l, nil
+}
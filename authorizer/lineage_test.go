@@ -0,0 +1,168 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func lineageRecordFixtures() []*influxdb.LineageRecord {
+	return []*influxdb.LineageRecord{
+		{
+			ID:          1,
+			OrgID:       10,
+			TaskID:      1,
+			RunID:       100,
+			Sources:     []influxdb.ID{1},
+			Destination: 2,
+		},
+		{
+			ID:          2,
+			OrgID:       10,
+			TaskID:      2,
+			RunID:       200,
+			Sources:     []influxdb.ID{2},
+			Destination: 3,
+		},
+	}
+}
+
+func TestLineageService_FindLineageRecords(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		records    []*influxdb.LineageRecord
+	}{
+		{
+			name: "authorized to see all lineage records",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+				},
+			},
+			records: lineageRecordFixtures(),
+		},
+		{
+			name: "authorized to see the lineage records of one task",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			records: lineageRecordFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewLineageService()
+			m.FindLineageRecordsFn = func(ctx context.Context, filter influxdb.LineageRecordFilter) ([]*influxdb.LineageRecord, error) {
+				return lineageRecordFixtures(), nil
+			}
+			s := authorizer.NewLineageService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			records, err := s.FindLineageRecords(ctx, influxdb.LineageRecordFilter{})
+			if err != nil {
+				t.Fatalf("failed to find lineage records: %v", err)
+			}
+			if diff := cmp.Diff(records, tt.records); diff != "" {
+				t.Errorf("lineage records are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestLineageService_AddLineageRecord(t *testing.T) {
+	s := authorizer.NewLineageService(mock.NewLineageService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.TasksResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	err := s.AddLineageRecord(ctx, lineageRecordFixtures()[0])
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
+
+func TestLineageService_FindLineage(t *testing.T) {
+	lineage := func() *influxdb.Lineage {
+		return &influxdb.Lineage{
+			BucketID:  1,
+			Direction: influxdb.LineageDownstream,
+			Edges: []influxdb.LineageEdge{
+				{TaskID: 1, Source: 1, Destination: 2, Runs: 1, LastRunID: 100},
+				{TaskID: 2, Source: 2, Destination: 3, Runs: 1, LastRunID: 200},
+			},
+		}
+	}
+	readBucket := func(id influxdb.ID) influxdb.Permission {
+		return influxdb.Permission{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   &id,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantEdges   []influxdb.LineageEdge
+		wantErr     error
+	}{
+		{
+			name:        "authorized to see the edges between readable buckets",
+			permissions: []influxdb.Permission{readBucket(1), readBucket(2)},
+			wantEdges:   lineage().Edges[:1],
+		},
+		{
+			name:        "unauthorized to see the lineage of a bucket",
+			permissions: []influxdb.Permission{readBucket(2)},
+			wantErr: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewLineageService()
+			m.FindLineageFn = func(ctx context.Context, filter influxdb.LineageFilter) (*influxdb.Lineage, error) {
+				return lineage(), nil
+			}
+			s := authorizer.NewLineageService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			l, err := s.FindLineage(ctx, influxdb.LineageFilter{OrgID: 10, BucketID: 1, Direction: influxdb.LineageDownstream})
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(l.Edges, tt.wantEdges); diff != "" {
+				t.Errorf("lineage edges are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MeasurementSchemaService = (*MeasurementSchemaService)(nil)
+
+// MeasurementSchemaService wraps a influxdb.MeasurementSchemaService and authorizes actions
+// against it appropriately.
+// Measurement schemas belong to their bucket, so reading them requires read access to the bucket
+// and managing them requires write access to the bucket.
+type MeasurementSchemaService struct {
+	s influxdb.MeasurementSchemaService
+}
+
+// NewMeasurementSchemaService constructs an instance of an authorizing measurement schema service.
+func NewMeasurementSchemaService(s influxdb.MeasurementSchemaService) *MeasurementSchemaService {
+	return &MeasurementSchemaService{
+		s: s,
+	}
+}
+
+// FindMeasurementSchemaByID checks to see if the authorizer on context has read access to the bucket of the measurement schema.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// FindMeasurementSchemas retrieves all measurement schemas that match the provided filter
+// and then filters the list down to only the schemas of buckets that are authorized.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
+	// will likely be expensive.
+	ss, err := s.s.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	schemas := ss[:0]
+	for _, ms := range ss {
+		err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		schemas = append(schemas, ms)
+	}
+
+	return schemas, nil
+}
+
+// CreateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the measurement schema.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.CreateMeasurementSchema(ctx, ms)
+}
+
+// UpdateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the measurement schema.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateMeasurementSchema(ctx, id, upd)
+}
+
+// DeleteMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the measurement schema.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteMeasurementSchema(ctx, id)
+}
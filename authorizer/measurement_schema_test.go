@@ -0,0 +1,107 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func measurementSchemaFixtures() []*influxdb.MeasurementSchema {
+	return []*influxdb.MeasurementSchema{
+		{
+			ID:       1,
+			OrgID:    10,
+			BucketID: 1,
+			Name:     "cpu",
+			Fields:   []influxdb.MeasurementSchemaField{{Name: "usage", Type: influxdb.SchemaFieldTypeFloat}},
+		},
+		{
+			ID:       2,
+			OrgID:    10,
+			BucketID: 2,
+			Name:     "cpu",
+			Fields:   []influxdb.MeasurementSchemaField{{Name: "usage", Type: influxdb.SchemaFieldTypeFloat}},
+		},
+	}
+}
+
+func TestMeasurementSchemaService_FindMeasurementSchemas(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		schemas    []*influxdb.MeasurementSchema
+	}{
+		{
+			name: "authorized to see all measurement schemas",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+				},
+			},
+			schemas: measurementSchemaFixtures(),
+		},
+		{
+			name: "authorized to see the measurement schemas of one bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			schemas: measurementSchemaFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewMeasurementSchemaService()
+			m.FindMeasurementSchemasFn = func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+				return measurementSchemaFixtures(), nil
+			}
+			s := authorizer.NewMeasurementSchemaService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			schemas, err := s.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{})
+			if err != nil {
+				t.Fatalf("failed to find measurement schemas: %v", err)
+			}
+
+			if diff := cmp.Diff(schemas, tt.schemas); diff != "" {
+				t.Errorf("measurement schemas are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestMeasurementSchemaService_UpdateMeasurementSchema(t *testing.T) {
+	m := mock.NewMeasurementSchemaService()
+	m.FindMeasurementSchemaByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+		return measurementSchemaFixtures()[0], nil
+	}
+	s := authorizer.NewMeasurementSchemaService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	_, err := s.UpdateMeasurementSchema(ctx, 1, influxdb.MeasurementSchemaUpdate{})
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
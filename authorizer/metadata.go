@@ -0,0 +1,90 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MetadataService = (*MetadataService)(nil)
+
+// MetadataService wraps a influxdb.MetadataService and authorizes actions
+// against it appropriately.
+type MetadataService struct {
+	s influxdb.MetadataService
+}
+
+// NewMetadataService constructs an instance of an authorizing metadata service.
+func NewMetadataService(s influxdb.MetadataService) *MetadataService {
+	return &MetadataService{
+		s: s,
+	}
+}
+
+func authorizeMetadataAction(ctx context.Context, action influxdb.Action, m *influxdb.Metadata) error {
+	p, err := newResourcePermission(action, m.ResourceID, m.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindMetadata retrieves all metadata that match the provided filter and then filters the list down to
+// the metadata of resources the authorizer on context has read access to.
+func (s *MetadataService) FindMetadata(ctx context.Context, filter influxdb.MetadataFilter) ([]*influxdb.Metadata, error) {
+	ms, err := s.s.FindMetadata(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	metadata := ms[:0]
+	for _, m := range ms {
+		err := authorizeMetadataAction(ctx, influxdb.ReadAction, m)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		metadata = append(metadata, m)
+	}
+
+	return metadata, nil
+}
+
+// SetMetadata checks to see if the authorizer on context has write access to the resource the metadata is set on.
+func (s *MetadataService) SetMetadata(ctx context.Context, m *influxdb.Metadata) error {
+	if err := authorizeMetadataAction(ctx, influxdb.WriteAction, m); err != nil {
+		return err
+	}
+
+	return s.s.SetMetadata(ctx, m)
+}
+
+// DeleteMetadata checks to see if the authorizer on context has write access to the resource the metadata is removed from.
+func (s *MetadataService) DeleteMetadata(ctx context.Context, resourceID influxdb.ID, key string) error {
+	ms, err := s.s.FindMetadata(ctx, influxdb.MetadataFilter{
+		ResourceID: &resourceID,
+		Key:        key,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if err := authorizeMetadataAction(ctx, influxdb.WriteAction, m); err != nil {
+			return err
+		}
+	}
+
+	return s.s.DeleteMetadata(ctx, resourceID, key)
+}
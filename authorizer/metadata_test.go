@@ -0,0 +1,235 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func metadataFixtures() []*influxdb.Metadata {
+	return []*influxdb.Metadata{
+		{
+			ResourceID:   1,
+			ResourceType: influxdb.BucketsResourceType,
+			Key:          "tier",
+			Type:         influxdb.MetadataNumber,
+			Value:        float64(1),
+		},
+		{
+			ResourceID:   2,
+			ResourceType: influxdb.BucketsResourceType,
+			Key:          "tier",
+			Type:         influxdb.MetadataNumber,
+			Value:        float64(2),
+		},
+	}
+}
+
+func TestMetadataService_FindMetadata(t *testing.T) {
+	type args struct {
+		permissions []influxdb.Permission
+	}
+	type wants struct {
+		err      error
+		metadata []*influxdb.Metadata
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to see the metadata of all buckets",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				metadata: metadataFixtures(),
+			},
+		},
+		{
+			name: "authorized to see the metadata of a single bucket",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(2),
+						},
+					},
+				},
+			},
+			wants: wants{
+				metadata: metadataFixtures()[1:],
+			},
+		},
+		{
+			name: "unable to see metadata without read permission on the resources",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.DashboardsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				metadata: []*influxdb.Metadata{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewMetadataService()
+			m.FindMetadataFn = func(ctx context.Context, filter influxdb.MetadataFilter) ([]*influxdb.Metadata, error) {
+				return metadataFixtures(), nil
+			}
+			s := authorizer.NewMetadataService(m)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.args.permissions})
+
+			metadata, err := s.FindMetadata(ctx, influxdb.MetadataFilter{})
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+
+			if diff := cmp.Diff(metadata, tt.wants.metadata); diff != "" {
+				t.Errorf("metadata are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestMetadataService_SetMetadata(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+	}
+	type wants struct {
+		err error
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to set metadata on a bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to set metadata on a bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:buckets/0000000000000001 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewMetadataService(mock.NewMetadataService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
+
+			err := s.SetMetadata(ctx, metadataFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
+
+func TestMetadataService_DeleteMetadata(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+	}
+	type wants struct {
+		err error
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to delete metadata from a bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to delete metadata from a bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:buckets/0000000000000001 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewMetadataService()
+			m.FindMetadataFn = func(ctx context.Context, filter influxdb.MetadataFilter) ([]*influxdb.Metadata, error) {
+				return metadataFixtures()[:1], nil
+			}
+			s := authorizer.NewMetadataService(m)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
+
+			err := s.DeleteMetadata(ctx, 1, "tier")
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationService wraps a influxdb.ReplicationService and authorizes actions
+// against it appropriately.
+type ReplicationService struct {
+	s influxdb.ReplicationService
+}
+
+// NewReplicationService constructs an instance of an authorizing replication service.
+func NewReplicationService(s influxdb.ReplicationService) *ReplicationService {
+	return &ReplicationService{
+		s: s,
+	}
+}
+
+// VerifyReplication checks to see if the authorizer on context has read access to both buckets of the request.
+func (s *ReplicationService) VerifyReplication(ctx context.Context, req influxdb.ReplicationRequest) (*influxdb.ReplicationReport, error) {
+	if err := authorizeReadBucket(ctx, req.OrgID, req.BucketID); err != nil {
+		return nil, err
+	}
+	if err := authorizeReadBucket(ctx, req.TargetOrgID, req.TargetBucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.VerifyReplication(ctx, req)
+}
@@ -0,0 +1,70 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestReplicationService_VerifyReplication(t *testing.T) {
+	read := func(id influxdb.ID) influxdb.Permission {
+		return influxdb.Permission{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   &id,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to read both buckets",
+			permissions: []influxdb.Permission{read(1), read(2)},
+		},
+		{
+			name:        "unauthorized to read the source bucket",
+			permissions: []influxdb.Permission{read(2)},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to read the target bucket",
+			permissions: []influxdb.Permission{read(1)},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000b/buckets/0000000000000002 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewReplicationService(mock.NewReplicationService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			_, err := s.VerifyReplication(ctx, influxdb.ReplicationRequest{
+				OrgID:          10,
+				BucketID:       1,
+				TargetOrgID:    11,
+				TargetBucketID: 2,
+				Start:          time.Unix(0, 0),
+				Stop:           time.Unix(3600, 0),
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// RunningQueryService wraps a influxdb.RunningQueryService and authorizes actions
+// against it appropriately.
+type RunningQueryService struct {
+	s influxdb.RunningQueryService
+}
+
+// NewRunningQueryService constructs an instance of an authorizing running query service.
+func NewRunningQueryService(s influxdb.RunningQueryService) *RunningQueryService {
+	return &RunningQueryService{
+		s: s,
+	}
+}
+
+// FindRunningQueries retrieves all running queries that match the provided filter and then filters the list down to only the queries
+// of the organizations that are authorized to be read.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	qs, err := s.s.FindRunningQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	queries := qs[:0]
+	for _, q := range qs {
+		err := authorizeReadOrg(ctx, q.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		queries = append(queries, q)
+	}
+
+	return queries, nil
+}
+
+// FindRunningQueryByID checks to see if the authorizer on context has read access to the organization of the query.
+func (s *RunningQueryService) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	q, err := s.s.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, q.OrgID); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// CancelRunningQuery checks to see if the authorizer on context has write access to the organization of the query.
+func (s *RunningQueryService) CancelRunningQuery(ctx context.Context, id influxdb.ID) error {
+	q, err := s.s.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, q.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CancelRunningQuery(ctx, id)
+}
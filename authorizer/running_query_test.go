@@ -0,0 +1,87 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func newRunningQueryService() *mock.RunningQueryService {
+	queries := []*influxdb.RunningQuery{
+		{ID: 1, OrgID: 10},
+		{ID: 2, OrgID: 20},
+	}
+	s := mock.NewRunningQueryService()
+	s.FindRunningQueriesFn = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		return append([]*influxdb.RunningQuery(nil), queries...), nil
+	}
+	s.FindRunningQueryByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+		for _, q := range queries {
+			if q.ID == id {
+				return q, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrRunningQueryNotFound}
+	}
+	return s
+}
+
+func TestRunningQueryService_FindRunningQueries(t *testing.T) {
+	s := authorizer.NewRunningQueryService(newRunningQueryService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action:   "read",
+			Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: influxdbtesting.IDPtr(20)},
+		},
+	}})
+	qs, err := s.FindRunningQueries(ctx, influxdb.RunningQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 || qs[0].ID != 2 {
+		t.Errorf("got running queries %+v, want only the query of the organization readable", qs)
+	}
+}
+
+func TestRunningQueryService_CancelRunningQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to cancel the queries of the organization",
+			permission: influxdb.Permission{
+				Action:   "write",
+				Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: influxdbtesting.IDPtr(10)},
+			},
+		},
+		{
+			name: "unauthorized to cancel the queries of the organization",
+			permission: influxdb.Permission{
+				Action:   "read",
+				Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: influxdbtesting.IDPtr(10)},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRunningQueryService(newRunningQueryService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+			err := s.CancelRunningQuery(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
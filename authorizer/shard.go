@@ -0,0 +1,74 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ShardService = (*ShardService)(nil)
+
+// ShardService wraps a influxdb.ShardService and authorizes actions
+// against it appropriately.
+type ShardService struct {
+	s influxdb.ShardService
+}
+
+// NewShardService constructs an instance of an authorizing shard service.
+func NewShardService(s influxdb.ShardService) *ShardService {
+	return &ShardService{
+		s: s,
+	}
+}
+
+// FindShards checks to see if the authorizer on context has read access to the bucket of the filter.
+func (s *ShardService) FindShards(ctx context.Context, filter influxdb.ShardFilter) ([]*influxdb.Shard, error) {
+	if err := authorizeReadBucket(ctx, filter.OrgID, filter.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindShards(ctx, filter)
+}
+
+// DeleteShard checks to see if the authorizer on context has write access to the bucket.
+func (s *ShardService) DeleteShard(ctx context.Context, orgID, bucketID influxdb.ID, id string) error {
+	if err := authorizeWriteBucket(ctx, orgID, bucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteShard(ctx, orgID, bucketID, id)
+}
+
+// BackupShard checks to see if the authorizer on context has read access to the bucket and
+// to ops, as the archive holds the points of the other buckets in the shard and the series of every bucket.
+func (s *ShardService) BackupShard(ctx context.Context, orgID, bucketID influxdb.ID, id string, w io.Writer) error {
+	if err := authorizeReadBucket(ctx, orgID, bucketID); err != nil {
+		return err
+	}
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return err
+	}
+
+	return s.s.BackupShard(ctx, orgID, bucketID, id, w)
+}
+
+// CompactShards checks to see if the authorizer on context has write access to ops,
+// as a compaction rewrites the shards of every bucket.
+func (s *ShardService) CompactShards(ctx context.Context) error {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.CompactShards(ctx)
+}
+
+// TopShards checks to see if the authorizer on context has read access to ops,
+// as the activity of the shards is that of every bucket.
+func (s *ShardService) TopShards(ctx context.Context, opts influxdb.TopShardsOptions) ([]*influxdb.ShardActivity, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.TopShards(ctx, opts)
+}
@@ -0,0 +1,104 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SQLConnectionService = (*SQLConnectionService)(nil)
+
+// SQLConnectionService wraps a influxdb.SQLConnectionService and authorizes actions
+// against it appropriately.
+// The data source name of a SQL connection is a secret of its organization, so reading SQL connections
+// requires read access to the secrets of the organization and managing them requires write access.
+type SQLConnectionService struct {
+	s influxdb.SQLConnectionService
+}
+
+// NewSQLConnectionService constructs an instance of an authorizing SQL connection service.
+func NewSQLConnectionService(s influxdb.SQLConnectionService) *SQLConnectionService {
+	return &SQLConnectionService{
+		s: s,
+	}
+}
+
+// FindSQLConnectionByID checks to see if the authorizer on context has read access to the secrets of the organization of the SQL connection.
+func (s *SQLConnectionService) FindSQLConnectionByID(ctx context.Context, id influxdb.ID) (*influxdb.SQLConnection, error) {
+	c, err := s.s.FindSQLConnectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadSecret(ctx, c.OrgID); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// FindSQLConnections retrieves all SQL connections that match the provided filter
+// and then filters the list down to only the connections of organizations whose secrets are authorized.
+func (s *SQLConnectionService) FindSQLConnections(ctx context.Context, filter influxdb.SQLConnectionFilter) ([]*influxdb.SQLConnection, error) {
+	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
+	// will likely be expensive.
+	cs, err := s.s.FindSQLConnections(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	connections := cs[:0]
+	for _, c := range cs {
+		err := authorizeReadSecret(ctx, c.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		connections = append(connections, c)
+	}
+
+	return connections, nil
+}
+
+// CreateSQLConnection checks to see if the authorizer on context has write access to the secrets of the organization of the SQL connection.
+func (s *SQLConnectionService) CreateSQLConnection(ctx context.Context, c *influxdb.SQLConnection) error {
+	if err := authorizeWriteSecret(ctx, c.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateSQLConnection(ctx, c)
+}
+
+// UpdateSQLConnection checks to see if the authorizer on context has write access to the secrets of the organization of the SQL connection.
+func (s *SQLConnectionService) UpdateSQLConnection(ctx context.Context, id influxdb.ID, upd influxdb.SQLConnectionUpdate) (*influxdb.SQLConnection, error) {
+	c, err := s.s.FindSQLConnectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteSecret(ctx, c.OrgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateSQLConnection(ctx, id, upd)
+}
+
+// DeleteSQLConnection checks to see if the authorizer on context has write access to the secrets of the organization of the SQL connection.
+func (s *SQLConnectionService) DeleteSQLConnection(ctx context.Context, id influxdb.ID) error {
+	c, err := s.s.FindSQLConnectionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteSecret(ctx, c.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteSQLConnection(ctx, id)
+}
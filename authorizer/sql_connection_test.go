@@ -0,0 +1,150 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func sqlConnectionFixtures() []*influxdb.SQLConnection {
+	return []*influxdb.SQLConnection{
+		{
+			ID:         1,
+			OrgID:      10,
+			Name:       "metrics",
+			DriverName: "postgres",
+			SecretKey:  "metrics-dsn",
+		},
+		{
+			ID:         2,
+			OrgID:      11,
+			Name:       "inventory",
+			DriverName: "mysql",
+			SecretKey:  "inventory-dsn",
+		},
+	}
+}
+
+func TestSQLConnectionService_FindSQLConnections(t *testing.T) {
+	tests := []struct {
+		name        string
+		permission  influxdb.Permission
+		connections []*influxdb.SQLConnection
+	}{
+		{
+			name: "authorized to see all sql connections",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.SecretsResourceType,
+				},
+			},
+			connections: sqlConnectionFixtures(),
+		},
+		{
+			name: "authorized to see the sql connections of one org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.SecretsResourceType,
+					OrgID: influxdbtesting.IDPtr(11),
+				},
+			},
+			connections: sqlConnectionFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewSQLConnectionService()
+			m.FindSQLConnectionsFn = func(ctx context.Context, filter influxdb.SQLConnectionFilter) ([]*influxdb.SQLConnection, error) {
+				return sqlConnectionFixtures(), nil
+			}
+			s := authorizer.NewSQLConnectionService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			connections, err := s.FindSQLConnections(ctx, influxdb.SQLConnectionFilter{})
+			if err != nil {
+				t.Fatalf("failed to find sql connections: %v", err)
+			}
+			if diff := cmp.Diff(connections, tt.connections); diff != "" {
+				t.Errorf("sql connections are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestSQLConnectionService_CreateSQLConnection(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to create a sql connection",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.SecretsResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to create a sql connection",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.SecretsResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/secrets is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewSQLConnectionService(mock.NewSQLConnectionService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.CreateSQLConnection(ctx, sqlConnectionFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSQLConnectionService_DeleteSQLConnection(t *testing.T) {
+	m := mock.NewSQLConnectionService()
+	m.FindSQLConnectionByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.SQLConnection, error) {
+		return sqlConnectionFixtures()[0], nil
+	}
+	s := authorizer.NewSQLConnectionService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.SecretsResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+			},
+		},
+	}})
+
+	err := s.DeleteSQLConnection(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/secrets is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -0,0 +1,53 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SubsystemService = (*SubsystemService)(nil)
+
+// SubsystemService wraps a influxdb.SubsystemService and authorizes actions
+// against it appropriately.
+type SubsystemService struct {
+	s influxdb.SubsystemService
+}
+
+// NewSubsystemService constructs an instance of an authorizing subsystem service.
+func NewSubsystemService(s influxdb.SubsystemService) *SubsystemService {
+	return &SubsystemService{
+		s: s,
+	}
+}
+
+func authorizeOpsAction(ctx context.Context, action influxdb.Action) error {
+	p, err := influxdb.NewGlobalPermission(action, influxdb.OpsResourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindSubsystems checks to see if the authorizer on context has read access to ops.
+func (s *SubsystemService) FindSubsystems(ctx context.Context) ([]*influxdb.SubsystemStatus, error) {
+	if err := authorizeOpsAction(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindSubsystems(ctx)
+}
+
+// RestartSubsystem checks to see if the authorizer on context has write access to ops.
+func (s *SubsystemService) RestartSubsystem(ctx context.Context, name string) (*influxdb.SubsystemStatus, error) {
+	if err := authorizeOpsAction(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.RestartSubsystem(ctx, name)
+}
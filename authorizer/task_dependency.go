@@ -0,0 +1,74 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskDependencyService = (*TaskDependencyService)(nil)
+
+// TaskDependencyService wraps a influxdb.TaskDependencyService and authorizes actions
+// against it appropriately.
+// The dependencies of a task are read with read access to the task and recorded with write access to it.
+type TaskDependencyService struct {
+	s influxdb.TaskDependencyService
+}
+
+// NewTaskDependencyService constructs an instance of an authorizing task dependency service.
+func NewTaskDependencyService(s influxdb.TaskDependencyService) *TaskDependencyService {
+	return &TaskDependencyService{
+		s: s,
+	}
+}
+
+// FindTaskDependencies retrieves the dependencies of the tasks that match the provided filter
+// and then filters the list down to only the tasks that are authorized.
+func (s *TaskDependencyService) FindTaskDependencies(ctx context.Context, filter influxdb.TaskDependencyFilter) ([]*influxdb.TaskDependencies, error) {
+	tds, err := s.s.FindTaskDependencies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	ds := tds[:0]
+	for _, d := range tds {
+		err := authorizeTask(ctx, influxdb.ReadAction, d.OrgID, d.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+// PutTaskDependencies checks to see if the authorizer on context has write access to the task.
+func (s *TaskDependencyService) PutTaskDependencies(ctx context.Context, d *influxdb.TaskDependencies) error {
+	if err := authorizeTask(ctx, influxdb.WriteAction, d.OrgID, d.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.PutTaskDependencies(ctx, d)
+}
+
+// DeleteTaskDependencies checks to see if the authorizer on context has write access to the task.
+func (s *TaskDependencyService) DeleteTaskDependencies(ctx context.Context, taskID influxdb.ID) error {
+	tds, err := s.s.FindTaskDependencies(ctx, influxdb.TaskDependencyFilter{TaskID: &taskID})
+	if err != nil {
+		return err
+	}
+	for _, d := range tds {
+		if err := authorizeTask(ctx, influxdb.WriteAction, d.OrgID, d.TaskID); err != nil {
+			return err
+		}
+	}
+
+	return s.s.DeleteTaskDependencies(ctx, taskID)
+}
@@ -0,0 +1,137 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func taskDependencyFixtures() []*influxdb.TaskDependencies {
+	return []*influxdb.TaskDependencies{
+		{
+			TaskID: 1,
+			OrgID:  10,
+			Dependencies: []influxdb.TaskDependency{
+				{Type: influxdb.TaskDependencyBucket, Name: "telemetry"},
+			},
+		},
+		{
+			TaskID: 2,
+			OrgID:  11,
+			Dependencies: []influxdb.TaskDependency{
+				{Type: influxdb.TaskDependencyBucket, Name: "telemetry"},
+			},
+		},
+	}
+}
+
+func TestTaskDependencyService_FindTaskDependencies(t *testing.T) {
+	tests := []struct {
+		name         string
+		permission   influxdb.Permission
+		dependencies []*influxdb.TaskDependencies
+	}{
+		{
+			name: "authorized to see all tasks",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+				},
+			},
+			dependencies: taskDependencyFixtures(),
+		},
+		{
+			name: "authorized to see the tasks of one org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(11),
+				},
+			},
+			dependencies: taskDependencyFixtures()[1:],
+		},
+		{
+			name: "authorized to see one task",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			dependencies: taskDependencyFixtures()[:1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewTaskDependencyService()
+			m.FindTaskDependenciesFn = func(ctx context.Context, filter influxdb.TaskDependencyFilter) ([]*influxdb.TaskDependencies, error) {
+				return taskDependencyFixtures(), nil
+			}
+			s := authorizer.NewTaskDependencyService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			ds, err := s.FindTaskDependencies(ctx, influxdb.TaskDependencyFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task dependencies: %v", err)
+			}
+			if diff := cmp.Diff(ds, tt.dependencies); diff != "" {
+				t.Errorf("task dependencies are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestTaskDependencyService_PutTaskDependencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to record the dependencies of a task",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to record the dependencies of a task",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewTaskDependencyService(mock.NewTaskDependencyService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.PutTaskDependencies(ctx, taskDependencyFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskScriptService = (*TaskScriptService)(nil)
+
+// TaskScriptService wraps a influxdb.TaskScriptService and authorizes actions
+// against it appropriately.
+// Task scripts are shared by the tasks of their organization, so reading them requires read access to the tasks
+// of the organization and managing them requires write access to the tasks of the organization.
+type TaskScriptService struct {
+	s influxdb.TaskScriptService
+}
+
+// NewTaskScriptService constructs an instance of an authorizing task script service.
+func NewTaskScriptService(s influxdb.TaskScriptService) *TaskScriptService {
+	return &TaskScriptService{
+		s: s,
+	}
+}
+
+func authorizeOrgTasks(ctx context.Context, a influxdb.Action, orgID influxdb.ID) error {
+	p, err := influxdb.NewPermission(a, influxdb.TasksResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	return IsAllowed(ctx, *p)
+}
+
+// FindTaskScript checks to see if the authorizer on context has read access to the tasks of the organization.
+func (s *TaskScriptService) FindTaskScript(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+	if err := authorizeOrgTasks(ctx, influxdb.ReadAction, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindTaskScript(ctx, orgID, name, version)
+}
+
+// FindTaskScripts retrieves all task scripts that match the provided filter
+// and then filters the list down to only the scripts of organizations whose tasks are authorized.
+func (s *TaskScriptService) FindTaskScripts(ctx context.Context, filter influxdb.TaskScriptFilter) ([]*influxdb.TaskScript, error) {
+	tss, err := s.s.FindTaskScripts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	scripts := tss[:0]
+	for _, ts := range tss {
+		err := authorizeOrgTasks(ctx, influxdb.ReadAction, ts.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		scripts = append(scripts, ts)
+	}
+
+	return scripts, nil
+}
+
+// FindTaskScriptVersions checks to see if the authorizer on context has read access to the tasks of the organization.
+func (s *TaskScriptService) FindTaskScriptVersions(ctx context.Context, orgID influxdb.ID, name string) ([]*influxdb.TaskScript, error) {
+	if err := authorizeOrgTasks(ctx, influxdb.ReadAction, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindTaskScriptVersions(ctx, orgID, name)
+}
+
+// CreateTaskScript checks to see if the authorizer on context has write access to the tasks of the organization.
+func (s *TaskScriptService) CreateTaskScript(ctx context.Context, ts *influxdb.TaskScript) error {
+	if err := authorizeOrgTasks(ctx, influxdb.WriteAction, ts.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateTaskScript(ctx, ts)
+}
+
+// DeleteTaskScript checks to see if the authorizer on context has write access to the tasks of the organization.
+func (s *TaskScriptService) DeleteTaskScript(ctx context.Context, orgID influxdb.ID, name string) error {
+	if err := authorizeOrgTasks(ctx, influxdb.WriteAction, orgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteTaskScript(ctx, orgID, name)
+}
@@ -0,0 +1,150 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func taskScriptFixtures() []*influxdb.TaskScript {
+	return []*influxdb.TaskScript{
+		{
+			ID:      1,
+			OrgID:   10,
+			Name:    "helpers",
+			Version: 1,
+			Flux:    `x = 1`,
+		},
+		{
+			ID:      2,
+			OrgID:   11,
+			Name:    "helpers",
+			Version: 1,
+			Flux:    `x = 2`,
+		},
+	}
+}
+
+func TestTaskScriptService_FindTaskScripts(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		scripts    []*influxdb.TaskScript
+	}{
+		{
+			name: "authorized to see all task scripts",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+				},
+			},
+			scripts: taskScriptFixtures(),
+		},
+		{
+			name: "authorized to see the task scripts of one org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(11),
+				},
+			},
+			scripts: taskScriptFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewTaskScriptService()
+			m.FindTaskScriptsFn = func(ctx context.Context, filter influxdb.TaskScriptFilter) ([]*influxdb.TaskScript, error) {
+				return taskScriptFixtures(), nil
+			}
+			s := authorizer.NewTaskScriptService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			scripts, err := s.FindTaskScripts(ctx, influxdb.TaskScriptFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task scripts: %v", err)
+			}
+			if diff := cmp.Diff(scripts, tt.scripts); diff != "" {
+				t.Errorf("task scripts are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestTaskScriptService_FindTaskScript(t *testing.T) {
+	m := mock.NewTaskScriptService()
+	m.FindTaskScriptFn = func(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+		return taskScriptFixtures()[0], nil
+	}
+	s := authorizer.NewTaskScriptService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.TasksResourceType,
+				OrgID: influxdbtesting.IDPtr(11),
+			},
+		},
+	}})
+
+	_, err := s.FindTaskScript(ctx, 10, "helpers", 0)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/tasks is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
+
+func TestTaskScriptService_CreateTaskScript(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to create a task script",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to create a task script",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/tasks is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewTaskScriptService(mock.NewTaskScriptService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.CreateTaskScript(ctx, taskScriptFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskWebhookService = (*TaskWebhookService)(nil)
+
+// TaskWebhookService wraps a influxdb.TaskWebhookService and authorizes actions
+// against it appropriately.
+// Task webhooks belong to their task, so reading them and their delivery log requires read access to the task
+// and managing them requires write access to the task.
+type TaskWebhookService struct {
+	s influxdb.TaskWebhookService
+}
+
+// NewTaskWebhookService constructs an instance of an authorizing task webhook service.
+func NewTaskWebhookService(s influxdb.TaskWebhookService) *TaskWebhookService {
+	return &TaskWebhookService{
+		s: s,
+	}
+}
+
+func authorizeTask(ctx context.Context, a influxdb.Action, orgID, id influxdb.ID) error {
+	p, err := influxdb.NewPermissionAtID(id, a, influxdb.TasksResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	return IsAllowed(ctx, *p)
+}
+
+// FindTaskWebhookByID checks to see if the authorizer on context has read access to the task of the webhook.
+func (s *TaskWebhookService) FindTaskWebhookByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskWebhook, error) {
+	w, err := s.s.FindTaskWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.ReadAction, w.OrgID, w.TaskID); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// FindTaskWebhooks retrieves all task webhooks that match the provided filter
+// and then filters the list down to only the webhooks of tasks that are authorized.
+func (s *TaskWebhookService) FindTaskWebhooks(ctx context.Context, filter influxdb.TaskWebhookFilter) ([]*influxdb.TaskWebhook, error) {
+	ws, err := s.s.FindTaskWebhooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	webhooks := ws[:0]
+	for _, w := range ws {
+		err := authorizeTask(ctx, influxdb.ReadAction, w.OrgID, w.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, nil
+}
+
+// CreateTaskWebhook checks to see if the authorizer on context has write access to the task of the webhook.
+func (s *TaskWebhookService) CreateTaskWebhook(ctx context.Context, w *influxdb.TaskWebhook) error {
+	if err := authorizeTask(ctx, influxdb.WriteAction, w.OrgID, w.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.CreateTaskWebhook(ctx, w)
+}
+
+// UpdateTaskWebhook checks to see if the authorizer on context has write access to the task of the webhook.
+func (s *TaskWebhookService) UpdateTaskWebhook(ctx context.Context, id influxdb.ID, upd influxdb.TaskWebhookUpdate) (*influxdb.TaskWebhook, error) {
+	w, err := s.s.FindTaskWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, w.OrgID, w.TaskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateTaskWebhook(ctx, id, upd)
+}
+
+// DeleteTaskWebhook checks to see if the authorizer on context has write access to the task of the webhook.
+func (s *TaskWebhookService) DeleteTaskWebhook(ctx context.Context, id influxdb.ID) error {
+	w, err := s.s.FindTaskWebhookByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, w.OrgID, w.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteTaskWebhook(ctx, id)
+}
+
+// AddTaskWebhookDelivery checks to see if the authorizer on context has write access to the task of the webhook.
+func (s *TaskWebhookService) AddTaskWebhookDelivery(ctx context.Context, d *influxdb.TaskWebhookDelivery) error {
+	w, err := s.s.FindTaskWebhookByID(ctx, d.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, w.OrgID, w.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.AddTaskWebhookDelivery(ctx, d)
+}
+
+// FindTaskWebhookDeliveries checks to see if the authorizer on context has read access to the task of the webhook.
+func (s *TaskWebhookService) FindTaskWebhookDeliveries(ctx context.Context, webhookID influxdb.ID) ([]*influxdb.TaskWebhookDelivery, error) {
+	w, err := s.s.FindTaskWebhookByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.ReadAction, w.OrgID, w.TaskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindTaskWebhookDeliveries(ctx, webhookID)
+}
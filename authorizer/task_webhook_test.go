@@ -0,0 +1,150 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func taskWebhookFixtures() []*influxdb.TaskWebhook {
+	return []*influxdb.TaskWebhook{
+		{
+			ID:     1,
+			TaskID: 1,
+			OrgID:  10,
+			URL:    "https://example.com/hooks",
+			Events: []string{influxdb.TaskWebhookEventFailure},
+		},
+		{
+			ID:     2,
+			TaskID: 2,
+			OrgID:  10,
+			URL:    "https://example.com/hooks",
+			Events: []string{influxdb.TaskWebhookEventSuccess},
+		},
+	}
+}
+
+func TestTaskWebhookService_FindTaskWebhooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		webhooks   []*influxdb.TaskWebhook
+	}{
+		{
+			name: "authorized to see all task webhooks",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+				},
+			},
+			webhooks: taskWebhookFixtures(),
+		},
+		{
+			name: "authorized to see the webhooks of one task",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			webhooks: taskWebhookFixtures()[1:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewTaskWebhookService()
+			m.FindTaskWebhooksFn = func(ctx context.Context, filter influxdb.TaskWebhookFilter) ([]*influxdb.TaskWebhook, error) {
+				return taskWebhookFixtures(), nil
+			}
+			s := authorizer.NewTaskWebhookService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			webhooks, err := s.FindTaskWebhooks(ctx, influxdb.TaskWebhookFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task webhooks: %v", err)
+			}
+			if diff := cmp.Diff(webhooks, tt.webhooks); diff != "" {
+				t.Errorf("task webhooks are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestTaskWebhookService_CreateTaskWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to create a task webhook",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "unauthorized to create a task webhook",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewTaskWebhookService(mock.NewTaskWebhookService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.CreateTaskWebhook(ctx, taskWebhookFixtures()[0])
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTaskWebhookService_FindTaskWebhookDeliveries(t *testing.T) {
+	m := mock.NewTaskWebhookService()
+	m.FindTaskWebhookByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.TaskWebhook, error) {
+		return taskWebhookFixtures()[0], nil
+	}
+	s := authorizer.NewTaskWebhookService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.TasksResourceType,
+				ID:   influxdbtesting.IDPtr(2),
+			},
+		},
+	}})
+
+	_, err := s.FindTaskWebhookDeliveries(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 )
 
 var (
@@ -122,6 +123,10 @@ const (
 	// ViewsResourceType gives permission to one or more views.
 	ViewsResourceType     = ResourceType("views")     // 12
 	DocumentsResourceType = ResourceType("documents") // 13
+	// AnnouncementsResourceType gives permission to manage the announcements shown to every user.
+	AnnouncementsResourceType = ResourceType("announcements") // 14
+	// OpsResourceType gives permission to view and restart the subsystems of the instance, and to tune its compactions.
+	OpsResourceType = ResourceType("ops") // 15
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -140,6 +145,8 @@ var AllResourceTypes = []ResourceType{
 	LabelsResourceType,         // 11
 	ViewsResourceType,          // 12
 	DocumentsResourceType,      // 13
+	AnnouncementsResourceType,  // 14
+	OpsResourceType,            // 15
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -178,6 +185,8 @@ func (t ResourceType) Valid() (err error) {
 	case LabelsResourceType: // 11
 	case ViewsResourceType: // 12
 	case DocumentsResourceType: // 13
+	case AnnouncementsResourceType: // 14
+	case OpsResourceType: // 15
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -357,3 +366,60 @@ func MemberPermissions(orgID ID) []Permission {
 
 	return ps
 }
+
+// PermissionScope is the set of resources of a type on which an action is allowed.
+type PermissionScope struct {
+	// All is true if the action is allowed on every resource of the type, in every organization.
+	All bool `json:"all"`
+	// OrgIDs are the organizations in which the action is allowed on every resource of the type.
+	OrgIDs []ID `json:"orgIDs"`
+	// IDs are the individual resources on which the action is allowed.
+	IDs []ID `json:"ids"`
+}
+
+// PermissionMatrix is the expansion of a list of permissions into the scope of every action on every resource type.
+type PermissionMatrix map[ResourceType]map[Action]*PermissionScope
+
+// NewPermissionMatrix expands ps into a matrix holding a scope, possibly empty, for every known action and resource type.
+// Permissions of unknown actions or resource types are ignored.
+func NewPermissionMatrix(ps []Permission) PermissionMatrix {
+	m := make(PermissionMatrix, len(AllResourceTypes))
+	for _, rt := range AllResourceTypes {
+		m[rt] = make(map[Action]*PermissionScope, len(actions))
+		for _, a := range actions {
+			m[rt][a] = &PermissionScope{OrgIDs: []ID{}, IDs: []ID{}}
+		}
+	}
+
+	for _, p := range ps {
+		s, ok := m[p.Resource.Type][p.Action]
+		if !ok {
+			continue
+		}
+		switch {
+		case p.Resource.ID != nil:
+			s.IDs = appendUniqueID(s.IDs, *p.Resource.ID)
+		case p.Resource.OrgID != nil:
+			s.OrgIDs = appendUniqueID(s.OrgIDs, *p.Resource.OrgID)
+		default:
+			s.All = true
+		}
+	}
+
+	for _, scopes := range m {
+		for _, s := range scopes {
+			sort.Slice(s.OrgIDs, func(i, j int) bool { return s.OrgIDs[i] < s.OrgIDs[j] })
+			sort.Slice(s.IDs, func(i, j int) bool { return s.IDs[i] < s.IDs[j] })
+		}
+	}
+	return m
+}
+
+func appendUniqueID(ids []ID, id ID) []ID {
+	for _, i := range ids {
+		if i == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
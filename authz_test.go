@@ -1,6 +1,7 @@
 package influxdb_test
 
 import (
+	"reflect"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
@@ -435,3 +436,46 @@ func validID() *platform.ID {
 	id := platform.ID(100)
 	return &id
 }
+
+func TestNewPermissionMatrix(t *testing.T) {
+	ps := []platform.Permission{
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(2)}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)}},
+		{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1), ID: influxdbtesting.IDPtr(20)}},
+		{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.BucketsResourceType, ID: influxdbtesting.IDPtr(10)}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.UsersResourceType}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.ResourceType("unknown")}},
+	}
+
+	m := platform.NewPermissionMatrix(ps)
+
+	if len(m) != len(platform.AllResourceTypes) {
+		t.Fatalf("expected a row for each of the %d resource types, got %d", len(platform.AllResourceTypes), len(m))
+	}
+	if _, ok := m[platform.ResourceType("unknown")]; ok {
+		t.Error("unexpected row for unknown resource type")
+	}
+
+	want := map[platform.ResourceType]map[platform.Action]platform.PermissionScope{
+		platform.BucketsResourceType: {
+			platform.ReadAction:  {OrgIDs: []platform.ID{1, 2}, IDs: []platform.ID{}},
+			platform.WriteAction: {OrgIDs: []platform.ID{}, IDs: []platform.ID{10, 20}},
+		},
+		platform.UsersResourceType: {
+			platform.ReadAction:  {All: true, OrgIDs: []platform.ID{}, IDs: []platform.ID{}},
+			platform.WriteAction: {OrgIDs: []platform.ID{}, IDs: []platform.ID{}},
+		},
+		platform.TasksResourceType: {
+			platform.ReadAction:  {OrgIDs: []platform.ID{}, IDs: []platform.ID{}},
+			platform.WriteAction: {OrgIDs: []platform.ID{}, IDs: []platform.ID{}},
+		},
+	}
+	for rt, scopes := range want {
+		for a, s := range scopes {
+			if got := m[rt][a]; !reflect.DeepEqual(*got, s) {
+				t.Errorf("%s:%s = %+v, want %+v", a, rt, *got, s)
+			}
+		}
+	}
+}
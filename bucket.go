@@ -13,6 +13,10 @@ type BucketType int
 const (
 	// BucketTypeLogs defines the bucket ID of the system logs.
 	BucketTypeLogs = BucketType(iota + 10)
+	// BucketTypeSlowQueries defines the bucket ID of the slow query log.
+	BucketTypeSlowQueries
+	// BucketTypeAudit defines the bucket ID of the audit log.
+	BucketTypeAudit
 )
 
 // InfiniteRetention is default infinite retention period.
@@ -26,6 +30,9 @@ type Bucket struct {
 	Name                string        `json:"name"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+
+	// SchemaType is set when the bucket is created and cannot change. The empty type is implicit.
+	SchemaType BucketSchemaType `json:"schemaType,omitempty"`
 }
 
 // ops for buckets error and buckets op logs.
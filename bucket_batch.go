@@ -0,0 +1,73 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// The operations of a bucket batch.
+const (
+	BucketBatchCreate = "create"
+	BucketBatchUpdate = "update"
+	BucketBatchDelete = "delete"
+)
+
+// MaxBucketBatchSize is the most operations a bucket batch can have.
+const MaxBucketBatchSize = 1000
+
+// BucketBatchOperation is a change of a bucket in a batch: the creation of Bucket,
+// or the update or the deletion of the bucket ID.
+type BucketBatchOperation struct {
+	Op     string
+	ID     ID
+	Bucket *Bucket
+	Update *BucketUpdate
+}
+
+// Validate returns an error if the operation is missing what it applies.
+func (o BucketBatchOperation) Validate() error {
+	switch o.Op {
+	case BucketBatchCreate:
+		if o.Bucket == nil {
+			return fmt.Errorf("create operation requires a bucket")
+		}
+		if o.Bucket.Organization == "" && !o.Bucket.OrganizationID.Valid() {
+			return fmt.Errorf("bucket requires an organization")
+		}
+	case BucketBatchUpdate:
+		if !o.ID.Valid() || o.Update == nil {
+			return fmt.Errorf("update operation requires a bucket ID and an update")
+		}
+	case BucketBatchDelete:
+		if !o.ID.Valid() {
+			return fmt.Errorf("delete operation requires a bucket ID")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q; valid operations are create, update and delete", o.Op)
+	}
+	return nil
+}
+
+// BucketBatchResult is the result of an operation of a bucket batch: the bucket created, updated or deleted,
+// or the error of the operation.
+type BucketBatchResult struct {
+	Bucket *Bucket
+	Err    error
+}
+
+// BucketBatchService applies many changes of buckets at once.
+type BucketBatchService interface {
+	// ApplyBucketBatch applies the operations all together, or none of them if any fails.
+	// The results are those of the operations in order, with the errors of the operations that failed.
+	// The error is a BucketBatchError if the batch is not applied because of them.
+	ApplyBucketBatch(ctx context.Context, ops []BucketBatchOperation) ([]BucketBatchResult, error)
+}
+
+// BucketBatchError is the error of a bucket batch not applied because of the operations that failed.
+type BucketBatchError struct {
+	Failed int
+}
+
+func (e *BucketBatchError) Error() string {
+	return fmt.Sprintf("%d operations of the batch failed; no operation is applied", e.Failed)
+}
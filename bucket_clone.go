@@ -0,0 +1,115 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// MaxBucketCloneDays is the most days of data a bucket clone copies.
+const MaxBucketCloneDays = 90
+
+// BucketCloneRequest asks for the most recent Days of data of a bucket to be copied into a new bucket,
+// named DestBucketName in the organization DestOrgID, which may be the organization of the bucket.
+//
+// If DownsampleEvery is set, the data are copied as windows of that length: numeric fields
+// are the mean of the points of a window, and the other fields its last point,
+// at the start of the window. It must divide a day, so that windows do not span days.
+type BucketCloneRequest struct {
+	OrgID           ID            `json:"orgID"`
+	BucketID        ID            `json:"bucketID"`
+	DestOrgID       ID            `json:"destOrgID"`
+	DestBucketName  string        `json:"destBucketName"`
+	Days            int           `json:"days"`
+	DownsampleEvery time.Duration `json:"downsampleEvery,omitempty"`
+}
+
+// Validate returns an error if the request is invalid.
+func (r *BucketCloneRequest) Validate() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone orgID is required",
+		}
+	}
+	if !r.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone bucketID is required",
+		}
+	}
+	if !r.DestOrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone destOrgID is required",
+		}
+	}
+	if r.DestBucketName == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone destBucketName is required",
+		}
+	}
+	if r.Days < 1 || r.Days > MaxBucketCloneDays {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone days must be between 1 and 90",
+		}
+	}
+	if r.DownsampleEvery < 0 || (r.DownsampleEvery > 0 && (r.DownsampleEvery%time.Second != 0 || (24*time.Hour)%r.DownsampleEvery != 0)) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket clone downsampleEvery must be a whole number of seconds dividing a day",
+		}
+	}
+	return nil
+}
+
+// Statuses of a BucketCloneJob.
+const (
+	BucketCloneJobQueued  = "queued"
+	BucketCloneJobRunning = "running"
+	BucketCloneJobSuccess = "success"
+	BucketCloneJobFailed  = "failed"
+)
+
+// BucketCloneService copies the recent data of buckets into new buckets in the background,
+// as jobs whose progress can be followed.
+type BucketCloneService interface {
+	// CreateBucketCloneJob creates the bucket the data of req are copied into, and queues the job copying them.
+	CreateBucketCloneJob(ctx context.Context, req BucketCloneRequest) (*BucketCloneJob, error)
+
+	// FindBucketCloneJobByID returns a single bucket clone job by ID.
+	FindBucketCloneJobByID(ctx context.Context, id ID) (*BucketCloneJob, error)
+
+	// FindBucketCloneJobs returns the bucket clone jobs matching filter, most recent first.
+	FindBucketCloneJobs(ctx context.Context, filter BucketCloneJobFilter) ([]*BucketCloneJob, error)
+}
+
+// BucketCloneJob is a bucket clone running in the background.
+//
+// The data are copied a day at a time, oldest first. DaysCopied is the number of days copied so far,
+// and PointsWritten the number of points written to the bucket DestBucketID so far.
+type BucketCloneJob struct {
+	ID            ID                 `json:"id"`
+	Request       BucketCloneRequest `json:"request"`
+	DestBucketID  ID                 `json:"destBucketID"`
+	Status        string             `json:"status"`
+	Error         string             `json:"error,omitempty"`
+	DaysCopied    int                `json:"daysCopied"`
+	PointsWritten int64              `json:"pointsWritten"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	StartedAt     time.Time          `json:"startedAt"`
+	FinishedAt    time.Time          `json:"finishedAt"`
+}
+
+// Finished returns true if the job succeeded or failed.
+func (j *BucketCloneJob) Finished() bool {
+	return j.Status == BucketCloneJobSuccess || j.Status == BucketCloneJobFailed
+}
+
+// BucketCloneJobFilter represents a set of filters that restrict the returned bucket clone jobs.
+type BucketCloneJobFilter struct {
+	OrgID     *ID
+	BucketID  *ID
+	DestOrgID *ID
+}
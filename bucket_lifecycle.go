@@ -0,0 +1,179 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrBucketLifecyclePolicyNotFound is the error msg for a missing bucket lifecycle policy.
+const ErrBucketLifecyclePolicyNotFound = "bucket lifecycle policy not found"
+
+// ops for bucket lifecycle policy error
+const (
+	OpFindBucketLifecyclePolicy   = "FindBucketLifecyclePolicy"
+	OpPutBucketLifecyclePolicy    = "PutBucketLifecyclePolicy"
+	OpDeleteBucketLifecyclePolicy = "DeleteBucketLifecyclePolicy"
+	OpFindBucketLifecycleStatus   = "FindBucketLifecycleStatus"
+)
+
+// MaxBucketLifecycleTiers is the most downsample tiers of a bucket lifecycle policy.
+const MaxBucketLifecycleTiers = 5
+
+// DefaultBucketLifecycleFn is the aggregate a tier downsamples with, if it sets none.
+const DefaultBucketLifecycleFn = "mean"
+
+// bucketLifecycleFns are the aggregates a tier can downsample with.
+var bucketLifecycleFns = map[string]bool{
+	"mean":  true,
+	"sum":   true,
+	"count": true,
+	"min":   true,
+	"max":   true,
+	"first": true,
+	"last":  true,
+}
+
+// BucketLifecycleService manages the lifecycle policies of buckets, which the service materializes
+// as the buckets and tasks downsampling their data.
+type BucketLifecycleService interface {
+	// FindBucketLifecyclePolicy returns the lifecycle policy of a bucket.
+	FindBucketLifecyclePolicy(ctx context.Context, bucketID ID) (*BucketLifecyclePolicy, error)
+
+	// PutBucketLifecyclePolicy sets the lifecycle policy of a bucket, replacing its previous one,
+	// and sets the buckets and tasks of the tiers of p with those materializing them.
+	PutBucketLifecyclePolicy(ctx context.Context, p *BucketLifecyclePolicy) error
+
+	// DeleteBucketLifecyclePolicy removes the lifecycle policy of a bucket, along with its tasks.
+	// The buckets of its tiers are kept, with their data.
+	DeleteBucketLifecyclePolicy(ctx context.Context, bucketID ID) error
+
+	// FindBucketLifecycleStatus returns the status of the buckets and tasks materializing
+	// the lifecycle policy of a bucket.
+	FindBucketLifecycleStatus(ctx context.Context, bucketID ID) (*BucketLifecycleStatus, error)
+}
+
+// BucketLifecyclePolicy declares how long the data of a bucket are kept, raw and downsampled,
+// such as raw for 7 days, downsampled to 1m for 90 days, and to 1h for 2 years.
+//
+// The raw data are kept for RawRetention, the retention period of the bucket, forever if it is zero.
+// Each tier downsamples the data of the tier before it, the first one the raw data, into a bucket
+// of its own, where they are kept for the retention period of the tier.
+type BucketLifecyclePolicy struct {
+	BucketID     ID                    `json:"bucketID"`
+	OrgID        ID                    `json:"orgID"`
+	RawRetention time.Duration         `json:"rawRetention"`
+	Tiers        []BucketLifecycleTier `json:"tiers"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
+}
+
+// BucketLifecycleTier is a tier of a bucket lifecycle policy, downsampling data to windows of Every
+// with the aggregate Fn, and keeping them for Retention, forever if it is zero.
+//
+// BucketID and TaskID are the bucket the tier is written to and the task downsampling the data,
+// both managed by the service materializing the policy.
+type BucketLifecycleTier struct {
+	Every     time.Duration `json:"every"`
+	Retention time.Duration `json:"retention"`
+	Fn        string        `json:"fn,omitempty"`
+	BucketID  ID            `json:"bucketID,omitempty"`
+	TaskID    ID            `json:"taskID,omitempty"`
+}
+
+// Validate returns an error if the policy is invalid.
+//
+// The window of a tier is a whole number of seconds, and a multiple of the window of the tier before it.
+// Its data, like the raw data, must be kept for at least the window of the tier after it,
+// for that tier to be able to downsample them.
+func (p *BucketLifecyclePolicy) Validate() error {
+	if !p.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket lifecycle policy bucketID is required",
+		}
+	}
+	if !p.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket lifecycle policy orgID is required",
+		}
+	}
+	if p.RawRetention < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket lifecycle policy rawRetention must not be negative",
+		}
+	}
+	if len(p.Tiers) == 0 || len(p.Tiers) > MaxBucketLifecycleTiers {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket lifecycle policy must have between 1 and 5 tiers",
+		}
+	}
+
+	retention := p.RawRetention
+	var every time.Duration
+	for _, t := range p.Tiers {
+		if t.Every < time.Second || t.Every%time.Second != 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket lifecycle tier every must be a whole number of seconds",
+			}
+		}
+		if every > 0 && (t.Every <= every || t.Every%every != 0) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket lifecycle tier every must be a multiple of the every of the tier before it",
+			}
+		}
+		if retention > 0 && retention < t.Every {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket lifecycle data must be kept for at least the every of the tier downsampling them",
+			}
+		}
+		if t.Retention < 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket lifecycle tier retention must not be negative",
+			}
+		}
+		if t.Fn != "" && !bucketLifecycleFns[t.Fn] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket lifecycle tier fn must be one of mean, sum, count, min, max, first and last",
+			}
+		}
+		retention, every = t.Retention, t.Every
+	}
+	return nil
+}
+
+// States of the tiers of a bucket lifecycle policy, and of the policy, the worst of those of its tiers.
+const (
+	BucketLifecycleStateOK       = "ok"
+	BucketLifecycleStateInactive = "inactive"
+	BucketLifecycleStateFailing  = "failing"
+	BucketLifecycleStateMissing  = "missing"
+)
+
+// BucketLifecycleStatus is the status of the materialization of a bucket lifecycle policy.
+type BucketLifecycleStatus struct {
+	BucketID     ID                          `json:"bucketID"`
+	OrgID        ID                          `json:"orgID"`
+	State        string                      `json:"state"`
+	RawRetention time.Duration               `json:"rawRetention"`
+	Tiers        []BucketLifecycleTierStatus `json:"tiers"`
+}
+
+// BucketLifecycleTierStatus is the status of a tier of a bucket lifecycle policy.
+//
+// The state of a tier is missing if its bucket or its task was deleted, inactive if its task is,
+// failing if the latest run of its task failed, and ok otherwise.
+// LatestRun is the latest run of its task scheduled in the last ten windows, if any.
+type BucketLifecycleTierStatus struct {
+	BucketLifecycleTier
+	State      string `json:"state"`
+	BucketName string `json:"bucketName,omitempty"`
+	TaskStatus string `json:"taskStatus,omitempty"`
+	LatestRun  *Run   `json:"latestRun,omitempty"`
+}
@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// BucketPolicy is a set of guardrails an organization places on its buckets.
+// The zero value places no restrictions.
+type BucketPolicy struct {
+	// DefaultRetentionPeriod is assigned to new buckets created with infinite retention.
+	DefaultRetentionPeriod time.Duration `json:"defaultRetentionPeriod,omitempty"`
+
+	// MinRetentionPeriod is the shortest retention period a bucket may have.
+	// If zero, there is no lower bound.
+	MinRetentionPeriod time.Duration `json:"minRetentionPeriod,omitempty"`
+
+	// MaxRetentionPeriod is the longest retention period a bucket may have.
+	// If zero, there is no upper bound and infinite retention is allowed.
+	MaxRetentionPeriod time.Duration `json:"maxRetentionPeriod,omitempty"`
+
+	// NamePattern is a regular expression that bucket names must match.
+	// If empty, any name is allowed.
+	NamePattern string `json:"namePattern,omitempty"`
+}
+
+// Validate returns an error if the policy is not internally consistent.
+func (p *BucketPolicy) Validate() error {
+	if p.DefaultRetentionPeriod < 0 || p.MinRetentionPeriod < 0 || p.MaxRetentionPeriod < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket policy retention periods must not be negative",
+		}
+	}
+
+	if p.MaxRetentionPeriod != 0 && p.MinRetentionPeriod > p.MaxRetentionPeriod {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket policy minimum retention period is greater than maximum retention period",
+		}
+	}
+
+	if p.DefaultRetentionPeriod != 0 {
+		if err := p.checkRetention(p.DefaultRetentionPeriod); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket policy default retention period is outside of the allowed range",
+				Err:  err,
+			}
+		}
+	}
+
+	if _, err := p.nameRegexp(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ApplyDefaults sets the policy's default retention period on b if b has infinite retention.
+func (p *BucketPolicy) ApplyDefaults(b *Bucket) {
+	if b.RetentionPeriod == InfiniteRetention {
+		b.RetentionPeriod = p.DefaultRetentionPeriod
+	}
+}
+
+// CheckBucket returns an error if b violates the policy.
+func (p *BucketPolicy) CheckBucket(b *Bucket) error {
+	if err := p.checkRetention(b.RetentionPeriod); err != nil {
+		return err
+	}
+
+	re, err := p.nameRegexp()
+	if err != nil {
+		return err
+	}
+	if re != nil && !re.MatchString(b.Name) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("bucket name %q does not match organization naming policy %q", b.Name, p.NamePattern),
+		}
+	}
+
+	return nil
+}
+
+func (p *BucketPolicy) checkRetention(d time.Duration) error {
+	if p.MaxRetentionPeriod != 0 && (d == InfiniteRetention || d > p.MaxRetentionPeriod) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("retention period must not be longer than %s", p.MaxRetentionPeriod),
+		}
+	}
+
+	if d != InfiniteRetention && d < p.MinRetentionPeriod {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("retention period must not be shorter than %s", p.MinRetentionPeriod),
+		}
+	}
+
+	return nil
+}
+
+func (p *BucketPolicy) nameRegexp() (*regexp.Regexp, error) {
+	if p.NamePattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(p.NamePattern)
+	if err != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "bucket policy name pattern is not a valid regular expression",
+			Err:  err,
+		}
+	}
+	return re, nil
+}
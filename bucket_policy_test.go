@@ -0,0 +1,101 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestBucketPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  influxdb.BucketPolicy
+		wantErr bool
+	}{
+		{
+			name: "zero value is valid",
+		},
+		{
+			name: "valid policy",
+			policy: influxdb.BucketPolicy{
+				DefaultRetentionPeriod: 24 * time.Hour,
+				MinRetentionPeriod:     time.Hour,
+				MaxRetentionPeriod:     48 * time.Hour,
+				NamePattern:            "^[a-z]+$",
+			},
+		},
+		{
+			name: "minimum greater than maximum",
+			policy: influxdb.BucketPolicy{
+				MinRetentionPeriod: 48 * time.Hour,
+				MaxRetentionPeriod: time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "default outside of range",
+			policy: influxdb.BucketPolicy{
+				DefaultRetentionPeriod: time.Minute,
+				MinRetentionPeriod:     time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative retention",
+			policy: influxdb.BucketPolicy{
+				MinRetentionPeriod: -time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name pattern",
+			policy: influxdb.BucketPolicy{
+				NamePattern: "[a-z",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("BucketPolicy.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBucketPolicyCheckBucket(t *testing.T) {
+	policy := influxdb.BucketPolicy{
+		MaxRetentionPeriod: 48 * time.Hour,
+		NamePattern:        "^team-",
+	}
+
+	tests := []struct {
+		name    string
+		bucket  influxdb.Bucket
+		wantErr bool
+	}{
+		{
+			name:   "bucket within policy",
+			bucket: influxdb.Bucket{Name: "team-a", RetentionPeriod: time.Hour},
+		},
+		{
+			name:    "infinite retention exceeds maximum",
+			bucket:  influxdb.Bucket{Name: "team-a"},
+			wantErr: true,
+		},
+		{
+			name:    "name does not match pattern",
+			bucket:  influxdb.Bucket{Name: "a", RetentionPeriod: time.Hour},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := policy.CheckBucket(&tt.bucket); (err != nil) != tt.wantErr {
+				t.Errorf("BucketPolicy.CheckBucket() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
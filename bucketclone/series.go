@@ -0,0 +1,174 @@
+package bucketclone
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/models"
+)
+
+// seriesReader turns the rows of the table of a series into points, downsampling them if every is set.
+type seriesReader struct {
+	measurement string
+	field       string
+	tags        models.Tags
+	every       time.Duration
+	w           *batchWriter
+
+	// The window being downsampled, the number of its points,
+	// the sum of their values if they are numeric, and its last value.
+	window time.Time
+	n      int
+	sum    float64
+	last   interface{}
+}
+
+// newSeriesReader returns a seriesReader of the series of the group key of a table,
+// or nil if the table is not the table of a series.
+func newSeriesReader(key flux.GroupKey, every time.Duration, w *batchWriter) (*seriesReader, error) {
+	sr := &seriesReader{
+		every: every,
+		w:     w,
+	}
+	tags := make(map[string]string)
+	for j, col := range key.Cols() {
+		// The _start and _stop columns of the key are times.
+		if col.Type != flux.TString {
+			continue
+		}
+		switch col.Label {
+		case "_measurement":
+			sr.measurement = key.ValueString(j)
+		case "_field":
+			sr.field = key.ValueString(j)
+		default:
+			tags[col.Label] = key.ValueString(j)
+		}
+	}
+	if sr.measurement == "" || sr.field == "" {
+		return nil, nil
+	}
+	sr.tags = models.NewTags(tags)
+	return sr, nil
+}
+
+// read reads the rows of cr, which are in time order.
+func (sr *seriesReader) read(ctx context.Context, cr flux.ColReader) error {
+	timeIdx, valueIdx := -1, -1
+	for j, col := range cr.Cols() {
+		switch {
+		case col.Label == "_time" && col.Type == flux.TTime:
+			timeIdx = j
+		case col.Label == "_value":
+			valueIdx = j
+		}
+	}
+	if timeIdx < 0 || valueIdx < 0 {
+		return nil
+	}
+
+	ts := cr.Times(timeIdx)
+	for i := 0; i < cr.Len(); i++ {
+		if ts.IsNull(i) {
+			continue
+		}
+		v, ok := value(cr, valueIdx, i)
+		if !ok {
+			continue
+		}
+		if err := sr.add(ctx, time.Unix(0, ts.Value(i)).UTC(), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close writes the window being downsampled.
+func (sr *seriesReader) close(ctx context.Context) error {
+	return sr.flushWindow(ctx)
+}
+
+func (sr *seriesReader) add(ctx context.Context, t time.Time, v interface{}) error {
+	if sr.every <= 0 {
+		return sr.writePoint(ctx, t, v)
+	}
+
+	window := t.Truncate(sr.every)
+	if sr.n > 0 && !window.Equal(sr.window) {
+		if err := sr.flushWindow(ctx); err != nil {
+			return err
+		}
+	}
+	sr.window = window
+	sr.n++
+	sr.last = v
+	switch v := v.(type) {
+	case float64:
+		sr.sum += v
+	case int64:
+		sr.sum += float64(v)
+	case uint64:
+		sr.sum += float64(v)
+	}
+	return nil
+}
+
+// flushWindow writes the mean of the window being downsampled if its values are numeric, and its last value otherwise.
+func (sr *seriesReader) flushWindow(ctx context.Context) error {
+	if sr.n == 0 {
+		return nil
+	}
+	v := sr.last
+	switch v.(type) {
+	case float64, int64, uint64:
+		v = sr.sum / float64(sr.n)
+	}
+	sr.n, sr.sum, sr.last = 0, 0, nil
+	return sr.writePoint(ctx, sr.window, v)
+}
+
+func (sr *seriesReader) writePoint(ctx context.Context, t time.Time, v interface{}) error {
+	pt, err := models.NewPoint(sr.measurement, sr.tags, models.Fields{sr.field: v}, t)
+	if err != nil {
+		return err
+	}
+	return sr.w.write(ctx, pt)
+}
+
+// value returns the value of row i of column j of cr, or false if it is null or of an unsupported type.
+func value(cr flux.ColReader, j, i int) (interface{}, bool) {
+	switch cr.Cols()[j].Type {
+	case flux.TFloat:
+		vs := cr.Floats(j)
+		if vs.IsNull(i) {
+			return nil, false
+		}
+		return vs.Value(i), true
+	case flux.TInt:
+		vs := cr.Ints(j)
+		if vs.IsNull(i) {
+			return nil, false
+		}
+		return vs.Value(i), true
+	case flux.TUInt:
+		vs := cr.UInts(j)
+		if vs.IsNull(i) {
+			return nil, false
+		}
+		return vs.Value(i), true
+	case flux.TString:
+		vs := cr.Strings(j)
+		if vs.IsNull(i) {
+			return nil, false
+		}
+		return vs.ValueString(i), true
+	case flux.TBool:
+		vs := cr.Bools(j)
+		if vs.IsNull(i) {
+			return nil, false
+		}
+		return vs.Value(i), true
+	}
+	return nil, false
+}
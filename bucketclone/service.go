@@ -0,0 +1,353 @@
+// Package bucketclone copies the recent data of buckets into new buckets, such as to seed
+// a staging organization from production, as jobs running in the background.
+package bucketclone
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/snowflake"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+var _ platform.BucketCloneService = (*Service)(nil)
+
+const (
+	// DefaultBatchSize is the number of points of a clone written at once, by default.
+	DefaultBatchSize = 5000
+
+	// jobQueueSize is the number of clone jobs that can wait for the job running before them.
+	jobQueueSize = 100
+
+	// maxFinishedJobs is the number of finished clone jobs kept to be found after they finish.
+	maxFinishedJobs = 1000
+)
+
+// PointsWriter writes points to the storage engine.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// Service clones buckets, running the clone jobs one at a time in the order they are created,
+// while Run runs. The jobs are kept in memory, and the jobs still queued when Run returns
+// run once it runs again.
+type Service struct {
+	BucketService platform.BucketService
+	QueryService  query.QueryService
+	PointsWriter  PointsWriter
+
+	// BatchSize is the number of points written at once. It defaults to DefaultBatchSize.
+	BatchSize int
+
+	Now    func() time.Time
+	Logger *zap.Logger
+
+	idgen platform.IDGenerator
+	queue chan *platform.BucketCloneJob
+
+	mu       sync.Mutex
+	jobs     map[platform.ID]*platform.BucketCloneJob
+	finished []platform.ID // IDs of the finished jobs, oldest first.
+}
+
+// NewService returns a Service creating buckets with bs, reading their data through qs and writing them with pw.
+func NewService(logger *zap.Logger, bs platform.BucketService, qs query.QueryService, pw PointsWriter) *Service {
+	return &Service{
+		BucketService: bs,
+		QueryService:  qs,
+		PointsWriter:  pw,
+		BatchSize:     DefaultBatchSize,
+		Now:           time.Now,
+		Logger:        logger,
+		idgen:         snowflake.NewIDGenerator(),
+		queue:         make(chan *platform.BucketCloneJob, jobQueueSize),
+		jobs:          make(map[platform.ID]*platform.BucketCloneJob),
+	}
+}
+
+// CreateBucketCloneJob creates the bucket the data of req are copied into, with the retention period
+// of the bucket cloned, and queues the job copying them.
+func (s *Service) CreateBucketCloneJob(ctx context.Context, req platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	src, err := s.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if src.OrganizationID != req.OrgID {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "bucket not found",
+		}
+	}
+
+	dest := &platform.Bucket{
+		OrganizationID:  req.DestOrgID,
+		Name:            req.DestBucketName,
+		RetentionPeriod: src.RetentionPeriod,
+	}
+	if err := s.BucketService.CreateBucket(ctx, dest); err != nil {
+		return nil, err
+	}
+
+	job := &platform.BucketCloneJob{
+		ID:           s.idgen.ID(),
+		Request:      req,
+		DestBucketID: dest.ID,
+		Status:       platform.BucketCloneJobQueued,
+		CreatedAt:    s.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	select {
+	case s.queue <- job:
+		s.jobs[job.ID] = job
+		j := *job
+		s.mu.Unlock()
+		return &j, nil
+	default:
+		s.mu.Unlock()
+	}
+
+	// The bucket is of no use without its data.
+	if err := s.BucketService.DeleteBucket(ctx, dest.ID); err != nil {
+		s.Logger.Info("Failed to delete the bucket of a clone job not queued", zap.String("bucket_id", dest.ID.String()), zap.Error(err))
+	}
+	return nil, &platform.Error{
+		Code: platform.EUnavailable,
+		Msg:  "too many bucket clone jobs are queued",
+	}
+}
+
+// FindBucketCloneJobByID returns a single bucket clone job by ID.
+func (s *Service) FindBucketCloneJobByID(ctx context.Context, id platform.ID) (*platform.BucketCloneJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "bucket clone job not found",
+		}
+	}
+	j := *job
+	return &j, nil
+}
+
+// FindBucketCloneJobs returns the bucket clone jobs matching filter, most recent first.
+func (s *Service) FindBucketCloneJobs(ctx context.Context, filter platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := []*platform.BucketCloneJob{}
+	for _, job := range s.jobs {
+		if filter.OrgID != nil && *filter.OrgID != job.Request.OrgID {
+			continue
+		}
+		if filter.BucketID != nil && *filter.BucketID != job.Request.BucketID {
+			continue
+		}
+		if filter.DestOrgID != nil && *filter.DestOrgID != job.Request.DestOrgID {
+			continue
+		}
+		j := *job
+		jobs = append(jobs, &j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// Run runs the queued clone jobs one at a time until ctx is done, which fails the job running.
+func (s *Service) Run(ctx context.Context) {
+	s.Logger.Info("Starting")
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("Stopping")
+			return
+		case job := <-s.queue:
+			s.runJob(ctx, job)
+		}
+	}
+}
+
+// runJob runs a single clone job, following its progress.
+func (s *Service) runJob(ctx context.Context, job *platform.BucketCloneJob) {
+	now := time.Now()
+	s.update(job, func(job *platform.BucketCloneJob) {
+		job.Status = platform.BucketCloneJobRunning
+		job.StartedAt = s.Now().UTC()
+	})
+
+	err := s.clone(ctx, job)
+	s.finish(job, err)
+
+	l := s.Logger.With(zap.String("job_id", job.ID.String()))
+	if err != nil {
+		l.Error("Bucket clone job failed", zap.Error(err))
+	} else {
+		l.Info("Bucket clone job finished", zap.Duration("duration", time.Since(now)))
+	}
+}
+
+// update calls fn to change job under the lock of the jobs.
+func (s *Service) update(job *platform.BucketCloneJob, fn func(job *platform.BucketCloneJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(job)
+}
+
+// finish marks job as finished, and forgets the oldest finished jobs beyond maxFinishedJobs.
+func (s *Service) finish(job *platform.BucketCloneJob, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.FinishedAt = s.Now().UTC()
+	if err != nil {
+		job.Status = platform.BucketCloneJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = platform.BucketCloneJobSuccess
+	}
+
+	s.finished = append(s.finished, job.ID)
+	for len(s.finished) > maxFinishedJobs {
+		delete(s.jobs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+// clone copies the data of the request of job a day at a time, up to the time the job was created.
+// When the data are downsampled, only the whole windows before then are copied.
+func (s *Service) clone(ctx context.Context, job *platform.BucketCloneJob) error {
+	req := job.Request
+	stop := job.CreatedAt
+	if req.DownsampleEvery > 0 {
+		stop = stop.Truncate(req.DownsampleEvery)
+	}
+	start := stop.Add(-time.Duration(req.Days) * 24 * time.Hour)
+
+	w := &batchWriter{
+		orgID:    req.DestOrgID,
+		bucketID: job.DestBucketID,
+		size:     s.BatchSize,
+		writer:   s.PointsWriter,
+	}
+	if w.size <= 0 {
+		w.size = DefaultBatchSize
+	}
+
+	for day := 0; day < req.Days; day++ {
+		dayStart := start.Add(time.Duration(day) * 24 * time.Hour)
+		if err := s.copyRange(ctx, req, dayStart, dayStart.Add(24*time.Hour), w); err != nil {
+			return err
+		}
+		if err := w.flush(ctx); err != nil {
+			return err
+		}
+		s.update(job, func(job *platform.BucketCloneJob) {
+			job.DaysCopied = day + 1
+			job.PointsWritten = w.written
+		})
+	}
+	return nil
+}
+
+// copyRange reads the data of the bucket of req between start and stop, and writes them with w.
+func (s *Service) copyRange(ctx context.Context, req platform.BucketCloneRequest, start, stop time.Time, w *batchWriter) error {
+	p, err := platform.NewPermissionAtID(req.BucketID, platform.ReadAction, platform.BucketsResourceType, req.OrgID)
+	if err != nil {
+		return err
+	}
+	itr, err := s.QueryService.Query(ctx, &query.Request{
+		Authorization: &platform.Authorization{
+			OrgID:       req.OrgID,
+			Status:      platform.Active,
+			Permissions: []platform.Permission{*p},
+		},
+		OrganizationID: req.OrgID,
+		Compiler:       lang.FluxCompiler{Query: rangeQuery(req.BucketID, start, stop)},
+	})
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	for itr.More() {
+		err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+			sr, err := newSeriesReader(tbl.Key(), req.DownsampleEvery, w)
+			if err != nil || sr == nil {
+				return err
+			}
+			if err := tbl.Do(func(cr flux.ColReader) error {
+				return sr.read(ctx, cr)
+			}); err != nil {
+				return err
+			}
+			return sr.close(ctx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return itr.Err()
+}
+
+// rangeQuery returns the script reading the points of bucketID between start and stop, a table per series.
+func rangeQuery(bucketID platform.ID, start, stop time.Time) string {
+	return fmt.Sprintf(`from(bucketID: %q)
+  |> range(start: %s, stop: %s)`,
+		bucketID.String(), start.UTC().Format(time.RFC3339Nano), stop.UTC().Format(time.RFC3339Nano))
+}
+
+// batchWriter writes points to a bucket in batches.
+type batchWriter struct {
+	orgID    platform.ID
+	bucketID platform.ID
+	size     int
+	writer   PointsWriter
+
+	batch   []models.Point
+	written int64
+}
+
+func (w *batchWriter) write(ctx context.Context, pt models.Point) error {
+	w.batch = append(w.batch, pt)
+	if len(w.batch) >= w.size {
+		return w.flush(ctx)
+	}
+	return nil
+}
+
+func (w *batchWriter) flush(ctx context.Context) error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	exploded, err := tsdb.ExplodePoints(w.orgID, w.bucketID, w.batch)
+	if err != nil {
+		return err
+	}
+	if err := w.writer.WritePoints(ctx, exploded); err != nil {
+		return err
+	}
+	w.written += int64(len(w.batch))
+	w.batch = w.batch[:0]
+	return nil
+}
@@ -0,0 +1,247 @@
+package bucketclone_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bucketclone"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// linesWriter records the points it is given as "measurement,tags field=value time" lines.
+type linesWriter struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (w *linesWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, pt := range points {
+		var tags []string
+		var measurement string
+		for _, tag := range pt.Tags() {
+			switch string(tag.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(tag.Value)
+			case models.FieldKeyTagKey:
+			default:
+				tags = append(tags, string(tag.Key)+"="+string(tag.Value))
+			}
+		}
+		fields, err := pt.Fields()
+		if err != nil {
+			return err
+		}
+		for k, v := range fields {
+			w.lines = append(w.lines, fmt.Sprintf("%s,%s %s=%v %s", measurement, strings.Join(tags, ","), k, v, pt.Time().UTC().Format(time.RFC3339)))
+		}
+	}
+	sort.Strings(w.lines)
+	return nil
+}
+
+func TestService_CreateBucketCloneJob(t *testing.T) {
+	now := time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC)
+	ts := func(s string) execute.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return execute.Time(tm.UnixNano())
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, OrganizationID: 1, Name: "production", RetentionPeriod: 30 * 24 * time.Hour}, nil
+	}
+	var created []*platform.Bucket
+	buckets.CreateBucketFn = func(ctx context.Context, b *platform.Bucket) error {
+		b.ID = platform.ID(100 + len(created))
+		created = append(created, b)
+		return nil
+	}
+
+	var mu sync.Mutex
+	var scripts []string
+	qs := &querymock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.Authorization == nil || req.OrganizationID != 1 || !req.Authorization.Allowed(platform.Permission{
+				Action:   platform.ReadAction,
+				Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &req.OrganizationID, ID: idPtr(2)},
+			}) {
+				t.Errorf("unexpected query request: %+v", req)
+			}
+			script := req.Compiler.(lang.FluxCompiler).Query
+			mu.Lock()
+			scripts = append(scripts, script)
+			mu.Unlock()
+
+			// The points are all in the first day cloned.
+			if !strings.Contains(script, "start: 2019-06-08T12:30:00Z") {
+				return flux.NewSliceResultIterator(nil), nil
+			}
+			cols := []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_stop", Type: flux.TTime},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			}
+			start, stop := ts("2019-06-08T12:30:00Z"), ts("2019-06-09T12:30:00Z")
+			usage := &executetest.Table{
+				KeyCols: []string{"_start", "_stop", "_field", "_measurement", "host"},
+				ColMeta: cols,
+				Data: [][]interface{}{
+					{start, stop, ts("2019-06-08T13:00:00Z"), 1.0, "usage", "cpu", "a"},
+					{start, stop, ts("2019-06-08T13:00:30Z"), 2.0, "usage", "cpu", "a"},
+					{start, stop, ts("2019-06-08T13:01:10Z"), 4.0, "usage", "cpu", "a"},
+				},
+			}
+			stateCols := append([]flux.ColMeta(nil), cols...)
+			stateCols[3] = flux.ColMeta{Label: "_value", Type: flux.TString}
+			state := &executetest.Table{
+				KeyCols: []string{"_start", "_stop", "_field", "_measurement", "host"},
+				ColMeta: stateCols,
+				Data: [][]interface{}{
+					{start, stop, ts("2019-06-08T13:00:00Z"), "up", "state", "cpu", "a"},
+					{start, stop, ts("2019-06-08T13:00:50Z"), "down", "state", "cpu", "a"},
+				},
+			}
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm:   "_result",
+				Tbls: []*executetest.Table{usage, state},
+			}}), nil
+		},
+	}
+
+	tests := []struct {
+		name  string
+		every time.Duration
+		want  []string
+	}{
+		{
+			name: "raw",
+			want: []string{
+				"cpu,host=a state=down 2019-06-08T13:00:50Z",
+				"cpu,host=a state=up 2019-06-08T13:00:00Z",
+				"cpu,host=a usage=1 2019-06-08T13:00:00Z",
+				"cpu,host=a usage=2 2019-06-08T13:00:30Z",
+				"cpu,host=a usage=4 2019-06-08T13:01:10Z",
+			},
+		},
+		{
+			name:  "downsampled",
+			every: time.Minute,
+			want: []string{
+				"cpu,host=a state=down 2019-06-08T13:00:00Z",
+				"cpu,host=a usage=1.5 2019-06-08T13:00:00Z",
+				"cpu,host=a usage=4 2019-06-08T13:01:00Z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, scripts = nil, nil
+			pw := &linesWriter{}
+			s := bucketclone.NewService(zaptest.NewLogger(t), buckets, qs, pw)
+			s.Now = func() time.Time { return now }
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+
+			job, err := s.CreateBucketCloneJob(ctx, platform.BucketCloneRequest{
+				OrgID:           1,
+				BucketID:        2,
+				DestOrgID:       3,
+				DestBucketName:  "staging",
+				Days:            2,
+				DownsampleEvery: tt.every,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(created) != 1 || created[0].OrganizationID != 3 || created[0].Name != "staging" || created[0].RetentionPeriod != 30*24*time.Hour {
+				t.Fatalf("unexpected buckets created: %+v", created)
+			}
+			if job.DestBucketID != created[0].ID {
+				t.Errorf("got destination bucket %s, want %s", job.DestBucketID, created[0].ID)
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for !job.Finished() {
+				if time.Now().After(deadline) {
+					t.Fatalf("timed out waiting for the job to finish: %+v", job)
+				}
+				time.Sleep(10 * time.Millisecond)
+				if job, err = s.FindBucketCloneJobByID(ctx, job.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if job.Status != platform.BucketCloneJobSuccess || job.DaysCopied != 2 || job.PointsWritten != int64(len(tt.want)) {
+				t.Errorf("unexpected finished job: %+v", job)
+			}
+
+			mu.Lock()
+			if len(scripts) != 2 || !strings.Contains(scripts[1], "start: 2019-06-09T12:30:00Z, stop: 2019-06-10T12:30:00Z") {
+				t.Errorf("expected a query per day, got:\n%s", strings.Join(scripts, "\n"))
+			}
+			mu.Unlock()
+
+			pw.mu.Lock()
+			defer pw.mu.Unlock()
+			if strings.Join(pw.lines, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unexpected points written:\n%s\nwant:\n%s", strings.Join(pw.lines, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestService_CreateBucketCloneJob_Invalid(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, OrganizationID: 1}, nil
+	}
+	buckets.CreateBucketFn = func(ctx context.Context, b *platform.Bucket) error {
+		t.Errorf("unexpected bucket created: %+v", b)
+		return nil
+	}
+	s := bucketclone.NewService(zaptest.NewLogger(t), buckets, &querymock.QueryService{}, &linesWriter{})
+
+	for _, req := range []platform.BucketCloneRequest{
+		{OrgID: 1, BucketID: 2, DestOrgID: 3, DestBucketName: "staging"},
+		{OrgID: 1, BucketID: 2, DestOrgID: 3, DestBucketName: "staging", Days: 1, DownsampleEvery: 7 * time.Minute},
+		{OrgID: 1, BucketID: 2, DestOrgID: 3, Days: 1},
+	} {
+		if _, err := s.CreateBucketCloneJob(context.Background(), req); platform.ErrorCode(err) != platform.EInvalid {
+			t.Errorf("expected an invalid error for %+v, got %v", req, err)
+		}
+	}
+
+	// The bucket cloned must be of the organization of the request.
+	_, err := s.CreateBucketCloneJob(context.Background(), platform.BucketCloneRequest{OrgID: 4, BucketID: 2, DestOrgID: 3, DestBucketName: "staging", Days: 1})
+	if platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func idPtr(id platform.ID) *platform.ID {
+	return &id
+}
@@ -0,0 +1,403 @@
+// Package bucketlifecycle materializes the lifecycle policies of buckets as the buckets and tasks
+// downsampling their data, in place of hand-written chains of downsampling tasks.
+package bucketlifecycle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"go.uber.org/zap"
+)
+
+var _ platform.BucketLifecycleService = (*Service)(nil)
+
+// statusRuns is the number of windows of a tier the latest run of its task is looked for in.
+const statusRuns = 10
+
+// PolicyStore stores the lifecycle policies of buckets as they are, along with
+// the buckets and tasks of their tiers.
+type PolicyStore interface {
+	FindBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecyclePolicy, error)
+	PutBucketLifecyclePolicy(ctx context.Context, p *platform.BucketLifecyclePolicy) error
+	DeleteBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) error
+}
+
+// Service materializes the lifecycle policies of buckets.
+//
+// The raw data of a bucket are kept for the retention period of the bucket, set to the raw retention
+// of its policy. Each tier is materialized as a bucket named after the bucket and the window of the tier,
+// such as telegraf_1m, and an active task downsampling the data of the tier before it into that bucket
+// every window. A bucket of that name that already exists, such as one written by hand-written tasks,
+// is adopted by the tier. The tasks are owned by, and run with the authorization of, the caller
+// setting the policy.
+type Service struct {
+	Store         PolicyStore
+	BucketService platform.BucketService
+	TaskService   platform.TaskService
+
+	Now    func() time.Time
+	Logger *zap.Logger
+
+	// mu serializes the changes of the policies, so that concurrent ones do not materialize the same tiers twice.
+	mu sync.Mutex
+}
+
+// NewService returns a Service storing the policies in store, and materializing them with bs and ts.
+func NewService(logger *zap.Logger, store PolicyStore, bs platform.BucketService, ts platform.TaskService) *Service {
+	return &Service{
+		Store:         store,
+		BucketService: bs,
+		TaskService:   ts,
+		Now:           time.Now,
+		Logger:        logger,
+	}
+}
+
+// FindBucketLifecyclePolicy returns the lifecycle policy of a bucket.
+func (s *Service) FindBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecyclePolicy, error) {
+	return s.Store.FindBucketLifecyclePolicy(ctx, bucketID)
+}
+
+// PutBucketLifecyclePolicy materializes the lifecycle policy of a bucket, and stores it.
+//
+// The buckets and tasks of the tiers of the previous policy of the bucket are reused by the tiers
+// of the same window, their task updated to downsample the data of the tier now before them.
+// The tasks of the tiers no longer in the policy are deleted, and their buckets kept, with their data.
+func (s *Service) PutBucketLifecyclePolicy(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.BucketService.FindBucketByID(ctx, p.BucketID)
+	if err != nil {
+		return err
+	}
+	if src.OrganizationID != p.OrgID {
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "bucket not found",
+		}
+	}
+
+	prev, err := s.Store.FindBucketLifecyclePolicy(ctx, p.BucketID)
+	if err != nil && platform.ErrorCode(err) != platform.ENotFound {
+		return err
+	}
+	managed := make(map[time.Duration]platform.BucketLifecycleTier)
+	if prev != nil {
+		for _, t := range prev.Tiers {
+			managed[t.Every] = t
+		}
+	}
+
+	if src.RetentionPeriod != p.RawRetention {
+		if _, err := s.BucketService.UpdateBucket(ctx, src.ID, platform.BucketUpdate{RetentionPeriod: &p.RawRetention}); err != nil {
+			return err
+		}
+	}
+
+	// The tasks created are deleted if the policy cannot be materialized entirely,
+	// while the buckets created are adopted when it is set again.
+	var created []platform.ID
+	from := src
+	for i := range p.Tiers {
+		t := &p.Tiers[i]
+		prevTier := managed[t.Every]
+		delete(managed, t.Every)
+
+		dest, err := s.materializeBucket(ctx, src, prevTier.BucketID, *t)
+		if err != nil {
+			s.deleteTasks(ctx, created)
+			return err
+		}
+		t.BucketID = dest.ID
+
+		taskID, isNew, err := s.materializeTask(ctx, from, dest, prevTier.TaskID, *t)
+		if err != nil {
+			s.deleteTasks(ctx, created)
+			return err
+		}
+		if isNew {
+			created = append(created, taskID)
+		}
+		t.TaskID = taskID
+
+		from = dest
+	}
+
+	for _, t := range managed {
+		if err := s.deleteTask(ctx, t.TaskID); err != nil {
+			return err
+		}
+	}
+
+	p.UpdatedAt = s.Now().UTC()
+	return s.Store.PutBucketLifecyclePolicy(ctx, p)
+}
+
+// materializeBucket returns the bucket of a tier, id if it still exists, with the retention period of the tier.
+func (s *Service) materializeBucket(ctx context.Context, src *platform.Bucket, id platform.ID, t platform.BucketLifecycleTier) (*platform.Bucket, error) {
+	var b *platform.Bucket
+	if id.Valid() {
+		var err error
+		b, err = s.BucketService.FindBucketByID(ctx, id)
+		if err != nil && platform.ErrorCode(err) != platform.ENotFound {
+			return nil, err
+		}
+	}
+
+	if b == nil {
+		name := tierBucketName(src.Name, t.Every)
+		var err error
+		b, err = s.BucketService.FindBucket(ctx, platform.BucketFilter{Name: &name, OrganizationID: &src.OrganizationID})
+		if err != nil && platform.ErrorCode(err) != platform.ENotFound {
+			return nil, err
+		}
+		if b == nil {
+			b = &platform.Bucket{
+				OrganizationID:  src.OrganizationID,
+				Name:            name,
+				RetentionPeriod: t.Retention,
+			}
+			if err := s.BucketService.CreateBucket(ctx, b); err != nil {
+				return nil, err
+			}
+			return b, nil
+		}
+	}
+
+	if b.RetentionPeriod != t.Retention {
+		return s.BucketService.UpdateBucket(ctx, b.ID, platform.BucketUpdate{RetentionPeriod: &t.Retention})
+	}
+	return b, nil
+}
+
+// materializeTask returns the active task downsampling the data of from into dest, id if it still exists,
+// and whether it was created.
+func (s *Service) materializeTask(ctx context.Context, from, dest *platform.Bucket, id platform.ID, t platform.BucketLifecycleTier) (platform.ID, bool, error) {
+	flux := tierScript(from, dest, t)
+	active := platform.TaskStatusActive
+
+	if id.Valid() {
+		task, err := s.findTask(ctx, id)
+		if err != nil {
+			return 0, false, err
+		}
+		if task != nil {
+			if _, err := s.TaskService.UpdateTask(ctx, id, platform.TaskUpdate{Flux: &flux, Status: &active}); err != nil {
+				return 0, false, err
+			}
+			return id, false, nil
+		}
+	}
+
+	task, err := s.TaskService.CreateTask(ctx, platform.TaskCreate{
+		OrganizationID: dest.OrganizationID,
+		Flux:           flux,
+		Status:         active,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return task.ID, true, nil
+}
+
+// findTask returns the task of id, nil if it does not exist.
+func (s *Service) findTask(ctx context.Context, id platform.ID) (*platform.Task, error) {
+	task, err := s.TaskService.FindTaskByID(ctx, id)
+	if isTaskNotFound(err) {
+		return nil, nil
+	}
+	return task, err
+}
+
+// deleteTask deletes the task of id, if it exists.
+func (s *Service) deleteTask(ctx context.Context, id platform.ID) error {
+	if !id.Valid() {
+		return nil
+	}
+	if err := s.TaskService.DeleteTask(ctx, id); err != nil && !isTaskNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteTasks deletes the tasks of a policy that could not be materialized, logging the errors.
+func (s *Service) deleteTasks(ctx context.Context, ids []platform.ID) {
+	for _, id := range ids {
+		if err := s.deleteTask(ctx, id); err != nil {
+			s.Logger.Warn("Failed to delete bucket lifecycle task", zap.Stringer("task_id", id), zap.Error(err))
+		}
+	}
+}
+
+func isTaskNotFound(err error) bool {
+	if err == backend.ErrTaskNotFound {
+		return true
+	}
+	if perr, ok := err.(*platform.Error); ok && perr.Err == backend.ErrTaskNotFound {
+		return true
+	}
+	return platform.ErrorCode(err) == platform.ENotFound
+}
+
+// DeleteBucketLifecyclePolicy removes the lifecycle policy of a bucket, along with the tasks of its tiers.
+// The buckets of its tiers are kept, with their data.
+func (s *Service) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.Store.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	for _, t := range p.Tiers {
+		if err := s.deleteTask(ctx, t.TaskID); err != nil {
+			return err
+		}
+	}
+	return s.Store.DeleteBucketLifecyclePolicy(ctx, bucketID)
+}
+
+// stateSeverity orders the states of tiers, the state of a policy being the most severe of those of its tiers.
+var stateSeverity = map[string]int{
+	platform.BucketLifecycleStateOK:       0,
+	platform.BucketLifecycleStateInactive: 1,
+	platform.BucketLifecycleStateFailing:  2,
+	platform.BucketLifecycleStateMissing:  3,
+}
+
+// FindBucketLifecycleStatus returns the status of the buckets and tasks of the tiers of the lifecycle policy of a bucket.
+func (s *Service) FindBucketLifecycleStatus(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecycleStatus, error) {
+	p, err := s.Store.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &platform.BucketLifecycleStatus{
+		BucketID:     p.BucketID,
+		OrgID:        p.OrgID,
+		State:        platform.BucketLifecycleStateOK,
+		RawRetention: p.RawRetention,
+		Tiers:        make([]platform.BucketLifecycleTierStatus, 0, len(p.Tiers)),
+	}
+	now := s.Now()
+	for _, t := range p.Tiers {
+		ts, err := s.tierStatus(ctx, t, now)
+		if err != nil {
+			return nil, err
+		}
+		if stateSeverity[ts.State] > stateSeverity[st.State] {
+			st.State = ts.State
+		}
+		st.Tiers = append(st.Tiers, *ts)
+	}
+	return st, nil
+}
+
+func (s *Service) tierStatus(ctx context.Context, t platform.BucketLifecycleTier, now time.Time) (*platform.BucketLifecycleTierStatus, error) {
+	ts := &platform.BucketLifecycleTierStatus{
+		BucketLifecycleTier: t,
+		State:               platform.BucketLifecycleStateOK,
+	}
+
+	b, err := s.BucketService.FindBucketByID(ctx, t.BucketID)
+	switch {
+	case err == nil:
+		ts.BucketName = b.Name
+	case platform.ErrorCode(err) == platform.ENotFound:
+		ts.State = platform.BucketLifecycleStateMissing
+	default:
+		return nil, err
+	}
+
+	task, err := s.findTask(ctx, t.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		ts.State = platform.BucketLifecycleStateMissing
+		return ts, nil
+	}
+	ts.TaskStatus = task.Status
+
+	runs, _, err := s.TaskService.FindRuns(ctx, platform.RunFilter{
+		Task:      t.TaskID,
+		AfterTime: now.Add(-statusRuns * t.Every).UTC().Format(time.RFC3339),
+	})
+	if err != nil && err != backend.ErrNoRunsFound {
+		return nil, err
+	}
+	for _, r := range runs {
+		if ts.LatestRun == nil || r.ScheduledFor > ts.LatestRun.ScheduledFor {
+			ts.LatestRun = r
+		}
+	}
+
+	switch {
+	case ts.State == platform.BucketLifecycleStateMissing:
+	case task.Status == platform.TaskStatusInactive:
+		ts.State = platform.BucketLifecycleStateInactive
+	case ts.LatestRun != nil && ts.LatestRun.Status == backend.RunFail.String():
+		ts.State = platform.BucketLifecycleStateFailing
+	}
+	return ts, nil
+}
+
+// tierBucketName returns the name of the bucket of a tier of the bucket src downsampling to windows of every.
+func tierBucketName(src string, every time.Duration) string {
+	return src + "_" + durationLiteral(every)
+}
+
+// tierScript returns the script of the task of a tier, downsampling the data of the last window
+// of from into dest, every window.
+func tierScript(from, dest *platform.Bucket, t platform.BucketLifecycleTier) string {
+	every := durationLiteral(t.Every)
+
+	return fmt.Sprintf(`option task = {name: %q, every: %s}
+
+from(bucketID: %q)
+  |> range(start: -task.every)
+  |> aggregateWindow(every: %s, fn: %s)
+  |> to(bucketID: %q, orgID: %q)
+`, "Downsample "+from.Name+" to "+dest.Name, every, from.ID.String(), every, tierFn(t.Fn), dest.ID.String(), dest.OrganizationID.String())
+}
+
+// tierFn returns the function aggregating the windows of a tier with fn. aggregateWindow calls it
+// with the columns to aggregate, which selectors do not take, so they are wrapped to select
+// from the first one, and their time dropped for it to be set to the end of the window.
+func tierFn(fn string) string {
+	switch fn {
+	case "":
+		return platform.DefaultBucketLifecycleFn
+	case "min", "max", "first", "last":
+		return fmt.Sprintf(`(columns, tables=<-) => tables |> %s(column: columns[0]) |> drop(columns: ["_time"])`, fn)
+	default:
+		return fn
+	}
+}
+
+// durationLiteral returns the Flux duration literal of d, a whole number of seconds, in its largest unit.
+func durationLiteral(d time.Duration) string {
+	for _, u := range []struct {
+		d    time.Duration
+		unit string
+	}{
+		{d: 24 * time.Hour, unit: "d"},
+		{d: time.Hour, unit: "h"},
+		{d: time.Minute, unit: "m"},
+	} {
+		if d%u.d == 0 {
+			return strconv.FormatInt(int64(d/u.d), 10) + u.unit
+		}
+	}
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+}
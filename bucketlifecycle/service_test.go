@@ -0,0 +1,389 @@
+package bucketlifecycle_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bucketlifecycle"
+	"github.com/influxdata/influxdb/mock"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/options"
+	"go.uber.org/zap/zaptest"
+)
+
+type policyStore map[platform.ID]platform.BucketLifecyclePolicy
+
+func (s policyStore) FindBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecyclePolicy, error) {
+	p, ok := s[bucketID]
+	if !ok {
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrBucketLifecyclePolicyNotFound}
+	}
+	p.Tiers = append([]platform.BucketLifecycleTier(nil), p.Tiers...)
+	return &p, nil
+}
+
+func (s policyStore) PutBucketLifecyclePolicy(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+	s[p.BucketID] = *p
+	return nil
+}
+
+func (s policyStore) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) error {
+	delete(s, bucketID)
+	return nil
+}
+
+// fixture is an organization of buckets and tasks, kept in maps.
+type fixture struct {
+	buckets map[platform.ID]*platform.Bucket
+	tasks   map[platform.ID]*platform.Task
+	runs    map[platform.ID][]*platform.Run
+	nextID  platform.ID
+
+	// failCreateTask, if set, fails the creation of tasks with a script containing it.
+	failCreateTask string
+}
+
+func newFixture() *fixture {
+	return &fixture{
+		buckets: map[platform.ID]*platform.Bucket{
+			1: {ID: 1, OrganizationID: 10, Name: "telegraf", RetentionPeriod: 30 * 24 * time.Hour},
+		},
+		tasks:  make(map[platform.ID]*platform.Task),
+		runs:   make(map[platform.ID][]*platform.Run),
+		nextID: 100,
+	}
+}
+
+func (f *fixture) id() platform.ID {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *fixture) service(t *testing.T, store policyStore) *bucketlifecycle.Service {
+	notFound := &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		b, ok := f.buckets[id]
+		if !ok {
+			return nil, notFound
+		}
+		c := *b
+		return &c, nil
+	}
+	bs.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		for _, b := range f.buckets {
+			if b.Name == *filter.Name && b.OrganizationID == *filter.OrganizationID {
+				c := *b
+				return &c, nil
+			}
+		}
+		return nil, notFound
+	}
+	bs.CreateBucketFn = func(ctx context.Context, b *platform.Bucket) error {
+		b.ID = f.id()
+		c := *b
+		f.buckets[b.ID] = &c
+		return nil
+	}
+	bs.UpdateBucketFn = func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+		b, ok := f.buckets[id]
+		if !ok {
+			return nil, notFound
+		}
+		if upd.RetentionPeriod != nil {
+			b.RetentionPeriod = *upd.RetentionPeriod
+		}
+		c := *b
+		return &c, nil
+	}
+
+	ts := &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			task, ok := f.tasks[id]
+			if !ok {
+				return nil, backend.ErrTaskNotFound
+			}
+			return task, nil
+		},
+		CreateTaskFn: func(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+			if f.failCreateTask != "" && strings.Contains(tc.Flux, f.failCreateTask) {
+				return nil, errors.New("task quota reached")
+			}
+			task := &platform.Task{ID: f.id(), OrganizationID: tc.OrganizationID, Flux: tc.Flux, Status: tc.Status}
+			f.tasks[task.ID] = task
+			return task, nil
+		},
+		UpdateTaskFn: func(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+			task, ok := f.tasks[id]
+			if !ok {
+				return nil, backend.ErrTaskNotFound
+			}
+			task.Flux, task.Status = *upd.Flux, *upd.Status
+			return task, nil
+		},
+		DeleteTaskFn: func(ctx context.Context, id platform.ID) error {
+			if _, ok := f.tasks[id]; !ok {
+				return backend.ErrTaskNotFound
+			}
+			delete(f.tasks, id)
+			return nil
+		},
+		FindRunsFn: func(ctx context.Context, filter platform.RunFilter) ([]*platform.Run, int, error) {
+			runs := f.runs[filter.Task]
+			return runs, len(runs), nil
+		},
+	}
+
+	s := bucketlifecycle.NewService(zaptest.NewLogger(t), store, bs, ts)
+	s.Now = func() time.Time { return time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func (f *fixture) bucketByName(name string) *platform.Bucket {
+	for _, b := range f.buckets {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+const day = 24 * time.Hour
+
+func newPolicy() *platform.BucketLifecyclePolicy {
+	return &platform.BucketLifecyclePolicy{
+		BucketID:     1,
+		OrgID:        10,
+		RawRetention: 7 * day,
+		Tiers: []platform.BucketLifecycleTier{
+			{Every: time.Minute, Retention: 90 * day},
+			{Every: time.Hour, Retention: 730 * day, Fn: "max"},
+		},
+	}
+}
+
+func TestService_PutBucketLifecyclePolicy(t *testing.T) {
+	f := newFixture()
+	store := policyStore{}
+	s := f.service(t, store)
+	ctx := context.Background()
+
+	p := newPolicy()
+	if err := s.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.buckets[1].RetentionPeriod; got != 7*day {
+		t.Errorf("raw retention is %s, want %s", got, 7*day)
+	}
+	minutes, hours := f.bucketByName("telegraf_1m"), f.bucketByName("telegraf_1h")
+	if minutes == nil || hours == nil {
+		t.Fatal("expected the buckets telegraf_1m and telegraf_1h to be created")
+	}
+	if minutes.RetentionPeriod != 90*day || hours.RetentionPeriod != 730*day {
+		t.Errorf("unexpected retention periods %s and %s of the tier buckets", minutes.RetentionPeriod, hours.RetentionPeriod)
+	}
+	if p.Tiers[0].BucketID != minutes.ID || p.Tiers[1].BucketID != hours.ID {
+		t.Errorf("the tiers of the policy are not set with their buckets: %+v", p.Tiers)
+	}
+	if stored := store[1]; len(stored.Tiers) != 2 || stored.Tiers[1].TaskID != p.Tiers[1].TaskID || stored.UpdatedAt.IsZero() {
+		t.Errorf("unexpected stored policy %+v", stored)
+	}
+
+	// Each tier downsamples the tier before it, every window.
+	for i, want := range []struct {
+		every    time.Duration
+		from, to platform.ID
+		fn       string
+	}{
+		{every: time.Minute, from: 1, to: minutes.ID, fn: "mean"},
+		{every: time.Hour, from: minutes.ID, to: hours.ID, fn: `(columns, tables=<-) => tables |> max(column: columns[0]) |> drop(columns: ["_time"])`},
+	} {
+		task := f.tasks[p.Tiers[i].TaskID]
+		if task == nil {
+			t.Fatalf("task of tier %d not created", i)
+		}
+		if task.Status != platform.TaskStatusActive {
+			t.Errorf("task of tier %d is %s", i, task.Status)
+		}
+		opts, err := options.FromScript(task.Flux)
+		if err != nil {
+			t.Fatalf("invalid script of tier %d: %v\n%s", i, err, task.Flux)
+		}
+		if time.Duration(opts.Every) != want.every {
+			t.Errorf("task of tier %d runs every %s, want %s", i, opts.Every, want.every)
+		}
+		for _, s := range []string{
+			`from(bucketID: "` + want.from.String() + `")`,
+			"fn: " + want.fn + ")",
+			`to(bucketID: "` + want.to.String() + `", orgID: "` + platform.ID(10).String() + `")`,
+		} {
+			if !strings.Contains(task.Flux, s) {
+				t.Errorf("script of tier %d does not contain %s:\n%s", i, s, task.Flux)
+			}
+		}
+	}
+
+	// Setting the policy again with the 1m tier removed keeps the 1h tier, downsampling the raw data now.
+	hourTask := p.Tiers[1].TaskID
+	minuteTask := p.Tiers[0].TaskID
+	p = newPolicy()
+	p.Tiers = p.Tiers[1:]
+	p.Tiers[0].Retention = 365 * day
+	if err := s.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tiers[0].TaskID != hourTask || p.Tiers[0].BucketID != hours.ID {
+		t.Errorf("the 1h tier was not reused: %+v", p.Tiers[0])
+	}
+	if _, ok := f.tasks[minuteTask]; ok {
+		t.Error("the task of the removed tier was not deleted")
+	}
+	if f.bucketByName("telegraf_1m") == nil {
+		t.Error("the bucket of the removed tier was deleted")
+	}
+	if got := f.buckets[hours.ID].RetentionPeriod; got != 365*day {
+		t.Errorf("retention of the 1h tier is %s, want %s", got, 365*day)
+	}
+	if !strings.Contains(f.tasks[hourTask].Flux, `from(bucketID: "`+platform.ID(1).String()+`")`) {
+		t.Errorf("the 1h tier does not downsample the raw data:\n%s", f.tasks[hourTask].Flux)
+	}
+}
+
+func TestService_PutBucketLifecyclePolicy_AdoptsBucket(t *testing.T) {
+	f := newFixture()
+	f.buckets[2] = &platform.Bucket{ID: 2, OrganizationID: 10, Name: "telegraf_1m"}
+	s := f.service(t, policyStore{})
+
+	p := newPolicy()
+	if err := s.PutBucketLifecyclePolicy(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tiers[0].BucketID != 2 {
+		t.Errorf("the existing bucket telegraf_1m was not adopted, got bucket %s", p.Tiers[0].BucketID)
+	}
+	if got := f.buckets[2].RetentionPeriod; got != 90*day {
+		t.Errorf("retention of the adopted bucket is %s, want %s", got, 90*day)
+	}
+}
+
+func TestService_PutBucketLifecyclePolicy_Errors(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		s := newFixture().service(t, policyStore{})
+		p := newPolicy()
+		p.Tiers[1].Every = 90 * time.Second
+		if err := s.PutBucketLifecyclePolicy(context.Background(), p); platform.ErrorCode(err) != platform.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+	})
+
+	t.Run("bucket of another organization", func(t *testing.T) {
+		s := newFixture().service(t, policyStore{})
+		p := newPolicy()
+		p.OrgID = 11
+		if err := s.PutBucketLifecyclePolicy(context.Background(), p); platform.ErrorCode(err) != platform.ENotFound {
+			t.Fatalf("expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("task creation fails", func(t *testing.T) {
+		f := newFixture()
+		f.failCreateTask = "every: 1h"
+		store := policyStore{}
+		s := f.service(t, store)
+		if err := s.PutBucketLifecyclePolicy(context.Background(), newPolicy()); err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(f.tasks) != 0 {
+			t.Errorf("the tasks created were not deleted: %v", f.tasks)
+		}
+		if len(store) != 0 {
+			t.Error("the policy was stored")
+		}
+	})
+}
+
+func TestService_FindBucketLifecycleStatus(t *testing.T) {
+	f := newFixture()
+	s := f.service(t, policyStore{})
+	ctx := context.Background()
+
+	p := newPolicy()
+	if err := s.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	minuteTask, hourTask := p.Tiers[0].TaskID, p.Tiers[1].TaskID
+	f.runs[minuteTask] = []*platform.Run{
+		{ID: 1, TaskID: minuteTask, Status: "failed", ScheduledFor: "2019-06-10T11:58:00Z"},
+		{ID: 2, TaskID: minuteTask, Status: "success", ScheduledFor: "2019-06-10T11:59:00Z"},
+	}
+	f.runs[hourTask] = []*platform.Run{
+		{ID: 3, TaskID: hourTask, Status: "failed", ScheduledFor: "2019-06-10T11:00:00Z"},
+	}
+
+	st, err := s.FindBucketLifecycleStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != platform.BucketLifecycleStateFailing {
+		t.Errorf("policy is %s, want %s", st.State, platform.BucketLifecycleStateFailing)
+	}
+	if got := st.Tiers[0]; got.State != platform.BucketLifecycleStateOK || got.BucketName != "telegraf_1m" || got.LatestRun == nil || got.LatestRun.ID != 2 {
+		t.Errorf("unexpected status of the 1m tier %+v", got)
+	}
+	if got := st.Tiers[1]; got.State != platform.BucketLifecycleStateFailing || got.TaskStatus != platform.TaskStatusActive {
+		t.Errorf("unexpected status of the 1h tier %+v", got)
+	}
+
+	// A deleted task is reported as missing, until the policy is set again.
+	delete(f.tasks, hourTask)
+	f.tasks[minuteTask].Status = platform.TaskStatusInactive
+	st, err = s.FindBucketLifecycleStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != platform.BucketLifecycleStateMissing || st.Tiers[0].State != platform.BucketLifecycleStateInactive || st.Tiers[1].State != platform.BucketLifecycleStateMissing {
+		t.Errorf("unexpected status %+v", st)
+	}
+
+	if err := s.PutBucketLifecyclePolicy(ctx, newPolicy()); err != nil {
+		t.Fatal(err)
+	}
+	st, err = s.FindBucketLifecycleStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Tiers[0].State != platform.BucketLifecycleStateOK || st.Tiers[1].TaskStatus != platform.TaskStatusActive {
+		t.Errorf("the policy set again did not restore its tiers: %+v", st)
+	}
+}
+
+func TestService_DeleteBucketLifecyclePolicy(t *testing.T) {
+	f := newFixture()
+	store := policyStore{}
+	s := f.service(t, store)
+	ctx := context.Background()
+
+	if err := s.PutBucketLifecyclePolicy(ctx, newPolicy()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteBucketLifecyclePolicy(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.tasks) != 0 || len(store) != 0 {
+		t.Errorf("tasks %v or policy not deleted", f.tasks)
+	}
+	if f.bucketByName("telegraf_1m") == nil || f.bucketByName("telegraf_1h") == nil {
+		t.Error("the buckets of the tiers were deleted")
+	}
+	if err := s.DeleteBucketLifecyclePolicy(ctx, 1); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+// bucketService retries finding buckets.
+type bucketService struct {
+	influxdb.BucketService
+	retry RetryPolicy
+}
+
+func (s *bucketService) FindBucketByID(ctx context.Context, id influxdb.ID) (b *influxdb.Bucket, err error) {
+	err = s.retry.do(ctx, func() error {
+		b, err = s.BucketService.FindBucketByID(ctx, id)
+		return err
+	})
+	return b, err
+}
+
+func (s *bucketService) FindBucket(ctx context.Context, filter influxdb.BucketFilter) (b *influxdb.Bucket, err error) {
+	err = s.retry.do(ctx, func() error {
+		b, err = s.BucketService.FindBucket(ctx, filter)
+		return err
+	})
+	return b, err
+}
+
+func (s *bucketService) FindBuckets(ctx context.Context, filter influxdb.BucketFilter, opt ...influxdb.FindOptions) (bs []*influxdb.Bucket, n int, err error) {
+	err = s.retry.do(ctx, func() error {
+		bs, n, err = s.BucketService.FindBuckets(ctx, filter, opt...)
+		return err
+	})
+	return bs, n, err
+}
+
+// ForEachBucket calls fn with every bucket matching filter, finding them pageSize at a time.
+// The page size is influxdb.DefaultPageSize if pageSize is 0. It stops at the first error of fn.
+func ForEachBucket(ctx context.Context, s influxdb.BucketService, filter influxdb.BucketFilter, pageSize int, fn func(*influxdb.Bucket) error) error {
+	if pageSize <= 0 {
+		pageSize = influxdb.DefaultPageSize
+	}
+
+	opt := influxdb.FindOptions{Limit: pageSize}
+	for {
+		bs, _, err := s.FindBuckets(ctx, filter, opt)
+		if err != nil {
+			return err
+		}
+		for _, b := range bs {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+		if len(bs) < pageSize {
+			return nil
+		}
+		opt.Offset += len(bs)
+	}
+}
+
+// FindAllBuckets returns every bucket matching filter, finding them pageSize at a time.
+func FindAllBuckets(ctx context.Context, s influxdb.BucketService, filter influxdb.BucketFilter, pageSize int) ([]*influxdb.Bucket, error) {
+	var buckets []*influxdb.Bucket
+	err := ForEachBucket(ctx, s, filter, pageSize, func(b *influxdb.Bucket) error {
+		buckets = append(buckets, b)
+		return nil
+	})
+	return buckets, err
+}
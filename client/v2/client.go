@@ -0,0 +1,98 @@
+// Package client is a typed Go client of the HTTP API of influxd.
+//
+// The services of a Client implement the service interfaces of the influxdb and query packages
+// on top of the HTTP clients of the http package, so that the same types are used on both sides
+// of the API. Requests that are safe to send again, such as finding buckets and tasks, writing
+// points and running queries, are retried when influxd is unavailable or cannot be reached.
+// The Find and ForEach functions page through all the buckets and tasks matching a filter.
+package client
+
+import (
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/query"
+)
+
+// Client is a client of the HTTP API of influxd.
+type Client struct {
+	Buckets influxdb.BucketService
+	Tasks   influxdb.TaskService
+	Writes  influxdb.WriteService
+	Queries query.QueryService
+}
+
+type options struct {
+	insecureSkipVerify bool
+	precision          string
+	retry              RetryPolicy
+}
+
+// Option changes the way a Client sends requests.
+type Option func(o *options)
+
+// WithInsecureSkipVerify makes the client accept any certificate presented by influxd.
+func WithInsecureSkipVerify() Option {
+	return func(o *options) {
+		o.insecureSkipVerify = true
+	}
+}
+
+// WithPrecision sets the precision of the timestamps of the points written, such as "s" or "ms".
+// It is "ns" by default.
+func WithPrecision(precision string) Option {
+	return func(o *options) {
+		o.precision = precision
+	}
+}
+
+// WithRetryPolicy replaces the DefaultRetryPolicy of the client.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// New returns a client of the influxd listening at addr, such as "http://localhost:9999",
+// authenticating its requests with token.
+func New(addr, token string, opts ...Option) *Client {
+	o := options{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Client{
+		Buckets: &bucketService{
+			BucketService: &http.BucketService{
+				Addr:               addr,
+				Token:              token,
+				InsecureSkipVerify: o.insecureSkipVerify,
+			},
+			retry: o.retry,
+		},
+		Tasks: &taskService{
+			TaskService: http.TaskService{
+				Addr:               addr,
+				Token:              token,
+				InsecureSkipVerify: o.insecureSkipVerify,
+			},
+			retry: o.retry,
+		},
+		Writes: &writeService{
+			WriteService: &http.WriteService{
+				Addr:               addr,
+				Token:              token,
+				Precision:          o.precision,
+				InsecureSkipVerify: o.insecureSkipVerify,
+			},
+			retry: o.retry,
+		},
+		Queries: &queryService{
+			QueryService: &http.FluxQueryService{
+				Addr:               addr,
+				Token:              token,
+				InsecureSkipVerify: o.insecureSkipVerify,
+			},
+			retry: o.retry,
+		},
+	}
+}
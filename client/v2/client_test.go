@@ -0,0 +1,162 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+}
+
+func TestRetryPolicy_do(t *testing.T) {
+	unavailable := &influxdb.Error{Code: influxdb.EUnavailable, Msg: "unavailable"}
+	invalid := &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid"}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  error
+		attempts int
+	}{
+		{name: "success", errs: []error{nil}, attempts: 1},
+		{name: "unavailable once", errs: []error{unavailable, nil}, attempts: 2},
+		{name: "unavailable until exhausted", errs: []error{unavailable, unavailable, unavailable, nil}, wantErr: unavailable, attempts: 3},
+		{name: "not retryable", errs: []error{invalid, nil}, wantErr: invalid, attempts: 1},
+		{name: "not an influxdb error", errs: []error{errors.New("oops"), nil}, wantErr: errors.New("oops"), attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := testRetryPolicy.do(context.Background(), func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestClient_WriteRetry(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":"unavailable","message":"engine is busy"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "token", WithRetryPolicy(testRetryPolicy))
+	if err := c.Writes.Write(context.Background(), 1, 2, strings.NewReader("m,t=a f=1 1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("got %d writes, want 2", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != "m,t=a f=1 1\n" {
+			t.Errorf("write %d sent %q", i, body)
+		}
+	}
+}
+
+func TestForEachBucket(t *testing.T) {
+	var buckets []*influxdb.Bucket
+	for i := 1; i <= 5; i++ {
+		buckets = append(buckets, &influxdb.Bucket{ID: influxdb.ID(i)})
+	}
+
+	s := mock.NewBucketService()
+	s.FindBucketsFn = func(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		start, end := opts[0].Offset, opts[0].Offset+opts[0].Limit
+		if start > len(buckets) {
+			start = len(buckets)
+		}
+		if end > len(buckets) {
+			end = len(buckets)
+		}
+		return buckets[start:end], end - start, nil
+	}
+
+	got, err := FindAllBuckets(context.Background(), s, influxdb.BucketFilter{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(buckets) {
+		t.Fatalf("got %d buckets, want %d", len(got), len(buckets))
+	}
+	for i := range got {
+		if got[i].ID != buckets[i].ID {
+			t.Errorf("bucket %d has ID %s, want %s", i, got[i].ID, buckets[i].ID)
+		}
+	}
+}
+
+func TestForEachTask(t *testing.T) {
+	var tasks []*influxdb.Task
+	for i := 1; i <= 5; i++ {
+		tasks = append(tasks, &influxdb.Task{ID: influxdb.ID(i)})
+	}
+
+	s := &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+			var page []*influxdb.Task
+			for _, t := range tasks {
+				if filter.After != nil && t.ID <= *filter.After {
+					continue
+				}
+				if len(page) == filter.Limit {
+					break
+				}
+				page = append(page, t)
+			}
+			return page, len(page), nil
+		},
+	}
+
+	got, err := FindAllTasks(context.Background(), s, influxdb.TaskFilter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(tasks) {
+		t.Fatalf("got %d tasks, want %d", len(got), len(tasks))
+	}
+	for i := range got {
+		if got[i].ID != tasks[i].ID {
+			t.Errorf("task %d has ID %s, want %s", i, got[i].ID, tasks[i].ID)
+		}
+	}
+}
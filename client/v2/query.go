@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// queryService retries sending queries. Once influxd responds, the results are not read again.
+type queryService struct {
+	query.QueryService
+	retry RetryPolicy
+}
+
+func (s *queryService) Query(ctx context.Context, req *query.Request) (itr flux.ResultIterator, err error) {
+	err = s.retry.do(ctx, func() error {
+		itr, err = s.QueryService.Query(ctx, req)
+		return err
+	})
+	return itr, err
+}
+
+// Query runs the Flux script in the organization orgID.
+// Release must be called on the returned results to free their resources.
+func (c *Client) Query(ctx context.Context, orgID influxdb.ID, script string) (flux.ResultIterator, error) {
+	return c.Queries.Query(ctx, &query.Request{
+		OrganizationID: orgID,
+		Compiler: lang.FluxCompiler{
+			Query: script,
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+// RetryPolicy is how many times and how often a request is sent again.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent before giving up.
+	// A request is sent once if MaxAttempts is less than 2.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before sending a request again for the first time.
+	// It doubles before every other attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// NoRetry sends every request only once.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// do calls fn until it succeeds, it fails with an error that is not retryable,
+// the attempts of p are exhausted or ctx is done.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// retryable returns true if err is returned by an influxd that is unavailable for now,
+// or if influxd could not be reached.
+func retryable(err error) bool {
+	switch err := err.(type) {
+	case *influxdb.Error:
+		return influxdb.ErrorCode(err) == influxdb.EUnavailable
+	case net.Error:
+		return true
+	default:
+		return false
+	}
+}
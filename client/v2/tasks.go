@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+// taskService retries finding tasks, their runs and their logs.
+type taskService struct {
+	influxdb.TaskService
+	retry RetryPolicy
+}
+
+func (s *taskService) FindTaskByID(ctx context.Context, id influxdb.ID) (t *influxdb.Task, err error) {
+	err = s.retry.do(ctx, func() error {
+		t, err = s.TaskService.FindTaskByID(ctx, id)
+		return err
+	})
+	return t, err
+}
+
+func (s *taskService) FindTasks(ctx context.Context, filter influxdb.TaskFilter) (ts []*influxdb.Task, n int, err error) {
+	err = s.retry.do(ctx, func() error {
+		ts, n, err = s.TaskService.FindTasks(ctx, filter)
+		return err
+	})
+	return ts, n, err
+}
+
+func (s *taskService) FindLogs(ctx context.Context, filter influxdb.LogFilter) (ls []*influxdb.Log, n int, err error) {
+	err = s.retry.do(ctx, func() error {
+		ls, n, err = s.TaskService.FindLogs(ctx, filter)
+		return err
+	})
+	return ls, n, err
+}
+
+func (s *taskService) FindRuns(ctx context.Context, filter influxdb.RunFilter) (rs []*influxdb.Run, n int, err error) {
+	err = s.retry.do(ctx, func() error {
+		rs, n, err = s.TaskService.FindRuns(ctx, filter)
+		return err
+	})
+	return rs, n, err
+}
+
+func (s *taskService) FindRunByID(ctx context.Context, taskID, runID influxdb.ID) (r *influxdb.Run, err error) {
+	err = s.retry.do(ctx, func() error {
+		r, err = s.TaskService.FindRunByID(ctx, taskID, runID)
+		return err
+	})
+	return r, err
+}
+
+func (s *taskService) FindTaskVersions(ctx context.Context, taskID influxdb.ID) (vs []*influxdb.TaskVersion, err error) {
+	err = s.retry.do(ctx, func() error {
+		vs, err = s.TaskService.FindTaskVersions(ctx, taskID)
+		return err
+	})
+	return vs, err
+}
+
+// ForEachTask calls fn with every task matching filter, finding them filter.Limit at a time, in the order of their IDs.
+// The page size is influxdb.TaskDefaultPageSize if filter.Limit is 0. It stops at the first error of fn.
+func ForEachTask(ctx context.Context, s influxdb.TaskService, filter influxdb.TaskFilter, fn func(*influxdb.Task) error) error {
+	if filter.Limit <= 0 {
+		filter.Limit = influxdb.TaskDefaultPageSize
+	}
+
+	for {
+		ts, _, err := s.FindTasks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+		if len(ts) < filter.Limit {
+			return nil
+		}
+		after := ts[len(ts)-1].ID
+		filter.After = &after
+	}
+}
+
+// FindAllTasks returns every task matching filter, finding them filter.Limit at a time.
+func FindAllTasks(ctx context.Context, s influxdb.TaskService, filter influxdb.TaskFilter) ([]*influxdb.Task, error) {
+	var tasks []*influxdb.Task
+	err := ForEachTask(ctx, s, filter, func(t *influxdb.Task) error {
+		tasks = append(tasks, t)
+		return nil
+	})
+	return tasks, err
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/influxdata/influxdb"
+)
+
+// writeService retries writing points. The points are read in memory first, to be sent again.
+// Writing the same points again is safe, as they replace the points written before.
+type writeService struct {
+	influxdb.WriteService
+	retry RetryPolicy
+}
+
+func (s *writeService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return s.retry.do(ctx, func() error {
+		return s.WriteService.Write(ctx, orgID, bucketID, bytes.NewReader(data))
+	})
+}
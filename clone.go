@@ -0,0 +1,214 @@
+package influxdb
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// CloneRequest describes how a resource is copied from its organization into another one.
+type CloneRequest struct {
+	// OrganizationID is the organization the clone is created in.
+	OrganizationID ID `json:"orgID,omitempty"`
+
+	// BucketMapping maps the buckets referenced by the source resource,
+	// by name or by ID, to the buckets the clone should reference instead.
+	// Buckets that are not in the mapping are left untouched.
+	BucketMapping map[string]string `json:"bucketMapping,omitempty"`
+}
+
+// Validate returns an error if the clone request is invalid.
+func (r CloneRequest) Validate() error {
+	if !r.OrganizationID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "clone requires a valid orgID",
+		}
+	}
+	return r.ValidateBucketMapping()
+}
+
+// ValidateBucketMapping returns an error if the bucket mapping of the clone request is invalid.
+// It lets a resource that can be cloned within its own organization validate a request without orgID.
+func (r CloneRequest) ValidateBucketMapping() error {
+	for from, to := range r.BucketMapping {
+		if from == "" || to == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "bucket mapping cannot contain empty bucket references",
+			}
+		}
+	}
+	return nil
+}
+
+// RewriteBucketReferences replaces the string literals passed as the bucket or bucketID
+// argument of any function call in the flux script, according to mapping.
+// Only the literals are replaced, so the rest of the script, including its formatting, is kept.
+func RewriteBucketReferences(script string, mapping map[string]string) (string, error) {
+	if len(mapping) == 0 {
+		return script, nil
+	}
+
+	pkg := parser.ParseSource(script)
+	if ast.Check(pkg) > 0 {
+		return "", &Error{
+			Code: EInvalid,
+			Err:  ast.GetError(pkg),
+		}
+	}
+
+	var lits []*ast.StringLiteral
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		for _, arg := range call.Arguments {
+			obj, ok := arg.(*ast.ObjectExpression)
+			if !ok {
+				continue
+			}
+			for _, p := range obj.Properties {
+				if k := p.Key.Key(); k != "bucket" && k != "bucketID" {
+					continue
+				}
+				if lit, ok := p.Value.(*ast.StringLiteral); ok && lit.Loc != nil {
+					if _, ok := mapping[lit.Value]; ok {
+						lits = append(lits, lit)
+					}
+				}
+			}
+		}
+	}), pkg)
+	if len(lits) == 0 {
+		return script, nil
+	}
+
+	// lineOffsets[i] is the byte offset of line i+1, as positions are 1-based lines and byte columns.
+	lineOffsets := []int{0}
+	for i := 0; i < len(script); i++ {
+		if script[i] == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	offset := func(p ast.Position) int {
+		return lineOffsets[p.Line-1] + p.Column - 1
+	}
+
+	// Replace from the end of the script so that the offsets of the remaining literals stay valid.
+	sort.Slice(lits, func(i, j int) bool {
+		return offset(lits[i].Loc.Start) > offset(lits[j].Loc.Start)
+	})
+	for _, lit := range lits {
+		quoted := `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(mapping[lit.Value]) + `"`
+		script = script[:offset(lit.Loc.Start)] + quoted + script[offset(lit.Loc.End):]
+	}
+	return script, nil
+}
+
+// CloneDashboard copies the dashboard with id, along with its cells and their views,
+// into the organization of the request, rewriting the bucket references of every query.
+// The copy is deleted if any of its cells fails to be added.
+func CloneDashboard(ctx context.Context, s DashboardService, id ID, req CloneRequest) (*Dashboard, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	src, err := s.FindDashboardByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*View, 0, len(src.Cells))
+	for _, c := range src.Cells {
+		v, err := s.GetDashboardCellView(ctx, src.ID, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		props, err := rewriteViewProperties(v.Properties, req.BucketMapping)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, &View{
+			ViewContents: ViewContents{Name: v.Name},
+			Properties:   props,
+		})
+	}
+
+	d := &Dashboard{
+		OrganizationID: req.OrganizationID,
+		Name:           src.Name,
+		Description:    src.Description,
+	}
+	if err := s.CreateDashboard(ctx, d); err != nil {
+		return nil, err
+	}
+
+	for i, c := range src.Cells {
+		cell := &Cell{X: c.X, Y: c.Y, W: c.W, H: c.H}
+		if err := s.AddDashboardCell(ctx, d.ID, cell, AddDashboardCellOptions{View: views[i]}); err != nil {
+			// Do not leave a partial clone behind. The error of the cell is the one reported.
+			_ = s.DeleteDashboard(ctx, d.ID)
+			return nil, err
+		}
+	}
+
+	return s.FindDashboardByID(ctx, d.ID)
+}
+
+// rewriteViewProperties returns a copy of the view properties with the bucket references of their queries rewritten.
+func rewriteViewProperties(props ViewProperties, mapping map[string]string) (ViewProperties, error) {
+	var err error
+	switch p := props.(type) {
+	case XYViewProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	case LinePlusSingleStatProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	case SingleStatViewProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	case HistogramViewProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	case GaugeViewProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	case TableViewProperties:
+		p.Queries, err = rewriteQueries(p.Queries, mapping)
+		return p, err
+	}
+	return props, nil
+}
+
+func rewriteQueries(qs []DashboardQuery, mapping map[string]string) ([]DashboardQuery, error) {
+	if len(mapping) == 0 || len(qs) == 0 {
+		return qs, nil
+	}
+
+	out := make([]DashboardQuery, len(qs))
+	for i, q := range qs {
+		text, err := RewriteBucketReferences(q.Text, mapping)
+		if err != nil {
+			return nil, err
+		}
+		q.Text = text
+
+		buckets := make([]string, len(q.BuilderConfig.Buckets))
+		for j, b := range q.BuilderConfig.Buckets {
+			if to, ok := mapping[b]; ok {
+				b = to
+			}
+			buckets[j] = b
+		}
+		q.BuilderConfig.Buckets = buckets
+
+		out[i] = q
+	}
+	return out, nil
+}
@@ -0,0 +1,230 @@
+package influxdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestRewriteBucketReferences(t *testing.T) {
+	mapping := map[string]string{
+		"staging":          "production",
+		"020f755c3c082000": "020f755c3c082001",
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "from and to",
+			script: `from(bucket: "staging") |> range(start: -1h) |> to(bucket: "staging", org: "o")`,
+			want:   `from(bucket: "production") |> range(start: -1h) |> to(bucket: "production", org: "o")`,
+		},
+		{
+			name:   "bucket ID",
+			script: `from(bucketID: "020f755c3c082000") |> range(start: -1h)`,
+			want:   `from(bucketID: "020f755c3c082001") |> range(start: -1h)`,
+		},
+		{
+			name:   "unmapped bucket is left untouched",
+			script: `from(bucket:"other")|>range(start:-1h)`,
+			want:   `from(bucket:"other")|>range(start:-1h)`,
+		},
+		{
+			name:   "only bucket arguments are rewritten",
+			script: `from(bucket: "other") |> range(start: -1h) |> filter(fn: (r) => r.bucket == "staging")`,
+			want:   `from(bucket: "other") |> range(start: -1h) |> filter(fn: (r) => r.bucket == "staging")`,
+		},
+		{
+			name: "formatting and comments are kept",
+			script: `// promoted from staging
+from(bucket:"staging")
+  |> range(start: -1h)
+  |> to(bucket:   "staging")`,
+			want: `// promoted from staging
+from(bucket:"production")
+  |> range(start: -1h)
+  |> to(bucket:   "production")`,
+		},
+		{
+			name:    "invalid script",
+			script:  `from(bucket: "staging")) |> range(start: -1h)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := platform.RewriteBucketReferences(tt.script, mapping)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RewriteBucketReferences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RewriteBucketReferences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneRequest_Validate(t *testing.T) {
+	if err := (platform.CloneRequest{}).Validate(); err == nil {
+		t.Error("expected an error for a clone request without orgID")
+	}
+	req := platform.CloneRequest{OrganizationID: 1, BucketMapping: map[string]string{"staging": ""}}
+	if err := req.Validate(); err == nil {
+		t.Error("expected an error for a bucket mapping to an empty bucket")
+	}
+	req.BucketMapping["staging"] = "production"
+	if err := req.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	req = platform.CloneRequest{BucketMapping: map[string]string{"staging": "production"}}
+	if err := req.ValidateBucketMapping(); err != nil {
+		t.Errorf("unexpected error validating the bucket mapping of a clone request without orgID: %v", err)
+	}
+	req.BucketMapping[""] = "production"
+	if err := req.ValidateBucketMapping(); err == nil {
+		t.Error("expected an error for a bucket mapping from an empty bucket")
+	}
+}
+
+func TestCloneDashboard(t *testing.T) {
+	ctx := context.Background()
+	s := kv.NewService(inmem.NewKVStore())
+	if err := s.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+
+	src := &platform.Dashboard{
+		OrganizationID: 1,
+		Name:           "ops",
+		Description:    "validated in staging",
+	}
+	if err := s.CreateDashboard(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	s.IDGenerator = mock.NewIDGenerator("020f755c3c082001", t)
+	view := &platform.View{
+		ViewContents: platform.ViewContents{Name: "cpu"},
+		Properties: platform.XYViewProperties{
+			Type: "xy",
+			Queries: []platform.DashboardQuery{
+				{
+					Text:          `from(bucket: "staging") |> range(start: -1h)`,
+					BuilderConfig: platform.BuilderConfig{Buckets: []string{"staging"}},
+				},
+			},
+		},
+	}
+	if err := s.AddDashboardCell(ctx, src.ID, &platform.Cell{X: 1, Y: 2, W: 3, H: 4}, platform.AddDashboardCellOptions{View: view}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.IDGenerator = mock.NewIDGenerator("020f755c3c082002", t)
+	clone, err := platform.CloneDashboard(ctx, s, src.ID, platform.CloneRequest{
+		OrganizationID: 2,
+		BucketMapping:  map[string]string{"staging": "production"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.ID == src.ID || clone.OrganizationID != 2 || clone.Name != src.Name || clone.Description != src.Description {
+		t.Fatalf("unexpected clone: %+v", clone)
+	}
+	if len(clone.Cells) != 1 {
+		t.Fatalf("expected 1 cell, got %d", len(clone.Cells))
+	}
+	if c := clone.Cells[0]; c.X != 1 || c.Y != 2 || c.W != 3 || c.H != 4 {
+		t.Errorf("unexpected cell position: %+v", c)
+	}
+
+	v, err := s.GetDashboardCellView(ctx, clone.ID, clone.Cells[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []platform.DashboardQuery{
+		{
+			Text:          `from(bucket: "production") |> range(start: -1h)`,
+			BuilderConfig: platform.BuilderConfig{Buckets: []string{"production"}},
+		},
+	}
+	if diff := cmp.Diff(v.Properties.(platform.XYViewProperties).Queries, want); diff != "" {
+		t.Errorf("cloned queries are different -got/+want\ndiff %s", diff)
+	}
+
+	// The source dashboard is left untouched.
+	src, err = s.FindDashboardByID(ctx, src.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = s.GetDashboardCellView(ctx, src.ID, src.Cells[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Properties.(platform.XYViewProperties).Queries[0].Text; got != `from(bucket: "staging") |> range(start: -1h)` {
+		t.Errorf("source query was modified: %s", got)
+	}
+}
+
+func TestCloneDashboard_CellFailure(t *testing.T) {
+	ctx := context.Background()
+	s := kv.NewService(inmem.NewKVStore())
+	if err := s.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+
+	src := &platform.Dashboard{OrganizationID: 1, Name: "ops"}
+	if err := s.CreateDashboard(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"020f755c3c082001", "020f755c3c082002"} {
+		s.IDGenerator = mock.NewIDGenerator(id, t)
+		view := &platform.View{
+			ViewContents: platform.ViewContents{Name: "cpu"},
+			Properties:   platform.XYViewProperties{Type: "xy"},
+		}
+		if err := s.AddDashboardCell(ctx, src.ID, &platform.Cell{Y: int32(i)}, platform.AddDashboardCellOptions{View: view}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The second cell of the clone fails to be added.
+	ds := mock.NewDashboardService()
+	ds.FindDashboardByIDF = s.FindDashboardByID
+	ds.GetDashboardCellViewF = s.GetDashboardCellView
+	ds.CreateDashboardF = s.CreateDashboard
+	ds.DeleteDashboardF = s.DeleteDashboard
+	var cells int
+	ds.AddDashboardCellF = func(ctx context.Context, id platform.ID, c *platform.Cell, opts platform.AddDashboardCellOptions) error {
+		if cells++; cells == 2 {
+			return &platform.Error{Code: platform.EInternal, Msg: "cell failed"}
+		}
+		return s.AddDashboardCell(ctx, id, c, opts)
+	}
+
+	s.IDGenerator = mock.NewIDGenerator("020f755c3c082003", t)
+	if _, err := platform.CloneDashboard(ctx, ds, src.ID, platform.CloneRequest{OrganizationID: 2}); platform.ErrorMessage(err) != "cell failed" {
+		t.Fatalf("expected the error of the cell, got %v", err)
+	}
+
+	orgID := platform.ID(2)
+	dashes, _, err := s.FindDashboards(ctx, platform.DashboardFilter{OrganizationID: &orgID}, platform.DefaultDashboardFindOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dashes) != 0 {
+		t.Errorf("expected the partial clone to be deleted, got %d dashboards", len(dashes))
+	}
+}
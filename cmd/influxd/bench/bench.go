@@ -0,0 +1,204 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1/bench"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates the new command.
+func NewCommand() *cobra.Command {
+	base := &cobra.Command{
+		Use:   "bench",
+		Short: "Commands for benchmarking the storage engine",
+	}
+
+	runCommand := &cobra.Command{
+		Use:   "run",
+		Short: "Run the storage engine workloads",
+		Long: `
+This command runs standardized workloads against a new storage engine in a
+temporary directory, and outputs their results. The workloads are, in order:
+
+	* ingest: the points are indexed and written to the cache of the engine, in
+	  values per second;
+	* snapshot: the cache is written to TSM files, in bytes per second;
+	* compaction: the TSM files are fully compacted, in bytes per second; and
+	* query: every value of every series is read, --queries times, in values per
+	  second.
+
+The results are written as JSON to the --out file, to be used as the baseline of
+later runs. With the --baseline flag, the results are compared with those of a
+baseline file run with the same data set flags, and the command fails if the
+rate of a workload decreased by more than the --threshold.
+
+NOTES:
+
+* This tool is intended for development and testing purposes only. The results
+  of runs on different machines should not be compared.`,
+		Args: cobra.NoArgs,
+		RunE: benchRunF,
+	}
+
+	cfg := bench.DefaultConfig()
+	runCommand.Flags().IntVarP(&runFlags.cfg.Series, "series", "", cfg.Series, "number of series of the points.")
+	runCommand.Flags().IntVarP(&runFlags.cfg.PointsPerSeries, "points-per-series", "", cfg.PointsPerSeries, "number of points written to each series.")
+	runCommand.Flags().IntVarP(&runFlags.cfg.BatchSize, "batch-size", "", cfg.BatchSize, "number of points of each write.")
+	runCommand.Flags().IntVarP(&runFlags.cfg.Snapshots, "snapshots", "", cfg.Snapshots, "number of snapshots of the points, and so of TSM files compacted.")
+	runCommand.Flags().IntVarP(&runFlags.cfg.Queries, "queries", "", cfg.Queries, "number of times every series is read.")
+	runCommand.Flags().StringVarP(&runFlags.dir, "dir", "", "", "run the engine in this empty directory, instead of a temporary directory.")
+	runCommand.Flags().StringVarP(&runFlags.out, "out", "", "", "write the results as JSON to this file.")
+	runCommand.Flags().StringVarP(&runFlags.baseline, "baseline", "", "", "compare the results with those of this file.")
+	runCommand.Flags().Float64VarP(&runFlags.threshold, "threshold", "", bench.DefaultThreshold, "relative decrease of the rate of a workload over which it regressed, such as 0.1 for 10%.")
+
+	base.AddCommand(runCommand)
+
+	compareCommand := &cobra.Command{
+		Use:   "compare <baseline> <current>",
+		Short: "Compare the results of two runs of the storage engine workloads",
+		Long: `
+This command compares the results of two runs of the storage engine workloads,
+written by the run command, and fails if the rate of a workload of the current
+results decreased by more than the --threshold from the baseline. Both runs must
+have been run with the same data set flags.`,
+		Args: cobra.ExactArgs(2),
+		RunE: benchCompareF,
+	}
+
+	compareCommand.Flags().Float64VarP(&compareFlags.threshold, "threshold", "", bench.DefaultThreshold, "relative decrease of the rate of a workload over which it regressed, such as 0.1 for 10%.")
+
+	base.AddCommand(compareCommand)
+	return base
+}
+
+// runFlags defines the `run` Command.
+var runFlags = struct {
+	cfg       bench.Config
+	dir       string
+	out       string
+	baseline  string
+	threshold float64
+}{}
+
+// benchRunF runs the run tool.
+func benchRunF(cmd *cobra.Command, args []string) error {
+	if err := runFlags.cfg.Validate(); err != nil {
+		return err
+	}
+
+	// The baseline is read first, not to run the workloads for nothing.
+	var baseline *bench.Report
+	if runFlags.baseline != "" {
+		r, err := readReport(runFlags.baseline)
+		if err != nil {
+			return err
+		}
+		baseline = r
+	}
+
+	dir := runFlags.dir
+	if dir == "" {
+		tmp, err := ioutil.TempDir("", "influxd-bench-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+
+	report, err := bench.Run(context.Background(), dir, runFlags.cfg)
+	if err != nil {
+		return err
+	}
+	printReport(os.Stdout, report)
+
+	if runFlags.out != "" {
+		if err := writeReport(runFlags.out, report); err != nil {
+			return err
+		}
+	}
+
+	if baseline == nil {
+		return nil
+	}
+	return compare(os.Stdout, baseline, report, runFlags.threshold)
+}
+
+// compareFlags defines the `compare` Command.
+var compareFlags = struct {
+	threshold float64
+}{}
+
+// benchCompareF runs the compare tool.
+func benchCompareF(cmd *cobra.Command, args []string) error {
+	baseline, err := readReport(args[0])
+	if err != nil {
+		return err
+	}
+	current, err := readReport(args[1])
+	if err != nil {
+		return err
+	}
+	return compare(os.Stdout, baseline, current, compareFlags.threshold)
+}
+
+// compare prints the comparison of current with baseline, and returns an error if a workload regressed.
+func compare(w io.Writer, baseline, current *bench.Report, threshold float64) error {
+	cs, err := bench.Compare(baseline, current, threshold)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Workload\tBaseline\tCurrent\tChange\t")
+	for _, c := range cs {
+		status := ""
+		if c.Regressed {
+			status = "REGRESSED"
+		}
+		fmt.Fprintf(tw, "%s\t%.0f/s\t%.0f/s\t%+.1f%%\t%s\n", c.Workload, c.Baseline, c.Current, 100*c.Change, status)
+	}
+	tw.Flush()
+
+	if bench.Regressed(cs) {
+		return errors.New("the storage engine workloads regressed")
+	}
+	return nil
+}
+
+func printReport(w io.Writer, r *bench.Report) {
+	tw := tabwriter.NewWriter(w, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Workload\tN\tDuration\tRate\tAllocated\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%d %s\t%s\t%.0f %s/s\t%d bytes\t\n", res.Workload, res.N, res.Unit, res.Duration, res.Rate, res.Unit, res.AllocatedBytes)
+	}
+	tw.Flush()
+}
+
+func readReport(path string) (*bench.Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bench.ReadReport(f)
+}
+
+func writeReport(path string, r *bench.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteReport(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -67,6 +67,70 @@ in the following ways:
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
 
 	base.AddCommand(reportTSMCommand)
+
+	diffSnapshotsCommand := &cobra.Command{
+		Use:   "diff-snapshots <before> <after>",
+		Short: "Compare two snapshots of the storage engine directory",
+		Long: `
+This command compares two snapshots of a storage engine directory, such as two
+copies of the engine directory taken at different points in time, to help
+diagnose unexplained disk growth between them.
+
+For each snapshot, the following is read, and the change between the snapshots
+is output:
+
+	* The series cardinality within the TSM files, after applying tombstones;
+	* The series cardinality for each bucket;
+	* The size of each entry at the root of the snapshot, such as the data,
+	  index and wal directories;
+	* The number and size of TSM files; and
+	* The number and size of tombstone files.
+
+By default, series cardinalities are estimated by using the HLL++ algorithm.
+Exact cardinalities can be determined by using the --exact flag.`,
+		Args: cobra.ExactArgs(2),
+		RunE: inspectDiffSnapshotsF,
+	}
+
+	diffSnapshotsCommand.Flags().BoolVarP(&diffSnapshotsFlags.exact, "exact", "", false, "calculate exact cardinality counts. Warning, may use significant memory...")
+	diffSnapshotsCommand.Flags().BoolVarP(&diffSnapshotsFlags.changedOnly, "changed-only", "", false, "only report the statistics that changed between the snapshots.")
+
+	base.AddCommand(diffSnapshotsCommand)
+
+	replayWALCommand := &cobra.Command{
+		Use:   "replay-wal <path>...",
+		Short: "Replay WAL segments, such as those of a snapshot",
+		Long: `
+This command reads the WAL segments of a snapshot of a storage engine directory,
+to recover the data they hold after a partial data loss. Each path is a WAL
+segment file, or a WAL directory of which every segment is read. The segments
+are never modified.
+
+The points of the segments are either:
+
+	* Written again to the buckets of a running instance, with the --host flag.
+	  The token must be allowed to write to every bucket of a segment, and the
+	  encrypted segments are decrypted with the keys of the instance; or
+	* Converted to line protocol, with the --output-dir flag. A file is written
+	  for each bucket, named after the IDs of its organization and bucket, which
+	  may be written to the bucket with the write API.
+
+Only the points of a bucket are replayed with the --bucket-id flag. The deletes
+of a segment only remove the points written before them in the segment, as
+replaying them could delete the data written since. The entries of a segment
+after its first corrupt entry are not read.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: inspectReplayWALF,
+	}
+
+	replayWALCommand.Flags().StringVarP(&replayWALFlags.outputDir, "output-dir", "", "", "convert the points to line protocol files in this directory.")
+	replayWALCommand.Flags().StringVarP(&replayWALFlags.host, "host", "", "", "write the points to the instance at this HTTP address, such as http://localhost:9999.")
+	replayWALCommand.Flags().StringVarP(&replayWALFlags.token, "token", "t", "", "token of the writes to the instance.")
+	replayWALCommand.Flags().BoolVarP(&replayWALFlags.skipVerify, "skip-verify", "", false, "skip the verification of the TLS certificate of the instance.")
+	replayWALCommand.Flags().StringVarP(&replayWALFlags.bucketID, "bucket-id", "", "", "replay only the points of this bucket ID.")
+	replayWALCommand.Flags().StringVarP(&replayWALFlags.encryptionKeyFile, "encryption-key-file", "", "", "decrypt the encrypted segments converted with the keys of this file.")
+
+	base.AddCommand(replayWALCommand)
 	return base
 }
 
@@ -114,3 +178,23 @@ func inspectReportTSMF(cmd *cobra.Command, args []string) error {
 	_, err := report.Run(true)
 	return err
 }
+
+// diffSnapshotsFlags defines the `diff-snapshots` Command.
+var diffSnapshotsFlags = struct {
+	exact       bool
+	changedOnly bool
+}{}
+
+// inspectDiffSnapshotsF runs the diff-snapshots tool.
+func inspectDiffSnapshotsF(cmd *cobra.Command, args []string) error {
+	diff := &tsm1.SnapshotDiff{
+		Stdout:      os.Stdout,
+		Before:      args[0],
+		After:       args[1],
+		Exact:       diffSnapshotsFlags.exact,
+		ChangedOnly: diffSnapshotsFlags.changedOnly,
+	}
+
+	_, err := diff.Run()
+	return err
+}
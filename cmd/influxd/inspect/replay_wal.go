@@ -0,0 +1,206 @@
+package inspect
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/spf13/cobra"
+)
+
+// replayWALFlags defines the `replay-wal` Command.
+var replayWALFlags = struct {
+	outputDir string
+
+	host       string
+	token      string
+	skipVerify bool
+
+	bucketID          string
+	encryptionKeyFile string
+}{}
+
+// inspectReplayWALF runs the replay-wal tool.
+func inspectReplayWALF(cmd *cobra.Command, args []string) error {
+	if (replayWALFlags.outputDir == "") == (replayWALFlags.host == "") {
+		return errors.New("exactly one of output-dir and host must be set")
+	}
+
+	var bucket *influxdb.ID
+	if replayWALFlags.bucketID != "" {
+		id, err := influxdb.IDFromString(replayWALFlags.bucketID)
+		if err != nil {
+			return err
+		}
+		bucket = id
+	}
+
+	files, err := walSegmentFiles(args)
+	if err != nil {
+		return err
+	}
+
+	if replayWALFlags.host != "" {
+		if replayWALFlags.encryptionKeyFile != "" {
+			return errors.New("encryption-key-file cannot be set with host, the segments are decrypted with the keys of the server")
+		}
+		svc := &http.WriteService{
+			Addr:               replayWALFlags.host,
+			Token:              replayWALFlags.token,
+			InsecureSkipVerify: replayWALFlags.skipVerify,
+		}
+		return replayWALSegments(svc, files, bucket)
+	}
+
+	var keyring *encryption.Keyring
+	if replayWALFlags.encryptionKeyFile != "" {
+		if keyring, err = encryption.NewKeyringFromProvider(context.Background(), encryption.KeyFile(replayWALFlags.encryptionKeyFile)); err != nil {
+			return fmt.Errorf("error loading encryption keys: %v", err)
+		}
+	}
+	return convertWALSegments(replayWALFlags.outputDir, files, bucket, keyring)
+}
+
+// walSegmentFiles returns the WAL segment files of paths, each a segment file or a WAL directory.
+func walSegmentFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		names, err := wal.SegmentFileNames(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, names...)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no WAL segment files found")
+	}
+	return files, nil
+}
+
+// replayWALSegments writes the points of the segment files to the buckets of the server of svc,
+// only those of bucket if it is set.
+func replayWALSegments(svc *http.WriteService, files []string, bucket *influxdb.ID) error {
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		res, err := svc.ReplayWAL(context.Background(), f, bucket)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		fmt.Fprintf(os.Stdout, "%s: %d points, %d deletes\n", file, res.Points, res.Deletes)
+		for _, b := range res.Buckets {
+			fmt.Fprintf(os.Stdout, "  bucket %s of organization %s: %d points written, %d dropped\n", b.BucketID, b.OrgID, b.Points, b.Dropped)
+		}
+		if res.Corrupt != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s, the entries after it were not replayed\n", file, res.Corrupt)
+		}
+	}
+	return nil
+}
+
+// convertWALSegments writes the points of the segment files as line protocol to a file of dir
+// for every bucket, named after the organization and bucket, only those of bucket if it is set.
+func convertWALSegments(dir string, files []string, bucket *influxdb.ID, keyring *encryption.Keyring) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	outputs := make(map[string]*bufio.Writer)
+	var closers []*os.File
+	defer func() {
+		for _, f := range closers {
+			f.Close()
+		}
+	}()
+	output := func(org, bucket influxdb.ID) (*bufio.Writer, error) {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.lp", org, bucket))
+		if w, ok := outputs[path]; ok {
+			return w, nil
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, f)
+		w := bufio.NewWriter(f)
+		outputs[path] = w
+		return w, nil
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		points, stats, err := storage.ReadWALSegment(f, keyring)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		written := 0
+		for _, pt := range points {
+			org, b, pt, err := tsdb.UnexplodePoint(pt)
+			if err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			if bucket != nil && b != *bucket {
+				continue
+			}
+			w, err := output(org, b)
+			if err != nil {
+				return err
+			}
+			if _, err := w.WriteString(pt.String() + "\n"); err != nil {
+				return err
+			}
+			written++
+		}
+
+		fmt.Fprintf(os.Stdout, "%s: %d points, %d deletes, %d points written\n", file, stats.Points, stats.Deletes, written)
+		if stats.Corrupt != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s, the entries after it were not converted\n", file, stats.Corrupt)
+		}
+	}
+
+	paths := make([]string, 0, len(outputs))
+	for path := range outputs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := outputs[path].Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, path)
+	}
+	for _, f := range closers {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	closers = nil
+	return nil
+}
@@ -0,0 +1,65 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestLauncher_AuditLog(t *testing.T) {
+	l := RunLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	do := func(req *nethttp.Request, status int) []byte {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+		}
+		return body
+	}
+
+	var b platform.Bucket
+	body := do(l.MustNewHTTPRequest("POST", "/api/v2/buckets", fmt.Sprintf(`{"organizationID": %q, "name": "audited", "retentionRules": []}`, l.Org.ID)), nethttp.StatusCreated)
+	if err := json.Unmarshal(body, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	// The events are written in the background.
+	var events struct {
+		Events []*platform.AuditEvent `json:"events"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		body := do(l.MustNewHTTPRequest("GET", fmt.Sprintf("/api/v2/audit?orgID=%s&resourceType=buckets", l.Org.ID), ""), nethttp.StatusOK)
+		if err := json.Unmarshal(body, &events); err != nil {
+			t.Fatal(err)
+		}
+		if len(events.Events) > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if len(events.Events) != 1 {
+		t.Fatalf("expected the creation of the bucket to be audited, got %d events", len(events.Events))
+	}
+	e := events.Events[0]
+	if e.Action != platform.AuditActionCreate || e.ResourceID != b.ID || e.AuthorizerID != l.Auth.ID || e.UserID != l.User.ID || e.StatusCode != nethttp.StatusCreated {
+		t.Errorf("unexpected audit event %+v", e)
+	}
+}
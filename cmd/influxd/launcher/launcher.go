@@ -2,8 +2,12 @@ package launcher
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	nethttp "net/http"
 	_ "net/http/pprof" // needed to add pprof to our binary.
@@ -24,35 +28,55 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
 	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/bucketclone"
+	"github.com/influxdata/influxdb/bucketlifecycle"
 	"github.com/influxdata/influxdb/chronograf/server"
+	"github.com/influxdata/influxdb/flight"
 	protofs "github.com/influxdata/influxdb/fs"
 	"github.com/influxdata/influxdb/gather"
 	"github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kafka"
 	"github.com/influxdata/influxdb/kit/cli"
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/limitsim"
 	influxlogger "github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/mqtt"
 	"github.com/influxdata/influxdb/nats"
+	"github.com/influxdata/influxdb/opentsdb"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/proto"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/bulkhead"
 	pcontrol "github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/resultcache"
+	"github.com/influxdata/influxdb/query/stdlib/egress"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/catalog"
+	"github.com/influxdata/influxdb/reaper"
+	"github.com/influxdata/influxdb/reporter"
+	"github.com/influxdata/influxdb/rpc"
 	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/source"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/subsystem"
 	"github.com/influxdata/influxdb/task"
 	taskbackend "github.com/influxdata/influxdb/task/backend"
 	taskbolt "github.com/influxdata/influxdb/task/backend/bolt"
 	"github.com/influxdata/influxdb/task/backend/coordinator"
 	taskexecutor "github.com/influxdata/influxdb/task/backend/executor"
+	tasklineage "github.com/influxdata/influxdb/task/lineage"
+	taskwebhook "github.com/influxdata/influxdb/task/webhook"
+	"github.com/influxdata/influxdb/toml"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/influxdata/influxdb/vault"
+	"github.com/influxdata/influxdb/write"
 	pzap "github.com/influxdata/influxdb/zap"
 )
 
@@ -137,6 +161,61 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP: &l.tlsCert,
+			Flag:  "tls-cert",
+			Desc:  "TLS certificate file; serves the REST HTTP API over HTTPS when set with tls-key",
+		},
+		{
+			DestP: &l.tlsKey,
+			Flag:  "tls-key",
+			Desc:  "TLS private key file of tls-cert",
+		},
+		{
+			DestP:   &l.tlsReloadInterval,
+			Flag:    "tls-reload-interval",
+			Default: 10 * time.Second,
+			Desc:    "how often the tls-cert and tls-key files are checked for changes, which reloads them; they are also reloaded on SIGHUP; 0 disables the checks",
+		},
+		{
+			DestP: &l.tlsClientCA,
+			Flag:  "tls-client-ca",
+			Desc:  "CA certificates file verifying the client certificates of the operational endpoints authenticated with mtls and of the requests authorized by tls-client-cert-authorizations",
+		},
+		{
+			DestP:   &l.tlsClientAuth,
+			Flag:    "tls-client-auth",
+			Default: "optional",
+			Desc:    "whether clients must present a certificate verified by tls-client-ca, optional or require",
+		},
+		{
+			DestP: &l.tlsClientCertAuths,
+			Flag:  "tls-client-cert-authorizations",
+			Desc:  "authorizations of the requests with neither a token nor a session, by the common name of their client certificate, as commonName=authorizationID pairs",
+		},
+		{
+			DestP:   &l.corsPolicy.AllowedOrigins,
+			Flag:    "cors-allowed-origins",
+			Default: platform.DefaultCORSPolicy().AllowedOrigins,
+			Desc:    "origins of the browser apps allowed to call the REST HTTP API, such as https://app.example.com, or * for any; the policy set through the API takes precedence",
+		},
+		{
+			DestP:   &l.corsPolicy.AllowedMethods,
+			Flag:    "cors-allowed-methods",
+			Default: platform.DefaultCORSPolicy().AllowedMethods,
+			Desc:    "HTTP methods allowed in the cross-origin requests to the REST HTTP API",
+		},
+		{
+			DestP:   &l.corsPolicy.AllowedHeaders,
+			Flag:    "cors-allowed-headers",
+			Default: platform.DefaultCORSPolicy().AllowedHeaders,
+			Desc:    "headers allowed in the cross-origin requests to the REST HTTP API",
+		},
+		{
+			DestP: &l.operationalAuth,
+			Flag:  "operational-auth",
+			Desc:  "authentication of the metrics, ready, health and debug endpoints as endpoint=mode pairs, with modes none, token or mtls; endpoints default to none",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -166,6 +245,34 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP: &l.coldTierPath,
+			Flag:  "storage-cold-tier-path",
+			Desc:  "directory, typically a mount of an object store, that cold TSM files are moved to; empty keeps every file on local disk",
+		},
+		{
+			DestP:   &l.coldTierAge,
+			Flag:    "storage-cold-tier-age",
+			Default: 30 * 24 * time.Hour,
+			Desc:    "age of the data after which fully compacted TSM files are moved to the cold tier",
+		},
+		{
+			DestP: &l.StorageConfig.EncryptionKeyFile,
+			Flag:  "storage-encryption-key-file",
+			Desc:  "file of the keys encrypting the TSM blocks, the WAL and the series file, one id and base64 AES key per line, the last one current; empty does not encrypt them. Series keys stay readable on disk in the TSM and TSI indexes",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.BlockCompression,
+			Flag:    "storage-block-compression",
+			Default: string(tsm1.BlockCompressionDefault),
+			Desc:    "compression of the values of float and string TSM blocks, default (Gorilla and snappy) or zstd; compactions rewrite existing blocks with it",
+		},
+		{
+			DestP:   &l.compileCachePath,
+			Flag:    "query-compile-cache-path",
+			Default: filepath.Join(dir, "compilecache"),
+			Desc:    "path to the compiled Flux specs reused across restarts; empty compiles every script on each run",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
@@ -184,6 +291,341 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disable sending telemetry data to https://telemetry.influxdata.com every 8 hours",
 		},
+		{
+			DestP:   &l.taskQuota.MaxActiveTasks,
+			Flag:    "task-max-active-tasks",
+			Default: 0,
+			Desc:    "maximum number of active tasks per organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.taskQuota.MaxRunsPerHour,
+			Flag:    "task-max-runs-per-hour",
+			Default: 0,
+			Desc:    "maximum number of task runs created per organization per hour; 0 means unlimited",
+		},
+		{
+			DestP:   &l.taskOrgConcurrency,
+			Flag:    "task-org-concurrency",
+			Default: 0,
+			Desc:    "maximum number of task runs executing at once per organization, shared fairly between its tasks by weight; 0 means unlimited",
+		},
+		{
+			DestP:   &l.queryBulkhead.MaxConcurrency,
+			Flag:    "query-org-concurrency",
+			Default: 0,
+			Desc:    "maximum number of queries executing at once per organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.queryOrgQuotas.ConcurrencyQuota,
+			Flag:    "query-org-concurrency-quota",
+			Default: 0,
+			Desc:    "maximum number of queries running at once per organization in the query controller, over which they queue; 0 means unlimited",
+		},
+		{
+			DestP:   &l.queryOrgMemoryBytesQuota,
+			Flag:    "query-org-memory-bytes-quota",
+			Default: 0,
+			Desc:    "maximum memory in bytes allocated by the queries running at once per organization, over which they queue; 0 means unlimited",
+		},
+		{
+			DestP:   &l.queryOrgQuotas.QueueSize,
+			Flag:    "query-org-queue-size",
+			Default: 10,
+			Desc:    "maximum number of queries queued per organization for its quotas, over which they are rejected",
+		},
+		{
+			DestP:   &l.queryLanes.Concurrency,
+			Flag:    "query-lane-concurrency",
+			Default: 0,
+			Desc:    "maximum number of queries running at once in the interactive and task lanes of the query controller, shared by their weights; 0 disables the lanes",
+		},
+		{
+			DestP:   &l.queryInteractiveLaneWeight,
+			Flag:    "query-interactive-lane-weight",
+			Default: 1,
+			Desc:    "weight of the lane of the queries of the API when the lanes are busy",
+		},
+		{
+			DestP:   &l.queryTaskLaneWeight,
+			Flag:    "query-task-lane-weight",
+			Default: 1,
+			Desc:    "weight of the lane of the task runs and system queries when the lanes are busy",
+		},
+		{
+			DestP:   &l.queryLanes.QueueSize,
+			Flag:    "query-lane-queue-size",
+			Default: 100,
+			Desc:    "maximum number of queries queued per lane, over which they are rejected",
+		},
+		{
+			DestP:   &l.queryBulkhead.FailureThreshold,
+			Flag:    "query-org-breaker-threshold",
+			Default: 0,
+			Desc:    "number of consecutive panicking queries or task runs of an organization that suspend its queries or task runs; 0 means never",
+		},
+		{
+			DestP:   &l.queryBulkhead.Cooldown,
+			Flag:    "query-org-breaker-cooldown",
+			Default: bulkhead.DefaultCooldown,
+			Desc:    "how long the queries or task runs of an organization stay suspended",
+		},
+		{
+			DestP:   &l.slowQueryThreshold,
+			Flag:    "query-slow-log-threshold",
+			Default: time.Duration(0),
+			Desc:    "how long a query runs for before it is recorded as slow to the log and to the slow queries bucket of its organization; 0 means queries are not slow by duration",
+		},
+		{
+			DestP:   &l.slowQueryScannedBytes,
+			Flag:    "query-slow-log-scanned-bytes",
+			Default: 0,
+			Desc:    "how many bytes a query reads from storage before it is recorded as slow; 0 means queries are not slow by bytes scanned",
+		},
+		{
+			DestP:   &l.slowQueryMaxTextLength,
+			Flag:    "query-slow-log-max-text-length",
+			Default: pcontrol.DefaultSlowQueryMaxTextLength,
+			Desc:    "length in bytes the text of the slow queries is truncated to",
+		},
+		{
+			DestP:   &l.egressDenyWithoutPolicy,
+			Flag:    "egress-deny-without-policy",
+			Default: false,
+			Desc:    "deny the outbound connections of http.to and sql.from to the organizations without an egress policy, instead of not restricting them",
+		},
+		{
+			DestP:   &l.queryCacheMaxBytes,
+			Flag:    "query-cache-max-bytes",
+			Default: 0,
+			Desc:    "total size in bytes of the results of the queries cached, returned again while the buckets they read are not written to; 0 disables the query result cache",
+		},
+		{
+			DestP:   &l.queryCacheMaxResultBytes,
+			Flag:    "query-cache-max-result-bytes",
+			Default: 0,
+			Desc:    "size in bytes of the largest results of a query cached; 0 means a tenth of query-cache-max-bytes",
+		},
+		{
+			DestP:   &l.queryCache.Resolution,
+			Flag:    "query-cache-resolution",
+			Default: resultcache.DefaultResolution,
+			Desc:    "how old the cached results of the queries with time bounds relative to now, such as range(start: -1h), can be",
+		},
+		{
+			DestP:   &l.taskWatchdog.MinDuration,
+			Flag:    "task-watchdog-min-duration",
+			Default: time.Duration(0),
+			Desc:    "minimum duration before a task run that is still executing is force-finished as failed; 0 disables the watchdog",
+		},
+		{
+			DestP:   &l.taskWatchdog.DurationFactor,
+			Flag:    "task-watchdog-duration-factor",
+			Default: 10,
+			Desc:    "multiple of the typical duration of a task's runs after which a run is force-finished as failed",
+		},
+		{
+			DestP:   &l.taskWatchdogRetry,
+			Flag:    "task-watchdog-retry",
+			Default: false,
+			Desc:    "retry the scheduled task runs force-finished by the watchdog",
+		},
+		{
+			DestP:   &l.reaperInterval,
+			Flag:    "inactivity-reaper-interval",
+			Default: reaper.DefaultInterval,
+			Desc:    "time between two passes finding the resources left inactive according to the inactivity policy of their organization; 0 disables the reaper",
+		},
+		{
+			DestP:   &l.taskLogBatch.Size,
+			Flag:    "task-log-batch-size",
+			Default: 100,
+			Desc:    "number of task run states and logs written at once; 1 or less writes them one by one",
+		},
+		{
+			DestP:   &l.taskLogBatch.FlushInterval,
+			Flag:    "task-log-flush-interval",
+			Default: time.Second,
+			Desc:    "longest a batched task run state or log waits to be written",
+		},
+		{
+			DestP:   &l.taskLogCompaction.Interval,
+			Flag:    "task-log-compaction-interval",
+			Default: time.Duration(0),
+			Desc:    "time between two compactions of the task run states and logs into hourly summaries; 0 disables the compaction",
+		},
+		{
+			DestP:   &l.taskLogCompaction.Retention,
+			Flag:    "task-log-retention",
+			Default: taskbackend.DefaultRunLogRetention,
+			Desc:    "how long task run states and logs are kept before being compacted into hourly summaries",
+		},
+		{
+			DestP:   &l.auditLogDisabled,
+			Flag:    "audit-log-disabled",
+			Default: false,
+			Desc:    "disable recording the API calls creating, updating or deleting resources to the audit bucket of their organization",
+		},
+		{
+			DestP:   &l.auditLogRetention,
+			Flag:    "audit-log-retention",
+			Default: audit.DefaultRetention,
+			Desc:    "how long the events of the audit log are kept",
+		},
+		{
+			DestP:   &l.writeLimits.TokenPointsPerSecond,
+			Flag:    "write-token-points-per-second",
+			Default: 0,
+			Desc:    "maximum rate of points written with each authorization token; 0 means unlimited",
+		},
+		{
+			DestP:   &l.writeLimits.TokenBytesPerSecond,
+			Flag:    "write-token-bytes-per-second",
+			Default: 0,
+			Desc:    "maximum rate of line protocol bytes written with each authorization token; 0 means unlimited",
+		},
+		{
+			DestP:   &l.writeLimits.OrgPointsPerSecond,
+			Flag:    "write-org-points-per-second",
+			Default: 0,
+			Desc:    "maximum rate of points written to the buckets of each organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.writeLimits.OrgBytesPerSecond,
+			Flag:    "write-org-bytes-per-second",
+			Default: 0,
+			Desc:    "maximum rate of line protocol bytes written to the buckets of each organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.requestLimits.RequestsPerSecond,
+			Flag:    "http-token-requests-per-second",
+			Default: 0,
+			Desc:    "maximum rate of the HTTP requests made with each authorization token; 0 means unlimited",
+		},
+		{
+			DestP:   &l.requestLimits.Burst,
+			Flag:    "http-token-request-burst",
+			Default: 0,
+			Desc:    "number of HTTP requests each authorization token may make at once above its rate; 0 means the rate",
+		},
+		{
+			DestP:   &l.requestLimits.MaxConcurrent,
+			Flag:    "http-token-max-concurrent-requests",
+			Default: 0,
+			Desc:    "maximum number of HTTP requests made with each authorization token served at the same time; 0 means unlimited",
+		},
+		{
+			DestP: &l.writeQueuePath,
+			Flag:  "write-queue-path",
+			Desc:  "directory of the durable write queue; if set, writes are acknowledged once queued and written to the storage engine in the background",
+		},
+		{
+			DestP:   &l.writeQueueMaxSize,
+			Flag:    "write-queue-max-size",
+			Default: 0,
+			Desc:    "size in bytes of the write queue past which writes are rejected until it drains; 0 means unlimited",
+		},
+		{
+			DestP:   &l.alertingQuota.MaxTaskWebhooks,
+			Flag:    "alerting-max-task-webhooks",
+			Default: 0,
+			Desc:    "maximum number of task webhooks per organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.alertingQuota.MaxExpectedReporters,
+			Flag:    "alerting-max-expected-reporters",
+			Default: 0,
+			Desc:    "maximum number of expected reporters per organization; 0 means unlimited",
+		},
+		{
+			DestP:   &l.natsConfig.Mode,
+			Flag:    "nats-mode",
+			Default: nats.ModeEmbedded,
+			Desc:    "streaming of the scraper: embedded runs a NATS streaming server, in-process passes the messages in memory and external connects to nats-url",
+		},
+		{
+			DestP: &l.natsConfig.URL,
+			Flag:  "nats-url",
+			Desc:  "URL of the external NATS streaming cluster",
+		},
+		{
+			DestP: &l.natsConfig.ClusterID,
+			Flag:  "nats-cluster-id",
+			Desc:  "cluster ID of the external NATS streaming cluster",
+		},
+		{
+			DestP:   &l.natsConfig.ClientID,
+			Flag:    "nats-client-id",
+			Default: "influxd",
+			Desc:    "prefix of the client IDs of influxd in the external NATS streaming cluster, unique to each influxd sharing it",
+		},
+		{
+			DestP: &l.opentsdbBindAddress,
+			Flag:  "opentsdb-bind-address",
+			Desc:  "bind address of the telnet listener accepting OpenTSDB put commands; the listener is disabled if empty",
+		},
+		{
+			DestP: &l.opentsdbBucketID,
+			Flag:  "opentsdb-bucket-id",
+			Desc:  "ID of the bucket the datapoints of the OpenTSDB telnet listener are written to",
+		},
+		{
+			DestP: &l.flightBindAddress,
+			Flag:  "flight-bind-address",
+			Desc:  "bind address of the Arrow Flight gRPC endpoint streaming the results of Flux queries as Arrow record batches; the endpoint is disabled if empty",
+		},
+		{
+			DestP: &l.grpcBindAddress,
+			Flag:  "grpc-bind-address",
+			Desc:  "bind address of the gRPC API of the bucket, organization and task services and of writes; the API is disabled if empty",
+		},
+		{
+			DestP: &l.mqttBroker,
+			Flag:  "mqtt-broker",
+			Desc:  "URL of the MQTT broker to subscribe to, such as tcp://localhost:1883; the MQTT subscriber is disabled if empty",
+		},
+		{
+			DestP:   &l.mqttOptions.ClientID,
+			Flag:    "mqtt-client-id",
+			Default: "influxd",
+			Desc:    "client ID of influxd on the MQTT broker",
+		},
+		{
+			DestP: &l.mqttOptions.Username,
+			Flag:  "mqtt-username",
+			Desc:  "user name of influxd on the MQTT broker",
+		},
+		{
+			DestP: &l.mqttOptions.Password,
+			Flag:  "mqtt-password",
+			Desc:  "password of influxd on the MQTT broker",
+		},
+		{
+			DestP: &l.mqttSubsPath,
+			Flag:  "mqtt-subscriptions-path",
+			Desc:  "path to the JSON file of the MQTT subscriptions, listing the topic, bucketID and format of each",
+		},
+		{
+			DestP: &l.kafkaBrokers,
+			Flag:  "kafka-brokers",
+			Desc:  "addresses of the Kafka brokers to consume topics from, such as localhost:9092; the Kafka consumer is disabled if empty",
+		},
+		{
+			DestP:   &l.kafkaGroupID,
+			Flag:    "kafka-group-id",
+			Default: kafka.DefaultGroupID,
+			Desc:    "consumer group of influxd, under which the offsets of the Kafka topics consumed are checkpointed",
+		},
+		{
+			DestP:   &l.kafkaCheckpointInterval,
+			Flag:    "kafka-checkpoint-interval",
+			Default: kafka.DefaultCheckpointInterval,
+			Desc:    "how often the offsets of the Kafka topics consumed are checkpointed",
+		},
+		{
+			DestP: &l.kafkaSubsPath,
+			Flag:  "kafka-subscriptions-path",
+			Desc:  "path to the JSON file of the Kafka subscriptions, listing the topic, bucketID and format of each",
+		},
 	}
 
 	cli.BindOptions(cmd, opts)
@@ -203,26 +645,98 @@ type Launcher struct {
 	tracingType       string
 	reportingDisabled bool
 
-	httpBindAddress string
-	boltPath        string
-	enginePath      string
-	protosPath      string
-	secretStore     string
+	httpBindAddress  string
+	tlsCert          string
+	tlsKey           string
+	tlsClientCA      string
+	operationalAuth  []string
+	boltPath         string
+	enginePath       string
+	coldTierPath     string
+	coldTierAge      time.Duration
+	protosPath       string
+	compileCachePath string
+	secretStore      string
+
+	corsPolicy platform.CORSPolicy
+
+	tlsReloadInterval  time.Duration
+	tlsClientAuth      string
+	tlsClientCertAuths []string
+	certReloader       *certificateReloader
 
 	boltClient    *bolt.Client
 	kvService     *kv.Service
 	engine        *storage.Engine
 	StorageConfig storage.Config
 
-	queryController *pcontrol.Controller
+	queryController    *pcontrol.Controller
+	sqlPools           *egress.SQLPools
+	queryBulkhead      bulkhead.Config
+	slowQueryThreshold time.Duration
+
+	slowQueryScannedBytes  int
+	slowQueryMaxTextLength int
+
+	// egressDenyWithoutPolicy denies the outbound connections of the organizations without an egress policy.
+	egressDenyWithoutPolicy bool
+
+	queryCache               resultcache.Config
+	queryCacheMaxBytes       int
+	queryCacheMaxResultBytes int
+
+	queryOrgQuotas           pcontrol.OrgQuotas
+	queryOrgMemoryBytesQuota int
+
+	queryLanes                 pcontrol.LaneConfig
+	queryInteractiveLaneWeight int
+	queryTaskLaneWeight        int
 
 	httpPort   int
 	httpServer *nethttp.Server
 
-	natsServer *nats.Server
+	natsConfig nats.Config
+
+	opentsdbBindAddress string
+	opentsdbBucketID    string
+
+	flightBindAddress string
+	grpcBindAddress   string
+
+	mqttBroker   string
+	mqttOptions  mqtt.ClientOptions
+	mqttSubsPath string
+
+	kafkaBrokers            []string
+	kafkaGroupID            string
+	kafkaCheckpointInterval time.Duration
+	kafkaSubsPath           string
+
+	subsystems *subsystem.Registry
 
 	scheduler *taskbackend.TickScheduler
 	taskStore taskbackend.Store
+	taskQuota taskbackend.TaskQuota
+
+	taskOrgConcurrency int
+
+	taskWatchdog      taskbackend.WatchdogConfig
+	taskWatchdogRetry bool
+
+	taskLogBatch      taskbackend.LogBatchConfig
+	taskLogCompaction taskbackend.RunLogCompactionConfig
+	taskLogWriter     *taskbackend.PointLogWriter
+
+	reaperInterval time.Duration
+
+	auditLogDisabled  bool
+	auditLogRetention time.Duration
+
+	requestLimits     http.RequestLimits
+	writeLimits       write.Limits
+	writeQueuePath    string
+	writeQueueMaxSize int
+	alertingQuota     platform.AlertingQuota
 
 	jaegerTracerCloser io.Closer
 	logger             *zap.Logger
@@ -266,7 +780,11 @@ func (m *Launcher) Logger() *zap.Logger {
 
 // URL returns the URL to connect to the HTTP server.
 func (m *Launcher) URL() string {
-	return fmt.Sprintf("http://127.0.0.1:%d", m.httpPort)
+	scheme := "http"
+	if m.tlsCert != "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://127.0.0.1:%d", scheme, m.httpPort)
 }
 
 // Engine returns a reference to the storage engine. It should only be called
@@ -275,33 +793,22 @@ func (m *Launcher) Engine() *storage.Engine {
 	return m.engine
 }
 
-// Shutdown shuts down the HTTP server and waits for all services to clean up.
+// Shutdown stops the subsystems, starting with the HTTP server, and waits for all services to clean up.
 func (m *Launcher) Shutdown(ctx context.Context) {
-	m.httpServer.Shutdown(ctx)
-
-	m.logger.Info("Stopping", zap.String("service", "task"))
-	m.scheduler.Stop()
-
-	m.logger.Info("Stopping", zap.String("service", "nats"))
-	m.natsServer.Close()
+	// The subsystems log their own failures to stop.
+	_ = m.subsystems.Stop(ctx)
 
 	m.logger.Info("Stopping", zap.String("service", "bolt"))
 	if err := m.boltClient.Close(); err != nil {
 		m.logger.Info("failed closing bolt", zap.Error(err))
 	}
 
-	m.logger.Info("Stopping", zap.String("service", "query"))
-	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
-		m.logger.Info("Failed closing query service", zap.Error(err))
-	}
+	m.wg.Wait()
 
-	m.logger.Info("Stopping", zap.String("service", "storage-engine"))
-	if err := m.engine.Close(); err != nil {
-		m.logger.Error("failed to close engine", zap.Error(err))
+	if m.sqlPools != nil {
+		m.sqlPools.Close()
 	}
 
-	m.wg.Wait()
-
 	if m.jaegerTracerCloser != nil {
 		if err := m.jaegerTracerCloser.Close(); err != nil {
 			m.logger.Warn("failed to closer Jaeger tracer", zap.Error(err))
@@ -352,6 +859,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	// The subsystems are registered in the order they start, each after the subsystems it depends on.
+	m.subsystems = subsystem.NewRegistry(m.logger)
+
 	info := platform.GetBuildInfo()
 	m.logger.Info("Welcome to InfluxDB",
 		zap.String("version", info.Version),
@@ -414,6 +924,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	m.kvService.Logger = m.logger.With(zap.String("store", "kv"))
+	m.kvService.AlertingQuota = m.alertingQuota
 	if err := m.kvService.Initialize(ctx); err != nil {
 		m.logger.Error("failed to initialize kv service", zap.Error(err))
 		return err
@@ -446,8 +957,18 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		telegrafSvc      platform.TelegrafConfigStore             = m.kvService
 		userResourceSvc  platform.UserResourceMappingService      = m.kvService
 		labelSvc         platform.LabelService                    = m.kvService
+		metadataSvc      platform.MetadataService                 = m.kvService
+		announcementSvc  platform.AnnouncementService             = m.kvService
+		reporterSvc      platform.ExpectedReporterService         = m.kvService
+		taskWebhookSvc   platform.TaskWebhookService              = m.kvService
+		taskScriptSvc    platform.TaskScriptService               = m.kvService
+		taskDepsSvc      platform.TaskDependencyService           = m.kvService
+		dbrpMappingSvc   platform.DBRPMappingService              = m.kvService
+		sqlConnectionSvc platform.SQLConnectionService            = m.kvService
 		secretSvc        platform.SecretService                   = m.kvService
 		lookupSvc        platform.LookupService                   = m.kvService
+		activitySvc      platform.ActivityService                 = m.kvService
+		lineageSvc       platform.LineageService                  = m.kvService
 	)
 
 	switch m.secretStore {
@@ -491,12 +1012,24 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	var pointsWriter storage.PointsWriter
+	var catalogDeps *catalog.Dependencies
+	var compileCache *pcontrol.CompileCache
 	{
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engineOpts := []storage.Option{storage.WithRetentionEnforcer(bucketSvc)}
+		if m.coldTierPath != "" {
+			m.StorageConfig.ColdTierAge = toml.Duration(m.coldTierAge)
+			engineOpts = append(engineOpts, storage.WithObjectStore(tsm1.NewDirObjectStore(m.coldTierPath)))
+		}
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, engineOpts...)
 		m.engine.WithLogger(m.logger)
 
-		if err := m.engine.Open(ctx); err != nil {
-			m.logger.Error("failed to open engine", zap.Error(err))
+		m.subsystems.Register("storage", subsystem.Funcs{
+			StartFn: m.engine.Open,
+			StopFn: func(context.Context) error {
+				return m.engine.Close()
+			},
+		}, false)
+		if err := m.subsystems.Start(ctx); err != nil {
 			return err
 		}
 		// The Engine's metrics must be registered after it opens.
@@ -523,11 +1056,85 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			return err
 		}
 
+		catalogDeps = &catalog.Dependencies{
+			BucketService:              bucketSvc,
+			UserService:                userSvc,
+			UserResourceMappingService: userResourceSvc,
+		}
+		if err := catalog.InjectDependencies(cc.ExecutorDependencies, catalogDeps); err != nil {
+			m.logger.Error("Failed to configure query controller dependencies", zap.Error(err))
+			return err
+		}
+
+		// The pools of the named SQL connections are shared by all queries and task runs.
+		m.sqlPools = egress.NewSQLPools(secretSvc, orgSvc)
+		m.sqlPools.DenyWithoutPolicy = m.egressDenyWithoutPolicy
+		if err := egress.InjectDependencies(cc.ExecutorDependencies, egress.Dependencies{
+			OrganizationService:  orgSvc,
+			SQLConnectionService: sqlConnectionSvc,
+			SQLPools:             m.sqlPools,
+			DenyWithoutPolicy:    m.egressDenyWithoutPolicy,
+		}); err != nil {
+			m.logger.Error("Failed to configure query controller dependencies", zap.Error(err))
+			return err
+		}
+
 		m.queryController = pcontrol.New(cc)
+		m.queryOrgQuotas.MemoryBytesQuota = int64(m.queryOrgMemoryBytesQuota)
+		m.queryController.WithOrgQuotas(m.queryOrgQuotas)
+		// The task runs and the queries of the subsystems have a lane of their own, so that they do not delay dashboards.
+		m.queryLanes.Weights = map[pcontrol.Lane]int{
+			pcontrol.LaneInteractive: m.queryInteractiveLaneWeight,
+			pcontrol.LaneTask:        m.queryTaskLaneWeight,
+		}
+		m.queryController.WithLanes(m.queryLanes)
 		m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
+
+		// The slow queries are logged, and written to the slow queries bucket of their organization.
+		slowQueryConfig := pcontrol.SlowQueryConfig{
+			Duration:      m.slowQueryThreshold,
+			ScannedBytes:  int64(m.slowQueryScannedBytes),
+			MaxTextLength: m.slowQueryMaxTextLength,
+		}
+		slowQueryWriter := pcontrol.NewSlowQueryWriter(pointsWriter, m.logger.With(zap.String("service", "slow-query-writer")))
+		m.queryController.WithSlowQueryLog(slowQueryConfig,
+			pcontrol.NewSlowQueryLogger(m.logger.With(zap.String("service", "slow-query-log"))),
+			slowQueryWriter,
+		)
+
+		// Load the specs compiled before the restart, so that the first runs of tasks do not recompile them all.
+		if m.compileCachePath != "" {
+			compileCache = pcontrol.NewCompileCache(m.compileCachePath, pcontrol.FluxVersion(), m.logger.With(zap.String("service", "compile-cache")))
+			if err := compileCache.Open(ctx); err != nil {
+				m.logger.Error("Failed to open query compile cache", zap.Error(err))
+				return err
+			}
+			m.queryController.WithCompileCache(compileCache)
+			m.reg.MustRegister(compileCache.PrometheusCollectors()...)
+		}
+
+		m.subsystems.Register("query", subsystem.Funcs{
+			StopFn: func(ctx context.Context) error {
+				if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
+					return err
+				}
+				return slowQueryWriter.Close()
+			},
+		}, false)
 	}
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	// The bulkheads keep the queries and task runs of an organization from bringing down the query controller.
+	queryBulkhead := bulkhead.New("query", m.queryBulkhead, m.logger)
+	m.reg.MustRegister(queryBulkhead.PrometheusCollectors()...)
+	var storageQueryService = readservice.NewProxyQueryService(queryBulkhead.AsyncQueryService(m.queryController))
+	if m.queryCacheMaxBytes > 0 {
+		// The results of the queries of the API are cached, not those of the task runs.
+		m.queryCache.MaxBytes = int64(m.queryCacheMaxBytes)
+		m.queryCache.MaxResultBytes = int64(m.queryCacheMaxResultBytes)
+		queryCache := resultcache.New(m.queryCache, m.engine, bucketSvc, m.logger.With(zap.String("service", "query-cache")))
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		storageQueryService = queryCache.ProxyQueryService(storageQueryService)
+	}
 	var taskSvc platform.TaskService
 	{
 		var (
@@ -543,81 +1150,380 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		if m.storeType == "memory" {
 			store = taskbackend.NewInMemStore()
 		}
+		store = taskbackend.NewQuotaStore(store, m.taskQuota)
 
-		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), m.queryController, authSvc, store)
+		// The fair executor already limits the concurrency of the task runs of an organization.
+		taskBulkheadConfig := m.queryBulkhead
+		taskBulkheadConfig.MaxConcurrency = 0
+		taskBulkhead := bulkhead.New("task", taskBulkheadConfig, m.logger)
+		m.reg.MustRegister(taskBulkhead.PrometheusCollectors()...)
 
-		lw := taskbackend.NewPointLogWriter(pointsWriter)
-		m.scheduler = taskbackend.NewScheduler(store, executor, lw, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, 100*time.Millisecond), taskbackend.WithLogger(m.logger))
-		m.scheduler.Start(ctx)
+		executorOpts := []taskexecutor.Option{taskexecutor.WithTaskScriptService(taskScriptSvc)}
+		if compileCache != nil {
+			executorOpts = append(executorOpts, taskexecutor.WithCompileCache(compileCache))
+		}
+		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), taskBulkhead.AsyncQueryService(m.queryController.Lane(pcontrol.LaneTask)), authSvc, store, executorOpts...)
+		executor = taskexecutor.NewFairExecutor(executor, store, m.taskOrgConcurrency)
+
+		m.taskLogWriter = taskbackend.NewPointLogWriter(pointsWriter,
+			taskbackend.WithLogBatching(m.taskLogBatch),
+			taskbackend.WithLogWriterLogger(m.logger.With(zap.String("service", "task-log-writer"))),
+		)
+		lw := m.taskLogWriter
+		if m.taskWatchdogRetry {
+			m.taskWatchdog.Retrier = store
+		}
+		notifiers := taskbackend.RunNotifiers{
+			taskwebhook.NewNotifier(taskWebhookSvc, m.logger),
+			tasklineage.NewRecorder(lineageSvc, bucketSvc, taskScriptSvc, m.logger),
+		}
+		m.scheduler = taskbackend.NewScheduler(store, executor, lw, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, 100*time.Millisecond), taskbackend.WithLogger(m.logger), taskbackend.WithWatchdog(m.taskWatchdog), taskbackend.WithRunNotifier(notifiers))
 		m.reg.MustRegister(m.scheduler.PrometheusCollectors()...)
 
+		// Stopping the scheduler releases the tasks it claimed, so it cannot be restarted without the coordinator claiming them again.
+		m.subsystems.Register("scheduler", subsystem.Funcs{
+			StartFn: func(ctx context.Context) error {
+				m.scheduler.Start(ctx)
+				return nil
+			},
+			StopFn: func(ctx context.Context) error {
+				m.scheduler.Stop()
+				if err := m.taskLogWriter.Flush(ctx); err != nil {
+					m.logger.Info("Failed writing batched task run states and logs", zap.Error(err))
+				}
+				return nil
+			},
+		}, false)
+		if err := m.subsystems.Start(ctx); err != nil {
+			return err
+		}
+
 		queryService := query.QueryServiceBridge{AsyncQueryService: m.queryController}
 		lr := taskbackend.NewQueryLogReader(queryService)
-		taskSvc = task.PlatformAdapter(coordinator.New(m.logger.With(zap.String("service", "task-coordinator")), m.scheduler, store), lr, m.scheduler, authSvc, userResourceSvc, orgSvc)
-		taskSvc = task.NewValidator(m.logger.With(zap.String("service", "task-authz-validator")), taskSvc, bucketSvc)
+		taskSvc = task.PlatformAdapter(coordinator.New(m.logger.With(zap.String("service", "task-coordinator")), m.scheduler, store), lr, lw, m.scheduler, authSvc, userResourceSvc, orgSvc)
+		// The catalog filters tasks by the authorization of the query itself.
+		catalogDeps.TaskService = taskSvc
+		// Record the dependencies of the tasks created before they were recorded.
+		if err := task.RecordAllTaskDependencies(ctx, m.logger.With(zap.String("service", "task-dependencies")), taskSvc, taskDepsSvc, taskScriptSvc); err != nil {
+			m.logger.Error("Failed to record task dependencies", zap.Error(err))
+		}
+		taskSvc = task.NewDependencyRecorder(m.logger.With(zap.String("service", "task-dependencies")), taskSvc, taskDepsSvc, taskScriptSvc)
+		taskSvc = task.NewValidator(m.logger.With(zap.String("service", "task-authz-validator")), taskSvc, bucketSvc, task.WithTaskScriptService(taskScriptSvc))
 		m.taskStore = store
 	}
 
-	// NATS streaming server
-	m.natsServer = nats.NewServer()
-	if err := m.natsServer.Open(); err != nil {
-		m.logger.Error("failed to start nats streaming server", zap.Error(err))
+	// NATS streaming, embedded, in process or external
+	streaming, err := nats.NewStreaming(m.natsConfig, m.logger.With(zap.String("service", "nats")))
+	if err != nil {
+		m.logger.Error("failed to configure streaming", zap.Error(err))
 		return err
 	}
-
-	publisher := nats.NewAsyncPublisher("nats-publisher")
-	if err := publisher.Open(); err != nil {
-		m.logger.Error("failed to connect to streaming server", zap.Error(err))
+	// The scraper subscribes to the streaming once, so it cannot be restarted under it.
+	m.subsystems.Register("nats", subsystem.Funcs{
+		StartFn: func(context.Context) error {
+			return streaming.Open()
+		},
+		StopFn: func(context.Context) error {
+			return streaming.Close()
+		},
+	}, false)
+	if err := m.subsystems.Start(ctx); err != nil {
 		return err
 	}
 
 	// TODO(jm): this is an example of using a subscriber to consume from the channel. It should be removed.
-	subscriber := nats.NewQueueSubscriber("nats-subscriber")
-	if err := subscriber.Open(); err != nil {
-		m.logger.Error("failed to connect to streaming server", zap.Error(err))
-		return err
-	}
-
-	subscriber.Subscribe(gather.MetricsSubject, "metrics", &gather.RecorderHandler{
+	streaming.Subscribe(gather.MetricsSubject, "metrics", &gather.RecorderHandler{
 		Logger: m.logger,
 		Recorder: gather.PointWriter{
 			Writer: pointsWriter,
 		},
 	})
-	scraperScheduler, err := gather.NewScheduler(10, m.logger, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
+	scraperScheduler, err := gather.NewScheduler(10, m.logger, scraperTargetSvc, streaming, streaming, 10*time.Second, 30*time.Second)
 	if err != nil {
 		m.logger.Error("failed to create scraper subscriber", zap.Error(err))
 		return err
 	}
 
-	m.wg.Add(1)
-	go func(logger *zap.Logger) {
-		defer m.wg.Done()
-		logger = logger.With(zap.String("service", "scraper"))
-		if err := scraperScheduler.Run(ctx); err != nil {
-			logger.Error("failed scraper service", zap.Error(err))
+	m.subsystems.Register("gather", newGatherSubsystem(scraperScheduler, m.logger.With(zap.String("service", "scraper"))), true)
+
+	// The services writing to buckets by ID check the points written to explicit-schema buckets with it.
+	schemaChecker := &write.SchemaChecker{
+		BucketService:            bucketSvc,
+		MeasurementSchemaService: m.kvService,
+	}
+
+	if m.opentsdbBindAddress != "" {
+		bucketID, err := platform.IDFromString(m.opentsdbBucketID)
+		if err != nil {
+			m.logger.Error("invalid OpenTSDB bucket ID", zap.String("bucket_id", m.opentsdbBucketID), zap.Error(err))
+			return err
 		}
-		logger.Info("Stopping")
-	}(m.logger)
+		b, err := bucketSvc.FindBucketByID(ctx, *bucketID)
+		if err != nil {
+			m.logger.Error("failed to find OpenTSDB bucket", zap.String("bucket_id", m.opentsdbBucketID), zap.Error(err))
+			return err
+		}
+		tsdbSvc := opentsdb.NewService(b.OrganizationID, b.ID, pointsWriter, m.logger.With(zap.String("service", "opentsdb")))
+		tsdbSvc.SchemaChecker = schemaChecker
+		m.subsystems.Register("opentsdb", subsystem.Funcs{
+			StartFn: func(context.Context) error {
+				return tsdbSvc.Open(m.opentsdbBindAddress)
+			},
+			StopFn: func(context.Context) error {
+				return tsdbSvc.Close()
+			},
+		}, true)
+	}
+
+	if m.tlsCert != "" || m.tlsKey != "" {
+		// The certificate is shared by the HTTP, Flight and gRPC servers, which serve it as it is reloaded.
+		m.certReloader, err = newCertificateReloader(m.tlsCert, m.tlsKey, m.tlsReloadInterval, m.logger.With(zap.String("service", "tls")))
+		if err != nil {
+			m.logger.Error("failed to load TLS certificate", zap.Error(err))
+			return err
+		}
+		m.subsystems.Register("tls", newRunnerSubsystem(m.certReloader), true)
+	}
+
+	if m.flightBindAddress != "" {
+		// The Flight endpoint is served over TLS like the HTTP server.
+		tlsConfig, err := m.tlsConfig()
+		if err != nil {
+			m.logger.Error("failed to configure TLS", zap.Error(err))
+			return err
+		}
+		flightSvc := flight.NewService(authSvc, orgSvc, query.QueryServiceBridge{AsyncQueryService: m.queryController},
+			m.logger.With(zap.String("service", "flight")))
+		flightSvc.TLSConfig = tlsConfig
+		m.subsystems.Register("flight", subsystem.Funcs{
+			StartFn: func(context.Context) error {
+				return flightSvc.Open(m.flightBindAddress)
+			},
+			StopFn: func(context.Context) error {
+				return flightSvc.Close()
+			},
+		}, true)
+	}
+
+	// The write limits are shared by the writes over HTTP and gRPC.
+	writeLimiter := write.NewLimiter(m.writeLimits)
+	m.reg.MustRegister(writeLimiter.PrometheusCollectors()...)
+
+	if m.grpcBindAddress != "" {
+		tlsConfig, err := m.tlsConfig()
+		if err != nil {
+			m.logger.Error("failed to configure TLS", zap.Error(err))
+			return err
+		}
+		rpcSvc := rpc.NewService(authSvc, storage.NewBucketService(bucketSvc, m.engine), orgSvc, taskSvc, pointsWriter,
+			m.logger.With(zap.String("service", "grpc")))
+		rpcSvc.TLSConfig = tlsConfig
+		rpcSvc.MeasurementSchemaService = m.kvService
+		rpcSvc.WriteLimiter = writeLimiter
+		m.subsystems.Register("grpc", subsystem.Funcs{
+			StartFn: func(context.Context) error {
+				return rpcSvc.Open(m.grpcBindAddress)
+			},
+			StopFn: func(context.Context) error {
+				return rpcSvc.Close()
+			},
+		}, true)
+	}
+
+	if m.mqttBroker != "" {
+		subs, err := mqtt.ReadSubscriptionsFile(m.mqttSubsPath)
+		if err != nil {
+			m.logger.Error("failed to read MQTT subscriptions", zap.String("path", m.mqttSubsPath), zap.Error(err))
+			return err
+		}
+		// The subscriptions write to the organizations of their buckets.
+		for i := range subs {
+			b, err := bucketSvc.FindBucketByID(ctx, subs[i].BucketID)
+			if err != nil {
+				m.logger.Error("failed to find MQTT subscription bucket", zap.String("topic", subs[i].Topic), zap.Error(err))
+				return err
+			}
+			subs[i].OrgID = b.OrganizationID
+		}
+		mqttSvc := mqtt.NewService(m.mqttBroker, m.mqttOptions, subs, pointsWriter, m.logger.With(zap.String("service", "mqtt")))
+		mqttSvc.SchemaChecker = schemaChecker
+		m.subsystems.Register("mqtt", subsystem.Funcs{
+			StartFn: func(context.Context) error {
+				return mqttSvc.Open()
+			},
+			StopFn: func(context.Context) error {
+				return mqttSvc.Close()
+			},
+		}, true)
+	}
+
+	if len(m.kafkaBrokers) > 0 {
+		subs, err := kafka.ReadSubscriptionsFile(m.kafkaSubsPath)
+		if err != nil {
+			m.logger.Error("failed to read Kafka subscriptions", zap.String("path", m.kafkaSubsPath), zap.Error(err))
+			return err
+		}
+		// The subscriptions write to the organizations of their buckets.
+		for i := range subs {
+			b, err := bucketSvc.FindBucketByID(ctx, subs[i].BucketID)
+			if err != nil {
+				m.logger.Error("failed to find Kafka subscription bucket", zap.String("topic", subs[i].Topic), zap.Error(err))
+				return err
+			}
+			subs[i].OrgID = b.OrganizationID
+		}
+		kafkaSvc := kafka.NewService(m.kafkaBrokers, subs, pointsWriter, m.kvService, m.logger.With(zap.String("service", "kafka")))
+		kafkaSvc.GroupID = m.kafkaGroupID
+		kafkaSvc.SchemaChecker = schemaChecker
+		kafkaSvc.CheckpointInterval = m.kafkaCheckpointInterval
+		m.reg.MustRegister(kafkaSvc.PrometheusCollectors()...)
+		m.subsystems.Register("kafka", subsystem.Funcs{
+			StartFn: func(context.Context) error {
+				return kafkaSvc.Open()
+			},
+			StopFn: func(context.Context) error {
+				return kafkaSvc.Close()
+			},
+		}, true)
+	}
+
+	if m.reaperInterval > 0 {
+		r := reaper.NewReaper(m.logger)
+		r.Interval = m.reaperInterval
+		r.OrganizationService = orgSvc
+		r.AuthorizationService = authSvc
+		r.DashboardService = dashboardSvc
+		r.TaskService = taskSvc
+		r.UserResourceMappingService = userResourceSvc
+		r.ActivityService = activitySvc
+		m.subsystems.Register("reaper", newRunnerSubsystem(r), true)
+	}
+
+	if m.taskLogCompaction.Interval > 0 {
+		c := taskbackend.NewRunLogCompactor(m.logger.With(zap.String("service", "task-log-compactor")), m.taskLogCompaction,
+			orgSvc, query.QueryServiceBridge{AsyncQueryService: m.queryController.Lane(pcontrol.LaneTask)}, pointsWriter, m.engine)
+		m.subsystems.Register("task-log-compactor", newRunnerSubsystem(c), true)
+	}
+
+	// Bucket clones are created through the API, and copied while the subsystem runs.
+	bucketCloneSvc := bucketclone.NewService(m.logger.With(zap.String("service", "bucket-clone")),
+		storage.NewBucketService(bucketSvc, m.engine), query.QueryServiceBridge{AsyncQueryService: m.queryController.Lane(pcontrol.LaneTask)}, pointsWriter)
+	m.subsystems.Register("bucket-clone", newRunnerSubsystem(bucketCloneSvc), true)
+
+	// Bucket lifecycle policies are materialized as buckets and tasks when they are set through the API.
+	bucketLifecycleSvc := bucketlifecycle.NewService(m.logger.With(zap.String("service", "bucket-lifecycle")),
+		m.kvService, storage.NewBucketService(bucketSvc, m.engine), taskSvc)
 
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
 
+	requestLimiter := http.NewRequestLimiter(m.requestLimits)
+	m.reg.MustRegister(requestLimiter.PrometheusCollectors()...)
+
+	// The write queue is opened before the HTTP server starts, and drains while the subsystem runs.
+	var writeQueue *write.Queue
+	if m.writeQueuePath != "" {
+		writeQueue = write.NewQueue(m.writeQueuePath, pointsWriter)
+		writeQueue.MaxSize = int64(m.writeQueueMaxSize)
+		writeQueue.Logger = m.logger.With(zap.String("service", "write-queue"))
+		m.reg.MustRegister(writeQueue.PrometheusCollectors()...)
+		drainer := newRunnerSubsystem(writeQueue)
+		m.subsystems.Register("write-queue", subsystem.Funcs{
+			StartFn: func(ctx context.Context) error {
+				if err := writeQueue.Open(); err != nil {
+					return err
+				}
+				return drainer.Start(ctx)
+			},
+			StopFn: func(ctx context.Context) error {
+				if err := drainer.Stop(ctx); err != nil {
+					return err
+				}
+				return writeQueue.Close()
+			},
+		}, true)
+	}
+
+	// The API calls creating, updating or deleting resources are written to the audit buckets
+	// until the HTTP server stops, and the events older than the retention are deleted while the subsystem runs.
+	var auditRecorder platform.AuditRecorder
+	var auditLogSvc platform.AuditLogService
+	if !m.auditLogDisabled {
+		auditWriter := audit.NewWriter(pointsWriter, m.logger.With(zap.String("service", "audit-writer")))
+		auditSvc := audit.NewService(m.logger.With(zap.String("service", "audit-log")), m.auditLogRetention,
+			orgSvc, query.QueryServiceBridge{AsyncQueryService: m.queryController.Lane(pcontrol.LaneTask)}, m.engine)
+		retention := newRunnerSubsystem(auditSvc)
+		m.subsystems.Register("audit-log", subsystem.Funcs{
+			StartFn: retention.Start,
+			StopFn: func(ctx context.Context) error {
+				if err := retention.Stop(ctx); err != nil {
+					return err
+				}
+				return auditWriter.Close()
+			},
+		}, false)
+		auditRecorder, auditLogSvc = auditWriter, auditSvc
+	}
+
+	if err := m.corsPolicy.Validate(); err != nil {
+		m.logger.Error("invalid CORS policy", zap.Error(err))
+		return err
+	}
+	cors := http.NewCORS(m.corsPolicy, m.kvService)
+	if err := cors.Load(ctx); err != nil {
+		m.logger.Error("failed to load the stored CORS policy", zap.Error(err))
+		return err
+	}
+
+	certAuths, err := http.ParseCertificateAuthorizations(m.tlsClientCertAuths)
+	if err != nil {
+		m.logger.Error("invalid client certificate authorizations", zap.Error(err))
+		return err
+	}
+	if len(certAuths) > 0 && m.tlsClientCA == "" {
+		err := errors.New("tls-client-cert-authorizations require tls-client-ca")
+		m.logger.Error("invalid client certificate authorizations", zap.Error(err))
+		return err
+	}
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		Logger:               m.logger,
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
 		PointsWriter:         pointsWriter,
+		WriteLimiter:         writeLimiter,
+		RequestLimiter:       requestLimiter,
+		WriteQueue:           writeQueue,
+		WALSegmentReader:     m.engine,
+		SeriesFinder:         m.engine,
+		SeriesCounter:        m.engine,
 		AuthorizationService: authSvc,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
 		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		CoverageGapService:              m.engine,
+		ReplicationService:              m.engine,
+		MeasurementSchemaService:        m.kvService,
+		DeleteJobService:                m.engine,
+		BucketCloneService:              bucketCloneSvc,
+		BucketBatchService:              storage.NewBucketBatchService(m.kvService, m.engine),
+		BucketLifecycleService:          bucketLifecycleSvc,
+		CompactionService:               m.engine,
+		IndexCheckService:               m.engine,
+		LimitsSimulationService:         limitsim.NewService(writeLimiter, queryBulkhead, m.engine),
+		RunningQueryService:             m.queryController,
+		LineageService:                  lineageSvc,
+		ShardService:                    m.engine,
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
 		UserResourceMappingService:      userResourceSvc,
 		LabelService:                    labelSvc,
+		MetadataService:                 metadataSvc,
+		AnnouncementService:             announcementSvc,
+		ExpectedReporterService:         reporterSvc,
+		ExpectedReporterMonitor:         reporter.NewMonitor(query.QueryServiceBridge{AsyncQueryService: m.queryController.Lane(pcontrol.LaneTask)}),
 		DashboardService:                dashboardSvc,
 		DashboardOperationLogService:    dashboardLogSvc,
 		BucketOperationLogService:       bucketLogSvc,
@@ -628,12 +1534,24 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		PasswordsService:                passwdsSvc,
 		OnboardingService:               onboardingSvc,
 		InfluxQLService:                 nil, // No InfluxQL support
+		DBRPMappingService:              dbrpMappingSvc,
 		FluxService:                     storageQueryService,
 		TaskService:                     taskSvc,
+		TaskWebhookService:              taskWebhookSvc,
+		TaskScriptService:               taskScriptSvc,
+		TaskDependencyService:           taskDepsSvc,
+		SQLConnectionService:            sqlConnectionSvc,
+		SQLConnectionChecker:            m.sqlPools,
 		TelegrafService:                 telegrafSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
 		SecretService:                   secretSvc,
+		ActivityService:                 activitySvc,
+		AuditRecorder:                   auditRecorder,
+		AuditLogService:                 auditLogSvc,
+		CertificateAuthorizations:       certAuths,
+		CORS:                            cors,
+		SubsystemService:                m.subsystems,
 		LookupService:                   lookupSvc,
 		ProtoService:                    protoSvc,
 		DocumentService:                 m.kvService,
@@ -650,16 +1568,44 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	h.Logger = httpLogger
 	h.Tracer = opentracing.GlobalTracer()
 
+	modes, err := http.ParseOperationalAuthModes(m.operationalAuth)
+	if err != nil {
+		httpLogger.Error("invalid operational endpoint authentication", zap.Error(err))
+		return err
+	}
+	h.OperationalAuth = &http.OperationalAuth{
+		Modes:                modes,
+		AuthorizationService: authSvc,
+		SessionService:       sessionSvc,
+	}
+	if h.OperationalAuth.Requires(http.OperationalAuthMTLS) && m.tlsClientCA == "" {
+		err := errors.New("operational endpoints authenticated with mtls require tls-client-ca")
+		httpLogger.Error("invalid operational endpoint authentication", zap.Error(err))
+		return err
+	}
+
 	m.httpServer.Handler = h
 	// If we are in testing mode we allow all data to be flushed and removed.
 	if m.testing {
 		m.httpServer.Handler = http.DebugFlush(ctx, h, flusher)
 	}
 
+	// Restarting the HTTP server would drop the request restarting it.
+	m.subsystems.Register("http", subsystem.Funcs{
+		StartFn: func(context.Context) error {
+			return m.serveHTTP(httpLogger)
+		},
+		StopFn: m.httpServer.Shutdown,
+	}, false)
+	return m.subsystems.Start(ctx)
+}
+
+// serveHTTP starts serving the HTTP server on its listener.
+func (m *Launcher) serveHTTP(logger *zap.Logger) error {
 	ln, err := net.Listen("tcp", m.httpBindAddress)
 	if err != nil {
-		httpLogger.Error("failed http listener", zap.Error(err))
-		httpLogger.Info("Stopping")
+		logger.Error("failed http listener", zap.Error(err))
+		logger.Info("Stopping")
 		return err
 	}
 
@@ -667,8 +1613,18 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.httpPort = addr.Port
 	}
 
+	tlsConfig, err := m.tlsConfig()
+	if err != nil {
+		logger.Error("failed to configure TLS", zap.Error(err))
+		ln.Close()
+		return err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
 	m.wg.Add(1)
-	go func(logger *zap.Logger) {
+	go func() {
 		defer m.wg.Done()
 		logger.Info("Listening", zap.String("transport", "http"), zap.String("addr", m.httpBindAddress), zap.Int("port", m.httpPort))
 
@@ -676,11 +1632,56 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			logger.Error("failed http service", zap.Error(err))
 		}
 		logger.Info("Stopping")
-	}(httpLogger)
+	}()
 
 	return nil
 }
 
+// tlsConfig returns the TLS configuration of the HTTP server, or nil if it serves plain HTTP.
+func (m *Launcher) tlsConfig() (*tls.Config, error) {
+	if m.tlsCert == "" && m.tlsKey == "" {
+		if m.tlsClientCA != "" {
+			return nil, errors.New("tls-client-ca requires tls-cert and tls-key")
+		}
+		if m.tlsClientAuth == "require" {
+			return nil, errors.New("tls-client-auth require requires tls-cert and tls-key")
+		}
+		return nil, nil
+	}
+
+	if m.certReloader == nil {
+		return nil, errors.New("TLS certificate is not loaded")
+	}
+	cfg := &tls.Config{GetCertificate: m.certReloader.GetCertificate}
+
+	if m.tlsClientCA != "" {
+		pem, err := ioutil.ReadFile(m.tlsClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates in %s", m.tlsClientCA)
+		}
+		cfg.ClientCAs = pool
+		// Clients authenticating with a token do not need a certificate,
+		// so certificates are only verified when given unless they are required.
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	switch m.tlsClientAuth {
+	case "", "optional":
+	case "require":
+		if m.tlsClientCA == "" {
+			return nil, errors.New("tls-client-auth require requires tls-client-ca")
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown tls-client-auth %q; valid values are optional and require", m.tlsClientAuth)
+	}
+	return cfg, nil
+}
+
 // OrganizationService returns the internal organization service.
 func (m *Launcher) OrganizationService() platform.OrganizationService {
 	return m.apibackend.OrganizationService
@@ -200,6 +200,8 @@ func (l *Launcher) Run(ctx context.Context, args ...string) error {
 	args = append(args, "--engine-path", filepath.Join(l.Path, "engine"))
 	args = append(args, "--http-bind-address", "127.0.0.1:0")
 	args = append(args, "--log-level", "debug")
+	// Write task run states and logs synchronously, so that tests read them as soon as they are written.
+	args = append(args, "--task-log-batch-size", "0")
 	return l.Launcher.Run(ctx, args...)
 }
 
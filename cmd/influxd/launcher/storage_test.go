@@ -91,7 +91,8 @@ func TestLauncher_WriteAndQuery(t *testing.T) {
 }
 
 func TestLauncher_BucketDelete(t *testing.T) {
-	l := RunLauncherOrFail(t, ctx)
+	// The audit event of the delete would add the series of its audit bucket.
+	l := RunLauncherOrFail(t, ctx, "--audit-log-disabled")
 	l.SetupOrFail(t)
 	defer l.ShutdownOrFail(t, ctx)
 
@@ -0,0 +1,142 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/bucketclone"
+	"github.com/influxdata/influxdb/gather"
+	"github.com/influxdata/influxdb/reaper"
+	taskbackend "github.com/influxdata/influxdb/task/backend"
+	"go.uber.org/zap"
+)
+
+// gatherSubsystem runs the scraper scheduler until it is stopped.
+// It can be restarted, as it keeps no state between runs.
+type gatherSubsystem struct {
+	scheduler *gather.Scheduler
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // Why the scheduler stopped running, if it did on its own.
+}
+
+func newGatherSubsystem(scheduler *gather.Scheduler, logger *zap.Logger) *gatherSubsystem {
+	return &gatherSubsystem{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+func (s *gatherSubsystem) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	s.err = nil
+
+	go func(done chan struct{}) {
+		defer close(done)
+		err := s.scheduler.Run(ctx)
+		if err != nil {
+			s.logger.Error("failed scraper service", zap.Error(err))
+		}
+		s.mu.Lock()
+		if err == nil && ctx.Err() == nil {
+			err = errors.New("scraper scheduler stopped")
+		}
+		s.err = err
+		s.mu.Unlock()
+		s.logger.Info("Stopping")
+	}(s.done)
+	return nil
+}
+
+func (s *gatherSubsystem) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *gatherSubsystem) Health(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// runner runs until ctx is done, keeping no state between runs.
+type runner interface {
+	Run(ctx context.Context)
+}
+
+var (
+	_ runner = (*audit.Service)(nil)
+	_ runner = (*bucketclone.Service)(nil)
+	_ runner = (*reaper.Reaper)(nil)
+	_ runner = (*taskbackend.RunLogCompactor)(nil)
+	_ runner = (*certificateReloader)(nil)
+)
+
+// runnerSubsystem runs a runner, such as the inactive resource reaper, until it is stopped.
+// It can be restarted, as the runner keeps no state between runs.
+type runnerSubsystem struct {
+	runner runner
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRunnerSubsystem(r runner) *runnerSubsystem {
+	return &runnerSubsystem{runner: r}
+}
+
+func (s *runnerSubsystem) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		s.runner.Run(ctx)
+	}(s.done)
+	return nil
+}
+
+func (s *runnerSubsystem) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *runnerSubsystem) Health(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package launcher
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// certificateReloader serves the certificate of a TLS certificate and key files,
+// reloading them when they change or the process receives a SIGHUP,
+// so that certificates are renewed without restarting the servers.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string, interval time.Duration, logger *zap.Logger) (*certificateReloader, error) {
+	r := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the certificate last loaded, as the GetCertificate of a tls.Config.
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// reload loads the certificate and key files. The certificate last loaded is kept if they are invalid,
+// such as while they are written.
+func (r *certificateReloader) reload() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// lastModified returns the time the certificate or key file was last modified.
+func (r *certificateReloader) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// changed returns true if the certificate or key file changed since they were last loaded.
+func (r *certificateReloader) changed() bool {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !modTime.Equal(r.modTime)
+}
+
+// Run reloads the certificate when its files change, checking them every interval, or on SIGHUP, until ctx is done.
+func (r *certificateReloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+			if !r.changed() {
+				continue
+			}
+		}
+
+		if err := r.reload(); err != nil {
+			r.logger.Error("Failed to reload TLS certificate", zap.String("cert", r.certFile), zap.Error(err))
+			continue
+		}
+		r.logger.Info("Reloaded TLS certificate", zap.String("cert", r.certFile))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/bench"
 	"github.com/influxdata/influxdb/cmd/influxd/generate"
 	"github.com/influxdata/influxdb/cmd/influxd/inspect"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
@@ -37,6 +38,7 @@ func init() {
 	rootCmd.AddCommand(launcher.NewCommand())
 	rootCmd.AddCommand(generate.Command)
 	rootCmd.AddCommand(inspect.NewCommand())
+	rootCmd.AddCommand(bench.NewCommand())
 }
 
 // find determines the default behavior when running influxd.
@@ -0,0 +1,56 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// CompactionSettings are the settings of the compactions of the storage engine that can change while influxd runs.
+// They are reset to the configuration of influxd when it restarts.
+type CompactionSettings struct {
+	// MaxConcurrent is the number of compactions that can run at the same time.
+	MaxConcurrent int `json:"maxConcurrent"`
+	// Throughput is the rate in bytes per second at which compactions write TSM files, or 0 if it is not limited.
+	Throughput int64 `json:"throughput"`
+	// FullWriteColdDuration is how long the engine goes without writes before all of its TSM files are compacted.
+	FullWriteColdDuration time.Duration `json:"fullWriteColdDuration"`
+}
+
+// CompactionSettingsUpdate changes the settings that are not nil.
+type CompactionSettingsUpdate struct {
+	MaxConcurrent         *int           `json:"maxConcurrent,omitempty"`
+	Throughput            *int64         `json:"throughput,omitempty"`
+	FullWriteColdDuration *time.Duration `json:"fullWriteColdDuration,omitempty"`
+}
+
+// Valid returns an error if the update is invalid.
+func (u *CompactionSettingsUpdate) Valid() error {
+	if u.MaxConcurrent != nil && *u.MaxConcurrent < 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "compaction max concurrent must be at least 1",
+		}
+	}
+	if u.Throughput != nil && *u.Throughput < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "compaction throughput must not be negative",
+		}
+	}
+	if u.FullWriteColdDuration != nil && *u.FullWriteColdDuration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "compaction full write cold duration must not be negative",
+		}
+	}
+	return nil
+}
+
+// CompactionService views and changes the settings of the compactions of the storage engine.
+type CompactionService interface {
+	// FindCompactionSettings returns the current compaction settings.
+	FindCompactionSettings(ctx context.Context) (*CompactionSettings, error)
+
+	// UpdateCompactionSettings changes the compaction settings, and returns the settings in effect.
+	UpdateCompactionSettings(ctx context.Context, upd CompactionSettingsUpdate) (*CompactionSettings, error)
+}
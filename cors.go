@@ -0,0 +1,93 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrCORSPolicyNotFound is the error msg for a missing CORS policy.
+const ErrCORSPolicyNotFound = "CORS policy not found"
+
+// ops for CORS policy errors.
+const (
+	OpFindCORSPolicy   = "FindCORSPolicy"
+	OpUpdateCORSPolicy = "UpdateCORSPolicy"
+	OpDeleteCORSPolicy = "DeleteCORSPolicy"
+)
+
+// CORSPolicy is the policy of the cross-origin requests to the HTTP API, which browser apps
+// served from other origins make.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins allowed to call the API, such as https://app.example.com;
+	// "*" allows any origin.
+	AllowedOrigins []string `json:"allowedOrigins"`
+	AllowedMethods []string `json:"allowedMethods"`
+	AllowedHeaders []string `json:"allowedHeaders"`
+}
+
+// DefaultCORSPolicy returns the policy allowing any origin to call the API.
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "Authorization"},
+	}
+}
+
+// Validate returns an error if an origin is not "*" or a scheme and host, or a method or a header is empty.
+func (p CORSPolicy) Validate() error {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid allowed origin %q: expected * or scheme://host[:port]", o),
+			}
+		}
+	}
+	for _, m := range p.AllowedMethods {
+		if strings.TrimSpace(m) == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "allowed methods cannot be empty",
+			}
+		}
+	}
+	for _, h := range p.AllowedHeaders {
+		if strings.TrimSpace(h) == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "allowed headers cannot be empty",
+			}
+		}
+	}
+	return nil
+}
+
+// AllowsOrigin returns true if requests from origin are allowed.
+func (p CORSPolicy) AllowsOrigin(origin string) bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSPolicyService stores the CORS policy of the HTTP API, which takes precedence
+// over the policy configured at startup.
+type CORSPolicyService interface {
+	// FindCORSPolicy returns the stored policy, or an ENotFound error if there is none.
+	FindCORSPolicy(ctx context.Context) (*CORSPolicy, error)
+
+	// UpdateCORSPolicy stores the policy.
+	UpdateCORSPolicy(ctx context.Context, p CORSPolicy) (*CORSPolicy, error)
+
+	// DeleteCORSPolicy deletes the stored policy.
+	DeleteCORSPolicy(ctx context.Context) error
+}
@@ -0,0 +1,93 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// MaxCoverageIntervals is the largest number of intervals that the window of a CoverageGapRequest can be split into.
+const MaxCoverageIntervals = 100000
+
+// CoverageGapService finds the gaps in the data of buckets.
+type CoverageGapService interface {
+	// FindCoverageGaps returns the gaps in the coverage of the measurement of a bucket over the window of req,
+	// in time order.
+	FindCoverageGaps(ctx context.Context, req CoverageGapRequest) ([]*CoverageGap, error)
+}
+
+// CoverageGapRequest asks for the gaps in a measurement of a bucket, which is expected to have
+// a point at least once every Interval between Start and Stop.
+//
+// The window is split into intervals of Interval from Start, and an interval is covered if any series
+// of the measurement has a point within it. The last interval is cut short at Stop.
+type CoverageGapRequest struct {
+	OrgID       ID            `json:"orgID"`
+	BucketID    ID            `json:"bucketID"`
+	Measurement string        `json:"measurement"`
+	Interval    time.Duration `json:"interval"`
+	Start       time.Time     `json:"start"`
+	Stop        time.Time     `json:"stop"`
+}
+
+// Validate returns an error if the request is invalid.
+func (r *CoverageGapRequest) Validate() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap orgID is required",
+		}
+	}
+	if !r.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap bucketID is required",
+		}
+	}
+	if r.Measurement == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap measurement is required",
+		}
+	}
+	if r.Interval < time.Second {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap interval must be at least 1 second",
+		}
+	}
+	if !r.Start.Before(r.Stop) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap start must be before stop",
+		}
+	}
+	if r.Intervals() > MaxCoverageIntervals {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "coverage gap window is split into too many intervals",
+		}
+	}
+	return nil
+}
+
+// Intervals returns the number of intervals that the window of the request is split into.
+func (r *CoverageGapRequest) Intervals() int64 {
+	window := r.Stop.Sub(r.Start)
+	n := int64(window / r.Interval)
+	if window%r.Interval != 0 {
+		n++
+	}
+	return n
+}
+
+// CoverageGap is a time range without any point, made of one or more consecutive intervals of a CoverageGapRequest.
+// Start is inclusive and Stop is exclusive.
+type CoverageGap struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+// Duration returns the length of the gap.
+func (g *CoverageGap) Duration() time.Duration {
+	return g.Stop.Sub(g.Start)
+}
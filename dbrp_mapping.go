@@ -89,12 +89,23 @@ func (m *DBRPMapping) Equal(o *DBRPMapping) bool {
 		m.BucketID == o.BucketID
 }
 
-// DBRPMappingFilter represents a set of filters that restrict the returned results by cluster, database and retention policy.
+// DBRPMappingFilter represents a set of filters that restrict the returned results by cluster, database and retention policy,
+// and by the organization they map to.
 type DBRPMappingFilter struct {
 	Cluster         *string
 	Database        *string
 	RetentionPolicy *string
 	Default         *bool
+	OrganizationID  *ID
+}
+
+// Matches returns true if m matches every filter of f.
+func (f DBRPMappingFilter) Matches(m *DBRPMapping) bool {
+	return (f.Cluster == nil || *f.Cluster == m.Cluster) &&
+		(f.Database == nil || *f.Database == m.Database) &&
+		(f.RetentionPolicy == nil || *f.RetentionPolicy == m.RetentionPolicy) &&
+		(f.Default == nil || *f.Default == m.Default) &&
+		(f.OrganizationID == nil || *f.OrganizationID == m.OrganizationID)
 }
 
 func (f DBRPMappingFilter) String() string {
@@ -127,6 +138,13 @@ func (f DBRPMappingFilter) String() string {
 	} else {
 		s.WriteString("<nil>")
 	}
+
+	s.WriteString(" org:")
+	if f.OrganizationID != nil {
+		s.WriteString(f.OrganizationID.String())
+	} else {
+		s.WriteString("<nil>")
+	}
 	s.WriteString("}")
 	return s.String()
 }
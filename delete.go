@@ -0,0 +1,99 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// DeleteService deletes the data of buckets.
+type DeleteService interface {
+	// DeleteBucketRangePredicate deletes the points of the series of a bucket matching the predicate of req,
+	// over the window of req.
+	DeleteBucketRangePredicate(ctx context.Context, req DeleteRequest) error
+}
+
+// DeleteRequest asks for the deletion of the points of a bucket between Start and Stop.
+//
+// Predicate restricts the deletion to the series whose tags match it, such as
+// `_measurement="cpu" AND (host="a" OR host="b")`. Tags are compared with = and !=,
+// the measurement and the field of a series are the _measurement and _field tags,
+// and an empty predicate matches every series of the bucket.
+type DeleteRequest struct {
+	OrgID     ID        `json:"orgID"`
+	BucketID  ID        `json:"bucketID"`
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	Predicate string    `json:"predicate"`
+}
+
+// Validate returns an error if the request is invalid.
+func (r *DeleteRequest) Validate() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "delete orgID is required",
+		}
+	}
+	if !r.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "delete bucketID is required",
+		}
+	}
+	if !r.Start.Before(r.Stop) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "delete start must be before stop",
+		}
+	}
+	return nil
+}
+
+// Statuses of a DeleteJob.
+const (
+	DeleteJobQueued  = "queued"
+	DeleteJobRunning = "running"
+	DeleteJobSuccess = "success"
+	DeleteJobFailed  = "failed"
+)
+
+// DeleteJobService runs deletes in the background, as jobs whose progress can be followed.
+type DeleteJobService interface {
+	// CreateDeleteJob queues a job deleting the points of the series of a bucket matching the predicate of req.
+	CreateDeleteJob(ctx context.Context, req DeleteRequest) (*DeleteJob, error)
+
+	// FindDeleteJobByID returns a single delete job by ID.
+	FindDeleteJobByID(ctx context.Context, id ID) (*DeleteJob, error)
+
+	// FindDeleteJobs returns the delete jobs matching filter, most recent first.
+	FindDeleteJobs(ctx context.Context, filter DeleteJobFilter) ([]*DeleteJob, error)
+}
+
+// DeleteJob is a delete running in the background.
+//
+// A delete by predicate first finds the series matching its predicate, then deletes their points in batches.
+// SeriesTotal is the number of series found, and SeriesProcessed the number of series deleted so far.
+// TombstonesWritten is the number of series of TSM files whose points were tombstoned so far.
+type DeleteJob struct {
+	ID                ID            `json:"id"`
+	Request           DeleteRequest `json:"request"`
+	Status            string        `json:"status"`
+	Error             string        `json:"error,omitempty"`
+	SeriesTotal       int64         `json:"seriesTotal"`
+	SeriesProcessed   int64         `json:"seriesProcessed"`
+	TombstonesWritten int64         `json:"tombstonesWritten"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	StartedAt         time.Time     `json:"startedAt"`
+	FinishedAt        time.Time     `json:"finishedAt"`
+}
+
+// Finished returns true if the job succeeded or failed.
+func (j *DeleteJob) Finished() bool {
+	return j.Status == DeleteJobSuccess || j.Status == DeleteJobFailed
+}
+
+// DeleteJobFilter represents a set of filters that restrict the returned delete jobs.
+type DeleteJobFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
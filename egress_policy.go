@@ -0,0 +1,114 @@
+package influxdb
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EgressPolicy restricts the outbound connections made by the Flux queries and tasks of an organization:
+// the hosts http.to sends to, the databases sql.from reads, how long they take and how much they send.
+// The outbound connections of the queries of an organization without an egress policy are not restricted,
+// unless the server is configured to deny them.
+//
+// Hosts and data source names are patterns, in which * matches any sequence of characters.
+type EgressPolicy struct {
+	// AllowedHosts are the hosts of the URLs http.to may send to, such as "hooks.example.com" or "*.example.com".
+	// A pattern with a port, such as "10.0.0.1:8086", only matches the URLs with this port.
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+
+	// AllowedDSNs are the data source names sql.from may connect with, such as "postgres://*@db.example.com/metrics".
+	// Matching the credentials with * keeps them out of the policy.
+	AllowedDSNs []string `json:"allowedDSNs,omitempty"`
+
+	// Timeout is the longest an http.to request or a sql.from query may take. If zero, it is not limited.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// MaxEgressBytes is the largest body a query may send with http.to. If zero, it is not limited.
+	MaxEgressBytes int64 `json:"maxEgressBytes,omitempty"`
+}
+
+// Validate returns an error if the policy is invalid.
+func (p *EgressPolicy) Validate() error {
+	if p.Timeout < 0 || p.MaxEgressBytes < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "egress policy timeout and byte limit must not be negative",
+		}
+	}
+	for _, h := range p.AllowedHosts {
+		if h == "" || strings.ContainsAny(h, "/?#@") {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("egress policy allowed host %q is not a host pattern", h),
+			}
+		}
+	}
+	for _, dsn := range p.AllowedDSNs {
+		if dsn == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "egress policy allowed data source names must not be empty",
+			}
+		}
+	}
+	return nil
+}
+
+// CheckURL returns an EForbidden error unless the policy allows sending to the http or https URL rawurl.
+func (p *EgressPolicy) CheckURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("%q is not an absolute http or https URL", rawurl),
+		}
+	}
+
+	for _, pattern := range p.AllowedHosts {
+		host := u.Hostname()
+		if strings.Contains(pattern, ":") {
+			host = u.Host
+			if u.Port() == "" {
+				host += ":" + defaultPorts[u.Scheme]
+			}
+		}
+		if matchEgressPattern(strings.ToLower(pattern), strings.ToLower(host)) {
+			return nil
+		}
+	}
+	return &Error{
+		Code: EForbidden,
+		Msg:  fmt.Sprintf("the egress policy of the organization does not allow host %q", u.Host),
+	}
+}
+
+// CheckDSN returns an EForbidden error unless the policy allows connecting with the data source name dsn.
+// The error does not show dsn, which may hold credentials.
+func (p *EgressPolicy) CheckDSN(dsn string) error {
+	for _, pattern := range p.AllowedDSNs {
+		if matchEgressPattern(pattern, dsn) {
+			return nil
+		}
+	}
+	return &Error{
+		Code: EForbidden,
+		Msg:  "the egress policy of the organization does not allow the data source name",
+	}
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// matchEgressPattern returns true if s matches pattern, in which * matches any sequence of characters.
+func matchEgressPattern(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(s)
+}
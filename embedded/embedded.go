@@ -0,0 +1,317 @@
+// Package embedded runs influxdb as a library, in the process of another Go program.
+//
+// An embedded DB opens the storage engine, the query controller and the task scheduler of influxd
+// without its HTTP API. Points are written and Flux queries are run through the methods of the DB,
+// and the organizations, buckets, authorizations and tasks are managed through its services.
+//
+// Tasks are created in a context holding an authorizer, see the context package, with the token
+// of an authorization of the organization of the task, the same way as through the HTTP API.
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	_ "github.com/influxdata/influxdb/query/builtin" // needed to compile Flux queries
+	pcontrol "github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/stdlib/egress"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/catalog"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/task"
+	taskbackend "github.com/influxdata/influxdb/task/backend"
+	taskbolt "github.com/influxdata/influxdb/task/backend/bolt"
+	"github.com/influxdata/influxdb/task/backend/coordinator"
+	taskexecutor "github.com/influxdata/influxdb/task/backend/executor"
+	"github.com/influxdata/influxdb/tsdb"
+	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultConcurrencyQuota is the number of queries run at the same time by default.
+	DefaultConcurrencyQuota = 10
+
+	// DefaultMemoryBytesQuota is the memory in bytes used by all the queries running by default.
+	DefaultMemoryBytesQuota = 1e6
+)
+
+// Config is the configuration of an embedded DB.
+type Config struct {
+	// Path is the directory of the files of the DB, which is created if it does not exist.
+	// The REST resources and the tasks are stored in influxd.bolt, and the points in engine.
+	Path string
+
+	// StorageConfig is the configuration of the storage engine.
+	StorageConfig storage.Config
+
+	// ConcurrencyQuota is the number of queries and task runs that run at the same time,
+	// and MemoryBytesQuota the memory in bytes they use. The defaults are used if they are 0.
+	ConcurrencyQuota int
+	MemoryBytesQuota int64
+
+	// Precision is the precision of the timestamps of the points written, such as "s" or "ms".
+	// It is "ns" if empty.
+	Precision string
+
+	// Logger is the logger of the DB. Nothing is logged if it is nil.
+	Logger *zap.Logger
+}
+
+// NewConfig returns the default configuration of a DB storing its files in path.
+func NewConfig(path string) Config {
+	return Config{
+		Path:             path,
+		StorageConfig:    storage.NewConfig(),
+		ConcurrencyQuota: DefaultConcurrencyQuota,
+		MemoryBytesQuota: DefaultMemoryBytesQuota,
+		Precision:        "ns",
+	}
+}
+
+// DB is influxdb embedded in the current process.
+type DB struct {
+	OrganizationService  platform.OrganizationService
+	UserService          platform.UserService
+	AuthorizationService platform.AuthorizationService
+	BucketService        platform.BucketService
+	TaskService          platform.TaskService
+
+	// QueryService runs Flux queries, see also Query.
+	QueryService query.QueryService
+
+	config Config
+	logger *zap.Logger
+
+	cancel    context.CancelFunc
+	bolt      *bolt.Client
+	engine    *storage.Engine
+	queries   *pcontrol.Controller
+	scheduler *taskbackend.TickScheduler
+	logWriter *taskbackend.PointLogWriter
+
+	closeOnce sync.Once
+}
+
+var _ platform.WriteService = (*DB)(nil)
+
+// Open opens the DB with config, and starts running its tasks.
+//
+// The DB must be closed to release its files before it is opened again.
+func Open(ctx context.Context, config Config) (*DB, error) {
+	if config.Path == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "embedded db path is required",
+		}
+	}
+	if config.ConcurrencyQuota == 0 {
+		config.ConcurrencyQuota = DefaultConcurrencyQuota
+	}
+	if config.MemoryBytesQuota == 0 {
+		config.MemoryBytesQuota = DefaultMemoryBytesQuota
+	}
+	if config.Precision == "" {
+		config.Precision = "ns"
+	}
+	if !models.ValidPrecision(config.Precision) {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid precision %q", config.Precision),
+		}
+	}
+	if err := os.MkdirAll(config.Path, 0700); err != nil {
+		return nil, err
+	}
+
+	db := &DB{
+		config: config,
+		logger: config.Logger,
+	}
+	if db.logger == nil {
+		db.logger = zap.NewNop()
+	}
+
+	if err := db.open(ctx); err != nil {
+		db.Close(ctx)
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *DB) open(ctx context.Context) error {
+	// The ticker of the scheduler runs until the DB closes, not until ctx is done.
+	schedulerCtx, cancel := context.WithCancel(context.Background())
+	db.cancel = cancel
+
+	boltPath := filepath.Join(db.config.Path, "influxd.bolt")
+	db.bolt = bolt.NewClient()
+	db.bolt.Path = boltPath
+	db.bolt.WithLogger(db.logger.With(zap.String("service", "bolt")))
+	if err := db.bolt.Open(ctx); err != nil {
+		return err
+	}
+
+	store := bolt.NewKVStore(boltPath)
+	store.WithDB(db.bolt.DB())
+	kvService := kv.NewService(store)
+	kvService.Logger = db.logger.With(zap.String("store", "kv"))
+	if err := kvService.Initialize(ctx); err != nil {
+		return err
+	}
+
+	var (
+		orgSvc          platform.OrganizationService        = kvService
+		userSvc         platform.UserService                = kvService
+		authSvc         platform.AuthorizationService       = kvService
+		bucketSvc       platform.BucketService              = kvService
+		userResourceSvc platform.UserResourceMappingService = kvService
+		taskScriptSvc   platform.TaskScriptService          = kvService
+	)
+
+	db.engine = storage.NewEngine(filepath.Join(db.config.Path, "engine"), db.config.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+	db.engine.WithLogger(db.logger)
+	if err := db.engine.Open(ctx); err != nil {
+		return err
+	}
+
+	cc := control.Config{
+		ExecutorDependencies: make(execute.Dependencies),
+		ConcurrencyQuota:     db.config.ConcurrencyQuota,
+		MemoryBytesQuota:     db.config.MemoryBytesQuota,
+		Logger:               db.logger.With(zap.String("service", "storage-reads")),
+	}
+	if err := readservice.AddControllerConfigDependencies(&cc, db.engine, bucketSvc, orgSvc); err != nil {
+		return err
+	}
+	catalogDeps := &catalog.Dependencies{
+		BucketService:              bucketSvc,
+		UserService:                userSvc,
+		UserResourceMappingService: userResourceSvc,
+	}
+	if err := catalog.InjectDependencies(cc.ExecutorDependencies, catalogDeps); err != nil {
+		return err
+	}
+	if err := egress.InjectDependencies(cc.ExecutorDependencies, egress.Dependencies{OrganizationService: orgSvc}); err != nil {
+		return err
+	}
+	db.queries = pcontrol.New(cc)
+	queryService := query.QueryServiceBridge{AsyncQueryService: db.queries}
+
+	taskStore, err := taskbolt.New(db.bolt.DB(), "tasks", taskbolt.NoCatchUp)
+	if err != nil {
+		return err
+	}
+	executor := taskexecutor.NewAsyncQueryServiceExecutor(db.logger.With(zap.String("service", "task-executor")), db.queries, authSvc, taskStore, taskexecutor.WithTaskScriptService(taskScriptSvc))
+	db.logWriter = taskbackend.NewPointLogWriter(db.engine,
+		taskbackend.WithLogWriterLogger(db.logger.With(zap.String("service", "task-log-writer"))),
+	)
+	db.scheduler = taskbackend.NewScheduler(taskStore, executor, db.logWriter, time.Now().UTC().Unix(), taskbackend.WithTicker(schedulerCtx, 100*time.Millisecond), taskbackend.WithLogger(db.logger))
+	db.scheduler.Start(schedulerCtx)
+
+	lr := taskbackend.NewQueryLogReader(queryService)
+	taskSvc := task.PlatformAdapter(coordinator.New(db.logger.With(zap.String("service", "task-coordinator")), db.scheduler, taskStore), lr, db.logWriter, db.scheduler, authSvc, userResourceSvc, orgSvc)
+	// The catalog filters tasks by the authorization of the query itself.
+	catalogDeps.TaskService = taskSvc
+
+	db.OrganizationService = orgSvc
+	db.UserService = userSvc
+	db.AuthorizationService = authSvc
+	// Deleting a bucket deletes its points from the engine.
+	db.BucketService = storage.NewBucketService(bucketSvc, db.engine)
+	db.TaskService = taskSvc
+	db.QueryService = queryService
+	return nil
+}
+
+// Write writes the points in line protocol read from r to a bucket,
+// with timestamps of the precision of the configuration of the DB.
+func (db *DB) Write(ctx context.Context, orgID, bucketID platform.ID, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	points, err := models.ParsePointsWithPrecision(data, time.Now(), db.config.Precision)
+	if err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("unable to parse points: %v", err),
+			Err:  err,
+		}
+	}
+	return db.WritePoints(ctx, orgID, bucketID, points)
+}
+
+// WritePoints writes points to a bucket.
+func (db *DB) WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error {
+	exploded, err := tsdb.ExplodePoints(orgID, bucketID, points)
+	if err != nil {
+		return err
+	}
+	return db.engine.WritePoints(ctx, exploded)
+}
+
+// Query runs the Flux script in the organization orgID.
+// Release must be called on the returned results to free their resources.
+func (db *DB) Query(ctx context.Context, orgID platform.ID, script string) (flux.ResultIterator, error) {
+	return db.QueryService.Query(ctx, &query.Request{
+		OrganizationID: orgID,
+		Compiler: lang.FluxCompiler{
+			Query: script,
+		},
+	})
+}
+
+// Engine returns the storage engine of the DB.
+func (db *DB) Engine() *storage.Engine {
+	return db.engine
+}
+
+// Close stops running tasks, waits for the queries running to finish until ctx is done,
+// and closes the files of the DB.
+func (db *DB) Close(ctx context.Context) error {
+	var err error
+	db.closeOnce.Do(func() {
+		if db.scheduler != nil {
+			db.scheduler.Stop()
+			if e := db.logWriter.Flush(ctx); e != nil {
+				db.logger.Info("Failed writing batched task run states and logs", zap.Error(e))
+			}
+		}
+		if db.cancel != nil {
+			db.cancel()
+		}
+		if db.queries != nil {
+			if e := db.queries.Shutdown(ctx); e != nil && e != context.Canceled && err == nil {
+				err = e
+			}
+		}
+		if db.engine != nil {
+			if e := db.engine.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		if db.bolt != nil {
+			if e := db.bolt.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	})
+	return err
+}
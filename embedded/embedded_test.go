@@ -0,0 +1,99 @@
+package embedded_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/embedded"
+)
+
+func TestDB_WriteQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxdb-embedded-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	db, err := embedded.Open(ctx, embedded.NewConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := &platform.Organization{Name: "org"}
+	if err := db.OrganizationService.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &platform.Bucket{OrganizationID: org.ID, Name: "bucket"}
+	if err := db.BucketService.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Write(ctx, org.ID, bucket.ID, strings.NewReader("cpu,host=a value=1 1000000000\ncpu,host=b value=2 2000000000\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	script := `from(bucket: "bucket") |> range(start: 0) |> filter(fn: (r) => r._measurement == "cpu")`
+	if got, want := countRows(t, db, org.ID, script), 2; got != want {
+		t.Fatalf("got %d rows, want %d", got, want)
+	}
+
+	// The points are still there once the DB opens again.
+	if err := db.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	db, err = embedded.Open(ctx, embedded.NewConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close(ctx)
+
+	if got, want := countRows(t, db, org.ID, script), 2; got != want {
+		t.Fatalf("got %d rows after reopening, want %d", got, want)
+	}
+}
+
+func TestOpen_InvalidConfig(t *testing.T) {
+	ctx := context.Background()
+	if _, err := embedded.Open(ctx, embedded.Config{}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("got error %v without a path, want invalid", err)
+	}
+
+	config := embedded.NewConfig("unused")
+	config.Precision = "d"
+	if _, err := embedded.Open(ctx, config); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("got error %v with an invalid precision, want invalid", err)
+	}
+}
+
+func countRows(t *testing.T, db *embedded.DB, orgID platform.ID, script string) int {
+	t.Helper()
+
+	results, err := db.Query(context.Background(), orgID, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer results.Release()
+
+	n := 0
+	for results.More() {
+		err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				n += cr.Len()
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
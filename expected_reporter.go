@@ -0,0 +1,146 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrExpectedReporterNotFound is the error msg for a missing expected reporter.
+const ErrExpectedReporterNotFound = "expected reporter not found"
+
+// ops for expected reporter error
+const (
+	OpFindExpectedReporterByID = "FindExpectedReporterByID"
+	OpFindExpectedReporters    = "FindExpectedReporters"
+	OpCreateExpectedReporter   = "CreateExpectedReporter"
+	OpUpdateExpectedReporter   = "UpdateExpectedReporter"
+	OpDeleteExpectedReporter   = "DeleteExpectedReporter"
+)
+
+// ExpectedReporterService represents a service for managing the sources expected to write to buckets regularly.
+type ExpectedReporterService interface {
+	// FindExpectedReporterByID returns a single expected reporter by ID.
+	FindExpectedReporterByID(ctx context.Context, id ID) (*ExpectedReporter, error)
+
+	// FindExpectedReporters returns the expected reporters that match a filter.
+	FindExpectedReporters(ctx context.Context, filter ExpectedReporterFilter) ([]*ExpectedReporter, error)
+
+	// CreateExpectedReporter creates a new expected reporter and sets r.ID with the new identifier.
+	CreateExpectedReporter(ctx context.Context, r *ExpectedReporter) error
+
+	// UpdateExpectedReporter updates a single expected reporter with changeset.
+	// Returns the new expected reporter state after update.
+	UpdateExpectedReporter(ctx context.Context, id ID, upd ExpectedReporterUpdate) (*ExpectedReporter, error)
+
+	// DeleteExpectedReporter removes an expected reporter by ID.
+	DeleteExpectedReporter(ctx context.Context, id ID) error
+}
+
+// ExpectedReporter is a source expected to write points to a bucket at least once every Interval,
+// e.g. a host of a fleet running Telegraf.
+// The points of the source are the points of the bucket with the tag TagKey set to TagValue.
+type ExpectedReporter struct {
+	ID       ID `json:"id,omitempty"`
+	OrgID    ID `json:"orgID"`
+	BucketID ID `json:"bucketID"`
+
+	// Measurement, if set, only counts the points of the source in that measurement.
+	Measurement string `json:"measurement,omitempty"`
+
+	TagKey   string        `json:"tagKey"`
+	TagValue string        `json:"tagValue"`
+	Interval time.Duration `json:"interval"`
+}
+
+// Validate returns an error if the expected reporter is invalid.
+func (r *ExpectedReporter) Validate() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expected reporter orgID is required",
+		}
+	}
+	if !r.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expected reporter bucketID is required",
+		}
+	}
+	if r.TagKey == "" || r.TagValue == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expected reporter tagKey and tagValue are required",
+		}
+	}
+	if r.Interval < time.Second {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expected reporter interval must be at least 1 second",
+		}
+	}
+	return nil
+}
+
+// ExpectedReporterFilter represents a set of filters that restrict the returned expected reporters.
+type ExpectedReporterFilter struct {
+	ID       *ID
+	OrgID    *ID
+	BucketID *ID
+}
+
+// Matches returns true if r passes the filter.
+func (f ExpectedReporterFilter) Matches(r *ExpectedReporter) bool {
+	if f.ID != nil && *f.ID != r.ID {
+		return false
+	}
+	if f.OrgID != nil && *f.OrgID != r.OrgID {
+		return false
+	}
+	if f.BucketID != nil && *f.BucketID != r.BucketID {
+		return false
+	}
+	return true
+}
+
+// ExpectedReporterUpdate is the set of changes that can be applied to an expected reporter.
+// The bucket of an expected reporter cannot change.
+type ExpectedReporterUpdate struct {
+	Measurement *string        `json:"measurement,omitempty"`
+	TagKey      *string        `json:"tagKey,omitempty"`
+	TagValue    *string        `json:"tagValue,omitempty"`
+	Interval    *time.Duration `json:"interval,omitempty"`
+}
+
+// Apply applies the update to r and validates the result.
+func (u ExpectedReporterUpdate) Apply(r *ExpectedReporter) error {
+	if u.Measurement != nil {
+		r.Measurement = *u.Measurement
+	}
+	if u.TagKey != nil {
+		r.TagKey = *u.TagKey
+	}
+	if u.TagValue != nil {
+		r.TagValue = *u.TagValue
+	}
+	if u.Interval != nil {
+		r.Interval = *u.Interval
+	}
+	return r.Validate()
+}
+
+// ExpectedReporterStatus tells whether an expected reporter has written to its bucket within its interval.
+type ExpectedReporterStatus struct {
+	ExpectedReporter
+
+	// LastSeen is the time of the latest point of the reporter, if one was found.
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+
+	// Missing is true if the reporter has not written a point within its interval.
+	Missing bool `json:"missing"`
+}
+
+// ExpectedReporterMonitor finds out whether expected reporters are still writing to their buckets.
+type ExpectedReporterMonitor interface {
+	// ExpectedReporterStatuses returns the current status of each of reporters, in the same order.
+	ExpectedReporterStatuses(ctx context.Context, reporters []*ExpectedReporter) ([]*ExpectedReporterStatus, error)
+}
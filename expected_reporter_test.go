@@ -0,0 +1,96 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestExpectedReporterValidate(t *testing.T) {
+	valid := func() influxdb.ExpectedReporter {
+		return influxdb.ExpectedReporter{
+			OrgID:    1,
+			BucketID: 2,
+			TagKey:   "host",
+			TagValue: "server01",
+			Interval: time.Minute,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*influxdb.ExpectedReporter)
+		wantErr bool
+	}{
+		{
+			name:   "valid expected reporter",
+			modify: func(*influxdb.ExpectedReporter) {},
+		},
+		{
+			name:   "valid expected reporter with measurement",
+			modify: func(r *influxdb.ExpectedReporter) { r.Measurement = "cpu" },
+		},
+		{
+			name:    "missing org",
+			modify:  func(r *influxdb.ExpectedReporter) { r.OrgID = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "missing bucket",
+			modify:  func(r *influxdb.ExpectedReporter) { r.BucketID = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "missing tag key",
+			modify:  func(r *influxdb.ExpectedReporter) { r.TagKey = "" },
+			wantErr: true,
+		},
+		{
+			name:    "missing tag value",
+			modify:  func(r *influxdb.ExpectedReporter) { r.TagValue = "" },
+			wantErr: true,
+		},
+		{
+			name:    "sub-second interval",
+			modify:  func(r *influxdb.ExpectedReporter) { r.Interval = time.Millisecond },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.modify(&r)
+			err := r.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpectedReporterUpdateApply(t *testing.T) {
+	r := &influxdb.ExpectedReporter{
+		ID:       3,
+		OrgID:    1,
+		BucketID: 2,
+		TagKey:   "host",
+		TagValue: "server01",
+		Interval: time.Minute,
+	}
+
+	measurement := "cpu"
+	interval := 5 * time.Minute
+	if err := (influxdb.ExpectedReporterUpdate{Measurement: &measurement, Interval: &interval}).Apply(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Measurement != "cpu" || r.Interval != 5*time.Minute || r.TagValue != "server01" {
+		t.Errorf("unexpected expected reporter after update: %+v", r)
+	}
+
+	empty := ""
+	if err := (influxdb.ExpectedReporterUpdate{TagValue: &empty}).Apply(r); err == nil {
+		t.Error("expected error for update removing the tag value")
+	}
+}
@@ -0,0 +1,169 @@
+package flight
+
+import (
+	"encoding/binary"
+)
+
+// The metadata of Arrow IPC messages is encoded as flatbuffers.
+// The few tables of the messages written by the service are built with the minimal builder below
+// instead of code generated from the Arrow schema files.
+
+// fbObject is an object referred to by an offset: a table, a string or a vector.
+type fbObject interface {
+	// writeTo appends the object to b and returns its position.
+	writeTo(b *fbBuilder) int
+}
+
+// fbField is a field of a table: a scalar of size bytes, an offset to ref, or an absent field.
+type fbField struct {
+	size  int
+	value uint64
+	ref   fbObject
+}
+
+func fbBool(v bool) fbField {
+	if v {
+		return fbField{size: 1, value: 1}
+	}
+	return fbField{size: 1}
+}
+
+func fbUint8(v uint8) fbField   { return fbField{size: 1, value: uint64(v)} }
+func fbInt16(v int16) fbField   { return fbField{size: 2, value: uint64(uint16(v))} }
+func fbInt32(v int32) fbField   { return fbField{size: 4, value: uint64(uint32(v))} }
+func fbInt64(v int64) fbField   { return fbField{size: 8, value: uint64(v)} }
+func fbRef(o fbObject) fbField  { return fbField{ref: o} }
+func fbString(s string) fbField { return fbRef(fbStringObject(s)) }
+
+// fbTable is a table, whose fields are in the order of their IDs.
+type fbTable []fbField
+
+// fbVector is a vector of offsets to tables.
+type fbVector []fbObject
+
+// fbStructs is a vector of n structs of 8-byte aligned fields, encoded as data.
+type fbStructs struct {
+	n    int
+	data []byte
+}
+
+type fbStringObject string
+
+// fbBuilder builds a flatbuffer front to back: every object is written before the objects it refers to,
+// so that offsets, which flatbuffers require to point forward, are patched once the objects are written.
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbFinish returns the flatbuffer of the root table t.
+func fbFinish(t fbTable) []byte {
+	b := &fbBuilder{buf: make([]byte, 4, 256)}
+	b.patch(0, t.writeTo(b))
+	return b.buf
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) appendUint32(v uint32) {
+	var x [4]byte
+	binary.LittleEndian.PutUint32(x[:], v)
+	b.buf = append(b.buf, x[:]...)
+}
+
+// patch sets the offset at site to the object at pos.
+func (b *fbBuilder) patch(site, pos int) {
+	binary.LittleEndian.PutUint32(b.buf[site:], uint32(pos-site))
+}
+
+func (t fbTable) writeTo(b *fbBuilder) int {
+	// The table starts with the offset to its vtable, followed by its present fields aligned to their size.
+	offsets := make([]int, len(t))
+	size, align := 4, 4
+	for i, f := range t {
+		n := f.size
+		if f.ref != nil {
+			n = 4
+		}
+		if n == 0 {
+			continue
+		}
+		size = (size + n - 1) / n * n
+		offsets[i] = size
+		size += n
+		if n > align {
+			align = n
+		}
+	}
+
+	// The vtable immediately precedes the table, which is aligned to its largest field.
+	vtLen := 4 + 2*len(t)
+	start := (len(b.buf) + vtLen + align - 1) / align * align
+	for len(b.buf) < start-vtLen {
+		b.buf = append(b.buf, 0)
+	}
+	vt := make([]byte, vtLen)
+	binary.LittleEndian.PutUint16(vt[0:], uint16(vtLen))
+	binary.LittleEndian.PutUint16(vt[2:], uint16(size))
+	for i, off := range offsets {
+		binary.LittleEndian.PutUint16(vt[4+2*i:], uint16(off))
+	}
+	b.buf = append(b.buf, vt...)
+
+	table := make([]byte, size)
+	binary.LittleEndian.PutUint32(table, uint32(vtLen))
+	for i, f := range t {
+		switch f.size {
+		case 1:
+			table[offsets[i]] = byte(f.value)
+		case 2:
+			binary.LittleEndian.PutUint16(table[offsets[i]:], uint16(f.value))
+		case 4:
+			binary.LittleEndian.PutUint32(table[offsets[i]:], uint32(f.value))
+		case 8:
+			binary.LittleEndian.PutUint64(table[offsets[i]:], f.value)
+		}
+	}
+	b.buf = append(b.buf, table...)
+
+	for i, f := range t {
+		if f.ref != nil {
+			b.patch(start+offsets[i], f.ref.writeTo(b))
+		}
+	}
+	return start
+}
+
+func (v fbVector) writeTo(b *fbBuilder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.appendUint32(uint32(len(v)))
+	b.buf = append(b.buf, make([]byte, 4*len(v))...)
+	for i, o := range v {
+		b.patch(pos+4+4*i, o.writeTo(b))
+	}
+	return pos
+}
+
+func (s fbStructs) writeTo(b *fbBuilder) int {
+	// The structs follow the length of the vector, aligned to their 8-byte fields.
+	for (len(b.buf)+4)%8 != 0 {
+		b.buf = append(b.buf, 0)
+	}
+	pos := len(b.buf)
+	b.appendUint32(uint32(s.n))
+	b.buf = append(b.buf, s.data...)
+	return pos
+}
+
+func (s fbStringObject) writeTo(b *fbBuilder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.appendUint32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
@@ -0,0 +1,250 @@
+package flight
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+)
+
+// The identifiers of the Arrow IPC format used by the messages written by the service.
+const (
+	metadataVersionV4 = 3
+
+	messageHeaderSchema      = 1
+	messageHeaderRecordBatch = 3
+
+	typeInt           = 2
+	typeFloatingPoint = 3
+	typeUtf8          = 5
+	typeBool          = 6
+	typeTimestamp     = 10
+
+	precisionDouble    = 2
+	timeUnitNanosecond = 3
+)
+
+// schemaMessage returns the header of the IPC message of the schema of cols.
+func schemaMessage(cols []flux.ColMeta) ([]byte, error) {
+	fields := make(fbVector, 0, len(cols))
+	for _, c := range cols {
+		var typeID uint8
+		var typ fbTable
+		switch c.Type {
+		case flux.TBool:
+			typeID, typ = typeBool, fbTable{}
+		case flux.TInt:
+			typeID, typ = typeInt, fbTable{fbInt32(64), fbBool(true)}
+		case flux.TUInt:
+			typeID, typ = typeInt, fbTable{fbInt32(64), fbBool(false)}
+		case flux.TFloat:
+			typeID, typ = typeFloatingPoint, fbTable{fbInt16(precisionDouble)}
+		case flux.TString:
+			typeID, typ = typeUtf8, fbTable{}
+		case flux.TTime:
+			typeID, typ = typeTimestamp, fbTable{fbInt16(timeUnitNanosecond), fbString("UTC")}
+		default:
+			return nil, fmt.Errorf("column %q has an unsupported type %v", c.Label, c.Type)
+		}
+		fields = append(fields, fbTable{
+			fbString(c.Label),
+			fbBool(true),
+			fbUint8(typeID),
+			fbRef(typ),
+			{}, // dictionary
+			fbRef(fbVector{}),
+		})
+	}
+
+	return fbFinish(fbTable{
+		fbInt16(metadataVersionV4),
+		fbUint8(messageHeaderSchema),
+		fbRef(fbTable{
+			fbInt16(0), // little endian
+			fbRef(fields),
+		}),
+		fbInt64(0),
+	}), nil
+}
+
+// recordBatch is the body of a record batch, with the nodes and the buffers of its columns.
+type recordBatch struct {
+	body    []byte
+	nodes   []byte
+	buffers []byte
+	n       int
+}
+
+// recordBatchMessage returns the header and the body of the IPC message of the record batch of cr.
+// The columns of the batch are the columns idx of cr, in the order of the schema.
+func recordBatchMessage(cr flux.ColReader, idx []int) (header, body []byte) {
+	rb := &recordBatch{}
+	n := cr.Len()
+	for _, j := range idx {
+		switch c := cr.Cols()[j]; c.Type {
+		case flux.TBool:
+			a := cr.Bools(j)
+			values := make([]byte, (n+7)/8)
+			for i := 0; i < n; i++ {
+				if a.IsValid(i) && a.Value(i) {
+					values[i/8] |= 1 << uint(i%8)
+				}
+			}
+			rb.appendColumn(n, a.IsValid, values)
+		case flux.TInt:
+			a := cr.Ints(j)
+			rb.appendColumn(n, a.IsValid, int64Values(n, func(i int) uint64 { return uint64(a.Value(i)) }))
+		case flux.TUInt:
+			a := cr.UInts(j)
+			rb.appendColumn(n, a.IsValid, int64Values(n, a.Value))
+		case flux.TFloat:
+			a := cr.Floats(j)
+			rb.appendColumn(n, a.IsValid, int64Values(n, func(i int) uint64 { return math.Float64bits(a.Value(i)) }))
+		case flux.TTime:
+			a := cr.Times(j)
+			rb.appendColumn(n, a.IsValid, int64Values(n, func(i int) uint64 { return uint64(a.Value(i)) }))
+		case flux.TString:
+			a := cr.Strings(j)
+			offsets := make([]byte, 4*(n+1))
+			var data []byte
+			for i := 0; i < n; i++ {
+				if a.IsValid(i) {
+					data = append(data, a.Value(i)...)
+				}
+				binary.LittleEndian.PutUint32(offsets[4*(i+1):], uint32(len(data)))
+			}
+			rb.appendColumn(n, a.IsValid, offsets, data)
+		}
+	}
+
+	header = fbFinish(fbTable{
+		fbInt16(metadataVersionV4),
+		fbUint8(messageHeaderRecordBatch),
+		fbRef(fbTable{
+			fbInt64(int64(n)),
+			fbRef(fbStructs{n: len(idx), data: rb.nodes}),
+			fbRef(fbStructs{n: rb.n, data: rb.buffers}),
+		}),
+		fbInt64(int64(len(rb.body))),
+	})
+	return header, rb.body
+}
+
+// appendColumn appends the node of a column of n values and its buffers:
+// its validity bitmap, which is empty without nulls, and its data buffers.
+func (rb *recordBatch) appendColumn(n int, valid func(i int) bool, buffers ...[]byte) {
+	bitmap := make([]byte, (n+7)/8)
+	nulls := 0
+	for i := 0; i < n; i++ {
+		if valid(i) {
+			bitmap[i/8] |= 1 << uint(i%8)
+		} else {
+			nulls++
+		}
+	}
+	if nulls == 0 {
+		bitmap = nil
+	}
+	rb.nodes = appendInt64(appendInt64(rb.nodes, int64(n)), int64(nulls))
+
+	for _, b := range append([][]byte{bitmap}, buffers...) {
+		// The buffers of the body are aligned to 8 bytes.
+		rb.buffers = appendInt64(appendInt64(rb.buffers, int64(len(rb.body))), int64(len(b)))
+		rb.body = append(rb.body, b...)
+		for len(rb.body)%8 != 0 {
+			rb.body = append(rb.body, 0)
+		}
+		rb.n++
+	}
+}
+
+func int64Values(n int, value func(i int) uint64) []byte {
+	b := make([]byte, 8*n)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint64(b[8*i:], value(i))
+	}
+	return b
+}
+
+func appendInt64(b []byte, v int64) []byte {
+	var x [8]byte
+	binary.LittleEndian.PutUint64(x[:], uint64(v))
+	return append(b, x[:]...)
+}
+
+// recordWriter sends the tables of the results of a query as a stream of Arrow IPC messages:
+// the schema of the first table, then a record batch per buffer of the tables.
+// An Arrow stream has a single schema, so the tables must all have the columns of the first one.
+type recordWriter struct {
+	send func(*FlightData) error
+	cols []flux.ColMeta
+}
+
+// writeTable sends the record batches of tbl, after the schema if it is the first table.
+func (w *recordWriter) writeTable(tbl flux.Table) error {
+	if w.cols == nil {
+		if err := w.writeSchema(tbl.Cols()); err != nil {
+			return err
+		}
+	}
+	idx, err := w.columns(tbl.Cols())
+	if err != nil {
+		return err
+	}
+
+	return tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+		header, body := recordBatchMessage(cr, idx)
+		return w.send(&FlightData{DataHeader: header, DataBody: body})
+	})
+}
+
+// close sends an empty schema if the results have no tables.
+func (w *recordWriter) close() error {
+	if w.cols != nil {
+		return nil
+	}
+	return w.writeSchema([]flux.ColMeta{})
+}
+
+func (w *recordWriter) writeSchema(cols []flux.ColMeta) error {
+	header, err := schemaMessage(cols)
+	if err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Err:  err,
+		}
+	}
+	w.cols = cols
+	return w.send(&FlightData{DataHeader: header})
+}
+
+// columns returns the indexes of the columns of the schema in cols.
+func (w *recordWriter) columns(cols []flux.ColMeta) ([]int, error) {
+	idx := make([]int, len(w.cols))
+	if len(cols) != len(w.cols) {
+		return nil, errDifferentColumns
+	}
+	for i, c := range w.cols {
+		idx[i] = -1
+		for j, col := range cols {
+			if col == c {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, errDifferentColumns
+		}
+	}
+	return idx, nil
+}
+
+var errDifferentColumns = &platform.Error{
+	Code: platform.EInvalid,
+	Msg:  "the tables of the results have different columns; pivot, drop or group the columns so that every table has the same ones",
+}
@@ -0,0 +1,302 @@
+package flight
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	platform "github.com/influxdata/influxdb"
+)
+
+// fbReader reads a table of a flatbuffer.
+type fbReader struct {
+	buf []byte
+	pos int
+}
+
+func fbRoot(buf []byte) fbReader {
+	return fbReader{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of the field i, or 0 if it is absent.
+func (t fbReader) field(i int) int {
+	vt := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*i >= int(binary.LittleEndian.Uint16(t.buf[vt:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vt+4+2*i:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbReader) int64(i int) int64 {
+	if p := t.field(i); p != 0 {
+		if p%8 != 0 {
+			panic("unaligned 8-byte field")
+		}
+		return int64(binary.LittleEndian.Uint64(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbReader) int32(i int) int32 {
+	if p := t.field(i); p != 0 {
+		return int32(binary.LittleEndian.Uint32(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbReader) int16(i int) int16 {
+	if p := t.field(i); p != 0 {
+		return int16(binary.LittleEndian.Uint16(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbReader) uint8(i int) uint8 {
+	if p := t.field(i); p != 0 {
+		return t.buf[p]
+	}
+	return 0
+}
+
+func (t fbReader) deref(p int) int {
+	return p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t fbReader) table(i int) fbReader {
+	return fbReader{buf: t.buf, pos: t.deref(t.field(i))}
+}
+
+func (t fbReader) string(i int) string {
+	p := t.deref(t.field(i))
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
+
+func (t fbReader) tables(i int) []fbReader {
+	if t.field(i) == 0 {
+		return nil
+	}
+	p := t.deref(t.field(i))
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	tables := make([]fbReader, n)
+	for j := range tables {
+		tables[j] = fbReader{buf: t.buf, pos: t.deref(p + 4 + 4*j)}
+	}
+	return tables
+}
+
+// int64s returns the fields of the vector of structs i.
+func (t fbReader) int64s(i int) []int64 {
+	p := t.deref(t.field(i))
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if (p+4)%8 != 0 {
+		panic("unaligned structs")
+	}
+	var vs []int64
+	for j := 0; j < 2*n; j++ {
+		vs = append(vs, int64(binary.LittleEndian.Uint64(t.buf[p+4+8*j:])))
+	}
+	return vs
+}
+
+func TestSchemaMessage(t *testing.T) {
+	header, err := schemaMessage([]flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "host", Type: flux.TString},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "count", Type: flux.TInt},
+		{Label: "bytes", Type: flux.TUInt},
+		{Label: "up", Type: flux.TBool},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := fbRoot(header)
+	if v := msg.int16(0); v != metadataVersionV4 {
+		t.Errorf("unexpected version %d", v)
+	}
+	if typ := msg.uint8(1); typ != messageHeaderSchema {
+		t.Fatalf("unexpected header type %d", typ)
+	}
+	fields := msg.table(2).tables(1)
+	if len(fields) != 6 {
+		t.Fatalf("unexpected number of fields %d", len(fields))
+	}
+
+	for i, want := range []struct {
+		name   string
+		typeID uint8
+	}{
+		{"_time", typeTimestamp},
+		{"host", typeUtf8},
+		{"_value", typeFloatingPoint},
+		{"count", typeInt},
+		{"bytes", typeInt},
+		{"up", typeBool},
+	} {
+		f := fields[i]
+		if got := f.string(0); got != want.name {
+			t.Errorf("field %d: unexpected name %q, want %q", i, got, want.name)
+		}
+		if got := f.uint8(2); got != want.typeID {
+			t.Errorf("field %d: unexpected type %d, want %d", i, got, want.typeID)
+		}
+		if f.tables(5) == nil {
+			t.Errorf("field %d: missing children", i)
+		}
+	}
+	if ts := fields[0].table(3); ts.int16(0) != timeUnitNanosecond || ts.string(1) != "UTC" {
+		t.Errorf("unexpected timestamp type: unit %d, timezone %q", ts.int16(0), ts.string(1))
+	}
+	if i := fields[3].table(3); i.int32(0) != 64 || i.uint8(1) != 1 {
+		t.Errorf("unexpected int type: width %d, signed %d", i.int32(0), i.uint8(1))
+	}
+	if i := fields[4].table(3); i.int32(0) != 64 || i.uint8(1) != 0 {
+		t.Errorf("unexpected uint type: width %d, signed %d", i.int32(0), i.uint8(1))
+	}
+}
+
+func TestRecordWriter(t *testing.T) {
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "host", Type: flux.TString},
+		{Label: "_value", Type: flux.TFloat},
+	}
+	var sent []*FlightData
+	w := &recordWriter{send: func(d *FlightData) error {
+		sent = append(sent, d)
+		return nil
+	}}
+
+	if err := w.writeTable(&executetest.Table{
+		KeyCols: []string{"host"},
+		ColMeta: cols,
+		Data: [][]interface{}{
+			{execute.Time(10), "a", 1.5},
+			{execute.Time(20), "a", nil},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The columns of the next table are in another order.
+	if err := w.writeTable(&executetest.Table{
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{cols[2], cols[1], cols[0]},
+		Data: [][]interface{}{
+			{2.5, "bc", execute.Time(30)},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 3 {
+		t.Fatalf("unexpected number of messages %d", len(sent))
+	}
+	if typ := fbRoot(sent[0].DataHeader).uint8(1); typ != messageHeaderSchema {
+		t.Fatalf("unexpected header type of the first message %d", typ)
+	}
+
+	for i, want := range []struct {
+		times  []int64
+		hosts  []string
+		values []float64
+		nulls  int64
+	}{
+		{times: []int64{10, 20}, hosts: []string{"a", "a"}, values: []float64{1.5, 0}, nulls: 1},
+		{times: []int64{30}, hosts: []string{"bc"}, values: []float64{2.5}},
+	} {
+		msg := fbRoot(sent[i+1].DataHeader)
+		if typ := msg.uint8(1); typ != messageHeaderRecordBatch {
+			t.Fatalf("batch %d: unexpected header type %d", i, typ)
+		}
+		body := sent[i+1].DataBody
+		if n := msg.int64(3); n != int64(len(body)) {
+			t.Errorf("batch %d: unexpected body length %d, want %d", i, n, len(body))
+		}
+		rb := msg.table(2)
+		n := len(want.times)
+		if l := rb.int64(0); l != int64(n) {
+			t.Errorf("batch %d: unexpected length %d", i, l)
+		}
+		nodes := rb.int64s(1)
+		if len(nodes) != 6 || nodes[4] != int64(n) || nodes[5] != want.nulls {
+			t.Errorf("batch %d: unexpected nodes %v", i, nodes)
+		}
+
+		// The buffers are the validity and the values of the times, the validity, the offsets and the data
+		// of the hosts, and the validity and the values of the values.
+		buffers := rb.int64s(2)
+		if len(buffers) != 14 {
+			t.Fatalf("batch %d: unexpected buffers %v", i, buffers)
+		}
+		buffer := func(k int) []byte {
+			if buffers[2*k]%8 != 0 {
+				t.Errorf("batch %d: unaligned buffer %d", i, k)
+			}
+			return body[buffers[2*k] : buffers[2*k]+buffers[2*k+1]]
+		}
+		for j, ts := range want.times {
+			if got := int64(binary.LittleEndian.Uint64(buffer(1)[8*j:])); got != ts {
+				t.Errorf("batch %d: unexpected time %d, want %d", i, got, ts)
+			}
+			start, end := binary.LittleEndian.Uint32(buffer(3)[4*j:]), binary.LittleEndian.Uint32(buffer(3)[4*j+4:])
+			if got := string(buffer(4)[start:end]); got != want.hosts[j] {
+				t.Errorf("batch %d: unexpected host %q, want %q", i, got, want.hosts[j])
+			}
+			if got := math.Float64frombits(binary.LittleEndian.Uint64(buffer(6)[8*j:])); got != want.values[j] {
+				t.Errorf("batch %d: unexpected value %v, want %v", i, got, want.values[j])
+			}
+		}
+		if want.nulls == 0 {
+			if len(buffer(5)) != 0 {
+				t.Errorf("batch %d: unexpected validity of values without nulls", i)
+			}
+		} else if v := buffer(5); len(v) != 1 || v[0] != 1 {
+			t.Errorf("batch %d: unexpected validity of values %v", i, v)
+		}
+	}
+}
+
+func TestRecordWriter_DifferentColumns(t *testing.T) {
+	w := &recordWriter{send: func(*FlightData) error { return nil }}
+	if err := w.writeTable(&executetest.Table{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TFloat}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err := w.writeTable(&executetest.Table{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TInt}},
+	})
+	if platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRecordWriter_NoTables(t *testing.T) {
+	var sent []*FlightData
+	w := &recordWriter{send: func(d *FlightData) error {
+		sent = append(sent, d)
+		return nil
+	}}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("unexpected number of messages %d", len(sent))
+	}
+	msg := fbRoot(sent[0].DataHeader)
+	if msg.uint8(1) != messageHeaderSchema || len(msg.table(2).tables(1)) != 0 {
+		t.Errorf("expected an empty schema")
+	}
+}
@@ -0,0 +1,78 @@
+package flight
+
+import (
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// The messages and the service below are the subset of the Arrow Flight protocol, Flight.proto,
+// that the service implements. The other methods of the protocol are answered as unimplemented.
+
+// Ticket identifies the stream of a DoGet call.
+type Ticket struct {
+	Ticket []byte `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+}
+
+func (m *Ticket) Reset()         { *m = Ticket{} }
+func (m *Ticket) String() string { return proto.CompactTextString(m) }
+func (*Ticket) ProtoMessage()    {}
+
+// FlightData is a message of a stream: the header of an Arrow IPC message and its body.
+type FlightData struct {
+	DataHeader  []byte `protobuf:"bytes,2,opt,name=data_header,json=dataHeader,proto3" json:"data_header,omitempty"`
+	AppMetadata []byte `protobuf:"bytes,3,opt,name=app_metadata,json=appMetadata,proto3" json:"app_metadata,omitempty"`
+	DataBody    []byte `protobuf:"bytes,1000,opt,name=data_body,json=dataBody,proto3" json:"data_body,omitempty"`
+}
+
+func (m *FlightData) Reset()         { *m = FlightData{} }
+func (m *FlightData) String() string { return proto.CompactTextString(m) }
+func (*FlightData) ProtoMessage()    {}
+
+// DoGetMethod is the full name of the DoGet method of the Flight service.
+const DoGetMethod = "/arrow.flight.protocol.FlightService/DoGet"
+
+// FlightServer is the server of the Flight service.
+type FlightServer interface {
+	DoGet(*Ticket, FlightService_DoGetServer) error
+}
+
+// FlightService_DoGetServer is the stream of the messages of a DoGet call.
+type FlightService_DoGetServer interface {
+	Send(*FlightData) error
+	grpc.ServerStream
+}
+
+type flightServiceDoGetServer struct {
+	grpc.ServerStream
+}
+
+func (x *flightServiceDoGetServer) Send(m *FlightData) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func flightServiceDoGetHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Ticket)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FlightServer).DoGet(m, &flightServiceDoGetServer{stream})
+}
+
+// RegisterFlightServer registers srv as the Flight service of s.
+func RegisterFlightServer(s *grpc.Server, srv FlightServer) {
+	s.RegisterService(&flightServiceDesc, srv)
+}
+
+var flightServiceDesc = grpc.ServiceDesc{
+	ServiceName: "arrow.flight.protocol.FlightService",
+	HandlerType: (*FlightServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DoGet",
+			Handler:       flightServiceDoGetHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "Flight.proto",
+}
@@ -0,0 +1,264 @@
+// Package flight serves the results of Flux queries over the Arrow Flight protocol,
+// as Arrow record batches instead of annotated CSV, for analytical clients such as pandas.
+package flight
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	kitgrpc "github.com/influxdata/influxdb/kit/grpc"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ FlightServer = (*Service)(nil)
+
+// TicketRequest is the query of a ticket, encoded as JSON.
+// A ticket that is not a JSON object is the Flux script of a query of the organization of the token.
+type TicketRequest struct {
+	// Query is the Flux script to run.
+	Query string `json:"query"`
+	// OrgID or Org is the organization running the query. It defaults to the organization of the token.
+	OrgID string `json:"orgID,omitempty"`
+	Org   string `json:"org,omitempty"`
+}
+
+// Service serves the Flight protocol over gRPC. A DoGet call runs the query of its ticket,
+// authorized by the token of its authorization metadata, and streams the tables of its results
+// as Arrow record batches.
+type Service struct {
+	AuthorizationService platform.AuthorizationService
+	OrganizationService  platform.OrganizationService
+	QueryService         query.QueryService
+
+	// TLSConfig, if not nil, serves gRPC over TLS.
+	TLSConfig *tls.Config
+
+	Logger *zap.Logger
+
+	mu       sync.Mutex
+	server   *grpc.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewService returns a Service running the queries of its tickets with qs.
+func NewService(auths platform.AuthorizationService, orgs platform.OrganizationService, qs query.QueryService, logger *zap.Logger) *Service {
+	return &Service{
+		AuthorizationService: auths,
+		OrganizationService:  orgs,
+		QueryService:         qs,
+		Logger:               logger,
+	}
+}
+
+// Open listens on addr and serves the Flight calls until Close is called.
+func (s *Service) Open(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.Serve(ln)
+	return nil
+}
+
+// Serve serves the Flight calls of ln until Close is called.
+func (s *Service) Serve(ln net.Listener) {
+	var opts []grpc.ServerOption
+	if s.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLSConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	RegisterFlightServer(server, s)
+
+	s.mu.Lock()
+	s.server = server
+	s.listener = ln
+	s.mu.Unlock()
+
+	s.Logger.Info("Listening for Flight calls", zap.String("addr", ln.Addr().String()))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := server.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+			s.Logger.Error("Failed to serve Flight calls", zap.Error(err))
+		}
+	}()
+}
+
+// Addr returns the address the service listens on, or nil if it does not listen.
+func (s *Service) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops listening, cancels the calls and waits for them to be done.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	server.Stop()
+	s.wg.Wait()
+	return nil
+}
+
+// DoGet runs the query of t and streams the tables of its results.
+func (s *Service) DoGet(t *Ticket, stream FlightService_DoGetServer) error {
+	ctx := stream.Context()
+
+	a, err := s.authorize(ctx)
+	if err != nil {
+		return toStatus(err)
+	}
+	req, err := s.decodeTicket(ctx, t, a)
+	if err != nil {
+		return toStatus(err)
+	}
+	ctx = pcontext.SetAuthorizer(ctx, a)
+
+	results, err := s.QueryService.Query(ctx, req)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer results.Release()
+
+	w := &recordWriter{send: stream.Send}
+	for results.More() {
+		if err := results.Next().Tables().Do(w.writeTable); err != nil {
+			s.Logger.Info("Failed to stream query results", zap.Error(err))
+			return toStatus(err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return toStatus(err)
+	}
+	return toStatus(w.close())
+}
+
+// authorize returns the authorization of the token of the authorization metadata of the call,
+// sent as "Token <token>" or "Bearer <token>".
+func (s *Service) authorize(ctx context.Context) (*platform.Authorization, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		for _, scheme := range []string{"Token ", "Bearer "} {
+			if strings.HasPrefix(v, scheme) {
+				token = strings.TrimPrefix(v, scheme)
+			}
+		}
+	}
+	if token == "" {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "missing authorization token",
+		}
+	}
+
+	a, err := s.AuthorizationService.FindAuthorizationByToken(ctx, token)
+	if err != nil {
+		if platform.ErrorCode(err) == platform.ENotFound {
+			return nil, &platform.Error{
+				Code: platform.EUnauthorized,
+				Msg:  "invalid authorization token",
+			}
+		}
+		return nil, err
+	}
+	if !a.IsActive() {
+		return nil, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  "authorization token is inactive",
+		}
+	}
+	return a, nil
+}
+
+// decodeTicket returns the query request of t.
+func (s *Service) decodeTicket(ctx context.Context, t *Ticket, a *platform.Authorization) (*query.Request, error) {
+	var tr TicketRequest
+	if b := bytes.TrimSpace(t.Ticket); len(b) > 0 && b[0] == '{' {
+		if err := json.Unmarshal(b, &tr); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid ticket",
+				Err:  err,
+			}
+		}
+	} else {
+		tr.Query = string(t.Ticket)
+	}
+	if strings.TrimSpace(tr.Query) == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "ticket has no query",
+		}
+	}
+
+	var org *platform.Organization
+	var err error
+	switch {
+	case tr.OrgID != "":
+		var id *platform.ID
+		if id, err = platform.IDFromString(tr.OrgID); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid organization ID of the ticket",
+				Err:  err,
+			}
+		}
+		org, err = s.OrganizationService.FindOrganizationByID(ctx, *id)
+	case tr.Org != "":
+		org, err = s.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &tr.Org})
+	default:
+		org, err = s.OrganizationService.FindOrganizationByID(ctx, a.OrgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &query.Request{
+		Authorization:  a,
+		OrganizationID: org.ID,
+		Compiler:       lang.FluxCompiler{Query: tr.Query},
+	}, nil
+}
+
+// toStatus returns err as the error of a gRPC status.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	perr, ok := err.(*platform.Error)
+	if !ok {
+		perr = &platform.Error{
+			Code: platform.EInternal,
+			Err:  err,
+		}
+	}
+	st, serr := kitgrpc.ToStatus(perr)
+	if serr != nil {
+		return serr
+	}
+	return st.Err()
+}
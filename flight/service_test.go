@@ -0,0 +1,151 @@
+package flight_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/flight"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	qmock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func doGet(ctx context.Context, t *testing.T, s *flight.Service, token string, ticket string) ([]*flight.FlightData, error) {
+	t.Helper()
+	cc, err := grpc.Dial(s.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Token "+token)
+	}
+	stream, err := cc.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, flight.DoGetMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&flight.Ticket{Ticket: []byte(ticket)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var data []*flight.FlightData
+	for {
+		d := new(flight.FlightData)
+		if err := stream.RecvMsg(d); err == io.EOF {
+			return data, nil
+		} else if err != nil {
+			return data, err
+		}
+		data = append(data, d)
+	}
+}
+
+func TestService_DoGet(t *testing.T) {
+	org := &platform.Organization{ID: 1, Name: "org"}
+	auth := &platform.Authorization{ID: 2, OrgID: org.ID, Status: platform.Active}
+
+	auths := mock.NewAuthorizationService()
+	auths.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*platform.Authorization, error) {
+		if token != "tok" {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "authorization not found"}
+		}
+		return auth, nil
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return org, nil
+	}
+
+	var got *query.Request
+	qs := &qmock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			got = req
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "host", Type: flux.TString},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(10), "a", 1.5},
+						{execute.Time(20), "a", 2.5},
+					},
+				}},
+			}}), nil
+		},
+	}
+
+	s := flight.NewService(auths, orgs, qs, zaptest.NewLogger(t))
+	if err := s.Open("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	data, err := doGet(ctx, t, s, "tok", `from(bucket: "b") |> range(start: -1h)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The schema, then the record batch of the table.
+	if len(data) != 2 {
+		t.Fatalf("unexpected number of messages %d", len(data))
+	}
+	if len(data[0].DataHeader) == 0 || len(data[0].DataBody) != 0 {
+		t.Errorf("unexpected schema message")
+	}
+	if len(data[1].DataHeader) == 0 || len(data[1].DataBody) == 0 {
+		t.Errorf("unexpected record batch message")
+	}
+
+	if got.OrganizationID != org.ID || got.Authorization != auth {
+		t.Errorf("unexpected scope of the query: org %v, authorization %v", got.OrganizationID, got.Authorization)
+	}
+	if c, ok := got.Compiler.(lang.FluxCompiler); !ok || c.Query != `from(bucket: "b") |> range(start: -1h)` {
+		t.Errorf("unexpected compiler %#v", got.Compiler)
+	}
+
+	// The query of a JSON ticket runs in its organization.
+	if _, err := doGet(ctx, t, s, "tok", `{"query": "from(bucket: \"b\")", "orgID": "0000000000000003"}`); err != nil {
+		t.Fatal(err)
+	}
+	if c := got.Compiler.(lang.FluxCompiler); c.Query != `from(bucket: "b")` {
+		t.Errorf("unexpected query of a JSON ticket %q", c.Query)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		token  string
+		ticket string
+		code   codes.Code
+	}{
+		{name: "missing token", ticket: "from(bucket: \"b\")", code: codes.Unauthenticated},
+		{name: "invalid token", token: "nope", ticket: "from(bucket: \"b\")", code: codes.Unauthenticated},
+		{name: "empty ticket", token: "tok", code: codes.InvalidArgument},
+		{name: "invalid ticket", token: "tok", ticket: "{", code: codes.InvalidArgument},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := doGet(ctx, t, s, tt.token, tt.ticket)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("unexpected code %v, want %v: %v", code, tt.code, err)
+			}
+		})
+	}
+}
@@ -132,49 +132,73 @@ func (s *ProtoService) findProto(ctx context.Context, id platform.ID) (*platform
 }
 
 // CreateDashboardsFromProto creates instances of each dashboard in a proto.
+// Either every dashboard is created or none is: the dashboards created are deleted if a later one fails to be.
 func (s *ProtoService) CreateDashboardsFromProto(ctx context.Context, protoID platform.ID, orgID platform.ID) ([]*platform.Dashboard, error) {
-	// TODO(desa): this should be done transactionally.
 	proto, err := s.findProto(ctx, protoID)
 	if err != nil {
 		return nil, err
 	}
 
-	dashes := []*platform.Dashboard{}
-
+	// Check the views before creating anything, for a bad proto to fail without a rollback.
 	for _, protodash := range proto.Dashboards {
-		dash := &platform.Dashboard{}
-		*dash = protodash.Dashboard
-		dash.Cells = nil
-		dash.OrganizationID = orgID
+		for _, protocell := range protodash.Dashboard.Cells {
+			if _, ok := protodash.Views[protocell.ID.String()]; !ok {
+				return nil, &platform.Error{Msg: fmt.Sprintf("view for ID %q does not exist", protocell.ID)}
+			}
+		}
+	}
 
-		if err := s.DashboardService.CreateDashboard(ctx, dash); err != nil {
+	dashes := []*platform.Dashboard{}
+	for _, protodash := range proto.Dashboards {
+		dash, err := s.createDashboardFromProto(ctx, protodash, orgID)
+		if dash != nil {
+			dashes = append(dashes, dash)
+		}
+		if err != nil {
+			s.deleteDashboards(ctx, dashes)
 			return nil, err
 		}
+	}
 
-		cells := []*platform.Cell{}
-		for _, protocell := range protodash.Dashboard.Cells {
-			cell := &platform.Cell{}
-			*cell = *protocell
+	return dashes, nil
+}
 
-			protoview, ok := protodash.Views[cell.ID.String()]
-			if !ok {
-				return nil, &platform.Error{Msg: fmt.Sprintf("view for ID %q does not exist", cell.ID)}
-			}
+// createDashboardFromProto creates an instance of a dashboard of a proto. It returns the dashboard
+// if it was created, even if its cells failed to be added.
+func (s *ProtoService) createDashboardFromProto(ctx context.Context, protodash platform.ProtoDashboard, orgID platform.ID) (*platform.Dashboard, error) {
+	dash := &platform.Dashboard{}
+	*dash = protodash.Dashboard
+	dash.Cells = nil
+	dash.OrganizationID = orgID
 
-			view := &platform.View{}
-			*view = protoview
-			opts := platform.AddDashboardCellOptions{View: view}
-			if err := s.DashboardService.AddDashboardCell(ctx, dash.ID, cell, opts); err != nil {
-				return nil, err
-			}
+	if err := s.DashboardService.CreateDashboard(ctx, dash); err != nil {
+		return nil, err
+	}
 
-			cells = append(cells, cell)
-		}
+	cells := []*platform.Cell{}
+	for _, protocell := range protodash.Dashboard.Cells {
+		cell := &platform.Cell{}
+		*cell = *protocell
 
-		dash.Cells = cells
+		view := &platform.View{}
+		*view = protodash.Views[cell.ID.String()]
+		opts := platform.AddDashboardCellOptions{View: view}
+		if err := s.DashboardService.AddDashboardCell(ctx, dash.ID, cell, opts); err != nil {
+			return dash, err
+		}
 
-		dashes = append(dashes, dash)
+		cells = append(cells, cell)
 	}
 
-	return dashes, nil
+	dash.Cells = cells
+	return dash, nil
+}
+
+// deleteDashboards rolls back the dashboards created from a proto, along with their cells.
+func (s *ProtoService) deleteDashboards(ctx context.Context, dashes []*platform.Dashboard) {
+	for _, dash := range dashes {
+		if err := s.DashboardService.DeleteDashboard(ctx, dash.ID); err != nil {
+			s.Logger.Error("Failed to roll back dashboard created from proto", zap.String("dashboard", dash.ID.String()), zap.Error(err))
+		}
+	}
 }
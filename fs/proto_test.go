@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/fs"
+	"github.com/influxdata/influxdb/mock"
 	platformtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap"
 )
@@ -135,4 +136,88 @@ func TestProtoService_FindProtos(t *testing.T) {
 	}
 }
 
-// TODO(desa): Add tests for CreateDashboardsFromProto.
+func TestProtoService_CreateDashboardsFromProto(t *testing.T) {
+	cellID := platformtesting.MustIDBase16("da7aba5e5d81e550")
+	protoDashboard := func(name string) platform.ProtoDashboard {
+		return platform.ProtoDashboard{
+			Dashboard: platform.Dashboard{
+				Name:  name,
+				Cells: []*platform.Cell{{ID: cellID, W: 3, H: 4}},
+			},
+			Views: map[string]platform.View{
+				cellID.String(): {ViewContents: platform.ViewContents{Name: name}},
+			},
+		}
+	}
+	badDashboard := protoDashboard("bad")
+	badDashboard.Views = nil
+
+	tests := []struct {
+		name       string
+		dashboards []platform.ProtoDashboard
+		failCell   string // the name of the dashboard whose cells fail to be added
+		created    []string
+		deleted    []string
+		wantErr    bool
+	}{
+		{
+			name:       "creates every dashboard",
+			dashboards: []platform.ProtoDashboard{protoDashboard("a"), protoDashboard("b")},
+			created:    []string{"a", "b"},
+		},
+		{
+			name:       "creates nothing if a view is missing",
+			dashboards: []platform.ProtoDashboard{protoDashboard("a"), badDashboard},
+			wantErr:    true,
+		},
+		{
+			name:       "rolls back the dashboards created if a cell fails to be added",
+			dashboards: []platform.ProtoDashboard{protoDashboard("a"), protoDashboard("b"), protoDashboard("c")},
+			failCell:   "b",
+			created:    []string{"a", "b"},
+			deleted:    []string{"a", "b"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created, deleted []string
+			names := make(map[platform.ID]string)
+			ds := mock.NewDashboardService()
+			ds.CreateDashboardF = func(ctx context.Context, d *platform.Dashboard) error {
+				d.ID = platform.ID(len(names) + 1)
+				names[d.ID] = d.Name
+				created = append(created, d.Name)
+				return nil
+			}
+			ds.AddDashboardCellF = func(ctx context.Context, id platform.ID, c *platform.Cell, opts platform.AddDashboardCellOptions) error {
+				if names[id] == tt.failCell {
+					return &platform.Error{Msg: "unable to add cell"}
+				}
+				return nil
+			}
+			ds.DeleteDashboardF = func(ctx context.Context, id platform.ID) error {
+				deleted = append(deleted, names[id])
+				return nil
+			}
+
+			s := fs.NewProtoService("", zap.NewNop(), ds)
+			s.WithProtos([]*platform.Proto{{ID: 1, Name: "system", Dashboards: tt.dashboards}})
+
+			dashes, err := s.CreateDashboardsFromProto(context.Background(), 1, 2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(dashes) != len(tt.dashboards) {
+				t.Errorf("got %d dashboards, want %d", len(dashes), len(tt.dashboards))
+			}
+			if diff := cmp.Diff(tt.created, created, protoCmpOptions...); diff != "" {
+				t.Errorf("unexpected dashboards created -want/+got\ndiff %s", diff)
+			}
+			if diff := cmp.Diff(tt.deleted, deleted, protoCmpOptions...); diff != "" {
+				t.Errorf("unexpected dashboards deleted -want/+got\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// AlertingHandler represents an HTTP API handler for exporting the alerting resources of an
+// organization, its expected reporters and task webhooks, to Terraform.
+type AlertingHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ExpectedReporterService platform.ExpectedReporterService
+	TaskWebhookService      platform.TaskWebhookService
+}
+
+const (
+	alertingExportPath = "/api/v2/alerting/export"
+)
+
+// NewAlertingHandler returns a new instance of AlertingHandler
+func NewAlertingHandler(rs platform.ExpectedReporterService, ws platform.TaskWebhookService) *AlertingHandler {
+	h := &AlertingHandler{
+		Router:                  NewRouter(),
+		Logger:                  zap.NewNop(),
+		ExpectedReporterService: rs,
+		TaskWebhookService:      ws,
+	}
+
+	h.HandlerFunc("GET", alertingExportPath, h.handleGetAlertingExport)
+
+	return h
+}
+
+// handleGetAlertingExport is the HTTP handler for the GET /api/v2/alerting/export route.
+func (h *AlertingHandler) handleGetAlertingExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qp := r.URL.Query()
+	var orgID platform.ID
+	if err := orgID.DecodeFromString(qp.Get(OrgID)); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		}, w)
+		return
+	}
+	format := qp.Get("format")
+	if format == "" {
+		format = platform.TerraformFormatJSON
+	}
+
+	rs, err := h.ExpectedReporterService.FindExpectedReporters(ctx, platform.ExpectedReporterFilter{OrgID: &orgID})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	ws, err := h.TaskWebhookService.FindTaskWebhooks(ctx, platform.TaskWebhookFilter{OrgID: &orgID})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	b, err := platform.NewTerraformExport(rs, ws).Encode(format)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	filename := "alerting.tf"
+	if format == platform.TerraformFormatJSON {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		filename += ".json"
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// AlertingService connects to Influx via HTTP using tokens to export the alerting resources of organizations.
+type AlertingService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+// ExportTerraform writes to w the Terraform configuration of the alerting resources of an organization,
+// in the format platform.TerraformFormatJSON or platform.TerraformFormatHCL.
+func (s *AlertingService) ExportTerraform(ctx context.Context, orgID platform.ID, format string, w io.Writer) error {
+	u, err := newURL(s.Addr, alertingExportPath)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = url.Values{
+		OrgID:    []string{orgID.String()},
+		"format": []string{format},
+	}.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestAlertingService_ExportTerraform(t *testing.T) {
+	rs := mock.NewExpectedReporterService()
+	rs.FindExpectedReportersFn = func(ctx context.Context, filter platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error) {
+		if filter.OrgID == nil || *filter.OrgID != 10 {
+			return nil, nil
+		}
+		return []*platform.ExpectedReporter{
+			{ID: 1, OrgID: 10, BucketID: 2, TagKey: "host", TagValue: "a", Interval: time.Minute},
+		}, nil
+	}
+	ws := mock.NewTaskWebhookService()
+	ws.FindTaskWebhooksFn = func(ctx context.Context, filter platform.TaskWebhookFilter) ([]*platform.TaskWebhook, error) {
+		if filter.OrgID == nil || *filter.OrgID != 10 {
+			return nil, nil
+		}
+		return []*platform.TaskWebhook{
+			{ID: 3, OrgID: 10, TaskID: 4, URL: "https://example.com", Events: []string{"failure"}, Secret: "s3cr3t"},
+		}, nil
+	}
+
+	server := httptest.NewServer(NewAlertingHandler(rs, ws))
+	defer server.Close()
+	client := AlertingService{Addr: server.URL}
+	ctx := context.Background()
+
+	for _, format := range []string{platform.TerraformFormatJSON, platform.TerraformFormatHCL} {
+		var buf bytes.Buffer
+		if err := client.ExportTerraform(ctx, 10, format, &buf); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, want := range []string{"reporter_0000000000000001", "webhook_0000000000000003", "var.webhook_0000000000000003_secret"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected the %s export to contain %q, got:\n%s", format, want, got)
+			}
+		}
+		if strings.Contains(got, "s3cr3t") {
+			t.Errorf("expected the %s export not to contain the secret of the webhook, got:\n%s", format, got)
+		}
+	}
+
+	if err := client.ExportTerraform(ctx, 10, "yaml", &bytes.Buffer{}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected an unsupported format to be invalid, got %v", err)
+	}
+}
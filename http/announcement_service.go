@@ -0,0 +1,375 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// AnnouncementHandler represents an HTTP API handler for announcements
+type AnnouncementHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	AnnouncementService platform.AnnouncementService
+}
+
+const (
+	announcementsPath       = "/api/v2/announcements"
+	announcementsActivePath = "/api/v2/announcements/active"
+	announcementsIDPath     = "/api/v2/announcements/:id"
+)
+
+// NewAnnouncementHandler returns a new instance of AnnouncementHandler
+func NewAnnouncementHandler(s platform.AnnouncementService) *AnnouncementHandler {
+	h := &AnnouncementHandler{
+		Router:              NewRouter(),
+		Logger:              zap.NewNop(),
+		AnnouncementService: s,
+	}
+
+	h.HandlerFunc("GET", announcementsPath, h.handleGetAnnouncements)
+	h.HandlerFunc("POST", announcementsPath, h.handlePostAnnouncement)
+	// The active announcements are rendered by the UI before sign in,
+	// so this route is registered as not requiring authentication.
+	h.HandlerFunc("GET", announcementsActivePath, h.handleGetActiveAnnouncements)
+	h.HandlerFunc("PATCH", announcementsIDPath, h.handlePatchAnnouncement)
+	h.HandlerFunc("DELETE", announcementsIDPath, h.handleDeleteAnnouncement)
+
+	return h
+}
+
+type announcementsResponse struct {
+	Links         map[string]string        `json:"links"`
+	Announcements []*platform.Announcement `json:"announcements"`
+}
+
+func newAnnouncementsResponse(as []*platform.Announcement) *announcementsResponse {
+	return &announcementsResponse{
+		Links: map[string]string{
+			"self":   announcementsPath,
+			"active": announcementsActivePath,
+		},
+		Announcements: as,
+	}
+}
+
+// handleGetAnnouncements is the HTTP handler for the GET /api/v2/announcements route.
+func (h *AnnouncementHandler) handleGetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetAnnouncementsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	h.findAnnouncements(w, r, req.filter)
+}
+
+// handleGetActiveAnnouncements is the HTTP handler for the GET /api/v2/announcements/active route.
+func (h *AnnouncementHandler) handleGetActiveAnnouncements(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	h.findAnnouncements(w, r, platform.AnnouncementFilter{ActiveAt: &now})
+}
+
+func (h *AnnouncementHandler) findAnnouncements(w http.ResponseWriter, r *http.Request, filter platform.AnnouncementFilter) {
+	ctx := r.Context()
+
+	as, err := h.AnnouncementService.FindAnnouncements(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newAnnouncementsResponse(as)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type getAnnouncementsRequest struct {
+	filter platform.AnnouncementFilter
+}
+
+func decodeGetAnnouncementsRequest(ctx context.Context, r *http.Request) (*getAnnouncementsRequest, error) {
+	qp := r.URL.Query()
+	req := &getAnnouncementsRequest{}
+
+	if id := qp.Get("id"); id != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(id); err != nil {
+			return nil, err
+		}
+		req.filter.ID = &i
+	}
+
+	if at := qp.Get("activeAt"); at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "activeAt must be an RFC3339 time",
+				Err:  err,
+			}
+		}
+		req.filter.ActiveAt = &t
+	}
+
+	return req, nil
+}
+
+// handlePostAnnouncement is the HTTP handler for the POST /api/v2/announcements route.
+func (h *AnnouncementHandler) handlePostAnnouncement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a := &platform.Announcement{}
+	if err := json.NewDecoder(r.Body).Decode(a); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode announcement request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.AnnouncementService.CreateAnnouncement(ctx, a); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, a); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchAnnouncement is the HTTP handler for the PATCH /api/v2/announcements/:id route.
+func (h *AnnouncementHandler) handlePatchAnnouncement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeAnnouncementID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd platform.AnnouncementUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode announcement update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	a, err := h.AnnouncementService.UpdateAnnouncement(ctx, id, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, a); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteAnnouncement is the HTTP handler for the DELETE /api/v2/announcements/:id route.
+func (h *AnnouncementHandler) handleDeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeAnnouncementID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.AnnouncementService.DeleteAnnouncement(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeAnnouncementID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, err
+	}
+
+	return i, nil
+}
+
+// AnnouncementService connects to Influx via HTTP using tokens to manage announcements
+type AnnouncementService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.AnnouncementService = (*AnnouncementService)(nil)
+
+// FindAnnouncements returns the announcements that match a filter.
+func (s *AnnouncementService) FindAnnouncements(ctx context.Context, filter platform.AnnouncementFilter) ([]*platform.Announcement, error) {
+	u, err := newURL(s.Addr, announcementsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.ID != nil {
+		query.Add("id", filter.ID.String())
+	}
+	if filter.ActiveAt != nil {
+		query.Add("activeAt", filter.ActiveAt.Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r announcementsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Announcements, nil
+}
+
+// CreateAnnouncement creates a new announcement and sets a.ID with the new identifier.
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, a *platform.Announcement) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	u, err := newURL(s.Addr, announcementsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(a)
+}
+
+// UpdateAnnouncement updates a single announcement with changeset.
+// Returns the new announcement state after update.
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, id platform.ID, upd platform.AnnouncementUpdate) (*platform.Announcement, error) {
+	u, err := newURL(s.Addr, announcementIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var a platform.Announcement
+	if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// DeleteAnnouncement removes an announcement by ID.
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id platform.ID) error {
+	u, err := newURL(s.Addr, announcementIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func announcementIDPath(id platform.ID) string {
+	return path.Join(announcementsPath, id.String())
+}
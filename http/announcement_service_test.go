@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initAnnouncementService(f platformtesting.AnnouncementFields, t *testing.T) (platform.AnnouncementService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize announcement service: %v", err)
+	}
+	for _, a := range f.Announcements {
+		if err := svc.PutAnnouncement(ctx, a); err != nil {
+			t.Fatalf("failed to populate announcements: %v", err)
+		}
+	}
+
+	handler := NewAnnouncementHandler(svc)
+	server := httptest.NewServer(handler)
+	client := AnnouncementService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestAnnouncementService(t *testing.T) {
+	platformtesting.AnnouncementService(initAnnouncementService, t)
+}
+
+func TestAnnouncementHandler_ActiveWithoutAuthentication(t *testing.T) {
+	var filter platform.AnnouncementFilter
+	svc := mock.NewAnnouncementService()
+	svc.FindAnnouncementsFn = func(ctx context.Context, f platform.AnnouncementFilter) ([]*platform.Announcement, error) {
+		filter = f
+		return []*platform.Announcement{}, nil
+	}
+
+	h := NewAuthenticationHandler()
+	h.Handler = NewAnnouncementHandler(svc)
+	h.RegisterNoAuthRoute("GET", "/api/v2/announcements/active")
+
+	before := time.Now()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://any.url/api/v2/announcements/active", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET active announcements = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if filter.ActiveAt == nil || filter.ActiveAt.Before(before) {
+		t.Errorf("expected announcements active now, got filter %+v", filter)
+	}
+
+	// Managing announcements still requires authentication.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://any.url/api/v2/announcements", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("GET announcements = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
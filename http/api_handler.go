@@ -3,37 +3,59 @@ package http
 import (
 	http "net/http"
 	"strings"
+	"time"
 
 	influxdb "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/authorizer"
 	"github.com/influxdata/influxdb/chronograf/server"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/write"
 	"go.uber.org/zap"
 )
 
 // APIHandler is a collection of all the service handlers.
 type APIHandler struct {
+	AnnouncementHandler  *AnnouncementHandler
+	AuditLogHandler      *AuditLogHandler
 	BucketHandler        *BucketHandler
 	UserHandler          *UserHandler
 	OrgHandler           *OrgHandler
 	AuthorizationHandler *AuthorizationHandler
 	DashboardHandler     *DashboardHandler
+	DeleteHandler        *DeleteHandler
 	LabelHandler         *LabelHandler
+	MetadataHandler      *MetadataHandler
 	AssetHandler         *AssetHandler
 	ChronografHandler    *ChronografHandler
 	ScraperHandler       *ScraperHandler
 	SourceHandler        *SourceHandler
 	VariableHandler      *VariableHandler
 	TaskHandler          *TaskHandler
+	TaskScriptHandler    *TaskScriptHandler
+	DependencyHandler    *TaskDependencyHandler
 	TelegrafHandler      *TelegrafHandler
 	QueryHandler         *FluxHandler
+	RunningHandler       *RunningQueryHandler
+	ReporterHandler      *ReporterHandler
+	SQLConnectionHandler *SQLConnectionHandler
+	DBRPMappingHandler   *DBRPMappingHandler
+	AlertingHandler      *AlertingHandler
 	ProtoHandler         *ProtoHandler
 	WriteHandler         *WriteHandler
 	DocumentHandler      *DocumentHandler
 	SetupHandler         *SetupHandler
 	SessionHandler       *SessionHandler
+	SubsystemHandler     *SubsystemHandler
+	CompactionHandler    *CompactionHandler
+	IndexCheckHandler    *IndexCheckHandler
+	LimitsHandler        *LimitsSimulationHandler
+	LineageHandler       *LineageHandler
+	ShardHandler         *ShardHandler
+	CORSHandler          *CORSHandler
 	SwaggerHandler       http.Handler
+
+	CORS *CORS
 }
 
 // APIBackend is all services and associated parameters required to construct
@@ -46,6 +68,12 @@ type APIBackend struct {
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
 	PointsWriter                    storage.PointsWriter
+	WriteLimiter                    *write.Limiter
+	RequestLimiter                  *RequestLimiter
+	WriteQueue                      *write.Queue
+	WALSegmentReader                storage.WALSegmentReader
+	SeriesFinder                    storage.SeriesFinder
+	SeriesCounter                   storage.SeriesCounter
 	AuthorizationService            influxdb.AuthorizationService
 	BucketService                   influxdb.BucketService
 	SessionService                  influxdb.SessionService
@@ -53,9 +81,20 @@ type APIBackend struct {
 	OrganizationService             influxdb.OrganizationService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	LabelService                    influxdb.LabelService
+	MetadataService                 influxdb.MetadataService
+	AnnouncementService             influxdb.AnnouncementService
+	ExpectedReporterService         influxdb.ExpectedReporterService
+	ExpectedReporterMonitor         influxdb.ExpectedReporterMonitor
 	DashboardService                influxdb.DashboardService
 	DashboardOperationLogService    influxdb.DashboardOperationLogService
 	BucketOperationLogService       influxdb.BucketOperationLogService
+	CoverageGapService              influxdb.CoverageGapService
+	ReplicationService              influxdb.ReplicationService
+	MeasurementSchemaService        influxdb.MeasurementSchemaService
+	BucketCloneService              influxdb.BucketCloneService
+	BucketBatchService              influxdb.BucketBatchService
+	BucketLifecycleService          influxdb.BucketLifecycleService
+	DeleteJobService                influxdb.DeleteJobService
 	UserOperationLogService         influxdb.UserOperationLogService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
 	SourceService                   influxdb.SourceService
@@ -63,16 +102,35 @@ type APIBackend struct {
 	PasswordsService                influxdb.PasswordsService
 	OnboardingService               influxdb.OnboardingService
 	InfluxQLService                 query.ProxyQueryService
+	DBRPMappingService              influxdb.DBRPMappingService
 	FluxService                     query.ProxyQueryService
+	SlowQueryThreshold              time.Duration
 	TaskService                     influxdb.TaskService
+	TaskWebhookService              influxdb.TaskWebhookService
+	TaskScriptService               influxdb.TaskScriptService
+	TaskDependencyService           influxdb.TaskDependencyService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
 	SecretService                   influxdb.SecretService
+	SQLConnectionService            influxdb.SQLConnectionService
+	SQLConnectionChecker            influxdb.SQLConnectionChecker
+	ActivityService                 influxdb.ActivityService
+	AuditRecorder                   influxdb.AuditRecorder
+	AuditLogService                 influxdb.AuditLogService
+	CertificateAuthorizations       map[string]influxdb.ID
+	SubsystemService                influxdb.SubsystemService
+	CompactionService               influxdb.CompactionService
+	IndexCheckService               influxdb.IndexCheckService
+	LimitsSimulationService         influxdb.LimitsSimulationService
+	RunningQueryService             influxdb.RunningQueryService
+	LineageService                  influxdb.LineageService
+	ShardService                    influxdb.ShardService
 	LookupService                   influxdb.LookupService
 	ChronografService               *server.Service
 	ProtoService                    influxdb.ProtoService
 	OrgLookupService                authorizer.OrganizationService
 	DocumentService                 influxdb.DocumentService
+	CORS                            *CORS
 }
 
 // NewAPIHandler constructs all api handlers beneath it and returns an APIHandler
@@ -90,10 +148,18 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 
 	bucketBackend := NewBucketBackend(b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	bucketBackend.CoverageGapService = authorizer.NewCoverageGapService(b.CoverageGapService)
+	bucketBackend.ReplicationService = authorizer.NewReplicationService(b.ReplicationService)
+	bucketBackend.MeasurementSchemaService = authorizer.NewMeasurementSchemaService(b.MeasurementSchemaService)
+	bucketBackend.BucketCloneService = authorizer.NewBucketCloneService(b.BucketCloneService)
+	bucketBackend.BucketBatchService = authorizer.NewBucketBatchService(b.BucketBatchService, b.BucketService)
+	bucketBackend.BucketLifecycleService = authorizer.NewBucketLifecycleService(b.BucketLifecycleService)
 	h.BucketHandler = NewBucketHandler(bucketBackend)
 
 	orgBackend := NewOrgBackend(b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	orgBackend.TaskDependencyService = authorizer.NewTaskDependencyService(b.TaskDependencyService)
+	orgBackend.SQLConnectionService = authorizer.NewSQLConnectionService(b.SQLConnectionService)
 	h.OrgHandler = NewOrgHandler(orgBackend)
 
 	userBackend := NewUserBackend(b)
@@ -102,6 +168,8 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 
 	dashboardBackend := NewDashboardBackend(b)
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
+	dashboardBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
+	dashboardBackend.BucketService = authorizer.NewBucketService(b.BucketService)
 	h.DashboardHandler = NewDashboardHandler(dashboardBackend)
 
 	variableBackend := NewVariableBackend(b)
@@ -125,16 +193,26 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.SetupHandler = NewSetupHandler(setupBackend)
 
 	taskBackend := NewTaskBackend(b)
+	taskBackend.TaskWebhookService = authorizer.NewTaskWebhookService(b.TaskWebhookService)
+	taskBackend.TaskScriptService = authorizer.NewTaskScriptService(b.TaskScriptService)
 	h.TaskHandler = NewTaskHandler(taskBackend)
 	h.TaskHandler.UserResourceMappingService = internalURM
 
 	telegrafBackend := NewTelegrafBackend(b)
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
+	telegrafBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
+	telegrafBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	telegrafBackend.SecretService = authorizer.NewSecretService(b.SecretService)
 	h.TelegrafHandler = NewTelegrafHandler(telegrafBackend)
 
 	writeBackend := NewWriteBackend(b)
 	h.WriteHandler = NewWriteHandler(writeBackend)
 
+	deleteBackend := NewDeleteBackend(b)
+	deleteBackend.DeleteJobService = authorizer.NewDeleteJobService(b.DeleteJobService)
+	deleteBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.DeleteHandler = NewDeleteHandler(deleteBackend)
+
 	fluxBackend := NewFluxBackend(b)
 	h.QueryHandler = NewFluxHandler(fluxBackend)
 
@@ -142,6 +220,24 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.ChronografHandler = NewChronografHandler(b.ChronografService)
 	h.SwaggerHandler = newSwaggerLoader(b.Logger.With(zap.String("service", "swagger-loader")))
 	h.LabelHandler = NewLabelHandler(authorizer.NewLabelService(b.LabelService))
+	h.MetadataHandler = NewMetadataHandler(authorizer.NewMetadataService(b.MetadataService))
+	h.AnnouncementHandler = NewAnnouncementHandler(authorizer.NewAnnouncementService(b.AnnouncementService))
+	h.AuditLogHandler = NewAuditLogHandler(authorizer.NewAuditLogService(b.AuditLogService))
+	h.SubsystemHandler = NewSubsystemHandler(authorizer.NewSubsystemService(b.SubsystemService))
+	h.CompactionHandler = NewCompactionHandler(authorizer.NewCompactionService(b.CompactionService))
+	h.CORS = b.cors()
+	h.CORSHandler = NewCORSHandler(authorizer.NewCORSPolicyService(h.CORS))
+	h.IndexCheckHandler = NewIndexCheckHandler(authorizer.NewIndexCheckService(b.IndexCheckService))
+	h.LimitsHandler = NewLimitsSimulationHandler(authorizer.NewLimitsSimulationService(b.LimitsSimulationService))
+	h.RunningHandler = NewRunningQueryHandler(authorizer.NewRunningQueryService(b.RunningQueryService))
+	h.LineageHandler = NewLineageHandler(authorizer.NewLineageService(b.LineageService))
+	h.ShardHandler = NewShardHandler(authorizer.NewShardService(b.ShardService))
+	h.ReporterHandler = NewReporterHandler(authorizer.NewExpectedReporterService(b.ExpectedReporterService), b.ExpectedReporterMonitor)
+	h.AlertingHandler = NewAlertingHandler(authorizer.NewExpectedReporterService(b.ExpectedReporterService), authorizer.NewTaskWebhookService(b.TaskWebhookService))
+	h.TaskScriptHandler = NewTaskScriptHandler(authorizer.NewTaskScriptService(b.TaskScriptService))
+	h.DependencyHandler = NewTaskDependencyHandler(authorizer.NewTaskDependencyService(b.TaskDependencyService), authorizer.NewBucketService(b.BucketService))
+	h.SQLConnectionHandler = NewSQLConnectionHandler(authorizer.NewSQLConnectionService(b.SQLConnectionService), b.SQLConnectionChecker, authorizer.NewSecretService(b.SecretService))
+	h.DBRPMappingHandler = NewDBRPMappingHandler(authorizer.NewDBRPMappingService(b.DBRPMappingService))
 
 	return h
 }
@@ -149,30 +245,56 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"alerting": map[string]string{
+		"export": "/api/v2/alerting/export",
+	},
+	"announcements":  "/api/v2/announcements",
+	"audit":          "/api/v2/audit",
 	"authorizations": "/api/v2/authorizations",
 	"buckets":        "/api/v2/buckets",
 	"dashboards":     "/api/v2/dashboards",
+	"dbrps":          "/api/v2/dbrps",
+	"delete":         "/api/v2/delete",
+	"dependencies":   "/api/v2/dependencies",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
 	"labels":    "/api/v2/labels",
+	"lineage":   "/api/v2/lineage",
 	"variables": "/api/v2/variables",
 	"me":        "/api/v2/me",
-	"orgs":      "/api/v2/orgs",
-	"protos":    "/api/v2/protos",
+	"metadata":  "/api/v2/metadata",
+	"ops": map[string]string{
+		"subsystems":       "/api/v2/ops/subsystems",
+		"compactions":      "/api/v2/ops/compactions",
+		"cors":             "/api/v2/ops/cors",
+		"indexCheck":       "/api/v2/ops/index/check",
+		"topShards":        "/api/v2/ops/shards/top",
+		"limitsSimulation": "/api/v2/ops/limits/simulate",
+	},
+	"orgs":   "/api/v2/orgs",
+	"protos": "/api/v2/protos",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
 		"analyze":     "/api/v2/query/analyze",
+		"batch":       "/api/v2/query/batch",
+		"explain":     "/api/v2/query/explain",
+		"queries":     "/api/v2/query/queries",
 		"spec":        "/api/v2/query/spec",
+		"stream":      "/api/v2/query/ws",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"reporters":      "/api/v2/reporters",
+	"setup":          "/api/v2/setup",
+	"signin":         "/api/v2/signin",
+	"signout":        "/api/v2/signout",
+	"sources":        "/api/v2/sources",
+	"scrapers":       "/api/v2/scrapers",
+	"scripts":        "/api/v2/scripts",
+	"shards":         "/api/v2/shards",
+	"sqlConnections": "/api/v2/sqlconnections",
+	"swagger":        "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
@@ -192,9 +314,17 @@ func (h *APIHandler) serveLinks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// cors returns the CORS of the backend, or one allowing any origin if it has none.
+func (b *APIBackend) cors() *CORS {
+	if b.CORS == nil {
+		b.CORS = NewCORS(influxdb.DefaultCORSPolicy(), nil)
+	}
+	return b.CORS
+}
+
 // ServeHTTP delegates a request to the appropriate subhandler.
 func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	setCORSResponseHeaders(w, r)
+	h.CORS.setResponseHeaders(w, r)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -215,21 +345,96 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.HasPrefix(r.URL.Path, "/api/v2/write") {
+	if strings.HasPrefix(r.URL.Path, "/api/v2/write") || r.URL.Path == opentsdbPutPath {
 		h.WriteHandler.ServeHTTP(w, r)
 		return
 	}
 
-	if strings.HasPrefix(r.URL.Path, "/api/v2/query") {
+	if strings.HasPrefix(r.URL.Path, "/api/v2/delete") {
+		h.DeleteHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/query/queries") {
+		h.RunningHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/query") || r.URL.Path == influxQLQueryPath {
 		h.QueryHandler.ServeHTTP(w, r)
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/lineage") {
+		h.LineageHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/buckets") {
 		h.BucketHandler.ServeHTTP(w, r)
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/audit") {
+		h.AuditLogHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/announcements") {
+		h.AnnouncementHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops/cors") {
+		h.CORSHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops/compactions") {
+		h.CompactionHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops/index") {
+		h.IndexCheckHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops/shards") {
+		h.ShardHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops/limits") {
+		h.LimitsHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/ops") {
+		h.SubsystemHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/reporters") {
+		h.ReporterHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/alerting") {
+		h.AlertingHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/sqlconnections") {
+		h.SQLConnectionHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/dbrps") {
+		h.DBRPMappingHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/labels") {
 		h.LabelHandler.ServeHTTP(w, r)
 		return
@@ -240,6 +445,12 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// metadata must be matched before me, as both share a prefix.
+	if strings.HasPrefix(r.URL.Path, "/api/v2/metadata") {
+		h.MetadataHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/me") {
 		h.UserHandler.ServeHTTP(w, r)
 		return
@@ -270,6 +481,21 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/shards") {
+		h.ShardHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/dependencies") {
+		h.DependencyHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/scripts") {
+		h.TaskScriptHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/tasks") {
 		h.TaskHandler.ServeHTTP(w, r)
 		return
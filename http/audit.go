@@ -0,0 +1,345 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+)
+
+// maxAuditBodySize is the number of bytes of the request and response bodies read at most
+// to find the properties set by a call and the IDs of the resource it created.
+const maxAuditBodySize = 64 * 1024
+
+// AuditHandler records the calls to its handler that create, update or delete resources
+// as audit events. It must be wrapped by the authentication handler, so that the events
+// record the authorizer of the calls.
+type AuditHandler struct {
+	Handler  http.Handler
+	Recorder platform.AuditRecorder
+}
+
+// NewAuditHandler returns an AuditHandler recording the calls to h with rec.
+func NewAuditHandler(h http.Handler, rec platform.AuditRecorder) *AuditHandler {
+	return &AuditHandler{
+		Handler:  h,
+		Recorder: rec,
+	}
+}
+
+// ServeHTTP serves r with the handler, and records it if it creates, updates or deletes a resource.
+func (h *AuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := auditAction(r)
+	if action == "" {
+		h.Handler.ServeHTTP(w, r)
+		return
+	}
+
+	e := &platform.AuditEvent{
+		Time:       time.Now().UTC(),
+		Action:     action,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: remoteHost(r.RemoteAddr),
+	}
+	e.ResourceType, e.ResourceID = auditResource(r.URL.Path)
+	if a, err := pctx.GetAuthorizer(r.Context()); err == nil {
+		e.AuthorizerKind = a.Kind()
+		e.AuthorizerID = a.Identifier()
+		e.UserID = a.GetUserID()
+	}
+
+	// The request body is read ahead of the handler, which reads it whole again.
+	var reqBody []byte
+	if action != platform.AuditActionDelete && r.Body != nil {
+		reqBody, _ = ioutil.ReadAll(io.LimitReader(r.Body, maxAuditBodySize))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+	}
+
+	aw := &auditResponseWriter{statusResponseWriter: newStatusResponseWriter(w)}
+	h.Handler.ServeHTTP(aw, r)
+	e.StatusCode = aw.code()
+
+	req := decodeAuditBody(reqBody)
+	res := decodeAuditBody(aw.body.Bytes())
+	e.Changes = req.properties
+	if !e.ResourceID.Valid() && action == platform.AuditActionCreate {
+		e.ResourceID = res.id
+	}
+	e.OrgID = auditOrgID(r, req, res)
+
+	h.Recorder.RecordAuditEvent(e)
+}
+
+// auditAction returns the action of the call r, or an empty string if it is not audited.
+// Writes and queries are not audited, as they do not change resources, and neither are
+// signing in and out.
+func auditAction(r *http.Request) string {
+	p := r.URL.Path
+	switch {
+	case strings.HasPrefix(p, "/api/v2/write"),
+		strings.HasPrefix(p, "/api/v2/query"),
+		p == influxQLQueryPath,
+		p == opentsdbPutPath,
+		p == "/api/v2/signin",
+		p == "/api/v2/signout":
+		return ""
+	}
+
+	switch r.Method {
+	case "POST":
+		return platform.AuditActionCreate
+	case "PUT", "PATCH":
+		return platform.AuditActionUpdate
+	case "DELETE":
+		return platform.AuditActionDelete
+	}
+	return ""
+}
+
+// auditResource returns the resource type of a path of the API, which is its first segment,
+// and the ID of the resource, which is its second segment if it is an ID.
+func auditResource(path string) (string, platform.ID) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v2"), "/"), "/")
+	var id platform.ID
+	if len(segments) > 1 && id.DecodeFromString(segments[1]) != nil {
+		id = 0
+	}
+	return segments[0], id
+}
+
+// auditBody are the properties of a JSON object of a request or response body.
+type auditBody struct {
+	properties []string
+	id         platform.ID
+	orgID      platform.ID
+}
+
+// decodeAuditBody returns the properties of the JSON object b, and the ID and org ID it has.
+// It returns no properties if b is not a JSON object, such as line protocol or a truncated body.
+func decodeAuditBody(b []byte) auditBody {
+	var body auditBody
+	var obj map[string]json.RawMessage
+	if len(bytes.TrimSpace(b)) == 0 || json.Unmarshal(b, &obj) != nil {
+		return body
+	}
+
+	for k, v := range obj {
+		body.properties = append(body.properties, k)
+		switch k {
+		case "id":
+			_ = json.Unmarshal(v, &body.id)
+		case "orgID", "organizationID":
+			_ = json.Unmarshal(v, &body.orgID)
+		}
+	}
+	sort.Strings(body.properties)
+	return body
+}
+
+// auditOrgID returns the organization of the call r: the orgID of its query or its body,
+// or that of the resource it responded with, or else that of its authorization.
+func auditOrgID(r *http.Request, req, res auditBody) platform.ID {
+	var id platform.ID
+	if s := r.URL.Query().Get("orgID"); s != "" && id.DecodeFromString(s) == nil {
+		return id
+	}
+	if req.orgID.Valid() {
+		return req.orgID
+	}
+	if res.orgID.Valid() {
+		return res.orgID
+	}
+	if a, err := pctx.GetAuthorizer(r.Context()); err == nil {
+		if auth, ok := a.(*platform.Authorization); ok {
+			return auth.OrgID
+		}
+	}
+	return 0
+}
+
+// remoteHost returns the host of the remote address addr.
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// auditResponseWriter captures the status code and the beginning of the body of a response.
+type auditResponseWriter struct {
+	*statusResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes b to the response, and keeps it if the body is not over maxAuditBodySize yet.
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if n := maxAuditBodySize - w.body.Len(); n > 0 {
+		if len(b) < n {
+			n = len(b)
+		}
+		w.body.Write(b[:n])
+	}
+	return w.statusResponseWriter.Write(b)
+}
+
+// AuditLogHandler represents an HTTP API handler for the audit log.
+type AuditLogHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	AuditLogService platform.AuditLogService
+}
+
+const (
+	auditPath = "/api/v2/audit"
+
+	// defaultAuditRange is the time range of the audit events returned if the request has no start.
+	defaultAuditRange = 24 * time.Hour
+)
+
+// NewAuditLogHandler returns a new instance of AuditLogHandler.
+func NewAuditLogHandler(s platform.AuditLogService) *AuditLogHandler {
+	h := &AuditLogHandler{
+		Router:          NewRouter(),
+		Logger:          zap.NewNop(),
+		AuditLogService: s,
+	}
+
+	h.HandlerFunc("GET", auditPath, h.handleGetAuditEvents)
+
+	return h
+}
+
+type auditEventsResponse struct {
+	Links  map[string]string      `json:"links"`
+	Events []*platform.AuditEvent `json:"events"`
+}
+
+// handleGetAuditEvents is the HTTP handler for the GET /api/v2/audit route.
+// The events are exported as JSON lines if the request accepts them.
+func (h *AuditLogHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeAuditEventFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	events, err := h.AuditLogService.FindAuditEvents(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if formatFromAccept(r.Header.Get("Accept")) == FormatJSON {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				logEncodingError(h.Logger, r, err)
+				return
+			}
+		}
+		return
+	}
+
+	if events == nil {
+		events = []*platform.AuditEvent{}
+	}
+	res := &auditEventsResponse{
+		Links: map[string]string{
+			"self": auditPath,
+		},
+		Events: events,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeAuditEventFilter(r *http.Request) (platform.AuditEventFilter, error) {
+	qp := r.URL.Query()
+	filter := platform.AuditEventFilter{
+		ResourceType: qp.Get("resourceType"),
+		Action:       qp.Get("action"),
+		Stop:         time.Now().UTC(),
+	}
+
+	if err := filter.OrgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return filter, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		}
+	}
+	if s := qp.Get("resourceID"); s != "" {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid resourceID",
+				Err:  err,
+			}
+		}
+		filter.ResourceID = id
+	}
+
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{
+		{name: "start", t: &filter.Start},
+		{name: "stop", t: &filter.Stop},
+	} {
+		s := qp.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  p.name + " must be an RFC3339 time",
+				Err:  err,
+			}
+		}
+		*p.t = t
+	}
+	if filter.Start.IsZero() {
+		filter.Start = filter.Stop.Add(-defaultAuditRange)
+	}
+
+	if s := qp.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 0 {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "limit must be a non-negative integer",
+			}
+		}
+		filter.Limit = limit
+	}
+
+	return filter, filter.Validate()
+}
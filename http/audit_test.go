@@ -0,0 +1,241 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+type auditRecorderFunc func(e *platform.AuditEvent)
+
+func (f auditRecorderFunc) RecordAuditEvent(e *platform.AuditEvent) { f(e) }
+
+func TestAuditHandler(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082010")
+	auth := &platform.Authorization{
+		ID:     platformtesting.MustIDBase16("020f755c3c082100"),
+		UserID: platformtesting.MustIDBase16("020f755c3c082200"),
+		OrgID:  platformtesting.MustIDBase16("020f755c3c082002"),
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   *platform.AuditEvent
+	}{
+		{
+			name:   "create",
+			method: "POST",
+			path:   "/api/v2/buckets",
+			body:   `{"orgID": "020f755c3c082001", "name": "a", "retentionRules": []}`,
+			want: &platform.AuditEvent{
+				OrgID:        orgID,
+				Action:       platform.AuditActionCreate,
+				ResourceType: "buckets",
+				ResourceID:   bucketID,
+				Changes:      []string{"name", "orgID", "retentionRules"},
+				Method:       "POST",
+				Path:         "/api/v2/buckets",
+				StatusCode:   http.StatusCreated,
+			},
+		},
+		{
+			name:   "update",
+			method: "PATCH",
+			path:   "/api/v2/buckets/020f755c3c082010",
+			body:   `{"name": "b"}`,
+			want: &platform.AuditEvent{
+				OrgID:        orgID,
+				Action:       platform.AuditActionUpdate,
+				ResourceType: "buckets",
+				ResourceID:   bucketID,
+				Changes:      []string{"name"},
+				Method:       "PATCH",
+				Path:         "/api/v2/buckets/020f755c3c082010",
+				StatusCode:   http.StatusCreated,
+			},
+		},
+		{
+			name:   "delete of the organization of the authorization",
+			method: "DELETE",
+			path:   "/api/v2/dashboards/020f755c3c082010",
+			want: &platform.AuditEvent{
+				OrgID:        auth.OrgID,
+				Action:       platform.AuditActionDelete,
+				ResourceType: "dashboards",
+				ResourceID:   bucketID,
+				Method:       "DELETE",
+				Path:         "/api/v2/dashboards/020f755c3c082010",
+				StatusCode:   http.StatusNoContent,
+			},
+		},
+		{
+			name:   "read",
+			method: "GET",
+			path:   "/api/v2/buckets",
+		},
+		{
+			name:   "write",
+			method: "POST",
+			path:   "/api/v2/write",
+			body:   "m f=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *platform.AuditEvent
+			h := NewAuditHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if string(body) != tt.body {
+					t.Errorf("handler read body %q, want %q", body, tt.body)
+				}
+				switch r.Method {
+				case "DELETE":
+					w.WriteHeader(http.StatusNoContent)
+				case "GET":
+					w.WriteHeader(http.StatusOK)
+				default:
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id": "020f755c3c082010", "orgID": "020f755c3c082001", "name": "a"}`))
+				}
+			}), auditRecorderFunc(func(e *platform.AuditEvent) {
+				got = e
+			}))
+
+			r := httptest.NewRequest(tt.method, "http://any.url"+tt.path, strings.NewReader(tt.body))
+			r.RemoteAddr = "10.0.0.1:54321"
+			r = r.WithContext(pctx.SetAuthorizer(context.Background(), auth))
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("expected no audit event, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("expected an audit event")
+			}
+			if got.Time.IsZero() {
+				t.Error("expected the time of the event")
+			}
+			tt.want.Time = got.Time
+			tt.want.AuthorizerKind = platform.AuthorizationKind
+			tt.want.AuthorizerID = auth.ID
+			tt.want.UserID = auth.UserID
+			tt.want.RemoteAddr = "10.0.0.1"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got event\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditLogHandler_handleGetAuditEvents(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	eventTime := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	var gotFilter platform.AuditEventFilter
+	s := mock.NewAuditLogService()
+	s.FindAuditEventsFn = func(ctx context.Context, filter platform.AuditEventFilter) ([]*platform.AuditEvent, error) {
+		gotFilter = filter
+		return []*platform.AuditEvent{
+			{
+				Time:         eventTime,
+				OrgID:        orgID,
+				Action:       platform.AuditActionDelete,
+				ResourceType: "buckets",
+				ResourceID:   platformtesting.MustIDBase16("020f755c3c082010"),
+				Method:       "DELETE",
+				Path:         "/api/v2/buckets/020f755c3c082010",
+				RemoteAddr:   "10.0.0.1",
+				StatusCode:   http.StatusNoContent,
+			},
+		}, nil
+	}
+	h := NewAuditLogHandler(s)
+
+	t.Run("json", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/audit?orgID=020f755c3c082001&start=2019-06-10T00:00:00Z&stop=2019-06-11T00:00:00Z&resourceType=buckets&limit=10", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		body, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d: %s", res.StatusCode, body)
+		}
+		want := platform.AuditEventFilter{
+			OrgID:        orgID,
+			Start:        time.Date(2019, 6, 10, 0, 0, 0, 0, time.UTC),
+			Stop:         time.Date(2019, 6, 11, 0, 0, 0, 0, time.UTC),
+			ResourceType: "buckets",
+			Limit:        10,
+		}
+		if !reflect.DeepEqual(gotFilter, want) {
+			t.Errorf("got filter %+v, want %+v", gotFilter, want)
+		}
+		wantBody := `
+{
+  "links": {"self": "/api/v2/audit"},
+  "events": [
+    {
+      "time": "2019-06-10T12:00:00Z",
+      "orgID": "020f755c3c082001",
+      "action": "delete",
+      "resourceType": "buckets",
+      "resourceID": "020f755c3c082010",
+      "method": "DELETE",
+      "path": "/api/v2/buckets/020f755c3c082010",
+      "remoteAddr": "10.0.0.1",
+      "statusCode": 204
+    }
+  ]
+}`
+		if eq, diff, _ := jsonEqual(string(body), wantBody); !eq {
+			t.Errorf("handleGetAuditEvents() = ***%s***", diff)
+		}
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/audit?orgID=020f755c3c082001", nil)
+		r.Header.Set("Accept", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		body, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("got status %d and content type %q: %s", res.StatusCode, res.Header.Get("Content-Type"), body)
+		}
+		if lines := strings.Split(strings.TrimSpace(string(body)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"resourceID":"020f755c3c082010"`) {
+			t.Errorf("unexpected JSON lines %q", body)
+		}
+		if d := gotFilter.Stop.Sub(gotFilter.Start); d != defaultAuditRange {
+			t.Errorf("got a time range of %s by default, want %s", d, defaultAuditRange)
+		}
+	})
+
+	t.Run("missing organization", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/audit", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want 400", w.Code)
+		}
+	})
+}
@@ -329,6 +329,12 @@ func (h *AuthorizationHandler) handleGetAuthorizations(w http.ResponseWriter, r
 		return
 	}
 
+	// The authorizations are paged once those that are not authorized are filtered out.
+	if req.opts != nil {
+		as = pageAuthorizations(as, *req.opts)
+		setOffsetPagingLinkHeader(w, r, *req.opts, len(as))
+	}
+
 	auths := make([]*authResponse, len(as))
 	for i, a := range as {
 		o, err := h.OrganizationService.FindOrganizationByID(ctx, a.OrgID)
@@ -360,6 +366,8 @@ func (h *AuthorizationHandler) handleGetAuthorizations(w http.ResponseWriter, r
 
 type getAuthorizationsRequest struct {
 	filter platform.AuthorizationFilter
+	// opts pages the authorizations if any of the paging query params is set.
+	opts *platform.FindOptions
 }
 
 func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getAuthorizationsRequest, error) {
@@ -390,9 +398,31 @@ func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getA
 		req.filter.ID = id
 	}
 
+	if qp.Get("limit") != "" || qp.Get("offset") != "" || qp.Get("cursor") != "" {
+		opts, err := decodeFindOptions(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		req.opts = opts
+	}
+
 	return req, nil
 }
 
+func pageAuthorizations(as []*platform.Authorization, opts platform.FindOptions) []*platform.Authorization {
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	if opts.Offset >= len(as) {
+		return []*platform.Authorization{}
+	}
+	as = as[opts.Offset:]
+	if opts.Limit > 0 && len(as) > opts.Limit {
+		as = as[:opts.Limit]
+	}
+	return as
+}
+
 // handleGetAuthorization is the HTTP handler for the GET /api/v2/authorizations/:id route.
 func (h *AuthorizationHandler) handleGetAuthorization(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
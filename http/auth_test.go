@@ -208,6 +208,73 @@ func TestService_handleGetAuthorizations(t *testing.T) {
 	}
 }
 
+func TestService_handleGetAuthorizations_paging(t *testing.T) {
+	authorizationBackend := NewMockAuthorizationBackend()
+	authorizationBackend.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationsFn: func(ctx context.Context, filter platform.AuthorizationFilter, opts ...platform.FindOptions) ([]*platform.Authorization, int, error) {
+			as := make([]*platform.Authorization, 5)
+			for i := range as {
+				as[i] = &platform.Authorization{ID: platform.ID(i + 1), UserID: 2, OrgID: 3}
+			}
+			return as, len(as), nil
+		},
+	}
+	authorizationBackend.UserService = &mock.UserService{
+		FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+			return &platform.User{ID: id, Name: "u"}, nil
+		},
+	}
+	authorizationBackend.OrganizationService = &mock.OrganizationService{
+		FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+			return &platform.Organization{ID: id, Name: "o"}, nil
+		},
+	}
+	h := NewAuthorizationHandler(authorizationBackend)
+
+	get := func(url string) ([]string, string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.handleGetAuthorizations(w, httptest.NewRequest("GET", url, nil))
+		res := w.Result()
+		if res.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(res.Body)
+			t.Fatalf("got status %d: %s", res.StatusCode, body)
+		}
+		var as struct {
+			Auths []struct {
+				ID string `json:"id"`
+			} `json:"authorizations"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&as); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, a := range as.Auths {
+			ids = append(ids, a.ID[len(a.ID)-1:])
+		}
+		return ids, res.Header.Get("Link")
+	}
+
+	if ids, link := get("http://any.url/api/v2/authorizations"); len(ids) != 5 || link != "" {
+		t.Errorf("expected all the authorizations without paging, got %v and link %q", ids, link)
+	}
+
+	ids, link := get("http://any.url/api/v2/authorizations?limit=2&offset=2")
+	if fmt.Sprint(ids) != "[3 4]" {
+		t.Errorf("unexpected page %v", ids)
+	}
+	next := pageCursor{Offset: 4}.String()
+	prev := pageCursor{}.String()
+	want := fmt.Sprintf(`</api/v2/authorizations?cursor=%s&limit=2>; rel="next", </api/v2/authorizations?cursor=%s&limit=2>; rel="prev"`, next, prev)
+	if link != want {
+		t.Errorf("got link %q, want %q", link, want)
+	}
+
+	if ids, _ := get("http://any.url/api/v2/authorizations?limit=2&cursor=" + next); fmt.Sprint(ids) != "[5]" {
+		t.Errorf("unexpected last page %v", ids)
+	}
+}
+
 func TestService_handleGetAuthorization(t *testing.T) {
 	type fields struct {
 		AuthorizationService platform.AuthorizationService
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	platform "github.com/influxdata/influxdb"
@@ -19,6 +20,13 @@ type AuthenticationHandler struct {
 	AuthorizationService platform.AuthorizationService
 	SessionService       platform.SessionService
 
+	// ActivityService, if set, records the use of the tokens authenticating requests.
+	ActivityService platform.ActivityService
+
+	// CertificateAuthorizations maps the common names of client certificates verified by the TLS server
+	// to the IDs of the authorizations of the requests that have neither a token nor a session.
+	CertificateAuthorizations map[string]platform.ID
+
 	// This is only really used for it's lookup method the specific http
 	// hanlder used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -72,7 +80,12 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 	scheme, err := ProbeAuthScheme(r)
 	if err != nil {
-		UnauthorizedError(ctx, w)
+		if ctx, err = h.extractCertificateAuthorization(ctx, r); err != nil {
+			UnauthorizedError(ctx, w)
+			return
+		}
+		r = r.WithContext(ctx)
+		h.Handler.ServeHTTP(w, r)
 		return
 	}
 
@@ -109,6 +122,33 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 		return ctx, err
 	}
 
+	if h.ActivityService != nil {
+		if err := h.ActivityService.RecordActivity(ctx, platform.AuthorizationsResourceType, a.ID, time.Now()); err != nil {
+			h.Logger.Info("Failed to record token use", zap.Stringer("authorization_id", a.ID), zap.Error(err))
+		}
+	}
+
+	return platcontext.SetAuthorizer(ctx, a), nil
+}
+
+// extractCertificateAuthorization authorizes the request with the authorization mapped to the common name
+// of its client certificate.
+func (h *AuthenticationHandler) extractCertificateAuthorization(ctx context.Context, r *http.Request) (context.Context, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ctx, fmt.Errorf("token required")
+	}
+
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	id, ok := h.CertificateAuthorizations[cn]
+	if !ok {
+		return ctx, fmt.Errorf("no authorization for the client certificate %q", cn)
+	}
+
+	a, err := h.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return ctx, err
+	}
+
 	return platcontext.SetAuthorizer(ctx, a), nil
 }
 
@@ -131,3 +171,22 @@ func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Requ
 
 	return platcontext.SetAuthorizer(ctx, s), nil
 }
+
+// ParseCertificateAuthorizations parses commonName=authorizationID pairs, such as telegraf=0384b45b3d0f2000,
+// into the CertificateAuthorizations of an AuthenticationHandler.
+func ParseCertificateAuthorizations(pairs []string) (map[string]platform.ID, error) {
+	auths := make(map[string]platform.ID, len(pairs))
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid client certificate authorization %q: expected commonName=authorizationID", pair)
+		}
+
+		var id platform.ID
+		if err := id.DecodeFromString(pair[i+1:]); err != nil {
+			return nil, fmt.Errorf("invalid authorization ID of client certificate %q: %v", pair[:i], err)
+		}
+		auths[pair[:i]] = id
+	}
+	return auths, nil
+}
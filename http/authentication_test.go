@@ -2,6 +2,9 @@ package http_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +12,7 @@ import (
 	"time"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	platformhttp "github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/mock"
 )
@@ -295,3 +299,98 @@ func TestAuthenticationHandler_NoAuthRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationHandler_RecordsTokenUse(t *testing.T) {
+	var recorded []platform.ID
+	h := platformhttp.NewAuthenticationHandler()
+	h.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+			return &platform.Authorization{ID: platform.ID(3)}, nil
+		},
+	}
+	h.SessionService = mock.NewSessionService()
+	h.ActivityService = &mock.ActivityService{
+		RecordActivityFn: func(ctx context.Context, rt platform.ResourceType, id platform.ID, t time.Time) error {
+			if rt != platform.AuthorizationsResourceType {
+				return fmt.Errorf("unexpected resource type %s", rt)
+			}
+			recorded = append(recorded, id)
+			return nil
+		},
+	}
+	h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://any.url", nil)
+	platformhttp.SetToken("abc123", r)
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code to be %d got %d", http.StatusOK, w.Code)
+	}
+	if len(recorded) != 1 || recorded[0] != 3 {
+		t.Errorf("expected use of authorization 0000000000000003 to be recorded, got %v", recorded)
+	}
+}
+
+func TestAuthenticationHandler_ClientCertificate(t *testing.T) {
+	auths, err := platformhttp.ParseCertificateAuthorizations([]string{"telegraf=0000000000000003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := platformhttp.NewAuthenticationHandler()
+	h.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+			return &platform.Authorization{ID: id}, nil
+		},
+	}
+	h.SessionService = mock.NewSessionService()
+	h.CertificateAuthorizations = auths
+	var authorized platform.ID
+	h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, err := pcontext.GetAuthorizer(r.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		authorized = a.Identifier()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	withCertificate := func(cn string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	tests := []struct {
+		name string
+		tls  *tls.ConnectionState
+		code int
+	}{
+		{name: "mapped certificate", tls: withCertificate("telegraf"), code: http.StatusOK},
+		{name: "unmapped certificate", tls: withCertificate("other"), code: http.StatusUnauthorized},
+		{name: "unverified certificate", tls: &tls.ConnectionState{}, code: http.StatusUnauthorized},
+		{name: "no TLS", code: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorized = 0
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://any.url", nil)
+			r.TLS = tt.tls
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status code to be %d got %d", tt.code, w.Code)
+			}
+			if tt.code == http.StatusOK && authorized != 3 {
+				t.Errorf("expected the request to be authorized by authorization 0000000000000003, got %v", authorized)
+			}
+		})
+	}
+
+	if _, err := platformhttp.ParseCertificateAuthorizations([]string{"telegraf"}); err == nil {
+		t.Error("expected an error parsing a pair without an authorization")
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handlePostBucketID is the HTTP handler for the POST /api/v2/buckets/:id route.
+// The only POST on a bucket ID is the batch of buckets.
+func (h *BucketHandler) handlePostBucketID(w http.ResponseWriter, r *http.Request) {
+	if httprouter.ParamsFromContext(r.Context()).ByName("id") == bucketsBatchID {
+		h.handlePostBucketBatch(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", "GET, PATCH, DELETE")
+	methodNotAllowedHandler(w, r)
+}
+
+// bucketBatchRequest is the body of a POST /api/v2/buckets/batch request.
+type bucketBatchRequest struct {
+	Operations []bucketBatchOperation `json:"operations"`
+}
+
+// bucketBatchOperation is an operation of a bucket batch: bucket is the bucket created by a create,
+// and update the update of the bucket id by an update.
+type bucketBatchOperation struct {
+	Op     string        `json:"op"`
+	ID     platform.ID   `json:"id,omitempty"`
+	Bucket *bucket       `json:"bucket,omitempty"`
+	Update *bucketUpdate `json:"update,omitempty"`
+}
+
+type bucketBatchResultResponse struct {
+	Index   int             `json:"index"`
+	Status  string          `json:"status"`
+	Bucket  *bucketResponse `json:"bucket,omitempty"`
+	Code    string          `json:"code,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+type bucketBatchResponse struct {
+	Applied bool                        `json:"applied"`
+	Results []bucketBatchResultResponse `json:"results"`
+}
+
+func newBucketBatchResponse(applied bool, results []platform.BucketBatchResult) *bucketBatchResponse {
+	res := &bucketBatchResponse{
+		Applied: applied,
+		Results: make([]bucketBatchResultResponse, 0, len(results)),
+	}
+	for i, result := range results {
+		r := bucketBatchResultResponse{Index: i}
+		switch {
+		case result.Err != nil:
+			r.Status = "failed"
+			r.Code = platform.ErrorCode(result.Err)
+			r.Message = platform.ErrorMessage(result.Err)
+		case applied:
+			r.Status = "applied"
+			if result.Bucket != nil {
+				r.Bucket = newBucketResponse(result.Bucket, []*platform.Label{})
+			}
+		default:
+			r.Status = "skipped"
+		}
+		res.Results = append(res.Results, r)
+	}
+	return res
+}
+
+// handlePostBucketBatch is the HTTP handler for the POST /api/v2/buckets/batch route.
+// The operations of the batch are applied all together, or none of them if any fails,
+// in which case the response is a 400 with the errors of the operations that failed.
+func (h *BucketHandler) handlePostBucketBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ops, err := decodePostBucketBatchRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// Resolve organization names to IDs before create.
+	results := make([]platform.BucketBatchResult, len(ops))
+	failed := 0
+	for i, op := range ops {
+		if op.Op != platform.BucketBatchCreate || op.Bucket == nil || op.Bucket.OrganizationID.Valid() || op.Bucket.Organization == "" {
+			continue
+		}
+		o, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &op.Bucket.Organization})
+		if err != nil {
+			results[i].Err = err
+			failed++
+			continue
+		}
+		op.Bucket.OrganizationID = o.ID
+	}
+
+	applied := false
+	if failed == 0 {
+		results, err = h.BucketBatchService.ApplyBucketBatch(ctx, ops)
+		if _, ok := err.(*platform.BucketBatchError); err != nil && !ok {
+			EncodeError(ctx, err, w)
+			return
+		}
+		applied = err == nil
+	}
+
+	status := http.StatusOK
+	if !applied {
+		status = http.StatusBadRequest
+	}
+	if err := encodeResponse(ctx, w, status, newBucketBatchResponse(applied, results)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePostBucketBatchRequest(r *http.Request) ([]platform.BucketBatchOperation, error) {
+	req := &bucketBatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "batch requires at least one operation",
+		}
+	}
+	if len(req.Operations) > platform.MaxBucketBatchSize {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("batch has %d operations; the most a batch can have is %d", len(req.Operations), platform.MaxBucketBatchSize),
+		}
+	}
+
+	ops := make([]platform.BucketBatchOperation, 0, len(req.Operations))
+	for i, o := range req.Operations {
+		b, err := o.Bucket.toInfluxDB()
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("operation %d", i),
+				Err:  err,
+			}
+		}
+		upd, err := o.Update.toInfluxDB()
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("operation %d", i),
+				Err:  err,
+			}
+		}
+		ops = append(ops, platform.BucketBatchOperation{
+			Op:     o.Op,
+			ID:     o.ID,
+			Bucket: b,
+			Update: upd,
+		})
+	}
+	return ops, nil
+}
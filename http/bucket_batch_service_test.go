@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestService_handlePostBucketBatch(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name       string
+		body       string
+		failed     bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "applied batch",
+			body: `
+{
+  "operations": [
+    {"op": "create", "bucket": {"organization": "org", "name": "a", "retentionRules": []}},
+    {"op": "delete", "id": "020f755c3c082000"}
+  ]
+}`,
+			wantStatus: http.StatusOK,
+			wantBody: `
+{
+  "applied": true,
+  "results": [
+    {
+      "index": 0,
+      "status": "applied",
+      "bucket": {
+        "links": {
+          "labels": "/api/v2/buckets/020f755c3c082010/labels",
+          "logs": "/api/v2/buckets/020f755c3c082010/logs",
+          "members": "/api/v2/buckets/020f755c3c082010/members",
+          "org": "/api/v2/orgs/020f755c3c082001",
+          "owners": "/api/v2/buckets/020f755c3c082010/owners",
+          "self": "/api/v2/buckets/020f755c3c082010",
+          "write": "/api/v2/write?org=020f755c3c082001&bucket=020f755c3c082010"
+        },
+        "id": "020f755c3c082010",
+        "organization": "org",
+        "organizationID": "020f755c3c082001",
+        "name": "a",
+        "retentionRules": [],
+        "labels": []
+      }
+    },
+    {
+      "index": 1,
+      "status": "applied"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "failed operation",
+			body: `
+{
+  "operations": [
+    {"op": "create", "bucket": {"organizationID": "020f755c3c082001", "name": "a", "retentionRules": []}},
+    {"op": "delete", "id": "020f755c3c082000"}
+  ]
+}`,
+			failed:     true,
+			wantStatus: http.StatusBadRequest,
+			wantBody: `
+{
+  "applied": false,
+  "results": [
+    {
+      "index": 0,
+      "status": "skipped"
+    },
+    {
+      "index": 1,
+      "status": "failed",
+      "code": "not found",
+      "message": "bucket not found"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:       "organization not found",
+			body:       `{"operations": [{"op": "create", "bucket": {"organization": "nope", "name": "a"}}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty batch",
+			body:       `{"operations": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.OrganizationService = &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+					if *filter.Name != "org" {
+						return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+					}
+					return &platform.Organization{ID: orgID, Name: "org"}, nil
+				},
+			}
+			bucketBackend.BucketBatchService = &mock.BucketBatchService{
+				ApplyBucketBatchFn: func(ctx context.Context, ops []platform.BucketBatchOperation) ([]platform.BucketBatchResult, error) {
+					results := make([]platform.BucketBatchResult, len(ops))
+					if tt.failed {
+						results[1].Err = &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+						return results, &platform.BucketBatchError{Failed: 1}
+					}
+					b := *ops[0].Bucket
+					b.ID = platformtesting.MustIDBase16("020f755c3c082010")
+					results[0].Bucket = &b
+					return results, nil
+				},
+			}
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/buckets/batch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wantBody); tt.wantBody != "" && !eq {
+				t.Errorf("handlePostBucketBatch() = ***%s***", diff)
+			}
+		})
+	}
+}
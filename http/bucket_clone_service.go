@@ -0,0 +1,340 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+type bucketCloneJobResponse struct {
+	Links map[string]string `json:"links"`
+	platform.BucketCloneJob
+}
+
+func newBucketCloneJobResponse(j *platform.BucketCloneJob) *bucketCloneJobResponse {
+	return &bucketCloneJobResponse{
+		Links: map[string]string{
+			"self":   bucketCloneIDPath(j.Request.BucketID, j.ID),
+			"bucket": bucketIDPath(j.Request.BucketID),
+			"clone":  bucketIDPath(j.DestBucketID),
+		},
+		BucketCloneJob: *j,
+	}
+}
+
+type bucketCloneJobsResponse struct {
+	Links map[string]string         `json:"links"`
+	Jobs  []*bucketCloneJobResponse `json:"jobs"`
+}
+
+func newBucketCloneJobsResponse(bucketID platform.ID, js []*platform.BucketCloneJob) *bucketCloneJobsResponse {
+	res := &bucketCloneJobsResponse{
+		Links: map[string]string{
+			"self":   bucketClonesPath(bucketID),
+			"bucket": bucketIDPath(bucketID),
+		},
+		Jobs: make([]*bucketCloneJobResponse, 0, len(js)),
+	}
+	for _, j := range js {
+		res.Jobs = append(res.Jobs, newBucketCloneJobResponse(j))
+	}
+	return res
+}
+
+// bucketCloneRequest is the body of a POST /api/v2/buckets/:id/clones request.
+// The clone is created in the organization of the bucket if destOrgID is not set,
+// and downsampleEvery is a duration such as "5m".
+type bucketCloneRequest struct {
+	DestOrgID       *platform.ID `json:"destOrgID,omitempty"`
+	DestBucketName  string       `json:"destBucketName"`
+	Days            int          `json:"days"`
+	DownsampleEvery string       `json:"downsampleEvery,omitempty"`
+}
+
+// handlePostBucketClone is the HTTP handler for the POST /api/v2/buckets/:id/clones route.
+func (h *BucketHandler) handlePostBucketClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := h.decodePostBucketCloneRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	job, err := h.BucketCloneService.CreateBucketCloneJob(ctx, *req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newBucketCloneJobResponse(job)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *BucketHandler) decodePostBucketCloneRequest(ctx context.Context, r *http.Request) (*platform.BucketCloneRequest, error) {
+	bucketID, _, err := decodeBucketCloneParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var br bucketCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&br); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &platform.BucketCloneRequest{
+		OrgID:          b.OrganizationID,
+		BucketID:       b.ID,
+		DestOrgID:      b.OrganizationID,
+		DestBucketName: br.DestBucketName,
+		Days:           br.Days,
+	}
+	if br.DestOrgID != nil {
+		req.DestOrgID = *br.DestOrgID
+	}
+	if br.DownsampleEvery != "" {
+		every, err := time.ParseDuration(br.DownsampleEvery)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "downsampleEvery must be a duration",
+				Err:  err,
+			}
+		}
+		req.DownsampleEvery = every
+	}
+	return req, nil
+}
+
+// handleGetBucketClones is the HTTP handler for the GET /api/v2/buckets/:id/clones route.
+func (h *BucketHandler) handleGetBucketClones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, _, err := decodeBucketCloneParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	jobs, err := h.BucketCloneService.FindBucketCloneJobs(ctx, platform.BucketCloneJobFilter{BucketID: &bucketID})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketCloneJobsResponse(bucketID, jobs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetBucketClone is the HTTP handler for the GET /api/v2/buckets/:id/clones/:cloneID route.
+func (h *BucketHandler) handleGetBucketClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, id, err := decodeBucketCloneParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	job, err := h.BucketCloneService.FindBucketCloneJobByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if job.Request.BucketID != bucketID {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "bucket clone job not found",
+		}, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketCloneJobResponse(job)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodeBucketCloneParams returns the bucket ID and, if any, the clone job ID of the route.
+func decodeBucketCloneParams(ctx context.Context) (bucketID, id platform.ID, err error) {
+	params := httprouter.ParamsFromContext(ctx)
+	bid := params.ByName("id")
+	if bid == "" {
+		return 0, 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+	if err := bucketID.DecodeFromString(bid); err != nil {
+		return 0, 0, err
+	}
+
+	if cid := params.ByName("cloneID"); cid != "" {
+		if err := id.DecodeFromString(cid); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return bucketID, id, nil
+}
+
+// BucketCloneService connects to Influx via HTTP using tokens to clone buckets.
+type BucketCloneService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+
+	// BucketID is the bucket of the clone jobs found by ID,
+	// and of the clone jobs found with a filter without a bucket.
+	BucketID platform.ID
+}
+
+var _ platform.BucketCloneService = (*BucketCloneService)(nil)
+
+// CreateBucketCloneJob creates the bucket the data of req are copied into, and queues the job copying them.
+// The organization of the bucket cloned is the one of the bucket.
+func (s *BucketCloneService) CreateBucketCloneJob(ctx context.Context, req platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+	u, err := newURL(s.Addr, bucketClonesPath(req.BucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	br := bucketCloneRequest{
+		DestBucketName: req.DestBucketName,
+		Days:           req.Days,
+	}
+	if req.DestOrgID.Valid() {
+		br.DestOrgID = &req.DestOrgID
+	}
+	if req.DownsampleEvery > 0 {
+		br.DownsampleEvery = req.DownsampleEvery.String()
+	}
+	octets, err := json.Marshal(br)
+	if err != nil {
+		return nil, err
+	}
+
+	hreq, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r bucketCloneJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r.BucketCloneJob, nil
+}
+
+// FindBucketCloneJobByID returns a single clone job of the bucket of the service by ID.
+func (s *BucketCloneService) FindBucketCloneJobByID(ctx context.Context, id platform.ID) (*platform.BucketCloneJob, error) {
+	u, err := newURL(s.Addr, bucketCloneIDPath(s.BucketID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r bucketCloneJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r.BucketCloneJob, nil
+}
+
+// FindBucketCloneJobs returns the clone jobs of a bucket, most recent first.
+// Only the bucket of the filter is sent to the server.
+func (s *BucketCloneService) FindBucketCloneJobs(ctx context.Context, filter platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error) {
+	bucketID := s.BucketID
+	if filter.BucketID != nil {
+		bucketID = *filter.BucketID
+	}
+
+	u, err := newURL(s.Addr, bucketClonesPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r bucketCloneJobsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*platform.BucketCloneJob, 0, len(r.Jobs))
+	for _, j := range r.Jobs {
+		jobs = append(jobs, &j.BucketCloneJob)
+	}
+	return jobs, nil
+}
+
+func bucketClonesPath(bucketID platform.ID) string {
+	return path.Join(bucketIDPath(bucketID), "clones")
+}
+
+func bucketCloneIDPath(bucketID, id platform.ID) string {
+	return path.Join(bucketClonesPath(bucketID), id.String())
+}
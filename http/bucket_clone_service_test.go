@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestService_handlePostBucketClone(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	destOrgID := platformtesting.MustIDBase16("020f755c3c082002")
+	created := time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantReq    platform.BucketCloneRequest
+		wantBody   string
+	}{
+		{
+			name:       "downsampled clone in another organization",
+			body:       `{"destOrgID": "020f755c3c082002", "destBucketName": "staging", "days": 7, "downsampleEvery": "5m"}`,
+			wantStatus: http.StatusAccepted,
+			wantReq: platform.BucketCloneRequest{
+				OrgID:           orgID,
+				BucketID:        bucketID,
+				DestOrgID:       destOrgID,
+				DestBucketName:  "staging",
+				Days:            7,
+				DownsampleEvery: 5 * time.Minute,
+			},
+			wantBody: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/clones/020f755c3c082010",
+    "bucket": "/api/v2/buckets/020f755c3c082000",
+    "clone": "/api/v2/buckets/020f755c3c082020"
+  },
+  "id": "020f755c3c082010",
+  "request": {
+    "orgID": "020f755c3c082001",
+    "bucketID": "020f755c3c082000",
+    "destOrgID": "020f755c3c082002",
+    "destBucketName": "staging",
+    "days": 7,
+    "downsampleEvery": 300000000000
+  },
+  "destBucketID": "020f755c3c082020",
+  "status": "queued",
+  "daysCopied": 0,
+  "pointsWritten": 0,
+  "createdAt": "2019-06-10T12:30:00Z",
+  "startedAt": "0001-01-01T00:00:00Z",
+  "finishedAt": "0001-01-01T00:00:00Z"
+}
+`,
+		},
+		{
+			name:       "clone in the organization of the bucket",
+			body:       `{"destBucketName": "staging", "days": 1}`,
+			wantStatus: http.StatusAccepted,
+			wantReq: platform.BucketCloneRequest{
+				OrgID:          orgID,
+				BucketID:       bucketID,
+				DestOrgID:      orgID,
+				DestBucketName: "staging",
+				Days:           1,
+			},
+		},
+		{
+			name:       "invalid downsampling",
+			body:       `{"destBucketName": "staging", "days": 1, "downsampleEvery": "often"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.BucketService = newReplicationBucketService(orgID)
+			bucketBackend.BucketCloneService = &mock.BucketCloneService{
+				CreateBucketCloneJobFn: func(ctx context.Context, req platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+					if diff := cmp.Diff(tt.wantReq, req); diff != "" {
+						t.Errorf("unexpected request -want/+got:\n%s", diff)
+					}
+					return &platform.BucketCloneJob{
+						ID:           platformtesting.MustIDBase16("020f755c3c082010"),
+						Request:      req,
+						DestBucketID: platformtesting.MustIDBase16("020f755c3c082020"),
+						Status:       platform.BucketCloneJobQueued,
+						CreatedAt:    created,
+					}, nil
+				},
+			}
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/buckets/020f755c3c082000/clones", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wantBody); tt.wantBody != "" && !eq {
+				t.Errorf("handlePostBucketClone() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketClone(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	job := &platform.BucketCloneJob{
+		ID:      platformtesting.MustIDBase16("020f755c3c082010"),
+		Request: platform.BucketCloneRequest{BucketID: bucketID},
+		Status:  platform.BucketCloneJobRunning,
+	}
+
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.BucketCloneService = &mock.BucketCloneService{
+		FindBucketCloneJobByIDFn: func(ctx context.Context, id platform.ID) (*platform.BucketCloneJob, error) {
+			if id != job.ID {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket clone job not found"}
+			}
+			return job, nil
+		},
+	}
+	h := NewBucketHandler(bucketBackend)
+
+	for path, want := range map[string]int{
+		"/api/v2/buckets/020f755c3c082000/clones/020f755c3c082010": http.StatusOK,
+		"/api/v2/buckets/020f755c3c082000/clones/020f755c3c082011": http.StatusNotFound,
+		// The job is not a clone of this bucket.
+		"/api/v2/buckets/020f755c3c082001/clones/020f755c3c082010": http.StatusNotFound,
+	} {
+		r := httptest.NewRequest("GET", "http://any.url"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("GET %s: got status %d, want %d: %s", path, w.Code, want, w.Body.String())
+		}
+	}
+}
+
+func TestBucketCloneService(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	var jobs []*platform.BucketCloneJob
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.BucketService = newReplicationBucketService(orgID)
+	bucketBackend.BucketCloneService = &mock.BucketCloneService{
+		CreateBucketCloneJobFn: func(ctx context.Context, req platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+			j := &platform.BucketCloneJob{
+				ID:           platform.ID(100 + len(jobs)),
+				Request:      req,
+				DestBucketID: 200,
+				Status:       platform.BucketCloneJobQueued,
+			}
+			jobs = append(jobs, j)
+			return j, nil
+		},
+		FindBucketCloneJobsFn: func(ctx context.Context, filter platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error) {
+			if filter.BucketID == nil || *filter.BucketID != bucketID {
+				t.Errorf("unexpected filter %+v", filter)
+			}
+			return jobs, nil
+		},
+		FindBucketCloneJobByIDFn: func(ctx context.Context, id platform.ID) (*platform.BucketCloneJob, error) {
+			for _, j := range jobs {
+				if j.ID == id {
+					return j, nil
+				}
+			}
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket clone job not found"}
+		},
+	}
+	server := httptest.NewServer(NewBucketHandler(bucketBackend))
+	defer server.Close()
+
+	s := &BucketCloneService{Addr: server.URL, BucketID: bucketID}
+	job, err := s.CreateBucketCloneJob(context.Background(), platform.BucketCloneRequest{
+		BucketID:        bucketID,
+		DestBucketName:  "staging",
+		Days:            3,
+		DownsampleEvery: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := platform.BucketCloneRequest{
+		OrgID:           orgID,
+		BucketID:        bucketID,
+		DestOrgID:       orgID,
+		DestBucketName:  "staging",
+		Days:            3,
+		DownsampleEvery: time.Hour,
+	}
+	if diff := cmp.Diff(want, job.Request); diff != "" {
+		t.Errorf("unexpected request -want/+got:\n%s", diff)
+	}
+
+	found, err := s.FindBucketCloneJobs(context.Background(), platform.BucketCloneJobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != job.ID || found[0].DestBucketID != 200 {
+		t.Errorf("unexpected clone jobs %+v", found)
+	}
+
+	if _, err := s.FindBucketCloneJobByID(context.Background(), job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindBucketCloneJobByID(context.Background(), 999); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
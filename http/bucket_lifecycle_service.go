@@ -0,0 +1,507 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// bucketLifecycleTier is a tier of a bucket lifecycle policy, with its durations formatted such as "90d".
+// A retention of "0s" keeps the data of the tier forever.
+type bucketLifecycleTier struct {
+	Every     string       `json:"every"`
+	Retention string       `json:"retention"`
+	Fn        string       `json:"fn,omitempty"`
+	BucketID  *platform.ID `json:"bucketID,omitempty"`
+	TaskID    *platform.ID `json:"taskID,omitempty"`
+}
+
+func newBucketLifecycleTier(t platform.BucketLifecycleTier) bucketLifecycleTier {
+	tier := bucketLifecycleTier{
+		Every:     FormatDuration(t.Every),
+		Retention: FormatDuration(t.Retention),
+		Fn:        t.Fn,
+	}
+	if t.BucketID.Valid() {
+		bucketID := t.BucketID
+		tier.BucketID = &bucketID
+	}
+	if t.TaskID.Valid() {
+		taskID := t.TaskID
+		tier.TaskID = &taskID
+	}
+	return tier
+}
+
+func (t bucketLifecycleTier) toPlatform() (platform.BucketLifecycleTier, error) {
+	every, err := parseLifecycleDuration("every", t.Every)
+	if err != nil {
+		return platform.BucketLifecycleTier{}, err
+	}
+	retention, err := parseLifecycleDuration("retention", t.Retention)
+	if err != nil {
+		return platform.BucketLifecycleTier{}, err
+	}
+	return platform.BucketLifecycleTier{
+		Every:     every,
+		Retention: retention,
+		Fn:        t.Fn,
+	}, nil
+}
+
+// parseLifecycleDuration parses a duration of a bucket lifecycle policy, zero if it is not set.
+func parseLifecycleDuration(name, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := ParseDuration(s)
+	if err != nil {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "bucket lifecycle " + name + " must be a duration",
+			Err:  err,
+		}
+	}
+	return d, nil
+}
+
+// bucketLifecyclePolicyRequest is the body of a PUT /api/v2/buckets/:id/lifecycle request.
+type bucketLifecyclePolicyRequest struct {
+	RawRetention string                `json:"rawRetention"`
+	Tiers        []bucketLifecycleTier `json:"tiers"`
+}
+
+type bucketLifecyclePolicyResponse struct {
+	Links        map[string]string     `json:"links"`
+	BucketID     platform.ID           `json:"bucketID"`
+	OrgID        platform.ID           `json:"orgID"`
+	RawRetention string                `json:"rawRetention"`
+	Tiers        []bucketLifecycleTier `json:"tiers"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
+}
+
+func newBucketLifecyclePolicyResponse(p *platform.BucketLifecyclePolicy) *bucketLifecyclePolicyResponse {
+	res := &bucketLifecyclePolicyResponse{
+		Links:        bucketLifecycleLinks(p.BucketID),
+		BucketID:     p.BucketID,
+		OrgID:        p.OrgID,
+		RawRetention: FormatDuration(p.RawRetention),
+		Tiers:        make([]bucketLifecycleTier, 0, len(p.Tiers)),
+		UpdatedAt:    p.UpdatedAt,
+	}
+	for _, t := range p.Tiers {
+		res.Tiers = append(res.Tiers, newBucketLifecycleTier(t))
+	}
+	return res
+}
+
+func (r *bucketLifecyclePolicyResponse) toPlatform() (*platform.BucketLifecyclePolicy, error) {
+	rawRetention, err := parseLifecycleDuration("rawRetention", r.RawRetention)
+	if err != nil {
+		return nil, err
+	}
+	p := &platform.BucketLifecyclePolicy{
+		BucketID:     r.BucketID,
+		OrgID:        r.OrgID,
+		RawRetention: rawRetention,
+		Tiers:        make([]platform.BucketLifecycleTier, 0, len(r.Tiers)),
+		UpdatedAt:    r.UpdatedAt,
+	}
+	for _, rt := range r.Tiers {
+		t, err := rt.toPlatform()
+		if err != nil {
+			return nil, err
+		}
+		if rt.BucketID != nil {
+			t.BucketID = *rt.BucketID
+		}
+		if rt.TaskID != nil {
+			t.TaskID = *rt.TaskID
+		}
+		p.Tiers = append(p.Tiers, t)
+	}
+	return p, nil
+}
+
+type bucketLifecycleTierStatus struct {
+	bucketLifecycleTier
+	State      string        `json:"state"`
+	BucketName string        `json:"bucketName,omitempty"`
+	TaskStatus string        `json:"taskStatus,omitempty"`
+	LatestRun  *platform.Run `json:"latestRun,omitempty"`
+}
+
+type bucketLifecycleStatusResponse struct {
+	Links        map[string]string           `json:"links"`
+	BucketID     platform.ID                 `json:"bucketID"`
+	OrgID        platform.ID                 `json:"orgID"`
+	State        string                      `json:"state"`
+	RawRetention string                      `json:"rawRetention"`
+	Tiers        []bucketLifecycleTierStatus `json:"tiers"`
+}
+
+func newBucketLifecycleStatusResponse(st *platform.BucketLifecycleStatus) *bucketLifecycleStatusResponse {
+	res := &bucketLifecycleStatusResponse{
+		Links:        bucketLifecycleLinks(st.BucketID),
+		BucketID:     st.BucketID,
+		OrgID:        st.OrgID,
+		State:        st.State,
+		RawRetention: FormatDuration(st.RawRetention),
+		Tiers:        make([]bucketLifecycleTierStatus, 0, len(st.Tiers)),
+	}
+	for _, t := range st.Tiers {
+		res.Tiers = append(res.Tiers, bucketLifecycleTierStatus{
+			bucketLifecycleTier: newBucketLifecycleTier(t.BucketLifecycleTier),
+			State:               t.State,
+			BucketName:          t.BucketName,
+			TaskStatus:          t.TaskStatus,
+			LatestRun:           t.LatestRun,
+		})
+	}
+	return res
+}
+
+func (r *bucketLifecycleStatusResponse) toPlatform() (*platform.BucketLifecycleStatus, error) {
+	rawRetention, err := parseLifecycleDuration("rawRetention", r.RawRetention)
+	if err != nil {
+		return nil, err
+	}
+	st := &platform.BucketLifecycleStatus{
+		BucketID:     r.BucketID,
+		OrgID:        r.OrgID,
+		State:        r.State,
+		RawRetention: rawRetention,
+		Tiers:        make([]platform.BucketLifecycleTierStatus, 0, len(r.Tiers)),
+	}
+	for _, rt := range r.Tiers {
+		t, err := rt.toPlatform()
+		if err != nil {
+			return nil, err
+		}
+		if rt.BucketID != nil {
+			t.BucketID = *rt.BucketID
+		}
+		if rt.TaskID != nil {
+			t.TaskID = *rt.TaskID
+		}
+		st.Tiers = append(st.Tiers, platform.BucketLifecycleTierStatus{
+			BucketLifecycleTier: t,
+			State:               rt.State,
+			BucketName:          rt.BucketName,
+			TaskStatus:          rt.TaskStatus,
+			LatestRun:           rt.LatestRun,
+		})
+	}
+	return st, nil
+}
+
+func bucketLifecycleLinks(bucketID platform.ID) map[string]string {
+	return map[string]string{
+		"self":   bucketLifecyclePath(bucketID),
+		"status": bucketLifecycleStatusPath(bucketID),
+		"bucket": bucketIDPath(bucketID),
+	}
+}
+
+// handleGetBucketLifecycle is the HTTP handler for the GET /api/v2/buckets/:id/lifecycle route.
+func (h *BucketHandler) handleGetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, err := decodeBucketLifecycleParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	p, err := h.BucketLifecycleService.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketLifecyclePolicyResponse(p)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePutBucketLifecycle is the HTTP handler for the PUT /api/v2/buckets/:id/lifecycle route.
+func (h *BucketHandler) handlePutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p, err := h.decodePutBucketLifecycleRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketLifecycleService.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketLifecyclePolicyResponse(p)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *BucketHandler) decodePutBucketLifecycleRequest(ctx context.Context, r *http.Request) (*platform.BucketLifecyclePolicy, error) {
+	bucketID, err := decodeBucketLifecycleParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req bucketLifecyclePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRetention, err := parseLifecycleDuration("rawRetention", req.RawRetention)
+	if err != nil {
+		return nil, err
+	}
+	p := &platform.BucketLifecyclePolicy{
+		BucketID:     b.ID,
+		OrgID:        b.OrganizationID,
+		RawRetention: rawRetention,
+		Tiers:        make([]platform.BucketLifecycleTier, 0, len(req.Tiers)),
+	}
+	for _, rt := range req.Tiers {
+		t, err := rt.toPlatform()
+		if err != nil {
+			return nil, err
+		}
+		p.Tiers = append(p.Tiers, t)
+	}
+	return p, nil
+}
+
+// handleDeleteBucketLifecycle is the HTTP handler for the DELETE /api/v2/buckets/:id/lifecycle route.
+func (h *BucketHandler) handleDeleteBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, err := decodeBucketLifecycleParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketLifecycleService.DeleteBucketLifecyclePolicy(ctx, bucketID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBucketLifecycleStatus is the HTTP handler for the GET /api/v2/buckets/:id/lifecycle/status route.
+func (h *BucketHandler) handleGetBucketLifecycleStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, err := decodeBucketLifecycleParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	st, err := h.BucketLifecycleService.FindBucketLifecycleStatus(ctx, bucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketLifecycleStatusResponse(st)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodeBucketLifecycleParams returns the bucket ID of the route.
+func decodeBucketLifecycleParams(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var bucketID platform.ID
+	if err := bucketID.DecodeFromString(id); err != nil {
+		return 0, err
+	}
+	return bucketID, nil
+}
+
+// BucketLifecycleService connects to Influx via HTTP using tokens to manage the lifecycle policies of buckets.
+type BucketLifecycleService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.BucketLifecycleService = (*BucketLifecycleService)(nil)
+
+// FindBucketLifecyclePolicy returns the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) FindBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecyclePolicy, error) {
+	u, err := newURL(s.Addr, bucketLifecyclePath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r bucketLifecyclePolicyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.toPlatform()
+}
+
+// PutBucketLifecyclePolicy sets the lifecycle policy of a bucket, and sets p with the policy materialized.
+// The organization of the policy is the one of the bucket.
+func (s *BucketLifecycleService) PutBucketLifecyclePolicy(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+	u, err := newURL(s.Addr, bucketLifecyclePath(p.BucketID))
+	if err != nil {
+		return err
+	}
+
+	br := bucketLifecyclePolicyRequest{
+		RawRetention: FormatDuration(p.RawRetention),
+		Tiers:        make([]bucketLifecycleTier, 0, len(p.Tiers)),
+	}
+	for _, t := range p.Tiers {
+		br.Tiers = append(br.Tiers, bucketLifecycleTier{
+			Every:     FormatDuration(t.Every),
+			Retention: FormatDuration(t.Retention),
+			Fn:        t.Fn,
+		})
+	}
+	octets, err := json.Marshal(br)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var r bucketLifecyclePolicyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	materialized, err := r.toPlatform()
+	if err != nil {
+		return err
+	}
+	*p = *materialized
+	return nil
+}
+
+// DeleteBucketLifecyclePolicy removes the lifecycle policy of a bucket, along with its tasks.
+func (s *BucketLifecycleService) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) error {
+	u, err := newURL(s.Addr, bucketLifecyclePath(bucketID))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// FindBucketLifecycleStatus returns the status of the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) FindBucketLifecycleStatus(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecycleStatus, error) {
+	u, err := newURL(s.Addr, bucketLifecycleStatusPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r bucketLifecycleStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.toPlatform()
+}
+
+func bucketLifecyclePath(bucketID platform.ID) string {
+	return path.Join(bucketIDPath(bucketID), "lifecycle")
+}
+
+func bucketLifecycleStatusPath(bucketID platform.ID) string {
+	return path.Join(bucketLifecyclePath(bucketID), "status")
+}
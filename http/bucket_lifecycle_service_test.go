@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+const day = 24 * time.Hour
+
+func TestService_handlePutBucketLifecycle(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	updated := time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantPolicy *platform.BucketLifecyclePolicy
+		wantBody   string
+	}{
+		{
+			name:       "raw for 7 days, 1m for 90 days and 1h for 2 years",
+			body:       `{"rawRetention": "7d", "tiers": [{"every": "1m", "retention": "90d"}, {"every": "1h", "retention": "2y", "fn": "max"}]}`,
+			wantStatus: http.StatusOK,
+			wantPolicy: &platform.BucketLifecyclePolicy{
+				BucketID:     bucketID,
+				OrgID:        orgID,
+				RawRetention: 7 * day,
+				Tiers: []platform.BucketLifecycleTier{
+					{Every: time.Minute, Retention: 90 * day},
+					{Every: time.Hour, Retention: 730 * day, Fn: "max"},
+				},
+			},
+			wantBody: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/lifecycle",
+    "status": "/api/v2/buckets/020f755c3c082000/lifecycle/status",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "orgID": "020f755c3c082001",
+  "rawRetention": "1w",
+  "tiers": [
+    {
+      "every": "1m",
+      "retention": "3mo",
+      "bucketID": "020f755c3c082010",
+      "taskID": "020f755c3c082020"
+    },
+    {
+      "every": "1h",
+      "retention": "2y",
+      "fn": "max",
+      "bucketID": "020f755c3c082011",
+      "taskID": "020f755c3c082021"
+    }
+  ],
+  "updatedAt": "2019-06-10T12:30:00Z"
+}
+`,
+		},
+		{
+			name:       "raw data and tier kept forever",
+			body:       `{"tiers": [{"every": "5m"}]}`,
+			wantStatus: http.StatusOK,
+			wantPolicy: &platform.BucketLifecyclePolicy{
+				BucketID: bucketID,
+				OrgID:    orgID,
+				Tiers: []platform.BucketLifecycleTier{
+					{Every: 5 * time.Minute},
+				},
+			},
+		},
+		{
+			name:       "invalid duration",
+			body:       `{"rawRetention": "a week", "tiers": [{"every": "1m"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.BucketService = newReplicationBucketService(orgID)
+			bucketBackend.BucketLifecycleService = &mock.BucketLifecycleService{
+				PutBucketLifecyclePolicyFn: func(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+					if diff := cmp.Diff(tt.wantPolicy, p); diff != "" {
+						t.Errorf("unexpected policy -want/+got:\n%s", diff)
+					}
+					for i := range p.Tiers {
+						p.Tiers[i].BucketID = platformtesting.MustIDBase16("020f755c3c082010") + platform.ID(i)
+						p.Tiers[i].TaskID = platformtesting.MustIDBase16("020f755c3c082020") + platform.ID(i)
+					}
+					p.UpdatedAt = updated
+					return nil
+				},
+			}
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("PUT", "http://any.url/api/v2/buckets/020f755c3c082000/lifecycle", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wantBody); tt.wantBody != "" && !eq {
+				t.Errorf("handlePutBucketLifecycle() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestBucketLifecycleService(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	var stored *platform.BucketLifecyclePolicy
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.BucketService = newReplicationBucketService(orgID)
+	bucketBackend.BucketLifecycleService = &mock.BucketLifecycleService{
+		PutBucketLifecyclePolicyFn: func(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+			p.Tiers[0].BucketID, p.Tiers[0].TaskID = 100, 200
+			p.UpdatedAt = time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC)
+			stored = p
+			return nil
+		},
+		FindBucketLifecyclePolicyFn: func(ctx context.Context, id platform.ID) (*platform.BucketLifecyclePolicy, error) {
+			if stored == nil || id != stored.BucketID {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrBucketLifecyclePolicyNotFound}
+			}
+			return stored, nil
+		},
+		DeleteBucketLifecyclePolicyFn: func(ctx context.Context, id platform.ID) error {
+			if stored == nil || id != stored.BucketID {
+				return &platform.Error{Code: platform.ENotFound, Msg: platform.ErrBucketLifecyclePolicyNotFound}
+			}
+			stored = nil
+			return nil
+		},
+		FindBucketLifecycleStatusFn: func(ctx context.Context, id platform.ID) (*platform.BucketLifecycleStatus, error) {
+			return &platform.BucketLifecycleStatus{
+				BucketID:     id,
+				OrgID:        orgID,
+				State:        platform.BucketLifecycleStateFailing,
+				RawRetention: stored.RawRetention,
+				Tiers: []platform.BucketLifecycleTierStatus{
+					{
+						BucketLifecycleTier: stored.Tiers[0],
+						State:               platform.BucketLifecycleStateFailing,
+						BucketName:          "hello_1m",
+						TaskStatus:          platform.TaskStatusActive,
+						LatestRun:           &platform.Run{ID: 300, TaskID: 200, Status: "failed", ScheduledFor: "2019-06-10T12:29:00Z"},
+					},
+				},
+			}, nil
+		},
+	}
+	server := httptest.NewServer(NewBucketHandler(bucketBackend))
+	defer server.Close()
+
+	s := &BucketLifecycleService{Addr: server.URL}
+	ctx := context.Background()
+
+	p := &platform.BucketLifecyclePolicy{
+		BucketID:     bucketID,
+		RawRetention: 7 * day,
+		Tiers:        []platform.BucketLifecycleTier{{Every: time.Minute, Retention: 90 * day, Fn: "last"}},
+	}
+	if err := s.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	want := &platform.BucketLifecyclePolicy{
+		BucketID:     bucketID,
+		OrgID:        orgID,
+		RawRetention: 7 * day,
+		Tiers:        []platform.BucketLifecycleTier{{Every: time.Minute, Retention: 90 * day, Fn: "last", BucketID: 100, TaskID: 200}},
+		UpdatedAt:    time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("unexpected policy set -want/+got:\n%s", diff)
+	}
+
+	found, err := s.FindBucketLifecyclePolicy(ctx, bucketID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, found); diff != "" {
+		t.Errorf("unexpected policy found -want/+got:\n%s", diff)
+	}
+
+	st, err := s.FindBucketLifecycleStatus(ctx, bucketID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != platform.BucketLifecycleStateFailing || len(st.Tiers) != 1 || st.Tiers[0].TaskID != 200 ||
+		st.Tiers[0].BucketName != "hello_1m" || st.Tiers[0].LatestRun == nil || st.Tiers[0].LatestRun.ID != 300 {
+		t.Errorf("unexpected status %+v", st)
+	}
+
+	if err := s.DeleteBucketLifecyclePolicy(ctx, bucketID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindBucketLifecyclePolicy(ctx, bucketID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
@@ -23,6 +23,12 @@ type BucketBackend struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	CoverageGapService         influxdb.CoverageGapService
+	ReplicationService         influxdb.ReplicationService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
+	BucketCloneService         influxdb.BucketCloneService
+	BucketBatchService         influxdb.BucketBatchService
+	BucketLifecycleService     influxdb.BucketLifecycleService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -36,6 +42,12 @@ func NewBucketBackend(b *APIBackend) *BucketBackend {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		CoverageGapService:         b.CoverageGapService,
+		ReplicationService:         b.ReplicationService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
+		BucketCloneService:         b.BucketCloneService,
+		BucketBatchService:         b.BucketBatchService,
+		BucketLifecycleService:     b.BucketLifecycleService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -51,6 +63,12 @@ type BucketHandler struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	CoverageGapService         influxdb.CoverageGapService
+	ReplicationService         influxdb.ReplicationService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
+	BucketCloneService         influxdb.BucketCloneService
+	BucketBatchService         influxdb.BucketBatchService
+	BucketLifecycleService     influxdb.BucketLifecycleService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -61,12 +79,20 @@ const (
 	bucketsPath            = "/api/v2/buckets"
 	bucketsIDPath          = "/api/v2/buckets/:id"
 	bucketsIDLogPath       = "/api/v2/buckets/:id/logs"
+	bucketsIDGapsPath      = "/api/v2/buckets/:id/gaps"
+	bucketsIDReplPath      = "/api/v2/buckets/:id/replication"
+	bucketsIDSchemasPath   = "/api/v2/buckets/:id/schema/measurements"
+	bucketsIDSchemasIDPath = "/api/v2/buckets/:id/schema/measurements/:measurementID"
+	bucketsIDClonesPath    = "/api/v2/buckets/:id/clones"
+	bucketsIDClonesIDPath  = "/api/v2/buckets/:id/clones/:cloneID"
+	bucketsIDLifecyclePath = "/api/v2/buckets/:id/lifecycle"
 	bucketsIDMembersPath   = "/api/v2/buckets/:id/members"
 	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
 	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
 	bucketsIDOwnersIDPath  = "/api/v2/buckets/:id/owners/:userID"
 	bucketsIDLabelsPath    = "/api/v2/buckets/:id/labels"
 	bucketsIDLabelsIDPath  = "/api/v2/buckets/:id/labels/:lid"
+	bucketsBatchID         = "batch"
 )
 
 // NewBucketHandler returns a new instance of BucketHandler.
@@ -77,6 +103,12 @@ func NewBucketHandler(b *BucketBackend) *BucketHandler {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		CoverageGapService:         b.CoverageGapService,
+		ReplicationService:         b.ReplicationService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
+		BucketCloneService:         b.BucketCloneService,
+		BucketBatchService:         b.BucketBatchService,
+		BucketLifecycleService:     b.BucketLifecycleService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -86,9 +118,24 @@ func NewBucketHandler(b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("POST", bucketsPath, h.handlePostBucket)
 	h.HandlerFunc("GET", bucketsPath, h.handleGetBuckets)
 	h.HandlerFunc("GET", bucketsIDPath, h.handleGetBucket)
+	h.HandlerFunc("POST", bucketsIDPath, h.handlePostBucketID)
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
+	h.HandlerFunc("GET", bucketsIDGapsPath, h.handleGetBucketGaps)
+	h.HandlerFunc("GET", bucketsIDReplPath, h.handleGetBucketReplication)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
+	h.HandlerFunc("GET", bucketsIDSchemasPath, h.handleGetMeasurementSchemas)
+	h.HandlerFunc("POST", bucketsIDSchemasPath, h.handlePostMeasurementSchema)
+	h.HandlerFunc("GET", bucketsIDSchemasIDPath, h.handleGetMeasurementSchema)
+	h.HandlerFunc("PATCH", bucketsIDSchemasIDPath, h.handlePatchMeasurementSchema)
+	h.HandlerFunc("DELETE", bucketsIDSchemasIDPath, h.handleDeleteMeasurementSchema)
+	h.HandlerFunc("POST", bucketsIDClonesPath, h.handlePostBucketClone)
+	h.HandlerFunc("GET", bucketsIDClonesPath, h.handleGetBucketClones)
+	h.HandlerFunc("GET", bucketsIDClonesIDPath, h.handleGetBucketClone)
+	h.HandlerFunc("GET", bucketsIDLifecyclePath, h.handleGetBucketLifecycle)
+	h.HandlerFunc("PUT", bucketsIDLifecyclePath, h.handlePutBucketLifecycle)
+	h.HandlerFunc("DELETE", bucketsIDLifecyclePath, h.handleDeleteBucketLifecycle)
+	h.HandlerFunc("GET", bucketsIDLifecyclePath+"/status", h.handleGetBucketLifecycleStatus)
 
 	memberBackend := MemberBackend{
 		Logger:                     b.Logger.With(zap.String("handler", "member")),
@@ -132,6 +179,8 @@ type bucket struct {
 	Name                string          `json:"name"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+
+	SchemaType influxdb.BucketSchemaType `json:"schemaType,omitempty"`
 }
 
 // retentionRule is the retention rule action for a bucket.
@@ -165,6 +214,7 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		SchemaType:          b.SchemaType,
 	}, nil
 }
 
@@ -189,6 +239,7 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Name:                pb.Name,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		SchemaType:          pb.SchemaType,
 	}
 }
 
@@ -461,6 +512,7 @@ func (h *BucketHandler) handleGetBuckets(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setOffsetPagingLinkHeader(w, r, req.opts, len(bs))
 	if err := encodeResponse(ctx, w, http.StatusOK, newBucketsResponse(ctx, req.opts, req.filter, bs, h.LabelService)); err != nil {
 		logEncodingError(h.Logger, r, err)
 		return
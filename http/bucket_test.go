@@ -26,6 +26,11 @@ func NewMockBucketBackend() *BucketBackend {
 
 		BucketService:              mock.NewBucketService(),
 		BucketOperationLogService:  mock.NewBucketOperationLogService(),
+		CoverageGapService:         mock.NewCoverageGapService(),
+		ReplicationService:         mock.NewReplicationService(),
+		BucketCloneService:         mock.NewBucketCloneService(),
+		BucketBatchService:         mock.NewBucketBatchService(),
+		BucketLifecycleService:     mock.NewBucketLifecycleService(),
 		UserResourceMappingService: mock.NewUserResourceMappingService(),
 		LabelService:               mock.NewLabelService(),
 		UserService:                mock.NewUserService(),
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CompactionHandler represents an HTTP API handler for the compaction settings of the storage engine
+type CompactionHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	CompactionService platform.CompactionService
+}
+
+const (
+	compactionsPath = "/api/v2/ops/compactions"
+)
+
+// NewCompactionHandler returns a new instance of CompactionHandler
+func NewCompactionHandler(s platform.CompactionService) *CompactionHandler {
+	h := &CompactionHandler{
+		Router:            NewRouter(),
+		Logger:            zap.NewNop(),
+		CompactionService: s,
+	}
+
+	h.HandlerFunc("GET", compactionsPath, h.handleGetCompactionSettings)
+	h.HandlerFunc("PATCH", compactionsPath, h.handlePatchCompactionSettings)
+
+	return h
+}
+
+// compactionSettingsResponse is the JSON of the compaction settings, with the duration as a string such as "4h0m0s".
+type compactionSettingsResponse struct {
+	Links                 map[string]string `json:"links"`
+	MaxConcurrent         int               `json:"maxConcurrent"`
+	Throughput            int64             `json:"throughput"`
+	FullWriteColdDuration string            `json:"fullWriteColdDuration"`
+}
+
+func newCompactionSettingsResponse(s *platform.CompactionSettings) *compactionSettingsResponse {
+	return &compactionSettingsResponse{
+		Links: map[string]string{
+			"self": compactionsPath,
+		},
+		MaxConcurrent:         s.MaxConcurrent,
+		Throughput:            s.Throughput,
+		FullWriteColdDuration: s.FullWriteColdDuration.String(),
+	}
+}
+
+func (r *compactionSettingsResponse) toPlatform() (*platform.CompactionSettings, error) {
+	d, err := time.ParseDuration(r.FullWriteColdDuration)
+	if err != nil {
+		return nil, err
+	}
+	return &platform.CompactionSettings{
+		MaxConcurrent:         r.MaxConcurrent,
+		Throughput:            r.Throughput,
+		FullWriteColdDuration: d,
+	}, nil
+}
+
+// compactionSettingsUpdate is the JSON of a platform.CompactionSettingsUpdate.
+type compactionSettingsUpdate struct {
+	MaxConcurrent         *int    `json:"maxConcurrent,omitempty"`
+	Throughput            *int64  `json:"throughput,omitempty"`
+	FullWriteColdDuration *string `json:"fullWriteColdDuration,omitempty"`
+}
+
+// handleGetCompactionSettings is the HTTP handler for the GET /api/v2/ops/compactions route.
+func (h *CompactionHandler) handleGetCompactionSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	s, err := h.CompactionService.FindCompactionSettings(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newCompactionSettingsResponse(s)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchCompactionSettings is the HTTP handler for the PATCH /api/v2/ops/compactions route.
+func (h *CompactionHandler) handlePatchCompactionSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	upd, err := decodePatchCompactionSettingsRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	s, err := h.CompactionService.UpdateCompactionSettings(ctx, *upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newCompactionSettingsResponse(s)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePatchCompactionSettingsRequest(r *http.Request) (*platform.CompactionSettingsUpdate, error) {
+	var req compactionSettingsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+
+	upd := &platform.CompactionSettingsUpdate{
+		MaxConcurrent: req.MaxConcurrent,
+		Throughput:    req.Throughput,
+	}
+	if req.FullWriteColdDuration != nil {
+		d, err := time.ParseDuration(*req.FullWriteColdDuration)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid fullWriteColdDuration",
+				Err:  err,
+			}
+		}
+		upd.FullWriteColdDuration = &d
+	}
+
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
+	return upd, nil
+}
+
+// CompactionService connects to Influx via HTTP using tokens to view and change the compaction settings of influxd
+type CompactionService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.CompactionService = (*CompactionService)(nil)
+
+// FindCompactionSettings returns the current compaction settings.
+func (s *CompactionService) FindCompactionSettings(ctx context.Context) (*platform.CompactionSettings, error) {
+	u, err := newURL(s.Addr, compactionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+// UpdateCompactionSettings changes the compaction settings, and returns the settings in effect.
+func (s *CompactionService) UpdateCompactionSettings(ctx context.Context, upd platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error) {
+	u, err := newURL(s.Addr, compactionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	body := compactionSettingsUpdate{
+		MaxConcurrent: upd.MaxConcurrent,
+		Throughput:    upd.Throughput,
+	}
+	if upd.FullWriteColdDuration != nil {
+		d := upd.FullWriteColdDuration.String()
+		body.FullWriteColdDuration = &d
+	}
+	octets, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+func (s *CompactionService) do(req *http.Request) (*platform.CompactionSettings, error) {
+	hc := newClient(req.URL.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r compactionSettingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.toPlatform()
+}
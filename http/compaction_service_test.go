@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestCompactionService(t *testing.T) {
+	settings := &platform.CompactionSettings{
+		MaxConcurrent:         2,
+		Throughput:            48 * 1024 * 1024,
+		FullWriteColdDuration: 4 * time.Hour,
+	}
+
+	svc := mock.NewCompactionService()
+	svc.FindCompactionSettingsFn = func(context.Context) (*platform.CompactionSettings, error) {
+		return settings, nil
+	}
+	svc.UpdateCompactionSettingsFn = func(ctx context.Context, upd platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error) {
+		s := *settings
+		if upd.MaxConcurrent != nil {
+			s.MaxConcurrent = *upd.MaxConcurrent
+		}
+		if upd.Throughput != nil {
+			s.Throughput = *upd.Throughput
+		}
+		if upd.FullWriteColdDuration != nil {
+			s.FullWriteColdDuration = *upd.FullWriteColdDuration
+		}
+		return &s, nil
+	}
+
+	server := httptest.NewServer(NewCompactionHandler(svc))
+	defer server.Close()
+	client := CompactionService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.FindCompactionSettings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(settings, got); diff != "" {
+		t.Errorf("unexpected settings -want/+got:\n%s", diff)
+	}
+
+	throughput, cold := int64(1024*1024), 30*time.Minute
+	got, err = client.UpdateCompactionSettings(ctx, platform.CompactionSettingsUpdate{
+		Throughput:            &throughput,
+		FullWriteColdDuration: &cold,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &platform.CompactionSettings{
+		MaxConcurrent:         2,
+		Throughput:            throughput,
+		FullWriteColdDuration: cold,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected settings -want/+got:\n%s", diff)
+	}
+
+	zero := 0
+	if _, err := client.UpdateCompactionSettings(ctx, platform.CompactionSettingsUpdate{MaxConcurrent: &zero}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected no concurrent compactions to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CORS applies a CORS policy to the responses of the HTTP API. The policy is the one stored
+// by its CORSPolicyService, if any, or the policy configured at startup.
+// CORS is itself a CORSPolicyService, which applies the policies as they are stored or deleted.
+type CORS struct {
+	CORSPolicyService platform.CORSPolicyService
+
+	configured platform.CORSPolicy
+
+	mu     sync.RWMutex
+	policy platform.CORSPolicy
+}
+
+var _ platform.CORSPolicyService = (*CORS)(nil)
+
+// NewCORS returns a CORS applying the configured policy until a policy is stored by s.
+func NewCORS(configured platform.CORSPolicy, s platform.CORSPolicyService) *CORS {
+	return &CORS{
+		CORSPolicyService: s,
+		configured:        configured,
+		policy:            configured,
+	}
+}
+
+// errCORSPolicyNotStored is the error of changing the policy of a CORS without a CORSPolicyService.
+var errCORSPolicyNotStored = &platform.Error{
+	Code: platform.EInvalid,
+	Msg:  "CORS policies are not stored; the configured policy applies",
+}
+
+// Load applies the stored policy, if any.
+func (c *CORS) Load(ctx context.Context) error {
+	p, err := c.FindCORSPolicy(ctx)
+	if platform.ErrorCode(err) == platform.ENotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.set(*p)
+	return nil
+}
+
+// Policy returns the policy applied.
+func (c *CORS) Policy() platform.CORSPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.policy
+}
+
+func (c *CORS) set(p platform.CORSPolicy) {
+	c.mu.Lock()
+	c.policy = p
+	c.mu.Unlock()
+}
+
+// FindCORSPolicy returns the stored policy.
+func (c *CORS) FindCORSPolicy(ctx context.Context) (*platform.CORSPolicy, error) {
+	if c.CORSPolicyService == nil {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  platform.ErrCORSPolicyNotFound,
+		}
+	}
+	return c.CORSPolicyService.FindCORSPolicy(ctx)
+}
+
+// UpdateCORSPolicy stores the policy and applies it.
+func (c *CORS) UpdateCORSPolicy(ctx context.Context, p platform.CORSPolicy) (*platform.CORSPolicy, error) {
+	if c.CORSPolicyService == nil {
+		return nil, errCORSPolicyNotStored
+	}
+	stored, err := c.CORSPolicyService.UpdateCORSPolicy(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.set(*stored)
+	return stored, nil
+}
+
+// DeleteCORSPolicy deletes the stored policy and applies the configured one again.
+func (c *CORS) DeleteCORSPolicy(ctx context.Context) error {
+	if c.CORSPolicyService == nil {
+		return errCORSPolicyNotStored
+	}
+	if err := c.CORSPolicyService.DeleteCORSPolicy(ctx); err != nil {
+		return err
+	}
+	c.set(c.configured)
+	return nil
+}
+
+// setResponseHeaders allows the origin of r to read the response if the policy allows it.
+func (c *CORS) setResponseHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+
+	// The response depends on the origin, which the API handler and the platform handler both check.
+	if !headerHasValue(w.Header(), "Vary", "Origin") {
+		w.Header().Add("Vary", "Origin")
+	}
+	p := c.Policy()
+	if !p.AllowsOrigin(origin) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if len(p.AllowedMethods) > 0 {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+	}
+	if len(p.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.AllowedHeaders, ", "))
+	}
+}
+
+func headerHasValue(h http.Header, key, value string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(key)] {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CORSHandler represents an HTTP API handler for the CORS policy of the HTTP API.
+type CORSHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	CORSPolicyService platform.CORSPolicyService
+}
+
+const (
+	corsPath = "/api/v2/ops/cors"
+)
+
+// NewCORSHandler returns a new instance of CORSHandler.
+func NewCORSHandler(s platform.CORSPolicyService) *CORSHandler {
+	h := &CORSHandler{
+		Router:            NewRouter(),
+		Logger:            zap.NewNop(),
+		CORSPolicyService: s,
+	}
+
+	h.HandlerFunc("GET", corsPath, h.handleGetCORSPolicy)
+	h.HandlerFunc("PUT", corsPath, h.handlePutCORSPolicy)
+	h.HandlerFunc("DELETE", corsPath, h.handleDeleteCORSPolicy)
+
+	return h
+}
+
+// handleGetCORSPolicy is the HTTP handler for the GET /api/v2/ops/cors route.
+// It responds with a 404 if no policy is stored, in which case the policy configured at startup applies.
+func (h *CORSHandler) handleGetCORSPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p, err := h.CORSPolicyService.FindCORSPolicy(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, p); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePutCORSPolicy is the HTTP handler for the PUT /api/v2/ops/cors route.
+func (h *CORSHandler) handlePutCORSPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var p platform.CORSPolicy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid CORS policy",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	stored, err := h.CORSPolicyService.UpdateCORSPolicy(ctx, p)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, stored); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteCORSPolicy is the HTTP handler for the DELETE /api/v2/ops/cors route.
+// The policy configured at startup applies again.
+func (h *CORSHandler) handleDeleteCORSPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.CORSPolicyService.DeleteCORSPolicy(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestCORS(t *testing.T) {
+	var stored *platform.CORSPolicy
+	s := &mock.CORSPolicyService{
+		FindCORSPolicyFn: func(ctx context.Context) (*platform.CORSPolicy, error) {
+			if stored == nil {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrCORSPolicyNotFound}
+			}
+			return stored, nil
+		},
+		UpdateCORSPolicyFn: func(ctx context.Context, p platform.CORSPolicy) (*platform.CORSPolicy, error) {
+			if err := p.Validate(); err != nil {
+				return nil, err
+			}
+			stored = &p
+			return stored, nil
+		},
+		DeleteCORSPolicyFn: func(ctx context.Context) error {
+			stored = nil
+			return nil
+		},
+	}
+	cors := NewCORS(platform.CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET"},
+	}, s)
+	if err := cors.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h := NewCORSHandler(cors)
+
+	allowedOrigin := func(origin string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("OPTIONS", "http://any.url/api/v2/buckets", nil)
+		r.Header.Set("Origin", origin)
+		cors.setResponseHeaders(w, r)
+		if vary := w.Header().Get("Vary"); vary != "Origin" {
+			t.Errorf("got Vary %q, want Origin", vary)
+		}
+		return w.Header().Get("Access-Control-Allow-Origin")
+	}
+	serve := func(method, body string) *http.Response {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "http://any.url/api/v2/ops/cors", strings.NewReader(body)))
+		return w.Result()
+	}
+
+	if got := allowedOrigin("https://app.example.com"); got != "https://app.example.com" {
+		t.Errorf("expected the configured origin to be allowed, got %q", got)
+	}
+	if got := allowedOrigin("https://evil.example.com"); got != "" {
+		t.Errorf("expected another origin not to be allowed, got %q", got)
+	}
+	if res := serve("GET", ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d finding the policy before it is stored, want 404", res.StatusCode)
+	}
+
+	// A stored policy applies at once.
+	if res := serve("PUT", `{"allowedOrigins": ["https://other.example.com"], "allowedMethods": ["GET", "POST"]}`); res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d storing a policy", res.StatusCode)
+	}
+	if got := allowedOrigin("https://other.example.com"); got != "https://other.example.com" {
+		t.Errorf("expected the stored origin to be allowed, got %q", got)
+	}
+	if got := allowedOrigin("https://app.example.com"); got != "" {
+		t.Errorf("expected the configured origin not to be allowed, got %q", got)
+	}
+	if res := serve("PUT", `{"allowedOrigins": ["app.example.com/path"]}`); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("got status %d storing an invalid policy, want 400", res.StatusCode)
+	}
+
+	// Deleting the stored policy applies the configured one again.
+	if res := serve("DELETE", ""); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("got status %d deleting the policy", res.StatusCode)
+	}
+	if got := allowedOrigin("https://app.example.com"); got != "https://app.example.com" {
+		t.Errorf("expected the configured origin to be allowed again, got %q", got)
+	}
+}
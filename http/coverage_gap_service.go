@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+type coverageGap struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+type coverageGapsResponse struct {
+	Links map[string]string `json:"links"`
+	Gaps  []*coverageGap    `json:"gaps"`
+}
+
+func newCoverageGapsResponse(id platform.ID, gs []*platform.CoverageGap) *coverageGapsResponse {
+	gaps := make([]*coverageGap, 0, len(gs))
+	for _, g := range gs {
+		gaps = append(gaps, &coverageGap{Start: g.Start, Stop: g.Stop})
+	}
+	return &coverageGapsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/buckets/%s/gaps", id),
+		},
+		Gaps: gaps,
+	}
+}
+
+// handleGetBucketGaps is the HTTP handler for the GET /api/v2/buckets/:id/gaps route.
+func (h *BucketHandler) handleGetBucketGaps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketGapsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	req.OrgID = b.OrganizationID
+
+	gaps, err := h.CoverageGapService.FindCoverageGaps(ctx, *req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newCoverageGapsResponse(req.BucketID, gaps)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetBucketGapsRequest(ctx context.Context, r *http.Request) (*platform.CoverageGapRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	req := &platform.CoverageGapRequest{}
+	if err := req.BucketID.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	qp := r.URL.Query()
+	req.Measurement = qp.Get("measurement")
+
+	if interval := qp.Get("intervalSeconds"); interval != "" {
+		n, err := strconv.ParseInt(interval, 10, 64)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "intervalSeconds must be an integer",
+				Err:  err,
+			}
+		}
+		req.Interval = time.Duration(n) * time.Second
+	}
+
+	for _, t := range []struct {
+		name string
+		v    *time.Time
+	}{
+		{name: "start", v: &req.Start},
+		{name: "stop", v: &req.Stop},
+	} {
+		v := qp.Get(t.name)
+		if v == "" {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("%s is required", t.name),
+			}
+		}
+		tm, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("%s must be an RFC3339 time", t.name),
+				Err:  err,
+			}
+		}
+		*t.v = tm
+	}
+
+	return req, nil
+}
+
+// CoverageGapService connects to Influx via HTTP using tokens to find the gaps in the data of buckets.
+type CoverageGapService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.CoverageGapService = (*CoverageGapService)(nil)
+
+// FindCoverageGaps returns the gaps in the coverage of the measurement of a bucket over the window of req.
+// The organization of the request is the one of the bucket.
+func (s *CoverageGapService) FindCoverageGaps(ctx context.Context, req platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+	u, err := newURL(s.Addr, bucketIDPath(req.BucketID)+"/gaps")
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("measurement", req.Measurement)
+	query.Add("intervalSeconds", strconv.FormatInt(int64(req.Interval/time.Second), 10))
+	query.Add("start", req.Start.Format(time.RFC3339))
+	query.Add("stop", req.Stop.Format(time.RFC3339))
+
+	hreq, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	hreq.URL.RawQuery = query.Encode()
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r coverageGapsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	gaps := make([]*platform.CoverageGap, 0, len(r.Gaps))
+	for _, g := range r.Gaps {
+		gaps = append(gaps, &platform.CoverageGap{Start: g.Start, Stop: g.Stop})
+	}
+	return gaps, nil
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestService_handleGetBucketGaps(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantReq    platform.CoverageGapRequest
+		wantBody   string
+	}{
+		{
+			name:       "gaps of a measurement",
+			query:      "?measurement=cpu&intervalSeconds=60&start=2019-03-01T00:00:00Z&stop=2019-03-01T01:00:00Z",
+			wantStatus: http.StatusOK,
+			wantReq: platform.CoverageGapRequest{
+				OrgID:       orgID,
+				BucketID:    bucketID,
+				Measurement: "cpu",
+				Interval:    time.Minute,
+				Start:       start,
+				Stop:        start.Add(time.Hour),
+			},
+			wantBody: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/gaps"
+  },
+  "gaps": [
+    {"start": "2019-03-01T00:10:00Z", "stop": "2019-03-01T00:20:00Z"}
+  ]
+}
+`,
+		},
+		{
+			name:       "missing stop",
+			query:      "?measurement=cpu&intervalSeconds=60&start=2019-03-01T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid interval",
+			query:      "?measurement=cpu&intervalSeconds=1m&start=2019-03-01T00:00:00Z&stop=2019-03-01T01:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.BucketService = &mock.BucketService{
+				FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+					return &platform.Bucket{ID: id, OrganizationID: orgID, Name: "hello"}, nil
+				},
+			}
+			bucketBackend.CoverageGapService = &mock.CoverageGapService{
+				FindCoverageGapsFn: func(ctx context.Context, req platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+					if diff := cmp.Diff(tt.wantReq, req); diff != "" {
+						t.Errorf("unexpected request -want/+got:\n%s", diff)
+					}
+					return []*platform.CoverageGap{
+						{Start: start.Add(10 * time.Minute), Stop: start.Add(20 * time.Minute)},
+					}, nil
+				},
+			}
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/gaps"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wantBody); tt.wantBody != "" && !eq {
+				t.Errorf("handleGetBucketGaps() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestCoverageGapService(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	want := []*platform.CoverageGap{
+		{Start: start.Add(10 * time.Minute), Stop: start.Add(20 * time.Minute)},
+	}
+
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.BucketService = &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			return &platform.Bucket{ID: id, OrganizationID: orgID, Name: "hello"}, nil
+		},
+	}
+	bucketBackend.CoverageGapService = &mock.CoverageGapService{
+		FindCoverageGapsFn: func(ctx context.Context, req platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+			return want, nil
+		},
+	}
+	server := httptest.NewServer(NewBucketHandler(bucketBackend))
+	defer server.Close()
+
+	s := &CoverageGapService{Addr: server.URL}
+	gaps, err := s.FindCoverageGaps(context.Background(), platform.CoverageGapRequest{
+		BucketID:    platformtesting.MustIDBase16("020f755c3c082000"),
+		Measurement: "cpu",
+		Interval:    time.Minute,
+		Start:       start,
+		Stop:        start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, gaps); diff != "" {
+		t.Errorf("unexpected gaps -want/+got:\n%s", diff)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
@@ -24,6 +26,9 @@ type DashboardBackend struct {
 	UserResourceMappingService   platform.UserResourceMappingService
 	LabelService                 platform.LabelService
 	UserService                  platform.UserService
+	AuthorizationService         platform.AuthorizationService
+	BucketService                platform.BucketService
+	ActivityService              platform.ActivityService
 }
 
 // NewDashboardBackend creates a backend used by the dashboard handler.
@@ -36,6 +41,9 @@ func NewDashboardBackend(b *APIBackend) *DashboardBackend {
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		AuthorizationService:         b.AuthorizationService,
+		BucketService:                b.BucketService,
+		ActivityService:              b.ActivityService,
 	}
 }
 
@@ -50,12 +58,16 @@ type DashboardHandler struct {
 	UserResourceMappingService   platform.UserResourceMappingService
 	LabelService                 platform.LabelService
 	UserService                  platform.UserService
+	AuthorizationService         platform.AuthorizationService
+	BucketService                platform.BucketService
+	ActivityService              platform.ActivityService
 }
 
 const (
 	dashboardsPath              = "/api/v2/dashboards"
 	dashboardsIDPath            = "/api/v2/dashboards/:id"
 	dashboardsIDCellsPath       = "/api/v2/dashboards/:id/cells"
+	dashboardsIDClonePath       = "/api/v2/dashboards/:id/clone"
 	dashboardsIDCellsIDPath     = "/api/v2/dashboards/:id/cells/:cellID"
 	dashboardsIDCellsIDViewPath = "/api/v2/dashboards/:id/cells/:cellID/view"
 	dashboardsIDMembersPath     = "/api/v2/dashboards/:id/members"
@@ -65,6 +77,7 @@ const (
 	dashboardsIDOwnersIDPath    = "/api/v2/dashboards/:id/owners/:userID"
 	dashboardsIDLabelsPath      = "/api/v2/dashboards/:id/labels"
 	dashboardsIDLabelsIDPath    = "/api/v2/dashboards/:id/labels/:lid"
+	dashboardsIDTokensPath      = "/api/v2/dashboards/:id/tokens"
 )
 
 // NewDashboardHandler returns a new instance of DashboardHandler.
@@ -78,6 +91,9 @@ func NewDashboardHandler(b *DashboardBackend) *DashboardHandler {
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		AuthorizationService:         b.AuthorizationService,
+		BucketService:                b.BucketService,
+		ActivityService:              b.ActivityService,
 	}
 
 	h.HandlerFunc("POST", dashboardsPath, h.handlePostDashboard)
@@ -86,6 +102,8 @@ func NewDashboardHandler(b *DashboardBackend) *DashboardHandler {
 	h.HandlerFunc("GET", dashboardsIDLogPath, h.handleGetDashboardLog)
 	h.HandlerFunc("DELETE", dashboardsIDPath, h.handleDeleteDashboard)
 	h.HandlerFunc("PATCH", dashboardsIDPath, h.handlePatchDashboard)
+	h.HandlerFunc("POST", dashboardsIDClonePath, h.handlePostDashboardClone)
+	h.HandlerFunc("POST", dashboardsIDTokensPath, h.handlePostDashboardToken)
 
 	h.HandlerFunc("PUT", dashboardsIDCellsPath, h.handlePutDashboardCells)
 	h.HandlerFunc("POST", dashboardsIDCellsPath, h.handlePostDashboardCell)
@@ -335,6 +353,7 @@ func (h *DashboardHandler) handleGetDashboards(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	setOffsetPagingLinkHeader(w, r, req.opts, len(dashboards))
 	if err := encodeResponse(ctx, w, http.StatusOK, newGetDashboardsResponse(ctx, dashboards, req.filter, req.opts, h.LabelService)); err != nil {
 		logEncodingError(h.Logger, r, err)
 		return
@@ -473,6 +492,13 @@ func (h *DashboardHandler) handleGetDashboard(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Views are recorded so that dashboards nobody looks at can be found by the inactivity policy of their organization.
+	if h.ActivityService != nil {
+		if err := h.ActivityService.RecordActivity(ctx, platform.DashboardsResourceType, dashboard.ID, time.Now()); err != nil {
+			h.Logger.Info("Failed to record dashboard view", zap.Stringer("dashboard_id", dashboard.ID), zap.Error(err))
+		}
+	}
+
 	if err := encodeResponse(ctx, w, http.StatusOK, newDashboardResponse(dashboard, labels)); err != nil {
 		logEncodingError(h.Logger, r, err)
 		return
@@ -503,6 +529,106 @@ func decodeGetDashboardRequest(ctx context.Context, r *http.Request) (*getDashbo
 	}, nil
 }
 
+// handlePostDashboardClone copies a dashboard, its cells and their views into another organization.
+func (h *DashboardHandler) handlePostDashboardClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePostDashboardCloneRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	d, err := platform.CloneDashboard(ctx, h.DashboardService, req.DashboardID, req.Clone)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newDashboardResponse(d, []*platform.Label{})); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type postDashboardCloneRequest struct {
+	DashboardID platform.ID
+	Clone       platform.CloneRequest
+}
+
+func decodePostDashboardCloneRequest(ctx context.Context, r *http.Request) (*postDashboardCloneRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	req := &postDashboardCloneRequest{}
+	if err := req.DashboardID.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req.Clone); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode clone request",
+			Err:  err,
+		}
+	}
+
+	return req, req.Clone.Validate()
+}
+
+// handlePostDashboardToken is the HTTP handler for the POST /api/v2/dashboards/:id/tokens route.
+// It creates a kiosk token, which can only read the dashboard and the buckets its cells query,
+// for displays rendering the dashboard without a user session.
+func (h *DashboardHandler) handlePostDashboardToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetDashboardRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	d, err := h.DashboardService.FindDashboardByID(ctx, req.DashboardID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ps, err := platform.KioskPermissions(ctx, h.DashboardService, h.BucketService, d.ID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	a := &platform.Authorization{
+		OrgID:       d.OrganizationID,
+		UserID:      auth.GetUserID(),
+		Description: fmt.Sprintf("kiosk token for dashboard %s", d.Name),
+		Permissions: ps,
+	}
+	if err := h.AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, a); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
 // hanldeGetDashboardLog retrieves a dashboard log by the dashboards ID.
 func (h *DashboardHandler) handleGetDashboardLog(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -1145,6 +1271,84 @@ func (s *DashboardService) CreateDashboard(ctx context.Context, d *platform.Dash
 	return nil
 }
 
+// CloneDashboard copies the dashboard with id into the organization of the clone request.
+func (s *DashboardService) CloneDashboard(ctx context.Context, id platform.ID, clone platform.CloneRequest) (*platform.Dashboard, error) {
+	if err := clone.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := newURL(s.Addr, path.Join(dashboardIDPath(id), "clone"))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var dr dashboardResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dr); err != nil {
+		return nil, err
+	}
+
+	return dr.toPlatform(), nil
+}
+
+// CreateDashboardToken creates a kiosk token, which can only read the dashboard with id and the buckets its cells query.
+func (s *DashboardService) CreateDashboardToken(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+	u, err := newURL(s.Addr, path.Join(dashboardIDPath(id), "tokens"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var a platform.Authorization
+	if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
 // UpdateDashboard updates a single dashboard with changeset.
 // Returns the new dashboard state after update.
 func (s *DashboardService) UpdateDashboard(ctx context.Context, id platform.ID, upd platform.DashboardUpdate) (*platform.Dashboard, error) {
@@ -15,7 +15,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
 	"github.com/influxdata/influxdb/mock"
 	platformtesting "github.com/influxdata/influxdb/testing"
 	"github.com/julienschmidt/httprouter"
@@ -33,6 +35,9 @@ func NewMockDashboardBackend() *DashboardBackend {
 		UserResourceMappingService:   mock.NewUserResourceMappingService(),
 		LabelService:                 mock.NewLabelService(),
 		UserService:                  mock.NewUserService(),
+		AuthorizationService:         mock.NewAuthorizationService(),
+		BucketService:                mock.NewBucketService(),
+		ActivityService:              mock.NewActivityService(),
 	}
 }
 
@@ -1420,3 +1425,120 @@ func jsonEqual(s1, s2 string) (eq bool, diff string, err error) {
 
 	return cmp.Equal(o1, o2), diff, err
 }
+
+func TestDashboardService_CloneDashboard(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	src := &platform.Dashboard{OrganizationID: 1, Name: "ops"}
+	if err := svc.CreateDashboard(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	view := &platform.View{
+		Properties: platform.SingleStatViewProperties{
+			Type:    "single-stat",
+			Queries: []platform.DashboardQuery{{Text: `from(bucket: "staging") |> range(start: -1h)`}},
+		},
+	}
+	if err := svc.AddDashboardCell(ctx, src.ID, &platform.Cell{W: 4, H: 4}, platform.AddDashboardCellOptions{View: view}); err != nil {
+		t.Fatal(err)
+	}
+
+	dashboardBackend := NewMockDashboardBackend()
+	dashboardBackend.DashboardService = svc
+	server := httptest.NewServer(NewDashboardHandler(dashboardBackend))
+	defer server.Close()
+	client := DashboardService{Addr: server.URL}
+
+	clone, err := client.CloneDashboard(ctx, src.ID, platform.CloneRequest{
+		OrganizationID: 2,
+		BucketMapping:  map[string]string{"staging": "production"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.ID == src.ID || clone.OrganizationID != 2 || clone.Name != "ops" || len(clone.Cells) != 1 {
+		t.Fatalf("unexpected clone: %+v", clone)
+	}
+
+	v, err := svc.GetDashboardCellView(ctx, clone.ID, clone.Cells[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Properties.(platform.SingleStatViewProperties).Queries[0].Text; got != `from(bucket: "production") |> range(start: -1h)` {
+		t.Errorf("unexpected cloned query: %s", got)
+	}
+
+	if _, err := client.CloneDashboard(ctx, src.ID, platform.CloneRequest{}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected clone without orgID to be invalid, got %v", err)
+	}
+}
+
+func TestDashboardService_CreateDashboardToken(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &platform.Organization{Name: "o"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &platform.User{Name: "kiosk-admin"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	b := &platform.Bucket{OrganizationID: org.ID, Name: "telegraf"}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	d := &platform.Dashboard{OrganizationID: org.ID, Name: "wall"}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	view := &platform.View{
+		Properties: platform.SingleStatViewProperties{
+			Type:    "single-stat",
+			Queries: []platform.DashboardQuery{{Text: `from(bucket: "telegraf") |> range(start: -1h)`}},
+		},
+	}
+	if err := svc.AddDashboardCell(ctx, d.ID, &platform.Cell{W: 4, H: 4}, platform.AddDashboardCellOptions{View: view}); err != nil {
+		t.Fatal(err)
+	}
+
+	dashboardBackend := NewMockDashboardBackend()
+	dashboardBackend.DashboardService = svc
+	dashboardBackend.BucketService = svc
+	dashboardBackend.AuthorizationService = svc
+	h := NewDashboardHandler(dashboardBackend)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{UserID: user.ID}))
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := DashboardService{Addr: server.URL}
+
+	a, err := client.CreateDashboardToken(ctx, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Token == "" || a.OrgID != org.ID || a.UserID != user.ID {
+		t.Fatalf("unexpected authorization: %+v", a)
+	}
+
+	want := []platform.Permission{
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.DashboardsResourceType, ID: &d.ID, OrgID: &org.ID}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, ID: &b.ID, OrgID: &org.ID}},
+	}
+	if diff := cmp.Diff(a.Permissions, want); diff != "" {
+		t.Errorf("permissions are different -got/+want\ndiff %s", diff)
+	}
+
+	if _, err := client.CreateDashboardToken(ctx, platform.ID(1)); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected token for missing dashboard to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// DBRPMappingHandler represents an HTTP API handler for the dbrp mappings,
+// which map the databases and retention policies of InfluxQL queries to buckets.
+type DBRPMappingHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	DBRPMappingService platform.DBRPMappingService
+}
+
+const (
+	dbrpMappingsPath  = "/api/v2/dbrps"
+	dbrpMappingIDPath = "/api/v2/dbrps/:cluster/:db/:rp"
+)
+
+// NewDBRPMappingHandler returns a new instance of DBRPMappingHandler.
+func NewDBRPMappingHandler(s platform.DBRPMappingService) *DBRPMappingHandler {
+	h := &DBRPMappingHandler{
+		Router:             NewRouter(),
+		Logger:             zap.NewNop(),
+		DBRPMappingService: s,
+	}
+
+	h.HandlerFunc("GET", dbrpMappingsPath, h.handleGetDBRPMappings)
+	h.HandlerFunc("POST", dbrpMappingsPath, h.handlePostDBRPMapping)
+	h.HandlerFunc("GET", dbrpMappingIDPath, h.handleGetDBRPMapping)
+	h.HandlerFunc("DELETE", dbrpMappingIDPath, h.handleDeleteDBRPMapping)
+
+	return h
+}
+
+type dbrpMappingResponse struct {
+	platform.DBRPMapping
+	Links map[string]string `json:"links"`
+}
+
+func newDBRPMappingResponse(m *platform.DBRPMapping) *dbrpMappingResponse {
+	return &dbrpMappingResponse{
+		DBRPMapping: *m,
+		Links: map[string]string{
+			"self":   dbrpMappingPath(m.Cluster, m.Database, m.RetentionPolicy),
+			"org":    fmt.Sprintf("/api/v2/orgs/%s", m.OrganizationID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", m.BucketID),
+		},
+	}
+}
+
+type dbrpMappingsResponse struct {
+	Links        map[string]string      `json:"links"`
+	DBRPMappings []*dbrpMappingResponse `json:"dbrps"`
+}
+
+func newDBRPMappingsResponse(ms []*platform.DBRPMapping) *dbrpMappingsResponse {
+	res := &dbrpMappingsResponse{
+		Links: map[string]string{
+			"self": dbrpMappingsPath,
+		},
+		DBRPMappings: make([]*dbrpMappingResponse, 0, len(ms)),
+	}
+	for _, m := range ms {
+		res.DBRPMappings = append(res.DBRPMappings, newDBRPMappingResponse(m))
+	}
+	return res
+}
+
+// handleGetDBRPMappings is the HTTP handler for the GET /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handleGetDBRPMappings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetDBRPMappingsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ms, _, err := h.DBRPMappingService.FindMany(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDBRPMappingsResponse(ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetDBRPMappingsRequest(ctx context.Context, r *http.Request) (platform.DBRPMappingFilter, error) {
+	qp := r.URL.Query()
+	var filter platform.DBRPMappingFilter
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(orgID); err != nil {
+			return filter, err
+		}
+		filter.OrganizationID = &i
+	}
+
+	if cluster := qp.Get("cluster"); cluster != "" {
+		filter.Cluster = &cluster
+	}
+	if db := qp.Get("db"); db != "" {
+		filter.Database = &db
+	}
+	if rp := qp.Get("rp"); rp != "" {
+		filter.RetentionPolicy = &rp
+	}
+
+	if def := qp.Get("default"); def != "" {
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "default must be true or false",
+				Err:  err,
+			}
+		}
+		filter.Default = &b
+	}
+
+	return filter, nil
+}
+
+// handlePostDBRPMapping is the HTTP handler for the POST /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handlePostDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	m := &platform.DBRPMapping{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode dbrp mapping request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := m.Validate(); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	if err := h.DBRPMappingService.Create(ctx, m); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newDBRPMappingResponse(m)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetDBRPMapping is the HTTP handler for the GET /api/v2/dbrps/:cluster/:db/:rp route.
+func (h *DBRPMappingHandler) handleGetDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cluster, db, rp := decodeDBRPMappingPath(ctx)
+	m, err := h.DBRPMappingService.FindBy(ctx, cluster, db, rp)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDBRPMappingResponse(m)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteDBRPMapping is the HTTP handler for the DELETE /api/v2/dbrps/:cluster/:db/:rp route.
+func (h *DBRPMappingHandler) handleDeleteDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cluster, db, rp := decodeDBRPMappingPath(ctx)
+	if err := h.DBRPMappingService.Delete(ctx, cluster, db, rp); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeDBRPMappingPath(ctx context.Context) (cluster, db, rp string) {
+	params := httprouter.ParamsFromContext(ctx)
+	return params.ByName("cluster"), params.ByName("db"), params.ByName("rp")
+}
+
+// DBRPMappingService connects to Influx via HTTP using tokens to manage dbrp mappings.
+type DBRPMappingService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.DBRPMappingService = (*DBRPMappingService)(nil)
+
+// FindBy returns the dbrp mapping for cluster, db and rp.
+func (s *DBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*platform.DBRPMapping, error) {
+	u, err := newURL(s.Addr, dbrpMappingPath(cluster, db, rp))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var mr dbrpMappingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
+		return nil, err
+	}
+
+	return &mr.DBRPMapping, nil
+}
+
+// Find returns the first dbrp mapping that matches filter.
+func (s *DBRPMappingService) Find(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error) {
+	ms, n, err := s.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "dbrp mapping not found",
+		}
+	}
+
+	return ms[0], nil
+}
+
+// FindMany returns the dbrp mappings that match filter and their count.
+func (s *DBRPMappingService) FindMany(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error) {
+	u, err := newURL(s.Addr, dbrpMappingsPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := u.Query()
+	if filter.OrganizationID != nil {
+		query.Add("orgID", filter.OrganizationID.String())
+	}
+	if filter.Cluster != nil {
+		query.Add("cluster", *filter.Cluster)
+	}
+	if filter.Database != nil {
+		query.Add("db", *filter.Database)
+	}
+	if filter.RetentionPolicy != nil {
+		query.Add("rp", *filter.RetentionPolicy)
+	}
+	if filter.Default != nil {
+		query.Add("default", strconv.FormatBool(*filter.Default))
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, 0, err
+	}
+
+	var r dbrpMappingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, 0, err
+	}
+
+	ms := make([]*platform.DBRPMapping, 0, len(r.DBRPMappings))
+	for _, mr := range r.DBRPMappings {
+		m := mr.DBRPMapping
+		ms = append(ms, &m)
+	}
+	return ms, len(ms), nil
+}
+
+// Create creates a new dbrp mapping.
+func (s *DBRPMappingService) Create(ctx context.Context, m *platform.DBRPMapping) error {
+	if err := m.Validate(); err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	u, err := newURL(s.Addr, dbrpMappingsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// Delete removes the dbrp mapping for cluster, db and rp.
+func (s *DBRPMappingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	u, err := newURL(s.Addr, dbrpMappingPath(cluster, db, rp))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// dbrpMappingPath is the path of the mapping of cluster, db and rp, which cannot hold slashes.
+func dbrpMappingPath(cluster, db, rp string) string {
+	return path.Join(dbrpMappingsPath, cluster, db, rp)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initDBRPMappingService(f platformtesting.DBRPMappingFields, t *testing.T) (platform.DBRPMappingService, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize dbrp mapping service: %v", err)
+	}
+	if err := f.Populate(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewDBRPMappingHandler(svc)
+	server := httptest.NewServer(handler)
+	client := DBRPMappingService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, done
+}
+
+func TestDBRPMappingService(t *testing.T) {
+	t.Run("CreateDBRPMapping", func(t *testing.T) { platformtesting.CreateDBRPMapping(initDBRPMappingService, t) })
+	t.Run("FindDBRPMappingByKey", func(t *testing.T) { platformtesting.FindDBRPMappingByKey(initDBRPMappingService, t) })
+	t.Run("FindDBRPMappings", func(t *testing.T) { platformtesting.FindDBRPMappings(initDBRPMappingService, t) })
+	t.Run("DeleteDBRPMapping", func(t *testing.T) { platformtesting.DeleteDBRPMapping(initDBRPMappingService, t) })
+	t.Run("FindDBRPMapping", func(t *testing.T) { platformtesting.FindDBRPMapping(initDBRPMappingService, t) })
+}
@@ -0,0 +1,407 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// DeleteBackend is all services and associated parameters required to construct
+// the DeleteHandler.
+type DeleteBackend struct {
+	Logger *zap.Logger
+
+	DeleteJobService platform.DeleteJobService
+	BucketService    platform.BucketService
+}
+
+// NewDeleteBackend returns a new instance of DeleteBackend.
+func NewDeleteBackend(b *APIBackend) *DeleteBackend {
+	return &DeleteBackend{
+		Logger: b.Logger.With(zap.String("handler", "delete")),
+
+		DeleteJobService: b.DeleteJobService,
+		BucketService:    b.BucketService,
+	}
+}
+
+// DeleteHandler deletes the points of buckets, as delete jobs running in the background.
+type DeleteHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	DeleteJobService platform.DeleteJobService
+	BucketService    platform.BucketService
+}
+
+const (
+	deletePath         = "/api/v2/delete"
+	deleteJobsPath     = "/api/v2/delete/jobs"
+	deleteJobsIDPath   = "/api/v2/delete/jobs/:id"
+	deleteJobPollDelay = 500 * time.Millisecond
+)
+
+// NewDeleteHandler creates a new handler at /api/v2/delete to delete points.
+func NewDeleteHandler(b *DeleteBackend) *DeleteHandler {
+	h := &DeleteHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		DeleteJobService: b.DeleteJobService,
+		BucketService:    b.BucketService,
+	}
+
+	h.HandlerFunc("POST", deletePath, h.handleDelete)
+	h.HandlerFunc("GET", deleteJobsPath, h.handleGetDeleteJobs)
+	h.HandlerFunc("GET", deleteJobsIDPath, h.handleGetDeleteJob)
+	return h
+}
+
+type deleteJobResponse struct {
+	Links map[string]string `json:"links"`
+	platform.DeleteJob
+}
+
+func newDeleteJobResponse(j *platform.DeleteJob) *deleteJobResponse {
+	return &deleteJobResponse{
+		Links: map[string]string{
+			"self": path.Join(deleteJobsPath, j.ID.String()),
+		},
+		DeleteJob: *j,
+	}
+}
+
+type deleteJobsResponse struct {
+	Links map[string]string    `json:"links"`
+	Jobs  []*deleteJobResponse `json:"jobs"`
+}
+
+func newDeleteJobsResponse(js []*platform.DeleteJob) *deleteJobsResponse {
+	res := &deleteJobsResponse{
+		Links: map[string]string{
+			"self": deleteJobsPath,
+		},
+		Jobs: make([]*deleteJobResponse, 0, len(js)),
+	}
+	for _, j := range js {
+		res.Jobs = append(res.Jobs, newDeleteJobResponse(j))
+	}
+	return res
+}
+
+type deleteRequest struct {
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	Predicate string    `json:"predicate"`
+}
+
+// handleDelete is the HTTP handler for the POST /api/v2/delete route.
+func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := h.decodeDeleteRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	job, err := h.DeleteJobService.CreateDeleteJob(ctx, *req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newDeleteJobResponse(job)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetDeleteJobs is the HTTP handler for the GET /api/v2/delete/jobs route.
+func (h *DeleteHandler) handleGetDeleteJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter platform.DeleteJobFilter
+	qp := r.URL.Query()
+	if id := qp.Get(OrgID); id != "" {
+		orgID, err := platform.IDFromString(id)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		filter.OrgID = orgID
+	}
+	if id := qp.Get("bucketID"); id != "" {
+		bucketID, err := platform.IDFromString(id)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		filter.BucketID = bucketID
+	}
+
+	jobs, err := h.DeleteJobService.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDeleteJobsResponse(jobs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetDeleteJob is the HTTP handler for the GET /api/v2/delete/jobs/:id route.
+func (h *DeleteHandler) handleGetDeleteJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params := httprouter.ParamsFromContext(ctx)
+	var id platform.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	job, err := h.DeleteJobService.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDeleteJobResponse(job)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodeDeleteRequest decodes the request, in which the bucket is either the bucketID parameter,
+// or the bucket parameter naming a bucket of the organization of the orgID or org parameter.
+func (h *DeleteHandler) decodeDeleteRequest(ctx context.Context, r *http.Request) (*platform.DeleteRequest, error) {
+	var dr deleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&dr); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+
+	b, err := h.findBucket(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &platform.DeleteRequest{
+		OrgID:     b.OrganizationID,
+		BucketID:  b.ID,
+		Start:     dr.Start,
+		Stop:      dr.Stop,
+		Predicate: dr.Predicate,
+	}, nil
+}
+
+func (h *DeleteHandler) findBucket(ctx context.Context, r *http.Request) (*platform.Bucket, error) {
+	qp := r.URL.Query()
+	if id := qp.Get("bucketID"); id != "" {
+		bucketID, err := platform.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		return h.BucketService.FindBucketByID(ctx, *bucketID)
+	}
+
+	name := qp.Get("bucket")
+	if name == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "bucket or bucketID is required",
+		}
+	}
+	filter := platform.BucketFilter{Name: &name}
+	if id := qp.Get(OrgID); id != "" {
+		orgID, err := platform.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		filter.OrganizationID = orgID
+	} else if org := qp.Get(OrgName); org != "" {
+		filter.Organization = &org
+	} else {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "org or orgID is required with bucket",
+		}
+	}
+	return h.BucketService.FindBucket(ctx, filter)
+}
+
+// DeleteService connects to Influx via HTTP using tokens to delete the points of buckets.
+type DeleteService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.DeleteService = (*DeleteService)(nil)
+var _ platform.DeleteJobService = (*DeleteService)(nil)
+
+// DeleteBucketRangePredicate deletes the points of the series of a bucket matching the predicate of req.
+// It creates a delete job and waits for it to finish.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, req platform.DeleteRequest) error {
+	job, err := s.CreateDeleteJob(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for !job.Finished() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(deleteJobPollDelay):
+		}
+
+		if job, err = s.FindDeleteJobByID(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+
+	if job.Status == platform.DeleteJobFailed {
+		return &platform.Error{
+			Code: platform.EInternal,
+			Msg:  fmt.Sprintf("delete job %s failed: %s", job.ID, job.Error),
+		}
+	}
+	return nil
+}
+
+// CreateDeleteJob queues a job deleting the points of the series of a bucket matching the predicate of req.
+// The organization of the request is the one of the bucket.
+func (s *DeleteService) CreateDeleteJob(ctx context.Context, req platform.DeleteRequest) (*platform.DeleteJob, error) {
+	u, err := newURL(s.Addr, deletePath)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(deleteRequest{
+		Start:     req.Start,
+		Stop:      req.Stop,
+		Predicate: req.Predicate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("bucketID", req.BucketID.String())
+
+	hreq, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	hreq.URL.RawQuery = query.Encode()
+	hreq.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var jr deleteJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+		return nil, err
+	}
+	return &jr.DeleteJob, nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (s *DeleteService) FindDeleteJobByID(ctx context.Context, id platform.ID) (*platform.DeleteJob, error) {
+	u, err := newURL(s.Addr, path.Join(deleteJobsPath, id.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	hreq, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var jr deleteJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+		return nil, err
+	}
+	return &jr.DeleteJob, nil
+}
+
+// FindDeleteJobs returns the delete jobs matching filter, most recent first.
+func (s *DeleteService) FindDeleteJobs(ctx context.Context, filter platform.DeleteJobFilter) ([]*platform.DeleteJob, error) {
+	u, err := newURL(s.Addr, deleteJobsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.OrgID != nil {
+		query.Add(OrgID, filter.OrgID.String())
+	}
+	if filter.BucketID != nil {
+		query.Add("bucketID", filter.BucketID.String())
+	}
+
+	hreq, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	hreq.URL.RawQuery = query.Encode()
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var jr deleteJobsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+		return nil, err
+	}
+	jobs := make([]*platform.DeleteJob, 0, len(jr.Jobs))
+	for _, j := range jr.Jobs {
+		jobs = append(jobs, &j.DeleteJob)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+func newMockDeleteBackend(orgID platform.ID) *DeleteBackend {
+	return &DeleteBackend{
+		Logger:           zap.NewNop().With(zap.String("handler", "delete")),
+		DeleteJobService: mock.NewDeleteJobService(),
+		BucketService: &mock.BucketService{
+			FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+				return &platform.Bucket{ID: id, OrganizationID: orgID, Name: "hello"}, nil
+			},
+			FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				if *filter.Name != "hello" {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+				}
+				return &platform.Bucket{ID: platformtesting.MustIDBase16("020f755c3c082000"), OrganizationID: orgID, Name: "hello"}, nil
+			},
+		},
+	}
+}
+
+func TestDeleteHandler_handleDelete(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	body := `{"start": "2019-03-01T00:00:00Z", "stop": "2019-03-01T01:00:00Z", "predicate": "host=\"a\" AND region=\"us\""}`
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "delete by bucket ID",
+			query:      "?bucketID=020f755c3c082000",
+			body:       body,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "delete by bucket name",
+			query:      "?org=myorg&bucket=hello",
+			body:       body,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "bucket name without org",
+			query:      "?bucket=hello",
+			body:       body,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing bucket",
+			query:      "?org=myorg&bucket=other",
+			body:       body,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid body",
+			query:      "?bucketID=020f755c3c082000",
+			body:       `{"start": 1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleteBackend := newMockDeleteBackend(orgID)
+			deleteBackend.DeleteJobService = &mock.DeleteJobService{
+				CreateDeleteJobFn: func(ctx context.Context, req platform.DeleteRequest) (*platform.DeleteJob, error) {
+					want := platform.DeleteRequest{
+						OrgID:     orgID,
+						BucketID:  bucketID,
+						Start:     start,
+						Stop:      start.Add(time.Hour),
+						Predicate: `host="a" AND region="us"`,
+					}
+					if diff := cmp.Diff(want, req); diff != "" {
+						t.Errorf("unexpected request -want/+got:\n%s", diff)
+					}
+					return &platform.DeleteJob{ID: 1, Request: req, Status: platform.DeleteJobQueued}, nil
+				},
+			}
+			h := NewDeleteHandler(deleteBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/delete"+tt.query, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wantStatus {
+				b, _ := ioutil.ReadAll(res.Body)
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, b)
+			}
+		})
+	}
+}
+
+func TestDeleteHandler_handleGetDeleteJob(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	createdAt := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	deleteBackend := newMockDeleteBackend(orgID)
+	deleteBackend.DeleteJobService = &mock.DeleteJobService{
+		FindDeleteJobByIDFn: func(ctx context.Context, id platform.ID) (*platform.DeleteJob, error) {
+			if id != platformtesting.MustIDBase16("020f755c3c082002") {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "delete job not found"}
+			}
+			return &platform.DeleteJob{
+				ID: id,
+				Request: platform.DeleteRequest{
+					OrgID:     orgID,
+					BucketID:  platformtesting.MustIDBase16("020f755c3c082000"),
+					Start:     createdAt,
+					Stop:      createdAt.Add(time.Hour),
+					Predicate: `host="a"`,
+				},
+				Status:            platform.DeleteJobRunning,
+				SeriesTotal:       10,
+				SeriesProcessed:   4,
+				TombstonesWritten: 6,
+				CreatedAt:         createdAt,
+				StartedAt:         createdAt.Add(time.Second),
+			}, nil
+		},
+	}
+	h := NewDeleteHandler(deleteBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/delete/jobs/020f755c3c082002", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `
+{
+  "links": {
+    "self": "/api/v2/delete/jobs/020f755c3c082002"
+  },
+  "id": "020f755c3c082002",
+  "request": {
+    "orgID": "020f755c3c082001",
+    "bucketID": "020f755c3c082000",
+    "start": "2019-03-01T00:00:00Z",
+    "stop": "2019-03-01T01:00:00Z",
+    "predicate": "host=\"a\""
+  },
+  "status": "running",
+  "seriesTotal": 10,
+  "seriesProcessed": 4,
+  "tombstonesWritten": 6,
+  "createdAt": "2019-03-01T00:00:00Z",
+  "startedAt": "2019-03-01T00:00:01Z",
+  "finishedAt": "0001-01-01T00:00:00Z"
+}
+`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("unexpected response -got/+want:\n%s", diff)
+	}
+
+	r = httptest.NewRequest("GET", "http://any.url/api/v2/delete/jobs/020f755c3c082003", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if res := w.Result(); res.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", res.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	jobID := platformtesting.MustIDBase16("020f755c3c082002")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var job *platform.DeleteJob
+	deleteBackend := newMockDeleteBackend(orgID)
+	deleteBackend.DeleteJobService = &mock.DeleteJobService{
+		CreateDeleteJobFn: func(ctx context.Context, req platform.DeleteRequest) (*platform.DeleteJob, error) {
+			job = &platform.DeleteJob{ID: jobID, Request: req, Status: platform.DeleteJobQueued}
+			return job, nil
+		},
+		FindDeleteJobByIDFn: func(ctx context.Context, id platform.ID) (*platform.DeleteJob, error) {
+			if job == nil || job.ID != id {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "delete job not found"}
+			}
+			j := *job
+			j.Status = platform.DeleteJobSuccess
+			return &j, nil
+		},
+		FindDeleteJobsFn: func(ctx context.Context, filter platform.DeleteJobFilter) ([]*platform.DeleteJob, error) {
+			if filter.BucketID == nil || *filter.BucketID != bucketID {
+				t.Errorf("unexpected filter %+v", filter)
+			}
+			return []*platform.DeleteJob{job}, nil
+		},
+	}
+	server := httptest.NewServer(NewDeleteHandler(deleteBackend))
+	defer server.Close()
+
+	want := platform.DeleteRequest{
+		OrgID:     orgID,
+		BucketID:  bucketID,
+		Start:     start,
+		Stop:      start.Add(time.Hour),
+		Predicate: `_measurement="cpu"`,
+	}
+	s := &DeleteService{Addr: server.URL}
+	if err := s.DeleteBucketRangePredicate(context.Background(), want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, job.Request); diff != "" {
+		t.Errorf("unexpected request -want/+got:\n%s", diff)
+	}
+
+	jobs, err := s.FindDeleteJobs(context.Background(), platform.DeleteJobFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != jobID {
+		t.Errorf("unexpected delete jobs %+v", jobs)
+	}
+}
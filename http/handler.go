@@ -45,6 +45,10 @@ type Handler struct {
 	// Handler handles all other requests
 	Handler http.Handler
 
+	// OperationalAuth if set authenticates the requests to the metrics, ready, health and debug endpoints.
+	// Otherwise they are served without authentication.
+	OperationalAuth *OperationalAuth
+
 	requests   *prometheus.CounterVec
 	requestDur *prometheus.HistogramVec
 
@@ -155,13 +159,13 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case r.URL.Path == MetricsPath:
-		h.MetricsHandler.ServeHTTP(w, r)
+		h.OperationalAuth.handler(MetricsPath, h.MetricsHandler).ServeHTTP(w, r)
 	case r.URL.Path == ReadyPath:
-		h.ReadyHandler.ServeHTTP(w, r)
+		h.OperationalAuth.handler(ReadyPath, h.ReadyHandler).ServeHTTP(w, r)
 	case r.URL.Path == HealthPath:
-		h.HealthHandler.ServeHTTP(w, r)
+		h.OperationalAuth.handler(HealthPath, h.HealthHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, DebugPath):
-		h.DebugHandler.ServeHTTP(w, r)
+		h.OperationalAuth.handler(DebugPath, h.DebugHandler).ServeHTTP(w, r)
 	default:
 		h.Handler.ServeHTTP(w, r)
 	}
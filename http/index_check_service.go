@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// IndexCheckHandler represents an HTTP API handler for the checks of the index of the storage engine
+type IndexCheckHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	IndexCheckService platform.IndexCheckService
+}
+
+const (
+	indexCheckPath = "/api/v2/ops/index/check"
+)
+
+// NewIndexCheckHandler returns a new instance of IndexCheckHandler
+func NewIndexCheckHandler(s platform.IndexCheckService) *IndexCheckHandler {
+	h := &IndexCheckHandler{
+		Router:            NewRouter(),
+		Logger:            zap.NewNop(),
+		IndexCheckService: s,
+	}
+
+	h.HandlerFunc("GET", indexCheckPath, h.handleGetIndexCheck)
+	h.HandlerFunc("POST", indexCheckPath, h.handlePostIndexCheck)
+
+	return h
+}
+
+type indexCheckResponse struct {
+	Links map[string]string `json:"links"`
+	platform.IndexCheck
+}
+
+func newIndexCheckResponse(c *platform.IndexCheck) *indexCheckResponse {
+	return &indexCheckResponse{
+		Links: map[string]string{
+			"self": indexCheckPath,
+		},
+		IndexCheck: *c,
+	}
+}
+
+// handleGetIndexCheck is the HTTP handler for the GET /api/v2/ops/index/check route.
+func (h *IndexCheckHandler) handleGetIndexCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	c, err := h.IndexCheckService.FindIndexCheck(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newIndexCheckResponse(c)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostIndexCheck is the HTTP handler for the POST /api/v2/ops/index/check route.
+func (h *IndexCheckHandler) handlePostIndexCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	opts, err := decodePostIndexCheckRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	c, err := h.IndexCheckService.CheckIndex(ctx, *opts)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newIndexCheckResponse(c)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodePostIndexCheckRequest decodes the options of a check, which are the default ones if the body is empty.
+func decodePostIndexCheckRequest(r *http.Request) (*platform.IndexCheckOptions, error) {
+	opts := &platform.IndexCheckOptions{}
+	if err := json.NewDecoder(r.Body).Decode(opts); err != nil && err != io.EOF {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+	return opts, nil
+}
+
+// IndexCheckService connects to Influx via HTTP using tokens to check the index of the storage engine of influxd
+type IndexCheckService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.IndexCheckService = (*IndexCheckService)(nil)
+
+// CheckIndex starts checking the index in the background, and returns the check started.
+func (s *IndexCheckService) CheckIndex(ctx context.Context, opts platform.IndexCheckOptions) (*platform.IndexCheck, error) {
+	u, err := newURL(s.Addr, indexCheckPath)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+// FindIndexCheck returns the running check of the index, or the last check that finished.
+func (s *IndexCheckService) FindIndexCheck(ctx context.Context) (*platform.IndexCheck, error) {
+	u, err := newURL(s.Addr, indexCheckPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+func (s *IndexCheckService) do(req *http.Request) (*platform.IndexCheck, error) {
+	hc := newClient(req.URL.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r indexCheckResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r.IndexCheck, nil
+}
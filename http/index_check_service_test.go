@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestIndexCheckService(t *testing.T) {
+	started := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	check := &platform.IndexCheck{
+		Status: platform.IndexCheckSuccess,
+		Repair: true,
+		Partitions: []platform.IndexPartitionCheck{
+			{
+				Partition:        0,
+				SeriesCount:      10,
+				DiscrepancyCount: 1,
+				Discrepancies: []platform.IndexDiscrepancy{
+					{
+						Kind:        platform.IndexDiscrepancySeriesNotIndexed,
+						SeriesID:    3,
+						OrgID:       1,
+						BucketID:    2,
+						Measurement: "cpu",
+						Field:       "usage",
+						Tags:        map[string]string{"host": "a"},
+					},
+				},
+				Rebuilt: true,
+			},
+		},
+		MissingSeriesCount:   2,
+		ReindexedSeriesCount: 2,
+		StartedAt:            started,
+		FinishedAt:           started.Add(time.Minute),
+	}
+
+	svc := mock.NewIndexCheckService()
+	svc.FindIndexCheckFn = func(context.Context) (*platform.IndexCheck, error) {
+		return check, nil
+	}
+	svc.CheckIndexFn = func(ctx context.Context, opts platform.IndexCheckOptions) (*platform.IndexCheck, error) {
+		if !opts.Repair {
+			return nil, &platform.Error{
+				Code: platform.EConflict,
+				Msg:  "an index check is already running",
+			}
+		}
+		return &platform.IndexCheck{
+			Status:     platform.IndexCheckRunning,
+			Repair:     opts.Repair,
+			Partitions: []platform.IndexPartitionCheck{},
+			StartedAt:  started,
+		}, nil
+	}
+
+	server := httptest.NewServer(NewIndexCheckHandler(svc))
+	defer server.Close()
+	client := IndexCheckService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.FindIndexCheck(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(check, got); diff != "" {
+		t.Errorf("unexpected check -want/+got:\n%s", diff)
+	}
+
+	got, err = client.CheckIndex(ctx, platform.IndexCheckOptions{Repair: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != platform.IndexCheckRunning || !got.Repair {
+		t.Errorf("expected a running repair, got %+v", got)
+	}
+
+	if _, err := client.CheckIndex(ctx, platform.IndexCheckOptions{}); platform.ErrorCode(err) != platform.EConflict {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+
+	// A check without a body uses the default options.
+	resp, err := http.Post(server.URL+indexCheckPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/flux/iocounter"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	iql "github.com/influxdata/influxdb/query/influxql"
+	"go.uber.org/zap"
+)
+
+// influxQLQueryPath is the path of the InfluxDB 1.x compatible query endpoint,
+// which the client libraries of InfluxDB 1.x and the InfluxQL datasources of Grafana query.
+const influxQLQueryPath = "/query"
+
+// handleInfluxQLQuery runs the InfluxQL query of the q parameter like InfluxDB 1.x does,
+// mapping its databases and retention policies to buckets with the DBRP mappings.
+// The query runs in the organization of the org or orgID parameter, or of the token of the request.
+func (h *FluxHandler) handleInfluxQLQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		encodeInfluxQLError(w, err)
+		return
+	}
+
+	req, err := h.decodeInfluxQLQueryRequest(r, a)
+	if err != nil {
+		encodeInfluxQLError(w, err)
+		return
+	}
+
+	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+
+	req.Dialect.(*iql.Dialect).SetHeaders(w)
+
+	cw := iocounter.Writer{Writer: w}
+	start := h.Now()
+	_, err = h.ProxyQueryService.Query(ctx, &cw, req)
+	h.logSlowQuery(req, h.Now().Sub(start), cw.Count(), err)
+	if err != nil {
+		if cw.Count() == 0 {
+			encodeInfluxQLError(w, err)
+			return
+		}
+		h.Logger.Info("Error writing response to client",
+			zap.String("handler", "influxql"),
+			zap.String("tag", req.Request.Tag),
+			zap.Error(err),
+		)
+	}
+}
+
+func (h *FluxHandler) decodeInfluxQLQueryRequest(r *http.Request, auth platform.Authorizer) (*query.ProxyRequest, error) {
+	ctx := r.Context()
+
+	q := r.FormValue("q")
+	if q == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  `missing required parameter "q"`,
+		}
+	}
+
+	var org *platform.Organization
+	var err error
+	if params := r.URL.Query(); params.Get(OrgID) == "" && params.Get(OrgName) == "" {
+		// The clients of InfluxDB 1.x do not know of organizations,
+		// their queries run in the organization of their token.
+		a, ok := auth.(*platform.Authorization)
+		if !ok {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "influxql queries of sessions require an organization",
+			}
+		}
+		org, err = h.OrganizationService.FindOrganizationByID(ctx, a.OrgID)
+	} else {
+		org, err = queryOrganization(ctx, r, h.OrganizationService)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	qr := QueryRequest{
+		Type:               "influxql",
+		Query:              q,
+		DB:                 r.FormValue("db"),
+		RP:                 r.FormValue("rp"),
+		Tag:                r.Header.Get(QueryTagHeader),
+		Org:                org,
+		DBRPMappingService: h.DBRPMappingService,
+	}
+	req, err := qr.WithDefaults().proxyRequest(h.Now)
+	if err != nil {
+		return nil, err
+	}
+	req.Dialect = &iql.Dialect{
+		TimeFormat: influxQLTimeFormat(r.FormValue("epoch")),
+		Encoding:   iql.JSON,
+	}
+	if r.FormValue("pretty") == "true" {
+		req.Dialect.(*iql.Dialect).Encoding = iql.JSONPretty
+	}
+
+	req.Request.Authorization, err = queryAuthorization(auth, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// influxQLTimeFormat returns the format of the timestamps of the epoch parameter of a query.
+// Like InfluxDB 1.x, timestamps are formatted as RFC3339Nano without an epoch, and as nanoseconds
+// with an unknown one.
+func influxQLTimeFormat(epoch string) iql.TimeFormat {
+	switch epoch {
+	case "":
+		return iql.RFC3339Nano
+	case "h":
+		return iql.Hour
+	case "m":
+		return iql.Minute
+	case "s":
+		return iql.Second
+	case "ms":
+		return iql.Millisecond
+	case "u", "µ":
+		return iql.Microsecond
+	default:
+		return iql.Nanosecond
+	}
+}
+
+// encodeInfluxQLError encodes err like InfluxDB 1.x does, as the error of the JSON response,
+// with the status code of its platform error code.
+func encodeInfluxQLError(w http.ResponseWriter, err error) {
+	code := platform.ErrorCode(err)
+	httpCode, ok := statusCodePlatformError[code]
+	if !ok {
+		httpCode = http.StatusBadRequest
+	}
+	w.Header().Set(PlatformErrorCodeHeader, code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	_ = json.NewEncoder(w).Encode(iql.Response{Err: influxQLErrorMessage(err)})
+}
+
+// influxQLErrorMessage returns the message of err, without the code of a platform error.
+func influxQLErrorMessage(err error) string {
+	if pe, ok := err.(*platform.Error); ok && pe.Err == nil && pe.Msg != "" {
+		return pe.Msg
+	}
+	return err.Error()
+}
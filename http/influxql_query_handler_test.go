@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	iql "github.com/influxdata/influxdb/query/influxql"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFluxHandler_handleInfluxQLQuery(t *testing.T) {
+	org := &platform.Organization{ID: platform.ID(1), Name: "org"}
+	auth := &platform.Authorization{ID: platform.ID(2), OrgID: org.ID}
+
+	var got *query.ProxyRequest
+	h := NewFluxHandler(&FluxBackend{
+		Logger: zaptest.NewLogger(t),
+		OrganizationService: &mock.OrganizationService{
+			FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+				if id != org.ID {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+				}
+				return org, nil
+			},
+			FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+				return org, nil
+			},
+		},
+		DBRPMappingService: mock.NewDBRPMappingService(),
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryFn: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				got = req
+				_, err := io.WriteString(w, `{"results":[{"statement_id":0}]}`)
+				return flux.Statistics{}, err
+			},
+		},
+	})
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	h.Now = func() time.Time { return now }
+
+	for _, tt := range []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		status  int
+		want    string
+		dialect iql.Dialect
+	}{
+		{
+			name:   "GET",
+			method: "GET",
+			target: "/query?db=telegraf&rp=autogen&q=SELECT+*+FROM+cpu",
+			status: http.StatusOK,
+			want:   `{"results":[{"statement_id":0}]}`,
+		},
+		{
+			name:    "POST form with epoch and pretty",
+			method:  "POST",
+			target:  "/query?org=org",
+			body:    "db=telegraf&rp=autogen&q=SELECT+*+FROM+cpu&epoch=s&pretty=true",
+			status:  http.StatusOK,
+			want:    `{"results":[{"statement_id":0}]}`,
+			dialect: iql.Dialect{TimeFormat: iql.Second, Encoding: iql.JSONPretty},
+		},
+		{
+			name:   "missing query",
+			method: "GET",
+			target: "/query?db=telegraf",
+			status: http.StatusBadRequest,
+			want:   `{"error":"missing required parameter \"q\""}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.method == "POST" {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if body := w.Body.String(); body != tt.want {
+				t.Errorf("got body %s, want %s", body, tt.want)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			c, ok := got.Request.Compiler.(*iql.Compiler)
+			if !ok {
+				t.Fatalf("got compiler %T, want an influxql compiler", got.Request.Compiler)
+			}
+			if c.DB != "telegraf" || c.RP != "autogen" || c.Query != "SELECT * FROM cpu" || c.OrganizationID != org.ID || !c.Now.Equal(now) {
+				t.Errorf("unexpected compiler %+v", c)
+			}
+			if got.Request.OrganizationID != org.ID || got.Request.Authorization != auth {
+				t.Errorf("query runs in organization %v with authorization %v, want those of the token", got.Request.OrganizationID, got.Request.Authorization)
+			}
+			if d := got.Dialect.(*iql.Dialect); *d != tt.dialect {
+				t.Errorf("got dialect %+v, want %+v", *d, tt.dialect)
+			}
+		})
+	}
+}
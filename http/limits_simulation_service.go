@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// LimitsSimulationHandler represents an HTTP API handler for the simulations of limits on the recent traffic
+type LimitsSimulationHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	LimitsSimulationService platform.LimitsSimulationService
+}
+
+const (
+	limitsSimulationPath = "/api/v2/ops/limits/simulate"
+)
+
+// NewLimitsSimulationHandler returns a new instance of LimitsSimulationHandler
+func NewLimitsSimulationHandler(s platform.LimitsSimulationService) *LimitsSimulationHandler {
+	h := &LimitsSimulationHandler{
+		Router:                  NewRouter(),
+		Logger:                  zap.NewNop(),
+		LimitsSimulationService: s,
+	}
+
+	h.HandlerFunc("POST", limitsSimulationPath, h.handlePostLimitsSimulation)
+
+	return h
+}
+
+type limitsSimulationResponse struct {
+	Links map[string]string `json:"links"`
+	platform.LimitsSimulation
+}
+
+func newLimitsSimulationResponse(s *platform.LimitsSimulation) *limitsSimulationResponse {
+	return &limitsSimulationResponse{
+		Links: map[string]string{
+			"self": limitsSimulationPath,
+		},
+		LimitsSimulation: *s,
+	}
+}
+
+// handlePostLimitsSimulation is the HTTP handler for the POST /api/v2/ops/limits/simulate route.
+func (h *LimitsSimulationHandler) handlePostLimitsSimulation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limits, err := decodePostLimitsSimulationRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	s, err := h.LimitsSimulationService.SimulateLimits(ctx, *limits)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLimitsSimulationResponse(s)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodePostLimitsSimulationRequest decodes the limits proposed, which are all zero if the body is empty.
+func decodePostLimitsSimulationRequest(r *http.Request) (*platform.LimitsProposal, error) {
+	limits := &platform.LimitsProposal{}
+	if err := json.NewDecoder(r.Body).Decode(limits); err != nil && err != io.EOF {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid request body",
+			Err:  err,
+		}
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// LimitsSimulationService connects to Influx via HTTP using tokens to simulate limits on the recent traffic of influxd
+type LimitsSimulationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.LimitsSimulationService = (*LimitsSimulationService)(nil)
+
+// SimulateLimits reports what the limits proposed would have rejected of the recent traffic of the server.
+func (s *LimitsSimulationService) SimulateLimits(ctx context.Context, limits platform.LimitsProposal) (*platform.LimitsSimulation, error) {
+	u, err := newURL(s.Addr, limitsSimulationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(limits)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r limitsSimulationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r.LimitsSimulation, nil
+}
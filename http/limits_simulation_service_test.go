@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestLimitsSimulationService(t *testing.T) {
+	since := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	limits := platform.LimitsProposal{
+		OrgPointsPerSecond:  1000,
+		QueryOrgConcurrency: 2,
+		BucketSeries:        100000,
+	}
+	sim := &platform.LimitsSimulation{
+		Limits:          limits,
+		Since:           since,
+		Until:           since.Add(time.Hour),
+		Writes:          20,
+		Points:          50000,
+		RejectedWrites:  3,
+		RejectedPoints:  7500,
+		Queries:         12,
+		RejectedQueries: 1,
+		Orgs: []platform.OrgLimitsSimulation{
+			{
+				OrgID:                1,
+				Writes:               20,
+				Points:               50000,
+				RejectedWrites:       3,
+				RejectedPoints:       7500,
+				Queries:              12,
+				RejectedQueries:      1,
+				PeakQueryConcurrency: 3,
+			},
+		},
+		Buckets: []platform.BucketLimitsSimulation{
+			{OrgID: 1, BucketID: 2, Series: 250000},
+		},
+	}
+
+	svc := mock.NewLimitsSimulationService()
+	svc.SimulateLimitsFn = func(ctx context.Context, p platform.LimitsProposal) (*platform.LimitsSimulation, error) {
+		if p != limits {
+			t.Errorf("got limits %+v, want %+v", p, limits)
+		}
+		return sim, nil
+	}
+
+	server := httptest.NewServer(NewLimitsSimulationHandler(svc))
+	defer server.Close()
+	client := LimitsSimulationService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.SimulateLimits(ctx, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(sim, got); diff != "" {
+		t.Errorf("unexpected simulation -want/+got:\n%s", diff)
+	}
+
+	// Negative limits are rejected before they are simulated.
+	resp, err := http.Post(server.URL+limitsSimulationPath, "application/json", strings.NewReader(`{"bucketSeries":-1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,338 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// LineageHandler represents an HTTP API handler for the lineage of the data written by tasks
+type LineageHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	LineageService platform.LineageService
+}
+
+const (
+	lineagePath        = "/api/v2/lineage"
+	lineageRecordsPath = "/api/v2/lineage/records"
+)
+
+// NewLineageHandler returns a new instance of LineageHandler
+func NewLineageHandler(s platform.LineageService) *LineageHandler {
+	h := &LineageHandler{
+		Router:         NewRouter(),
+		Logger:         zap.NewNop(),
+		LineageService: s,
+	}
+
+	h.HandlerFunc("GET", lineagePath, h.handleGetLineage)
+	h.HandlerFunc("GET", lineageRecordsPath, h.handleGetLineageRecords)
+	h.HandlerFunc("POST", lineageRecordsPath, h.handlePostLineageRecord)
+
+	return h
+}
+
+type lineageResponse struct {
+	Links map[string]string `json:"links"`
+	*platform.Lineage
+}
+
+type lineageRecordsResponse struct {
+	Links   map[string]string         `json:"links"`
+	Records []*platform.LineageRecord `json:"records"`
+}
+
+// handleGetLineage is the HTTP handler for the GET /api/v2/lineage route.
+func (h *LineageHandler) handleGetLineage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeLineageFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	l, err := h.LineageService.FindLineage(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := &lineageResponse{
+		Links: map[string]string{
+			"self":    lineagePath,
+			"records": lineageRecordsPath,
+		},
+		Lineage: l,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeLineageFilter(r *http.Request) (platform.LineageFilter, error) {
+	qp := r.URL.Query()
+	filter := platform.LineageFilter{
+		Direction: platform.LineageDirection(qp.Get("direction")),
+	}
+	if filter.Direction == "" {
+		filter.Direction = platform.LineageUpstream
+	}
+
+	for _, p := range []struct {
+		name string
+		id   *platform.ID
+	}{
+		{name: "orgID", id: &filter.OrgID},
+		{name: "bucketID", id: &filter.BucketID},
+	} {
+		s := qp.Get(p.name)
+		if s == "" {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  p.name + " is required",
+			}
+		}
+		if err := p.id.DecodeFromString(s); err != nil {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid " + p.name,
+				Err:  err,
+			}
+		}
+	}
+
+	if s := qp.Get("depth"); s != "" {
+		depth, err := strconv.Atoi(s)
+		if err != nil || depth < 0 {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "depth must be a non-negative integer",
+			}
+		}
+		filter.Depth = depth
+	}
+
+	return filter, nil
+}
+
+// handleGetLineageRecords is the HTTP handler for the GET /api/v2/lineage/records route.
+func (h *LineageHandler) handleGetLineageRecords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeLineageRecordFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.LineageService.FindLineageRecords(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := &lineageRecordsResponse{
+		Links: map[string]string{
+			"self": lineageRecordsPath,
+		},
+		Records: rs,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeLineageRecordFilter(r *http.Request) (platform.LineageRecordFilter, error) {
+	qp := r.URL.Query()
+	var filter platform.LineageRecordFilter
+	for _, p := range []struct {
+		name string
+		id   **platform.ID
+	}{
+		{name: "orgID", id: &filter.OrgID},
+		{name: "taskID", id: &filter.TaskID},
+		{name: "runID", id: &filter.RunID},
+		{name: "bucketID", id: &filter.BucketID},
+	} {
+		s := qp.Get(p.name)
+		if s == "" {
+			continue
+		}
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid " + p.name,
+				Err:  err,
+			}
+		}
+		*p.id = id
+	}
+	return filter, nil
+}
+
+// handlePostLineageRecord is the HTTP handler for the POST /api/v2/lineage/records route.
+func (h *LineageHandler) handlePostLineageRecord(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rec := &platform.LineageRecord{}
+	if err := json.NewDecoder(r.Body).Decode(rec); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid lineage record",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.LineageService.AddLineageRecord(ctx, rec); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, rec); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// LineageService connects to Influx via HTTP using tokens to record and trace the lineage of the data written by tasks
+type LineageService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.LineageService = (*LineageService)(nil)
+
+// AddLineageRecord records the lineage of a run, and sets r.ID with the new identifier.
+func (s *LineageService) AddLineageRecord(ctx context.Context, r *platform.LineageRecord) error {
+	u, err := newURL(s.Addr, lineageRecordsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(r)
+}
+
+// FindLineageRecords returns the lineage records that match the filter, latest first.
+func (s *LineageService) FindLineageRecords(ctx context.Context, filter platform.LineageRecordFilter) ([]*platform.LineageRecord, error) {
+	u, err := newURL(s.Addr, lineageRecordsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.OrgID != nil {
+		query.Add("orgID", filter.OrgID.String())
+	}
+	if filter.TaskID != nil {
+		query.Add("taskID", filter.TaskID.String())
+	}
+	if filter.RunID != nil {
+		query.Add("runID", filter.RunID.String())
+	}
+	if filter.BucketID != nil {
+		query.Add("bucketID", filter.BucketID.String())
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r lineageRecordsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.Records, nil
+}
+
+// FindLineage traverses the lineage of a bucket of an organization.
+func (s *LineageService) FindLineage(ctx context.Context, filter platform.LineageFilter) (*platform.Lineage, error) {
+	u, err := newURL(s.Addr, lineagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("orgID", filter.OrgID.String())
+	query.Add("bucketID", filter.BucketID.String())
+	query.Add("direction", string(filter.Direction))
+	if filter.Depth > 0 {
+		query.Add("depth", strconv.Itoa(filter.Depth))
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r lineageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.Lineage, nil
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initLineageService(f platformtesting.LineageFields, t *testing.T) (platform.LineageService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = f.IDGenerator
+	svc.WithTime(f.NowFn)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize lineage service: %v", err)
+	}
+	for _, r := range f.LineageRecords {
+		if err := svc.AddLineageRecord(ctx, r); err != nil {
+			t.Fatalf("failed to populate lineage records: %v", err)
+		}
+	}
+
+	handler := NewLineageHandler(svc)
+	server := httptest.NewServer(handler)
+	client := LineageService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestLineageService(t *testing.T) {
+	platformtesting.LineageService(initLineageService, t)
+}
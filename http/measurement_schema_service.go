@@ -0,0 +1,427 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+type measurementSchemaResponse struct {
+	*platform.MeasurementSchema
+	Links map[string]string `json:"links"`
+}
+
+func newMeasurementSchemaResponse(ms *platform.MeasurementSchema) *measurementSchemaResponse {
+	return &measurementSchemaResponse{
+		MeasurementSchema: ms,
+		Links: map[string]string{
+			"self":   measurementSchemaIDPath(ms.BucketID, ms.ID),
+			"bucket": bucketIDPath(ms.BucketID),
+		},
+	}
+}
+
+type measurementSchemasResponse struct {
+	Links              map[string]string            `json:"links"`
+	MeasurementSchemas []*measurementSchemaResponse `json:"measurementSchemas"`
+}
+
+func newMeasurementSchemasResponse(bucketID platform.ID, ss []*platform.MeasurementSchema) *measurementSchemasResponse {
+	res := &measurementSchemasResponse{
+		Links: map[string]string{
+			"self": measurementSchemasPath(bucketID),
+		},
+		MeasurementSchemas: make([]*measurementSchemaResponse, 0, len(ss)),
+	}
+	for _, ms := range ss {
+		res.MeasurementSchemas = append(res.MeasurementSchemas, newMeasurementSchemaResponse(ms))
+	}
+	return res
+}
+
+// handleGetMeasurementSchemas is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handleGetMeasurementSchemas(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, _, err := decodeMeasurementSchemaParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	filter := platform.MeasurementSchemaFilter{BucketID: &bucketID}
+	if name := r.URL.Query().Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	ss, err := h.MeasurementSchemaService.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMeasurementSchemasResponse(bucketID, ss)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostMeasurementSchema is the HTTP handler for the POST /api/v2/buckets/:id/schema/measurements route.
+// The organization of the schema defaults to the one of the bucket.
+func (h *BucketHandler) handlePostMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bucketID, _, err := decodeMeasurementSchemaParams(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ms := &platform.MeasurementSchema{}
+	if err := json.NewDecoder(r.Body).Decode(ms); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode measurement schema request",
+			Err:  err,
+		}, w)
+		return
+	}
+	ms.BucketID = bucketID
+
+	if !ms.OrgID.Valid() {
+		b, err := h.BucketService.FindBucketByID(ctx, bucketID)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		ms.OrgID = b.OrganizationID
+	}
+
+	if err := h.MeasurementSchemaService.CreateMeasurementSchema(ctx, ms); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newMeasurementSchemaResponse(ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetMeasurementSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handleGetMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findBucketMeasurementSchema(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMeasurementSchemaResponse(ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchMeasurementSchema is the HTTP handler for the PATCH /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handlePatchMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findBucketMeasurementSchema(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd platform.MeasurementSchemaUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode measurement schema update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	ms, err = h.MeasurementSchemaService.UpdateMeasurementSchema(ctx, ms.ID, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMeasurementSchemaResponse(ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteMeasurementSchema is the HTTP handler for the DELETE /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handleDeleteMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findBucketMeasurementSchema(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.MeasurementSchemaService.DeleteMeasurementSchema(ctx, ms.ID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findBucketMeasurementSchema returns the measurement schema of the route, which must be a schema of the bucket of the route.
+func (h *BucketHandler) findBucketMeasurementSchema(ctx context.Context) (*platform.MeasurementSchema, error) {
+	bucketID, id, err := decodeMeasurementSchemaParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := h.MeasurementSchemaService.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ms.BucketID != bucketID {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  platform.ErrMeasurementSchemaNotFound,
+		}
+	}
+	return ms, nil
+}
+
+// decodeMeasurementSchemaParams returns the bucket ID and, if any, the measurement schema ID of the route.
+func decodeMeasurementSchemaParams(ctx context.Context) (bucketID, id platform.ID, err error) {
+	params := httprouter.ParamsFromContext(ctx)
+	bid := params.ByName("id")
+	if bid == "" {
+		return 0, 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+	if err := bucketID.DecodeFromString(bid); err != nil {
+		return 0, 0, err
+	}
+
+	if mid := params.ByName("measurementID"); mid != "" {
+		if err := id.DecodeFromString(mid); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return bucketID, id, nil
+}
+
+// MeasurementSchemaService connects to Influx via HTTP using tokens to manage the measurement schemas of buckets.
+type MeasurementSchemaService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+
+	// BucketID is the bucket of the schemas found, updated and deleted by ID,
+	// and of the schemas found with a filter without a bucket.
+	BucketID platform.ID
+}
+
+var _ platform.MeasurementSchemaService = (*MeasurementSchemaService)(nil)
+
+// FindMeasurementSchemaByID returns a single measurement schema of the bucket of the service by ID.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id platform.ID) (*platform.MeasurementSchema, error) {
+	u, err := newURL(s.Addr, measurementSchemaIDPath(s.BucketID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r measurementSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.MeasurementSchema, nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas of a bucket that match a filter.
+// Only the name of the filter is sent to the server.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+	bucketID := s.BucketID
+	if filter.BucketID != nil {
+		bucketID = *filter.BucketID
+	}
+
+	u, err := newURL(s.Addr, measurementSchemasPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.Name != nil {
+		query.Add("name", *filter.Name)
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r measurementSchemasResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	ss := make([]*platform.MeasurementSchema, 0, len(r.MeasurementSchemas))
+	for _, sr := range r.MeasurementSchemas {
+		if filter.Matches(sr.MeasurementSchema) {
+			ss = append(ss, sr.MeasurementSchema)
+		}
+	}
+	return ss, nil
+}
+
+// CreateMeasurementSchema creates a new measurement schema of the bucket ms.BucketID and sets ms.ID with the new identifier.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *platform.MeasurementSchema) error {
+	u, err := newURL(s.Addr, measurementSchemasPath(ms.BucketID))
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(ms)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var r measurementSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	*ms = *r.MeasurementSchema
+
+	return nil
+}
+
+// UpdateMeasurementSchema updates a single measurement schema of the bucket of the service with changeset.
+// Returns the new measurement schema state after update.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id platform.ID, upd platform.MeasurementSchemaUpdate) (*platform.MeasurementSchema, error) {
+	u, err := newURL(s.Addr, measurementSchemaIDPath(s.BucketID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r measurementSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.MeasurementSchema, nil
+}
+
+// DeleteMeasurementSchema removes a measurement schema of the bucket of the service by ID.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id platform.ID) error {
+	u, err := newURL(s.Addr, measurementSchemaIDPath(s.BucketID, id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func measurementSchemasPath(bucketID platform.ID) string {
+	return path.Join(bucketIDPath(bucketID), "schema", "measurements")
+}
+
+func measurementSchemaIDPath(bucketID, id platform.ID) string {
+	return path.Join(measurementSchemasPath(bucketID), id.String())
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+func initMeasurementSchemaService(f platformtesting.MeasurementSchemaFields, t *testing.T) (platform.MeasurementSchemaService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize measurement schema service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, b := range f.Buckets {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatalf("failed to populate buckets: %v", err)
+		}
+	}
+	for _, ms := range f.MeasurementSchemas {
+		if err := svc.PutMeasurementSchema(ctx, ms); err != nil {
+			t.Fatalf("failed to populate measurement schemas: %v", err)
+		}
+	}
+
+	handler := NewBucketHandler(&BucketBackend{
+		Logger:                   zap.NewNop(),
+		BucketService:            svc,
+		MeasurementSchemaService: svc,
+	})
+	server := httptest.NewServer(handler)
+	client := MeasurementSchemaService{
+		Addr:     server.URL,
+		BucketID: platformtesting.MustIDBase16("020f755c3c082000"),
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestMeasurementSchemaService(t *testing.T) {
+	platformtesting.MeasurementSchemaService(initMeasurementSchemaService, t)
+}
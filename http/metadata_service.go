@@ -0,0 +1,330 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// MetadataHandler represents an HTTP API handler for resource metadata
+type MetadataHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	MetadataService platform.MetadataService
+}
+
+const (
+	metadataPath      = "/api/v2/metadata"
+	metadataIDKeyPath = "/api/v2/metadata/:id/:key"
+)
+
+// NewMetadataHandler returns a new instance of MetadataHandler
+func NewMetadataHandler(s platform.MetadataService) *MetadataHandler {
+	h := &MetadataHandler{
+		Router:          NewRouter(),
+		Logger:          zap.NewNop(),
+		MetadataService: s,
+	}
+
+	h.HandlerFunc("GET", metadataPath, h.handleGetMetadata)
+	h.HandlerFunc("PUT", metadataIDKeyPath, h.handlePutMetadata)
+	h.HandlerFunc("DELETE", metadataIDKeyPath, h.handleDeleteMetadata)
+
+	return h
+}
+
+type metadataResponse struct {
+	Links    map[string]string    `json:"links"`
+	Metadata []*platform.Metadata `json:"metadata"`
+}
+
+func newMetadataResponse(ms []*platform.Metadata) *metadataResponse {
+	return &metadataResponse{
+		Links: map[string]string{
+			"self": metadataPath,
+		},
+		Metadata: ms,
+	}
+}
+
+// handleGetMetadata is the HTTP handler for the GET /api/v2/metadata route.
+func (h *MetadataHandler) handleGetMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetMetadataRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ms, err := h.MetadataService.FindMetadata(ctx, req.filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMetadataResponse(ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type getMetadataRequest struct {
+	filter platform.MetadataFilter
+}
+
+func decodeGetMetadataRequest(ctx context.Context, r *http.Request) (*getMetadataRequest, error) {
+	qp := r.URL.Query()
+	req := &getMetadataRequest{}
+
+	if id := qp.Get("resourceID"); id != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(id); err != nil {
+			return nil, err
+		}
+		req.filter.ResourceID = &i
+	}
+
+	if rt := qp.Get("resourceType"); rt != "" {
+		req.filter.ResourceType = platform.ResourceType(rt)
+		if err := req.filter.ResourceType.Valid(); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Err:  err,
+			}
+		}
+	}
+
+	req.filter.Key = qp.Get("key")
+
+	if v, ok := qp["value"]; ok {
+		typ := platform.MetadataString
+		if t := qp.Get("type"); t != "" {
+			typ = platform.MetadataType(t)
+		}
+		value, err := typ.ParseValue(v[0])
+		if err != nil {
+			return nil, err
+		}
+		req.filter.Value = value
+	}
+
+	return req, nil
+}
+
+// handlePutMetadata is the HTTP handler for the PUT /api/v2/metadata/:id/:key route.
+func (h *MetadataHandler) handlePutMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePutMetadataRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.MetadataService.SetMetadata(ctx, req.Metadata); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, req.Metadata); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type putMetadataRequest struct {
+	Metadata *platform.Metadata
+}
+
+func decodePutMetadataRequest(ctx context.Context, r *http.Request) (*putMetadataRequest, error) {
+	id, key, err := decodeMetadataIDKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &platform.Metadata{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode metadata request",
+			Err:  err,
+		}
+	}
+	m.ResourceID = id
+	m.Key = key
+
+	return &putMetadataRequest{
+		Metadata: m,
+	}, m.Validate()
+}
+
+// handleDeleteMetadata is the HTTP handler for the DELETE /api/v2/metadata/:id/:key route.
+func (h *MetadataHandler) handleDeleteMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, key, err := decodeMetadataIDKey(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.MetadataService.DeleteMetadata(ctx, id, key); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeMetadataIDKey(ctx context.Context) (platform.ID, string, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, "", &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, "", err
+	}
+
+	return i, params.ByName("key"), nil
+}
+
+// MetadataService connects to Influx via HTTP using tokens to manage resource metadata
+type MetadataService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.MetadataService = (*MetadataService)(nil)
+
+// FindMetadata returns the metadata that match a filter.
+func (s *MetadataService) FindMetadata(ctx context.Context, filter platform.MetadataFilter) ([]*platform.Metadata, error) {
+	u, err := newURL(s.Addr, metadataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.ResourceID != nil {
+		query.Add("resourceID", filter.ResourceID.String())
+	}
+	if filter.ResourceType != "" {
+		query.Add("resourceType", string(filter.ResourceType))
+	}
+	if filter.Key != "" {
+		query.Add("key", filter.Key)
+	}
+	switch v := filter.Value.(type) {
+	case string:
+		query.Add("type", string(platform.MetadataString))
+		query.Add("value", v)
+	case float64:
+		query.Add("type", string(platform.MetadataNumber))
+		query.Add("value", strconv.FormatFloat(v, 'g', -1, 64))
+	case bool:
+		query.Add("type", string(platform.MetadataBool))
+		query.Add("value", strconv.FormatBool(v))
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r metadataResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Metadata, nil
+}
+
+// SetMetadata creates or replaces the metadata with m's key on m's resource.
+func (s *MetadataService) SetMetadata(ctx context.Context, m *platform.Metadata) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	u, err := newURL(s.Addr, metadataIDKeyURL(m.ResourceID, m.Key))
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// DeleteMetadata removes the metadata with key from a resource.
+func (s *MetadataService) DeleteMetadata(ctx context.Context, resourceID platform.ID, key string) error {
+	u, err := newURL(s.Addr, metadataIDKeyURL(resourceID, key))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func metadataIDKeyURL(id platform.ID, key string) string {
+	return path.Join(metadataPath, id.String(), key)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initMetadataService(f platformtesting.MetadataServiceFields, t *testing.T) (platform.MetadataService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize metadata service: %v", err)
+	}
+	for _, m := range f.Metadata {
+		if err := svc.SetMetadata(ctx, m); err != nil {
+			t.Fatalf("failed to populate metadata: %v", err)
+		}
+	}
+
+	handler := NewMetadataHandler(svc)
+	server := httptest.NewServer(handler)
+	client := MetadataService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestMetadataService(t *testing.T) {
+	platformtesting.MetadataService(initMetadataService, t)
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/opentsdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+)
+
+// opentsdbPutPath is the path of the OpenTSDB compatible write endpoint,
+// which OpenTSDB collectors are pointed at with the org and bucket query parameters.
+const opentsdbPutPath = "/api/put"
+
+// putResponse is the response of /api/put listing the datapoints that failed to be written,
+// as OpenTSDB answers when the details parameter is set.
+type putResponse struct {
+	Success int           `json:"success"`
+	Failed  int           `json:"failed"`
+	Errors  []putDPFailed `json:"errors"`
+}
+
+// putDPFailed is a datapoint that failed to be written, and why.
+type putDPFailed struct {
+	Datapoint opentsdb.Datapoint `json:"datapoint"`
+	Error     string             `json:"error"`
+}
+
+// handlePut is the HTTP handler for the POST /api/put route, writing OpenTSDB datapoints to the bucket of the request.
+// The valid datapoints are written even if others are not, which are then listed in a 400 response.
+// A successful write is answered with 204, or with the count of datapoints written if the details or summary parameter is set.
+func (h *WriteHandler) handlePut(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var in io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handlePut",
+				Msg:  errInvalidGzipHeader,
+				Err:  err,
+			}, w)
+			return
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	qp := r.URL.Query()
+	orgName, bucketName := qp.Get("org"), qp.Get("bucket")
+	logger := h.Logger.With(zap.String("org", orgName), zap.String("bucket", bucketName))
+
+	org, bucket, err := h.findWriteBucket(ctx, a, orgName, bucketName)
+	if err != nil {
+		logger.Info("Failed to find bucket to write to", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePut",
+			Msg:  fmt.Sprintf("unable to read data: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+	dps, err := opentsdb.ParseJSON(data)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handlePut",
+			Msg:  fmt.Sprintf("unable to parse datapoints: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	// The index of a datapoint is its line, to share the checks of the line protocol writes.
+	var (
+		points []models.Point
+		lines  []int
+		failed []rejectedLine
+	)
+	for i := range dps {
+		pt, err := dps[i].Point()
+		if err != nil {
+			failed = append(failed, rejectedLine{Line: i, Error: err.Error()})
+			continue
+		}
+		points = append(points, pt)
+		lines = append(lines, i)
+	}
+
+	if h.WriteLimiter != nil {
+		if err := h.WriteLimiter.Allow(org.ID, a.Identifier(), len(points), len(data)); err != nil {
+			lee, ok := err.(*write.LimitExceededError)
+			if !ok {
+				EncodeError(ctx, err, w)
+				return
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(lee.RetryAfter/time.Second)))
+			EncodeError(ctx, &platform.Error{
+				Code: platform.ETooManyRequests,
+				Op:   "http/handlePut",
+				Msg:  err.Error(),
+			}, w)
+			return
+		}
+	}
+
+	points, lines, bad, err := checkSchema(ctx, h.MeasurementSchemaService, bucket, points, lines)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	failed = append(failed, bad...)
+
+	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
+	if err != nil {
+		var bad []rejectedLine
+		points, lines, bad = explodeErrors(org.ID, bucket.ID, points, lines)
+		failed = append(failed, bad...)
+		if exploded, err = tsdb.ExplodePoints(org.ID, bucket.ID, points); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
+	success := len(points)
+	if len(exploded) > 0 {
+		if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
+			pwe, ok := err.(tsdb.PartialWriteError)
+			if !ok {
+				logger.Error("Error writing points", zap.Error(err))
+				EncodeError(ctx, &platform.Error{
+					Code: platform.EInternal,
+					Op:   "http/handlePut",
+					Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+					Err:  err,
+				}, w)
+				return
+			}
+			bad := droppedLines(org.ID, bucket.ID, points, lines, pwe)
+			success -= len(bad)
+			failed = append(failed, bad...)
+		}
+	}
+
+	res := &putResponse{Success: success, Failed: len(failed), Errors: []putDPFailed{}}
+	for _, f := range failed {
+		res.Errors = append(res.Errors, putDPFailed{Datapoint: dps[f.Line], Error: f.Error})
+	}
+	switch {
+	case len(failed) > 0:
+		logger.Info("Rejected OpenTSDB datapoints", zap.Int("rejected", len(failed)))
+		if err := encodeResponse(ctx, w, http.StatusBadRequest, res); err != nil {
+			logEncodingError(logger, r, err)
+		}
+	case qp["details"] != nil || qp["summary"] != nil:
+		if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+			logEncodingError(logger, r, err)
+		}
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func TestWriteHandler_Put(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+
+	put := func(query, body string, permissions []platform.Permission) (*httptest.ResponseRecorder, *mock.PointsWriter) {
+		pw := &mock.PointsWriter{}
+		h := NewWriteHandler(&WriteBackend{
+			Logger:              zap.NewNop(),
+			PointsWriter:        pw,
+			BucketService:       buckets,
+			OrganizationService: orgs,
+		})
+		r := httptest.NewRequest("POST", "/api/put?org=0000000000000001&bucket=0000000000000002"+query, strings.NewReader(body))
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: permissions}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w, pw
+	}
+
+	t.Run("datapoints", func(t *testing.T) {
+		w, pw := put("", `[
+  {"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18, "tags": {"host": "web01"}},
+  {"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 9, "tags": {"host": "web02"}}
+]`, platform.OperPermissions())
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+		}
+		if len(pw.Points) != 2 {
+			t.Errorf("got %d points written, want 2", len(pw.Points))
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		w, _ := put("&summary", `{"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18, "tags": {"host": "web01"}}`, platform.OperPermissions())
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var res putResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Success != 1 || res.Failed != 0 {
+			t.Errorf("unexpected summary %+v", res)
+		}
+	})
+
+	t.Run("invalid datapoint", func(t *testing.T) {
+		w, pw := put("", `[
+  {"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18, "tags": {"host": "web01"}},
+  {"metric": "", "timestamp": 1346846400, "value": 9, "tags": {"host": "web02"}}
+]`, platform.OperPermissions())
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+		}
+		var res putResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Success != 1 || res.Failed != 1 || len(res.Errors) != 1 || res.Errors[0].Datapoint.Tags["host"] != "web02" {
+			t.Errorf("unexpected response %+v", res)
+		}
+		if len(pw.Points) != 1 {
+			t.Errorf("got %d points written, want the valid one", len(pw.Points))
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		w, pw := put("", `{"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18}`, nil)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+		}
+		if len(pw.Points) != 0 {
+			t.Errorf("got %d points written, want none", len(pw.Points))
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// OperationalAuthMode is how the requests to an operational endpoint are authenticated.
+type OperationalAuthMode string
+
+const (
+	// OperationalAuthNone serves the endpoint without authentication.
+	OperationalAuthNone OperationalAuthMode = "none"
+	// OperationalAuthToken requires a token or a session, like the rest of the API.
+	OperationalAuthToken OperationalAuthMode = "token"
+	// OperationalAuthMTLS requires a client certificate verified by the TLS server instead of a token.
+	OperationalAuthMTLS OperationalAuthMode = "mtls"
+)
+
+// operationalPaths maps the names of the operational endpoints to their paths.
+var operationalPaths = map[string]string{
+	"metrics": MetricsPath,
+	"ready":   ReadyPath,
+	"health":  HealthPath,
+	"debug":   DebugPath,
+}
+
+// OperationalAuth configures how the requests to the operational endpoints of a Handler are authenticated.
+type OperationalAuth struct {
+	// Modes maps the paths of the operational endpoints to their authentication.
+	// Endpoints that are not in Modes are served without authentication.
+	Modes map[string]OperationalAuthMode
+
+	// AuthorizationService and SessionService authenticate the endpoints requiring a token.
+	AuthorizationService platform.AuthorizationService
+	SessionService       platform.SessionService
+}
+
+// ParseOperationalAuthModes parses endpoint=mode pairs, such as metrics=mtls,
+// into the modes of OperationalAuth.
+// The endpoints are metrics, ready, health and debug, and the modes are none, token and mtls.
+func ParseOperationalAuthModes(pairs []string) (map[string]OperationalAuthMode, error) {
+	modes := make(map[string]OperationalAuthMode, len(pairs))
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid operational endpoint authentication %q: expected endpoint=mode", pair)
+		}
+
+		path, ok := operationalPaths[kv[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown operational endpoint %q", kv[0])
+		}
+
+		mode := OperationalAuthMode(kv[1])
+		switch mode {
+		case OperationalAuthNone, OperationalAuthToken, OperationalAuthMTLS:
+		default:
+			return nil, fmt.Errorf("unknown authentication mode %q for operational endpoint %q", kv[1], kv[0])
+		}
+		modes[path] = mode
+	}
+	return modes, nil
+}
+
+// Requires returns true if any operational endpoint is authenticated with mode.
+func (a *OperationalAuth) Requires(mode OperationalAuthMode) bool {
+	if a == nil {
+		return false
+	}
+	for _, m := range a.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// handler returns next wrapped with the authentication of the operational endpoint at path.
+func (a *OperationalAuth) handler(path string, next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	switch a.Modes[path] {
+	case OperationalAuthToken:
+		h := NewAuthenticationHandler()
+		h.AuthorizationService = a.AuthorizationService
+		h.SessionService = a.SessionService
+		h.Handler = next
+		return h
+	case OperationalAuthMTLS:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				UnauthorizedError(r.Context(), w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	default:
+		return next
+	}
+}
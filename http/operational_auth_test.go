@@ -0,0 +1,162 @@
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	platformhttp "github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestParseOperationalAuthModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]platformhttp.OperationalAuthMode
+		wantErr bool
+	}{
+		{
+			name: "no pairs",
+			want: map[string]platformhttp.OperationalAuthMode{},
+		},
+		{
+			name:  "every mode",
+			pairs: []string{"metrics=mtls", "debug=token", "health=none"},
+			want: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.MetricsPath: platformhttp.OperationalAuthMTLS,
+				platformhttp.DebugPath:   platformhttp.OperationalAuthToken,
+				platformhttp.HealthPath:  platformhttp.OperationalAuthNone,
+			},
+		},
+		{
+			name:    "missing mode",
+			pairs:   []string{"metrics"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown endpoint",
+			pairs:   []string{"write=token"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown mode",
+			pairs:   []string{"ready=basic"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := platformhttp.ParseOperationalAuthModes(tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got modes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_OperationalAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{&x509.Certificate{}}}}
+
+	tests := []struct {
+		name  string
+		auth  *platformhttp.OperationalAuth
+		path  string
+		token string
+		tls   *tls.ConnectionState
+		want  int
+	}{
+		{
+			name: "no authentication by default",
+			path: platformhttp.MetricsPath,
+			want: http.StatusOK,
+		},
+		{
+			name: "unconfigured endpoint",
+			auth: &platformhttp.OperationalAuth{Modes: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.DebugPath: platformhttp.OperationalAuthToken,
+			}},
+			path: platformhttp.HealthPath,
+			want: http.StatusOK,
+		},
+		{
+			name: "token required",
+			auth: &platformhttp.OperationalAuth{Modes: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.DebugPath: platformhttp.OperationalAuthToken,
+			}},
+			path: platformhttp.DebugPath + "/pprof",
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "token provided",
+			auth: &platformhttp.OperationalAuth{Modes: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.DebugPath: platformhttp.OperationalAuthToken,
+			}},
+			path:  platformhttp.DebugPath + "/pprof",
+			token: "abc123",
+			want:  http.StatusOK,
+		},
+		{
+			name: "client certificate required",
+			auth: &platformhttp.OperationalAuth{Modes: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.MetricsPath: platformhttp.OperationalAuthMTLS,
+			}},
+			path:  platformhttp.MetricsPath,
+			token: "abc123",
+			tls:   &tls.ConnectionState{},
+			want:  http.StatusUnauthorized,
+		},
+		{
+			name: "client certificate verified",
+			auth: &platformhttp.OperationalAuth{Modes: map[string]platformhttp.OperationalAuthMode{
+				platformhttp.MetricsPath: platformhttp.OperationalAuthMTLS,
+			}},
+			path: platformhttp.MetricsPath,
+			tls:  verified,
+			want: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.auth != nil {
+				tt.auth.AuthorizationService = &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						return &platform.Authorization{}, nil
+					},
+				}
+				tt.auth.SessionService = mock.NewSessionService()
+			}
+
+			h := platformhttp.NewHandler("test")
+			h.MetricsHandler = ok
+			h.HealthHandler = ok
+			h.DebugHandler = ok
+			h.OperationalAuth = tt.auth
+
+			r := httptest.NewRequest("GET", tt.path, nil)
+			r.TLS = tt.tls
+			if tt.token != "" {
+				platformhttp.SetToken(tt.token, r)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
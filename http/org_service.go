@@ -26,6 +26,8 @@ type OrgBackend struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	TaskDependencyService           influxdb.TaskDependencyService
+	SQLConnectionService            influxdb.SQLConnectionService
 }
 
 // NewOrgBackend is a datasource used by the org handler.
@@ -39,6 +41,8 @@ func NewOrgBackend(b *APIBackend) *OrgBackend {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		TaskDependencyService:           b.TaskDependencyService,
+		SQLConnectionService:            b.SQLConnectionService,
 	}
 }
 
@@ -54,6 +58,8 @@ type OrgHandler struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	TaskDependencyService           influxdb.TaskDependencyService
+	SQLConnectionService            influxdb.SQLConnectionService
 }
 
 const (
@@ -67,6 +73,7 @@ const (
 	organizationsIDSecretsPath   = "/api/v2/orgs/:id/secrets"
 	// TODO(desa): need a way to specify which secrets to delete. this should work for now
 	organizationsIDSecretsDeletePath = "/api/v2/orgs/:id/secrets/delete"
+	organizationsIDSecretsUsagePath  = "/api/v2/orgs/:id/secrets/usage"
 	organizationsIDLabelsPath        = "/api/v2/orgs/:id/labels"
 	organizationsIDLabelsIDPath      = "/api/v2/orgs/:id/labels/:lid"
 )
@@ -83,6 +90,8 @@ func NewOrgHandler(b *OrgBackend) *OrgHandler {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		TaskDependencyService:           b.TaskDependencyService,
+		SQLConnectionService:            b.SQLConnectionService,
 	}
 
 	h.HandlerFunc("POST", organizationsPath, h.handlePostOrg)
@@ -118,6 +127,7 @@ func NewOrgHandler(b *OrgBackend) *OrgHandler {
 	h.HandlerFunc("PATCH", organizationsIDSecretsPath, h.handlePatchSecrets)
 	// TODO(desa): need a way to specify which secrets to delete. this should work for now
 	h.HandlerFunc("POST", organizationsIDSecretsDeletePath, h.handleDeleteSecrets)
+	h.HandlerFunc("GET", organizationsIDSecretsUsagePath, h.handleGetSecretsUsage)
 
 	labelBackend := &LabelBackend{
 		Logger:       b.Logger.With(zap.String("handler", "label")),
@@ -194,6 +204,22 @@ func newSecretsResponse(orgID influxdb.ID, ks []string) *secretsResponse {
 	}
 }
 
+type secretsUsageResponse struct {
+	Links  map[string]string       `json:"links"`
+	Usages []*influxdb.SecretUsage `json:"usages"`
+}
+
+func newSecretsUsageResponse(orgID influxdb.ID, us []*influxdb.SecretUsage) *secretsUsageResponse {
+	return &secretsUsageResponse{
+		Links: map[string]string{
+			"org":     fmt.Sprintf("/api/v2/orgs/%s", orgID),
+			"secrets": fmt.Sprintf("/api/v2/orgs/%s/secrets", orgID),
+			"self":    fmt.Sprintf("/api/v2/orgs/%s/secrets/usage", orgID),
+		},
+		Usages: us,
+	}
+}
+
 // handlePostOrg is the HTTP handler for the POST /api/v2/orgs route.
 func (h *OrgHandler) handlePostOrg(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -465,6 +491,28 @@ func decodeGetSecretsRequest(ctx context.Context, r *http.Request) (*getSecretsR
 	return req, nil
 }
 
+// handleGetSecretsUsage is the HTTP handler for the GET /api/v2/orgs/:id/secrets/usage route.
+func (h *OrgHandler) handleGetSecretsUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetSecretsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	us, err := influxdb.FindSecretUsages(ctx, req.orgID, h.SecretService, h.TaskDependencyService, h.SQLConnectionService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSecretsUsageResponse(req.orgID, us)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
 // handleGetPatchSecrets is the HTTP handler for the PATCH /api/v2/orgs/:id/secrets route.
 func (h *OrgHandler) handlePatchSecrets(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -29,6 +29,8 @@ func NewMockOrgBackend() *OrgBackend {
 		SecretService:                   mock.NewSecretService(),
 		LabelService:                    mock.NewLabelService(),
 		UserService:                     mock.NewUserService(),
+		TaskDependencyService:           mock.NewTaskDependencyService(),
+		SQLConnectionService:            mock.NewSQLConnectionService(),
 	}
 }
 
@@ -167,6 +169,72 @@ func TestSecretService_handleGetSecrets(t *testing.T) {
 	}
 }
 
+func TestSecretService_handleGetSecretsUsage(t *testing.T) {
+	orgBackend := NewMockOrgBackend()
+	orgBackend.SecretService = &mock.SecretService{
+		GetSecretKeysFn: func(ctx context.Context, orgID platform.ID) ([]string, error) {
+			return []string{"api-token", "unused"}, nil
+		},
+	}
+	depsSvc := mock.NewTaskDependencyService()
+	depsSvc.FindTaskDependenciesFn = func(ctx context.Context, filter platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error) {
+		return []*platform.TaskDependencies{
+			{
+				TaskID:       2,
+				OrgID:        1,
+				Dependencies: []platform.TaskDependency{{Type: platform.TaskDependencySecret, Name: "api-token"}},
+			},
+		}, nil
+	}
+	orgBackend.TaskDependencyService = depsSvc
+	connSvc := mock.NewSQLConnectionService()
+	connSvc.FindSQLConnectionsFn = func(ctx context.Context, filter platform.SQLConnectionFilter) ([]*platform.SQLConnection, error) {
+		return []*platform.SQLConnection{{ID: 3, OrgID: 1, Name: "metrics", SecretKey: "metrics-dsn"}}, nil
+	}
+	orgBackend.SQLConnectionService = connSvc
+	h := NewOrgHandler(orgBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/orgs/0000000000000001/secrets/usage", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetSecretsUsage() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `
+{
+  "links": {
+    "org": "/api/v2/orgs/0000000000000001",
+    "secrets": "/api/v2/orgs/0000000000000001/secrets",
+    "self": "/api/v2/orgs/0000000000000001/secrets/usage"
+  },
+  "usages": [
+    {
+      "key": "api-token",
+      "exists": true,
+      "references": [{"type": "task", "id": "0000000000000002"}]
+    },
+    {
+      "key": "metrics-dsn",
+      "exists": false,
+      "references": [{"type": "sqlConnection", "id": "0000000000000003", "name": "metrics"}]
+    },
+    {
+      "key": "unused",
+      "exists": true,
+      "references": []
+    }
+  ]
+}
+`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("handleGetSecretsUsage() = ***%s***", diff)
+	}
+}
+
 func TestSecretService_handlePatchSecrets(t *testing.T) {
 	type fields struct {
 		SecretService platform.SecretService
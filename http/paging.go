@@ -2,10 +2,13 @@ package http
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	platform "github.com/influxdata/influxdb"
 )
@@ -24,6 +27,15 @@ func decodeFindOptions(ctx context.Context, r *http.Request) (*platform.FindOpti
 		opts.Offset = o
 	}
 
+	// The cursor of a Link header takes precedence over the offset.
+	c, err := decodePageCursor(r)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		opts.Offset = c.Offset
+	}
+
 	if limit := qp.Get("limit"); limit != "" {
 		l, err := strconv.Atoi(limit)
 		if err != nil {
@@ -111,3 +123,86 @@ func newPagingLinks(basePath string, opts platform.FindOptions, f platform.Pagin
 
 	return links
 }
+
+// pageCursor is the position of a page of a list. It is sent to clients as an opaque cursor,
+// so that the lists paged by offset and those paged after the ID of the last item of a page
+// are paged the same way.
+type pageCursor struct {
+	Offset int          `json:"offset,omitempty"`
+	After  *platform.ID `json:"after,omitempty"`
+}
+
+func (c pageCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageCursor returns the cursor of the cursor query param of r, or nil if there is none.
+func decodePageCursor(r *http.Request) (*pageCursor, error) {
+	cursor := r.URL.Query().Get("cursor")
+	if cursor == "" {
+		return nil, nil
+	}
+
+	invalid := &platform.Error{
+		Code: platform.EInvalid,
+		Msg:  "invalid cursor",
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	c := &pageCursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.Offset < 0 || (c.After != nil && !c.After.Valid()) {
+		return nil, invalid
+	}
+	return c, nil
+}
+
+// setPagingLinkHeader sets the Link header of the response to r to the next and previous pages of the list,
+// with the query params of r but the position, which is the cursor of the page.
+func setPagingLinkHeader(w http.ResponseWriter, r *http.Request, next, prev *pageCursor) {
+	var links []string
+	for _, l := range []struct {
+		rel    string
+		cursor *pageCursor
+	}{
+		{rel: "next", cursor: next},
+		{rel: "prev", cursor: prev},
+	} {
+		if l.cursor == nil {
+			continue
+		}
+
+		values := r.URL.Query()
+		values.Del("offset")
+		values.Del("after")
+		values.Set("cursor", l.cursor.String())
+		u := url.URL{
+			Path:     r.URL.Path,
+			RawQuery: values.Encode(),
+		}
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", u.String(), l.rel))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// setOffsetPagingLinkHeader sets the Link header of a response to a list paged by the offset of opts.
+// num is the number of returned results.
+func setOffsetPagingLinkHeader(w http.ResponseWriter, r *http.Request, opts platform.FindOptions, num int) {
+	var next, prev *pageCursor
+	if opts.Limit > 0 && num >= opts.Limit {
+		next = &pageCursor{Offset: opts.Offset + opts.Limit}
+	}
+	if opts.Offset > 0 {
+		prevOffset := opts.Offset - opts.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev = &pageCursor{Offset: prevOffset}
+	}
+	setPagingLinkHeader(w, r, next, prev)
+}
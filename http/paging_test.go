@@ -2,7 +2,9 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
@@ -194,3 +196,93 @@ func TestPaging_newPagingLinks(t *testing.T) {
 		})
 	}
 }
+
+func TestPaging_setOffsetPagingLinkHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		num     int
+		offsets map[string]int
+	}{
+		{
+			name:    "next and prev pages",
+			url:     "http://any.url/api/v2/buckets?name=name&offset=10&limit=10",
+			num:     10,
+			offsets: map[string]int{"next": 20, "prev": 0},
+		},
+		{
+			name:    "last page",
+			url:     "http://any.url/api/v2/buckets?name=name&offset=10&limit=10",
+			num:     5,
+			offsets: map[string]int{"prev": 0},
+		},
+		{
+			name:    "first page",
+			url:     "http://any.url/api/v2/buckets?name=name&limit=10",
+			num:     10,
+			offsets: map[string]int{"next": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			opts, err := decodeFindOptions(context.Background(), r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			setOffsetPagingLinkHeader(w, r, *opts, tt.num)
+
+			links := map[string]string{}
+			for _, l := range strings.Split(w.Header().Get("Link"), ", ") {
+				if l == "" {
+					continue
+				}
+				var u, rel string
+				if _, err := fmt.Sscanf(l, "<%s rel=%q", &u, &rel); err != nil {
+					t.Fatalf("invalid link %q: %v", l, err)
+				}
+				links[rel] = strings.TrimSuffix(u, ">;")
+			}
+			if len(links) != len(tt.offsets) {
+				t.Fatalf("got links %v, want %v", links, tt.offsets)
+			}
+
+			// Following a link pages the list from the offset of its cursor, with the other query params.
+			for rel, offset := range tt.offsets {
+				next := httptest.NewRequest("GET", "http://any.url"+links[rel], nil)
+				if next.URL.Path != "/api/v2/buckets" || next.URL.Query().Get("name") != "name" || next.URL.Query().Get("offset") != "" {
+					t.Errorf("unexpected %s link %s", rel, links[rel])
+				}
+				opts, err := decodeFindOptions(context.Background(), next)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if opts.Offset != offset || opts.Limit != 10 {
+					t.Errorf("%s link has offset %d and limit %d, want %d and 10", rel, opts.Offset, opts.Limit, offset)
+				}
+			}
+		})
+	}
+}
+
+func TestPaging_decodePageCursor(t *testing.T) {
+	id := platform.ID(10)
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/tasks?cursor="+pageCursor{After: &id}.String(), nil)
+	c, err := decodePageCursor(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.After == nil || *c.After != id {
+		t.Errorf("unexpected cursor %+v", c)
+	}
+
+	// The cursors are not base64, and of a negative offset.
+	for _, cursor := range []string{"nope!", "eyJvZmZzZXQiOi0xfQ"} {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/tasks?cursor="+cursor, nil)
+		if _, err := decodePageCursor(r); platform.ErrorCode(err) != platform.EInvalid {
+			t.Errorf("expected cursor %q to be invalid, got %v", cursor, err)
+		}
+	}
+}
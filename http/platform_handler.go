@@ -12,22 +12,24 @@ type PlatformHandler struct {
 	AssetHandler *AssetHandler
 	DocsHandler  http.HandlerFunc
 	APIHandler   http.Handler
-}
-
-func setCORSResponseHeaders(w http.ResponseWriter, r *http.Request) {
-	if origin := r.Header.Get("Origin"); origin != "" {
-		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
-	}
+	CORS         *CORS
 }
 
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
 func NewPlatformHandler(b *APIBackend) *PlatformHandler {
 	h := NewAuthenticationHandler()
 	h.Handler = NewAPIHandler(b)
+	if b.AuditRecorder != nil {
+		h.Handler = NewAuditHandler(h.Handler, b.AuditRecorder)
+	}
+	// The requests over the limits of their token are rejected before they are audited.
+	if b.RequestLimiter != nil {
+		h.Handler = NewRequestLimitHandler(h.Handler, b.RequestLimiter)
+	}
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
+	h.ActivityService = b.ActivityService
+	h.CertificateAuthorizations = b.CertificateAuthorizations
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
@@ -35,6 +37,7 @@ func NewPlatformHandler(b *APIBackend) *PlatformHandler {
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", "/api/v2/announcements/active")
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
@@ -43,12 +46,13 @@ func NewPlatformHandler(b *APIBackend) *PlatformHandler {
 		AssetHandler: assetHandler,
 		DocsHandler:  Redoc("/api/v2/swagger.json"),
 		APIHandler:   h,
+		CORS:         b.cors(),
 	}
 }
 
 // ServeHTTP delegates a request to the appropriate subhandler.
 func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	setCORSResponseHeaders(w, r)
+	h.CORS.setResponseHeaders(w, r)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -62,6 +66,8 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// of the platform API.
 	if !strings.HasPrefix(r.URL.Path, "/v1") &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
+		r.URL.Path != opentsdbPutPath &&
+		r.URL.Path != influxQLQueryPath &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.AssetHandler.ServeHTTP(w, r)
 		return
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/influxdata/flux"
@@ -20,9 +21,19 @@ import (
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
+	iql "github.com/influxdata/influxdb/query/influxql"
 	"github.com/influxdata/influxql"
 )
 
+const (
+	// QueryTagHeader is the header identifying the application, or the dashboard cell, running a query,
+	// unless its request sets a tag.
+	QueryTagHeader = "X-Influx-Query-Tag"
+
+	// maxQueryTagLength is the length, in bytes, of the longest query tag.
+	maxQueryTagLength = 128
+)
+
 // QueryRequest is a flux query request.
 type QueryRequest struct {
 	Extern  *ast.File    `json:"extern,omitempty"`
@@ -32,16 +43,38 @@ type QueryRequest struct {
 	Type    string       `json:"type"`
 	Dialect QueryDialect `json:"dialect"`
 
+	// Tag identifies the application, or the dashboard cell, running the query, to attribute its load.
+	// It defaults to the QueryTagHeader of the request.
+	Tag string `json:"tag,omitempty"`
+
+	// DB, RP and Cluster are the default database, retention policy and cluster of an influxql query.
+	// They are mapped to a bucket of the organization of the query by its DBRP mappings.
+	DB      string `json:"db,omitempty"`
+	RP      string `json:"rp,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+
 	Org *influxdb.Organization `json:"-"`
+
+	// DBRPMappingService maps the databases and retention policies of influxql queries to buckets.
+	DBRPMappingService influxdb.DBRPMappingService `json:"-"`
 }
 
 // QueryDialect is the formatting options for the query response.
 type QueryDialect struct {
+	// Format is the format of the results: csv, the default, json for JSON lines, or msgpack for MessagePack.
+	// It defaults to the format asked for by the Accept header of the request.
+	// Only the CSV format has a header, a delimiter, annotations and limits.
+	Format         string   `json:"format,omitempty"`
 	Header         *bool    `json:"header"`
 	Delimiter      string   `json:"delimiter"`
 	CommentPrefix  string   `json:"commentPrefix"`
 	DateTimeFormat string   `json:"dateTimeFormat"`
 	Annotations    []string `json:"annotations"`
+
+	// MaxRows and MaxBytes truncate the results once they reach that many rows or bytes.
+	// Zero is no limit.
+	MaxRows  int   `json:"maxRows,omitempty"`
+	MaxBytes int64 `json:"maxBytes,omitempty"`
 }
 
 // WithDefaults adds default values to the request.
@@ -75,10 +108,32 @@ func (r QueryRequest) Validate() error {
 		}
 	}
 
-	if r.Type != "flux" {
+	switch r.Type {
+	case "flux":
+	case "influxql":
+		if r.Spec != nil || r.AST != nil || r.Extern != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "influxql queries cannot specify a spec, an AST or external declarations",
+			}
+		}
+	default:
 		return fmt.Errorf(`unknown query type: %s`, r.Type)
 	}
 
+	switch r.Dialect.Format {
+	case "", FormatCSV:
+	case FormatJSON, FormatMsgpack:
+		if r.Type == "influxql" {
+			return fmt.Errorf("invalid dialect format: the results of influxql queries cannot be encoded as %s", r.Dialect.Format)
+		}
+		if r.Dialect.MaxRows > 0 || r.Dialect.MaxBytes > 0 {
+			return fmt.Errorf("invalid dialect format: the results can only be limited in the csv format")
+		}
+	default:
+		return fmt.Errorf(`unknown dialect format: %s`, r.Dialect.Format)
+	}
+
 	if len(r.Dialect.CommentPrefix) > 1 {
 		return fmt.Errorf("invalid dialect comment prefix: must be length 0 or 1")
 	}
@@ -106,6 +161,23 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf(`unknown dialect date time format: %s`, r.Dialect.DateTimeFormat)
 	}
 
+	if r.Dialect.MaxRows < 0 {
+		return fmt.Errorf("invalid dialect max rows: must not be negative")
+	}
+
+	if r.Dialect.MaxBytes < 0 {
+		return fmt.Errorf("invalid dialect max bytes: must not be negative")
+	}
+
+	if len(r.Tag) > maxQueryTagLength {
+		return fmt.Errorf("invalid tag: must be at most %d bytes long", maxQueryTagLength)
+	}
+	for _, c := range r.Tag {
+		if !unicode.IsPrint(c) {
+			return fmt.Errorf("invalid tag: must only contain printable characters")
+		}
+	}
+
 	return nil
 }
 
@@ -220,6 +292,9 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	if err := r.Validate(); err != nil {
 		return nil, err
 	}
+	if r.Type == "influxql" {
+		return r.influxQLProxyRequest(now())
+	}
 	// Query is preferred over spec
 	var compiler flux.Compiler
 	if r.Query != "" {
@@ -250,6 +325,22 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		}
 	}
 
+	pr := &query.ProxyRequest{
+		Request: query.Request{
+			OrganizationID: r.Org.ID,
+			Compiler:       compiler,
+			Tag:            r.Tag,
+		},
+	}
+	switch r.Dialect.Format {
+	case FormatJSON:
+		pr.Dialect = &JSONDialect{}
+		return pr, nil
+	case FormatMsgpack:
+		pr.Dialect = &MsgpackDialect{}
+		return pr, nil
+	}
+
 	delimiter, _ := utf8.DecodeRuneInString(r.Dialect.Delimiter)
 
 	noHeader := false
@@ -259,25 +350,57 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 
 	// TODO(nathanielc): Use commentPrefix and dateTimeFormat
 	// once they are supported.
+	dialect := csv.Dialect{
+		ResultEncoderConfig: csv.ResultEncoderConfig{
+			NoHeader:    noHeader,
+			Delimiter:   delimiter,
+			Annotations: r.Dialect.Annotations,
+		},
+	}
+
+	pr.Dialect = &dialect
+	if r.Dialect.MaxRows > 0 || r.Dialect.MaxBytes > 0 {
+		pr.Dialect = &LimitedDialect{
+			Dialect:  dialect,
+			MaxRows:  r.Dialect.MaxRows,
+			MaxBytes: r.Dialect.MaxBytes,
+		}
+	}
+	return pr, nil
+}
+
+// influxQLProxyRequest returns a request to proxy the influxql query r,
+// whose results are encoded like those of InfluxDB 1.x.
+func (r QueryRequest) influxQLProxyRequest(now time.Time) (*query.ProxyRequest, error) {
+	if r.DBRPMappingService == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "influxql queries are not supported",
+		}
+	}
+
+	compiler := iql.NewCompiler(r.DBRPMappingService)
+	compiler.Cluster = r.Cluster
+	compiler.DB = r.DB
+	compiler.RP = r.RP
+	compiler.Query = r.Query
+	compiler.Now = &now
+	compiler.OrganizationID = r.Org.ID
+
 	return &query.ProxyRequest{
 		Request: query.Request{
 			OrganizationID: r.Org.ID,
 			Compiler:       compiler,
+			Tag:            r.Tag,
 		},
-		Dialect: &csv.Dialect{
-			ResultEncoderConfig: csv.ResultEncoderConfig{
-				NoHeader:    noHeader,
-				Delimiter:   delimiter,
-				Annotations: r.Dialect.Annotations,
-			},
-		},
+		Dialect: &iql.Dialect{},
 	}, nil
 }
 
 // QueryRequestFromProxyRequest converts a query.ProxyRequest into a QueryRequest.
 // The ProxyRequest must contain supported compilers and dialects otherwise an error occurs.
 func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error) {
-	qr := new(QueryRequest)
+	qr := &QueryRequest{Tag: req.Request.Tag}
 	switch c := req.Request.Compiler.(type) {
 	case lang.FluxCompiler:
 		qr.Type = "flux"
@@ -288,6 +411,12 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 	case lang.ASTCompiler:
 		qr.Type = "flux"
 		qr.AST = c.AST
+	case *iql.Compiler:
+		qr.Type = "influxql"
+		qr.Query = c.Query
+		qr.DB = c.DB
+		qr.RP = c.RP
+		qr.Cluster = c.Cluster
 	default:
 		return nil, fmt.Errorf("unsupported compiler %T", c)
 	}
@@ -299,6 +428,21 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *LimitedDialect:
+		var header = !d.ResultEncoderConfig.NoHeader
+		qr.Dialect.Header = &header
+		qr.Dialect.Delimiter = string(d.ResultEncoderConfig.Delimiter)
+		qr.Dialect.CommentPrefix = "#"
+		qr.Dialect.DateTimeFormat = "RFC3339"
+		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+		qr.Dialect.MaxRows = d.MaxRows
+		qr.Dialect.MaxBytes = d.MaxBytes
+	case *JSONDialect:
+		qr.Dialect.Format = FormatJSON
+	case *MsgpackDialect:
+		qr.Dialect.Format = FormatMsgpack
+	case *iql.Dialect:
+		// The results of influxql queries are always encoded like those of InfluxDB 1.x.
 	default:
 		return nil, fmt.Errorf("unsupported dialect %T", d)
 	}
@@ -332,6 +476,12 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 		}
 	}
 
+	if req.Tag == "" {
+		req.Tag = r.Header.Get(QueryTagHeader)
+	}
+	if req.Dialect.Format == "" {
+		req.Dialect.Format = formatFromAccept(r.Header.Get("Accept"))
+	}
 	req = req.WithDefaults()
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -341,27 +491,35 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 	return &req, err
 }
 
-func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService) (*query.ProxyRequest, error) {
+func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService, dbrps influxdb.DBRPMappingService) (*query.ProxyRequest, error) {
 	req, err := decodeQueryRequest(ctx, r, svc)
 	if err != nil {
 		return nil, err
 	}
+	req.DBRPMappingService = dbrps
 
 	pr, err := req.ProxyRequest()
 	if err != nil {
 		return nil, err
 	}
 
-	var token *influxdb.Authorization
+	token, err := queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, err
+	}
+
+	pr.Request.Authorization = token
+	return pr, nil
+}
+
+// queryAuthorization returns the authorization that queries of the organization run with on behalf of auth.
+func queryAuthorization(auth influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
 	switch a := auth.(type) {
 	case *influxdb.Authorization:
-		token = a
+		return a, nil
 	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	default:
-		return pr, influxdb.ErrAuthorizerNotSupported
+		return nil, influxdb.ErrAuthorizerNotSupported
 	}
-
-	pr.Request.Authorization = token
-	return pr, nil
 }
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/resultcache"
+	"go.uber.org/zap"
+)
+
+const (
+	fluxBatchPath = "/api/v2/query/batch"
+
+	// DefaultMaxBatchQueries is the default maximum number of queries in a batch.
+	DefaultMaxBatchQueries = 50
+	// DefaultBatchConcurrency is the default number of queries of a batch that run at the same time.
+	DefaultBatchConcurrency = 4
+)
+
+// BatchQueryRequest is a request to run several named flux queries at once.
+type BatchQueryRequest struct {
+	Queries []NamedQueryRequest `json:"queries"`
+}
+
+// NamedQueryRequest is a query of a batch, named so that its results can be told apart from the others.
+type NamedQueryRequest struct {
+	Name string `json:"name"`
+	QueryRequest
+}
+
+// Validate checks the batch and returns an error if the batch or any of its queries is invalid.
+func (r BatchQueryRequest) Validate(maxQueries int) error {
+	if len(r.Queries) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "batch requires at least one query",
+		}
+	}
+	if maxQueries > 0 && len(r.Queries) > maxQueries {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("batch has %d queries, more than the maximum of %d", len(r.Queries), maxQueries),
+		}
+	}
+
+	names := make(map[string]bool, len(r.Queries))
+	for _, q := range r.Queries {
+		if q.Name == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "every query of a batch requires a name",
+			}
+		}
+		if names[q.Name] {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("query name %q is used more than once", q.Name),
+			}
+		}
+		names[q.Name] = true
+
+		if err := q.Validate(); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid query %q", q.Name),
+				Err:  err,
+			}
+		}
+		// The results of the queries of a batch are encoded as CSV, which influxql queries are not.
+		if q.Type == "influxql" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("query %q of a batch cannot be an influxql query", q.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// namedProxyRequest is a query of a batch, ready to run.
+type namedProxyRequest struct {
+	name string
+	req  *query.ProxyRequest
+}
+
+func decodeBatchQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService, now time.Time, maxQueries int) ([]namedProxyRequest, error) {
+	var req BatchQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+	for i := range req.Queries {
+		if req.Queries[i].Tag == "" {
+			req.Queries[i].Tag = r.Header.Get(QueryTagHeader)
+		}
+		req.Queries[i].QueryRequest = req.Queries[i].WithDefaults()
+	}
+	if err := req.Validate(maxQueries); err != nil {
+		return nil, err
+	}
+
+	org, err := queryOrganization(ctx, r, svc)
+	if err != nil {
+		return nil, err
+	}
+	token, err := queryAuthorization(auth, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every query of the batch is compiled at the same time,
+	// so that relative time ranges cover the same period in every result.
+	nowFn := func() time.Time { return now }
+	prs := make([]namedProxyRequest, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		q.Org = org
+		pr, err := q.proxyRequest(nowFn)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid query %q", q.Name),
+				Err:  err,
+			}
+		}
+		pr.Request.Authorization = token
+		prs = append(prs, namedProxyRequest{name: q.Name, req: pr})
+	}
+	return prs, nil
+}
+
+// batchResult is the outcome of a query of a batch.
+type batchResult struct {
+	buf bytes.Buffer
+	err error
+}
+
+// handleQueryBatch runs the queries of a batch and writes their results as the parts of a multipart/mixed response,
+// in the order of the queries in the request.
+// Each part is named after its query, and holds either the CSV results of the query or the error that it failed with.
+func (h *FluxHandler) handleQueryBatch(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	reqs, err := decodeBatchQueryRequest(ctx, r, a, h.OrganizationService, h.Now(), h.MaxBatchQueries)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ctx = pcontext.SetAuthorizer(ctx, reqs[0].req.Request.Authorization)
+	if bypassQueryCache(r) {
+		ctx = resultcache.WithBypass(ctx)
+	}
+
+	concurrency := h.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// A slot is taken before a query starts, and given back once its results are written,
+	// so that at most concurrency results are running or waiting to be written at any time.
+	slots := make(chan struct{}, concurrency)
+	results := make([]batchResult, len(reqs))
+	done := make([]chan struct{}, len(reqs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	go func() {
+		for i := range reqs {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int) {
+				defer close(done[i])
+				start := h.Now()
+				_, results[i].err = h.ProxyQueryService.Query(ctx, &results[i].buf, reqs[i].req)
+				h.logSlowQuery(reqs[i].req, h.Now().Sub(start), int64(results[i].buf.Len()), results[i].err)
+			}(i)
+		}
+	}()
+
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	w.WriteHeader(http.StatusOK)
+
+	for i, req := range reqs {
+		select {
+		case <-done[i]:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := writeBatchPart(mw, req.name, &results[i]); err != nil {
+			h.Logger.Info("Error writing response to client",
+				zap.String("handler", "flux"),
+				zap.Error(err),
+			)
+			return
+		}
+		results[i].buf.Reset()
+		<-slots
+	}
+
+	if err := mw.Close(); err != nil {
+		h.Logger.Info("Error writing response to client",
+			zap.String("handler", "flux"),
+			zap.Error(err),
+		)
+	}
+}
+
+// writeBatchPart writes the results of the named query as a part of mw.
+// The results of a query that failed are replaced by its error, encoded like the error of a single query.
+func writeBatchPart(mw *multipart.Writer, name string, res *batchResult) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"name": name}))
+
+	body := res.buf.Bytes()
+	if res.err != nil {
+		code := influxdb.ErrorCode(res.err)
+		header.Set(PlatformErrorCodeHeader, code)
+		header.Set("Content-Type", "application/json; charset=utf-8")
+		var err error
+		body, err = json.Marshal(&influxdb.Error{
+			Code: code,
+			Msg:  fmt.Sprintf("query %q failed", name),
+			Err:  res.err,
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+	}
+
+	pw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = pw.Write(body)
+	return err
+}
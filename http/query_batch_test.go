@@ -0,0 +1,187 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+type batchPart struct {
+	Name        string
+	ContentType string
+	ErrorCode   string
+	Body        string
+}
+
+func readBatchParts(t *testing.T, w *httptest.ResponseRecorder) []batchPart {
+	t.Helper()
+
+	mt, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != "multipart/mixed" {
+		t.Fatalf("got content type %q, want multipart/mixed: %s", mt, w.Body.String())
+	}
+
+	var parts []batchPart
+	mr := multipart.NewReader(w.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, disposition, err := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, batchPart{
+			Name:        disposition["name"],
+			ContentType: p.Header.Get("Content-Type"),
+			ErrorCode:   p.Header.Get(PlatformErrorCodeHeader),
+			Body:        string(body),
+		})
+	}
+}
+
+func TestFluxHandler_handleQueryBatch(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	org := &platform.Organization{ID: platform.ID(1), Name: "org"}
+	auth := &platform.Authorization{ID: platform.ID(2), OrgID: org.ID}
+
+	tests := []struct {
+		name       string
+		body       string
+		maxQueries int
+		wantStatus int
+		want       []batchPart
+	}{
+		{
+			name: "results in the order of the queries",
+			body: `{"queries": [
+				{"name": "cpu", "query": "from(bucket: \"cpu\")"},
+				{"name": "broken", "query": "from(bucket: \"fail\")"},
+				{"name": "mem", "query": "from(bucket: \"mem\")"}
+			]}`,
+			wantStatus: http.StatusOK,
+			want: []batchPart{
+				{Name: "cpu", ContentType: "text/csv; charset=utf-8", Body: `from(bucket: "cpu")`},
+				{Name: "broken", ContentType: "application/json; charset=utf-8", ErrorCode: platform.EInternal, Body: `{"code":"internal error","message":"query \"broken\" failed","error":"query failed"}`},
+				{Name: "mem", ContentType: "text/csv; charset=utf-8", Body: `from(bucket: "mem")`},
+			},
+		},
+		{
+			name:       "no queries",
+			body:       `{"queries": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate names",
+			body: `{"queries": [
+				{"name": "cpu", "query": "from(bucket: \"cpu\")"},
+				{"name": "cpu", "query": "from(bucket: \"mem\")"}
+			]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing name",
+			body:       `{"queries": [{"query": "from(bucket: \"cpu\")"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "too many queries",
+			body: `{"queries": [
+				{"name": "cpu", "query": "from(bucket: \"cpu\")"},
+				{"name": "mem", "query": "from(bucket: \"mem\")"}
+			]}`,
+			maxQueries: 1,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs := &mock.ProxyQueryService{
+				QueryFn: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+					if req.Request.Authorization != auth {
+						t.Errorf("query has authorization %v, want %v", req.Request.Authorization, auth)
+					}
+					if req.Request.OrganizationID != org.ID {
+						t.Errorf("query has organization %v, want %v", req.Request.OrganizationID, org.ID)
+					}
+					c, ok := req.Request.Compiler.(lang.ASTCompiler)
+					if !ok {
+						return flux.Statistics{}, errors.New("unexpected compiler")
+					}
+					if !c.Now.Equal(now) {
+						t.Errorf("query compiled at %v, want %v", c.Now, now)
+					}
+
+					script := ast.Format(c.AST.Files[0].Body[0])
+					if strings.Contains(script, "fail") {
+						_, _ = io.WriteString(w, "partial")
+						return flux.Statistics{}, errors.New("query failed")
+					}
+					_, err := io.WriteString(w, script)
+					return flux.Statistics{}, err
+				},
+			}
+
+			h := NewFluxHandler(&FluxBackend{
+				Logger:            zap.NewNop(),
+				ProxyQueryService: qs,
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return org, nil
+					},
+				},
+			})
+			h.Now = func() time.Time { return now }
+			h.BatchConcurrency = 2
+			if tt.maxQueries > 0 {
+				h.MaxBatchQueries = tt.maxQueries
+			}
+
+			r := httptest.NewRequest("POST", "/api/v2/query/batch?orgID="+org.ID.String(), bytes.NewBufferString(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if diff := cmp.Diff(tt.want, readBatchParts(t, w)); diff != "" {
+				t.Errorf("unexpected parts -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
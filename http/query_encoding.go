@@ -0,0 +1,252 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// The formats of the results of flux queries, set by the format of the dialect of a query,
+// or negotiated with the Accept header of the request.
+const (
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+	FormatMsgpack = "msgpack"
+)
+
+// The dialect types of the formats of results other than CSV.
+const (
+	JSONDialectType    = "json"
+	MsgpackDialectType = "msgpack"
+)
+
+// formatFromAccept returns the format of the results asked for by the Accept header of a request,
+// or an empty string if it does not ask for a format other than CSV.
+// Only the media types of the row formats are matched, so that clients accepting anything,
+// or JSON for their errors, keep getting CSV.
+func formatFromAccept(accept string) string {
+	for _, mt := range strings.Split(accept, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(mt))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case "application/x-ndjson", "application/jsonl", "application/json-lines":
+			return FormatJSON
+		case "application/x-msgpack", "application/msgpack", "application/vnd.msgpack":
+			return FormatMsgpack
+		case "text/csv":
+			return FormatCSV
+		}
+	}
+	return ""
+}
+
+// JSONDialect encodes the results of a query as JSON lines: a JSON object per row of their tables,
+// with the name of the result, the index of the table in it, and the values of the columns of the row.
+// An error of the query while its results are encoded is a last object with an error member.
+type JSONDialect struct{}
+
+// Encoder returns an encoder of the results as JSON lines.
+func (d *JSONDialect) Encoder() flux.MultiResultEncoder {
+	return &rowEncoder{appendRow: appendJSONRow}
+}
+
+// DialectType returns the type of the dialect.
+func (d *JSONDialect) DialectType() flux.DialectType {
+	return JSONDialectType
+}
+
+// SetHeaders sets the content type of JSON lines.
+func (d *JSONDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+}
+
+// MsgpackDialect encodes the results of a query as a stream of MessagePack maps, one per row of their tables,
+// shaped like the objects of a JSONDialect. Times are encoded as RFC3339 strings.
+type MsgpackDialect struct{}
+
+// Encoder returns an encoder of the results as MessagePack maps.
+func (d *MsgpackDialect) Encoder() flux.MultiResultEncoder {
+	return &rowEncoder{appendRow: appendMsgpackRow}
+}
+
+// DialectType returns the type of the dialect.
+func (d *MsgpackDialect) DialectType() flux.DialectType {
+	return MsgpackDialectType
+}
+
+// SetHeaders sets the content type of MessagePack.
+func (d *MsgpackDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-msgpack")
+}
+
+// rowField is a member of the record of a row, with a nil, bool, int64, uint64, float64, string or time value.
+type rowField struct {
+	key   string
+	value interface{}
+}
+
+// rowEncoder encodes the results of a query as a record per row of their tables.
+type rowEncoder struct {
+	appendRow func(b []byte, fields []rowField) ([]byte, error)
+}
+
+func (e *rowEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	var buf []byte
+	var werr error
+	write := func(fields []rowField) error {
+		b, err := e.appendRow(buf[:0], fields)
+		if err != nil {
+			werr = err
+			return err
+		}
+		buf = b
+		_, werr = wc.Write(b)
+		return werr
+	}
+
+	for results.More() {
+		res := results.Next()
+		if err := encodeResultRows(res, write, w); err != nil {
+			if werr != nil {
+				return wc.Count(), werr
+			}
+			// The error is from the query execution, so it is encoded instead.
+			err := write([]rowField{{key: "error", value: err.Error()}})
+			return wc.Count(), err
+		}
+	}
+	if err := results.Err(); err != nil {
+		err := write([]rowField{{key: "error", value: err.Error()}})
+		return wc.Count(), err
+	}
+	return wc.Count(), nil
+}
+
+// encodeResultRows writes the rows of the tables of res, flushing w after every table.
+func encodeResultRows(res flux.Result, write func([]rowField) error, w io.Writer) error {
+	table := int64(0)
+	return res.Tables().Do(func(tbl flux.Table) error {
+		err := tbl.Do(func(cr flux.ColReader) error {
+			cols := cr.Cols()
+			fields := make([]rowField, len(cols)+2)
+			for i := 0; i < cr.Len(); i++ {
+				fields[0] = rowField{key: "result", value: res.Name()}
+				fields[1] = rowField{key: "table", value: table}
+				for j, c := range cols {
+					fields[j+2] = rowField{key: c.Label, value: rowValue(cr, i, j)}
+				}
+				if err := write(fields); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		table++
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	})
+}
+
+// rowValue returns the value of the column j of the row i of cr, or nil if it is null.
+func rowValue(cr flux.ColReader, i, j int) interface{} {
+	switch cr.Cols()[j].Type {
+	case flux.TBool:
+		if a := cr.Bools(j); a.IsValid(i) {
+			return a.Value(i)
+		}
+	case flux.TInt:
+		if a := cr.Ints(j); a.IsValid(i) {
+			return a.Value(i)
+		}
+	case flux.TUInt:
+		if a := cr.UInts(j); a.IsValid(i) {
+			return a.Value(i)
+		}
+	case flux.TFloat:
+		if a := cr.Floats(j); a.IsValid(i) {
+			return a.Value(i)
+		}
+	case flux.TString:
+		if a := cr.Strings(j); a.IsValid(i) {
+			return a.ValueString(i)
+		}
+	case flux.TTime:
+		if a := cr.Times(j); a.IsValid(i) {
+			return time.Unix(0, a.Value(i)).UTC()
+		}
+	}
+	return nil
+}
+
+// appendJSONRow appends the JSON object of a row, ending with a newline.
+// The members of the object are in the order of the columns of the row.
+func appendJSONRow(b []byte, fields []rowField) ([]byte, error) {
+	b = append(b, '{')
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, err := json.Marshal(f.key)
+		if err != nil {
+			return b, err
+		}
+		b = append(b, k...)
+		b = append(b, ':')
+
+		v := f.value
+		// NaN and infinite floats have no JSON encoding.
+		if x, ok := v.(float64); ok && (math.IsNaN(x) || math.IsInf(x, 0)) {
+			v = nil
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339Nano)
+		}
+		vb, err := json.Marshal(v)
+		if err != nil {
+			return b, err
+		}
+		b = append(b, vb...)
+	}
+	return append(b, '}', '\n'), nil
+}
+
+// appendMsgpackRow appends the MessagePack map of a row.
+func appendMsgpackRow(b []byte, fields []rowField) ([]byte, error) {
+	b = msgp.AppendMapHeader(b, uint32(len(fields)))
+	for _, f := range fields {
+		b = msgp.AppendString(b, f.key)
+		switch v := f.value.(type) {
+		case nil:
+			b = msgp.AppendNil(b)
+		case bool:
+			b = msgp.AppendBool(b, v)
+		case int64:
+			b = msgp.AppendInt64(b, v)
+		case uint64:
+			b = msgp.AppendUint64(b, v)
+		case float64:
+			b = msgp.AppendFloat64(b, v)
+		case string:
+			b = msgp.AppendString(b, v)
+		case time.Time:
+			b = msgp.AppendString(b, v.Format(time.RFC3339Nano))
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	platform "github.com/influxdata/influxdb"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func encodeTestResults(t *testing.T, d flux.Dialect, err error) []byte {
+	t.Helper()
+	results := flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+		Nm: "_result",
+		Tbls: []*executetest.Table{
+			{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a", 1.5},
+					{execute.Time(1e9), "a", nil},
+				},
+			},
+			{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "b", math.NaN()},
+				},
+			},
+		},
+		Err: err,
+	}})
+
+	var buf bytes.Buffer
+	n, encErr := d.Encoder().Encode(&buf, results)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("unexpected count of bytes %d, want %d", n, buf.Len())
+	}
+	return buf.Bytes()
+}
+
+func TestJSONDialect(t *testing.T) {
+	got := string(encodeTestResults(t, &JSONDialect{}, nil))
+	want := `{"result":"_result","table":0,"_time":"1970-01-01T00:00:00Z","host":"a","_value":1.5}
+{"result":"_result","table":0,"_time":"1970-01-01T00:00:01Z","host":"a","_value":null}
+{"result":"_result","table":1,"_time":"1970-01-01T00:00:00Z","host":"b","_value":null}
+`
+	if got != want {
+		t.Errorf("unexpected JSON lines\n%s\nwant\n%s", got, want)
+	}
+
+	got = string(encodeTestResults(t, &JSONDialect{}, errors.New("boom")))
+	if want := "{\"error\":\"boom\"}\n"; got != want {
+		t.Errorf("unexpected JSON lines of an error %q, want %q", got, want)
+	}
+}
+
+func TestMsgpackDialect(t *testing.T) {
+	b := encodeTestResults(t, &MsgpackDialect{}, nil)
+
+	var rows []map[string]interface{}
+	for len(b) > 0 {
+		n, rest, err := msgp.ReadMapHeaderBytes(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := make(map[string]interface{}, n)
+		for i := uint32(0); i < n; i++ {
+			var k string
+			var v interface{}
+			if k, rest, err = msgp.ReadStringBytes(rest); err != nil {
+				t.Fatal(err)
+			}
+			if v, rest, err = msgp.ReadIntfBytes(rest); err != nil {
+				t.Fatal(err)
+			}
+			row[k] = v
+		}
+		rows = append(rows, row)
+		b = rest
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("unexpected number of rows %d", len(rows))
+	}
+	if rows[0]["_time"] != "1970-01-01T00:00:00Z" || rows[0]["host"] != "a" || rows[0]["_value"] != 1.5 || rows[0]["table"] != int64(0) {
+		t.Errorf("unexpected first row %v", rows[0])
+	}
+	if v, ok := rows[1]["_value"]; !ok || v != nil {
+		t.Errorf("unexpected null value %v", rows[1]["_value"])
+	}
+	if rows[2]["table"] != int64(1) || rows[2]["host"] != "b" {
+		t.Errorf("unexpected row of the second table %v", rows[2])
+	}
+}
+
+func TestFormatFromAccept(t *testing.T) {
+	for accept, want := range map[string]string{
+		"":                                    "",
+		"*/*":                                 "",
+		"application/json":                    "",
+		"text/csv":                            FormatCSV,
+		"application/x-ndjson":                FormatJSON,
+		"application/json, application/jsonl": FormatJSON,
+		"application/x-msgpack;q=0.9":         FormatMsgpack,
+	} {
+		if got := formatFromAccept(accept); got != want {
+			t.Errorf("format of %q = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestQueryRequest_proxyRequest_Format(t *testing.T) {
+	org := &platform.Organization{ID: 1}
+	for _, tt := range []struct {
+		format  string
+		dialect flux.Dialect
+	}{
+		{format: FormatJSON, dialect: &JSONDialect{}},
+		{format: FormatMsgpack, dialect: &MsgpackDialect{}},
+	} {
+		req := QueryRequest{Query: `from(bucket: "b")`, Org: org, Dialect: QueryDialect{Format: tt.format}}.WithDefaults()
+		pr, err := req.ProxyRequest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pr.Dialect.DialectType() != tt.dialect.DialectType() {
+			t.Errorf("unexpected dialect %T for format %s", pr.Dialect, tt.format)
+		}
+
+		qr, err := QueryRequestFromProxyRequest(pr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if qr.Dialect.Format != tt.format {
+			t.Errorf("unexpected format %q of the request of the proxy request, want %q", qr.Dialect.Format, tt.format)
+		}
+	}
+
+	for _, req := range []QueryRequest{
+		{Query: `from(bucket: "b")`, Org: org, Dialect: QueryDialect{Format: "xml"}},
+		{Query: `from(bucket: "b")`, Org: org, Dialect: QueryDialect{Format: FormatJSON, MaxRows: 10}},
+		{Query: `SELECT * FROM cpu`, Type: "influxql", Org: org, Dialect: QueryDialect{Format: FormatMsgpack}},
+	} {
+		if _, err := req.WithDefaults().ProxyRequest(); err == nil {
+			t.Errorf("expected an error for the format %q", req.Dialect.Format)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux"
@@ -18,10 +19,13 @@ import (
 	"github.com/influxdata/flux/iocounter"
 	"github.com/influxdata/flux/parser"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/explain"
+	"github.com/influxdata/influxdb/query/resultcache"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,7 +33,8 @@ import (
 )
 
 const (
-	fluxPath = "/api/v2/query"
+	fluxPath        = "/api/v2/query"
+	fluxExplainPath = "/api/v2/query/explain"
 )
 
 // FluxBackend is all services and associated parameters required to construct
@@ -38,7 +43,10 @@ type FluxBackend struct {
 	Logger *zap.Logger
 
 	OrganizationService platform.OrganizationService
+	DBRPMappingService  platform.DBRPMappingService
 	ProxyQueryService   query.ProxyQueryService
+	ExplainService      *explain.Service
+	SlowQueryThreshold  time.Duration
 }
 
 // NewFluxBackend returns a new instance of FluxBackend.
@@ -48,6 +56,13 @@ func NewFluxBackend(b *APIBackend) *FluxBackend {
 
 		ProxyQueryService:   b.FluxService,
 		OrganizationService: b.OrganizationService,
+		DBRPMappingService:  b.DBRPMappingService,
+		SlowQueryThreshold:  b.SlowQueryThreshold,
+		ExplainService: explain.NewService(
+			authorizer.NewBucketService(b.BucketService),
+			authorizer.NewShardService(b.ShardService),
+			b.SeriesCounter,
+		),
 	}
 }
 
@@ -60,6 +75,25 @@ type FluxHandler struct {
 	Now                 func() time.Time
 	OrganizationService platform.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+
+	// DBRPMappingService maps the databases and retention policies of InfluxQL queries to buckets.
+	// InfluxQL queries are not supported without it.
+	DBRPMappingService platform.DBRPMappingService
+
+	// ExplainService plans the queries of /api/v2/query/explain without running them.
+	// Queries cannot be explained without it.
+	ExplainService *explain.Service
+
+	// MaxBatchQueries limits the number of queries in a batch.
+	MaxBatchQueries int
+	// BatchConcurrency limits the number of queries of a batch that run at the same time.
+	BatchConcurrency int
+
+	// SlowQueryThreshold, if positive, is how long a query runs for before it is logged as slow.
+	SlowQueryThreshold time.Duration
+
+	// MinStreamInterval is the shortest interval a query streamed over a WebSocket can be re-run at.
+	MinStreamInterval time.Duration
 }
 
 // NewFluxHandler returns a new handler at /api/v2/query for flux queries.
@@ -71,11 +105,23 @@ func NewFluxHandler(b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		DBRPMappingService:  b.DBRPMappingService,
+		ExplainService:      b.ExplainService,
+
+		MaxBatchQueries:    DefaultMaxBatchQueries,
+		BatchConcurrency:   DefaultBatchConcurrency,
+		SlowQueryThreshold: b.SlowQueryThreshold,
+		MinStreamInterval:  DefaultMinStreamInterval,
 	}
 
 	h.HandlerFunc("POST", fluxPath, h.handleQuery)
+	h.HandlerFunc("GET", fluxWebSocketPath, h.handleQueryWebSocket)
+	h.HandlerFunc("POST", fluxBatchPath, h.handleQueryBatch)
+	h.HandlerFunc("GET", influxQLQueryPath, h.handleInfluxQLQuery)
+	h.HandlerFunc("POST", influxQLQueryPath, h.handleInfluxQLQuery)
 	h.HandlerFunc("POST", "/api/v2/query/ast", h.postFluxAST)
 	h.HandlerFunc("POST", "/api/v2/query/analyze", h.postQueryAnalyze)
+	h.HandlerFunc("POST", fluxExplainPath, h.postQueryExplain)
 	h.HandlerFunc("POST", "/api/v2/query/spec", h.postFluxSpec)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions", h.getFluxSuggestions)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions/:name", h.getFluxSuggestion)
@@ -94,7 +140,7 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService)
+	req, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService, h.DBRPMappingService)
 	if err != nil && err != platform.ErrAuthorizerNotSupported {
 		EncodeError(ctx, err, w)
 		return
@@ -102,6 +148,9 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 	// Transform the context into one with the request's authorization.
 	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+	if bypassQueryCache(r) {
+		ctx = resultcache.WithBypass(ctx)
+	}
 
 	hd, ok := req.Dialect.(HTTPDialect)
 	if !ok {
@@ -111,7 +160,10 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	hd.SetHeaders(w)
 
 	cw := iocounter.Writer{Writer: w}
-	if _, err := h.ProxyQueryService.Query(ctx, &cw, req); err != nil {
+	start := h.Now()
+	_, err = h.ProxyQueryService.Query(ctx, &cw, req)
+	h.logSlowQuery(req, h.Now().Sub(start), cw.Count(), err)
+	if err != nil {
 		if cw.Count() == 0 {
 			// Only record the error headers IFF nothing has been written to w.
 			EncodeError(ctx, err, w)
@@ -119,11 +171,42 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 		}
 		h.Logger.Info("Error writing response to client",
 			zap.String("handler", "flux"),
+			zap.String("tag", req.Request.Tag),
 			zap.Error(err),
 		)
 	}
 }
 
+// bypassQueryCache returns true if the request asks for its queries to run even if their results are cached,
+// with a Cache-Control: no-cache header.
+func bypassQueryCache(r *http.Request) bool {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		if d := strings.ToLower(strings.TrimSpace(directive)); d == "no-cache" || d == "no-store" {
+			return true
+		}
+	}
+	return false
+}
+
+// logSlowQuery logs the query req if it ran for at least SlowQueryThreshold, with the tag attributing it,
+// the size of its response and its error, if any.
+func (h *FluxHandler) logSlowQuery(req *query.ProxyRequest, d time.Duration, size int64, err error) {
+	if h.SlowQueryThreshold <= 0 || d < h.SlowQueryThreshold {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("handler", "flux"),
+		zap.String("org", req.Request.OrganizationID.String()),
+		zap.String("tag", req.Request.Tag),
+		zap.Duration("duration", d),
+		zap.Int64("response_size", size),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	h.Logger.Warn("Slow query", fields...)
+}
+
 type langRequest struct {
 	Query string `json:"query"`
 }
@@ -199,6 +282,46 @@ func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postQueryExplain returns the physical plan of a flux query, with the reads it pushes down to
+// the storage engine and the shards and series they touch, without running it.
+func (h *FluxHandler) postQueryExplain(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	if h.ExplainService == nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "explaining queries is not supported",
+		}, w)
+		return
+	}
+
+	req, err := decodeQueryRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if req.Type != "flux" || req.Query == "" {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "only the query of a flux script can be explained",
+		}, w)
+		return
+	}
+
+	p, err := h.ExplainService.Explain(ctx, req.Org.ID, req.Query)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, p); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
 type postFluxSpecResponse struct {
 	Spec *flux.Spec `json:"spec"`
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -16,8 +17,13 @@ import (
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/lang"
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/explain"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestFluxService_Query(t *testing.T) {
@@ -292,6 +298,70 @@ func TestFluxHandler_postFluxSpec(t *testing.T) {
 	}
 }
 
+func TestFluxHandler_postQueryExplain(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+		return &platform.Organization{ID: 1, Name: "org"}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: 2, OrganizationID: 1, Name: *filter.Name}, nil
+	}
+	shards := mock.NewShardService()
+	shards.FindShardsFn = func(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+		return []*platform.Shard{{
+			ID:         "000000001-000000001",
+			BucketSize: 100,
+			MinTime:    time.Unix(0, 0).UTC(),
+			MaxTime:    time.Unix(3600, 0).UTC(),
+		}}, nil
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "explain a query",
+			body:   `{"query": "from(bucket: \"b\") |> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T00:30:00Z)"}`,
+			status: http.StatusOK,
+			want:   `"shards":["000000001-000000001"]`,
+		},
+		{
+			name:   "unbounded query",
+			body:   `{"query": "from(bucket: \"b\")"}`,
+			status: http.StatusBadRequest,
+			want:   `"code":"invalid"`,
+		},
+		{
+			name:   "influxql query",
+			body:   `{"query": "SELECT * FROM cpu", "type": "influxql"}`,
+			status: http.StatusBadRequest,
+			want:   `only the query of a flux script can be explained`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewFluxHandler(&FluxBackend{
+				Logger:              zap.NewNop(),
+				OrganizationService: orgs,
+				ExplainService:      explain.NewService(buckets, shards, nil),
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v2/query/explain?orgID=0000000000000001", bytes.NewBufferString(tt.body))
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("unexpected status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.want)) {
+				t.Errorf("expected %s in the response, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestFluxService_Check(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(HealthHandler))
 	defer ts.Close()
@@ -333,3 +403,81 @@ var crlfPattern = regexp.MustCompile(`\r?\n`)
 func toCRLF(data string) string {
 	return crlfPattern.ReplaceAllString(data, "\r\n")
 }
+
+func TestFluxHandler_handleQuery_SlowQueryLog(t *testing.T) {
+	org := &platform.Organization{ID: platform.ID(1), Name: "org"}
+	auth := &platform.Authorization{ID: platform.ID(2), OrgID: org.ID}
+
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewFluxHandler(&FluxBackend{
+		Logger: zap.New(core),
+		OrganizationService: &mock.OrganizationService{
+			FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+				return org, nil
+			},
+		},
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryFn: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				if req.Request.Tag != "dashboard/cpu" {
+					t.Errorf("query has tag %q, want dashboard/cpu", req.Request.Tag)
+				}
+				_, err := io.WriteString(w, "result")
+				return flux.Statistics{}, err
+			},
+		},
+		SlowQueryThreshold: time.Second,
+	})
+	// Each query appears to run for 1s, the threshold.
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	h.Now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	r := httptest.NewRequest("POST", "/api/v2/query?org=org", bytes.NewBufferString(`{"query": "from(bucket: \"cpu\")"}`))
+	r.Header.Set(QueryTagHeader, "dashboard/cpu")
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	entries := logs.FilterMessage("Slow query").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d slow query logs, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["tag"] != "dashboard/cpu" || fields["duration"] != time.Second || fields["response_size"] != int64(6) {
+		t.Errorf("unexpected slow query log fields %v", fields)
+	}
+
+	// Queries faster than the threshold are not logged.
+	h.SlowQueryThreshold = time.Minute
+	r = httptest.NewRequest("POST", "/api/v2/query?org=org", bytes.NewBufferString(`{"query": "from(bucket: \"cpu\")", "tag": "dashboard/cpu"}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if n := logs.FilterMessage("Slow query").Len(); n != 1 {
+		t.Errorf("got %d slow query logs, want only the first one", n)
+	}
+}
+
+func TestBypassQueryCache(t *testing.T) {
+	for _, tt := range []struct {
+		cacheControl string
+		want         bool
+	}{
+		{cacheControl: "", want: false},
+		{cacheControl: "max-age=60", want: false},
+		{cacheControl: "no-cache", want: true},
+		{cacheControl: "max-age=0, No-Store", want: true},
+	} {
+		r := httptest.NewRequest("POST", "/api/v2/query?org=org", nil)
+		if tt.cacheControl != "" {
+			r.Header.Set("Cache-Control", tt.cacheControl)
+		}
+		if got := bypassQueryCache(r); got != tt.want {
+			t.Errorf("bypassQueryCache with Cache-Control %q = %v, want %v", tt.cacheControl, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/iocounter"
+)
+
+// The reasons that the results of a LimitedDialect are truncated for.
+const (
+	TruncatedMaxRows  = "maxRows"
+	TruncatedMaxBytes = "maxBytes"
+)
+
+// LimitedDialect is a CSV dialect that stops encoding the results of a query
+// once they reach MaxRows rows or MaxBytes bytes, so that a preview of the results
+// does not need the whole query to run.
+//
+// Truncated results end with a table marking them as truncated,
+// shaped like the table of an error:
+//
+//	,truncated,reason
+//	,true,maxRows
+//
+// The limits are checked between rows, so the results may go over MaxBytes by a row.
+// A limit of zero is no limit.
+type LimitedDialect struct {
+	csv.Dialect
+	MaxRows  int   `json:"maxRows,omitempty"`
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+}
+
+// Encoder returns an encoder of the results, truncating them at the limits of the dialect.
+func (d *LimitedDialect) Encoder() flux.MultiResultEncoder {
+	return &limitedEncoder{
+		encoder:  csv.NewResultEncoder(d.ResultEncoderConfig),
+		config:   d.ResultEncoderConfig,
+		maxRows:  d.MaxRows,
+		maxBytes: d.MaxBytes,
+	}
+}
+
+// errTruncated stops the encoding of results that reached a limit.
+var errTruncated = errors.New("results truncated")
+
+type limitedEncoder struct {
+	encoder  *csv.ResultEncoder
+	config   csv.ResultEncoderConfig
+	maxRows  int
+	maxBytes int64
+}
+
+func (e *limitedEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	l := &limiter{w: wc, maxRows: e.maxRows, maxBytes: e.maxBytes}
+
+	for results.More() {
+		res := results.Next()
+		if _, err := e.encoder.Encode(wc, limitedResult{Result: res, l: l}); err != nil {
+			if l.reason != "" {
+				break
+			}
+			if flux.IsEncoderError(err) {
+				return wc.Count(), err
+			}
+			// The error is from the query execution, so it is encoded instead.
+			err := e.encoder.EncodeError(wc, err)
+			return wc.Count(), err
+		}
+		if _, err := wc.Write([]byte("\r\n")); err != nil {
+			return wc.Count(), err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	if l.reason != "" {
+		// The rest of the results are dropped, and the query is canceled once they are released.
+		err := e.encodeTruncated(wc, l.reason)
+		return wc.Count(), err
+	}
+	if err := results.Err(); err != nil {
+		err := e.encoder.EncodeError(wc, err)
+		return wc.Count(), err
+	}
+	return wc.Count(), nil
+}
+
+// encodeTruncated writes the table marking the results as truncated for reason.
+func (e *limitedEncoder) encodeTruncated(w *iocounter.Writer, reason string) error {
+	writer := stdcsv.NewWriter(w)
+	if e.config.Delimiter != 0 {
+		writer.Comma = e.config.Delimiter
+	}
+	writer.UseCRLF = true
+
+	if w.Count() > 0 {
+		writer.Write(nil)
+	}
+	for _, anno := range e.config.Annotations {
+		switch anno {
+		case "datatype":
+			writer.Write([]string{"#datatype", "boolean", "string"})
+		case "group":
+			writer.Write([]string{"#group", "true", "true"})
+		case "default":
+			writer.Write([]string{"#default", "", ""})
+		}
+	}
+	writer.Write([]string{"", "truncated", "reason"})
+	writer.Write([]string{"", "true", reason})
+	writer.Flush()
+	return writer.Error()
+}
+
+// limiter counts the rows and bytes of the results, and records the limit they reached.
+type limiter struct {
+	w        *iocounter.Writer
+	maxRows  int
+	maxBytes int64
+
+	rows   int
+	reason string
+}
+
+// full returns true if no more rows can be encoded,
+// in which case the results are truncated.
+func (l *limiter) full() bool {
+	switch {
+	case l.reason != "":
+	case l.maxRows > 0 && l.rows >= l.maxRows:
+		l.reason = TruncatedMaxRows
+	case l.maxBytes > 0 && l.w.Count() >= l.maxBytes:
+		l.reason = TruncatedMaxBytes
+	}
+	return l.reason != ""
+}
+
+type limitedResult struct {
+	flux.Result
+	l *limiter
+}
+
+func (r limitedResult) Tables() flux.TableIterator {
+	return limitedTables{TableIterator: r.Result.Tables(), l: r.l}
+}
+
+type limitedTables struct {
+	flux.TableIterator
+	l *limiter
+}
+
+func (ti limitedTables) Do(f func(flux.Table) error) error {
+	return ti.TableIterator.Do(func(tbl flux.Table) error {
+		if ti.l.full() {
+			return errTruncated
+		}
+		return f(limitedTable{Table: tbl, l: ti.l})
+	})
+}
+
+type limitedTable struct {
+	flux.Table
+	l *limiter
+}
+
+func (t limitedTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); {
+			if t.l.full() {
+				return errTruncated
+			}
+
+			n := cr.Len() - i
+			if t.l.maxRows > 0 && n > t.l.maxRows-t.l.rows {
+				n = t.l.maxRows - t.l.rows
+			}
+			if t.l.maxBytes > 0 {
+				// The bytes of a row are only known once it is encoded, so rows are encoded one by one.
+				n = 1
+			}
+
+			var err error
+			if n == cr.Len() {
+				err = f(cr)
+			} else {
+				s := &slicedColReader{ColReader: cr, start: i, stop: i + n, arrs: make([]array.Interface, len(cr.Cols()))}
+				err = f(s)
+				s.release()
+			}
+			if err != nil {
+				return err
+			}
+			t.l.rows += n
+			i += n
+		}
+		return nil
+	})
+}
+
+// slicedColReader reads the rows of a ColReader from start to stop.
+type slicedColReader struct {
+	flux.ColReader
+	start, stop int
+	arrs        []array.Interface
+}
+
+func (cr *slicedColReader) Len() int {
+	return cr.stop - cr.start
+}
+
+func (cr *slicedColReader) Bools(j int) *array.Boolean {
+	return cr.slice(j, cr.ColReader.Bools(j), func(d *array.Data) array.Interface { return array.NewBooleanData(d) }).(*array.Boolean)
+}
+
+func (cr *slicedColReader) Ints(j int) *array.Int64 {
+	return cr.slice(j, cr.ColReader.Ints(j), func(d *array.Data) array.Interface { return array.NewInt64Data(d) }).(*array.Int64)
+}
+
+func (cr *slicedColReader) UInts(j int) *array.Uint64 {
+	return cr.slice(j, cr.ColReader.UInts(j), func(d *array.Data) array.Interface { return array.NewUint64Data(d) }).(*array.Uint64)
+}
+
+func (cr *slicedColReader) Floats(j int) *array.Float64 {
+	return cr.slice(j, cr.ColReader.Floats(j), func(d *array.Data) array.Interface { return array.NewFloat64Data(d) }).(*array.Float64)
+}
+
+func (cr *slicedColReader) Strings(j int) *array.Binary {
+	return cr.slice(j, cr.ColReader.Strings(j), func(d *array.Data) array.Interface { return array.NewBinaryData(d) }).(*array.Binary)
+}
+
+func (cr *slicedColReader) Times(j int) *array.Int64 {
+	return cr.slice(j, cr.ColReader.Times(j), func(d *array.Data) array.Interface { return array.NewInt64Data(d) }).(*array.Int64)
+}
+
+// slice returns the rows of column j, slicing arr on the first read of the column.
+// The slice is made with mk, as the data of a column does not always match the type of its array.
+func (cr *slicedColReader) slice(j int, arr array.Interface, mk func(*array.Data) array.Interface) array.Interface {
+	if cr.arrs[j] == nil {
+		data := array.NewSliceData(arr.Data(), int64(cr.start), int64(cr.stop))
+		cr.arrs[j] = mk(data)
+		data.Release()
+	}
+	return cr.arrs[j]
+}
+
+func (cr *slicedColReader) release() {
+	for _, arr := range cr.arrs {
+		if arr != nil {
+			arr.Release()
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute/executetest"
+)
+
+func TestLimitedDialect_Encoder(t *testing.T) {
+	tables := func() []flux.Result {
+		return []flux.Result{
+			executetest.NewResult([]*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"a", 1.0},
+						{"a", 2.0},
+						{"a", 3.0},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"b", 4.0},
+					},
+				},
+			}),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		annotations []string
+		maxRows     int
+		maxBytes    int64
+		want        string
+	}{
+		{
+			name: "no limits",
+			want: toCRLF(`,result,table,host,_value
+,,0,a,1
+,,0,a,2
+,,0,a,3
+,,1,b,4
+
+`),
+		},
+		{
+			name:    "limit above the rows",
+			maxRows: 4,
+			want: toCRLF(`,result,table,host,_value
+,,0,a,1
+,,0,a,2
+,,0,a,3
+,,1,b,4
+
+`),
+		},
+		{
+			name:    "rows within a table",
+			maxRows: 2,
+			want: toCRLF(`,result,table,host,_value
+,,0,a,1
+,,0,a,2
+
+,truncated,reason
+,true,maxRows
+`),
+		},
+		{
+			name:    "rows at the end of a table",
+			maxRows: 3,
+			want: toCRLF(`,result,table,host,_value
+,,0,a,1
+,,0,a,2
+,,0,a,3
+
+,truncated,reason
+,true,maxRows
+`),
+		},
+		{
+			name:     "bytes",
+			maxBytes: 30,
+			want: toCRLF(`,result,table,host,_value
+,,0,a,1
+
+,truncated,reason
+,true,maxBytes
+`),
+		},
+		{
+			name:        "annotated",
+			annotations: []string{"datatype", "group", "default"},
+			maxRows:     1,
+			want: toCRLF(`#datatype,string,long,string,double
+#group,false,false,true,false
+#default,,,,
+,result,table,host,_value
+,,0,a,1
+
+#datatype,boolean,string
+#group,true,true
+#default,,
+,truncated,reason
+,true,maxRows
+`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &LimitedDialect{
+				Dialect:  csv.Dialect{ResultEncoderConfig: csv.ResultEncoderConfig{Annotations: tt.annotations}},
+				MaxRows:  tt.maxRows,
+				MaxBytes: tt.maxBytes,
+			}
+
+			var buf bytes.Buffer
+			n, err := d.Encoder().Encode(&buf, flux.NewSliceResultIterator(tables()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("got %d bytes written, want %d", n, buf.Len())
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("unexpected results -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	fluxWebSocketPath = "/api/v2/query/ws"
+
+	// DefaultMinStreamInterval is the default shortest interval a streamed query can be re-run at.
+	DefaultMinStreamInterval = time.Second
+)
+
+// StreamQueryRequest is the first message of a WebSocket query stream: the flux query to run,
+// and the interval to re-run it at, if any.
+type StreamQueryRequest struct {
+	QueryRequest
+	// Every, if set, re-runs the query at this interval until the stream is closed.
+	Every string `json:"every,omitempty"`
+}
+
+// The types of the messages of a WebSocket query stream.
+const (
+	streamMessageTable = "table"
+	streamMessageDone  = "done"
+	streamMessageError = "error"
+)
+
+// StreamMessage is a message of a WebSocket query stream. The tables of the results of a run are
+// sent as they are produced, then a done message ends the run. A run of a query re-run at an interval
+// only sends the tables that changed since the previous run; the done message tells the others.
+type StreamMessage struct {
+	Type string `json:"type"`
+	Run  int    `json:"run"`
+
+	// Result, GroupKey, Columns and Values are the table of a table message.
+	Result   string                 `json:"result,omitempty"`
+	GroupKey map[string]interface{} `json:"groupKey,omitempty"`
+	Columns  []StreamColumn         `json:"columns,omitempty"`
+	Values   [][]interface{}        `json:"values,omitempty"`
+
+	// Tables, Unchanged and Removed tell the tables of a run in its done message: the number of tables
+	// sent, the number of tables that are the same as in the previous run and were not sent again,
+	// and the tables of the previous run that are no longer in the results.
+	Tables    int           `json:"tables,omitempty"`
+	Unchanged int           `json:"unchanged,omitempty"`
+	Removed   []StreamTable `json:"removed,omitempty"`
+
+	// Code and Message are the error of an error message.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StreamColumn is a column of a streamed table.
+type StreamColumn struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+}
+
+// StreamTable identifies a table of the results of a streamed query.
+type StreamTable struct {
+	Result   string                 `json:"result"`
+	GroupKey map[string]interface{} `json:"groupKey"`
+}
+
+// handleQueryWebSocket runs a flux query over a WebSocket, pushing the tables of its results as
+// they are produced, and re-running it at the interval of the request until the client goes away.
+// The organization of the query is the org or orgID parameter of the URL, as for other queries.
+func (h *FluxHandler) handleQueryWebSocket(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	org, err := queryOrganization(ctx, r, h.OrganizationService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	token, err := queryAuthorization(a, org.ID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			h.streamQuery(pcontext.SetAuthorizer(ctx, token), ws, org, token)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// streamQuery reads the request of the stream ws and runs its query until the stream is closed.
+func (h *FluxHandler) streamQuery(ctx context.Context, ws *websocket.Conn, org *influxdb.Organization, token *influxdb.Authorization) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var req StreamQueryRequest
+	if err := websocket.JSON.Receive(ws, &req); err != nil {
+		h.sendStreamError(ws, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json",
+			Err:  err,
+		})
+		return
+	}
+	every, err := h.decodeStreamQueryRequest(&req, org)
+	if err != nil {
+		h.sendStreamError(ws, 0, err)
+		return
+	}
+
+	// The client ends the stream by closing it; it does not send anything else.
+	go func() {
+		defer cancel()
+		var msg json.RawMessage
+		for websocket.JSON.Receive(ws, &msg) == nil {
+		}
+	}()
+
+	qs := query.QueryServiceProxyBridge{ProxyQueryService: h.ProxyQueryService}
+	var prev map[string]uint64
+	for run := 1; ; run++ {
+		// Every run is compiled anew, so that relative time ranges move forward with time.
+		pr, err := req.proxyRequest(h.Now)
+		if err != nil {
+			h.sendStreamError(ws, run, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid query",
+				Err:  err,
+			})
+			return
+		}
+		pr.Request.Authorization = token
+
+		start := h.Now()
+		tables, err := h.sendStreamRun(ctx, ws, qs, &pr.Request, run, prev)
+		h.logSlowQuery(pr, h.Now().Sub(start), 0, err)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if h.sendStreamError(ws, run, err) != nil {
+				return
+			}
+		} else {
+			prev = tables
+		}
+
+		if every <= 0 {
+			return
+		}
+		select {
+		case <-time.After(every):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// decodeStreamQueryRequest validates req and returns the interval to re-run its query at.
+func (h *FluxHandler) decodeStreamQueryRequest(req *StreamQueryRequest, org *influxdb.Organization) (time.Duration, error) {
+	req.QueryRequest = req.WithDefaults()
+	if err := req.Validate(); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query",
+			Err:  err,
+		}
+	}
+	// The tables of influxql queries are not encoded like those of flux queries.
+	if req.Type != "flux" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "only flux queries can be streamed",
+		}
+	}
+	req.Org = org
+
+	if req.Every == "" {
+		return 0, nil
+	}
+	every, err := time.ParseDuration(req.Every)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid interval",
+			Err:  err,
+		}
+	}
+	min := h.MinStreamInterval
+	if min <= 0 {
+		min = DefaultMinStreamInterval
+	}
+	if every < min {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("interval %v is shorter than the minimum of %v", every, min),
+		}
+	}
+	return every, nil
+}
+
+// sendStreamRun runs the query req and sends the tables of its results that are not the same as
+// in the previous run, whose digests are prev. It returns the digests of the tables of the run.
+func (h *FluxHandler) sendStreamRun(ctx context.Context, ws *websocket.Conn, qs query.QueryService, req *query.Request, run int, prev map[string]uint64) (map[string]uint64, error) {
+	results, err := qs.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Release()
+
+	done := StreamMessage{Type: streamMessageDone, Run: run}
+	tables := make(map[string]uint64)
+	for results.More() {
+		res := results.Next()
+		err := res.Tables().Do(func(tbl flux.Table) error {
+			msg, err := newStreamTableMessage(run, res.Name(), tbl)
+			if err != nil {
+				return err
+			}
+			key := streamTableKey(msg.Result, msg.GroupKey)
+			digest, err := streamTableDigest(msg)
+			if err != nil {
+				return err
+			}
+			tables[key] = digest
+
+			if d, ok := prev[key]; ok && d == digest {
+				done.Unchanged++
+				return nil
+			}
+			done.Tables++
+			return websocket.JSON.Send(ws, msg)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return nil, err
+	}
+
+	for key := range prev {
+		if _, ok := tables[key]; !ok {
+			var t StreamTable
+			if err := json.Unmarshal([]byte(key), &t); err != nil {
+				return nil, err
+			}
+			done.Removed = append(done.Removed, t)
+		}
+	}
+	return tables, websocket.JSON.Send(ws, done)
+}
+
+func (h *FluxHandler) sendStreamError(ws *websocket.Conn, run int, err error) error {
+	msg := StreamMessage{
+		Type:    streamMessageError,
+		Run:     run,
+		Code:    influxdb.ErrorCode(err),
+		Message: err.Error(),
+	}
+	if serr := websocket.JSON.Send(ws, msg); serr != nil {
+		h.Logger.Info("Error writing response to client",
+			zap.String("handler", "flux"),
+			zap.Error(serr),
+		)
+		return serr
+	}
+	return nil
+}
+
+// newStreamTableMessage returns the table message of tbl, a table of the result named result.
+func newStreamTableMessage(run int, result string, tbl flux.Table) (*StreamMessage, error) {
+	msg := &StreamMessage{
+		Type:     streamMessageTable,
+		Run:      run,
+		Result:   result,
+		GroupKey: make(map[string]interface{}),
+		Columns:  make([]StreamColumn, 0, len(tbl.Cols())),
+		Values:   [][]interface{}{},
+	}
+	key := tbl.Key()
+	for j, c := range key.Cols() {
+		if key.IsNull(j) {
+			msg.GroupKey[c.Label] = nil
+			continue
+		}
+		switch c.Type {
+		case flux.TBool:
+			msg.GroupKey[c.Label] = key.ValueBool(j)
+		case flux.TInt:
+			msg.GroupKey[c.Label] = key.ValueInt(j)
+		case flux.TUInt:
+			msg.GroupKey[c.Label] = key.ValueUInt(j)
+		case flux.TFloat:
+			msg.GroupKey[c.Label] = key.ValueFloat(j)
+		case flux.TString:
+			msg.GroupKey[c.Label] = key.ValueString(j)
+		case flux.TTime:
+			msg.GroupKey[c.Label] = key.ValueTime(j).Time().UTC()
+		}
+	}
+	for _, c := range tbl.Cols() {
+		msg.Columns = append(msg.Columns, StreamColumn{Label: c.Label, Type: c.Type.String()})
+	}
+
+	err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			row := make([]interface{}, len(cr.Cols()))
+			for j, c := range cr.Cols() {
+				switch c.Type {
+				case flux.TBool:
+					if a := cr.Bools(j); a.IsValid(i) {
+						row[j] = a.Value(i)
+					}
+				case flux.TInt:
+					if a := cr.Ints(j); a.IsValid(i) {
+						row[j] = a.Value(i)
+					}
+				case flux.TUInt:
+					if a := cr.UInts(j); a.IsValid(i) {
+						row[j] = a.Value(i)
+					}
+				case flux.TFloat:
+					if a := cr.Floats(j); a.IsValid(i) {
+						row[j] = a.Value(i)
+					}
+				case flux.TString:
+					if a := cr.Strings(j); a.IsValid(i) {
+						row[j] = a.ValueString(i)
+					}
+				case flux.TTime:
+					if a := cr.Times(j); a.IsValid(i) {
+						row[j] = time.Unix(0, a.Value(i)).UTC()
+					}
+				}
+			}
+			msg.Values = append(msg.Values, row)
+		}
+		return nil
+	})
+	return msg, err
+}
+
+// streamTableKey identifies the table of a result by its group key, encoded as a StreamTable.
+func streamTableKey(result string, groupKey map[string]interface{}) string {
+	// The keys of maps are encoded in order, so equal group keys have the same encoding.
+	b, _ := json.Marshal(StreamTable{Result: result, GroupKey: groupKey})
+	return string(b)
+}
+
+// streamTableDigest returns a digest of the columns and the values of the table of msg.
+func streamTableDigest(msg *StreamMessage) (uint64, error) {
+	h := fnv.New64a()
+	enc := json.NewEncoder(h)
+	if err := enc.Encode(msg.Columns); err != nil {
+		return 0, err
+	}
+	if err := enc.Encode(msg.Values); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+func newStreamTestServer(t *testing.T, results ...string) (*httptest.Server, func() int) {
+	t.Helper()
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+		return &platform.Organization{ID: 1, Name: "org"}, nil
+	}
+
+	var mu sync.Mutex
+	runs := 0
+	qs := mock.NewProxyQueryService()
+	qs.QueryFn = func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+		mu.Lock()
+		res := results[runs%len(results)]
+		runs++
+		mu.Unlock()
+		_, err := io.WriteString(w, res)
+		return flux.Statistics{}, err
+	}
+
+	h := NewFluxHandler(&FluxBackend{
+		Logger:              zap.NewNop(),
+		OrganizationService: orgs,
+		ProxyQueryService:   qs,
+	})
+	h.MinStreamInterval = time.Millisecond
+	auth := &platform.Authorization{ID: 2, OrgID: 1, Status: platform.Active}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(pcontext.SetAuthorizer(r.Context(), auth)))
+	}))
+	return ts, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs
+	}
+}
+
+func dialStream(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+	u := "ws" + strings.TrimPrefix(ts.URL, "http") + fluxWebSocketPath + "?orgID=0000000000000001"
+	ws, err := websocket.Dial(u, "", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func receiveStream(t *testing.T, ws *websocket.Conn) StreamMessage {
+	t.Helper()
+	var msg StreamMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+const streamTwoHosts = `#datatype,string,long,dateTime:RFC3339,string,double
+#group,false,false,false,true,false
+#default,_result,,,,
+,result,table,_time,host,_value
+,,0,2019-01-01T00:00:00Z,a,1
+,,1,2019-01-01T00:00:00Z,b,2
+
+`
+
+const streamOneHost = `#datatype,string,long,dateTime:RFC3339,string,double
+#group,false,false,false,true,false
+#default,_result,,,,
+,result,table,_time,host,_value
+,,0,2019-01-01T00:00:00Z,a,3
+
+`
+
+func TestFluxHandler_handleQueryWebSocket(t *testing.T) {
+	ts, _ := newStreamTestServer(t, streamTwoHosts)
+	defer ts.Close()
+
+	ws := dialStream(t, ts)
+	defer ws.Close()
+	if err := websocket.JSON.Send(ws, StreamQueryRequest{QueryRequest: QueryRequest{Query: `from(bucket: "b") |> range(start: -1h)`}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, host := range []string{"a", "b"} {
+		msg := receiveStream(t, ws)
+		if msg.Type != streamMessageTable || msg.Run != 1 || msg.Result != "_result" {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		if msg.GroupKey["host"] != host || len(msg.Values) != 1 || len(msg.Columns) != 3 {
+			t.Errorf("unexpected table %+v", msg)
+		}
+	}
+	if msg := receiveStream(t, ws); msg.Type != streamMessageDone || msg.Tables != 2 {
+		t.Errorf("unexpected done message %+v", msg)
+	}
+
+	// The stream of a query that is not re-run ends with its results.
+	var msg StreamMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != io.EOF {
+		t.Errorf("expected the end of the stream, got %+v, %v", msg, err)
+	}
+}
+
+func TestFluxHandler_handleQueryWebSocket_Every(t *testing.T) {
+	ts, runs := newStreamTestServer(t, streamTwoHosts, streamTwoHosts, streamOneHost)
+	defer ts.Close()
+
+	ws := dialStream(t, ts)
+	if err := websocket.JSON.Send(ws, StreamQueryRequest{
+		QueryRequest: QueryRequest{Query: `from(bucket: "b") |> range(start: -1h)`},
+		Every:        "5ms",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveStream(t, ws)
+	receiveStream(t, ws)
+	if msg := receiveStream(t, ws); msg.Type != streamMessageDone || msg.Run != 1 || msg.Tables != 2 {
+		t.Fatalf("unexpected done message of the first run %+v", msg)
+	}
+
+	// The tables of the second run are the same as those of the first one.
+	if msg := receiveStream(t, ws); msg.Type != streamMessageDone || msg.Run != 2 || msg.Tables != 0 || msg.Unchanged != 2 {
+		t.Fatalf("unexpected done message of the second run %+v", msg)
+	}
+
+	// The table of host a changed and the one of host b is gone.
+	if msg := receiveStream(t, ws); msg.Type != streamMessageTable || msg.Run != 3 || msg.GroupKey["host"] != "a" || msg.Values[0][2] != 3.0 {
+		t.Fatalf("unexpected table of the third run %+v", msg)
+	}
+	msg := receiveStream(t, ws)
+	if msg.Type != streamMessageDone || msg.Run != 3 || msg.Tables != 1 || len(msg.Removed) != 1 || msg.Removed[0].GroupKey["host"] != "b" {
+		t.Fatalf("unexpected done message of the third run %+v", msg)
+	}
+
+	// Closing the stream stops the query from being re-run.
+	ws.Close()
+	time.Sleep(50 * time.Millisecond)
+	n := runs()
+	time.Sleep(50 * time.Millisecond)
+	if runs() != n {
+		t.Errorf("query is still re-run after the stream is closed")
+	}
+}
+
+func TestFluxHandler_handleQueryWebSocket_Invalid(t *testing.T) {
+	ts, _ := newStreamTestServer(t, streamTwoHosts)
+	defer ts.Close()
+
+	for _, req := range []StreamQueryRequest{
+		{QueryRequest: QueryRequest{Query: "SELECT * FROM cpu", Type: "influxql"}},
+		{QueryRequest: QueryRequest{Query: `from(bucket: "b")`}, Every: "soon"},
+		{QueryRequest: QueryRequest{Query: `from(bucket: "b")`}, Every: "1us"},
+	} {
+		ws := dialStream(t, ts)
+		if err := websocket.JSON.Send(ws, req); err != nil {
+			t.Fatal(err)
+		}
+		if msg := receiveStream(t, ws); msg.Type != streamMessageError || msg.Code != platform.EInvalid {
+			t.Errorf("unexpected message %+v", msg)
+		}
+		ws.Close()
+	}
+}
@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+type replicationReportResponse struct {
+	Links           map[string]string            `json:"links"`
+	TargetBucketID  platform.ID                  `json:"targetBucketID"`
+	SourceWatermark *time.Time                   `json:"sourceWatermark,omitempty"`
+	TargetWatermark *time.Time                   `json:"targetWatermark,omitempty"`
+	Lag             string                       `json:"lag"`
+	CompareStop     time.Time                    `json:"compareStop"`
+	SampledSeries   int                          `json:"sampledSeries"`
+	Consistent      bool                         `json:"consistent"`
+	Divergences     []*platform.SeriesDivergence `json:"divergences"`
+}
+
+func newReplicationReportResponse(req *platform.ReplicationRequest, r *platform.ReplicationReport) *replicationReportResponse {
+	res := &replicationReportResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/replication", req.BucketID),
+			"target": bucketIDPath(req.TargetBucketID),
+		},
+		TargetBucketID: req.TargetBucketID,
+		Lag:            r.Lag.String(),
+		CompareStop:    r.CompareStop,
+		SampledSeries:  r.SampledSeries,
+		Consistent:     r.Consistent(),
+		Divergences:    r.Divergences,
+	}
+	if res.Divergences == nil {
+		res.Divergences = []*platform.SeriesDivergence{}
+	}
+	if !r.SourceWatermark.IsZero() {
+		res.SourceWatermark = &r.SourceWatermark
+	}
+	if !r.TargetWatermark.IsZero() {
+		res.TargetWatermark = &r.TargetWatermark
+	}
+	return res
+}
+
+// handleGetBucketReplication is the HTTP handler for the GET /api/v2/buckets/:id/replication route.
+func (h *BucketHandler) handleGetBucketReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketReplicationRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	source, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	req.OrgID = source.OrganizationID
+
+	target, err := h.BucketService.FindBucketByID(ctx, req.TargetBucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	req.TargetOrgID = target.OrganizationID
+
+	report, err := h.ReplicationService.VerifyReplication(ctx, *req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationReportResponse(req, report)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetBucketReplicationRequest(ctx context.Context, r *http.Request) (*platform.ReplicationRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	req := &platform.ReplicationRequest{}
+	if err := req.BucketID.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	qp := r.URL.Query()
+	target := qp.Get("targetBucketID")
+	if target == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "targetBucketID is required",
+		}
+	}
+	if err := req.TargetBucketID.DecodeFromString(target); err != nil {
+		return nil, err
+	}
+
+	if size := qp.Get("sampleSize"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "sampleSize must be an integer",
+				Err:  err,
+			}
+		}
+		req.SampleSize = n
+	}
+
+	for _, t := range []struct {
+		name string
+		v    *time.Time
+	}{
+		{name: "start", v: &req.Start},
+		{name: "stop", v: &req.Stop},
+	} {
+		v := qp.Get(t.name)
+		if v == "" {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("%s is required", t.name),
+			}
+		}
+		tm, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("%s must be an RFC3339 time", t.name),
+				Err:  err,
+			}
+		}
+		*t.v = tm
+	}
+
+	return req, nil
+}
+
+// ReplicationService connects to Influx via HTTP using tokens to verify that buckets are mirrored in other buckets.
+type ReplicationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.ReplicationService = (*ReplicationService)(nil)
+
+// VerifyReplication compares the data of the source bucket of req with the data of its target bucket.
+// The organizations of the request are the ones of the buckets.
+func (s *ReplicationService) VerifyReplication(ctx context.Context, req platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+	u, err := newURL(s.Addr, bucketIDPath(req.BucketID)+"/replication")
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("targetBucketID", req.TargetBucketID.String())
+	query.Add("start", req.Start.Format(time.RFC3339))
+	query.Add("stop", req.Stop.Format(time.RFC3339))
+	if req.SampleSize > 0 {
+		query.Add("sampleSize", strconv.Itoa(req.SampleSize))
+	}
+
+	hreq, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	hreq.URL.RawQuery = query.Encode()
+	SetToken(s.Token, hreq)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r replicationReportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	lag, err := time.ParseDuration(r.Lag)
+	if err != nil {
+		return nil, err
+	}
+	report := &platform.ReplicationReport{
+		Lag:           lag,
+		CompareStop:   r.CompareStop,
+		SampledSeries: r.SampledSeries,
+		Divergences:   r.Divergences,
+	}
+	if r.SourceWatermark != nil {
+		report.SourceWatermark = *r.SourceWatermark
+	}
+	if r.TargetWatermark != nil {
+		report.TargetWatermark = *r.TargetWatermark
+	}
+	return report, nil
+}
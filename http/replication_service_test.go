@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func newReplicationBucketService(orgID platform.ID) *mock.BucketService {
+	return &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			return &platform.Bucket{ID: id, OrganizationID: orgID, Name: "hello"}, nil
+		},
+	}
+}
+
+func TestService_handleGetBucketReplication(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	targetID := platformtesting.MustIDBase16("020f755c3c082002")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantReq    platform.ReplicationRequest
+		wantBody   string
+	}{
+		{
+			name:       "replication of a bucket",
+			query:      "?targetBucketID=020f755c3c082002&sampleSize=10&start=2019-03-01T00:00:00Z&stop=2019-03-01T01:00:00Z",
+			wantStatus: http.StatusOK,
+			wantReq: platform.ReplicationRequest{
+				OrgID:          orgID,
+				BucketID:       bucketID,
+				TargetOrgID:    orgID,
+				TargetBucketID: targetID,
+				Start:          start,
+				Stop:           start.Add(time.Hour),
+				SampleSize:     10,
+			},
+			wantBody: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/replication",
+    "target": "/api/v2/buckets/020f755c3c082002"
+  },
+  "targetBucketID": "020f755c3c082002",
+  "sourceWatermark": "2019-03-01T00:30:00Z",
+  "targetWatermark": "2019-03-01T00:20:00Z",
+  "lag": "10m0s",
+  "compareStop": "2019-03-01T00:20:00Z",
+  "sampledSeries": 10,
+  "consistent": false,
+  "divergences": [
+    {
+      "measurement": "cpu",
+      "field": "value",
+      "tags": {"host": "a"},
+      "reason": "missing",
+      "sourcePoints": 20,
+      "targetPoints": 0
+    }
+  ]
+}
+`,
+		},
+		{
+			name:       "missing target bucket",
+			query:      "?start=2019-03-01T00:00:00Z&stop=2019-03-01T01:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid sample size",
+			query:      "?targetBucketID=020f755c3c082002&sampleSize=all&start=2019-03-01T00:00:00Z&stop=2019-03-01T01:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.BucketService = newReplicationBucketService(orgID)
+			bucketBackend.ReplicationService = &mock.ReplicationService{
+				VerifyReplicationFn: func(ctx context.Context, req platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+					if diff := cmp.Diff(tt.wantReq, req); diff != "" {
+						t.Errorf("unexpected request -want/+got:\n%s", diff)
+					}
+					return &platform.ReplicationReport{
+						SourceWatermark: start.Add(30 * time.Minute),
+						TargetWatermark: start.Add(20 * time.Minute),
+						Lag:             10 * time.Minute,
+						CompareStop:     start.Add(20 * time.Minute),
+						SampledSeries:   10,
+						Divergences: []*platform.SeriesDivergence{
+							{
+								Measurement:  "cpu",
+								Field:        "value",
+								Tags:         map[string]string{"host": "a"},
+								Reason:       platform.DivergenceMissing,
+								SourcePoints: 20,
+							},
+						},
+					}, nil
+				},
+			}
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/replication"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wantBody); tt.wantBody != "" && !eq {
+				t.Errorf("handleGetBucketReplication() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestReplicationService(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	want := &platform.ReplicationReport{
+		SourceWatermark: start.Add(30 * time.Minute),
+		Lag:             30 * time.Minute,
+		CompareStop:     start,
+		SampledSeries:   1,
+		Divergences:     []*platform.SeriesDivergence{},
+	}
+
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.BucketService = newReplicationBucketService(orgID)
+	bucketBackend.ReplicationService = &mock.ReplicationService{
+		VerifyReplicationFn: func(ctx context.Context, req platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+			return want, nil
+		},
+	}
+	server := httptest.NewServer(NewBucketHandler(bucketBackend))
+	defer server.Close()
+
+	s := &ReplicationService{Addr: server.URL}
+	report, err := s.VerifyReplication(context.Background(), platform.ReplicationRequest{
+		BucketID:       platformtesting.MustIDBase16("020f755c3c082000"),
+		TargetBucketID: platformtesting.MustIDBase16("020f755c3c082002"),
+		Start:          start,
+		Stop:           start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("unexpected report -want/+got:\n%s", diff)
+	}
+}
@@ -0,0 +1,574 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ReporterHandler represents an HTTP API handler for expected reporters
+type ReporterHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ExpectedReporterService platform.ExpectedReporterService
+	ExpectedReporterMonitor platform.ExpectedReporterMonitor
+}
+
+const (
+	reportersPath       = "/api/v2/reporters"
+	reportersStatusPath = "/api/v2/reporters/status"
+	reportersIDPath     = "/api/v2/reporters/:id"
+)
+
+// NewReporterHandler returns a new instance of ReporterHandler
+func NewReporterHandler(s platform.ExpectedReporterService, m platform.ExpectedReporterMonitor) *ReporterHandler {
+	h := &ReporterHandler{
+		Router:                  NewRouter(),
+		Logger:                  zap.NewNop(),
+		ExpectedReporterService: s,
+		ExpectedReporterMonitor: m,
+	}
+
+	h.HandlerFunc("GET", reportersPath, h.handleGetReporters)
+	h.HandlerFunc("POST", reportersPath, h.handlePostReporter)
+	h.HandlerFunc("GET", reportersStatusPath, h.handleGetReportersStatus)
+	h.HandlerFunc("PATCH", reportersIDPath, h.handlePatchReporter)
+	h.HandlerFunc("DELETE", reportersIDPath, h.handleDeleteReporter)
+
+	return h
+}
+
+// expectedReporter is used for serialization/deserialization with the interval in seconds.
+type expectedReporter struct {
+	ID              platform.ID `json:"id,omitempty"`
+	OrgID           platform.ID `json:"orgID"`
+	BucketID        platform.ID `json:"bucketID"`
+	Measurement     string      `json:"measurement,omitempty"`
+	TagKey          string      `json:"tagKey"`
+	TagValue        string      `json:"tagValue"`
+	IntervalSeconds int64       `json:"intervalSeconds"`
+}
+
+func (r *expectedReporter) toPlatform() *platform.ExpectedReporter {
+	return &platform.ExpectedReporter{
+		ID:          r.ID,
+		OrgID:       r.OrgID,
+		BucketID:    r.BucketID,
+		Measurement: r.Measurement,
+		TagKey:      r.TagKey,
+		TagValue:    r.TagValue,
+		Interval:    time.Duration(r.IntervalSeconds) * time.Second,
+	}
+}
+
+func newExpectedReporter(r *platform.ExpectedReporter) *expectedReporter {
+	return &expectedReporter{
+		ID:              r.ID,
+		OrgID:           r.OrgID,
+		BucketID:        r.BucketID,
+		Measurement:     r.Measurement,
+		TagKey:          r.TagKey,
+		TagValue:        r.TagValue,
+		IntervalSeconds: int64(r.Interval.Round(time.Second) / time.Second),
+	}
+}
+
+// expectedReporterUpdate is used for serialization/deserialization with the interval in seconds.
+type expectedReporterUpdate struct {
+	Measurement     *string `json:"measurement,omitempty"`
+	TagKey          *string `json:"tagKey,omitempty"`
+	TagValue        *string `json:"tagValue,omitempty"`
+	IntervalSeconds *int64  `json:"intervalSeconds,omitempty"`
+}
+
+func (u *expectedReporterUpdate) toPlatform() platform.ExpectedReporterUpdate {
+	upd := platform.ExpectedReporterUpdate{
+		Measurement: u.Measurement,
+		TagKey:      u.TagKey,
+		TagValue:    u.TagValue,
+	}
+	if u.IntervalSeconds != nil {
+		d := time.Duration(*u.IntervalSeconds) * time.Second
+		upd.Interval = &d
+	}
+	return upd
+}
+
+func newExpectedReporterUpdate(upd platform.ExpectedReporterUpdate) *expectedReporterUpdate {
+	u := &expectedReporterUpdate{
+		Measurement: upd.Measurement,
+		TagKey:      upd.TagKey,
+		TagValue:    upd.TagValue,
+	}
+	if upd.Interval != nil {
+		s := int64(upd.Interval.Round(time.Second) / time.Second)
+		u.IntervalSeconds = &s
+	}
+	return u
+}
+
+type reporterResponse struct {
+	expectedReporter
+	Links map[string]string `json:"links"`
+}
+
+func newReporterResponse(r *platform.ExpectedReporter) *reporterResponse {
+	return &reporterResponse{
+		expectedReporter: *newExpectedReporter(r),
+		Links: map[string]string{
+			"self":   reporterIDPath(r.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", r.BucketID),
+		},
+	}
+}
+
+type reportersResponse struct {
+	Links     map[string]string   `json:"links"`
+	Reporters []*reporterResponse `json:"reporters"`
+}
+
+func newReportersResponse(rs []*platform.ExpectedReporter) *reportersResponse {
+	res := &reportersResponse{
+		Links: map[string]string{
+			"self":   reportersPath,
+			"status": reportersStatusPath,
+		},
+		Reporters: make([]*reporterResponse, 0, len(rs)),
+	}
+	for _, r := range rs {
+		res.Reporters = append(res.Reporters, newReporterResponse(r))
+	}
+	return res
+}
+
+type reporterStatusResponse struct {
+	reporterResponse
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+	Missing  bool       `json:"missing"`
+}
+
+type reportersStatusResponse struct {
+	Links     map[string]string         `json:"links"`
+	Reporters []*reporterStatusResponse `json:"reporters"`
+}
+
+func newReportersStatusResponse(ss []*platform.ExpectedReporterStatus) *reportersStatusResponse {
+	res := &reportersStatusResponse{
+		Links: map[string]string{
+			"self":      reportersStatusPath,
+			"reporters": reportersPath,
+		},
+		Reporters: make([]*reporterStatusResponse, 0, len(ss)),
+	}
+	for _, s := range ss {
+		res.Reporters = append(res.Reporters, &reporterStatusResponse{
+			reporterResponse: *newReporterResponse(&s.ExpectedReporter),
+			LastSeen:         s.LastSeen,
+			Missing:          s.Missing,
+		})
+	}
+	return res
+}
+
+// handleGetReporters is the HTTP handler for the GET /api/v2/reporters route.
+func (h *ReporterHandler) handleGetReporters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetReportersRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.ExpectedReporterService.FindExpectedReporters(ctx, req.filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReportersResponse(rs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetReportersStatus is the HTTP handler for the GET /api/v2/reporters/status route.
+func (h *ReporterHandler) handleGetReportersStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetReportersRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.ExpectedReporterService.FindExpectedReporters(ctx, req.filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ss, err := h.ExpectedReporterMonitor.ExpectedReporterStatuses(ctx, rs)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if req.missing {
+		// This filters without allocating
+		// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+		missing := ss[:0]
+		for _, s := range ss {
+			if s.Missing {
+				missing = append(missing, s)
+			}
+		}
+		ss = missing
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReportersStatusResponse(ss)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type getReportersRequest struct {
+	filter  platform.ExpectedReporterFilter
+	missing bool
+}
+
+func decodeGetReportersRequest(ctx context.Context, r *http.Request) (*getReportersRequest, error) {
+	qp := r.URL.Query()
+	req := &getReportersRequest{}
+
+	if id := qp.Get("id"); id != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(id); err != nil {
+			return nil, err
+		}
+		req.filter.ID = &i
+	}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(orgID); err != nil {
+			return nil, err
+		}
+		req.filter.OrgID = &i
+	}
+
+	if bucketID := qp.Get("bucketID"); bucketID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(bucketID); err != nil {
+			return nil, err
+		}
+		req.filter.BucketID = &i
+	}
+
+	if missing := qp.Get("missing"); missing != "" {
+		b, err := strconv.ParseBool(missing)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "missing must be a boolean",
+				Err:  err,
+			}
+		}
+		req.missing = b
+	}
+
+	return req, nil
+}
+
+// handlePostReporter is the HTTP handler for the POST /api/v2/reporters route.
+func (h *ReporterHandler) handlePostReporter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	er := &expectedReporter{}
+	if err := json.NewDecoder(r.Body).Decode(er); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode expected reporter request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	rep := er.toPlatform()
+	if err := h.ExpectedReporterService.CreateExpectedReporter(ctx, rep); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newReporterResponse(rep)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchReporter is the HTTP handler for the PATCH /api/v2/reporters/:id route.
+func (h *ReporterHandler) handlePatchReporter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeReporterID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd expectedReporterUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode expected reporter update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	rep, err := h.ExpectedReporterService.UpdateExpectedReporter(ctx, id, upd.toPlatform())
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReporterResponse(rep)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteReporter is the HTTP handler for the DELETE /api/v2/reporters/:id route.
+func (h *ReporterHandler) handleDeleteReporter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeReporterID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ExpectedReporterService.DeleteExpectedReporter(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeReporterID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, err
+	}
+
+	return i, nil
+}
+
+// ExpectedReporterService connects to Influx via HTTP using tokens to manage expected reporters
+type ExpectedReporterService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+	// OpPrefix is an additional property for error
+	// find expected reporter service, when finds nothing.
+	OpPrefix string
+}
+
+var _ platform.ExpectedReporterService = (*ExpectedReporterService)(nil)
+
+// FindExpectedReporterByID returns a single expected reporter by ID.
+func (s *ExpectedReporterService) FindExpectedReporterByID(ctx context.Context, id platform.ID) (*platform.ExpectedReporter, error) {
+	rs, err := s.FindExpectedReporters(ctx, platform.ExpectedReporterFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rs) == 0 {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Op:   s.OpPrefix + platform.OpFindExpectedReporterByID,
+			Msg:  platform.ErrExpectedReporterNotFound,
+		}
+	}
+
+	return rs[0], nil
+}
+
+// FindExpectedReporters returns the expected reporters that match a filter.
+func (s *ExpectedReporterService) FindExpectedReporters(ctx context.Context, filter platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error) {
+	u, err := newURL(s.Addr, reportersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.ID != nil {
+		query.Add("id", filter.ID.String())
+	}
+	if filter.OrgID != nil {
+		query.Add("orgID", filter.OrgID.String())
+	}
+	if filter.BucketID != nil {
+		query.Add("bucketID", filter.BucketID.String())
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r reportersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	rs := make([]*platform.ExpectedReporter, 0, len(r.Reporters))
+	for _, rep := range r.Reporters {
+		rs = append(rs, rep.toPlatform())
+	}
+	return rs, nil
+}
+
+// CreateExpectedReporter creates a new expected reporter and sets r.ID with the new identifier.
+func (s *ExpectedReporterService) CreateExpectedReporter(ctx context.Context, r *platform.ExpectedReporter) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	u, err := newURL(s.Addr, reportersPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(newExpectedReporter(r))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var rr reporterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return err
+	}
+	*r = *rr.toPlatform()
+
+	return nil
+}
+
+// UpdateExpectedReporter updates a single expected reporter with changeset.
+// Returns the new expected reporter state after update.
+func (s *ExpectedReporterService) UpdateExpectedReporter(ctx context.Context, id platform.ID, upd platform.ExpectedReporterUpdate) (*platform.ExpectedReporter, error) {
+	u, err := newURL(s.Addr, reporterIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(newExpectedReporterUpdate(upd))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr reporterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+
+	return rr.toPlatform(), nil
+}
+
+// DeleteExpectedReporter removes an expected reporter by ID.
+func (s *ExpectedReporterService) DeleteExpectedReporter(ctx context.Context, id platform.ID) error {
+	u, err := newURL(s.Addr, reporterIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func reporterIDPath(id platform.ID) string {
+	return path.Join(reportersPath, id.String())
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initExpectedReporterService(f platformtesting.ExpectedReporterFields, t *testing.T) (platform.ExpectedReporterService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize expected reporter service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, b := range f.Buckets {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatalf("failed to populate buckets: %v", err)
+		}
+	}
+	for _, r := range f.ExpectedReporters {
+		if err := svc.PutExpectedReporter(ctx, r); err != nil {
+			t.Fatalf("failed to populate expected reporters: %v", err)
+		}
+	}
+
+	handler := NewReporterHandler(svc, &mock.ExpectedReporterMonitor{})
+	server := httptest.NewServer(handler)
+	client := ExpectedReporterService{
+		Addr:     server.URL,
+		OpPrefix: kv.OpPrefix,
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestExpectedReporterService(t *testing.T) {
+	platformtesting.ExpectedReporterService(initExpectedReporterService, t)
+}
+
+func TestReporterHandler_handleGetReportersStatus(t *testing.T) {
+	lastSeen := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	reporters := []*platform.ExpectedReporter{
+		{ID: 1, OrgID: 10, BucketID: 100, TagKey: "host", TagValue: "server01", Interval: time.Minute},
+		{ID: 2, OrgID: 10, BucketID: 100, TagKey: "host", TagValue: "server02", Interval: 5 * time.Minute},
+	}
+
+	var filter platform.ExpectedReporterFilter
+	svc := mock.NewExpectedReporterService()
+	svc.FindExpectedReportersFn = func(ctx context.Context, f platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error) {
+		filter = f
+		return reporters, nil
+	}
+	monitor := &mock.ExpectedReporterMonitor{
+		ExpectedReporterStatusesFn: func(ctx context.Context, rs []*platform.ExpectedReporter) ([]*platform.ExpectedReporterStatus, error) {
+			return []*platform.ExpectedReporterStatus{
+				{ExpectedReporter: *rs[0], LastSeen: &lastSeen},
+				{ExpectedReporter: *rs[1], Missing: true},
+			}, nil
+		},
+	}
+	h := NewReporterHandler(svc, monitor)
+
+	tests := []struct {
+		name      string
+		url       string
+		wantTags  []string
+		wantCode  int
+		wantFirst *time.Time
+	}{
+		{
+			name:      "all statuses",
+			url:       "http://any.url/api/v2/reporters/status?bucketID=0000000000000064",
+			wantTags:  []string{"server01", "server02"},
+			wantCode:  http.StatusOK,
+			wantFirst: &lastSeen,
+		},
+		{
+			name:     "missing reporters only",
+			url:      "http://any.url/api/v2/reporters/status?bucketID=0000000000000064&missing=true",
+			wantTags: []string{"server02"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid missing parameter",
+			url:      "http://any.url/api/v2/reporters/status?missing=maybe",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("GET reporters status = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			if filter.BucketID == nil || *filter.BucketID != 100 {
+				t.Errorf("expected reporters of bucket 100, got filter %+v", filter)
+			}
+
+			var res struct {
+				Reporters []struct {
+					TagValue        string     `json:"tagValue"`
+					IntervalSeconds int64      `json:"intervalSeconds"`
+					LastSeen        *time.Time `json:"lastSeen"`
+					Missing         bool       `json:"missing"`
+				} `json:"reporters"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Reporters) != len(tt.wantTags) {
+				t.Fatalf("expected %d reporters, got %d", len(tt.wantTags), len(res.Reporters))
+			}
+			for i, r := range res.Reporters {
+				if r.TagValue != tt.wantTags[i] {
+					t.Errorf("reporter %d: expected tag value %s, got %s", i, tt.wantTags[i], r.TagValue)
+				}
+				if r.Missing != (r.TagValue == "server02") {
+					t.Errorf("reporter %d: unexpected missing %v", i, r.Missing)
+				}
+			}
+			if tt.wantFirst != nil {
+				if res.Reporters[0].LastSeen == nil || !res.Reporters[0].LastSeen.Equal(*tt.wantFirst) {
+					t.Errorf("unexpected last seen time %v", res.Reporters[0].LastSeen)
+				}
+				if res.Reporters[0].IntervalSeconds != 60 {
+					t.Errorf("unexpected interval %d", res.Reporters[0].IntervalSeconds)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	platform "github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+)
+
+// RequestLimits are the request rate and the concurrent requests allowed to every authorization token.
+// A zero limit is not enforced.
+type RequestLimits struct {
+	// RequestsPerSecond is the rate of the requests made with a token.
+	RequestsPerSecond int
+	// Burst is the number of requests a token may make at once above its rate.
+	// It defaults to RequestsPerSecond.
+	Burst int
+	// MaxConcurrent is the number of requests made with a token that may be served at the same time.
+	MaxConcurrent int
+}
+
+// The limits reported by RequestLimitExceededError.
+const (
+	RequestLimitRate        = "rate"
+	RequestLimitConcurrency = "concurrency"
+)
+
+// RequestLimitExceededError is returned when a request exceeds the RequestLimits of its token.
+type RequestLimitExceededError struct {
+	// Limit is RequestLimitRate or RequestLimitConcurrency.
+	Limit string
+	Value int
+
+	// RetryAfter is how long until the request would be allowed.
+	RetryAfter time.Duration
+}
+
+func (e *RequestLimitExceededError) Error() string {
+	if e.Limit == RequestLimitConcurrency {
+		return fmt.Sprintf("limit of %d concurrent requests per token exceeded, retry in %s", e.Value, e.RetryAfter)
+	}
+	return fmt.Sprintf("request rate limit of %d requests per second per token exceeded, retry in %s", e.Value, e.RetryAfter)
+}
+
+// RequestLimiter enforces RequestLimits on the requests of every authorization token.
+//
+// Each token has a budget of requests, which refills at the rate of its limit up to its burst.
+// A request is allowed if the budget of its token holds one request and fewer than MaxConcurrent
+// requests of the token are being served.
+//
+// The tokens are identified by a hash in the metrics, which count the requests of each token
+// and the requests it has in flight.
+type RequestLimiter struct {
+	limits RequestLimits
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	tokens    map[string]*tokenRequests
+	lastPrune time.Time
+
+	requests *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+}
+
+// tokenRequests are the budget of a token, as of updated, and its requests in flight.
+type tokenRequests struct {
+	balance  float64
+	updated  time.Time
+	inFlight int
+}
+
+// requestLimiterPruneInterval is how often the tokens that are idle again are forgotten.
+const requestLimiterPruneInterval = time.Minute
+
+// The results of the requests counted by a RequestLimiter.
+const (
+	requestAllowed            = "allowed"
+	requestRateLimited        = "rate_limited"
+	requestConcurrencyLimited = "concurrency_limited"
+)
+
+// NewRequestLimiter returns a RequestLimiter that enforces l.
+func NewRequestLimiter(l RequestLimits) *RequestLimiter {
+	const namespace = "http"
+	const subsystem = "token"
+
+	if l.Burst <= 0 {
+		l.Burst = l.RequestsPerSecond
+	}
+	return &RequestLimiter{
+		limits: l,
+		Now:    time.Now,
+		tokens: make(map[string]*tokenRequests),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Total number of requests made with authorization tokens, split out by token hash and whether they were allowed or the limit they exceeded.",
+		}, []string{"token", "result"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_in_flight",
+			Help:      "Number of requests being served, split out by token hash.",
+		}, []string{"token"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (l *RequestLimiter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.requests,
+		l.inFlight,
+	}
+}
+
+// tokenHash returns the hash identifying token in the metrics, which is not enough to recover it.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Allow draws a request of token from its budget and counts it in flight, or returns
+// a *RequestLimitExceededError, without drawing anything, if a limit of the token is exceeded.
+// done must be called once the request is served.
+func (l *RequestLimiter) Allow(token string) (done func(), err error) {
+	hash := tokenHash(token)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	l.prune(now)
+	t := l.token(hash, now)
+
+	if l.limits.MaxConcurrent > 0 && t.inFlight >= l.limits.MaxConcurrent {
+		l.requests.WithLabelValues(hash, requestConcurrencyLimited).Inc()
+		return nil, &RequestLimitExceededError{
+			Limit:      RequestLimitConcurrency,
+			Value:      l.limits.MaxConcurrent,
+			RetryAfter: time.Second,
+		}
+	}
+	if l.limits.RequestsPerSecond > 0 {
+		if t.balance < 1 {
+			l.requests.WithLabelValues(hash, requestRateLimited).Inc()
+			// Wait until the budget holds a request again, in whole seconds for Retry-After.
+			wait := math.Ceil((1 - t.balance) / float64(l.limits.RequestsPerSecond))
+			return nil, &RequestLimitExceededError{
+				Limit:      RequestLimitRate,
+				Value:      l.limits.RequestsPerSecond,
+				RetryAfter: time.Duration(wait) * time.Second,
+			}
+		}
+		t.balance--
+	}
+
+	t.inFlight++
+	l.requests.WithLabelValues(hash, requestAllowed).Inc()
+	l.inFlight.WithLabelValues(hash).Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			t.inFlight--
+			l.inFlight.WithLabelValues(hash).Dec()
+		})
+	}, nil
+}
+
+// token returns the requests of the token hash with its budget refilled until now, creating them if needed.
+// l.mu must be held.
+func (l *RequestLimiter) token(hash string, now time.Time) *tokenRequests {
+	t, ok := l.tokens[hash]
+	if !ok {
+		t = &tokenRequests{balance: float64(l.limits.Burst), updated: now}
+		l.tokens[hash] = t
+		return t
+	}
+	if elapsed := now.Sub(t.updated); elapsed > 0 {
+		t.balance = math.Min(float64(l.limits.Burst), t.balance+elapsed.Seconds()*float64(l.limits.RequestsPerSecond))
+		t.updated = now
+	}
+	return t
+}
+
+// prune forgets the tokens with a full budget and no request in flight, which are the same as new ones,
+// so that the tokens that stopped making requests are not kept forever.
+// l.mu must be held.
+func (l *RequestLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < requestLimiterPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for hash, t := range l.tokens {
+		if t.inFlight > 0 {
+			continue
+		}
+		if t.balance+now.Sub(t.updated).Seconds()*float64(l.limits.RequestsPerSecond) >= float64(l.limits.Burst) {
+			delete(l.tokens, hash)
+			l.inFlight.DeleteLabelValues(hash)
+		}
+	}
+}
+
+// RequestLimitHandler limits the requests to its handler made with every authorization token.
+// It must be wrapped by the authentication handler, so that the token of the requests is known.
+// The requests authenticated with a session are not limited.
+type RequestLimitHandler struct {
+	Handler http.Handler
+	Limiter *RequestLimiter
+}
+
+// NewRequestLimitHandler returns a RequestLimitHandler limiting the requests to h with l.
+func NewRequestLimitHandler(h http.Handler, l *RequestLimiter) *RequestLimitHandler {
+	return &RequestLimitHandler{
+		Handler: h,
+		Limiter: l,
+	}
+}
+
+// ServeHTTP serves r with the handler if the limits of its token allow it,
+// and responds with a 429 and a Retry-After header otherwise.
+func (h *RequestLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.Handler.ServeHTTP(w, r)
+		return
+	}
+	auth, ok := a.(*platform.Authorization)
+	if !ok {
+		h.Handler.ServeHTTP(w, r)
+		return
+	}
+
+	done, err := h.Limiter.Allow(auth.Token)
+	if err != nil {
+		if lee, ok := err.(*RequestLimitExceededError); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(lee.RetryAfter/time.Second)))
+		}
+		EncodeError(ctx, &platform.Error{
+			Code: platform.ETooManyRequests,
+			Op:   "http/RequestLimitHandler",
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+	defer done()
+
+	h.Handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+)
+
+func TestRequestLimiter_Allow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewRequestLimiter(RequestLimits{
+		RequestsPerSecond: 2,
+		Burst:             3,
+	})
+	l.Now = func() time.Time { return now }
+
+	// The burst is allowed at once...
+	for i := 0; i < 3; i++ {
+		done, err := l.Allow("token")
+		if err != nil {
+			t.Fatalf("expected request %d to be allowed: %v", i, err)
+		}
+		done()
+	}
+	// ...and the following requests of the token wait for its budget to refill.
+	_, err := l.Allow("token")
+	if diff := cmp.Diff(err, error(&RequestLimitExceededError{
+		Limit:      RequestLimitRate,
+		Value:      2,
+		RetryAfter: time.Second,
+	})); diff != "" {
+		t.Fatalf("unexpected error -got/+want\n%s", diff)
+	}
+
+	// Another token is not throttled.
+	done, err := l.Allow("other")
+	if err != nil {
+		t.Fatalf("expected the request of another token to be allowed: %v", err)
+	}
+	done()
+
+	// The budget refills at the rate of the limit.
+	now = now.Add(500 * time.Millisecond)
+	done, err = l.Allow("token")
+	if err != nil {
+		t.Fatalf("expected the token to be allowed once its budget refilled: %v", err)
+	}
+	done()
+
+	// Idle tokens are forgotten.
+	now = now.Add(time.Hour)
+	done, err = l.Allow("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done()
+	if len(l.tokens) != 1 {
+		t.Errorf("expected only the token of the last request to be kept, got %d", len(l.tokens))
+	}
+}
+
+func TestRequestLimiter_Concurrency(t *testing.T) {
+	l := NewRequestLimiter(RequestLimits{MaxConcurrent: 2})
+
+	first, err := l.Allow("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.Allow("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Allow("token")
+	if diff := cmp.Diff(err, error(&RequestLimitExceededError{
+		Limit:      RequestLimitConcurrency,
+		Value:      2,
+		RetryAfter: time.Second,
+	})); diff != "" {
+		t.Fatalf("unexpected error -got/+want\n%s", diff)
+	}
+
+	// Calling done more than once releases a single request.
+	first()
+	first()
+	third, err := l.Allow("token")
+	if err != nil {
+		t.Fatalf("expected a request to be allowed once another is done: %v", err)
+	}
+	if _, err := l.Allow("token"); err == nil {
+		t.Fatal("expected the limit to be enforced again")
+	}
+	second()
+	third()
+}
+
+func TestRequestLimitHandler(t *testing.T) {
+	limiter := NewRequestLimiter(RequestLimits{RequestsPerSecond: 1})
+	reg := prom.NewRegistry()
+	reg.MustRegister(limiter.PrometheusCollectors()...)
+
+	var served int
+	h := NewRequestLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusNoContent)
+	}), limiter)
+
+	get := func(a platform.Authorizer) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/api/v2/buckets", nil)
+		if a != nil {
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), a))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	auth := &platform.Authorization{ID: 1, Token: "secret", Status: platform.Active}
+	if w := get(auth); w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	w := get(auth)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusTooManyRequests, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("got Retry-After %q, want 1", got)
+	}
+	if got := w.Header().Get(PlatformErrorCodeHeader); got != platform.ETooManyRequests {
+		t.Errorf("got error code %q, want %q", got, platform.ETooManyRequests)
+	}
+
+	// The requests made with a session or without an authorizer are not limited.
+	for i := 0; i < 3; i++ {
+		if w := get(&platform.Session{ID: 2, UserID: 3}); w.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+		}
+		if w := get(nil); w.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+		}
+	}
+	if served != 7 {
+		t.Errorf("got %d requests served, want 7", served)
+	}
+
+	// The metrics identify the token by its hash.
+	mfs := promtest.MustGather(t, reg)
+	hash := tokenHash("secret")
+	for result, want := range map[string]float64{
+		requestAllowed:     1,
+		requestRateLimited: 1,
+	} {
+		m := promtest.MustFindMetric(t, mfs, "http_token_requests_total", map[string]string{"token": hash, "result": result})
+		if got := m.GetCounter().GetValue(); got != want {
+			t.Errorf("got %v %s requests, want %v", got, result, want)
+		}
+	}
+	m := promtest.MustFindMetric(t, mfs, "http_token_requests_in_flight", map[string]string{"token": hash})
+	if got := m.GetGauge().GetValue(); got != 0 {
+		t.Errorf("got %v requests in flight, want 0", got)
+	}
+	if promtest.FindMetric(mfs, "http_token_requests_total", map[string]string{"token": "secret", "result": requestAllowed}) != nil {
+		t.Error("expected the token not to be in the metrics")
+	}
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// RunningQueryHandler represents an HTTP API handler for the queries running on the query controller
+type RunningQueryHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	RunningQueryService platform.RunningQueryService
+}
+
+const (
+	runningQueriesPath   = "/api/v2/query/queries"
+	runningQueriesIDPath = "/api/v2/query/queries/:id"
+)
+
+// NewRunningQueryHandler returns a new instance of RunningQueryHandler
+func NewRunningQueryHandler(s platform.RunningQueryService) *RunningQueryHandler {
+	h := &RunningQueryHandler{
+		Router:              NewRouter(),
+		Logger:              zap.NewNop(),
+		RunningQueryService: s,
+	}
+
+	h.HandlerFunc("GET", runningQueriesPath, h.handleGetRunningQueries)
+	h.HandlerFunc("GET", runningQueriesIDPath, h.handleGetRunningQuery)
+	h.HandlerFunc("DELETE", runningQueriesIDPath, h.handleDeleteRunningQuery)
+
+	return h
+}
+
+type runningQueryResponse struct {
+	Links map[string]string `json:"links"`
+	*platform.RunningQuery
+}
+
+func newRunningQueryResponse(q *platform.RunningQuery) *runningQueryResponse {
+	return &runningQueryResponse{
+		Links: map[string]string{
+			"self": runningQueryIDPath(q.ID),
+		},
+		RunningQuery: q,
+	}
+}
+
+type runningQueriesResponse struct {
+	Links   map[string]string       `json:"links"`
+	Queries []*runningQueryResponse `json:"queries"`
+}
+
+func newRunningQueriesResponse(qs []*platform.RunningQuery) *runningQueriesResponse {
+	res := &runningQueriesResponse{
+		Links: map[string]string{
+			"self": runningQueriesPath,
+		},
+		Queries: make([]*runningQueryResponse, 0, len(qs)),
+	}
+	for _, q := range qs {
+		res.Queries = append(res.Queries, newRunningQueryResponse(q))
+	}
+	return res
+}
+
+// handleGetRunningQueries is the HTTP handler for the GET /api/v2/query/queries route.
+func (h *RunningQueryHandler) handleGetRunningQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter platform.RunningQueryFilter
+	if s := r.URL.Query().Get("orgID"); s != "" {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	qs, err := h.RunningQueryService.FindRunningQueries(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunningQueriesResponse(qs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetRunningQuery is the HTTP handler for the GET /api/v2/query/queries/:id route.
+func (h *RunningQueryHandler) handleGetRunningQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeRunningQueryID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	q, err := h.RunningQueryService.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunningQueryResponse(q)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteRunningQuery is the HTTP handler for the DELETE /api/v2/query/queries/:id route, canceling the query.
+func (h *RunningQueryHandler) handleDeleteRunningQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeRunningQueryID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.RunningQueryService.CancelRunningQuery(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeRunningQueryID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, err
+	}
+
+	return i, nil
+}
+
+func runningQueryIDPath(id platform.ID) string {
+	return path.Join(runningQueriesPath, id.String())
+}
+
+// RunningQueryService connects to Influx via HTTP using tokens to list and cancel the queries running on influxd
+type RunningQueryService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.RunningQueryService = (*RunningQueryService)(nil)
+
+// FindRunningQueries returns the running queries matching the filter, longest running first.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter platform.RunningQueryFilter) ([]*platform.RunningQuery, error) {
+	u, err := newURL(s.Addr, runningQueriesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.OrgID != nil {
+		query.Add("orgID", filter.OrgID.String())
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r runningQueriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	qs := make([]*platform.RunningQuery, 0, len(r.Queries))
+	for _, q := range r.Queries {
+		qs = append(qs, q.RunningQuery)
+	}
+	return qs, nil
+}
+
+// FindRunningQueryByID returns a single running query by ID.
+func (s *RunningQueryService) FindRunningQueryByID(ctx context.Context, id platform.ID) (*platform.RunningQuery, error) {
+	u, err := newURL(s.Addr, runningQueryIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r runningQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return r.RunningQuery, nil
+}
+
+// CancelRunningQuery stops the execution of a running query.
+func (s *RunningQueryService) CancelRunningQuery(ctx context.Context, id platform.ID) error {
+	u, err := newURL(s.Addr, runningQueryIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestRunningQueryService(t *testing.T) {
+	startedAt := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	queries := []*platform.RunningQuery{
+		{
+			ID:              1,
+			OrgID:           10,
+			AuthorizationID: 20,
+			Source:          "dashboard",
+			State:           "executing",
+			StartedAt:       startedAt,
+			Duration:        3 * time.Second,
+			MaxAllocated:    1 << 20,
+		},
+		{
+			ID:        2,
+			OrgID:     11,
+			State:     "queueing",
+			StartedAt: startedAt.Add(time.Second),
+			Duration:  2 * time.Second,
+		},
+	}
+
+	var canceled platform.ID
+	svc := mock.NewRunningQueryService()
+	svc.FindRunningQueriesFn = func(ctx context.Context, filter platform.RunningQueryFilter) ([]*platform.RunningQuery, error) {
+		var qs []*platform.RunningQuery
+		for _, q := range queries {
+			if filter.OrgID == nil || q.OrgID == *filter.OrgID {
+				qs = append(qs, q)
+			}
+		}
+		return qs, nil
+	}
+	svc.FindRunningQueryByIDFn = func(ctx context.Context, id platform.ID) (*platform.RunningQuery, error) {
+		for _, q := range queries {
+			if q.ID == id {
+				return q, nil
+			}
+		}
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrRunningQueryNotFound}
+	}
+	svc.CancelRunningQueryFn = func(ctx context.Context, id platform.ID) error {
+		if _, err := svc.FindRunningQueryByIDFn(ctx, id); err != nil {
+			return err
+		}
+		canceled = id
+		return nil
+	}
+
+	server := httptest.NewServer(NewRunningQueryHandler(svc))
+	defer server.Close()
+	client := RunningQueryService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.FindRunningQueries(ctx, platform.RunningQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(queries, got); diff != "" {
+		t.Errorf("unexpected running queries -want/+got:\n%s", diff)
+	}
+
+	orgID := platform.ID(11)
+	got, err = client.FindRunningQueries(ctx, platform.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(queries[1:], got); diff != "" {
+		t.Errorf("unexpected running queries of the org -want/+got:\n%s", diff)
+	}
+
+	q, err := client.FindRunningQueryByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(queries[0], q); diff != "" {
+		t.Errorf("unexpected running query -want/+got:\n%s", diff)
+	}
+
+	if err := client.CancelRunningQuery(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if canceled != 2 {
+		t.Errorf("got query %v canceled, want %v", canceled, platform.ID(2))
+	}
+
+	if err := client.CancelRunningQuery(ctx, 3); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("got error %v canceling an unknown query, want not found", err)
+	}
+}
@@ -0,0 +1,466 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ShardHandler represents an HTTP API handler for the shards of the storage engine
+type ShardHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ShardService platform.ShardService
+}
+
+const (
+	shardsPath        = "/api/v2/shards"
+	shardsIDPath      = "/api/v2/shards/:id"
+	shardsBackupPath  = "/api/v2/shards/:id/backup"
+	shardsCompactPath = "/api/v2/shards/compact"
+	shardsTopPath     = "/api/v2/ops/shards/top"
+)
+
+// NewShardHandler returns a new instance of ShardHandler
+func NewShardHandler(s platform.ShardService) *ShardHandler {
+	h := &ShardHandler{
+		Router:       NewRouter(),
+		Logger:       zap.NewNop(),
+		ShardService: s,
+	}
+
+	h.HandlerFunc("GET", shardsPath, h.handleGetShards)
+	h.HandlerFunc("POST", shardsCompactPath, h.handlePostShardsCompact)
+	h.HandlerFunc("DELETE", shardsIDPath, h.handleDeleteShard)
+	h.HandlerFunc("GET", shardsBackupPath, h.handleGetShardBackup)
+	h.HandlerFunc("GET", shardsTopPath, h.handleGetTopShards)
+
+	return h
+}
+
+type shardResponse struct {
+	Links map[string]string `json:"links"`
+	platform.Shard
+}
+
+func newShardResponse(s *platform.Shard) *shardResponse {
+	return &shardResponse{
+		Links: map[string]string{
+			"self":   path.Join(shardsPath, s.ID) + "?" + shardFilterQuery(s.OrgID, s.BucketID),
+			"backup": path.Join(shardsPath, s.ID, "backup") + "?" + shardFilterQuery(s.OrgID, s.BucketID),
+		},
+		Shard: *s,
+	}
+}
+
+type shardsResponse struct {
+	Links  map[string]string `json:"links"`
+	Shards []*shardResponse  `json:"shards"`
+}
+
+func newShardsResponse(ss []*platform.Shard) *shardsResponse {
+	res := &shardsResponse{
+		Links: map[string]string{
+			"self":    shardsPath,
+			"compact": shardsCompactPath,
+		},
+		Shards: make([]*shardResponse, 0, len(ss)),
+	}
+	for _, s := range ss {
+		res.Shards = append(res.Shards, newShardResponse(s))
+	}
+	return res
+}
+
+// handleGetShards is the HTTP handler for the GET /api/v2/shards route.
+func (h *ShardHandler) handleGetShards(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeShardFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	shards, err := h.ShardService.FindShards(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newShardsResponse(shards)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostShardsCompact is the HTTP handler for the POST /api/v2/shards/compact route.
+func (h *ShardHandler) handlePostShardsCompact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.ShardService.CompactShards(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleDeleteShard is the HTTP handler for the DELETE /api/v2/shards/:id route.
+func (h *ShardHandler) handleDeleteShard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeShardFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	id := httprouter.ParamsFromContext(ctx).ByName("id")
+	if err := h.ShardService.DeleteShard(ctx, filter.OrgID, filter.BucketID, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetShardBackup is the HTTP handler for the GET /api/v2/shards/:id/backup route.
+// It responds with 304 if the shard was not updated since the If-Modified-Since header,
+// so that backup tools only download the shards updated since their last run.
+func (h *ShardHandler) handleGetShardBackup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeShardFilter(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	id := httprouter.ParamsFromContext(ctx).ByName("id")
+	shards, err := h.ShardService.FindShards(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	var shard *platform.Shard
+	for _, s := range shards {
+		if s.ID == id {
+			shard = s
+			break
+		}
+	}
+	if shard == nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "shard not found",
+		}, w)
+		return
+	}
+
+	// HTTP dates have a resolution of a second.
+	updatedAt := shard.UpdatedAt.Truncate(time.Second)
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !updatedAt.After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// The headers are written with the first bytes of the archive, so that the errors
+	// returned before can still be encoded.
+	bw := &shardBackupWriter{ResponseWriter: w, header: func(h http.Header) {
+		h.Set("Content-Type", "application/gzip")
+		h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.tar.gz\"", id))
+		h.Set("Last-Modified", updatedAt.Format(http.TimeFormat))
+	}}
+	gw := gzip.NewWriter(bw)
+	if err := h.ShardService.BackupShard(ctx, filter.OrgID, filter.BucketID, id, gw); err != nil {
+		if !bw.written {
+			EncodeError(ctx, err, w)
+			return
+		}
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+	if err := gw.Close(); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// shardBackupWriter sets the headers of the response before its first write.
+type shardBackupWriter struct {
+	http.ResponseWriter
+	header  func(http.Header)
+	written bool
+}
+
+func (w *shardBackupWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		w.header(w.Header())
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+type topShardsResponse struct {
+	Links  map[string]string         `json:"links"`
+	Shards []*platform.ShardActivity `json:"shards"`
+}
+
+// handleGetTopShards is the HTTP handler for the GET /api/v2/ops/shards/top route.
+func (h *ShardHandler) handleGetTopShards(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	opts, err := decodeTopShardsOptions(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	shards, err := h.ShardService.TopShards(ctx, *opts)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if shards == nil {
+		shards = []*platform.ShardActivity{}
+	}
+
+	res := &topShardsResponse{
+		Links: map[string]string{
+			"self": shardsTopPath + "?" + r.URL.RawQuery,
+		},
+		Shards: shards,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodeTopShardsOptions decodes the by and limit parameters.
+func decodeTopShardsOptions(r *http.Request) (*platform.TopShardsOptions, error) {
+	qp := r.URL.Query()
+
+	opts := &platform.TopShardsOptions{By: qp.Get("by")}
+	if limit := qp.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "limit must be a number",
+				Err:  err,
+			}
+		}
+		opts.Limit = l
+	}
+	return opts, opts.Validate()
+}
+
+// decodeShardFilter decodes the orgID and bucketID parameters, which are required.
+func decodeShardFilter(r *http.Request) (*platform.ShardFilter, error) {
+	qp := r.URL.Query()
+
+	var filter platform.ShardFilter
+	if err := filter.OrgID.DecodeFromString(qp.Get(OrgID)); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		}
+	}
+	if err := filter.BucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "bucketID is required",
+			Err:  err,
+		}
+	}
+	return &filter, nil
+}
+
+// ShardService connects to Influx via HTTP using tokens to manage the shards of influxd
+type ShardService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.ShardService = (*ShardService)(nil)
+
+// FindShards returns the shards holding points of the bucket of filter.
+func (s *ShardService) FindShards(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+	u, err := newURL(s.Addr, shardsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = shardFilterQuery(filter.OrgID, filter.BucketID)
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var sr shardsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return nil, err
+	}
+	shards := make([]*platform.Shard, 0, len(sr.Shards))
+	for _, s := range sr.Shards {
+		shards = append(shards, &s.Shard)
+	}
+	return shards, nil
+}
+
+// DeleteShard deletes the points of a bucket held in a shard.
+func (s *ShardService) DeleteShard(ctx context.Context, orgID, bucketID platform.ID, id string) error {
+	u, err := newURL(s.Addr, path.Join(shardsPath, id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = shardFilterQuery(orgID, bucketID)
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+// BackupShard writes to w the tar archive of a shard, with the series index and the series file.
+func (s *ShardService) BackupShard(ctx context.Context, orgID, bucketID platform.ID, id string, w io.Writer) error {
+	u, err := newURL(s.Addr, path.Join(shardsPath, id, "backup"))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = shardFilterQuery(orgID, bucketID)
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	_, err = io.Copy(w, gr)
+	return err
+}
+
+// CompactShards schedules a full compaction of the shards of influxd.
+func (s *ShardService) CompactShards(ctx context.Context) error {
+	u, err := newURL(s.Addr, shardsCompactPath)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(req)
+}
+
+// TopShards returns the most active shards of influxd.
+func (s *ShardService) TopShards(ctx context.Context, opts platform.TopShardsOptions) ([]*platform.ShardActivity, error) {
+	u, err := newURL(s.Addr, shardsTopPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	qp := url.Values{}
+	if opts.By != "" {
+		qp.Set("by", opts.By)
+	}
+	if opts.Limit != 0 {
+		qp.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	req.URL.RawQuery = qp.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr topShardsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+	return tr.Shards, nil
+}
+
+func (s *ShardService) do(req *http.Request) error {
+	hc := newClient(req.URL.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// shardFilterQuery returns the orgID and bucketID parameters of the shards of a bucket.
+func shardFilterQuery(orgID, bucketID platform.ID) string {
+	return url.Values{
+		OrgID:      []string{orgID.String()},
+		"bucketID": []string{bucketID.String()},
+	}.Encode()
+}
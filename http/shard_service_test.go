@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestShardService(t *testing.T) {
+	shards := []*platform.Shard{
+		{
+			ID:          "000000001-000000002",
+			OrgID:       10,
+			BucketID:    1,
+			Size:        4096,
+			BucketSize:  1024,
+			SeriesCount: 3,
+			MinTime:     time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			MaxTime:     time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2019, 3, 2, 0, 0, 1, 0, time.UTC),
+		},
+	}
+
+	var deleted, compacted bool
+	svc := mock.NewShardService()
+	svc.FindShardsFn = func(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+		if filter.OrgID != 10 || filter.BucketID != 1 {
+			return nil, nil
+		}
+		return shards, nil
+	}
+	svc.DeleteShardFn = func(ctx context.Context, orgID, bucketID platform.ID, id string) error {
+		if orgID != 10 || bucketID != 1 || id != shards[0].ID {
+			return &platform.Error{Code: platform.ENotFound, Msg: "shard not found"}
+		}
+		deleted = true
+		return nil
+	}
+	svc.BackupShardFn = func(ctx context.Context, orgID, bucketID platform.ID, id string, w io.Writer) error {
+		_, err := w.Write([]byte("archive of " + id))
+		return err
+	}
+	svc.CompactShardsFn = func(context.Context) error {
+		compacted = true
+		return nil
+	}
+
+	server := httptest.NewServer(NewShardHandler(svc))
+	defer server.Close()
+	client := ShardService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.FindShards(ctx, platform.ShardFilter{OrgID: 10, BucketID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(shards, got); diff != "" {
+		t.Errorf("unexpected shards -want/+got:\n%s", diff)
+	}
+
+	if err := client.DeleteShard(ctx, 10, 1, "000000009-000000001"); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error deleting a missing shard, got %v", err)
+	}
+	if err := client.DeleteShard(ctx, 10, 1, shards[0].ID); err != nil {
+		t.Fatal(err)
+	} else if !deleted {
+		t.Error("expected the shard to be deleted")
+	}
+
+	var buf bytes.Buffer
+	if err := client.BackupShard(ctx, 10, 1, shards[0].ID, &buf); err != nil {
+		t.Fatal(err)
+	} else if got, want := buf.String(), "archive of "+shards[0].ID; got != want {
+		t.Errorf("unexpected archive %q, want %q", got, want)
+	}
+	if err := client.BackupShard(ctx, 10, 1, "000000009-000000001", &buf); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error backing up a missing shard, got %v", err)
+	}
+
+	// The archive is only sent if the shard was updated since the last backup.
+	for since, want := range map[time.Time]int{
+		shards[0].UpdatedAt:                 http.StatusNotModified,
+		shards[0].UpdatedAt.Add(-time.Hour): http.StatusOK,
+	} {
+		req, err := http.NewRequest("GET", server.URL+"/api/v2/shards/"+shards[0].ID+"/backup?"+shardFilterQuery(10, 1), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("If-Modified-Since", since.Format(http.TimeFormat))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("unexpected status %d backing up a shard modified since %s, want %d", resp.StatusCode, since, want)
+		}
+	}
+
+	if err := client.CompactShards(ctx); err != nil {
+		t.Fatal(err)
+	} else if !compacted {
+		t.Error("expected the shards to be compacted")
+	}
+
+	// The bucket of the shards is required.
+	if _, err := client.FindShards(ctx, platform.ShardFilter{OrgID: 10}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected a missing bucketID to be invalid, got %v", err)
+	}
+}
+
+func TestShardService_TopShards(t *testing.T) {
+	activities := []*platform.ShardActivity{
+		{ShardID: platform.CacheShardID, OrgID: 10, BucketID: 1, QueryHits: 5, WrittenValues: 100, WrittenBytes: 1600, CacheSize: 1024},
+		{ShardID: "000000001-000000002", OrgID: 10, BucketID: 2, QueryHits: 3},
+	}
+
+	svc := mock.NewShardService()
+	svc.TopShardsFn = func(ctx context.Context, opts platform.TopShardsOptions) ([]*platform.ShardActivity, error) {
+		if opts.By != platform.ShardsByWrites || opts.Limit != 2 {
+			return nil, nil
+		}
+		return activities, nil
+	}
+
+	server := httptest.NewServer(NewShardHandler(svc))
+	defer server.Close()
+	client := ShardService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.TopShards(ctx, platform.TopShardsOptions{By: platform.ShardsByWrites, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(activities, got); diff != "" {
+		t.Errorf("unexpected shards -want/+got:\n%s", diff)
+	}
+
+	if got, err := client.TopShards(ctx, platform.TopShardsOptions{}); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 0 {
+		t.Errorf("got %d shards, want 0", len(got))
+	}
+
+	for _, query := range []string{"by=size", "limit=ten", "limit=-1"} {
+		resp, err := http.Get(server.URL + shardsTopPath + "?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("unexpected status %d with %s, want %d", resp.StatusCode, query, http.StatusBadRequest)
+		}
+	}
+}
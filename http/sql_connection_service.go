@@ -0,0 +1,584 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// SQLConnectionHandler represents an HTTP API handler for SQL connections
+type SQLConnectionHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	SQLConnectionService platform.SQLConnectionService
+	SQLConnectionChecker platform.SQLConnectionChecker
+	SecretService        platform.SecretService
+}
+
+const (
+	sqlConnectionsPath      = "/api/v2/sqlconnections"
+	sqlConnectionsIDPath    = "/api/v2/sqlconnections/:id"
+	sqlConnectionHealthPath = "/api/v2/sqlconnections/:id/health"
+)
+
+// NewSQLConnectionHandler returns a new instance of SQLConnectionHandler.
+// The data source names sent with SQL connections are stored as secrets with ss.
+func NewSQLConnectionHandler(s platform.SQLConnectionService, c platform.SQLConnectionChecker, ss platform.SecretService) *SQLConnectionHandler {
+	h := &SQLConnectionHandler{
+		Router:               NewRouter(),
+		Logger:               zap.NewNop(),
+		SQLConnectionService: s,
+		SQLConnectionChecker: c,
+		SecretService:        ss,
+	}
+
+	h.HandlerFunc("GET", sqlConnectionsPath, h.handleGetSQLConnections)
+	h.HandlerFunc("POST", sqlConnectionsPath, h.handlePostSQLConnection)
+	h.HandlerFunc("GET", sqlConnectionsIDPath, h.handleGetSQLConnection)
+	h.HandlerFunc("PATCH", sqlConnectionsIDPath, h.handlePatchSQLConnection)
+	h.HandlerFunc("DELETE", sqlConnectionsIDPath, h.handleDeleteSQLConnection)
+	h.HandlerFunc("GET", sqlConnectionHealthPath, h.handleGetSQLConnectionHealth)
+
+	return h
+}
+
+// sqlConnection is used for serialization/deserialization with the lifetime of connections in seconds.
+// DataSourceName is only ever sent to the server, which stores it as the secret of the connection.
+type sqlConnection struct {
+	ID                     platform.ID `json:"id,omitempty"`
+	OrgID                  platform.ID `json:"orgID"`
+	Name                   string      `json:"name"`
+	Description            string      `json:"description,omitempty"`
+	DriverName             string      `json:"driverName"`
+	SecretKey              string      `json:"secretKey"`
+	DataSourceName         string      `json:"dataSourceName,omitempty"`
+	MaxOpenConns           int         `json:"maxOpenConns,omitempty"`
+	MaxIdleConns           int         `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetimeSeconds int64       `json:"connMaxLifetimeSeconds,omitempty"`
+}
+
+func (c *sqlConnection) toPlatform() *platform.SQLConnection {
+	return &platform.SQLConnection{
+		ID:              c.ID,
+		OrgID:           c.OrgID,
+		Name:            c.Name,
+		Description:     c.Description,
+		DriverName:      c.DriverName,
+		SecretKey:       c.SecretKey,
+		MaxOpenConns:    c.MaxOpenConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(c.ConnMaxLifetimeSeconds) * time.Second,
+	}
+}
+
+func newSQLConnection(c *platform.SQLConnection) *sqlConnection {
+	return &sqlConnection{
+		ID:                     c.ID,
+		OrgID:                  c.OrgID,
+		Name:                   c.Name,
+		Description:            c.Description,
+		DriverName:             c.DriverName,
+		SecretKey:              c.SecretKey,
+		MaxOpenConns:           c.MaxOpenConns,
+		MaxIdleConns:           c.MaxIdleConns,
+		ConnMaxLifetimeSeconds: int64(c.ConnMaxLifetime.Round(time.Second) / time.Second),
+	}
+}
+
+// sqlConnectionUpdate is used for serialization/deserialization with the lifetime of connections in seconds.
+type sqlConnectionUpdate struct {
+	Name                   *string `json:"name,omitempty"`
+	Description            *string `json:"description,omitempty"`
+	DriverName             *string `json:"driverName,omitempty"`
+	SecretKey              *string `json:"secretKey,omitempty"`
+	DataSourceName         *string `json:"dataSourceName,omitempty"`
+	MaxOpenConns           *int    `json:"maxOpenConns,omitempty"`
+	MaxIdleConns           *int    `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetimeSeconds *int64  `json:"connMaxLifetimeSeconds,omitempty"`
+}
+
+func (u *sqlConnectionUpdate) toPlatform() platform.SQLConnectionUpdate {
+	upd := platform.SQLConnectionUpdate{
+		Name:         u.Name,
+		Description:  u.Description,
+		DriverName:   u.DriverName,
+		SecretKey:    u.SecretKey,
+		MaxOpenConns: u.MaxOpenConns,
+		MaxIdleConns: u.MaxIdleConns,
+	}
+	if u.ConnMaxLifetimeSeconds != nil {
+		d := time.Duration(*u.ConnMaxLifetimeSeconds) * time.Second
+		upd.ConnMaxLifetime = &d
+	}
+	return upd
+}
+
+func newSQLConnectionUpdate(upd platform.SQLConnectionUpdate) *sqlConnectionUpdate {
+	u := &sqlConnectionUpdate{
+		Name:         upd.Name,
+		Description:  upd.Description,
+		DriverName:   upd.DriverName,
+		SecretKey:    upd.SecretKey,
+		MaxOpenConns: upd.MaxOpenConns,
+		MaxIdleConns: upd.MaxIdleConns,
+	}
+	if upd.ConnMaxLifetime != nil {
+		s := int64(upd.ConnMaxLifetime.Round(time.Second) / time.Second)
+		u.ConnMaxLifetimeSeconds = &s
+	}
+	return u
+}
+
+type sqlConnectionResponse struct {
+	sqlConnection
+	Links map[string]string `json:"links"`
+}
+
+func newSQLConnectionResponse(c *platform.SQLConnection) *sqlConnectionResponse {
+	return &sqlConnectionResponse{
+		sqlConnection: *newSQLConnection(c),
+		Links: map[string]string{
+			"self":   sqlConnectionIDPath(c.ID),
+			"health": path.Join(sqlConnectionIDPath(c.ID), "health"),
+			"org":    fmt.Sprintf("/api/v2/orgs/%s", c.OrgID),
+		},
+	}
+}
+
+type sqlConnectionsResponse struct {
+	Links          map[string]string        `json:"links"`
+	SQLConnections []*sqlConnectionResponse `json:"sqlConnections"`
+}
+
+func newSQLConnectionsResponse(cs []*platform.SQLConnection) *sqlConnectionsResponse {
+	res := &sqlConnectionsResponse{
+		Links: map[string]string{
+			"self": sqlConnectionsPath,
+		},
+		SQLConnections: make([]*sqlConnectionResponse, 0, len(cs)),
+	}
+	for _, c := range cs {
+		res.SQLConnections = append(res.SQLConnections, newSQLConnectionResponse(c))
+	}
+	return res
+}
+
+// sqlConnectionHealthResponse is the result of connecting to the database of a SQL connection.
+type sqlConnectionHealthResponse struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// handleGetSQLConnections is the HTTP handler for the GET /api/v2/sqlconnections route.
+func (h *SQLConnectionHandler) handleGetSQLConnections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetSQLConnectionsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	cs, err := h.SQLConnectionService.FindSQLConnections(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSQLConnectionsResponse(cs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetSQLConnectionsRequest(ctx context.Context, r *http.Request) (platform.SQLConnectionFilter, error) {
+	qp := r.URL.Query()
+	var filter platform.SQLConnectionFilter
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(orgID); err != nil {
+			return filter, err
+		}
+		filter.OrgID = &i
+	}
+
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	return filter, nil
+}
+
+// handlePostSQLConnection is the HTTP handler for the POST /api/v2/sqlconnections route.
+func (h *SQLConnectionHandler) handlePostSQLConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sc := &sqlConnection{}
+	if err := json.NewDecoder(r.Body).Decode(sc); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode sql connection request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	c := sc.toPlatform()
+	if err := c.Validate(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if sc.DataSourceName != "" {
+		if err := h.SecretService.PutSecret(ctx, c.OrgID, c.SecretKey, sc.DataSourceName); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
+	if err := h.SQLConnectionService.CreateSQLConnection(ctx, c); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newSQLConnectionResponse(c)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetSQLConnection is the HTTP handler for the GET /api/v2/sqlconnections/:id route.
+func (h *SQLConnectionHandler) handleGetSQLConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeSQLConnectionID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	c, err := h.SQLConnectionService.FindSQLConnectionByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSQLConnectionResponse(c)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchSQLConnection is the HTTP handler for the PATCH /api/v2/sqlconnections/:id route.
+func (h *SQLConnectionHandler) handlePatchSQLConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeSQLConnectionID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd sqlConnectionUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode sql connection update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	c, err := h.SQLConnectionService.UpdateSQLConnection(ctx, id, upd.toPlatform())
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if upd.DataSourceName != nil {
+		if err := h.SecretService.PutSecret(ctx, c.OrgID, c.SecretKey, *upd.DataSourceName); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSQLConnectionResponse(c)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteSQLConnection is the HTTP handler for the DELETE /api/v2/sqlconnections/:id route.
+func (h *SQLConnectionHandler) handleDeleteSQLConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeSQLConnectionID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.SQLConnectionService.DeleteSQLConnection(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetSQLConnectionHealth is the HTTP handler for the GET /api/v2/sqlconnections/:id/health route.
+// A connection that cannot connect to its database is reported with the fail status, not as an error.
+func (h *SQLConnectionHandler) handleGetSQLConnectionHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeSQLConnectionID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	c, err := h.SQLConnectionService.FindSQLConnectionByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := &sqlConnectionHealthResponse{Name: c.Name, Status: "pass"}
+	if err := h.SQLConnectionChecker.CheckSQLConnection(ctx, c); err != nil {
+		res.Status = "fail"
+		res.Message = err.Error()
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeSQLConnectionID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, err
+	}
+
+	return i, nil
+}
+
+// SQLConnectionService connects to Influx via HTTP using tokens to manage SQL connections
+type SQLConnectionService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.SQLConnectionService = (*SQLConnectionService)(nil)
+
+// FindSQLConnectionByID returns a single SQL connection by ID.
+func (s *SQLConnectionService) FindSQLConnectionByID(ctx context.Context, id platform.ID) (*platform.SQLConnection, error) {
+	u, err := newURL(s.Addr, sqlConnectionIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var cr sqlConnectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return nil, err
+	}
+
+	return cr.toPlatform(), nil
+}
+
+// FindSQLConnections returns the SQL connections that match a filter.
+// Only the organization and the name of the filter are sent to the server.
+func (s *SQLConnectionService) FindSQLConnections(ctx context.Context, filter platform.SQLConnectionFilter) ([]*platform.SQLConnection, error) {
+	u, err := newURL(s.Addr, sqlConnectionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.OrgID != nil {
+		query.Add("orgID", filter.OrgID.String())
+	}
+	if filter.Name != nil {
+		query.Add("name", *filter.Name)
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r sqlConnectionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	cs := make([]*platform.SQLConnection, 0, len(r.SQLConnections))
+	for _, cr := range r.SQLConnections {
+		if c := cr.toPlatform(); filter.Matches(c) {
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+// CreateSQLConnection creates a new SQL connection and sets c.ID with the new identifier.
+// The secret of the data source name of the connection must already exist.
+func (s *SQLConnectionService) CreateSQLConnection(ctx context.Context, c *platform.SQLConnection) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	u, err := newURL(s.Addr, sqlConnectionsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(newSQLConnection(c))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var cr sqlConnectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return err
+	}
+	*c = *cr.toPlatform()
+
+	return nil
+}
+
+// UpdateSQLConnection updates a single SQL connection with changeset.
+// Returns the new SQL connection state after update.
+func (s *SQLConnectionService) UpdateSQLConnection(ctx context.Context, id platform.ID, upd platform.SQLConnectionUpdate) (*platform.SQLConnection, error) {
+	u, err := newURL(s.Addr, sqlConnectionIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(newSQLConnectionUpdate(upd))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var cr sqlConnectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return nil, err
+	}
+
+	return cr.toPlatform(), nil
+}
+
+// DeleteSQLConnection removes a SQL connection by ID.
+func (s *SQLConnectionService) DeleteSQLConnection(ctx context.Context, id platform.ID) error {
+	u, err := newURL(s.Addr, sqlConnectionIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func sqlConnectionIDPath(id platform.ID) string {
+	return path.Join(sqlConnectionsPath, id.String())
+}
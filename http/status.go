@@ -1,6 +1,11 @@
 package http
 
-import "net/http"
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
 
 type statusResponseWriter struct {
 	statusCode int
@@ -19,6 +24,16 @@ func (w *statusResponseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Hijack lets the handler take over the connection, such as to upgrade it to a WebSocket.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking the connection")
+	}
+	w.statusCode = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
+
 func (w *statusResponseWriter) code() int {
 	code := w.statusCode
 	if code == 0 {
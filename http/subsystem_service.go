@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// SubsystemHandler represents an HTTP API handler for the subsystems of influxd
+type SubsystemHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	SubsystemService platform.SubsystemService
+}
+
+const (
+	subsystemsPath        = "/api/v2/ops/subsystems"
+	subsystemsRestartPath = "/api/v2/ops/subsystems/:name/restart"
+)
+
+// NewSubsystemHandler returns a new instance of SubsystemHandler
+func NewSubsystemHandler(s platform.SubsystemService) *SubsystemHandler {
+	h := &SubsystemHandler{
+		Router:           NewRouter(),
+		Logger:           zap.NewNop(),
+		SubsystemService: s,
+	}
+
+	h.HandlerFunc("GET", subsystemsPath, h.handleGetSubsystems)
+	h.HandlerFunc("POST", subsystemsRestartPath, h.handlePostSubsystemRestart)
+
+	return h
+}
+
+type subsystemsResponse struct {
+	Links      map[string]string           `json:"links"`
+	Subsystems []*platform.SubsystemStatus `json:"subsystems"`
+}
+
+// handleGetSubsystems is the HTTP handler for the GET /api/v2/ops/subsystems route.
+func (h *SubsystemHandler) handleGetSubsystems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ss, err := h.SubsystemService.FindSubsystems(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := &subsystemsResponse{
+		Links: map[string]string{
+			"self": subsystemsPath,
+		},
+		Subsystems: ss,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostSubsystemRestart is the HTTP handler for the POST /api/v2/ops/subsystems/:name/restart route.
+func (h *SubsystemHandler) handlePostSubsystemRestart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name := httprouter.ParamsFromContext(ctx).ByName("name")
+	if name == "" {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing name",
+		}, w)
+		return
+	}
+
+	s, err := h.SubsystemService.RestartSubsystem(ctx, name)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, s); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// SubsystemService connects to Influx via HTTP using tokens to view and restart the subsystems of influxd
+type SubsystemService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.SubsystemService = (*SubsystemService)(nil)
+
+// FindSubsystems returns the status of every subsystem, in the order they start.
+func (s *SubsystemService) FindSubsystems(ctx context.Context) ([]*platform.SubsystemStatus, error) {
+	u, err := newURL(s.Addr, subsystemsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var r subsystemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Subsystems, nil
+}
+
+// RestartSubsystem stops and starts again a restartable subsystem, and returns its new status.
+func (s *SubsystemService) RestartSubsystem(ctx context.Context, name string) (*platform.SubsystemStatus, error) {
+	u, err := newURL(s.Addr, path.Join(subsystemsPath, name, "restart"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var st platform.SubsystemStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, err
+	}
+
+	return &st, nil
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestSubsystemService(t *testing.T) {
+	since := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	subsystems := []*platform.SubsystemStatus{
+		{Name: "storage", State: platform.SubsystemRunning, Since: since, Healthy: true},
+		{Name: "gather", State: platform.SubsystemFailed, Since: since, Message: "scraper stopped", Restartable: true},
+	}
+
+	svc := mock.NewSubsystemService()
+	svc.FindSubsystemsFn = func(context.Context) ([]*platform.SubsystemStatus, error) {
+		return subsystems, nil
+	}
+	svc.RestartSubsystemFn = func(ctx context.Context, name string) (*platform.SubsystemStatus, error) {
+		switch name {
+		case "gather":
+			return &platform.SubsystemStatus{Name: name, State: platform.SubsystemRunning, Since: since, Healthy: true, Restartable: true}, nil
+		case "storage":
+			return nil, &platform.Error{Code: platform.EConflict, Msg: "subsystem storage cannot be restarted while influxd runs"}
+		default:
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "subsystem not found"}
+		}
+	}
+
+	server := httptest.NewServer(NewSubsystemHandler(svc))
+	defer server.Close()
+	client := SubsystemService{Addr: server.URL}
+	ctx := context.Background()
+
+	got, err := client.FindSubsystems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(subsystems, got); diff != "" {
+		t.Errorf("unexpected subsystems -want/+got:\n%s", diff)
+	}
+
+	s, err := client.RestartSubsystem(ctx, "gather")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "gather" || s.State != platform.SubsystemRunning {
+		t.Errorf("expected gather running, got %+v", s)
+	}
+
+	if _, err := client.RestartSubsystem(ctx, "storage"); platform.ErrorCode(err) != platform.EConflict {
+		t.Errorf("expected a conflict restarting storage, got %v", err)
+	}
+	if _, err := client.RestartSubsystem(ctx, "nats"); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected restarting an unknown subsystem to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/task/backend"
+	"go.uber.org/zap"
+)
+
+// taskBatchRequest is the body of a POST /api/v2/tasks/batch request.
+type taskBatchRequest struct {
+	Operations []taskBatchOperation `json:"operations"`
+}
+
+// taskBatchOperation is an operation of a task batch: task is the task created by a create,
+// and update the update of the task id by an update.
+type taskBatchOperation struct {
+	Op     string               `json:"op"`
+	ID     platform.ID          `json:"id,omitempty"`
+	Task   *platform.TaskCreate `json:"task,omitempty"`
+	Update *platform.TaskUpdate `json:"update,omitempty"`
+}
+
+// The statuses of the operations of a task batch.
+const (
+	taskBatchApplied    = "applied"
+	taskBatchRolledBack = "rolledBack"
+	taskBatchFailed     = "failed"
+	taskBatchSkipped    = "skipped"
+)
+
+// taskBatchResult is the result of an operation of a task batch.
+type taskBatchResult struct {
+	status string
+	// task is the task created, updated or deleted.
+	task *platform.Task
+	// prev is the task updated or deleted, before the operation is applied.
+	prev *platform.Task
+	err  error
+}
+
+type taskBatchResultResponse struct {
+	Index   int           `json:"index"`
+	Status  string        `json:"status"`
+	Task    *taskResponse `json:"task,omitempty"`
+	Code    string        `json:"code,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type taskBatchResponse struct {
+	Applied bool                      `json:"applied"`
+	Results []taskBatchResultResponse `json:"results"`
+}
+
+func newTaskBatchResponse(applied bool, results []taskBatchResult) *taskBatchResponse {
+	res := &taskBatchResponse{
+		Applied: applied,
+		Results: make([]taskBatchResultResponse, 0, len(results)),
+	}
+	for i, result := range results {
+		r := taskBatchResultResponse{Index: i, Status: result.status}
+		if result.err != nil {
+			r.Code = platform.ErrorCode(result.err)
+			r.Message = platform.ErrorMessage(result.err)
+		}
+		if result.status == taskBatchApplied && result.task != nil {
+			t := newTaskResponse(*result.task, []*platform.Label{})
+			r.Task = &t
+		}
+		res.Results = append(res.Results, r)
+	}
+	return res
+}
+
+// handlePostTaskBatch is the HTTP handler for the POST /api/v2/tasks/batch route.
+//
+// Tasks are not stored in the kv store, so the operations of a batch cannot share a transaction as those
+// of a bucket batch do. Every operation is checked before any is applied instead, and none is applied
+// if an operation is invalid or its task is not found. The creations and the updates are then applied
+// in order, and the deletions last as they cannot be undone. If an operation fails, the creations and
+// the updates applied are undone: the tasks created are deleted, and the tasks updated get back their
+// Flux script, status and params, but not the token an update replaced. The deletions applied before
+// a deletion that fails stay applied.
+//
+// The response is a 400 with the status of every operation if the batch is not applied in full.
+func (h *TaskHandler) handlePostTaskBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EUnauthorized,
+			Msg:  "failed to get authorizer",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ops, err := decodePostTaskBatchRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	results := make([]taskBatchResult, len(ops))
+	failed := 0
+	for i := range ops {
+		results[i].status = taskBatchSkipped
+		if err := h.checkTaskBatchOperation(ctx, &ops[i], &results[i]); err != nil {
+			results[i].status = taskBatchFailed
+			results[i].err = err
+			failed++
+		}
+	}
+
+	applied := failed == 0 && h.applyTaskBatch(ctx, auth, ops, results)
+
+	status := http.StatusOK
+	if !applied {
+		status = http.StatusBadRequest
+	}
+	if err := encodeResponse(ctx, w, status, newTaskBatchResponse(applied, results)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// checkTaskBatchOperation resolves the organization of a task created by op,
+// and finds the task updated or deleted by op.
+func (h *TaskHandler) checkTaskBatchOperation(ctx context.Context, op *platform.TaskBatchOperation, res *taskBatchResult) error {
+	if op.Op == platform.TaskBatchCreate {
+		if err := h.populateTaskCreateOrg(ctx, op.Create); err != nil {
+			return &platform.Error{
+				Err: err,
+				Msg: "could not identify organization",
+			}
+		}
+		return nil
+	}
+
+	t, err := h.TaskService.FindTaskByID(ctx, op.ID)
+	if err != nil {
+		return newTaskBatchError(err, "failed to find task")
+	}
+	res.prev = t
+	return nil
+}
+
+// applyTaskBatch applies the operations of a batch, undoing the operations applied if one fails.
+// It returns whether every operation is applied.
+func (h *TaskHandler) applyTaskBatch(ctx context.Context, auth platform.Authorizer, ops []platform.TaskBatchOperation, results []taskBatchResult) bool {
+	order := make([]int, 0, len(ops))
+	for i, op := range ops {
+		if op.Op != platform.TaskBatchDelete {
+			order = append(order, i)
+		}
+	}
+	for i, op := range ops {
+		if op.Op == platform.TaskBatchDelete {
+			order = append(order, i)
+		}
+	}
+
+	for n, i := range order {
+		if err := h.applyTaskBatchOperation(ctx, auth, ops[i], &results[i]); err != nil {
+			results[i].status = taskBatchFailed
+			results[i].err = err
+			h.undoTaskBatch(ctx, ops, results, order[:n])
+			return false
+		}
+		results[i].status = taskBatchApplied
+	}
+	return true
+}
+
+func (h *TaskHandler) applyTaskBatchOperation(ctx context.Context, auth platform.Authorizer, op platform.TaskBatchOperation, res *taskBatchResult) error {
+	switch op.Op {
+	case platform.TaskBatchCreate:
+		t, err := h.createTask(ctx, auth, *op.Create)
+		if err != nil {
+			return err
+		}
+		res.task = t
+	case platform.TaskBatchUpdate:
+		t, err := h.TaskService.UpdateTask(ctx, op.ID, *op.Update)
+		if err != nil {
+			return newTaskBatchError(err, "failed to update task")
+		}
+		res.task = t
+	case platform.TaskBatchDelete:
+		if err := h.TaskService.DeleteTask(ctx, op.ID); err != nil {
+			return newTaskBatchError(err, "failed to delete task")
+		}
+		if h.TaskWebhookService != nil {
+			if err := h.deleteTaskWebhooks(ctx, op.ID); err != nil {
+				h.logger.Info("Failed to delete webhooks of deleted task", zap.Stringer("task_id", op.ID), zap.Error(err))
+			}
+		}
+		res.task = res.prev
+	}
+	return nil
+}
+
+// undoTaskBatch undoes the creations and the updates of applied, the indexes of the operations applied in order.
+// An operation that cannot be undone stays applied.
+func (h *TaskHandler) undoTaskBatch(ctx context.Context, ops []platform.TaskBatchOperation, results []taskBatchResult, applied []int) {
+	for n := len(applied) - 1; n >= 0; n-- {
+		i := applied[n]
+		var err error
+		switch ops[i].Op {
+		case platform.TaskBatchCreate:
+			err = h.TaskService.DeleteTask(ctx, results[i].task.ID)
+		case platform.TaskBatchUpdate:
+			prev := results[i].prev
+			params := prev.Params
+			if params == nil {
+				params = map[string]string{}
+			}
+			_, err = h.TaskService.UpdateTask(ctx, prev.ID, platform.TaskUpdate{Flux: &prev.Flux, Status: &prev.Status, Params: params})
+		default:
+			continue
+		}
+		if err != nil {
+			h.logger.Info("Failed to undo operation of task batch", zap.Int("index", i), zap.String("op", ops[i].Op), zap.Error(err))
+			continue
+		}
+		results[i].status = taskBatchRolledBack
+	}
+}
+
+// newTaskBatchError wraps the error of the task service in a *platform.Error, as the handlers of a single task do.
+func newTaskBatchError(err error, msg string) error {
+	perr := &platform.Error{
+		Err: err,
+		Msg: msg,
+	}
+	if err == backend.ErrTaskNotFound {
+		perr.Code = platform.ENotFound
+	}
+	if _, ok := err.(backend.QuotaExceededError); ok {
+		perr.Code = platform.EForbidden
+	}
+	return perr
+}
+
+func decodePostTaskBatchRequest(r *http.Request) ([]platform.TaskBatchOperation, error) {
+	req := &taskBatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "batch requires at least one operation",
+		}
+	}
+	if len(req.Operations) > platform.MaxTaskBatchSize {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("batch has %d operations; the most a batch can have is %d", len(req.Operations), platform.MaxTaskBatchSize),
+		}
+	}
+
+	ops := make([]platform.TaskBatchOperation, 0, len(req.Operations))
+	for i, o := range req.Operations {
+		op := platform.TaskBatchOperation{
+			Op:     o.Op,
+			ID:     o.ID,
+			Create: o.Task,
+			Update: o.Update,
+		}
+		if err := op.Validate(); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("operation %d", i),
+				Err:  err,
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
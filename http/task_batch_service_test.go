@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// newBatchTaskService returns a task service keeping the tasks in tasks, failing the deletion of the task failDelete.
+func newBatchTaskService(tasks map[platform.ID]platform.Task, failDelete platform.ID) *mock.TaskService {
+	next := platform.ID(100)
+	return &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			t, ok := tasks[id]
+			if !ok {
+				return nil, backend.ErrTaskNotFound
+			}
+			return &t, nil
+		},
+		CreateTaskFn: func(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+			next++
+			t := platform.Task{ID: next, OrganizationID: tc.OrganizationID, AuthorizationID: 0x100, Organization: tc.Organization, Flux: tc.Flux, Status: tc.Status}
+			tasks[t.ID] = t
+			return &t, nil
+		},
+		UpdateTaskFn: func(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+			t, ok := tasks[id]
+			if !ok {
+				return nil, backend.ErrTaskNotFound
+			}
+			if upd.Flux != nil {
+				t.Flux = *upd.Flux
+			}
+			if upd.Status != nil {
+				t.Status = *upd.Status
+			}
+			tasks[id] = t
+			return &t, nil
+		},
+		DeleteTaskFn: func(ctx context.Context, id platform.ID) error {
+			if id == failDelete {
+				return errors.New("delete failed")
+			}
+			if _, ok := tasks[id]; !ok {
+				return backend.ErrTaskNotFound
+			}
+			delete(tasks, id)
+			return nil
+		},
+	}
+}
+
+func TestTaskHandler_handlePostTaskBatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		failDelete   platform.ID
+		wantStatus   int
+		wantStatuses []string
+		wantTasks    map[platform.ID]platform.Task
+	}{
+		{
+			name: "applied batch",
+			body: `
+{
+  "operations": [
+    {"op": "delete", "id": "0000000000000002"},
+    {"op": "create", "task": {"orgID": "0000000000000001", "flux": "c"}},
+    {"op": "update", "id": "0000000000000001", "update": {"status": "inactive"}}
+  ]
+}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []string{"applied", "applied", "applied"},
+			wantTasks: map[platform.ID]platform.Task{
+				1:   {ID: 1, OrganizationID: 1, AuthorizationID: 0x100, Flux: "a", Status: "inactive"},
+				101: {ID: 101, OrganizationID: 1, AuthorizationID: 0x100, Organization: "test", Flux: "c"},
+			},
+		},
+		{
+			name: "task not found",
+			body: `
+{
+  "operations": [
+    {"op": "create", "task": {"orgID": "0000000000000001", "flux": "c"}},
+    {"op": "update", "id": "00000000000000ff", "update": {"status": "inactive"}}
+  ]
+}`,
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []string{"skipped", "failed"},
+		},
+		{
+			name: "failed operation",
+			body: `
+{
+  "operations": [
+    {"op": "create", "task": {"orgID": "0000000000000001", "flux": "c"}},
+    {"op": "delete", "id": "0000000000000002"},
+    {"op": "update", "id": "0000000000000001", "update": {"flux": "b", "status": "inactive"}}
+  ]
+}`,
+			failDelete:   2,
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []string{"rolledBack", "failed", "rolledBack"},
+		},
+		{
+			name:       "invalid operation",
+			body:       `{"operations": [{"op": "update", "id": "0000000000000001", "update": {}}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty batch",
+			body:       `{"operations": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initial := map[platform.ID]platform.Task{
+				1: {ID: 1, OrganizationID: 1, AuthorizationID: 0x100, Flux: "a", Status: "active"},
+				2: {ID: 2, OrganizationID: 1, AuthorizationID: 0x100, Flux: "b", Status: "active"},
+			}
+			tasks := make(map[platform.ID]platform.Task, len(initial))
+			for id, task := range initial {
+				tasks[id] = task
+			}
+
+			taskBackend := NewMockTaskBackend(t)
+			taskBackend.TaskService = newBatchTaskService(tasks, tt.failDelete)
+			h := NewTaskHandler(taskBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/batch", strings.NewReader(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), new(platform.Authorization)))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatuses == nil {
+				return
+			}
+
+			var body struct {
+				Applied bool `json:"applied"`
+				Results []struct {
+					Status string `json:"status"`
+				} `json:"results"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			var statuses []string
+			for _, r := range body.Results {
+				statuses = append(statuses, r.Status)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatuses) {
+				t.Errorf("got statuses %v, want %v", statuses, tt.wantStatuses)
+			}
+			if body.Applied != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("unexpected applied %v", body.Applied)
+			}
+
+			want := tt.wantTasks
+			if want == nil {
+				want = initial
+			}
+			if !reflect.DeepEqual(tasks, want) {
+				t.Errorf("got tasks %+v, want %+v", tasks, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TaskDependencyHandler represents an HTTP API handler for the resources referenced by tasks,
+// answering which tasks break when a bucket, a secret, a SQL connection or an endpoint goes away.
+type TaskDependencyHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	TaskDependencyService platform.TaskDependencyService
+	BucketService         platform.BucketService
+}
+
+const (
+	taskDependenciesPath = "/api/v2/dependencies"
+)
+
+// NewTaskDependencyHandler returns a new instance of TaskDependencyHandler
+func NewTaskDependencyHandler(s platform.TaskDependencyService, bs platform.BucketService) *TaskDependencyHandler {
+	h := &TaskDependencyHandler{
+		Router:                NewRouter(),
+		Logger:                zap.NewNop(),
+		TaskDependencyService: s,
+		BucketService:         bs,
+	}
+
+	h.HandlerFunc("GET", taskDependenciesPath, h.handleGetTaskDependencies)
+
+	return h
+}
+
+type taskDependenciesResponse struct {
+	platform.TaskDependencies
+	Links map[string]string `json:"links"`
+}
+
+func newTaskDependenciesResponse(d *platform.TaskDependencies) *taskDependenciesResponse {
+	return &taskDependenciesResponse{
+		TaskDependencies: *d,
+		Links: map[string]string{
+			"task": "/api/v2/tasks/" + d.TaskID.String(),
+			"org":  "/api/v2/orgs/" + d.OrgID.String(),
+		},
+	}
+}
+
+type tasksDependenciesResponse struct {
+	Links map[string]string           `json:"links"`
+	Tasks []*taskDependenciesResponse `json:"tasks"`
+}
+
+func newTasksDependenciesResponse(ds []*platform.TaskDependencies) *tasksDependenciesResponse {
+	res := &tasksDependenciesResponse{
+		Links: map[string]string{
+			"self": taskDependenciesPath,
+		},
+		Tasks: make([]*taskDependenciesResponse, 0, len(ds)),
+	}
+	for _, d := range ds {
+		res.Tasks = append(res.Tasks, newTaskDependenciesResponse(d))
+	}
+	return res
+}
+
+// handleGetTaskDependencies is the HTTP handler for the GET /api/v2/dependencies route.
+// The type and name parameters find the tasks referencing a resource. The bucketID parameter
+// finds the tasks referencing a bucket by ID or by name within the organization of the bucket.
+func (h *TaskDependencyHandler) handleGetTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := h.decodeGetTaskDependenciesRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ds, err := h.TaskDependencyService.FindTaskDependencies(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTasksDependenciesResponse(ds)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *TaskDependencyHandler) decodeGetTaskDependenciesRequest(r *http.Request) (platform.TaskDependencyFilter, error) {
+	var filter platform.TaskDependencyFilter
+	qp := r.URL.Query()
+	if taskID := qp.Get("taskID"); taskID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(taskID); err != nil {
+			return filter, err
+		}
+		filter.TaskID = &i
+	}
+	if orgID := qp.Get("orgID"); orgID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(orgID); err != nil {
+			return filter, err
+		}
+		filter.OrgID = &i
+	}
+
+	typ, name := platform.TaskDependencyType(qp.Get("type")), qp.Get("name")
+	if typ != "" || name != "" {
+		switch typ {
+		case platform.TaskDependencyBucket, platform.TaskDependencyBucketID, platform.TaskDependencySecret,
+			platform.TaskDependencySQLConnection, platform.TaskDependencyEndpoint:
+		default:
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "type must be bucket, bucketID, secret, sqlConnection or endpoint",
+			}
+		}
+		if name == "" {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "name is required with type",
+			}
+		}
+		filter.References = append(filter.References, platform.TaskDependency{Type: typ, Name: name})
+	}
+
+	if bucketID := qp.Get("bucketID"); bucketID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(bucketID); err != nil {
+			return filter, err
+		}
+		b, err := h.BucketService.FindBucketByID(r.Context(), i)
+		if err != nil {
+			return filter, err
+		}
+		if filter.OrgID != nil && *filter.OrgID != b.OrganizationID {
+			return filter, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "bucket is not in the organization orgID",
+			}
+		}
+		// Bucket names are unique within an organization only.
+		filter.OrgID = &b.OrganizationID
+		filter.References = append(filter.References,
+			platform.TaskDependency{Type: platform.TaskDependencyBucketID, Name: b.ID.String()},
+			platform.TaskDependency{Type: platform.TaskDependencyBucket, Name: b.Name},
+		)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestTaskDependencyHandler_handleGetTaskDependencies(t *testing.T) {
+	var gotFilter platform.TaskDependencyFilter
+	ds := mock.NewTaskDependencyService()
+	ds.FindTaskDependenciesFn = func(ctx context.Context, filter platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error) {
+		gotFilter = filter
+		return []*platform.TaskDependencies{
+			{
+				TaskID: 1,
+				OrgID:  10,
+				Dependencies: []platform.TaskDependency{
+					{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+				},
+			},
+		}, nil
+	}
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, OrganizationID: 10, Name: "telemetry"}, nil
+	}
+	h := NewTaskDependencyHandler(ds, bs)
+
+	orgID := platform.ID(10)
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantFilter platform.TaskDependencyFilter
+		wantBody   string
+	}{
+		{
+			name:       "tasks depending on a secret",
+			url:        "/api/v2/dependencies?type=secret&name=api-token",
+			wantStatus: http.StatusOK,
+			wantFilter: platform.TaskDependencyFilter{References: []platform.TaskDependency{
+				{Type: platform.TaskDependencySecret, Name: "api-token"},
+			}},
+			wantBody: `
+{
+  "links": {
+    "self": "/api/v2/dependencies"
+  },
+  "tasks": [
+    {
+      "taskID": "0000000000000001",
+      "orgID": "000000000000000a",
+      "dependencies": [
+        {
+          "type": "bucket",
+          "name": "telemetry"
+        }
+      ],
+      "updatedAt": "0001-01-01T00:00:00Z",
+      "links": {
+        "task": "/api/v2/tasks/0000000000000001",
+        "org": "/api/v2/orgs/000000000000000a"
+      }
+    }
+  ]
+}`,
+		},
+		{
+			name:       "tasks depending on a bucket by id or by name",
+			url:        "/api/v2/dependencies?bucketID=000000000000000b",
+			wantStatus: http.StatusOK,
+			wantFilter: platform.TaskDependencyFilter{
+				OrgID: &orgID,
+				References: []platform.TaskDependency{
+					{Type: platform.TaskDependencyBucketID, Name: "000000000000000b"},
+					{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+				},
+			},
+		},
+		{
+			name:       "unknown type",
+			url:        "/api/v2/dependencies?type=dashboard&name=a",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "type without name",
+			url:        "/api/v2/dependencies?type=bucket",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bucket of another org",
+			url:        "/api/v2/dependencies?bucketID=000000000000000b&orgID=000000000000000c",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFilter = platform.TaskDependencyFilter{}
+			r := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if diff := cmp.Diff(gotFilter, tt.wantFilter); diff != "" {
+				t.Errorf("unexpected filter -got/+want\n%s", diff)
+			}
+			if tt.wantBody != "" {
+				if eq, diff, _ := jsonEqual(w.Body.String(), tt.wantBody); !eq {
+					t.Errorf("unexpected body -got/+want\n%s", diff)
+				}
+			}
+		})
+	}
+}
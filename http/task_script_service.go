@@ -0,0 +1,427 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TaskScriptHandler represents an HTTP API handler for the versioned scripts that tasks include.
+type TaskScriptHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	TaskScriptService platform.TaskScriptService
+}
+
+const (
+	taskScriptsPath            = "/api/v2/scripts"
+	taskScriptsNamePath        = "/api/v2/scripts/:name"
+	taskScriptsNameVersionPath = "/api/v2/scripts/:name/versions"
+)
+
+// NewTaskScriptHandler returns a new instance of TaskScriptHandler
+func NewTaskScriptHandler(s platform.TaskScriptService) *TaskScriptHandler {
+	h := &TaskScriptHandler{
+		Router:            NewRouter(),
+		Logger:            zap.NewNop(),
+		TaskScriptService: s,
+	}
+
+	h.HandlerFunc("GET", taskScriptsPath, h.handleGetTaskScripts)
+	h.HandlerFunc("POST", taskScriptsPath, h.handlePostTaskScript)
+	h.HandlerFunc("GET", taskScriptsNamePath, h.handleGetTaskScript)
+	h.HandlerFunc("DELETE", taskScriptsNamePath, h.handleDeleteTaskScript)
+	h.HandlerFunc("GET", taskScriptsNameVersionPath, h.handleGetTaskScriptVersions)
+
+	return h
+}
+
+type taskScriptResponse struct {
+	platform.TaskScript
+	Links map[string]string `json:"links"`
+}
+
+func newTaskScriptResponse(s *platform.TaskScript) *taskScriptResponse {
+	return &taskScriptResponse{
+		TaskScript: *s,
+		Links: map[string]string{
+			"self":     taskScriptVersionPath(s.OrgID, s.Name, s.Version),
+			"latest":   taskScriptPath(s.OrgID, s.Name),
+			"versions": taskScriptVersionsPath(s.OrgID, s.Name),
+			"org":      "/api/v2/orgs/" + s.OrgID.String(),
+		},
+	}
+}
+
+type taskScriptsResponse struct {
+	Links   map[string]string     `json:"links"`
+	Scripts []*taskScriptResponse `json:"scripts"`
+}
+
+func newTaskScriptsResponse(self string, ss []*platform.TaskScript) *taskScriptsResponse {
+	res := &taskScriptsResponse{
+		Links: map[string]string{
+			"self": self,
+		},
+		Scripts: make([]*taskScriptResponse, 0, len(ss)),
+	}
+	for _, s := range ss {
+		res.Scripts = append(res.Scripts, newTaskScriptResponse(s))
+	}
+	return res
+}
+
+// handleGetTaskScripts is the HTTP handler for the GET /api/v2/scripts route.
+func (h *TaskScriptHandler) handleGetTaskScripts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter platform.TaskScriptFilter
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		var i platform.ID
+		if err := i.DecodeFromString(orgID); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		filter.OrgID = &i
+	}
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	ss, err := h.TaskScriptService.FindTaskScripts(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskScriptsResponse(taskScriptsPath, ss)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostTaskScript is the HTTP handler for the POST /api/v2/scripts route.
+// Posting a script with the name of an existing script creates its next version.
+func (h *TaskScriptHandler) handlePostTaskScript(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	s := &platform.TaskScript{}
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode task script request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.TaskScriptService.CreateTaskScript(ctx, s); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskScriptResponse(s)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetTaskScript is the HTTP handler for the GET /api/v2/scripts/:name route.
+func (h *TaskScriptHandler) handleGetTaskScript(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeTaskScriptRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	s, err := h.TaskScriptService.FindTaskScript(ctx, req.orgID, req.name, req.version)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskScriptResponse(s)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetTaskScriptVersions is the HTTP handler for the GET /api/v2/scripts/:name/versions route.
+func (h *TaskScriptHandler) handleGetTaskScriptVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeTaskScriptRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ss, err := h.TaskScriptService.FindTaskScriptVersions(ctx, req.orgID, req.name)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskScriptsResponse(taskScriptVersionsPath(req.orgID, req.name), ss)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteTaskScript is the HTTP handler for the DELETE /api/v2/scripts/:name route.
+// It deletes every version of the script.
+func (h *TaskScriptHandler) handleDeleteTaskScript(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeTaskScriptRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.TaskScriptService.DeleteTaskScript(ctx, req.orgID, req.name); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type taskScriptRequest struct {
+	orgID   platform.ID
+	name    string
+	version int
+}
+
+func decodeTaskScriptRequest(ctx context.Context, r *http.Request) (*taskScriptRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	req := &taskScriptRequest{
+		name: params.ByName("name"),
+	}
+	if req.name == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing name",
+		}
+	}
+
+	qp := r.URL.Query()
+	orgID := qp.Get("orgID")
+	if orgID == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if err := req.orgID.DecodeFromString(orgID); err != nil {
+		return nil, err
+	}
+
+	if version := qp.Get("version"); version != "" {
+		v, err := strconv.Atoi(version)
+		if err != nil || v < 1 {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "version must be a positive integer",
+				Err:  err,
+			}
+		}
+		req.version = v
+	}
+
+	return req, nil
+}
+
+// TaskScriptService connects to Influx via HTTP using tokens to manage task scripts
+type TaskScriptService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.TaskScriptService = (*TaskScriptService)(nil)
+
+// FindTaskScript returns a version of the named script of an organization, or its latest version if version is 0.
+func (s *TaskScriptService) FindTaskScript(ctx context.Context, orgID platform.ID, name string, version int) (*platform.TaskScript, error) {
+	u, err := newURL(s.Addr, path.Join(taskScriptsPath, name))
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("orgID", orgID.String())
+	if version != 0 {
+		query.Add("version", strconv.Itoa(version))
+	}
+
+	var r taskScriptResponse
+	if err := s.get(u.String(), query.Encode(), &r); err != nil {
+		return nil, err
+	}
+	return &r.TaskScript, nil
+}
+
+// FindTaskScripts returns the latest version of the scripts that match a filter, sorted by name.
+func (s *TaskScriptService) FindTaskScripts(ctx context.Context, filter platform.TaskScriptFilter) ([]*platform.TaskScript, error) {
+	u, err := newURL(s.Addr, taskScriptsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if filter.OrgID != nil {
+		query.Add("orgID", filter.OrgID.String())
+	}
+	if filter.Name != nil {
+		query.Add("name", *filter.Name)
+	}
+
+	var r taskScriptsResponse
+	if err := s.get(u.String(), query.Encode(), &r); err != nil {
+		return nil, err
+	}
+	return r.toPlatform(), nil
+}
+
+// FindTaskScriptVersions returns every version of the named script of an organization, latest first.
+func (s *TaskScriptService) FindTaskScriptVersions(ctx context.Context, orgID platform.ID, name string) ([]*platform.TaskScript, error) {
+	u, err := newURL(s.Addr, path.Join(taskScriptsPath, name, "versions"))
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Add("orgID", orgID.String())
+
+	var r taskScriptsResponse
+	if err := s.get(u.String(), query.Encode(), &r); err != nil {
+		return nil, err
+	}
+	return r.toPlatform(), nil
+}
+
+func (s *TaskScriptService) get(url, rawQuery string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = rawQuery
+	SetToken(s.Token, req)
+
+	hc := newClient(req.URL.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (r *taskScriptsResponse) toPlatform() []*platform.TaskScript {
+	ss := make([]*platform.TaskScript, 0, len(r.Scripts))
+	for _, s := range r.Scripts {
+		ss = append(ss, &s.TaskScript)
+	}
+	return ss
+}
+
+// CreateTaskScript stores ts as a new version of the script named ts.Name,
+// and sets ts.ID, ts.Version and ts.CreatedAt.
+func (s *TaskScriptService) CreateTaskScript(ctx context.Context, ts *platform.TaskScript) error {
+	if err := ts.Validate(); err != nil {
+		return err
+	}
+
+	u, err := newURL(s.Addr, taskScriptsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(ts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var r taskScriptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	*ts = r.TaskScript
+
+	return nil
+}
+
+// DeleteTaskScript removes every version of the named script of an organization.
+func (s *TaskScriptService) DeleteTaskScript(ctx context.Context, orgID platform.ID, name string) error {
+	u, err := newURL(s.Addr, path.Join(taskScriptsPath, name))
+	if err != nil {
+		return err
+	}
+
+	query := u.Query()
+	query.Add("orgID", orgID.String())
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func taskScriptPath(orgID platform.ID, name string) string {
+	return path.Join(taskScriptsPath, name) + "?orgID=" + orgID.String()
+}
+
+func taskScriptVersionPath(orgID platform.ID, name string, version int) string {
+	return taskScriptPath(orgID, name) + "&version=" + strconv.Itoa(version)
+}
+
+func taskScriptVersionsPath(orgID platform.ID, name string) string {
+	return path.Join(taskScriptsPath, name, "versions") + "?orgID=" + orgID.String()
+}
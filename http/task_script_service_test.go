@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func initTaskScriptService(f platformtesting.TaskScriptFields, t *testing.T) (platform.TaskScriptService, string, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = f.IDGenerator
+	svc.WithTime(f.NowFn)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("failed to initialize task script service: %v", err)
+	}
+	for _, s := range f.TaskScripts {
+		if err := svc.PutTaskScript(ctx, s); err != nil {
+			t.Fatalf("failed to populate task scripts: %v", err)
+		}
+	}
+
+	handler := NewTaskScriptHandler(svc)
+	server := httptest.NewServer(handler)
+	client := TaskScriptService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, kv.OpPrefix, done
+}
+
+func TestTaskScriptService(t *testing.T) {
+	platformtesting.TaskScriptService(initTaskScriptService, t)
+}
+
+func TestTaskScriptHandler_handleGetTaskScript(t *testing.T) {
+	var gotVersion int
+	svc := mock.NewTaskScriptService()
+	svc.FindTaskScriptFn = func(ctx context.Context, orgID platform.ID, name string, version int) (*platform.TaskScript, error) {
+		gotVersion = version
+		return &platform.TaskScript{ID: 1, OrgID: orgID, Name: name, Version: 2, Flux: `x = 1`}, nil
+	}
+	h := NewTaskScriptHandler(svc)
+
+	tests := []struct {
+		name        string
+		url         string
+		wantStatus  int
+		wantVersion int
+		wantBody    string
+	}{
+		{
+			name:       "latest version",
+			url:        "/api/v2/scripts/helpers?orgID=000000000000000a",
+			wantStatus: http.StatusOK,
+			wantBody: `
+{
+  "id": "0000000000000001",
+  "orgID": "000000000000000a",
+  "name": "helpers",
+  "version": 2,
+  "flux": "x = 1",
+  "createdAt": "0001-01-01T00:00:00Z",
+  "links": {
+    "self": "/api/v2/scripts/helpers?orgID=000000000000000a&version=2",
+    "latest": "/api/v2/scripts/helpers?orgID=000000000000000a",
+    "versions": "/api/v2/scripts/helpers/versions?orgID=000000000000000a",
+    "org": "/api/v2/orgs/000000000000000a"
+  }
+}`,
+		},
+		{
+			name:        "pinned version",
+			url:         "/api/v2/scripts/helpers?orgID=000000000000000a&version=2",
+			wantStatus:  http.StatusOK,
+			wantVersion: 2,
+		},
+		{
+			name:       "missing org",
+			url:        "/api/v2/scripts/helpers",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid version",
+			url:        "/api/v2/scripts/helpers?orgID=000000000000000a&version=0",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotVersion = 0
+			r := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if gotVersion != tt.wantVersion {
+				t.Errorf("got version %d, want %d", gotVersion, tt.wantVersion)
+			}
+			if tt.wantBody != "" {
+				if eq, diff, _ := jsonEqual(w.Body.String(), tt.wantBody); !eq {
+					t.Errorf("unexpected body -got/+want\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -38,6 +40,8 @@ type TaskBackend struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	BucketService              platform.BucketService
+	TaskWebhookService         platform.TaskWebhookService
+	TaskScriptService          platform.TaskScriptService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -51,6 +55,8 @@ func NewTaskBackend(b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskWebhookService:         b.TaskWebhookService,
+		TaskScriptService:          b.TaskScriptService,
 	}
 }
 
@@ -66,22 +72,35 @@ type TaskHandler struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	BucketService              platform.BucketService
+	TaskWebhookService         platform.TaskWebhookService
+	TaskScriptService          platform.TaskScriptService
 }
 
 const (
-	tasksPath              = "/api/v2/tasks"
-	tasksIDPath            = "/api/v2/tasks/:id"
-	tasksIDLogsPath        = "/api/v2/tasks/:id/logs"
-	tasksIDMembersPath     = "/api/v2/tasks/:id/members"
-	tasksIDMembersIDPath   = "/api/v2/tasks/:id/members/:userID"
-	tasksIDOwnersPath      = "/api/v2/tasks/:id/owners"
-	tasksIDOwnersIDPath    = "/api/v2/tasks/:id/owners/:userID"
-	tasksIDRunsPath        = "/api/v2/tasks/:id/runs"
-	tasksIDRunsIDPath      = "/api/v2/tasks/:id/runs/:rid"
-	tasksIDRunsIDLogsPath  = "/api/v2/tasks/:id/runs/:rid/logs"
-	tasksIDRunsIDRetryPath = "/api/v2/tasks/:id/runs/:rid/retry"
-	tasksIDLabelsPath      = "/api/v2/tasks/:id/labels"
-	tasksIDLabelsIDPath    = "/api/v2/tasks/:id/labels/:lid"
+	tasksPath                     = "/api/v2/tasks"
+	tasksIDPath                   = "/api/v2/tasks/:id"
+	tasksDryRunID                 = "dry-run"
+	tasksImportID                 = "import"
+	tasksValidateScheduleID       = "validate-schedule"
+	tasksBatchID                  = "batch"
+	tasksRunsExportID             = "export"
+	tasksIDLogsPath               = "/api/v2/tasks/:id/logs"
+	tasksIDClonePath              = "/api/v2/tasks/:id/clone"
+	tasksIDMovePath               = "/api/v2/tasks/:id/move"
+	tasksIDExportPath             = "/api/v2/tasks/:id/export"
+	tasksIDSchedulePath           = "/api/v2/tasks/:id/schedule"
+	tasksIDVersionsPath           = "/api/v2/tasks/:id/versions"
+	tasksIDVersionsIDRollbackPath = "/api/v2/tasks/:id/versions/:version/rollback"
+	tasksIDMembersPath            = "/api/v2/tasks/:id/members"
+	tasksIDMembersIDPath          = "/api/v2/tasks/:id/members/:userID"
+	tasksIDOwnersPath             = "/api/v2/tasks/:id/owners"
+	tasksIDOwnersIDPath           = "/api/v2/tasks/:id/owners/:userID"
+	tasksIDRunsPath               = "/api/v2/tasks/:id/runs"
+	tasksIDRunsIDPath             = "/api/v2/tasks/:id/runs/:rid"
+	tasksIDRunsIDLogsPath         = "/api/v2/tasks/:id/runs/:rid/logs"
+	tasksIDRunsIDRetryPath        = "/api/v2/tasks/:id/runs/:rid/retry"
+	tasksIDLabelsPath             = "/api/v2/tasks/:id/labels"
+	tasksIDLabelsIDPath           = "/api/v2/tasks/:id/labels/:lid"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -97,15 +116,31 @@ func NewTaskHandler(b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskWebhookService:         b.TaskWebhookService,
+		TaskScriptService:          b.TaskScriptService,
 	}
 
 	h.HandlerFunc("GET", tasksPath, h.handleGetTasks)
 	h.HandlerFunc("POST", tasksPath, h.handlePostTask)
 
+	// httprouter does not allow the static dry-run, import, validate-schedule and batch paths to sit alongside the :id wildcard,
+	// so POST /api/v2/tasks/dry-run, POST /api/v2/tasks/import, POST /api/v2/tasks/validate-schedule
+	// and POST /api/v2/tasks/batch are dispatched by the POST handler for tasksIDPath.
+	h.HandlerFunc("POST", tasksIDPath, h.handlePostTaskID)
+
 	h.HandlerFunc("GET", tasksIDPath, h.handleGetTask)
 	h.HandlerFunc("PATCH", tasksIDPath, h.handleUpdateTask)
 	h.HandlerFunc("DELETE", tasksIDPath, h.handleDeleteTask)
 
+	h.HandlerFunc("GET", tasksIDSchedulePath, h.handleGetTaskSchedule)
+
+	h.HandlerFunc("GET", tasksIDVersionsPath, h.handleGetTaskVersions)
+	h.HandlerFunc("POST", tasksIDVersionsIDRollbackPath, h.handleRollbackTask)
+
+	h.HandlerFunc("POST", tasksIDClonePath, h.handleCloneTask)
+	h.HandlerFunc("POST", tasksIDMovePath, h.handleMoveTask)
+	h.HandlerFunc("GET", tasksIDExportPath, h.handleExportTask)
+
 	h.HandlerFunc("GET", tasksIDLogsPath, h.handleGetLogs)
 	h.HandlerFunc("GET", tasksIDRunsIDLogsPath, h.handleGetLogs)
 
@@ -132,11 +167,20 @@ func NewTaskHandler(b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("DELETE", tasksIDOwnersIDPath, newDeleteMemberHandler(ownerBackend))
 
 	h.HandlerFunc("GET", tasksIDRunsPath, h.handleGetRuns)
+	// GET /api/v2/tasks/:id/runs/export is dispatched by the GET handler for tasksIDRunsIDPath,
+	// for the same reason as the dry-run path.
 	h.HandlerFunc("POST", tasksIDRunsPath, h.handleForceRun)
 	h.HandlerFunc("GET", tasksIDRunsIDPath, h.handleGetRun)
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("GET", tasksIDWebhooksPath, h.handleGetTaskWebhooks)
+	h.HandlerFunc("POST", tasksIDWebhooksPath, h.handlePostTaskWebhook)
+	h.HandlerFunc("GET", tasksIDWebhooksIDPath, h.handleGetTaskWebhook)
+	h.HandlerFunc("PATCH", tasksIDWebhooksIDPath, h.handlePatchTaskWebhook)
+	h.HandlerFunc("DELETE", tasksIDWebhooksIDPath, h.handleDeleteTaskWebhook)
+	h.HandlerFunc("GET", tasksIDWebhooksIDDeliveriesPath, h.handleGetTaskWebhookDeliveries)
+
 	labelBackend := &LabelBackend{
 		Logger:       b.Logger.With(zap.String("handler", "label")),
 		LabelService: b.LabelService,
@@ -158,12 +202,14 @@ type taskResponse struct {
 func newTaskResponse(t platform.Task, labels []*platform.Label) taskResponse {
 	response := taskResponse{
 		Links: map[string]string{
-			"self":    fmt.Sprintf("/api/v2/tasks/%s", t.ID),
-			"members": fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
-			"owners":  fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
-			"labels":  fmt.Sprintf("/api/v2/tasks/%s/labels", t.ID),
-			"runs":    fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
-			"logs":    fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"self":     fmt.Sprintf("/api/v2/tasks/%s", t.ID),
+			"members":  fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
+			"owners":   fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
+			"labels":   fmt.Sprintf("/api/v2/tasks/%s/labels", t.ID),
+			"runs":     fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
+			"logs":     fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"schedule": fmt.Sprintf("/api/v2/tasks/%s/schedule", t.ID),
+			"versions": fmt.Sprintf("/api/v2/tasks/%s/versions", t.ID),
 		},
 		Task:   t,
 		Labels: []platform.Label{},
@@ -288,6 +334,9 @@ func (h *TaskHandler) handleGetTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(tasks) >= req.filter.Limit {
+		setPagingLinkHeader(w, r, &pageCursor{After: &tasks[req.filter.Limit-1].ID}, nil)
+	}
 	if err := encodeResponse(ctx, w, http.StatusOK, newTasksResponse(ctx, tasks, req.filter, h.LabelService)); err != nil {
 		logEncodingError(h.logger, r, err)
 		return
@@ -310,6 +359,15 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs platform.O
 		req.filter.After = id
 	}
 
+	// The cursor of a Link header takes precedence over after.
+	c, err := decodePageCursor(r)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		req.filter.After = c.After
+	}
+
 	if orgName := qp.Get("org"); orgName != "" {
 		o, err := orgs.FindOrganization(ctx, platform.OrganizationFilter{Name: &orgName})
 		if err != nil {
@@ -379,7 +437,12 @@ func (h *TaskHandler) createBootstrapTaskAuthorizationIfNotExists(ctx context.Co
 		return nil, nil
 	}
 
-	spec, err := flux.Compile(ctx, t.Flux, time.Now())
+	script, err := platform.ResolveTaskScriptIncludes(ctx, h.TaskScriptService, t.OrganizationID, t.Flux)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := flux.Compile(ctx, script, time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -499,43 +562,53 @@ func (h *TaskHandler) handlePostTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bootstrapAuthz, err := h.createBootstrapTaskAuthorizationIfNotExists(ctx, auth, &req.TaskCreate)
+	task, err := h.createTask(ctx, auth, req.TaskCreate)
 	if err != nil {
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	task, err := h.TaskService.CreateTask(ctx, req.TaskCreate)
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskResponse(*task, []*platform.Label{})); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// createTask creates the task, bootstrapping its authorization when it is created from a session without a token.
+func (h *TaskHandler) createTask(ctx context.Context, auth platform.Authorizer, tc platform.TaskCreate) (*platform.Task, error) {
+	bootstrapAuthz, err := h.createBootstrapTaskAuthorizationIfNotExists(ctx, auth, &tc)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := h.TaskService.CreateTask(ctx, tc)
 	if err != nil {
 		if e, ok := err.(AuthzError); ok {
 			h.logger.Error("failed authentication", zap.Errors("error messages", []error{err, e.AuthzError()}))
 		}
-		err = &platform.Error{
+		perr := &platform.Error{
 			Err: err,
 			Msg: "failed to create task",
 		}
-		EncodeError(ctx, err, w)
-		return
+		if _, ok := err.(backend.QuotaExceededError); ok {
+			perr.Code = platform.EForbidden
+		}
+		return nil, perr
 	}
 
 	if bootstrapAuthz != nil {
 		// There was a bootstrapped authorization for this task.
 		// Now we need to apply the final authorization for the task.
 		if err := h.finalizeBootstrappedTaskAuthorization(ctx, bootstrapAuthz, task); err != nil {
-			err = &platform.Error{
+			return nil, &platform.Error{
 				Err:  err,
 				Msg:  fmt.Sprintf("successfully created task with ID %s, but failed to finalize bootstrap token for task", task.ID.String()),
 				Code: platform.EInternal,
 			}
-			EncodeError(ctx, err, w)
-			return
 		}
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskResponse(*task, []*platform.Label{})); err != nil {
-		logEncodingError(h.logger, r, err)
-		return
-	}
+	return task, nil
 }
 
 type postTaskRequest struct {
@@ -557,10 +630,42 @@ func decodePostTaskRequest(ctx context.Context, r *http.Request) (*postTaskReque
 	}, nil
 }
 
-func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
+// handlePostTaskID serves POST requests to /api/v2/tasks/:id.
+// The only valid IDs are "dry-run", "import", "validate-schedule" and "batch"; any other ID is not allowed, as it was before this route existed.
+func (h *TaskHandler) handlePostTaskID(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+	switch params.ByName("id") {
+	case tasksDryRunID:
+		h.handlePostTaskDryRun(w, r)
+		return
+	case tasksImportID:
+		h.handleImportTask(w, r)
+		return
+	case tasksValidateScheduleID:
+		h.handlePostTaskValidateSchedule(w, r)
+		return
+	case tasksBatchID:
+		h.handlePostTaskBatch(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", "GET, PATCH, DELETE")
+	methodNotAllowedHandler(w, r)
+}
+
+type taskDryRunResponse struct {
+	Name          string                 `json:"name"`
+	EffectiveCron string                 `json:"effectiveCron"`
+	Offset        string                 `json:"offset,omitempty"`
+	Next          []scheduledRunResponse `json:"next"`
+}
+
+// handlePostTaskDryRun is the HTTP handler for the POST /api/v2/tasks/dry-run route.
+// It validates a task's Flux and previews the runs it would have if it were created now, without persisting anything.
+func (h *TaskHandler) handlePostTaskDryRun(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	req, err := decodeGetTaskRequest(ctx, r)
+	req, err := decodePostTaskDryRunRequest(ctx, r)
 	if err != nil {
 		err = &platform.Error{
 			Err:  err,
@@ -571,63 +676,111 @@ func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	script, err := platform.ResolveTaskScriptIncludes(ctx, h.TaskScriptService, req.OrgID, req.Flux)
 	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	now := time.Now()
+	if _, err := flux.Compile(ctx, script, now); err != nil {
 		err = &platform.Error{
 			Err:  err,
-			Code: platform.ENotFound,
-			Msg:  "failed to find task",
+			Code: platform.EInvalid,
+			Msg:  "failed to compile flux",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	opts, err := options.FromScript(req.Flux)
 	if err != nil {
 		err = &platform.Error{
-			Err: err,
-			Msg: "failed to find resource labels",
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "invalid task options",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+	// Build the meta a newly created task would have, so the preview follows the scheduler's code path,
+	// including the alignment of every-based schedules.
+	stm := backend.NewStoreTaskMeta(backend.CreateTaskRequest{ScheduleAfter: now.Unix()}, opts)
+	runs, err := stm.NextScheduledRuns(req.N)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to compute task schedule",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	loc := time.UTC
+	if opts.Timezone != "" {
+		// Already validated with the options.
+		loc, _ = time.LoadLocation(opts.Timezone)
+	}
+
+	resp := taskDryRunResponse{
+		Name:          opts.Name,
+		EffectiveCron: opts.EffectiveCronString(),
+		Next:          newScheduledRunsResponse(runs, loc),
+	}
+	if opts.Offset != nil && *opts.Offset != 0 {
+		resp.Offset = opts.Offset.String()
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
 		logEncodingError(h.logger, r, err)
 		return
 	}
 }
 
-type getTaskRequest struct {
-	TaskID platform.ID
+type postTaskDryRunRequest struct {
+	Flux string `json:"flux"`
+	// OrgID is the organization whose task scripts the Flux includes.
+	OrgID platform.ID `json:"orgID,omitempty"`
+	N     int         `json:"-"`
 }
 
-func decodeGetTaskRequest(ctx context.Context, r *http.Request) (*getTaskRequest, error) {
-	params := httprouter.ParamsFromContext(ctx)
-	id := params.ByName("id")
-	if id == "" {
+func decodePostTaskDryRunRequest(ctx context.Context, r *http.Request) (*postTaskDryRunRequest, error) {
+	req := &postTaskDryRunRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, err
+	}
+
+	if req.Flux == "" {
 		return nil, &platform.Error{
 			Code: platform.EInvalid,
-			Msg:  "url missing id",
+			Msg:  "missing flux",
 		}
 	}
 
-	var i platform.ID
-	if err := i.DecodeFromString(id); err != nil {
+	n, err := decodeScheduledRunsCount(r.URL.Query().Get("n"))
+	if err != nil {
 		return nil, err
 	}
-
-	req := &getTaskRequest{
-		TaskID: i,
-	}
+	req.N = n
 
 	return req, nil
 }
 
-func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+type taskValidateScheduleResponse struct {
+	EffectiveCron string                 `json:"effectiveCron"`
+	Offset        string                 `json:"offset,omitempty"`
+	Timezone      string                 `json:"timezone"`
+	Next          []scheduledRunResponse `json:"next"`
+}
+
+// handlePostTaskValidateSchedule is the HTTP handler for the POST /api/v2/tasks/validate-schedule route.
+// It validates a cron or every schedule and previews the runs a task with that schedule would have if it were created now,
+// in the time zone of the schedule if it has one, or else in the time zone of the organization.
+func (h *TaskHandler) handlePostTaskValidateSchedule(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	req, err := decodeUpdateTaskRequest(ctx, r)
+	req, err := decodePostTaskValidateScheduleRequest(ctx, r)
 	if err != nil {
 		err = &platform.Error{
 			Err:  err,
@@ -637,74 +790,126 @@ func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		EncodeError(ctx, err, w)
 		return
 	}
-	task, err := h.TaskService.UpdateTask(ctx, req.TaskID, req.Update)
+
+	org, err := h.OrganizationService.FindOrganization(ctx, req.OrgFilter)
 	if err != nil {
-		err := &platform.Error{
+		err = &platform.Error{
 			Err: err,
-			Msg: "failed to update task",
-		}
-		if err.Err == backend.ErrTaskNotFound {
-			err.Code = platform.ENotFound
+			Msg: "failed to find organization",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	loc, err := org.Location()
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if req.Options.Timezone != "" {
+		// Already validated with the options.
+		loc, _ = time.LoadLocation(req.Options.Timezone)
+	}
+
+	// Build the meta a newly created task would have, so the preview follows the scheduler's code path,
+	// including the alignment of every-based schedules.
+	stm := backend.NewStoreTaskMeta(backend.CreateTaskRequest{ScheduleAfter: time.Now().Unix()}, req.Options)
+	runs, err := stm.NextScheduledRuns(req.N)
 	if err != nil {
 		err = &platform.Error{
-			Err: err,
-			Msg: "failed to find resource labels",
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to compute task schedule",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+	resp := taskValidateScheduleResponse{
+		EffectiveCron: req.Options.EffectiveCronString(),
+		Timezone:      loc.String(),
+		Next:          newScheduledRunsResponse(runs, loc),
+	}
+	if req.Options.Offset != nil && *req.Options.Offset != 0 {
+		resp.Offset = req.Options.Offset.String()
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
 		logEncodingError(h.logger, r, err)
 		return
 	}
 }
 
-type updateTaskRequest struct {
-	Update platform.TaskUpdate
-	TaskID platform.ID
+type postTaskValidateScheduleRequest struct {
+	OrgFilter platform.OrganizationFilter
+	Options   options.Options
+	N         int
 }
 
-func decodeUpdateTaskRequest(ctx context.Context, r *http.Request) (*updateTaskRequest, error) {
-	params := httprouter.ParamsFromContext(ctx)
-	id := params.ByName("id")
-	if id == "" {
+func decodePostTaskValidateScheduleRequest(ctx context.Context, r *http.Request) (*postTaskValidateScheduleRequest, error) {
+	body := struct {
+		OrganizationID platform.ID `json:"orgID,omitempty"`
+		Organization   string      `json:"org,omitempty"`
+		Cron           string      `json:"cron,omitempty"`
+		Every          string      `json:"every,omitempty"`
+		Offset         string      `json:"offset,omitempty"`
+		Timezone       string      `json:"timezone,omitempty"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	req := &postTaskValidateScheduleRequest{}
+	switch {
+	case body.OrganizationID.Valid():
+		req.OrgFilter.ID = &body.OrganizationID
+	case body.Organization != "":
+		req.OrgFilter.Name = &body.Organization
+	default:
 		return nil, &platform.Error{
 			Code: platform.EInvalid,
-			Msg:  "you must provide a task ID",
+			Msg:  "missing orgID or org",
 		}
 	}
 
-	var i platform.ID
-	if err := i.DecodeFromString(id); err != nil {
-		return nil, err
+	req.Options.Cron = body.Cron
+	if body.Every != "" {
+		every, err := ParseDuration(body.Every)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("invalid every %q", body.Every),
+			}
+		}
+		req.Options.Every = every
 	}
-
-	var upd platform.TaskUpdate
-	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+	if body.Offset != "" {
+		offset, err := ParseDuration(body.Offset)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("invalid offset %q", body.Offset),
+			}
+		}
+		req.Options.Offset = &offset
+	}
+	req.Options.Timezone = body.Timezone
+	if err := req.Options.ValidateSchedule(); err != nil {
 		return nil, err
 	}
 
-	if err := upd.Validate(); err != nil {
+	n, err := decodeScheduledRunsCount(r.URL.Query().Get("n"))
+	if err != nil {
 		return nil, err
 	}
+	req.N = n
 
-	return &updateTaskRequest{
-		Update: upd,
-		TaskID: i,
-	}, nil
+	return req, nil
 }
 
-func (h *TaskHandler) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	req, err := decodeDeleteTaskRequest(ctx, r)
+	req, err := decodeGetTaskRequest(ctx, r)
 	if err != nil {
 		err = &platform.Error{
 			Err:  err,
@@ -715,49 +920,73 @@ func (h *TaskHandler) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.TaskService.DeleteTask(ctx, req.TaskID); err != nil {
-		err := &platform.Error{
-			Err: err,
-			Msg: "failed to delete task",
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.ENotFound,
+			Msg:  "failed to find task",
 		}
-		if err.Err == backend.ErrTaskNotFound {
-			err.Code = platform.ENotFound
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find resource labels",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
 }
 
-type deleteTaskRequest struct {
+type getTaskRequest struct {
 	TaskID platform.ID
 }
 
-func decodeDeleteTaskRequest(ctx context.Context, r *http.Request) (*deleteTaskRequest, error) {
-	params := httprouter.ParamsFromContext(ctx)
-	id := params.ByName("id")
-	if id == "" {
-		return nil, &platform.Error{
-			Code: platform.EInvalid,
-			Msg:  "you must provide a task ID",
-		}
-	}
+// defaultScheduledRuns and maxScheduledRuns bound how many upcoming runs are previewed.
+const (
+	defaultScheduledRuns = 10
+	maxScheduledRuns     = 100
+)
 
-	var i platform.ID
-	if err := i.DecodeFromString(id); err != nil {
-		return nil, err
+type scheduledRunResponse struct {
+	ScheduledFor string `json:"scheduledFor"`
+	DueAt        string `json:"dueAt"`
+}
+
+// newScheduledRunsResponse returns the response for runs, with their times shown in loc.
+func newScheduledRunsResponse(runs []backend.ScheduledRun, loc *time.Location) []scheduledRunResponse {
+	rs := make([]scheduledRunResponse, len(runs))
+	for i, r := range runs {
+		rs[i] = scheduledRunResponse{
+			ScheduledFor: time.Unix(r.Now, 0).In(loc).Format(time.RFC3339),
+			DueAt:        time.Unix(r.DueAt, 0).In(loc).Format(time.RFC3339),
+		}
 	}
+	return rs
+}
 
-	return &deleteTaskRequest{
-		TaskID: i,
-	}, nil
+type taskScheduleResponse struct {
+	Links         map[string]string      `json:"links"`
+	EffectiveCron string                 `json:"effectiveCron"`
+	Offset        string                 `json:"offset,omitempty"`
+	Next          []scheduledRunResponse `json:"next"`
 }
 
-func (h *TaskHandler) handleGetLogs(w http.ResponseWriter, r *http.Request) {
+// handleGetTaskSchedule is the HTTP handler for the GET /api/v2/tasks/:id/schedule route.
+// It previews the task's upcoming runs, as the scheduler will create them.
+func (h *TaskHandler) handleGetTaskSchedule(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	req, err := decodeGetLogsRequest(ctx, r)
+	req, err := decodeGetTaskScheduleRequest(ctx, r)
 	if err != nil {
 		err = &platform.Error{
 			Err:  err,
@@ -768,54 +997,844 @@ func (h *TaskHandler) handleGetLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth, err := pcontext.GetAuthorizer(ctx)
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
 	if err != nil {
 		err = &platform.Error{
 			Err:  err,
-			Code: platform.EUnauthorized,
-			Msg:  "failed to get authorizer",
+			Code: platform.ENotFound,
+			Msg:  "failed to find task",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	if k := auth.Kind(); k != platform.AuthorizationKind {
-		// Get the authorization for the task, if allowed.
-		authz, err := h.getAuthorizationForTask(ctx, req.filter.Task)
-		if err != nil {
-			EncodeError(ctx, err, w)
-			return
-		}
-
-		// We were able to access the authorizer for the task, so reassign that on the context for the rest of this call.
-		ctx = pcontext.SetAuthorizer(ctx, authz)
-	}
-
-	logs, _, err := h.TaskService.FindLogs(ctx, req.filter)
+	runs, err := taskScheduledRuns(task, req.N)
 	if err != nil {
-		err := &platform.Error{
-			Err: err,
-			Msg: "failed to find task logs",
-		}
-		if err.Err == backend.ErrTaskNotFound || err.Err == backend.ErrNoRunsFound {
-			err.Code = platform.ENotFound
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to compute task schedule",
 		}
 		EncodeError(ctx, err, w)
 		return
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusOK, &getLogsResponse{Events: logs}); err != nil {
+	resp := taskScheduleResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/schedule", task.ID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", task.ID),
+		},
+		EffectiveCron: task.EffectiveCron,
+		Offset:        task.Offset,
+		Next:          newScheduledRunsResponse(runs, time.UTC),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
 		logEncodingError(h.logger, r, err)
 		return
 	}
 }
 
-type getLogsRequest struct {
-	filter platform.LogFilter
+// taskScheduledRuns returns the next n runs of task, following its latest completed run.
+func taskScheduledRuns(task *platform.Task, n int) ([]backend.ScheduledRun, error) {
+	var offset time.Duration
+	if task.Offset != "" {
+		var err error
+		offset, err = time.ParseDuration(task.Offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	latest := time.Now()
+	if task.LatestCompleted != "" {
+		var err error
+		latest, err = time.Parse(time.RFC3339, task.LatestCompleted)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return backend.NextScheduledRuns(task.EffectiveCron, int64(offset/time.Second), latest.Unix(), n)
 }
 
-type getLogsResponse struct {
-	Events []*platform.Log `json:"events"`
+type getTaskScheduleRequest struct {
+	TaskID platform.ID
+	N      int
+}
+
+func decodeGetTaskScheduleRequest(ctx context.Context, r *http.Request) (*getTaskScheduleRequest, error) {
+	tr, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := decodeScheduledRunsCount(r.URL.Query().Get("n"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &getTaskScheduleRequest{
+		TaskID: tr.TaskID,
+		N:      n,
+	}, nil
+}
+
+// decodeScheduledRunsCount parses the number of scheduled runs to preview.
+func decodeScheduledRunsCount(s string) (int, error) {
+	if s == "" {
+		return defaultScheduledRuns, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > maxScheduledRuns {
+		return 0, &platform.Error{
+			Code: platform.EUnprocessableEntity,
+			Msg:  fmt.Sprintf("n must be between 1 and %d", maxScheduledRuns),
+		}
+	}
+	return n, nil
+}
+
+func decodeGetTaskRequest(ctx context.Context, r *http.Request) (*getTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	req := &getTaskRequest{
+		TaskID: i,
+	}
+
+	return req, nil
+}
+
+type taskVersionResponse struct {
+	Links map[string]string `json:"links"`
+	platform.TaskVersion
+}
+
+type taskVersionsResponse struct {
+	Links    map[string]string     `json:"links"`
+	Versions []taskVersionResponse `json:"versions"`
+}
+
+func newTaskVersionsResponse(vs []*platform.TaskVersion, taskID platform.ID) taskVersionsResponse {
+	r := taskVersionsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/versions", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Versions: make([]taskVersionResponse, len(vs)),
+	}
+
+	for i, v := range vs {
+		r.Versions[i] = taskVersionResponse{
+			Links: map[string]string{
+				"rollback": fmt.Sprintf("/api/v2/tasks/%s/versions/%d/rollback", taskID, v.Version),
+			},
+			TaskVersion: *v,
+		}
+	}
+	return r
+}
+
+// handleGetTaskVersions is the HTTP handler for the GET /api/v2/tasks/:id/versions route.
+func (h *TaskHandler) handleGetTaskVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	versions, err := h.TaskService.FindTaskVersions(ctx, req.TaskID)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find task versions",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskVersionsResponse(versions, req.TaskID)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handleRollbackTask is the HTTP handler for the POST /api/v2/tasks/:id/versions/:version/rollback route.
+// It restores the task's Flux to the given version, keeping the replaced Flux as a new version.
+func (h *TaskHandler) handleRollbackTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeRollbackTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.RollbackTask(ctx, req.TaskID, req.Version)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to roll back task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find resource labels",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type rollbackTaskRequest struct {
+	TaskID  platform.ID
+	Version int
+}
+
+func decodeRollbackTaskRequest(ctx context.Context, r *http.Request) (*rollbackTaskRequest, error) {
+	tr, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	params := httprouter.ParamsFromContext(ctx)
+	v, err := strconv.Atoi(params.ByName("version"))
+	if err != nil || v < 1 {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "version must be a positive integer",
+		}
+	}
+
+	return &rollbackTaskRequest{
+		TaskID:  tr.TaskID,
+		Version: v,
+	}, nil
+}
+
+// handleCloneTask is the HTTP handler for the POST /api/v2/tasks/:id/clone route.
+// It creates a copy of the task and its labels, in the same or in another organization, with its bucket references rewritten.
+// The copy starts with no run history, and its schedule starts from the time it is created.
+func (h *TaskHandler) handleCloneTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EUnauthorized,
+			Msg:  "failed to get authorizer",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeCloneTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	src, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if !req.OrganizationID.Valid() {
+		req.OrganizationID = src.OrganizationID
+	}
+
+	srcLabels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: src.ID, ResourceType: platform.TasksResourceType})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find task labels",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	flux, err := platform.RewriteBucketReferences(src.Flux, req.BucketMapping)
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to rewrite bucket references",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.createTask(ctx, auth, platform.TaskCreate{
+		Flux:           flux,
+		Status:         src.Status,
+		OrganizationID: req.OrganizationID,
+		Token:          req.Token,
+		Params:         src.Params,
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// Labels belong to an organization, so the clone gets the labels of its organization named like the labels of the source.
+	els := make([]platform.TaskExportLabel, 0, len(srcLabels))
+	for _, l := range srcLabels {
+		els = append(els, platform.TaskExportLabel{Name: l.Name, Properties: l.Properties})
+	}
+	labels, err := h.addTaskLabels(ctx, task, els)
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: fmt.Sprintf("successfully cloned task into task with ID %s, but failed to label it", task.ID),
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type cloneTaskRequest struct {
+	TaskID platform.ID
+	platform.CloneRequest
+
+	// Token is used by the clone.
+	// If empty, the token of the request is used, as the token of the source task is not copied.
+	Token string
+}
+
+func decodeCloneTaskRequest(ctx context.Context, r *http.Request) (*cloneTaskRequest, error) {
+	tr, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		platform.CloneRequest
+		Token string `json:"token,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	// Without orgID, the task is cloned into its own organization.
+	if err := body.ValidateBucketMapping(); err != nil {
+		return nil, err
+	}
+
+	return &cloneTaskRequest{
+		TaskID:       tr.TaskID,
+		CloneRequest: body.CloneRequest,
+		Token:        body.Token,
+	}, nil
+}
+
+// handleExportTask is the HTTP handler for the GET /api/v2/tasks/:id/export route.
+// It returns a portable document describing the task, to be imported with POST /api/v2/tasks/import.
+func (h *TaskHandler) handleExportTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find resource labels",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	e, err := platform.NewTaskExport(task, labels)
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to export task",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, e); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handleImportTask is the HTTP handler for the POST /api/v2/tasks/import route.
+// It creates a task from an exported document, with the labels of the document
+// found by name in the organization of the task, or created there.
+func (h *TaskHandler) handleImportTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EUnauthorized,
+			Msg:  "failed to get authorizer",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeImportTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	tc := req.TaskCreate()
+	if err := h.populateTaskCreateOrg(ctx, &tc); err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "could not identify organization",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.createTask(ctx, auth, tc)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.addTaskLabels(ctx, task, req.Task.Labels)
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: fmt.Sprintf("successfully imported task with ID %s, but failed to label it", task.ID),
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// addTaskLabels adds to the task the labels of its organization named like els, creating the missing labels.
+func (h *TaskHandler) addTaskLabels(ctx context.Context, task *platform.Task, els []platform.TaskExportLabel) ([]*platform.Label, error) {
+	labels := make([]*platform.Label, 0, len(els))
+	for _, el := range els {
+		l, err := h.findOrCreateLabel(ctx, task.OrganizationID, el)
+		if err != nil {
+			return nil, &platform.Error{
+				Err: err,
+				Msg: fmt.Sprintf("failed to find or create label %q", el.Name),
+			}
+		}
+		m := &platform.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   task.ID,
+			ResourceType: platform.TasksResourceType,
+		}
+		if err := h.LabelService.CreateLabelMapping(ctx, m); err != nil {
+			return nil, &platform.Error{
+				Err: err,
+				Msg: fmt.Sprintf("failed to add label %q", el.Name),
+			}
+		}
+		labels = append(labels, l)
+	}
+	return labels, nil
+}
+
+// findOrCreateLabel returns the label of orgID named like el, creating it if there is none.
+func (h *TaskHandler) findOrCreateLabel(ctx context.Context, orgID platform.ID, el platform.TaskExportLabel) (*platform.Label, error) {
+	ls, err := h.LabelService.FindLabels(ctx, platform.LabelFilter{Name: el.Name, OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) > 0 {
+		return ls[0], nil
+	}
+
+	l := &platform.Label{
+		OrganizationID: orgID,
+		Name:           el.Name,
+		Properties:     el.Properties,
+	}
+	if err := h.LabelService.CreateLabel(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func decodeImportTaskRequest(ctx context.Context, r *http.Request) (*platform.TaskImport, error) {
+	var ti platform.TaskImport
+	if err := json.NewDecoder(r.Body).Decode(&ti); err != nil {
+		return nil, err
+	}
+	if err := ti.Validate(); err != nil {
+		return nil, err
+	}
+	return &ti, nil
+}
+
+// handleMoveTask is the HTTP handler for the POST /api/v2/tasks/:id/move route.
+// It transfers the task, and optionally its run history, to another organization.
+func (h *TaskHandler) handleMoveTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeMoveTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.MoveTask(ctx, req.TaskID, req.Move)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to move task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		if _, ok := err.Err.(backend.QuotaExceededError); ok {
+			err.Code = platform.EForbidden
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find resource labels",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type moveTaskRequest struct {
+	TaskID platform.ID
+	Move   platform.TaskMove
+}
+
+func decodeMoveTaskRequest(ctx context.Context, r *http.Request) (*moveTaskRequest, error) {
+	tr, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var move platform.TaskMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		return nil, err
+	}
+	if err := move.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &moveTaskRequest{
+		TaskID: tr.TaskID,
+		Move:   move,
+	}, nil
+}
+
+func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeUpdateTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+	task, err := h.TaskService.UpdateTask(ctx, req.TaskID, req.Update)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to update task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		if _, ok := err.Err.(backend.QuotaExceededError); ok {
+			err.Code = platform.EForbidden
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		err = &platform.Error{
+			Err: err,
+			Msg: "failed to find resource labels",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type updateTaskRequest struct {
+	Update platform.TaskUpdate
+	TaskID platform.ID
+}
+
+func decodeUpdateTaskRequest(ctx context.Context, r *http.Request) (*updateTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "you must provide a task ID",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	var upd platform.TaskUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		return nil, err
+	}
+
+	if err := upd.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &updateTaskRequest{
+		Update: upd,
+		TaskID: i,
+	}, nil
+}
+
+func (h *TaskHandler) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeDeleteTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.TaskService.DeleteTask(ctx, req.TaskID); err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to delete task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if h.TaskWebhookService != nil {
+		if err := h.deleteTaskWebhooks(ctx, req.TaskID); err != nil {
+			h.logger.Info("Failed to delete webhooks of deleted task", zap.Stringer("task_id", req.TaskID), zap.Error(err))
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deleteTaskRequest struct {
+	TaskID platform.ID
+}
+
+func decodeDeleteTaskRequest(ctx context.Context, r *http.Request) (*deleteTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "you must provide a task ID",
+		}
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	return &deleteTaskRequest{
+		TaskID: i,
+	}, nil
+}
+
+func (h *TaskHandler) handleGetLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetLogsRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EUnauthorized,
+			Msg:  "failed to get authorizer",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if k := auth.Kind(); k != platform.AuthorizationKind {
+		// Get the authorization for the task, if allowed.
+		authz, err := h.getAuthorizationForTask(ctx, req.filter.Task)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+
+		// We were able to access the authorizer for the task, so reassign that on the context for the rest of this call.
+		ctx = pcontext.SetAuthorizer(ctx, authz)
+	}
+
+	logs, _, err := h.TaskService.FindLogs(ctx, req.filter)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find task logs",
+		}
+		if err.Err == backend.ErrTaskNotFound || err.Err == backend.ErrNoRunsFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, &getLogsResponse{Events: logs}); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type getLogsRequest struct {
+	filter platform.LogFilter
+}
+
+type getLogsResponse struct {
+	Events []*platform.Log `json:"events"`
 }
 
 func decodeGetLogsRequest(ctx context.Context, r *http.Request) (*getLogsRequest, error) {
@@ -896,6 +1915,9 @@ func (h *TaskHandler) handleGetRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.filter.Limit > 0 && len(runs) >= req.filter.Limit {
+		setPagingLinkHeader(w, r, &pageCursor{After: &runs[req.filter.Limit-1].ID}, nil)
+	}
 	if err := encodeResponse(ctx, w, http.StatusOK, newRunsResponse(runs, req.filter.Task)); err != nil {
 		logEncodingError(h.logger, r, err)
 		return
@@ -933,6 +1955,15 @@ func decodeGetRunsRequest(ctx context.Context, r *http.Request) (*getRunsRequest
 		req.filter.After = afterID
 	}
 
+	// The cursor of a Link header takes precedence over after.
+	c, err := decodePageCursor(r)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		req.filter.After = c.After
+	}
+
 	if limit := qp.Get("limit"); limit != "" {
 		i, err := strconv.Atoi(limit)
 		if err != nil {
@@ -964,19 +1995,290 @@ func decodeGetRunsRequest(ctx context.Context, r *http.Request) (*getRunsRequest
 		if err != nil {
 			return nil, err
 		}
-		req.filter.BeforeTime = bt
+		req.filter.BeforeTime = bt
+	}
+
+	if at != "" && bt != "" && !beforeTime.After(afterTime) {
+		return nil, &platform.Error{
+			Code: platform.EUnprocessableEntity,
+			Msg:  "beforeTime must be later than afterTime",
+		}
+	}
+
+	if status := qp.Get("status"); status != "" {
+		switch status {
+		case backend.RunScheduled.String(), backend.RunStarted.String(), backend.RunSuccess.String(), backend.RunFail.String(), backend.RunCanceled.String():
+			req.filter.Status = status
+		default:
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("invalid run status: %q", status),
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// runsExportPageSize is the number of runs read at a time when exporting the runs of a task.
+const runsExportPageSize = 100
+
+// handleExportRuns is the HTTP handler for the GET /api/v2/tasks/:id/runs/export route.
+// It streams every run of the task scheduled within the requested time range as annotated CSV,
+// reading the runs a page at a time.
+func (h *TaskHandler) handleExportRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeExportRunsRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EUnauthorized,
+			Msg:  "failed to get authorizer",
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if k := auth.Kind(); k != platform.AuthorizationKind {
+		// Get the authorization for the task, if allowed.
+		authz, err := h.getAuthorizationForTask(ctx, req.filter.Task)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+
+		// We were able to access the authorizer for the task, so reassign that on the context for the rest of this call.
+		ctx = pcontext.SetAuthorizer(ctx, authz)
+	}
+
+	// The first page is read before writing anything, so that an error can still be reported with its status code.
+	filter := req.filter
+	runs, err := h.findRunsPage(ctx, filter)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find runs",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"runs-%s.csv\"", filter.Task))
+	w.WriteHeader(http.StatusOK)
+
+	enc := newRunsCSVEncoder(w)
+	if err := enc.writeHeader(); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+	for {
+		for _, run := range runs {
+			if err := enc.writeRun(run, h.runErrorSummary(ctx, run)); err != nil {
+				logEncodingError(h.logger, r, err)
+				return
+			}
+		}
+		if err := enc.flush(); err != nil {
+			logEncodingError(h.logger, r, err)
+			return
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		if len(runs) < runsExportPageSize {
+			return
+		}
+		last := runs[0].ID
+		for _, run := range runs {
+			if run.ID > last {
+				last = run.ID
+			}
+		}
+		filter.After = &last
+
+		if runs, err = h.findRunsPage(ctx, filter); err != nil {
+			// The response has already started, so the export can only be cut short.
+			h.logger.Info("Failed to find runs to export", zap.String("task_id", filter.Task.String()), zap.Error(err))
+			return
+		}
+	}
+}
+
+// findRunsPage returns a page of the runs matching filter, which is empty once there are no more runs.
+func (h *TaskHandler) findRunsPage(ctx context.Context, filter platform.RunFilter) ([]*platform.Run, error) {
+	filter.Limit = runsExportPageSize
+	runs, _, err := h.TaskService.FindRuns(ctx, filter)
+	if err == backend.ErrNoRunsFound {
+		return nil, nil
+	}
+	return runs, err
+}
+
+// runErrorSummary returns the last log message of run if it failed, which is the reason it failed.
+func (h *TaskHandler) runErrorSummary(ctx context.Context, run *platform.Run) string {
+	if run.Status != backend.RunFail.String() {
+		return ""
+	}
+
+	logs := run.Log
+	if len(logs) == 0 {
+		// Not every run reader includes the logs of the runs it lists.
+		ls, _, err := h.TaskService.FindLogs(ctx, platform.LogFilter{Task: run.TaskID, Run: &run.ID})
+		if err != nil {
+			h.logger.Info("Failed to find logs of run to export", zap.String("run_id", run.ID.String()), zap.Error(err))
+			return ""
+		}
+		for _, l := range ls {
+			logs = append(logs, *l)
+		}
+	}
+	if len(logs) == 0 {
+		return ""
+	}
+	return logs[len(logs)-1].Message
+}
+
+type exportRunsRequest struct {
+	filter platform.RunFilter
+}
+
+func decodeExportRunsRequest(ctx context.Context, r *http.Request) (*exportRunsRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "you must provide a task ID",
+		}
+	}
+
+	req := &exportRunsRequest{}
+	taskID, err := platform.IDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+	req.filter.Task = *taskID
+
+	qp := r.URL.Query()
+
+	var start, stop time.Time
+	if s := qp.Get("start"); s != "" {
+		if start, err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, err
+		}
+		req.filter.AfterTime = s
+	}
+	if s := qp.Get("stop"); s != "" {
+		if stop, err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, err
+		}
+		req.filter.BeforeTime = s
 	}
 
-	if at != "" && bt != "" && !beforeTime.After(afterTime) {
+	if !start.IsZero() && !stop.IsZero() && !stop.After(start) {
 		return nil, &platform.Error{
 			Code: platform.EUnprocessableEntity,
-			Msg:  "beforeTime must be later than afterTime",
+			Msg:  "stop must be later than start",
 		}
 	}
 
 	return req, nil
 }
 
+// runsCSVColumns are the columns of an exported run, after the result and table columns of annotated CSV.
+var runsCSVColumns = []struct {
+	name     string
+	datatype string
+	group    bool
+}{
+	{name: "runID", datatype: "string"},
+	{name: "taskID", datatype: "string", group: true},
+	{name: "status", datatype: "string"},
+	{name: "scheduledFor", datatype: "dateTime:RFC3339"},
+	{name: "requestedAt", datatype: "dateTime:RFC3339"},
+	{name: "startedAt", datatype: "dateTime:RFC3339"},
+	{name: "finishedAt", datatype: "dateTime:RFC3339"},
+	{name: "durationMs", datatype: "long"},
+	{name: "error", datatype: "string"},
+}
+
+// runsCSVEncoder writes runs as a single table of annotated CSV, the format Flux query results are written in.
+type runsCSVEncoder struct {
+	w *csv.Writer
+}
+
+func newRunsCSVEncoder(w io.Writer) *runsCSVEncoder {
+	return &runsCSVEncoder{w: csv.NewWriter(w)}
+}
+
+// writeHeader writes the annotations and the header row.
+func (e *runsCSVEncoder) writeHeader() error {
+	datatypes := []string{"#datatype", "string", "long"}
+	groups := []string{"#group", "false", "false"}
+	defaults := []string{"#default", "_result", ""}
+	names := []string{"", "result", "table"}
+	for _, c := range runsCSVColumns {
+		datatypes = append(datatypes, c.datatype)
+		groups = append(groups, strconv.FormatBool(c.group))
+		defaults = append(defaults, "")
+		names = append(names, c.name)
+	}
+	return e.w.WriteAll([][]string{datatypes, groups, defaults, names})
+}
+
+// writeRun writes a row for run. The duration is only known for finished runs.
+func (e *runsCSVEncoder) writeRun(run *platform.Run, errorSummary string) error {
+	var duration string
+	if run.StartedAt != "" && run.FinishedAt != "" {
+		started, err := time.Parse(time.RFC3339Nano, run.StartedAt)
+		if err != nil {
+			return err
+		}
+		finished, err := time.Parse(time.RFC3339Nano, run.FinishedAt)
+		if err != nil {
+			return err
+		}
+		duration = strconv.FormatInt(int64(finished.Sub(started)/time.Millisecond), 10)
+	}
+
+	return e.w.Write([]string{
+		"",
+		"",
+		"0",
+		run.ID.String(),
+		run.TaskID.String(),
+		run.Status,
+		run.ScheduledFor,
+		run.RequestedAt,
+		run.StartedAt,
+		run.FinishedAt,
+		duration,
+		errorSummary,
+	})
+}
+
+func (e *runsCSVEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
 func (h *TaskHandler) handleForceRun(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -1056,6 +2358,11 @@ func decodeForceRunRequest(ctx context.Context, r *http.Request) (forceRunReques
 func (h *TaskHandler) handleGetRun(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if httprouter.ParamsFromContext(ctx).ByName("rid") == tasksRunsExportID {
+		h.handleExportRuns(w, r)
+		return
+	}
+
 	req, err := decodeGetRunRequest(ctx, r)
 	if err != nil {
 		err = &platform.Error{
@@ -1649,6 +2956,15 @@ func (t TaskService) FindRuns(ctx context.Context, filter platform.RunFilter) ([
 	if filter.Limit > 0 {
 		val.Set("limit", strconv.Itoa(filter.Limit))
 	}
+	if filter.AfterTime != "" {
+		val.Set("afterTime", filter.AfterTime)
+	}
+	if filter.BeforeTime != "" {
+		val.Set("beforeTime", filter.BeforeTime)
+	}
+	if filter.Status != "" {
+		val.Set("status", filter.Status)
+	}
 	u.RawQuery = val.Encode()
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
@@ -1824,6 +3140,273 @@ func (t TaskService) ForceRun(ctx context.Context, taskID platform.ID, scheduled
 	return &rs.Run, nil
 }
 
+// FindTaskVersions returns the previous revisions of a task, oldest first.
+func (t TaskService) FindTaskVersions(ctx context.Context, taskID platform.ID) ([]*platform.TaskVersion, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := newURL(t.Addr, taskIDVersionsPath(taskID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if platform.ErrorCode(err) == platform.ENotFound {
+			return nil, backend.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	var vr taskVersionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&vr); err != nil {
+		return nil, err
+	}
+
+	versions := make([]*platform.TaskVersion, len(vr.Versions))
+	for i := range vr.Versions {
+		versions[i] = &vr.Versions[i].TaskVersion
+	}
+	return versions, nil
+}
+
+// RollbackTask restores the Flux script of a previous revision of a task.
+func (t TaskService) RollbackTask(ctx context.Context, taskID platform.ID, version int) (*platform.Task, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p := path.Join(taskIDVersionsPath(taskID), strconv.Itoa(version), "rollback")
+	u, err := newURL(t.Addr, p)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+
+	return &tr.Task, nil
+}
+
+// CloneTask creates a copy of the task in the organization of the clone request,
+// or in the organization of the task if the clone request has no organization.
+func (t TaskService) CloneTask(ctx context.Context, taskID platform.ID, clone platform.CloneRequest) (*platform.Task, error) {
+	if err := clone.ValidateBucketMapping(); err != nil {
+		return nil, err
+	}
+
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := newURL(t.Addr, path.Join(taskIDPath(taskID), "clone"))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+
+	return &tr.Task, nil
+}
+
+// ExportTask returns a portable document describing the task.
+func (t TaskService) ExportTask(ctx context.Context, taskID platform.ID) (*platform.TaskExport, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := newURL(t.Addr, path.Join(taskIDPath(taskID), "export"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var e platform.TaskExport
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// ImportTask creates a task from an exported document.
+func (t TaskService) ImportTask(ctx context.Context, ti platform.TaskImport) (*platform.Task, error) {
+	if err := ti.Validate(); err != nil {
+		return nil, err
+	}
+
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := newURL(t.Addr, path.Join(tasksPath, tasksImportID))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(ti)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+
+	return &tr.Task, nil
+}
+
+// MoveTask transfers a task to another organization, along with its run history if requested.
+func (t TaskService) MoveTask(ctx context.Context, taskID platform.ID, move platform.TaskMove) (*platform.Task, error) {
+	if err := move.Validate(); err != nil {
+		return nil, err
+	}
+
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := newURL(t.Addr, path.Join(taskIDPath(taskID), "move"))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(move)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(t.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+
+	return &tr.Task, nil
+}
+
 func cancelPath(taskID, runID platform.ID) string {
 	return path.Join(taskID.String(), runID.String())
 }
@@ -1864,6 +3447,10 @@ func taskIDPath(id platform.ID) string {
 	return path.Join(tasksPath, id.String())
 }
 
+func taskIDVersionsPath(id platform.ID) string {
+	return path.Join(tasksPath, id.String(), "versions")
+}
+
 func taskIDRunsPath(id platform.ID) string {
 	return path.Join(tasksPath, id.String(), "runs")
 }
@@ -3,12 +3,14 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -133,7 +135,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000001/members",
         "labels": "/api/v2/tasks/0000000000000001/labels",
         "runs": "/api/v2/tasks/0000000000000001/runs",
-        "logs": "/api/v2/tasks/0000000000000001/logs"
+        "logs": "/api/v2/tasks/0000000000000001/logs",
+        "schedule": "/api/v2/tasks/0000000000000001/schedule",
+        "versions": "/api/v2/tasks/0000000000000001/versions"
       },
       "id": "0000000000000001",
       "name": "task1",
@@ -159,7 +163,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "schedule": "/api/v2/tasks/0000000000000002/schedule",
+        "versions": "/api/v2/tasks/0000000000000002/versions"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -232,7 +238,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "schedule": "/api/v2/tasks/0000000000000002/schedule",
+        "versions": "/api/v2/tasks/0000000000000002/versions"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -304,7 +312,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "schedule": "/api/v2/tasks/0000000000000002/schedule",
+        "versions": "/api/v2/tasks/0000000000000002/versions"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -464,7 +474,9 @@ func TestTaskHandler_handlePostTasks(t *testing.T) {
     "members": "/api/v2/tasks/0000000000000001/members",
     "labels": "/api/v2/tasks/0000000000000001/labels",
     "runs": "/api/v2/tasks/0000000000000001/runs",
-    "logs": "/api/v2/tasks/0000000000000001/logs"
+    "logs": "/api/v2/tasks/0000000000000001/logs",
+    "schedule": "/api/v2/tasks/0000000000000001/schedule",
+    "versions": "/api/v2/tasks/0000000000000001/versions"
   },
   "id": "0000000000000001",
   "name": "task1",
@@ -731,6 +743,723 @@ func TestTaskHandler_handleGetRuns(t *testing.T) {
 	}
 }
 
+func TestTaskHandler_handleExportRuns(t *testing.T) {
+	// More runs than fit in a page, with every tenth run failed.
+	var all []*platform.Run
+	for i := 1; i <= 150; i++ {
+		run := &platform.Run{
+			ID:           platform.ID(i),
+			TaskID:       1,
+			Status:       "success",
+			ScheduledFor: time.Unix(int64(i*60), 0).UTC().Format(time.RFC3339),
+			StartedAt:    time.Unix(int64(i*60+1), 0).UTC().Format(time.RFC3339Nano),
+			FinishedAt:   time.Unix(int64(i*60+3), 500000000).UTC().Format(time.RFC3339Nano),
+		}
+		if i%10 == 0 {
+			run.Status = "failed"
+		}
+		all = append(all, run)
+	}
+
+	var filters []platform.RunFilter
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		FindRunsFn: func(ctx context.Context, f platform.RunFilter) ([]*platform.Run, int, error) {
+			filters = append(filters, f)
+			var runs []*platform.Run
+			for _, r := range all {
+				if (f.After == nil || r.ID > *f.After) && len(runs) < f.Limit {
+					runs = append(runs, r)
+				}
+			}
+			return runs, len(runs), nil
+		},
+		FindLogsFn: func(ctx context.Context, f platform.LogFilter) ([]*platform.Log, int, error) {
+			logs := []*platform.Log{
+				{Message: "Started"},
+				{Message: fmt.Sprintf("Run failed to execute: run %s, error", f.Run)},
+			}
+			return logs, len(logs), nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/tasks/0000000000000001/runs/export?start=1970-01-01T00:00:00Z&stop=1970-01-02T00:00:00Z", nil)
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleExportRuns() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("handleExportRuns() Content-Type = %v", ct)
+	}
+
+	if len(filters) != 2 {
+		t.Fatalf("expected runs to be read in 2 pages, got %d", len(filters))
+	}
+	if f := filters[0]; f.AfterTime != "1970-01-01T00:00:00Z" || f.BeforeTime != "1970-01-02T00:00:00Z" || f.After != nil {
+		t.Errorf("unexpected filter for first page: %+v", f)
+	}
+	if f := filters[1]; f.After == nil || *f.After != 100 {
+		t.Errorf("expected second page to follow run 100, got %+v", f)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4+len(all) {
+		t.Fatalf("expected 4 header rows and %d runs, got %d rows", len(all), len(records))
+	}
+	header := `#datatype,string,long,string,string,string,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,long,string
+#group,false,false,false,true,false,false,false,false,false,false,false
+#default,_result,,,,,,,,,,
+,result,table,runID,taskID,status,scheduledFor,requestedAt,startedAt,finishedAt,durationMs,error
+`
+	if got := strings.Join(strings.SplitAfterN(string(body), "\n", 5)[:4], ""); got != header {
+		t.Errorf("unexpected header:\n%s\nwant:\n%s", got, header)
+	}
+
+	exp := []string{"", "", "0", "000000000000000a", "0000000000000001", "failed", "1970-01-01T00:10:00Z", "", "1970-01-01T00:10:01Z", "1970-01-01T00:10:03.5Z", "2500", "Run failed to execute: run 000000000000000a, error"}
+	if got := records[4+9]; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected failed run row:\n%q\nwant:\n%q", got, exp)
+	}
+	if got := records[len(records)-2]; got[3] != platform.ID(149).String() || got[5] != "success" || got[11] != "" {
+		t.Errorf("unexpected successful run row: %v", got)
+	}
+
+	// An invalid range is rejected before anything is written.
+	r = httptest.NewRequest("GET", "http://any.url/api/v2/tasks/0000000000000001/runs/export?start=1970-01-02T00:00:00Z&stop=1970-01-01T00:00:00Z", nil)
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("export with stop before start = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTaskHandler_handleGetTaskSchedule(t *testing.T) {
+	taskService := &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			return &platform.Task{
+				ID:              id,
+				Name:            "task1",
+				Every:           "1h0m0s",
+				Offset:          "5m0s",
+				EffectiveCron:   "@every 1h0m0s",
+				LatestCompleted: "2019-01-01T00:00:00Z",
+			}, nil
+		},
+	}
+
+	r := httptest.NewRequest("GET", "http://any.url?n=2", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: platform.ID(1).String(),
+			},
+		}))
+	w := httptest.NewRecorder()
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = taskService
+	h := NewTaskHandler(taskBackend)
+	h.handleGetTaskSchedule(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetTaskSchedule() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000001/schedule",
+    "task": "/api/v2/tasks/0000000000000001"
+  },
+  "effectiveCron": "@every 1h0m0s",
+  "offset": "5m0s",
+  "next": [
+    {
+      "scheduledFor": "2019-01-01T01:00:00Z",
+      "dueAt": "2019-01-01T01:05:00Z"
+    },
+    {
+      "scheduledFor": "2019-01-01T02:00:00Z",
+      "dueAt": "2019-01-01T02:05:00Z"
+    }
+  ]
+}`
+	if eq, diff, _ := jsonEqual(string(body), exp); !eq {
+		t.Errorf("handleGetTaskSchedule() = ***%s***", diff)
+	}
+}
+
+func TestTaskHandler_handlePostTaskValidateSchedule(t *testing.T) {
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.OrganizationService = &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+			if filter.ID == nil || *filter.ID != 1 {
+				return nil, &platform.Error{
+					Code: platform.ENotFound,
+					Msg:  "organization not found",
+				}
+			}
+			return &platform.Organization{ID: 1, Name: "org", Timezone: "Asia/Kolkata"}, nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/validate-schedule?n=3", strings.NewReader(`{"orgID": "0000000000000001", "every": "1h", "offset": "10m"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	b, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handlePostTaskValidateSchedule() = %v, want %v: %s", res.StatusCode, http.StatusOK, b)
+	}
+
+	var resp taskValidateScheduleResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.EffectiveCron != "@every 1h0m0s" || resp.Offset != "10m0s" || resp.Timezone != "Asia/Kolkata" {
+		t.Fatalf("unexpected schedule validation response: %+v", resp)
+	}
+	if len(resp.Next) != 3 {
+		t.Fatalf("expected 3 scheduled runs, got %d", len(resp.Next))
+	}
+	for i, run := range resp.Next {
+		// Runs aligned on the hour in UTC are at half past in India.
+		if !strings.HasSuffix(run.ScheduledFor, ":30:00+05:30") {
+			t.Errorf("run %d: expected scheduled time in the organization's time zone, got %s", i, run.ScheduledFor)
+		}
+		if !strings.HasSuffix(run.DueAt, ":40:00+05:30") {
+			t.Errorf("run %d: expected due time in the organization's time zone to include offset, got %s", i, run.DueAt)
+		}
+	}
+
+	// A schedule with a timezone is shown in its own time zone.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/validate-schedule?n=2", strings.NewReader(`{"orgID": "0000000000000001", "cron": "0 9 * * *", "timezone": "America/New_York"}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("handlePostTaskValidateSchedule() = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	resp = taskValidateScheduleResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.EffectiveCron != "TZ=America/New_York 0 9 * * *" || resp.Timezone != "America/New_York" {
+		t.Fatalf("unexpected schedule validation response: %+v", resp)
+	}
+	for i, run := range resp.Next {
+		if !strings.Contains(run.ScheduledFor, "T09:00:00-0") {
+			t.Errorf("run %d: expected 9am in New York, got %s", i, run.ScheduledFor)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "invalid cron", body: `{"orgID": "0000000000000001", "cron": "not a cron"}`, want: http.StatusBadRequest},
+		{name: "cron and every", body: `{"orgID": "0000000000000001", "cron": "0 * * * *", "every": "1h"}`, want: http.StatusBadRequest},
+		{name: "invalid every", body: `{"orgID": "0000000000000001", "every": "often"}`, want: http.StatusBadRequest},
+		{name: "missing org", body: `{"cron": "0 * * * *"}`, want: http.StatusBadRequest},
+		{name: "unknown org", body: `{"orgID": "0000000000000002", "cron": "0 * * * *"}`, want: http.StatusNotFound},
+		{name: "unknown timezone", body: `{"orgID": "0000000000000001", "cron": "0 9 * * *", "timezone": "Mars/Olympus_Mons"}`, want: http.StatusBadRequest},
+		{name: "timezone with every", body: `{"orgID": "0000000000000001", "every": "1h", "timezone": "America/New_York"}`, want: http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/validate-schedule", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("POST validate schedule = %v, want %v: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_handlePostTaskDryRun(t *testing.T) {
+	script := `option task = {name: "dry", every: 1h, offset: 10m}
+from(bucket: "b") |> range(start: -1h)`
+	buf, err := json.Marshal(map[string]string{"flux": script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(buf)
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/dry-run?n=3", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		CreateTaskFn: func(context.Context, platform.TaskCreate) (*platform.Task, error) {
+			t.Fatal("dry run must not create a task")
+			return nil, nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	b, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handlePostTaskDryRun() = %v, want %v: %s", res.StatusCode, http.StatusOK, b)
+	}
+
+	var resp taskDryRunResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Name != "dry" || resp.EffectiveCron != "@every 1h0m0s" || resp.Offset != "10m0s" {
+		t.Fatalf("unexpected dry run response: %+v", resp)
+	}
+	if len(resp.Next) != 3 {
+		t.Fatalf("expected 3 scheduled runs, got %d", len(resp.Next))
+	}
+	for i, run := range resp.Next {
+		scheduledFor, err := time.Parse(time.RFC3339, run.ScheduledFor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dueAt, err := time.Parse(time.RFC3339, run.DueAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dueAt.Sub(scheduledFor) != 10*time.Minute {
+			t.Errorf("run %d: expected due time to include offset, got scheduledFor %s and dueAt %s", i, run.ScheduledFor, run.DueAt)
+		}
+		if scheduledFor.Truncate(time.Hour) != scheduledFor {
+			t.Errorf("run %d: expected every-based schedule to be aligned, got %s", i, run.ScheduledFor)
+		}
+	}
+
+	// Any other task ID is still not a valid POST target.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/0000000000000001", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST to task ID = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	// Invalid flux is rejected.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/dry-run", strings.NewReader(`{"flux": "option task = {name: 1}"}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST invalid dry run = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTaskHandler_handlePostTaskDryRunWithIncludes(t *testing.T) {
+	script := `option task = {name: "dry", every: 1h}
+// @include "source"
+source |> range(start: -1h)`
+	scripts := mock.NewTaskScriptService()
+	scripts.FindTaskScriptFn = func(ctx context.Context, orgID platform.ID, name string, version int) (*platform.TaskScript, error) {
+		if orgID != 1 || name != "source" {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrTaskScriptNotFound}
+		}
+		return &platform.TaskScript{OrgID: orgID, Name: name, Version: 1, Flux: `source = from(bucket: "b")`}, nil
+	}
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskScriptService = scripts
+	h := NewTaskHandler(taskBackend)
+
+	tests := []struct {
+		name       string
+		orgID      string
+		wantStatus int
+	}{
+		{
+			name:       "included script of the org",
+			orgID:      "0000000000000001",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "included script missing from the org",
+			orgID:      "0000000000000002",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := json.Marshal(map[string]string{"flux": script, "orgID": tt.orgID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/dry-run", bytes.NewReader(buf))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("POST dry run = %v, want %v: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_handleCloneTask(t *testing.T) {
+	srcID := platformtesting.MustIDBase16("020f755c3c082000")
+	var created platform.TaskCreate
+	var mappings []platform.LabelMapping
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			if id != srcID {
+				return nil, backend.ErrTaskNotFound
+			}
+			return &platform.Task{
+				ID:             srcID,
+				OrganizationID: 1,
+				Status:         "inactive",
+				Flux:           `option task = {name: "t", every: 1h} from(bucket: "staging") |> range(start: -1h)`,
+				Params:         map[string]string{"env": "staging"},
+			}, nil
+		},
+		CreateTaskFn: func(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+			created = tc
+			return &platform.Task{
+				ID:              platformtesting.MustIDBase16("020f755c3c082001"),
+				OrganizationID:  tc.OrganizationID,
+				AuthorizationID: 3,
+				Status:          tc.Status,
+				Flux:            tc.Flux,
+			}, nil
+		},
+	}
+	taskBackend.LabelService = &mock.LabelService{
+		FindResourceLabelsFn: func(ctx context.Context, f platform.LabelMappingFilter) ([]*platform.Label, error) {
+			if f.ResourceID != srcID {
+				return nil, nil
+			}
+			return []*platform.Label{{ID: 10, OrganizationID: 1, Name: "staging", Properties: map[string]string{"color": "red"}}}, nil
+		},
+		FindLabelsFn: func(ctx context.Context, f platform.LabelFilter) ([]*platform.Label, error) {
+			// Only the source organization has the label already.
+			if *f.OrgID == 1 && f.Name == "staging" {
+				return []*platform.Label{{ID: 10, OrganizationID: 1, Name: "staging", Properties: map[string]string{"color": "red"}}}, nil
+			}
+			return nil, nil
+		},
+		CreateLabelFn: func(ctx context.Context, l *platform.Label) error {
+			l.ID = 20
+			return nil
+		},
+		CreateLabelMappingFn: func(ctx context.Context, m *platform.LabelMapping) error {
+			mappings = append(mappings, *m)
+			return nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	body := `{"orgID": "0000000000000002", "bucketMapping": {"staging": "production"}, "token": "tok"}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082000/clone", strings.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("handleCloneTask() = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	want := platform.TaskCreate{
+		Flux:           `option task = {name: "t", every: 1h} from(bucket: "production") |> range(start: -1h)`,
+		Status:         "inactive",
+		OrganizationID: 2,
+		Token:          "tok",
+		Params:         map[string]string{"env": "staging"},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("unexpected task create:\ngot  %+v\nwant %+v", created, want)
+	}
+
+	// The clone is labeled with a new label of its organization, named like the label of the source.
+	var resp struct {
+		Labels []platform.Label `json:"labels"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Labels) != 1 || resp.Labels[0].ID != 20 || resp.Labels[0].OrganizationID != 2 || resp.Labels[0].Properties["color"] != "red" {
+		t.Errorf("unexpected clone labels: %+v", resp.Labels)
+	}
+	wantMappings := []platform.LabelMapping{{LabelID: 20, ResourceID: platformtesting.MustIDBase16("020f755c3c082001"), ResourceType: platform.TasksResourceType}}
+	if !reflect.DeepEqual(mappings, wantMappings) {
+		t.Errorf("unexpected label mappings:\ngot  %+v\nwant %+v", mappings, wantMappings)
+	}
+
+	// Without orgID, the task is cloned into its own organization, with its own labels.
+	mappings = nil
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082000/clone", strings.NewReader(`{}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("clone without orgID = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if created.OrganizationID != 1 || created.Flux != `option task = {name: "t", every: 1h} from(bucket: "staging") |> range(start: -1h)` {
+		t.Errorf("unexpected task create: %+v", created)
+	}
+	wantMappings[0].LabelID = 10
+	if !reflect.DeepEqual(mappings, wantMappings) {
+		t.Errorf("unexpected label mappings:\ngot  %+v\nwant %+v", mappings, wantMappings)
+	}
+
+	// A bucket mapping cannot map to an empty bucket.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082000/clone", strings.NewReader(`{"bucketMapping": {"staging": ""}}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("clone with invalid bucket mapping = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+
+	// Cloning an unknown task is not found.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082009/clone", strings.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("clone of missing task = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTaskHandler_handleExportImportTask(t *testing.T) {
+	srcID := platformtesting.MustIDBase16("020f755c3c082000")
+	flux := `import "influxdata/influxdb/secrets"
+
+option task = {name: "t", every: 1h}
+
+token = secrets.get(key: "TOKEN")
+from(bucket: "b") |> range(start: -1h)`
+	var created platform.TaskCreate
+	var mappings []platform.LabelMapping
+	var newLabels []*platform.Label
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			if id != srcID {
+				return nil, backend.ErrTaskNotFound
+			}
+			return &platform.Task{
+				ID:             srcID,
+				OrganizationID: 1,
+				Name:           "t",
+				Status:         "active",
+				Flux:           flux,
+				Every:          "1h",
+			}, nil
+		},
+		CreateTaskFn: func(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+			created = tc
+			return &platform.Task{
+				ID:             platformtesting.MustIDBase16("020f755c3c082001"),
+				OrganizationID: tc.OrganizationID,
+				Status:         tc.Status,
+				Flux:           tc.Flux,
+			}, nil
+		},
+	}
+	taskBackend.LabelService = &mock.LabelService{
+		FindResourceLabelsFn: func(ctx context.Context, f platform.LabelMappingFilter) ([]*platform.Label, error) {
+			return []*platform.Label{
+				{ID: 10, OrganizationID: 1, Name: "existing"},
+				{ID: 11, OrganizationID: 1, Name: "missing", Properties: map[string]string{"color": "red"}},
+			}, nil
+		},
+		FindLabelsFn: func(ctx context.Context, f platform.LabelFilter) ([]*platform.Label, error) {
+			if f.Name == "existing" {
+				return []*platform.Label{{ID: 20, OrganizationID: *f.OrgID, Name: f.Name}}, nil
+			}
+			return nil, nil
+		},
+		CreateLabelFn: func(ctx context.Context, l *platform.Label) error {
+			l.ID = 21
+			newLabels = append(newLabels, l)
+			return nil
+		},
+		CreateLabelMappingFn: func(ctx context.Context, m *platform.LabelMapping) error {
+			mappings = append(mappings, *m)
+			return nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/tasks/020f755c3c082000/export", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("handleExportTask() = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var export platform.TaskExport
+	if err := json.NewDecoder(w.Body).Decode(&export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Flux != flux {
+		t.Errorf("exported flux = %q, want %q", export.Flux, flux)
+	}
+	if len(export.Secrets) != 1 || export.Secrets[0] != "TOKEN" {
+		t.Errorf("exported secrets = %v, want [TOKEN]", export.Secrets)
+	}
+
+	body, err := json.Marshal(platform.TaskImport{OrganizationID: 2, Token: "tok", Task: export})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/import", bytes.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("handleImportTask() = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	// The secret reference is kept as written, rather than being resolved.
+	want := platform.TaskCreate{
+		Flux:           flux,
+		Status:         "active",
+		OrganizationID: 2,
+		Organization:   "test",
+		Token:          "tok",
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("unexpected task create:\ngot  %+v\nwant %+v", created, want)
+	}
+
+	if len(newLabels) != 1 || newLabels[0].Name != "missing" || newLabels[0].OrganizationID != 2 || newLabels[0].Properties["color"] != "red" {
+		t.Errorf("unexpected created labels: %+v", newLabels)
+	}
+	wantMappings := []platform.LabelMapping{
+		{LabelID: 20, ResourceID: platformtesting.MustIDBase16("020f755c3c082001"), ResourceType: platform.TasksResourceType},
+		{LabelID: 21, ResourceID: platformtesting.MustIDBase16("020f755c3c082001"), ResourceType: platform.TasksResourceType},
+	}
+	if !reflect.DeepEqual(mappings, wantMappings) {
+		t.Errorf("unexpected label mappings:\ngot  %+v\nwant %+v", mappings, wantMappings)
+	}
+
+	// An export of an unknown version is refused.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/import", strings.NewReader(`{"orgID": "0000000000000002", "task": {"version": "0", "flux": "x"}}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Permissions: platform.OperPermissions()}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("import of unknown version = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTaskHandler_handleMoveTask(t *testing.T) {
+	taskID := platformtesting.MustIDBase16("020f755c3c082000")
+	var moved platform.TaskMove
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		MoveTaskFn: func(ctx context.Context, id platform.ID, move platform.TaskMove) (*platform.Task, error) {
+			if id != taskID {
+				return nil, backend.ErrTaskNotFound
+			}
+			moved = move
+			return &platform.Task{
+				ID:              taskID,
+				OrganizationID:  move.OrganizationID,
+				AuthorizationID: 3,
+				Status:          "active",
+			}, nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	body := `{"orgID": "0000000000000002", "token": "tok", "moveRuns": true}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082000/move", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("handleMoveTask() = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	want := platform.TaskMove{OrganizationID: 2, Token: "tok", MoveRuns: true}
+	if moved != want {
+		t.Errorf("unexpected task move:\ngot  %+v\nwant %+v", moved, want)
+	}
+	if !strings.Contains(w.Body.String(), `"orgID":"0000000000000002"`) {
+		t.Errorf("expected moved task in response, got %s", w.Body.String())
+	}
+
+	// A move request needs a destination organization.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082000/move", strings.NewReader(`{}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("move without orgID = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+
+	// Moving an unknown task is not found.
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/tasks/020f755c3c082009/move", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("move of missing task = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTaskHandler_decodeGetRunsRequestStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus string
+		wantErr    bool
+	}{
+		{
+			name:       "failed status",
+			query:      "?status=failed",
+			wantStatus: "failed",
+		},
+		{
+			name:  "no status",
+			query: "",
+		},
+		{
+			name:    "unknown status",
+			query:   "?status=exploded",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://any.url"+tt.query, nil)
+			ctx := context.WithValue(context.Background(), httprouter.ParamsKey, httprouter.Params{
+				{
+					Key:   "id",
+					Value: platform.ID(1).String(),
+				},
+			})
+
+			req, err := decodeGetRunsRequest(ctx, r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeGetRunsRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if req.filter.Status != tt.wantStatus {
+				t.Errorf("decodeGetRunsRequest() status = %q, want %q", req.filter.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
 func TestTaskHandler_NotFoundStatus(t *testing.T) {
 	// Ensure that the HTTP handlers return 404s for missing resources, and OKs for matching.
 
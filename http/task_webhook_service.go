@@ -0,0 +1,287 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	tasksIDWebhooksPath             = "/api/v2/tasks/:id/webhooks"
+	tasksIDWebhooksIDPath           = "/api/v2/tasks/:id/webhooks/:webhookID"
+	tasksIDWebhooksIDDeliveriesPath = "/api/v2/tasks/:id/webhooks/:webhookID/deliveries"
+)
+
+// taskWebhook is the HTTP representation of a task webhook; its secret is never sent back.
+type taskWebhook struct {
+	Links     map[string]string `json:"links"`
+	ID        platform.ID       `json:"id"`
+	TaskID    platform.ID       `json:"taskID"`
+	OrgID     platform.ID       `json:"orgID"`
+	URL       string            `json:"url"`
+	Events    []string          `json:"events"`
+	Template  string            `json:"template,omitempty"`
+	HasSecret bool              `json:"hasSecret"`
+}
+
+func newTaskWebhookResponse(w *platform.TaskWebhook) taskWebhook {
+	return taskWebhook{
+		Links: map[string]string{
+			"self":       fmt.Sprintf("/api/v2/tasks/%s/webhooks/%s", w.TaskID, w.ID),
+			"task":       fmt.Sprintf("/api/v2/tasks/%s", w.TaskID),
+			"deliveries": fmt.Sprintf("/api/v2/tasks/%s/webhooks/%s/deliveries", w.TaskID, w.ID),
+		},
+		ID:        w.ID,
+		TaskID:    w.TaskID,
+		OrgID:     w.OrgID,
+		URL:       w.URL,
+		Events:    w.Events,
+		Template:  w.Template,
+		HasSecret: w.Secret != "",
+	}
+}
+
+type taskWebhooksResponse struct {
+	Links    map[string]string `json:"links"`
+	Webhooks []taskWebhook     `json:"webhooks"`
+}
+
+func newTaskWebhooksResponse(taskID platform.ID, ws []*platform.TaskWebhook) taskWebhooksResponse {
+	res := taskWebhooksResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/webhooks", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Webhooks: make([]taskWebhook, 0, len(ws)),
+	}
+	for _, w := range ws {
+		res.Webhooks = append(res.Webhooks, newTaskWebhookResponse(w))
+	}
+	return res
+}
+
+type taskWebhookDeliveriesResponse struct {
+	Links      map[string]string               `json:"links"`
+	Deliveries []*platform.TaskWebhookDelivery `json:"deliveries"`
+}
+
+// handleGetTaskWebhooks is the HTTP handler for the GET /api/v2/tasks/:id/webhooks route.
+func (h *TaskHandler) handleGetTaskWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if _, err := h.findTask(ctx, req.TaskID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ws, err := h.TaskWebhookService.FindTaskWebhooks(ctx, platform.TaskWebhookFilter{TaskID: &req.TaskID})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskWebhooksResponse(req.TaskID, ws)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handlePostTaskWebhook is the HTTP handler for the POST /api/v2/tasks/:id/webhooks route.
+func (h *TaskHandler) handlePostTaskWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	wh := &platform.TaskWebhook{}
+	if err := json.NewDecoder(r.Body).Decode(wh); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	task, err := h.findTask(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	wh.TaskID = task.ID
+	wh.OrgID = task.OrganizationID
+
+	if err := h.TaskWebhookService.CreateTaskWebhook(ctx, wh); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskWebhookResponse(wh)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handleGetTaskWebhook is the HTTP handler for the GET /api/v2/tasks/:id/webhooks/:webhookID route.
+func (h *TaskHandler) handleGetTaskWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	wh, err := h.findTaskWebhook(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskWebhookResponse(wh)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handlePatchTaskWebhook is the HTTP handler for the PATCH /api/v2/tasks/:id/webhooks/:webhookID route.
+func (h *TaskHandler) handlePatchTaskWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var upd platform.TaskWebhookUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	wh, err := h.findTaskWebhook(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	wh, err = h.TaskWebhookService.UpdateTaskWebhook(ctx, wh.ID, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskWebhookResponse(wh)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// handleDeleteTaskWebhook is the HTTP handler for the DELETE /api/v2/tasks/:id/webhooks/:webhookID route.
+func (h *TaskHandler) handleDeleteTaskWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	wh, err := h.findTaskWebhook(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.TaskWebhookService.DeleteTaskWebhook(ctx, wh.ID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetTaskWebhookDeliveries is the HTTP handler for the GET /api/v2/tasks/:id/webhooks/:webhookID/deliveries route.
+func (h *TaskHandler) handleGetTaskWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	wh, err := h.findTaskWebhook(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ds, err := h.TaskWebhookService.FindTaskWebhookDeliveries(ctx, wh.ID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := taskWebhookDeliveriesResponse{
+		Links: map[string]string{
+			"self":    fmt.Sprintf("/api/v2/tasks/%s/webhooks/%s/deliveries", wh.TaskID, wh.ID),
+			"webhook": fmt.Sprintf("/api/v2/tasks/%s/webhooks/%s", wh.TaskID, wh.ID),
+		},
+		Deliveries: ds,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+// findTask returns the task with the given ID, if the authorizer on context can read it.
+func (h *TaskHandler) findTask(ctx context.Context, id platform.ID) (*platform.Task, error) {
+	task, err := h.TaskService.FindTaskByID(ctx, id)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find task",
+		}
+		if err.Err == backend.ErrTaskNotFound {
+			err.Code = platform.ENotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// findTaskWebhook returns the webhook identified by the path, which must belong to the task of the path.
+func (h *TaskHandler) findTaskWebhook(ctx context.Context) (*platform.TaskWebhook, error) {
+	params := httprouter.ParamsFromContext(ctx)
+
+	var taskID, id platform.ID
+	if err := taskID.DecodeFromString(params.ByName("id")); err != nil {
+		return nil, err
+	}
+	if err := id.DecodeFromString(params.ByName("webhookID")); err != nil {
+		return nil, err
+	}
+
+	wh, err := h.TaskWebhookService.FindTaskWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wh.TaskID != taskID {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  platform.ErrTaskWebhookNotFound,
+		}
+	}
+	return wh, nil
+}
+
+// deleteTaskWebhooks removes the webhooks of a deleted task.
+func (h *TaskHandler) deleteTaskWebhooks(ctx context.Context, taskID platform.ID) error {
+	ws, err := h.TaskWebhookService.FindTaskWebhooks(ctx, platform.TaskWebhookFilter{TaskID: &taskID})
+	if err != nil {
+		return err
+	}
+	for _, wh := range ws {
+		if err := h.TaskWebhookService.DeleteTaskWebhook(ctx, wh.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
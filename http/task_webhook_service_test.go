@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/task/backend"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestTaskHandler_TaskWebhooks(t *testing.T) {
+	taskID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+	otherTaskID := platformtesting.MustIDBase16("020f755c3c082002")
+
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.IDGenerator = mock.NewIDGenerator("020f755c3c083000", t)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutTaskWebhook(ctx, &platform.TaskWebhook{
+		ID:     platformtesting.MustIDBase16("020f755c3c083001"),
+		TaskID: otherTaskID,
+		OrgID:  orgID,
+		URL:    "http://example.com/hook",
+		Events: []string{platform.TaskWebhookEventFailure},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			if id != taskID && id != otherTaskID {
+				return nil, backend.ErrTaskNotFound
+			}
+			return &platform.Task{ID: id, OrganizationID: orgID}, nil
+		},
+	}
+	taskBackend.TaskWebhookService = svc
+	h := NewTaskHandler(taskBackend)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, "http://any.url"+path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do("POST", "/api/v2/tasks/020f755c3c082000/webhooks", `{"url": "https://example.com/notify", "events": ["failure"], "secret": "s3cr3t"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST webhook = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000",
+    "task": "/api/v2/tasks/020f755c3c082000",
+    "deliveries": "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000/deliveries"
+  },
+  "id": "020f755c3c083000",
+  "taskID": "020f755c3c082000",
+  "orgID": "020f755c3c082001",
+  "url": "https://example.com/notify",
+  "events": ["failure"],
+  "hasSecret": true
+}`
+	if eq, diff, _ := jsonEqual(w.Body.String(), exp); !eq {
+		t.Errorf("POST webhook = ***%s***", diff)
+	}
+
+	if w := do("POST", "/api/v2/tasks/020f755c3c082000/webhooks", `{"url": "example.com", "events": ["failure"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("POST invalid webhook = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+	if w := do("POST", "/api/v2/tasks/020f755c3c082009/webhooks", `{"url": "https://example.com/notify", "events": ["failure"]}`); w.Code != http.StatusNotFound {
+		t.Errorf("POST webhook of missing task = %v, want %v", w.Code, http.StatusNotFound)
+	}
+
+	w = do("GET", "/api/v2/tasks/020f755c3c082000/webhooks", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET webhooks = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if strings.Count(w.Body.String(), `"id":`) != 1 || strings.Contains(w.Body.String(), "s3cr3t") {
+		t.Errorf("unexpected webhooks %s", w.Body.String())
+	}
+
+	w = do("PATCH", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000", `{"events": ["success", "failure"], "secret": ""}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH webhook = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"events":["success","failure"]`) || !strings.Contains(w.Body.String(), `"hasSecret":false`) {
+		t.Errorf("unexpected updated webhook %s", w.Body.String())
+	}
+
+	if err := svc.AddTaskWebhookDelivery(ctx, &platform.TaskWebhookDelivery{
+		WebhookID:  platformtesting.MustIDBase16("020f755c3c083000"),
+		RunID:      3,
+		Event:      platform.TaskWebhookEventFailure,
+		Attempt:    1,
+		StatusCode: http.StatusOK,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	w = do("GET", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000/deliveries", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET deliveries = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"statusCode":200`) {
+		t.Errorf("unexpected deliveries %s", w.Body.String())
+	}
+
+	// A webhook is only found under its own task.
+	if w := do("GET", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083001", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET webhook of other task = %v, want %v", w.Code, http.StatusNotFound)
+	}
+	if w := do("DELETE", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083001", ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE webhook of other task = %v, want %v", w.Code, http.StatusNotFound)
+	}
+
+	if w := do("DELETE", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE webhook = %v, want %v: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if w := do("GET", "/api/v2/tasks/020f755c3c082000/webhooks/020f755c3c083000", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET deleted webhook = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTaskHandler_DeleteTaskDeletesWebhooks(t *testing.T) {
+	taskID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	var deleted []platform.ID
+	webhooks := mock.NewTaskWebhookService()
+	webhooks.FindTaskWebhooksFn = func(ctx context.Context, filter platform.TaskWebhookFilter) ([]*platform.TaskWebhook, error) {
+		return []*platform.TaskWebhook{{ID: 1, TaskID: *filter.TaskID}, {ID: 2, TaskID: *filter.TaskID}}, nil
+	}
+	webhooks.DeleteTaskWebhookFn = func(ctx context.Context, id platform.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.TaskService = &mock.TaskService{
+		DeleteTaskFn: func(ctx context.Context, id platform.ID) error {
+			if id != taskID {
+				return backend.ErrTaskNotFound
+			}
+			return nil
+		},
+	}
+	taskBackend.TaskWebhookService = webhooks
+	h := NewTaskHandler(taskBackend)
+
+	r := httptest.NewRequest("DELETE", "http://any.url/api/v2/tasks/020f755c3c082000", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("handleDeleteTask() = %v, want %v: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if len(deleted) != 2 || deleted[0] != 1 || deleted[1] != 2 {
+		t.Errorf("unexpected deleted webhooks %v", deleted)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	platform "github.com/influxdata/influxdb"
 	pctx "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/telegraf/plugins"
+	"github.com/influxdata/influxdb/telegraf/plugins/outputs"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
@@ -25,6 +26,9 @@ type TelegrafBackend struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	OrganizationService        platform.OrganizationService
+	AuthorizationService       platform.AuthorizationService
+	BucketService              platform.BucketService
+	SecretService              platform.SecretService
 }
 
 // NewTelegrafBackend returns a new instance of TelegrafBackend.
@@ -37,6 +41,9 @@ func NewTelegrafBackend(b *APIBackend) *TelegrafBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		AuthorizationService:       b.AuthorizationService,
+		BucketService:              b.BucketService,
+		SecretService:              b.SecretService,
 	}
 }
 
@@ -50,11 +57,15 @@ type TelegrafHandler struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	OrganizationService        platform.OrganizationService
+	AuthorizationService       platform.AuthorizationService
+	BucketService              platform.BucketService
+	SecretService              platform.SecretService
 }
 
 const (
 	telegrafsPath            = "/api/v2/telegrafs"
 	telegrafsIDPath          = "/api/v2/telegrafs/:id"
+	telegrafsIDTokensPath    = "/api/v2/telegrafs/:id/tokens"
 	telegrafsIDMembersPath   = "/api/v2/telegrafs/:id/members"
 	telegrafsIDMembersIDPath = "/api/v2/telegrafs/:id/members/:userID"
 	telegrafsIDOwnersPath    = "/api/v2/telegrafs/:id/owners"
@@ -74,12 +85,16 @@ func NewTelegrafHandler(b *TelegrafBackend) *TelegrafHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		AuthorizationService:       b.AuthorizationService,
+		BucketService:              b.BucketService,
+		SecretService:              b.SecretService,
 	}
 	h.HandlerFunc("POST", telegrafsPath, h.handlePostTelegraf)
 	h.HandlerFunc("GET", telegrafsPath, h.handleGetTelegrafs)
 	h.HandlerFunc("GET", telegrafsIDPath, h.handleGetTelegraf)
 	h.HandlerFunc("DELETE", telegrafsIDPath, h.handleDeleteTelegraf)
 	h.HandlerFunc("PUT", telegrafsIDPath, h.handlePutTelegraf)
+	h.HandlerFunc("POST", telegrafsIDTokensPath, h.handlePostTelegrafTokens)
 
 	memberBackend := MemberBackend{
 		Logger:                     b.Logger.With(zap.String("handler", "member")),
@@ -434,3 +449,129 @@ func (h *TelegrafHandler) handleDeleteTelegraf(w http.ResponseWriter, r *http.Re
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+type telegrafToken struct {
+	AuthorizationID platform.ID `json:"authorizationID"`
+	BucketID        platform.ID `json:"bucketID"`
+	SecretKey       string      `json:"secretKey"`
+	Token           string      `json:"token"`
+}
+
+type telegrafTokensResponse struct {
+	Tokens []telegrafToken `json:"tokens"`
+	Config string          `json:"config"`
+}
+
+// handlePostTelegrafTokens is the HTTP handler for the POST /api/v2/telegrafs/:id/tokens route.
+// It generates, for every influxdb_v2 output of the config, a token that can only write to the output's bucket.
+// Each token is stored as a secret of the organization, and the rendered TOML references
+// the secret key as an environment variable instead of containing the token itself.
+func (h *TelegrafHandler) handlePostTelegrafTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetTelegrafRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	tc, err := h.TelegrafService.FindTelegrafConfigByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res, err := h.createTelegrafTokens(ctx, auth.GetUserID(), tc)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *TelegrafHandler) createTelegrafTokens(ctx context.Context, userID platform.ID, tc *platform.TelegrafConfig) (*telegrafTokensResponse, error) {
+	res := &telegrafTokensResponse{Tokens: []telegrafToken{}}
+
+	// The rendered config is a copy so that the stored config keeps its plugins.
+	rendered := *tc
+	rendered.Plugins = make([]platform.TelegrafPlugin, len(tc.Plugins))
+	copy(rendered.Plugins, tc.Plugins)
+
+	for i, p := range rendered.Plugins {
+		o, ok := p.Config.(*outputs.InfluxDBV2)
+		if !ok {
+			continue
+		}
+
+		b, err := h.findTelegrafOutputBucket(ctx, tc.OrganizationID, o)
+		if err != nil {
+			return nil, err
+		}
+
+		perm, err := platform.NewPermissionAtID(b.ID, platform.WriteAction, platform.BucketsResourceType, b.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		a := &platform.Authorization{
+			OrgID:       b.OrganizationID,
+			UserID:      userID,
+			Description: fmt.Sprintf("telegraf config %s writing to bucket %s", tc.Name, b.Name),
+			Permissions: []platform.Permission{*perm},
+		}
+		if err := h.AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("TELEGRAF_%s_TOKEN_%d", tc.ID, len(res.Tokens))
+		if err := h.SecretService.PutSecret(ctx, tc.OrganizationID, key, a.Token); err != nil {
+			return nil, err
+		}
+
+		out := *o
+		out.Token = "$" + key
+		rendered.Plugins[i].Config = &out
+
+		res.Tokens = append(res.Tokens, telegrafToken{
+			AuthorizationID: a.ID,
+			BucketID:        b.ID,
+			SecretKey:       key,
+			Token:           a.Token,
+		})
+	}
+
+	if len(res.Tokens) == 0 {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "telegraf config has no influxdb_v2 output",
+		}
+	}
+
+	res.Config = rendered.TOML()
+	return res, nil
+}
+
+// findTelegrafOutputBucket returns the bucket o writes to.
+// The bucket is looked up in the organization named by o, or in orgID if o names none.
+func (h *TelegrafHandler) findTelegrafOutputBucket(ctx context.Context, orgID platform.ID, o *outputs.InfluxDBV2) (*platform.Bucket, error) {
+	if o.Organization != "" {
+		org, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &o.Organization})
+		if err != nil {
+			return nil, err
+		}
+		orgID = org.ID
+	}
+
+	return h.BucketService.FindBucket(ctx, platform.BucketFilter{
+		OrganizationID: &orgID,
+		Name:           &o.Bucket,
+	})
+}
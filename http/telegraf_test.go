@@ -10,9 +10,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/telegraf/plugins/inputs"
 	"github.com/influxdata/influxdb/telegraf/plugins/outputs"
@@ -28,6 +30,9 @@ func NewMockTelegrafBackend() *TelegrafBackend {
 		LabelService:               mock.NewLabelService(),
 		UserService:                mock.NewUserService(),
 		OrganizationService:        mock.NewOrganizationService(),
+		AuthorizationService:       mock.NewAuthorizationService(),
+		BucketService:              mock.NewBucketService(),
+		SecretService:              mock.NewSecretService(),
 	}
 }
 
@@ -752,6 +757,133 @@ func TestTelegrafHandler_handleGetTelegraf(t *testing.T) {
 	}
 }
 
+func TestTelegrafHandler_handlePostTelegrafTokens(t *testing.T) {
+	tc := &platform.TelegrafConfig{
+		ID:             platform.ID(1),
+		OrganizationID: platform.ID(2),
+		Name:           "my config",
+		Agent: platform.TelegrafAgentConfig{
+			Interval: 10000,
+		},
+		Plugins: []platform.TelegrafPlugin{
+			{
+				Comment: "my cpu stats",
+				Config:  &inputs.CPUStats{},
+			},
+			{
+				Comment: "my influx output",
+				Config: &outputs.InfluxDBV2{
+					URLs:   []string{"http://127.0.0.1:9999"},
+					Token:  "no_more_secrets",
+					Bucket: "my_bucket",
+				},
+			},
+			{
+				Comment: "my other influx output",
+				Config: &outputs.InfluxDBV2{
+					URLs:         []string{"http://127.0.0.1:9999"},
+					Token:        "no_more_secrets",
+					Organization: "other_org",
+					Bucket:       "other_bucket",
+				},
+			},
+		},
+	}
+
+	telegrafBackend := NewMockTelegrafBackend()
+	telegrafBackend.TelegrafService = &mock.TelegrafConfigStore{
+		FindTelegrafConfigByIDF: func(ctx context.Context, id platform.ID) (*platform.TelegrafConfig, error) {
+			return tc, nil
+		},
+	}
+	telegrafBackend.OrganizationService = &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+			if *filter.Name != "other_org" {
+				return nil, &platform.Error{Code: platform.ENotFound}
+			}
+			return &platform.Organization{ID: platform.ID(3), Name: *filter.Name}, nil
+		},
+	}
+	buckets := map[string]platform.ID{"my_bucket": 4, "other_bucket": 5}
+	telegrafBackend.BucketService = &mock.BucketService{
+		FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+			return &platform.Bucket{ID: buckets[*filter.Name], OrganizationID: *filter.OrganizationID, Name: *filter.Name}, nil
+		},
+	}
+	var auths []*platform.Authorization
+	telegrafBackend.AuthorizationService = &mock.AuthorizationService{
+		CreateAuthorizationFn: func(ctx context.Context, a *platform.Authorization) error {
+			a.ID = platform.ID(10 + len(auths))
+			a.Token = fmt.Sprintf("token%d", len(auths))
+			auths = append(auths, a)
+			return nil
+		},
+	}
+	secrets := map[string]string{}
+	telegrafBackend.SecretService = &mock.SecretService{
+		PutSecretFn: func(ctx context.Context, orgID platform.ID, k string, v string) error {
+			if orgID != tc.OrganizationID {
+				t.Errorf("secret stored in org %s, want %s", orgID, tc.OrganizationID)
+			}
+			secrets[k] = v
+			return nil
+		},
+	}
+	h := NewTelegrafHandler(telegrafBackend)
+
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/telegrafs/0000000000000001/tokens", nil)
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{UserID: platform.ID(6)}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	if res.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("handlePostTelegrafTokens() = %v, want %v: %s", res.StatusCode, http.StatusCreated, body)
+	}
+
+	var got telegrafTokensResponse
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []telegrafToken{
+		{AuthorizationID: 10, BucketID: 4, SecretKey: "TELEGRAF_0000000000000001_TOKEN_0", Token: "token0"},
+		{AuthorizationID: 11, BucketID: 5, SecretKey: "TELEGRAF_0000000000000001_TOKEN_1", Token: "token1"},
+	}
+	if diff := cmp.Diff(got.Tokens, want); diff != "" {
+		t.Errorf("tokens are different -got/+want\ndiff %s", diff)
+	}
+
+	for i, a := range auths {
+		if a.UserID != 6 {
+			t.Errorf("authorization %d belongs to user %s, want 0000000000000006", i, a.UserID)
+		}
+		if len(a.Permissions) != 1 || a.Permissions[0].Action != platform.WriteAction ||
+			a.Permissions[0].Resource.ID == nil || *a.Permissions[0].Resource.ID != want[i].BucketID {
+			t.Errorf("authorization %d has permissions %v, want write on bucket %s only", i, a.Permissions, want[i].BucketID)
+		}
+		if secrets[want[i].SecretKey] != a.Token {
+			t.Errorf("secret %s = %q, want %q", want[i].SecretKey, secrets[want[i].SecretKey], a.Token)
+		}
+	}
+	if auths[1].OrgID != 3 {
+		t.Errorf("authorization for other_org is in org %s, want 0000000000000003", auths[1].OrgID)
+	}
+
+	if strings.Contains(got.Config, "no_more_secrets") {
+		t.Errorf("rendered config contains the original token:\n%s", got.Config)
+	}
+	for _, tok := range want {
+		if !strings.Contains(got.Config, `token = "$`+tok.SecretKey+`"`) {
+			t.Errorf("rendered config does not reference secret %s:\n%s", tok.SecretKey, got.Config)
+		}
+	}
+	if tc.Plugins[1].Config.(*outputs.InfluxDBV2).Token != "no_more_secrets" {
+		t.Error("rendering the config modified the stored config")
+	}
+}
+
 func Test_newTelegrafResponses(t *testing.T) {
 	type args struct {
 		tcs []*platform.TelegrafConfig
@@ -16,11 +16,15 @@ var (
 )
 
 // GetToken will parse the token from http Authorization Header.
+// The token may also be the password of basic authentication, as the clients of InfluxDB 1.x send it.
 func GetToken(r *http.Request) (string, error) {
 	header := r.Header.Get("Authorization")
 	if header == "" {
 		return "", ErrAuthHeaderMissing
 	}
+	if _, password, ok := r.BasicAuth(); ok && password != "" {
+		return password, nil
+	}
 	if !strings.HasPrefix(header, tokenScheme) {
 		return "", ErrAuthBadScheme
 	}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -56,6 +57,24 @@ func TestGetToken(t *testing.T) {
 				result: "tok2",
 			},
 		},
+		{
+			name: "good basic header",
+			args: args{
+				header: "Basic " + base64.StdEncoding.EncodeToString([]byte("me:tok3")),
+			},
+			wants: wants{
+				result: "tok3",
+			},
+		},
+		{
+			name: "basic header without password",
+			args: args{
+				header: "Basic " + base64.StdEncoding.EncodeToString([]byte("me:")),
+			},
+			wants: wants{
+				err: ErrAuthBadScheme,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -46,6 +46,7 @@ const (
 	usersPath         = "/api/v2/users"
 	mePath            = "/api/v2/me"
 	mePasswordPath    = "/api/v2/me/password"
+	mePermissionsPath = "/api/v2/me/permissions"
 	usersIDPath       = "/api/v2/users/:id"
 	usersPasswordPath = "/api/v2/users/:id/password"
 	usersLogPath      = "/api/v2/users/:id/logs"
@@ -72,6 +73,7 @@ func NewUserHandler(b *UserBackend) *UserHandler {
 
 	h.HandlerFunc("GET", mePath, h.handleGetMe)
 	h.HandlerFunc("PUT", mePasswordPath, h.handlePutUserPassword)
+	h.HandlerFunc("GET", mePermissionsPath, h.handleGetMePermissions)
 
 	return h
 }
@@ -200,6 +202,50 @@ func (h *UserHandler) handleGetMe(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type mePermissionsResponse struct {
+	Links       map[string]string         `json:"links"`
+	Kind        string                    `json:"kind"`
+	Permissions influxdb.PermissionMatrix `json:"permissions"`
+}
+
+// handleGetMePermissions is the HTTP handler for the GET /api/v2/me/permissions route.
+// It expands the effective permissions of the calling token or session into a matrix
+// of the resources every action is allowed on, per resource type.
+func (h *UserHandler) handleGetMePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var ps []influxdb.Permission
+	switch s := a.(type) {
+	case *influxdb.Session:
+		if s.Expired() == nil {
+			ps = s.Permissions
+		}
+	case *influxdb.Authorization:
+		if s.IsActive() {
+			ps = s.Permissions
+		}
+	}
+
+	res := mePermissionsResponse{
+		Links: map[string]string{
+			"self": mePermissionsPath,
+			"me":   mePath,
+		},
+		Kind:        a.Kind(),
+		Permissions: influxdb.NewPermissionMatrix(ps),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
 // handleGetUser is the HTTP handler for the GET /api/v2/users/:id route.
 func (h *UserHandler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
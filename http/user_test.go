@@ -2,10 +2,15 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/mock"
 	platformtesting "github.com/influxdata/influxdb/testing"
@@ -52,3 +57,76 @@ func TestUserService(t *testing.T) {
 	t.Parallel()
 	platformtesting.UserService(initUserService, t)
 }
+
+func TestUserHandler_handleGetMePermissions(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name       string
+		authorizer platform.Authorizer
+		want       string
+	}{
+		{
+			name: "active token",
+			authorizer: &platform.Authorization{
+				Status: platform.Active,
+				OrgID:  orgID,
+				Permissions: []platform.Permission{
+					{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+					{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID, ID: &bucketID}},
+				},
+			},
+			want: `{"kind":"authorization","buckets":{"read":{"all":false,"orgIDs":["020f755c3c082000"],"ids":[]},"write":{"all":false,"orgIDs":[],"ids":["020f755c3c082001"]}}}`,
+		},
+		{
+			name: "inactive token",
+			authorizer: &platform.Authorization{
+				Status: platform.Inactive,
+				OrgID:  orgID,
+				Permissions: []platform.Permission{
+					{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+				},
+			},
+			want: `{"kind":"authorization","buckets":{"read":{"all":false,"orgIDs":[],"ids":[]},"write":{"all":false,"orgIDs":[],"ids":[]}}}`,
+		},
+		{
+			name: "session",
+			authorizer: &platform.Session{
+				ExpiresAt:   time.Now().Add(time.Hour),
+				Permissions: platform.OperPermissions(),
+			},
+			want: `{"kind":"session","buckets":{"read":{"all":true,"orgIDs":[],"ids":[]},"write":{"all":true,"orgIDs":[],"ids":[]}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(NewMockUserBackend())
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/me/permissions", nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), tt.authorizer))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("handleGetMePermissions() = %v, want %v: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var res struct {
+				Kind        string                     `json:"kind"`
+				Permissions map[string]json.RawMessage `json:"permissions"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Permissions) != len(platform.AllResourceTypes) {
+				t.Errorf("expected %d resource types, got %d", len(platform.AllResourceTypes), len(res.Permissions))
+			}
+			got := fmt.Sprintf(`{"kind":%q,"buckets":%s}`, res.Kind, res.Permissions["buckets"])
+			if eq, diff, _ := jsonEqual(got, tt.want); !eq {
+				t.Errorf("handleGetMePermissions() = ***%s***", diff)
+			}
+		})
+	}
+}
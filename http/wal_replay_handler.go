@@ -0,0 +1,229 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// walReplayPath is the path replaying the WAL segments of a snapshot, for disaster recovery.
+const walReplayPath = "/api/v2/write/wal"
+
+// WALReplayResult is the result of the replay of a WAL segment.
+type WALReplayResult struct {
+	storage.WALSegmentStats
+
+	// Buckets are the buckets written by the replay.
+	Buckets []WALReplayBucket `json:"buckets"`
+}
+
+// WALReplayBucket is a bucket written by the replay of a WAL segment, with the number of points
+// written to it, and of the points dropped by the engine, such as those of conflicting field types.
+type WALReplayBucket struct {
+	OrgID    platform.ID `json:"orgID"`
+	BucketID platform.ID `json:"bucketID"`
+	Points   int         `json:"points"`
+	Dropped  int         `json:"dropped"`
+}
+
+// handleReplayWAL is the HTTP handler for the POST /api/v2/write/wal route, writing the points of the
+// WAL segment of the request body again, such as a segment of a snapshot taken before a partial data loss.
+// The points of every bucket of the segment, or only those of the bucketID parameter, are written if the
+// request is allowed to write to every one of them. Unlike line protocol writes, the points are neither
+// rate limited nor checked against the schemas of the buckets, as they were when first written.
+func (h *WriteHandler) handleReplayWAL(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	if h.WALSegmentReader == nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Op:   "http/handleReplayWAL",
+			Msg:  "WAL segments cannot be replayed by this server",
+		}, w)
+		return
+	}
+
+	var in io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleReplayWAL",
+				Msg:  errInvalidGzipHeader,
+				Err:  err,
+			}, w)
+			return
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var only *platform.ID
+	if s := r.URL.Query().Get("bucketID"); s != "" {
+		if only, err = platform.IDFromString(s); err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleReplayWAL",
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	points, stats, err := h.WALSegmentReader.ReadWALSegment(in)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handleReplayWAL",
+			Msg:  fmt.Sprintf("unable to read WAL segment: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	// The points are grouped by bucket, to check every bucket before writing any point.
+	byName := make(map[string][]models.Point)
+	for _, pt := range points {
+		byName[string(pt.Name())] = append(byName[string(pt.Name())], pt)
+	}
+	res := WALReplayResult{WALSegmentStats: stats, Buckets: []WALReplayBucket{}}
+	for name := range byName {
+		var ob [16]byte
+		copy(ob[:], name)
+		org, bucket := tsdb.DecodeName(ob)
+		if only != nil && bucket != *only {
+			continue
+		}
+		if err := h.checkReplayBucket(ctx, a, org, bucket); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		res.Buckets = append(res.Buckets, WALReplayBucket{OrgID: org, BucketID: bucket})
+	}
+	sort.Slice(res.Buckets, func(i, j int) bool { return res.Buckets[i].BucketID < res.Buckets[j].BucketID })
+
+	for i := range res.Buckets {
+		b := &res.Buckets[i]
+		name := tsdb.EncodeName(b.OrgID, b.BucketID)
+		pts := byName[string(name[:])]
+		b.Points = len(pts)
+		if err := h.PointsWriter.WritePoints(ctx, pts); err != nil {
+			pwe, ok := err.(tsdb.PartialWriteError)
+			if !ok {
+				h.Logger.Error("Error replaying WAL segment", zap.String("bucket", b.BucketID.String()), zap.Error(err))
+				EncodeError(ctx, &platform.Error{
+					Code: platform.EInternal,
+					Op:   "http/handleReplayWAL",
+					Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+					Err:  err,
+				}, w)
+				return
+			}
+			b.Points -= pwe.Dropped
+			b.Dropped = pwe.Dropped
+		}
+	}
+
+	h.Logger.Info("Replayed WAL segment", zap.Int("points", stats.Points), zap.Int("buckets", len(res.Buckets)), zap.String("corrupt", stats.Corrupt))
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// checkReplayBucket returns an error unless bucket exists in org, and a is allowed to write to it.
+func (h *WriteHandler) checkReplayBucket(ctx context.Context, a platform.Authorizer, org, bucket platform.ID) error {
+	b, err := h.BucketService.FindBucketByID(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if b.OrganizationID != org {
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Op:   "http/handleReplayWAL",
+			Msg:  fmt.Sprintf("bucket %s not found in organization %s", bucket, org),
+		}
+	}
+
+	p, err := platform.NewPermissionAtID(bucket, platform.WriteAction, platform.BucketsResourceType, org)
+	if err != nil {
+		return err
+	}
+	if !a.Allowed(*p) {
+		return &platform.Error{
+			Code: platform.EForbidden,
+			Op:   "http/handleReplayWAL",
+			Msg:  fmt.Sprintf("insufficient permissions to write to bucket %s", bucket),
+		}
+	}
+	return nil
+}
+
+// ReplayWAL writes the points of the WAL segment read from r again, only those of bucket if it is set.
+// The encrypted entries of the segment are decrypted with the keys of the server.
+func (s *WriteService) ReplayWAL(ctx context.Context, r io.Reader, bucket *platform.ID) (*WALReplayResult, error) {
+	u, err := newURL(s.Addr, walReplayPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err = compressWithGzip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "gzip")
+	SetToken(s.Token, req)
+
+	if bucket != nil {
+		params := req.URL.Query()
+		params.Set("bucketID", bucket.String())
+		req.URL.RawQuery = params.Encode()
+	}
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var res WALReplayResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
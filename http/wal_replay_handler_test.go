@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+type walSegmentReaderFunc func(io.Reader) ([]models.Point, storage.WALSegmentStats, error)
+
+func (f walSegmentReaderFunc) ReadWALSegment(r io.Reader) ([]models.Point, storage.WALSegmentStats, error) {
+	return f(r)
+}
+
+func TestWriteHandler_ReplayWAL(t *testing.T) {
+	var segment []models.Point
+	for _, bucket := range []platform.ID{2, 3} {
+		points, err := models.ParsePointsString("cpu,host=a value=1 1\ncpu,host=b value=2 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		exploded, err := tsdb.ExplodePoints(1, bucket, points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment = append(segment, exploded...)
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		org := platform.ID(1)
+		if id == 3 {
+			org = 4
+		}
+		return &platform.Bucket{ID: id, OrganizationID: org}, nil
+	}
+
+	replay := func(query string, permissions []platform.Permission) (*httptest.ResponseRecorder, *mock.PointsWriter) {
+		pw := &mock.PointsWriter{}
+		h := NewWriteHandler(&WriteBackend{
+			Logger:        zap.NewNop(),
+			PointsWriter:  pw,
+			BucketService: buckets,
+			WALSegmentReader: walSegmentReaderFunc(func(io.Reader) ([]models.Point, storage.WALSegmentStats, error) {
+				return segment, storage.WALSegmentStats{Points: len(segment), Deletes: 1}, nil
+			}),
+		})
+		r := httptest.NewRequest("POST", walReplayPath+query, strings.NewReader("segment"))
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: permissions}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w, pw
+	}
+
+	t.Run("bucket", func(t *testing.T) {
+		w, pw := replay("?bucketID=0000000000000002", platform.OperPermissions())
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var res WALReplayResult
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Points != 4 || res.Deletes != 1 || len(res.Buckets) != 1 {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		if b := res.Buckets[0]; b.OrgID != 1 || b.BucketID != 2 || b.Points != 2 {
+			t.Errorf("unexpected bucket %+v", b)
+		}
+		if len(pw.Points) != 2 {
+			t.Errorf("got %d points written, want 2", len(pw.Points))
+		}
+	})
+
+	t.Run("bucket of another organization", func(t *testing.T) {
+		// The organization of bucket 3 is not the one of its points.
+		w, pw := replay("", platform.OperPermissions())
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNotFound, w.Body.String())
+		}
+		if len(pw.Points) != 0 {
+			t.Errorf("got %d points written, want none", len(pw.Points))
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		p, err := platform.NewPermissionAtID(2, platform.ReadAction, platform.BucketsResourceType, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, pw := replay("?bucketID=0000000000000002", []platform.Permission{*p})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+		}
+		if len(pw.Points) != 0 {
+			t.Errorf("got %d points written, want none", len(pw.Points))
+		}
+	})
+}
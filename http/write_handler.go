@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
@@ -18,6 +21,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
 )
 
 // WriteBackend is all services and associated parameters required to construct
@@ -25,9 +29,14 @@ import (
 type WriteBackend struct {
 	Logger *zap.Logger
 
-	PointsWriter        storage.PointsWriter
-	BucketService       platform.BucketService
-	OrganizationService platform.OrganizationService
+	PointsWriter             storage.PointsWriter
+	BucketService            platform.BucketService
+	OrganizationService      platform.OrganizationService
+	MeasurementSchemaService platform.MeasurementSchemaService
+	WriteLimiter             *write.Limiter
+	WriteQueue               *write.Queue
+	WALSegmentReader         storage.WALSegmentReader
+	SeriesFinder             storage.SeriesFinder
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -35,9 +44,14 @@ func NewWriteBackend(b *APIBackend) *WriteBackend {
 	return &WriteBackend{
 		Logger: b.Logger.With(zap.String("handler", "write")),
 
-		PointsWriter:        b.PointsWriter,
-		BucketService:       b.BucketService,
-		OrganizationService: b.OrganizationService,
+		PointsWriter:             b.PointsWriter,
+		BucketService:            b.BucketService,
+		OrganizationService:      b.OrganizationService,
+		MeasurementSchemaService: b.MeasurementSchemaService,
+		WriteLimiter:             b.WriteLimiter,
+		WriteQueue:               b.WriteQueue,
+		WALSegmentReader:         b.WALSegmentReader,
+		SeriesFinder:             b.SeriesFinder,
 	}
 }
 
@@ -47,16 +61,34 @@ type WriteHandler struct {
 
 	Logger *zap.Logger
 
-	BucketService       platform.BucketService
-	OrganizationService platform.OrganizationService
+	BucketService            platform.BucketService
+	OrganizationService      platform.OrganizationService
+	MeasurementSchemaService platform.MeasurementSchemaService
+
+	// WriteLimiter, if set, limits the rate of the writes of every token and organization.
+	WriteLimiter *write.Limiter
 
 	PointsWriter storage.PointsWriter
+
+	// WriteQueue, if set, queues the points written, which are acknowledged before they are written
+	// to the PointsWriter.
+	WriteQueue *write.Queue
+
+	// WALSegmentReader, if set, reads the WAL segments replayed.
+	WALSegmentReader storage.WALSegmentReader
+
+	// SeriesFinder, if set, looks up the series of the writes validated, which are otherwise all new.
+	SeriesFinder storage.SeriesFinder
 }
 
 const (
 	writePath            = "/api/v2/write"
 	errInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
 	errInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
+
+	// lineProtocolV2ContentType negotiates the v2 dialect of the line protocol, see models.ParsePointsV2.
+	// Any other content type is parsed as strict v1 line protocol.
+	lineProtocolV2ContentType = "text/vnd.influxdb.line-protocol.v2"
 )
 
 // NewWriteHandler creates a new handler at /api/v2/write to receive line protocol.
@@ -65,12 +97,21 @@ func NewWriteHandler(b *WriteBackend) *WriteHandler {
 		Router: NewRouter(),
 		Logger: b.Logger,
 
-		PointsWriter:        b.PointsWriter,
-		BucketService:       b.BucketService,
-		OrganizationService: b.OrganizationService,
+		PointsWriter:             b.PointsWriter,
+		BucketService:            b.BucketService,
+		OrganizationService:      b.OrganizationService,
+		MeasurementSchemaService: b.MeasurementSchemaService,
+		WriteLimiter:             b.WriteLimiter,
+		WriteQueue:               b.WriteQueue,
+		WALSegmentReader:         b.WALSegmentReader,
+		SeriesFinder:             b.SeriesFinder,
 	}
 
 	h.HandlerFunc("POST", writePath, h.handleWrite)
+	h.HandlerFunc("POST", walReplayPath, h.handleReplayWAL)
+	h.HandlerFunc("POST", writeFlushPath, h.handleFlushWriteQueue)
+	h.HandlerFunc("POST", writeValidatePath, h.handleValidateWrite)
+	h.HandlerFunc("POST", opentsdbPutPath, h.handlePut)
 	return h
 }
 
@@ -111,30 +152,185 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 
 	logger := h.Logger.With(zap.String("org", req.Org), zap.String("bucket", req.Bucket))
 
+	org, bucket, err := h.findWriteBucket(ctx, a, req.Org, req.Bucket)
+	if err != nil {
+		logger.Info("Failed to find bucket to write to", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// TODO(jeff): we should be publishing with the org and bucket instead of
+	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
+	// be sure to remove this when it is there!
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		logger.Error("Error reading body", zap.Error(err))
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handleWrite",
+			Msg:  fmt.Sprintf("unable to read data: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	parse := models.ParsePointsWithLines
+	if req.LineProtocolV2 {
+		parse = models.ParsePointsV2WithLines
+	}
+	points, lines, err := parse(data, time.Now(), req.Precision)
+	var rejected []rejectedLine
+	if err != nil {
+		lineErrs, ok := err.(models.LineErrors)
+		if !ok {
+			logger.Error("Error parsing points", zap.Error(err))
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to parse points: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+		logger.Info("Rejected unparsable lines", zap.Int("rejected", len(lineErrs)))
+		for _, le := range lineErrs {
+			rejected = append(rejected, rejectedLine{Line: le.Line, Error: le.Error()})
+		}
+	}
+
+	if h.WriteLimiter != nil {
+		if err := h.WriteLimiter.Allow(org.ID, a.Identifier(), len(points), len(data)); err != nil {
+			lee, ok := err.(*write.LimitExceededError)
+			if !ok {
+				EncodeError(ctx, err, w)
+				return
+			}
+			logger.Debug("Throttled write", zap.Error(err))
+			w.Header().Set("Retry-After", strconv.Itoa(int(lee.RetryAfter/time.Second)))
+			EncodeError(ctx, &platform.Error{
+				Code: platform.ETooManyRequests,
+				Op:   "http/handleWrite",
+				Msg:  err.Error(),
+			}, w)
+			return
+		}
+	}
+
+	points, lines, bad, err := checkSchema(ctx, h.MeasurementSchemaService, bucket, points, lines)
+	if err != nil {
+		logger.Error("Error finding bucket schema", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+	rejected = append(rejected, bad...)
+
+	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
+	if err != nil {
+		// Find the points that could not be converted, and write the others.
+		var bad []rejectedLine
+		points, lines, bad = explodeErrors(org.ID, bucket.ID, points, lines)
+		rejected = append(rejected, bad...)
+		if exploded, err = tsdb.ExplodePoints(org.ID, bucket.ID, points); err != nil {
+			logger.Error("Error exploding points", zap.Error(err))
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInternal,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	accepted := len(points)
+	if h.WriteQueue != nil {
+		if len(exploded) > 0 {
+			if err := h.WriteQueue.Enqueue(exploded); err != nil {
+				if err == write.ErrQueueFull {
+					w.Header().Set("Retry-After", "1")
+					EncodeError(ctx, &platform.Error{
+						Code: platform.EUnavailable,
+						Op:   "http/handleWrite",
+						Msg:  err.Error(),
+					}, w)
+					return
+				}
+				logger.Error("Error queueing points", zap.Error(err))
+				EncodeError(ctx, &platform.Error{
+					Code: platform.EInternal,
+					Op:   "http/handleWrite",
+					Msg:  fmt.Sprintf("unable to queue points: %v", err),
+					Err:  err,
+				}, w)
+				return
+			}
+		}
+	} else if len(exploded) > 0 {
+		if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
+			pwe, ok := err.(tsdb.PartialWriteError)
+			if !ok {
+				logger.Error("Error writing points", zap.Error(err))
+				EncodeError(ctx, &platform.Error{
+					Code: platform.EInternal,
+					Op:   "http/handleWrite",
+					Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+					Err:  err,
+				}, w)
+				return
+			}
+			logger.Info("Dropped points of a partial write", zap.Error(err))
+			bad := droppedLines(org.ID, bucket.ID, points, lines, pwe)
+			accepted -= len(bad)
+			rejected = append(rejected, bad...)
+		}
+	}
+
+	if len(rejected) > 0 {
+		sort.SliceStable(rejected, func(i, j int) bool { return rejected[i].Line < rejected[j].Line })
+		w.Header().Set(PlatformErrorCodeHeader, platform.EInvalid)
+		if err := encodeResponse(ctx, w, http.StatusBadRequest, &partialWriteResponse{
+			Code:     platform.EInvalid,
+			Message:  fmt.Sprintf("partial write: %d lines rejected, %d points accepted", len(rejected), accepted),
+			Accepted: accepted,
+			Rejected: rejected,
+		}); err != nil {
+			logEncodingError(logger, r, err)
+		}
+		return
+	}
+
+	if h.WriteQueue != nil {
+		// The points are written once the queue drains them.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findWriteBucket returns the organization and the bucket identified, or named, by orgName and bucketName,
+// if a is allowed to write to the bucket.
+func (h *WriteHandler) findWriteBucket(ctx context.Context, a platform.Authorizer, orgName, bucketName string) (*platform.Organization, *platform.Bucket, error) {
 	var org *platform.Organization
-	if id, err := platform.IDFromString(req.Org); err == nil {
+	if id, err := platform.IDFromString(orgName); err == nil {
 		// Decoded ID successfully. Make sure it's a real org.
 		o, err := h.OrganizationService.FindOrganizationByID(ctx, *id)
 		if err == nil {
 			org = o
 		} else if platform.ErrorCode(err) != platform.ENotFound {
-			EncodeError(ctx, err, w)
-			return
+			return nil, nil, err
 		}
 	}
 	if org == nil {
-		o, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &req.Org})
+		o, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &orgName})
 		if err != nil {
-			logger.Info("Failed to find organization", zap.Error(err))
-			EncodeError(ctx, err, w)
-			return
+			return nil, nil, err
 		}
 
 		org = o
 	}
 
 	var bucket *platform.Bucket
-	if id, err := platform.IDFromString(req.Bucket); err == nil {
+	if id, err := platform.IDFromString(bucketName); err == nil {
 		// Decoded ID successfully. Make sure it's a real bucket.
 		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
 			OrganizationID: &org.ID,
@@ -143,22 +339,20 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		if err == nil {
 			bucket = b
 		} else if platform.ErrorCode(err) != platform.ENotFound {
-			EncodeError(ctx, err, w)
-			return
+			return nil, nil, err
 		}
 	}
 
 	if bucket == nil {
 		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
 			OrganizationID: &org.ID,
-			Name:           &req.Bucket,
+			Name:           &bucketName,
 		})
 		if err != nil {
-			EncodeError(ctx, &platform.Error{
+			return nil, nil, &platform.Error{
 				Op:  "http/handleWrite",
 				Err: err,
-			}, w)
-			return
+			}
 		}
 
 		bucket = b
@@ -166,75 +360,112 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 
 	p, err := platform.NewPermissionAtID(bucket.ID, platform.WriteAction, platform.BucketsResourceType, org.ID)
 	if err != nil {
-		EncodeError(ctx, &platform.Error{
+		return nil, nil, &platform.Error{
 			Code: platform.EInternal,
 			Op:   "http/handleWrite",
 			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
 			Err:  err,
-		}, w)
-		return
+		}
 	}
 
 	if !a.Allowed(*p) {
-		EncodeError(ctx, &platform.Error{
+		return nil, nil, &platform.Error{
 			Code: platform.EForbidden,
 			Op:   "http/handleWrite",
 			Msg:  "insufficient permissions for write",
-		}, w)
-		return
+		}
 	}
 
-	// TODO(jeff): we should be publishing with the org and bucket instead of
-	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
-	// be sure to remove this when it is there!
-	data, err := ioutil.ReadAll(in)
-	if err != nil {
-		logger.Error("Error reading body", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to read data: %v", err),
-			Err:  err,
-		}, w)
-		return
+	return org, bucket, nil
+}
+
+// partialWriteResponse is the response to a write of which some lines were rejected.
+// It is a superset of the encoding of platform.Error.
+type partialWriteResponse struct {
+	Code     string         `json:"code"`
+	Message  string         `json:"message"`
+	Accepted int            `json:"accepted"`
+	Rejected []rejectedLine `json:"rejected"`
+}
+
+// rejectedLine is a line of a write that was rejected, and why.
+// Violation is set for the lines of explicit-schema buckets that do not conform to the schema.
+type rejectedLine struct {
+	Line      int                      `json:"line"`
+	Error     string                   `json:"error"`
+	Violation platform.SchemaViolation `json:"violation,omitempty"`
+}
+
+// checkSchema returns the points that conform to the schema of bucket, with their lines,
+// and the lines of the points that do not.
+func checkSchema(ctx context.Context, schemas platform.MeasurementSchemaService, bucket *platform.Bucket, points []models.Point, lines []int) ([]models.Point, []int, []rejectedLine, error) {
+	okPoints, bad, err := write.CheckSchema(ctx, schemas, bucket, points)
+	if err != nil || len(bad) == 0 {
+		return okPoints, lines, nil, err
 	}
 
-	points, err := models.ParsePointsWithPrecision(data, time.Now(), req.Precision)
-	if err != nil {
-		logger.Error("Error parsing points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInvalid,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to parse points: %v", err),
-			Err:  err,
-		}, w)
-		return
+	okLines := make([]int, 0, len(okPoints))
+	rejected := make([]rejectedLine, 0, len(bad))
+	for i, line := range lines {
+		if len(bad) > 0 && bad[0].Index == i {
+			rl := rejectedLine{Line: line, Error: bad[0].Err.Error()}
+			if sve, ok := bad[0].Err.(*platform.SchemaViolationError); ok {
+				rl.Violation = sve.Violation
+			}
+			rejected = append(rejected, rl)
+			bad = bad[1:]
+			continue
+		}
+		okLines = append(okLines, line)
 	}
+	return okPoints, okLines, rejected, nil
+}
 
-	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
-	if err != nil {
-		logger.Error("Error exploding points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
-			Err:  err,
-		}, w)
-		return
+// explodeErrors returns the points that can be converted to internal structures, with
+// their lines, and the lines of the points that cannot.
+func explodeErrors(org, bucket platform.ID, points []models.Point, lines []int) ([]models.Point, []int, []rejectedLine) {
+	var (
+		okPoints []models.Point
+		okLines  []int
+		rejected []rejectedLine
+	)
+	for i, pt := range points {
+		if _, err := tsdb.ExplodePoints(org, bucket, []models.Point{pt}); err != nil {
+			rejected = append(rejected, rejectedLine{Line: lines[i], Error: err.Error()})
+			continue
+		}
+		okPoints = append(okPoints, pt)
+		okLines = append(okLines, lines[i])
 	}
+	return okPoints, okLines, rejected
+}
 
-	if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
-		logger.Error("Error writing points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to write points to database: %v", err),
-			Err:  err,
-		}, w)
-		return
+// droppedLines returns the lines of the points of which the partial write err dropped values.
+// A series dropped by the engine rejects every line that writes it, even if only one of them
+// is at fault, e.g. the first line of a batch whose lines write a field with conflicting types.
+func droppedLines(org, bucket platform.ID, points []models.Point, lines []int, err tsdb.PartialWriteError) []rejectedLine {
+	dropped := make(map[string]bool, len(err.DroppedKeys))
+	for _, key := range err.DroppedKeys {
+		dropped[string(key)] = true
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	var rejected []rejectedLine
+	for i, pt := range points {
+		exploded, _ := tsdb.ExplodePoints(org, bucket, []models.Point{pt})
+		for _, ept := range exploded {
+			key := string(ept.Key())
+			if !dropped[key] {
+				continue
+			}
+			reason := err.Reasons[key]
+			if reason == "" {
+				reason = err.Reason
+			}
+			rejected = append(rejected, rejectedLine{Line: lines[i], Error: reason})
+			break
+		}
+	}
+	return rejected
 }
 
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
@@ -252,17 +483,22 @@ func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest
 		}
 	}
 
+	// A malformed content type falls back to v1.
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
 	return &postWriteRequest{
-		Bucket:    qp.Get("bucket"),
-		Org:       qp.Get("org"),
-		Precision: p,
+		Bucket:         qp.Get("bucket"),
+		Org:            qp.Get("org"),
+		Precision:      p,
+		LineProtocolV2: mt == lineProtocolV2ContentType,
 	}, nil
 }
 
 type postWriteRequest struct {
-	Org       string
-	Bucket    string
-	Precision string
+	Org            string
+	Bucket         string
+	Precision      string
+	LineProtocolV2 bool
 }
 
 // WriteService sends data over HTTP to influxdb via line protocol.
@@ -271,6 +507,9 @@ type WriteService struct {
 	Token              string
 	Precision          string
 	InsecureSkipVerify bool
+
+	// LineProtocolV2 sends the data as the v2 dialect of the line protocol, see models.ParsePointsV2.
+	LineProtocolV2 bool
 }
 
 var _ platform.WriteService = (*WriteService)(nil)
@@ -304,7 +543,11 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 		return err
 	}
 
-	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.LineProtocolV2 {
+		req.Header.Set("Content-Type", lineProtocolV2ContentType+"; charset=utf-8")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
 	req.Header.Set("Content-Encoding", "gzip")
 	SetToken(s.Token, req)
 
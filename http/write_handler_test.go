@@ -1,16 +1,27 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap"
 )
 
 func TestWriteService_Write(t *testing.T) {
@@ -69,3 +80,363 @@ func TestWriteService_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteHandler_LineProtocolV2(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		fields      map[string]interface{}
+		time        time.Time
+	}{
+		{
+			name:        "v1 is strict",
+			contentType: "text/plain; charset=utf-8",
+			body:        `cpu online 1000`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "v1 keeps escaped newlines literal",
+			contentType: "text/plain; charset=utf-8",
+			body:        `cpu str="a\nb" 1000`,
+			status:      http.StatusNoContent,
+			fields:      map[string]interface{}{"str": `a\nb`},
+			time:        time.Unix(0, 1000),
+		},
+		{
+			name:        "v2 boolean shorthand",
+			contentType: "text/vnd.influxdb.line-protocol.v2",
+			body:        `cpu online 1000`,
+			status:      http.StatusNoContent,
+			fields:      map[string]interface{}{"online": true},
+			time:        time.Unix(0, 1000),
+		},
+		{
+			name:        "v2 escaped newline and RFC3339 timestamp",
+			contentType: "text/vnd.influxdb.line-protocol.v2; charset=utf-8",
+			body:        `cpu str="a\nb" 2019-03-01T12:00:00.000000001Z`,
+			status:      http.StatusNoContent,
+			fields:      map[string]interface{}{"str": "a\nb"},
+			time:        time.Date(2019, 3, 1, 12, 0, 0, 1, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+				return &platform.Organization{ID: id}, nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+			}
+			pw := &mock.PointsWriter{}
+			h := NewWriteHandler(&WriteBackend{
+				Logger:              zap.NewNop(),
+				PointsWriter:        pw,
+				BucketService:       buckets,
+				OrganizationService: orgs,
+			})
+
+			r := httptest.NewRequest("POST", "/api/v2/write?org=0000000000000001&bucket=0000000000000002", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: platform.OperPermissions()}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.fields == nil {
+				return
+			}
+			if len(pw.Points) != len(tt.fields) {
+				t.Fatalf("got %d points, want %d", len(pw.Points), len(tt.fields))
+			}
+			for _, pt := range pw.Points {
+				fields, err := pt.Fields()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range fields {
+					if want, ok := tt.fields[k]; !ok || v != want {
+						t.Errorf("got field %s=%v, want %v", k, v, want)
+					}
+				}
+				if !pt.Time().Equal(tt.time) {
+					t.Errorf("got time %v, want %v", pt.Time(), tt.time)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHandler_PartialWrite(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+
+	// The engine drops the series of the last line.
+	points, err := models.ParsePointsString(`mem,host=a value="x" 3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exploded, err := tsdb.ExplodePoints(1, 2, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := exploded[0].Key()
+	pw := &mock.PointsWriter{}
+	pw.ForceError(tsdb.PartialWriteError{
+		Reason:      "conflicting field type",
+		Dropped:     1,
+		DroppedKeys: [][]byte{key},
+		Reasons:     map[string]string{string(key): "conflicting field type: value has field type string but expected float"},
+	})
+	h := NewWriteHandler(&WriteBackend{
+		Logger:              zap.NewNop(),
+		PointsWriter:        pw,
+		BucketService:       buckets,
+		OrganizationService: orgs,
+	})
+
+	body := "cpu value=1 1\ncpu value=\nmem value=2 2\nmem,host=a value=\"x\" 3\n"
+	r := httptest.NewRequest("POST", "/api/v2/write?org=0000000000000001&bucket=0000000000000002", strings.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: platform.OperPermissions()}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+	if len(pw.Points) != 3 {
+		t.Errorf("got %d points written, want 3", len(pw.Points))
+	}
+
+	var res partialWriteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	exp := partialWriteResponse{
+		Code:     platform.EInvalid,
+		Message:  "partial write: 2 lines rejected, 2 points accepted",
+		Accepted: 2,
+		Rejected: []rejectedLine{
+			{Line: 2, Error: "unable to parse 'cpu value=': missing field value"},
+			{Line: 4, Error: "conflicting field type: value has field type string but expected float"},
+		},
+	}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("unexpected response %+v, want %+v", res, exp)
+	}
+
+	// The client reports the message of the response.
+	err = CheckError(&http.Response{StatusCode: w.Code, Body: ioutil.NopCloser(bytes.NewReader(w.Body.Bytes()))})
+	if platform.ErrorCode(err) != platform.EInvalid || platform.ErrorMessage(err) != exp.Message {
+		t.Errorf("unexpected client error %v", err)
+	}
+}
+
+func TestWriteHandler_ExplicitSchema(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID, SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		if *filter.BucketID != 2 {
+			t.Errorf("unexpected bucket %s", filter.BucketID)
+		}
+		return []*platform.MeasurementSchema{
+			{
+				ID:       1,
+				OrgID:    1,
+				BucketID: 2,
+				Name:     "cpu",
+				Tags:     []string{"host"},
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+					{Name: "cores", Type: platform.SchemaFieldTypeInteger},
+				},
+			},
+		}, nil
+	}
+	pw := &mock.PointsWriter{}
+	h := NewWriteHandler(&WriteBackend{
+		Logger:                   zap.NewNop(),
+		PointsWriter:             pw,
+		BucketService:            buckets,
+		OrganizationService:      orgs,
+		MeasurementSchemaService: schemas,
+	})
+
+	body := "cpu,host=a usage=0.5,cores=4i 1\n" +
+		"mem,host=a used=1i 2\n" +
+		"cpu,host=a,dc=west usage=0.5 3\n" +
+		"cpu,host=a idle=0.5 4\n" +
+		"cpu,host=a cores=4 5\n"
+	r := httptest.NewRequest("POST", "/api/v2/write?org=0000000000000001&bucket=0000000000000002", strings.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: platform.OperPermissions()}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+	// The conforming point has two fields.
+	if len(pw.Points) != 2 {
+		t.Errorf("got %d points written, want 2", len(pw.Points))
+	}
+
+	var res partialWriteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	exp := partialWriteResponse{
+		Code:     platform.EInvalid,
+		Message:  "partial write: 4 lines rejected, 1 points accepted",
+		Accepted: 1,
+		Rejected: []rejectedLine{
+			{Line: 2, Error: `measurement "mem" is not in the bucket schema`, Violation: platform.SchemaViolationUnknownMeasurement},
+			{Line: 3, Error: `tag "dc" is not in the schema of measurement "cpu"`, Violation: platform.SchemaViolationUnknownTag},
+			{Line: 4, Error: `field "idle" is not in the schema of measurement "cpu"`, Violation: platform.SchemaViolationUnknownField},
+			{Line: 5, Error: `field "cores" of measurement "cpu" has type float but the schema declares integer`, Violation: platform.SchemaViolationFieldType},
+		},
+	}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("unexpected response %+v, want %+v", res, exp)
+	}
+}
+
+func TestWriteHandler_RateLimit(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	h := NewWriteHandler(&WriteBackend{
+		Logger:              zap.NewNop(),
+		PointsWriter:        pw,
+		BucketService:       buckets,
+		OrganizationService: orgs,
+		WriteLimiter:        write.NewLimiter(write.Limits{TokenPointsPerSecond: 2}),
+	})
+
+	post := func(token platform.ID) *httptest.ResponseRecorder {
+		body := "cpu,host=a usage=0.5 1\ncpu,host=b usage=0.5 1\ncpu,host=c usage=0.5 1\n"
+		r := httptest.NewRequest("POST", "/api/v2/write?org=0000000000000001&bucket=0000000000000002", strings.NewReader(body))
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{ID: token, Status: platform.Active, Permissions: platform.OperPermissions()}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post(3); w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	w := post(3)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusTooManyRequests, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("got Retry-After %q, want 1", got)
+	}
+	if got := w.Header().Get(PlatformErrorCodeHeader); got != platform.ETooManyRequests {
+		t.Errorf("got error code %q, want %q", got, platform.ETooManyRequests)
+	}
+	if len(pw.Points) != 3 {
+		t.Errorf("got %d points written, want only the 3 of the first write", len(pw.Points))
+	}
+
+	// The limit is per token.
+	if w := post(4); w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+}
+
+func TestWriteHandler_Queue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	q := write.NewQueue(dir, pw)
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	h := NewWriteHandler(&WriteBackend{
+		Logger:              zap.NewNop(),
+		PointsWriter:        pw,
+		BucketService:       buckets,
+		OrganizationService: orgs,
+		WriteQueue:          q,
+	})
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: platform.OperPermissions()}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	body := "cpu,host=a usage=0.5 1\ncpu,host=b usage=0.5 1\n"
+	w := serve(httptest.NewRequest("POST", "/api/v2/write?org=0000000000000001&bucket=0000000000000002", strings.NewReader(body)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	if len(pw.Points) != 0 {
+		t.Fatalf("got %d points written before the queue is drained, want none", len(pw.Points))
+	}
+
+	// The flush times out while the queue is not drained.
+	w = serve(httptest.NewRequest("POST", "/api/v2/write/flush?timeout=10ms", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	w = serve(httptest.NewRequest("POST", "/api/v2/write/flush?timeout=5s", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if len(pw.Points) != 2 {
+		t.Errorf("got %d points written after the flush, want 2", len(pw.Points))
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+// writeFlushPath is the path waiting for the write queue to drain.
+const writeFlushPath = "/api/v2/write/flush"
+
+// handleFlushWriteQueue is the HTTP handler for the POST /api/v2/write/flush route, responding once the
+// points acknowledged before the request are written from the write queue to the storage engine,
+// or with an error if they are not written within the timeout parameter.
+func (h *WriteHandler) handleFlushWriteQueue(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	if h.WriteQueue == nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Op:   "http/handleFlushWriteQueue",
+			Msg:  "writes are not queued by this server",
+		}, w)
+		return
+	}
+
+	if _, err := pcontext.GetAuthorizer(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if s := r.URL.Query().Get("timeout"); s != "" {
+		timeout, err := ParseDuration(s)
+		if err != nil || timeout <= 0 {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleFlushWriteQueue",
+				Msg:  fmt.Sprintf("invalid timeout %q", s),
+			}, w)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := h.WriteQueue.Flush(ctx); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EUnavailable,
+			Op:   "http/handleFlushWriteQueue",
+			Msg:  "the write queue was not drained in time",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FlushQueue waits until the points acknowledged by the server before the call are written from its
+// write queue to the storage engine, for at most timeout if it is positive.
+func (s *WriteService) FlushQueue(ctx context.Context, timeout time.Duration) error {
+	u, err := newURL(s.Addr, writeFlushPath)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	if timeout > 0 {
+		params := req.URL.Query()
+		params.Set("timeout", FormatDuration(timeout))
+		req.URL.RawQuery = params.Encode()
+	}
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
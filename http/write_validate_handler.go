@@ -0,0 +1,230 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// writeValidatePath is the path validating line protocol against a bucket without writing it.
+const writeValidatePath = "/api/v2/write/validate"
+
+// writeValidation is the response to the validation of a write.
+type writeValidation struct {
+	// Accepted is the number of points that the write would write.
+	Accepted int            `json:"accepted"`
+	Rejected []rejectedLine `json:"rejected"`
+
+	// Fields are the fields of the points accepted, sorted by measurement and name,
+	// with the types inferred from their values.
+	Fields []validatedField `json:"fields"`
+
+	// Series is the number of series of the points accepted, of which NewSeries are not in the bucket yet.
+	Series    int `json:"series"`
+	NewSeries int `json:"newSeries"`
+}
+
+// validatedField is a field of a validated write.
+type validatedField struct {
+	Measurement string                   `json:"measurement"`
+	Name        string                   `json:"name"`
+	Type        platform.SchemaFieldType `json:"type"`
+}
+
+// handleValidateWrite is the HTTP handler for the POST /api/v2/write/validate route, parsing the line protocol
+// of the request body as a write to the bucket would, and reporting the lines the write would reject,
+// the types of the fields it would write, and how many series it would create. Nothing is written,
+// and the write rate limits are neither enforced nor drawn from.
+//
+// Besides the lines rejected by a write, the lines writing a field with another type than the one of the
+// series already in the bucket, or than the one of the same field on a previous line, are rejected.
+func (h *WriteHandler) handleValidateWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	in := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		var err error
+		in, err = gzip.NewReader(r.Body)
+		if err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleValidateWrite",
+				Msg:  errInvalidGzipHeader,
+				Err:  err,
+			}, w)
+			return
+		}
+		defer in.Close()
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeWriteRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	logger := h.Logger.With(zap.String("org", req.Org), zap.String("bucket", req.Bucket))
+
+	org, bucket, err := h.findWriteBucket(ctx, a, req.Org, req.Bucket)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handleValidateWrite",
+			Msg:  fmt.Sprintf("unable to read data: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	parse := models.ParsePointsWithLines
+	if req.LineProtocolV2 {
+		parse = models.ParsePointsV2WithLines
+	}
+	points, lines, err := parse(data, time.Now(), req.Precision)
+	res := &writeValidation{Rejected: []rejectedLine{}, Fields: []validatedField{}}
+	if err != nil {
+		lineErrs, ok := err.(models.LineErrors)
+		if !ok {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handleValidateWrite",
+				Msg:  fmt.Sprintf("unable to parse points: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+		for _, le := range lineErrs {
+			res.Rejected = append(res.Rejected, rejectedLine{Line: le.Line, Error: le.Error()})
+		}
+	}
+
+	points, lines, bad, err := checkSchema(ctx, h.MeasurementSchemaService, bucket, points, lines)
+	if err != nil {
+		logger.Error("Error finding bucket schema", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+	res.Rejected = append(res.Rejected, bad...)
+
+	points, lines, bad = explodeErrors(org.ID, bucket.ID, points, lines)
+	res.Rejected = append(res.Rejected, bad...)
+
+	h.validateSeries(org.ID, bucket.ID, points, lines, res)
+
+	sort.SliceStable(res.Rejected, func(i, j int) bool { return res.Rejected[i].Line < res.Rejected[j].Line })
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(logger, r, err)
+	}
+}
+
+// validateSeries adds to res the points that the write would accept, their fields and their series,
+// and rejects the lines writing a field with another type than the one already written.
+func (h *WriteHandler) validateSeries(org, bucket platform.ID, points []models.Point, lines []int, res *writeValidation) {
+	type fieldKey struct{ measurement, name string }
+	fields := make(map[fieldKey]models.FieldType)
+	series := make(map[string]struct{})
+
+	for i, pt := range points {
+		exploded, _ := tsdb.ExplodePoints(org, bucket, []models.Point{pt})
+
+		var conflict string
+		for _, ept := range exploded {
+			itr := ept.FieldIterator()
+			itr.Next()
+			fk := fieldKey{measurement: string(pt.Name()), name: string(itr.FieldKey())}
+			typ := itr.Type()
+
+			if want, ok := fields[fk]; ok && want != typ {
+				conflict = fmt.Sprintf("field type conflict: field %q of measurement %q has type %s on a previous line, got %s",
+					fk.name, fk.measurement, schemaFieldType(want), schemaFieldType(typ))
+				break
+			}
+			if h.SeriesFinder == nil {
+				continue
+			}
+			if want, ok := h.SeriesFinder.FindSeriesType(ept.Name(), ept.Tags()); ok && want != models.Empty && want != typ {
+				conflict = fmt.Sprintf("field type conflict: field %q of measurement %q has type %s in the bucket, got %s",
+					fk.name, fk.measurement, schemaFieldType(want), schemaFieldType(typ))
+				break
+			}
+		}
+		if conflict != "" {
+			res.Rejected = append(res.Rejected, rejectedLine{Line: lines[i], Error: conflict})
+			continue
+		}
+
+		res.Accepted++
+		for _, ept := range exploded {
+			itr := ept.FieldIterator()
+			itr.Next()
+			fk := fieldKey{measurement: string(pt.Name()), name: string(itr.FieldKey())}
+			if _, ok := fields[fk]; !ok {
+				fields[fk] = itr.Type()
+				res.Fields = append(res.Fields, validatedField{Measurement: fk.measurement, Name: fk.name, Type: schemaFieldType(itr.Type())})
+			}
+
+			key := string(ept.Key())
+			if _, ok := series[key]; ok {
+				continue
+			}
+			series[key] = struct{}{}
+			res.Series++
+			if h.SeriesFinder == nil {
+				res.NewSeries++
+			} else if _, ok := h.SeriesFinder.FindSeriesType(ept.Name(), ept.Tags()); !ok {
+				res.NewSeries++
+			}
+		}
+	}
+
+	sort.Slice(res.Fields, func(i, j int) bool {
+		if res.Fields[i].Measurement != res.Fields[j].Measurement {
+			return res.Fields[i].Measurement < res.Fields[j].Measurement
+		}
+		return res.Fields[i].Name < res.Fields[j].Name
+	})
+}
+
+// schemaFieldType returns the name of the field type t, as declared by measurement schemas.
+func schemaFieldType(t models.FieldType) platform.SchemaFieldType {
+	switch t {
+	case models.Float:
+		return platform.SchemaFieldTypeFloat
+	case models.Integer:
+		return platform.SchemaFieldTypeInteger
+	case models.Unsigned:
+		return platform.SchemaFieldTypeUnsigned
+	case models.String:
+		return platform.SchemaFieldTypeString
+	case models.Boolean:
+		return platform.SchemaFieldTypeBoolean
+	}
+	return platform.SchemaFieldType(t.String())
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+// seriesFinder finds the series of the exploded points with the field types of types, keyed by
+// the host tag and the field key.
+type seriesFinder map[string]models.FieldType
+
+func (f seriesFinder) FindSeriesType(name []byte, tags models.Tags) (models.FieldType, bool) {
+	typ, ok := f[tags.GetString("host")+","+tags.GetString(models.FieldKeyTagKey)]
+	return typ, ok
+}
+
+func TestWriteHandler_Validate(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	h := NewWriteHandler(&WriteBackend{
+		Logger:              zap.NewNop(),
+		PointsWriter:        pw,
+		BucketService:       buckets,
+		OrganizationService: orgs,
+		SeriesFinder:        seriesFinder{"a,usage": models.Float, "a,state": models.String},
+	})
+
+	body := "cpu,host=a usage=0.5,cores=4i 1\n" +
+		"cpu,host=b usage=0.7 2\n" +
+		"cpu,host=a usage=\n" +
+		"cpu,host=c cores=4 3\n" +
+		"cpu,host=a state=1i 4\n" +
+		"cpu,host=b usage=0.9 5\n" +
+		"mem,host=a up=true 6\n"
+	r := httptest.NewRequest("POST", "/api/v2/write/validate?org=0000000000000001&bucket=0000000000000002", strings.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active, Permissions: platform.OperPermissions()}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if len(pw.Points) != 0 {
+		t.Errorf("got %d points written, want none", len(pw.Points))
+	}
+
+	var res writeValidation
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	exp := writeValidation{
+		Accepted: 4,
+		Rejected: []rejectedLine{
+			{Line: 3, Error: "unable to parse 'cpu,host=a usage=': missing field value"},
+			{Line: 4, Error: `field type conflict: field "cores" of measurement "cpu" has type integer on a previous line, got float`},
+			{Line: 5, Error: `field type conflict: field "state" of measurement "cpu" has type string in the bucket, got integer`},
+		},
+		Fields: []validatedField{
+			{Measurement: "cpu", Name: "cores", Type: platform.SchemaFieldTypeInteger},
+			{Measurement: "cpu", Name: "usage", Type: platform.SchemaFieldTypeFloat},
+			{Measurement: "mem", Name: "up", Type: platform.SchemaFieldTypeBoolean},
+		},
+		// Only the usage of host a is in the bucket.
+		Series:    4,
+		NewSeries: 3,
+	}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("unexpected response %+v, want %+v", res, exp)
+	}
+}
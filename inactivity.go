@@ -0,0 +1,136 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrActivityNotFound is the error msg for a resource without any recorded activity.
+const ErrActivityNotFound = "activity not found"
+
+// ops for activity error
+const (
+	OpRecordActivity   = "RecordActivity"
+	OpFindLastActivity = "FindLastActivity"
+)
+
+// ActivityResolution is how much later than its last recorded activity a resource must be used
+// for the use to be recorded, so that busy resources are not written on every use.
+const ActivityResolution = time.Minute
+
+// ActivityService records when resources were last used, e.g. when a token last authenticated
+// a request or a dashboard was last viewed.
+type ActivityService interface {
+	// RecordActivity records that the resource of type rt with id was used at t.
+	// It is a no-op unless t is later than the last recorded activity by ActivityResolution.
+	RecordActivity(ctx context.Context, rt ResourceType, id ID, t time.Time) error
+
+	// FindLastActivity returns the time the resource of type rt with id was last used.
+	// It returns an ENotFound error if no activity of the resource was ever recorded.
+	FindLastActivity(ctx context.Context, rt ResourceType, id ID) (time.Time, error)
+}
+
+// InactivityAction is what is done about the inactive resources of an organization.
+type InactivityAction string
+
+// Inactivity actions.
+const (
+	// InactivityNotify notifies the owners of the inactive resources.
+	InactivityNotify InactivityAction = "notify"
+
+	// InactivityDeactivate deactivates the inactive tokens and tasks, and notifies the owners
+	// of the inactive resources if the policy has a NotifyURL.
+	// Dashboards have no inactive state, so they are only ever notified about.
+	InactivityDeactivate InactivityAction = "deactivate"
+)
+
+// Valid returns an error if a is not a known action.
+func (a InactivityAction) Valid() error {
+	switch a {
+	case InactivityNotify, InactivityDeactivate:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("unknown inactivity action %q", a),
+	}
+}
+
+// InactivityPolicy tells which resources of an organization are inactive, and what is done about them.
+// A zero period or run count disables the detection of the corresponding resources.
+type InactivityPolicy struct {
+	// TokenUnusedPeriod is how long a token is left unused before it is inactive.
+	// The tokens of tasks are used without authenticating requests, so they are never inactive.
+	TokenUnusedPeriod time.Duration `json:"tokenUnusedPeriod,omitempty"`
+
+	// DashboardUnviewedPeriod is how long a dashboard is left neither viewed nor updated before it is inactive.
+	DashboardUnviewedPeriod time.Duration `json:"dashboardUnviewedPeriod,omitempty"`
+
+	// TaskFailedRuns is the number of latest runs of a task that must have all failed for the task to be inactive.
+	TaskFailedRuns int `json:"taskFailedRuns,omitempty"`
+
+	Action InactivityAction `json:"action"`
+
+	// NotifyURL is the http or https URL the InactivityNotification of the organization is POSTed to.
+	NotifyURL string `json:"notifyURL,omitempty"`
+}
+
+// Validate returns an error if the policy is invalid.
+func (p *InactivityPolicy) Validate() error {
+	if p.TokenUnusedPeriod < 0 || p.DashboardUnviewedPeriod < 0 || p.TaskFailedRuns < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "inactivity policy periods and run counts must not be negative",
+		}
+	}
+
+	if err := p.Action.Valid(); err != nil {
+		return err
+	}
+
+	if p.NotifyURL == "" {
+		if p.Action == InactivityNotify {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "inactivity policy notifying owners requires a notify URL",
+			}
+		}
+		return nil
+	}
+
+	u, err := url.Parse(p.NotifyURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "inactivity policy notify URL must be an absolute http or https URL",
+		}
+	}
+	return nil
+}
+
+// InactiveResource is a resource found inactive by the inactivity policy of its organization.
+type InactiveResource struct {
+	Type  ResourceType `json:"type"`
+	ID    ID           `json:"id"`
+	OrgID ID           `json:"orgID"`
+	Name  string       `json:"name"`
+
+	// OwnerIDs are the IDs of the users owning the resource, which are notified about it.
+	OwnerIDs []ID `json:"ownerIDs"`
+
+	// Reason tells why the resource is inactive.
+	Reason string `json:"reason"`
+
+	// Deactivated is true if the resource was deactivated per the policy.
+	Deactivated bool `json:"deactivated"`
+}
+
+// InactivityNotification is the body of the notification of the inactive resources of an organization.
+type InactivityNotification struct {
+	OrgID     ID                 `json:"orgID"`
+	OrgName   string             `json:"org"`
+	Time      time.Time          `json:"time"`
+	Resources []InactiveResource `json:"resources"`
+}
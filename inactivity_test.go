@@ -0,0 +1,69 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestInactivityPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  influxdb.InactivityPolicy
+		wantErr bool
+	}{
+		{
+			name: "notify",
+			policy: influxdb.InactivityPolicy{
+				TokenUnusedPeriod: 90 * 24 * time.Hour,
+				Action:            influxdb.InactivityNotify,
+				NotifyURL:         "https://example.com/hooks/inactive",
+			},
+		},
+		{
+			name: "deactivate without notifying",
+			policy: influxdb.InactivityPolicy{
+				TaskFailedRuns: 10,
+				Action:         influxdb.InactivityDeactivate,
+			},
+		},
+		{
+			name: "notify without URL",
+			policy: influxdb.InactivityPolicy{
+				Action: influxdb.InactivityNotify,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown action",
+			policy: influxdb.InactivityPolicy{
+				Action: "delete",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative period",
+			policy: influxdb.InactivityPolicy{
+				DashboardUnviewedPeriod: -time.Hour,
+				Action:                  influxdb.InactivityDeactivate,
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative notify URL",
+			policy: influxdb.InactivityPolicy{
+				Action:    influxdb.InactivityNotify,
+				NotifyURL: "/hooks/inactive",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("InactivityPolicy.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
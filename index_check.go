@@ -0,0 +1,83 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of an IndexCheck.
+const (
+	IndexCheckRunning = "running"
+	IndexCheckSuccess = "success"
+	IndexCheckFailed  = "failed"
+)
+
+// Kinds of an IndexDiscrepancy.
+const (
+	// IndexDiscrepancySeriesNotInSeriesFile is a series of the index missing or deleted from the series file.
+	IndexDiscrepancySeriesNotInSeriesFile = "seriesNotInSeriesFile"
+	// IndexDiscrepancySeriesInWrongPartition is a series in a partition of the index its key does not belong to.
+	IndexDiscrepancySeriesInWrongPartition = "seriesInWrongPartition"
+	// IndexDiscrepancySeriesMisindexed is a series listed by a measurement or a tag value it does not have.
+	IndexDiscrepancySeriesMisindexed = "seriesMisindexed"
+	// IndexDiscrepancySeriesNotIndexed is a series missing from its measurement or one of its tag values,
+	// which queries on them do not find.
+	IndexDiscrepancySeriesNotIndexed = "seriesNotIndexed"
+)
+
+// IndexCheckService checks the index of the storage engine against its series file and its data,
+// while the engine keeps serving reads and writes.
+type IndexCheckService interface {
+	// CheckIndex starts checking the index in the background, and returns the check started.
+	// Only one check runs at a time.
+	CheckIndex(ctx context.Context, opts IndexCheckOptions) (*IndexCheck, error)
+
+	// FindIndexCheck returns the running check, or the last check that finished.
+	FindIndexCheck(ctx context.Context) (*IndexCheck, error)
+}
+
+// IndexCheckOptions are the options of a check of the index.
+type IndexCheckOptions struct {
+	// Repair rebuilds the partitions of the index with discrepancies, and indexes the series with data
+	// that are missing from the index.
+	Repair bool `json:"repair"`
+}
+
+// IndexCheck is a check of the index running in the background.
+//
+// Each partition of the index is verified in turn, then the series of the data are looked up in the index:
+// MissingSeriesCount is the number of series with data that the index is missing.
+// When the check repairs the index, ReindexedSeriesCount is the number of those series indexed again.
+type IndexCheck struct {
+	Status               string                `json:"status"`
+	Error                string                `json:"error,omitempty"`
+	Repair               bool                  `json:"repair"`
+	Partitions           []IndexPartitionCheck `json:"partitions"`
+	MissingSeriesCount   int                   `json:"missingSeriesCount"`
+	ReindexedSeriesCount int                   `json:"reindexedSeriesCount"`
+	StartedAt            time.Time             `json:"startedAt"`
+	FinishedAt           time.Time             `json:"finishedAt,omitempty"`
+}
+
+// IndexPartitionCheck is the result of the check of a partition of the index.
+//
+// DiscrepancyCount is the number of discrepancies found, of which at most the first hundred are listed.
+type IndexPartitionCheck struct {
+	Partition        int                `json:"partition"`
+	SeriesCount      uint64             `json:"seriesCount"`
+	DiscrepancyCount int                `json:"discrepancyCount"`
+	Discrepancies    []IndexDiscrepancy `json:"discrepancies"`
+	Rebuilt          bool               `json:"rebuilt"`
+}
+
+// IndexDiscrepancy is a series of a partition of the index found to be inconsistent.
+// Only its ID is known when the series is not in the series file.
+type IndexDiscrepancy struct {
+	Kind        string            `json:"kind"`
+	SeriesID    uint64            `json:"seriesID"`
+	OrgID       ID                `json:"orgID,omitempty"`
+	BucketID    ID                `json:"bucketID,omitempty"`
+	Measurement string            `json:"measurement,omitempty"`
+	Field       string            `json:"field,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
@@ -72,11 +72,6 @@ func (s *Service) Find(ctx context.Context, filter platform.DBRPMappingFilter) (
 		return nil, fmt.Errorf("no filter parameters provided")
 	}
 
-	// filter by dbrpMapping id
-	if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
-		return s.FindBy(ctx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
-	}
-
 	mappings, n, err := s.FindMany(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -98,17 +93,13 @@ func (s *Service) FindMany(ctx context.Context, filter platform.DBRPMappingFilte
 		if err != nil {
 			return nil, 0, err
 		}
+		if filter.OrganizationID != nil && *filter.OrganizationID != m.OrganizationID {
+			return []*platform.DBRPMapping{}, 0, nil
+		}
 		return []*platform.DBRPMapping{m}, 1, nil
 	}
 
-	filterFunc := func(mapping *platform.DBRPMapping) bool {
-		return (filter.Cluster == nil || (*filter.Cluster) == mapping.Cluster) &&
-			(filter.Database == nil || (*filter.Database) == mapping.Database) &&
-			(filter.RetentionPolicy == nil || (*filter.RetentionPolicy) == mapping.RetentionPolicy) &&
-			(filter.Default == nil || (*filter.Default) == mapping.Default)
-	}
-
-	mappings, err := s.filterDBRPMappings(ctx, filterFunc)
+	mappings, err := s.filterDBRPMappings(ctx, filter.Matches)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The statuses of the messages consumed.
+const (
+	statusWritten = "written"
+	statusInvalid = "invalid"
+	statusDropped = "dropped"
+)
+
+type metrics struct {
+	messages      *prometheus.CounterVec
+	points        *prometheus.CounterVec
+	writeFailures *prometheus.CounterVec
+	lag           *prometheus.GaugeVec
+	checkpoints   *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	const namespace = "kafka"
+	const subsystem = "consumer"
+
+	return &metrics{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "messages_total",
+			Help:      "Total number of messages consumed, split out by topic and status: written, invalid for those that cannot be parsed, or dropped for those of which the engine or the bucket schema dropped points.",
+		}, []string{"topic", "status"}),
+		points: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_total",
+			Help:      "Total number of points written from the messages consumed, split out by topic.",
+		}, []string{"topic"}),
+		writeFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "write_failures_total",
+			Help:      "Total number of failed writes of the points of messages, which are retried, split out by topic.",
+		}, []string{"topic"}),
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lag",
+			Help:      "Number of messages of a partition not consumed yet, split out by topic and partition.",
+		}, []string{"topic", "partition"}),
+		checkpoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "checkpoints_total",
+			Help:      "Total number of checkpoints of the offsets consumed, split out by topic and status.",
+		}, []string{"topic", "status"}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the consumed topics.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		s.metrics.messages,
+		s.metrics.points,
+		s.metrics.writeFailures,
+		s.metrics.lag,
+		s.metrics.checkpoints,
+	}
+}
@@ -0,0 +1,415 @@
+// Package kafka consumes the topics of a Kafka cluster and writes the messages it receives to buckets,
+// for producers publishing their measurements to Kafka to be stored without an agent in between.
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+)
+
+const (
+	// DefaultGroupID is the consumer group of the offsets checkpointed, by default.
+	DefaultGroupID = "influxd"
+
+	// DefaultCheckpointInterval is how often the offsets consumed are checkpointed, by default.
+	DefaultCheckpointInterval = 5 * time.Second
+
+	// minRetryDelay and maxRetryDelay bound the delay before retrying to find the partitions of a topic,
+	// or to write the points of a message, which doubles with every failed attempt.
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+
+	// kafkaFirstOffset is the offset of the readers reading a partition from its first message.
+	kafkaFirstOffset = -1
+)
+
+// PointsWriter writes points to the storage engine.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// OffsetStore checkpoints the offsets of the partitions of the topics consumed by a group,
+// the offset of a partition being the offset of its next message to consume.
+type OffsetStore interface {
+	FindKafkaOffsets(ctx context.Context, group, topic string) (map[int]int64, error)
+	PutKafkaOffsets(ctx context.Context, group, topic string, offsets map[int]int64) error
+}
+
+// messageReader reads the messages of a partition of a topic.
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafkago.Message, error)
+	Lag() int64
+	Close() error
+}
+
+// Service consumes the topics of the subscriptions from the brokers of a Kafka cluster, and writes the messages
+// of each topic to the buckets of its subscriptions. The messages that cannot be parsed, and the points not conforming
+// to the schema of an explicit-schema bucket, are logged and dropped, and the writes that fail are retried,
+// so that every message is written at least once.
+//
+// The offsets consumed are checkpointed in the OffsetStore under GroupID, instead of being committed to the
+// cluster, and consumption resumes from them, or from the first message of the partitions without any.
+// Each topic is consumed from every one of its partitions, found when the service opens:
+// the partitions of a group are not balanced between the members of a Kafka consumer group.
+type Service struct {
+	Brokers       []string
+	GroupID       string
+	Subscriptions []Subscription
+	Writer        PointsWriter
+	Offsets       OffsetStore
+
+	// SchemaChecker, if set, checks the points against the schemas of the explicit-schema buckets.
+	SchemaChecker *write.SchemaChecker
+
+	// CheckpointInterval is how often the offsets consumed are checkpointed.
+	CheckpointInterval time.Duration
+
+	Now    func() time.Time
+	Logger *zap.Logger
+
+	// partitions returns the partitions of a topic, and newReader a reader of a partition from an offset.
+	partitions func(ctx context.Context, topic string) ([]int, error)
+	newReader  func(topic string, partition int, offset int64) (messageReader, error)
+
+	metrics *metrics
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService returns a Service writing the messages of the subscriptions from brokers with w,
+// and checkpointing their offsets in offsets.
+func NewService(brokers []string, subs []Subscription, w PointsWriter, offsets OffsetStore, logger *zap.Logger) *Service {
+	s := &Service{
+		Brokers:            brokers,
+		GroupID:            DefaultGroupID,
+		Subscriptions:      subs,
+		Writer:             w,
+		Offsets:            offsets,
+		CheckpointInterval: DefaultCheckpointInterval,
+		Now:                time.Now,
+		Logger:             logger,
+		metrics:            newMetrics(),
+	}
+	s.partitions = s.lookupPartitions
+	s.newReader = s.newPartitionReader
+	return s
+}
+
+// Open validates the subscriptions, and consumes their topics in the background until Close is called.
+func (s *Service) Open() error {
+	if len(s.Brokers) == 0 {
+		return fmt.Errorf("no Kafka brokers")
+	}
+	if len(s.Subscriptions) == 0 {
+		return fmt.Errorf("no Kafka subscriptions")
+	}
+	topics := make(map[string][]*Subscription)
+	var order []string
+	for i := range s.Subscriptions {
+		sub := &s.Subscriptions[i]
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+		if _, ok := topics[sub.Topic]; !ok {
+			order = append(order, sub.Topic)
+		}
+		topics[sub.Topic] = append(topics[sub.Topic], sub)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, topic := range order {
+		s.wg.Add(1)
+		go func(topic string, subs []*Subscription) {
+			defer s.wg.Done()
+			s.consumeTopic(ctx, topic, subs)
+		}(topic, topics[topic])
+	}
+	return nil
+}
+
+// Close stops consuming, and checkpoints the offsets of the messages written.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// consumeTopic consumes every partition of topic until ctx is done, then checkpoints their offsets.
+func (s *Service) consumeTopic(ctx context.Context, topic string, subs []*Subscription) {
+	l := s.Logger.With(zap.String("topic", topic), zap.String("group_id", s.GroupID))
+
+	var (
+		partitions []int
+		offsets    map[int]int64
+	)
+	for delay := minRetryDelay; ; delay = nextDelay(delay) {
+		var err error
+		if partitions, err = s.partitions(ctx, topic); err == nil {
+			if offsets, err = s.Offsets.FindKafkaOffsets(ctx, s.GroupID, topic); err == nil {
+				break
+			}
+		}
+		l.Info("Failed to find the Kafka partitions and offsets of topic, retrying", zap.Duration("delay", delay), zap.Error(err))
+		if !sleep(ctx, delay) {
+			return
+		}
+	}
+	l.Info("Consuming Kafka topic", zap.Ints("partitions", partitions))
+
+	cp := &checkpoint{offsets: make(map[int]int64)}
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		offset, ok := offsets[partition]
+		if !ok {
+			offset = kafkaFirstOffset
+		}
+		r, err := s.newReader(topic, partition, offset)
+		if err != nil {
+			l.Error("Failed to read Kafka partition", zap.Int("partition", partition), zap.Error(err))
+			continue
+		}
+
+		wg.Add(1)
+		go func(partition int, r messageReader) {
+			defer wg.Done()
+			defer r.Close()
+			s.consumePartition(ctx, topic, subs, partition, r, cp)
+		}(partition, r)
+	}
+
+	ticker := time.NewTicker(s.CheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkpoint(context.Background(), topic, cp)
+		case <-ctx.Done():
+			wg.Wait()
+			s.checkpoint(context.Background(), topic, cp)
+			return
+		}
+	}
+}
+
+// consumePartition writes the messages of a partition to the buckets of the subscriptions of its topic
+// until ctx is done, and records the offsets of those written in cp.
+func (s *Service) consumePartition(ctx context.Context, topic string, subs []*Subscription, partition int, r messageReader, cp *checkpoint) {
+	lag := s.metrics.lag.WithLabelValues(topic, strconv.Itoa(partition))
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if ctx.Err() != nil {
+			return
+		} else if err != nil {
+			s.Logger.Info("Failed to read Kafka message", zap.String("topic", topic), zap.Int("partition", partition), zap.Error(err))
+			if !sleep(ctx, minRetryDelay) {
+				return
+			}
+			continue
+		}
+
+		if !s.handle(ctx, topic, subs, msg) {
+			return
+		}
+		cp.set(partition, msg.Offset+1)
+		lag.Set(float64(r.Lag()))
+	}
+}
+
+// handle writes the points of a message to the buckets of subs, retrying the writes that fail,
+// and returns false if ctx is done before they are written. The points without timestamp are
+// written at the time of the message, or at the time it is received if it has none.
+func (s *Service) handle(ctx context.Context, topic string, subs []*Subscription, msg kafkago.Message) bool {
+	t := msg.Time
+	if t.IsZero() {
+		t = s.Now()
+	}
+	status := statusWritten
+	for _, sub := range subs {
+		l := s.Logger.With(zap.String("topic", topic), zap.Int("partition", msg.Partition), zap.Int64("offset", msg.Offset), zap.String("bucket_id", sub.BucketID.String()))
+		points, err := sub.Points(msg.Value, t)
+		if err != nil {
+			l.Info("Dropping Kafka message that cannot be parsed", zap.Error(err))
+			status = statusInvalid
+			continue
+		}
+		if s.SchemaChecker != nil {
+			var rejected []write.RejectedPoint
+			var ok bool
+			if points, rejected, ok = s.checkSchema(ctx, l, sub.BucketID, points); !ok {
+				return false
+			}
+			for _, rp := range rejected {
+				l.Info("Dropping Kafka point not conforming to the bucket schema", zap.Error(rp.Err))
+				status = statusDropped
+			}
+			if len(points) == 0 {
+				continue
+			}
+		}
+		exploded, err := tsdb.ExplodePoints(sub.OrgID, sub.BucketID, points)
+		if err != nil {
+			l.Info("Dropping Kafka message that cannot be written", zap.Error(err))
+			status = statusInvalid
+			continue
+		}
+
+		for delay := minRetryDelay; ; delay = nextDelay(delay) {
+			err := s.Writer.WritePoints(ctx, exploded)
+			if err == nil {
+				break
+			}
+			if _, ok := err.(tsdb.PartialWriteError); ok {
+				l.Info("Dropped points of Kafka message", zap.Error(err))
+				status = statusDropped
+				break
+			}
+			s.metrics.writeFailures.WithLabelValues(topic).Inc()
+			l.Error("Failed to write Kafka message, retrying", zap.Duration("delay", delay), zap.Error(err))
+			if !sleep(ctx, delay) {
+				return false
+			}
+		}
+		s.metrics.points.WithLabelValues(topic).Add(float64(len(points)))
+	}
+	s.metrics.messages.WithLabelValues(topic, status).Inc()
+	return true
+}
+
+// checkSchema returns the points of a message that conform to the schema of the bucket bucketID, and those
+// that do not, retrying to find the schema until ctx is done, in which case it returns false.
+func (s *Service) checkSchema(ctx context.Context, l *zap.Logger, bucketID platform.ID, points []models.Point) ([]models.Point, []write.RejectedPoint, bool) {
+	for delay := minRetryDelay; ; delay = nextDelay(delay) {
+		accepted, rejected, err := s.SchemaChecker.CheckSchema(ctx, bucketID, points)
+		if err == nil {
+			return accepted, rejected, true
+		}
+		l.Error("Failed to check Kafka message against the bucket schema, retrying", zap.Duration("delay", delay), zap.Error(err))
+		if !sleep(ctx, delay) {
+			return nil, nil, false
+		}
+	}
+}
+
+// checkpoint stores the offsets of cp recorded since the last checkpoint.
+func (s *Service) checkpoint(ctx context.Context, topic string, cp *checkpoint) {
+	offsets := cp.changed()
+	if len(offsets) == 0 {
+		return
+	}
+	if err := s.Offsets.PutKafkaOffsets(ctx, s.GroupID, topic, offsets); err != nil {
+		s.metrics.checkpoints.WithLabelValues(topic, "error").Inc()
+		s.Logger.Error("Failed to checkpoint Kafka offsets", zap.String("topic", topic), zap.Error(err))
+		cp.restore(offsets)
+		return
+	}
+	s.metrics.checkpoints.WithLabelValues(topic, "ok").Inc()
+}
+
+// lookupPartitions returns the partitions of topic from the first broker answering.
+func (s *Service) lookupPartitions(ctx context.Context, topic string) ([]int, error) {
+	var err error
+	for _, broker := range s.Brokers {
+		var ps []kafkago.Partition
+		if ps, err = kafkago.DefaultDialer.LookupPartitions(ctx, "tcp", broker, topic); err != nil {
+			continue
+		}
+		ids := make([]int, 0, len(ps))
+		for _, p := range ps {
+			ids = append(ids, p.ID)
+		}
+		return ids, nil
+	}
+	return nil, err
+}
+
+func (s *Service) newPartitionReader(topic string, partition int, offset int64) (messageReader, error) {
+	r := kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:   s.Brokers,
+		Topic:     topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	if err := r.SetOffset(offset); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// checkpoint records the offsets of the partitions of a topic to checkpoint.
+type checkpoint struct {
+	mu      sync.Mutex
+	offsets map[int]int64
+}
+
+func (c *checkpoint) set(partition int, offset int64) {
+	c.mu.Lock()
+	c.offsets[partition] = offset
+	c.mu.Unlock()
+}
+
+// changed returns the offsets recorded since it was last called, which are forgotten.
+func (c *checkpoint) changed() map[int]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	offsets := c.offsets
+	c.offsets = make(map[int]int64)
+	return offsets
+}
+
+// restore records again the offsets that failed to be checkpointed, unless newer ones were recorded since.
+func (c *checkpoint) restore(offsets map[int]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for partition, offset := range offsets {
+		if _, ok := c.offsets[partition]; !ok {
+			c.offsets[partition] = offset
+		}
+	}
+}
+
+func nextDelay(delay time.Duration) time.Duration {
+	if delay *= 2; delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// sleep waits for d, and returns false if ctx is done before.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"go.uber.org/zap/zaptest"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/write"
+)
+
+// fakeReader returns the messages of a partition, then blocks.
+type fakeReader struct {
+	msgs []kafkago.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	if len(r.msgs) == 0 {
+		<-ctx.Done()
+		return kafkago.Message{}, ctx.Err()
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func (r *fakeReader) Lag() int64   { return int64(len(r.msgs)) }
+func (r *fakeReader) Close() error { return nil }
+
+type offsetStore struct {
+	mu      sync.Mutex
+	offsets map[int]int64
+}
+
+func (s *offsetStore) FindKafkaOffsets(ctx context.Context, group, topic string) (map[int]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offsets := make(map[int]int64)
+	for p, o := range s.offsets {
+		offsets[p] = o
+	}
+	return offsets, nil
+}
+
+func (s *offsetStore) PutKafkaOffsets(ctx context.Context, group, topic string, offsets map[int]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p, o := range offsets {
+		s.offsets[p] = o
+	}
+	return nil
+}
+
+// failingWriter fails the first write, then records the points written.
+type failingWriter struct {
+	mu     sync.Mutex
+	failed bool
+	points []models.Point
+	writes chan struct{}
+}
+
+func (w *failingWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.failed {
+		w.failed = true
+		return errors.New("engine is closed")
+	}
+	w.points = append(w.points, points...)
+	w.writes <- struct{}{}
+	return nil
+}
+
+// recordingWriter records the points written.
+type recordingWriter struct {
+	points []models.Point
+}
+
+func (w *recordingWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.points = append(w.points, points...)
+	return nil
+}
+
+func TestService(t *testing.T) {
+	at := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	partitions := map[int][]kafkago.Message{
+		0: {
+			{Partition: 0, Offset: 5, Value: []byte("temp value=21.5 1560168000000000000")},
+			{Partition: 0, Offset: 6, Value: []byte("temp value=")},
+		},
+		1: {
+			{Partition: 1, Offset: 0, Value: []byte("temp value=19"), Time: at},
+		},
+	}
+
+	offsets := &offsetStore{offsets: map[int]int64{0: 5}}
+	w := &failingWriter{writes: make(chan struct{}, 10)}
+	s := NewService([]string{"localhost:9092"}, []Subscription{
+		{Topic: "metrics", OrgID: 1, BucketID: 2},
+	}, w, offsets, zaptest.NewLogger(t))
+	s.partitions = func(ctx context.Context, topic string) ([]int, error) {
+		if topic != "metrics" {
+			t.Errorf("unexpected topic %q", topic)
+		}
+		return []int{0, 1}, nil
+	}
+	var mu sync.Mutex
+	started := make(map[int]int64)
+	s.newReader = func(topic string, partition int, offset int64) (messageReader, error) {
+		mu.Lock()
+		started[partition] = offset
+		mu.Unlock()
+		return &fakeReader{msgs: partitions[partition]}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first write fails and is retried.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-w.writes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the messages to be written")
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Partitions are read from their checkpointed offset, or from the first one.
+	if exp := map[int]int64{0: 5, 1: kafkaFirstOffset}; !reflect.DeepEqual(started, exp) {
+		t.Errorf("partitions read from offsets %v, expected %v", started, exp)
+	}
+	// The offsets checkpointed on close follow the messages consumed, including the invalid one.
+	if exp := map[int]int64{0: 7, 1: 1}; !reflect.DeepEqual(offsets.offsets, exp) {
+		t.Errorf("checkpointed offsets %v, expected %v", offsets.offsets, exp)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.points) != 2 {
+		t.Fatalf("expected 2 exploded points, got %d", len(w.points))
+	}
+	for _, pt := range w.points {
+		// The point without timestamp is written at the time of its message.
+		if got := pt.Time(); !got.Equal(at) {
+			t.Errorf("unexpected time %s of point %s", got, pt)
+		}
+	}
+}
+
+func TestService_OpenInvalid(t *testing.T) {
+	s := NewService([]string{"localhost:9092"}, []Subscription{{Topic: "metrics"}}, &failingWriter{}, &offsetStore{}, zaptest.NewLogger(t))
+	if err := s.Open(); err == nil {
+		s.Close()
+		t.Fatal("expected an error for a subscription without a bucket")
+	}
+}
+
+func TestService_Schema(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	failed := false
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		// The schema is found again once the first attempt fails.
+		if !failed {
+			failed = true
+			return nil, errors.New("store is closed")
+		}
+		return []*platform.MeasurementSchema{{
+			Name:   "temp",
+			Fields: []platform.MeasurementSchemaField{{Name: "value", Type: platform.SchemaFieldTypeFloat}},
+		}}, nil
+	}
+
+	w := &recordingWriter{}
+	s := NewService([]string{"localhost:9092"}, []Subscription{
+		{Topic: "metrics", OrgID: 1, BucketID: 2},
+	}, w, &offsetStore{}, zaptest.NewLogger(t))
+	s.SchemaChecker = &write.SchemaChecker{BucketService: buckets, MeasurementSchemaService: schemas}
+
+	subs := []*Subscription{&s.Subscriptions[0]}
+	msg := kafkago.Message{Value: []byte("temp value=21.5 1560168000000000000\ntemp,room=hall value=19 1560168000000000000")}
+	if !s.handle(context.Background(), "metrics", subs, msg) {
+		t.Fatal("expected the message to be handled")
+	}
+	msg = kafkago.Message{Value: []byte("humidity value=40 1560168000000000000")}
+	if !s.handle(context.Background(), "metrics", subs, msg) {
+		t.Fatal("expected the message to be handled")
+	}
+
+	if len(w.points) != 1 {
+		t.Fatalf("expected 1 exploded point, got %d", len(w.points))
+	}
+	if got := w.points[0].Tags().GetString("room"); got != "" {
+		t.Errorf("unexpected point %s", w.points[0])
+	}
+}
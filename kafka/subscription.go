@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/write"
+)
+
+// Subscription writes the messages of a topic to a bucket.
+//
+// The payload of a message is either line protocol, or JSON mapped to points by the JSON mapping,
+// as set by Format, one of write.FormatLineProtocol and write.FormatJSON.
+// Precision is the precision of the timestamps of the payloads, ns by default.
+// Points without timestamp are written at the time of their message.
+type Subscription struct {
+	Topic     string             `json:"topic"`
+	OrgID     platform.ID        `json:"orgID"`
+	BucketID  platform.ID        `json:"bucketID"`
+	Format    string             `json:"format"`
+	Precision string             `json:"precision,omitempty"`
+	JSON      *write.JSONMapping `json:"json,omitempty"`
+}
+
+// Validate returns an error if the subscription is invalid.
+func (s *Subscription) Validate() error {
+	if s.Topic == "" {
+		return fmt.Errorf("subscription topic is required")
+	}
+	if !s.OrgID.Valid() || !s.BucketID.Valid() {
+		return fmt.Errorf("subscription to %q: orgID and bucketID are required", s.Topic)
+	}
+	if s.Precision != "" && !models.ValidPrecision(s.Precision) {
+		return fmt.Errorf("subscription to %q: precision must be ns, us, ms or s", s.Topic)
+	}
+	switch s.Format {
+	case "", write.FormatLineProtocol:
+	case write.FormatJSON:
+		if s.JSON == nil {
+			return fmt.Errorf("subscription to %q: the json format requires a json mapping", s.Topic)
+		}
+		if err := s.JSON.Validate(); err != nil {
+			return fmt.Errorf("subscription to %q: %v", s.Topic, err)
+		}
+	default:
+		return fmt.Errorf("subscription to %q: unknown format %q", s.Topic, s.Format)
+	}
+	return nil
+}
+
+// Points returns the points of the payload of a message, produced at t.
+func (s *Subscription) Points(payload []byte, t time.Time) ([]models.Point, error) {
+	return write.ParsePayload(s.Format, s.Precision, s.JSON, payload, t)
+}
+
+// ReadSubscriptionsFile returns the subscriptions of a JSON file, an array of subscriptions.
+// The organizations of the subscriptions may be omitted, to be set by the caller.
+func ReadSubscriptionsFile(path string) ([]Subscription, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var subs []Subscription
+	if err := json.NewDecoder(f).Decode(&subs); err != nil {
+		return nil, fmt.Errorf("invalid Kafka subscriptions file %s: %v", path, err)
+	}
+	return subs, nil
+}
@@ -0,0 +1,123 @@
+package kafka_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/kafka"
+	"github.com/influxdata/influxdb/write"
+)
+
+func TestSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     kafka.Subscription
+		wantErr bool
+	}{
+		{
+			name: "line protocol",
+			sub:  kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2},
+		},
+		{
+			name: "json",
+			sub: kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2, Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+				Fields:      map[string]string{"value": "v"},
+			}},
+		},
+		{
+			name:    "missing topic",
+			sub:     kafka.Subscription{OrgID: 1, BucketID: 2},
+			wantErr: true,
+		},
+		{
+			name:    "missing bucket",
+			sub:     kafka.Subscription{Topic: "metrics", OrgID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "json without mapping",
+			sub:     kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2, Format: write.FormatJSON},
+			wantErr: true,
+		},
+		{
+			name: "json mapping with two measurements",
+			sub: kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2, Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement:     "temp",
+				MeasurementPath: "type",
+				Fields:          map[string]string{"value": "v"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			sub:     kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2, Format: "csv"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid precision",
+			sub:     kafka.Subscription{Topic: "metrics", OrgID: 1, BucketID: 2, Precision: "h"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sub.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscription_Points(t *testing.T) {
+	at := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		sub     kafka.Subscription
+		payload string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "line protocol at the time of the message",
+			sub:     kafka.Subscription{Precision: "s"},
+			payload: "temp,room=kitchen value=21.5 1560168000\ntemp,room=hall value=19",
+			want: []string{
+				"temp,room=kitchen value=21.5 1560168000000000000",
+				"temp,room=hall value=19 1560168000000000000",
+			},
+		},
+		{
+			name: "json",
+			sub: kafka.Subscription{Format: write.FormatJSON, Precision: "ms", JSON: &write.JSONMapping{
+				Measurement: "climate",
+				TimePath:    "ts",
+				Tags:        map[string]string{"device": "device"},
+				Fields:      map[string]string{"temp": "temp"},
+			}},
+			payload: `{"ts": 1560168000123, "device": "d1", "temp": 21}`,
+			want:    []string{"climate,device=d1 temp=21 1560168000123000000"},
+		},
+		{
+			name:    "invalid line protocol",
+			payload: "temp value=",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := tt.sub.Points([]byte(tt.payload), at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Points() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+			}
+			for i, pt := range points {
+				if pt.String() != tt.want[i] {
+					t.Errorf("got point %q, want %q", pt.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// KioskPermissions returns the permissions of a kiosk token for the dashboard with id:
+// read access to the dashboard and to exactly the buckets the queries of its cells read from.
+// Buckets are referenced by the bucket or bucketID arguments of the flux queries, and by the
+// buckets of the query builder, and are looked up in the organization of the dashboard.
+func KioskPermissions(ctx context.Context, ds DashboardService, bs BucketService, id ID) ([]Permission, error) {
+	d, err := ds.FindDashboardByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	perm, err := NewPermissionAtID(d.ID, ReadAction, DashboardsResourceType, d.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	ps := []Permission{*perm}
+
+	seen := make(map[ID]bool)
+	addBucket := func(filter BucketFilter, ref string) error {
+		b, err := bs.FindBucket(ctx, filter)
+		if err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("dashboard query references unknown bucket %q", ref),
+				Err:  err,
+			}
+		}
+		if seen[b.ID] {
+			return nil
+		}
+		seen[b.ID] = true
+
+		perm, err := NewPermissionAtID(b.ID, ReadAction, BucketsResourceType, b.OrganizationID)
+		if err != nil {
+			return err
+		}
+		ps = append(ps, *perm)
+		return nil
+	}
+
+	for _, c := range d.Cells {
+		v, err := ds.GetDashboardCellView(ctx, d.ID, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range viewQueries(v.Properties) {
+			names := append([]string(nil), q.BuilderConfig.Buckets...)
+			bucketNames, bucketIDs, err := bucketReferences(q.Text)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, bucketNames...)
+
+			for _, name := range names {
+				name := name
+				if err := addBucket(BucketFilter{OrganizationID: &d.OrganizationID, Name: &name}, name); err != nil {
+					return nil, err
+				}
+			}
+			for _, s := range bucketIDs {
+				bucketID, err := IDFromString(s)
+				if err != nil {
+					return nil, &Error{
+						Code: EInvalid,
+						Msg:  fmt.Sprintf("dashboard query references invalid bucket ID %q", s),
+						Err:  err,
+					}
+				}
+				if err := addBucket(BucketFilter{OrganizationID: &d.OrganizationID, ID: bucketID}, s); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return ps, nil
+}
+
+// viewQueries returns the queries of the view properties, if they have any.
+func viewQueries(props ViewProperties) []DashboardQuery {
+	switch p := props.(type) {
+	case XYViewProperties:
+		return p.Queries
+	case LinePlusSingleStatProperties:
+		return p.Queries
+	case SingleStatViewProperties:
+		return p.Queries
+	case HistogramViewProperties:
+		return p.Queries
+	case GaugeViewProperties:
+		return p.Queries
+	case TableViewProperties:
+		return p.Queries
+	}
+	return nil
+}
+
+// bucketReferences returns the string literals passed as the bucket and bucketID arguments
+// of the function calls of the flux script. A bucket referenced by any other expression cannot
+// be determined, and is an error.
+func bucketReferences(script string) (names, ids []string, err error) {
+	if script == "" {
+		return nil, nil, nil
+	}
+
+	pkg := parser.ParseSource(script)
+	if ast.Check(pkg) > 0 {
+		return nil, nil, &Error{
+			Code: EInvalid,
+			Err:  ast.GetError(pkg),
+		}
+	}
+
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok || err != nil {
+			return
+		}
+		for _, arg := range call.Arguments {
+			obj, ok := arg.(*ast.ObjectExpression)
+			if !ok {
+				continue
+			}
+			for _, p := range obj.Properties {
+				k := p.Key.Key()
+				if k != "bucket" && k != "bucketID" {
+					continue
+				}
+				lit, ok := p.Value.(*ast.StringLiteral)
+				if !ok {
+					err = &Error{
+						Code: EInvalid,
+						Msg:  fmt.Sprintf("dashboard query %s argument must be a string literal", k),
+					}
+					return
+				}
+				if k == "bucket" {
+					names = append(names, lit.Value)
+				} else {
+					ids = append(ids, lit.Value)
+				}
+			}
+		}
+	}), pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return names, ids, nil
+}
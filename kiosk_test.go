@@ -0,0 +1,86 @@
+package influxdb_test
+
+import (
+	"context"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestKioskPermissions(t *testing.T) {
+	ctx := context.Background()
+	s := kv.NewService(inmem.NewKVStore())
+	if err := s.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &platform.Organization{Name: "o"}
+	if err := s.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	var buckets []*platform.Bucket
+	for _, name := range []string{"telegraf", "system", "secrets"} {
+		b := &platform.Bucket{OrganizationID: org.ID, Name: name}
+		if err := s.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	newDashboard := func(queries ...platform.DashboardQuery) *platform.Dashboard {
+		t.Helper()
+		d := &platform.Dashboard{OrganizationID: org.ID, Name: "wall"}
+		if err := s.CreateDashboard(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+		view := &platform.View{
+			Properties: platform.XYViewProperties{Type: "xy", Queries: queries},
+		}
+		if err := s.AddDashboardCell(ctx, d.ID, &platform.Cell{}, platform.AddDashboardCellOptions{View: view}); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	d := newDashboard(
+		platform.DashboardQuery{
+			Text: `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r.bucket == "secrets")`,
+		},
+		platform.DashboardQuery{
+			Text:          `from(bucketID: "` + buckets[1].ID.String() + `") |> range(start: -1h)`,
+			BuilderConfig: platform.BuilderConfig{Buckets: []string{"telegraf"}},
+		},
+	)
+	ps, err := platform.KioskPermissions(ctx, s, s, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []platform.Permission{
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.DashboardsResourceType, ID: &d.ID, OrgID: &org.ID}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, ID: &buckets[0].ID, OrgID: &org.ID}},
+		{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, ID: &buckets[1].ID, OrgID: &org.ID}},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("unexpected permissions: %v", ps)
+	}
+	for i := range want {
+		if ps[i].String() != want[i].String() {
+			t.Errorf("unexpected permission %d: got %s, want %s", i, ps[i], want[i])
+		}
+	}
+
+	for _, q := range []string{
+		`from(bucket: "missing") |> range(start: -1h)`,
+		`b = "telegraf"
+from(bucket: b) |> range(start: -1h)`,
+		`from(bucket: "telegraf")) |> range(start: -1h)`,
+	} {
+		d := newDashboard(platform.DashboardQuery{Text: q})
+		if _, err := platform.KioskPermissions(ctx, s, s, d.ID); platform.ErrorCode(err) != platform.EInvalid {
+			t.Errorf("expected invalid error for query %q, got %v", q, err)
+		}
+	}
+}
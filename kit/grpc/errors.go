@@ -25,6 +25,12 @@ func ToStatus(err *platform.Error) (*status.Status, error) {
 		c = codes.InvalidArgument
 	case platform.EUnavailable:
 		c = codes.Unavailable
+	case platform.EUnauthorized:
+		c = codes.Unauthenticated
+	case platform.EForbidden:
+		c = codes.PermissionDenied
+	case platform.ETooManyRequests:
+		c = codes.ResourceExhausted
 	}
 
 	buf, jerr := json.Marshal(err)
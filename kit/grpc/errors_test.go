@@ -66,6 +66,16 @@ func TestToStatus(t *testing.T) {
 			wantCode:    codes.Unavailable,
 			wantMessage: `{"code":"unavailable","message":"howdy","op":"kit/grpc","error":"error"}`,
 		},
+		{
+			name: "encode unauthorized error",
+			err: &platform.Error{
+				Op:   "kit/grpc",
+				Code: platform.EUnauthorized,
+				Msg:  "howdy",
+			},
+			wantCode:    codes.Unauthenticated,
+			wantMessage: `{"code":"unauthorized","message":"howdy","op":"kit/grpc"}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,148 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	activityBucket = []byte("activityv1")
+)
+
+var _ influxdb.ActivityService = (*Service)(nil)
+
+func (s *Service) initializeActivity(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(activityBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// activityKey returns the key of the last activity of the resource of type rt with id.
+func activityKey(rt influxdb.ResourceType, id influxdb.ID) ([]byte, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	k := make([]byte, 0, len(rt)+1+len(encID))
+	k = append(k, rt...)
+	k = append(k, '/')
+	return append(k, encID...), nil
+}
+
+// RecordActivity records that the resource of type rt with id was used at t.
+// The activity is read first, so that recording the use of a busy resource is usually read-only.
+func (s *Service) RecordActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID, t time.Time) error {
+	last, err := s.FindLastActivity(ctx, rt, id)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if err == nil && t.Sub(last) < influxdb.ActivityResolution {
+		return nil
+	}
+
+	err = s.kv.Update(ctx, func(tx Tx) error {
+		last, err := s.findLastActivity(ctx, tx, rt, id)
+		if err == nil && !t.After(last) {
+			return nil
+		}
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		return s.putActivity(ctx, tx, rt, id, t)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpRecordActivity,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putActivity(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID, t time.Time) error {
+	k, err := activityKey(rt, id)
+	if err != nil {
+		return err
+	}
+	v, err := t.UTC().MarshalText()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(activityBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(k, v)
+}
+
+// FindLastActivity returns the time the resource of type rt with id was last used.
+func (s *Service) FindLastActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) (time.Time, error) {
+	var last time.Time
+	err := s.kv.View(ctx, func(tx Tx) error {
+		t, err := s.findLastActivity(ctx, tx, rt, id)
+		last = t
+		return err
+	})
+	if err != nil {
+		return time.Time{}, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindLastActivity,
+			Err: err,
+		}
+	}
+	return last, nil
+}
+
+func (s *Service) findLastActivity(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID) (time.Time, error) {
+	k, err := activityKey(rt, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	b, err := tx.Bucket(activityBucket)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	v, err := b.Get(k)
+	if IsNotFound(err) {
+		return time.Time{}, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrActivityNotFound,
+		}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var t time.Time
+	if err := t.UnmarshalText(v); err != nil {
+		return time.Time{}, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return t, nil
+}
+
+// deleteActivity removes the recorded activity of the resource of type rt with id, if any.
+func (s *Service) deleteActivity(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID) error {
+	k, err := activityKey(rt, id)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(activityBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete(k); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_RecordActivity(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &influxdb.Dashboard{OrganizationID: 1, Name: "wall"}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.FindLastActivity(ctx, influxdb.DashboardsResourceType, d.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	t0 := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		at   time.Time
+		want time.Time
+	}{
+		{at: t0, want: t0},
+		// Uses within the resolution of the last recorded activity are not recorded.
+		{at: t0.Add(influxdb.ActivityResolution / 2), want: t0},
+		// Earlier uses never replace a later one.
+		{at: t0.Add(-time.Hour), want: t0},
+		{at: t0.Add(time.Hour), want: t0.Add(time.Hour)},
+	} {
+		if err := svc.RecordActivity(ctx, influxdb.DashboardsResourceType, d.ID, tt.at); err != nil {
+			t.Fatal(err)
+		}
+		got, err := svc.FindLastActivity(ctx, influxdb.DashboardsResourceType, d.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("recording activity at %s: last activity is %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	// Activity is recorded per resource type.
+	if _, err := svc.FindLastActivity(ctx, influxdb.AuthorizationsResourceType, d.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if err := svc.DeleteDashboard(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindLastActivity(ctx, influxdb.DashboardsResourceType, d.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected activity of deleted dashboard to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	announcementBucket = []byte("announcementsv1")
+)
+
+var _ influxdb.AnnouncementService = (*Service)(nil)
+
+func (s *Service) initializeAnnouncements(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(announcementBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindAnnouncements returns the announcements that match a filter.
+func (s *Service) FindAnnouncements(ctx context.Context, filter influxdb.AnnouncementFilter) ([]*influxdb.Announcement, error) {
+	as := []*influxdb.Announcement{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachAnnouncement(ctx, tx, func(a *influxdb.Announcement) bool {
+			if filter.Matches(a) {
+				as = append(as, a)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindAnnouncements,
+			Err: err,
+		}
+	}
+
+	return as, nil
+}
+
+// forEachAnnouncement will iterate through all announcements while fn returns true.
+func (s *Service) forEachAnnouncement(ctx context.Context, tx Tx, fn func(*influxdb.Announcement) bool) error {
+	b, err := tx.Bucket(announcementBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		a := &influxdb.Announcement{}
+		if err := json.Unmarshal(v, a); err != nil {
+			return err
+		}
+		if !fn(a) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findAnnouncementByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Announcement, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(announcementBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrAnnouncementNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := &influxdb.Announcement{}
+	if err := json.Unmarshal(v, a); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return a, nil
+}
+
+// CreateAnnouncement creates a new announcement and sets a.ID with the new identifier.
+func (s *Service) CreateAnnouncement(ctx context.Context, a *influxdb.Announcement) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		a.ID = s.IDGenerator.ID()
+		return s.putAnnouncement(ctx, tx, a)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateAnnouncement,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutAnnouncement creates an announcement from the provided struct, without generating a new ID.
+func (s *Service) PutAnnouncement(ctx context.Context, a *influxdb.Announcement) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putAnnouncement(ctx, tx, a)
+	})
+}
+
+func (s *Service) putAnnouncement(ctx context.Context, tx Tx, a *influxdb.Announcement) error {
+	v, err := json.Marshal(a)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := a.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(announcementBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(encID, v)
+}
+
+// UpdateAnnouncement updates a single announcement with changeset.
+// Returns the new announcement state after update.
+func (s *Service) UpdateAnnouncement(ctx context.Context, id influxdb.ID, upd influxdb.AnnouncementUpdate) (*influxdb.Announcement, error) {
+	var a *influxdb.Announcement
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		a, err = s.findAnnouncementByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := upd.Apply(a); err != nil {
+			return err
+		}
+
+		return s.putAnnouncement(ctx, tx, a)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateAnnouncement,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+// DeleteAnnouncement removes an announcement by ID.
+func (s *Service) DeleteAnnouncement(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findAnnouncementByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(announcementBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Delete(encID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteAnnouncement,
+			Err: err,
+		}
+	}
+	return nil
+}
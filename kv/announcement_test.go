@@ -0,0 +1,61 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltAnnouncementService(t *testing.T) {
+	influxdbtesting.AnnouncementService(initBoltAnnouncementService, t)
+}
+
+func TestInmemAnnouncementService(t *testing.T) {
+	influxdbtesting.AnnouncementService(initInmemAnnouncementService, t)
+}
+
+func initBoltAnnouncementService(f influxdbtesting.AnnouncementFields, t *testing.T) (influxdb.AnnouncementService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initAnnouncementService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemAnnouncementService(f influxdbtesting.AnnouncementFields, t *testing.T) (influxdb.AnnouncementService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initAnnouncementService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initAnnouncementService(s kv.Store, f influxdbtesting.AnnouncementFields, t *testing.T) (influxdb.AnnouncementService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing announcement service: %v", err)
+	}
+	for _, a := range f.Announcements {
+		if err := svc.PutAnnouncement(ctx, a); err != nil {
+			t.Fatalf("failed to populate announcements: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
@@ -409,7 +409,7 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 			Err: err,
 		}
 	}
-	return nil
+	return s.deleteActivity(ctx, tx, influxdb.AuthorizationsResourceType, id)
 }
 
 // SetAuthorizationStatus updates the status of the authorization. Useful
@@ -384,18 +384,25 @@ func (s *Service) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
 }
 
 func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) error {
+	if err := b.SchemaType.Valid(); err != nil {
+		return err
+	}
+
+	var o *influxdb.Organization
 	if b.OrganizationID.Valid() {
 		span, ctx := tracing.StartSpanFromContext(ctx)
 		defer span.Finish()
 
-		_, pe := s.findOrganizationByID(ctx, tx, b.OrganizationID)
+		var pe error
+		o, pe = s.findOrganizationByID(ctx, tx, b.OrganizationID)
 		if pe != nil {
 			return &influxdb.Error{
 				Err: pe,
 			}
 		}
 	} else {
-		o, pe := s.findOrganizationByName(ctx, tx, b.Organization)
+		var pe error
+		o, pe = s.findOrganizationByName(ctx, tx, b.Organization)
 		if pe != nil {
 			return &influxdb.Error{
 				Err: pe,
@@ -404,6 +411,13 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) e
 		b.OrganizationID = o.ID
 	}
 
+	if o.BucketPolicy != nil {
+		o.BucketPolicy.ApplyDefaults(b)
+		if err := o.BucketPolicy.CheckBucket(b); err != nil {
+			return err
+		}
+	}
+
 	// if the bucket name is not unique for this organization, then, do not
 	// allow creation.
 	if err := s.uniqueBucketName(ctx, tx, b); err != nil {
@@ -644,6 +658,10 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.Name = *upd.Name
 	}
 
+	if err := s.checkBucketPolicy(ctx, tx, b); err != nil {
+		return nil, err
+	}
+
 	if err := s.appendBucketEventToLog(ctx, tx, b.ID, bucketUpdatedEvent); err != nil {
 		return nil, err
 	}
@@ -659,6 +677,19 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 	return b, nil
 }
 
+// checkBucketPolicy returns an error if b violates its organization's bucket policy.
+func (s *Service) checkBucketPolicy(ctx context.Context, tx Tx, b *influxdb.Bucket) error {
+	o, err := s.findOrganizationByID(ctx, tx, b.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if o.BucketPolicy == nil {
+		return nil
+	}
+	return o.BucketPolicy.CheckBucket(b)
+}
+
 // DeleteBucket deletes a bucket and prunes it from the index.
 func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
@@ -718,6 +749,14 @@ func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error
 		return err
 	}
 
+	if err := s.deleteBucketExpectedReporters(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := s.deleteBucketMeasurementSchemas(ctx, tx, id); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -821,3 +860,57 @@ func BucketAlreadyExistsError(b *influxdb.Bucket) error {
 		Msg:  fmt.Sprintf("bucket with name %s already exists", b.Name),
 	}
 }
+
+// ApplyBucketBatch applies the operations of a bucket batch in a single transaction, rolled back if any fails.
+func (s *Service) ApplyBucketBatch(ctx context.Context, ops []influxdb.BucketBatchOperation) ([]influxdb.BucketBatchResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var results []influxdb.BucketBatchResult
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		results = make([]influxdb.BucketBatchResult, len(ops))
+		failed := 0
+		// Every operation is applied, so that the errors of all those failing are reported.
+		for i, op := range ops {
+			b, err := s.applyBucketBatchOperation(ctx, tx, op)
+			if err != nil {
+				failed++
+			}
+			results[i] = influxdb.BucketBatchResult{Bucket: b, Err: err}
+		}
+		if failed > 0 {
+			return &influxdb.BucketBatchError{Failed: failed}
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (s *Service) applyBucketBatchOperation(ctx context.Context, tx Tx, op influxdb.BucketBatchOperation) (*influxdb.Bucket, error) {
+	if err := op.Validate(); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	switch op.Op {
+	case influxdb.BucketBatchCreate:
+		b := *op.Bucket
+		if err := s.createBucket(ctx, tx, &b); err != nil {
+			return nil, err
+		}
+		return &b, nil
+	case influxdb.BucketBatchUpdate:
+		return s.updateBucket(ctx, tx, op.ID, *op.Update)
+	default:
+		b, err := s.findBucketByID(ctx, tx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.deleteBucket(ctx, tx, op.ID); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+}
@@ -0,0 +1,127 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	bucketLifecyclePoliciesBucket = []byte("bucketlifecyclepoliciesv1")
+)
+
+func (s *Service) initializeBucketLifecyclePolicies(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(bucketLifecyclePoliciesBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindBucketLifecyclePolicy returns the lifecycle policy of a bucket.
+func (s *Service) FindBucketLifecyclePolicy(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketLifecyclePolicy, error) {
+	var p *influxdb.BucketLifecyclePolicy
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		p, err = s.findBucketLifecyclePolicy(ctx, tx, bucketID)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindBucketLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findBucketLifecyclePolicy(ctx context.Context, tx Tx, bucketID influxdb.ID) (*influxdb.BucketLifecyclePolicy, error) {
+	encID, err := bucketID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucketLifecyclePoliciesBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrBucketLifecyclePolicyNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &influxdb.BucketLifecyclePolicy{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PutBucketLifecyclePolicy stores the lifecycle policy of a bucket as is, replacing its previous one.
+// It does not materialize the policy.
+func (s *Service) PutBucketLifecyclePolicy(ctx context.Context, p *influxdb.BucketLifecyclePolicy) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		encID, err := p.BucketID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		v, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(bucketLifecyclePoliciesBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(encID, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpPutBucketLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteBucketLifecyclePolicy removes the lifecycle policy of a bucket.
+// It does not remove the buckets and tasks materializing it.
+func (s *Service) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findBucketLifecyclePolicy(ctx, tx, bucketID); err != nil {
+			return err
+		}
+
+		encID, err := bucketID.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(bucketLifecyclePoliciesBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteBucketLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_BucketLifecyclePolicies(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.FindBucketLifecyclePolicy(ctx, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	p := &influxdb.BucketLifecyclePolicy{
+		BucketID:     1,
+		OrgID:        2,
+		RawRetention: 7 * 24 * time.Hour,
+		Tiers: []influxdb.BucketLifecycleTier{
+			{Every: time.Minute, Retention: 90 * 24 * time.Hour, BucketID: 3, TaskID: 4},
+		},
+		UpdatedAt: time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC),
+	}
+	if err := svc.PutBucketLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.FindBucketLifecyclePolicy(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Fatalf("got policy %+v, want %+v", got, p)
+	}
+
+	if err := svc.DeleteBucketLifecyclePolicy(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindBucketLifecyclePolicy(ctx, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error after delete, got %v", err)
+	}
+	if err := svc.DeleteBucketLifecyclePolicy(ctx, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error deleting a missing policy, got %v", err)
+	}
+}
@@ -3,9 +3,11 @@ package kv_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
 	influxdbtesting "github.com/influxdata/influxdb/testing"
 )
 
@@ -74,3 +76,159 @@ func initBucketService(s kv.Store, f influxdbtesting.BucketFields, t *testing.T)
 		}
 	}
 }
+
+func TestService_BucketPolicy(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing bucket service: %v", err)
+	}
+
+	o := &influxdb.Organization{Name: "tenant"}
+	if err := svc.CreateOrganization(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &influxdb.BucketPolicy{
+		DefaultRetentionPeriod: 24 * time.Hour,
+		MinRetentionPeriod:     time.Hour,
+		MaxRetentionPeriod:     7 * 24 * time.Hour,
+		NamePattern:            "^team-",
+	}
+	if _, err := svc.UpdateOrganization(ctx, o.ID, influxdb.OrganizationUpdate{BucketPolicy: policy}); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &influxdb.Bucket{OrganizationID: o.ID, Name: "team-a"}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.RetentionPeriod != policy.DefaultRetentionPeriod {
+		t.Errorf("expected default retention %s, got %s", policy.DefaultRetentionPeriod, b.RetentionPeriod)
+	}
+
+	err = svc.CreateBucket(ctx, &influxdb.Bucket{OrganizationID: o.ID, Name: "other"})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Errorf("expected invalid bucket name to be rejected, got %v", err)
+	}
+
+	tooLong := 30 * 24 * time.Hour
+	_, err = svc.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{RetentionPeriod: &tooLong})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Errorf("expected retention above maximum to be rejected, got %v", err)
+	}
+
+	found, err := svc.FindBucketByID(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.RetentionPeriod != policy.DefaultRetentionPeriod {
+		t.Errorf("rejected update should not have been persisted, got retention %s", found.RetentionPeriod)
+	}
+
+	// An empty policy removes the policy of the organization.
+	updated, err := svc.UpdateOrganization(ctx, o.ID, influxdb.OrganizationUpdate{BucketPolicy: &influxdb.BucketPolicy{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.BucketPolicy != nil {
+		t.Errorf("expected the bucket policy to be removed, got %+v", updated.BucketPolicy)
+	}
+	if err := svc.CreateBucket(ctx, &influxdb.Bucket{OrganizationID: o.ID, Name: "other"}); err != nil {
+		t.Errorf("expected any bucket to be allowed once the policy is removed, got %v", err)
+	}
+}
+
+func TestService_InvalidBucketPolicy(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing bucket service: %v", err)
+	}
+
+	invalid := []*influxdb.BucketPolicy{
+		{NamePattern: "("},
+		{MinRetentionPeriod: 2 * time.Hour, MaxRetentionPeriod: time.Hour},
+	}
+	for _, p := range invalid {
+		err := svc.CreateOrganization(ctx, &influxdb.Organization{Name: "tenant", BucketPolicy: p})
+		if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+			t.Errorf("expected organization with policy %+v to be rejected, got %v", p, err)
+		}
+		err = svc.PutOrganization(ctx, &influxdb.Organization{ID: 1, Name: "tenant", BucketPolicy: p})
+		if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+			t.Errorf("expected put organization with policy %+v to be rejected, got %v", p, err)
+		}
+	}
+
+	orgs, _, err := svc.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 0 {
+		t.Errorf("expected no organization to be stored, got %d", len(orgs))
+	}
+}
+
+func TestService_ApplyBucketBatch(t *testing.T) {
+	// The batch is rolled back by the transaction of the store, which the inmem store does not support.
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	svc, _, closeSvc := initBucketService(s, influxdbtesting.BucketFields{
+		IDGenerator: mock.NewIDGenerator("020f755c3c082010", t),
+		Organizations: []*influxdb.Organization{
+			{ID: influxdbtesting.MustIDBase16("020f755c3c082001"), Name: "org"},
+		},
+		Buckets: []*influxdb.Bucket{
+			{ID: influxdbtesting.MustIDBase16("020f755c3c082000"), OrganizationID: influxdbtesting.MustIDBase16("020f755c3c082001"), Name: "a"},
+		},
+	}, t)
+	defer closeSvc()
+	batches := svc.(influxdb.BucketBatchService)
+	ctx := context.Background()
+
+	name := "b"
+	ops := []influxdb.BucketBatchOperation{
+		{Op: influxdb.BucketBatchCreate, Bucket: &influxdb.Bucket{OrganizationID: influxdbtesting.MustIDBase16("020f755c3c082001"), Name: "c"}},
+		{Op: influxdb.BucketBatchUpdate, ID: influxdbtesting.MustIDBase16("020f755c3c082000"), Update: &influxdb.BucketUpdate{Name: &name}},
+		{Op: influxdb.BucketBatchDelete, ID: influxdbtesting.MustIDBase16("020f755c3c0820ff")},
+	}
+
+	results, err := batches.ApplyBucketBatch(ctx, ops)
+	if e, ok := err.(*influxdb.BucketBatchError); !ok || e.Failed != 1 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if results[0].Err != nil || results[1].Err != nil || influxdb.ErrorCode(results[2].Err) != influxdb.ENotFound {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if _, n, err := svc.FindBuckets(ctx, influxdb.BucketFilter{Name: &name}); err != nil || n != 0 {
+		t.Errorf("expected no operation of the failed batch to be applied, found %d buckets: %v", n, err)
+	}
+
+	results, err = batches.ApplyBucketBatch(ctx, ops[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Bucket.ID != influxdbtesting.MustIDBase16("020f755c3c082010") || results[1].Bucket.Name != "b" {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if _, n, err := svc.FindBuckets(ctx, influxdb.BucketFilter{Name: &name}); err != nil || n != 1 {
+		t.Errorf("expected the batch to be applied, found %d buckets: %v", n, err)
+	}
+}
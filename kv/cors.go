@@ -0,0 +1,112 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	corsPolicyBucket = []byte("corspolicyv1")
+	corsPolicyKey    = []byte("policy")
+)
+
+var _ influxdb.CORSPolicyService = (*Service)(nil)
+
+func (s *Service) initializeCORSPolicy(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(corsPolicyBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindCORSPolicy returns the stored CORS policy of the HTTP API.
+func (s *Service) FindCORSPolicy(ctx context.Context) (*influxdb.CORSPolicy, error) {
+	var p *influxdb.CORSPolicy
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		p, err = s.findCORSPolicy(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindCORSPolicy,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findCORSPolicy(ctx context.Context, tx Tx) (*influxdb.CORSPolicy, error) {
+	b, err := tx.Bucket(corsPolicyBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(corsPolicyKey)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrCORSPolicyNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &influxdb.CORSPolicy{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdateCORSPolicy stores the CORS policy of the HTTP API, replacing the previous one.
+func (s *Service) UpdateCORSPolicy(ctx context.Context, p influxdb.CORSPolicy) (*influxdb.CORSPolicy, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		v, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(corsPolicyBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(corsPolicyKey, v)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateCORSPolicy,
+			Err: err,
+		}
+	}
+	return &p, nil
+}
+
+// DeleteCORSPolicy removes the stored CORS policy of the HTTP API.
+func (s *Service) DeleteCORSPolicy(ctx context.Context) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findCORSPolicy(ctx, tx); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(corsPolicyBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(corsPolicyKey)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteCORSPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_CORSPolicy(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	if _, err := svc.FindCORSPolicy(ctx); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected no policy to be stored, got %v", err)
+	}
+
+	p := influxdb.CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET"},
+	}
+	if _, err := svc.UpdateCORSPolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.FindCORSPolicy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.AllowedOrigins) != 1 || got.AllowedOrigins[0] != "https://app.example.com" || len(got.AllowedMethods) != 1 {
+		t.Errorf("unexpected policy %+v", got)
+	}
+
+	if _, err := svc.UpdateCORSPolicy(ctx, influxdb.CORSPolicy{AllowedOrigins: []string{"app.example.com"}}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid origin to be rejected, got %v", err)
+	}
+
+	if err := svc.DeleteCORSPolicy(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindCORSPolicy(ctx); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the policy to be deleted, got %v", err)
+	}
+}
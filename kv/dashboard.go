@@ -899,6 +899,12 @@ func (s *Service) deleteDashboard(ctx context.Context, tx Tx, id influxdb.ID) er
 		}
 	}
 
+	if err := s.deleteActivity(ctx, tx, influxdb.DashboardsResourceType, id); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
 	if err := s.appendDashboardEventToLog(ctx, tx, d.ID, dashboardRemovedEvent); err != nil {
 		return &influxdb.Error{
 			Err: err,
@@ -0,0 +1,213 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	dbrpMappingBucket = []byte("dbrpmappingsv1")
+)
+
+var (
+	// ErrDBRPMappingNotFound is returned when a dbrp mapping is not found.
+	ErrDBRPMappingNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Err:  errors.New("dbrp mapping not found"),
+	}
+
+	// ErrDBRPMappingExists is returned when another mapping exists for the cluster, database and retention policy of a new mapping.
+	ErrDBRPMappingExists = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Err:  errors.New("dbrp mapping already exists"),
+	}
+)
+
+var _ influxdb.DBRPMappingService = (*Service)(nil)
+
+func (s *Service) initializeDBRPMappings(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(dbrpMappingBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dbrpMappingKey is the key of the mapping of cluster, db and rp, which cannot hold slashes.
+func dbrpMappingKey(cluster, db, rp string) []byte {
+	return []byte(path.Join(cluster, db, rp))
+}
+
+// FindBy returns the dbrp mapping for cluster, db and rp.
+func (s *Service) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	var m *influxdb.DBRPMapping
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		m, err = s.findDBRPMapping(ctx, tx, cluster, db, rp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) findDBRPMapping(ctx context.Context, tx Tx, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(dbrpMappingKey(cluster, db, rp))
+	if IsNotFound(err) {
+		return nil, ErrDBRPMappingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &influxdb.DBRPMapping{}
+	if err := json.Unmarshal(v, m); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return m, nil
+}
+
+// Find returns the first dbrp mapping that matches filter.
+func (s *Service) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	if filter.Cluster == nil && filter.Database == nil && filter.RetentionPolicy == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no filter parameters provided",
+		}
+	}
+
+	ms, n, err := s.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, ErrDBRPMappingNotFound
+	}
+	return ms[0], nil
+}
+
+// FindMany returns the dbrp mappings that match filter and their count.
+func (s *Service) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	ms := []*influxdb.DBRPMapping{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		// The mapping of a cluster, database and retention policy is looked up by its key,
+		// whether it is the default one or not.
+		if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
+			m, err := s.findDBRPMapping(ctx, tx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
+			if err != nil {
+				return err
+			}
+			if filter.OrganizationID == nil || *filter.OrganizationID == m.OrganizationID {
+				ms = append(ms, m)
+			}
+			return nil
+		}
+
+		return s.forEachDBRPMapping(ctx, tx, func(m *influxdb.DBRPMapping) bool {
+			if filter.Matches(m) {
+				ms = append(ms, m)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return ms, len(ms), nil
+}
+
+// forEachDBRPMapping will iterate through all dbrp mappings while fn returns true.
+func (s *Service) forEachDBRPMapping(ctx context.Context, tx Tx, fn func(*influxdb.DBRPMapping) bool) error {
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		m := &influxdb.DBRPMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return err
+		}
+		if !fn(m) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Create creates a new dbrp mapping. Creating a mapping identical to an existing one is not an error,
+// but creating a different mapping for the cluster, database and retention policy of another one is.
+func (s *Service) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	if err := m.Validate(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		existing, err := s.findDBRPMapping(ctx, tx, m.Cluster, m.Database, m.RetentionPolicy)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		if existing != nil && !existing.Equal(m) {
+			return ErrDBRPMappingExists
+		}
+		return s.putDBRPMapping(ctx, tx, m)
+	})
+}
+
+// PutDBRPMapping will put a dbrp mapping without any checks.
+func (s *Service) PutDBRPMapping(ctx context.Context, m *influxdb.DBRPMapping) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putDBRPMapping(ctx, tx, m)
+	})
+}
+
+func (s *Service) putDBRPMapping(ctx context.Context, tx Tx, m *influxdb.DBRPMapping) error {
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(dbrpMappingKey(m.Cluster, m.Database, m.RetentionPolicy), v)
+}
+
+// Delete removes the dbrp mapping for cluster, db and rp.
+// Deleting a mapping that does not exist is not an error.
+func (s *Service) Delete(ctx context.Context, cluster, db, rp string) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(dbrpMappingBucket)
+		if err != nil {
+			return err
+		}
+		err = b.Delete(dbrpMappingKey(cluster, db, rp))
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
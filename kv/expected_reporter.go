@@ -0,0 +1,281 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	expectedReporterBucket = []byte("expectedreportersv1")
+)
+
+var _ influxdb.ExpectedReporterService = (*Service)(nil)
+
+func (s *Service) initializeExpectedReporters(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(expectedReporterBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindExpectedReporterByID returns a single expected reporter by ID.
+func (s *Service) FindExpectedReporterByID(ctx context.Context, id influxdb.ID) (*influxdb.ExpectedReporter, error) {
+	var r *influxdb.ExpectedReporter
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		r, err = s.findExpectedReporterByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindExpectedReporterByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// FindExpectedReporters returns the expected reporters that match a filter.
+func (s *Service) FindExpectedReporters(ctx context.Context, filter influxdb.ExpectedReporterFilter) ([]*influxdb.ExpectedReporter, error) {
+	rs := []*influxdb.ExpectedReporter{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachExpectedReporter(ctx, tx, func(r *influxdb.ExpectedReporter) bool {
+			if filter.Matches(r) {
+				rs = append(rs, r)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindExpectedReporters,
+			Err: err,
+		}
+	}
+
+	return rs, nil
+}
+
+// forEachExpectedReporter will iterate through all expected reporters while fn returns true.
+func (s *Service) forEachExpectedReporter(ctx context.Context, tx Tx, fn func(*influxdb.ExpectedReporter) bool) error {
+	b, err := tx.Bucket(expectedReporterBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		r := &influxdb.ExpectedReporter{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		if !fn(r) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findExpectedReporterByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.ExpectedReporter, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(expectedReporterBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrExpectedReporterNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &influxdb.ExpectedReporter{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return r, nil
+}
+
+// CreateExpectedReporter creates a new expected reporter and sets r.ID with the new identifier.
+// The bucket of the expected reporter must exist in its organization.
+func (s *Service) CreateExpectedReporter(ctx context.Context, r *influxdb.ExpectedReporter) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+
+		b, err := s.findBucketByID(ctx, tx, r.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.OrganizationID != r.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "expected reporter bucket does not belong to its organization",
+			}
+		}
+		if err := s.checkExpectedReporterQuota(ctx, tx, r.OrgID); err != nil {
+			return err
+		}
+
+		r.ID = s.IDGenerator.ID()
+		return s.putExpectedReporter(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateExpectedReporter,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// checkExpectedReporterQuota returns an EForbidden error if the organization cannot have one more expected reporter.
+func (s *Service) checkExpectedReporterQuota(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	if s.AlertingQuota.MaxExpectedReporters <= 0 {
+		return nil
+	}
+	var count int
+	err := s.forEachExpectedReporter(ctx, tx, func(r *influxdb.ExpectedReporter) bool {
+		if r.OrgID == orgID {
+			count++
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return influxdb.CheckQuota(orgID, "expected reporters", count, s.AlertingQuota.MaxExpectedReporters)
+}
+
+// PutExpectedReporter creates an expected reporter from the provided struct, without generating a new ID.
+func (s *Service) PutExpectedReporter(ctx context.Context, r *influxdb.ExpectedReporter) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putExpectedReporter(ctx, tx, r)
+	})
+}
+
+func (s *Service) putExpectedReporter(ctx context.Context, tx Tx, r *influxdb.ExpectedReporter) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(expectedReporterBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(encID, v)
+}
+
+// UpdateExpectedReporter updates a single expected reporter with changeset.
+// Returns the new expected reporter state after update.
+func (s *Service) UpdateExpectedReporter(ctx context.Context, id influxdb.ID, upd influxdb.ExpectedReporterUpdate) (*influxdb.ExpectedReporter, error) {
+	var r *influxdb.ExpectedReporter
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		r, err = s.findExpectedReporterByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := upd.Apply(r); err != nil {
+			return err
+		}
+
+		return s.putExpectedReporter(ctx, tx, r)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateExpectedReporter,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// DeleteExpectedReporter removes an expected reporter by ID.
+func (s *Service) DeleteExpectedReporter(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findExpectedReporterByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.deleteExpectedReporter(ctx, tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteExpectedReporter,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteExpectedReporter(ctx context.Context, tx Tx, id influxdb.ID) error {
+	encID, err := id.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(expectedReporterBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Delete(encID)
+}
+
+// deleteBucketExpectedReporters removes the expected reporters of a deleted bucket.
+func (s *Service) deleteBucketExpectedReporters(ctx context.Context, tx Tx, bucketID influxdb.ID) error {
+	var ids []influxdb.ID
+	err := s.forEachExpectedReporter(ctx, tx, func(r *influxdb.ExpectedReporter) bool {
+		if r.BucketID == bucketID {
+			ids = append(ids, r.ID)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.deleteExpectedReporter(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
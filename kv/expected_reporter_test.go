@@ -0,0 +1,115 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltExpectedReporterService(t *testing.T) {
+	influxdbtesting.ExpectedReporterService(initBoltExpectedReporterService, t)
+}
+
+func TestInmemExpectedReporterService(t *testing.T) {
+	influxdbtesting.ExpectedReporterService(initInmemExpectedReporterService, t)
+}
+
+func initBoltExpectedReporterService(f influxdbtesting.ExpectedReporterFields, t *testing.T) (influxdb.ExpectedReporterService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initExpectedReporterService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemExpectedReporterService(f influxdbtesting.ExpectedReporterFields, t *testing.T) (influxdb.ExpectedReporterService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initExpectedReporterService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initExpectedReporterService(s kv.Store, f influxdbtesting.ExpectedReporterFields, t *testing.T) (influxdb.ExpectedReporterService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing expected reporter service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, b := range f.Buckets {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatalf("failed to populate buckets: %v", err)
+		}
+	}
+	for _, r := range f.ExpectedReporters {
+		if err := svc.PutExpectedReporter(ctx, r); err != nil {
+			t.Fatalf("failed to populate expected reporters: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
+
+func TestService_DeleteBucketDeletesExpectedReporters(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	var buckets []*influxdb.Bucket
+	for _, name := range []string{"fleet", "sensors"} {
+		b := &influxdb.Bucket{Name: name, OrganizationID: org.ID}
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		r := &influxdb.ExpectedReporter{OrgID: org.ID, BucketID: b.ID, TagKey: "host", TagValue: "server01", Interval: time.Minute}
+		if err := svc.CreateExpectedReporter(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	if err := svc.DeleteBucket(ctx, buckets[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := svc.FindExpectedReporters(ctx, influxdb.ExpectedReporterFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].BucketID != buckets[1].ID {
+		t.Errorf("expected only the reporter of the remaining bucket, got %+v", rs)
+	}
+}
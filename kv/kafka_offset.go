@@ -0,0 +1,93 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	kafkaOffsetsBucket = []byte("kafkaoffsetsv1")
+)
+
+func (s *Service) initializeKafkaOffsets(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(kafkaOffsetsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// kafkaOffsetsKey is the key of the offsets of a topic consumed by a group. Topic names
+// cannot contain slashes, so the key is unique even if the group contains some.
+func kafkaOffsetsKey(group, topic string) []byte {
+	return []byte(group + "/" + topic)
+}
+
+// FindKafkaOffsets returns the checkpointed offsets of the partitions of a topic consumed by a group,
+// by partition. The offset of a partition is the offset of the next message to consume.
+func (s *Service) FindKafkaOffsets(ctx context.Context, group, topic string) (map[int]int64, error) {
+	var offsets map[int]int64
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		offsets, err = s.findKafkaOffsets(ctx, tx, group, topic)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  "kv/findKafkaOffsets",
+			Err: err,
+		}
+	}
+	return offsets, nil
+}
+
+func (s *Service) findKafkaOffsets(ctx context.Context, tx Tx, group, topic string) (map[int]int64, error) {
+	b, err := tx.Bucket(kafkaOffsetsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make(map[int]int64)
+	v, err := b.Get(kafkaOffsetsKey(group, topic))
+	if IsNotFound(err) {
+		return offsets, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(v, &offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// PutKafkaOffsets checkpoints the offsets of partitions of a topic consumed by a group.
+// The offsets of the other partitions of the topic are kept.
+func (s *Service) PutKafkaOffsets(ctx context.Context, group, topic string, offsets map[int]int64) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		stored, err := s.findKafkaOffsets(ctx, tx, group, topic)
+		if err != nil {
+			return err
+		}
+		for partition, offset := range offsets {
+			stored[partition] = offset
+		}
+
+		v, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(kafkaOffsetsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(kafkaOffsetsKey(group, topic), v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  "kv/putKafkaOffsets",
+			Err: err,
+		}
+	}
+	return nil
+}
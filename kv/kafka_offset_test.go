@@ -0,0 +1,61 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_KafkaOffsets(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	offsets, err := svc.FindKafkaOffsets(ctx, "influxd", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(offsets) != 0 {
+		t.Fatalf("expected no offsets, got %v", offsets)
+	}
+
+	if err := svc.PutKafkaOffsets(ctx, "influxd", "metrics", map[int]int64{0: 10, 1: 20}); err != nil {
+		t.Fatal(err)
+	}
+	// The offsets of the other partitions are kept, and those of other groups and topics are distinct.
+	if err := svc.PutKafkaOffsets(ctx, "influxd", "metrics", map[int]int64{1: 25}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutKafkaOffsets(ctx, "influxd/b", "metrics", map[int]int64{0: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutKafkaOffsets(ctx, "influxd", "logs", map[int]int64{0: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		group, topic string
+		want         map[int]int64
+	}{
+		{group: "influxd", topic: "metrics", want: map[int]int64{0: 10, 1: 25}},
+		{group: "influxd/b", topic: "metrics", want: map[int]int64{0: 5}},
+		{group: "influxd", topic: "logs", want: map[int]int64{0: 7}},
+	} {
+		offsets, err := svc.FindKafkaOffsets(ctx, tt.group, tt.topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(offsets, tt.want) {
+			t.Errorf("offsets of topic %s of group %s: got %v, want %v", tt.topic, tt.group, offsets, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	lineageBucket = []byte("lineagerecordsv1")
+)
+
+var _ influxdb.LineageService = (*Service)(nil)
+
+func (s *Service) initializeLineage(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(lineageBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddLineageRecord records the lineage of a run, and sets r.ID with the new identifier.
+// Only the latest MaxTaskLineageRecords records of a task are kept.
+func (s *Service) AddLineageRecord(ctx context.Context, r *influxdb.LineageRecord) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if !r.OrgID.Valid() || !r.TaskID.Valid() || !r.Destination.Valid() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "lineage record orgID, taskID and destination are required",
+			}
+		}
+
+		r.ID = s.IDGenerator.ID()
+		if r.RecordedAt.IsZero() {
+			r.RecordedAt = s.time()
+		}
+		if r.Sources == nil {
+			r.Sources = []influxdb.ID{}
+		}
+
+		v, err := json.Marshal(r)
+		if err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		key, err := lineageRecordKey(r.TaskID, r.ID)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(lineageBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+
+		rs, err := s.findTaskLineageRecords(ctx, tx, r.TaskID)
+		if err != nil {
+			return err
+		}
+		if len(rs) <= influxdb.MaxTaskLineageRecords {
+			return nil
+		}
+		for _, r := range rs[influxdb.MaxTaskLineageRecords:] {
+			key, err := lineageRecordKey(r.TaskID, r.ID)
+			if err != nil {
+				return err
+			}
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpAddLineageRecord,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindLineageRecords returns the lineage records that match the filter, latest first.
+func (s *Service) FindLineageRecords(ctx context.Context, filter influxdb.LineageRecordFilter) ([]*influxdb.LineageRecord, error) {
+	var rs []*influxdb.LineageRecord
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		rs, err = s.findLineageRecords(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindLineageRecords,
+			Err: err,
+		}
+	}
+	return rs, nil
+}
+
+// FindLineage traverses the lineage of a bucket through the records of its organization.
+func (s *Service) FindLineage(ctx context.Context, filter influxdb.LineageFilter) (*influxdb.Lineage, error) {
+	if err := filter.Direction.Valid(); err != nil {
+		return nil, err
+	}
+
+	var l *influxdb.Lineage
+	err := s.kv.View(ctx, func(tx Tx) error {
+		rs, err := s.findLineageRecords(ctx, tx, influxdb.LineageRecordFilter{OrgID: &filter.OrgID})
+		if err != nil {
+			return err
+		}
+		l = influxdb.TraceLineage(rs, filter)
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindLineage,
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+// findLineageRecords returns the lineage records that match the filter, latest first.
+func (s *Service) findLineageRecords(ctx context.Context, tx Tx, filter influxdb.LineageRecordFilter) ([]*influxdb.LineageRecord, error) {
+	if filter.TaskID != nil {
+		rs, err := s.findTaskLineageRecords(ctx, tx, *filter.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		matched := rs[:0]
+		for _, r := range rs {
+			if filter.Matches(r) {
+				matched = append(matched, r)
+			}
+		}
+		return matched, nil
+	}
+
+	b, err := tx.Bucket(lineageBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := []*influxdb.LineageRecord{}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		r := &influxdb.LineageRecord{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return nil, err
+		}
+		if filter.Matches(r) {
+			rs = append(rs, r)
+		}
+	}
+	sortLineageRecords(rs)
+	return rs, nil
+}
+
+// findTaskLineageRecords returns the lineage records of a task, latest first.
+func (s *Service) findTaskLineageRecords(ctx context.Context, tx Tx, taskID influxdb.ID) ([]*influxdb.LineageRecord, error) {
+	prefix, err := taskID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(lineageBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := []*influxdb.LineageRecord{}
+	for k, v := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		r := &influxdb.LineageRecord{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	sortLineageRecords(rs)
+	return rs, nil
+}
+
+func sortLineageRecords(rs []*influxdb.LineageRecord) {
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].RecordedAt.Equal(rs[j].RecordedAt) {
+			return rs[i].ID > rs[j].ID
+		}
+		return rs[i].RecordedAt.After(rs[j].RecordedAt)
+	})
+}
+
+func lineageRecordKey(taskID, id influxdb.ID) ([]byte, error) {
+	encTaskID, err := taskID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(encTaskID, encID...), nil
+}
@@ -0,0 +1,62 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltLineageService(t *testing.T) {
+	influxdbtesting.LineageService(initBoltLineageService, t)
+}
+
+func TestInmemLineageService(t *testing.T) {
+	influxdbtesting.LineageService(initInmemLineageService, t)
+}
+
+func initBoltLineageService(f influxdbtesting.LineageFields, t *testing.T) (influxdb.LineageService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initLineageService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemLineageService(f influxdbtesting.LineageFields, t *testing.T) (influxdb.LineageService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initLineageService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initLineageService(s kv.Store, f influxdbtesting.LineageFields, t *testing.T) (influxdb.LineageService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+	svc.WithTime(f.NowFn)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing lineage service: %v", err)
+	}
+	for _, r := range f.LineageRecords {
+		if err := svc.AddLineageRecord(ctx, r); err != nil {
+			t.Fatalf("failed to populate lineage records: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
@@ -0,0 +1,282 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	measurementSchemaBucket = []byte("measurementschemasv1")
+)
+
+var _ influxdb.MeasurementSchemaService = (*Service)(nil)
+
+func (s *Service) initializeMeasurementSchemas(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(measurementSchemaBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindMeasurementSchemaByID returns a single measurement schema by ID.
+func (s *Service) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		ms, err = s.findMeasurementSchemaByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindMeasurementSchemaByID,
+			Err: err,
+		}
+	}
+	return ms, nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas that match a filter.
+func (s *Service) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	ss := []*influxdb.MeasurementSchema{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachMeasurementSchema(ctx, tx, func(ms *influxdb.MeasurementSchema) bool {
+			if filter.Matches(ms) {
+				ss = append(ss, ms)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindMeasurementSchemas,
+			Err: err,
+		}
+	}
+
+	return ss, nil
+}
+
+// forEachMeasurementSchema will iterate through all measurement schemas while fn returns true.
+func (s *Service) forEachMeasurementSchema(ctx context.Context, tx Tx, fn func(*influxdb.MeasurementSchema) bool) error {
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		ms := &influxdb.MeasurementSchema{}
+		if err := json.Unmarshal(v, ms); err != nil {
+			return err
+		}
+		if !fn(ms) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findMeasurementSchemaByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrMeasurementSchemaNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ms := &influxdb.MeasurementSchema{}
+	if err := json.Unmarshal(v, ms); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return ms, nil
+}
+
+// CreateMeasurementSchema creates a new measurement schema and sets ms.ID with the new identifier.
+// The bucket of the schema must be an explicit-schema bucket of its organization,
+// without another schema of the same measurement.
+func (s *Service) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := ms.Validate(); err != nil {
+			return err
+		}
+
+		b, err := s.findBucketByID(ctx, tx, ms.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.OrganizationID != ms.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "measurement schema bucket does not belong to its organization",
+			}
+		}
+		if b.SchemaType != influxdb.BucketSchemaTypeExplicit {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "measurement schema bucket does not have an explicit schema",
+			}
+		}
+
+		var exists bool
+		err = s.forEachMeasurementSchema(ctx, tx, func(other *influxdb.MeasurementSchema) bool {
+			exists = other.BucketID == ms.BucketID && other.Name == ms.Name
+			return !exists
+		})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "bucket already has a schema of measurement " + ms.Name,
+			}
+		}
+
+		ms.ID = s.IDGenerator.ID()
+		return s.putMeasurementSchema(ctx, tx, ms)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateMeasurementSchema,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutMeasurementSchema creates a measurement schema from the provided struct, without generating a new ID.
+func (s *Service) PutMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putMeasurementSchema(ctx, tx, ms)
+	})
+}
+
+func (s *Service) putMeasurementSchema(ctx context.Context, tx Tx, ms *influxdb.MeasurementSchema) error {
+	v, err := json.Marshal(ms)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := ms.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(encID, v)
+}
+
+// UpdateMeasurementSchema updates a single measurement schema with changeset.
+// Returns the new measurement schema state after update.
+func (s *Service) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		ms, err = s.findMeasurementSchemaByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := upd.Apply(ms); err != nil {
+			return err
+		}
+
+		return s.putMeasurementSchema(ctx, tx, ms)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateMeasurementSchema,
+			Err: err,
+		}
+	}
+	return ms, nil
+}
+
+// DeleteMeasurementSchema removes a measurement schema by ID.
+func (s *Service) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findMeasurementSchemaByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.deleteMeasurementSchema(ctx, tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteMeasurementSchema,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteMeasurementSchema(ctx context.Context, tx Tx, id influxdb.ID) error {
+	encID, err := id.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Delete(encID)
+}
+
+// deleteBucketMeasurementSchemas removes the measurement schemas of a deleted bucket.
+func (s *Service) deleteBucketMeasurementSchemas(ctx context.Context, tx Tx, bucketID influxdb.ID) error {
+	var ids []influxdb.ID
+	err := s.forEachMeasurementSchema(ctx, tx, func(ms *influxdb.MeasurementSchema) bool {
+		if ms.BucketID == bucketID {
+			ids = append(ids, ms.ID)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.deleteMeasurementSchema(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
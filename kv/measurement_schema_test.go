@@ -0,0 +1,155 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltMeasurementSchemaService(t *testing.T) {
+	influxdbtesting.MeasurementSchemaService(initBoltMeasurementSchemaService, t)
+}
+
+func TestInmemMeasurementSchemaService(t *testing.T) {
+	influxdbtesting.MeasurementSchemaService(initInmemMeasurementSchemaService, t)
+}
+
+func initBoltMeasurementSchemaService(f influxdbtesting.MeasurementSchemaFields, t *testing.T) (influxdb.MeasurementSchemaService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initMeasurementSchemaService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemMeasurementSchemaService(f influxdbtesting.MeasurementSchemaFields, t *testing.T) (influxdb.MeasurementSchemaService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initMeasurementSchemaService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initMeasurementSchemaService(s kv.Store, f influxdbtesting.MeasurementSchemaFields, t *testing.T) (influxdb.MeasurementSchemaService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing measurement schema service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, b := range f.Buckets {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatalf("failed to populate buckets: %v", err)
+		}
+	}
+	for _, ms := range f.MeasurementSchemas {
+		if err := svc.PutMeasurementSchema(ctx, ms); err != nil {
+			t.Fatalf("failed to populate measurement schemas: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
+
+func TestService_CreateBucketSchemaType(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &influxdb.Bucket{Name: "telemetry", OrganizationID: org.ID, SchemaType: influxdb.BucketSchemaTypeExplicit}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	found, err := svc.FindBucketByID(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.SchemaType != influxdb.BucketSchemaTypeExplicit {
+		t.Errorf("got schema type %q, want %q", found.SchemaType, influxdb.BucketSchemaTypeExplicit)
+	}
+
+	err = svc.CreateBucket(ctx, &influxdb.Bucket{Name: "other", OrganizationID: org.ID, SchemaType: "strict"})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid schema type error, got %v", err)
+	}
+}
+
+func TestService_DeleteBucketDeletesMeasurementSchemas(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	var buckets []*influxdb.Bucket
+	for _, name := range []string{"telemetry", "sensors"} {
+		b := &influxdb.Bucket{Name: name, OrganizationID: org.ID, SchemaType: influxdb.BucketSchemaTypeExplicit}
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		ms := &influxdb.MeasurementSchema{
+			OrgID:    org.ID,
+			BucketID: b.ID,
+			Name:     "cpu",
+			Fields:   []influxdb.MeasurementSchemaField{{Name: "usage", Type: influxdb.SchemaFieldTypeFloat}},
+		}
+		if err := svc.CreateMeasurementSchema(ctx, ms); err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	if err := svc.DeleteBucket(ctx, buckets[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].BucketID != buckets[1].ID {
+		t.Errorf("expected only the schema of the remaining bucket, got %+v", ss)
+	}
+}
@@ -0,0 +1,169 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	metadataBucket = []byte("metadatav1")
+)
+
+var _ influxdb.MetadataService = (*Service)(nil)
+
+func (s *Service) initializeMetadata(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(metadataBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindMetadata returns the metadata that match a filter.
+func (s *Service) FindMetadata(ctx context.Context, filter influxdb.MetadataFilter) ([]*influxdb.Metadata, error) {
+	ms := []*influxdb.Metadata{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachMetadata(ctx, tx, filter.ResourceID, func(m *influxdb.Metadata) bool {
+			if filter.Matches(m) {
+				ms = append(ms, m)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindMetadata,
+			Err: err,
+		}
+	}
+
+	return ms, nil
+}
+
+// forEachMetadata calls fn for every metadata entry, or only for the entries of resourceID if it is not nil.
+func (s *Service) forEachMetadata(ctx context.Context, tx Tx, resourceID *influxdb.ID, fn func(*influxdb.Metadata) bool) error {
+	b, err := tx.Bucket(metadataBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var prefix []byte
+	if resourceID != nil {
+		prefix, err = resourceID.Encode()
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		m := &influxdb.Metadata{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return err
+		}
+		if !fn(m) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// SetMetadata creates or replaces the metadata with m's key on m's resource.
+func (s *Service) SetMetadata(ctx context.Context, m *influxdb.Metadata) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.setMetadata(ctx, tx, m)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpSetMetadata,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) setMetadata(ctx context.Context, tx Tx, m *influxdb.Metadata) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	key, err := encodeMetadataKey(m.ResourceID, m.Key)
+	if err != nil {
+		return err
+	}
+
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	b, err := tx.Bucket(metadataBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, v)
+}
+
+// DeleteMetadata removes the metadata with key from a resource.
+func (s *Service) DeleteMetadata(ctx context.Context, resourceID influxdb.ID, key string) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteMetadata(ctx, tx, resourceID, key)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteMetadata,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteMetadata(ctx context.Context, tx Tx, resourceID influxdb.ID, key string) error {
+	k, err := encodeMetadataKey(resourceID, key)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(metadataBucket)
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.Get(k); IsNotFound(err) {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrMetadataNotFound,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return b.Delete(k)
+}
+
+func encodeMetadataKey(resourceID influxdb.ID, k string) ([]byte, error) {
+	buf, err := resourceID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	key := make([]byte, 0, influxdb.IDLength+len(k))
+	key = append(key, buf...)
+	key = append(key, k...)
+
+	return key, nil
+}
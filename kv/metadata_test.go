@@ -0,0 +1,66 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltMetadataService(t *testing.T) {
+	influxdbtesting.MetadataService(initBoltMetadataService, t)
+}
+
+func TestInmemMetadataService(t *testing.T) {
+	influxdbtesting.MetadataService(initInmemMetadataService, t)
+}
+
+func initBoltMetadataService(f influxdbtesting.MetadataServiceFields, t *testing.T) (influxdb.MetadataService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initMetadataService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemMetadataService(f influxdbtesting.MetadataServiceFields, t *testing.T) (influxdb.MetadataService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initMetadataService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initMetadataService(s kv.Store, f influxdbtesting.MetadataServiceFields, t *testing.T) (influxdb.MetadataService, string, func()) {
+	svc := kv.NewService(s)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing metadata service: %v", err)
+	}
+	for _, m := range f.Metadata {
+		if err := svc.SetMetadata(ctx, m); err != nil {
+			t.Fatalf("failed to populate metadata: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {
+		for _, m := range f.Metadata {
+			if err := svc.DeleteMetadata(ctx, m.ResourceID, m.Key); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+				t.Logf("failed to remove metadata: %v", err)
+			}
+		}
+	}
+}
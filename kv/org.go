@@ -250,6 +250,16 @@ func (s *Service) createOrganization(ctx context.Context, tx Tx, o *influxdb.Org
 		return err
 	}
 
+	if _, err := o.Location(); err != nil {
+		return err
+	}
+
+	if o.BucketPolicy != nil {
+		if err := o.BucketPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
 	o.ID = s.IDGenerator.ID()
 	if err := s.appendOrganizationEventToLog(ctx, tx, o.ID, organizationCreatedEvent); err != nil {
 		return &influxdb.Error{
@@ -267,6 +277,12 @@ func (s *Service) createOrganization(ctx context.Context, tx Tx, o *influxdb.Org
 
 // PutOrganization will put a organization without setting an ID.
 func (s *Service) PutOrganization(ctx context.Context, o *influxdb.Organization) error {
+	if o.BucketPolicy != nil {
+		if err := o.BucketPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	return s.kv.Update(ctx, func(tx Tx) error {
 		if pe := s.putOrganization(ctx, tx, o); pe != nil {
@@ -394,6 +410,39 @@ func (s *Service) updateOrganization(ctx context.Context, tx Tx, id influxdb.ID,
 		o.Name = *upd.Name
 	}
 
+	switch {
+	case upd.BucketPolicy == nil:
+	case *upd.BucketPolicy == influxdb.BucketPolicy{}:
+		// An empty policy removes the policy of the organization.
+		o.BucketPolicy = nil
+	default:
+		if err := upd.BucketPolicy.Validate(); err != nil {
+			return nil, err
+		}
+		o.BucketPolicy = upd.BucketPolicy
+	}
+
+	if upd.InactivityPolicy != nil {
+		if err := upd.InactivityPolicy.Validate(); err != nil {
+			return nil, err
+		}
+		o.InactivityPolicy = upd.InactivityPolicy
+	}
+
+	if upd.EgressPolicy != nil {
+		if err := upd.EgressPolicy.Validate(); err != nil {
+			return nil, err
+		}
+		o.EgressPolicy = upd.EgressPolicy
+	}
+
+	if upd.Timezone != nil {
+		if _, err := influxdb.LoadTimezone(*upd.Timezone); err != nil {
+			return nil, err
+		}
+		o.Timezone = *upd.Timezone
+	}
+
 	if err := s.appendOrganizationEventToLog(ctx, tx, o.ID, organizationUpdatedEvent); err != nil {
 		return nil, &influxdb.Error{
 			Err: err,
@@ -27,6 +27,9 @@ type Service struct {
 	TokenGenerator influxdb.TokenGenerator
 	Hash           Crypt
 
+	// AlertingQuota limits the task webhooks and expected reporters created in every organization.
+	AlertingQuota influxdb.AlertingQuota
+
 	time func() time.Time
 }
 
@@ -49,6 +52,10 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeActivity(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeDocuments(ctx, tx); err != nil {
 			return err
 		}
@@ -57,6 +64,10 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeDBRPMappings(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeDashboards(ctx, tx); err != nil {
 			return err
 		}
@@ -69,6 +80,54 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeMetadata(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeAnnouncements(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeExpectedReporters(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeTaskWebhooks(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeTaskScripts(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeTaskDependencies(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeLineage(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeKafkaOffsets(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeCORSPolicy(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeBucketLifecyclePolicies(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeSQLConnections(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeMeasurementSchemas(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeOnboarding(ctx, tx); err != nil {
 			return err
 		}
@@ -0,0 +1,254 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	sqlConnectionBucket = []byte("sqlconnectionsv1")
+)
+
+var _ influxdb.SQLConnectionService = (*Service)(nil)
+
+func (s *Service) initializeSQLConnections(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(sqlConnectionBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindSQLConnectionByID returns a single SQL connection by ID.
+func (s *Service) FindSQLConnectionByID(ctx context.Context, id influxdb.ID) (*influxdb.SQLConnection, error) {
+	var c *influxdb.SQLConnection
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		c, err = s.findSQLConnectionByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindSQLConnectionByID,
+			Err: err,
+		}
+	}
+	return c, nil
+}
+
+// FindSQLConnections returns the SQL connections that match a filter.
+func (s *Service) FindSQLConnections(ctx context.Context, filter influxdb.SQLConnectionFilter) ([]*influxdb.SQLConnection, error) {
+	cs := []*influxdb.SQLConnection{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachSQLConnection(ctx, tx, func(c *influxdb.SQLConnection) bool {
+			if filter.Matches(c) {
+				cs = append(cs, c)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindSQLConnections,
+			Err: err,
+		}
+	}
+
+	return cs, nil
+}
+
+// forEachSQLConnection will iterate through all SQL connections while fn returns true.
+func (s *Service) forEachSQLConnection(ctx context.Context, tx Tx, fn func(*influxdb.SQLConnection) bool) error {
+	b, err := tx.Bucket(sqlConnectionBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		c := &influxdb.SQLConnection{}
+		if err := json.Unmarshal(v, c); err != nil {
+			return err
+		}
+		if !fn(c) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findSQLConnectionByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.SQLConnection, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(sqlConnectionBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrSQLConnectionNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &influxdb.SQLConnection{}
+	if err := json.Unmarshal(v, c); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return c, nil
+}
+
+// uniqueSQLConnectionName returns an EConflict error if another SQL connection of the organization of c has its name.
+func (s *Service) uniqueSQLConnectionName(ctx context.Context, tx Tx, c *influxdb.SQLConnection) error {
+	var taken bool
+	err := s.forEachSQLConnection(ctx, tx, func(other *influxdb.SQLConnection) bool {
+		taken = other.ID != c.ID && other.OrgID == c.OrgID && other.Name == c.Name
+		return !taken
+	})
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("sql connection with name %s already exists", c.Name),
+		}
+	}
+	return nil
+}
+
+// CreateSQLConnection creates a new SQL connection and sets c.ID with the new identifier.
+// The name of the SQL connection must be unique within its organization.
+func (s *Service) CreateSQLConnection(ctx context.Context, c *influxdb.SQLConnection) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if _, err := s.findOrganizationByID(ctx, tx, c.OrgID); err != nil {
+			return err
+		}
+		if err := s.uniqueSQLConnectionName(ctx, tx, c); err != nil {
+			return err
+		}
+
+		c.ID = s.IDGenerator.ID()
+		return s.putSQLConnection(ctx, tx, c)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateSQLConnection,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutSQLConnection creates a SQL connection from the provided struct, without generating a new ID.
+func (s *Service) PutSQLConnection(ctx context.Context, c *influxdb.SQLConnection) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putSQLConnection(ctx, tx, c)
+	})
+}
+
+func (s *Service) putSQLConnection(ctx context.Context, tx Tx, c *influxdb.SQLConnection) error {
+	v, err := json.Marshal(c)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := c.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(sqlConnectionBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(encID, v)
+}
+
+// UpdateSQLConnection updates a single SQL connection with changeset.
+// Returns the new SQL connection state after update.
+func (s *Service) UpdateSQLConnection(ctx context.Context, id influxdb.ID, upd influxdb.SQLConnectionUpdate) (*influxdb.SQLConnection, error) {
+	var c *influxdb.SQLConnection
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		c, err = s.findSQLConnectionByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := upd.Apply(c); err != nil {
+			return err
+		}
+		if err := s.uniqueSQLConnectionName(ctx, tx, c); err != nil {
+			return err
+		}
+
+		return s.putSQLConnection(ctx, tx, c)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateSQLConnection,
+			Err: err,
+		}
+	}
+	return c, nil
+}
+
+// DeleteSQLConnection removes a SQL connection by ID. The secret of its data source name is kept.
+func (s *Service) DeleteSQLConnection(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findSQLConnectionByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(sqlConnectionBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Delete(encID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteSQLConnection,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltSQLConnectionService(t *testing.T) {
+	influxdbtesting.SQLConnectionService(initBoltSQLConnectionService, t)
+}
+
+func TestInmemSQLConnectionService(t *testing.T) {
+	influxdbtesting.SQLConnectionService(initInmemSQLConnectionService, t)
+}
+
+func initBoltSQLConnectionService(f influxdbtesting.SQLConnectionFields, t *testing.T) (influxdb.SQLConnectionService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initSQLConnectionService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemSQLConnectionService(f influxdbtesting.SQLConnectionFields, t *testing.T) (influxdb.SQLConnectionService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initSQLConnectionService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initSQLConnectionService(s kv.Store, f influxdbtesting.SQLConnectionFields, t *testing.T) (influxdb.SQLConnectionService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing sql connection service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, c := range f.SQLConnections {
+		if err := svc.PutSQLConnection(ctx, c); err != nil {
+			t.Fatalf("failed to populate sql connections: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
@@ -0,0 +1,140 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	taskDependencyBucket = []byte("taskdependenciesv1")
+)
+
+var _ influxdb.TaskDependencyService = (*Service)(nil)
+
+func (s *Service) initializeTaskDependencies(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(taskDependencyBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindTaskDependencies returns the dependencies of the tasks that match a filter.
+func (s *Service) FindTaskDependencies(ctx context.Context, filter influxdb.TaskDependencyFilter) ([]*influxdb.TaskDependencies, error) {
+	ds := []*influxdb.TaskDependencies{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachTaskDependencies(ctx, tx, func(d *influxdb.TaskDependencies) bool {
+			if filter.Matches(d) {
+				ds = append(ds, d)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskDependencies,
+			Err: err,
+		}
+	}
+
+	return ds, nil
+}
+
+// forEachTaskDependencies will iterate through the dependencies of all tasks while fn returns true.
+func (s *Service) forEachTaskDependencies(ctx context.Context, tx Tx, fn func(*influxdb.TaskDependencies) bool) error {
+	b, err := tx.Bucket(taskDependencyBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		d := &influxdb.TaskDependencies{}
+		if err := json.Unmarshal(v, d); err != nil {
+			return err
+		}
+		if !fn(d) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// PutTaskDependencies replaces the dependencies of the task d.TaskID and sets d.UpdatedAt.
+func (s *Service) PutTaskDependencies(ctx context.Context, d *influxdb.TaskDependencies) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if !d.OrgID.Valid() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "task dependencies orgID is required",
+			}
+		}
+		encID, err := d.TaskID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		if d.Dependencies == nil {
+			d.Dependencies = []influxdb.TaskDependency{}
+		}
+		d.UpdatedAt = s.time()
+		v, err := json.Marshal(d)
+		if err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		b, err := tx.Bucket(taskDependencyBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(encID, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpPutTaskDependencies,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteTaskDependencies removes the dependencies of a task. Deleting the dependencies of a task
+// that has none recorded is not an error, so that tasks created before their recording can be deleted.
+func (s *Service) DeleteTaskDependencies(ctx context.Context, taskID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		encID, err := taskID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(taskDependencyBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Delete(encID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteTaskDependencies,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltTaskDependencyService(t *testing.T) {
+	influxdbtesting.TaskDependencyService(initBoltTaskDependencyService, t)
+}
+
+func TestInmemTaskDependencyService(t *testing.T) {
+	influxdbtesting.TaskDependencyService(initInmemTaskDependencyService, t)
+}
+
+func initBoltTaskDependencyService(f influxdbtesting.TaskDependencyFields, t *testing.T) (influxdb.TaskDependencyService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskDependencyService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemTaskDependencyService(f influxdbtesting.TaskDependencyFields, t *testing.T) (influxdb.TaskDependencyService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskDependencyService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initTaskDependencyService(s kv.Store, f influxdbtesting.TaskDependencyFields, t *testing.T) (influxdb.TaskDependencyService, string, func()) {
+	svc := kv.NewService(s)
+	svc.WithTime(f.NowFn)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task dependency service: %v", err)
+	}
+	for _, d := range f.TaskDependencies {
+		if err := svc.PutTaskDependencies(ctx, d); err != nil {
+			t.Fatalf("failed to populate task dependencies: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
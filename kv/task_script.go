@@ -0,0 +1,296 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	taskScriptBucket = []byte("taskscriptsv1")
+)
+
+var _ influxdb.TaskScriptService = (*Service)(nil)
+
+func (s *Service) initializeTaskScripts(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(taskScriptBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindTaskScript returns a version of the named script of an organization, or its latest version if version is 0.
+func (s *Service) FindTaskScript(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+	var ts *influxdb.TaskScript
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		ts, err = s.findTaskScript(ctx, tx, orgID, name, version)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskScript,
+			Err: err,
+		}
+	}
+	return ts, nil
+}
+
+func (s *Service) findTaskScript(ctx context.Context, tx Tx, orgID influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+	versions, err := s.findTaskScriptVersions(ctx, tx, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ts := range versions {
+		if version == 0 || ts.Version == version {
+			return ts, nil
+		}
+	}
+	return nil, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrTaskScriptNotFound,
+	}
+}
+
+// FindTaskScripts returns the latest version of the scripts that match a filter, sorted by name.
+func (s *Service) FindTaskScripts(ctx context.Context, filter influxdb.TaskScriptFilter) ([]*influxdb.TaskScript, error) {
+	tss := []*influxdb.TaskScript{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var prefix []byte
+		if filter.OrgID != nil {
+			var err error
+			if prefix, err = filter.OrgID.Encode(); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Err:  err,
+				}
+			}
+		}
+
+		// Versions of a script are stored in increasing order, so the last one seen is the latest.
+		var latest *influxdb.TaskScript
+		err := s.forEachTaskScript(ctx, tx, prefix, func(ts *influxdb.TaskScript) bool {
+			if filter.Name != nil && ts.Name != *filter.Name {
+				return true
+			}
+			if latest != nil && (latest.OrgID != ts.OrgID || latest.Name != ts.Name) {
+				tss = append(tss, latest)
+			}
+			latest = ts
+			return true
+		})
+		if latest != nil {
+			tss = append(tss, latest)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskScripts,
+			Err: err,
+		}
+	}
+	return tss, nil
+}
+
+// FindTaskScriptVersions returns every version of the named script of an organization, latest first.
+func (s *Service) FindTaskScriptVersions(ctx context.Context, orgID influxdb.ID, name string) ([]*influxdb.TaskScript, error) {
+	var tss []*influxdb.TaskScript
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		tss, err = s.findTaskScriptVersions(ctx, tx, orgID, name)
+		if err != nil {
+			return err
+		}
+		if len(tss) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrTaskScriptNotFound,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskScriptVersions,
+			Err: err,
+		}
+	}
+	return tss, nil
+}
+
+// findTaskScriptVersions returns the versions of the named script of an organization, latest first.
+func (s *Service) findTaskScriptVersions(ctx context.Context, tx Tx, orgID influxdb.ID, name string) ([]*influxdb.TaskScript, error) {
+	prefix, err := taskScriptNamePrefix(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	tss := []*influxdb.TaskScript{}
+	err = s.forEachTaskScript(ctx, tx, prefix, func(ts *influxdb.TaskScript) bool {
+		tss = append([]*influxdb.TaskScript{ts}, tss...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tss, nil
+}
+
+// forEachTaskScript will iterate through the task scripts with keys starting with prefix while fn returns true,
+// ordered by organization, name and version.
+func (s *Service) forEachTaskScript(ctx context.Context, tx Tx, prefix []byte, fn func(*influxdb.TaskScript) bool) error {
+	b, err := tx.Bucket(taskScriptBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var k, v []byte
+	if prefix == nil {
+		k, v = cur.First()
+	} else {
+		k, v = cur.Seek(prefix)
+	}
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		ts := &influxdb.TaskScript{}
+		if err := json.Unmarshal(v, ts); err != nil {
+			return err
+		}
+		if !fn(ts) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// CreateTaskScript stores ts as a new version of the script named ts.Name,
+// and sets ts.ID, ts.Version and ts.CreatedAt.
+func (s *Service) CreateTaskScript(ctx context.Context, ts *influxdb.TaskScript) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := ts.Validate(); err != nil {
+			return err
+		}
+
+		versions, err := s.findTaskScriptVersions(ctx, tx, ts.OrgID, ts.Name)
+		if err != nil {
+			return err
+		}
+
+		ts.ID = s.IDGenerator.ID()
+		ts.Version = 1
+		if len(versions) > 0 {
+			ts.Version = versions[0].Version + 1
+		}
+		ts.CreatedAt = s.time()
+		return s.putTaskScript(ctx, tx, ts)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateTaskScript,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutTaskScript stores a version of a task script from the provided struct, without generating a new ID or version.
+func (s *Service) PutTaskScript(ctx context.Context, ts *influxdb.TaskScript) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putTaskScript(ctx, tx, ts)
+	})
+}
+
+func (s *Service) putTaskScript(ctx context.Context, tx Tx, ts *influxdb.TaskScript) error {
+	v, err := json.Marshal(ts)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	key, err := taskScriptKey(ts.OrgID, ts.Name, ts.Version)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(taskScriptBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, v)
+}
+
+// DeleteTaskScript removes every version of the named script of an organization.
+func (s *Service) DeleteTaskScript(ctx context.Context, orgID influxdb.ID, name string) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		versions, err := s.findTaskScriptVersions(ctx, tx, orgID, name)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrTaskScriptNotFound,
+			}
+		}
+
+		b, err := tx.Bucket(taskScriptBucket)
+		if err != nil {
+			return err
+		}
+		for _, ts := range versions {
+			key, err := taskScriptKey(ts.OrgID, ts.Name, ts.Version)
+			if err != nil {
+				return err
+			}
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteTaskScript,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// taskScriptNamePrefix returns the prefix of the keys of the versions of the named script of an organization:
+// the encoded organization ID, followed by the name and a zero byte, which names cannot contain.
+func taskScriptNamePrefix(orgID influxdb.ID, name string) ([]byte, error) {
+	encOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	prefix := append(encOrgID, name...)
+	return append(prefix, 0), nil
+}
+
+// taskScriptKey returns the key of a version of a task script, that is its name prefix followed by the big-endian version,
+// so that the versions of a script are ordered.
+func taskScriptKey(orgID influxdb.ID, name string, version int) ([]byte, error) {
+	prefix, err := taskScriptNamePrefix(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], uint64(version))
+	return append(prefix, v[:]...), nil
+}
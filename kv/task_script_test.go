@@ -0,0 +1,62 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltTaskScriptService(t *testing.T) {
+	influxdbtesting.TaskScriptService(initBoltTaskScriptService, t)
+}
+
+func TestInmemTaskScriptService(t *testing.T) {
+	influxdbtesting.TaskScriptService(initInmemTaskScriptService, t)
+}
+
+func initBoltTaskScriptService(f influxdbtesting.TaskScriptFields, t *testing.T) (influxdb.TaskScriptService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskScriptService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemTaskScriptService(f influxdbtesting.TaskScriptFields, t *testing.T) (influxdb.TaskScriptService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskScriptService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initTaskScriptService(s kv.Store, f influxdbtesting.TaskScriptFields, t *testing.T) (influxdb.TaskScriptService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+	svc.WithTime(f.NowFn)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task script service: %v", err)
+	}
+	for _, ts := range f.TaskScripts {
+		if err := svc.PutTaskScript(ctx, ts); err != nil {
+			t.Fatalf("failed to populate task scripts: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
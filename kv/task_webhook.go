@@ -0,0 +1,404 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	taskWebhookBucket         = []byte("taskwebhooksv1")
+	taskWebhookDeliveryBucket = []byte("taskwebhookdeliveriesv1")
+)
+
+var _ influxdb.TaskWebhookService = (*Service)(nil)
+
+func (s *Service) initializeTaskWebhooks(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(taskWebhookBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(taskWebhookDeliveryBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindTaskWebhookByID returns a single task webhook by ID.
+func (s *Service) FindTaskWebhookByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskWebhook, error) {
+	var w *influxdb.TaskWebhook
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		w, err = s.findTaskWebhookByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskWebhookByID,
+			Err: err,
+		}
+	}
+	return w, nil
+}
+
+// FindTaskWebhooks returns the task webhooks that match a filter.
+func (s *Service) FindTaskWebhooks(ctx context.Context, filter influxdb.TaskWebhookFilter) ([]*influxdb.TaskWebhook, error) {
+	ws := []*influxdb.TaskWebhook{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachTaskWebhook(ctx, tx, func(w *influxdb.TaskWebhook) bool {
+			if filter.Matches(w) {
+				ws = append(ws, w)
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskWebhooks,
+			Err: err,
+		}
+	}
+
+	return ws, nil
+}
+
+// forEachTaskWebhook will iterate through all task webhooks while fn returns true.
+func (s *Service) forEachTaskWebhook(ctx context.Context, tx Tx, fn func(*influxdb.TaskWebhook) bool) error {
+	b, err := tx.Bucket(taskWebhookBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		w := &influxdb.TaskWebhook{}
+		if err := json.Unmarshal(v, w); err != nil {
+			return err
+		}
+		if !fn(w) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findTaskWebhookByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.TaskWebhook, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(taskWebhookBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrTaskWebhookNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := &influxdb.TaskWebhook{}
+	if err := json.Unmarshal(v, w); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return w, nil
+}
+
+// CreateTaskWebhook creates a new task webhook and sets w.ID with the new identifier.
+func (s *Service) CreateTaskWebhook(ctx context.Context, w *influxdb.TaskWebhook) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+		if err := s.checkTaskWebhookQuota(ctx, tx, w.OrgID); err != nil {
+			return err
+		}
+
+		w.ID = s.IDGenerator.ID()
+		return s.putTaskWebhook(ctx, tx, w)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateTaskWebhook,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// checkTaskWebhookQuota returns an EForbidden error if the organization cannot have one more task webhook.
+func (s *Service) checkTaskWebhookQuota(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	if s.AlertingQuota.MaxTaskWebhooks <= 0 {
+		return nil
+	}
+	var count int
+	err := s.forEachTaskWebhook(ctx, tx, func(w *influxdb.TaskWebhook) bool {
+		if w.OrgID == orgID {
+			count++
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return influxdb.CheckQuota(orgID, "task webhooks", count, s.AlertingQuota.MaxTaskWebhooks)
+}
+
+// PutTaskWebhook creates a task webhook from the provided struct, without generating a new ID.
+func (s *Service) PutTaskWebhook(ctx context.Context, w *influxdb.TaskWebhook) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putTaskWebhook(ctx, tx, w)
+	})
+}
+
+func (s *Service) putTaskWebhook(ctx context.Context, tx Tx, w *influxdb.TaskWebhook) error {
+	v, err := json.Marshal(w)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := w.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(taskWebhookBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(encID, v)
+}
+
+// UpdateTaskWebhook updates a single task webhook with changeset.
+// Returns the new task webhook state after update.
+func (s *Service) UpdateTaskWebhook(ctx context.Context, id influxdb.ID, upd influxdb.TaskWebhookUpdate) (*influxdb.TaskWebhook, error) {
+	var w *influxdb.TaskWebhook
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		w, err = s.findTaskWebhookByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := upd.Apply(w); err != nil {
+			return err
+		}
+
+		return s.putTaskWebhook(ctx, tx, w)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateTaskWebhook,
+			Err: err,
+		}
+	}
+	return w, nil
+}
+
+// DeleteTaskWebhook removes a task webhook by ID, along with its delivery log.
+func (s *Service) DeleteTaskWebhook(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findTaskWebhookByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(taskWebhookBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(encID); err != nil {
+			return err
+		}
+
+		ds, err := s.findTaskWebhookDeliveries(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return s.deleteTaskWebhookDeliveries(ctx, tx, ds)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteTaskWebhook,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// AddTaskWebhookDelivery records a delivery attempt in the delivery log of its webhook, and sets d.ID with the new identifier.
+// Only the latest MaxTaskWebhookDeliveries deliveries of a webhook are kept.
+func (s *Service) AddTaskWebhookDelivery(ctx context.Context, d *influxdb.TaskWebhookDelivery) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findTaskWebhookByID(ctx, tx, d.WebhookID); err != nil {
+			return err
+		}
+
+		d.ID = s.IDGenerator.ID()
+		if d.Time.IsZero() {
+			d.Time = s.time()
+		}
+
+		v, err := json.Marshal(d)
+		if err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		key, err := taskWebhookDeliveryKey(d.WebhookID, d.ID)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(taskWebhookDeliveryBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+
+		ds, err := s.findTaskWebhookDeliveries(ctx, tx, d.WebhookID)
+		if err != nil {
+			return err
+		}
+		if len(ds) <= influxdb.MaxTaskWebhookDeliveries {
+			return nil
+		}
+		return s.deleteTaskWebhookDeliveries(ctx, tx, ds[influxdb.MaxTaskWebhookDeliveries:])
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpAddTaskWebhookDelivery,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindTaskWebhookDeliveries returns the delivery log of a task webhook, latest first.
+func (s *Service) FindTaskWebhookDeliveries(ctx context.Context, webhookID influxdb.ID) ([]*influxdb.TaskWebhookDelivery, error) {
+	var ds []*influxdb.TaskWebhookDelivery
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findTaskWebhookByID(ctx, tx, webhookID); err != nil {
+			return err
+		}
+
+		var err error
+		ds, err = s.findTaskWebhookDeliveries(ctx, tx, webhookID)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindTaskWebhookDeliveries,
+			Err: err,
+		}
+	}
+	return ds, nil
+}
+
+// findTaskWebhookDeliveries returns the deliveries of a task webhook, latest first.
+func (s *Service) findTaskWebhookDeliveries(ctx context.Context, tx Tx, webhookID influxdb.ID) ([]*influxdb.TaskWebhookDelivery, error) {
+	prefix, err := webhookID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(taskWebhookDeliveryBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	ds := []*influxdb.TaskWebhookDelivery{}
+	for k, v := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		d := &influxdb.TaskWebhookDelivery{}
+		if err := json.Unmarshal(v, d); err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].Time.Equal(ds[j].Time) {
+			return ds[i].ID > ds[j].ID
+		}
+		return ds[i].Time.After(ds[j].Time)
+	})
+	return ds, nil
+}
+
+func (s *Service) deleteTaskWebhookDeliveries(ctx context.Context, tx Tx, ds []*influxdb.TaskWebhookDelivery) error {
+	b, err := tx.Bucket(taskWebhookDeliveryBucket)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range ds {
+		key, err := taskWebhookDeliveryKey(d.WebhookID, d.ID)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func taskWebhookDeliveryKey(webhookID, id influxdb.ID) ([]byte, error) {
+	encWebhookID, err := webhookID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(encWebhookID, encID...), nil
+}
@@ -0,0 +1,131 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltTaskWebhookService(t *testing.T) {
+	influxdbtesting.TaskWebhookService(initBoltTaskWebhookService, t)
+}
+
+func TestInmemTaskWebhookService(t *testing.T) {
+	influxdbtesting.TaskWebhookService(initInmemTaskWebhookService, t)
+}
+
+func initBoltTaskWebhookService(f influxdbtesting.TaskWebhookFields, t *testing.T) (influxdb.TaskWebhookService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskWebhookService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemTaskWebhookService(f influxdbtesting.TaskWebhookFields, t *testing.T) (influxdb.TaskWebhookService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initTaskWebhookService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initTaskWebhookService(s kv.Store, f influxdbtesting.TaskWebhookFields, t *testing.T) (influxdb.TaskWebhookService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task webhook service: %v", err)
+	}
+	for _, w := range f.TaskWebhooks {
+		if err := svc.PutTaskWebhook(ctx, w); err != nil {
+			t.Fatalf("failed to populate task webhooks: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {}
+}
+
+func TestService_AlertingQuota(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	svc.AlertingQuota = influxdb.AlertingQuota{MaxTaskWebhooks: 2, MaxExpectedReporters: 1}
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var orgs []*influxdb.Organization
+	var buckets []*influxdb.Bucket
+	for _, name := range []string{"org1", "org2"} {
+		org := &influxdb.Organization{Name: name}
+		if err := svc.CreateOrganization(ctx, org); err != nil {
+			t.Fatal(err)
+		}
+		b := &influxdb.Bucket{Name: "telemetry", OrganizationID: org.ID}
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		orgs = append(orgs, org)
+		buckets = append(buckets, b)
+	}
+
+	webhook := func(org *influxdb.Organization) *influxdb.TaskWebhook {
+		return &influxdb.TaskWebhook{
+			TaskID: 1,
+			OrgID:  org.ID,
+			URL:    "https://hooks.example.com",
+			Events: []string{influxdb.TaskWebhookEventFailure},
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.CreateTaskWebhook(ctx, webhook(orgs[0])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.CreateTaskWebhook(ctx, webhook(orgs[0])); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected the task webhook quota to be exceeded, got %v", err)
+	}
+	if err := svc.CreateTaskWebhook(ctx, webhook(orgs[1])); err != nil {
+		t.Errorf("expected the quota to be per organization: %v", err)
+	}
+
+	reporter := func(i int) *influxdb.ExpectedReporter {
+		return &influxdb.ExpectedReporter{
+			OrgID:    orgs[i].ID,
+			BucketID: buckets[i].ID,
+			TagKey:   "host",
+			TagValue: "a",
+			Interval: time.Minute,
+		}
+	}
+	if err := svc.CreateExpectedReporter(ctx, reporter(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateExpectedReporter(ctx, reporter(0)); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected the expected reporter quota to be exceeded, got %v", err)
+	}
+	if err := svc.CreateExpectedReporter(ctx, reporter(1)); err != nil {
+		t.Errorf("expected the quota to be per organization: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// LimitsSimulationService evaluates the recent traffic of the server against proposed limits,
+// to tune the limits before enforcing them.
+type LimitsSimulationService interface {
+	// SimulateLimits reports what the limits proposed would have rejected of the recent traffic.
+	SimulateLimits(ctx context.Context, limits LimitsProposal) (*LimitsSimulation, error)
+}
+
+// LimitsProposal are limits proposed for the server, in place of those its flags set. A zero limit is not enforced.
+type LimitsProposal struct {
+	// TokenPointsPerSecond and TokenBytesPerSecond limit the rate of the writes of each authorization token.
+	TokenPointsPerSecond int `json:"tokenPointsPerSecond"`
+	TokenBytesPerSecond  int `json:"tokenBytesPerSecond"`
+
+	// OrgPointsPerSecond and OrgBytesPerSecond limit the rate of the writes to the buckets of each organization.
+	OrgPointsPerSecond int `json:"orgPointsPerSecond"`
+	OrgBytesPerSecond  int `json:"orgBytesPerSecond"`
+
+	// QueryOrgConcurrency limits the number of queries of each organization running at once.
+	QueryOrgConcurrency int `json:"queryOrgConcurrency"`
+
+	// BucketSeries limits the number of series of each bucket.
+	BucketSeries int `json:"bucketSeries"`
+}
+
+// Validate returns an error if a limit of the proposal is negative.
+func (p LimitsProposal) Validate() error {
+	for _, l := range []int{
+		p.TokenPointsPerSecond,
+		p.TokenBytesPerSecond,
+		p.OrgPointsPerSecond,
+		p.OrgBytesPerSecond,
+		p.QueryOrgConcurrency,
+		p.BucketSeries,
+	} {
+		if l < 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "proposed limits must not be negative",
+			}
+		}
+	}
+	return nil
+}
+
+// LimitsSimulation reports the writes and the queries of the recent traffic of the server, since Since,
+// that the limits proposed would have rejected, and the buckets with more series than they allow.
+//
+// The writes and the queries rejected by the limits enforced are evaluated too, since their clients
+// may have retried them.
+type LimitsSimulation struct {
+	Limits LimitsProposal `json:"limits"`
+	Since  time.Time      `json:"since"`
+	Until  time.Time      `json:"until"`
+
+	Writes          int `json:"writes"`
+	Points          int `json:"points"`
+	RejectedWrites  int `json:"rejectedWrites"`
+	RejectedPoints  int `json:"rejectedPoints"`
+	Queries         int `json:"queries"`
+	RejectedQueries int `json:"rejectedQueries"`
+
+	// Orgs are the organizations that wrote or queried, sorted by ID.
+	Orgs []OrgLimitsSimulation `json:"orgs"`
+
+	// Buckets are the buckets with more series than the proposed BucketSeries limit, which would have rejected
+	// the writes of their series over the limit, sorted by ID.
+	Buckets []BucketLimitsSimulation `json:"buckets"`
+}
+
+// OrgLimitsSimulation reports the writes and the queries of an organization that the limits proposed
+// would have rejected. PeakQueryConcurrency is the largest number of its queries that ran at once.
+type OrgLimitsSimulation struct {
+	OrgID                ID  `json:"orgID"`
+	Writes               int `json:"writes"`
+	Points               int `json:"points"`
+	RejectedWrites       int `json:"rejectedWrites"`
+	RejectedPoints       int `json:"rejectedPoints"`
+	Queries              int `json:"queries"`
+	RejectedQueries      int `json:"rejectedQueries"`
+	PeakQueryConcurrency int `json:"peakQueryConcurrency"`
+}
+
+// BucketLimitsSimulation is a bucket with more series than the limits proposed allow.
+type BucketLimitsSimulation struct {
+	OrgID    ID  `json:"orgID"`
+	BucketID ID  `json:"bucketID"`
+	Series   int `json:"series"`
+}
@@ -0,0 +1,120 @@
+// Package limitsim evaluates the recent traffic of the server against proposed limits,
+// reporting what they would have rejected before they are enforced.
+package limitsim
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query/bulkhead"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/write"
+)
+
+var _ platform.LimitsSimulationService = (*Service)(nil)
+
+// CardinalityStats returns the number of series of each bucket, keyed by its encoded name, see tsdb.EncodeName.
+type CardinalityStats interface {
+	MeasurementCardinalityStats() tsi1.MeasurementCardinalityStats
+}
+
+// Service simulates limits on the writes recorded by WriteLimiter, the queries recorded by QueryBulkhead,
+// and the series of the buckets of Cardinality. Any of them may be nil, which leaves its limits out.
+type Service struct {
+	WriteLimiter  *write.Limiter
+	QueryBulkhead *bulkhead.Bulkhead
+	Cardinality   CardinalityStats
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewService returns a Service simulating limits on the traffic of wl and qb, and on the series of cs.
+func NewService(wl *write.Limiter, qb *bulkhead.Bulkhead, cs CardinalityStats) *Service {
+	return &Service{
+		WriteLimiter:  wl,
+		QueryBulkhead: qb,
+		Cardinality:   cs,
+		Now:           time.Now,
+	}
+}
+
+// SimulateLimits reports what limits would have rejected of the recent traffic.
+func (s *Service) SimulateLimits(ctx context.Context, limits platform.LimitsProposal) (*platform.LimitsSimulation, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := &platform.LimitsSimulation{
+		Limits:  limits,
+		Until:   s.Now().UTC(),
+		Orgs:    []platform.OrgLimitsSimulation{},
+		Buckets: []platform.BucketLimitsSimulation{},
+	}
+	orgs := make(map[platform.ID]*platform.OrgLimitsSimulation)
+	org := func(id platform.ID) *platform.OrgLimitsSimulation {
+		o, ok := orgs[id]
+		if !ok {
+			o = &platform.OrgLimitsSimulation{OrgID: id}
+			orgs[id] = o
+		}
+		return o
+	}
+	since := func(t time.Time) {
+		if !t.IsZero() && (res.Since.IsZero() || t.Before(res.Since)) {
+			res.Since = t.UTC()
+		}
+	}
+
+	if s.WriteLimiter != nil {
+		sim := s.WriteLimiter.Simulate(write.Limits{
+			TokenPointsPerSecond: limits.TokenPointsPerSecond,
+			TokenBytesPerSecond:  limits.TokenBytesPerSecond,
+			OrgPointsPerSecond:   limits.OrgPointsPerSecond,
+			OrgBytesPerSecond:    limits.OrgBytesPerSecond,
+		})
+		since(sim.Since)
+		for _, w := range sim.Orgs {
+			o := org(w.OrgID)
+			o.Writes, o.Points = w.Writes, w.Points
+			o.RejectedWrites, o.RejectedPoints = w.Rejected, w.RejectedPoints
+			res.Writes += w.Writes
+			res.Points += w.Points
+			res.RejectedWrites += w.Rejected
+			res.RejectedPoints += w.RejectedPoints
+		}
+	}
+
+	if s.QueryBulkhead != nil {
+		first, qs := s.QueryBulkhead.SimulateConcurrency(limits.QueryOrgConcurrency)
+		since(first)
+		for _, q := range qs {
+			o := org(q.OrgID)
+			o.Queries, o.RejectedQueries, o.PeakQueryConcurrency = q.Queries, q.Rejected, q.Peak
+			res.Queries += q.Queries
+			res.RejectedQueries += q.Rejected
+		}
+	}
+
+	if s.Cardinality != nil && limits.BucketSeries > 0 {
+		for name, n := range s.Cardinality.MeasurementCardinalityStats() {
+			if n <= limits.BucketSeries || len(name) != 16 {
+				continue
+			}
+			var b [16]byte
+			copy(b[:], name)
+			orgID, bucketID := tsdb.DecodeName(b)
+			res.Buckets = append(res.Buckets, platform.BucketLimitsSimulation{OrgID: orgID, BucketID: bucketID, Series: n})
+		}
+		sort.Slice(res.Buckets, func(i, j int) bool { return res.Buckets[i].BucketID < res.Buckets[j].BucketID })
+	}
+
+	for _, o := range orgs {
+		res.Orgs = append(res.Orgs, *o)
+	}
+	sort.Slice(res.Orgs, func(i, j int) bool { return res.Orgs[i].OrgID < res.Orgs[j].OrgID })
+	return res, nil
+}
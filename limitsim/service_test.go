@@ -0,0 +1,88 @@
+package limitsim_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/limitsim"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/write"
+)
+
+type cardinalityStats tsi1.MeasurementCardinalityStats
+
+func (s cardinalityStats) MeasurementCardinalityStats() tsi1.MeasurementCardinalityStats {
+	return tsi1.MeasurementCardinalityStats(s)
+}
+
+func bucketName(orgID, bucketID platform.ID) string {
+	name := tsdb.EncodeName(orgID, bucketID)
+	return string(name[:])
+}
+
+func TestService_SimulateLimits(t *testing.T) {
+	start := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// The writes are recorded although no limit is enforced.
+	wl := write.NewLimiter(write.Limits{})
+	wl.Now = func() time.Time { return start }
+	for i := 0; i < 3; i++ {
+		if err := wl.Allow(1, 10, 100, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wl.Allow(2, 20, 10, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	s := limitsim.NewService(wl, nil, cardinalityStats{
+		bucketName(1, 100): 5000,
+		bucketName(1, 101): 10,
+		bucketName(2, 200): 2000,
+	})
+	s.Now = func() time.Time { return start.Add(time.Minute) }
+
+	got, err := s.SimulateLimits(context.Background(), platform.LimitsProposal{
+		OrgPointsPerSecond: 150,
+		BucketSeries:       1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &platform.LimitsSimulation{
+		Limits: platform.LimitsProposal{
+			OrgPointsPerSecond: 150,
+			BucketSeries:       1000,
+		},
+		Since:          start,
+		Until:          start.Add(time.Minute),
+		Writes:         4,
+		Points:         310,
+		RejectedWrites: 1,
+		RejectedPoints: 100,
+		Orgs: []platform.OrgLimitsSimulation{
+			{OrgID: 1, Writes: 3, Points: 300, RejectedWrites: 1, RejectedPoints: 100},
+			{OrgID: 2, Writes: 1, Points: 10},
+		},
+		Buckets: []platform.BucketLimitsSimulation{
+			{OrgID: 1, BucketID: 100, Series: 5000},
+			{OrgID: 2, BucketID: 200, Series: 2000},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got simulation\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestService_SimulateLimits_Invalid(t *testing.T) {
+	s := limitsim.NewService(nil, nil, nil)
+	_, err := s.SimulateLimits(context.Background(), platform.LimitsProposal{QueryOrgConcurrency: -1})
+	if platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("got error %v, want an invalid error", err)
+	}
+}
@@ -0,0 +1,324 @@
+package influxdb
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// ops for lineage errors
+const (
+	OpAddLineageRecord   = "AddLineageRecord"
+	OpFindLineageRecords = "FindLineageRecords"
+	OpFindLineage        = "FindLineage"
+)
+
+// MaxTaskLineageRecords is the number of latest lineage records kept for a task.
+const MaxTaskLineageRecords = 1000
+
+// LineageRecord records that a run of a task wrote to a bucket data read from other buckets.
+type LineageRecord struct {
+	ID     ID `json:"id,omitempty"`
+	OrgID  ID `json:"orgID,omitempty"`
+	TaskID ID `json:"taskID,omitempty"`
+	RunID  ID `json:"runID,omitempty"`
+
+	// Sources are the buckets read by the run, sorted by ID.
+	Sources     []ID `json:"sources"`
+	Destination ID   `json:"destination,omitempty"`
+
+	// Start and Stop are the time range of the data read by the run, or zero if it could not be determined.
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// LineageDirection is the direction the lineage of a bucket is traversed in.
+type LineageDirection string
+
+// The directions of the lineage of a bucket.
+const (
+	// LineageUpstream traverses the buckets the data of a bucket came from.
+	LineageUpstream LineageDirection = "upstream"
+	// LineageDownstream traverses the buckets the data of a bucket went to.
+	LineageDownstream LineageDirection = "downstream"
+)
+
+// Valid returns an error if d is not a direction.
+func (d LineageDirection) Valid() error {
+	switch d {
+	case LineageUpstream, LineageDownstream:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  "lineage direction must be upstream or downstream",
+	}
+}
+
+// LineageEdge is data flowing from a bucket into another through the runs of a task.
+type LineageEdge struct {
+	TaskID      ID `json:"taskID"`
+	Source      ID `json:"source"`
+	Destination ID `json:"destination"`
+
+	// Runs is the number of runs recorded, of which LastRunID is the latest.
+	Runs      int `json:"runs"`
+	LastRunID ID  `json:"lastRunID,omitempty"`
+
+	// Start and Stop cover the time ranges of the data read by the runs recorded.
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+// Lineage is the lineage of a bucket in a direction, as the edges traversed from it.
+type Lineage struct {
+	BucketID  ID               `json:"bucketID"`
+	Direction LineageDirection `json:"direction"`
+	Edges     []LineageEdge    `json:"edges"`
+}
+
+// LineageService records the lineage of the data written by the runs of tasks.
+type LineageService interface {
+	// AddLineageRecord records the lineage of a run, and sets r.ID with the new identifier.
+	// Only the latest MaxTaskLineageRecords records of a task are kept.
+	AddLineageRecord(ctx context.Context, r *LineageRecord) error
+
+	// FindLineageRecords returns the records that match the filter, latest first.
+	FindLineageRecords(ctx context.Context, filter LineageRecordFilter) ([]*LineageRecord, error)
+
+	// FindLineage traverses the lineage of a bucket of an organization.
+	FindLineage(ctx context.Context, filter LineageFilter) (*Lineage, error)
+}
+
+// LineageRecordFilter represents a set of filters that restrict the returned lineage records.
+type LineageRecordFilter struct {
+	OrgID  *ID
+	TaskID *ID
+	RunID  *ID
+
+	// BucketID, if set, only matches the records reading or writing the bucket.
+	BucketID *ID
+}
+
+// Matches returns true if r passes the filter.
+func (f LineageRecordFilter) Matches(r *LineageRecord) bool {
+	if f.OrgID != nil && *f.OrgID != r.OrgID {
+		return false
+	}
+	if f.TaskID != nil && *f.TaskID != r.TaskID {
+		return false
+	}
+	if f.RunID != nil && *f.RunID != r.RunID {
+		return false
+	}
+	if f.BucketID == nil || *f.BucketID == r.Destination {
+		return true
+	}
+	for _, id := range r.Sources {
+		if id == *f.BucketID {
+			return true
+		}
+	}
+	return false
+}
+
+// LineageFilter selects the lineage of a bucket.
+type LineageFilter struct {
+	OrgID     ID
+	BucketID  ID
+	Direction LineageDirection
+
+	// Depth is the number of edges traversed at most from the bucket. Zero means unlimited.
+	Depth int
+}
+
+// TraceLineage traverses the lineage of the records rs from the bucket of the filter, breadth first.
+// The edges are sorted by the order they are reached in, then by task, source and destination.
+func TraceLineage(rs []*LineageRecord, filter LineageFilter) *Lineage {
+	type edgeKey struct{ task, source, destination ID }
+	edges := map[edgeKey]*LineageEdge{}
+	// next holds the buckets reached from each bucket, by the edges to them.
+	next := map[ID][]edgeKey{}
+	for _, r := range rs {
+		for _, src := range r.Sources {
+			k := edgeKey{task: r.TaskID, source: src, destination: r.Destination}
+			e, ok := edges[k]
+			if !ok {
+				e = &LineageEdge{TaskID: r.TaskID, Source: src, Destination: r.Destination}
+				edges[k] = e
+				from := src
+				if filter.Direction == LineageUpstream {
+					from = r.Destination
+				}
+				next[from] = append(next[from], k)
+			}
+			e.Runs++
+			if e.Runs == 1 || r.RunID > e.LastRunID {
+				e.LastRunID = r.RunID
+			}
+			if !r.Start.IsZero() && (e.Start.IsZero() || r.Start.Before(e.Start)) {
+				e.Start = r.Start
+			}
+			if r.Stop.After(e.Stop) {
+				e.Stop = r.Stop
+			}
+		}
+	}
+
+	l := &Lineage{BucketID: filter.BucketID, Direction: filter.Direction, Edges: []LineageEdge{}}
+	visited := map[ID]bool{filter.BucketID: true}
+	frontier := []ID{filter.BucketID}
+	for depth := 0; len(frontier) > 0 && (filter.Depth <= 0 || depth < filter.Depth); depth++ {
+		var level []edgeKey
+		for _, b := range frontier {
+			level = append(level, next[b]...)
+		}
+		sort.Slice(level, func(i, j int) bool {
+			if level[i].task != level[j].task {
+				return level[i].task < level[j].task
+			}
+			if level[i].source != level[j].source {
+				return level[i].source < level[j].source
+			}
+			return level[i].destination < level[j].destination
+		})
+
+		frontier = nil
+		for _, k := range level {
+			l.Edges = append(l.Edges, *edges[k])
+			to := k.destination
+			if filter.Direction == LineageUpstream {
+				to = k.source
+			}
+			if !visited[to] {
+				visited[to] = true
+				frontier = append(frontier, to)
+			}
+		}
+	}
+	return l
+}
+
+// TaskLineage is the lineage of the data written by the Flux of a task.
+type TaskLineage struct {
+	// Sources are the buckets read by from(), and Destinations the buckets of this host written by to().
+	Sources      []TaskDependency
+	Destinations []TaskDependency
+
+	// Start and Stop are the earliest start and the latest stop of the range() calls, or zero if there are none.
+	Start time.Time
+	Stop  time.Time
+}
+
+// AnalyzeTaskLineage returns the lineage of the data written by the flux script, when run with now as the now option.
+// The buckets are referenced by name or by ID, as either the bucket or bucketID argument, sorted and distinct.
+// Only the buckets and the time ranges written as literals can be determined statically, the others are ignored.
+func AnalyzeTaskLineage(script string, now time.Time) (*TaskLineage, error) {
+	pkg := parser.ParseSource(script)
+	if err := ast.GetError(pkg); err != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Err:  err,
+		}
+	}
+
+	sources := map[TaskDependency]bool{}
+	destinations := map[TaskDependency]bool{}
+	l := &TaskLineage{}
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		args := map[string]ast.Expression{}
+		for _, arg := range call.Arguments {
+			if obj, ok := arg.(*ast.ObjectExpression); ok {
+				for _, p := range obj.Properties {
+					args[p.Key.Key()] = p.Value
+				}
+			}
+		}
+
+		switch calleeName(call) {
+		case "from":
+			addBucketReferences(sources, args)
+		case "to":
+			// The buckets of another host are not part of the lineage of this one.
+			if _, ok := args["host"]; !ok {
+				addBucketReferences(destinations, args)
+			}
+		case "range":
+			start, ok := timeLiteral(args["start"], now)
+			if !ok {
+				return
+			}
+			stop := now
+			if e, ok := args["stop"]; ok {
+				if stop, ok = timeLiteral(e, now); !ok {
+					return
+				}
+			}
+			if l.Start.IsZero() || start.Before(l.Start) {
+				l.Start = start
+			}
+			if stop.After(l.Stop) {
+				l.Stop = stop
+			}
+		}
+	}), pkg)
+
+	l.Sources = sortedDependencies(sources)
+	l.Destinations = sortedDependencies(destinations)
+	return l, nil
+}
+
+// addBucketReferences adds to refs the bucket referenced by the arguments of a call, if any.
+func addBucketReferences(refs map[TaskDependency]bool, args map[string]ast.Expression) {
+	if lit, ok := args["bucket"].(*ast.StringLiteral); ok && lit.Value != "" {
+		refs[TaskDependency{Type: TaskDependencyBucket, Name: lit.Value}] = true
+	}
+	if lit, ok := args["bucketID"].(*ast.StringLiteral); ok && lit.Value != "" {
+		refs[TaskDependency{Type: TaskDependencyBucketID, Name: lit.Value}] = true
+	}
+}
+
+// timeLiteral returns the time of a date time literal, or of a duration literal relative to now.
+func timeLiteral(e ast.Expression, now time.Time) (time.Time, bool) {
+	neg := false
+	if u, ok := e.(*ast.UnaryExpression); ok && u.Operator == ast.SubtractionOperator {
+		neg, e = true, u.Argument
+	}
+	switch lit := e.(type) {
+	case *ast.DateTimeLiteral:
+		return lit.Value.UTC(), !neg
+	case *ast.DurationLiteral:
+		d, err := ast.DurationFrom(lit, now)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if neg {
+			d = -d
+		}
+		return now.Add(d).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+func sortedDependencies(deps map[TaskDependency]bool) []TaskDependency {
+	out := make([]TaskDependency, 0, len(deps))
+	for d := range deps {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
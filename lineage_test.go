@@ -0,0 +1,79 @@
+package influxdb_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestAnalyzeTaskLineage(t *testing.T) {
+	script := `option task = {name: "downsample", every: 1h}
+
+cpu = from(bucket: "telemetry")
+	|> range(start: -2h, stop: -1h)
+mem = from(bucketID: "000000000000000b")
+	|> range(start: 2019-05-01T10:30:00Z)
+from(bucket: v.bucket)
+	|> range(start: -1d)
+	|> to(bucket: "remote", host: "https://eu.example.com:8086", token: "xyz")
+union(tables: [cpu, mem])
+	|> aggregateWindow(every: 5m, fn: mean)
+	|> to(bucket: "telemetry_5m", org: "my-org")
+`
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	l, err := platform.AnalyzeTaskLineage(script, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &platform.TaskLineage{
+		Sources: []platform.TaskDependency{
+			{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+			{Type: platform.TaskDependencyBucketID, Name: "000000000000000b"},
+		},
+		Destinations: []platform.TaskDependency{
+			{Type: platform.TaskDependencyBucket, Name: "telemetry_5m"},
+		},
+		Start: now.Add(-24 * time.Hour),
+		Stop:  now,
+	}
+	if !reflect.DeepEqual(l, exp) {
+		t.Errorf("got lineage %+v, want %+v", l, exp)
+	}
+
+	if _, err := platform.AnalyzeTaskLineage("from(", now); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("got error %v for an invalid script, want invalid", err)
+	}
+}
+
+func TestTraceLineage(t *testing.T) {
+	start := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+	// Buckets 1 and 2 are downsampled into 3 by task 10, which is copied into 4 by task 11.
+	rs := []*platform.LineageRecord{
+		{TaskID: 10, RunID: 100, Sources: []platform.ID{1, 2}, Destination: 3, Start: start, Stop: start.Add(time.Hour)},
+		{TaskID: 10, RunID: 101, Sources: []platform.ID{1, 2}, Destination: 3, Start: start.Add(time.Hour), Stop: start.Add(2 * time.Hour)},
+		{TaskID: 11, RunID: 200, Sources: []platform.ID{3}, Destination: 4},
+	}
+
+	l := platform.TraceLineage(rs, platform.LineageFilter{BucketID: 4, Direction: platform.LineageUpstream})
+	exp := []platform.LineageEdge{
+		{TaskID: 11, Source: 3, Destination: 4, Runs: 1, LastRunID: 200},
+		{TaskID: 10, Source: 1, Destination: 3, Runs: 2, LastRunID: 101, Start: start, Stop: start.Add(2 * time.Hour)},
+		{TaskID: 10, Source: 2, Destination: 3, Runs: 2, LastRunID: 101, Start: start, Stop: start.Add(2 * time.Hour)},
+	}
+	if !reflect.DeepEqual(l.Edges, exp) {
+		t.Errorf("got upstream edges %+v, want %+v", l.Edges, exp)
+	}
+
+	l = platform.TraceLineage(rs, platform.LineageFilter{BucketID: 1, Direction: platform.LineageDownstream, Depth: 1})
+	if len(l.Edges) != 1 || l.Edges[0].Destination != 3 {
+		t.Errorf("got downstream edges %+v, want the edge to bucket 3 only", l.Edges)
+	}
+
+	l = platform.TraceLineage(rs, platform.LineageFilter{BucketID: 1, Direction: platform.LineageDownstream})
+	if len(l.Edges) != 2 || l.Edges[1].Destination != 4 {
+		t.Errorf("got downstream edges %+v, want the edges to buckets 3 and 4", l.Edges)
+	}
+}
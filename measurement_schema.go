@@ -0,0 +1,354 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// BucketSchemaType is how a bucket constrains the points written to it.
+type BucketSchemaType string
+
+const (
+	// BucketSchemaTypeImplicit buckets accept any point, their schema is the union of the points written.
+	BucketSchemaTypeImplicit BucketSchemaType = "implicit"
+	// BucketSchemaTypeExplicit buckets only accept the writes of points that conform to their measurement schemas.
+	BucketSchemaTypeExplicit BucketSchemaType = "explicit"
+)
+
+// Valid returns an error if t is not a known schema type. The empty type is implicit.
+func (t BucketSchemaType) Valid() error {
+	switch t {
+	case "", BucketSchemaTypeImplicit, BucketSchemaTypeExplicit:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("bucket schema type must be %s or %s", BucketSchemaTypeImplicit, BucketSchemaTypeExplicit),
+	}
+}
+
+// ErrMeasurementSchemaNotFound is the error msg for a missing measurement schema.
+const ErrMeasurementSchemaNotFound = "measurement schema not found"
+
+// ops for measurement schema error
+const (
+	OpFindMeasurementSchemaByID = "FindMeasurementSchemaByID"
+	OpFindMeasurementSchemas    = "FindMeasurementSchemas"
+	OpCreateMeasurementSchema   = "CreateMeasurementSchema"
+	OpUpdateMeasurementSchema   = "UpdateMeasurementSchema"
+	OpDeleteMeasurementSchema   = "DeleteMeasurementSchema"
+)
+
+// MeasurementSchemaService represents a service for managing the measurement schemas of explicit-schema buckets.
+type MeasurementSchemaService interface {
+	// FindMeasurementSchemaByID returns a single measurement schema by ID.
+	FindMeasurementSchemaByID(ctx context.Context, id ID) (*MeasurementSchema, error)
+
+	// FindMeasurementSchemas returns the measurement schemas that match a filter.
+	FindMeasurementSchemas(ctx context.Context, filter MeasurementSchemaFilter) ([]*MeasurementSchema, error)
+
+	// CreateMeasurementSchema creates a new measurement schema and sets s.ID with the new identifier.
+	CreateMeasurementSchema(ctx context.Context, s *MeasurementSchema) error
+
+	// UpdateMeasurementSchema updates a single measurement schema with changeset.
+	// Returns the new measurement schema state after update.
+	UpdateMeasurementSchema(ctx context.Context, id ID, upd MeasurementSchemaUpdate) (*MeasurementSchema, error)
+
+	// DeleteMeasurementSchema removes a measurement schema by ID.
+	DeleteMeasurementSchema(ctx context.Context, id ID) error
+}
+
+// SchemaFieldType is the type of the values of a field declared by a measurement schema.
+type SchemaFieldType string
+
+// The types of the values of fields.
+const (
+	SchemaFieldTypeFloat    SchemaFieldType = "float"
+	SchemaFieldTypeInteger  SchemaFieldType = "integer"
+	SchemaFieldTypeUnsigned SchemaFieldType = "unsigned"
+	SchemaFieldTypeString   SchemaFieldType = "string"
+	SchemaFieldTypeBoolean  SchemaFieldType = "boolean"
+)
+
+// schemaFieldTypeOf returns the type of a field value, as returned by models.Point.Fields.
+func schemaFieldTypeOf(v interface{}) SchemaFieldType {
+	switch v.(type) {
+	case float64:
+		return SchemaFieldTypeFloat
+	case int64:
+		return SchemaFieldTypeInteger
+	case uint64:
+		return SchemaFieldTypeUnsigned
+	case string:
+		return SchemaFieldTypeString
+	case bool:
+		return SchemaFieldTypeBoolean
+	}
+	return SchemaFieldType(fmt.Sprintf("%T", v))
+}
+
+// MeasurementSchema declares a measurement that the points written to an explicit-schema bucket may have,
+// with the tag keys and the fields, and their types, that the points of the measurement may have.
+// The points need not have every tag and field of the schema.
+type MeasurementSchema struct {
+	ID       ID `json:"id,omitempty"`
+	OrgID    ID `json:"orgID"`
+	BucketID ID `json:"bucketID"`
+
+	// Name is the name of the measurement, unique within the bucket.
+	Name   string                   `json:"name"`
+	Tags   []string                 `json:"tags"`
+	Fields []MeasurementSchemaField `json:"fields"`
+}
+
+// MeasurementSchemaField is a field declared by a measurement schema.
+type MeasurementSchemaField struct {
+	Name string          `json:"name"`
+	Type SchemaFieldType `json:"type"`
+}
+
+// Validate returns an error if the measurement schema is invalid.
+func (s *MeasurementSchema) Validate() error {
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema orgID is required",
+		}
+	}
+	if !s.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema bucketID is required",
+		}
+	}
+	if s.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema name is required",
+		}
+	}
+	if len(s.Fields) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema must declare at least one field",
+		}
+	}
+
+	// A key cannot be both a tag and a field, as the storage engine would not be able to tell them apart.
+	keys := make(map[string]bool, len(s.Tags)+len(s.Fields))
+	declare := func(key string) error {
+		if key == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "measurement schema tag and field names are required",
+			}
+		}
+		if keys[key] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("measurement schema declares %q more than once", key),
+			}
+		}
+		keys[key] = true
+		return nil
+	}
+	for _, t := range s.Tags {
+		if err := declare(t); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.Fields {
+		if err := declare(f.Name); err != nil {
+			return err
+		}
+		switch f.Type {
+		case SchemaFieldTypeFloat, SchemaFieldTypeInteger, SchemaFieldTypeUnsigned, SchemaFieldTypeString, SchemaFieldTypeBoolean:
+		default:
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("measurement schema field %q has unknown type %q", f.Name, f.Type),
+			}
+		}
+	}
+	return nil
+}
+
+// MeasurementSchemaFilter represents a set of filters that restrict the returned measurement schemas.
+type MeasurementSchemaFilter struct {
+	ID       *ID
+	OrgID    *ID
+	BucketID *ID
+	Name     *string
+}
+
+// Matches returns true if s passes the filter.
+func (f MeasurementSchemaFilter) Matches(s *MeasurementSchema) bool {
+	if f.ID != nil && *f.ID != s.ID {
+		return false
+	}
+	if f.OrgID != nil && *f.OrgID != s.OrgID {
+		return false
+	}
+	if f.BucketID != nil && *f.BucketID != s.BucketID {
+		return false
+	}
+	if f.Name != nil && *f.Name != s.Name {
+		return false
+	}
+	return true
+}
+
+// MeasurementSchemaUpdate is the set of changes that can be applied to a measurement schema.
+// An update can only declare new tags and fields: the points already written to the bucket
+// must still conform to the schema, so its tags and fields cannot be removed or change type.
+type MeasurementSchemaUpdate struct {
+	Tags   []string                 `json:"tags,omitempty"`
+	Fields []MeasurementSchemaField `json:"fields,omitempty"`
+}
+
+// Apply applies the update to s and validates the result.
+func (u MeasurementSchemaUpdate) Apply(s *MeasurementSchema) error {
+	if u.Tags != nil {
+		tags := make(map[string]bool, len(u.Tags))
+		for _, t := range u.Tags {
+			tags[t] = true
+		}
+		for _, t := range s.Tags {
+			if !tags[t] {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("measurement schema tag %q cannot be removed", t),
+				}
+			}
+		}
+		s.Tags = u.Tags
+	}
+
+	if u.Fields != nil {
+		fields := make(map[string]SchemaFieldType, len(u.Fields))
+		for _, f := range u.Fields {
+			fields[f.Name] = f.Type
+		}
+		for _, f := range s.Fields {
+			typ, ok := fields[f.Name]
+			if !ok {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("measurement schema field %q cannot be removed", f.Name),
+				}
+			}
+			if typ != f.Type {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("measurement schema field %q cannot change type from %s to %s", f.Name, f.Type, typ),
+				}
+			}
+		}
+		s.Fields = u.Fields
+	}
+
+	return s.Validate()
+}
+
+// SchemaViolation is the way a point does not conform to the schema of an explicit-schema bucket.
+type SchemaViolation string
+
+// The ways a point can violate the schema of a bucket.
+const (
+	SchemaViolationUnknownMeasurement SchemaViolation = "unknownMeasurement"
+	SchemaViolationUnknownTag         SchemaViolation = "unknownTag"
+	SchemaViolationUnknownField       SchemaViolation = "unknownField"
+	SchemaViolationFieldType          SchemaViolation = "fieldType"
+)
+
+// SchemaViolationError is the error of a point that does not conform to the schema of an explicit-schema bucket.
+type SchemaViolationError struct {
+	Violation   SchemaViolation
+	Measurement string
+
+	// Key is the tag key or field name at fault, if any.
+	Key string
+
+	// Want and Got are the declared and written types of a field of the wrong type.
+	Want SchemaFieldType
+	Got  SchemaFieldType
+}
+
+// Error implements the error interface.
+func (e *SchemaViolationError) Error() string {
+	switch e.Violation {
+	case SchemaViolationUnknownMeasurement:
+		return fmt.Sprintf("measurement %q is not in the bucket schema", e.Measurement)
+	case SchemaViolationUnknownTag:
+		return fmt.Sprintf("tag %q is not in the schema of measurement %q", e.Key, e.Measurement)
+	case SchemaViolationUnknownField:
+		return fmt.Sprintf("field %q is not in the schema of measurement %q", e.Key, e.Measurement)
+	case SchemaViolationFieldType:
+		return fmt.Sprintf("field %q of measurement %q has type %s but the schema declares %s", e.Key, e.Measurement, e.Got, e.Want)
+	}
+	return fmt.Sprintf("point of measurement %q violates the bucket schema: %s", e.Measurement, e.Violation)
+}
+
+// BucketSchema is the schema of an explicit-schema bucket: its measurement schemas by measurement.
+type BucketSchema map[string]*MeasurementSchema
+
+// NewBucketSchema returns the schema of a bucket with the measurement schemas ss.
+func NewBucketSchema(ss []*MeasurementSchema) BucketSchema {
+	bs := make(BucketSchema, len(ss))
+	for _, s := range ss {
+		bs[s.Name] = s
+	}
+	return bs
+}
+
+// CheckPoint returns a *SchemaViolationError if a point of measurement with the tag keys and
+// the fields, as returned by models.Point.Fields, does not conform to the schema.
+func (bs BucketSchema) CheckPoint(measurement string, tagKeys []string, fields map[string]interface{}) error {
+	s, ok := bs[measurement]
+	if !ok {
+		return &SchemaViolationError{Violation: SchemaViolationUnknownMeasurement, Measurement: measurement}
+	}
+
+	for _, k := range tagKeys {
+		if !s.hasTag(k) {
+			return &SchemaViolationError{Violation: SchemaViolationUnknownTag, Measurement: measurement, Key: k}
+		}
+	}
+
+	// Check the fields in order, so that the violation reported is the same for every write of the point.
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		v := fields[k]
+		want, ok := s.fieldType(k)
+		if !ok {
+			return &SchemaViolationError{Violation: SchemaViolationUnknownField, Measurement: measurement, Key: k}
+		}
+		if got := schemaFieldTypeOf(v); got != want {
+			return &SchemaViolationError{Violation: SchemaViolationFieldType, Measurement: measurement, Key: k, Want: want, Got: got}
+		}
+	}
+	return nil
+}
+
+func (s *MeasurementSchema) hasTag(key string) bool {
+	for _, t := range s.Tags {
+		if t == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MeasurementSchema) fieldType(name string) (SchemaFieldType, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f.Type, true
+		}
+	}
+	return "", false
+}
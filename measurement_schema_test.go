@@ -0,0 +1,169 @@
+package influxdb_test
+
+import (
+	"reflect"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+func cpuSchema() *platform.MeasurementSchema {
+	return &platform.MeasurementSchema{
+		OrgID:    1,
+		BucketID: 2,
+		Name:     "cpu",
+		Tags:     []string{"host", "region"},
+		Fields: []platform.MeasurementSchemaField{
+			{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+			{Name: "cores", Type: platform.SchemaFieldTypeUnsigned},
+		},
+	}
+}
+
+func TestMeasurementSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(s *platform.MeasurementSchema)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			update: func(s *platform.MeasurementSchema) {},
+		},
+		{
+			name:    "missing name",
+			update:  func(s *platform.MeasurementSchema) { s.Name = "" },
+			wantErr: true,
+		},
+		{
+			name:    "no fields",
+			update:  func(s *platform.MeasurementSchema) { s.Fields = nil },
+			wantErr: true,
+		},
+		{
+			name:    "unknown field type",
+			update:  func(s *platform.MeasurementSchema) { s.Fields[0].Type = "decimal" },
+			wantErr: true,
+		},
+		{
+			name:    "tag and field with the same name",
+			update:  func(s *platform.MeasurementSchema) { s.Tags = append(s.Tags, "usage") },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := cpuSchema()
+			tt.update(s)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMeasurementSchemaUpdateApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		upd     platform.MeasurementSchemaUpdate
+		wantErr bool
+	}{
+		{
+			name: "add tag and field",
+			upd: platform.MeasurementSchemaUpdate{
+				Tags: []string{"host", "region", "rack"},
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+					{Name: "cores", Type: platform.SchemaFieldTypeUnsigned},
+					{Name: "model", Type: platform.SchemaFieldTypeString},
+				},
+			},
+		},
+		{
+			name:    "remove tag",
+			upd:     platform.MeasurementSchemaUpdate{Tags: []string{"host"}},
+			wantErr: true,
+		},
+		{
+			name: "change field type",
+			upd: platform.MeasurementSchemaUpdate{
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "usage", Type: platform.SchemaFieldTypeInteger},
+					{Name: "cores", Type: platform.SchemaFieldTypeUnsigned},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.upd.Apply(cpuSchema()); (err != nil) != tt.wantErr {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBucketSchemaCheckPoint(t *testing.T) {
+	bs := platform.NewBucketSchema([]*platform.MeasurementSchema{cpuSchema()})
+
+	tests := []struct {
+		name        string
+		measurement string
+		tags        []string
+		fields      map[string]interface{}
+		want        error
+	}{
+		{
+			name:        "conforms",
+			measurement: "cpu",
+			tags:        []string{"host"},
+			fields:      map[string]interface{}{"usage": 0.5},
+		},
+		{
+			name:        "unknown measurement",
+			measurement: "mem",
+			fields:      map[string]interface{}{"used": 1.0},
+			want:        &platform.SchemaViolationError{Violation: platform.SchemaViolationUnknownMeasurement, Measurement: "mem"},
+		},
+		{
+			name:        "unknown tag",
+			measurement: "cpu",
+			tags:        []string{"host", "dc"},
+			fields:      map[string]interface{}{"usage": 0.5},
+			want:        &platform.SchemaViolationError{Violation: platform.SchemaViolationUnknownTag, Measurement: "cpu", Key: "dc"},
+		},
+		{
+			name:        "unknown field",
+			measurement: "cpu",
+			fields:      map[string]interface{}{"usage": 0.5, "idle": 0.5},
+			want:        &platform.SchemaViolationError{Violation: platform.SchemaViolationUnknownField, Measurement: "cpu", Key: "idle"},
+		},
+		{
+			name:        "wrong field type",
+			measurement: "cpu",
+			fields:      map[string]interface{}{"cores": int64(4)},
+			want: &platform.SchemaViolationError{
+				Violation:   platform.SchemaViolationFieldType,
+				Measurement: "cpu",
+				Key:         "cores",
+				Want:        platform.SchemaFieldTypeUnsigned,
+				Got:         platform.SchemaFieldTypeInteger,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bs.CheckPoint(tt.measurement, tt.tags, tt.fields)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(err, tt.want) {
+				t.Errorf("got error %#v, want %#v", err, tt.want)
+			}
+		})
+	}
+}
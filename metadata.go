@@ -0,0 +1,161 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// ErrMetadataNotFound is the error msg for a missing metadata key.
+const ErrMetadataNotFound = "metadata not found"
+
+// ops for metadata error
+const (
+	OpFindMetadata   = "FindMetadata"
+	OpSetMetadata    = "SetMetadata"
+	OpDeleteMetadata = "DeleteMetadata"
+)
+
+// MetadataService represents a service for managing typed metadata on resources.
+type MetadataService interface {
+	// FindMetadata returns the metadata that match a filter.
+	FindMetadata(ctx context.Context, filter MetadataFilter) ([]*Metadata, error)
+
+	// SetMetadata creates or replaces the metadata with m's key on m's resource.
+	SetMetadata(ctx context.Context, m *Metadata) error
+
+	// DeleteMetadata removes the metadata with key from a resource.
+	DeleteMetadata(ctx context.Context, resourceID ID, key string) error
+}
+
+// MetadataType is the type of a metadata value.
+type MetadataType string
+
+// Metadata value types.
+const (
+	MetadataString MetadataType = "string"
+	MetadataNumber MetadataType = "number"
+	MetadataBool   MetadataType = "bool"
+)
+
+// Valid returns an error if t is not a known metadata type.
+func (t MetadataType) Valid() error {
+	switch t {
+	case MetadataString, MetadataNumber, MetadataBool:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("unknown metadata type %q", t),
+	}
+}
+
+// ParseValue parses s as a value of type t.
+func (t MetadataType) ParseValue(s string) (interface{}, error) {
+	var (
+		v   interface{}
+		err error
+	)
+	switch t {
+	case MetadataString:
+		v = s
+	case MetadataNumber:
+		v, err = strconv.ParseFloat(s, 64)
+	case MetadataBool:
+		v, err = strconv.ParseBool(s)
+	default:
+		return nil, t.Valid()
+	}
+	if err != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("metadata value %q is not a %s", s, t),
+		}
+	}
+	return v, nil
+}
+
+// Metadata is a typed key/value pair attached to a resource.
+// Unlike labels, which are org-wide objects meant for display and are shared across resources,
+// metadata belongs to the resource it annotates and is meant to be read by machines,
+// e.g. a "cost-center" or a "tier".
+//
+// Value holds a string, a float64 or a bool, according to Type.
+type Metadata struct {
+	ResourceID   ID           `json:"resourceID"`
+	ResourceType ResourceType `json:"resourceType"`
+	Key          string       `json:"key"`
+	Type         MetadataType `json:"type"`
+	Value        interface{}  `json:"value"`
+}
+
+// Validate returns an error if the metadata is invalid.
+func (m *Metadata) Validate() error {
+	if !m.ResourceID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "resource id is required",
+		}
+	}
+	if err := m.ResourceType.Valid(); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Err:  err,
+		}
+	}
+	if m.Key == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "metadata key is required",
+		}
+	}
+	if err := m.Type.Valid(); err != nil {
+		return err
+	}
+
+	var ok bool
+	switch m.Type {
+	case MetadataString:
+		_, ok = m.Value.(string)
+	case MetadataNumber:
+		_, ok = m.Value.(float64)
+	case MetadataBool:
+		_, ok = m.Value.(bool)
+	}
+	if !ok {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("metadata value for key %q is not a %s", m.Key, m.Type),
+		}
+	}
+
+	return nil
+}
+
+// MetadataFilter represents a set of filters that restrict the returned metadata.
+// Zero fields match any metadata.
+type MetadataFilter struct {
+	ResourceID   *ID
+	ResourceType ResourceType
+	Key          string
+
+	// Value, if set, must equal the metadata value, including its type.
+	Value interface{}
+}
+
+// Matches returns true if m passes the filter.
+func (f MetadataFilter) Matches(m *Metadata) bool {
+	if f.ResourceID != nil && *f.ResourceID != m.ResourceID {
+		return false
+	}
+	if f.ResourceType != "" && f.ResourceType != m.ResourceType {
+		return false
+	}
+	if f.Key != "" && f.Key != m.Key {
+		return false
+	}
+	if f.Value != nil && f.Value != m.Value {
+		return false
+	}
+	return true
+}
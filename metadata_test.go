@@ -0,0 +1,169 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestMetadataValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata influxdb.Metadata
+		wantErr  bool
+	}{
+		{
+			name: "string value",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.BucketsResourceType,
+				Key:          "cost-center",
+				Type:         influxdb.MetadataString,
+				Value:        "r&d",
+			},
+		},
+		{
+			name: "number value",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.BucketsResourceType,
+				Key:          "tier",
+				Type:         influxdb.MetadataNumber,
+				Value:        float64(2),
+			},
+		},
+		{
+			name: "bool value",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.TasksResourceType,
+				Key:          "critical",
+				Type:         influxdb.MetadataBool,
+				Value:        true,
+			},
+		},
+		{
+			name: "value does not match type",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.BucketsResourceType,
+				Key:          "tier",
+				Type:         influxdb.MetadataNumber,
+				Value:        "2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown type",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.BucketsResourceType,
+				Key:          "tier",
+				Type:         influxdb.MetadataType("duration"),
+				Value:        "1h",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing key",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.BucketsResourceType,
+				Type:         influxdb.MetadataBool,
+				Value:        true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid resource type",
+			metadata: influxdb.Metadata{
+				ResourceID:   1,
+				ResourceType: influxdb.ResourceType("widgets"),
+				Key:          "tier",
+				Type:         influxdb.MetadataBool,
+				Value:        true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing resource ID",
+			metadata: influxdb.Metadata{
+				ResourceType: influxdb.BucketsResourceType,
+				Key:          "tier",
+				Type:         influxdb.MetadataBool,
+				Value:        true,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metadata.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetadataTypeParseValue(t *testing.T) {
+	tests := []struct {
+		typ     influxdb.MetadataType
+		s       string
+		want    interface{}
+		wantErr bool
+	}{
+		{typ: influxdb.MetadataString, s: "gold", want: "gold"},
+		{typ: influxdb.MetadataNumber, s: "2.5", want: 2.5},
+		{typ: influxdb.MetadataBool, s: "true", want: true},
+		{typ: influxdb.MetadataNumber, s: "gold", wantErr: true},
+		{typ: influxdb.MetadataBool, s: "yes please", wantErr: true},
+		{typ: influxdb.MetadataType("duration"), s: "1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.typ.ParseValue(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s.ParseValue(%q) error = %v, wantErr %v", tt.typ, tt.s, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("%s.ParseValue(%q) = %v, want %v", tt.typ, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestMetadataFilterMatches(t *testing.T) {
+	rid := influxdb.ID(1)
+	otherID := influxdb.ID(2)
+	m := &influxdb.Metadata{
+		ResourceID:   rid,
+		ResourceType: influxdb.BucketsResourceType,
+		Key:          "tier",
+		Type:         influxdb.MetadataNumber,
+		Value:        float64(2),
+	}
+
+	tests := []struct {
+		name   string
+		filter influxdb.MetadataFilter
+		want   bool
+	}{
+		{name: "empty filter", want: true},
+		{name: "resource ID", filter: influxdb.MetadataFilter{ResourceID: &rid}, want: true},
+		{name: "other resource ID", filter: influxdb.MetadataFilter{ResourceID: &otherID}},
+		{name: "resource type", filter: influxdb.MetadataFilter{ResourceType: influxdb.BucketsResourceType}, want: true},
+		{name: "other resource type", filter: influxdb.MetadataFilter{ResourceType: influxdb.TasksResourceType}},
+		{name: "key and value", filter: influxdb.MetadataFilter{Key: "tier", Value: float64(2)}, want: true},
+		{name: "other value", filter: influxdb.MetadataFilter{Key: "tier", Value: float64(3)}},
+		{name: "value of another type", filter: influxdb.MetadataFilter{Key: "tier", Value: "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(m); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
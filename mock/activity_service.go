@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ActivityService = &ActivityService{}
+
+// ActivityService is a mock implementation of platform.ActivityService
+type ActivityService struct {
+	RecordActivityFn   func(context.Context, platform.ResourceType, platform.ID, time.Time) error
+	FindLastActivityFn func(context.Context, platform.ResourceType, platform.ID) (time.Time, error)
+}
+
+// NewActivityService returns a mock of ActivityService
+// where its methods will return zero values.
+func NewActivityService() *ActivityService {
+	return &ActivityService{
+		RecordActivityFn: func(context.Context, platform.ResourceType, platform.ID, time.Time) error { return nil },
+		FindLastActivityFn: func(context.Context, platform.ResourceType, platform.ID) (time.Time, error) {
+			return time.Time{}, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrActivityNotFound}
+		},
+	}
+}
+
+// RecordActivity records that the resource of type rt with id was used at t.
+func (s *ActivityService) RecordActivity(ctx context.Context, rt platform.ResourceType, id platform.ID, t time.Time) error {
+	return s.RecordActivityFn(ctx, rt, id, t)
+}
+
+// FindLastActivity returns the time the resource of type rt with id was last used.
+func (s *ActivityService) FindLastActivity(ctx context.Context, rt platform.ResourceType, id platform.ID) (time.Time, error) {
+	return s.FindLastActivityFn(ctx, rt, id)
+}
@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.AnnouncementService = &AnnouncementService{}
+
+// AnnouncementService is a mock implementation of platform.AnnouncementService
+type AnnouncementService struct {
+	FindAnnouncementsFn  func(context.Context, platform.AnnouncementFilter) ([]*platform.Announcement, error)
+	CreateAnnouncementFn func(context.Context, *platform.Announcement) error
+	UpdateAnnouncementFn func(context.Context, platform.ID, platform.AnnouncementUpdate) (*platform.Announcement, error)
+	DeleteAnnouncementFn func(context.Context, platform.ID) error
+}
+
+// NewAnnouncementService returns a mock of AnnouncementService
+// where its methods will return zero values.
+func NewAnnouncementService() *AnnouncementService {
+	return &AnnouncementService{
+		FindAnnouncementsFn: func(context.Context, platform.AnnouncementFilter) ([]*platform.Announcement, error) {
+			return []*platform.Announcement{}, nil
+		},
+		CreateAnnouncementFn: func(context.Context, *platform.Announcement) error { return nil },
+		UpdateAnnouncementFn: func(context.Context, platform.ID, platform.AnnouncementUpdate) (*platform.Announcement, error) {
+			return nil, nil
+		},
+		DeleteAnnouncementFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindAnnouncements returns the announcements that match a filter.
+func (s *AnnouncementService) FindAnnouncements(ctx context.Context, filter platform.AnnouncementFilter) ([]*platform.Announcement, error) {
+	return s.FindAnnouncementsFn(ctx, filter)
+}
+
+// CreateAnnouncement creates a new announcement and sets a.ID with the new identifier.
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, a *platform.Announcement) error {
+	return s.CreateAnnouncementFn(ctx, a)
+}
+
+// UpdateAnnouncement updates a single announcement with changeset.
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, id platform.ID, upd platform.AnnouncementUpdate) (*platform.Announcement, error) {
+	return s.UpdateAnnouncementFn(ctx, id, upd)
+}
+
+// DeleteAnnouncement removes an announcement by ID.
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id platform.ID) error {
+	return s.DeleteAnnouncementFn(ctx, id)
+}
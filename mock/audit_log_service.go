@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.AuditLogService = &AuditLogService{}
+
+// AuditLogService is a mock implementation of platform.AuditLogService
+type AuditLogService struct {
+	FindAuditEventsFn func(context.Context, platform.AuditEventFilter) ([]*platform.AuditEvent, error)
+}
+
+// NewAuditLogService returns a mock of AuditLogService
+// where its methods will return zero values.
+func NewAuditLogService() *AuditLogService {
+	return &AuditLogService{
+		FindAuditEventsFn: func(context.Context, platform.AuditEventFilter) ([]*platform.AuditEvent, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindAuditEvents returns the audit events matching filter.
+func (s *AuditLogService) FindAuditEvents(ctx context.Context, filter platform.AuditEventFilter) ([]*platform.AuditEvent, error) {
+	return s.FindAuditEventsFn(ctx, filter)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.BucketBatchService = &BucketBatchService{}
+
+// BucketBatchService is a mock implementation of platform.BucketBatchService
+type BucketBatchService struct {
+	ApplyBucketBatchFn func(context.Context, []platform.BucketBatchOperation) ([]platform.BucketBatchResult, error)
+}
+
+// NewBucketBatchService returns a mock of BucketBatchService
+// where its methods will return zero values.
+func NewBucketBatchService() *BucketBatchService {
+	return &BucketBatchService{
+		ApplyBucketBatchFn: func(context.Context, []platform.BucketBatchOperation) ([]platform.BucketBatchResult, error) {
+			return nil, nil
+		},
+	}
+}
+
+// ApplyBucketBatch applies the operations of a bucket batch.
+func (s *BucketBatchService) ApplyBucketBatch(ctx context.Context, ops []platform.BucketBatchOperation) ([]platform.BucketBatchResult, error) {
+	return s.ApplyBucketBatchFn(ctx, ops)
+}
@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.BucketCloneService = &BucketCloneService{}
+
+// BucketCloneService is a mock implementation of platform.BucketCloneService
+type BucketCloneService struct {
+	CreateBucketCloneJobFn   func(context.Context, platform.BucketCloneRequest) (*platform.BucketCloneJob, error)
+	FindBucketCloneJobByIDFn func(context.Context, platform.ID) (*platform.BucketCloneJob, error)
+	FindBucketCloneJobsFn    func(context.Context, platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error)
+}
+
+// NewBucketCloneService returns a mock of BucketCloneService
+// where its methods will return zero values.
+func NewBucketCloneService() *BucketCloneService {
+	return &BucketCloneService{
+		CreateBucketCloneJobFn: func(context.Context, platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+			return nil, nil
+		},
+		FindBucketCloneJobByIDFn: func(context.Context, platform.ID) (*platform.BucketCloneJob, error) {
+			return nil, nil
+		},
+		FindBucketCloneJobsFn: func(context.Context, platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error) {
+			return nil, nil
+		},
+	}
+}
+
+// CreateBucketCloneJob creates the bucket the data of req are copied into, and queues the job copying them.
+func (s *BucketCloneService) CreateBucketCloneJob(ctx context.Context, req platform.BucketCloneRequest) (*platform.BucketCloneJob, error) {
+	return s.CreateBucketCloneJobFn(ctx, req)
+}
+
+// FindBucketCloneJobByID returns a single bucket clone job by ID.
+func (s *BucketCloneService) FindBucketCloneJobByID(ctx context.Context, id platform.ID) (*platform.BucketCloneJob, error) {
+	return s.FindBucketCloneJobByIDFn(ctx, id)
+}
+
+// FindBucketCloneJobs returns the bucket clone jobs matching filter.
+func (s *BucketCloneService) FindBucketCloneJobs(ctx context.Context, filter platform.BucketCloneJobFilter) ([]*platform.BucketCloneJob, error) {
+	return s.FindBucketCloneJobsFn(ctx, filter)
+}
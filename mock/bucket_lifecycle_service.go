@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.BucketLifecycleService = &BucketLifecycleService{}
+
+// BucketLifecycleService is a mock implementation of platform.BucketLifecycleService
+type BucketLifecycleService struct {
+	FindBucketLifecyclePolicyFn   func(context.Context, platform.ID) (*platform.BucketLifecyclePolicy, error)
+	PutBucketLifecyclePolicyFn    func(context.Context, *platform.BucketLifecyclePolicy) error
+	DeleteBucketLifecyclePolicyFn func(context.Context, platform.ID) error
+	FindBucketLifecycleStatusFn   func(context.Context, platform.ID) (*platform.BucketLifecycleStatus, error)
+}
+
+// NewBucketLifecycleService returns a mock of BucketLifecycleService
+// where its methods will return zero values.
+func NewBucketLifecycleService() *BucketLifecycleService {
+	return &BucketLifecycleService{
+		FindBucketLifecyclePolicyFn: func(context.Context, platform.ID) (*platform.BucketLifecyclePolicy, error) {
+			return nil, nil
+		},
+		PutBucketLifecyclePolicyFn: func(context.Context, *platform.BucketLifecyclePolicy) error {
+			return nil
+		},
+		DeleteBucketLifecyclePolicyFn: func(context.Context, platform.ID) error {
+			return nil
+		},
+		FindBucketLifecycleStatusFn: func(context.Context, platform.ID) (*platform.BucketLifecycleStatus, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindBucketLifecyclePolicy returns the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) FindBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecyclePolicy, error) {
+	return s.FindBucketLifecyclePolicyFn(ctx, bucketID)
+}
+
+// PutBucketLifecyclePolicy sets the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) PutBucketLifecyclePolicy(ctx context.Context, p *platform.BucketLifecyclePolicy) error {
+	return s.PutBucketLifecyclePolicyFn(ctx, p)
+}
+
+// DeleteBucketLifecyclePolicy removes the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) DeleteBucketLifecyclePolicy(ctx context.Context, bucketID platform.ID) error {
+	return s.DeleteBucketLifecyclePolicyFn(ctx, bucketID)
+}
+
+// FindBucketLifecycleStatus returns the status of the lifecycle policy of a bucket.
+func (s *BucketLifecycleService) FindBucketLifecycleStatus(ctx context.Context, bucketID platform.ID) (*platform.BucketLifecycleStatus, error) {
+	return s.FindBucketLifecycleStatusFn(ctx, bucketID)
+}
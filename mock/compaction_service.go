@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.CompactionService = &CompactionService{}
+
+// CompactionService is a mock implementation of platform.CompactionService
+type CompactionService struct {
+	FindCompactionSettingsFn   func(context.Context) (*platform.CompactionSettings, error)
+	UpdateCompactionSettingsFn func(context.Context, platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error)
+}
+
+// NewCompactionService returns a mock of CompactionService
+// where its methods will return zero values.
+func NewCompactionService() *CompactionService {
+	return &CompactionService{
+		FindCompactionSettingsFn: func(context.Context) (*platform.CompactionSettings, error) {
+			return &platform.CompactionSettings{}, nil
+		},
+		UpdateCompactionSettingsFn: func(context.Context, platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error) {
+			return &platform.CompactionSettings{}, nil
+		},
+	}
+}
+
+// FindCompactionSettings returns the current compaction settings.
+func (s *CompactionService) FindCompactionSettings(ctx context.Context) (*platform.CompactionSettings, error) {
+	return s.FindCompactionSettingsFn(ctx)
+}
+
+// UpdateCompactionSettings changes the compaction settings.
+func (s *CompactionService) UpdateCompactionSettings(ctx context.Context, upd platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error) {
+	return s.UpdateCompactionSettingsFn(ctx, upd)
+}
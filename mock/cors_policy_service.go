@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.CORSPolicyService = &CORSPolicyService{}
+
+// CORSPolicyService is a mock implementation of platform.CORSPolicyService
+type CORSPolicyService struct {
+	FindCORSPolicyFn   func(context.Context) (*platform.CORSPolicy, error)
+	UpdateCORSPolicyFn func(context.Context, platform.CORSPolicy) (*platform.CORSPolicy, error)
+	DeleteCORSPolicyFn func(context.Context) error
+}
+
+// NewCORSPolicyService returns a mock of CORSPolicyService
+// where its methods will return zero values.
+func NewCORSPolicyService() *CORSPolicyService {
+	return &CORSPolicyService{
+		FindCORSPolicyFn: func(context.Context) (*platform.CORSPolicy, error) {
+			return nil, nil
+		},
+		UpdateCORSPolicyFn: func(ctx context.Context, p platform.CORSPolicy) (*platform.CORSPolicy, error) {
+			return &p, nil
+		},
+		DeleteCORSPolicyFn: func(context.Context) error {
+			return nil
+		},
+	}
+}
+
+// FindCORSPolicy returns the stored CORS policy.
+func (s *CORSPolicyService) FindCORSPolicy(ctx context.Context) (*platform.CORSPolicy, error) {
+	return s.FindCORSPolicyFn(ctx)
+}
+
+// UpdateCORSPolicy stores the CORS policy.
+func (s *CORSPolicyService) UpdateCORSPolicy(ctx context.Context, p platform.CORSPolicy) (*platform.CORSPolicy, error) {
+	return s.UpdateCORSPolicyFn(ctx, p)
+}
+
+// DeleteCORSPolicy deletes the stored CORS policy.
+func (s *CORSPolicyService) DeleteCORSPolicy(ctx context.Context) error {
+	return s.DeleteCORSPolicyFn(ctx)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.CoverageGapService = &CoverageGapService{}
+
+// CoverageGapService is a mock implementation of platform.CoverageGapService
+type CoverageGapService struct {
+	FindCoverageGapsFn func(context.Context, platform.CoverageGapRequest) ([]*platform.CoverageGap, error)
+}
+
+// NewCoverageGapService returns a mock of CoverageGapService
+// where its methods will return zero values.
+func NewCoverageGapService() *CoverageGapService {
+	return &CoverageGapService{
+		FindCoverageGapsFn: func(context.Context, platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+			return []*platform.CoverageGap{}, nil
+		},
+	}
+}
+
+// FindCoverageGaps returns the gaps in the coverage of the measurement of a bucket.
+func (s *CoverageGapService) FindCoverageGaps(ctx context.Context, req platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+	return s.FindCoverageGapsFn(ctx, req)
+}
@@ -0,0 +1,69 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.DeleteService = &DeleteService{}
+
+// DeleteService is a mock implementation of platform.DeleteService
+type DeleteService struct {
+	DeleteBucketRangePredicateFn func(context.Context, platform.DeleteRequest) error
+}
+
+// NewDeleteService returns a mock of DeleteService
+// where its methods will return zero values.
+func NewDeleteService() *DeleteService {
+	return &DeleteService{
+		DeleteBucketRangePredicateFn: func(context.Context, platform.DeleteRequest) error {
+			return nil
+		},
+	}
+}
+
+// DeleteBucketRangePredicate deletes the points of the series of a bucket matching the predicate of req.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, req platform.DeleteRequest) error {
+	return s.DeleteBucketRangePredicateFn(ctx, req)
+}
+
+var _ platform.DeleteJobService = &DeleteJobService{}
+
+// DeleteJobService is a mock implementation of platform.DeleteJobService
+type DeleteJobService struct {
+	CreateDeleteJobFn   func(context.Context, platform.DeleteRequest) (*platform.DeleteJob, error)
+	FindDeleteJobByIDFn func(context.Context, platform.ID) (*platform.DeleteJob, error)
+	FindDeleteJobsFn    func(context.Context, platform.DeleteJobFilter) ([]*platform.DeleteJob, error)
+}
+
+// NewDeleteJobService returns a mock of DeleteJobService
+// where its methods will return zero values.
+func NewDeleteJobService() *DeleteJobService {
+	return &DeleteJobService{
+		CreateDeleteJobFn: func(context.Context, platform.DeleteRequest) (*platform.DeleteJob, error) {
+			return nil, nil
+		},
+		FindDeleteJobByIDFn: func(context.Context, platform.ID) (*platform.DeleteJob, error) {
+			return nil, nil
+		},
+		FindDeleteJobsFn: func(context.Context, platform.DeleteJobFilter) ([]*platform.DeleteJob, error) {
+			return nil, nil
+		},
+	}
+}
+
+// CreateDeleteJob queues a job deleting the points of the series of a bucket matching the predicate of req.
+func (s *DeleteJobService) CreateDeleteJob(ctx context.Context, req platform.DeleteRequest) (*platform.DeleteJob, error) {
+	return s.CreateDeleteJobFn(ctx, req)
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id platform.ID) (*platform.DeleteJob, error) {
+	return s.FindDeleteJobByIDFn(ctx, id)
+}
+
+// FindDeleteJobs returns the delete jobs matching filter.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter platform.DeleteJobFilter) ([]*platform.DeleteJob, error) {
+	return s.FindDeleteJobsFn(ctx, filter)
+}
@@ -0,0 +1,72 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ExpectedReporterService = &ExpectedReporterService{}
+var _ platform.ExpectedReporterMonitor = &ExpectedReporterMonitor{}
+
+// ExpectedReporterService is a mock implementation of platform.ExpectedReporterService
+type ExpectedReporterService struct {
+	FindExpectedReporterByIDFn func(context.Context, platform.ID) (*platform.ExpectedReporter, error)
+	FindExpectedReportersFn    func(context.Context, platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error)
+	CreateExpectedReporterFn   func(context.Context, *platform.ExpectedReporter) error
+	UpdateExpectedReporterFn   func(context.Context, platform.ID, platform.ExpectedReporterUpdate) (*platform.ExpectedReporter, error)
+	DeleteExpectedReporterFn   func(context.Context, platform.ID) error
+}
+
+// NewExpectedReporterService returns a mock of ExpectedReporterService
+// where its methods will return zero values.
+func NewExpectedReporterService() *ExpectedReporterService {
+	return &ExpectedReporterService{
+		FindExpectedReporterByIDFn: func(context.Context, platform.ID) (*platform.ExpectedReporter, error) {
+			return nil, nil
+		},
+		FindExpectedReportersFn: func(context.Context, platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error) {
+			return []*platform.ExpectedReporter{}, nil
+		},
+		CreateExpectedReporterFn: func(context.Context, *platform.ExpectedReporter) error { return nil },
+		UpdateExpectedReporterFn: func(context.Context, platform.ID, platform.ExpectedReporterUpdate) (*platform.ExpectedReporter, error) {
+			return nil, nil
+		},
+		DeleteExpectedReporterFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindExpectedReporterByID returns a single expected reporter by ID.
+func (s *ExpectedReporterService) FindExpectedReporterByID(ctx context.Context, id platform.ID) (*platform.ExpectedReporter, error) {
+	return s.FindExpectedReporterByIDFn(ctx, id)
+}
+
+// FindExpectedReporters returns the expected reporters that match a filter.
+func (s *ExpectedReporterService) FindExpectedReporters(ctx context.Context, filter platform.ExpectedReporterFilter) ([]*platform.ExpectedReporter, error) {
+	return s.FindExpectedReportersFn(ctx, filter)
+}
+
+// CreateExpectedReporter creates a new expected reporter and sets r.ID with the new identifier.
+func (s *ExpectedReporterService) CreateExpectedReporter(ctx context.Context, r *platform.ExpectedReporter) error {
+	return s.CreateExpectedReporterFn(ctx, r)
+}
+
+// UpdateExpectedReporter updates a single expected reporter with changeset.
+func (s *ExpectedReporterService) UpdateExpectedReporter(ctx context.Context, id platform.ID, upd platform.ExpectedReporterUpdate) (*platform.ExpectedReporter, error) {
+	return s.UpdateExpectedReporterFn(ctx, id, upd)
+}
+
+// DeleteExpectedReporter removes an expected reporter by ID.
+func (s *ExpectedReporterService) DeleteExpectedReporter(ctx context.Context, id platform.ID) error {
+	return s.DeleteExpectedReporterFn(ctx, id)
+}
+
+// ExpectedReporterMonitor is a mock implementation of platform.ExpectedReporterMonitor
+type ExpectedReporterMonitor struct {
+	ExpectedReporterStatusesFn func(context.Context, []*platform.ExpectedReporter) ([]*platform.ExpectedReporterStatus, error)
+}
+
+// ExpectedReporterStatuses returns the current status of each of reporters.
+func (m *ExpectedReporterMonitor) ExpectedReporterStatuses(ctx context.Context, reporters []*platform.ExpectedReporter) ([]*platform.ExpectedReporterStatus, error) {
+	return m.ExpectedReporterStatusesFn(ctx, reporters)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.IndexCheckService = &IndexCheckService{}
+
+// IndexCheckService is a mock implementation of platform.IndexCheckService
+type IndexCheckService struct {
+	CheckIndexFn     func(context.Context, platform.IndexCheckOptions) (*platform.IndexCheck, error)
+	FindIndexCheckFn func(context.Context) (*platform.IndexCheck, error)
+}
+
+// NewIndexCheckService returns a mock of IndexCheckService
+// where its methods will return zero values.
+func NewIndexCheckService() *IndexCheckService {
+	return &IndexCheckService{
+		CheckIndexFn: func(context.Context, platform.IndexCheckOptions) (*platform.IndexCheck, error) {
+			return &platform.IndexCheck{}, nil
+		},
+		FindIndexCheckFn: func(context.Context) (*platform.IndexCheck, error) {
+			return &platform.IndexCheck{}, nil
+		},
+	}
+}
+
+// CheckIndex starts checking the index.
+func (s *IndexCheckService) CheckIndex(ctx context.Context, opts platform.IndexCheckOptions) (*platform.IndexCheck, error) {
+	return s.CheckIndexFn(ctx, opts)
+}
+
+// FindIndexCheck returns the running or the last check of the index.
+func (s *IndexCheckService) FindIndexCheck(ctx context.Context) (*platform.IndexCheck, error) {
+	return s.FindIndexCheckFn(ctx)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.LimitsSimulationService = &LimitsSimulationService{}
+
+// LimitsSimulationService is a mock implementation of platform.LimitsSimulationService
+type LimitsSimulationService struct {
+	SimulateLimitsFn func(context.Context, platform.LimitsProposal) (*platform.LimitsSimulation, error)
+}
+
+// NewLimitsSimulationService returns a mock of LimitsSimulationService
+// where its methods will return zero values.
+func NewLimitsSimulationService() *LimitsSimulationService {
+	return &LimitsSimulationService{
+		SimulateLimitsFn: func(_ context.Context, limits platform.LimitsProposal) (*platform.LimitsSimulation, error) {
+			return &platform.LimitsSimulation{Limits: limits}, nil
+		},
+	}
+}
+
+// SimulateLimits reports what the limits would have rejected of the recent traffic.
+func (s *LimitsSimulationService) SimulateLimits(ctx context.Context, limits platform.LimitsProposal) (*platform.LimitsSimulation, error) {
+	return s.SimulateLimitsFn(ctx, limits)
+}
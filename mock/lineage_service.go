@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.LineageService = &LineageService{}
+
+// LineageService is a mock implementation of platform.LineageService
+type LineageService struct {
+	AddLineageRecordFn   func(context.Context, *platform.LineageRecord) error
+	FindLineageRecordsFn func(context.Context, platform.LineageRecordFilter) ([]*platform.LineageRecord, error)
+	FindLineageFn        func(context.Context, platform.LineageFilter) (*platform.Lineage, error)
+}
+
+// NewLineageService returns a mock of LineageService
+// where its methods will return zero values.
+func NewLineageService() *LineageService {
+	return &LineageService{
+		AddLineageRecordFn: func(context.Context, *platform.LineageRecord) error { return nil },
+		FindLineageRecordsFn: func(context.Context, platform.LineageRecordFilter) ([]*platform.LineageRecord, error) {
+			return []*platform.LineageRecord{}, nil
+		},
+		FindLineageFn: func(context.Context, platform.LineageFilter) (*platform.Lineage, error) {
+			return nil, nil
+		},
+	}
+}
+
+// AddLineageRecord records the lineage of a run.
+func (s *LineageService) AddLineageRecord(ctx context.Context, r *platform.LineageRecord) error {
+	return s.AddLineageRecordFn(ctx, r)
+}
+
+// FindLineageRecords returns the lineage records that match the filter.
+func (s *LineageService) FindLineageRecords(ctx context.Context, filter platform.LineageRecordFilter) ([]*platform.LineageRecord, error) {
+	return s.FindLineageRecordsFn(ctx, filter)
+}
+
+// FindLineage traverses the lineage of a bucket.
+func (s *LineageService) FindLineage(ctx context.Context, filter platform.LineageFilter) (*platform.Lineage, error) {
+	return s.FindLineageFn(ctx, filter)
+}
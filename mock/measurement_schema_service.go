@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.MeasurementSchemaService = &MeasurementSchemaService{}
+
+// MeasurementSchemaService is a mock implementation of platform.MeasurementSchemaService
+type MeasurementSchemaService struct {
+	FindMeasurementSchemaByIDFn func(context.Context, platform.ID) (*platform.MeasurementSchema, error)
+	FindMeasurementSchemasFn    func(context.Context, platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error)
+	CreateMeasurementSchemaFn   func(context.Context, *platform.MeasurementSchema) error
+	UpdateMeasurementSchemaFn   func(context.Context, platform.ID, platform.MeasurementSchemaUpdate) (*platform.MeasurementSchema, error)
+	DeleteMeasurementSchemaFn   func(context.Context, platform.ID) error
+}
+
+// NewMeasurementSchemaService returns a mock of MeasurementSchemaService
+// where its methods will return zero values.
+func NewMeasurementSchemaService() *MeasurementSchemaService {
+	return &MeasurementSchemaService{
+		FindMeasurementSchemaByIDFn: func(context.Context, platform.ID) (*platform.MeasurementSchema, error) {
+			return nil, nil
+		},
+		FindMeasurementSchemasFn: func(context.Context, platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+			return []*platform.MeasurementSchema{}, nil
+		},
+		CreateMeasurementSchemaFn: func(context.Context, *platform.MeasurementSchema) error { return nil },
+		UpdateMeasurementSchemaFn: func(context.Context, platform.ID, platform.MeasurementSchemaUpdate) (*platform.MeasurementSchema, error) {
+			return nil, nil
+		},
+		DeleteMeasurementSchemaFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindMeasurementSchemaByID returns a single measurement schema by ID.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id platform.ID) (*platform.MeasurementSchema, error) {
+	return s.FindMeasurementSchemaByIDFn(ctx, id)
+}
+
+// FindMeasurementSchemas returns the measurement schemas that match a filter.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+	return s.FindMeasurementSchemasFn(ctx, filter)
+}
+
+// CreateMeasurementSchema creates a new measurement schema and sets ms.ID with the new identifier.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *platform.MeasurementSchema) error {
+	return s.CreateMeasurementSchemaFn(ctx, ms)
+}
+
+// UpdateMeasurementSchema updates a single measurement schema with changeset.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id platform.ID, upd platform.MeasurementSchemaUpdate) (*platform.MeasurementSchema, error) {
+	return s.UpdateMeasurementSchemaFn(ctx, id, upd)
+}
+
+// DeleteMeasurementSchema removes a measurement schema by ID.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id platform.ID) error {
+	return s.DeleteMeasurementSchemaFn(ctx, id)
+}
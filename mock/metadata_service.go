@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.MetadataService = &MetadataService{}
+
+// MetadataService is a mock implementation of platform.MetadataService
+type MetadataService struct {
+	FindMetadataFn   func(context.Context, platform.MetadataFilter) ([]*platform.Metadata, error)
+	SetMetadataFn    func(context.Context, *platform.Metadata) error
+	DeleteMetadataFn func(context.Context, platform.ID, string) error
+}
+
+// NewMetadataService returns a mock of MetadataService
+// where its methods will return zero values.
+func NewMetadataService() *MetadataService {
+	return &MetadataService{
+		FindMetadataFn: func(context.Context, platform.MetadataFilter) ([]*platform.Metadata, error) {
+			return []*platform.Metadata{}, nil
+		},
+		SetMetadataFn:    func(context.Context, *platform.Metadata) error { return nil },
+		DeleteMetadataFn: func(context.Context, platform.ID, string) error { return nil },
+	}
+}
+
+// FindMetadata returns the metadata that match a filter.
+func (s *MetadataService) FindMetadata(ctx context.Context, filter platform.MetadataFilter) ([]*platform.Metadata, error) {
+	return s.FindMetadataFn(ctx, filter)
+}
+
+// SetMetadata creates or replaces the metadata with m's key on m's resource.
+func (s *MetadataService) SetMetadata(ctx context.Context, m *platform.Metadata) error {
+	return s.SetMetadataFn(ctx, m)
+}
+
+// DeleteMetadata removes the metadata with key from a resource.
+func (s *MetadataService) DeleteMetadata(ctx context.Context, resourceID platform.ID, key string) error {
+	return s.DeleteMetadataFn(ctx, resourceID, key)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ReplicationService = &ReplicationService{}
+
+// ReplicationService is a mock implementation of platform.ReplicationService
+type ReplicationService struct {
+	VerifyReplicationFn func(context.Context, platform.ReplicationRequest) (*platform.ReplicationReport, error)
+}
+
+// NewReplicationService returns a mock of ReplicationService
+// where its methods will return zero values.
+func NewReplicationService() *ReplicationService {
+	return &ReplicationService{
+		VerifyReplicationFn: func(context.Context, platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+			return &platform.ReplicationReport{}, nil
+		},
+	}
+}
+
+// VerifyReplication compares the data of the source bucket of req with the data of its target bucket.
+func (s *ReplicationService) VerifyReplication(ctx context.Context, req platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+	return s.VerifyReplicationFn(ctx, req)
+}
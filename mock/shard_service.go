@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ShardService = &ShardService{}
+
+// ShardService is a mock implementation of platform.ShardService
+type ShardService struct {
+	FindShardsFn    func(context.Context, platform.ShardFilter) ([]*platform.Shard, error)
+	DeleteShardFn   func(context.Context, platform.ID, platform.ID, string) error
+	BackupShardFn   func(context.Context, platform.ID, platform.ID, string, io.Writer) error
+	CompactShardsFn func(context.Context) error
+	TopShardsFn     func(context.Context, platform.TopShardsOptions) ([]*platform.ShardActivity, error)
+}
+
+// NewShardService returns a mock of ShardService
+// where its methods will return zero values.
+func NewShardService() *ShardService {
+	return &ShardService{
+		FindShardsFn: func(context.Context, platform.ShardFilter) ([]*platform.Shard, error) {
+			return nil, nil
+		},
+		DeleteShardFn:   func(context.Context, platform.ID, platform.ID, string) error { return nil },
+		BackupShardFn:   func(context.Context, platform.ID, platform.ID, string, io.Writer) error { return nil },
+		CompactShardsFn: func(context.Context) error { return nil },
+		TopShardsFn: func(context.Context, platform.TopShardsOptions) ([]*platform.ShardActivity, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindShards returns the shards holding points of a bucket.
+func (s *ShardService) FindShards(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+	return s.FindShardsFn(ctx, filter)
+}
+
+// DeleteShard deletes the points of a bucket held in a shard.
+func (s *ShardService) DeleteShard(ctx context.Context, orgID, bucketID platform.ID, id string) error {
+	return s.DeleteShardFn(ctx, orgID, bucketID, id)
+}
+
+// BackupShard writes an archive of a shard to w.
+func (s *ShardService) BackupShard(ctx context.Context, orgID, bucketID platform.ID, id string, w io.Writer) error {
+	return s.BackupShardFn(ctx, orgID, bucketID, id, w)
+}
+
+// CompactShards schedules a full compaction of the shards.
+func (s *ShardService) CompactShards(ctx context.Context) error {
+	return s.CompactShardsFn(ctx)
+}
+
+// TopShards returns the most active shards.
+func (s *ShardService) TopShards(ctx context.Context, opts platform.TopShardsOptions) ([]*platform.ShardActivity, error) {
+	return s.TopShardsFn(ctx, opts)
+}
@@ -0,0 +1,72 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.SQLConnectionService = &SQLConnectionService{}
+var _ platform.SQLConnectionChecker = &SQLConnectionChecker{}
+
+// SQLConnectionService is a mock implementation of platform.SQLConnectionService
+type SQLConnectionService struct {
+	FindSQLConnectionByIDFn func(context.Context, platform.ID) (*platform.SQLConnection, error)
+	FindSQLConnectionsFn    func(context.Context, platform.SQLConnectionFilter) ([]*platform.SQLConnection, error)
+	CreateSQLConnectionFn   func(context.Context, *platform.SQLConnection) error
+	UpdateSQLConnectionFn   func(context.Context, platform.ID, platform.SQLConnectionUpdate) (*platform.SQLConnection, error)
+	DeleteSQLConnectionFn   func(context.Context, platform.ID) error
+}
+
+// NewSQLConnectionService returns a mock of SQLConnectionService
+// where its methods will return zero values.
+func NewSQLConnectionService() *SQLConnectionService {
+	return &SQLConnectionService{
+		FindSQLConnectionByIDFn: func(context.Context, platform.ID) (*platform.SQLConnection, error) {
+			return nil, nil
+		},
+		FindSQLConnectionsFn: func(context.Context, platform.SQLConnectionFilter) ([]*platform.SQLConnection, error) {
+			return []*platform.SQLConnection{}, nil
+		},
+		CreateSQLConnectionFn: func(context.Context, *platform.SQLConnection) error { return nil },
+		UpdateSQLConnectionFn: func(context.Context, platform.ID, platform.SQLConnectionUpdate) (*platform.SQLConnection, error) {
+			return nil, nil
+		},
+		DeleteSQLConnectionFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindSQLConnectionByID returns a single SQL connection by ID.
+func (s *SQLConnectionService) FindSQLConnectionByID(ctx context.Context, id platform.ID) (*platform.SQLConnection, error) {
+	return s.FindSQLConnectionByIDFn(ctx, id)
+}
+
+// FindSQLConnections returns the SQL connections that match a filter.
+func (s *SQLConnectionService) FindSQLConnections(ctx context.Context, filter platform.SQLConnectionFilter) ([]*platform.SQLConnection, error) {
+	return s.FindSQLConnectionsFn(ctx, filter)
+}
+
+// CreateSQLConnection creates a new SQL connection and sets c.ID with the new identifier.
+func (s *SQLConnectionService) CreateSQLConnection(ctx context.Context, c *platform.SQLConnection) error {
+	return s.CreateSQLConnectionFn(ctx, c)
+}
+
+// UpdateSQLConnection updates a single SQL connection with changeset.
+func (s *SQLConnectionService) UpdateSQLConnection(ctx context.Context, id platform.ID, upd platform.SQLConnectionUpdate) (*platform.SQLConnection, error) {
+	return s.UpdateSQLConnectionFn(ctx, id, upd)
+}
+
+// DeleteSQLConnection removes a SQL connection by ID.
+func (s *SQLConnectionService) DeleteSQLConnection(ctx context.Context, id platform.ID) error {
+	return s.DeleteSQLConnectionFn(ctx, id)
+}
+
+// SQLConnectionChecker is a mock implementation of platform.SQLConnectionChecker
+type SQLConnectionChecker struct {
+	CheckSQLConnectionFn func(context.Context, *platform.SQLConnection) error
+}
+
+// CheckSQLConnection returns an error if c cannot connect to its database.
+func (c *SQLConnectionChecker) CheckSQLConnection(ctx context.Context, conn *platform.SQLConnection) error {
+	return c.CheckSQLConnectionFn(ctx, conn)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.SubsystemService = &SubsystemService{}
+
+// SubsystemService is a mock implementation of platform.SubsystemService
+type SubsystemService struct {
+	FindSubsystemsFn   func(context.Context) ([]*platform.SubsystemStatus, error)
+	RestartSubsystemFn func(context.Context, string) (*platform.SubsystemStatus, error)
+}
+
+// NewSubsystemService returns a mock of SubsystemService
+// where its methods will return zero values.
+func NewSubsystemService() *SubsystemService {
+	return &SubsystemService{
+		FindSubsystemsFn: func(context.Context) ([]*platform.SubsystemStatus, error) {
+			return []*platform.SubsystemStatus{}, nil
+		},
+		RestartSubsystemFn: func(context.Context, string) (*platform.SubsystemStatus, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindSubsystems returns the status of every subsystem.
+func (s *SubsystemService) FindSubsystems(ctx context.Context) ([]*platform.SubsystemStatus, error) {
+	return s.FindSubsystemsFn(ctx)
+}
+
+// RestartSubsystem restarts a subsystem.
+func (s *SubsystemService) RestartSubsystem(ctx context.Context, name string) (*platform.SubsystemStatus, error) {
+	return s.RestartSubsystemFn(ctx, name)
+}
@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.TaskDependencyService = &TaskDependencyService{}
+
+// TaskDependencyService is a mock implementation of platform.TaskDependencyService
+type TaskDependencyService struct {
+	FindTaskDependenciesFn   func(context.Context, platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error)
+	PutTaskDependenciesFn    func(context.Context, *platform.TaskDependencies) error
+	DeleteTaskDependenciesFn func(context.Context, platform.ID) error
+}
+
+// NewTaskDependencyService returns a mock of TaskDependencyService
+// where its methods will return zero values.
+func NewTaskDependencyService() *TaskDependencyService {
+	return &TaskDependencyService{
+		FindTaskDependenciesFn: func(context.Context, platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error) {
+			return []*platform.TaskDependencies{}, nil
+		},
+		PutTaskDependenciesFn:    func(context.Context, *platform.TaskDependencies) error { return nil },
+		DeleteTaskDependenciesFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindTaskDependencies returns the dependencies of the tasks that match a filter.
+func (s *TaskDependencyService) FindTaskDependencies(ctx context.Context, filter platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error) {
+	return s.FindTaskDependenciesFn(ctx, filter)
+}
+
+// PutTaskDependencies replaces the dependencies of the task d.TaskID.
+func (s *TaskDependencyService) PutTaskDependencies(ctx context.Context, d *platform.TaskDependencies) error {
+	return s.PutTaskDependenciesFn(ctx, d)
+}
+
+// DeleteTaskDependencies removes the dependencies of a task.
+func (s *TaskDependencyService) DeleteTaskDependencies(ctx context.Context, taskID platform.ID) error {
+	return s.DeleteTaskDependenciesFn(ctx, taskID)
+}
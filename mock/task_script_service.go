@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.TaskScriptService = &TaskScriptService{}
+
+// TaskScriptService is a mock implementation of platform.TaskScriptService
+type TaskScriptService struct {
+	FindTaskScriptFn         func(context.Context, platform.ID, string, int) (*platform.TaskScript, error)
+	FindTaskScriptsFn        func(context.Context, platform.TaskScriptFilter) ([]*platform.TaskScript, error)
+	FindTaskScriptVersionsFn func(context.Context, platform.ID, string) ([]*platform.TaskScript, error)
+	CreateTaskScriptFn       func(context.Context, *platform.TaskScript) error
+	DeleteTaskScriptFn       func(context.Context, platform.ID, string) error
+}
+
+// NewTaskScriptService returns a mock of TaskScriptService
+// where its methods will return zero values.
+func NewTaskScriptService() *TaskScriptService {
+	return &TaskScriptService{
+		FindTaskScriptFn: func(context.Context, platform.ID, string, int) (*platform.TaskScript, error) {
+			return nil, nil
+		},
+		FindTaskScriptsFn: func(context.Context, platform.TaskScriptFilter) ([]*platform.TaskScript, error) {
+			return []*platform.TaskScript{}, nil
+		},
+		FindTaskScriptVersionsFn: func(context.Context, platform.ID, string) ([]*platform.TaskScript, error) {
+			return []*platform.TaskScript{}, nil
+		},
+		CreateTaskScriptFn: func(context.Context, *platform.TaskScript) error { return nil },
+		DeleteTaskScriptFn: func(context.Context, platform.ID, string) error { return nil },
+	}
+}
+
+// FindTaskScript returns a version of the named script of an organization, or its latest version if version is 0.
+func (s *TaskScriptService) FindTaskScript(ctx context.Context, orgID platform.ID, name string, version int) (*platform.TaskScript, error) {
+	return s.FindTaskScriptFn(ctx, orgID, name, version)
+}
+
+// FindTaskScripts returns the latest version of the scripts that match a filter.
+func (s *TaskScriptService) FindTaskScripts(ctx context.Context, filter platform.TaskScriptFilter) ([]*platform.TaskScript, error) {
+	return s.FindTaskScriptsFn(ctx, filter)
+}
+
+// FindTaskScriptVersions returns every version of the named script of an organization.
+func (s *TaskScriptService) FindTaskScriptVersions(ctx context.Context, orgID platform.ID, name string) ([]*platform.TaskScript, error) {
+	return s.FindTaskScriptVersionsFn(ctx, orgID, name)
+}
+
+// CreateTaskScript stores a new version of a task script.
+func (s *TaskScriptService) CreateTaskScript(ctx context.Context, ts *platform.TaskScript) error {
+	return s.CreateTaskScriptFn(ctx, ts)
+}
+
+// DeleteTaskScript removes every version of the named script of an organization.
+func (s *TaskScriptService) DeleteTaskScript(ctx context.Context, orgID platform.ID, name string) error {
+	return s.DeleteTaskScriptFn(ctx, orgID, name)
+}
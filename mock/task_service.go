@@ -20,6 +20,10 @@ type TaskService struct {
 	CancelRunFn    func(context.Context, platform.ID, platform.ID) error
 	RetryRunFn     func(context.Context, platform.ID, platform.ID) (*platform.Run, error)
 	ForceRunFn     func(context.Context, platform.ID, int64) (*platform.Run, error)
+
+	FindTaskVersionsFn func(context.Context, platform.ID) ([]*platform.TaskVersion, error)
+	RollbackTaskFn     func(context.Context, platform.ID, int) (*platform.Task, error)
+	MoveTaskFn         func(context.Context, platform.ID, platform.TaskMove) (*platform.Task, error)
 }
 
 func (s *TaskService) FindTaskByID(ctx context.Context, id platform.ID) (*platform.Task, error) {
@@ -65,3 +69,15 @@ func (s *TaskService) RetryRun(ctx context.Context, taskID, runID platform.ID) (
 func (s *TaskService) ForceRun(ctx context.Context, taskID platform.ID, scheduledFor int64) (*platform.Run, error) {
 	return s.ForceRunFn(ctx, taskID, scheduledFor)
 }
+
+func (s *TaskService) FindTaskVersions(ctx context.Context, taskID platform.ID) ([]*platform.TaskVersion, error) {
+	return s.FindTaskVersionsFn(ctx, taskID)
+}
+
+func (s *TaskService) RollbackTask(ctx context.Context, taskID platform.ID, version int) (*platform.Task, error) {
+	return s.RollbackTaskFn(ctx, taskID, version)
+}
+
+func (s *TaskService) MoveTask(ctx context.Context, taskID platform.ID, move platform.TaskMove) (*platform.Task, error) {
+	return s.MoveTaskFn(ctx, taskID, move)
+}
@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.TaskWebhookService = &TaskWebhookService{}
+
+// TaskWebhookService is a mock implementation of platform.TaskWebhookService
+type TaskWebhookService struct {
+	FindTaskWebhookByIDFn       func(context.Context, platform.ID) (*platform.TaskWebhook, error)
+	FindTaskWebhooksFn          func(context.Context, platform.TaskWebhookFilter) ([]*platform.TaskWebhook, error)
+	CreateTaskWebhookFn         func(context.Context, *platform.TaskWebhook) error
+	UpdateTaskWebhookFn         func(context.Context, platform.ID, platform.TaskWebhookUpdate) (*platform.TaskWebhook, error)
+	DeleteTaskWebhookFn         func(context.Context, platform.ID) error
+	AddTaskWebhookDeliveryFn    func(context.Context, *platform.TaskWebhookDelivery) error
+	FindTaskWebhookDeliveriesFn func(context.Context, platform.ID) ([]*platform.TaskWebhookDelivery, error)
+}
+
+// NewTaskWebhookService returns a mock of TaskWebhookService
+// where its methods will return zero values.
+func NewTaskWebhookService() *TaskWebhookService {
+	return &TaskWebhookService{
+		FindTaskWebhookByIDFn: func(context.Context, platform.ID) (*platform.TaskWebhook, error) {
+			return nil, nil
+		},
+		FindTaskWebhooksFn: func(context.Context, platform.TaskWebhookFilter) ([]*platform.TaskWebhook, error) {
+			return []*platform.TaskWebhook{}, nil
+		},
+		CreateTaskWebhookFn: func(context.Context, *platform.TaskWebhook) error { return nil },
+		UpdateTaskWebhookFn: func(context.Context, platform.ID, platform.TaskWebhookUpdate) (*platform.TaskWebhook, error) {
+			return nil, nil
+		},
+		DeleteTaskWebhookFn:      func(context.Context, platform.ID) error { return nil },
+		AddTaskWebhookDeliveryFn: func(context.Context, *platform.TaskWebhookDelivery) error { return nil },
+		FindTaskWebhookDeliveriesFn: func(context.Context, platform.ID) ([]*platform.TaskWebhookDelivery, error) {
+			return []*platform.TaskWebhookDelivery{}, nil
+		},
+	}
+}
+
+// FindTaskWebhookByID returns a single task webhook by ID.
+func (s *TaskWebhookService) FindTaskWebhookByID(ctx context.Context, id platform.ID) (*platform.TaskWebhook, error) {
+	return s.FindTaskWebhookByIDFn(ctx, id)
+}
+
+// FindTaskWebhooks returns the task webhooks that match a filter.
+func (s *TaskWebhookService) FindTaskWebhooks(ctx context.Context, filter platform.TaskWebhookFilter) ([]*platform.TaskWebhook, error) {
+	return s.FindTaskWebhooksFn(ctx, filter)
+}
+
+// CreateTaskWebhook creates a new task webhook and sets w.ID with the new identifier.
+func (s *TaskWebhookService) CreateTaskWebhook(ctx context.Context, w *platform.TaskWebhook) error {
+	return s.CreateTaskWebhookFn(ctx, w)
+}
+
+// UpdateTaskWebhook updates a single task webhook with changeset.
+func (s *TaskWebhookService) UpdateTaskWebhook(ctx context.Context, id platform.ID, upd platform.TaskWebhookUpdate) (*platform.TaskWebhook, error) {
+	return s.UpdateTaskWebhookFn(ctx, id, upd)
+}
+
+// DeleteTaskWebhook removes a task webhook by ID.
+func (s *TaskWebhookService) DeleteTaskWebhook(ctx context.Context, id platform.ID) error {
+	return s.DeleteTaskWebhookFn(ctx, id)
+}
+
+// AddTaskWebhookDelivery records a delivery attempt in the delivery log of its webhook.
+func (s *TaskWebhookService) AddTaskWebhookDelivery(ctx context.Context, d *platform.TaskWebhookDelivery) error {
+	return s.AddTaskWebhookDeliveryFn(ctx, d)
+}
+
+// FindTaskWebhookDeliveries returns the delivery log of a task webhook.
+func (s *TaskWebhookService) FindTaskWebhookDeliveries(ctx context.Context, webhookID platform.ID) ([]*platform.TaskWebhookDelivery, error) {
+	return s.FindTaskWebhookDeliveriesFn(ctx, webhookID)
+}
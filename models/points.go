@@ -361,13 +361,57 @@ func ValidPrecision(precision string) bool {
 // NOTE: to minimize heap allocations, the returned Points will refer to subslices of buf.
 // This can have the unintended effect preventing buf from being garbage collected.
 func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
+	points, _, err := parsePoints(buf, defaultTime, precision, parsePoint, false)
+	return points, err
+}
+
+// ParsePointsWithLines is similar to ParsePointsWithPrecision, but also returns the number of the line
+// of each point, starting at 1. The lines that could not be parsed are returned as a LineErrors.
+func ParsePointsWithLines(buf []byte, defaultTime time.Time, precision string) ([]Point, []int, error) {
+	return parsePoints(buf, defaultTime, precision, parsePoint, true)
+}
+
+// LineError is the error parsing a line of line protocol.
+type LineError struct {
+	Line int    // The number of the line, starting at 1.
+	Text string // The text of the line.
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s': %v", e.Text, e.Err)
+}
+
+// LineErrors are the errors parsing the lines of line protocol, in the order of the lines.
+type LineErrors []*LineError
+
+func (e LineErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// parsePoints parses each line of buf with parse, and returns the number of the line of each point if withLines is true.
+func parsePoints(buf []byte, defaultTime time.Time, precision string, parse func([]byte, time.Time, string) (Point, error), withLines bool) ([]Point, []int, error) {
 	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
 	var (
 		pos    int
 		block  []byte
-		failed []string
+		lines  []int
+		failed LineErrors
 	)
+	if withLines {
+		lines = make([]int, 0, cap(points))
+	}
+	// line is the number of the line of the block; a block spans several lines
+	// if a string field value holds newlines.
+	line, counted := 1, 0
 	for pos < len(buf) {
+		line += bytes.Count(buf[counted:pos], []byte{'\n'})
+		counted = pos
+
 		pos, block = scanLine(buf, pos)
 		pos++
 
@@ -392,18 +436,21 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 			block = block[:len(block)-1]
 		}
 
-		pt, err := parsePoint(block[start:], defaultTime, precision)
+		pt, err := parse(block[start:], defaultTime, precision)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
+			failed = append(failed, &LineError{Line: line, Text: string(block[start:]), Err: err})
 		} else {
 			points = append(points, pt)
+			if withLines {
+				lines = append(lines, line)
+			}
 		}
 
 	}
 	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+		return points, lines, failed
 	}
-	return points, nil
+	return points, lines, nil
 
 }
 
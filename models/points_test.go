@@ -2540,3 +2540,167 @@ func BenchmarkMakeKey(b *testing.B) {
 		})
 	}
 }
+
+func TestParsePointsV2(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		precision string
+		exp       string
+		fields    models.Fields
+		time      time.Time
+	}{
+		{
+			name:      "v1 line",
+			line:      `cpu,host=serverA value=1.0,str="foo\"bar" 1000`,
+			precision: "s",
+			exp:       `cpu,host=serverA value=1.0,str="foo\"bar" 1000000000000`,
+			fields:    models.Fields{"value": 1.0, "str": `foo"bar`},
+			time:      time.Unix(1000, 0),
+		},
+		{
+			name:      "escaped newline in string field",
+			line:      `cpu str="foo\nbar",value=1i 1000`,
+			precision: "ns",
+			fields:    models.Fields{"str": "foo\nbar", "value": int64(1)},
+			time:      time.Unix(0, 1000),
+		},
+		{
+			name:      "escaped backslash before n in string field",
+			line:      `cpu str="foo\\nbar" 1000`,
+			precision: "ns",
+			fields:    models.Fields{"str": `foo\nbar`},
+			time:      time.Unix(0, 1000),
+		},
+		{
+			name:      "RFC3339 timestamp ignores precision",
+			line:      `cpu value=1 2019-03-01T12:00:00.000000001Z`,
+			precision: "s",
+			fields:    models.Fields{"value": 1.0},
+			time:      time.Date(2019, 3, 1, 12, 0, 0, 1, time.UTC),
+		},
+		{
+			name:      "RFC3339 timestamp with offset",
+			line:      `cpu value=1 2019-03-01T12:00:00+01:00 `,
+			precision: "ns",
+			fields:    models.Fields{"value": 1.0},
+			time:      time.Date(2019, 3, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "boolean shorthand",
+			line:      `cpu,host=serverA online,value=1,idle 1000`,
+			precision: "ns",
+			fields:    models.Fields{"online": true, "value": 1.0, "idle": true},
+			time:      time.Unix(0, 1000),
+		},
+		{
+			name:      "boolean shorthand with escaped key",
+			line:      `cpu is\ up`,
+			precision: "ns",
+			fields:    models.Fields{"is up": true},
+			time:      time.Unix(0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pts, err := models.ParsePointsV2([]byte(tt.line), time.Unix(0, 0), tt.precision)
+			if err != nil {
+				t.Fatalf("ParsePointsV2(%q) unexpected error: %v", tt.line, err)
+			}
+			if len(pts) != 1 {
+				t.Fatalf("ParsePointsV2(%q) got %d points, exp 1", tt.line, len(pts))
+			}
+			fields, err := pts[0].Fields()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("ParsePointsV2(%q) fields mismatch.\ngot %v\nexp %v", tt.line, fields, tt.fields)
+			}
+			if !pts[0].Time().Equal(tt.time) {
+				t.Errorf("ParsePointsV2(%q) time mismatch. got %v, exp %v", tt.line, pts[0].Time(), tt.time)
+			}
+			if tt.exp != "" && pts[0].String() != tt.exp {
+				t.Errorf("ParsePointsV2(%q) string mismatch.\ngot %s\nexp %s", tt.line, pts[0].String(), tt.exp)
+			}
+		})
+	}
+}
+
+func TestParsePointsV2_Invalid(t *testing.T) {
+	for _, line := range []string{
+		`cpu`,
+		`cpu str="foo`,
+		`cpu value=1,`,
+		`cpu value=1 2019-03-01`,
+		`cpu value=1 2019-03-01T12:00:00Z extra`,
+		`cpu value=1 3000-01-01T00:00:00Z`,
+		`cpu value= 1000`,
+	} {
+		if _, err := models.ParsePointsV2([]byte(line), time.Unix(0, 0), "ns"); err == nil {
+			t.Errorf("ParsePointsV2(%q) expected error", line)
+		}
+	}
+}
+
+func TestParsePoints_StrictV1(t *testing.T) {
+	// The v2 extensions are not valid or have another meaning in v1.
+	if _, err := models.ParsePointsString(`cpu online`); err == nil {
+		t.Error("expected boolean shorthand to be invalid in v1")
+	}
+	if _, err := models.ParsePointsString(`cpu value=1 2019-03-01T12:00:00Z`); err == nil {
+		t.Error("expected RFC3339 timestamp to be invalid in v1")
+	}
+
+	pts, err := models.ParsePointsString(`cpu str="foo\nbar"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := pts[0].Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := fields["str"], `foo\nbar`; got != exp {
+		t.Errorf("expected literal \\n in v1 string field. got %q, exp %q", got, exp)
+	}
+}
+
+func TestParsePointsWithLines(t *testing.T) {
+	buf := `# comment
+cpu value=1 1
+
+cpu value=
+cpu value="multi
+line" 2
+cpu value=3 3
+mem value=t=
+`
+	points, lines, err := models.ParsePointsWithLines([]byte(buf), time.Unix(0, 0), "ns")
+	if len(points) != 3 {
+		t.Fatalf("got %d points, want 3", len(points))
+	}
+	if exp := []int{2, 5, 7}; !reflect.DeepEqual(lines, exp) {
+		t.Errorf("got lines %v, want %v", lines, exp)
+	}
+
+	errs, ok := err.(models.LineErrors)
+	if !ok {
+		t.Fatalf("expected line errors, got %T: %v", err, err)
+	}
+	if len(errs) != 2 || errs[0].Line != 4 || errs[0].Text != "cpu value=" || errs[1].Line != 8 {
+		t.Fatalf("unexpected line errors %v", errs)
+	}
+	if got, exp := err.Error(), errs[0].Error()+"\n"+errs[1].Error(); got != exp {
+		t.Errorf("unexpected error %q, want %q", got, exp)
+	}
+
+	// The lines of the v2 dialect are numbered the same.
+	points, lines, err = models.ParsePointsV2WithLines([]byte("cpu value=1 1\n\ncpu online 2\n"), time.Unix(0, 0), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []int{1, 3}; len(points) != 2 || !reflect.DeepEqual(lines, exp) {
+		t.Errorf("got %d points on lines %v, want 2 on lines %v", len(points), lines, exp)
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParsePointsV2 is similar to ParsePointsWithPrecision, but parses the v2 dialect of the line protocol.
+// The v2 dialect relaxes the syntax of v1 with:
+//
+//   - escaped newlines in string field values, e.g. str="line 1\nline 2";
+//   - timestamps as RFC3339 strings with nanosecond precision, e.g. 2019-03-01T12:00:00.000000001Z,
+//     which are exact regardless of precision;
+//   - a boolean shorthand, where a field key without a value is a true boolean field,
+//     e.g. "host,name=a online,load=0.5".
+//
+// A line valid in both dialects has the same meaning in both, except for string field values containing
+// the sequence \n, which is literal in v1. The dialects can therefore not be mixed, and v1 remains the default.
+//
+// NOTE: unlike ParsePointsWithPrecision, the returned Points do not refer to subslices of buf.
+func ParsePointsV2(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
+	points, _, err := parsePoints(buf, defaultTime, precision, parsePointV2, false)
+	return points, err
+}
+
+// ParsePointsV2WithLines is similar to ParsePointsV2, but also returns the number of the line
+// of each point, starting at 1. The lines that could not be parsed are returned as a LineErrors.
+func ParsePointsV2WithLines(buf []byte, defaultTime time.Time, precision string) ([]Point, []int, error) {
+	return parsePoints(buf, defaultTime, precision, parsePointV2, true)
+}
+
+// parsePointV2 rewrites a v2 line into its v1 equivalent and parses it.
+func parsePointV2(buf []byte, defaultTime time.Time, precision string) (Point, error) {
+	line, exact, err := rewriteV2Line(buf)
+	if err != nil {
+		return nil, err
+	}
+	if exact {
+		precision = "n"
+	}
+	return parsePoint(line, defaultTime, precision)
+}
+
+// rewriteV2Line returns the v1 equivalent of the v2 line buf, and whether its timestamp
+// is in nanoseconds because it was written as an RFC3339 string.
+// Syntax errors that the dialects have in common are left to parsePoint.
+func rewriteV2Line(buf []byte) ([]byte, bool, error) {
+	// The key ends at the first unescaped space; quotes are not significant in it.
+	i := 0
+	for i < len(buf) && buf[i] != ' ' {
+		if buf[i] == '\\' && i+1 < len(buf) {
+			i++
+		}
+		i++
+	}
+
+	out := make([]byte, 0, len(buf)+16)
+	out = append(out, buf[:i]...)
+
+	i = skipWhitespace(buf, i)
+	if i >= len(buf) {
+		return buf, false, nil
+	}
+	out = append(out, ' ')
+
+	for {
+		start := i
+		for i < len(buf) && buf[i] != '=' && buf[i] != ',' && buf[i] != ' ' {
+			if buf[i] == '\\' && i+1 < len(buf) {
+				i++
+			}
+			i++
+		}
+		out = append(out, buf[start:i]...)
+
+		if i < len(buf) && buf[i] == '=' {
+			out = append(out, '=')
+			i++
+			if i < len(buf) && buf[i] == '"' {
+				var err error
+				if i, out, err = appendV2StringField(out, buf, i); err != nil {
+					return nil, false, err
+				}
+			} else {
+				start = i
+				for i < len(buf) && buf[i] != ',' && buf[i] != ' ' {
+					i++
+				}
+				out = append(out, buf[start:i]...)
+			}
+		} else if i > start {
+			// Boolean shorthand.
+			out = append(out, "=true"...)
+		}
+
+		if i < len(buf) && buf[i] == ',' {
+			out = append(out, ',')
+			i++
+			continue
+		}
+		break
+	}
+
+	end := skipWhitespace(buf, i)
+	tsEnd := end
+	for tsEnd < len(buf) && buf[tsEnd] != ' ' {
+		tsEnd++
+	}
+	ts, err := time.Parse(time.RFC3339Nano, string(buf[end:tsEnd]))
+	if err != nil {
+		// Not an RFC3339 timestamp, so it must be a v1 timestamp.
+		return append(out, buf[i:]...), false, nil
+	}
+	if err := CheckTime(ts); err != nil {
+		return nil, false, err
+	}
+	out = append(out, ' ')
+	out = strconv.AppendInt(out, ts.UnixNano(), 10)
+	return append(out, buf[tsEnd:]...), true, nil
+}
+
+// appendV2StringField appends the v1 equivalent of the quoted string field value starting at buf[i] to out,
+// and returns the position in buf after the value.
+func appendV2StringField(out, buf []byte, i int) (int, []byte, error) {
+	out = append(out, '"')
+	i++
+	for i < len(buf) {
+		switch {
+		case buf[i] == '\\' && i+1 < len(buf):
+			if buf[i+1] == 'n' {
+				out = append(out, '\n')
+			} else {
+				out = append(out, buf[i], buf[i+1])
+			}
+			i += 2
+		case buf[i] == '"':
+			return i + 1, append(out, '"'), nil
+		default:
+			out = append(out, buf[i])
+			i++
+		}
+	}
+	return i, out, fmt.Errorf("unbalanced quotes")
+}
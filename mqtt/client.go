@@ -0,0 +1,176 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKeepAlive is the keep alive interval of the connections to the broker, by default.
+	DefaultKeepAlive = 30 * time.Second
+
+	// DefaultConnectTimeout is how long connecting to the broker may take, by default.
+	DefaultConnectTimeout = 10 * time.Second
+)
+
+// ClientOptions configures the connections to an MQTT broker.
+type ClientOptions struct {
+	ClientID string
+	Username string
+	Password string
+
+	// KeepAlive is the interval the connections are kept alive at. It defaults to DefaultKeepAlive.
+	KeepAlive time.Duration
+
+	// ConnectTimeout is how long connecting may take. It defaults to DefaultConnectTimeout.
+	ConnectTimeout time.Duration
+
+	// TLSConfig configures the connections to the brokers of ssl:// URLs.
+	TLSConfig *tls.Config
+}
+
+// client is a connection to an MQTT 3.1.1 broker, receiving the messages of its subscriptions
+// with a QoS of at most 1. The messages of QoS 1 are acknowledged once they are handled.
+type client struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	keepAlive time.Duration
+	filters   []string
+
+	wmu sync.Mutex
+}
+
+// dial connects to broker, a tcp:// or ssl:// URL, and returns once the broker accepts the connection.
+func dial(broker string, opts ClientOptions) (*client, error) {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = DefaultKeepAlive
+	}
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = DefaultConnectTimeout
+	}
+
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, err
+	}
+	d := &net.Dialer{Timeout: opts.ConnectTimeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		conn, err = d.Dial("tcp", u.Host)
+	case "ssl", "tls", "mqtts":
+		conn, err = tls.DialWithDialer(d, "tcp", u.Host, opts.TLSConfig)
+	default:
+		return nil, fmt.Errorf("unsupported MQTT broker scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		conn:      conn,
+		r:         bufio.NewReader(conn),
+		keepAlive: opts.KeepAlive,
+	}
+	if err := c.connect(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *client) connect(opts ClientOptions) error {
+	c.conn.SetDeadline(time.Now().Add(opts.ConnectTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.write(packetConnect, 0, connectBody(opts)); err != nil {
+		return err
+	}
+	p, err := readPacket(c.r)
+	if err != nil {
+		return err
+	}
+	if p.typ != packetConnAck {
+		return fmt.Errorf("expected an MQTT CONNACK packet, got packet type %d", p.typ)
+	}
+	return connAckError(p)
+}
+
+func (c *client) write(typ, flags byte, body []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return writePacket(c.conn, typ, flags, body)
+}
+
+// subscribe subscribes to filters, with the QoS of each filter. Whether the broker accepts them is checked by run.
+func (c *client) subscribe(filters []string, qos []byte) error {
+	c.filters = filters
+	return c.write(packetSubscribe, 0x02, subscribeBody(1, filters, qos))
+}
+
+// run calls handle with the topic and payload of every message received, until the connection fails or is closed,
+// or the broker refuses a subscription. The connection is kept alive meanwhile.
+func (c *client) run(handle func(topic string, payload []byte)) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(c.keepAlive / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.write(packetPingReq, 0, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		// The broker answers the pings, so a connection silent for longer is lost.
+		c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		p, err := readPacket(c.r)
+		if err != nil {
+			return err
+		}
+
+		switch p.typ {
+		case packetPublish:
+			topic, qos, id, payload, err := decodePublish(p)
+			if err != nil {
+				return err
+			}
+			handle(topic, payload)
+			if qos > 0 {
+				if err := c.write(packetPubAck, 0, appendUint16(nil, id)); err != nil {
+					return err
+				}
+			}
+		case packetSubAck:
+			if len(p.body) < 2 {
+				return errMalformedPacket
+			}
+			for i, code := range p.body[2:] {
+				if code == 0x80 && i < len(c.filters) {
+					return fmt.Errorf("MQTT broker refused the subscription to %q", c.filters[i])
+				}
+			}
+		case packetPingResp:
+		default:
+			return fmt.Errorf("unexpected MQTT packet type %d", p.typ)
+		}
+	}
+}
+
+// close disconnects from the broker.
+func (c *client) close() error {
+	c.write(packetDisconnect, 0, nil)
+	return c.conn.Close()
+}
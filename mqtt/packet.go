@@ -0,0 +1,191 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The control packet types of MQTT 3.1.1 used by the client.
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetSubscribe  = 8
+	packetSubAck     = 9
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+// maxPacketSize is the size of the largest packet read, to not read arbitrary large payloads in memory.
+const maxPacketSize = 16 << 20
+
+var errMalformedPacket = errors.New("malformed MQTT packet")
+
+// packet is an MQTT control packet, with the flags of its fixed header and its variable header and payload as body.
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (*packet, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// The remaining length is encoded in up to 4 bytes, 7 bits at a time.
+	var n, shift int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, errMalformedPacket
+		}
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		n |= int(c&0x7f) << shift
+		if c&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	if n > maxPacketSize {
+		return nil, fmt.Errorf("MQTT packet of %d bytes is too large", n)
+	}
+
+	p := &packet{typ: b >> 4, flags: b & 0x0f, body: make([]byte, n)}
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func writePacket(w io.Writer, typ, flags byte, body []byte) error {
+	buf := make([]byte, 0, len(body)+5)
+	buf = append(buf, typ<<4|flags)
+	n := len(body)
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n > 0 {
+			c |= 0x80
+		}
+		buf = append(buf, c)
+		if n == 0 {
+			break
+		}
+	}
+	buf = append(buf, body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+func appendUint16(buf []byte, v uint16) []byte {
+	return append(buf, byte(v>>8), byte(v))
+}
+
+// readString reads a length-prefixed string of buf, and returns the rest of buf.
+func readString(buf []byte) (string, []byte, error) {
+	if len(buf) < 2 {
+		return "", nil, errMalformedPacket
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	if len(buf) < 2+n {
+		return "", nil, errMalformedPacket
+	}
+	return string(buf[2 : 2+n]), buf[2+n:], nil
+}
+
+// connectBody returns the body of a CONNECT packet asking for a clean session.
+func connectBody(opts ClientOptions) []byte {
+	flags := byte(0x02) // Clean session.
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+
+	var buf []byte
+	buf = appendString(buf, "MQTT")
+	buf = append(buf, 4, flags) // Protocol level 4 is MQTT 3.1.1.
+	buf = appendUint16(buf, uint16(opts.KeepAlive.Seconds()))
+	buf = appendString(buf, opts.ClientID)
+	if opts.Username != "" {
+		buf = appendString(buf, opts.Username)
+	}
+	if opts.Password != "" {
+		buf = appendString(buf, opts.Password)
+	}
+	return buf
+}
+
+// connAckError returns the error of the return code of a CONNACK packet, or nil if the connection is accepted.
+func connAckError(p *packet) error {
+	if len(p.body) != 2 {
+		return errMalformedPacket
+	}
+	switch code := p.body[1]; code {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("MQTT broker refused the connection: unacceptable protocol version")
+	case 2:
+		return errors.New("MQTT broker refused the connection: identifier rejected")
+	case 3:
+		return errors.New("MQTT broker refused the connection: server unavailable")
+	case 4:
+		return errors.New("MQTT broker refused the connection: bad user name or password")
+	case 5:
+		return errors.New("MQTT broker refused the connection: not authorized")
+	default:
+		return fmt.Errorf("MQTT broker refused the connection: return code %d", code)
+	}
+}
+
+// subscribeBody returns the body of a SUBSCRIBE packet to filters, with the QoS of each filter.
+func subscribeBody(id uint16, filters []string, qos []byte) []byte {
+	buf := appendUint16(nil, id)
+	for i, f := range filters {
+		buf = appendString(buf, f)
+		buf = append(buf, qos[i])
+	}
+	return buf
+}
+
+// publishBody returns the body of a PUBLISH packet, with a packet identifier if qos is above 0.
+func publishBody(topic string, id uint16, qos byte, payload []byte) []byte {
+	buf := appendString(nil, topic)
+	if qos > 0 {
+		buf = appendUint16(buf, id)
+	}
+	return append(buf, payload...)
+}
+
+// decodePublish returns the topic, the QoS, the packet identifier and the payload of a PUBLISH packet.
+func decodePublish(p *packet) (topic string, qos byte, id uint16, payload []byte, err error) {
+	qos = (p.flags >> 1) & 0x03
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return "", 0, 0, nil, err
+	}
+	if qos > 0 {
+		if len(rest) < 2 {
+			return "", 0, 0, nil, errMalformedPacket
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	return topic, qos, id, rest, nil
+}
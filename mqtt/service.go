@@ -0,0 +1,211 @@
+// Package mqtt subscribes to the topics of an MQTT broker and writes the messages it receives to buckets,
+// for devices publishing their measurements to a broker to be stored without an agent in between.
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap"
+)
+
+const (
+	// minReconnectDelay and maxReconnectDelay bound the delay before reconnecting to the broker,
+	// which doubles with every failed attempt.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// PointsWriter writes points to the storage engine.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// Service subscribes to the topics of the subscriptions on an MQTT broker, and writes the messages of each topic
+// to the buckets of the subscriptions matching it. The messages that cannot be parsed or written are logged and dropped,
+// as are the points not conforming to the schema of an explicit-schema bucket.
+//
+// The service connects with a clean session, and reconnects whenever the connection is lost,
+// so the messages published while it is disconnected are only received if they are retained.
+type Service struct {
+	Broker        string
+	Options       ClientOptions
+	Subscriptions []Subscription
+	Writer        PointsWriter
+
+	// SchemaChecker, if set, checks the points against the schemas of the explicit-schema buckets.
+	SchemaChecker *write.SchemaChecker
+
+	Now    func() time.Time
+	Logger *zap.Logger
+
+	mu      sync.Mutex
+	client  *client
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewService returns a Service writing the messages of the subscriptions of broker with w.
+func NewService(broker string, opts ClientOptions, subs []Subscription, w PointsWriter, logger *zap.Logger) *Service {
+	return &Service{
+		Broker:        broker,
+		Options:       opts,
+		Subscriptions: subs,
+		Writer:        w,
+		Now:           time.Now,
+		Logger:        logger,
+	}
+}
+
+// Open validates the subscriptions, and connects to the broker in the background until Close is called.
+func (s *Service) Open() error {
+	if len(s.Subscriptions) == 0 {
+		return fmt.Errorf("no MQTT subscriptions")
+	}
+	for i := range s.Subscriptions {
+		if err := s.Subscriptions[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing != nil {
+		return nil
+	}
+	s.closing = make(chan struct{})
+
+	s.wg.Add(1)
+	go func(closing chan struct{}) {
+		defer s.wg.Done()
+		s.run(closing)
+	}(s.closing)
+	return nil
+}
+
+// Close disconnects from the broker and waits for the messages being written.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.closing == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.closing)
+	s.closing = nil
+	if s.client != nil {
+		s.client.close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// run connects to the broker until closing is closed, waiting longer after every failed attempt.
+func (s *Service) run(closing chan struct{}) {
+	delay := minReconnectDelay
+	for {
+		connected, err := s.serve(closing)
+		select {
+		case <-closing:
+			return
+		default:
+		}
+
+		if connected {
+			delay = minReconnectDelay
+		}
+		s.Logger.Info("MQTT connection lost, reconnecting", zap.String("broker", s.Broker), zap.Duration("delay", delay), zap.Error(err))
+		select {
+		case <-closing:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// serve connects to the broker and handles the messages of the subscriptions until the connection ends,
+// and returns whether it connected.
+func (s *Service) serve(closing chan struct{}) (bool, error) {
+	c, err := dial(s.Broker, s.Options)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	select {
+	case <-closing:
+		s.mu.Unlock()
+		return true, c.close()
+	default:
+	}
+	s.client = c
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.client = nil
+		s.mu.Unlock()
+		c.close()
+	}()
+
+	filters := make([]string, 0, len(s.Subscriptions))
+	qos := make([]byte, 0, len(s.Subscriptions))
+	for _, sub := range s.Subscriptions {
+		filters = append(filters, sub.Topic)
+		qos = append(qos, sub.QoS)
+	}
+	if err := c.subscribe(filters, qos); err != nil {
+		return true, err
+	}
+	s.Logger.Info("Subscribed to MQTT topics", zap.String("broker", s.Broker), zap.Strings("topics", filters))
+
+	return true, c.run(s.handle)
+}
+
+// handle writes the points of a message to the buckets of the subscriptions matching its topic.
+func (s *Service) handle(topic string, payload []byte) {
+	now := s.Now()
+	for i := range s.Subscriptions {
+		sub := &s.Subscriptions[i]
+		if !MatchTopic(sub.Topic, topic) {
+			continue
+		}
+
+		l := s.Logger.With(zap.String("topic", topic), zap.String("bucket_id", sub.BucketID.String()))
+		points, err := sub.Points(topic, payload, now)
+		if err != nil {
+			l.Info("Dropping MQTT message that cannot be parsed", zap.Error(err))
+			continue
+		}
+		if s.SchemaChecker != nil {
+			var rejected []write.RejectedPoint
+			if points, rejected, err = s.SchemaChecker.CheckSchema(context.Background(), sub.BucketID, points); err != nil {
+				l.Error("Failed to check MQTT message against the bucket schema", zap.Error(err))
+				continue
+			}
+			for _, rp := range rejected {
+				l.Info("Dropping MQTT point not conforming to the bucket schema", zap.Error(rp.Err))
+			}
+			if len(points) == 0 {
+				continue
+			}
+		}
+		exploded, err := tsdb.ExplodePoints(sub.OrgID, sub.BucketID, points)
+		if err != nil {
+			l.Info("Dropping MQTT message that cannot be written", zap.Error(err))
+			continue
+		}
+		if err := s.Writer.WritePoints(context.Background(), exploded); err != nil {
+			l.Error("Failed to write MQTT message", zap.Int("points", len(points)), zap.Error(err))
+		}
+	}
+}
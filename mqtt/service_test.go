@@ -0,0 +1,186 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingWriter struct {
+	mu     sync.Mutex
+	points []models.Point
+	writes chan struct{}
+}
+
+func (w *recordingWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	w.points = append(w.points, points...)
+	w.mu.Unlock()
+	w.writes <- struct{}{}
+	return nil
+}
+
+// broker accepts a connection and its subscription, then publishes the messages of topics and waits for them to be acknowledged.
+func broker(t *testing.T, ln net.Listener, msgs [][2]string, done chan<- error) {
+	conn, err := ln.Accept()
+	if err != nil {
+		done <- err
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	p, err := readPacket(r)
+	if err != nil {
+		done <- err
+		return
+	}
+	if p.typ != packetConnect {
+		t.Errorf("expected a CONNECT packet, got type %d", p.typ)
+	}
+	name, rest, _ := readString(p.body)
+	if name != "MQTT" || rest[0] != 4 || rest[1] != 0xc2 {
+		t.Errorf("unexpected CONNECT packet %v", p.body)
+	}
+	clientID, rest, _ := readString(rest[4:])
+	user, rest, _ := readString(rest)
+	password, _, _ := readString(rest)
+	if clientID != "influxd" || user != "iot" || password != "secret" {
+		t.Errorf("unexpected credentials %q %q %q", clientID, user, password)
+	}
+	writePacket(conn, packetConnAck, 0, []byte{0, 0})
+
+	if p, err = readPacket(r); err != nil {
+		done <- err
+		return
+	}
+	if p.typ != packetSubscribe || p.flags != 0x02 {
+		t.Errorf("expected a SUBSCRIBE packet, got type %d", p.typ)
+	}
+	filter, rest, _ := readString(p.body[2:])
+	if filter != "sensors/+" || rest[0] != 1 {
+		t.Errorf("unexpected subscription %q with QoS %d", filter, rest[0])
+	}
+	writePacket(conn, packetSubAck, 0, append(p.body[:2:2], 1))
+
+	for i, msg := range msgs {
+		id := uint16(i + 1)
+		writePacket(conn, packetPublish, 1<<1, publishBody(msg[0], id, 1, []byte(msg[1])))
+		if p, err = readPacket(r); err != nil {
+			done <- err
+			return
+		}
+		// Pings may arrive before the acknowledgement.
+		for p.typ == packetPingReq {
+			if p, err = readPacket(r); err != nil {
+				done <- err
+				return
+			}
+		}
+		if p.typ != packetPubAck || len(p.body) != 2 || uint16(p.body[0])<<8|uint16(p.body[1]) != id {
+			t.Errorf("expected a PUBACK of message %d, got type %d %v", id, p.typ, p.body)
+		}
+	}
+	done <- nil
+}
+
+func TestService(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	done := make(chan error, 1)
+	go broker(t, ln, [][2]string{
+		{"sensors/kitchen", "temp value=21.5 1560168000000000000"},
+		{"sensors/hall", "temp value="},
+		{"sensors/hall", "temp value=19 1560168000000000000"},
+	}, done)
+
+	w := &recordingWriter{writes: make(chan struct{}, 10)}
+	s := NewService("tcp://"+ln.Addr().String(), ClientOptions{
+		ClientID: "influxd",
+		Username: "iot",
+		Password: "secret",
+	}, []Subscription{
+		{Topic: "sensors/+", QoS: 1, OrgID: 1, BucketID: 2, TopicTag: "topic"},
+	}, w, zaptest.NewLogger(t))
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the messages to be acknowledged")
+	}
+
+	// The messages are acknowledged once written, and the invalid one is dropped.
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.points) != 2 {
+		t.Fatalf("expected 2 exploded points, got %d", len(w.points))
+	}
+	for i, room := range []string{"kitchen", "hall"} {
+		if topic := w.points[i].Tags().GetString("topic"); topic != "sensors/"+room {
+			t.Errorf("unexpected point %s", w.points[i])
+		}
+	}
+}
+
+func TestService_OpenInvalid(t *testing.T) {
+	s := NewService("tcp://127.0.0.1:1883", ClientOptions{}, []Subscription{{Topic: "sensors/#"}}, &recordingWriter{}, zaptest.NewLogger(t))
+	if err := s.Open(); err == nil {
+		s.Close()
+		t.Fatal("expected an error for a subscription without a bucket")
+	}
+}
+
+func TestService_Schema(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		return []*platform.MeasurementSchema{{
+			Name:   "temp",
+			Tags:   []string{"topic"},
+			Fields: []platform.MeasurementSchemaField{{Name: "value", Type: platform.SchemaFieldTypeFloat}},
+		}}, nil
+	}
+
+	w := &recordingWriter{writes: make(chan struct{}, 10)}
+	s := NewService("tcp://127.0.0.1:1883", ClientOptions{}, []Subscription{
+		{Topic: "sensors/+", OrgID: 1, BucketID: 2, TopicTag: "topic"},
+	}, w, zaptest.NewLogger(t))
+	s.SchemaChecker = &write.SchemaChecker{BucketService: buckets, MeasurementSchemaService: schemas}
+
+	// The points not conforming to the schema are dropped, and the others written.
+	s.handle("sensors/kitchen", []byte("temp value=21.5 1560168000000000000\ntemp value=\"warm\" 1560168000000000000\nhumidity value=40 1560168000000000000"))
+	s.handle("sensors/hall", []byte("humidity value=40 1560168000000000000"))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.points) != 1 {
+		t.Fatalf("expected 1 exploded point, got %d", len(w.points))
+	}
+	if topic := w.points[0].Tags().GetString("topic"); topic != "sensors/kitchen" {
+		t.Errorf("unexpected point %s", w.points[0])
+	}
+}
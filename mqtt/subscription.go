@@ -0,0 +1,131 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/write"
+)
+
+// Subscription writes the messages of the topics matching Topic to a bucket.
+//
+// The payload of a message is either line protocol, or JSON mapped to points by the JSON mapping,
+// as set by Format, one of write.FormatLineProtocol and write.FormatJSON.
+// Precision is the precision of the timestamps of the payloads, ns by default.
+// If TopicTag is set, the points are tagged with the topic of their message, with TopicTag as key.
+type Subscription struct {
+	Topic     string             `json:"topic"`
+	QoS       byte               `json:"qos"`
+	OrgID     platform.ID        `json:"orgID"`
+	BucketID  platform.ID        `json:"bucketID"`
+	Format    string             `json:"format"`
+	Precision string             `json:"precision,omitempty"`
+	TopicTag  string             `json:"topicTag,omitempty"`
+	JSON      *write.JSONMapping `json:"json,omitempty"`
+}
+
+// Validate returns an error if the subscription is invalid.
+func (s *Subscription) Validate() error {
+	if err := validateFilter(s.Topic); err != nil {
+		return err
+	}
+	if s.QoS > 1 {
+		return fmt.Errorf("subscription to %q: qos must be 0 or 1", s.Topic)
+	}
+	if !s.OrgID.Valid() || !s.BucketID.Valid() {
+		return fmt.Errorf("subscription to %q: orgID and bucketID are required", s.Topic)
+	}
+	if s.Precision != "" && !models.ValidPrecision(s.Precision) {
+		return fmt.Errorf("subscription to %q: precision must be ns, us, ms or s", s.Topic)
+	}
+	switch s.Format {
+	case "", write.FormatLineProtocol:
+	case write.FormatJSON:
+		if s.JSON == nil {
+			return fmt.Errorf("subscription to %q: the json format requires a json mapping", s.Topic)
+		}
+		if err := s.JSON.Validate(); err != nil {
+			return fmt.Errorf("subscription to %q: %v", s.Topic, err)
+		}
+	default:
+		return fmt.Errorf("subscription to %q: unknown format %q", s.Topic, s.Format)
+	}
+	return nil
+}
+
+// Points returns the points of the payload of a message of topic, received at now.
+func (s *Subscription) Points(topic string, payload []byte, now time.Time) ([]models.Point, error) {
+	points, err := write.ParsePayload(s.Format, s.Precision, s.JSON, payload, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.TopicTag != "" {
+		for _, pt := range points {
+			pt.AddTag(s.TopicTag, topic)
+		}
+	}
+	return points, nil
+}
+
+// validateFilter returns an error if filter is not a valid topic filter.
+func validateFilter(filter string) error {
+	if filter == "" {
+		return fmt.Errorf("subscription topic is required")
+	}
+	levels := strings.Split(filter, "/")
+	for i, l := range levels {
+		if l == "#" && i != len(levels)-1 {
+			return fmt.Errorf("subscription topic %q: # must be the last level", filter)
+		}
+		if l != "+" && l != "#" && strings.ContainsAny(l, "+#") {
+			return fmt.Errorf("subscription topic %q: wildcards must be whole levels", filter)
+		}
+	}
+	return nil
+}
+
+// MatchTopic returns true if topic matches filter, in which + matches a level and # the remaining levels.
+func MatchTopic(filter, topic string) bool {
+	fs, ts := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, f := range fs {
+		if f == "#" {
+			// Topics starting with $ are only matched by filters starting with $.
+			return i > 0 || !strings.HasPrefix(topic, "$")
+		}
+		if i >= len(ts) {
+			return false
+		}
+		if f == "+" {
+			if i == 0 && strings.HasPrefix(topic, "$") {
+				return false
+			}
+			continue
+		}
+		if f != ts[i] {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
+
+// ReadSubscriptionsFile returns the subscriptions of a JSON file, an array of subscriptions.
+// The organizations of the subscriptions may be omitted, to be set by the caller.
+func ReadSubscriptionsFile(path string) ([]Subscription, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var subs []Subscription
+	if err := json.NewDecoder(f).Decode(&subs); err != nil {
+		return nil, fmt.Errorf("invalid MQTT subscriptions file %s: %v", path, err)
+	}
+	return subs, nil
+}
@@ -0,0 +1,197 @@
+package mqtt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/mqtt"
+	"github.com/influxdata/influxdb/write"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{filter: "sensors/a/temp", topic: "sensors/a/temp", want: true},
+		{filter: "sensors/a/temp", topic: "sensors/b/temp"},
+		{filter: "sensors/+/temp", topic: "sensors/b/temp", want: true},
+		{filter: "sensors/+/temp", topic: "sensors/b/humidity"},
+		{filter: "sensors/+", topic: "sensors/b/temp"},
+		{filter: "sensors/#", topic: "sensors/b/temp", want: true},
+		{filter: "sensors/#", topic: "sensors", want: true},
+		{filter: "#", topic: "sensors/b", want: true},
+		{filter: "#", topic: "$SYS/uptime"},
+		{filter: "+/uptime", topic: "$SYS/uptime"},
+		{filter: "$SYS/#", topic: "$SYS/uptime", want: true},
+	}
+	for _, tt := range tests {
+		if got := mqtt.MatchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     mqtt.Subscription
+		wantErr bool
+	}{
+		{
+			name: "line protocol",
+			sub:  mqtt.Subscription{Topic: "sensors/#", OrgID: 1, BucketID: 2},
+		},
+		{
+			name: "json",
+			sub: mqtt.Subscription{Topic: "sensors/+/temp", QoS: 1, OrgID: 1, BucketID: 2, Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+				Fields:      map[string]string{"value": "v"},
+			}},
+		},
+		{
+			name:    "wildcard inside a level",
+			sub:     mqtt.Subscription{Topic: "sensors/a+", OrgID: 1, BucketID: 2},
+			wantErr: true,
+		},
+		{
+			name:    "# before the last level",
+			sub:     mqtt.Subscription{Topic: "sensors/#/temp", OrgID: 1, BucketID: 2},
+			wantErr: true,
+		},
+		{
+			name:    "qos 2",
+			sub:     mqtt.Subscription{Topic: "sensors/#", QoS: 2, OrgID: 1, BucketID: 2},
+			wantErr: true,
+		},
+		{
+			name:    "missing bucket",
+			sub:     mqtt.Subscription{Topic: "sensors/#", OrgID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "json without mapping",
+			sub:     mqtt.Subscription{Topic: "sensors/#", OrgID: 1, BucketID: 2, Format: write.FormatJSON},
+			wantErr: true,
+		},
+		{
+			name: "json without fields",
+			sub: mqtt.Subscription{Topic: "sensors/#", OrgID: 1, BucketID: 2, Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid precision",
+			sub:     mqtt.Subscription{Topic: "sensors/#", OrgID: 1, BucketID: 2, Precision: "h"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sub.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscription_Points(t *testing.T) {
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		sub     mqtt.Subscription
+		payload string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "line protocol",
+			sub:     mqtt.Subscription{Precision: "s"},
+			payload: "temp,room=kitchen value=21.5 1560168000\ntemp,room=hall value=19",
+			want: []string{
+				"temp,room=kitchen value=21.5 1560168000000000000",
+				"temp,room=hall value=19 1560168000000000000",
+			},
+		},
+		{
+			name:    "line protocol tagged with the topic",
+			sub:     mqtt.Subscription{TopicTag: "topic"},
+			payload: "temp value=21.5 1560168000000000000",
+			want:    []string{"temp,topic=sensors/kitchen value=21.5 1560168000000000000"},
+		},
+		{
+			name: "json object",
+			sub: mqtt.Subscription{Format: write.FormatJSON, Precision: "ms", JSON: &write.JSONMapping{
+				Measurement: "climate",
+				TimePath:    "ts",
+				Tags:        map[string]string{"device": "device.id", "floor": "device.floor"},
+				Fields:      map[string]string{"temp": "readings.temp", "ok": "status.ok", "missing": "readings.missing"},
+			}},
+			payload: `{"ts": 1560168000123, "device": {"id": "d1", "floor": 2}, "readings": {"temp": 21}, "status": {"ok": true}}`,
+			want:    []string{"climate,device=d1,floor=2 ok=true,temp=21 1560168000123000000"},
+		},
+		{
+			name: "json array with measurements from the payload",
+			sub: mqtt.Subscription{Format: write.FormatJSON, JSON: &write.JSONMapping{
+				MeasurementPath: "type",
+				TimePath:        "time",
+				Fields:          map[string]string{"value": "value"},
+			}},
+			payload: `[{"type": "temp", "time": "2019-06-10T11:00:00Z", "value": 20.5}, {"type": "humidity", "time": "2019-06-10T11:00:00Z", "value": 40}]`,
+			want: []string{
+				"temp value=20.5 1560164400000000000",
+				"humidity value=40 1560164400000000000",
+			},
+		},
+		{
+			name: "json received at now",
+			sub: mqtt.Subscription{Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+				Fields:      map[string]string{"value": "v"},
+			}},
+			payload: `{"v": 1}`,
+			want:    []string{"temp value=1 1560168000000000000"},
+		},
+		{
+			name: "json without fields",
+			sub: mqtt.Subscription{Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+				Fields:      map[string]string{"value": "v"},
+			}},
+			payload: `{"w": 1}`,
+			wantErr: true,
+		},
+		{
+			name: "json with an object field",
+			sub: mqtt.Subscription{Format: write.FormatJSON, JSON: &write.JSONMapping{
+				Measurement: "temp",
+				Fields:      map[string]string{"value": "v"},
+			}},
+			payload: `{"v": {"c": 1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid line protocol",
+			payload: "temp value=",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := tt.sub.Points("sensors/kitchen", []byte(tt.payload), now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Points() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+			}
+			for i, pt := range points {
+				if pt.String() != tt.want[i] {
+					t.Errorf("got point %q, want %q", pt.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
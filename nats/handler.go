@@ -7,6 +7,14 @@ type Handler interface {
 	Process(s Subscription, m Message)
 }
 
+// HandlerFunc adapts a function to the Handler interface.
+type HandlerFunc func(s Subscription, m Message)
+
+// Process calls f.
+func (f HandlerFunc) Process(s Subscription, m Message) {
+	f(s, m)
+}
+
 type LogHandler struct {
 	Logger *zap.Logger
 }
@@ -0,0 +1,178 @@
+package nats
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is the number of messages a queue group of InProcess holds
+// before the messages published to it are rejected.
+const DefaultQueueSize = 1024
+
+// ErrQueueFull is returned when a message is published to a subject
+// a queue group of which is too far behind to hold it.
+var ErrQueueFull = errors.New("nats queue is full")
+
+// InProcess is a Streaming passing the messages in memory to the subscribers of the process.
+// Unlike NATS streaming, it neither persists the messages nor redelivers the ones not acked,
+// and the messages published to a subject before a queue group subscribes to it are not delivered to it.
+type InProcess struct {
+	// QueueSize is the number of messages each queue group holds. It defaults to DefaultQueueSize.
+	QueueSize int
+
+	mu     sync.Mutex
+	open   bool
+	groups map[string]map[string]*queueGroup // The queue groups by subject and name.
+}
+
+var _ Streaming = (*InProcess)(nil)
+
+// NewInProcess returns an InProcess to open.
+func NewInProcess() *InProcess {
+	return &InProcess{QueueSize: DefaultQueueSize}
+}
+
+// queueGroup holds the messages of a subject its subscribers take in turn.
+type queueGroup struct {
+	messages     chan []byte
+	pendingBytes int64
+}
+
+// Open starts passing messages.
+func (s *InProcess) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.open = true
+	s.groups = make(map[string]map[string]*queueGroup)
+	return nil
+}
+
+// Close stops passing messages, dropping the ones not delivered yet.
+func (s *InProcess) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.open {
+		return nil
+	}
+	s.open = false
+	for _, gs := range s.groups {
+		for _, g := range gs {
+			close(g.messages)
+		}
+	}
+	s.groups = nil
+	return nil
+}
+
+// Publish queues the message read from r for every queue group subscribed to subject.
+// It returns ErrQueueFull if a queue group cannot hold it, in which case the other groups may still receive it.
+func (s *InProcess) Publish(subject string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.open {
+		return ErrNoNatsConnection
+	}
+	var full bool
+	for _, g := range s.groups[subject] {
+		select {
+		case g.messages <- data:
+			atomic.AddInt64(&g.pendingBytes, int64(len(data)))
+		default:
+			full = true
+		}
+	}
+	if full {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// Subscribe processes with handler the messages published to subject from now on
+// that the other subscribers of group do not take.
+func (s *InProcess) Subscribe(subject, group string, handler Handler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.open {
+		return ErrNoNatsConnection
+	}
+	gs, ok := s.groups[subject]
+	if !ok {
+		gs = make(map[string]*queueGroup)
+		s.groups[subject] = gs
+	}
+	g, ok := gs[group]
+	if !ok {
+		size := s.QueueSize
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		g = &queueGroup{messages: make(chan []byte, size)}
+		gs[group] = g
+	}
+
+	sub := &inProcessSubscription{group: g, done: make(chan struct{})}
+	go sub.run(handler)
+	return nil
+}
+
+// inProcessSubscription is a subscriber of a queue group of InProcess.
+type inProcessSubscription struct {
+	group     *queueGroup
+	delivered int64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (s *inProcessSubscription) run(handler Handler) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case data, ok := <-s.group.messages:
+			if !ok {
+				return
+			}
+			atomic.AddInt64(&s.group.pendingBytes, -int64(len(data)))
+			atomic.AddInt64(&s.delivered, 1)
+			handler.Process(s, inProcessMessage(data))
+		}
+	}
+}
+
+func (s *inProcessSubscription) Pending() (int64, int64, error) {
+	return int64(len(s.group.messages)), atomic.LoadInt64(&s.group.pendingBytes), nil
+}
+
+func (s *inProcessSubscription) Delivered() (int64, error) {
+	return atomic.LoadInt64(&s.delivered), nil
+}
+
+// Close stops the subscriber. The other subscribers of its queue group keep taking its messages.
+func (s *inProcessSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
+}
+
+// inProcessMessage is a message of InProcess, which needs no ack.
+type inProcessMessage []byte
+
+func (m inProcessMessage) Data() []byte {
+	return m
+}
+
+func (m inProcessMessage) Ack() error {
+	return nil
+}
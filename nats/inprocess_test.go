@@ -0,0 +1,147 @@
+package nats_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/nats"
+	"go.uber.org/zap"
+)
+
+type recordingHandler struct {
+	mu   sync.Mutex
+	msgs []string
+	ch   chan struct{}
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{ch: make(chan struct{}, 100)}
+}
+
+func (h *recordingHandler) Process(s nats.Subscription, m nats.Message) {
+	h.mu.Lock()
+	h.msgs = append(h.msgs, string(m.Data()))
+	h.mu.Unlock()
+	m.Ack()
+	h.ch <- struct{}{}
+}
+
+func (h *recordingHandler) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-h.ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %d", i+1)
+		}
+	}
+}
+
+func TestInProcess(t *testing.T) {
+	s := nats.NewInProcess()
+	if err := s.Publish("metrics", bytes.NewBufferString("before open")); err != nats.ErrNoNatsConnection {
+		t.Fatalf("expected a connection error before Open, got %v", err)
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Two subscribers of a group share its messages, and every group gets every message.
+	g1, g2, other := newRecordingHandler(), newRecordingHandler(), newRecordingHandler()
+	for _, sub := range []struct {
+		group   string
+		handler nats.Handler
+	}{{"recorder", g1}, {"recorder", g2}, {"audit", other}} {
+		if err := s.Subscribe("metrics", sub.group, sub.handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, m := range []string{"a", "b", "c", "d"} {
+		if err := s.Publish("metrics", bytes.NewBufferString(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Publish("unsubscribed", bytes.NewBufferString("dropped")); err != nil {
+		t.Fatal(err)
+	}
+
+	other.wait(t, 4)
+	for i := 0; i < 4; i++ {
+		select {
+		case <-g1.ch:
+		case <-g2.ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the recorder group")
+		}
+	}
+	if got := len(g1.msgs) + len(g2.msgs); got != 4 {
+		t.Errorf("recorder group processed %d messages, want 4", got)
+	}
+	if got := len(other.msgs); got != 4 {
+		t.Errorf("audit group processed %d messages, want 4", got)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Subscribe("metrics", "recorder", g1); err != nats.ErrNoNatsConnection {
+		t.Errorf("expected a connection error after Close, got %v", err)
+	}
+}
+
+func TestInProcess_QueueFull(t *testing.T) {
+	s := nats.NewInProcess()
+	s.QueueSize = 1
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{}, 1)
+	h := nats.HandlerFunc(func(sub nats.Subscription, m nats.Message) {
+		started <- struct{}{}
+		<-block
+	})
+	if err := s.Subscribe("metrics", "recorder", h); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first message is being processed, the second is queued and the third does not fit.
+	if err := s.Publish("metrics", bytes.NewBufferString("1")); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := s.Publish("metrics", bytes.NewBufferString("2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Publish("metrics", bytes.NewBufferString("3")); err != nats.ErrQueueFull {
+		t.Errorf("expected a full queue, got %v", err)
+	}
+}
+
+func TestNewStreaming(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		config  nats.Config
+		wantErr bool
+	}{
+		{name: "default", config: nats.Config{}},
+		{name: "in-process", config: nats.Config{Mode: nats.ModeInProcess}},
+		{name: "external", config: nats.Config{Mode: nats.ModeExternal, URL: "nats://nats:4222", ClusterID: "prod", ClientID: "influxd-1"}},
+		{name: "external without URL", config: nats.Config{Mode: nats.ModeExternal, ClusterID: "prod", ClientID: "influxd-1"}, wantErr: true},
+		{name: "unknown mode", config: nats.Config{Mode: "kafka"}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := nats.NewStreaming(tt.config, zap.NewNop())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewStreaming() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,15 +17,25 @@ type AsyncPublisher struct {
 	ClientID   string
 	Connection stan.Conn
 	Logger     *zap.Logger
+
+	// ClusterID and URL locate the NATS streaming server.
+	// They default to the embedded server.
+	ClusterID string
+	URL       string
 }
 
 func NewAsyncPublisher(clientID string) *AsyncPublisher {
-	return &AsyncPublisher{ClientID: clientID}
+	return &AsyncPublisher{
+		ClientID:  clientID,
+		Logger:    zap.NewNop(),
+		ClusterID: ServerName,
+		URL:       stan.DefaultNatsURL,
+	}
 }
 
 // Open creates and maintains a connection to NATS server
 func (p *AsyncPublisher) Open() error {
-	sc, err := stan.Connect(ServerName, p.ClientID)
+	sc, err := stan.Connect(p.ClusterID, p.ClientID, stan.NatsURL(p.URL))
 	if err != nil {
 		return err
 	}
@@ -33,6 +43,16 @@ func (p *AsyncPublisher) Open() error {
 	return nil
 }
 
+// Close closes the connection to the NATS server.
+func (p *AsyncPublisher) Close() error {
+	if p.Connection == nil {
+		return nil
+	}
+	err := p.Connection.Close()
+	p.Connection = nil
+	return err
+}
+
 func (p *AsyncPublisher) Publish(subject string, r io.Reader) error {
 	if p.Connection == nil {
 		return ErrNoNatsConnection
@@ -0,0 +1,115 @@
+package nats
+
+import (
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// Streaming carries the messages published to a subject to one subscriber
+// of every queue group subscribed to it.
+type Streaming interface {
+	Publisher
+	Subscriber
+
+	// Open starts the streaming, or connects to it.
+	Open() error
+	// Close stops the streaming, or disconnects from it, and closes the subscriptions.
+	Close() error
+}
+
+// The modes of the streaming of influxd.
+const (
+	// ModeEmbedded runs a NATS streaming server in influxd.
+	ModeEmbedded = "embedded"
+	// ModeInProcess passes the messages in memory, without NATS.
+	ModeInProcess = "in-process"
+	// ModeExternal connects to an external NATS streaming cluster.
+	ModeExternal = "external"
+)
+
+// Config configures the streaming of influxd.
+type Config struct {
+	// Mode is ModeEmbedded, ModeInProcess or ModeExternal.
+	Mode string
+
+	// URL and ClusterID locate the external NATS streaming cluster.
+	URL       string
+	ClusterID string
+	// ClientID prefixes the IDs of the clients of influxd, which must be unique in the external cluster.
+	ClientID string
+}
+
+// NewStreaming returns the streaming configured by c.
+func NewStreaming(c Config, logger *zap.Logger) (Streaming, error) {
+	switch c.Mode {
+	case ModeEmbedded, "":
+		return newStanStreaming(NewServer(), "nats", ServerName, "", logger), nil
+	case ModeInProcess:
+		return NewInProcess(), nil
+	case ModeExternal:
+		if c.URL == "" || c.ClusterID == "" || c.ClientID == "" {
+			return nil, fmt.Errorf("the external NATS streaming mode requires a URL, a cluster ID and a client ID")
+		}
+		return newStanStreaming(nil, c.ClientID, c.ClusterID, c.URL, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown NATS streaming mode %q", c.Mode)
+	}
+}
+
+// stanStreaming publishes and subscribes to a NATS streaming server,
+// which it runs if it is embedded.
+type stanStreaming struct {
+	server     *Server // nil if the server is external.
+	publisher  *AsyncPublisher
+	subscriber *QueueSubscriber
+}
+
+func newStanStreaming(server *Server, clientID, clusterID, url string, logger *zap.Logger) *stanStreaming {
+	s := &stanStreaming{
+		server:     server,
+		publisher:  NewAsyncPublisher(clientID + "-publisher"),
+		subscriber: NewQueueSubscriber(clientID + "-subscriber"),
+	}
+	s.publisher.Logger = logger
+	s.publisher.ClusterID = clusterID
+	s.subscriber.ClusterID = clusterID
+	if url != "" {
+		s.publisher.URL = url
+		s.subscriber.URL = url
+	}
+	return s
+}
+
+func (s *stanStreaming) Open() error {
+	if s.server != nil {
+		if err := s.server.Open(); err != nil {
+			return err
+		}
+	}
+	if err := s.publisher.Open(); err != nil {
+		return err
+	}
+	return s.subscriber.Open()
+}
+
+func (s *stanStreaming) Close() error {
+	perr := s.publisher.Close()
+	serr := s.subscriber.Close()
+	if s.server != nil && s.server.Server != nil {
+		s.server.Close()
+	}
+	if perr != nil {
+		return perr
+	}
+	return serr
+}
+
+func (s *stanStreaming) Publish(subject string, r io.Reader) error {
+	return s.publisher.Publish(subject, r)
+}
+
+func (s *stanStreaming) Subscribe(subject, group string, handler Handler) error {
+	return s.subscriber.Subscribe(subject, group, handler)
+}
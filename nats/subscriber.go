@@ -12,15 +12,24 @@ type Subscriber interface {
 type QueueSubscriber struct {
 	ClientID   string
 	Connection stan.Conn
+
+	// ClusterID and URL locate the NATS streaming server.
+	// They default to the embedded server.
+	ClusterID string
+	URL       string
 }
 
 func NewQueueSubscriber(clientID string) *QueueSubscriber {
-	return &QueueSubscriber{ClientID: clientID}
+	return &QueueSubscriber{
+		ClientID:  clientID,
+		ClusterID: ServerName,
+		URL:       stan.DefaultNatsURL,
+	}
 }
 
 // Open creates and maintains a connection to NATS server
 func (s *QueueSubscriber) Open() error {
-	sc, err := stan.Connect(ServerName, s.ClientID)
+	sc, err := stan.Connect(s.ClusterID, s.ClientID, stan.NatsURL(s.URL))
 	if err != nil {
 		return err
 	}
@@ -28,6 +37,16 @@ func (s *QueueSubscriber) Open() error {
 	return nil
 }
 
+// Close closes the connection to the NATS server, which closes its subscriptions.
+func (s *QueueSubscriber) Close() error {
+	if s.Connection == nil {
+		return nil
+	}
+	err := s.Connection.Close()
+	s.Connection = nil
+	return err
+}
+
 type messageHandler struct {
 	handler Handler
 	sub     subscription
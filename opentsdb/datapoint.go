@@ -0,0 +1,104 @@
+// Package opentsdb accepts the datapoints of collectors speaking the OpenTSDB protocols,
+// over telnet-style TCP connections and as the JSON of the /api/put HTTP endpoint.
+//
+// The metric of a datapoint is the measurement of its point, its tags are the tags of the point,
+// and its value is the float "value" field of the point.
+package opentsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// ValueField is the field of the points holding the values of datapoints.
+const ValueField = "value"
+
+// millisecondThreshold is the smallest timestamp read as milliseconds rather than seconds,
+// as OpenTSDB reads 10 digit timestamps as seconds and 13 digit timestamps as milliseconds.
+const millisecondThreshold = 1e12
+
+// Datapoint is a value of a metric at a time, identified by tags.
+type Datapoint struct {
+	Metric string `json:"metric"`
+	// Timestamp is the time of the datapoint in seconds, or milliseconds, since the epoch.
+	Timestamp json.Number       `json:"timestamp"`
+	Value     json.Number       `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// Point returns the point of the datapoint.
+func (dp *Datapoint) Point() (models.Point, error) {
+	if dp.Metric == "" {
+		return nil, fmt.Errorf("metric is required")
+	}
+	ts, err := strconv.ParseInt(dp.Timestamp.String(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q", dp.Timestamp)
+	}
+	if ts <= 0 {
+		return nil, fmt.Errorf("timestamp must be positive")
+	}
+	v, err := strconv.ParseFloat(dp.Value.String(), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", dp.Value)
+	}
+
+	var t time.Time
+	if ts < millisecondThreshold {
+		t = time.Unix(ts, 0)
+	} else {
+		t = time.Unix(0, ts*int64(time.Millisecond))
+	}
+	return models.NewPoint(dp.Metric, models.NewTags(dp.Tags), models.Fields{ValueField: v}, t)
+}
+
+// ParseJSON returns the datapoints of the body of an /api/put request,
+// which is either a single datapoint or an array of them.
+func ParseJSON(data []byte) ([]Datapoint, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var dps []Datapoint
+		if err := json.Unmarshal(data, &dps); err != nil {
+			return nil, err
+		}
+		return dps, nil
+	}
+	var dp Datapoint
+	if err := json.Unmarshal(data, &dp); err != nil {
+		return nil, err
+	}
+	return []Datapoint{dp}, nil
+}
+
+// ParsePut returns the datapoint of a telnet put command, such as
+// "put sys.cpu.user 1356998400 42.5 host=webserver01 cpu=0".
+func ParsePut(line string) (*Datapoint, error) {
+	args := strings.Fields(line)
+	if len(args) == 0 || args[0] != "put" {
+		return nil, fmt.Errorf("not a put command")
+	}
+	if len(args) < 4 {
+		return nil, fmt.Errorf("put requires a metric, a timestamp and a value")
+	}
+
+	dp := &Datapoint{
+		Metric:    args[1],
+		Timestamp: json.Number(args[2]),
+		Value:     json.Number(args[3]),
+		Tags:      make(map[string]string, len(args)-4),
+	}
+	for _, tag := range args[4:] {
+		i := strings.IndexByte(tag, '=')
+		if i <= 0 || i == len(tag)-1 {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		dp.Tags[tag[:i]] = tag[i+1:]
+	}
+	return dp, nil
+}
@@ -0,0 +1,105 @@
+package opentsdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/opentsdb"
+)
+
+func TestParsePut(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "seconds",
+			line: "put sys.cpu.user 1356998400 42.5 host=webserver01 cpu=0",
+			want: "sys.cpu.user,cpu=0,host=webserver01 value=42.5 1356998400000000000",
+		},
+		{
+			name: "milliseconds",
+			line: "put sys.cpu.user 1356998400123 42 host=webserver01",
+			want: "sys.cpu.user,host=webserver01 value=42 1356998400123000000",
+		},
+		{
+			name: "no tags",
+			line: "put load 1356998400 1e3",
+			want: "load value=1000 1356998400000000000",
+		},
+		{
+			name:    "missing value",
+			line:    "put sys.cpu.user 1356998400",
+			wantErr: true,
+		},
+		{
+			name:    "invalid tag",
+			line:    "put sys.cpu.user 1356998400 1 host",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			line:    "put sys.cpu.user 1356998400 high host=a",
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp",
+			line:    "put sys.cpu.user now 1 host=a",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp, err := opentsdb.ParsePut(tt.line)
+			if err == nil {
+				var pt interface{ String() string }
+				pt, err = dp.Point()
+				if err == nil && pt.String() != tt.want {
+					t.Errorf("got point %q, want %q", pt.String(), tt.want)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	dps, err := opentsdb.ParseJSON([]byte(`[
+  {"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18, "tags": {"host": "web01", "dc": "lga"}},
+  {"metric": "sys.cpu.nice", "timestamp": 1346846400000, "value": "9.5", "tags": {"host": "web02", "dc": "lga"}}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"sys.cpu.nice,dc=lga,host=web01 value=18 1346846400000000000",
+		"sys.cpu.nice,dc=lga,host=web02 value=9.5 1346846400000000000",
+	}
+	if len(dps) != len(want) {
+		t.Fatalf("got %d datapoints, want %d", len(dps), len(want))
+	}
+	for i, dp := range dps {
+		pt, err := dp.Point()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt.String() != want[i] {
+			t.Errorf("got point %q, want %q", pt.String(), want[i])
+		}
+	}
+
+	dps, err = opentsdb.ParseJSON([]byte(`{"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dps) != 1 || dps[0].Metric != "sys.cpu.nice" {
+		t.Errorf("unexpected datapoints of a single object: %+v", dps)
+	}
+
+	if _, err := opentsdb.ParseJSON([]byte(`{"metric": "sys.cpu.nice", "value": true}`)); err == nil {
+		t.Error("expected an error for a boolean value")
+	}
+}
@@ -0,0 +1,222 @@
+package opentsdb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap"
+)
+
+// DefaultBatchSize is the number of datapoints of a connection written at once, by default.
+const DefaultBatchSize = 1000
+
+// PointsWriter writes points to the storage engine.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// Service listens for telnet-style connections of OpenTSDB collectors, and writes their datapoints to a bucket.
+//
+// A connection sends a command per line. The datapoints of put commands are written in batches,
+// when a batch is full or the connection has no more lines to read for now; a version command is answered,
+// and a datapoint that cannot be parsed or written, or does not conform to the schema of the bucket,
+// is answered with an error line.
+type Service struct {
+	OrgID    platform.ID
+	BucketID platform.ID
+	Writer   PointsWriter
+
+	// SchemaChecker, if set, checks the datapoints against the schema of the bucket, if it is an explicit-schema bucket.
+	SchemaChecker *write.SchemaChecker
+
+	// BatchSize is the number of datapoints written at once. It defaults to DefaultBatchSize.
+	BatchSize int
+
+	Logger *zap.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewService returns a Service writing the datapoints it receives to the bucket bucketID of orgID.
+func NewService(orgID, bucketID platform.ID, w PointsWriter, logger *zap.Logger) *Service {
+	return &Service{
+		OrgID:     orgID,
+		BucketID:  bucketID,
+		Writer:    w,
+		BatchSize: DefaultBatchSize,
+		Logger:    logger,
+	}
+}
+
+// Open listens on addr and serves the connections until Close is called.
+func (s *Service) Open(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.Serve(ln)
+	return nil
+}
+
+// Serve serves the connections of ln until Close is called.
+func (s *Service) Serve(ln net.Listener) {
+	s.mu.Lock()
+	s.listener = ln
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	s.Logger.Info("Listening for OpenTSDB datapoints", zap.String("addr", ln.Addr().String()))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				// The listener is closed.
+				return
+			}
+			s.mu.Lock()
+			if s.listener == nil {
+				// Close was called while the connection was accepted.
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conns[conn] = struct{}{}
+			s.wg.Add(1)
+			s.mu.Unlock()
+
+			go func() {
+				defer s.wg.Done()
+				s.handleConn(conn)
+
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+			}()
+		}
+	}()
+}
+
+// Addr returns the address the service listens on, or nil if it does not listen.
+func (s *Service) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops listening, closes the connections and waits for them to be done.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	err := s.listener.Close()
+	s.listener = nil
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Service) handleConn(conn net.Conn) {
+	defer conn.Close()
+	logger := s.Logger.With(zap.String("remote_addr", conn.RemoteAddr().String()))
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	r := bufio.NewReader(conn)
+	var batch []models.Point
+	for {
+		line, err := r.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			switch strings.SplitN(line, " ", 2)[0] {
+			case "put":
+				pt, perr := s.parsePut(line)
+				if perr != nil {
+					fmt.Fprintf(conn, "put: illegal argument: %v\n", perr)
+				} else {
+					batch = append(batch, pt)
+				}
+			case "version":
+				fmt.Fprintln(conn, "influxdb OpenTSDB listener")
+			default:
+				fmt.Fprintf(conn, "unknown command: %s\n", strings.SplitN(line, " ", 2)[0])
+			}
+		}
+
+		// The batch is written once full, or once the lines received so far are read.
+		if len(batch) >= batchSize || (len(batch) > 0 && (err != nil || r.Buffered() == 0)) {
+			rejected, werr := s.write(batch)
+			if len(rejected) > 0 {
+				logger.Info("Rejected OpenTSDB datapoints not conforming to the bucket schema", zap.Int("rejected", len(rejected)))
+			}
+			for _, rp := range rejected {
+				fmt.Fprintf(conn, "put: illegal argument: %v\n", rp.Err)
+			}
+			if werr != nil {
+				logger.Info("Failed to write OpenTSDB datapoints", zap.Int("points", len(batch)), zap.Error(werr))
+				fmt.Fprintf(conn, "put: unable to write datapoints: %v\n", werr)
+			}
+			batch = batch[:0]
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				logger.Debug("OpenTSDB connection closed", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+func (s *Service) parsePut(line string) (models.Point, error) {
+	dp, err := ParsePut(line)
+	if err != nil {
+		return nil, err
+	}
+	return dp.Point()
+}
+
+// write writes the points, but for those not conforming to the schema of the bucket, which it returns.
+func (s *Service) write(points []models.Point) ([]write.RejectedPoint, error) {
+	ctx := context.Background()
+	var rejected []write.RejectedPoint
+	if s.SchemaChecker != nil {
+		var err error
+		if points, rejected, err = s.SchemaChecker.CheckSchema(ctx, s.BucketID, points); err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			return rejected, nil
+		}
+	}
+
+	exploded, err := tsdb.ExplodePoints(s.OrgID, s.BucketID, points)
+	if err != nil {
+		return rejected, err
+	}
+	return rejected, s.Writer.WritePoints(ctx, exploded)
+}
@@ -0,0 +1,145 @@
+package opentsdb_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/opentsdb"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingWriter struct {
+	mu     sync.Mutex
+	points []models.Point
+	writes chan struct{}
+}
+
+func (w *recordingWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	w.points = append(w.points, points...)
+	w.mu.Unlock()
+	w.writes <- struct{}{}
+	return nil
+}
+
+func TestService(t *testing.T) {
+	w := &recordingWriter{writes: make(chan struct{}, 10)}
+	s := opentsdb.NewService(1, 2, w, zaptest.NewLogger(t))
+	if err := s.Open("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "put sys.cpu.user 1356998400 42.5 host=web01\nput sys.cpu.user 1356998401 43 host=web01\nput broken\n")
+
+	r := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(reply, "put: illegal argument") {
+		t.Errorf("unexpected reply to an invalid put: %q", reply)
+	}
+
+	// The datapoints may be read, and written, one at a time.
+	for n := 0; n < 2; {
+		select {
+		case <-w.writes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the datapoints to be written")
+		}
+		w.mu.Lock()
+		n = len(w.points)
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.points) != 2 {
+		t.Fatalf("expected 2 exploded points, got %d", len(w.points))
+	}
+	for _, pt := range w.points {
+		var measurement, field string
+		for _, tag := range pt.Tags() {
+			switch string(tag.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(tag.Value)
+			case models.FieldKeyTagKey:
+				field = string(tag.Value)
+			}
+		}
+		if measurement != "sys.cpu.user" || field != opentsdb.ValueField {
+			t.Errorf("unexpected point %s", pt)
+		}
+	}
+}
+
+func TestService_Schema(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		return []*platform.MeasurementSchema{{
+			Name:   "sys.cpu.user",
+			Tags:   []string{"host"},
+			Fields: []platform.MeasurementSchemaField{{Name: opentsdb.ValueField, Type: platform.SchemaFieldTypeFloat}},
+		}}, nil
+	}
+
+	w := &recordingWriter{writes: make(chan struct{}, 10)}
+	s := opentsdb.NewService(1, 2, w, zaptest.NewLogger(t))
+	s.SchemaChecker = &write.SchemaChecker{BucketService: buckets, MeasurementSchemaService: schemas}
+	if err := s.Open("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "put sys.cpu.user 1356998400 42.5 host=web01 dc=lga\n")
+
+	r := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(reply, "put: illegal argument") || !strings.Contains(reply, `tag "dc"`) {
+		t.Errorf("unexpected reply to a datapoint not conforming to the schema: %q", reply)
+	}
+
+	fmt.Fprint(conn, "put sys.cpu.user 1356998401 43 host=web01\n")
+	select {
+	case <-w.writes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the datapoint to be written")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.points) != 1 {
+		t.Fatalf("expected 1 exploded point, got %d", len(w.points))
+	}
+}
@@ -1,11 +1,54 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // Organization is an organization. 🎉
 type Organization struct {
 	ID   ID     `json:"id,omitempty"`
 	Name string `json:"name"`
+
+	// BucketPolicy restricts the buckets that may be created in the organization.
+	BucketPolicy *BucketPolicy `json:"bucketPolicy,omitempty"`
+
+	// InactivityPolicy tells which resources of the organization are inactive, and what is done about them.
+	InactivityPolicy *InactivityPolicy `json:"inactivityPolicy,omitempty"`
+
+	// EgressPolicy restricts the outbound connections made by the queries of the organization.
+	EgressPolicy *EgressPolicy `json:"egressPolicy,omitempty"`
+
+	// Timezone is the IANA name of the time zone times are shown in for the organization.
+	// If empty, times are shown in UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Location returns the time zone of the organization.
+func (o *Organization) Location() (*time.Location, error) {
+	return LoadTimezone(o.Timezone)
+}
+
+// LoadTimezone returns the time zone with the IANA name tz, or UTC if tz is empty.
+// The server's local time zone is not a valid organization time zone.
+func LoadTimezone(tz string) (*time.Location, error) {
+	if tz == "Local" {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid timezone %q", tz),
+		}
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid timezone %q", tz),
+			Err:  err,
+		}
+	}
+	return loc, nil
 }
 
 // ops for orgs error and orgs op logs.
@@ -42,9 +85,13 @@ type OrganizationService interface {
 }
 
 // OrganizationUpdate represents updates to a organization.
-// Only fields which are set are updated.
+// Only fields which are set are updated. An empty BucketPolicy removes the bucket policy.
 type OrganizationUpdate struct {
-	Name *string
+	Name             *string
+	BucketPolicy     *BucketPolicy
+	InactivityPolicy *InactivityPolicy
+	EgressPolicy     *EgressPolicy
+	Timezone         *string
 }
 
 // OrganizationFilter represents a set of filter that restrict the returned results.
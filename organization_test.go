@@ -0,0 +1,39 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		tz      string
+		want    string
+		wantErr bool
+	}{
+		{tz: "", want: "UTC"},
+		{tz: "UTC", want: "UTC"},
+		{tz: "Europe/Paris", want: "Europe/Paris"},
+		{tz: "Local", wantErr: true},
+		{tz: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tz, func(t *testing.T) {
+			loc, err := platform.LoadTimezone(tt.tz)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				if platform.ErrorCode(err) != platform.EInvalid {
+					t.Errorf("expected invalid error, got %v", err)
+				}
+				return
+			}
+			if loc.String() != tt.want {
+				t.Errorf("expected time zone %s, got %s", tt.want, loc)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// KeyFile is the path of a file of keys, one per line, oldest first. A line is the ID of
+// a key and the base64 encoding of its secret, separated by spaces, such as:
+//
+//	1 c2VjcmV0IGtleSBvZiAzMiBieXRlcyBmb3IgYWVzLTI1Ng==
+//
+// Empty lines and lines starting with # are ignored. Keys are rotated by appending a new key.
+type KeyFile string
+
+var _ KeyProvider = KeyFile("")
+
+// Keys returns the keys of the file.
+func (f KeyFile) Keys(ctx context.Context) ([]Key, error) {
+	fd, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var keys []Key
+	scanner := bufio.NewScanner(fd)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("encryption: %s:%d: expected a key id and secret", f, n)
+		}
+		id, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s:%d: invalid key id: %v", f, n, err)
+		}
+		secret, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s:%d: invalid key secret: %v", f, n, err)
+		}
+		keys = append(keys, Key{ID: uint32(id), Secret: secret})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,167 @@
+// Package encryption provides the AES-GCM encryption of data at rest.
+//
+// Data is encrypted with the current key of a Keyring, and decrypted with the key it was
+// encrypted with, found by the ID stored with the encrypted data. Keys are rotated by adding
+// a new current key to the keyring, while keeping the older keys to decrypt existing data.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// keyIDSize is the size of the ID of the key stored before the encrypted data.
+	keyIDSize = 4
+
+	// nonceSize is the size of the AES-GCM nonce stored after the ID of the key.
+	nonceSize = 12
+
+	// Overhead is the number of bytes the encryption adds to data.
+	Overhead = keyIDSize + nonceSize + 16
+)
+
+var (
+	// ErrNoKey is returned when encrypting with a keyring without keys.
+	ErrNoKey = errors.New("encryption: no key")
+
+	// ErrKeyNotFound is returned when decrypting data encrypted with a key not in the keyring.
+	ErrKeyNotFound = errors.New("encryption: key not found")
+
+	// ErrCiphertextTooShort is returned when decrypting data too short to have been encrypted.
+	ErrCiphertextTooShort = errors.New("encryption: ciphertext too short")
+)
+
+// Key is an AES key of 16, 24 or 32 bytes with its ID.
+type Key struct {
+	ID     uint32
+	Secret []byte
+}
+
+// KeyProvider provides the keys of a keyring, such as a key file or a key management service.
+type KeyProvider interface {
+	// Keys returns the keys, oldest first. The last key is the current key.
+	Keys(ctx context.Context) ([]Key, error)
+}
+
+// Keyring encrypts data with its current key and decrypts data with any of its keys.
+// It is safe for concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	aeads   map[uint32]cipher.AEAD
+	current uint32
+}
+
+// NewKeyring returns a keyring of keys, oldest first. The last key is the current key.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[uint32]cipher.AEAD)}
+	for _, key := range keys {
+		if err := k.Rotate(key); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// NewKeyringFromProvider returns a keyring of the keys of p.
+func NewKeyringFromProvider(ctx context.Context, p KeyProvider) (*Keyring, error) {
+	keys, err := p.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKey
+	}
+	return NewKeyring(keys...)
+}
+
+// Rotate adds key to the keyring and makes it the current key. The data encrypted with the
+// previous keys can still be decrypted.
+func (k *Keyring) Rotate(key Key) error {
+	block, err := aes.NewCipher(key.Secret)
+	if err != nil {
+		return fmt.Errorf("encryption: key %d: %v", key.ID, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("encryption: key %d: %v", key.ID, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.aeads[key.ID]; ok {
+		return fmt.Errorf("encryption: duplicate key %d", key.ID)
+	}
+	k.aeads[key.ID] = aead
+	k.current = key.ID
+	return nil
+}
+
+// CurrentKeyID returns the ID of the key data is encrypted with.
+func (k *Keyring) CurrentKeyID() uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Encrypt appends to dst the plaintext encrypted with the current key, and returns the result.
+func (k *Keyring) Encrypt(dst, plaintext []byte) ([]byte, error) {
+	k.mu.RLock()
+	id := k.current
+	aead, ok := k.aeads[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoKey
+	}
+
+	n := len(dst)
+	if need := n + Overhead + len(plaintext); cap(dst) < need {
+		b := make([]byte, n, need)
+		copy(b, dst)
+		dst = b
+	}
+	dst = dst[:n+keyIDSize+nonceSize]
+	binary.BigEndian.PutUint32(dst[n:], id)
+	nonce := dst[n+keyIDSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(dst, nonce, plaintext, nil), nil
+}
+
+// Decrypt appends to dst the ciphertext returned by Encrypt decrypted, and returns the result.
+func (k *Keyring) Decrypt(dst, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < Overhead {
+		return nil, ErrCiphertextTooShort
+	}
+	id := binary.BigEndian.Uint32(ciphertext)
+
+	k.mu.RLock()
+	aead, ok := k.aeads[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	nonce := ciphertext[keyIDSize : keyIDSize+nonceSize]
+	b, err := aead.Open(dst, nonce, ciphertext[keyIDSize+nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: key %d: %v", id, err)
+	}
+	return b, nil
+}
+
+// KeyID returns the ID of the key the ciphertext returned by Encrypt was encrypted with.
+func KeyID(ciphertext []byte) (uint32, error) {
+	if len(ciphertext) < Overhead {
+		return 0, ErrCiphertextTooShort
+	}
+	return binary.BigEndian.Uint32(ciphertext), nil
+}
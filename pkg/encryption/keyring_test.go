@@ -0,0 +1,128 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+func TestKeyring_Rotate(t *testing.T) {
+	k, err := encryption.NewKeyring(encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := k.Encrypt(nil, []byte("old data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := encryption.KeyID(old); err != nil || id != 1 {
+		t.Fatalf("unexpected key id: %d, %v", id, err)
+	}
+
+	if err := k.Rotate(encryption.Key{ID: 2, Secret: bytes.Repeat([]byte{2}, 16)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Rotate(encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{3}, 32)}); err == nil {
+		t.Fatal("expected error rotating to an existing key id")
+	}
+	if got := k.CurrentKeyID(); got != 2 {
+		t.Fatalf("unexpected current key: %d", got)
+	}
+
+	data, err := k.Encrypt([]byte("prefix"), []byte("new data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("prefix")) {
+		t.Fatalf("dst not kept: %q", data)
+	}
+	data = data[len("prefix"):]
+	if id, _ := encryption.KeyID(data); id != 2 {
+		t.Fatalf("unexpected key id: %d", id)
+	}
+	if len(data) != len("new data")+encryption.Overhead {
+		t.Fatalf("unexpected ciphertext length: %d", len(data))
+	}
+
+	for ciphertext, exp := range map[string]string{string(old): "old data", string(data): "new data"} {
+		got, err := k.Decrypt(nil, []byte(ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != exp {
+			t.Fatalf("unexpected plaintext: got %q, exp %q", got, exp)
+		}
+	}
+
+	data[len(data)-1] ^= 0xff
+	if _, err := k.Decrypt(nil, data); err == nil {
+		t.Fatal("expected error decrypting modified ciphertext")
+	}
+	if _, err := k.Decrypt(nil, data[:4]); err != encryption.ErrCiphertextTooShort {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other, _ := encryption.NewKeyring(encryption.Key{ID: 3, Secret: bytes.Repeat([]byte{3}, 32)})
+	if _, err := other.Decrypt(nil, old); err != encryption.ErrKeyNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyring_NoKey(t *testing.T) {
+	k, err := encryption.NewKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Encrypt(nil, []byte("data")); err != encryption.ErrNoKey {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := encryption.NewKeyring(encryption.Key{ID: 1, Secret: []byte("short")}); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keys")
+	content := `# rotated on 2019-03-01
+1 AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+
+2 AgICAgICAgICAgICAgICAg==
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := encryption.NewKeyringFromProvider(context.Background(), encryption.KeyFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := k.CurrentKeyID(); got != 2 {
+		t.Fatalf("unexpected current key: %d", got)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("1 not-base64\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryption.NewKeyringFromProvider(context.Background(), encryption.KeyFile(path)); err == nil {
+		t.Fatal("expected error for invalid key file")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("# no keys\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryption.NewKeyringFromProvider(context.Background(), encryption.KeyFile(path)); err != encryption.ErrNoKey {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
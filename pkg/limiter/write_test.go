@@ -2,6 +2,7 @@ package limiter_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"testing"
 	"time"
@@ -28,6 +29,27 @@ func TestWriter_Limited(t *testing.T) {
 	}
 }
 
+func TestSetRate(t *testing.T) {
+	r := limiter.NewRate(1, 1)
+	if !limiter.SetRate(r, 0) {
+		t.Fatal("expected the rate of NewRate to change")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.WaitN(ctx, 1024*1024); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+
+	if limiter.SetRate(rateFunc(nil), 1) {
+		t.Error("expected another rate not to change")
+	}
+}
+
+type rateFunc func(ctx context.Context, n int) error
+
+func (f rateFunc) WaitN(ctx context.Context, n int) error { return f(ctx, n) }
+
 type discardCloser struct{}
 
 func (d discardCloser) Write(b []byte) (int, error) { return len(b), nil }
@@ -25,6 +25,21 @@ func NewRate(bytesPerSec, burstLimit int) Rate {
 	return limiter
 }
 
+// SetRate changes the number of bytes per second allowed by r, which must have been returned by NewRate,
+// and returns false otherwise. A rate of 0 removes the limit.
+func SetRate(r Rate, bytesPerSec int) bool {
+	limiter, ok := r.(*rate.Limiter)
+	if !ok {
+		return false
+	}
+	if bytesPerSec == 0 {
+		limiter.SetLimit(rate.Inf)
+	} else {
+		limiter.SetLimit(rate.Limit(bytesPerSec))
+	}
+	return true
+}
+
 // NewWriter returns a writer that implements io.Writer with rate limiting.
 // The limiter use a token bucket approach and limits the rate to bytesPerSec
 // with a maximum burst of burstLimit.
@@ -0,0 +1,108 @@
+// Package tar writes snapshots of files to tar archives.
+package tar
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// File is a snapshot of a file written to a tar archive by WriteFiles.
+//
+// Snapshots of files that are written to are taken by opening them, and recording their size,
+// under the lock serializing the writes. They are written to the archive after the lock is
+// released, without the bytes written since.
+type File struct {
+	// Name is the name of the file in the archive.
+	Name string
+
+	// File is the file to write, or nil to write Data.
+	File *os.File
+	Data []byte
+
+	// N is the number of bytes of File written, and Size the size of the file in the archive,
+	// of which the bytes after the first N are zeros. This keeps the size of the files
+	// preallocated with zeros, such as memory mapped files, without the bytes written
+	// after the snapshot.
+	N, Size int64
+
+	ModTime time.Time
+}
+
+// OpenFile returns a snapshot of the first n bytes of the file at path, zero padded to size.
+// A size of -1 is the size of the file, as is an n of -1.
+func OpenFile(name, path string, n, size int64) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return File{}, err
+	}
+	if size < 0 {
+		size = fi.Size()
+	}
+	if n < 0 || n > size {
+		n = size
+	}
+	return File{Name: name, File: f, N: n, Size: size, ModTime: fi.ModTime()}, nil
+}
+
+// DataFile returns a snapshot of a file holding data.
+func DataFile(name string, data []byte) File {
+	return File{Name: name, Data: data, N: int64(len(data)), Size: int64(len(data)), ModTime: time.Now()}
+}
+
+// WriteFiles writes the snapshots of files to tw in the directory dir, and closes them.
+func WriteFiles(tw *tar.Writer, dir string, files []File) error {
+	defer CloseFiles(files)
+
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(dir, f.Name),
+			Mode:     0666,
+			Size:     f.Size,
+			ModTime:  f.ModTime,
+		}); err != nil {
+			return err
+		}
+
+		if f.File == nil {
+			if _, err := tw.Write(f.Data); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.CopyN(tw, f.File, f.N); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(tw, zeros{}, f.Size-f.N); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseFiles closes the snapshots of files, such as when they are not written.
+func CloseFiles(files []File) {
+	for _, f := range files {
+		if f.File != nil {
+			f.File.Close()
+		}
+	}
+}
+
+// zeros is a reader of zeros.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
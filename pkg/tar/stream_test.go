@@ -0,0 +1,65 @@
+package tar_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	intar "github.com/influxdata/influxdb/pkg/tar"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "segment")
+	if err := ioutil.WriteFile(path, []byte("abcdef"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snapshot keeps the first 3 bytes, zero padded to 8 bytes.
+	f, err := intar.OpenFile("segment", path, 3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file grows after the snapshot, and only its first 3 bytes are written.
+	if err := ioutil.WriteFile(path, []byte("ABCDEFGH"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := intar.WriteFiles(tw, "dir", []intar.File{f, intar.DataFile("MANIFEST", []byte("{}"))}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(&buf)
+	for _, want := range []struct {
+		name string
+		data []byte
+	}{
+		{"dir/segment", []byte("ABC\x00\x00\x00\x00\x00")},
+		{"dir/MANIFEST", []byte("{}")},
+	} {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name != want.name || !bytes.Equal(data, want.data) {
+			t.Fatalf("got file %s: %q, want %s: %q", hdr.Name, data, want.name, want.data)
+		}
+	}
+}
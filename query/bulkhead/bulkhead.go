@@ -0,0 +1,416 @@
+// Package bulkhead isolates the queries of organizations from each other,
+// so that a query of one organization that panics or exhausts resources
+// cannot take down the query subsystem shared by every organization.
+package bulkhead
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultCooldown is how long the breaker of an organization stays open, unless configured otherwise.
+const DefaultCooldown = time.Minute
+
+const (
+	// DefaultTrafficWindow is how long a bulkhead records the queries for, unless configured otherwise.
+	DefaultTrafficWindow = time.Hour
+
+	// maxTrafficRecords is the number of queries a bulkhead records at most, the latest ones.
+	maxTrafficRecords = 100000
+)
+
+// Config configures a Bulkhead.
+type Config struct {
+	// MaxConcurrency is the maximum number of queries of an organization running at once.
+	// The queries over the limit are rejected. Zero means unlimited.
+	MaxConcurrency int
+
+	// FailureThreshold is the number of consecutive failed queries of an organization
+	// that open its breaker. Zero disables the breaker.
+	FailureThreshold int
+
+	// Cooldown is how long the breaker of an organization stays open before a query is let through to try it.
+	// It defaults to DefaultCooldown.
+	Cooldown time.Duration
+}
+
+// BreakerState is the state of the circuit breaker of an organization.
+type BreakerState string
+
+// The states of a circuit breaker.
+const (
+	// BreakerClosed lets every query through.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects every query, until the cooldown ends.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single query through once the cooldown ends,
+	// which closes the breaker if it succeeds or opens it again if it fails.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// Bulkhead gives each organization its own compartment of a query subsystem,
+// recovering, limiting and reporting the queries of each organization separately.
+//
+// A query fails if it panics, either while it is submitted or while it executes,
+// or if IsFailure returns true for its error.
+// Consecutive failures of an organization open its breaker, which rejects its queries for a while.
+//
+// The queries of the last TrafficWindow are recorded, whether they are let through or not,
+// for SimulateConcurrency to evaluate them against another concurrency limit.
+type Bulkhead struct {
+	name   string
+	config Config
+	logger *zap.Logger
+
+	// IsFailure, if set, tells which errors of queries count as failures, besides panics.
+	IsFailure func(error) bool
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	// TrafficWindow is how long the queries are recorded for. It defaults to DefaultTrafficWindow.
+	TrafficWindow time.Duration
+
+	mu   sync.Mutex
+	orgs map[influxdb.ID]*compartment
+
+	traffic      []*queryRecord
+	trafficStart int // the index of the oldest query of traffic
+
+	metrics *metrics
+}
+
+// compartment holds the state of an organization.
+type compartment struct {
+	active   int
+	failures int // The number of consecutive failures.
+
+	state     BreakerState
+	openUntil time.Time
+}
+
+// queryRecord is a query recorded by a bulkhead, from when it was submitted until it was done.
+// A query rejected is done when it is submitted, and a query still running is not done.
+type queryRecord struct {
+	orgID influxdb.ID
+	start time.Time
+	end   time.Time
+	done  bool
+}
+
+// New returns a bulkhead named name, which tells apart the metrics of the bulkheads of different subsystems.
+func New(name string, config Config, logger *zap.Logger) *Bulkhead {
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCooldown
+	}
+	return &Bulkhead{
+		name:          name,
+		config:        config,
+		logger:        logger.With(zap.String("bulkhead", name)),
+		Now:           time.Now,
+		TrafficWindow: DefaultTrafficWindow,
+		orgs:          make(map[influxdb.ID]*compartment),
+		metrics:       newMetrics(name),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (b *Bulkhead) PrometheusCollectors() []prometheus.Collector {
+	return b.metrics.PrometheusCollectors()
+}
+
+// State returns the state of the breaker of the organization.
+func (b *Bulkhead) State(orgID influxdb.ID) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.orgs[orgID]; ok {
+		return c.state
+	}
+	return BreakerClosed
+}
+
+// compartment returns the compartment of the organization, creating it if needed.
+// b.mu must be held.
+func (b *Bulkhead) compartment(orgID influxdb.ID) *compartment {
+	c, ok := b.orgs[orgID]
+	if !ok {
+		c = &compartment{state: BreakerClosed}
+		b.orgs[orgID] = c
+	}
+	return c
+}
+
+// acquire lets a query of the organization through, or returns why it is rejected.
+func (b *Bulkhead) acquire(orgID influxdb.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.compartment(orgID)
+	// An open breaker rejects queries until its cooldown is over, and a half open one is already being tried by a query.
+	if c.state == BreakerHalfOpen || (c.state == BreakerOpen && b.Now().Before(c.openUntil)) {
+		b.metrics.rejected.WithLabelValues(orgID.String(), "breaker_open").Inc()
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("queries of organization %s are suspended after %d consecutive failures", orgID, c.failures),
+		}
+	}
+
+	if b.config.MaxConcurrency > 0 && c.active >= b.config.MaxConcurrency {
+		b.metrics.rejected.WithLabelValues(orgID.String(), "concurrency").Inc()
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("organization %s is already running %d queries", orgID, c.active),
+		}
+	}
+
+	if c.state == BreakerOpen {
+		// The cooldown is over, so this query tries the breaker.
+		b.setState(orgID, c, BreakerHalfOpen)
+	}
+	c.active++
+	b.metrics.active.WithLabelValues(orgID.String()).Inc()
+	return nil
+}
+
+// release records the end of a query of the organization, and whether it failed.
+func (b *Bulkhead) release(orgID influxdb.ID, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.compartment(orgID)
+	c.active--
+	b.metrics.active.WithLabelValues(orgID.String()).Dec()
+
+	if !failed {
+		c.failures = 0
+		if c.state == BreakerHalfOpen {
+			b.setState(orgID, c, BreakerClosed)
+		}
+		return
+	}
+
+	c.failures++
+	b.metrics.failures.WithLabelValues(orgID.String()).Inc()
+	if b.config.FailureThreshold > 0 && (c.state == BreakerHalfOpen || c.failures >= b.config.FailureThreshold) {
+		c.openUntil = b.Now().Add(b.config.Cooldown)
+		if c.state != BreakerOpen {
+			b.logger.Warn("Suspending the queries of an organization",
+				zap.String("org_id", orgID.String()),
+				zap.Int("failures", c.failures),
+				zap.Duration("cooldown", b.config.Cooldown))
+		}
+		b.setState(orgID, c, BreakerOpen)
+	}
+}
+
+// record records a query of the organization submitted now, forgetting the queries older than the traffic window.
+func (b *Bulkhead) record(orgID influxdb.ID) *queryRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := &queryRecord{orgID: orgID, start: b.Now()}
+	b.traffic = append(b.traffic, r)
+	for len(b.traffic)-b.trafficStart > maxTrafficRecords || r.start.Sub(b.traffic[b.trafficStart].start) > b.TrafficWindow {
+		b.traffic[b.trafficStart] = nil
+		b.trafficStart++
+	}
+	// Move the queries kept to the front once the forgotten ones are the most.
+	if b.trafficStart > len(b.traffic)/2 {
+		n := copy(b.traffic, b.traffic[b.trafficStart:])
+		b.traffic, b.trafficStart = b.traffic[:n], 0
+	}
+	return r
+}
+
+// finish records that the query r is done.
+func (b *Bulkhead) finish(r *queryRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r.end, r.done = b.Now(), true
+}
+
+// OrgConcurrency reports the queries of an organization recorded by a bulkhead that another concurrency limit
+// would have rejected.
+type OrgConcurrency struct {
+	OrgID    influxdb.ID
+	Queries  int
+	Rejected int
+
+	// Peak is the largest number of queries of the organization that ran at once.
+	Peak int
+}
+
+// SimulateConcurrency evaluates the queries recorded against the concurrency limit max, as if it had been
+// enforced instead of the one of b, and returns the time of the first query recorded along with the queries
+// of each organization, sorted by ID. The queries rejected by b are evaluated as if they had run for no time.
+func (b *Bulkhead) SimulateConcurrency(max int) (time.Time, []OrgConcurrency) {
+	b.mu.Lock()
+	now := b.Now()
+	traffic := make([]queryRecord, 0, len(b.traffic)-b.trafficStart)
+	for _, r := range b.traffic[b.trafficStart:] {
+		traffic = append(traffic, *r)
+	}
+	b.mu.Unlock()
+
+	var since time.Time
+	if len(traffic) > 0 {
+		since = traffic[0].start
+	}
+
+	type org struct {
+		OrgConcurrency
+		running []time.Time // the ends of the queries running under max
+		peak    []time.Time // the ends of the queries running without a limit
+	}
+	orgs := make(map[influxdb.ID]*org)
+	// stillRunning removes the queries done at t from ends.
+	stillRunning := func(ends []time.Time, t time.Time) []time.Time {
+		running := ends[:0]
+		for _, end := range ends {
+			if end.After(t) {
+				running = append(running, end)
+			}
+		}
+		return running
+	}
+	for _, r := range traffic {
+		o, ok := orgs[r.orgID]
+		if !ok {
+			o = &org{OrgConcurrency: OrgConcurrency{OrgID: r.orgID}}
+			orgs[r.orgID] = o
+		}
+		end := r.end
+		if !r.done {
+			end = now
+		}
+
+		o.Queries++
+		o.peak = append(stillRunning(o.peak, r.start), end)
+		if len(o.peak) > o.Peak {
+			o.Peak = len(o.peak)
+		}
+		o.running = stillRunning(o.running, r.start)
+		if max > 0 && len(o.running) >= max {
+			o.Rejected++
+			continue
+		}
+		o.running = append(o.running, end)
+	}
+
+	res := make([]OrgConcurrency, 0, len(orgs))
+	for _, o := range orgs {
+		res = append(res, o.OrgConcurrency)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].OrgID < res[j].OrgID })
+	return since, res
+}
+
+// setState sets the state of the breaker of c.
+// b.mu must be held.
+func (b *Bulkhead) setState(orgID influxdb.ID, c *compartment, state BreakerState) {
+	c.state = state
+	open := 0.0
+	if state != BreakerClosed {
+		open = 1
+	}
+	b.metrics.breakerOpen.WithLabelValues(orgID.String()).Set(open)
+}
+
+// recovered reports a panic of a query of the organization, and returns it as an error.
+func (b *Bulkhead) recovered(orgID influxdb.ID, r interface{}) error {
+	b.metrics.panics.WithLabelValues(orgID.String()).Inc()
+	err := fmt.Errorf("panic: %v", r)
+	b.logger.Error("Recovered from a query panic",
+		zap.String("org_id", orgID.String()),
+		zap.Error(err),
+		zap.String("stack", string(debug.Stack())))
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  "query panicked",
+		Err:  err,
+	}
+}
+
+// failed returns true if err is the error of a failed query of the organization,
+// reporting the panics that Flux recovered from while executing the query.
+func (b *Bulkhead) failed(orgID influxdb.ID, err error) bool {
+	if err == nil {
+		return false
+	}
+	// Flux recovers from the panics of the goroutines executing a query, and returns them as errors.
+	if strings.HasPrefix(err.Error(), "panic:") {
+		b.metrics.panics.WithLabelValues(orgID.String()).Inc()
+		b.logger.Error("Query of an organization panicked",
+			zap.String("org_id", orgID.String()),
+			zap.Error(err))
+		return true
+	}
+	return b.IsFailure != nil && b.IsFailure(err)
+}
+
+// AsyncQueryService returns s with its queries going through the compartments of b.
+func (b *Bulkhead) AsyncQueryService(s query.AsyncQueryService) query.AsyncQueryService {
+	return &asyncQueryService{s: s, b: b}
+}
+
+type asyncQueryService struct {
+	s query.AsyncQueryService
+	b *Bulkhead
+}
+
+// Query submits the query through the compartment of its organization.
+// The query holds its place in the compartment until it is done.
+func (s *asyncQueryService) Query(ctx context.Context, req *query.Request) (q flux.Query, err error) {
+	orgID := req.OrganizationID
+	rec := s.b.record(orgID)
+	if err := s.b.acquire(orgID); err != nil {
+		s.b.finish(rec)
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			q, err = nil, s.b.recovered(orgID, r)
+			s.b.release(orgID, true)
+			s.b.finish(rec)
+		}
+	}()
+
+	q, err = s.s.Query(ctx, req)
+	if err != nil {
+		s.b.release(orgID, s.b.failed(orgID, err))
+		s.b.finish(rec)
+		return nil, err
+	}
+	return &compartmentQuery{Query: q, b: s.b, orgID: orgID, rec: rec}, nil
+}
+
+// compartmentQuery releases the place of a query in its compartment once it is done.
+type compartmentQuery struct {
+	flux.Query
+	b     *Bulkhead
+	orgID influxdb.ID
+	rec   *queryRecord
+	once  sync.Once
+}
+
+func (q *compartmentQuery) Done() {
+	q.Query.Done()
+	q.once.Do(func() {
+		q.b.release(q.orgID, q.b.failed(q.orgID, q.Query.Err()))
+		q.b.finish(q.rec)
+	})
+}
@@ -0,0 +1,137 @@
+package bulkhead_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/bulkhead"
+	"github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgA = influxdb.ID(1)
+	orgB = influxdb.ID(2)
+)
+
+// newService returns an AsyncQueryService through b, whose queries panic when submitted if their org is in panics,
+// or fail with the error in errs of their org.
+func newService(b *bulkhead.Bulkhead, panics map[influxdb.ID]bool, errs map[influxdb.ID]error) query.AsyncQueryService {
+	return b.AsyncQueryService(&mock.AsyncQueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.Query, error) {
+			if panics[req.OrganizationID] {
+				panic("boom")
+			}
+			q := mock.NewQuery(nil)
+			if err := errs[req.OrganizationID]; err != nil {
+				q.SetErr(err)
+			}
+			return q, nil
+		},
+	})
+}
+
+func runQuery(s query.AsyncQueryService, orgID influxdb.ID) error {
+	q, err := s.Query(context.Background(), &query.Request{OrganizationID: orgID})
+	if err != nil {
+		return err
+	}
+	q.Done()
+	return nil
+}
+
+func TestBulkhead_RecoversPanics(t *testing.T) {
+	b := bulkhead.New("query", bulkhead.Config{}, zaptest.NewLogger(t))
+	s := newService(b, map[influxdb.ID]bool{orgA: true}, nil)
+
+	err := runQuery(s, orgA)
+	if influxdb.ErrorCode(err) != influxdb.EInternal {
+		t.Fatalf("expected the panic as an internal error, got %v", err)
+	}
+	if err := runQuery(s, orgB); err != nil {
+		t.Fatalf("expected the queries of another org to run, got %v", err)
+	}
+}
+
+func TestBulkhead_Breaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := bulkhead.New("query", bulkhead.Config{FailureThreshold: 2, Cooldown: time.Minute}, zaptest.NewLogger(t))
+	b.Now = func() time.Time { return now }
+
+	errs := map[influxdb.ID]error{orgA: errors.New("panic: runtime error: index out of range")}
+	s := newService(b, nil, errs)
+
+	for i := 0; i < 2; i++ {
+		if err := runQuery(s, orgA); err != nil {
+			t.Fatalf("expected the query to be submitted, got %v", err)
+		}
+	}
+	if got := b.State(orgA); got != bulkhead.BreakerOpen {
+		t.Fatalf("expected the breaker of the org to open after 2 panics, got %s", got)
+	}
+	if err := runQuery(s, orgA); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected the queries of the org to be rejected, got %v", err)
+	}
+	if err := runQuery(s, orgB); err != nil {
+		t.Fatalf("expected the queries of another org to run, got %v", err)
+	}
+
+	// Once the cooldown is over, a failing query opens the breaker again.
+	now = now.Add(time.Minute)
+	if err := runQuery(s, orgA); err != nil {
+		t.Fatalf("expected a query to try the breaker, got %v", err)
+	}
+	if got := b.State(orgA); got != bulkhead.BreakerOpen {
+		t.Fatalf("expected the breaker of the org to open again, got %s", got)
+	}
+
+	// And a successful query closes it.
+	now = now.Add(time.Minute)
+	delete(errs, orgA)
+	if err := runQuery(s, orgA); err != nil {
+		t.Fatalf("expected a query to try the breaker, got %v", err)
+	}
+	if got := b.State(orgA); got != bulkhead.BreakerClosed {
+		t.Fatalf("expected the breaker of the org to close, got %s", got)
+	}
+}
+
+func TestBulkhead_IgnoresUserErrors(t *testing.T) {
+	b := bulkhead.New("query", bulkhead.Config{FailureThreshold: 1}, zaptest.NewLogger(t))
+	s := newService(b, nil, map[influxdb.ID]error{orgA: errors.New("bucket not found")})
+
+	for i := 0; i < 3; i++ {
+		if err := runQuery(s, orgA); err != nil {
+			t.Fatalf("expected the query to be submitted, got %v", err)
+		}
+	}
+	if got := b.State(orgA); got != bulkhead.BreakerClosed {
+		t.Fatalf("expected errors other than panics to leave the breaker closed, got %s", got)
+	}
+}
+
+func TestBulkhead_MaxConcurrency(t *testing.T) {
+	b := bulkhead.New("query", bulkhead.Config{MaxConcurrency: 1}, zaptest.NewLogger(t))
+	s := newService(b, nil, nil)
+
+	q, err := s.Query(context.Background(), &query.Request{OrganizationID: orgA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runQuery(s, orgA); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected a second query of the org to be rejected, got %v", err)
+	}
+	if err := runQuery(s, orgB); err != nil {
+		t.Fatalf("expected the queries of another org to run, got %v", err)
+	}
+
+	q.Done()
+	if err := runQuery(s, orgA); err != nil {
+		t.Fatalf("expected a query of the org to run once the first is done, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package bulkhead
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics are the metrics of a bulkhead, split out by organization.
+type metrics struct {
+	active      *prometheus.GaugeVec
+	rejected    *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	panics      *prometheus.CounterVec
+	breakerOpen *prometheus.GaugeVec
+}
+
+func newMetrics(name string) *metrics {
+	const namespace = "query"
+	const subsystem = "bulkhead"
+	labels := prometheus.Labels{"bulkhead": name}
+
+	return &metrics{
+		active: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "active",
+			Help:        "Number of queries running, split out by organization.",
+			ConstLabels: labels,
+		}, []string{"org"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "rejected_total",
+			Help:        "Total number of queries rejected, split out by organization and reason.",
+			ConstLabels: labels,
+		}, []string{"org", "reason"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "failures_total",
+			Help:        "Total number of failed queries counted by the breakers, split out by organization.",
+			ConstLabels: labels,
+		}, []string{"org"}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "panics_total",
+			Help:        "Total number of queries that panicked, split out by organization.",
+			ConstLabels: labels,
+		}, []string{"org"}),
+		breakerOpen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "breaker_open",
+			Help:        "Whether the breaker of an organization is open (1) or closed (0).",
+			ConstLabels: labels,
+		}, []string{"org"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.active,
+		m.rejected,
+		m.failures,
+		m.panics,
+		m.breakerOpen,
+	}
+}
@@ -0,0 +1,267 @@
+package control
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultCompileCacheMaxAge is how long a compiled spec stays in a CompileCache without being used.
+const DefaultCompileCacheMaxAge = 7 * 24 * time.Hour
+
+// fluxModulePath is the path of the Flux module, as reported in the build information.
+const fluxModulePath = "github.com/influxdata/flux"
+
+// versionDirPrefix prefixes the directories holding the specs of a version of Flux.
+const versionDirPrefix = "flux-"
+
+// FluxVersion returns the version of Flux this binary was built with, or "unknown".
+func FluxVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path == fluxModulePath {
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
+
+// CompileCache compiles Flux scripts into specs, reusing the specs it compiled before.
+// Compiled specs are persisted in a directory, keyed by the hash of the script and
+// the version of Flux, so that they survive restarts: opening the cache loads them
+// back, and the first runs of tasks after a restart do not recompile their scripts.
+//
+// A spec is reused for a later time by setting its now time, so scripts reading
+// the current time explicitly, through now() or systemTime(), are never cached.
+type CompileCache struct {
+	dir         string // Directory of the specs of fluxVersion.
+	fluxVersion string
+	logger      *zap.Logger
+
+	// MaxAge is how long a persisted spec is kept without being used.
+	MaxAge time.Duration
+
+	mu      sync.RWMutex
+	specs   map[string][]byte // JSON specs by key.
+	touched map[string]bool   // Keys of the persisted specs used since the cache was opened.
+
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// NewCompileCache returns a CompileCache persisting specs compiled with fluxVersion in dir.
+func NewCompileCache(dir, fluxVersion string, logger *zap.Logger) *CompileCache {
+	const namespace = "query"
+	const subsystem = "compile_cache"
+
+	return &CompileCache{
+		dir:         filepath.Join(dir, versionDirPrefix+sanitizeVersion(fluxVersion)),
+		fluxVersion: fluxVersion,
+		logger:      logger,
+		MaxAge:      DefaultCompileCacheMaxAge,
+		specs:       make(map[string][]byte),
+		touched:     make(map[string]bool),
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "hits_total",
+			Help:      "Total number of Flux scripts whose compiled spec was reused.",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "misses_total",
+			Help:      "Total number of Flux scripts compiled.",
+		}),
+	}
+}
+
+// sanitizeVersion makes a Flux version usable as a directory name.
+func sanitizeVersion(version string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, version)
+}
+
+// Open loads the persisted specs compiled with the version of Flux of the cache.
+// It removes the specs compiled with other versions, and the specs unused for longer than MaxAge.
+func (c *CompileCache) Open(ctx context.Context) error {
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		return err
+	}
+
+	// Specs of other versions of Flux are stale.
+	parent := filepath.Dir(c.dir)
+	versions, err := ioutil.ReadDir(parent)
+	if err != nil {
+		return err
+	}
+	for _, fi := range versions {
+		if !fi.IsDir() || !strings.HasPrefix(fi.Name(), versionDirPrefix) {
+			continue
+		}
+		if path := filepath.Join(parent, fi.Name()); path != c.dir {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, fi := range files {
+		path := filepath.Join(c.dir, fi.Name())
+		key := strings.TrimSuffix(fi.Name(), ".json")
+		if fi.IsDir() || key == fi.Name() {
+			continue
+		}
+		if c.MaxAge > 0 && now.Sub(fi.ModTime()) > c.MaxAge {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c.specs[key] = b
+	}
+
+	c.logger.Info("Loaded compiled Flux specs",
+		zap.String("path", c.dir),
+		zap.String("flux_version", c.fluxVersion),
+		zap.Int("specs", len(c.specs)))
+	return nil
+}
+
+// Compile returns the spec of script for the time now.
+func (c *CompileCache) Compile(ctx context.Context, script string, now time.Time) (*flux.Spec, error) {
+	key := c.key(script)
+	if spec := c.lookup(key); spec != nil {
+		c.hits.Inc()
+		spec.Now = now
+		return spec, nil
+	}
+	c.misses.Inc()
+
+	pkg, err := flux.Parse(script)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := flux.CompileAST(ctx, pkg, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if readsCurrentTime(pkg) {
+		return spec, nil
+	}
+	if err := c.store(key, spec); err != nil {
+		c.logger.Info("Failed to cache compiled Flux spec", zap.String("key", key), zap.Error(err))
+	}
+	return spec, nil
+}
+
+// key returns the key of the spec of script.
+func (c *CompileCache) key(script string) string {
+	h := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(h[:])
+}
+
+// lookup returns a new copy of the spec of key, so that callers can modify it, or nil if there is none.
+func (c *CompileCache) lookup(key string) *flux.Spec {
+	c.mu.RLock()
+	b, ok := c.specs[key]
+	touched := c.touched[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	spec := new(flux.Spec)
+	if err := json.Unmarshal(b, spec); err != nil {
+		c.logger.Info("Failed to decode cached Flux spec", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+
+	// Record the use of the persisted spec once, to keep it past MaxAge.
+	if !touched {
+		now := time.Now()
+		if err := os.Chtimes(c.path(key), now, now); err != nil && !os.IsNotExist(err) {
+			c.logger.Info("Failed to record use of cached Flux spec", zap.String("key", key), zap.Error(err))
+		}
+		c.mu.Lock()
+		c.touched[key] = true
+		c.mu.Unlock()
+	}
+	return spec
+}
+
+// store caches and persists spec as the spec of key.
+func (c *CompileCache) store(key string, spec *flux.Spec) error {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	// Only cache specs that can be decoded, which requires every operation to be registered.
+	if err := json.Unmarshal(b, new(flux.Spec)); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.specs[key] = b
+	c.touched[key] = true
+	c.mu.Unlock()
+
+	tmp := c.path(key) + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(key))
+}
+
+func (c *CompileCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// readsCurrentTime returns true if the script in pkg reads the current time explicitly.
+func readsCurrentTime(pkg *ast.Package) bool {
+	var found bool
+	ast.Visit(pkg, func(n ast.Node) {
+		if id, ok := n.(*ast.Identifier); ok && (id.Name == "now" || id.Name == "systemTime") {
+			found = true
+		}
+	})
+	return found
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *CompileCache) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{c.hits, c.misses}
+}
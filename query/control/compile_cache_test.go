@@ -0,0 +1,148 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap/zaptest"
+)
+
+const testScript = `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "cpu")`
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func mustMarshal(t *testing.T, spec *flux.Spec) string {
+	t.Helper()
+	b, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func mustOpenCompileCache(t *testing.T, dir, version string) *CompileCache {
+	t.Helper()
+	c := NewCompileCache(dir, version, zaptest.NewLogger(t))
+	if err := c.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCompileCache_Compile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compile-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	want, err := flux.Compile(ctx, testScript, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := mustOpenCompileCache(t, dir, "v0.23.0")
+	if _, err := c.Compile(ctx, testScript, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// The spec compiled for a previous run is reused for the next one.
+	later := now.Add(time.Hour)
+	spec, err := c.Compile(ctx, testScript, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := counterValue(t, c.hits); got != 1 {
+		t.Errorf("expected 1 hit, got %v", got)
+	}
+	if !spec.Now.Equal(later) {
+		t.Errorf("expected the spec for %v, got %v", later, spec.Now)
+	}
+	want.Now = later
+	if diff := cmp.Diff(mustMarshal(t, want), mustMarshal(t, spec)); diff != "" {
+		t.Errorf("unexpected spec -want/+got:\n%s", diff)
+	}
+
+	// The spec survives a restart.
+	c = mustOpenCompileCache(t, dir, "v0.23.0")
+	if _, err := c.Compile(ctx, testScript, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := counterValue(t, c.hits); got != 1 {
+		t.Errorf("expected the persisted spec to be reused, got %v hits", got)
+	}
+}
+
+func TestCompileCache_Compile_ReadsCurrentTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compile-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	c := mustOpenCompileCache(t, dir, "v0.23.0")
+	script := `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: now())`
+	for i := 0; i < 2; i++ {
+		if _, err := c.Compile(ctx, script, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := counterValue(t, c.misses); got != 2 {
+		t.Errorf("expected a script calling now() to be compiled every time, got %v misses", got)
+	}
+}
+
+func TestCompileCache_Open_RemovesStaleSpecs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compile-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	old := mustOpenCompileCache(t, dir, "v0.22.0")
+	if _, err := old.Compile(ctx, testScript, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	c := mustOpenCompileCache(t, dir, "v0.23.0")
+	if _, err := os.Stat(old.dir); !os.IsNotExist(err) {
+		t.Errorf("expected the specs of another version of Flux removed, got %v", err)
+	}
+
+	if _, err := c.Compile(ctx, testScript, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	long := time.Now().Add(-2 * DefaultCompileCacheMaxAge)
+	if err := os.Chtimes(c.path(c.key(testScript)), long, long); err != nil {
+		t.Fatal(err)
+	}
+
+	c = mustOpenCompileCache(t, dir, "v0.23.0")
+	if len(c.specs) != 0 {
+		t.Errorf("expected the specs unused for longer than the max age removed, got %d", len(c.specs))
+	}
+	if files, _ := filepath.Glob(filepath.Join(c.dir, "*.json")); len(files) != 0 {
+		t.Errorf("expected no persisted spec, got %v", files)
+	}
+}
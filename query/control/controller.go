@@ -3,9 +3,12 @@ package control
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/lang"
 	"github.com/prometheus/client_golang/prometheus"
 
 	platform "github.com/influxdata/influxdb"
@@ -16,29 +19,109 @@ import (
 // orgLabel is the metric label to use in the controller
 const orgLabel = "org"
 
+// tagLabel is the metric label of the tag of the queries, see query.Request.
+const tagLabel = "tag"
+
 // Controller implements AsyncQueryService by consuming a control.Controller.
 type Controller struct {
-	c *control.Controller
+	c     *control.Controller
+	cache *CompileCache
+
+	mu      sync.Mutex
+	running map[platform.ID]*runningQuery
+
+	quotas       OrgQuotas
+	active       map[platform.ID]int // the queries of each organization holding a place in its quotas
+	queued       map[platform.ID]int // the queries of each organization waiting for its quotas
+	released     chan struct{}       // closed whenever a place is released
+	quotaMetrics *quotaMetrics
+
+	lanes *lanes
+
+	slowQueries    SlowQueryConfig
+	slowRecorders  []SlowQueryRecorder
+	slowQueryCount *prometheus.CounterVec
 }
 
 // NewController creates a new Controller specific to platform.
 func New(config control.Config) *Controller {
-	config.MetricLabelKeys = append(config.MetricLabelKeys, orgLabel)
+	config.MetricLabelKeys = append(config.MetricLabelKeys, orgLabel, tagLabel)
 	c := control.New(config)
-	return &Controller{c: c}
+	return &Controller{
+		c:            c,
+		running:      make(map[platform.ID]*runningQuery),
+		active:       make(map[platform.ID]int),
+		queued:       make(map[platform.ID]int),
+		released:     make(chan struct{}),
+		quotaMetrics: newQuotaMetrics(),
+		lanes:        newLanes(),
+
+		slowQueryCount: newSlowQueryCount(),
+	}
+}
+
+// WithCompileCache sets the cache that Flux scripts are compiled through.
+func (c *Controller) WithCompileCache(cache *CompileCache) {
+	c.cache = cache
 }
 
 // Query satisfies the AsyncQueryService while ensuring the request is propagated on the context.
+// The query waits for the quotas of its organization, or fails with a QuotaExceededError
+// if its organization has too many queries waiting already. It is then submitted to the interactive lane.
 func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	return c.query(ctx, req, LaneInteractive)
+}
+
+// query submits req to the lane l once its organization is within its quotas.
+func (c *Controller) query(ctx context.Context, req *query.Request, l Lane) (flux.Query, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	// The text of the query is kept for the slow query log, before the cache compiles it.
+	text := queryText(req.Compiler)
+	if c.cache != nil {
+		var err error
+		if req, err = c.compileCached(ctx, req); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  err.Error(),
+			}
+		}
+	}
+
 	// Set the request on the context so platform specific Flux operations can retrieve it later.
 	ctx = query.ContextWithRequest(ctx, req)
 	// Set the org label value for controller metrics
 	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String())
+	ctx = context.WithValue(ctx, tagLabel, req.Tag)
+
+	admitted, err := c.admit(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.ETooManyRequests,
+			Msg:  err.Error(),
+			Err:  err,
+		}
+	}
+
+	laneAdmitted, err := c.admitLane(ctx, l)
+	if err != nil {
+		c.mu.Lock()
+		c.release(req.OrganizationID, admitted)
+		c.mu.Unlock()
+		return nil, &platform.Error{
+			Code: platform.ETooManyRequests,
+			Msg:  err.Error(),
+			Err:  err,
+		}
+	}
+
 	q, err := c.c.Query(ctx, req.Compiler)
 	if err != nil {
+		c.mu.Lock()
+		c.release(req.OrganizationID, admitted)
+		c.releaseLane(laneAdmitted)
+		c.mu.Unlock()
 		// If the controller reports an error, it's usually because of a syntax error
 		// or other problem that the client must fix.
 		return q, &platform.Error{
@@ -47,12 +130,36 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 		}
 	}
 
-	return q, nil
+	return c.track(q, req, text, admitted, laneAdmitted), nil
+}
+
+// compileCached returns a copy of req compiling its Flux script through the cache of c.
+// Requests with other compilers are returned as is.
+func (c *Controller) compileCached(ctx context.Context, req *query.Request) (*query.Request, error) {
+	var script string
+	switch compiler := req.Compiler.(type) {
+	case lang.FluxCompiler:
+		script = compiler.Query
+	case *lang.FluxCompiler:
+		script = compiler.Query
+	default:
+		return req, nil
+	}
+
+	spec, err := c.cache.Compile(ctx, script, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	r := *req
+	r.Compiler = lang.SpecCompiler{Spec: spec}
+	return &r, nil
 }
 
 // PrometheusCollectors satisifies the prom.PrometheusCollector interface.
 func (c *Controller) PrometheusCollectors() []prometheus.Collector {
-	return c.c.PrometheusCollectors()
+	return append(c.c.PrometheusCollectors(), c.quotaMetrics.queued, c.quotaMetrics.rejected,
+		c.lanes.queued, c.lanes.admitted, c.lanes.rejected, c.slowQueryCount)
 }
 
 // Shutdown shuts down the underlying Controller.
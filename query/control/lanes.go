@@ -0,0 +1,234 @@
+package control
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/influxdata/influxdb/query"
+)
+
+// Lane is the admission queue of a kind of queries of a Controller.
+type Lane string
+
+// The lanes of a Controller.
+const (
+	// LaneInteractive is the lane of the queries of the API, such as the ones of dashboards.
+	// It is the lane of the queries submitted with Query.
+	LaneInteractive Lane = "interactive"
+	// LaneTask is the lane of the runs of tasks and of the queries of the subsystems of the server.
+	LaneTask Lane = "task"
+)
+
+// LaneConfig shares the queries running on a Controller at once between its lanes, so that the queries
+// of a lane do not delay the ones of the other. While the queries of every lane are waiting, the places
+// that free up are given to the lanes in proportion to their weights; a lane without waiting queries
+// leaves its share to the others.
+type LaneConfig struct {
+	// Concurrency is the number of queries of all the lanes running at once. Zero disables the lanes.
+	Concurrency int
+
+	// Weights are the shares of the places of the lanes. A lane without a positive weight has a weight of 1.
+	Weights map[Lane]int
+
+	// QueueSize is the number of queries waiting in a lane at most.
+	// The queries over it are rejected right away.
+	QueueSize int
+}
+
+func (lc LaneConfig) weight(l Lane) int {
+	if w := lc.Weights[l]; w > 0 {
+		return w
+	}
+	return 1
+}
+
+// LaneFullError is the error of a query rejected because its lane has too many queries waiting,
+// or because its context is done before it gets a place.
+type LaneFullError struct {
+	Lane      Lane
+	QueueSize int
+}
+
+func (e *LaneFullError) Error() string {
+	return fmt.Sprintf("the %s lane of the query controller is full with %d queries waiting", e.Lane, e.QueueSize)
+}
+
+// laneWaiter is a query waiting in a lane. Its ready channel is closed once it is given a place.
+type laneWaiter struct {
+	ready chan struct{}
+}
+
+// lanes are the places and queues of the lanes of a Controller. They are guarded by the mutex of the controller.
+type lanes struct {
+	config LaneConfig
+	active int
+	queues map[Lane][]*laneWaiter
+	// pass is the virtual time of each lane, which moves forward by the inverse of its weight whenever
+	// a query of the lane is given a place. The waiting lane with the lowest pass gets the next place.
+	pass map[Lane]float64
+	now  float64
+
+	queued   *prometheus.GaugeVec
+	admitted *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+}
+
+func newLanes() *lanes {
+	const namespace = "query"
+	const subsystem = "control_lane"
+
+	return &lanes{
+		queues: make(map[Lane][]*laneWaiter),
+		pass:   make(map[Lane]float64),
+		queued: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queued",
+			Help:      "Number of queries waiting for a place in their lane, split out by lane.",
+		}, []string{"lane"}),
+		admitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "admitted_total",
+			Help:      "Total number of queries given a place in their lane, split out by lane.",
+		}, []string{"lane"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rejected_total",
+			Help:      "Total number of queries rejected by their lane, split out by lane.",
+		}, []string{"lane"}),
+	}
+}
+
+// WithLanes sets the lanes of the queries of the controller.
+func (c *Controller) WithLanes(config LaneConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lanes.config = config
+}
+
+// Lane returns an AsyncQueryService submitting its queries to the lane l of the controller.
+func (c *Controller) Lane(l Lane) query.AsyncQueryService {
+	return laneQueryService{c: c, lane: l}
+}
+
+type laneQueryService struct {
+	c    *Controller
+	lane Lane
+}
+
+func (s laneQueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	return s.c.query(ctx, req, s.lane)
+}
+
+// admitLane waits for a place for a query in the lane l. It returns whether a place is reserved,
+// which must be released once the query is done.
+func (c *Controller) admitLane(ctx context.Context, l Lane) (bool, error) {
+	c.mu.Lock()
+	ls := c.lanes
+	if ls.config.Concurrency <= 0 {
+		c.mu.Unlock()
+		return false, nil
+	}
+	if ls.active < ls.config.Concurrency && ls.waiting() == 0 {
+		ls.admit(l)
+		c.mu.Unlock()
+		return true, nil
+	}
+	if len(ls.queues[l]) >= ls.config.QueueSize {
+		c.mu.Unlock()
+		ls.rejected.WithLabelValues(string(l)).Inc()
+		return false, &LaneFullError{Lane: l, QueueSize: ls.config.QueueSize}
+	}
+
+	// A lane that was idle catches up with the others, so that it does not make up for the time it was idle.
+	if len(ls.queues[l]) == 0 && ls.pass[l] < ls.now {
+		ls.pass[l] = ls.now
+	}
+	w := &laneWaiter{ready: make(chan struct{})}
+	ls.queues[l] = append(ls.queues[l], w)
+	ls.queued.WithLabelValues(string(l)).Inc()
+	c.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true, nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		select {
+		case <-w.ready:
+			// The query got its place as its context was done; the place goes to the next one.
+			ls.release()
+		default:
+			ls.remove(l, w)
+		}
+		ls.rejected.WithLabelValues(string(l)).Inc()
+		return false, &LaneFullError{Lane: l, QueueSize: ls.config.QueueSize}
+	}
+}
+
+// releaseLane frees the place of a query, if admitted, and gives it to the next query waiting.
+// c.mu must be held.
+func (c *Controller) releaseLane(admitted bool) {
+	if admitted {
+		c.lanes.release()
+	}
+}
+
+func (ls *lanes) waiting() int {
+	n := 0
+	for _, q := range ls.queues {
+		n += len(q)
+	}
+	return n
+}
+
+func (ls *lanes) admit(l Lane) {
+	ls.active++
+	ls.now = ls.pass[l]
+	ls.pass[l] += 1 / float64(ls.config.weight(l))
+	ls.admitted.WithLabelValues(string(l)).Inc()
+}
+
+// release frees a place and gives the places free to the waiting lanes with the lowest pass.
+func (ls *lanes) release() {
+	ls.active--
+	for ls.active < ls.config.Concurrency {
+		var next Lane
+		found := false
+		for l, q := range ls.queues {
+			if len(q) == 0 {
+				continue
+			}
+			// The lanes are compared by name when their pass is the same, so that the order is stable.
+			if !found || ls.pass[l] < ls.pass[next] || (ls.pass[l] == ls.pass[next] && l < next) {
+				next, found = l, true
+			}
+		}
+		if !found {
+			return
+		}
+
+		w := ls.queues[next][0]
+		ls.queues[next] = ls.queues[next][1:]
+		ls.queued.WithLabelValues(string(next)).Dec()
+		ls.admit(next)
+		close(w.ready)
+	}
+}
+
+func (ls *lanes) remove(l Lane, w *laneWaiter) {
+	q := ls.queues[l]
+	for i := range q {
+		if q[i] == w {
+			ls.queues[l] = append(q[:i:i], q[i+1:]...)
+			ls.queued.WithLabelValues(string(l)).Dec()
+			return
+		}
+	}
+}
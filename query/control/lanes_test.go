@@ -0,0 +1,192 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+func submitLane(ctx context.Context, c *Controller, l Lane) (flux.Query, error) {
+	return c.Lane(l).Query(ctx, &query.Request{
+		Authorization:  &platform.Authorization{ID: 3},
+		OrganizationID: 1,
+		Compiler:       lang.FluxCompiler{Query: testScript},
+	})
+}
+
+func TestController_LaneWeights(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithLanes(LaneConfig{
+		Concurrency: 1,
+		Weights:     map[Lane]int{LaneInteractive: 2},
+		QueueSize:   10,
+	})
+
+	ctx := context.Background()
+	q, err := c.Query(ctx, &query.Request{
+		Authorization:  &platform.Authorization{ID: 3},
+		OrganizationID: 1,
+		Compiler:       lang.FluxCompiler{Query: testScript},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type admitted struct {
+		lane Lane
+		q    flux.Query
+	}
+	ready := make(chan admitted)
+	for i, l := range []Lane{LaneTask, LaneInteractive, LaneTask, LaneInteractive, LaneTask, LaneInteractive} {
+		l := l
+		go func() {
+			q, err := submitLane(ctx, c, l)
+			if err != nil {
+				t.Error(err)
+			}
+			ready <- admitted{lane: l, q: q}
+		}()
+		waitLaneQueued(t, c, i+1)
+	}
+
+	// The interactive lane gets twice the places of the task lane while both have queries waiting.
+	want := []Lane{LaneTask, LaneInteractive, LaneInteractive, LaneTask, LaneInteractive, LaneTask}
+	for i, l := range want {
+		q.Done()
+		select {
+		case a := <-ready:
+			if a.lane != l {
+				t.Fatalf("query %d admitted from the %s lane, want %s", i, a.lane, l)
+			}
+			q = a.q
+		case <-time.After(5 * time.Second):
+			t.Fatalf("query %d was not admitted", i)
+		}
+		select {
+		case a := <-ready:
+			t.Fatalf("query from the %s lane admitted over the concurrency of the lanes", a.lane)
+		default:
+		}
+	}
+	q.Done()
+}
+
+func TestController_LaneQueueFull(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithLanes(LaneConfig{Concurrency: 2, QueueSize: 1})
+
+	// The task lane takes all the places while the interactive lane is idle.
+	ctx := context.Background()
+	q1, err := submitLane(ctx, c, LaneTask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q2, err := submitLane(ctx, c, LaneTask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q2.Done()
+
+	taskQueued := make(chan flux.Query, 1)
+	go func() {
+		q, err := submitLane(ctx, c, LaneTask)
+		if err != nil {
+			t.Error(err)
+		}
+		taskQueued <- q
+	}()
+	waitLaneQueued(t, c, 1)
+
+	// The queue of the task lane is full.
+	_, err = submitLane(ctx, c, LaneTask)
+	if platform.ErrorCode(err) != platform.ETooManyRequests {
+		t.Fatalf("got error %v, want too many requests", err)
+	}
+	if lerr, ok := err.(*platform.Error).Err.(*LaneFullError); !ok || *lerr != (LaneFullError{Lane: LaneTask, QueueSize: 1}) {
+		t.Errorf("got error %#v, want the task lane full", err.(*platform.Error).Err)
+	}
+
+	// The interactive query queued after the task query gets the next place.
+	interactiveQueued := make(chan flux.Query, 1)
+	go func() {
+		q, err := submitLane(ctx, c, LaneInteractive)
+		if err != nil {
+			t.Error(err)
+		}
+		interactiveQueued <- q
+	}()
+	waitLaneQueued(t, c, 2)
+
+	q1.Done()
+	select {
+	case q := <-interactiveQueued:
+		q.Done()
+	case <-taskQueued:
+		t.Fatal("the queued task query ran before the interactive query")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the interactive query did not run once a place was free")
+	}
+	select {
+	case q := <-taskQueued:
+		q.Done()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task query did not run once a place was free")
+	}
+}
+
+func TestController_LaneQueueCanceled(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithLanes(LaneConfig{Concurrency: 1, QueueSize: 1})
+
+	q1, err := submitLane(context.Background(), c, LaneInteractive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := submitLane(ctx, c, LaneInteractive)
+		errs <- err
+	}()
+	waitLaneQueued(t, c, 1)
+	cancel()
+	if err := <-errs; platform.ErrorCode(err) != platform.ETooManyRequests {
+		t.Fatalf("got error %v, want too many requests", err)
+	}
+	waitLaneQueued(t, c, 0)
+
+	// The place of the first query goes to the next one once it is done.
+	q1.Done()
+	q2, err := submitLane(context.Background(), c, LaneInteractive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q2.Done()
+}
+
+func waitLaneQueued(t *testing.T, c *Controller, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		queued := c.lanes.waiting()
+		c.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d queries queued in the lanes, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,171 @@
+package control
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// The quotas of an organization, as reported by QuotaExceededError.
+const (
+	QuotaConcurrency = "concurrency"
+	QuotaMemory      = "memory"
+)
+
+// OrgQuotas limits the queries of each organization running on a Controller at once,
+// so that the queries of one organization cannot take all the resources of the controller.
+type OrgQuotas struct {
+	// ConcurrencyQuota is the number of queries of an organization running at once. Zero means unlimited.
+	ConcurrencyQuota int
+
+	// MemoryBytesQuota is the memory in bytes allocated by the queries of an organization running at once,
+	// counting the most each query has allocated so far. A query is let through while the queries
+	// of its organization allocate less. Zero means unlimited.
+	MemoryBytesQuota int64
+
+	// QueueSize is the number of queries of an organization waiting for its quotas at most.
+	// The queries over it are rejected right away.
+	QueueSize int
+}
+
+func (q OrgQuotas) enabled() bool {
+	return q.ConcurrencyQuota > 0 || q.MemoryBytesQuota > 0
+}
+
+// QuotaExceededError is the error of a query rejected because its organization is over one of its quotas,
+// either with its queue full or until the context of the query is done.
+type QuotaExceededError struct {
+	OrgID platform.ID
+	// Quota is either QuotaConcurrency or QuotaMemory.
+	Quota string
+	Limit int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.Quota == QuotaMemory {
+		return fmt.Sprintf("organization %s exceeds its query memory quota of %d bytes", e.OrgID, e.Limit)
+	}
+	return fmt.Sprintf("organization %s exceeds its quota of %d concurrent queries", e.OrgID, e.Limit)
+}
+
+// quotaMetrics are the metrics of the quotas of the organizations.
+type quotaMetrics struct {
+	queued   *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+func newQuotaMetrics() *quotaMetrics {
+	const namespace = "query"
+	const subsystem = "control_org"
+
+	return &quotaMetrics{
+		queued: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queued",
+			Help:      "Number of queries waiting for the quotas of their organization, split out by organization.",
+		}, []string{"org"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "quota_rejected_total",
+			Help:      "Total number of queries rejected by the quotas of their organization, split out by organization and quota.",
+		}, []string{"org", "quota"}),
+	}
+}
+
+// WithOrgQuotas sets the quotas of the queries of each organization.
+func (c *Controller) WithOrgQuotas(quotas OrgQuotas) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quotas = quotas
+}
+
+// admit waits until the organization is within its quotas, and reserves a place for a query of it.
+// It returns whether a place is reserved, which must be released once the query is done.
+func (c *Controller) admit(ctx context.Context, orgID platform.ID) (bool, error) {
+	c.mu.Lock()
+	if !c.quotas.enabled() {
+		c.mu.Unlock()
+		return false, nil
+	}
+
+	queued := false
+	dequeue := func() {
+		if queued {
+			if c.queued[orgID]--; c.queued[orgID] <= 0 {
+				delete(c.queued, orgID)
+			}
+			c.quotaMetrics.queued.WithLabelValues(orgID.String()).Dec()
+		}
+	}
+	for {
+		err := c.exceeded(orgID)
+		if err == nil {
+			dequeue()
+			c.active[orgID]++
+			c.mu.Unlock()
+			return true, nil
+		}
+
+		if !queued {
+			if c.queued[orgID] >= c.quotas.QueueSize {
+				c.mu.Unlock()
+				c.quotaMetrics.rejected.WithLabelValues(orgID.String(), err.Quota).Inc()
+				return false, err
+			}
+			c.queued[orgID]++
+			c.quotaMetrics.queued.WithLabelValues(orgID.String()).Inc()
+			queued = true
+		}
+
+		released := c.released
+		c.mu.Unlock()
+		select {
+		case <-released:
+			c.mu.Lock()
+		case <-ctx.Done():
+			c.mu.Lock()
+			dequeue()
+			c.mu.Unlock()
+			c.quotaMetrics.rejected.WithLabelValues(orgID.String(), err.Quota).Inc()
+			return false, err
+		}
+	}
+}
+
+// exceeded returns the quota of the organization that keeps another query of it from running, if any.
+// c.mu must be held.
+func (c *Controller) exceeded(orgID platform.ID) *QuotaExceededError {
+	if max := c.quotas.ConcurrencyQuota; max > 0 && c.active[orgID] >= max {
+		return &QuotaExceededError{OrgID: orgID, Quota: QuotaConcurrency, Limit: int64(max)}
+	}
+	if max := c.quotas.MemoryBytesQuota; max > 0 {
+		var allocated int64
+		for _, q := range c.running {
+			if q.req.OrganizationID == orgID {
+				allocated += q.Statistics().MaxAllocated
+			}
+		}
+		if allocated >= max {
+			return &QuotaExceededError{OrgID: orgID, Quota: QuotaMemory, Limit: max}
+		}
+	}
+	return nil
+}
+
+// release frees the place of a query of the organization, if admitted, and wakes up the queries waiting
+// for the quotas, whose memory the query done frees either way.
+// c.mu must be held.
+func (c *Controller) release(orgID platform.ID, admitted bool) {
+	if admitted {
+		if c.active[orgID]--; c.active[orgID] <= 0 {
+			delete(c.active, orgID)
+		}
+	}
+	close(c.released)
+	c.released = make(chan struct{})
+}
@@ -0,0 +1,148 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestController_OrgConcurrencyQuota(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithOrgQuotas(OrgQuotas{ConcurrencyQuota: 1, QueueSize: 1})
+
+	submit := func(ctx context.Context, orgID platform.ID) (flux.Query, error) {
+		return c.Query(ctx, &query.Request{
+			Authorization:  &platform.Authorization{ID: 3},
+			OrganizationID: orgID,
+			Compiler:       lang.FluxCompiler{Query: testScript},
+		})
+	}
+
+	ctx := context.Background()
+	q1, err := submit(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second query of the org waits for the first one to be done.
+	queued := make(chan flux.Query)
+	go func() {
+		q, err := submit(ctx, 1)
+		if err != nil {
+			t.Error(err)
+		}
+		queued <- q
+	}()
+	waitQueued(t, c, 1, 1)
+
+	// The queue of the org is full.
+	_, err = submit(ctx, 1)
+	if platform.ErrorCode(err) != platform.ETooManyRequests {
+		t.Fatalf("got error %v, want too many requests", err)
+	}
+	qerr, ok := err.(*platform.Error).Err.(*QuotaExceededError)
+	if !ok || *qerr != (QuotaExceededError{OrgID: 1, Quota: QuotaConcurrency, Limit: 1}) {
+		t.Errorf("got error %#v, want the concurrency quota of the org exceeded", err.(*platform.Error).Err)
+	}
+
+	// The queries of other orgs do not wait.
+	q3, err := submit(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q3.Done()
+
+	select {
+	case <-queued:
+		t.Fatal("the query queued ran before the first query of the org was done")
+	default:
+	}
+	q1.Done()
+	select {
+	case q2 := <-queued:
+		q2.Done()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query queued did not run once the first query of the org was done")
+	}
+}
+
+func TestController_OrgQuotaQueueCanceled(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithOrgQuotas(OrgQuotas{ConcurrencyQuota: 1, QueueSize: 1})
+
+	req := &query.Request{
+		Authorization:  &platform.Authorization{ID: 3},
+		OrganizationID: 1,
+		Compiler:       lang.FluxCompiler{Query: testScript},
+	}
+	q1, err := c.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q1.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Query(ctx, req); platform.ErrorCode(err) != platform.ETooManyRequests {
+		t.Fatalf("got error %v, want too many requests once the query waited until its context is done", err)
+	}
+	waitQueued(t, c, 1, 0)
+}
+
+// allocatedQuery is a query that allocated the given bytes at most.
+type allocatedQuery struct {
+	flux.Query
+	allocated int64
+}
+
+func (q *allocatedQuery) Statistics() flux.Statistics {
+	return flux.Statistics{MaxAllocated: q.allocated}
+}
+
+func TestController_OrgMemoryQuota(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+	c.WithOrgQuotas(OrgQuotas{MemoryBytesQuota: 1000})
+
+	c.mu.Lock()
+	c.running[1] = &runningQuery{Query: &allocatedQuery{allocated: 600}, id: 1, req: &query.Request{OrganizationID: 1}}
+	c.running[2] = &runningQuery{Query: &allocatedQuery{allocated: 600}, id: 2, req: &query.Request{OrganizationID: 2}}
+	c.mu.Unlock()
+	if _, err := c.admit(context.Background(), 1); err != nil {
+		t.Fatalf("got error %v, want the query admitted under the memory quota", err)
+	}
+
+	c.mu.Lock()
+	c.running[3] = &runningQuery{Query: &allocatedQuery{allocated: 400}, id: 3, req: &query.Request{OrganizationID: 1}}
+	c.mu.Unlock()
+	_, err := c.admit(context.Background(), 1)
+	if qerr, ok := err.(*QuotaExceededError); !ok || *qerr != (QuotaExceededError{OrgID: 1, Quota: QuotaMemory, Limit: 1000}) {
+		t.Errorf("got error %v, want the memory quota of the org exceeded", err)
+	}
+}
+
+// waitQueued waits until n queries of the org are queued.
+func waitQueued(t *testing.T, c *Controller, orgID platform.ID, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		queued := c.queued[orgID]
+		c.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d queries of org %v queued, want %d", queued, orgID, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,151 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ platform.RunningQueryService = (*Controller)(nil)
+
+// runningQuery is a query submitted to the controller that is not done yet.
+type runningQuery struct {
+	flux.Query
+
+	c         *Controller
+	id        platform.ID
+	req       *query.Request
+	text      string // the Flux or InfluxQL of the query, if any
+	startedAt time.Time
+	admitted  bool // whether the query holds a place in the quotas of its organization
+	inLane    bool // whether the query holds a place in its lane
+	once      sync.Once
+}
+
+// Done removes the query from the running queries of the controller, releases its place
+// in the quotas of its organization and in its lane, and records it if it is slow.
+func (q *runningQuery) Done() {
+	q.Query.Done()
+	q.once.Do(func() {
+		q.c.mu.Lock()
+		delete(q.c.running, q.id)
+		q.c.release(q.req.OrganizationID, q.admitted)
+		q.c.releaseLane(q.inLane)
+		q.c.mu.Unlock()
+		q.c.recordSlowQuery(q)
+	})
+}
+
+// track adds q to the running queries until it is done. It returns q as is if the controller
+// does not identify it, releasing its places in the quotas and in its lane right away.
+func (c *Controller) track(q flux.Query, req *query.Request, text string, admitted, inLane bool) flux.Query {
+	cq, ok := q.(*control.Query)
+	if !ok {
+		c.mu.Lock()
+		c.release(req.OrganizationID, admitted)
+		c.releaseLane(inLane)
+		c.mu.Unlock()
+		return q
+	}
+
+	rq := &runningQuery{
+		Query:     q,
+		c:         c,
+		id:        platform.ID(cq.ID()),
+		req:       req,
+		text:      text,
+		startedAt: time.Now(),
+		admitted:  admitted,
+		inLane:    inLane,
+	}
+	c.mu.Lock()
+	c.running[rq.id] = rq
+	c.mu.Unlock()
+	return rq
+}
+
+// FindRunningQueries returns the queries submitted to the controller that are not done, longest running first.
+func (c *Controller) FindRunningQueries(ctx context.Context, filter platform.RunningQueryFilter) ([]*platform.RunningQuery, error) {
+	c.mu.Lock()
+	qs := make([]*runningQuery, 0, len(c.running))
+	for _, q := range c.running {
+		if filter.OrgID != nil && q.req.OrganizationID != *filter.OrgID {
+			continue
+		}
+		qs = append(qs, q)
+	}
+	c.mu.Unlock()
+
+	now := time.Now()
+	res := make([]*platform.RunningQuery, 0, len(qs))
+	for _, q := range qs {
+		res = append(res, q.info(now))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].StartedAt.Equal(res[j].StartedAt) {
+			return res[i].StartedAt.Before(res[j].StartedAt)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+// FindRunningQueryByID returns a query submitted to the controller that is not done.
+func (c *Controller) FindRunningQueryByID(ctx context.Context, id platform.ID) (*platform.RunningQuery, error) {
+	q, err := c.findRunningQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	return q.info(time.Now()), nil
+}
+
+// CancelRunningQuery cancels a query submitted to the controller that is not done.
+// The query is running until its client is done with it.
+func (c *Controller) CancelRunningQuery(ctx context.Context, id platform.ID) error {
+	q, err := c.findRunningQuery(id)
+	if err != nil {
+		return err
+	}
+	q.Cancel()
+	return nil
+}
+
+func (c *Controller) findRunningQuery(id platform.ID) (*runningQuery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	q, ok := c.running[id]
+	if !ok {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  platform.ErrRunningQueryNotFound,
+		}
+	}
+	return q, nil
+}
+
+// info reports q as of now.
+func (q *runningQuery) info(now time.Time) *platform.RunningQuery {
+	rq := &platform.RunningQuery{
+		ID:           q.id,
+		OrgID:        q.req.OrganizationID,
+		Source:       q.req.Tag,
+		StartedAt:    q.startedAt.UTC(),
+		Duration:     now.Sub(q.startedAt),
+		MaxAllocated: q.Statistics().MaxAllocated,
+	}
+	if q.req.Authorization != nil {
+		rq.AuthorizationID = q.req.Authorization.ID
+	}
+	if cq, ok := q.Query.(*control.Query); ok {
+		rq.State = cq.State().String()
+	}
+	return rq
+}
@@ -0,0 +1,72 @@
+package control
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestController_RunningQueries(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 1, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+
+	ctx := context.Background()
+	submit := func(orgID platform.ID, tag string) *runningQuery {
+		t.Helper()
+		q, err := c.Query(ctx, &query.Request{
+			Authorization:  &platform.Authorization{ID: 3},
+			OrganizationID: orgID,
+			Tag:            tag,
+			Compiler:       lang.FluxCompiler{Query: testScript},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q.(*runningQuery)
+	}
+	q1 := submit(1, "dashboard")
+	q2 := submit(2, "")
+	defer q2.Done()
+
+	qs, err := c.FindRunningQueries(ctx, platform.RunningQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 2 || qs[0].ID != q1.id || qs[1].ID != q2.id {
+		t.Fatalf("got running queries %+v, want both queries in the order they started", qs)
+	}
+	if qs[0].OrgID != 1 || qs[0].Source != "dashboard" || qs[0].AuthorizationID != 3 || qs[0].State == "" {
+		t.Errorf("unexpected running query %+v", qs[0])
+	}
+
+	orgID := platform.ID(2)
+	qs, err = c.FindRunningQueries(ctx, platform.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 || qs[0].ID != q2.id {
+		t.Errorf("got running queries %+v, want the query of org 2", qs)
+	}
+
+	// A canceled query is running until its client is done with it.
+	if err := c.CancelRunningQuery(ctx, q1.id); err != nil {
+		t.Fatal(err)
+	}
+	for range q1.Ready() {
+	}
+	if _, err := c.FindRunningQueryByID(ctx, q1.id); err != nil {
+		t.Errorf("got error %v, want the query canceled to be running", err)
+	}
+	q1.Done()
+
+	if _, err := c.FindRunningQueryByID(ctx, q1.id); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("got error %v, want the query done to be not found", err)
+	}
+	if err := c.CancelRunningQuery(ctx, q1.id); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("got error %v canceling a query done, want not found", err)
+	}
+}
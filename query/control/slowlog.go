@@ -0,0 +1,324 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query/influxql"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// DefaultSlowQueryMaxTextLength is the length in bytes the text of slow queries is truncated to by default.
+const DefaultSlowQueryMaxTextLength = 1024
+
+// The metadata keys of the storage statistics of the queries, summed over the storage reads of a query.
+const (
+	scannedBytesKey  = "influxdb/scanned-bytes"
+	scannedValuesKey = "influxdb/scanned-values"
+)
+
+// SlowQueryConfig sets the thresholds over which a query done on a Controller is slow.
+// A query is slow once it exceeds either threshold.
+type SlowQueryConfig struct {
+	// Duration is the total duration of a slow query. Zero means queries are not slow by duration.
+	Duration time.Duration
+
+	// ScannedBytes is the number of bytes read from storage by a slow query. Zero means queries are not slow by bytes.
+	ScannedBytes int64
+
+	// MaxTextLength is the length in bytes the text of slow queries is truncated to.
+	// DefaultSlowQueryMaxTextLength is used if it is 0.
+	MaxTextLength int
+}
+
+func (cfg SlowQueryConfig) enabled() bool {
+	return cfg.Duration > 0 || cfg.ScannedBytes > 0
+}
+
+// SlowQuery is a query done on a Controller that exceeded the thresholds of its SlowQueryConfig.
+type SlowQuery struct {
+	ID              platform.ID
+	OrgID           platform.ID
+	AuthorizationID platform.ID
+	// Source is the tag the query was submitted with, see query.Request.
+	Source string
+
+	// Text is the Flux or InfluxQL of the query, truncated to the MaxTextLength of the config,
+	// or empty if the query was compiled by its client.
+	Text      string
+	Truncated bool
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Statistics flux.Statistics
+	// ScannedBytes and ScannedValues are the bytes and values read from storage by the query.
+	ScannedBytes  int64
+	ScannedValues int64
+
+	// Err is the error the query failed with, if any.
+	Err error
+}
+
+// SlowQueryRecorder records the slow queries of a Controller.
+type SlowQueryRecorder interface {
+	// RecordSlowQuery records q. It must not block the query.
+	RecordSlowQuery(q *SlowQuery)
+}
+
+// WithSlowQueryLog records the queries over the thresholds of cfg with each of the recorders.
+func (c *Controller) WithSlowQueryLog(cfg SlowQueryConfig, recorders ...SlowQueryRecorder) {
+	if cfg.MaxTextLength <= 0 {
+		cfg.MaxTextLength = DefaultSlowQueryMaxTextLength
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slowQueries = cfg
+	c.slowRecorders = recorders
+}
+
+// recordSlowQuery records q with the slow query recorders of the controller if it is slow.
+// q must be done.
+func (c *Controller) recordSlowQuery(q *runningQuery) {
+	c.mu.Lock()
+	cfg, recorders := c.slowQueries, c.slowRecorders
+	c.mu.Unlock()
+	if !cfg.enabled() || len(recorders) == 0 {
+		return
+	}
+
+	stats := q.Statistics()
+	scannedBytes, scannedValues := sumMetadata(stats.Metadata, scannedBytesKey), sumMetadata(stats.Metadata, scannedValuesKey)
+	if (cfg.Duration <= 0 || stats.TotalDuration < cfg.Duration) && (cfg.ScannedBytes <= 0 || scannedBytes < cfg.ScannedBytes) {
+		return
+	}
+
+	sq := &SlowQuery{
+		ID:            q.id,
+		OrgID:         q.req.OrganizationID,
+		Source:        q.req.Tag,
+		StartedAt:     q.startedAt.UTC(),
+		FinishedAt:    time.Now().UTC(),
+		Statistics:    stats,
+		ScannedBytes:  scannedBytes,
+		ScannedValues: scannedValues,
+		Err:           q.Err(),
+	}
+	if q.req.Authorization != nil {
+		sq.AuthorizationID = q.req.Authorization.ID
+	}
+	sq.Text, sq.Truncated = truncateText(q.text, cfg.MaxTextLength)
+
+	c.slowQueryCount.WithLabelValues(sq.OrgID.String()).Inc()
+	for _, r := range recorders {
+		r.RecordSlowQuery(sq)
+	}
+}
+
+// queryText returns the Flux or InfluxQL the compiler compiles, or an empty string for the other compilers.
+func queryText(compiler flux.Compiler) string {
+	switch compiler := compiler.(type) {
+	case lang.FluxCompiler:
+		return compiler.Query
+	case *lang.FluxCompiler:
+		return compiler.Query
+	case *influxql.Compiler:
+		return compiler.Query
+	}
+	return ""
+}
+
+// truncateText truncates s to at most n bytes without splitting a character, and reports whether it did.
+func truncateText(s string, n int) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n], true
+}
+
+// sumMetadata sums the integer values of the metadata key, which are reported once per storage read.
+func sumMetadata(md flux.Metadata, key string) int64 {
+	var sum int64
+	for _, v := range md[key] {
+		switch v := v.(type) {
+		case int64:
+			sum += v
+		case int:
+			sum += int64(v)
+		case float64:
+			sum += int64(v)
+		}
+	}
+	return sum
+}
+
+func newSlowQueryCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "query",
+		Subsystem: "control",
+		Name:      "slow_queries_total",
+		Help:      "Total number of queries over the thresholds of the slow query log, split out by organization.",
+	}, []string{orgLabel})
+}
+
+// SlowQueryLogger records the slow queries to a structured log.
+type SlowQueryLogger struct {
+	logger *zap.Logger
+}
+
+// NewSlowQueryLogger returns a SlowQueryLogger logging the slow queries with logger.
+func NewSlowQueryLogger(logger *zap.Logger) *SlowQueryLogger {
+	return &SlowQueryLogger{logger: logger}
+}
+
+// RecordSlowQuery logs q.
+func (l *SlowQueryLogger) RecordSlowQuery(q *SlowQuery) {
+	fields := []zap.Field{
+		zap.Stringer("query_id", q.ID),
+		zap.Stringer("org_id", q.OrgID),
+		zap.String("source", q.Source),
+		zap.String("text", q.Text),
+		zap.Bool("truncated", q.Truncated),
+		zap.Time("started_at", q.StartedAt),
+		zap.Duration("total_duration", q.Statistics.TotalDuration),
+		zap.Duration("queue_duration", q.Statistics.QueueDuration),
+		zap.Duration("compile_duration", q.Statistics.CompileDuration),
+		zap.Duration("plan_duration", q.Statistics.PlanDuration),
+		zap.Duration("execute_duration", q.Statistics.ExecuteDuration),
+		zap.Int64("max_allocated", q.Statistics.MaxAllocated),
+		zap.Int64("scanned_bytes", q.ScannedBytes),
+		zap.Int64("scanned_values", q.ScannedValues),
+	}
+	if q.AuthorizationID.Valid() {
+		fields = append(fields, zap.Stringer("authorization_id", q.AuthorizationID))
+	}
+	if q.Err != nil {
+		fields = append(fields, zap.Error(q.Err))
+	}
+	l.logger.Warn("Slow query", fields...)
+}
+
+// PointsWriter writes points to storage. It is a copy of storage.PointsWriter,
+// so that the controller does not depend on storage.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// slowQueryMeasurement is the measurement of the points of the slow queries.
+const slowQueryMeasurement = "slow_queries"
+
+// SlowQueryWriter records the slow queries as points of the slow queries system bucket of their organization,
+// platform.BucketTypeSlowQueries, where they can be queried with Flux.
+// The points are written in the background, and dropped if the writer falls behind.
+type SlowQueryWriter struct {
+	pointsWriter PointsWriter
+	logger       *zap.Logger
+
+	mu      sync.Mutex
+	closed  bool
+	queries chan *SlowQuery
+	done    chan struct{}
+}
+
+// slowQueryWriterBuffer is the number of slow queries waiting to be written at most.
+const slowQueryWriterBuffer = 100
+
+// NewSlowQueryWriter returns a SlowQueryWriter writing the slow queries with pw, which must be closed once done.
+func NewSlowQueryWriter(pw PointsWriter, logger *zap.Logger) *SlowQueryWriter {
+	w := &SlowQueryWriter{
+		pointsWriter: pw,
+		logger:       logger,
+		queries:      make(chan *SlowQuery, slowQueryWriterBuffer),
+		done:         make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// RecordSlowQuery queues q to be written, or drops it if too many slow queries are waiting already.
+func (w *SlowQueryWriter) RecordSlowQuery(q *SlowQuery) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.queries <- q:
+	default:
+		w.logger.Info("Dropped slow query, too many waiting to be written", zap.Stringer("query_id", q.ID), zap.Stringer("org_id", q.OrgID))
+	}
+}
+
+// Close writes the slow queries waiting to be written, and stops the writer.
+func (w *SlowQueryWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queries)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+func (w *SlowQueryWriter) run() {
+	defer close(w.done)
+	for q := range w.queries {
+		if err := w.write(q); err != nil {
+			w.logger.Info("Failed to write slow query", zap.Stringer("query_id", q.ID), zap.Stringer("org_id", q.OrgID), zap.Error(err))
+		}
+	}
+}
+
+func (w *SlowQueryWriter) write(q *SlowQuery) error {
+	var tags models.Tags
+	if q.Source != "" {
+		tags = models.NewTags(map[string]string{"source": q.Source})
+	}
+	fields := map[string]interface{}{
+		"queryID":         q.ID.String(),
+		"text":            q.Text,
+		"truncated":       q.Truncated,
+		"startedAt":       q.StartedAt.Format(time.RFC3339Nano),
+		"totalDuration":   int64(q.Statistics.TotalDuration),
+		"queueDuration":   int64(q.Statistics.QueueDuration),
+		"compileDuration": int64(q.Statistics.CompileDuration),
+		"planDuration":    int64(q.Statistics.PlanDuration),
+		"executeDuration": int64(q.Statistics.ExecuteDuration),
+		"maxAllocated":    q.Statistics.MaxAllocated,
+		"scannedBytes":    q.ScannedBytes,
+		"scannedValues":   q.ScannedValues,
+	}
+	if q.AuthorizationID.Valid() {
+		fields["authorizationID"] = q.AuthorizationID.String()
+	}
+	if q.Err != nil {
+		fields["error"] = q.Err.Error()
+	}
+
+	pt, err := models.NewPoint(slowQueryMeasurement, tags, fields, q.FinishedAt)
+	if err != nil {
+		return err
+	}
+
+	bucketID := platform.ID(platform.BucketTypeSlowQueries)
+	exploded, err := tsdb.ExplodePoints(q.OrgID, bucketID, []models.Point{pt})
+	if err != nil {
+		return err
+	}
+	return w.pointsWriter.WritePoints(context.Background(), exploded)
+}
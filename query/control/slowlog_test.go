@@ -0,0 +1,162 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type slowQueryLog struct {
+	mu      sync.Mutex
+	queries []*SlowQuery
+}
+
+func (l *slowQueryLog) RecordSlowQuery(q *SlowQuery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = append(l.queries, q)
+}
+
+func (l *slowQueryLog) Queries() []*SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*SlowQuery(nil), l.queries...)
+}
+
+func TestController_SlowQueryLog(t *testing.T) {
+	c := New(control.Config{ConcurrencyQuota: 10, MemoryBytesQuota: 1 << 20})
+	defer c.Shutdown(context.Background())
+
+	run := func(cfg SlowQueryConfig, script string) []*SlowQuery {
+		t.Helper()
+		log := &slowQueryLog{}
+		c.WithSlowQueryLog(cfg, log)
+
+		q, err := c.Query(context.Background(), &query.Request{
+			Authorization:  &platform.Authorization{ID: 3},
+			OrganizationID: 1,
+			Tag:            "dashboard",
+			Compiler:       lang.FluxCompiler{Query: script},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for results := range q.Ready() {
+			for _, r := range results {
+				r.Tables().Do(func(flux.Table) error { return nil })
+			}
+		}
+		q.Done()
+		return log.Queries()
+	}
+
+	// Every query lasts a nanosecond.
+	qs := run(SlowQueryConfig{Duration: time.Nanosecond}, testScript)
+	if len(qs) != 1 {
+		t.Fatalf("got %d slow queries, want 1", len(qs))
+	}
+	if q := qs[0]; q.OrgID != 1 || q.AuthorizationID != 3 || q.Source != "dashboard" || q.Text != testScript || q.Truncated || q.Statistics.TotalDuration <= 0 {
+		t.Errorf("unexpected slow query %+v", q)
+	}
+
+	// The text of slow queries is truncated.
+	qs = run(SlowQueryConfig{Duration: time.Nanosecond, MaxTextLength: 10}, testScript)
+	if len(qs) != 1 || qs[0].Text != testScript[:10] || !qs[0].Truncated {
+		t.Errorf("got slow queries %+v, want the query with its text truncated", qs)
+	}
+
+	// Queries under the thresholds are not recorded.
+	if qs := run(SlowQueryConfig{Duration: time.Hour, ScannedBytes: 1 << 30}, testScript); len(qs) != 0 {
+		t.Errorf("got slow queries %+v, want none", qs)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, tt := range []struct {
+		s, want   string
+		n         int
+		truncated bool
+	}{
+		{s: "from()", n: 10, want: "from()"},
+		{s: "from()", n: 4, want: "from", truncated: true},
+		// The 3 bytes of "é…" are not split.
+		{s: "aé…", n: 4, want: "aé", truncated: true},
+	} {
+		got, truncated := truncateText(tt.s, tt.n)
+		if got != tt.want || truncated != tt.truncated {
+			t.Errorf("truncateText(%q, %d) = %q, %v, want %q, %v", tt.s, tt.n, got, truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+type pointsWriter struct {
+	mu     sync.Mutex
+	points []models.Point
+}
+
+func (w *pointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.points = append(w.points, points...)
+	return nil
+}
+
+func TestSlowQueryWriter(t *testing.T) {
+	pw := &pointsWriter{}
+	w := NewSlowQueryWriter(pw, zaptest.NewLogger(t))
+
+	finishedAt := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	w.RecordSlowQuery(&SlowQuery{
+		ID:           1,
+		OrgID:        2,
+		Source:       "dashboard",
+		Text:         testScript,
+		FinishedAt:   finishedAt,
+		Statistics:   flux.Statistics{TotalDuration: 3 * time.Second},
+		ScannedBytes: 1 << 20,
+	})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Slow queries recorded once closed are dropped.
+	w.RecordSlowQuery(&SlowQuery{ID: 2, OrgID: 2})
+
+	// The points are exploded into a point per field.
+	fields := make(map[string]interface{})
+	for _, pt := range pw.points {
+		var name [16]byte
+		copy(name[:], pt.Name())
+		if orgID, bucketID := tsdb.DecodeName(name); orgID != 2 || bucketID != platform.ID(platform.BucketTypeSlowQueries) {
+			t.Errorf("got point of org %s and bucket %s, want the slow queries bucket of org 2", orgID, bucketID)
+		}
+		if !pt.Time().Equal(finishedAt) {
+			t.Errorf("got point at %s, want %s", pt.Time(), finishedAt)
+		}
+		if m, src := string(pt.Tags().Get(models.MeasurementTagKeyBytes)), string(pt.Tags().Get([]byte("source"))); m != "slow_queries" || src != "dashboard" {
+			t.Errorf("got point of measurement %q and source %q, want slow_queries and dashboard", m, src)
+		}
+		fs, err := pt.Fields()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range fs {
+			fields[k] = v
+		}
+	}
+	if fields["text"] != testScript || fields["totalDuration"] != int64(3*time.Second) || fields["scannedBytes"] != int64(1<<20) || fields["queryID"] != platform.ID(1).String() {
+		t.Errorf("unexpected fields %v", fields)
+	}
+	if _, ok := fields["error"]; ok {
+		t.Errorf("got an error field for a query that did not fail")
+	}
+}
@@ -0,0 +1,287 @@
+// Package explain plans Flux queries without running them, to tell the physical plan of a query,
+// the work pushed down to the storage engine and the shards and series its reads touch.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/universe"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxql"
+)
+
+// Plan is the explanation of a query: the nodes of its physical plan, the reads of the storage
+// engine it does, and an estimate of what they touch.
+type Plan struct {
+	Nodes []*Node `json:"nodes"`
+	Reads []*Read `json:"reads"`
+
+	// Shards, Series and Bytes are the totals of the reads.
+	Shards int   `json:"shards"`
+	Series int   `json:"series"`
+	Bytes  int64 `json:"bytes"`
+
+	// Warnings tell the parts of the query that are not pushed down to the storage engine.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Node is a node of a physical plan.
+type Node struct {
+	ID           string   `json:"id"`
+	Kind         string   `json:"kind"`
+	Predecessors []string `json:"predecessors,omitempty"`
+	Start        string   `json:"start,omitempty"`
+	Stop         string   `json:"stop,omitempty"`
+}
+
+// Read is a read of a bucket by the storage engine, with the operations pushed down to it.
+type Read struct {
+	Node     string      `json:"node"`
+	BucketID platform.ID `json:"bucketID"`
+	Bucket   string      `json:"bucket"`
+	Start    time.Time   `json:"start"`
+	Stop     time.Time   `json:"stop"`
+
+	// Pushdowns are the operations the storage engine does for the read, such as "range" or "filter".
+	Pushdowns []string `json:"pushdowns"`
+	// Predicate is the predicate of a pushed down filter.
+	Predicate string `json:"predicate,omitempty"`
+	// Window is the window of a pushed down aggregate.
+	Window *Window `json:"window,omitempty"`
+	// Aggregate is the method of a pushed down aggregate.
+	Aggregate string `json:"aggregate,omitempty"`
+	// GroupKeys are the keys of a pushed down group.
+	GroupKeys []string `json:"groupKeys,omitempty"`
+
+	// Shards are the IDs of the shards with points of the bucket in the range of the read,
+	// and Bytes the size of the blocks of the bucket in them.
+	Shards []string `json:"shards"`
+	Bytes  int64    `json:"bytes"`
+	// Series is the number of series keys of the bucket matching the predicate, one for every field
+	// of a series, or -1 if it is unknown.
+	Series int `json:"series"`
+}
+
+// Window is the window of a read.
+type Window struct {
+	Every  flux.Duration `json:"every"`
+	Period flux.Duration `json:"period"`
+	Offset flux.Duration `json:"offset"`
+}
+
+// Service explains the queries of an organization. Its services are expected to be authorized,
+// so that the buckets and shards of a plan are the ones the caller may read.
+type Service struct {
+	BucketService platform.BucketService
+	ShardService  platform.ShardService
+
+	// SeriesCounter, if not nil, counts the series of the reads.
+	SeriesCounter storage.SeriesCounter
+
+	Now func() time.Time
+}
+
+// NewService returns a Service explaining queries with the buckets, shards and series of the services.
+func NewService(bs platform.BucketService, ss platform.ShardService, sc storage.SeriesCounter) *Service {
+	return &Service{
+		BucketService: bs,
+		ShardService:  ss,
+		SeriesCounter: sc,
+		Now:           time.Now,
+	}
+}
+
+// Explain plans the Flux script of a query of the organization orgID and returns its explanation.
+func (s *Service) Explain(ctx context.Context, orgID platform.ID, script string) (*Plan, error) {
+	now := s.Now()
+	spec, err := flux.Compile(ctx, script, now)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to compile query",
+			Err:  err,
+		}
+	}
+
+	lp, err := plan.NewLogicalPlanner().CreateInitialPlan(spec)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to plan query",
+			Err:  err,
+		}
+	}
+	if lp, err = plan.NewLogicalPlanner().Plan(lp); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to plan query",
+			Err:  err,
+		}
+	}
+	pp, err := plan.NewPhysicalPlanner().Plan(lp)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to plan query",
+			Err:  err,
+		}
+	}
+
+	p := &Plan{Nodes: []*Node{}, Reads: []*Read{}}
+	err = pp.TopDownWalk(func(pn plan.PlanNode) error {
+		n := &Node{
+			ID:   string(pn.ID()),
+			Kind: string(pn.Kind()),
+		}
+		for _, pred := range pn.Predecessors() {
+			n.Predecessors = append(n.Predecessors, string(pred.ID()))
+		}
+		if b := pn.Bounds(); b != nil {
+			n.Start = b.Start.Time().UTC().Format(time.RFC3339Nano)
+			n.Stop = b.Stop.Time().UTC().Format(time.RFC3339Nano)
+		}
+		p.Nodes = append(p.Nodes, n)
+
+		switch spec := pn.ProcedureSpec().(type) {
+		case *influxdb.PhysicalFromProcedureSpec:
+			r, err := s.explainRead(ctx, orgID, pn.ID(), spec)
+			if err != nil {
+				return err
+			}
+			p.Reads = append(p.Reads, r)
+			p.Shards += len(r.Shards)
+			p.Bytes += r.Bytes
+			if r.Series > 0 {
+				p.Series += r.Series
+			}
+		case *universe.FilterProcedureSpec:
+			p.Warnings = append(p.Warnings, fmt.Sprintf("%s: the filter is not pushed down to the storage engine, so it is applied to every point read", pn.ID()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The reads are listed from the sources, in the order the plan runs them.
+	for i, j := 0, len(p.Reads)-1; i < j; i, j = i+1, j-1 {
+		p.Reads[i], p.Reads[j] = p.Reads[j], p.Reads[i]
+	}
+	return p, nil
+}
+
+// explainRead returns the read of the bucket of spec, with the shards and series it touches.
+func (s *Service) explainRead(ctx context.Context, orgID platform.ID, id plan.NodeID, spec *influxdb.PhysicalFromProcedureSpec) (*Read, error) {
+	b, err := s.findBucket(ctx, orgID, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Read{
+		Node:      string(id),
+		BucketID:  b.ID,
+		Bucket:    b.Name,
+		Start:     spec.Bounds.Start.Time(spec.Bounds.Now).UTC(),
+		Stop:      spec.Bounds.Stop.Time(spec.Bounds.Now).UTC(),
+		Pushdowns: []string{"range"},
+		Shards:    []string{},
+		Series:    -1,
+	}
+
+	var cond influxql.Expr
+	if spec.FilterSet {
+		r.Pushdowns = append(r.Pushdowns, "filter")
+		pred, err := reads.ToStoragePredicate(spec.Filter)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid filter",
+				Err:  err,
+			}
+		}
+		r.Predicate = reads.PredicateToExprString(pred)
+		if cond, err = reads.NodeToExpr(pred.Root, nil); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid filter",
+				Err:  err,
+			}
+		}
+		// The series are matched by their tags, as the storage engine does before it reads the
+		// values of the fields.
+		cond = influxql.Reduce(reads.RewriteExprRemoveFieldValue(cond), nil)
+		if reads.IsTrueBooleanLiteral(cond) {
+			cond = nil
+		}
+	}
+	if spec.GroupingSet {
+		r.Pushdowns = append(r.Pushdowns, "group")
+		r.GroupKeys = spec.GroupKeys
+	}
+	if spec.LimitSet {
+		if spec.PointsLimit == -1 {
+			r.Pushdowns = append(r.Pushdowns, "keys")
+		} else {
+			r.Pushdowns = append(r.Pushdowns, "limit")
+		}
+	}
+	if spec.WindowSet {
+		r.Pushdowns = append(r.Pushdowns, "window")
+		r.Window = &Window{
+			Every:  spec.Window.Every,
+			Period: spec.Window.Period,
+			Offset: spec.Window.Offset,
+		}
+	}
+	if spec.AggregateSet {
+		r.Pushdowns = append(r.Pushdowns, "aggregate")
+		r.Aggregate = spec.AggregateMethod
+	}
+	if spec.DescendingSet && spec.Descending {
+		r.Pushdowns = append(r.Pushdowns, "descending")
+	}
+
+	shards, err := s.ShardService.FindShards(ctx, platform.ShardFilter{OrgID: orgID, BucketID: b.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, sh := range shards {
+		if sh.MaxTime.Before(r.Start) || !sh.MinTime.Before(r.Stop) {
+			continue
+		}
+		r.Shards = append(r.Shards, sh.ID)
+		r.Bytes += sh.BucketSize
+	}
+
+	if s.SeriesCounter != nil {
+		if r.Series, err = s.SeriesCounter.CountSeries(ctx, orgID, b.ID, cond); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findBucket(ctx context.Context, orgID platform.ID, spec *influxdb.PhysicalFromProcedureSpec) (*platform.Bucket, error) {
+	if spec.BucketID != "" {
+		id, err := platform.IDFromString(spec.BucketID)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid bucket ID",
+				Err:  err,
+			}
+		}
+		return s.BucketService.FindBucketByID(ctx, *id)
+	}
+	return s.BucketService.FindBucket(ctx, platform.BucketFilter{
+		Name:           &spec.Bucket,
+		OrganizationID: &orgID,
+	})
+}
@@ -0,0 +1,131 @@
+package explain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/explain"
+	"github.com/influxdata/influxql"
+)
+
+type seriesCounter struct {
+	cond influxql.Expr
+}
+
+func (c *seriesCounter) CountSeries(ctx context.Context, orgID, bucketID platform.ID, cond influxql.Expr) (int, error) {
+	c.cond = cond
+	return 12, nil
+}
+
+func newService(t *testing.T, sc *seriesCounter) *explain.Service {
+	t.Helper()
+	bs := mock.NewBucketService()
+	bs.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		if *filter.Name != "b" || *filter.OrganizationID != 1 {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+		}
+		return &platform.Bucket{ID: 2, OrganizationID: 1, Name: "b"}, nil
+	}
+	ss := mock.NewShardService()
+	ss.FindShardsFn = func(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+		day := func(d int) time.Time { return time.Date(2019, 1, d, 0, 0, 0, 0, time.UTC) }
+		return []*platform.Shard{
+			{ID: "000000001-000000001", BucketSize: 100, MinTime: day(1), MaxTime: day(2)},
+			{ID: "000000002-000000001", BucketSize: 200, MinTime: day(2), MaxTime: day(3)},
+			{ID: "000000003-000000001", BucketSize: 400, MinTime: day(4), MaxTime: day(5)},
+		}, nil
+	}
+	s := explain.NewService(bs, ss, sc)
+	s.Now = func() time.Time { return time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestService_Explain(t *testing.T) {
+	sc := &seriesCounter{}
+	s := newService(t, sc)
+
+	p, err := s.Explain(context.Background(), 1, `from(bucket: "b")
+	|> range(start: 2019-01-01T12:00:00Z, stop: 2019-01-02T12:00:00Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r.host == "a")
+	|> group(columns: ["host"])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Reads) != 1 {
+		t.Fatalf("unexpected number of reads %d", len(p.Reads))
+	}
+	r := p.Reads[0]
+	if r.Bucket != "b" || r.BucketID != 2 {
+		t.Errorf("unexpected bucket %q %v", r.Bucket, r.BucketID)
+	}
+	if !r.Start.Equal(time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)) || !r.Stop.Equal(time.Date(2019, 1, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range [%v, %v)", r.Start, r.Stop)
+	}
+	if !hasPushdown(r, "range") || !hasPushdown(r, "filter") {
+		t.Errorf("unexpected pushdowns %v", r.Pushdowns)
+	}
+	if r.Predicate == "" || sc.cond == nil {
+		t.Errorf("expected the predicate of the filter, got %q and condition %v", r.Predicate, sc.cond)
+	}
+	if len(r.Shards) != 2 || r.Bytes != 300 {
+		t.Errorf("unexpected shards %v of %d bytes", r.Shards, r.Bytes)
+	}
+	if r.Series != 12 || p.Series != 12 || p.Shards != 2 || p.Bytes != 300 {
+		t.Errorf("unexpected totals: %d series, %d shards, %d bytes", p.Series, p.Shards, p.Bytes)
+	}
+	if len(p.Nodes) == 0 {
+		t.Errorf("expected the nodes of the plan")
+	}
+}
+
+func TestService_Explain_FieldValueFilter(t *testing.T) {
+	sc := &seriesCounter{}
+	s := newService(t, sc)
+
+	p, err := s.Explain(context.Background(), 1, `from(bucket: "b")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._value > 1.0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Reads) != 1 {
+		t.Fatalf("unexpected number of reads %d", len(p.Reads))
+	}
+	// The series of a filter of the values of the fields are all the series of the bucket.
+	if sc.cond != nil {
+		t.Errorf("unexpected condition %v", sc.cond)
+	}
+	if len(p.Reads[0].Shards) != 0 {
+		t.Errorf("unexpected shards %v", p.Reads[0].Shards)
+	}
+}
+
+func TestService_Explain_Invalid(t *testing.T) {
+	s := newService(t, &seriesCounter{})
+	for _, script := range []string{
+		`from(bucket: "b"`,
+		`from(bucket: "b")`,
+	} {
+		if _, err := s.Explain(context.Background(), 1, script); platform.ErrorCode(err) != platform.EInvalid {
+			t.Errorf("%s: expected an invalid error, got %v", script, err)
+		}
+	}
+
+	if _, err := s.Explain(context.Background(), 1, `from(bucket: "other") |> range(start: -1h)`); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func hasPushdown(r *explain.Read, name string) bool {
+	for _, p := range r.Pushdowns {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
@@ -25,6 +25,9 @@ type Compiler struct {
 	Query   string     `json:"query"`
 	Now     *time.Time `json:"now,omitempty"`
 
+	// OrganizationID, if valid, is the organization running the query, whose buckets only are queried.
+	OrganizationID platform.ID `json:"organization_id,omitempty"`
+
 	dbrpMappingSvc platform.DBRPMappingService
 }
 
@@ -49,6 +52,7 @@ func (c *Compiler) Compile(ctx context.Context) (*flux.Spec, error) {
 			DefaultDatabase:        c.DB,
 			DefaultRetentionPolicy: c.RP,
 			Now:                    now,
+			OrganizationID:         c.OrganizationID,
 		},
 	)
 	astPkg, err := transpiler.Transpile(ctx, c.Query)
@@ -2,6 +2,8 @@ package influxql
 
 import (
 	"time"
+
+	platform "github.com/influxdata/influxdb"
 )
 
 // Config modifies the behavior of the Transpiler.
@@ -10,4 +12,7 @@ type Config struct {
 	DefaultRetentionPolicy string
 	Now                    time.Time
 	Cluster                string
+
+	// OrganizationID, if valid, restricts the databases and retention policies to those mapped to its buckets.
+	OrganizationID platform.ID
 }
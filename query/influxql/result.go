@@ -13,7 +13,12 @@ import (
 )
 
 // MultiResultEncoder encodes results as InfluxQL JSON format.
-type MultiResultEncoder struct{}
+type MultiResultEncoder struct {
+	// TimeFormat is the format of the timestamps; they are formatted as RFC3339Nano by default.
+	TimeFormat TimeFormat
+	// Pretty indents the JSON of the results.
+	Pretty bool
+}
 
 // Encode writes a collection of results to the influxdb 1.X http response format.
 // Expectations/Assumptions:
@@ -154,7 +159,7 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 						vs := cr.Times(idx)
 						for i := 0; i < vs.Len(); i++ {
 							if vs.IsValid(i) {
-								values[i][j] = execute.Time(vs.Value(i)).Time().Format(time.RFC3339Nano)
+								values[i][j] = e.formatTime(execute.Time(vs.Value(i)))
 							}
 						}
 					default:
@@ -182,9 +187,37 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 		resp.error(err)
 	}
 
-	err := json.NewEncoder(wc).Encode(resp)
+	enc := json.NewEncoder(wc)
+	if e.Pretty {
+		enc.SetIndent("", "    ")
+	}
+	err := enc.Encode(resp)
 	return wc.Count(), err
 }
+
+// formatTime formats t as a string in RFC3339Nano, or as a number of units of the epoch
+// in the other time formats, like the epoch parameter of the queries of InfluxDB 1.x.
+func (e *MultiResultEncoder) formatTime(t execute.Time) interface{} {
+	var unit time.Duration
+	switch e.TimeFormat {
+	case Hour:
+		unit = time.Hour
+	case Minute:
+		unit = time.Minute
+	case Second:
+		unit = time.Second
+	case Millisecond:
+		unit = time.Millisecond
+	case Microsecond:
+		unit = time.Microsecond
+	case Nanosecond:
+		unit = time.Nanosecond
+	default:
+		return t.Time().Format(time.RFC3339Nano)
+	}
+	return int64(t) / int64(unit)
+}
+
 func NewMultiResultEncoder() *MultiResultEncoder {
 	return new(MultiResultEncoder)
 }
@@ -16,6 +16,7 @@ import (
 func TestMultiResultEncoder_Encode(t *testing.T) {
 	for _, tt := range []struct {
 		name string
+		enc  *influxql.MultiResultEncoder
 		in   flux.ResultIterator
 		out  string
 	}{
@@ -40,6 +41,27 @@ func TestMultiResultEncoder_Encode(t *testing.T) {
 			),
 			out: `{"results":[{"statement_id":0,"series":[{"name":"m0","tags":{"host":"server01"},"columns":["time","value"],"values":[["2018-05-24T09:00:00Z",2]]}]}]}`,
 		},
+		{
+			name: "Epoch",
+			enc:  &influxql.MultiResultEncoder{TimeFormat: influxql.Second},
+			in: flux.NewSliceResultIterator(
+				[]flux.Result{&executetest.Result{
+					Nm: "0",
+					Tbls: []*executetest.Table{{
+						KeyCols: []string{"_measurement"},
+						ColMeta: []flux.ColMeta{
+							{Label: "_time", Type: flux.TTime},
+							{Label: "_measurement", Type: flux.TString},
+							{Label: "value", Type: flux.TFloat},
+						},
+						Data: [][]interface{}{
+							{ts("2018-05-24T09:00:00Z"), "m0", float64(2)},
+						},
+					}},
+				}},
+			),
+			out: `{"results":[{"statement_id":0,"series":[{"name":"m0","columns":["time","value"],"values":[[1527152400,2]]}]}]}`,
+		},
 		{
 			name: "No _time column",
 			in: flux.NewSliceResultIterator(
@@ -90,7 +112,10 @@ func TestMultiResultEncoder_Encode(t *testing.T) {
 			tt.out += "\n"
 
 			var buf bytes.Buffer
-			enc := influxql.NewMultiResultEncoder()
+			enc := tt.enc
+			if enc == nil {
+				enc = influxql.NewMultiResultEncoder()
+			}
 			n, err := enc.Encode(&buf, tt.in)
 			if err != nil {
 				t.Fatalf("unexpected error: %s", err)
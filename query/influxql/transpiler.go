@@ -665,6 +665,9 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 	}
 	defaultRP := rp == ""
 	filter.Default = &defaultRP
+	if t.config.OrganizationID.Valid() {
+		filter.OrganizationID = &t.config.OrganizationID
+	}
 	mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
 	if err != nil {
 		return nil, err
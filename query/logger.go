@@ -18,6 +18,8 @@ type Log struct {
 	Time time.Time
 	// OrganizationID is the ID of the organization that requested the query
 	OrganizationID platform.ID
+	// Tag identifies the application, or the dashboard cell, that ran the query
+	Tag string
 	// Error is any error encountered by the query
 	Error error
 
@@ -30,6 +30,7 @@ func (s *LoggingServiceBridge) Query(ctx context.Context, w io.Writer, req *Prox
 		}
 		log := Log{
 			OrganizationID: req.Request.OrganizationID,
+			Tag:            req.Request.Tag,
 			ProxyRequest:   req,
 			ResponseSize:   n,
 			Time:           time.Now(),
@@ -15,6 +15,9 @@ type Request struct {
 	Authorization  *platform.Authorization `json:"authorization,omitempty"`
 	OrganizationID platform.ID             `json:"organization_id"`
 
+	// Tag identifies the application, or the dashboard cell, running the query, to attribute its load.
+	Tag string `json:"tag,omitempty"`
+
 	// Command
 
 	// Compiler converts the query to a specification to run against the data.
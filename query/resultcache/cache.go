@@ -0,0 +1,381 @@
+// Package resultcache caches the encoded results of the Flux queries over unchanged data,
+// so that repeated queries, such as those of the cells of dashboards refreshing, return
+// without running again.
+//
+// The results of a query are cached under a key of its organization, its dialect, its
+// normalized text, the time bounds of its ranges and the write generations of the buckets
+// it reads. A write to or a delete from a bucket changes its generation, and so the key of
+// every query reading it: the results cached before are never returned again, and are
+// eventually evicted.
+package resultcache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/stdlib/universe"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// DefaultResolution is the resolution of the time bounds of queries, unless configured otherwise.
+const DefaultResolution = 10 * time.Second
+
+// Config configures a Cache.
+type Config struct {
+	// MaxBytes is the total size of the results cached. The least recently used results
+	// are evicted over it.
+	MaxBytes int64
+
+	// MaxResultBytes is the size of the largest result cached. It defaults to a tenth of MaxBytes.
+	MaxResultBytes int64
+
+	// Resolution is the precision of the time bounds relative to now of the queries, such as
+	// those of range(start: -1h). Now is truncated to it, so that the same query run again
+	// within it returns the same results, as old as Resolution at most.
+	// It defaults to DefaultResolution.
+	Resolution time.Duration
+}
+
+// BucketGenerations returns the write generations of buckets, such as storage.Engine.
+type BucketGenerations interface {
+	// BucketGeneration returns the generation of the data of a bucket, which changes
+	// every time points are written to or deleted from the bucket.
+	BucketGeneration(orgID, bucketID platform.ID) uint64
+}
+
+// Cache caches the results of the queries of the ProxyQueryService it wraps.
+type Cache struct {
+	config      Config
+	generations BucketGenerations
+	buckets     platform.BucketService
+	logger      *zap.Logger
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int64
+
+	metrics *metrics
+}
+
+// entry is the result of a query cached.
+type entry struct {
+	key    string
+	result []byte
+}
+
+// New returns a Cache of the results of the queries, which finds the buckets read by the queries
+// with buckets and their generations with generations.
+func New(config Config, generations BucketGenerations, buckets platform.BucketService, logger *zap.Logger) *Cache {
+	if config.MaxResultBytes <= 0 {
+		config.MaxResultBytes = config.MaxBytes / 10
+	}
+	if config.Resolution <= 0 {
+		config.Resolution = DefaultResolution
+	}
+
+	return &Cache{
+		config:      config,
+		generations: generations,
+		buckets:     buckets,
+		logger:      logger,
+		Now:         time.Now,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		metrics:     newMetrics(),
+	}
+}
+
+type bypassKey struct{}
+
+// WithBypass returns a context whose queries bypass the cache: they are run, and their results are not cached.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+// ProxyQueryService returns a ProxyQueryService running the queries with s,
+// unless their results are cached.
+func (c *Cache) ProxyQueryService(s query.ProxyQueryService) query.ProxyQueryService {
+	return &proxyQueryService{cache: c, ProxyQueryService: s}
+}
+
+type proxyQueryService struct {
+	cache *Cache
+	query.ProxyQueryService
+}
+
+// Query writes the cached results of the query to w, or runs it and caches its results if they are not.
+func (s *proxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	c := s.cache
+	if bypassed(ctx) {
+		c.metrics.requests.WithLabelValues("bypass").Inc()
+		return s.ProxyQueryService.Query(ctx, w, req)
+	}
+
+	k, err := c.key(ctx, req)
+	if err != nil {
+		c.logger.Info("Failed to key query, it is not cached", zap.String("org_id", req.Request.OrganizationID.String()), zap.Error(err))
+	}
+	if k == nil {
+		c.metrics.requests.WithLabelValues("uncacheable").Inc()
+		return s.ProxyQueryService.Query(ctx, w, req)
+	}
+
+	if result, ok := c.get(k.key); ok {
+		c.metrics.requests.WithLabelValues("hit").Inc()
+		_, err := w.Write(result)
+		return flux.Statistics{}, err
+	}
+	c.metrics.requests.WithLabelValues("miss").Inc()
+
+	buf := &limitedBuffer{max: c.config.MaxResultBytes}
+	stats, err := s.ProxyQueryService.Query(ctx, io.MultiWriter(w, buf), req)
+	// The results are only cached if the data did not change while the query ran,
+	// as they may hold part of the change.
+	if err == nil && !buf.overflow && c.unchanged(k) {
+		c.put(k.key, buf.Bytes())
+	}
+	return stats, err
+}
+
+// Check checks the ProxyQueryService the cache wraps.
+func (s *proxyQueryService) Check(ctx context.Context) check.Response {
+	return s.ProxyQueryService.Check(ctx)
+}
+
+// limitedBuffer buffers the bytes written to it, until they exceed its max.
+// It never fails, not to fail the writes to the writers it is written with.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int64
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if int64(b.Len()+len(p)) > b.max {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// queryKey is the key of the results of a query, with the generations of the buckets it reads.
+type queryKey struct {
+	key     string
+	buckets []bucketGeneration
+}
+
+type bucketGeneration struct {
+	orgID, bucketID platform.ID
+	generation      uint64
+}
+
+// key returns the key of the results of the query req, or nil if they cannot be cached:
+// if it is not a Flux query, if it writes, if it reads from another source than buckets
+// or if its authorization is not allowed to read every bucket it reads.
+func (c *Cache) key(ctx context.Context, req *query.ProxyRequest) (*queryKey, error) {
+	var text string
+	switch compiler := req.Request.Compiler.(type) {
+	case lang.FluxCompiler:
+		text = compiler.Query
+	case *lang.FluxCompiler:
+		text = compiler.Query
+	default:
+		return nil, nil
+	}
+	auth := req.Request.Authorization
+	if auth == nil || req.Dialect == nil {
+		return nil, nil
+	}
+
+	pkg := parser.ParseSource(text)
+	if ast.Check(pkg) > 0 {
+		return nil, nil
+	}
+	now := c.Now().Truncate(c.config.Resolution)
+	spec := compile(ctx, text, now)
+	if spec == nil || !readsBucketsOnly(spec) {
+		return nil, nil
+	}
+
+	orgID := req.Request.OrganizationID
+	read, written, err := query.BucketsAccessed(spec, &orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(written) > 0 || len(read) == 0 {
+		return nil, nil
+	}
+
+	k := &queryKey{}
+	for _, filter := range read {
+		b, err := c.buckets.FindBucket(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		perm, err := platform.NewPermissionAtID(b.ID, platform.ReadAction, platform.BucketsResourceType, b.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if !auth.Allowed(*perm) {
+			return nil, nil
+		}
+		k.buckets = append(k.buckets, bucketGeneration{
+			orgID:      b.OrganizationID,
+			bucketID:   b.ID,
+			generation: c.generations.BucketGeneration(b.OrganizationID, b.ID),
+		})
+	}
+	sort.Slice(k.buckets, func(i, j int) bool { return k.buckets[i].bucketID < k.buckets[j].bucketID })
+
+	dialect, err := json.Marshal(req.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	var buf [8]byte
+	putInt := func(v uint64) {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	putBytes := func(b []byte) {
+		putInt(uint64(len(b)))
+		h.Write(b)
+	}
+	putInt(uint64(orgID))
+	putBytes([]byte(req.Dialect.DialectType()))
+	putBytes(dialect)
+	putBytes([]byte(ast.Format(pkg)))
+	for _, b := range rangeBounds(spec, now) {
+		putInt(uint64(b))
+	}
+	for _, b := range k.buckets {
+		putInt(uint64(b.bucketID))
+		putInt(b.generation)
+	}
+	k.key = hex.EncodeToString(h.Sum(nil))
+	return k, nil
+}
+
+// compile compiles the query text, or returns nil if it fails, the same way it fails when it runs.
+// Some invalid queries panic flux, the query is let through to report it.
+func compile(ctx context.Context, text string, now time.Time) (spec *flux.Spec) {
+	defer func() {
+		if r := recover(); r != nil {
+			spec = nil
+		}
+	}()
+
+	spec, err := flux.Compile(ctx, text, now)
+	if err != nil {
+		return nil
+	}
+	return spec
+}
+
+// readsBucketsOnly returns true if every source of spec reads a bucket.
+func readsBucketsOnly(spec *flux.Spec) bool {
+	children := make(map[flux.OperationID]bool)
+	for _, e := range spec.Edges {
+		children[e.Child] = true
+	}
+	for _, o := range spec.Operations {
+		if !children[o.ID] && o.Spec.Kind() != influxdb.FromKind {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeBounds returns the start and stop of every range of spec, relative to now.
+func rangeBounds(spec *flux.Spec, now time.Time) []int64 {
+	var bounds []int64
+	spec.Walk(func(o *flux.Operation) error {
+		if r, ok := o.Spec.(*universe.RangeOpSpec); ok {
+			bounds = append(bounds, r.Start.Time(now).UnixNano(), r.Stop.Time(now).UnixNano())
+		}
+		return nil
+	})
+	return bounds
+}
+
+// unchanged returns true if the generations of the buckets of k did not change.
+func (c *Cache) unchanged(k *queryKey) bool {
+	for _, b := range k.buckets {
+		if c.generations.BucketGeneration(b.orgID, b.bucketID) != b.generation {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*entry).result, true
+}
+
+func (c *Cache) put(key string, result []byte) {
+	if int64(len(result)) > c.config.MaxResultBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.size -= int64(len(e.Value.(*entry).result))
+		e.Value.(*entry).result = result
+		c.lru.MoveToFront(e)
+	} else {
+		c.entries[key] = c.lru.PushFront(&entry{key: key, result: result})
+	}
+	c.size += int64(len(result))
+
+	for c.size > c.config.MaxBytes {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		evicted := e.Value.(*entry)
+		delete(c.entries, evicted.key)
+		c.size -= int64(len(evicted.result))
+		c.metrics.evictions.Inc()
+	}
+	c.metrics.entries.Set(float64(len(c.entries)))
+	c.metrics.size.Set(float64(c.size))
+}
@@ -0,0 +1,199 @@
+package resultcache_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	pmock "github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/query/resultcache"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    = platform.ID(1)
+	bucketID = platform.ID(2)
+)
+
+type generations struct {
+	mu  sync.Mutex
+	gen uint64
+}
+
+func (g *generations) BucketGeneration(orgID, bucketID platform.ID) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gen
+}
+
+func (g *generations) incr() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gen++
+}
+
+func newCache(t *testing.T, config resultcache.Config, gens *generations) (query.ProxyQueryService, *int) {
+	t.Helper()
+
+	buckets := pmock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		if filter.Name == nil || *filter.Name != "telegraf" {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+		}
+		return &platform.Bucket{ID: bucketID, OrganizationID: orgID, Name: "telegraf"}, nil
+	}
+	c := resultcache.New(config, gens, buckets, zaptest.NewLogger(t))
+	c.Now = func() time.Time { return time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	var runs int
+	svc := &mock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			runs++
+			_, err := fmt.Fprintf(w, "result %d", runs)
+			return flux.Statistics{}, err
+		},
+	}
+	return c.ProxyQueryService(svc), &runs
+}
+
+func request(text string, perms ...platform.Permission) *query.ProxyRequest {
+	if perms == nil {
+		perms = []platform.Permission{{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: idPtr(orgID)},
+		}}
+	}
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  &platform.Authorization{ID: 3, OrgID: orgID, Status: platform.Active, Permissions: perms},
+			OrganizationID: orgID,
+			Compiler:       lang.FluxCompiler{Query: text},
+		},
+		Dialect: csv.DefaultDialect(),
+	}
+}
+
+func idPtr(id platform.ID) *platform.ID {
+	return &id
+}
+
+func run(t *testing.T, s query.ProxyQueryService, ctx context.Context, req *query.ProxyRequest) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := s.Query(ctx, &buf, req); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCache(t *testing.T) {
+	gens := &generations{}
+	s, runs := newCache(t, resultcache.Config{MaxBytes: 1 << 20}, gens)
+	ctx := context.Background()
+
+	const text = `from(bucket: "telegraf") |> range(start: -1h)`
+	if got := run(t, s, ctx, request(text)); got != "result 1" {
+		t.Fatalf("got %q, want the result of the first run", got)
+	}
+
+	// The same query, formatted differently, is returned from the cache.
+	if got := run(t, s, ctx, request("from(bucket:\"telegraf\")\n\t|> range(start:-1h)")); got != "result 1" || *runs != 1 {
+		t.Errorf("got %q after %d runs, want the cached result", got, *runs)
+	}
+
+	// Queries bypassing the cache are run.
+	if got := run(t, s, resultcache.WithBypass(ctx), request(text)); got != "result 2" {
+		t.Errorf("got %q, want the result of a new run", got)
+	}
+
+	// Other time bounds are another key.
+	if got := run(t, s, ctx, request(`from(bucket: "telegraf") |> range(start: -2h)`)); got != "result 3" {
+		t.Errorf("got %q, want the result of a new run", got)
+	}
+
+	// A write to the bucket invalidates its results.
+	gens.incr()
+	if got := run(t, s, ctx, request(text)); got != "result 4" {
+		t.Errorf("got %q, want the result of a new run after a write", got)
+	}
+	if got := run(t, s, ctx, request(text)); got != "result 4" {
+		t.Errorf("got %q, want the result cached after the write", got)
+	}
+}
+
+func TestCache_Uncacheable(t *testing.T) {
+	s, runs := newCache(t, resultcache.Config{MaxBytes: 1 << 20}, &generations{})
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name string
+		req  *query.ProxyRequest
+	}{
+		{
+			name: "writes",
+			req:  request(`from(bucket: "telegraf") |> range(start: -1h) |> to(bucket: "telegraf", org: "my-org")`),
+		},
+		{
+			name: "other source",
+			req:  request(`from(bucket: "telegraf") |> range(start: -1h) |> yield(name: "a") buckets()`),
+		},
+		{
+			name: "not allowed",
+			req: request(`from(bucket: "telegraf") |> range(start: -1h)`, platform.Permission{
+				Action:   platform.ReadAction,
+				Resource: platform.Resource{Type: platform.BucketsResourceType, ID: idPtr(bucketID + 1), OrgID: idPtr(orgID)},
+			}),
+		},
+		{
+			name: "invalid",
+			req:  request(`from(bucket: "telegraf") |>`),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			before := *runs
+			run(t, s, ctx, tt.req)
+			run(t, s, ctx, tt.req)
+			if *runs != before+2 {
+				t.Errorf("got %d runs, want every query to run", *runs-before)
+			}
+		})
+	}
+}
+
+func TestCache_Eviction(t *testing.T) {
+	s, runs := newCache(t, resultcache.Config{MaxBytes: 16, MaxResultBytes: 8}, &generations{})
+	ctx := context.Background()
+
+	queries := []string{
+		`from(bucket: "telegraf") |> range(start: -1h)`,
+		`from(bucket: "telegraf") |> range(start: -2h)`,
+		`from(bucket: "telegraf") |> range(start: -3h)`,
+	}
+	for _, q := range queries {
+		run(t, s, ctx, request(q))
+	}
+	if *runs != 3 {
+		t.Fatalf("got %d runs, want 3", *runs)
+	}
+
+	// The cache holds the two latest results of 8 bytes, the first one was evicted.
+	run(t, s, ctx, request(queries[2]))
+	run(t, s, ctx, request(queries[1]))
+	if *runs != 3 {
+		t.Errorf("got %d runs, want the latest results cached", *runs)
+	}
+	if got := run(t, s, ctx, request(queries[0])); got != "result 4" {
+		t.Errorf("got %q, want the evicted result to run again", got)
+	}
+}
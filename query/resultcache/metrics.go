@@ -0,0 +1,53 @@
+package resultcache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics are the metrics of a cache.
+type metrics struct {
+	requests  *prometheus.CounterVec
+	evictions prometheus.Counter
+	entries   prometheus.Gauge
+	size      prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	const namespace = "query"
+	const subsystem = "cache"
+
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Total number of queries, split out by whether their results were cached (hit), not yet cached (miss), not cacheable (uncacheable) or not looked up (bypass).",
+		}, []string{"result"}),
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Total number of results evicted from the cache.",
+		}),
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of results cached.",
+		}),
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "size_bytes",
+			Help:      "Total size of the results cached.",
+		}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the cache.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.metrics.requests,
+		c.metrics.evictions,
+		c.metrics.entries,
+		c.metrics.size,
+	}
+}
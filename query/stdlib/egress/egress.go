@@ -0,0 +1,91 @@
+// Package egress enforces the egress policies of organizations on the Flux functions
+// making outbound connections, http.to and sql.from.
+// sql.from also reads from the SQL connections of organizations by name, see SQLPools.
+//
+// It replaces the implementations of the functions of the Flux standard library with ones
+// that look up the policy of the organization of the query in the dependencies of the executor:
+// the URLs and data source names the policy does not allow are rejected before any connection is made,
+// and the timeout and the byte limit of the policy apply to the connections allowed.
+//
+// The outbound connections of the organizations without an egress policy are not restricted,
+// unless Dependencies.DenyWithoutPolicy is set.
+package egress
+
+import (
+	"context"
+	"errors"
+
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// EgressKind is the key of the dependencies of the Flux functions making outbound connections.
+const EgressKind = "egress"
+
+// Dependencies are the services the egress policies of organizations are read from.
+type Dependencies struct {
+	OrganizationService platform.OrganizationService
+
+	// SQLConnectionService and SQLPools, if set, let sql.from read from the SQL connections of organizations.
+	SQLConnectionService platform.SQLConnectionService
+	SQLPools             *SQLPools
+
+	// DenyWithoutPolicy makes the queries of organizations without an egress policy make no
+	// outbound connections. If false, their outbound connections are not restricted.
+	DenyWithoutPolicy bool
+}
+
+// unrestrictedPolicy is the egress policy of the organizations without one, which allows
+// any host and any data source name.
+var unrestrictedPolicy = platform.EgressPolicy{
+	AllowedHosts: []string{"*"},
+	AllowedDSNs:  []string{"*"},
+}
+
+// InjectDependencies adds the dependencies of the Flux functions making outbound connections to depsMap.
+func InjectDependencies(depsMap execute.Dependencies, deps Dependencies) error {
+	if deps.OrganizationService == nil {
+		return errors.New("missing organization service dependency")
+	}
+
+	depsMap[EgressKind] = deps
+	return nil
+}
+
+// FindPolicy returns the egress policy of the organization of the query administered by a.
+// If the organization has no egress policy, it returns a policy allowing any outbound connection,
+// or an EForbidden error if the dependencies deny them without policy.
+func FindPolicy(a execute.Administration) (*platform.EgressPolicy, error) {
+	deps, ok := a.Dependencies()[EgressKind].(Dependencies)
+	if !ok {
+		return nil, errors.New("missing egress dependencies")
+	}
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+
+	return findOrganizationPolicy(a.Context(), deps.OrganizationService, req.OrganizationID, deps.DenyWithoutPolicy)
+}
+
+// findOrganizationPolicy returns the egress policy of the organization orgID.
+// If the organization has no egress policy, it returns a policy allowing any outbound connection,
+// or an EForbidden error if deny is true.
+func findOrganizationPolicy(ctx context.Context, orgs platform.OrganizationService, orgID platform.ID, deny bool) (*platform.EgressPolicy, error) {
+	o, err := orgs.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if o.EgressPolicy == nil {
+		if !deny {
+			p := unrestrictedPolicy
+			return &p, nil
+		}
+		return nil, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  "outbound connections require an egress policy for the organization",
+		}
+	}
+	return o.EgressPolicy, nil
+}
@@ -0,0 +1,328 @@
+package egress
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	fluxsql "github.com/influxdata/flux/stdlib/sql"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// FromSQLKind is the kind of sql.from.
+const FromSQLKind = "egressFromSQL"
+
+func init() {
+	fromSQLSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"driverName":     semantic.String,
+			"dataSourceName": semantic.String,
+			"connection":     semantic.String,
+			"query":          semantic.String,
+		},
+		Required: semantic.LabelSet{"query"},
+		Return:   flux.TableObjectType,
+	}
+	flux.ReplacePackageValue("sql", "from", flux.FunctionValue(FromSQLKind, createFromSQLOpSpec, fromSQLSignature))
+	flux.RegisterOpSpec(FromSQLKind, newFromSQLOp)
+	plan.RegisterProcedureSpec(FromSQLKind, newFromSQLProcedure, FromSQLKind)
+	execute.RegisterSource(FromSQLKind, createFromSQLSource)
+}
+
+// FromSQLOpSpec is the operation of sql.from, which replaces the one of the Flux standard library.
+// It reads either from the database of a data source name, or from the database of a SQL connection
+// of the organization of the query, referred to by name.
+type FromSQLOpSpec struct {
+	DriverName     string `json:"driverName,omitempty"`
+	DataSourceName string `json:"dataSourceName,omitempty"`
+	Connection     string `json:"connection,omitempty"`
+	Query          string `json:"query,omitempty"`
+}
+
+func createFromSQLOpSpec(args flux.Arguments, administration *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(FromSQLOpSpec)
+
+	if connection, ok, err := args.GetString("connection"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Connection = connection
+	}
+
+	if driverName, ok, err := args.GetString("driverName"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.DriverName = driverName
+	}
+
+	if dataSourceName, ok, err := args.GetString("dataSourceName"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.DataSourceName = dataSourceName
+	}
+
+	switch {
+	case spec.Connection != "" && (spec.DriverName != "" || spec.DataSourceName != ""):
+		return nil, errors.New("sql.from takes either a connection or a driverName and a dataSourceName")
+	case spec.Connection == "" && (spec.DriverName == "" || spec.DataSourceName == ""):
+		return nil, errors.New("sql.from requires a connection or a driverName and a dataSourceName")
+	}
+
+	if query, err := args.GetRequiredString("query"); err != nil {
+		return nil, err
+	} else {
+		spec.Query = query
+	}
+
+	return spec, nil
+}
+
+func newFromSQLOp() flux.OperationSpec {
+	return new(FromSQLOpSpec)
+}
+
+func (s *FromSQLOpSpec) Kind() flux.OperationKind {
+	return FromSQLKind
+}
+
+// FromSQLProcedureSpec is the procedure of sql.from, which connects under the egress policy
+// of the organization of the query.
+type FromSQLProcedureSpec struct {
+	fluxsql.FromSQLProcedureSpec
+	Connection string
+}
+
+func newFromSQLProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromSQLOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &FromSQLProcedureSpec{
+		FromSQLProcedureSpec: fluxsql.FromSQLProcedureSpec{
+			DriverName:     spec.DriverName,
+			DataSourceName: spec.DataSourceName,
+			Query:          spec.Query,
+		},
+		Connection: spec.Connection,
+	}, nil
+}
+
+func (s *FromSQLProcedureSpec) Kind() plan.ProcedureKind {
+	return FromSQLKind
+}
+
+func (s *FromSQLProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createFromSQLSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromSQLProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+
+	policy, err := FindPolicy(a)
+	if err != nil {
+		return nil, err
+	}
+	d := &sqlDecoder{
+		spec:    spec,
+		alloc:   a,
+		timeout: policy.Timeout,
+	}
+
+	if spec.Connection != "" {
+		if d.pool, err = findSQLConnectionPool(a, spec.Connection); err != nil {
+			return nil, err
+		}
+	} else {
+		if spec.DriverName != "postgres" && spec.DriverName != "mysql" {
+			return nil, fmt.Errorf("sql driver %s not supported", spec.DriverName)
+		}
+		if err := policy.CheckDSN(spec.DataSourceName); err != nil {
+			return nil, err
+		}
+	}
+
+	return execute.CreateSourceFromDecoder(d, dsid, a)
+}
+
+// findSQLConnectionPool returns the pool of database connections of the SQL connection name
+// of the organization of the query administered by a.
+func findSQLConnectionPool(a execute.Administration, name string) (*sql.DB, error) {
+	deps, ok := a.Dependencies()[EgressKind].(Dependencies)
+	if !ok {
+		return nil, errors.New("missing egress dependencies")
+	}
+	if deps.SQLConnectionService == nil || deps.SQLPools == nil {
+		return nil, errors.New("sql connections are not supported")
+	}
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+
+	cs, err := deps.SQLConnectionService.FindSQLConnections(a.Context(), platform.SQLConnectionFilter{
+		OrgID: &req.OrganizationID,
+		Name:  &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  fmt.Sprintf("sql connection %q not found", name),
+		}
+	}
+	return deps.SQLPools.DB(a.Context(), cs[0])
+}
+
+// sqlDecoder reads the rows of the query of sql.from into a table.
+// The whole query, from connecting to reading the last row, is bounded by the timeout of the policy.
+type sqlDecoder struct {
+	spec    *FromSQLProcedureSpec
+	alloc   execute.Administration
+	timeout time.Duration
+
+	// pool is the shared pool of connections of the SQL connection of the query, if any,
+	// which the decoder uses instead of opening its own.
+	pool *sql.DB
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	db     *sql.DB
+	rows   *sql.Rows
+}
+
+func (d *sqlDecoder) Connect() error {
+	d.ctx, d.cancel = context.Background(), func() {}
+	if d.timeout > 0 {
+		d.ctx, d.cancel = context.WithTimeout(d.ctx, d.timeout)
+	}
+
+	if d.pool != nil {
+		d.db = d.pool
+		return d.db.PingContext(d.ctx)
+	}
+
+	db, err := sql.Open(d.spec.DriverName, d.spec.DataSourceName)
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(d.ctx); err != nil {
+		db.Close()
+		return err
+	}
+	d.db = db
+	return nil
+}
+
+func (d *sqlDecoder) Fetch() (bool, error) {
+	rows, err := d.db.QueryContext(d.ctx, d.spec.Query)
+	if err != nil {
+		return false, err
+	}
+	d.rows = rows
+	return false, nil
+}
+
+func (d *sqlDecoder) Decode() (flux.Table, error) {
+	builder := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), d.alloc.Allocator())
+
+	names, err := d.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]interface{}, len(names))
+	ptrs := make([]interface{}, len(names))
+	for i := range row {
+		ptrs[i] = &row[i]
+	}
+
+	first := true
+	for d.rows.Next() {
+		if err := d.rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		// The types of the columns are the types of the values of the first row.
+		if first {
+			for i, v := range row {
+				var typ flux.ColType
+				switch v.(type) {
+				case bool:
+					typ = flux.TBool
+				case int64:
+					typ = flux.TInt
+				case uint64:
+					typ = flux.TUInt
+				case float64:
+					typ = flux.TFloat
+				case string, []byte:
+					typ = flux.TString
+				case time.Time:
+					typ = flux.TTime
+				default:
+					return nil, fmt.Errorf("unsupported type %T of column %s", v, names[i])
+				}
+				if _, err := builder.AddCol(flux.ColMeta{Label: names[i], Type: typ}); err != nil {
+					return nil, err
+				}
+			}
+			first = false
+		}
+
+		for i, v := range row {
+			var err error
+			switch v := v.(type) {
+			case bool:
+				err = builder.AppendBool(i, v)
+			case int64:
+				err = builder.AppendInt(i, v)
+			case uint64:
+				err = builder.AppendUInt(i, v)
+			case float64:
+				err = builder.AppendFloat(i, v)
+			case string:
+				err = builder.AppendString(i, v)
+			case []byte:
+				err = builder.AppendString(i, string(v))
+			case time.Time:
+				err = builder.AppendTime(i, values.ConvertTime(v))
+			default:
+				err = fmt.Errorf("unsupported type %T of column %s", v, names[i])
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := d.rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Table()
+}
+
+func (d *sqlDecoder) Close() error {
+	if d.cancel != nil {
+		defer d.cancel()
+	}
+	if d.rows != nil {
+		d.rows.Close()
+	}
+	if d.db == nil || d.db == d.pool {
+		return nil
+	}
+	return d.db.Close()
+}
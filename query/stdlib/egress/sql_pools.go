@@ -0,0 +1,112 @@
+package egress
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// SQLPools are the pools of database connections of the SQL connections of organizations.
+// The pool of a SQL connection is shared by all the queries and task runs using it, and is
+// reopened when the connection or its data source name change.
+type SQLPools struct {
+	SecretService       platform.SecretService
+	OrganizationService platform.OrganizationService
+
+	// DenyWithoutPolicy denies the SQL connections of the organizations without an egress policy.
+	DenyWithoutPolicy bool
+
+	mu    sync.Mutex
+	pools map[platform.ID]*sqlPool
+}
+
+type sqlPool struct {
+	conn platform.SQLConnection
+	dsn  string
+	db   *sql.DB
+}
+
+var _ platform.SQLConnectionChecker = (*SQLPools)(nil)
+
+// NewSQLPools returns the pools of the SQL connections whose data source names are the secrets of secrets,
+// under the egress policies of the organizations of orgs.
+func NewSQLPools(secrets platform.SecretService, orgs platform.OrganizationService) *SQLPools {
+	return &SQLPools{
+		SecretService:       secrets,
+		OrganizationService: orgs,
+		pools:               make(map[platform.ID]*sqlPool),
+	}
+}
+
+// DB returns the pool of database connections of c, opening it if needed.
+// The data source name of c must be allowed by the egress policy of its organization.
+func (p *SQLPools) DB(ctx context.Context, c *platform.SQLConnection) (*sql.DB, error) {
+	policy, err := findOrganizationPolicy(ctx, p.OrganizationService, c.OrgID, p.DenyWithoutPolicy)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := p.SecretService.LoadSecret(ctx, c.OrgID, c.SecretKey)
+	if platform.ErrorCode(err) == platform.ENotFound {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("secret %s of sql connection %s not found", c.SecretKey, c.Name),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckDSN(dsn); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pool := p.pools[c.ID]; pool != nil {
+		if pool.conn == *c && pool.dsn == dsn {
+			return pool.db, nil
+		}
+		// The queries running on the old pool finish before it closes.
+		go pool.db.Close()
+		delete(p.pools, c.ID)
+	}
+
+	db, err := sql.Open(c.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+
+	p.pools[c.ID] = &sqlPool{conn: *c, dsn: dsn, db: db}
+	return db, nil
+}
+
+// CheckSQLConnection returns an error if c cannot connect to its database.
+func (p *SQLPools) CheckSQLConnection(ctx context.Context, c *platform.SQLConnection) error {
+	db, err := p.DB(ctx, c)
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+// Close closes the pools.
+func (p *SQLPools) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for id, pool := range p.pools {
+		if err := pool.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.pools, id)
+	}
+	return firstErr
+}
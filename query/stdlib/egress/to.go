@@ -0,0 +1,263 @@
+package egress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	fluxhttp "github.com/influxdata/flux/stdlib/http"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	protocol "github.com/influxdata/line-protocol"
+)
+
+func init() {
+	execute.ReplaceTransformation(fluxhttp.ToHTTPKind, createToHTTPTransformation)
+}
+
+func newTransport(keepAlive bool) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: true,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
+		DisableKeepAlives:     !keepAlive,
+	}
+}
+
+var (
+	keepAliveTransport   = newTransport(true)
+	noKeepAliveTransport = newTransport(false)
+)
+
+func createToHTTPTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*fluxhttp.ToHTTPProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	policy, err := FindPolicy(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t, err := NewToHTTPTransformation(d, cache, s, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, d, nil
+}
+
+// ToHTTPTransformation is the transformation of http.to, which sends each table to a URL as line protocol
+// under the egress policy of the organization of the query.
+//
+// A table is encoded before it is sent, so that no request is made once the tables sent would exceed
+// the byte limit of the policy.
+type ToHTTPTransformation struct {
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	spec   *fluxhttp.ToHTTPProcedureSpec
+	policy *platform.EgressPolicy
+	client *http.Client
+
+	timeout time.Duration
+	sent    int64 // The number of bytes sent so far.
+}
+
+// NewToHTTPTransformation returns the transformation of http.to, or an EForbidden error if policy does not
+// allow the URL of spec.
+func NewToHTTPTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *fluxhttp.ToHTTPProcedureSpec, policy *platform.EgressPolicy) (*ToHTTPTransformation, error) {
+	if err := policy.CheckURL(spec.Spec.URL); err != nil {
+		return nil, err
+	}
+
+	transport := keepAliveTransport
+	if spec.Spec.NoKeepAlive {
+		transport = noKeepAliveTransport
+	}
+	timeout := spec.Spec.Timeout
+	if policy.Timeout > 0 && (timeout <= 0 || timeout > policy.Timeout) {
+		timeout = policy.Timeout
+	}
+
+	return &ToHTTPTransformation{
+		d:      d,
+		cache:  cache,
+		spec:   spec,
+		policy: policy,
+		client: &http.Client{
+			Transport: transport,
+			// Redirects are followed only to the hosts the policy allows.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return errors.New("stopped after 10 redirects")
+				}
+				return policy.CheckURL(req.URL.String())
+			},
+		},
+		timeout: timeout,
+	}, nil
+}
+
+func (t *ToHTTPTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *ToHTTPTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	spec := t.spec.Spec
+
+	labels := make(map[string]flux.ColMeta, len(tbl.Cols()))
+	for _, col := range tbl.Cols() {
+		labels[col.Label] = col
+	}
+	if col, ok := labels[spec.TimeColumn]; !ok {
+		return errors.New("could not get time column")
+	} else if col.Type != flux.TTime {
+		return fmt.Errorf("column %s is not of type %s", spec.TimeColumn, col.Type)
+	}
+	var nameColumn string
+	if spec.Name == "" {
+		nameColumn = spec.NameColumn
+	}
+
+	// The tag and value columns of the spec are sorted.
+	cols := tbl.Cols()
+	isTag := make([]bool, len(cols))
+	isValue := make([]bool, len(cols))
+	for i, col := range cols {
+		j := sort.SearchStrings(spec.ValueColumns, col.Label)
+		isValue[i] = j < len(spec.ValueColumns) && spec.ValueColumns[j] == col.Label
+		j = sort.SearchStrings(spec.TagColumns, col.Label)
+		isTag[i] = j < len(spec.TagColumns) && spec.TagColumns[j] == col.Label
+	}
+
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if created {
+		if err := execute.AddTableCols(tbl, builder); err != nil {
+			return err
+		}
+	}
+
+	var body bytes.Buffer
+	e := protocol.NewEncoder(&body)
+	e.FailOnFieldErr(true)
+	e.SetFieldSortOrder(protocol.SortFields)
+	m := &metric{name: spec.Name}
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			m.tags, m.fields = m.tags[:0], m.fields[:0]
+			for j, col := range cr.Cols() {
+				switch {
+				case col.Label == spec.TimeColumn:
+					m.t = values.Time(cr.Times(j).Value(i)).Time()
+				case nameColumn != "" && col.Label == nameColumn:
+					if col.Type != flux.TString {
+						return errors.New("invalid type for measurement column")
+					}
+					m.name = cr.Strings(j).ValueString(i)
+				case isTag[j]:
+					if col.Type != flux.TString {
+						return errors.New("invalid type for tag column")
+					}
+					m.tags = append(m.tags, &protocol.Tag{Key: col.Label, Value: cr.Strings(j).ValueString(i)})
+				case isValue[j]:
+					var v interface{}
+					switch col.Type {
+					case flux.TFloat:
+						v = cr.Floats(j).Value(i)
+					case flux.TInt:
+						v = cr.Ints(j).Value(i)
+					case flux.TUInt:
+						v = cr.UInts(j).Value(i)
+					case flux.TString:
+						v = cr.Strings(j).ValueString(i)
+					case flux.TTime:
+						v = values.Time(cr.Times(j).Value(i))
+					case flux.TBool:
+						v = cr.Bools(j).Value(i)
+					default:
+						return fmt.Errorf("invalid type for column %s", col.Label)
+					}
+					m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: v})
+				}
+			}
+			if _, err := e.Encode(m); err != nil {
+				return err
+			}
+			if err := execute.AppendRecord(i, cr, builder); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if max := t.policy.MaxEgressBytes; max > 0 && t.sent+int64(body.Len()) > max {
+		return &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  fmt.Sprintf("the egress policy of the organization limits the data sent by a query to %d bytes", max),
+		}
+	}
+	t.sent += int64(body.Len())
+
+	req, err := http.NewRequest(spec.Method, spec.URL, &body)
+	if err != nil {
+		return err
+	}
+	for k, v := range spec.Headers {
+		req.Header.Set(k, v)
+	}
+	if t.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (t *ToHTTPTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateWatermark(pt)
+}
+
+func (t *ToHTTPTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *ToHTTPTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// metric is a row of a table encoded as line protocol.
+type metric struct {
+	name   string
+	tags   []*protocol.Tag
+	fields []*protocol.Field
+	t      time.Time
+}
+
+func (m *metric) Name() string                 { return m.name }
+func (m *metric) TagList() []*protocol.Tag     { return m.tags }
+func (m *metric) FieldList() []*protocol.Field { return m.fields }
+func (m *metric) Time() time.Time              { return m.t }
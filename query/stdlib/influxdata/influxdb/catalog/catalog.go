@@ -0,0 +1,341 @@
+// Package catalog implements the Flux package influxdata/influxdb/catalog,
+// which exposes the metadata of an organization as read-only system tables:
+//
+//	import "influxdata/influxdb/catalog"
+//
+//	catalog.buckets() |> filter(fn: (r) => r.retentionPeriod == 0)
+//
+// Every table is scoped to the organization of the query,
+// and only has the rows of the resources that the authorization of the query can read.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// PackagePath is the import path of the Flux package.
+const PackagePath = "influxdata/influxdb/catalog"
+
+// CatalogKind is the kind of the operations reading a system table.
+const CatalogKind = "catalog"
+
+var signature = semantic.FunctionPolySignature{
+	Return: flux.TableObjectType,
+}
+
+// table is a system table, read by the Flux function of the same name.
+type table struct {
+	columns []flux.ColMeta
+	// rows returns the rows of the table for the organization of the query, in the order of columns.
+	rows func(ctx context.Context, deps *Dependencies, orgID platform.ID, auth *platform.Authorization) ([][]values.Value, error)
+}
+
+var tables = map[string]table{
+	"buckets": bucketsTable,
+	"tasks":   tasksTable,
+	"users":   usersTable,
+}
+
+func init() {
+	for name := range tables {
+		name := name
+		flux.RegisterPackageValue(PackagePath, name, flux.FunctionValue(name, func(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+			return &CatalogOpSpec{Table: name}, nil
+		}, signature))
+	}
+	flux.RegisterOpSpec(CatalogKind, newCatalogOp)
+	plan.RegisterProcedureSpec(CatalogKind, newCatalogProcedure, CatalogKind)
+	execute.RegisterSource(CatalogKind, createCatalogSource)
+}
+
+// CatalogOpSpec is the operation reading a system table.
+type CatalogOpSpec struct {
+	Table string `json:"table"`
+}
+
+func newCatalogOp() flux.OperationSpec {
+	return new(CatalogOpSpec)
+}
+
+func (s *CatalogOpSpec) Kind() flux.OperationKind {
+	return CatalogKind
+}
+
+// CatalogProcedureSpec is the procedure reading a system table.
+type CatalogProcedureSpec struct {
+	plan.DefaultCost
+	Table string
+}
+
+func newCatalogProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*CatalogOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &CatalogProcedureSpec{Table: spec.Table}, nil
+}
+
+func (s *CatalogProcedureSpec) Kind() plan.ProcedureKind {
+	return CatalogKind
+}
+
+func (s *CatalogProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+// Dependencies are the services that the system tables are read from.
+type Dependencies struct {
+	BucketService              platform.BucketService
+	UserService                platform.UserService
+	UserResourceMappingService platform.UserResourceMappingService
+
+	// TaskService may be set after the dependencies are injected,
+	// as long as it is set before any query reads the tasks table.
+	TaskService platform.TaskService
+}
+
+// InjectDependencies adds the dependencies of the system tables to depsMap.
+func InjectDependencies(depsMap execute.Dependencies, deps *Dependencies) error {
+	if deps.BucketService == nil {
+		return errors.New("missing bucket service dependency")
+	}
+	if deps.UserService == nil {
+		return errors.New("missing user service dependency")
+	}
+	if deps.UserResourceMappingService == nil {
+		return errors.New("missing user resource mapping service dependency")
+	}
+
+	depsMap[CatalogKind] = deps
+	return nil
+}
+
+type catalogDecoder struct {
+	ctx   context.Context
+	table table
+	deps  *Dependencies
+	orgID platform.ID
+	auth  *platform.Authorization
+	alloc *memory.Allocator
+	rows  [][]values.Value
+}
+
+func (d *catalogDecoder) Connect() error {
+	return nil
+}
+
+func (d *catalogDecoder) Fetch() (bool, error) {
+	rows, err := d.table.rows(d.ctx, d.deps, d.orgID, d.auth)
+	if err != nil {
+		return false, err
+	}
+	d.rows = rows
+	return false, nil
+}
+
+func (d *catalogDecoder) Decode() (flux.Table, error) {
+	kb := execute.NewGroupKeyBuilder(nil)
+	kb.AddKeyValue("organizationID", values.NewString(d.orgID.String()))
+	gk, err := kb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	b := execute.NewColListTableBuilder(gk, d.alloc)
+	for _, c := range d.table.columns {
+		if _, err := b.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range d.rows {
+		for j, v := range row {
+			if err := b.AppendValue(j, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.Table()
+}
+
+func (d *catalogDecoder) Close() error {
+	return nil
+}
+
+func createCatalogSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*CatalogProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+	t, ok := tables[spec.Table]
+	if !ok {
+		return nil, fmt.Errorf("unknown catalog table %q", spec.Table)
+	}
+
+	deps, ok := a.Dependencies()[CatalogKind].(*Dependencies)
+	if !ok {
+		return nil, errors.New("missing catalog dependencies")
+	}
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	if req.Authorization == nil {
+		return nil, errors.New("missing authorization on request")
+	}
+
+	d := &catalogDecoder{
+		ctx:   a.Context(),
+		table: t,
+		deps:  deps,
+		orgID: req.OrganizationID,
+		auth:  req.Authorization,
+		alloc: a.Allocator(),
+	}
+
+	return execute.CreateSourceFromDecoder(d, dsid, a)
+}
+
+// canRead returns true if auth can read the resource of type rt with the id in the organization.
+func canRead(auth *platform.Authorization, rt platform.ResourceType, orgID, id platform.ID) bool {
+	p, err := platform.NewPermissionAtID(id, platform.ReadAction, rt, orgID)
+	if err != nil {
+		return false
+	}
+	return auth.Allowed(*p)
+}
+
+var bucketsTable = table{
+	columns: []flux.ColMeta{
+		{Label: "organizationID", Type: flux.TString},
+		{Label: "id", Type: flux.TString},
+		{Label: "name", Type: flux.TString},
+		{Label: "retentionPolicy", Type: flux.TString},
+		{Label: "retentionPeriod", Type: flux.TInt},
+	},
+	rows: func(ctx context.Context, deps *Dependencies, orgID platform.ID, auth *platform.Authorization) ([][]values.Value, error) {
+		bs, _, err := deps.BucketService.FindBuckets(ctx, platform.BucketFilter{OrganizationID: &orgID})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]values.Value, 0, len(bs))
+		for _, b := range bs {
+			if !canRead(auth, platform.BucketsResourceType, orgID, b.ID) {
+				continue
+			}
+			rows = append(rows, []values.Value{
+				values.NewString(orgID.String()),
+				values.NewString(b.ID.String()),
+				values.NewString(b.Name),
+				values.NewString(b.RetentionPolicyName),
+				values.NewInt(b.RetentionPeriod.Nanoseconds()),
+			})
+		}
+		return rows, nil
+	},
+}
+
+var tasksTable = table{
+	columns: []flux.ColMeta{
+		{Label: "organizationID", Type: flux.TString},
+		{Label: "id", Type: flux.TString},
+		{Label: "name", Type: flux.TString},
+		{Label: "status", Type: flux.TString},
+		{Label: "every", Type: flux.TString},
+		{Label: "cron", Type: flux.TString},
+		{Label: "offset", Type: flux.TString},
+		{Label: "latestCompleted", Type: flux.TString},
+	},
+	rows: func(ctx context.Context, deps *Dependencies, orgID platform.ID, auth *platform.Authorization) ([][]values.Value, error) {
+		if deps.TaskService == nil {
+			return nil, errors.New("tasks are not available to queries")
+		}
+
+		var rows [][]values.Value
+		filter := platform.TaskFilter{OrganizationID: &orgID, Limit: platform.TaskMaxPageSize}
+		for {
+			ts, _, err := deps.TaskService.FindTasks(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range ts {
+				if !canRead(auth, platform.TasksResourceType, orgID, t.ID) {
+					continue
+				}
+				rows = append(rows, []values.Value{
+					values.NewString(orgID.String()),
+					values.NewString(t.ID.String()),
+					values.NewString(t.Name),
+					values.NewString(t.Status),
+					values.NewString(t.Every),
+					values.NewString(t.Cron),
+					values.NewString(t.Offset),
+					values.NewString(t.LatestCompleted),
+				})
+			}
+			if len(ts) < filter.Limit {
+				return rows, nil
+			}
+			after := ts[len(ts)-1].ID
+			filter.After = &after
+		}
+	},
+}
+
+var usersTable = table{
+	columns: []flux.ColMeta{
+		{Label: "organizationID", Type: flux.TString},
+		{Label: "id", Type: flux.TString},
+		{Label: "name", Type: flux.TString},
+		{Label: "role", Type: flux.TString},
+	},
+	rows: func(ctx context.Context, deps *Dependencies, orgID platform.ID, auth *platform.Authorization) ([][]values.Value, error) {
+		ms, _, err := deps.UserResourceMappingService.FindUserResourceMappings(ctx, platform.UserResourceMappingFilter{
+			ResourceType: platform.OrgsResourceType,
+			ResourceID:   orgID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]values.Value, 0, len(ms))
+		for _, m := range ms {
+			p := platform.Permission{
+				Action:   platform.ReadAction,
+				Resource: platform.Resource{Type: platform.UsersResourceType, ID: &m.UserID},
+			}
+			if !auth.Allowed(p) {
+				continue
+			}
+			u, err := deps.UserService.FindUserByID(ctx, m.UserID)
+			if err != nil {
+				if platform.ErrorCode(err) == platform.ENotFound {
+					continue
+				}
+				return nil, err
+			}
+			rows = append(rows, []values.Value{
+				values.NewString(orgID.String()),
+				values.NewString(u.ID.String()),
+				values.NewString(u.Name),
+				values.NewString(string(m.UserType)),
+			})
+		}
+		return rows, nil
+	},
+}
@@ -0,0 +1,230 @@
+package catalog_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	pcontrol "github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/catalog"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID   platform.ID = 10
+	otherID platform.ID = 11
+)
+
+func newCatalogQueryService(t *testing.T) (query.QueryService, func()) {
+	t.Helper()
+	ctx := context.Background()
+
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range []*platform.Organization{
+		{ID: orgID, Name: "org"},
+		{ID: otherID, Name: "other"},
+	} {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range []*platform.Bucket{
+		{ID: 1, OrganizationID: orgID, Name: "forever"},
+		{ID: 2, OrganizationID: orgID, Name: "week", RetentionPeriod: 7 * 24 * time.Hour},
+		{ID: 3, OrganizationID: orgID, Name: "secret"},
+		{ID: 4, OrganizationID: otherID, Name: "other"},
+	} {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, u := range []*platform.User{
+		{ID: 20, Name: "owner"},
+		{ID: 21, Name: "member"},
+		{ID: 22, Name: "outsider"},
+	} {
+		if err := svc.PutUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range []*platform.UserResourceMapping{
+		{UserID: 20, UserType: platform.Owner, ResourceType: platform.OrgsResourceType, ResourceID: orgID},
+		{UserID: 21, UserType: platform.Member, ResourceType: platform.OrgsResourceType, ResourceID: orgID},
+		{UserID: 22, UserType: platform.Member, ResourceType: platform.OrgsResourceType, ResourceID: otherID},
+	} {
+		if err := svc.CreateUserResourceMapping(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tasks := &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, f platform.TaskFilter) ([]*platform.Task, int, error) {
+			if f.OrganizationID == nil || *f.OrganizationID != orgID || f.After != nil {
+				return nil, 0, nil
+			}
+			return []*platform.Task{
+				{ID: 30, OrganizationID: orgID, Name: "downsample", Status: "active", Every: "1h"},
+				{ID: 31, OrganizationID: orgID, Name: "alert", Status: "inactive", Cron: "0 * * * *", Offset: "10s"},
+			}, 2, nil
+		},
+	}
+
+	deps := make(execute.Dependencies)
+	catalogDeps := &catalog.Dependencies{
+		BucketService:              svc,
+		UserService:                svc,
+		UserResourceMappingService: svc,
+	}
+	if err := catalog.InjectDependencies(deps, catalogDeps); err != nil {
+		t.Fatal(err)
+	}
+	// The task service is set after the dependencies are injected, as the launcher does.
+	catalogDeps.TaskService = tasks
+
+	ctrl := pcontrol.New(control.Config{
+		ExecutorDependencies: deps,
+		ConcurrencyQuota:     1,
+		MemoryBytesQuota:     1e6,
+		Logger:               zaptest.NewLogger(t),
+	})
+	done := func() {
+		_ = ctrl.Shutdown(context.Background())
+	}
+	return query.QueryServiceBridge{AsyncQueryService: ctrl}, done
+}
+
+// readRows returns the rows of the tables returned by the query, as maps of column labels to values.
+func readRows(t *testing.T, qs query.QueryService, auth *platform.Authorization, script string) []map[string]interface{} {
+	t.Helper()
+
+	it, err := qs.Query(context.Background(), &query.Request{
+		Authorization:  auth,
+		OrganizationID: orgID,
+		Compiler:       lang.FluxCompiler{Query: script},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Release()
+
+	var rows []map[string]interface{}
+	for it.More() {
+		err := it.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					row := make(map[string]interface{}, len(cr.Cols()))
+					for j, c := range cr.Cols() {
+						v := execute.ValueForRow(cr, i, j)
+						switch c.Type {
+						case flux.TString:
+							row[c.Label] = v.Str()
+						case flux.TInt:
+							row[c.Label] = v.Int()
+						}
+					}
+					rows = append(rows, row)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestCatalog(t *testing.T) {
+	qs, done := newCatalogQueryService(t)
+	defer done()
+
+	var (
+		readBucketOne = platform.Permission{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(orgID), ID: influxdbtesting.IDPtr(1)},
+		}
+		readBucketTwo = platform.Permission{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(orgID), ID: influxdbtesting.IDPtr(2)},
+		}
+		readTasks = platform.Permission{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.TasksResourceType, OrgID: influxdbtesting.IDPtr(orgID)},
+		}
+		readUsers = platform.Permission{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.UsersResourceType},
+		}
+	)
+
+	tests := []struct {
+		name        string
+		permissions []platform.Permission
+		script      string
+		want        []map[string]interface{}
+	}{
+		{
+			name:        "buckets with infinite retention",
+			permissions: []platform.Permission{readBucketOne, readBucketTwo},
+			script: `import "influxdata/influxdb/catalog"
+catalog.buckets() |> filter(fn: (r) => r.retentionPeriod == 0) |> keep(columns: ["id", "name"])`,
+			want: []map[string]interface{}{
+				{"id": platform.ID(1).String(), "name": "forever"},
+			},
+		},
+		{
+			name:        "tasks",
+			permissions: []platform.Permission{readTasks},
+			script: `import "influxdata/influxdb/catalog"
+catalog.tasks() |> keep(columns: ["name", "status", "every", "cron", "offset"])`,
+			want: []map[string]interface{}{
+				{"name": "downsample", "status": "active", "every": "1h", "cron": "", "offset": ""},
+				{"name": "alert", "status": "inactive", "every": "", "cron": "0 * * * *", "offset": "10s"},
+			},
+		},
+		{
+			name:        "users",
+			permissions: []platform.Permission{readUsers},
+			script: `import "influxdata/influxdb/catalog"
+catalog.users() |> keep(columns: ["name", "role"])`,
+			want: []map[string]interface{}{
+				{"name": "owner", "role": "owner"},
+				{"name": "member", "role": "member"},
+			},
+		},
+		{
+			name:        "unreadable resources are left out",
+			permissions: []platform.Permission{readBucketOne},
+			script: `import "influxdata/influxdb/catalog"
+catalog.tasks() |> keep(columns: ["name"])`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &platform.Authorization{Status: platform.Active, OrgID: orgID, Permissions: tt.permissions}
+			got := readRows(t, qs, auth, tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected rows\ngot  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
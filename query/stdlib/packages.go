@@ -2,7 +2,9 @@ package stdlib
 
 // Import all stdlib packages
 import (
+	_ "github.com/influxdata/influxdb/query/stdlib/egress"
 	_ "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	_ "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/catalog"
 	_ "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/v1"
 	_ "github.com/influxdata/influxdb/query/stdlib/testing"
 )
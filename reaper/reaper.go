@@ -0,0 +1,402 @@
+// Package reaper finds the resources of organizations left inactive according to the inactivity
+// policy of their organization, notifies their owners and deactivates them per policy.
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/task/backend"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInterval is the default time between two passes over the organizations.
+	DefaultInterval = 24 * time.Hour
+
+	// DefaultTimeout is the default time limit of the delivery of a notification.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Reaper periodically finds the inactive resources of the organizations with an inactivity policy.
+type Reaper struct {
+	OrganizationService        influxdb.OrganizationService
+	AuthorizationService       influxdb.AuthorizationService
+	DashboardService           influxdb.DashboardService
+	TaskService                influxdb.TaskService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	ActivityService            influxdb.ActivityService
+
+	Client   *http.Client
+	Logger   *zap.Logger
+	Interval time.Duration
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewReaper returns a Reaper passing over the organizations every DefaultInterval.
+// Its services must be set before it is run.
+func NewReaper(logger *zap.Logger) *Reaper {
+	return &Reaper{
+		Client:   &http.Client{Timeout: DefaultTimeout},
+		Logger:   logger.With(zap.String("service", "reaper")),
+		Interval: DefaultInterval,
+		Now:      time.Now,
+	}
+}
+
+// Run passes over the organizations every interval until ctx is done.
+func (r *Reaper) Run(ctx context.Context) {
+	logger := r.Logger.With(influxlogger.DurationLiteral("interval", r.Interval))
+
+	logger.Info("Starting")
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Reap(ctx); err != nil {
+				logger.Info("Failed to find inactive resources", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Reap finds the inactive resources of every organization with an inactivity policy, and acts on them.
+// The failure of an organization is logged, and does not prevent the others from being reaped.
+func (r *Reaper) Reap(ctx context.Context) error {
+	orgs, _, err := r.OrganizationService.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orgs {
+		if o.InactivityPolicy == nil {
+			continue
+		}
+		rs, err := r.ReapOrganization(ctx, o)
+		if err != nil {
+			r.Logger.Info("Failed to reap organization", zap.Stringer("org_id", o.ID), zap.Error(err))
+			continue
+		}
+		if len(rs) > 0 {
+			r.Logger.Info("Found inactive resources", zap.Stringer("org_id", o.ID), zap.Int("count", len(rs)))
+		}
+	}
+	return nil
+}
+
+// ReapOrganization finds the inactive resources of o according to its inactivity policy, deactivates
+// them if the policy says so, notifies their owners, and returns them.
+func (r *Reaper) ReapOrganization(ctx context.Context, o *influxdb.Organization) ([]influxdb.InactiveResource, error) {
+	p := o.InactivityPolicy
+	if p == nil {
+		return nil, nil
+	}
+	now := r.now()
+
+	// The runs of the tasks are read, and the tasks updated, on behalf of the organization.
+	ctx = pctx.SetAuthorizer(ctx, &influxdb.Authorization{
+		OrgID:       o.ID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OwnerPermissions(o.ID),
+	})
+
+	var tasks []*influxdb.Task
+	if p.TokenUnusedPeriod > 0 || p.TaskFailedRuns > 0 {
+		var err error
+		if tasks, err = r.findTasks(ctx, o.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var res []influxdb.InactiveResource
+	if p.TokenUnusedPeriod > 0 {
+		rs, err := r.inactiveTokens(ctx, o, tasks, now)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rs...)
+	}
+	if p.DashboardUnviewedPeriod > 0 {
+		rs, err := r.inactiveDashboards(ctx, o, now)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rs...)
+	}
+	if p.TaskFailedRuns > 0 {
+		rs, err := r.inactiveTasks(ctx, o, tasks)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rs...)
+	}
+
+	if p.Action == influxdb.InactivityDeactivate {
+		for i := range res {
+			if err := r.deactivate(ctx, res[i]); err != nil {
+				r.Logger.Info("Failed to deactivate inactive resource", zap.String("type", string(res[i].Type)), zap.Stringer("id", res[i].ID), zap.Error(err))
+				continue
+			}
+			res[i].Deactivated = res[i].Type != influxdb.DashboardsResourceType
+		}
+	}
+
+	if p.NotifyURL != "" && len(res) > 0 {
+		if err := r.notify(ctx, p.NotifyURL, influxdb.InactivityNotification{
+			OrgID:     o.ID,
+			OrgName:   o.Name,
+			Time:      now.UTC(),
+			Resources: res,
+		}); err != nil {
+			r.Logger.Info("Failed to notify owners of inactive resources", zap.Stringer("org_id", o.ID), zap.Error(err))
+		}
+	}
+
+	return res, nil
+}
+
+func (r *Reaper) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// inactiveTokens returns the active tokens of o not used within the period of its policy.
+// The tokens of tasks are skipped, as tasks use them without authenticating requests.
+func (r *Reaper) inactiveTokens(ctx context.Context, o *influxdb.Organization, tasks []*influxdb.Task, now time.Time) ([]influxdb.InactiveResource, error) {
+	taskAuths := make(map[influxdb.ID]bool, len(tasks))
+	for _, t := range tasks {
+		taskAuths[t.AuthorizationID] = true
+	}
+
+	as, _, err := r.AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []influxdb.InactiveResource
+	for _, a := range as {
+		if a.OrgID != o.ID || a.Status == influxdb.Inactive || taskAuths[a.ID] {
+			continue
+		}
+		last, err := r.lastActivity(ctx, influxdb.AuthorizationsResourceType, a.ID, time.Time{}, now)
+		if err != nil {
+			return nil, err
+		}
+		if now.Sub(last) < o.InactivityPolicy.TokenUnusedPeriod {
+			continue
+		}
+		res = append(res, influxdb.InactiveResource{
+			Type:     influxdb.AuthorizationsResourceType,
+			ID:       a.ID,
+			OrgID:    o.ID,
+			Name:     a.Description,
+			OwnerIDs: []influxdb.ID{a.UserID},
+			Reason:   fmt.Sprintf("token not used since %s", last.UTC().Format(time.RFC3339)),
+		})
+	}
+	return res, nil
+}
+
+// inactiveDashboards returns the dashboards of o neither viewed nor updated within the period of its policy.
+func (r *Reaper) inactiveDashboards(ctx context.Context, o *influxdb.Organization, now time.Time) ([]influxdb.InactiveResource, error) {
+	ds, _, err := r.DashboardService.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &o.ID}, influxdb.DefaultDashboardFindOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []influxdb.InactiveResource
+	for _, d := range ds {
+		updated := d.Meta.UpdatedAt
+		if updated.IsZero() {
+			updated = d.Meta.CreatedAt
+		}
+		last, err := r.lastActivity(ctx, influxdb.DashboardsResourceType, d.ID, updated, now)
+		if err != nil {
+			return nil, err
+		}
+		if now.Sub(last) < o.InactivityPolicy.DashboardUnviewedPeriod {
+			continue
+		}
+		owners, err := r.owners(ctx, influxdb.DashboardsResourceType, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, influxdb.InactiveResource{
+			Type:     influxdb.DashboardsResourceType,
+			ID:       d.ID,
+			OrgID:    o.ID,
+			Name:     d.Name,
+			OwnerIDs: owners,
+			Reason:   fmt.Sprintf("dashboard not viewed since %s", last.UTC().Format(time.RFC3339)),
+		})
+	}
+	return res, nil
+}
+
+// inactiveTasks returns the active tasks whose latest runs, as many as the policy of o says, all failed.
+func (r *Reaper) inactiveTasks(ctx context.Context, o *influxdb.Organization, tasks []*influxdb.Task) ([]influxdb.InactiveResource, error) {
+	n := o.InactivityPolicy.TaskFailedRuns
+
+	var res []influxdb.InactiveResource
+	for _, t := range tasks {
+		if t.Status == influxdb.TaskStatusInactive {
+			continue
+		}
+		runs, _, err := r.TaskService.FindRuns(ctx, influxdb.RunFilter{Task: t.ID})
+		if err != nil {
+			return nil, err
+		}
+		if !latestRunsFailed(runs, n) {
+			continue
+		}
+		owners, err := r.owners(ctx, influxdb.TasksResourceType, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, influxdb.InactiveResource{
+			Type:     influxdb.TasksResourceType,
+			ID:       t.ID,
+			OrgID:    o.ID,
+			Name:     t.Name,
+			OwnerIDs: owners,
+			Reason:   fmt.Sprintf("latest %d runs failed", n),
+		})
+	}
+	return res, nil
+}
+
+// latestRunsFailed returns true if the latest n finished runs failed.
+// Canceled runs and runs in progress are ignored.
+func latestRunsFailed(runs []*influxdb.Run, n int) bool {
+	var finished []*influxdb.Run
+	for _, run := range runs {
+		if run.Status == backend.RunSuccess.String() || run.Status == backend.RunFail.String() {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) < n {
+		return false
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, finished[i].ScheduledFor)
+		tj, _ := time.Parse(time.RFC3339, finished[j].ScheduledFor)
+		return ti.After(tj)
+	})
+	for _, run := range finished[:n] {
+		if run.Status != backend.RunFail.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// lastActivity returns the time the resource was last used, or since if it was updated later.
+// A resource without any recorded activity nor update time is recorded as used now, so that it is
+// only found inactive after a full period, as the time it was created at is unknown.
+func (r *Reaper) lastActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID, since, now time.Time) (time.Time, error) {
+	last, err := r.ActivityService.FindLastActivity(ctx, rt, id)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		if !since.IsZero() {
+			return since, nil
+		}
+		return now, r.ActivityService.RecordActivity(ctx, rt, id, now)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if since.After(last) {
+		return since, nil
+	}
+	return last, nil
+}
+
+// owners returns the IDs of the owners of the resource of type rt with id.
+func (r *Reaper) owners(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.ID, error) {
+	ms, _, err := r.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: rt,
+		ResourceID:   id,
+		UserType:     influxdb.Owner,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]influxdb.ID, 0, len(ms))
+	for _, m := range ms {
+		ids = append(ids, m.UserID)
+	}
+	return ids, nil
+}
+
+// findTasks returns all the tasks of the organization with orgID.
+func (r *Reaper) findTasks(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Task, error) {
+	filter := influxdb.TaskFilter{OrganizationID: &orgID, Limit: influxdb.TaskMaxPageSize}
+
+	var tasks []*influxdb.Task
+	for {
+		ts, _, err := r.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, ts...)
+		if len(ts) < filter.Limit {
+			return tasks, nil
+		}
+		filter.After = &ts[len(ts)-1].ID
+	}
+}
+
+// deactivate deactivates the inactive token or task res. Dashboards have no inactive state, and are left as is.
+func (r *Reaper) deactivate(ctx context.Context, res influxdb.InactiveResource) error {
+	switch res.Type {
+	case influxdb.AuthorizationsResourceType:
+		return r.AuthorizationService.SetAuthorizationStatus(ctx, res.ID, influxdb.Inactive)
+	case influxdb.TasksResourceType:
+		status := influxdb.TaskStatusInactive
+		_, err := r.TaskService.UpdateTask(ctx, res.ID, influxdb.TaskUpdate{Status: &status})
+		return err
+	}
+	return nil
+}
+
+// notify POSTs the notification n to url.
+func (r *Reaper) notify(ctx context.Context, url string, n influxdb.InactivityNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification rejected with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package reaper_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/reaper"
+	"go.uber.org/zap"
+)
+
+func TestReaper_ReapOrganization(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	svc := kv.NewService(inmem.NewKVStore())
+	svc.WithTime(func() time.Time { return now.Add(-30 * 24 * time.Hour) })
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "o"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "u"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	newToken := func(desc string) *influxdb.Authorization {
+		t.Helper()
+		a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, Description: desc}
+		if err := svc.CreateAuthorization(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	unused, used, taskToken, unseen := newToken("unused"), newToken("used"), newToken("task"), newToken("unseen")
+	if err := svc.RecordActivity(ctx, influxdb.AuthorizationsResourceType, unused.ID, now.Add(-10*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RecordActivity(ctx, influxdb.AuthorizationsResourceType, used.ID, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RecordActivity(ctx, influxdb.AuthorizationsResourceType, taskToken.ID, now.Add(-10*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The dashboards were last updated 30 days ago.
+	stale := &influxdb.Dashboard{OrganizationID: org.ID, Name: "stale"}
+	viewed := &influxdb.Dashboard{OrganizationID: org.ID, Name: "viewed"}
+	for _, d := range []*influxdb.Dashboard{stale, viewed} {
+		if err := svc.CreateDashboard(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.RecordActivity(ctx, influxdb.DashboardsResourceType, viewed.ID, now.Add(-24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       user.ID,
+		UserType:     influxdb.Owner,
+		ResourceType: influxdb.DashboardsResourceType,
+		ResourceID:   stale.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := []*influxdb.Task{
+		{ID: 100, OrganizationID: org.ID, Name: "broken", Status: influxdb.TaskStatusActive, AuthorizationID: taskToken.ID},
+		{ID: 101, OrganizationID: org.ID, Name: "flaky", Status: influxdb.TaskStatusActive, AuthorizationID: taskToken.ID},
+	}
+	runs := map[influxdb.ID][]*influxdb.Run{
+		100: {
+			{Status: "failed", ScheduledFor: "2019-03-01T10:00:00Z"},
+			{Status: "canceled", ScheduledFor: "2019-03-01T10:30:00Z"},
+			{Status: "failed", ScheduledFor: "2019-03-01T11:00:00Z"},
+			{Status: "success", ScheduledFor: "2019-03-01T09:00:00Z"},
+		},
+		101: {
+			{Status: "failed", ScheduledFor: "2019-03-01T10:00:00Z"},
+			{Status: "success", ScheduledFor: "2019-03-01T11:00:00Z"},
+		},
+	}
+	var deactivatedTasks []influxdb.ID
+	taskSvc := &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+			return tasks, len(tasks), nil
+		},
+		FindRunsFn: func(ctx context.Context, filter influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+			return runs[filter.Task], len(runs[filter.Task]), nil
+		},
+		UpdateTaskFn: func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+			if upd.Status == nil || *upd.Status != influxdb.TaskStatusInactive {
+				t.Errorf("unexpected task update: %+v", upd)
+			}
+			deactivatedTasks = append(deactivatedTasks, id)
+			return nil, nil
+		},
+	}
+
+	var notifications []influxdb.InactivityNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n influxdb.InactivityNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Error(err)
+		}
+		notifications = append(notifications, n)
+	}))
+	defer server.Close()
+
+	r := reaper.NewReaper(zap.NewNop())
+	r.OrganizationService = svc
+	r.AuthorizationService = svc
+	r.DashboardService = svc
+	r.TaskService = taskSvc
+	r.UserResourceMappingService = svc
+	r.ActivityService = svc
+	r.Now = func() time.Time { return now }
+
+	org.InactivityPolicy = &influxdb.InactivityPolicy{
+		TokenUnusedPeriod:       7 * 24 * time.Hour,
+		DashboardUnviewedPeriod: 14 * 24 * time.Hour,
+		TaskFailedRuns:          2,
+		Action:                  influxdb.InactivityDeactivate,
+		NotifyURL:               server.URL,
+	}
+	res, err := r.ReapOrganization(ctx, org)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []influxdb.InactiveResource{
+		{Type: influxdb.AuthorizationsResourceType, ID: unused.ID, Name: "unused", OwnerIDs: []influxdb.ID{user.ID}, Deactivated: true},
+		{Type: influxdb.DashboardsResourceType, ID: stale.ID, Name: "stale", OwnerIDs: []influxdb.ID{user.ID}},
+		{Type: influxdb.TasksResourceType, ID: 100, Name: "broken", OwnerIDs: []influxdb.ID{}, Deactivated: true},
+	}
+	if len(res) != len(want) {
+		t.Fatalf("unexpected inactive resources: %+v", res)
+	}
+	for i := range want {
+		got := res[i]
+		if got.Type != want[i].Type || got.ID != want[i].ID || got.Name != want[i].Name || got.OrgID != org.ID ||
+			got.Deactivated != want[i].Deactivated || len(got.OwnerIDs) != len(want[i].OwnerIDs) || got.Reason == "" {
+			t.Errorf("unexpected inactive resource %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	a, err := svc.FindAuthorizationByID(ctx, unused.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != influxdb.Inactive {
+		t.Errorf("expected unused token to be deactivated, got status %s", a.Status)
+	}
+	if len(deactivatedTasks) != 1 || deactivatedTasks[0] != 100 {
+		t.Errorf("unexpected deactivated tasks: %v", deactivatedTasks)
+	}
+
+	if len(notifications) != 1 || notifications[0].OrgID != org.ID || len(notifications[0].Resources) != len(want) {
+		t.Errorf("unexpected notifications: %+v", notifications)
+	}
+
+	// A token never seen before is recorded as used now, rather than found inactive right away.
+	if last, err := svc.FindLastActivity(ctx, influxdb.AuthorizationsResourceType, unseen.ID); err != nil || !last.Equal(now) {
+		t.Errorf("expected unseen token to be recorded as used now, got %s, %v", last, err)
+	}
+}
+
+func TestReaper_ReapOrganization_Notify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "o"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "u"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RecordActivity(ctx, influxdb.AuthorizationsResourceType, a.ID, now.Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	var notified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified++
+	}))
+	defer server.Close()
+
+	r := reaper.NewReaper(zap.NewNop())
+	r.OrganizationService = svc
+	r.AuthorizationService = svc
+	r.TaskService = &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+			return nil, 0, nil
+		},
+	}
+	r.UserResourceMappingService = svc
+	r.ActivityService = svc
+
+	org.InactivityPolicy = &influxdb.InactivityPolicy{
+		TokenUnusedPeriod: 24 * time.Hour,
+		Action:            influxdb.InactivityNotify,
+		NotifyURL:         server.URL,
+	}
+	if _, err := svc.UpdateOrganization(ctx, org.ID, influxdb.OrganizationUpdate{InactivityPolicy: org.InactivityPolicy}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reap(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if notified != 1 {
+		t.Errorf("expected 1 notification, got %d", notified)
+	}
+	// Notifying leaves the token active.
+	if a, err := svc.FindAuthorizationByID(ctx, a.ID); err != nil || a.Status != influxdb.Active {
+		t.Errorf("expected token to be left active, got %+v, %v", a, err)
+	}
+}
@@ -0,0 +1,115 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultReplicationSampleSize is the number of series compared by a ReplicationRequest without a sample size.
+	DefaultReplicationSampleSize = 100
+
+	// MaxReplicationSampleSize is the largest number of series that a ReplicationRequest can compare.
+	MaxReplicationSampleSize = 10000
+)
+
+// ReplicationService verifies that the data of buckets is mirrored in other buckets.
+type ReplicationService interface {
+	// VerifyReplication compares the data of the source bucket of req with the data of its target bucket
+	// over the window of req.
+	VerifyReplication(ctx context.Context, req ReplicationRequest) (*ReplicationReport, error)
+}
+
+// ReplicationRequest asks for the comparison of a source bucket with the target bucket that mirrors it,
+// between Start and Stop.
+//
+// The watermark of each bucket is the time of its latest point in the window. A sample of SampleSize
+// series of the source bucket is compared with the same series of the target bucket, up to the watermark
+// of the target bucket, so that the points the target bucket has not received yet are reported as lag
+// rather than as divergence.
+type ReplicationRequest struct {
+	OrgID          ID        `json:"orgID"`
+	BucketID       ID        `json:"bucketID"`
+	TargetOrgID    ID        `json:"targetOrgID"`
+	TargetBucketID ID        `json:"targetBucketID"`
+	Start          time.Time `json:"start"`
+	Stop           time.Time `json:"stop"`
+	SampleSize     int       `json:"sampleSize"`
+}
+
+// Validate returns an error if the request is invalid.
+func (r *ReplicationRequest) Validate() error {
+	if !r.OrgID.Valid() || !r.TargetOrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication orgID and targetOrgID are required",
+		}
+	}
+	if !r.BucketID.Valid() || !r.TargetBucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication bucketID and targetBucketID are required",
+		}
+	}
+	if r.BucketID == r.TargetBucketID {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication target bucket must be another bucket",
+		}
+	}
+	if !r.Start.Before(r.Stop) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication start must be before stop",
+		}
+	}
+	if r.SampleSize < 0 || r.SampleSize > MaxReplicationSampleSize {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication sample size is out of range",
+		}
+	}
+	return nil
+}
+
+// Divergence reasons of a SeriesDivergence.
+const (
+	// DivergenceMissing is the reason of a series of the source bucket without any point in the target bucket.
+	DivergenceMissing = "missing"
+	// DivergencePoints is the reason of a series with a different number of points in the target bucket.
+	DivergencePoints = "points"
+	// DivergenceValues is the reason of a series with as many points in both buckets, but different times or values.
+	DivergenceValues = "values"
+)
+
+// ReplicationReport is the result of the comparison of a source bucket with its target bucket.
+type ReplicationReport struct {
+	// SourceWatermark and TargetWatermark are the times of the latest points of the buckets in the window.
+	// They are zero if a bucket has no point in the window.
+	SourceWatermark time.Time `json:"sourceWatermark"`
+	TargetWatermark time.Time `json:"targetWatermark"`
+
+	// Lag is how far the target bucket is behind the source bucket. A target bucket
+	// without any point in the window is behind by the whole window up to SourceWatermark.
+	Lag time.Duration `json:"lag"`
+
+	// The sampled series are compared from the start of the window up to CompareStop, exclusive.
+	CompareStop   time.Time           `json:"compareStop"`
+	SampledSeries int                 `json:"sampledSeries"`
+	Divergences   []*SeriesDivergence `json:"divergences"`
+}
+
+// Consistent returns true if every sampled series is the same in both buckets.
+func (r *ReplicationReport) Consistent() bool {
+	return len(r.Divergences) == 0
+}
+
+// SeriesDivergence is a sampled series of which the target bucket does not have the same points as the source bucket.
+type SeriesDivergence struct {
+	Measurement  string            `json:"measurement"`
+	Field        string            `json:"field"`
+	Tags         map[string]string `json:"tags"`
+	Reason       string            `json:"reason"`
+	SourcePoints int64             `json:"sourcePoints"`
+	TargetPoints int64             `json:"targetPoints"`
+}
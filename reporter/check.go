@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/check"
+)
+
+var _ check.NamedChecker = (*Checker)(nil)
+
+// Checker fails when any expected reporter is missing,
+// so that missing reporters can be surfaced by the /health or /ready endpoints.
+type Checker struct {
+	Service influxdb.ExpectedReporterService
+	Monitor influxdb.ExpectedReporterMonitor
+
+	// Filter restricts the expected reporters that are checked.
+	Filter influxdb.ExpectedReporterFilter
+
+	// Authorization is used to query the buckets of the expected reporters.
+	Authorization *influxdb.Authorization
+}
+
+// CheckName returns the name of the check.
+func (c *Checker) CheckName() string {
+	return "expected-reporters"
+}
+
+// Check returns a failing response listing the missing reporters, if any.
+func (c *Checker) Check(ctx context.Context) check.Response {
+	ctx = pctx.SetAuthorizer(ctx, c.Authorization)
+
+	reporters, err := c.Service.FindExpectedReporters(ctx, c.Filter)
+	if err != nil {
+		return check.Response{Status: check.StatusFail, Message: err.Error()}
+	}
+
+	statuses, err := c.Monitor.ExpectedReporterStatuses(ctx, reporters)
+	if err != nil {
+		return check.Response{Status: check.StatusFail, Message: err.Error()}
+	}
+
+	var missing []string
+	for _, s := range statuses {
+		if s.Missing {
+			missing = append(missing, fmt.Sprintf("%s=%s in bucket %s", s.TagKey, s.TagValue, s.BucketID))
+		}
+	}
+	if len(missing) > 0 {
+		return check.Response{
+			Status:  check.StatusFail,
+			Message: fmt.Sprintf("missing reporters: %s", strings.Join(missing, ", ")),
+		}
+	}
+
+	return check.Response{Status: check.StatusPass}
+}
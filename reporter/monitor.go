@@ -0,0 +1,152 @@
+// Package reporter finds out whether the sources expected to write to buckets are still writing.
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+)
+
+// DefaultLookback is how far back the latest point of a reporter is looked for,
+// unless the interval of the reporter is longer.
+const DefaultLookback = 24 * time.Hour
+
+var _ influxdb.ExpectedReporterMonitor = (*Monitor)(nil)
+
+// Monitor queries the buckets of expected reporters for their latest point.
+type Monitor struct {
+	QueryService query.QueryService
+
+	// Lookback is how far back the latest point of a reporter is looked for.
+	// It defaults to DefaultLookback.
+	Lookback time.Duration
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewMonitor returns a Monitor querying through qs.
+func NewMonitor(qs query.QueryService) *Monitor {
+	return &Monitor{
+		QueryService: qs,
+		Lookback:     DefaultLookback,
+		Now:          time.Now,
+	}
+}
+
+// ExpectedReporterStatuses returns the current status of each of reporters, in the same order.
+// The queries are made with the authorizer on context.
+func (m *Monitor) ExpectedReporterStatuses(ctx context.Context, reporters []*influxdb.ExpectedReporter) ([]*influxdb.ExpectedReporterStatus, error) {
+	a, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if m.Now != nil {
+		now = m.Now()
+	}
+
+	statuses := make([]*influxdb.ExpectedReporterStatus, 0, len(reporters))
+	for _, r := range reporters {
+		var auth *influxdb.Authorization
+		switch a := a.(type) {
+		case *influxdb.Authorization:
+			auth = a
+		case *influxdb.Session:
+			auth = a.EphemeralAuth(r.OrgID)
+		default:
+			return nil, influxdb.ErrAuthorizerNotSupported
+		}
+
+		lastSeen, err := m.lastSeen(ctx, auth, r)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, &influxdb.ExpectedReporterStatus{
+			ExpectedReporter: *r,
+			LastSeen:         lastSeen,
+			Missing:          lastSeen == nil || now.Sub(*lastSeen) > r.Interval,
+		})
+	}
+	return statuses, nil
+}
+
+// lastSeen returns the time of the latest point of r, or nil if it has none within the lookback.
+func (m *Monitor) lastSeen(ctx context.Context, auth *influxdb.Authorization, r *influxdb.ExpectedReporter) (*time.Time, error) {
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: r.OrgID,
+		Compiler:       lang.FluxCompiler{Query: m.lastSeenQuery(r)},
+	}
+
+	itr, err := m.QueryService.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Release()
+
+	var lastSeen *time.Time
+	for itr.More() {
+		err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for j, col := range cr.Cols() {
+					if col.Label != "_time" || col.Type != flux.TTime {
+						continue
+					}
+					ts := cr.Times(j)
+					for i := 0; i < cr.Len(); i++ {
+						if ts.IsNull(i) {
+							continue
+						}
+						t := values.Time(ts.Value(i)).Time()
+						if lastSeen == nil || t.After(*lastSeen) {
+							lastSeen = &t
+						}
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, err
+	}
+
+	return lastSeen, nil
+}
+
+func (m *Monitor) lastSeenQuery(r *influxdb.ExpectedReporter) string {
+	lookback := m.Lookback
+	if lookback <= 0 {
+		lookback = DefaultLookback
+	}
+	if r.Interval > lookback {
+		lookback = r.Interval
+	}
+
+	predicates := []string{fmt.Sprintf("r[%q] == %q", r.TagKey, r.TagValue)}
+	if r.Measurement != "" {
+		predicates = append([]string{fmt.Sprintf("r._measurement == %q", r.Measurement)}, predicates...)
+	}
+
+	return fmt.Sprintf(`from(bucketID: %q)
+  |> range(start: -%ds)
+  |> filter(fn: (r) => %s)
+  |> keep(columns: ["_time"])
+  |> group()
+  |> max(column: "_time")`,
+		r.BucketID.String(), int64(lookback/time.Second), strings.Join(predicates, " and "))
+}
@@ -0,0 +1,186 @@
+package reporter_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/reporter"
+)
+
+var now = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func reporterFixtures() []*influxdb.ExpectedReporter {
+	return []*influxdb.ExpectedReporter{
+		{
+			ID:       1,
+			OrgID:    10,
+			BucketID: 100,
+			TagKey:   "host",
+			TagValue: "server01",
+			Interval: time.Minute,
+		},
+		{
+			ID:          2,
+			OrgID:       10,
+			BucketID:    100,
+			Measurement: "cpu",
+			TagKey:      "host",
+			TagValue:    "server02",
+			Interval:    time.Minute,
+		},
+		{
+			ID:       3,
+			OrgID:    10,
+			BucketID: 200,
+			TagKey:   "sensor",
+			TagValue: "s-42",
+			Interval: 48 * time.Hour,
+		},
+	}
+}
+
+// lastSeenQueryService answers the queries of a Monitor with the latest point of each tag value in lastSeen,
+// and records the queries it receives.
+func lastSeenQueryService(t *testing.T, lastSeen map[string]time.Time, queries *[]string) *querymock.QueryService {
+	return &querymock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.Authorization == nil || req.OrganizationID != 10 {
+				t.Fatalf("unexpected query request: %+v", req)
+			}
+			script := req.Compiler.(lang.FluxCompiler).Query
+			*queries = append(*queries, script)
+
+			var data [][]interface{}
+			for tagValue, ts := range lastSeen {
+				if strings.Contains(script, `"`+tagValue+`"`) {
+					data = append(data, []interface{}{execute.Time(ts.UnixNano())})
+				}
+			}
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					ColMeta: []flux.ColMeta{{Label: "_time", Type: flux.TTime}},
+					Data:    data,
+				}},
+			}}), nil
+		},
+	}
+}
+
+func TestMonitor_ExpectedReporterStatuses(t *testing.T) {
+	var queries []string
+	m := reporter.NewMonitor(lastSeenQueryService(t, map[string]time.Time{
+		"server01": now.Add(-30 * time.Second),
+		"server02": now.Add(-5 * time.Minute),
+	}, &queries))
+	m.Now = func() time.Time { return now }
+
+	ctx := pctx.SetAuthorizer(context.Background(), &influxdb.Session{UserID: 1})
+	statuses, err := m.ExpectedReporterStatuses(ctx, reporterFixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+	wantMissing := []bool{false, true, true}
+	for i, s := range statuses {
+		if s.ID != reporterFixtures()[i].ID {
+			t.Errorf("status %d: expected reporter %s, got %s", i, reporterFixtures()[i].ID, s.ID)
+		}
+		if s.Missing != wantMissing[i] {
+			t.Errorf("status %d: expected missing %v, got %v", i, wantMissing[i], s.Missing)
+		}
+	}
+	if statuses[0].LastSeen == nil || !statuses[0].LastSeen.Equal(now.Add(-30*time.Second)) {
+		t.Errorf("unexpected last seen time: %v", statuses[0].LastSeen)
+	}
+	if statuses[2].LastSeen != nil {
+		t.Errorf("expected no last seen time, got %v", statuses[2].LastSeen)
+	}
+
+	if !strings.Contains(queries[0], `from(bucketID: "0000000000000064")`) || !strings.Contains(queries[0], "range(start: -86400s)") {
+		t.Errorf("unexpected query: %s", queries[0])
+	}
+	if !strings.Contains(queries[1], `r._measurement == "cpu" and r["host"] == "server02"`) {
+		t.Errorf("unexpected query: %s", queries[1])
+	}
+	// The lookback is extended to the interval of the reporter.
+	if !strings.Contains(queries[2], "range(start: -172800s)") {
+		t.Errorf("unexpected query: %s", queries[2])
+	}
+}
+
+func TestMonitor_ExpectedReporterStatusesUnauthorized(t *testing.T) {
+	var queries []string
+	m := reporter.NewMonitor(lastSeenQueryService(t, nil, &queries))
+	if _, err := m.ExpectedReporterStatuses(context.Background(), reporterFixtures()); err == nil {
+		t.Fatal("expected error without an authorizer")
+	}
+	if len(queries) != 0 {
+		t.Errorf("expected no query, got %d", len(queries))
+	}
+}
+
+func TestChecker(t *testing.T) {
+	svc := mock.NewExpectedReporterService()
+	svc.FindExpectedReportersFn = func(ctx context.Context, filter influxdb.ExpectedReporterFilter) ([]*influxdb.ExpectedReporter, error) {
+		return reporterFixtures()[:2], nil
+	}
+
+	tests := []struct {
+		name       string
+		lastSeen   map[string]time.Time
+		wantStatus check.Status
+	}{
+		{
+			name: "all reporters seen",
+			lastSeen: map[string]time.Time{
+				"server01": now.Add(-30 * time.Second),
+				"server02": now.Add(-30 * time.Second),
+			},
+			wantStatus: check.StatusPass,
+		},
+		{
+			name: "missing reporter",
+			lastSeen: map[string]time.Time{
+				"server01": now.Add(-30 * time.Second),
+			},
+			wantStatus: check.StatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			m := reporter.NewMonitor(lastSeenQueryService(t, tt.lastSeen, &queries))
+			m.Now = func() time.Time { return now }
+
+			c := &reporter.Checker{
+				Service:       svc,
+				Monitor:       m,
+				Authorization: &influxdb.Authorization{ID: 1, OrgID: 10},
+			}
+			resp := c.Check(context.Background())
+			if resp.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s: %s", tt.wantStatus, resp.Status, resp.Message)
+			}
+			if tt.wantStatus == check.StatusFail && !strings.Contains(resp.Message, "host=server02") {
+				t.Errorf("unexpected message: %s", resp.Message)
+			}
+		})
+	}
+}
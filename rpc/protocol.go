@@ -0,0 +1,429 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/grpc"
+)
+
+// The messages and the services below are those of platform.proto, kept by hand like the ones of the flight package.
+
+// FindByIDRequest identifies the resource of a find or delete call.
+type FindByIDRequest struct {
+	ID uint64 `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *FindByIDRequest) Reset()         { *m = FindByIDRequest{} }
+func (m *FindByIDRequest) String() string { return proto.CompactTextString(m) }
+func (*FindByIDRequest) ProtoMessage()    {}
+
+// Bucket is a bucket of an organization.
+type Bucket struct {
+	ID                uint64 `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgID             uint64 `protobuf:"fixed64,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Org               string `protobuf:"bytes,3,opt,name=org,proto3" json:"org,omitempty"`
+	Name              string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	RetentionPeriodNs int64  `protobuf:"varint,5,opt,name=retention_period_ns,json=retentionPeriodNs,proto3" json:"retention_period_ns,omitempty"`
+	SchemaType        string `protobuf:"bytes,6,opt,name=schema_type,json=schemaType,proto3" json:"schema_type,omitempty"`
+}
+
+func (m *Bucket) Reset()         { *m = Bucket{} }
+func (m *Bucket) String() string { return proto.CompactTextString(m) }
+func (*Bucket) ProtoMessage()    {}
+
+// FindBucketsRequest filters the buckets of a FindBuckets call.
+type FindBucketsRequest struct {
+	OrgID uint64 `protobuf:"fixed64,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Org   string `protobuf:"bytes,2,opt,name=org,proto3" json:"org,omitempty"`
+	Name  string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *FindBucketsRequest) Reset()         { *m = FindBucketsRequest{} }
+func (m *FindBucketsRequest) String() string { return proto.CompactTextString(m) }
+func (*FindBucketsRequest) ProtoMessage()    {}
+
+// UpdateBucketRequest updates the fields of a bucket that are set.
+type UpdateBucketRequest struct {
+	ID                uint64                `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              *wrappers.StringValue `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	RetentionPeriodNs *wrappers.Int64Value  `protobuf:"bytes,3,opt,name=retention_period_ns,json=retentionPeriodNs,proto3" json:"retention_period_ns,omitempty"`
+}
+
+func (m *UpdateBucketRequest) Reset()         { *m = UpdateBucketRequest{} }
+func (m *UpdateBucketRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateBucketRequest) ProtoMessage()    {}
+
+// Organization is an organization.
+type Organization struct {
+	ID   uint64 `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *Organization) Reset()         { *m = Organization{} }
+func (m *Organization) String() string { return proto.CompactTextString(m) }
+func (*Organization) ProtoMessage()    {}
+
+// FindOrganizationsRequest filters the organizations of a FindOrganizations call.
+type FindOrganizationsRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *FindOrganizationsRequest) Reset()         { *m = FindOrganizationsRequest{} }
+func (m *FindOrganizationsRequest) String() string { return proto.CompactTextString(m) }
+func (*FindOrganizationsRequest) ProtoMessage()    {}
+
+// UpdateOrganizationRequest updates the fields of an organization that are set.
+type UpdateOrganizationRequest struct {
+	ID   uint64                `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name *wrappers.StringValue `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *UpdateOrganizationRequest) Reset()         { *m = UpdateOrganizationRequest{} }
+func (m *UpdateOrganizationRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateOrganizationRequest) ProtoMessage()    {}
+
+// Task is a task of an organization.
+type Task struct {
+	ID              uint64            `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgID           uint64            `protobuf:"fixed64,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Org             string            `protobuf:"bytes,3,opt,name=org,proto3" json:"org,omitempty"`
+	AuthorizationID uint64            `protobuf:"fixed64,4,opt,name=authorization_id,json=authorizationId,proto3" json:"authorization_id,omitempty"`
+	Name            string            `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Status          string            `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Flux            string            `protobuf:"bytes,7,opt,name=flux,proto3" json:"flux,omitempty"`
+	Every           string            `protobuf:"bytes,8,opt,name=every,proto3" json:"every,omitempty"`
+	Cron            string            `protobuf:"bytes,9,opt,name=cron,proto3" json:"cron,omitempty"`
+	Offset          string            `protobuf:"bytes,10,opt,name=offset,proto3" json:"offset,omitempty"`
+	LatestCompleted string            `protobuf:"bytes,11,opt,name=latest_completed,json=latestCompleted,proto3" json:"latest_completed,omitempty"`
+	CreatedAt       string            `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string            `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Params          map[string]string `protobuf:"bytes,14,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Task) Reset()         { *m = Task{} }
+func (m *Task) String() string { return proto.CompactTextString(m) }
+func (*Task) ProtoMessage()    {}
+
+// FindTasksRequest filters the tasks of a FindTasks call.
+type FindTasksRequest struct {
+	OrgID  uint64 `protobuf:"fixed64,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Org    string `protobuf:"bytes,2,opt,name=org,proto3" json:"org,omitempty"`
+	UserID uint64 `protobuf:"fixed64,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	After  uint64 `protobuf:"fixed64,4,opt,name=after,proto3" json:"after,omitempty"`
+	Limit  int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *FindTasksRequest) Reset()         { *m = FindTasksRequest{} }
+func (m *FindTasksRequest) String() string { return proto.CompactTextString(m) }
+func (*FindTasksRequest) ProtoMessage()    {}
+
+// CreateTaskRequest is the task of a CreateTask call.
+type CreateTaskRequest struct {
+	Flux   string            `protobuf:"bytes,1,opt,name=flux,proto3" json:"flux,omitempty"`
+	Status string            `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	OrgID  uint64            `protobuf:"fixed64,3,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Org    string            `protobuf:"bytes,4,opt,name=org,proto3" json:"org,omitempty"`
+	Token  string            `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	Params map[string]string `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *CreateTaskRequest) Reset()         { *m = CreateTaskRequest{} }
+func (m *CreateTaskRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateTaskRequest) ProtoMessage()    {}
+
+// UpdateTaskRequest updates the fields of a task that are set.
+type UpdateTaskRequest struct {
+	ID     uint64                `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	Flux   *wrappers.StringValue `protobuf:"bytes,2,opt,name=flux,proto3" json:"flux,omitempty"`
+	Status *wrappers.StringValue `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Token  string                `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (m *UpdateTaskRequest) Reset()         { *m = UpdateTaskRequest{} }
+func (m *UpdateTaskRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateTaskRequest) ProtoMessage()    {}
+
+// WriteRequest is line protocol written to a bucket.
+type WriteRequest struct {
+	Org       string `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	Bucket    string `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Precision string `protobuf:"bytes,3,opt,name=precision,proto3" json:"precision,omitempty"`
+	Data      []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *WriteRequest) Reset()         { *m = WriteRequest{} }
+func (m *WriteRequest) String() string { return proto.CompactTextString(m) }
+func (*WriteRequest) ProtoMessage()    {}
+
+// WriteResponse reports the field values written by a Write call and the lines it rejected.
+type WriteResponse struct {
+	Accepted int64           `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected []*RejectedLine `protobuf:"bytes,2,rep,name=rejected,proto3" json:"rejected,omitempty"`
+}
+
+func (m *WriteResponse) Reset()         { *m = WriteResponse{} }
+func (m *WriteResponse) String() string { return proto.CompactTextString(m) }
+func (*WriteResponse) ProtoMessage()    {}
+
+// RejectedLine is a line of a write request that was not written.
+type RejectedLine struct {
+	Request int64  `protobuf:"varint,1,opt,name=request,proto3" json:"request,omitempty"`
+	Line    int64  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Error   string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *RejectedLine) Reset()         { *m = RejectedLine{} }
+func (m *RejectedLine) String() string { return proto.CompactTextString(m) }
+func (*RejectedLine) ProtoMessage()    {}
+
+// BucketServiceServer is the server of the bucket service.
+type BucketServiceServer interface {
+	FindBucketByID(context.Context, *FindByIDRequest) (*Bucket, error)
+	FindBuckets(*FindBucketsRequest, BucketService_FindBucketsServer) error
+	CreateBucket(context.Context, *Bucket) (*Bucket, error)
+	UpdateBucket(context.Context, *UpdateBucketRequest) (*Bucket, error)
+	DeleteBucket(context.Context, *FindByIDRequest) (*empty.Empty, error)
+}
+
+// BucketService_FindBucketsServer is the stream of the buckets of a FindBuckets call.
+type BucketService_FindBucketsServer interface {
+	Send(*Bucket) error
+	grpc.ServerStream
+}
+
+// OrganizationServiceServer is the server of the organization service.
+type OrganizationServiceServer interface {
+	FindOrganizationByID(context.Context, *FindByIDRequest) (*Organization, error)
+	FindOrganizations(*FindOrganizationsRequest, OrganizationService_FindOrganizationsServer) error
+	CreateOrganization(context.Context, *Organization) (*Organization, error)
+	UpdateOrganization(context.Context, *UpdateOrganizationRequest) (*Organization, error)
+	DeleteOrganization(context.Context, *FindByIDRequest) (*empty.Empty, error)
+}
+
+// OrganizationService_FindOrganizationsServer is the stream of the organizations of a FindOrganizations call.
+type OrganizationService_FindOrganizationsServer interface {
+	Send(*Organization) error
+	grpc.ServerStream
+}
+
+// TaskServiceServer is the server of the task service.
+type TaskServiceServer interface {
+	FindTaskByID(context.Context, *FindByIDRequest) (*Task, error)
+	FindTasks(*FindTasksRequest, TaskService_FindTasksServer) error
+	CreateTask(context.Context, *CreateTaskRequest) (*Task, error)
+	UpdateTask(context.Context, *UpdateTaskRequest) (*Task, error)
+	DeleteTask(context.Context, *FindByIDRequest) (*empty.Empty, error)
+}
+
+// TaskService_FindTasksServer is the stream of the tasks of a FindTasks call.
+type TaskService_FindTasksServer interface {
+	Send(*Task) error
+	grpc.ServerStream
+}
+
+// WriteServiceServer is the server of the write service.
+type WriteServiceServer interface {
+	Write(WriteService_WriteServer) error
+}
+
+// WriteService_WriteServer is the stream of the requests of a Write call.
+type WriteService_WriteServer interface {
+	Recv() (*WriteRequest, error)
+	SendAndClose(*WriteResponse) error
+	grpc.ServerStream
+}
+
+type bucketsServer struct{ grpc.ServerStream }
+
+func (x bucketsServer) Send(m *Bucket) error { return x.ServerStream.SendMsg(m) }
+
+type organizationsServer struct{ grpc.ServerStream }
+
+func (x organizationsServer) Send(m *Organization) error { return x.ServerStream.SendMsg(m) }
+
+type tasksServer struct{ grpc.ServerStream }
+
+func (x tasksServer) Send(m *Task) error { return x.ServerStream.SendMsg(m) }
+
+type writeServer struct{ grpc.ServerStream }
+
+func (x writeServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x writeServer) SendAndClose(m *WriteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// unaryMethod returns the description of the method name of a service, decoding its request with newReq
+// and calling call with it.
+func unaryMethod(service, name string, newReq func() interface{}, call func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := newReq()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv, ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + service + "/" + name,
+			}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv, ctx, req)
+			})
+		},
+	}
+}
+
+const (
+	bucketServiceName       = "influxdata.platform.BucketService"
+	organizationServiceName = "influxdata.platform.OrganizationService"
+	taskServiceName         = "influxdata.platform.TaskService"
+	writeServiceName        = "influxdata.platform.WriteService"
+)
+
+// RegisterBucketServiceServer registers srv as the bucket service of s.
+func RegisterBucketServiceServer(s *grpc.Server, srv BucketServiceServer) {
+	s.RegisterService(&bucketServiceDesc, srv)
+}
+
+var bucketServiceDesc = grpc.ServiceDesc{
+	ServiceName: bucketServiceName,
+	HandlerType: (*BucketServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(bucketServiceName, "FindBucketByID", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(BucketServiceServer).FindBucketByID(ctx, req.(*FindByIDRequest))
+		}),
+		unaryMethod(bucketServiceName, "CreateBucket", func() interface{} { return new(Bucket) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(BucketServiceServer).CreateBucket(ctx, req.(*Bucket))
+		}),
+		unaryMethod(bucketServiceName, "UpdateBucket", func() interface{} { return new(UpdateBucketRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(BucketServiceServer).UpdateBucket(ctx, req.(*UpdateBucketRequest))
+		}),
+		unaryMethod(bucketServiceName, "DeleteBucket", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(BucketServiceServer).DeleteBucket(ctx, req.(*FindByIDRequest))
+		}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "FindBuckets",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(FindBucketsRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(BucketServiceServer).FindBuckets(m, bucketsServer{stream})
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "platform.proto",
+}
+
+// RegisterOrganizationServiceServer registers srv as the organization service of s.
+func RegisterOrganizationServiceServer(s *grpc.Server, srv OrganizationServiceServer) {
+	s.RegisterService(&organizationServiceDesc, srv)
+}
+
+var organizationServiceDesc = grpc.ServiceDesc{
+	ServiceName: organizationServiceName,
+	HandlerType: (*OrganizationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(organizationServiceName, "FindOrganizationByID", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(OrganizationServiceServer).FindOrganizationByID(ctx, req.(*FindByIDRequest))
+		}),
+		unaryMethod(organizationServiceName, "CreateOrganization", func() interface{} { return new(Organization) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(OrganizationServiceServer).CreateOrganization(ctx, req.(*Organization))
+		}),
+		unaryMethod(organizationServiceName, "UpdateOrganization", func() interface{} { return new(UpdateOrganizationRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(OrganizationServiceServer).UpdateOrganization(ctx, req.(*UpdateOrganizationRequest))
+		}),
+		unaryMethod(organizationServiceName, "DeleteOrganization", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(OrganizationServiceServer).DeleteOrganization(ctx, req.(*FindByIDRequest))
+		}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "FindOrganizations",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(FindOrganizationsRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(OrganizationServiceServer).FindOrganizations(m, organizationsServer{stream})
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "platform.proto",
+}
+
+// RegisterTaskServiceServer registers srv as the task service of s.
+func RegisterTaskServiceServer(s *grpc.Server, srv TaskServiceServer) {
+	s.RegisterService(&taskServiceDesc, srv)
+}
+
+var taskServiceDesc = grpc.ServiceDesc{
+	ServiceName: taskServiceName,
+	HandlerType: (*TaskServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(taskServiceName, "FindTaskByID", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(TaskServiceServer).FindTaskByID(ctx, req.(*FindByIDRequest))
+		}),
+		unaryMethod(taskServiceName, "CreateTask", func() interface{} { return new(CreateTaskRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(TaskServiceServer).CreateTask(ctx, req.(*CreateTaskRequest))
+		}),
+		unaryMethod(taskServiceName, "UpdateTask", func() interface{} { return new(UpdateTaskRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(TaskServiceServer).UpdateTask(ctx, req.(*UpdateTaskRequest))
+		}),
+		unaryMethod(taskServiceName, "DeleteTask", func() interface{} { return new(FindByIDRequest) }, func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(TaskServiceServer).DeleteTask(ctx, req.(*FindByIDRequest))
+		}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "FindTasks",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(FindTasksRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(TaskServiceServer).FindTasks(m, tasksServer{stream})
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "platform.proto",
+}
+
+// RegisterWriteServiceServer registers srv as the write service of s.
+func RegisterWriteServiceServer(s *grpc.Server, srv WriteServiceServer) {
+	s.RegisterService(&writeServiceDesc, srv)
+}
+
+var writeServiceDesc = grpc.ServiceDesc{
+	ServiceName: writeServiceName,
+	HandlerType: (*WriteServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "Write",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				return srv.(WriteServiceServer).Write(writeServer{stream})
+			},
+			ClientStreams: true,
+		},
+	},
+	Metadata: "platform.proto",
+}
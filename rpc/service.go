@@ -0,0 +1,679 @@
+// Package rpc serves the bucket, organization and task services, and writes, over gRPC,
+// for internal tooling and sidecars that want typed and streaming access instead of HTTP and JSON.
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	pcontext "github.com/influxdata/influxdb/context"
+	kitgrpc "github.com/influxdata/influxdb/kit/grpc"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	_ BucketServiceServer       = (*Service)(nil)
+	_ OrganizationServiceServer = (*Service)(nil)
+	_ TaskServiceServer         = (*Service)(nil)
+	_ WriteServiceServer        = (*Service)(nil)
+)
+
+// Service serves the services of platform.proto over gRPC. Every call is authorized by the token
+// of its authorization metadata, whose permissions are checked as they are by the HTTP API.
+type Service struct {
+	AuthorizationService platform.AuthorizationService
+	BucketService        platform.BucketService
+	OrganizationService  platform.OrganizationService
+	// TaskService checks the permissions of the calls itself, as the task service of the HTTP API does.
+	TaskService  platform.TaskService
+	PointsWriter storage.PointsWriter
+
+	// MeasurementSchemaService, if set, checks the points written to explicit-schema buckets against their schemas.
+	MeasurementSchemaService platform.MeasurementSchemaService
+	// WriteLimiter, if set, limits the rate of the writes of every token and organization, as over HTTP.
+	WriteLimiter *write.Limiter
+
+	// TLSConfig, if not nil, serves gRPC over TLS.
+	TLSConfig *tls.Config
+
+	Logger *zap.Logger
+
+	mu       sync.Mutex
+	server   *grpc.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewService returns a Service of the services given.
+func NewService(auths platform.AuthorizationService, buckets platform.BucketService, orgs platform.OrganizationService, tasks platform.TaskService, pw storage.PointsWriter, logger *zap.Logger) *Service {
+	return &Service{
+		AuthorizationService: auths,
+		BucketService:        buckets,
+		OrganizationService:  orgs,
+		TaskService:          tasks,
+		PointsWriter:         pw,
+		Logger:               logger,
+	}
+}
+
+// Open listens on addr and serves the gRPC calls until Close is called.
+func (s *Service) Open(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.Serve(ln)
+	return nil
+}
+
+// Serve serves the gRPC calls of ln until Close is called.
+func (s *Service) Serve(ln net.Listener) {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.authorizeUnary),
+		grpc.StreamInterceptor(s.authorizeStream),
+	}
+	if s.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLSConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	RegisterBucketServiceServer(server, s)
+	RegisterOrganizationServiceServer(server, s)
+	RegisterTaskServiceServer(server, s)
+	RegisterWriteServiceServer(server, s)
+
+	s.mu.Lock()
+	s.server = server
+	s.listener = ln
+	s.mu.Unlock()
+
+	s.Logger.Info("Listening for gRPC calls", zap.String("addr", ln.Addr().String()))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := server.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+			s.Logger.Error("Failed to serve gRPC calls", zap.Error(err))
+		}
+	}()
+}
+
+// Addr returns the address the service listens on, or nil if it does not listen.
+func (s *Service) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops listening, cancels the calls and waits for them to be done.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	server.Stop()
+	s.wg.Wait()
+	return nil
+}
+
+// authorizeUnary is the interceptor of the unary calls, setting the authorization of their token on their context.
+func (s *Service) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	a, err := s.authorize(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res, err := handler(pcontext.SetAuthorizer(ctx, a), req)
+	return res, toStatus(err)
+}
+
+// authorizeStream is the interceptor of the streaming calls, setting the authorization of their token on their context.
+func (s *Service) authorizeStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	a, err := s.authorize(stream.Context())
+	if err != nil {
+		return toStatus(err)
+	}
+	return toStatus(handler(srv, &authorizedStream{
+		ServerStream: stream,
+		ctx:          pcontext.SetAuthorizer(stream.Context(), a),
+	}))
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize returns the authorization of the token of the authorization metadata of the call,
+// sent as "Token <token>" or "Bearer <token>".
+func (s *Service) authorize(ctx context.Context) (*platform.Authorization, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		for _, scheme := range []string{"Token ", "Bearer "} {
+			if strings.HasPrefix(v, scheme) {
+				token = strings.TrimPrefix(v, scheme)
+			}
+		}
+	}
+	if token == "" {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "missing authorization token",
+		}
+	}
+
+	a, err := s.AuthorizationService.FindAuthorizationByToken(ctx, token)
+	if err != nil {
+		if platform.ErrorCode(err) == platform.ENotFound {
+			return nil, &platform.Error{
+				Code: platform.EUnauthorized,
+				Msg:  "invalid authorization token",
+			}
+		}
+		return nil, err
+	}
+	if !a.IsActive() {
+		return nil, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  "authorization token is inactive",
+		}
+	}
+	return a, nil
+}
+
+// FindBucketByID returns a bucket the token can read.
+func (s *Service) FindBucketByID(ctx context.Context, req *FindByIDRequest) (*Bucket, error) {
+	b, err := authorizer.NewBucketService(s.BucketService).FindBucketByID(ctx, platform.ID(req.ID))
+	if err != nil {
+		return nil, err
+	}
+	return newBucket(b), nil
+}
+
+// FindBuckets streams the buckets of the filter of req that the token can read.
+func (s *Service) FindBuckets(req *FindBucketsRequest, stream BucketService_FindBucketsServer) error {
+	var filter platform.BucketFilter
+	if req.OrgID != 0 {
+		id := platform.ID(req.OrgID)
+		filter.OrganizationID = &id
+	}
+	if req.Org != "" {
+		filter.Organization = &req.Org
+	}
+	if req.Name != "" {
+		filter.Name = &req.Name
+	}
+	bs, _, err := authorizer.NewBucketService(s.BucketService).FindBuckets(stream.Context(), filter)
+	if err != nil {
+		return err
+	}
+	for _, b := range bs {
+		if err := stream.Send(newBucket(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateBucket creates a bucket.
+func (s *Service) CreateBucket(ctx context.Context, req *Bucket) (*Bucket, error) {
+	b := &platform.Bucket{
+		OrganizationID:  platform.ID(req.OrgID),
+		Organization:    req.Org,
+		Name:            req.Name,
+		RetentionPeriod: time.Duration(req.RetentionPeriodNs),
+		SchemaType:      platform.BucketSchemaType(req.SchemaType),
+	}
+	if !b.OrganizationID.Valid() && b.Organization != "" {
+		org, err := s.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &b.Organization})
+		if err != nil {
+			return nil, err
+		}
+		b.OrganizationID = org.ID
+	}
+	if err := authorizer.NewBucketService(s.BucketService).CreateBucket(ctx, b); err != nil {
+		return nil, err
+	}
+	return newBucket(b), nil
+}
+
+// UpdateBucket updates the fields of a bucket set by req.
+func (s *Service) UpdateBucket(ctx context.Context, req *UpdateBucketRequest) (*Bucket, error) {
+	var upd platform.BucketUpdate
+	if req.Name != nil {
+		upd.Name = &req.Name.Value
+	}
+	if req.RetentionPeriodNs != nil {
+		rp := time.Duration(req.RetentionPeriodNs.Value)
+		upd.RetentionPeriod = &rp
+	}
+	b, err := authorizer.NewBucketService(s.BucketService).UpdateBucket(ctx, platform.ID(req.ID), upd)
+	if err != nil {
+		return nil, err
+	}
+	return newBucket(b), nil
+}
+
+// DeleteBucket deletes a bucket.
+func (s *Service) DeleteBucket(ctx context.Context, req *FindByIDRequest) (*empty.Empty, error) {
+	if err := authorizer.NewBucketService(s.BucketService).DeleteBucket(ctx, platform.ID(req.ID)); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func newBucket(b *platform.Bucket) *Bucket {
+	return &Bucket{
+		ID:                uint64(b.ID),
+		OrgID:             uint64(b.OrganizationID),
+		Org:               b.Organization,
+		Name:              b.Name,
+		RetentionPeriodNs: int64(b.RetentionPeriod),
+		SchemaType:        string(b.SchemaType),
+	}
+}
+
+// FindOrganizationByID returns an organization the token can read.
+func (s *Service) FindOrganizationByID(ctx context.Context, req *FindByIDRequest) (*Organization, error) {
+	o, err := authorizer.NewOrgService(s.OrganizationService).FindOrganizationByID(ctx, platform.ID(req.ID))
+	if err != nil {
+		return nil, err
+	}
+	return newOrganization(o), nil
+}
+
+// FindOrganizations streams the organizations of the filter of req that the token can read.
+func (s *Service) FindOrganizations(req *FindOrganizationsRequest, stream OrganizationService_FindOrganizationsServer) error {
+	var filter platform.OrganizationFilter
+	if req.Name != "" {
+		filter.Name = &req.Name
+	}
+	os, _, err := authorizer.NewOrgService(s.OrganizationService).FindOrganizations(stream.Context(), filter)
+	if err != nil {
+		return err
+	}
+	for _, o := range os {
+		if err := stream.Send(newOrganization(o)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateOrganization creates an organization.
+func (s *Service) CreateOrganization(ctx context.Context, req *Organization) (*Organization, error) {
+	o := &platform.Organization{Name: req.Name}
+	if err := authorizer.NewOrgService(s.OrganizationService).CreateOrganization(ctx, o); err != nil {
+		return nil, err
+	}
+	return newOrganization(o), nil
+}
+
+// UpdateOrganization updates the fields of an organization set by req.
+func (s *Service) UpdateOrganization(ctx context.Context, req *UpdateOrganizationRequest) (*Organization, error) {
+	var upd platform.OrganizationUpdate
+	if req.Name != nil {
+		upd.Name = &req.Name.Value
+	}
+	o, err := authorizer.NewOrgService(s.OrganizationService).UpdateOrganization(ctx, platform.ID(req.ID), upd)
+	if err != nil {
+		return nil, err
+	}
+	return newOrganization(o), nil
+}
+
+// DeleteOrganization deletes an organization.
+func (s *Service) DeleteOrganization(ctx context.Context, req *FindByIDRequest) (*empty.Empty, error) {
+	if err := authorizer.NewOrgService(s.OrganizationService).DeleteOrganization(ctx, platform.ID(req.ID)); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func newOrganization(o *platform.Organization) *Organization {
+	return &Organization{
+		ID:   uint64(o.ID),
+		Name: o.Name,
+	}
+}
+
+// FindTaskByID returns a task the token can read.
+func (s *Service) FindTaskByID(ctx context.Context, req *FindByIDRequest) (*Task, error) {
+	t, err := s.TaskService.FindTaskByID(ctx, platform.ID(req.ID))
+	if err != nil {
+		return nil, err
+	}
+	return newTask(t), nil
+}
+
+// FindTasks streams the tasks of the filter of req that the token can read.
+func (s *Service) FindTasks(req *FindTasksRequest, stream TaskService_FindTasksServer) error {
+	filter := platform.TaskFilter{
+		Organization: req.Org,
+		Limit:        int(req.Limit),
+	}
+	if req.OrgID != 0 {
+		id := platform.ID(req.OrgID)
+		filter.OrganizationID = &id
+	}
+	if req.UserID != 0 {
+		id := platform.ID(req.UserID)
+		filter.User = &id
+	}
+	if req.After != 0 {
+		id := platform.ID(req.After)
+		filter.After = &id
+	}
+	ts, _, err := s.TaskService.FindTasks(stream.Context(), filter)
+	if err != nil {
+		return err
+	}
+	for _, t := range ts {
+		if err := stream.Send(newTask(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTask creates a task in the organization of req, or in the organization of the token if it has none.
+func (s *Service) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	tc := platform.TaskCreate{
+		Flux:           req.Flux,
+		Status:         req.Status,
+		OrganizationID: platform.ID(req.OrgID),
+		Organization:   req.Org,
+		Token:          req.Token,
+		Params:         req.Params,
+	}
+	switch {
+	case tc.OrganizationID.Valid():
+	case tc.Organization != "":
+		org, err := s.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &tc.Organization})
+		if err != nil {
+			return nil, err
+		}
+		tc.OrganizationID = org.ID
+	default:
+		a, err := pcontext.GetAuthorizer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if auth, ok := a.(*platform.Authorization); ok {
+			tc.OrganizationID = auth.OrgID
+		}
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Err:  err,
+		}
+	}
+
+	t, err := s.TaskService.CreateTask(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(t), nil
+}
+
+// UpdateTask updates the fields of a task set by req.
+func (s *Service) UpdateTask(ctx context.Context, req *UpdateTaskRequest) (*Task, error) {
+	upd := platform.TaskUpdate{Token: req.Token}
+	if req.Flux != nil {
+		upd.Flux = &req.Flux.Value
+	}
+	if req.Status != nil {
+		upd.Status = &req.Status.Value
+	}
+	t, err := s.TaskService.UpdateTask(ctx, platform.ID(req.ID), upd)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(t), nil
+}
+
+// DeleteTask deletes a task.
+func (s *Service) DeleteTask(ctx context.Context, req *FindByIDRequest) (*empty.Empty, error) {
+	if err := s.TaskService.DeleteTask(ctx, platform.ID(req.ID)); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func newTask(t *platform.Task) *Task {
+	return &Task{
+		ID:              uint64(t.ID),
+		OrgID:           uint64(t.OrganizationID),
+		Org:             t.Organization,
+		AuthorizationID: uint64(t.AuthorizationID),
+		Name:            t.Name,
+		Status:          t.Status,
+		Flux:            t.Flux,
+		Every:           t.Every,
+		Cron:            t.Cron,
+		Offset:          t.Offset,
+		LatestCompleted: t.LatestCompleted,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+		Params:          t.Params,
+	}
+}
+
+// Write writes the line protocol of the requests of the stream as they are received.
+// The bucket of a request is looked up again only when it is not the one of the previous request.
+// A request exceeding the write limits ends the stream with a ResourceExhausted error, once the
+// requests before it are written.
+func (s *Service) Write(stream WriteService_WriteServer) error {
+	ctx := stream.Context()
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	res := &WriteResponse{}
+	var org *platform.Organization
+	var bucket *platform.Bucket
+	var orgRef, bucketRef string
+	for i := int64(0); ; i++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+
+		if bucket == nil || req.Org != orgRef || req.Bucket != bucketRef {
+			if org, bucket, err = s.findWriteBucket(ctx, a, req.Org, req.Bucket); err != nil {
+				return err
+			}
+			orgRef, bucketRef = req.Org, req.Bucket
+		}
+		if err := s.write(ctx, a, org, bucket, i, req, res); err != nil {
+			return err
+		}
+	}
+}
+
+// findWriteBucket returns the organization and the bucket named or identified by orgRef and bucketRef,
+// if a can write to the bucket.
+func (s *Service) findWriteBucket(ctx context.Context, a platform.Authorizer, orgRef, bucketRef string) (*platform.Organization, *platform.Bucket, error) {
+	if orgRef == "" || bucketRef == "" {
+		return nil, nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "write request has no organization or bucket",
+		}
+	}
+
+	var org *platform.Organization
+	if id, err := platform.IDFromString(orgRef); err == nil {
+		if org, err = s.OrganizationService.FindOrganizationByID(ctx, *id); err != nil && platform.ErrorCode(err) != platform.ENotFound {
+			return nil, nil, err
+		}
+	}
+	if org == nil {
+		o, err := s.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &orgRef})
+		if err != nil {
+			return nil, nil, err
+		}
+		org = o
+	}
+
+	var bucket *platform.Bucket
+	if id, err := platform.IDFromString(bucketRef); err == nil {
+		b, err := s.BucketService.FindBucket(ctx, platform.BucketFilter{OrganizationID: &org.ID, ID: id})
+		if err != nil && platform.ErrorCode(err) != platform.ENotFound {
+			return nil, nil, err
+		}
+		bucket = b
+	}
+	if bucket == nil {
+		b, err := s.BucketService.FindBucket(ctx, platform.BucketFilter{OrganizationID: &org.ID, Name: &bucketRef})
+		if err != nil {
+			return nil, nil, err
+		}
+		bucket = b
+	}
+
+	p, err := platform.NewPermissionAtID(bucket.ID, platform.WriteAction, platform.BucketsResourceType, org.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !a.Allowed(*p) {
+		return nil, nil, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  "insufficient permissions for write",
+		}
+	}
+	return org, bucket, nil
+}
+
+// write writes the points of req, the request n of the stream, and adds the field values written to res.
+// The lines that cannot be parsed, or do not conform to the schema of the bucket, are rejected and the others written.
+func (s *Service) write(ctx context.Context, a platform.Authorizer, org *platform.Organization, bucket *platform.Bucket, n int64, req *WriteRequest, res *WriteResponse) error {
+	precision := req.Precision
+	if precision == "" {
+		precision = "ns"
+	}
+	if !models.ValidPrecision(precision) {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid precision; valid precision units are ns, us, ms, and s",
+		}
+	}
+
+	points, lines, err := models.ParsePointsWithLines(req.Data, time.Now(), precision)
+	if err != nil {
+		lineErrs, ok := err.(models.LineErrors)
+		if !ok {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("unable to parse points: %v", err),
+				Err:  err,
+			}
+		}
+		for _, le := range lineErrs {
+			res.Rejected = append(res.Rejected, &RejectedLine{Request: n, Line: int64(le.Line), Error: le.Error()})
+		}
+	}
+
+	if s.WriteLimiter != nil {
+		if err := s.WriteLimiter.Allow(org.ID, a.Identifier(), len(points), len(req.Data)); err != nil {
+			if _, ok := err.(*write.LimitExceededError); !ok {
+				return err
+			}
+			return &platform.Error{
+				Code: platform.ETooManyRequests,
+				Msg:  err.Error(),
+			}
+		}
+	}
+
+	if s.MeasurementSchemaService != nil {
+		accepted, rejected, err := write.CheckSchema(ctx, s.MeasurementSchemaService, bucket, points)
+		if err != nil {
+			return err
+		}
+		for _, rp := range rejected {
+			res.Rejected = append(res.Rejected, &RejectedLine{Request: n, Line: int64(lines[rp.Index]), Error: rp.Err.Error()})
+		}
+		points = accepted
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
+	if err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
+			Err:  err,
+		}
+	}
+	accepted := int64(len(exploded))
+	if err := s.PointsWriter.WritePoints(ctx, exploded); err != nil {
+		pwe, ok := err.(tsdb.PartialWriteError)
+		if !ok {
+			s.Logger.Error("Error writing points", zap.Error(err))
+			return &platform.Error{
+				Code: platform.EInternal,
+				Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+				Err:  err,
+			}
+		}
+		accepted -= int64(pwe.Dropped)
+	}
+	res.Accepted += accepted
+	return nil
+}
+
+// toStatus returns err as the error of a gRPC status. The errors that are gRPC statuses already are returned as is.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	perr, ok := err.(*platform.Error)
+	if !ok {
+		perr = &platform.Error{
+			Code: platform.EInternal,
+			Err:  err,
+		}
+	}
+	st, serr := kitgrpc.ToStatus(perr)
+	if serr != nil {
+		return serr
+	}
+	return st.Err()
+}
@@ -0,0 +1,320 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/rpc"
+	"github.com/influxdata/influxdb/write"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestService(t *testing.T, buckets *mock.BucketService, tasks *mock.TaskService, pw *mock.PointsWriter) (*rpc.Service, *grpc.ClientConn) {
+	t.Helper()
+	orgID := platform.ID(1)
+	auth := &platform.Authorization{
+		ID:     2,
+		OrgID:  orgID,
+		Status: platform.Active,
+		Permissions: []platform.Permission{
+			{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+			{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+		},
+	}
+	auths := mock.NewAuthorizationService()
+	auths.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*platform.Authorization, error) {
+		if token != "tok" {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "authorization not found"}
+		}
+		return auth, nil
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+		if filter.Name != nil && *filter.Name == "org" {
+			return &platform.Organization{ID: orgID, Name: "org"}, nil
+		}
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+	}
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+	}
+
+	s := rpc.NewService(auths, buckets, orgs, tasks, pw, zaptest.NewLogger(t))
+	if err := s.Open("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	cc, err := grpc.Dial(s.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, cc
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Token "+token)
+}
+
+func TestService_Buckets(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketsFn = func(ctx context.Context, filter platform.BucketFilter, opts ...platform.FindOptions) ([]*platform.Bucket, int, error) {
+		return []*platform.Bucket{
+			{ID: 10, OrganizationID: 1, Name: "a"},
+			{ID: 11, OrganizationID: 1, Name: "b"},
+			{ID: 12, OrganizationID: 3, Name: "c"},
+		}, 3, nil
+	}
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		if id == 12 {
+			return &platform.Bucket{ID: id, OrganizationID: 3, Name: "c"}, nil
+		}
+		return &platform.Bucket{ID: id, OrganizationID: 1, Name: "a"}, nil
+	}
+	var created *platform.Bucket
+	buckets.CreateBucketFn = func(ctx context.Context, b *platform.Bucket) error {
+		b.ID = 13
+		created = b
+		return nil
+	}
+	var updated platform.BucketUpdate
+	buckets.UpdateBucketFn = func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+		updated = upd
+		return &platform.Bucket{ID: id, OrganizationID: 1, Name: *upd.Name}, nil
+	}
+
+	s, cc := newTestService(t, buckets, &mock.TaskService{}, &mock.PointsWriter{})
+	defer s.Close()
+	defer cc.Close()
+	ctx := withToken(context.Background(), "tok")
+
+	// Only the buckets the token can read are streamed.
+	stream, err := cc.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/influxdata.platform.BucketService/FindBuckets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&rpc.FindBucketsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for {
+		b := new(rpc.Bucket)
+		if err := stream.RecvMsg(b); err != nil {
+			break
+		}
+		names = append(names, b.Name)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("unexpected buckets %v", names)
+	}
+
+	if err := cc.Invoke(ctx, "/influxdata.platform.BucketService/FindBucketByID", &rpc.FindByIDRequest{ID: 12}, new(rpc.Bucket)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("unexpected error finding a bucket of another organization: %v", err)
+	}
+
+	var b rpc.Bucket
+	if err := cc.Invoke(ctx, "/influxdata.platform.BucketService/CreateBucket", &rpc.Bucket{Org: "org", Name: "d", RetentionPeriodNs: 3600e9}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != 13 || b.OrgID != 1 || created.RetentionPeriod.Hours() != 1 {
+		t.Errorf("unexpected bucket created %+v", b)
+	}
+
+	if err := cc.Invoke(ctx, "/influxdata.platform.BucketService/UpdateBucket", &rpc.UpdateBucketRequest{ID: 10, Name: &wrappers.StringValue{Value: "e"}}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Name != "e" || updated.RetentionPeriod != nil {
+		t.Errorf("unexpected update %+v of bucket %+v", updated, b)
+	}
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{name: "missing token", ctx: context.Background(), code: codes.Unauthenticated},
+		{name: "invalid token", ctx: withToken(context.Background(), "nope"), code: codes.Unauthenticated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cc.Invoke(tt.ctx, "/influxdata.platform.BucketService/FindBucketByID", &rpc.FindByIDRequest{ID: 10}, new(rpc.Bucket))
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("unexpected code %v, want %v: %v", code, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestService_CreateTask(t *testing.T) {
+	var got platform.TaskCreate
+	tasks := &mock.TaskService{
+		CreateTaskFn: func(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+			if _, err := pcontext.GetAuthorizer(ctx); err != nil {
+				return nil, err
+			}
+			got = tc
+			return &platform.Task{ID: 20, OrganizationID: tc.OrganizationID, Name: "t", Flux: tc.Flux, Every: "1h", Params: tc.Params}, nil
+		},
+	}
+	s, cc := newTestService(t, mock.NewBucketService(), tasks, &mock.PointsWriter{})
+	defer s.Close()
+	defer cc.Close()
+	ctx := withToken(context.Background(), "tok")
+
+	// The task is created in the organization of the token.
+	var task rpc.Task
+	flux := `option task = {name: "t", every: 1h} from(bucket: "b") |> range(start: -1h)`
+	if err := cc.Invoke(ctx, "/influxdata.platform.TaskService/CreateTask", &rpc.CreateTaskRequest{Flux: flux, Params: map[string]string{"k": "v"}}, &task); err != nil {
+		t.Fatal(err)
+	}
+	if got.OrganizationID != 1 || got.Flux != flux || got.Params["k"] != "v" {
+		t.Errorf("unexpected task create %+v", got)
+	}
+	if task.ID != 20 || task.OrgID != 1 || task.Every != "1h" || task.Params["k"] != "v" {
+		t.Errorf("unexpected task %+v", task)
+	}
+
+	if err := cc.Invoke(ctx, "/influxdata.platform.TaskService/CreateTask", &rpc.CreateTaskRequest{}, &task); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unexpected error creating a task without flux: %v", err)
+	}
+}
+
+func TestService_Write(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		if filter.Name != nil && *filter.Name == "b" {
+			return &platform.Bucket{ID: 10, OrganizationID: 1, Name: "b"}, nil
+		}
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+	}
+	pw := &mock.PointsWriter{}
+	s, cc := newTestService(t, buckets, &mock.TaskService{}, pw)
+	defer s.Close()
+	defer cc.Close()
+	ctx := withToken(context.Background(), "tok")
+
+	stream, err := cc.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, "/influxdata.platform.WriteService/Write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{
+		"cpu,host=a usage=1,idle=2 1000000000\n",
+		"cpu,host=b usage=3 1000000000\ncpu,host=b usage= 2000000000\n",
+	} {
+		if err := stream.SendMsg(&rpc.WriteRequest{Org: "org", Bucket: "b", Data: []byte(data)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	var res rpc.WriteResponse
+	if err := stream.RecvMsg(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Accepted != 3 || len(pw.Points) != 3 {
+		t.Errorf("unexpected values written %d, %d points", res.Accepted, len(pw.Points))
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].Request != 1 || res.Rejected[0].Line != 2 {
+		t.Errorf("unexpected lines rejected %v", res.Rejected)
+	}
+
+	// A bucket that does not exist ends the stream.
+	stream, err = cc.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, "/influxdata.platform.WriteService/Write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&rpc.WriteRequest{Org: "org", Bucket: "nope", Data: []byte("cpu usage=1\n")}); err != nil {
+		t.Fatal(err)
+	}
+	stream.CloseSend()
+	if err := stream.RecvMsg(&res); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error writing to a missing bucket: %v", err)
+	}
+}
+
+func TestService_WriteSchema(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: 10, OrganizationID: 1, Name: "b", SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	pw := &mock.PointsWriter{}
+	s, cc := newTestService(t, buckets, &mock.TaskService{}, pw)
+	defer s.Close()
+	defer cc.Close()
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		return []*platform.MeasurementSchema{{
+			BucketID: *filter.BucketID,
+			Name:     "cpu",
+			Tags:     []string{"host"},
+			Fields:   []platform.MeasurementSchemaField{{Name: "usage", Type: platform.SchemaFieldTypeFloat}},
+		}}, nil
+	}
+	s.MeasurementSchemaService = schemas
+	ctx := withToken(context.Background(), "tok")
+
+	stream, err := cc.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, "/influxdata.platform.WriteService/Write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := "cpu,host=a usage=1 1000000000\nmem,host=a used=1i 1000000000\ncpu,host=a usage=\"high\" 1000000000\n"
+	if err := stream.SendMsg(&rpc.WriteRequest{Org: "org", Bucket: "b", Data: []byte(data)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	var res rpc.WriteResponse
+	if err := stream.RecvMsg(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Accepted != 1 || len(pw.Points) != 1 {
+		t.Errorf("unexpected values written %d, %d points", res.Accepted, len(pw.Points))
+	}
+	if len(res.Rejected) != 2 || res.Rejected[0].Line != 2 || res.Rejected[1].Line != 3 {
+		t.Errorf("unexpected lines rejected %v", res.Rejected)
+	}
+}
+
+func TestService_WriteLimit(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: 10, OrganizationID: 1, Name: "b"}, nil
+	}
+	pw := &mock.PointsWriter{}
+	s, cc := newTestService(t, buckets, &mock.TaskService{}, pw)
+	defer s.Close()
+	defer cc.Close()
+	s.WriteLimiter = write.NewLimiter(write.Limits{TokenPointsPerSecond: 1})
+	ctx := withToken(context.Background(), "tok")
+
+	stream, err := cc.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, "/influxdata.platform.WriteService/Write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first request overdraws the budget of the token, and the second is refused.
+	for i := 0; i < 2; i++ {
+		if err := stream.SendMsg(&rpc.WriteRequest{Org: "org", Bucket: "b", Data: []byte("cpu usage=1 1000000000\ncpu usage=2 2000000000\n")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stream.CloseSend()
+	var res rpc.WriteResponse
+	if err := stream.RecvMsg(&res); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("unexpected error exceeding the write limits: %v", err)
+	}
+	if len(pw.Points) != 2 {
+		t.Errorf("expected the request within the limits to be written, got %d points", len(pw.Points))
+	}
+}
@@ -0,0 +1,48 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrRunningQueryNotFound is returned when a running query is not found, such as when it is done.
+const ErrRunningQueryNotFound = "running query not found"
+
+// RunningQueryService lists the queries running on the query controller, and cancels them.
+type RunningQueryService interface {
+	// FindRunningQueries returns the running queries matching the filter, longest running first.
+	FindRunningQueries(ctx context.Context, filter RunningQueryFilter) ([]*RunningQuery, error)
+
+	// FindRunningQueryByID returns a single running query by ID.
+	FindRunningQueryByID(ctx context.Context, id ID) (*RunningQuery, error)
+
+	// CancelRunningQuery stops the execution of a running query.
+	CancelRunningQuery(ctx context.Context, id ID) error
+}
+
+// RunningQuery is a query submitted to the query controller that is not done yet.
+type RunningQuery struct {
+	// ID is the ID of the query in the controller, which is reused after the restart of the server.
+	ID    ID `json:"id"`
+	OrgID ID `json:"orgID"`
+
+	// AuthorizationID is the ID of the authorization the query was submitted with, if any.
+	AuthorizationID ID `json:"authorizationID,omitempty"`
+
+	// Source is the tag of the query, which identifies the application or the dashboard cell running it.
+	Source string `json:"source,omitempty"`
+
+	// State is the state of the query in the controller, such as queueing or executing.
+	State string `json:"state"`
+
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+
+	// MaxAllocated is the largest number of bytes the query has allocated so far.
+	MaxAllocated int64 `json:"maxAllocated"`
+}
+
+// RunningQueryFilter represents a set of filters that restrict the running queries returned.
+type RunningQueryFilter struct {
+	OrgID *ID
+}
@@ -0,0 +1,90 @@
+package influxdb
+
+import (
+	"context"
+	"sort"
+)
+
+// The types of the resources referencing the secrets of an organization.
+const (
+	SecretReferenceTask          = "task"
+	SecretReferenceSQLConnection = "sqlConnection"
+)
+
+// SecretUsage is a secret key of an organization and the resources that reference it,
+// which have to be updated when the secret is rotated and break when it is deleted.
+type SecretUsage struct {
+	Key string `json:"key"`
+
+	// Exists is false for the keys referenced by resources but missing from the secrets of the organization.
+	Exists bool `json:"exists"`
+
+	References []SecretReference `json:"references"`
+}
+
+// SecretReference is a resource that references a secret.
+type SecretReference struct {
+	// Type is SecretReferenceTask or SecretReferenceSQLConnection.
+	Type string `json:"type"`
+	ID   ID     `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// FindSecretUsages returns the secret keys of the organization orgID, and the keys its resources reference,
+// sorted by key, each with the resources that reference it:
+// the tasks whose Flux gets it with secrets.get(), as recorded by ds,
+// and the SQL connections of cs whose data source name it is.
+func FindSecretUsages(ctx context.Context, orgID ID, ss SecretService, ds TaskDependencyService, cs SQLConnectionService) ([]*SecretUsage, error) {
+	keys, err := ss.GetSecretKeys(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := map[string]*SecretUsage{}
+	usage := func(key string) *SecretUsage {
+		u, ok := usages[key]
+		if !ok {
+			u = &SecretUsage{Key: key, References: []SecretReference{}}
+			usages[key] = u
+		}
+		return u
+	}
+	for _, k := range keys {
+		usage(k).Exists = true
+	}
+
+	tds, err := ds.FindTaskDependencies(ctx, TaskDependencyFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, td := range tds {
+		for _, d := range td.Dependencies {
+			if d.Type == TaskDependencySecret {
+				u := usage(d.Name)
+				u.References = append(u.References, SecretReference{Type: SecretReferenceTask, ID: td.TaskID})
+			}
+		}
+	}
+
+	conns, err := cs.FindSQLConnections(ctx, SQLConnectionFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range conns {
+		u := usage(c.SecretKey)
+		u.References = append(u.References, SecretReference{Type: SecretReferenceSQLConnection, ID: c.ID, Name: c.Name})
+	}
+
+	out := make([]*SecretUsage, 0, len(usages))
+	for _, u := range usages {
+		sort.Slice(u.References, func(i, j int) bool {
+			if u.References[i].Type != u.References[j].Type {
+				return u.References[i].Type < u.References[j].Type
+			}
+			return u.References[i].ID < u.References[j].ID
+		})
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
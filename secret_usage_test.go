@@ -0,0 +1,86 @@
+package influxdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestFindSecretUsages(t *testing.T) {
+	orgID := platform.ID(10)
+
+	ss := mock.NewSecretService()
+	ss.GetSecretKeysFn = func(ctx context.Context, id platform.ID) ([]string, error) {
+		return []string{"unused", "api-token", "metrics-dsn"}, nil
+	}
+	ds := mock.NewTaskDependencyService()
+	ds.FindTaskDependenciesFn = func(ctx context.Context, filter platform.TaskDependencyFilter) ([]*platform.TaskDependencies, error) {
+		if *filter.OrgID != orgID {
+			t.Errorf("unexpected org %s", filter.OrgID)
+		}
+		return []*platform.TaskDependencies{
+			{
+				TaskID: 2,
+				OrgID:  orgID,
+				Dependencies: []platform.TaskDependency{
+					{Type: platform.TaskDependencyBucket, Name: "api-token"},
+					{Type: platform.TaskDependencySecret, Name: "api-token"},
+				},
+			},
+			{
+				TaskID: 1,
+				OrgID:  orgID,
+				Dependencies: []platform.TaskDependency{
+					{Type: platform.TaskDependencySecret, Name: "api-token"},
+					{Type: platform.TaskDependencySecret, Name: "deleted"},
+				},
+			},
+		}, nil
+	}
+	cs := mock.NewSQLConnectionService()
+	cs.FindSQLConnectionsFn = func(ctx context.Context, filter platform.SQLConnectionFilter) ([]*platform.SQLConnection, error) {
+		return []*platform.SQLConnection{
+			{ID: 3, OrgID: orgID, Name: "metrics", DriverName: "postgres", SecretKey: "metrics-dsn"},
+		}, nil
+	}
+
+	usages, err := platform.FindSecretUsages(context.Background(), orgID, ss, ds, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []*platform.SecretUsage{
+		{
+			Key:    "api-token",
+			Exists: true,
+			References: []platform.SecretReference{
+				{Type: platform.SecretReferenceTask, ID: 1},
+				{Type: platform.SecretReferenceTask, ID: 2},
+			},
+		},
+		{
+			Key: "deleted",
+			References: []platform.SecretReference{
+				{Type: platform.SecretReferenceTask, ID: 1},
+			},
+		},
+		{
+			Key:    "metrics-dsn",
+			Exists: true,
+			References: []platform.SecretReference{
+				{Type: platform.SecretReferenceSQLConnection, ID: 3, Name: "metrics"},
+			},
+		},
+		{
+			Key:        "unused",
+			Exists:     true,
+			References: []platform.SecretReference{},
+		},
+	}
+	if diff := cmp.Diff(usages, exp); diff != "" {
+		t.Errorf("secret usages are different -got/+want\ndiff %s", diff)
+	}
+}
@@ -0,0 +1,142 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ShardService manages the shards of the storage engine.
+//
+// The storage engine keeps the points of all the buckets in the same TSM files, so the shards
+// of a bucket are the TSM files holding some of its points, and a TSM file is a shard of every
+// bucket it holds points of.
+type ShardService interface {
+	// FindShards returns the shards holding points of the bucket of filter, oldest first.
+	FindShards(ctx context.Context, filter ShardFilter) ([]*Shard, error)
+
+	// DeleteShard deletes the points of a bucket held in a shard. The points of other buckets
+	// held in the shard are kept.
+	DeleteShard(ctx context.Context, orgID, bucketID ID, id string) error
+
+	// BackupShard writes to w a tar archive of a shard, with the series index and the series
+	// file it refers to, as they were when the backup started.
+	BackupShard(ctx context.Context, orgID, bucketID ID, id string, w io.Writer) error
+
+	// CompactShards schedules a full compaction of the shards of the storage engine.
+	CompactShards(ctx context.Context) error
+
+	// TopShards returns the most active shards of the storage engine, most active first.
+	TopShards(ctx context.Context, opts TopShardsOptions) ([]*ShardActivity, error)
+}
+
+// Shard is a TSM file of the storage engine, with the statistics of the points of a bucket held in it.
+type Shard struct {
+	// ID is the name of the TSM file, without its extension.
+	ID       string `json:"id"`
+	OrgID    ID     `json:"orgID"`
+	BucketID ID     `json:"bucketID"`
+
+	// Size is the size in bytes of the TSM file, and BucketSize the size of the blocks
+	// of points of the bucket in it.
+	Size       int64 `json:"size"`
+	BucketSize int64 `json:"bucketSize"`
+
+	// SeriesCount is the number of series keys of the bucket in the shard,
+	// one for every field of a series.
+	SeriesCount int `json:"seriesCount"`
+
+	// MinTime and MaxTime are the times of the first and last points of the bucket in the shard.
+	MinTime time.Time `json:"minTime"`
+	MaxTime time.Time `json:"maxTime"`
+
+	// HasTombstone is true if points of the shard were deleted since it was written.
+	HasTombstone bool `json:"hasTombstone"`
+
+	// UpdatedAt is the last time the TSM file or its tombstones were written.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ShardFilter represents a set of filters that restrict the returned shards.
+type ShardFilter struct {
+	OrgID    ID
+	BucketID ID
+}
+
+// Validate returns an error if the filter does not have a bucket.
+func (f *ShardFilter) Validate() error {
+	if !f.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "shard orgID is required",
+		}
+	}
+	if !f.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "shard bucketID is required",
+		}
+	}
+	return nil
+}
+
+// CacheShardID is the ID of the cache of the storage engine in the activity of the shards.
+// The points written to a bucket are held in the cache until they are written to a TSM file,
+// so the cache is the shard receiving the writes of every bucket.
+const CacheShardID = "cache"
+
+// Ways of ranking the activity of shards.
+const (
+	ShardsByQueries   = "queries"   // by QueryHits
+	ShardsByWrites    = "writes"    // by WrittenBytes
+	ShardsByCacheSize = "cacheSize" // by CacheSize
+)
+
+// DefaultTopShardsLimit is the number of shards returned by TopShards if the options have no limit.
+const DefaultTopShardsLimit = 10
+
+// ShardActivity is the activity of the points of a bucket in a shard since the storage engine opened.
+type ShardActivity struct {
+	// ShardID is CacheShardID or the ID of a TSM file.
+	ShardID  string `json:"shardID"`
+	OrgID    ID     `json:"orgID"`
+	BucketID ID     `json:"bucketID"`
+
+	// QueryHits is the number of series keys of the bucket read by queries from the shard.
+	QueryHits int64 `json:"queryHits"`
+
+	// WrittenValues and WrittenBytes are the field values of the bucket written to the cache,
+	// and CacheSize the size in bytes of the values of the bucket in the cache. They are zero for TSM files.
+	WrittenValues int64 `json:"writtenValues"`
+	WrittenBytes  int64 `json:"writtenBytes"`
+	CacheSize     int64 `json:"cacheSize"`
+}
+
+// TopShardsOptions are the options of the ranking of the activity of shards.
+type TopShardsOptions struct {
+	// By is ShardsByQueries, ShardsByWrites or ShardsByCacheSize. It defaults to ShardsByQueries.
+	By string
+
+	// Limit is the number of shards returned. It defaults to DefaultTopShardsLimit.
+	Limit int
+}
+
+// Validate returns an error if the options are invalid.
+func (o *TopShardsOptions) Validate() error {
+	switch o.By {
+	case "", ShardsByQueries, ShardsByWrites, ShardsByCacheSize:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("shards cannot be ranked by %q", o.By),
+		}
+	}
+	if o.Limit < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "limit must not be negative",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrSQLConnectionNotFound is the error msg for a missing SQL connection.
+const ErrSQLConnectionNotFound = "sql connection not found"
+
+// ops for SQL connection error
+const (
+	OpFindSQLConnectionByID = "FindSQLConnectionByID"
+	OpFindSQLConnections    = "FindSQLConnections"
+	OpCreateSQLConnection   = "CreateSQLConnection"
+	OpUpdateSQLConnection   = "UpdateSQLConnection"
+	OpDeleteSQLConnection   = "DeleteSQLConnection"
+)
+
+// SQLDrivers are the drivers of the SQL databases that can be connected to.
+var SQLDrivers = []string{"postgres", "mysql"}
+
+// SQLConnectionService represents a service for managing the connections of organizations to external SQL databases.
+type SQLConnectionService interface {
+	// FindSQLConnectionByID returns a single SQL connection by ID.
+	FindSQLConnectionByID(ctx context.Context, id ID) (*SQLConnection, error)
+
+	// FindSQLConnections returns the SQL connections that match a filter.
+	FindSQLConnections(ctx context.Context, filter SQLConnectionFilter) ([]*SQLConnection, error)
+
+	// CreateSQLConnection creates a new SQL connection and sets c.ID with the new identifier.
+	CreateSQLConnection(ctx context.Context, c *SQLConnection) error
+
+	// UpdateSQLConnection updates a single SQL connection with changeset.
+	// Returns the new SQL connection state after update.
+	UpdateSQLConnection(ctx context.Context, id ID, upd SQLConnectionUpdate) (*SQLConnection, error)
+
+	// DeleteSQLConnection removes a SQL connection by ID.
+	DeleteSQLConnection(ctx context.Context, id ID) error
+}
+
+// SQLConnection is a connection of an organization to an external SQL database, which the
+// Flux sql functions of its queries and tasks refer to by name, e.g. sql.from(connection: "metrics", query: "...").
+// The data source name is not part of the connection, so that its credentials are neither in scripts
+// nor in the connection: it is the secret SecretKey of the organization.
+type SQLConnection struct {
+	ID    ID `json:"id,omitempty"`
+	OrgID ID `json:"orgID"`
+
+	// Name is unique within the organization.
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	DriverName  string `json:"driverName"`
+	SecretKey   string `json:"secretKey"`
+
+	// The limits of the pool of connections to the database shared by the queries using the connection.
+	// Zero means no limit on the open connections, the default of database/sql on the idle connections,
+	// and no limit on the lifetime of a connection.
+	MaxOpenConns    int           `json:"maxOpenConns,omitempty"`
+	MaxIdleConns    int           `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetime time.Duration `json:"connMaxLifetime,omitempty"`
+}
+
+// Validate returns an error if the SQL connection is invalid.
+func (c *SQLConnection) Validate() error {
+	if !c.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "sql connection orgID is required",
+		}
+	}
+	if c.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "sql connection name is required",
+		}
+	}
+	supported := false
+	for _, d := range SQLDrivers {
+		supported = supported || c.DriverName == d
+	}
+	if !supported {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "sql connection driverName must be postgres or mysql",
+		}
+	}
+	if c.SecretKey == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "sql connection secretKey is required",
+		}
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "sql connection pool limits cannot be negative",
+		}
+	}
+	return nil
+}
+
+// SQLConnectionFilter represents a set of filters that restrict the returned SQL connections.
+type SQLConnectionFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// Matches returns true if c passes the filter.
+func (f SQLConnectionFilter) Matches(c *SQLConnection) bool {
+	if f.ID != nil && *f.ID != c.ID {
+		return false
+	}
+	if f.OrgID != nil && *f.OrgID != c.OrgID {
+		return false
+	}
+	if f.Name != nil && *f.Name != c.Name {
+		return false
+	}
+	return true
+}
+
+// SQLConnectionUpdate is the set of changes that can be applied to a SQL connection.
+type SQLConnectionUpdate struct {
+	Name            *string        `json:"name,omitempty"`
+	Description     *string        `json:"description,omitempty"`
+	DriverName      *string        `json:"driverName,omitempty"`
+	SecretKey       *string        `json:"secretKey,omitempty"`
+	MaxOpenConns    *int           `json:"maxOpenConns,omitempty"`
+	MaxIdleConns    *int           `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetime *time.Duration `json:"connMaxLifetime,omitempty"`
+}
+
+// Apply applies the update to c and validates the result.
+func (u SQLConnectionUpdate) Apply(c *SQLConnection) error {
+	if u.Name != nil {
+		c.Name = *u.Name
+	}
+	if u.Description != nil {
+		c.Description = *u.Description
+	}
+	if u.DriverName != nil {
+		c.DriverName = *u.DriverName
+	}
+	if u.SecretKey != nil {
+		c.SecretKey = *u.SecretKey
+	}
+	if u.MaxOpenConns != nil {
+		c.MaxOpenConns = *u.MaxOpenConns
+	}
+	if u.MaxIdleConns != nil {
+		c.MaxIdleConns = *u.MaxIdleConns
+	}
+	if u.ConnMaxLifetime != nil {
+		c.ConnMaxLifetime = *u.ConnMaxLifetime
+	}
+	return c.Validate()
+}
+
+// SQLConnectionChecker checks that SQL connections can connect to their databases.
+type SQLConnectionChecker interface {
+	// CheckSQLConnection returns an error if c cannot connect to its database.
+	CheckSQLConnection(ctx context.Context, c *SQLConnection) error
+}
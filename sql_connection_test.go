@@ -0,0 +1,73 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestSQLConnectionValidate(t *testing.T) {
+	valid := func() influxdb.SQLConnection {
+		return influxdb.SQLConnection{
+			OrgID:      1,
+			Name:       "metrics",
+			DriverName: "postgres",
+			SecretKey:  "metrics-dsn",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*influxdb.SQLConnection)
+		wantErr bool
+	}{
+		{
+			name:   "valid sql connection",
+			modify: func(*influxdb.SQLConnection) {},
+		},
+		{
+			name: "valid sql connection with pool limits",
+			modify: func(c *influxdb.SQLConnection) {
+				c.DriverName = "mysql"
+				c.MaxOpenConns = 10
+				c.MaxIdleConns = 2
+				c.ConnMaxLifetime = time.Hour
+			},
+		},
+		{
+			name:    "missing org",
+			modify:  func(c *influxdb.SQLConnection) { c.OrgID = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			modify:  func(c *influxdb.SQLConnection) { c.Name = "" },
+			wantErr: true,
+		},
+		{
+			name:    "unsupported driver",
+			modify:  func(c *influxdb.SQLConnection) { c.DriverName = "sqlite3" },
+			wantErr: true,
+		},
+		{
+			name:    "missing secret key",
+			modify:  func(c *influxdb.SQLConnection) { c.SecretKey = "" },
+			wantErr: true,
+		},
+		{
+			name:    "negative pool limit",
+			modify:  func(c *influxdb.SQLConnection) { c.MaxIdleConns = -1 },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(&c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("SQLConnection.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
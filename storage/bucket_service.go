@@ -107,3 +107,42 @@ func (s *BucketService) DeleteBucket(ctx context.Context, bucketID platform.ID)
 	}
 	return s.inner.DeleteBucket(ctx, bucketID)
 }
+
+// BucketBatchService wraps an existing platform.BucketBatchService implementation.
+//
+// BucketBatchService ensures that the stored data of the buckets deleted by a batch
+// is removed once the batch is applied.
+type BucketBatchService struct {
+	inner  platform.BucketBatchService
+	engine BucketDeleter
+}
+
+// NewBucketBatchService returns a new BucketBatchService for the provided BucketDeleter,
+// which typically will be an Engine.
+func NewBucketBatchService(s platform.BucketBatchService, engine BucketDeleter) *BucketBatchService {
+	return &BucketBatchService{
+		inner:  s,
+		engine: engine,
+	}
+}
+
+// ApplyBucketBatch applies the operations, then removes the data of the buckets deleted.
+// The bucket of a deletion whose data cannot be removed is deleted still, and the error is that of its result.
+func (s *BucketBatchService) ApplyBucketBatch(ctx context.Context, ops []platform.BucketBatchOperation) ([]platform.BucketBatchResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	results, err := s.inner.ApplyBucketBatch(ctx, ops)
+	if err != nil {
+		return results, err
+	}
+	for i, op := range ops {
+		if op.Op != platform.BucketBatchDelete || results[i].Bucket == nil {
+			continue
+		}
+		if err := s.engine.DeleteBucket(results[i].Bucket.OrganizationID, op.ID); err != nil {
+			results[i].Err = err
+		}
+	}
+	return results, nil
+}
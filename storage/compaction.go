@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+var _ platform.CompactionService = (*Engine)(nil)
+
+// FindCompactionSettings returns the current compaction settings of the TSM engine.
+func (e *Engine) FindCompactionSettings(ctx context.Context) (*platform.CompactionSettings, error) {
+	return newCompactionSettings(e.engine.CompactionSettings()), nil
+}
+
+// UpdateCompactionSettings changes the compaction settings of the TSM engine until it restarts,
+// and returns the settings in effect.
+func (e *Engine) UpdateCompactionSettings(ctx context.Context, upd platform.CompactionSettingsUpdate) (*platform.CompactionSettings, error) {
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
+
+	s := e.engine.CompactionSettings()
+	if upd.MaxConcurrent != nil {
+		s.MaxConcurrent = *upd.MaxConcurrent
+	}
+	if upd.Throughput != nil {
+		s.Throughput = int(*upd.Throughput)
+	}
+	if upd.FullWriteColdDuration != nil {
+		s.FullWriteColdDuration = *upd.FullWriteColdDuration
+	}
+	s = e.engine.SetCompactionSettings(s)
+
+	e.logger.Info("Changed compaction settings",
+		zap.Int("max_concurrent", s.MaxConcurrent),
+		zap.Int("throughput", s.Throughput),
+		zap.Duration("full_write_cold_duration", s.FullWriteColdDuration))
+	return newCompactionSettings(s), nil
+}
+
+func newCompactionSettings(s tsm1.CompactionSettings) *platform.CompactionSettings {
+	return &platform.CompactionSettings{
+		MaxConcurrent:         s.MaxConcurrent,
+		Throughput:            int64(s.Throughput),
+		FullWriteColdDuration: s.FullWriteColdDuration,
+	}
+}
@@ -0,0 +1,43 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestEngine_UpdateCompactionSettings(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	before, err := engine.FindCompactionSettings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	throughput := int64(1024 * 1024)
+	after, err := engine.UpdateCompactionSettings(context.Background(), influxdb.CompactionSettingsUpdate{
+		Throughput: &throughput,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := influxdb.CompactionSettings{
+		MaxConcurrent:         before.MaxConcurrent,
+		Throughput:            throughput,
+		FullWriteColdDuration: before.FullWriteColdDuration,
+	}
+	if *after != exp {
+		t.Fatalf("unexpected settings: exp %+v, got %+v", exp, *after)
+	}
+
+	cold := -time.Hour
+	if _, err := engine.UpdateCompactionSettings(context.Background(), influxdb.CompactionSettingsUpdate{
+		FullWriteColdDuration: &cold,
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid update error, got %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 // Default configuration values.
 const (
 	DefaultRetentionInterval       = time.Hour
+	DefaultColdTierInterval        = time.Hour
 	DefaultSeriesFileDirectoryName = "_series"
 	DefaultIndexDirectoryName      = "index"
 	DefaultWALDirectoryName        = "wal"
@@ -23,6 +24,13 @@ type Config struct {
 	// Frequency of retention in seconds.
 	RetentionInterval toml.Duration `toml:"retention-interval"`
 
+	// Age of the data after which fully compacted TSM files are moved to the
+	// object store set on the Engine. Zero disables moving files.
+	ColdTierAge toml.Duration `toml:"cold-tier-age"`
+
+	// Frequency of moving cold TSM files to the object store.
+	ColdTierInterval toml.Duration `toml:"cold-tier-interval"`
+
 	// Series file config.
 	SeriesFilePath string `toml:"series-file-path"` // Overrides the default path.
 
@@ -37,12 +45,18 @@ type Config struct {
 	// Index config.
 	Index     tsi1.Config `toml:"index"`
 	IndexPath string      `toml:"index-path"` // Overrides the default path.
+
+	// Path of the file of the keys encrypting the TSM files, the WAL and the series file, see
+	// encryption.KeyFile. Empty does not encrypt them. The series keys stay readable on disk
+	// in the TSM and TSI indexes.
+	EncryptionKeyFile string `toml:"encryption-key-file"`
 }
 
 // NewConfig initialises a new config for an Engine.
 func NewConfig() Config {
 	return Config{
 		RetentionInterval: toml.Duration(DefaultRetentionInterval),
+		ColdTierInterval:  toml.Duration(DefaultColdTierInterval),
 		WAL:               tsm1.NewWALConfig(),
 		Engine:            tsm1.NewConfig(),
 		Index:             tsi1.NewConfig(),
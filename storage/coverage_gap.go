@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+var _ platform.CoverageGapService = (*Engine)(nil)
+
+// FindCoverageGaps returns the gaps in the coverage of the measurement of a bucket over the window of req.
+//
+// The series of the measurement are found in the index, and their points are read one series at a time.
+// Each series is only read over the part of the window that the series before it left uncovered,
+// and no more series are read once the whole window is covered.
+func (e *Engine) FindCoverageGaps(ctx context.Context, req platform.CoverageGapRequest) ([]*platform.CoverageGap, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	cond := &influxql.BinaryExpr{
+		Op:  influxql.EQ,
+		LHS: &influxql.VarRef{Val: models.MeasurementTagKey},
+		RHS: &influxql.StringLiteral{Val: req.Measurement},
+	}
+	series, err := e.CreateSeriesCursor(ctx, SeriesCursorRequest{Name: tsdb.EncodeName(req.OrgID, req.BucketID)}, cond)
+	if err != nil {
+		return nil, err
+	}
+	defer series.Close()
+
+	itr, err := e.CreateCursorIterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cov := newCoverage(req.Start.UnixNano(), req.Stop.UnixNano(), int64(req.Interval))
+	for !cov.full() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row, err := series.Next()
+		if err != nil {
+			return nil, err
+		} else if row == nil {
+			break
+		}
+
+		min, max := cov.uncovered()
+		cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+			Name:      row.Name,
+			Tags:      row.Tags,
+			Field:     string(row.Tags.Get(models.FieldKeyTagKeyBytes)),
+			Ascending: true,
+			StartTime: min,
+			EndTime:   max,
+		})
+		if err != nil {
+			return nil, err
+		} else if cur == nil {
+			continue
+		}
+		if err := cov.addCursor(cur); err != nil {
+			return nil, err
+		}
+	}
+	return cov.gaps(), nil
+}
+
+// coverage records which intervals of a window have at least one point.
+type coverage struct {
+	start, stop, interval int64
+
+	covered []bool
+	left    int // The number of intervals not covered yet.
+
+	// lo and hi are the first and last intervals not covered yet, if any.
+	lo, hi int
+}
+
+func newCoverage(start, stop, interval int64) *coverage {
+	n := int((stop - start) / interval)
+	if (stop-start)%interval != 0 {
+		n++
+	}
+	return &coverage{
+		start:    start,
+		stop:     stop,
+		interval: interval,
+		covered:  make([]bool, n),
+		left:     n,
+		lo:       0,
+		hi:       n - 1,
+	}
+}
+
+// full returns true if every interval is covered.
+func (c *coverage) full() bool {
+	return c.left == 0
+}
+
+// uncovered returns the smallest time range, with an inclusive end, that holds every interval not covered yet.
+func (c *coverage) uncovered() (min, max int64) {
+	return c.intervalStart(c.lo), c.intervalStop(c.hi) - 1
+}
+
+func (c *coverage) intervalStart(i int) int64 {
+	return c.start + int64(i)*c.interval
+}
+
+func (c *coverage) intervalStop(i int) int64 {
+	if stop := c.start + int64(i+1)*c.interval; stop < c.stop {
+		return stop
+	}
+	return c.stop
+}
+
+// add covers the intervals of the timestamps, which are sorted in ascending order.
+func (c *coverage) add(timestamps []int64) {
+	for _, t := range timestamps {
+		if t < c.start || t >= c.stop {
+			continue
+		}
+		if i := int((t - c.start) / c.interval); !c.covered[i] {
+			c.covered[i] = true
+			c.left--
+		}
+	}
+	for c.left > 0 && c.covered[c.lo] {
+		c.lo++
+	}
+	for c.left > 0 && c.covered[c.hi] {
+		c.hi--
+	}
+}
+
+// addCursor covers the intervals of the points of cur, and closes it.
+func (c *coverage) addCursor(cur tsdb.Cursor) error {
+	defer cur.Close()
+
+	switch cur := cur.(type) {
+	case tsdb.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0 && !c.full(); a = cur.Next() {
+			c.add(a.Timestamps)
+		}
+	case tsdb.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0 && !c.full(); a = cur.Next() {
+			c.add(a.Timestamps)
+		}
+	case tsdb.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0 && !c.full(); a = cur.Next() {
+			c.add(a.Timestamps)
+		}
+	case tsdb.StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0 && !c.full(); a = cur.Next() {
+			c.add(a.Timestamps)
+		}
+	case tsdb.BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0 && !c.full(); a = cur.Next() {
+			c.add(a.Timestamps)
+		}
+	default:
+		return fmt.Errorf("unreachable: %T", cur)
+	}
+	return cur.Err()
+}
+
+// gaps returns the runs of consecutive intervals that are not covered.
+func (c *coverage) gaps() []*platform.CoverageGap {
+	var gaps []*platform.CoverageGap
+	for i := 0; i < len(c.covered); i++ {
+		if c.covered[i] {
+			continue
+		}
+		j := i
+		for j+1 < len(c.covered) && !c.covered[j+1] {
+			j++
+		}
+		gaps = append(gaps, &platform.CoverageGap{
+			Start: time.Unix(0, c.intervalStart(i)).UTC(),
+			Stop:  time.Unix(0, c.intervalStop(j)).UTC(),
+		})
+		i = j
+	}
+	return gaps
+}
@@ -0,0 +1,117 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_FindCoverageGaps(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := func(measurement, host string, min int) models.Point {
+		return models.MustNewPoint(
+			measurement,
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			start.Add(time.Duration(min)*time.Minute),
+		)
+	}
+
+	if err := engine.Write1xPoints([]models.Point{
+		point("cpu", "a", 0),
+		point("cpu", "a", 1),
+		point("cpu", "b", 4),
+		point("mem", "a", 2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The same measurement in another bucket does not cover the gaps.
+	if err := engine.Write1xPointsWithOrgBucket([]models.Point{point("cpu", "a", 3)}, "3131313131313131", "8888888888888888"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		measurement string
+		stop        time.Duration
+		want        []*influxdb.CoverageGap
+	}{
+		{
+			name:        "gaps between series",
+			measurement: "cpu",
+			stop:        6 * time.Minute,
+			want: []*influxdb.CoverageGap{
+				{Start: start.Add(2 * time.Minute), Stop: start.Add(4 * time.Minute)},
+				{Start: start.Add(5 * time.Minute), Stop: start.Add(6 * time.Minute)},
+			},
+		},
+		{
+			name:        "last interval cut short",
+			measurement: "cpu",
+			stop:        5*time.Minute + 30*time.Second,
+			want: []*influxdb.CoverageGap{
+				{Start: start.Add(2 * time.Minute), Stop: start.Add(4 * time.Minute)},
+				{Start: start.Add(5 * time.Minute), Stop: start.Add(5*time.Minute + 30*time.Second)},
+			},
+		},
+		{
+			name:        "covered",
+			measurement: "cpu",
+			stop:        2 * time.Minute,
+		},
+		{
+			name:        "missing measurement",
+			measurement: "disk",
+			stop:        3 * time.Minute,
+			want: []*influxdb.CoverageGap{
+				{Start: start, Stop: start.Add(3 * time.Minute)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps, err := engine.FindCoverageGaps(context.Background(), influxdb.CoverageGapRequest{
+				OrgID:       engine.org,
+				BucketID:    engine.bucket,
+				Measurement: tt.measurement,
+				Interval:    time.Minute,
+				Start:       start,
+				Stop:        start.Add(tt.stop),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, gaps); diff != "" {
+				t.Errorf("unexpected gaps -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEngine_FindCoverageGaps_TooManyIntervals(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := engine.FindCoverageGaps(context.Background(), influxdb.CoverageGapRequest{
+		OrgID:       engine.org,
+		BucketID:    engine.bucket,
+		Measurement: "cpu",
+		Interval:    time.Second,
+		Start:       start,
+		Stop:        start.Add(time.Duration(influxdb.MaxCoverageIntervals+1) * time.Second),
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid request, got %v", err)
+	}
+}
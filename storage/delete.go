@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/influxdata/influxql"
+)
+
+var _ platform.DeleteService = (*Engine)(nil)
+
+// deleteBatchSize is the number of series whose points are deleted at a time.
+const deleteBatchSize = 1000
+
+// DeleteBucketRangePredicate deletes the points of the series of a bucket matching the predicate of req.
+//
+// The series matching the predicate are found in the index, and their points are deleted in batches,
+// each of them written to the WAL before tombstoning the points in the TSM files and removing the
+// series left without any point from the index.
+func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, req platform.DeleteRequest) error {
+	return e.deleteBucketRangePredicate(ctx, req, nil)
+}
+
+// deleteProgress is how far a delete by predicate is through the series matching its predicate.
+type deleteProgress struct {
+	SeriesTotal       int64
+	SeriesProcessed   int64
+	TombstonesWritten int64
+}
+
+// deleteBucketRangePredicate deletes the points of the series of a bucket matching the predicate of req,
+// calling progress, if not nil, once the series are found and after every batch of them is deleted.
+func (e *Engine) deleteBucketRangePredicate(ctx context.Context, req platform.DeleteRequest, progress func(deleteProgress)) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	cond, err := parseDeletePredicate(req.Predicate)
+	if err != nil {
+		return err
+	}
+
+	// The window of the request has an exclusive stop, the ranges of the engine are inclusive.
+	min, max := req.Start.UnixNano(), req.Stop.UnixNano()-1
+	if cond == nil {
+		return e.DeleteBucketRange(req.OrgID, req.BucketID, min, max)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(req.OrgID, req.BucketID)
+	keys, err := e.seriesFieldKeys(ctx, name, cond)
+	if err != nil {
+		return err
+	}
+	defer e.generations.incr(name)
+	bytesutil.Sort(keys)
+
+	p := deleteProgress{SeriesTotal: int64(len(keys))}
+	if progress != nil {
+		progress(p)
+	}
+
+	labels := e.deleteMetrics.Labels()
+	for len(keys) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n := deleteBatchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+
+		// Add the delete to the WAL to be replayed if there is a crash or shutdown.
+		if _, err := e.wal.DeleteSeriesRange(keys[:n], min, max); err != nil {
+			return err
+		}
+		tombstones, err := e.engine.DeleteSeriesRange(keys[:n], min, max)
+		if err != nil {
+			return err
+		}
+		keys = keys[n:]
+
+		e.deleteMetrics.Series.With(labels).Add(float64(n))
+		e.deleteMetrics.Tombstones.With(labels).Add(float64(tombstones))
+		p.SeriesProcessed += int64(n)
+		p.TombstonesWritten += int64(tombstones)
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return nil
+}
+
+// seriesFieldKeys returns the keys of the TSM data of the series of the bucket name matching cond.
+// It must be called under the lock of the engine.
+func (e *Engine) seriesFieldKeys(ctx context.Context, name [platform.IDLength]byte, cond influxql.Expr) ([][]byte, error) {
+	series, err := newSeriesCursor(SeriesCursorRequest{Name: name}, e.index, e.sfile, cond)
+	if err != nil {
+		return nil, err
+	}
+	defer series.Close()
+
+	var keys [][]byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row, err := series.Next()
+		if err != nil {
+			return nil, err
+		} else if row == nil {
+			return keys, nil
+		}
+
+		key := models.MakeKey(row.Name, row.Tags)
+		key = append(key, tsm1.KeyFieldSeparatorBytes...)
+		key = append(key, row.Tags.Get(models.FieldKeyTagKeyBytes)...)
+		keys = append(keys, key)
+	}
+}
+
+// parseDeletePredicate parses the predicate of a DeleteRequest into a condition on the tags of
+// series, or returns nil if the predicate is empty.
+//
+// The predicate is an InfluxQL expression in which values may be double quoted, as in `host="a"`.
+func parseDeletePredicate(s string) (influxql.Expr, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	expr, err := influxql.ParseExpr(s)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid delete predicate",
+			Err:  err,
+		}
+	}
+	return rewriteDeletePredicate(expr)
+}
+
+// rewriteDeletePredicate returns the condition on tags of expr, in which the values are string literals
+// and the _measurement and _field tags are the tag keys of the measurement and field of series.
+func rewriteDeletePredicate(expr influxql.Expr) (influxql.Expr, error) {
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		inner, err := rewriteDeletePredicate(expr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &influxql.ParenExpr{Expr: inner}, nil
+
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND, influxql.OR:
+			lhs, err := rewriteDeletePredicate(expr.LHS)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := rewriteDeletePredicate(expr.RHS)
+			if err != nil {
+				return nil, err
+			}
+			return &influxql.BinaryExpr{Op: expr.Op, LHS: lhs, RHS: rhs}, nil
+
+		case influxql.EQ, influxql.NEQ:
+			key, ok := expr.LHS.(*influxql.VarRef)
+			if !ok {
+				break
+			}
+
+			var value string
+			switch rhs := expr.RHS.(type) {
+			case *influxql.StringLiteral:
+				value = rhs.Val
+			case *influxql.VarRef:
+				value = rhs.Val
+			default:
+				return nil, &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  "delete predicate compares tag " + key.Val + " with a value that is not a string",
+				}
+			}
+
+			tagKey := key.Val
+			switch tagKey {
+			case "_measurement":
+				tagKey = models.MeasurementTagKey
+			case "_field":
+				tagKey = models.FieldKeyTagKey
+			}
+			return &influxql.BinaryExpr{
+				Op:  expr.Op,
+				LHS: &influxql.VarRef{Val: tagKey},
+				RHS: &influxql.StringLiteral{Val: value},
+			}, nil
+		}
+	}
+
+	return nil, &platform.Error{
+		Code: platform.EInvalid,
+		Msg:  "delete predicate only compares tags with = and !=, combined with AND and OR",
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/snowflake"
+	"go.uber.org/zap"
+)
+
+var _ platform.DeleteJobService = (*Engine)(nil)
+
+const (
+	// deleteJobQueueSize is the number of delete jobs that can wait for the job running before them.
+	deleteJobQueueSize = 100
+
+	// maxFinishedDeleteJobs is the number of finished delete jobs kept to be found after they finish.
+	maxFinishedDeleteJobs = 1000
+)
+
+// deleteJobs holds the delete jobs of an engine, which run one at a time in the order they are created.
+type deleteJobs struct {
+	idgen platform.IDGenerator
+	queue chan *platform.DeleteJob
+
+	mu       sync.Mutex
+	jobs     map[platform.ID]*platform.DeleteJob
+	finished []platform.ID // IDs of the finished jobs, oldest first.
+}
+
+func newDeleteJobs() *deleteJobs {
+	return &deleteJobs{
+		idgen: snowflake.NewIDGenerator(),
+		queue: make(chan *platform.DeleteJob, deleteJobQueueSize),
+		jobs:  make(map[platform.ID]*platform.DeleteJob),
+	}
+}
+
+// update calls fn to change job under the lock of the jobs.
+func (j *deleteJobs) update(job *platform.DeleteJob, fn func(job *platform.DeleteJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(job)
+}
+
+// finish marks job as finished, and forgets the oldest finished jobs beyond maxFinishedDeleteJobs.
+func (j *deleteJobs) finish(job *platform.DeleteJob, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job.FinishedAt = time.Now().UTC()
+	if err != nil {
+		job.Status = platform.DeleteJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = platform.DeleteJobSuccess
+	}
+
+	j.finished = append(j.finished, job.ID)
+	for len(j.finished) > maxFinishedDeleteJobs {
+		delete(j.jobs, j.finished[0])
+		j.finished = j.finished[1:]
+	}
+}
+
+// CreateDeleteJob queues a job deleting the points of the series of a bucket matching the predicate of req.
+//
+// The request is validated before the job is queued. A job running when the engine closes fails,
+// and the jobs still queued run once the engine opens again.
+func (e *Engine) CreateDeleteJob(ctx context.Context, req platform.DeleteRequest) (*platform.DeleteJob, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := parseDeletePredicate(req.Predicate); err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	job := &platform.DeleteJob{
+		ID:        e.deleteJobs.idgen.ID(),
+		Request:   req,
+		Status:    platform.DeleteJobQueued,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	e.deleteJobs.mu.Lock()
+	defer e.deleteJobs.mu.Unlock()
+	select {
+	case e.deleteJobs.queue <- job:
+	default:
+		return nil, &platform.Error{
+			Code: platform.EUnavailable,
+			Msg:  "too many delete jobs are queued",
+		}
+	}
+	e.deleteJobs.jobs[job.ID] = job
+	e.deleteMetrics.QueuedJobs.With(e.deleteMetrics.Labels()).Inc()
+
+	j := *job
+	return &j, nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (e *Engine) FindDeleteJobByID(ctx context.Context, id platform.ID) (*platform.DeleteJob, error) {
+	e.deleteJobs.mu.Lock()
+	defer e.deleteJobs.mu.Unlock()
+
+	job, ok := e.deleteJobs.jobs[id]
+	if !ok {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "delete job not found",
+		}
+	}
+	j := *job
+	return &j, nil
+}
+
+// FindDeleteJobs returns the delete jobs matching filter, most recent first.
+func (e *Engine) FindDeleteJobs(ctx context.Context, filter platform.DeleteJobFilter) ([]*platform.DeleteJob, error) {
+	e.deleteJobs.mu.Lock()
+	defer e.deleteJobs.mu.Unlock()
+
+	jobs := []*platform.DeleteJob{}
+	for _, job := range e.deleteJobs.jobs {
+		if filter.OrgID != nil && *filter.OrgID != job.Request.OrgID {
+			continue
+		}
+		if filter.BucketID != nil && *filter.BucketID != job.Request.BucketID {
+			continue
+		}
+		j := *job
+		jobs = append(jobs, &j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// runDeleteJobs runs the queued delete jobs one at a time, in a separate goroutine.
+func (e *Engine) runDeleteJobs() {
+	l := e.logger.With(zap.String("component", "delete_jobs"))
+
+	// Stop the running job as soon as the engine closes.
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	go func() {
+		<-closing
+		cancel()
+	}()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-closing:
+				return
+			case job := <-e.deleteJobs.queue:
+				e.runDeleteJob(ctx, l, job)
+			}
+		}
+	}()
+}
+
+// runDeleteJob runs a single delete job, following its progress.
+func (e *Engine) runDeleteJob(ctx context.Context, l *zap.Logger, job *platform.DeleteJob) {
+	e.deleteMetrics.QueuedJobs.With(e.deleteMetrics.Labels()).Dec()
+
+	now := time.Now()
+	e.deleteJobs.update(job, func(job *platform.DeleteJob) {
+		job.Status = platform.DeleteJobRunning
+		job.StartedAt = now.UTC()
+	})
+
+	err := e.deleteBucketRangePredicate(ctx, job.Request, func(p deleteProgress) {
+		e.deleteJobs.update(job, func(job *platform.DeleteJob) {
+			job.SeriesTotal = p.SeriesTotal
+			job.SeriesProcessed = p.SeriesProcessed
+			job.TombstonesWritten = p.TombstonesWritten
+		})
+	})
+	e.deleteJobs.finish(job, err)
+
+	labels := e.deleteMetrics.Labels()
+	e.deleteMetrics.Duration.With(labels).Observe(time.Since(now).Seconds())
+	if err != nil {
+		labels["status"] = platform.DeleteJobFailed
+		l.Error("Delete job failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+	} else {
+		labels["status"] = platform.DeleteJobSuccess
+		l.Info("Delete job finished", zap.String("job_id", job.ID.String()), zap.Duration("duration", time.Since(now)))
+	}
+	e.deleteMetrics.Jobs.With(labels).Inc()
+}
@@ -0,0 +1,103 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_CreateDeleteJob(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	var points []models.Point
+	for _, host := range []string{"a", "b", "c"} {
+		points = append(points, models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			start,
+		))
+	}
+	if err := engine.Write1xPoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := engine.CreateDeleteJob(context.Background(), influxdb.DeleteRequest{
+		OrgID:     engine.org,
+		BucketID:  engine.bucket,
+		Start:     start,
+		Stop:      start.Add(time.Hour),
+		Predicate: `host="a" OR host="b"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if job.Status != influxdb.DeleteJobQueued {
+		t.Fatalf("expected a queued job, got %q", job.Status)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !job.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("delete job did not finish: %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if job, err = engine.FindDeleteJobByID(context.Background(), job.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if job.Status != influxdb.DeleteJobSuccess || job.Error != "" {
+		t.Fatalf("expected the delete job to succeed, got %q: %s", job.Status, job.Error)
+	}
+	if job.SeriesTotal != 2 || job.SeriesProcessed != 2 {
+		t.Errorf("expected 2 series processed out of 2, got %d out of %d", job.SeriesProcessed, job.SeriesTotal)
+	}
+	if job.StartedAt.IsZero() || job.FinishedAt.Before(job.StartedAt) {
+		t.Errorf("unexpected job times: started %v, finished %v", job.StartedAt, job.FinishedAt)
+	}
+	for host, exp := range map[string]int{"a": 0, "b": 0, "c": 1} {
+		if got := countPoints(t, engine, "cpu", host); got != exp {
+			t.Errorf("expected %d points of cpu,host=%s, got %d", exp, host, got)
+		}
+	}
+
+	other := influxdb.ID(0x8888888888888888)
+	if jobs, err := engine.FindDeleteJobs(context.Background(), influxdb.DeleteJobFilter{BucketID: &engine.bucket}); err != nil {
+		t.Fatal(err)
+	} else if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("expected the delete job of the bucket, got %+v", jobs)
+	}
+	if jobs, err := engine.FindDeleteJobs(context.Background(), influxdb.DeleteJobFilter{BucketID: &other}); err != nil {
+		t.Fatal(err)
+	} else if len(jobs) != 0 {
+		t.Errorf("expected no delete job of another bucket, got %+v", jobs)
+	}
+}
+
+func TestEngine_CreateDeleteJob_Invalid(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := engine.CreateDeleteJob(context.Background(), influxdb.DeleteRequest{
+		OrgID:     engine.org,
+		BucketID:  engine.bucket,
+		Start:     start,
+		Stop:      start.Add(time.Hour),
+		Predicate: `host > "a"`,
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid predicate error, got %v", err)
+	}
+
+	if _, err := engine.FindDeleteJobByID(context.Background(), influxdb.ID(1)); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
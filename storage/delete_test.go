@@ -0,0 +1,147 @@
+package storage_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// countPoints returns the number of points of the value field of a series of the engine's bucket.
+func countPoints(t *testing.T, engine *Engine, measurement, host string) int {
+	t.Helper()
+
+	itr, err := engine.CreateCursorIterator(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := tsdb.EncodeName(engine.org, engine.bucket)
+	tags := models.NewTags(map[string]string{
+		models.MeasurementTagKey: measurement,
+		"host":                   host,
+		models.FieldKeyTagKey:    "value",
+	})
+	cur, err := itr.Next(context.Background(), &tsdb.CursorRequest{
+		Name:      name[:],
+		Tags:      tags,
+		Field:     "value",
+		Ascending: true,
+		StartTime: math.MinInt64,
+		EndTime:   math.MaxInt64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if cur == nil {
+		return 0
+	}
+	defer cur.Close()
+
+	var n int
+	fc := cur.(tsdb.FloatArrayCursor)
+	for a := fc.Next(); a.Len() > 0; a = fc.Next() {
+		n += a.Len()
+	}
+	return n
+}
+
+func TestEngine_DeleteBucketRangePredicate(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := func(measurement, host string, min int) models.Point {
+		return models.MustNewPoint(
+			measurement,
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			start.Add(time.Duration(min)*time.Minute),
+		)
+	}
+
+	if err := engine.Write1xPoints([]models.Point{
+		point("cpu", "a", 0),
+		point("cpu", "a", 1),
+		point("cpu", "a", 2),
+		point("cpu", "b", 0),
+		point("cpu", "b", 1),
+		point("mem", "a", 0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Write1xPoints([]models.Point{point("cpu", "a", 3)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete part of cpu,host=a.
+	if err := engine.DeleteBucketRangePredicate(context.Background(), influxdb.DeleteRequest{
+		OrgID:     engine.org,
+		BucketID:  engine.bucket,
+		Start:     start.Add(time.Minute),
+		Stop:      start.Add(3 * time.Minute),
+		Predicate: `_measurement="cpu" AND host="a"`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := countPoints(t, engine, "cpu", "a"), 2; got != exp {
+		t.Fatalf("got %d points of cpu,host=a, exp %d", got, exp)
+	}
+	if got, exp := countPoints(t, engine, "cpu", "b"), 2; got != exp {
+		t.Fatalf("got %d points of cpu,host=b, exp %d", got, exp)
+	}
+
+	// Deleting every point of a series removes it from the index.
+	if err := engine.DeleteBucketRangePredicate(context.Background(), influxdb.DeleteRequest{
+		OrgID:     engine.org,
+		BucketID:  engine.bucket,
+		Start:     start,
+		Stop:      start.Add(time.Hour),
+		Predicate: `host = 'a' AND (_measurement = 'mem' OR _measurement = 'disk')`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := countPoints(t, engine, "mem", "a"), 0; got != exp {
+		t.Fatalf("got %d points of mem,host=a, exp %d", got, exp)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// The deletes are replayed from the WAL on top of the points written before them.
+	engine.Engine.Close() // Don't remove the data
+	engine.MustOpen()
+	if got, exp := countPoints(t, engine, "cpu", "a"), 2; got != exp {
+		t.Fatalf("got %d points of cpu,host=a after reopening, exp %d", got, exp)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series after reopening, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_DeleteBucketRangePredicate_InvalidPredicate(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	for _, predicate := range []string{
+		`host =`,
+		`host =~ /a/`,
+		`value > 1`,
+		`host = 1`,
+	} {
+		err := engine.DeleteBucketRangePredicate(context.Background(), influxdb.DeleteRequest{
+			OrgID:     engine.org,
+			BucketID:  engine.bucket,
+			Start:     time.Unix(0, 0),
+			Stop:      time.Unix(3600, 0),
+			Predicate: predicate,
+		})
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected predicate %q to be invalid, got %v", predicate, err)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -43,6 +44,13 @@ type Engine struct {
 	engine            *tsm1.Engine
 	wal               *wal.WAL
 	retentionEnforcer *retentionEnforcer
+	objectStore       tsm1.ObjectStore
+	keyProvider       encryption.KeyProvider
+	keyring           *encryption.Keyring
+	deleteJobs        *deleteJobs
+	deleteMetrics     *deleteMetrics
+	indexChecks       *indexChecks
+	generations       *bucketGenerations
 
 	defaultMetricLabels prometheus.Labels
 
@@ -97,6 +105,23 @@ func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	}
 }
 
+// WithObjectStore sets the object store that TSM files holding data older than
+// the cold tier age of the configuration are moved to.
+func WithObjectStore(store tsm1.ObjectStore) Option {
+	return func(e *Engine) {
+		e.objectStore = store
+		e.engine.WithObjectStore(store)
+	}
+}
+
+// WithKeyProvider sets the provider of the keys encrypting the TSM files, the WAL and the series file,
+// such as a key management service. It takes precedence over the key file of the configuration.
+func WithKeyProvider(p encryption.KeyProvider) Option {
+	return func(e *Engine) {
+		e.keyProvider = p
+	}
+}
+
 // WithCompactionPlanner makes the engine have the provided compaction planner.
 func WithCompactionPlanner(planner tsm1.CompactionPlanner) Option {
 	return func(e *Engine) {
@@ -142,6 +167,10 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	if e.wal != nil {
 		e.wal.SetDefaultMetricLabels(e.defaultMetricLabels)
 	}
+	e.deleteMetrics = newDeleteMetrics(e.defaultMetricLabels)
+	e.deleteJobs = newDeleteJobs()
+	e.indexChecks = newIndexChecks()
+	e.generations = newBucketGenerations()
 
 	return e
 }
@@ -174,7 +203,11 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, tsi1.PrometheusCollectors()...)
 	metrics = append(metrics, tsm1.PrometheusCollectors()...)
 	metrics = append(metrics, wal.PrometheusCollectors()...)
-	metrics = append(metrics, e.retentionEnforcer.PrometheusCollectors()...)
+	if e.retentionEnforcer != nil {
+		metrics = append(metrics, e.retentionEnforcer.PrometheusCollectors()...)
+	}
+	metrics = append(metrics, e.deleteMetrics.PrometheusCollectors()...)
+	metrics = append(metrics, newShardActivityCollector(e, e.defaultMetricLabels))
 	return metrics
 }
 
@@ -191,6 +224,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if err := e.openKeyring(ctx); err != nil {
+		return err
+	}
+
 	// Open the services in order and clean up if any fail.
 	var oh openHelper
 	oh.Open(ctx, e.sfile)
@@ -211,7 +248,38 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	// For now we will just run on an interval as we only have the retention
 	// policy enforcer.
 	e.runRetentionEnforcer()
+	e.runColdTier()
+	e.runDeleteJobs()
+	e.runIndexChecks()
+
+	return nil
+}
+
+// openKeyring sets the keyring of the key provider, or of the key file of the configuration,
+// on the series file, the WAL and the TSM engine. The TSM files, the WAL entries and the series
+// keys of the series file are written encrypted with the current key, and those encrypted with
+// older keys are still read. Compactions encrypt the files they write with the current key.
+//
+// The TSM index, the tombstones and the TSI index are not encrypted: they keep the series keys
+// readable on disk.
+func (e *Engine) openKeyring(ctx context.Context) error {
+	p := e.keyProvider
+	if p == nil && e.config.EncryptionKeyFile != "" {
+		p = encryption.KeyFile(e.config.EncryptionKeyFile)
+	}
+	if p == nil {
+		return nil
+	}
 
+	keyring, err := encryption.NewKeyringFromProvider(ctx, p)
+	if err != nil {
+		return fmt.Errorf("error loading encryption keys: %v", err)
+	}
+	e.sfile.WithKeyring(keyring)
+	e.wal.WithKeyring(keyring)
+	e.engine.WithKeyring(keyring)
+	e.keyring = keyring
+	e.logger.Info("Encryption at rest enabled", zap.Uint32("key_id", keyring.CurrentKeyID()))
 	return nil
 }
 
@@ -238,6 +306,7 @@ func (e *Engine) replayWAL() error {
 	// Execute all the entries in the WAL again
 	reader := wal.NewWALReader(walPaths)
 	reader.WithLogger(e.logger)
+	reader.WithKeyring(e.keyring)
 	err = reader.Read(func(entry wal.WALEntry) error {
 		switch en := entry.(type) {
 		case *wal.WriteWALEntry:
@@ -250,6 +319,10 @@ func (e *Engine) replayWAL() error {
 
 		case *wal.DeleteBucketRangeWALEntry:
 			return e.deleteBucketRangeLocked(en.OrgID, en.BucketID, en.Min, en.Max)
+
+		case *wal.DeleteSeriesRangeWALEntry:
+			_, err := e.engine.DeleteSeriesRange(en.Keys, en.Min, en.Max)
+			return err
 		}
 
 		return nil
@@ -305,6 +378,67 @@ func (e *Engine) runRetentionEnforcer() {
 	}()
 }
 
+// runColdTier moves cold TSM files to the object store on an interval, in a
+// separate goroutine.
+func (e *Engine) runColdTier() {
+	age := time.Duration(e.config.ColdTierAge)
+	interval := time.Duration(e.config.ColdTierInterval)
+
+	if e.objectStore == nil || age == 0 {
+		e.logger.Info("Cold storage tier disabled")
+		return
+	} else if age < 0 || interval <= 0 {
+		e.logger.Error("Invalid cold storage tier configuration",
+			logger.DurationLiteral("age", age),
+			logger.DurationLiteral("check_interval", interval))
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "cold_tier"), logger.DurationLiteral("check_interval", interval))
+	l.Info("Starting")
+
+	// Stop moving files as soon as the engine closes, rather than after the current check.
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	go func() {
+		<-closing
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closing:
+				l.Info("Stopping")
+				return
+			case <-ticker.C:
+				n, err := e.MoveColdFiles(ctx, time.Now().Add(-age))
+				if err != nil && err != context.Canceled {
+					l.Error("Failed to move cold files", zap.Error(err))
+				} else if n > 0 {
+					l.Info("Moved cold files", zap.Int("files", n))
+				}
+			}
+		}
+	}()
+}
+
+// MoveColdFiles moves to the object store the fully compacted TSM files holding
+// no data at or after before, and returns the number of files moved.
+func (e *Engine) MoveColdFiles(ctx context.Context, before time.Time) (int, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return 0, ErrEngineClosed
+	}
+
+	return e.engine.MoveColdFiles(ctx, before.UnixNano())
+}
+
 // Close closes the store and all underlying resources. It returns an error if
 // any of the underlying systems fail to close.
 func (e *Engine) Close() error {
@@ -367,13 +501,7 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 
 	// dropPoint should be called whenever there is reason to drop a point from
 	// the batch.
-	dropPoint := func(key []byte, reason string) {
-		if collection.Reason == "" {
-			collection.Reason = reason
-		}
-		collection.Dropped++
-		collection.DroppedKeys = append(collection.DroppedKeys, key)
-	}
+	dropPoint := collection.Drop
 
 	for iter := collection.Iterator(); iter.Next(); {
 		tags := iter.Tags()
@@ -467,8 +595,10 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 		}
 	}
 
-	// Write the values to the engine.
-	if err := e.engine.WriteValues(values); err != nil {
+	// Write the values to the engine. The data of the buckets may have changed even if it fails.
+	err := e.engine.WriteValues(values)
+	e.generations.incrNames(collection.Names)
+	if err != nil {
 		return err
 	}
 
@@ -535,10 +665,8 @@ func (e *Engine) DeleteBucketRange(orgID, bucketID platform.ID, min, max int64)
 func (e *Engine) deleteBucketRangeLocked(orgID, bucketID platform.ID, min, max int64) error {
 	// TODO(edd): we need to clean up how we're encoding the prefix so that we
 	// don't have to remember to get it right everywhere we need to touch TSM data.
-	encoded := tsdb.EncodeName(orgID, bucketID)
-	name := models.EscapeMeasurement(encoded[:])
-
-	return e.engine.DeleteBucketRange(name, min, max)
+	defer e.generations.incr(tsdb.EncodeName(orgID, bucketID))
+	return e.engine.DeleteBucketRange(bucketTSMName(orgID, bucketID), min, max)
 }
 
 // SeriesCardinality returns the number of series in the engine.
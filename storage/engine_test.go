@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
 
 func TestEngine_WriteAndIndex(t *testing.T) {
@@ -226,6 +228,46 @@ func TestEngine_DeleteBucket(t *testing.T) {
 	}
 }
 
+func TestEngine_BucketGeneration(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	other, err := influxdb.IDFromString("8888888888888888")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generations := func() (uint64, uint64) {
+		return engine.BucketGeneration(engine.org, engine.bucket), engine.BucketGeneration(engine.org, *other)
+	}
+
+	if gen, otherGen := generations(); gen != 0 || otherGen != 0 {
+		t.Fatalf("got generations %d and %d, want 0 before any write", gen, otherGen)
+	}
+
+	// A write of several series of a bucket increments its generation once.
+	pt := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0, "value2": 2.0},
+		time.Unix(1, 2),
+	)
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	if gen, otherGen := generations(); gen != 1 || otherGen != 0 {
+		t.Fatalf("got generations %d and %d, want 1 and 0 after a write", gen, otherGen)
+	}
+
+	// A delete increments the generation of its bucket only.
+	if err := engine.DeleteBucketRange(engine.org, engine.bucket, math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if gen, otherGen := generations(); gen != 2 || otherGen != 0 {
+		t.Fatalf("got generations %d and %d, want 2 and 0 after a delete", gen, otherGen)
+	}
+}
+
 func TestEngine_OpenClose(t *testing.T) {
 	engine := NewDefaultEngine()
 	engine.MustOpen()
@@ -295,6 +337,78 @@ func TestEngine_WALDisabled(t *testing.T) {
 	}
 }
 
+func TestEngine_Encrypted(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "storage_engine_keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(keyFile.Name())
+	if _, err := keyFile.WriteString("1 AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	config := storage.NewConfig()
+	config.EncryptionKeyFile = keyFile.Name()
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.CompactShards(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.GetEnginePath(engine.path), "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) == 0 {
+		t.Fatal("expected a tsm file")
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b[4] != tsm1.EncryptedVersion {
+			t.Fatalf("expected %s to be encrypted", file)
+		}
+	}
+
+	// Points written after the snapshot are in the encrypted WAL.
+	pt.SetTime(time.Unix(2, 3))
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The engine can not be opened without the keys.
+	plain := storage.NewEngine(engine.path, storage.NewConfig())
+	if err := plain.Open(context.Background()); err == nil {
+		plain.Close()
+		t.Fatal("expected an error opening an encrypted engine without keys")
+	}
+	plain.Close()
+
+	engine.Engine = storage.NewEngine(engine.path, config)
+	engine.MustOpen()
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
 func TestEngine_WriteConflictingBatch(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
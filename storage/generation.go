@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"bytes"
+	"sync"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// bucketGenerations counts the changes to the data of each bucket since the engine was created.
+type bucketGenerations struct {
+	mu   sync.RWMutex
+	gens map[[platform.IDLength]byte]uint64
+}
+
+func newBucketGenerations() *bucketGenerations {
+	return &bucketGenerations{gens: make(map[[platform.IDLength]byte]uint64)}
+}
+
+// get returns the generation of the bucket name.
+func (g *bucketGenerations) get(name [platform.IDLength]byte) uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.gens[name]
+}
+
+// incr increments the generation of the bucket name.
+func (g *bucketGenerations) incr(name [platform.IDLength]byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gens[name]++
+}
+
+// incrNames increments once the generation of each bucket of names, the names of the points of a write.
+func (g *bucketGenerations) incrNames(names [][]byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var last []byte
+	seen := make(map[[platform.IDLength]byte]struct{})
+	for _, name := range names {
+		// The points of a write are usually grouped by bucket.
+		if len(name) < platform.IDLength || bytes.Equal(name, last) {
+			continue
+		}
+		last = name
+
+		var key [platform.IDLength]byte
+		copy(key[:], name)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		g.gens[key]++
+	}
+}
+
+// BucketGeneration returns the generation of the data of a bucket, which changes every time
+// points are written to or deleted from the bucket. Generations start over when the engine is
+// created, and so can only be compared with those of the same engine.
+func (e *Engine) BucketGeneration(orgID, bucketID platform.ID) uint64 {
+	return e.generations.get(tsdb.EncodeName(orgID, bucketID))
+}
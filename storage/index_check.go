@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"go.uber.org/zap"
+)
+
+var _ platform.IndexCheckService = (*Engine)(nil)
+
+// indexChecks holds the check of the index of an engine, which runs in the background.
+type indexChecks struct {
+	queue chan *platform.IndexCheck
+
+	mu    sync.Mutex
+	check *platform.IndexCheck // The running check, or the last check that finished.
+}
+
+func newIndexChecks() *indexChecks {
+	return &indexChecks{
+		queue: make(chan *platform.IndexCheck, 1),
+	}
+}
+
+// update calls fn to change check under the lock of the checks.
+func (c *indexChecks) update(check *platform.IndexCheck, fn func(check *platform.IndexCheck)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(check)
+}
+
+// finish marks check as finished.
+func (c *indexChecks) finish(check *platform.IndexCheck, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	check.FinishedAt = time.Now().UTC()
+	if err != nil {
+		check.Status = platform.IndexCheckFailed
+		check.Error = err.Error()
+	} else {
+		check.Status = platform.IndexCheckSuccess
+	}
+}
+
+// CheckIndex starts checking the index against the series file and the TSM data in the background.
+//
+// Every partition of the index is verified in turn, then the series with data are looked up in the index.
+// When opts.Repair is set, the partitions with discrepancies are rebuilt from the series file, and the
+// series with data missing from the index are indexed again. The index stays online meanwhile.
+// A check running when the engine closes fails.
+func (e *Engine) CheckIndex(ctx context.Context, opts platform.IndexCheckOptions) (*platform.IndexCheck, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	e.indexChecks.mu.Lock()
+	defer e.indexChecks.mu.Unlock()
+	if c := e.indexChecks.check; c != nil && c.Status == platform.IndexCheckRunning {
+		return nil, &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "an index check is already running",
+		}
+	}
+
+	check := &platform.IndexCheck{
+		Status:     platform.IndexCheckRunning,
+		Repair:     opts.Repair,
+		Partitions: []platform.IndexPartitionCheck{},
+		StartedAt:  time.Now().UTC(),
+	}
+	e.indexChecks.queue <- check // Never blocks, as at most one check is running or queued.
+	e.indexChecks.check = check
+
+	return copyIndexCheck(check), nil
+}
+
+// FindIndexCheck returns the running check of the index, or the last check that finished.
+func (e *Engine) FindIndexCheck(ctx context.Context) (*platform.IndexCheck, error) {
+	e.indexChecks.mu.Lock()
+	defer e.indexChecks.mu.Unlock()
+
+	if e.indexChecks.check == nil {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "the index was not checked",
+		}
+	}
+	return copyIndexCheck(e.indexChecks.check), nil
+}
+
+// copyIndexCheck returns a copy of check, which must be called under the lock of the checks.
+func copyIndexCheck(check *platform.IndexCheck) *platform.IndexCheck {
+	c := *check
+	c.Partitions = append([]platform.IndexPartitionCheck{}, check.Partitions...)
+	return &c
+}
+
+// runIndexChecks runs the checks of the index, in a separate goroutine.
+func (e *Engine) runIndexChecks() {
+	l := e.logger.With(zap.String("component", "index_check"))
+
+	closing := e.closing
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-closing:
+				return
+			case check := <-e.indexChecks.queue:
+				e.runIndexCheck(l, check, closing)
+			}
+		}
+	}()
+}
+
+// runIndexCheck runs a single check of the index, following its progress. Closing cancel interrupts it.
+func (e *Engine) runIndexCheck(l *zap.Logger, check *platform.IndexCheck, cancel <-chan struct{}) {
+	now := time.Now()
+	l.Info("Index check started", zap.Bool("repair", check.Repair))
+
+	err := e.checkIndex(l, check, cancel)
+	e.indexChecks.finish(check, err)
+
+	if err != nil {
+		l.Error("Index check failed", zap.Error(err))
+	} else {
+		l.Info("Index check finished", zap.Duration("duration", time.Since(now)))
+	}
+}
+
+func (e *Engine) checkIndex(l *zap.Logger, check *platform.IndexCheck, cancel <-chan struct{}) error {
+	for i := 0; i < int(e.index.PartitionN); i++ {
+		report, err := e.index.VerifyPartition(i, cancel)
+		if err != nil {
+			return err
+		}
+
+		pc := newIndexPartitionCheck(report)
+		if check.Repair && report.DiscrepancyN > 0 {
+			select {
+			case <-cancel:
+				return tsi1.ErrVerifyInterrupted
+			default:
+			}
+
+			l.Info("Rebuilding index partition", zap.Int("partition", i), zap.Int("discrepancies", report.DiscrepancyN))
+			if err := e.index.RebuildPartition(i); err != nil {
+				return err
+			}
+			pc.Rebuilt = true
+		}
+
+		e.indexChecks.update(check, func(check *platform.IndexCheck) {
+			check.Partitions = append(check.Partitions, pc)
+		})
+	}
+
+	collection, err := e.engine.UnindexedSeries(cancel)
+	if err != nil {
+		return err
+	}
+	missing := collection.Length()
+	e.indexChecks.update(check, func(check *platform.IndexCheck) {
+		check.MissingSeriesCount = missing
+	})
+	if !check.Repair || missing == 0 {
+		return nil
+	}
+
+	l.Info("Indexing series missing from the index", zap.Int("series", missing))
+	if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
+		return err
+	}
+	e.indexChecks.update(check, func(check *platform.IndexCheck) {
+		check.ReindexedSeriesCount = missing - int(collection.Dropped)
+	})
+	return nil
+}
+
+func newIndexPartitionCheck(report *tsi1.PartitionReport) platform.IndexPartitionCheck {
+	pc := platform.IndexPartitionCheck{
+		Partition:        report.Partition,
+		SeriesCount:      report.SeriesN,
+		DiscrepancyCount: report.DiscrepancyN,
+		Discrepancies:    make([]platform.IndexDiscrepancy, 0, len(report.Discrepancies)),
+	}
+	for _, d := range report.Discrepancies {
+		pd := platform.IndexDiscrepancy{
+			Kind:     d.Kind,
+			SeriesID: d.SeriesID.RawID(),
+		}
+		if d.Key != nil {
+			name, tags := tsdb.ParseSeriesKey(d.Key)
+			var b [16]byte
+			if len(name) == len(b) {
+				copy(b[:], name)
+				pd.OrgID, pd.BucketID = tsdb.DecodeName(b)
+			}
+			pd.Measurement = string(tags.Get(models.MeasurementTagKeyBytes))
+			pd.Field = string(tags.Get(models.FieldKeyTagKeyBytes))
+			pd.Tags = make(map[string]string, len(tags))
+			for _, t := range tags {
+				if k := string(t.Key); k != models.MeasurementTagKey && k != models.FieldKeyTagKey {
+					pd.Tags[k] = string(t.Value)
+				}
+			}
+		}
+		pc.Discrepancies = append(pc.Discrepancies, pd)
+	}
+	return pc
+}
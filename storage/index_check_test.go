@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_CheckIndex(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	if _, err := engine.FindIndexCheck(context.Background()); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected no index check, got %v", err)
+	}
+
+	var points []models.Point
+	for _, host := range []string{"a", "b", "c"} {
+		points = append(points, models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 0),
+		))
+	}
+	if err := engine.Write1xPoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := engine.CheckIndex(context.Background(), influxdb.IndexCheckOptions{Repair: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if check.Status != influxdb.IndexCheckRunning || !check.Repair {
+		t.Fatalf("expected a running repair, got %+v", check)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for check.Status == influxdb.IndexCheckRunning {
+		if time.Now().After(deadline) {
+			t.Fatalf("index check did not finish: %+v", check)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if check, err = engine.FindIndexCheck(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if check.Status != influxdb.IndexCheckSuccess || check.Error != "" {
+		t.Fatalf("expected the index check to succeed, got %q: %s", check.Status, check.Error)
+	}
+	if check.FinishedAt.Before(check.StartedAt) {
+		t.Errorf("unexpected check times: started %v, finished %v", check.StartedAt, check.FinishedAt)
+	}
+	var seriesN uint64
+	for _, p := range check.Partitions {
+		seriesN += p.SeriesCount
+		if p.DiscrepancyCount != 0 || p.Rebuilt {
+			t.Errorf("expected partition %d to be consistent, got %+v", p.Partition, p)
+		}
+	}
+	if seriesN != 3 {
+		t.Errorf("expected 3 series checked, got %d", seriesN)
+	}
+	if check.MissingSeriesCount != 0 || check.ReindexedSeriesCount != 0 {
+		t.Errorf("expected no series missing from the index, got %d", check.MissingSeriesCount)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(3); got != exp {
+		t.Errorf("got %d series, exp %d series in index", got, exp)
+	}
+
+	engine.Engine.Close()
+	if _, err := engine.CheckIndex(context.Background(), influxdb.IndexCheckOptions{}); err == nil {
+		t.Error("expected checking the index of a closed engine to fail")
+	}
+}
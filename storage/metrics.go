@@ -3,6 +3,7 @@ package storage
 import (
 	"sort"
 
+	platform "github.com/influxdata/influxdb"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -64,3 +65,149 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.CheckDuration,
 	}
 }
+
+const deleteSubsystem = "delete" // sub-system associated with metrics for deleting series.
+
+// deleteMetrics is a set of metrics concerned with tracking the deletes of series by predicate.
+type deleteMetrics struct {
+	labels     prometheus.Labels
+	Jobs       *prometheus.CounterVec
+	QueuedJobs *prometheus.GaugeVec
+	Duration   *prometheus.HistogramVec
+	Series     *prometheus.CounterVec
+	Tombstones *prometheus.CounterVec
+}
+
+func newDeleteMetrics(labels prometheus.Labels) *deleteMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	jobsNames := append(append([]string(nil), names...), "status")
+	sort.Strings(jobsNames)
+
+	return &deleteMetrics{
+		labels: labels,
+		Jobs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: deleteSubsystem,
+			Name:      "jobs_total",
+			Help:      "Number of finished delete jobs by status.",
+		}, jobsNames),
+
+		QueuedJobs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: deleteSubsystem,
+			Name:      "queued_jobs",
+			Help:      "Number of delete jobs waiting to run.",
+		}, names),
+
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: deleteSubsystem,
+			Name:      "job_duration_seconds",
+			Help:      "Time taken to run a delete job.",
+			// 20 buckets spaced exponentially between 10ms and ~30m
+			Buckets: prometheus.ExponentialBuckets(0.01, 1.9, 20),
+		}, names),
+
+		Series: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: deleteSubsystem,
+			Name:      "series_total",
+			Help:      "Number of series whose points were deleted by predicate.",
+		}, names),
+
+		Tombstones: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: deleteSubsystem,
+			Name:      "tombstones_total",
+			Help:      "Number of tombstones written to TSM files by deletes by predicate.",
+		}, names),
+	}
+}
+
+// Labels returns a copy of labels for use with delete metrics.
+func (m *deleteMetrics) Labels() prometheus.Labels {
+	l := make(map[string]string, len(m.labels))
+	for k, v := range m.labels {
+		l[k] = v
+	}
+	return l
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *deleteMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Jobs,
+		m.QueuedJobs,
+		m.Duration,
+		m.Series,
+		m.Tombstones,
+	}
+}
+
+const shardSubsystem = "shard" // sub-system associated with metrics for the activity of shards.
+
+// shardActivityCollector collects the activity of the buckets in the cache and in the TSM files
+// of an engine, as reported by its ShardActivity, so that the hot shards can be found.
+type shardActivityCollector struct {
+	e *Engine
+
+	queryHits     *prometheus.Desc
+	writtenValues *prometheus.Desc
+	writtenBytes  *prometheus.Desc
+	cacheSize     *prometheus.Desc
+}
+
+func newShardActivityCollector(e *Engine, labels prometheus.Labels) *shardActivityCollector {
+	names := []string{"shard", "org_id", "bucket_id"}
+	return &shardActivityCollector{
+		e: e,
+		queryHits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, shardSubsystem, "query_hits_total"),
+			"Number of series keys of a bucket read by queries from a shard.",
+			names, labels),
+		writtenValues: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, shardSubsystem, "written_values_total"),
+			"Number of field values of a bucket written to the cache.",
+			names, labels),
+		writtenBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, shardSubsystem, "written_bytes_total"),
+			"Number of bytes of field values of a bucket written to the cache.",
+			names, labels),
+		cacheSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, shardSubsystem, "cache_bytes"),
+			"Size in bytes of the field values of a bucket in the cache.",
+			names, labels),
+	}
+}
+
+// Describe returns all descriptions of the collector.
+func (c *shardActivityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queryHits
+	ch <- c.writtenValues
+	ch <- c.writtenBytes
+	ch <- c.cacheSize
+}
+
+// Collect returns the current activity of the shards. It returns nothing while the engine is closed.
+func (c *shardActivityCollector) Collect(ch chan<- prometheus.Metric) {
+	activities, err := c.e.shardActivity()
+	if err != nil {
+		return
+	}
+
+	for _, a := range activities {
+		labels := []string{a.ShardID, a.OrgID.String(), a.BucketID.String()}
+		ch <- prometheus.MustNewConstMetric(c.queryHits, prometheus.CounterValue, float64(a.QueryHits), labels...)
+		if a.ShardID != platform.CacheShardID {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.writtenValues, prometheus.CounterValue, float64(a.WrittenValues), labels...)
+		ch <- prometheus.MustNewConstMetric(c.writtenBytes, prometheus.CounterValue, float64(a.WrittenBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.cacheSize, prometheus.GaugeValue, float64(a.CacheSize), labels...)
+	}
+}
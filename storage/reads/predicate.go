@@ -154,7 +154,8 @@ func (v *predicateExpressionPrinter) Visit(n *datatypes.Node) NodeVisitor {
 	}
 }
 
-func toStoragePredicate(f *semantic.FunctionExpression) (*datatypes.Predicate, error) {
+// ToStoragePredicate returns the storage predicate of the filter function f of a read.
+func ToStoragePredicate(f *semantic.FunctionExpression) (*datatypes.Predicate, error) {
 	if f.Block.Parameters == nil || len(f.Block.Parameters.List) != 1 {
 		return nil, errors.New("storage predicate functions must have exactly one parameter")
 	}
@@ -35,7 +35,7 @@ func NewReader(s Store) influxdb.Reader {
 func (r *storeReader) Read(ctx context.Context, rs influxdb.ReadSpec, start, stop execute.Time, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	var predicate *datatypes.Predicate
 	if rs.Predicate != nil {
-		p, err := toStoragePredicate(rs.Predicate)
+		p, err := ToStoragePredicate(rs.Predicate)
 		if err != nil {
 			return nil, err
 		}
@@ -4,7 +4,6 @@ import (
 	"github.com/influxdata/flux/control"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
-	pcontrol "github.com/influxdata/influxdb/query/control"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads"
@@ -12,7 +11,7 @@ import (
 
 // NewProxyQueryService returns a proxy query service based on the given queryController
 // suitable for the storage read service.
-func NewProxyQueryService(queryController *pcontrol.Controller) query.ProxyQueryService {
+func NewProxyQueryService(queryController query.AsyncQueryService) query.ProxyQueryService {
 	return query.ProxyQueryServiceAsyncBridge{
 		AsyncQueryService: queryController,
 	}
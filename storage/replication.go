@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+var _ platform.ReplicationService = (*Engine)(nil)
+
+// VerifyReplication compares the data of the source bucket of req with the data of its target bucket.
+//
+// Every series of both buckets is read back from its latest point to find the watermarks of the buckets.
+// The series sampled from the source bucket are those with the smallest hashes of their keys, so that
+// the same series are compared every time, and each of them is read from both buckets to compare the
+// number of points and a checksum of their times and values.
+func (e *Engine) VerifyReplication(ctx context.Context, req platform.ReplicationRequest) (*platform.ReplicationReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.SampleSize == 0 {
+		req.SampleSize = platform.DefaultReplicationSampleSize
+	}
+
+	itr, err := e.CreateCursorIterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, stop := req.Start.UnixNano(), req.Stop.UnixNano()
+	source := tsdb.EncodeName(req.OrgID, req.BucketID)
+	target := tsdb.EncodeName(req.TargetOrgID, req.TargetBucketID)
+
+	sample := newSeriesSample(req.SampleSize)
+	sourceMark, err := e.watermark(ctx, itr, source, start, stop, sample.add)
+	if err != nil {
+		return nil, err
+	}
+	targetMark, err := e.watermark(ctx, itr, target, start, stop, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &platform.ReplicationReport{
+		SampledSeries: sample.Len(),
+		Divergences:   []*platform.SeriesDivergence{},
+	}
+	compareStop := start
+	if sourceMark.ok {
+		report.SourceWatermark = time.Unix(0, sourceMark.t).UTC()
+	}
+	if targetMark.ok {
+		report.TargetWatermark = time.Unix(0, targetMark.t).UTC()
+		compareStop = targetMark.t + 1
+	}
+	if sourceMark.ok {
+		behind := start
+		if targetMark.ok {
+			behind = targetMark.t
+		}
+		if sourceMark.t > behind {
+			report.Lag = time.Duration(sourceMark.t - behind)
+		}
+	}
+	report.CompareStop = time.Unix(0, compareStop).UTC()
+
+	for _, tags := range sample.series() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		src, err := checksumSeries(ctx, itr, source[:], tags, start, compareStop)
+		if err != nil {
+			return nil, err
+		}
+		dst, err := checksumSeries(ctx, itr, target[:], tags, start, compareStop)
+		if err != nil {
+			return nil, err
+		}
+		if d := src.diverge(dst, tags); d != nil {
+			report.Divergences = append(report.Divergences, d)
+		}
+	}
+	return report, nil
+}
+
+// mark is the time of the latest point of a bucket, if ok.
+type mark struct {
+	t  int64
+	ok bool
+}
+
+// watermark returns the time of the latest point of the bucket name between start and stop, exclusive.
+// If fn is not nil, it is called with the tags of every series of the bucket.
+func (e *Engine) watermark(ctx context.Context, itr tsdb.CursorIterator, name [platform.IDLength]byte, start, stop int64, fn func(models.Tags)) (mark, error) {
+	series, err := e.CreateSeriesCursor(ctx, SeriesCursorRequest{Name: name}, nil)
+	if err != nil {
+		return mark{}, err
+	}
+	defer series.Close()
+
+	var m mark
+	for {
+		if err := ctx.Err(); err != nil {
+			return mark{}, err
+		}
+
+		row, err := series.Next()
+		if err != nil {
+			return mark{}, err
+		} else if row == nil {
+			return m, nil
+		}
+		if fn != nil {
+			fn(row.Tags)
+		}
+
+		// Only the part of the window after the latest point found so far can move the watermark.
+		min := start
+		if m.ok {
+			min = m.t + 1
+		}
+		if min >= stop {
+			continue
+		}
+		cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+			Name:      name[:],
+			Tags:      row.Tags,
+			Field:     string(row.Tags.Get(models.FieldKeyTagKeyBytes)),
+			Ascending: false,
+			StartTime: min,
+			EndTime:   stop - 1,
+		})
+		if err != nil {
+			return mark{}, err
+		} else if cur == nil {
+			continue
+		}
+		if t, ok, err := lastTimestamp(cur); err != nil {
+			return mark{}, err
+		} else if ok && (!m.ok || t > m.t) {
+			m = mark{t: t, ok: true}
+		}
+	}
+}
+
+// lastTimestamp returns the time of the first point of cur, which reads points in descending order, and closes it.
+func lastTimestamp(cur tsdb.Cursor) (int64, bool, error) {
+	defer cur.Close()
+
+	var ts []int64
+	switch cur := cur.(type) {
+	case tsdb.FloatArrayCursor:
+		ts = cur.Next().Timestamps
+	case tsdb.IntegerArrayCursor:
+		ts = cur.Next().Timestamps
+	case tsdb.UnsignedArrayCursor:
+		ts = cur.Next().Timestamps
+	case tsdb.StringArrayCursor:
+		ts = cur.Next().Timestamps
+	case tsdb.BooleanArrayCursor:
+		ts = cur.Next().Timestamps
+	default:
+		return 0, false, fmt.Errorf("unreachable: %T", cur)
+	}
+	if err := cur.Err(); err != nil {
+		return 0, false, err
+	}
+	if len(ts) == 0 {
+		return 0, false, nil
+	}
+	return ts[0], true, nil
+}
+
+// seriesChecksum is the number of points of a series and a checksum of their times and values.
+type seriesChecksum struct {
+	n   int64
+	sum uint64
+}
+
+// checksumSeries returns the checksum of the points of the series of tags in the bucket name between start and stop, exclusive.
+func checksumSeries(ctx context.Context, itr tsdb.CursorIterator, name []byte, tags models.Tags, start, stop int64) (seriesChecksum, error) {
+	if start >= stop {
+		return seriesChecksum{}, nil
+	}
+
+	cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+		Name:      name,
+		Tags:      tags,
+		Field:     string(tags.Get(models.FieldKeyTagKeyBytes)),
+		Ascending: true,
+		StartTime: start,
+		EndTime:   stop - 1,
+	})
+	if err != nil || cur == nil {
+		return seriesChecksum{}, err
+	}
+	defer cur.Close()
+
+	var (
+		c   seriesChecksum
+		h   = fnv.New64a()
+		buf [8]byte
+	)
+	put := func(v uint64) {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	switch cur := cur.(type) {
+	case tsdb.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				put(uint64(t))
+				put(math.Float64bits(a.Values[i]))
+			}
+			c.n += int64(a.Len())
+		}
+	case tsdb.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				put(uint64(t))
+				put(uint64(a.Values[i]))
+			}
+			c.n += int64(a.Len())
+		}
+	case tsdb.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				put(uint64(t))
+				put(a.Values[i])
+			}
+			c.n += int64(a.Len())
+		}
+	case tsdb.StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				put(uint64(t))
+				put(uint64(len(a.Values[i])))
+				h.Write([]byte(a.Values[i]))
+			}
+			c.n += int64(a.Len())
+		}
+	case tsdb.BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				put(uint64(t))
+				if a.Values[i] {
+					put(1)
+				} else {
+					put(0)
+				}
+			}
+			c.n += int64(a.Len())
+		}
+	default:
+		return seriesChecksum{}, fmt.Errorf("unreachable: %T", cur)
+	}
+	if err := cur.Err(); err != nil {
+		return seriesChecksum{}, err
+	}
+	c.sum = h.Sum64()
+	return c, nil
+}
+
+// diverge returns the divergence of the series of tags if its checksum in the target bucket, dst, is not c.
+func (c seriesChecksum) diverge(dst seriesChecksum, tags models.Tags) *platform.SeriesDivergence {
+	var reason string
+	switch {
+	case c == dst:
+		return nil
+	case dst.n == 0:
+		reason = platform.DivergenceMissing
+	case c.n != dst.n:
+		reason = platform.DivergencePoints
+	default:
+		reason = platform.DivergenceValues
+	}
+
+	d := &platform.SeriesDivergence{
+		Measurement:  string(tags.Get(models.MeasurementTagKeyBytes)),
+		Field:        string(tags.Get(models.FieldKeyTagKeyBytes)),
+		Tags:         make(map[string]string, len(tags)),
+		Reason:       reason,
+		SourcePoints: c.n,
+		TargetPoints: dst.n,
+	}
+	for _, t := range tags {
+		if k := string(t.Key); k != models.MeasurementTagKey && k != models.FieldKeyTagKey {
+			d.Tags[k] = string(t.Value)
+		}
+	}
+	return d
+}
+
+// seriesSample keeps the series with the smallest hashes of their keys, up to its size.
+// It is a max-heap on the hashes, so the series with the largest hash is replaced first.
+type seriesSample struct {
+	size   int
+	hashes []uint64
+	tags   []models.Tags
+}
+
+func newSeriesSample(size int) *seriesSample {
+	return &seriesSample{size: size}
+}
+
+func (s *seriesSample) Len() int           { return len(s.hashes) }
+func (s *seriesSample) Less(i, j int) bool { return s.hashes[i] > s.hashes[j] }
+func (s *seriesSample) Swap(i, j int) {
+	s.hashes[i], s.hashes[j] = s.hashes[j], s.hashes[i]
+	s.tags[i], s.tags[j] = s.tags[j], s.tags[i]
+}
+
+// Push and Pop satisfy heap.Interface, and are only called by the heap package.
+func (s *seriesSample) Push(x interface{}) {
+	e := x.(sampledSeries)
+	s.hashes = append(s.hashes, e.hash)
+	s.tags = append(s.tags, e.tags)
+}
+
+func (s *seriesSample) Pop() interface{} {
+	n := len(s.hashes) - 1
+	e := sampledSeries{hash: s.hashes[n], tags: s.tags[n]}
+	s.hashes, s.tags = s.hashes[:n], s.tags[:n]
+	return e
+}
+
+type sampledSeries struct {
+	hash uint64
+	tags models.Tags
+}
+
+// add samples the series of tags if its hash is among the smallest ones.
+func (s *seriesSample) add(tags models.Tags) {
+	h := fnv.New64a()
+	for _, t := range tags {
+		h.Write(t.Key)
+		h.Write([]byte{0})
+		h.Write(t.Value)
+		h.Write([]byte{0})
+	}
+	sum := h.Sum64()
+
+	if len(s.hashes) < s.size {
+		heap.Push(s, sampledSeries{hash: sum, tags: tags.Clone()})
+	} else if sum < s.hashes[0] {
+		s.hashes[0], s.tags[0] = sum, tags.Clone()
+		heap.Fix(s, 0)
+	}
+}
+
+// series returns the tags of the sampled series, in hash order.
+func (s *seriesSample) series() []models.Tags {
+	sort.Sort(sort.Reverse(s))
+	return s.tags
+}
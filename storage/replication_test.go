@@ -0,0 +1,135 @@
+package storage_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_VerifyReplication(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := func(measurement, host string, min int, v float64) models.Point {
+		return models.MustNewPoint(
+			measurement,
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": v},
+			start.Add(time.Duration(min)*time.Minute),
+		)
+	}
+
+	if err := engine.Write1xPoints([]models.Point{
+		point("cpu", "a", 0, 1),
+		point("cpu", "a", 1, 1),
+		point("cpu", "a", 2, 1),
+		point("cpu", "a", 3, 1),
+		point("cpu", "b", 0, 1),
+		point("cpu", "b", 1, 1),
+		point("mem", "a", 0, 1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The target bucket has not received the last points of cpu,host=a yet,
+	// has another value for cpu,host=b and misses mem,host=a.
+	if err := engine.Write1xPointsWithOrgBucket([]models.Point{
+		point("cpu", "a", 0, 1),
+		point("cpu", "a", 1, 1),
+		point("cpu", "b", 0, 2),
+		point("cpu", "b", 1, 1),
+	}, "3131313131313131", "8888888888888888"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := influxdb.ReplicationRequest{
+		OrgID:          engine.org,
+		BucketID:       engine.bucket,
+		TargetOrgID:    engine.org,
+		TargetBucketID: influxdb.ID(0x8888888888888888),
+		Start:          start,
+		Stop:           start.Add(time.Hour),
+	}
+	report, err := engine.VerifyReplication(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(report.Divergences, func(i, j int) bool {
+		return report.Divergences[i].Measurement+report.Divergences[i].Tags["host"] <
+			report.Divergences[j].Measurement+report.Divergences[j].Tags["host"]
+	})
+
+	want := &influxdb.ReplicationReport{
+		SourceWatermark: start.Add(3 * time.Minute),
+		TargetWatermark: start.Add(time.Minute),
+		Lag:             2 * time.Minute,
+		CompareStop:     start.Add(time.Minute + time.Nanosecond),
+		SampledSeries:   3,
+		Divergences: []*influxdb.SeriesDivergence{
+			{
+				Measurement:  "cpu",
+				Field:        "value",
+				Tags:         map[string]string{"host": "b"},
+				Reason:       influxdb.DivergenceValues,
+				SourcePoints: 2,
+				TargetPoints: 2,
+			},
+			{
+				Measurement:  "mem",
+				Field:        "value",
+				Tags:         map[string]string{"host": "a"},
+				Reason:       influxdb.DivergenceMissing,
+				SourcePoints: 1,
+				TargetPoints: 0,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("unexpected report -want/+got:\n%s", diff)
+	}
+
+	// Only a sample of the series is compared.
+	req.SampleSize = 1
+	if report, err = engine.VerifyReplication(context.Background(), req); err != nil {
+		t.Fatal(err)
+	} else if report.SampledSeries != 1 {
+		t.Errorf("expected 1 sampled series, got %d", report.SampledSeries)
+	}
+}
+
+func TestEngine_VerifyReplication_EmptyTarget(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, start.Add(time.Minute)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.VerifyReplication(context.Background(), influxdb.ReplicationRequest{
+		OrgID:          engine.org,
+		BucketID:       engine.bucket,
+		TargetOrgID:    engine.org,
+		TargetBucketID: influxdb.ID(0x8888888888888888),
+		Start:          start,
+		Stop:           start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.TargetWatermark.IsZero() || report.Lag != time.Minute {
+		t.Errorf("expected the target to lag by the whole window, got %v behind %v", report.Lag, report.TargetWatermark)
+	}
+	if !report.Consistent() {
+		t.Errorf("expected nothing compared before the target receives points, got %+v", report.Divergences)
+	}
+}
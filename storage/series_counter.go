@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// SeriesCounter counts the series of a bucket matching a condition, such as to estimate the
+// series a query reads before it runs.
+type SeriesCounter interface {
+	// CountSeries returns the number of series keys of the bucket matching cond, one for every
+	// field of a series. A nil cond matches every series.
+	CountSeries(ctx context.Context, orgID, bucketID influxdb.ID, cond influxql.Expr) (int, error)
+}
+
+// CountSeries returns the number of series keys of the bucket matching cond, as looked up in
+// the index of the engine.
+func (e *Engine) CountSeries(ctx context.Context, orgID, bucketID influxdb.ID, cond influxql.Expr) (int, error) {
+	req := SeriesCursorRequest{Name: tsdb.EncodeName(orgID, bucketID)}
+	cur, err := e.CreateSeriesCursor(ctx, req, cond)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close()
+
+	var n int
+	for {
+		row, err := cur.Next()
+		if err != nil {
+			return n, err
+		} else if row == nil {
+			return n, nil
+		}
+		n++
+		if n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return n, err
+			}
+		}
+	}
+}
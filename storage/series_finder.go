@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"github.com/influxdata/influxdb/models"
+)
+
+// SeriesFinder looks up the series of the engine, such as to tell the series a write would create
+// before it is written.
+type SeriesFinder interface {
+	// FindSeriesType returns the type of the field of the series of an exploded point, and whether
+	// the series exists. The type is models.Empty if the series exists but its type is not known.
+	FindSeriesType(name []byte, tags models.Tags) (models.FieldType, bool)
+}
+
+// FindSeriesType returns the type of the field of the series of an exploded point, and whether
+// the series exists, as looked up in the series file of the engine.
+func (e *Engine) FindSeriesType(name []byte, tags models.Tags) (models.FieldType, bool) {
+	id := e.sfile.SeriesIDTyped(name, tags, nil)
+	if id.IsZero() {
+		return models.Empty, false
+	}
+	if !id.HasType() {
+		return models.Empty, true
+	}
+	return id.Type(), true
+}
@@ -0,0 +1,53 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestEngine_FindSeriesType(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0, "count": int64(2)},
+		time.Unix(1, 2),
+	)
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "other"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	exploded, err := tsdb.ExplodePoints(engine.org, engine.bucket, []models.Point{pt, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		typ    models.FieldType
+		exists bool
+	}{
+		"host=server,count": {models.Integer, true},
+		"host=server,value": {models.Float, true},
+		"host=other,value":  {models.Empty, false},
+	}
+	for _, ept := range exploded {
+		tags := ept.Tags()
+		key := "host=" + tags.GetString("host") + "," + tags.GetString(models.FieldKeyTagKey)
+		typ, exists := engine.FindSeriesType(ept.Name(), tags)
+		if w := want[key]; typ != w.typ || exists != w.exists {
+			t.Errorf("series %s: got type %v and exists %v, want %v and %v", key, typ, exists, w.typ, w.exists)
+		}
+	}
+}
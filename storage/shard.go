@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+var _ platform.ShardService = (*Engine)(nil)
+
+// FindShards returns the TSM files holding points of the bucket of filter, oldest first.
+func (e *Engine) FindShards(ctx context.Context, filter platform.ShardFilter) ([]*platform.Shard, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	stats, err := e.engine.BucketFileStats(bucketTSMName(filter.OrgID, filter.BucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]*platform.Shard, 0, len(stats))
+	for _, s := range stats {
+		shards = append(shards, &platform.Shard{
+			ID:           tsm1.FileID(s.Path),
+			OrgID:        filter.OrgID,
+			BucketID:     filter.BucketID,
+			Size:         int64(s.Size),
+			BucketSize:   s.BucketSize,
+			SeriesCount:  s.KeyCount,
+			MinTime:      time.Unix(0, s.BucketMinTime).UTC(),
+			MaxTime:      time.Unix(0, s.BucketMaxTime).UTC(),
+			HasTombstone: s.HasTombstone,
+			UpdatedAt:    time.Unix(0, s.LastModified).UTC(),
+		})
+	}
+	return shards, nil
+}
+
+// DeleteShard deletes the points of a bucket held in the TSM file id, as tombstones
+// removed by the next compaction of the file.
+func (e *Engine) DeleteShard(ctx context.Context, orgID, bucketID platform.ID, id string) error {
+	filter := platform.ShardFilter{OrgID: orgID, BucketID: bucketID}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	if err := e.engine.DeleteBucketFile(bucketTSMName(orgID, bucketID), id); err == tsm1.ErrFileNotFound {
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "shard not found",
+		}
+	} else if err != nil {
+		return err
+	}
+	e.generations.incr(tsdb.EncodeName(orgID, bucketID))
+
+	e.logger.Info("Deleted bucket points of shard",
+		zap.String("shard_id", id),
+		zap.String("org_id", orgID.String()),
+		zap.String("bucket_id", bucketID.String()))
+	return nil
+}
+
+// BackupShard writes to w a tar archive of the TSM file id, in the data directory, with the
+// series index and the series file, in the index and _series directories.
+//
+// The series index and the series file are written after the TSM file, as the series are
+// added to them before their points are written, so every series of the TSM file is in them.
+func (e *Engine) BackupShard(ctx context.Context, orgID, bucketID platform.ID, id string, w io.Writer) error {
+	filter := platform.ShardFilter{OrgID: orgID, BucketID: bucketID}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	tw := tar.NewWriter(w)
+	if err := e.engine.BackupBucketFile(bucketTSMName(orgID, bucketID), id, tw, "data"); err == tsm1.ErrFileNotFound {
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "shard not found",
+		}
+	} else if err != nil {
+		return err
+	}
+	if err := e.index.Backup(tw, "index"); err != nil {
+		return err
+	}
+	if err := e.sfile.Backup(tw, "_series"); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// CompactShards writes the cache to a TSM file and schedules a full compaction of the TSM files.
+func (e *Engine) CompactShards(ctx context.Context) error {
+	// Writing the cache acquires the WAL segments under the lock of the engine, so it must not be held.
+	e.mu.RLock()
+	closed := e.closing == nil
+	e.mu.RUnlock()
+	if closed {
+		return ErrEngineClosed
+	}
+
+	return e.engine.ScheduleFullCompaction(ctx)
+}
+
+// TopShards returns the activity of the buckets in the cache and in the TSM files, ranked as opts says,
+// most active first. The shards without any activity of the ranking are left out.
+func (e *Engine) TopShards(ctx context.Context, opts platform.TopShardsOptions) ([]*platform.ShardActivity, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Limit == 0 {
+		opts.Limit = platform.DefaultTopShardsLimit
+	}
+	rank := func(a *platform.ShardActivity) int64 {
+		switch opts.By {
+		case platform.ShardsByWrites:
+			return a.WrittenBytes
+		case platform.ShardsByCacheSize:
+			return a.CacheSize
+		default:
+			return a.QueryHits
+		}
+	}
+
+	activities, err := e.shardActivity()
+	if err != nil {
+		return nil, err
+	}
+	top := activities[:0]
+	for _, a := range activities {
+		if rank(a) > 0 {
+			top = append(top, a)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return rank(top[i]) > rank(top[j])
+	})
+	if len(top) > opts.Limit {
+		top = top[:opts.Limit]
+	}
+	return top, nil
+}
+
+// shardActivity returns the activity of the buckets in the cache and in the TSM files.
+func (e *Engine) shardActivity() ([]*platform.ShardActivity, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	activities, err := e.engine.ShardActivity()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*platform.ShardActivity, 0, len(activities))
+	for _, a := range activities {
+		// The keys of the engine are all prefixed by the name of their bucket.
+		if len(a.Name) != 16 {
+			continue
+		}
+		var name [16]byte
+		copy(name[:], a.Name)
+		orgID, bucketID := tsdb.DecodeName(name)
+		res = append(res, &platform.ShardActivity{
+			ShardID:       a.ID,
+			OrgID:         orgID,
+			BucketID:      bucketID,
+			QueryHits:     a.QueryHits,
+			WrittenValues: a.WrittenValues,
+			WrittenBytes:  a.WrittenBytes,
+			CacheSize:     a.CacheSize,
+		})
+	}
+	return res, nil
+}
+
+// bucketTSMName returns the prefix of the keys of the bucket in the TSM files.
+func bucketTSMName(orgID, bucketID platform.ID) []byte {
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	return models.EscapeMeasurement(encoded[:])
+}
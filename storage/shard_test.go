@@ -0,0 +1,264 @@
+package storage_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_Shards(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := func(host string, min int) models.Point {
+		return models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			start.Add(time.Duration(min)*time.Minute),
+		)
+	}
+
+	if err := engine.Write1xPoints([]models.Point{point("a", 0), point("b", 2)}); err != nil {
+		t.Fatal(err)
+	}
+	// The points of another bucket are held in the same shard.
+	if err := engine.Write1xPointsWithOrgBucket([]models.Point{point("a", 1)}, "3131313131313131", "3333333333333333"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	filter := influxdb.ShardFilter{OrgID: engine.org, BucketID: engine.bucket}
+	if shards, err := engine.FindShards(ctx, filter); err != nil {
+		t.Fatal(err)
+	} else if len(shards) != 0 {
+		t.Fatalf("got %d shards before writing the cache, want 0", len(shards))
+	}
+
+	// Compacting the shards writes the cache to a shard.
+	if err := engine.CompactShards(ctx); err != nil {
+		t.Fatal(err)
+	}
+	shards, err := engine.FindShards(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 1 {
+		t.Fatalf("got %d shards, want 1", len(shards))
+	}
+	s := shards[0]
+	if s.SeriesCount != 2 || !s.MinTime.Equal(start) || !s.MaxTime.Equal(start.Add(2*time.Minute)) || s.BucketSize <= 0 || s.Size < s.BucketSize {
+		t.Fatalf("unexpected shard: %+v", s)
+	}
+
+	if err := engine.DeleteShard(ctx, engine.org, engine.bucket, "000000099-000000001"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error deleting a missing shard, got %v", err)
+	}
+	if err := engine.DeleteShard(ctx, engine.org, engine.bucket, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := countPoints(t, engine, "cpu", "a"); got != 0 {
+		t.Fatalf("got %d points of the deleted shard, want 0", got)
+	}
+	if shards, err := engine.FindShards(ctx, filter); err != nil {
+		t.Fatal(err)
+	} else if len(shards) != 0 {
+		t.Fatalf("got %d shards after deleting the shard, want 0", len(shards))
+	}
+
+	// The points of the other bucket are kept.
+	other := influxdb.ShardFilter{OrgID: 0x3131313131313131, BucketID: 0x3333333333333333}
+	if shards, err := engine.FindShards(ctx, other); err != nil {
+		t.Fatal(err)
+	} else if len(shards) != 1 || shards[0].SeriesCount != 1 {
+		t.Fatalf("unexpected shards of the other bucket: %+v", shards)
+	}
+
+	if _, err := engine.FindShards(ctx, influxdb.ShardFilter{}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid filter error, got %v", err)
+	}
+}
+
+func TestEngine_BackupShard(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	p := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "a"}),
+		map[string]interface{}{"value": 1.0},
+		time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+	)
+	if err := engine.Write1xPoints([]models.Point{p}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := engine.CompactShards(ctx); err != nil {
+		t.Fatal(err)
+	}
+	shards, err := engine.FindShards(ctx, influxdb.ShardFilter{OrgID: engine.org, BucketID: engine.bucket})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(shards) != 1 {
+		t.Fatalf("got %d shards, want 1", len(shards))
+	}
+
+	if err := engine.BackupShard(ctx, engine.org, engine.bucket, "000000099-000000001", ioutil.Discard); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error backing up a missing shard, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.BackupShard(ctx, engine.org, engine.bucket, shards[0].ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var tsm, manifest, segment bool
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case hdr.Name == "data/"+shards[0].ID+".tsm":
+			tsm = hdr.Size == shards[0].Size
+		case strings.HasPrefix(hdr.Name, "index/") && path.Base(hdr.Name) == "MANIFEST":
+			manifest = true
+		case strings.HasPrefix(hdr.Name, "_series/") && path.Base(hdr.Name) == "0000":
+			segment = true
+		}
+	}
+	if !tsm || !manifest || !segment {
+		t.Fatalf("missing files in the archive: tsm=%v manifest=%v segment=%v", tsm, manifest, segment)
+	}
+}
+
+func TestEngine_TopShards(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := func(host string, min int) models.Point {
+		return models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": 1.0},
+			start.Add(time.Duration(min)*time.Minute),
+		)
+	}
+	const otherOrg, otherBucket influxdb.ID = 0x3131313131313131, 0x3333333333333333
+
+	if err := engine.Write1xPoints([]models.Point{point("a", 0), point("a", 1)}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := engine.CompactShards(ctx); err != nil {
+		t.Fatal(err)
+	}
+	shards, err := engine.FindShards(ctx, influxdb.ShardFilter{OrgID: engine.org, BucketID: engine.bucket})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(shards) != 1 {
+		t.Fatalf("got %d shards, want 1", len(shards))
+	}
+	// A query reads the series from the shard.
+	countPoints(t, engine, "cpu", "a")
+	if err := engine.Write1xPointsWithOrgBucket([]models.Point{point("b", 2)}, otherOrg.String(), otherBucket.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts influxdb.TopShardsOptions
+		want []influxdb.ShardActivity
+	}{
+		{
+			name: "by queries",
+			want: []influxdb.ShardActivity{
+				{ShardID: shards[0].ID, OrgID: engine.org, BucketID: engine.bucket},
+			},
+		},
+		{
+			name: "by writes",
+			opts: influxdb.TopShardsOptions{By: influxdb.ShardsByWrites},
+			want: []influxdb.ShardActivity{
+				{ShardID: influxdb.CacheShardID, OrgID: engine.org, BucketID: engine.bucket},
+				{ShardID: influxdb.CacheShardID, OrgID: otherOrg, BucketID: otherBucket},
+			},
+		},
+		{
+			name: "by cache size",
+			opts: influxdb.TopShardsOptions{By: influxdb.ShardsByCacheSize},
+			want: []influxdb.ShardActivity{
+				{ShardID: influxdb.CacheShardID, OrgID: otherOrg, BucketID: otherBucket},
+			},
+		},
+		{
+			name: "limited",
+			opts: influxdb.TopShardsOptions{By: influxdb.ShardsByWrites, Limit: 1},
+			want: []influxdb.ShardActivity{
+				{ShardID: influxdb.CacheShardID, OrgID: engine.org, BucketID: engine.bucket},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			top, err := engine.TopShards(ctx, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(top) != len(tt.want) {
+				t.Fatalf("got %d shards, want %d: %+v", len(top), len(tt.want), top)
+			}
+			for i, a := range top {
+				if w := tt.want[i]; a.ShardID != w.ShardID || a.OrgID != w.OrgID || a.BucketID != w.BucketID {
+					t.Errorf("unexpected shard %d: %+v", i, a)
+				}
+			}
+		})
+	}
+
+	if _, err := engine.TopShards(ctx, influxdb.TopShardsOptions{By: "size"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid options error, got %v", err)
+	}
+
+	// The activity is exported as metrics.
+	reg := prom.NewRegistry()
+	reg.MustRegister(engine.PrometheusCollectors()...)
+	mfs := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mfs, "storage_shard_query_hits_total", map[string]string{
+		"shard":     shards[0].ID,
+		"org_id":    engine.org.String(),
+		"bucket_id": engine.bucket.String(),
+	})
+	if got := m.GetCounter().GetValue(); got != 1 {
+		t.Errorf("got %v query hits, want 1", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "storage_shard_written_values_total", map[string]string{
+		"shard":     influxdb.CacheShardID,
+		"org_id":    otherOrg.String(),
+		"bucket_id": otherBucket.String(),
+	})
+	if got := m.GetCounter().GetValue(); got != 1 {
+		t.Errorf("got %v written values, want 1", got)
+	}
+}
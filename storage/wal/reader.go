@@ -1,17 +1,22 @@
 package wal
 
 import (
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"sort"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"go.uber.org/zap"
 )
 
 // WALReader helps one read out the WAL into entries.
 type WALReader struct {
-	files  []string
-	logger *zap.Logger
-	r      *WALSegmentReader
+	files   []string
+	logger  *zap.Logger
+	r       *WALSegmentReader
+	keyring *encryption.Keyring
 }
 
 // NewWALReader constructs a WALReader over the given set of files.
@@ -27,6 +32,9 @@ func NewWALReader(files []string) *WALReader {
 // WithLogger sets the logger for the WALReader.
 func (r *WALReader) WithLogger(logger *zap.Logger) { r.logger = logger }
 
+// WithKeyring sets the keyring decrypting the encrypted entries of the WAL.
+func (r *WALReader) WithKeyring(keyring *encryption.Keyring) { r.keyring = keyring }
+
 // Read calls the callback with every entry in the WAL files. If, during
 // reading of a segment file, corruption is encountered, that segment file
 // is truncated up to and including the last valid byte, and processing
@@ -61,6 +69,7 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 
 	if r.r == nil {
 		r.r = NewWALSegmentReader(f)
+		r.r.WithKeyring(r.keyring)
 	} else {
 		r.r.Reset(f)
 	}
@@ -68,7 +77,9 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 
 	for r.r.Next() {
 		entry, err := r.r.Read()
-		if err != nil {
+		if _, ok := err.(decryptError); ok {
+			return fmt.Errorf("%s: %v", file, err)
+		} else if err != nil {
 			n := r.r.Count()
 			r.logger.Info("File corrupt", zap.Error(err), zap.String("path", file), zap.Int64("pos", n))
 			if err := f.Truncate(n); err != nil {
@@ -84,3 +95,35 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 
 	return r.r.Close()
 }
+
+// SegmentCorruptError is the error of ReadSegment at the first corrupt entry of a segment.
+type SegmentCorruptError struct {
+	// Offset is the number of bytes of the valid entries before the corrupt one.
+	Offset int64
+	Err    error
+}
+
+func (e *SegmentCorruptError) Error() string {
+	return fmt.Sprintf("corrupt WAL entry at offset %d: %v", e.Offset, e.Err)
+}
+
+// ReadSegment calls fn with every entry of the segment read from r. Unlike the WALReader, it
+// never modifies the segment, so it may read the segments of a snapshot: it stops at the first
+// corrupt entry, and returns a *SegmentCorruptError once the entries before it are read.
+func ReadSegment(r io.Reader, keyring *encryption.Keyring, fn func(WALEntry) error) error {
+	sr := NewWALSegmentReader(ioutil.NopCloser(r))
+	sr.WithKeyring(keyring)
+	for sr.Next() {
+		entry, err := sr.Read()
+		if _, ok := err.(decryptError); ok {
+			return err
+		} else if err != nil {
+			return &SegmentCorruptError{Offset: sr.Count(), Err: err}
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/pool"
 	"github.com/influxdata/influxdb/tsdb/value"
@@ -64,6 +65,12 @@ const (
 
 	// DeleteBucketRangeWALEntryType indicates a delete bucket range entry.
 	DeleteBucketRangeWALEntryType WalEntryType = 0x04
+
+	// DeleteSeriesRangeWALEntryType indicates a delete series range entry.
+	DeleteSeriesRangeWALEntryType WalEntryType = 0x05
+
+	// encryptedEntryFlag is set in the type of an entry whose compressed block is encrypted.
+	encryptedEntryFlag WalEntryType = 0x80
 )
 
 var (
@@ -73,6 +80,9 @@ var (
 	// ErrWALCorrupt is returned when reading a corrupt WAL entry.
 	ErrWALCorrupt = fmt.Errorf("corrupted WAL entry")
 
+	// ErrNoKeyring is returned when reading an encrypted WAL entry without a keyring.
+	ErrNoKeyring = fmt.Errorf("WAL entry is encrypted, no encryption keyring")
+
 	defaultWaitingWALWrites = runtime.GOMAXPROCS(0) * 2
 
 	// bytePool is a shared bytes pool buffer re-cycle []byte slices to reduce allocations.
@@ -114,6 +124,9 @@ type WAL struct {
 	defaultMetricLabels prometheus.Labels // N.B this must not be mutated after Open is called.
 
 	limiter limiter.Fixed
+
+	// keyring encrypts the entries written, if not nil.
+	keyring *encryption.Keyring
 }
 
 // NewWAL initializes a new WAL at the given directory.
@@ -137,6 +150,12 @@ func (l *WAL) WithFsyncDelay(delay time.Duration) {
 	l.syncDelay = delay
 }
 
+// WithKeyring sets the keyring encrypting the entries written with its current key.
+// It should be called before the WAL is opened.
+func (l *WAL) WithKeyring(keyring *encryption.Keyring) {
+	l.keyring = keyring
+}
+
 // SetEnabled sets if the WAL is enabled and should be called before the WAL is opened.
 func (l *WAL) SetEnabled(enabled bool) {
 	l.enabled = enabled
@@ -435,6 +454,15 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	compressed := snappy.Encode(encBuf, b)
 	bytesPool.Put(bytes)
 
+	entryType := entry.Type()
+	if l.keyring != nil {
+		if compressed, err = l.keyring.Encrypt(nil, compressed); err != nil {
+			bytesPool.Put(encBuf)
+			return -1, err
+		}
+		entryType |= encryptedEntryFlag
+	}
+
 	syncErr := make(chan error)
 
 	segID, err := func() (int, error) {
@@ -454,7 +482,7 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		}
 
 		// write and sync
-		if err := l.currentSegmentWriter.Write(entry.Type(), compressed); err != nil {
+		if err := l.currentSegmentWriter.Write(entryType, compressed); err != nil {
 			return -1, fmt.Errorf("error writing WAL entry: %v", err)
 		}
 
@@ -539,6 +567,26 @@ func (l *WAL) DeleteBucketRange(orgID, bucketID influxdb.ID, min, max int64) (in
 	return id, nil
 }
 
+// DeleteSeriesRange deletes the data of the keys between the two times, returning
+// the segment ID for the operation.
+func (l *WAL) DeleteSeriesRange(keys [][]byte, min, max int64) (int, error) {
+	if !l.enabled {
+		return -1, nil
+	}
+
+	entry := &DeleteSeriesRangeWALEntry{
+		Keys: keys,
+		Min:  min,
+		Max:  max,
+	}
+
+	id, err := l.writeToLog(entry)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
 // Close will finish any flush that is currently in progress and close file handles.
 func (l *WAL) Close() error {
 	l.mu.Lock()
@@ -1057,6 +1105,81 @@ func (w *DeleteBucketRangeWALEntry) Type() WalEntryType {
 	return DeleteBucketRangeWALEntryType
 }
 
+// DeleteSeriesRangeWALEntry represents the deletion of the data of series keys.
+type DeleteSeriesRangeWALEntry struct {
+	Keys     [][]byte
+	Min, Max int64
+}
+
+// MarshalBinary returns a binary representation of the entry in a new byte slice.
+func (w *DeleteSeriesRangeWALEntry) MarshalBinary() ([]byte, error) {
+	b := make([]byte, w.MarshalSize())
+	return w.Encode(b)
+}
+
+// UnmarshalBinary deserializes the byte slice into w.
+func (w *DeleteSeriesRangeWALEntry) UnmarshalBinary(b []byte) error {
+	if len(b) < 16 {
+		return ErrWALCorrupt
+	}
+
+	w.Min = int64(binary.BigEndian.Uint64(b[0:8]))
+	w.Max = int64(binary.BigEndian.Uint64(b[8:16]))
+	w.Keys = w.Keys[:0]
+
+	for i := 16; i < len(b); {
+		if i+4 > len(b) {
+			return ErrWALCorrupt
+		}
+		sz := int(binary.BigEndian.Uint32(b[i : i+4]))
+		i += 4
+
+		if sz == 0 || i+sz > len(b) {
+			return ErrWALCorrupt
+		}
+		key := make([]byte, sz)
+		copy(key, b[i:i+sz])
+		w.Keys = append(w.Keys, key)
+		i += sz
+	}
+	return nil
+}
+
+// MarshalSize returns the number of bytes the entry takes when marshaled.
+func (w *DeleteSeriesRangeWALEntry) MarshalSize() int {
+	sz := 16
+	for _, k := range w.Keys {
+		sz += 4 + len(k)
+	}
+	return sz
+}
+
+// Encode converts the entry into a byte stream using b if it is large enough.
+// If b is too small, a newly allocated slice is returned.
+func (w *DeleteSeriesRangeWALEntry) Encode(b []byte) ([]byte, error) {
+	sz := w.MarshalSize()
+	if len(b) < sz {
+		b = make([]byte, sz)
+	}
+
+	binary.BigEndian.PutUint64(b[0:8], uint64(w.Min))
+	binary.BigEndian.PutUint64(b[8:16], uint64(w.Max))
+
+	i := 16
+	for _, k := range w.Keys {
+		binary.BigEndian.PutUint32(b[i:i+4], uint32(len(k)))
+		i += 4
+		i += copy(b[i:], k)
+	}
+
+	return b[:sz], nil
+}
+
+// Type returns DeleteSeriesRangeWALEntryType.
+func (w *DeleteSeriesRangeWALEntry) Type() WalEntryType {
+	return DeleteSeriesRangeWALEntryType
+}
+
 // WALSegmentWriter writes WAL segments.
 type WALSegmentWriter struct {
 	bw   *bufio.Writer
@@ -1129,6 +1252,8 @@ type WALSegmentReader struct {
 	entry WALEntry
 	n     int64
 	err   error
+
+	keyring *encryption.Keyring
 }
 
 // NewWALSegmentReader returns a new WALSegmentReader reading from r.
@@ -1139,6 +1264,11 @@ func NewWALSegmentReader(r io.ReadCloser) *WALSegmentReader {
 	}
 }
 
+// WithKeyring sets the keyring decrypting the encrypted entries.
+func (r *WALSegmentReader) WithKeyring(keyring *encryption.Keyring) {
+	r.keyring = keyring
+}
+
 func (r *WALSegmentReader) Reset(rc io.ReadCloser) {
 	r.rc = rc
 	r.r.Reset(rc)
@@ -1180,7 +1310,20 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
-	decLen, err := snappy.DecodedLen(b[:length])
+	compressed := b[:length]
+	if WalEntryType(entryType)&encryptedEntryFlag != 0 {
+		if r.keyring == nil {
+			r.err = decryptError{ErrNoKeyring}
+			return true
+		}
+		if compressed, err = r.keyring.Decrypt(nil, compressed); err != nil {
+			r.err = decryptError{err}
+			return true
+		}
+		entryType &^= byte(encryptedEntryFlag)
+	}
+
+	decLen, err := snappy.DecodedLen(compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1188,7 +1331,7 @@ func (r *WALSegmentReader) Next() bool {
 	decBuf := *(getBuf(decLen))
 	defer putBuf(&decBuf)
 
-	data, err := snappy.Decode(decBuf, b[:length])
+	data, err := snappy.Decode(decBuf, compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1202,6 +1345,8 @@ func (r *WALSegmentReader) Next() bool {
 		}
 	case DeleteBucketRangeWALEntryType:
 		r.entry = &DeleteBucketRangeWALEntry{}
+	case DeleteSeriesRangeWALEntryType:
+		r.entry = &DeleteSeriesRangeWALEntry{}
 	default:
 		r.err = fmt.Errorf("unknown wal entry type: %v", entryType)
 		return true
@@ -1215,6 +1360,16 @@ func (r *WALSegmentReader) Next() bool {
 	return true
 }
 
+// decryptError is the error of an entry that can not be decrypted. Unlike a corrupt
+// entry, it is not truncated from the segment, as it can be read with the right keyring.
+type decryptError struct {
+	err error
+}
+
+func (e decryptError) Error() string {
+	return fmt.Sprintf("error decrypting WAL entry: %v", e.err)
+}
+
 // Read returns the next entry in the reader.
 func (r *WALSegmentReader) Read() (WALEntry, error) {
 	if r.err != nil {
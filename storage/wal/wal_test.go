@@ -1,9 +1,11 @@
 package wal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"reflect"
@@ -12,6 +14,7 @@ import (
 	"github.com/golang/snappy"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb/value"
 )
 
@@ -307,6 +310,82 @@ func TestWAL_ClosedSegments(t *testing.T) {
 	}
 }
 
+func TestWAL_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keyring, err := encryption.NewKeyring(encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWAL(dir)
+	w.WithKeyring(keyring)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+		"cpu,host=A#!~#value": []value.Value{
+			value.NewValue(1, "secret-value"),
+		},
+	}); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+	if _, err := w.DeleteBucketRange(influxdb.ID(1), influxdb.ID(2), 0, 10); err != nil {
+		t.Fatalf("error deleting bucket range: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+
+	files, err := SegmentFileNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Fatalf("unexpected segments: %v", files)
+	}
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("secret-value")) {
+		t.Fatal("expected the entries to be encrypted")
+	}
+
+	// The segment can not be read without the keyring, and is not truncated as corrupt.
+	if err := NewWALReader(files).Read(func(WALEntry) error { return nil }); err == nil {
+		t.Fatal("expected an error reading encrypted entries without a keyring")
+	}
+	if stat, err := os.Stat(files[0]); err != nil {
+		t.Fatal(err)
+	} else if stat.Size() != int64(len(b)) {
+		t.Fatalf("segment truncated: got %d bytes, exp %d", stat.Size(), len(b))
+	}
+
+	var entries []WALEntry
+	r := NewWALReader(files)
+	r.WithKeyring(keyring)
+	if err := r.Read(func(entry WALEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("error reading WAL: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	if e, ok := entries[0].(*WriteWALEntry); !ok {
+		t.Fatalf("expected WriteWALEntry: got %#v", entries[0])
+	} else if got := e.Values["cpu,host=A#!~#value"][0].Value(); got != "secret-value" {
+		t.Fatalf("unexpected value: %v", got)
+	}
+	if e, ok := entries[1].(*DeleteBucketRangeWALEntry); !ok {
+		t.Fatalf("expected DeleteBucketRangeWALEntry: got %#v", entries[1])
+	} else if e.OrgID != 1 || e.BucketID != 2 || e.Max != 10 {
+		t.Fatalf("unexpected delete entry: %#v", e)
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -364,6 +443,59 @@ func TestWALWriter_Corrupt(t *testing.T) {
 	}
 }
 
+func TestReadSegment_Corrupt(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+	w := NewWALSegmentWriter(f)
+	corruption := []byte{1, 4, 0, 0, 0}
+
+	entries := []WALEntry{
+		&WriteWALEntry{Values: map[string][]value.Value{
+			"cpu,host=A#!~#float": []value.Value{value.NewValue(1, 1.1)},
+		}},
+		&DeleteBucketRangeWALEntry{OrgID: 1, BucketID: 2, Min: 0, Max: 10},
+	}
+	for _, entry := range entries {
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			fatal(t, "write entry", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+	size := MustReadFileSize(f)
+	if _, err := f.Write(corruption); err != nil {
+		fatal(t, "corrupt WAL segment", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		fatal(t, "seek", err)
+	}
+	var got []WALEntry
+	err := ReadSegment(f, nil, func(entry WALEntry) error {
+		got = append(got, entry)
+		return nil
+	})
+	if e, ok := err.(*SegmentCorruptError); !ok || e.Offset != size {
+		t.Fatalf("expected a corrupt segment error at offset %d, got %v", size, err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(got))
+	}
+	if e, ok := got[0].(*WriteWALEntry); !ok || !reflect.DeepEqual(e.Values, entries[0].(*WriteWALEntry).Values) {
+		t.Fatalf("unexpected write entry %v", got[0])
+	}
+	if !reflect.DeepEqual(got[1], entries[1]) {
+		t.Fatalf("unexpected delete entry %v, exp %v", got[1], entries[1])
+	}
+
+	// The segment is left as is.
+	if n := MustReadFileSize(f); n != size+int64(len(corruption)) {
+		t.Fatalf("segment was modified: got size %d, exp %d", n, size+int64(len(corruption)))
+	}
+}
+
 // Reproduces a `panic: runtime error: makeslice: cap out of range` when run with
 // GOARCH=386 go test -run TestWALSegmentReader_Corrupt -v ./tsdb/engine/tsm1/
 func TestWALSegmentReader_Corrupt(t *testing.T) {
@@ -496,6 +628,41 @@ func TestWriteWALSegment_UnmarshalBinary_DeleteBucketRangeWALCorrupt(t *testing.
 	}
 }
 
+func TestDeleteSeriesRangeWALEntry_UnmarshalBinary(t *testing.T) {
+	in := &DeleteSeriesRangeWALEntry{
+		Keys: [][]byte{
+			[]byte("cpu,host=a#!~#value"),
+			[]byte("cpu,host=b#!~#value"),
+		},
+		Min: 3,
+		Max: 4,
+	}
+
+	b, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error, got %v", err)
+	}
+
+	out := &DeleteSeriesRangeWALEntry{}
+	if err := out.UnmarshalBinary(b); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("got %+v, expected %+v", out, in)
+	}
+
+	// Test every possible truncation of the entry
+	for i := 0; i < len(b); i++ {
+		truncated := make([]byte, i)
+		copy(truncated, b[:i])
+		err := out.UnmarshalBinary(truncated)
+		if err != nil && err != ErrWALCorrupt {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
 func BenchmarkWALSegmentWriter(b *testing.B) {
 	points := map[string][]value.Value{}
 	for i := 0; i < 5000; i++ {
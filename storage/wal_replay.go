@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// WALSegmentStats describes a WAL segment read by ReadWALSegment.
+type WALSegmentStats struct {
+	// Points is the number of points written by the segment, after its deletes.
+	Points int `json:"points"`
+
+	// Deletes is the number of delete entries of the segment.
+	Deletes int `json:"deletes"`
+
+	// Corrupt is the error of the first corrupt entry of the segment, if any.
+	// The entries after it are not read.
+	Corrupt string `json:"corrupt,omitempty"`
+}
+
+// WALSegmentReader reads the points written by WAL segments, such as those of a snapshot,
+// to replay them into the engine after a partial data loss.
+type WALSegmentReader interface {
+	ReadWALSegment(r io.Reader) ([]models.Point, WALSegmentStats, error)
+}
+
+// ReadWALSegment returns the points written by the segment read from r, whose encrypted
+// entries are decrypted with keyring. The points are exploded, as written to the engine.
+//
+// The deletes of the segment remove the points written before them in the segment, but are
+// otherwise ignored, as replaying them could delete the data written since the segment was.
+func ReadWALSegment(r io.Reader, keyring *encryption.Keyring) ([]models.Point, WALSegmentStats, error) {
+	var (
+		points []models.Point
+		stats  WALSegmentStats
+	)
+	err := wal.ReadSegment(r, keyring, func(entry wal.WALEntry) error {
+		switch en := entry.(type) {
+		case *wal.WriteWALEntry:
+			points = append(points, tsm1.ValuesToPoints(en.Values)...)
+
+		case *wal.DeleteBucketRangeWALEntry:
+			stats.Deletes++
+			name := tsdb.EncodeName(en.OrgID, en.BucketID)
+			points = filterPoints(points, func(pt models.Point) bool {
+				return string(pt.Name()) == string(name[:]) && inRange(pt, en.Min, en.Max)
+			})
+
+		case *wal.DeleteSeriesRangeWALEntry:
+			stats.Deletes++
+			keys := make(map[string]struct{}, len(en.Keys))
+			for _, key := range en.Keys {
+				keys[string(key)] = struct{}{}
+			}
+			points = filterPoints(points, func(pt models.Point) bool {
+				_, ok := keys[string(pt.Key())]
+				return ok && inRange(pt, en.Min, en.Max)
+			})
+		}
+		return nil
+	})
+	if e, ok := err.(*wal.SegmentCorruptError); ok {
+		stats.Corrupt = e.Error()
+		err = nil
+	}
+	if err != nil {
+		return nil, WALSegmentStats{}, err
+	}
+
+	stats.Points = len(points)
+	return points, stats, nil
+}
+
+// ReadWALSegment returns the points written by the segment read from r,
+// whose encrypted entries are decrypted with the keyring of the engine.
+func (e *Engine) ReadWALSegment(r io.Reader) ([]models.Point, WALSegmentStats, error) {
+	return ReadWALSegment(r, e.keyring)
+}
+
+// filterPoints removes the points matching deleted from points, in place.
+func filterPoints(points []models.Point, deleted func(models.Point) bool) []models.Point {
+	kept := points[:0]
+	for _, pt := range points {
+		if !deleted(pt) {
+			kept = append(kept, pt)
+		}
+	}
+	return kept
+}
+
+func inRange(pt models.Point, min, max int64) bool {
+	t := pt.UnixNano()
+	return t >= min && t <= max
+}
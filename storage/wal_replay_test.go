@@ -0,0 +1,91 @@
+package storage_test
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestReadWALSegment(t *testing.T) {
+	config := storage.NewConfig()
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	var points []models.Point
+	for i, host := range []string{"a", "b"} {
+		points = append(points, models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(int64(i+1), 0),
+		))
+	}
+	if err := engine.Write1xPoints(points); err != nil {
+		t.Fatal(err)
+	}
+	// The deleted point is not replayed, but the point written after the delete is.
+	if err := engine.DeleteBucketRange(engine.org, engine.bucket, 0, time.Unix(1, 0).UnixNano()); err != nil {
+		t.Fatal(err)
+	}
+	points[0].SetTime(time.Unix(0, 5))
+	if err := engine.Write1xPoints(points[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := wal.SegmentFileNames(config.GetWALPath(engine.path))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) == 0 {
+		t.Fatal("expected a WAL segment")
+	}
+
+	var (
+		lines   []string
+		deletes int
+	)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points, stats, err := storage.ReadWALSegment(f, nil)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Points != len(points) || stats.Corrupt != "" {
+			t.Fatalf("unexpected stats %+v of %d points", stats, len(points))
+		}
+		deletes += stats.Deletes
+
+		for _, pt := range points {
+			org, bucket, pt, err := tsdb.UnexplodePoint(pt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if org != engine.org || bucket != engine.bucket {
+				t.Fatalf("unexpected organization %s and bucket %s", org, bucket)
+			}
+			lines = append(lines, pt.String())
+		}
+	}
+	sort.Strings(lines)
+
+	if deletes != 1 {
+		t.Fatalf("expected 1 delete, got %d", deletes)
+	}
+	if exp := []string{"cpu,host=a value=0i 5", "cpu,host=b value=1i 2000000000"}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected points %q, exp %q", lines, exp)
+	}
+}
@@ -0,0 +1,42 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// SubsystemState is the state of a subsystem of influxd.
+type SubsystemState string
+
+// The states of a subsystem.
+const (
+	// SubsystemStopped is the state of a subsystem not started yet, or stopped.
+	SubsystemStopped SubsystemState = "stopped"
+	// SubsystemRunning is the state of a subsystem started successfully.
+	SubsystemRunning SubsystemState = "running"
+	// SubsystemFailed is the state of a subsystem that failed to start or to stop.
+	SubsystemFailed SubsystemState = "failed"
+)
+
+// SubsystemStatus is the status of a subsystem of influxd, such as the storage engine or the task scheduler.
+type SubsystemStatus struct {
+	Name  string         `json:"name"`
+	State SubsystemState `json:"state"`
+	// Since is when the subsystem entered its state.
+	Since time.Time `json:"since"`
+	// Healthy is false if the subsystem failed, or runs but reports a problem.
+	Healthy bool `json:"healthy"`
+	// Message explains why the subsystem is not healthy.
+	Message string `json:"message,omitempty"`
+	// Restartable is true if the subsystem can be restarted without restarting influxd.
+	Restartable bool `json:"restartable"`
+}
+
+// SubsystemService views and restarts the subsystems of influxd.
+type SubsystemService interface {
+	// FindSubsystems returns the status of every subsystem, in the order they start.
+	FindSubsystems(ctx context.Context) ([]*SubsystemStatus, error)
+
+	// RestartSubsystem stops and starts again a restartable subsystem, and returns its new status.
+	RestartSubsystem(ctx context.Context, name string) (*SubsystemStatus, error)
+}
@@ -0,0 +1,265 @@
+// Package subsystem manages the lifecycle of the subsystems of influxd,
+// such as the storage engine, the task scheduler or the HTTP server.
+package subsystem
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// Subsystem is a part of influxd that can be started and stopped.
+type Subsystem interface {
+	// Start starts the subsystem, which runs until ctx is done or Stop is called.
+	Start(ctx context.Context) error
+	// Stop stops the subsystem, waiting for it to clean up until ctx is done.
+	Stop(ctx context.Context) error
+	// Health returns an error if the running subsystem has a problem.
+	Health(ctx context.Context) error
+}
+
+// Funcs adapts functions to the Subsystem interface.
+// A nil function does nothing and returns nil.
+type Funcs struct {
+	StartFn  func(ctx context.Context) error
+	StopFn   func(ctx context.Context) error
+	HealthFn func(ctx context.Context) error
+}
+
+var _ Subsystem = Funcs{}
+
+// Start calls StartFn.
+func (f Funcs) Start(ctx context.Context) error {
+	if f.StartFn == nil {
+		return nil
+	}
+	return f.StartFn(ctx)
+}
+
+// Stop calls StopFn.
+func (f Funcs) Stop(ctx context.Context) error {
+	if f.StopFn == nil {
+		return nil
+	}
+	return f.StopFn(ctx)
+}
+
+// Health calls HealthFn.
+func (f Funcs) Health(ctx context.Context) error {
+	if f.HealthFn == nil {
+		return nil
+	}
+	return f.HealthFn(ctx)
+}
+
+// entry is a registered subsystem.
+type entry struct {
+	name        string
+	s           Subsystem
+	restartable bool
+
+	state influxdb.SubsystemState
+	since time.Time
+	err   error // Why the subsystem failed, if it did.
+}
+
+// Registry starts and stops subsystems in order, and reports their status.
+// Subsystems start in the order they are registered and stop in reverse order,
+// so that a subsystem is registered after the subsystems it depends on.
+type Registry struct {
+	logger *zap.Logger
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	ctx        context.Context // The context the subsystems run in, set by Start.
+	subsystems []*entry
+}
+
+var _ influxdb.SubsystemService = (*Registry)(nil)
+
+// NewRegistry returns an empty registry.
+func NewRegistry(logger *zap.Logger) *Registry {
+	return &Registry{
+		logger: logger,
+		Now:    time.Now,
+	}
+}
+
+// Register adds the subsystem s named name to r.
+// A restartable subsystem can be restarted through RestartSubsystem while the others run,
+// which is only safe if no other subsystem holds on to its state.
+func (r *Registry) Register(name string, s Subsystem, restartable bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.subsystems {
+		if e.name == name {
+			panic(fmt.Sprintf("subsystem %s registered twice", name))
+		}
+	}
+	r.subsystems = append(r.subsystems, &entry{
+		name:        name,
+		s:           s,
+		restartable: restartable,
+		state:       influxdb.SubsystemStopped,
+		since:       r.Now(),
+	})
+}
+
+// Start starts, in order, the registered subsystems not started yet.
+// The subsystems run in ctx, and are restarted in it.
+// Start may be called again after registering more subsystems.
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ctx = ctx
+	for _, e := range r.subsystems {
+		if e.state == influxdb.SubsystemRunning {
+			continue
+		}
+		if err := r.start(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops the running subsystems in reverse order.
+// It stops every subsystem even if some fail to stop, and returns the first error.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for i := len(r.subsystems) - 1; i >= 0; i-- {
+		e := r.subsystems[i]
+		if e.state != influxdb.SubsystemRunning {
+			continue
+		}
+		if err := r.stop(ctx, e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FindSubsystems returns the status of every subsystem, in the order they start.
+func (r *Registry) FindSubsystems(ctx context.Context) ([]*influxdb.SubsystemStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ss := make([]*influxdb.SubsystemStatus, 0, len(r.subsystems))
+	for _, e := range r.subsystems {
+		ss = append(ss, r.status(ctx, e))
+	}
+	return ss, nil
+}
+
+// RestartSubsystem stops and starts again a restartable subsystem, and returns its new status.
+// A restartable subsystem that failed is started again.
+func (r *Registry) RestartSubsystem(ctx context.Context, name string) (*influxdb.SubsystemStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var e *entry
+	for _, s := range r.subsystems {
+		if s.name == name {
+			e = s
+		}
+	}
+	if e == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("subsystem %s not found", name),
+		}
+	}
+	if !e.restartable {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("subsystem %s cannot be restarted while influxd runs", name),
+		}
+	}
+	if r.ctx == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "subsystems have not started",
+		}
+	}
+
+	r.logger.Info("Restarting", zap.String("service", name))
+	if e.state == influxdb.SubsystemRunning {
+		if err := r.stop(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+	// The subsystem runs in the context of the registry, not the one of the request restarting it.
+	if err := r.start(e); err != nil {
+		return nil, err
+	}
+	return r.status(ctx, e), nil
+}
+
+// start starts e in r.ctx. r.mu must be held.
+func (r *Registry) start(e *entry) error {
+	if err := e.s.Start(r.ctx); err != nil {
+		r.logger.Error("Failed to start", zap.String("service", e.name), zap.Error(err))
+		r.setState(e, influxdb.SubsystemFailed, err)
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("failed to start subsystem %s", e.name),
+			Err:  err,
+		}
+	}
+	r.setState(e, influxdb.SubsystemRunning, nil)
+	return nil
+}
+
+// stop stops e. r.mu must be held.
+func (r *Registry) stop(ctx context.Context, e *entry) error {
+	r.logger.Info("Stopping", zap.String("service", e.name))
+	if err := e.s.Stop(ctx); err != nil {
+		r.logger.Error("Failed to stop", zap.String("service", e.name), zap.Error(err))
+		r.setState(e, influxdb.SubsystemFailed, err)
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("failed to stop subsystem %s", e.name),
+			Err:  err,
+		}
+	}
+	r.setState(e, influxdb.SubsystemStopped, nil)
+	return nil
+}
+
+func (r *Registry) setState(e *entry, state influxdb.SubsystemState, err error) {
+	e.state = state
+	e.since = r.Now()
+	e.err = err
+}
+
+// status returns the status of e, checking its health if it runs. r.mu must be held.
+func (r *Registry) status(ctx context.Context, e *entry) *influxdb.SubsystemStatus {
+	s := &influxdb.SubsystemStatus{
+		Name:        e.name,
+		State:       e.state,
+		Since:       e.since,
+		Healthy:     e.state != influxdb.SubsystemFailed,
+		Restartable: e.restartable,
+	}
+	switch e.state {
+	case influxdb.SubsystemFailed:
+		s.Message = e.err.Error()
+	case influxdb.SubsystemRunning:
+		if err := e.s.Health(ctx); err != nil {
+			s.Healthy = false
+			s.Message = err.Error()
+		}
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package subsystem_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/subsystem"
+	"go.uber.org/zap/zaptest"
+)
+
+// recorder returns a subsystem appending its lifecycle events to events.
+func recorder(name string, events *[]string) subsystem.Funcs {
+	return subsystem.Funcs{
+		StartFn: func(context.Context) error {
+			*events = append(*events, "start "+name)
+			return nil
+		},
+		StopFn: func(context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func states(t *testing.T, r *subsystem.Registry) map[string]influxdb.SubsystemState {
+	t.Helper()
+	ss, err := r.FindSubsystems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]influxdb.SubsystemState, len(ss))
+	for _, s := range ss {
+		m[s.Name] = s.State
+	}
+	return m
+}
+
+func TestRegistry_StartStop(t *testing.T) {
+	ctx := context.Background()
+	var events []string
+	r := subsystem.NewRegistry(zaptest.NewLogger(t))
+	r.Register("storage", recorder("storage", &events), false)
+	r.Register("scheduler", recorder("scheduler", &events), false)
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting again only starts the subsystems registered since.
+	r.Register("http", recorder("http", &events), false)
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"start storage", "start scheduler", "start http",
+		"stop http", "stop scheduler", "stop storage",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("unexpected lifecycle events:\nwant %v\ngot  %v", want, events)
+	}
+	for name, state := range states(t, r) {
+		if state != influxdb.SubsystemStopped {
+			t.Errorf("expected %s stopped, got %s", name, state)
+		}
+	}
+}
+
+func TestRegistry_StartFailure(t *testing.T) {
+	var events []string
+	r := subsystem.NewRegistry(zaptest.NewLogger(t))
+	r.Register("storage", recorder("storage", &events), false)
+	r.Register("nats", subsystem.Funcs{
+		StartFn: func(context.Context) error { return errors.New("port in use") },
+	}, false)
+	r.Register("http", recorder("http", &events), false)
+
+	if err := r.Start(context.Background()); influxdb.ErrorCode(err) != influxdb.EInternal {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+
+	want := map[string]influxdb.SubsystemState{
+		"storage": influxdb.SubsystemRunning,
+		"nats":    influxdb.SubsystemFailed,
+		"http":    influxdb.SubsystemStopped,
+	}
+	if got := states(t, r); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected states:\nwant %v\ngot  %v", want, got)
+	}
+}
+
+func TestRegistry_Health(t *testing.T) {
+	r := subsystem.NewRegistry(zaptest.NewLogger(t))
+	r.Register("gather", subsystem.Funcs{
+		HealthFn: func(context.Context) error { return errors.New("scraper stopped") },
+	}, true)
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := r.FindSubsystems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].Healthy || ss[0].Message != "scraper stopped" {
+		t.Errorf("expected an unhealthy subsystem, got %+v", ss)
+	}
+}
+
+func TestRegistry_RestartSubsystem(t *testing.T) {
+	var (
+		events []string
+		runCtx context.Context
+	)
+	r := subsystem.NewRegistry(zaptest.NewLogger(t))
+	r.Register("storage", recorder("storage", &events), false)
+	r.Register("gather", subsystem.Funcs{
+		StartFn: func(ctx context.Context) error {
+			runCtx = ctx
+			events = append(events, "start gather")
+			return nil
+		},
+		StopFn: func(context.Context) error {
+			events = append(events, "stop gather")
+			return nil
+		},
+	}, true)
+
+	ctx := context.Background()
+	if _, err := r.RestartSubsystem(ctx, "gather"); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected restarting before starting to be unavailable, got %v", err)
+	}
+
+	type key struct{}
+	runningCtx := context.WithValue(ctx, key{}, "running")
+	if err := r.Start(runningCtx); err != nil {
+		t.Fatal(err)
+	}
+	events = nil
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s, err := r.RestartSubsystem(reqCtx, "gather")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.State != influxdb.SubsystemRunning || !s.Healthy {
+		t.Errorf("expected the restarted subsystem running, got %+v", s)
+	}
+	if want := []string{"stop gather", "start gather"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("unexpected lifecycle events:\nwant %v\ngot  %v", want, events)
+	}
+	if runCtx.Value(key{}) != "running" {
+		t.Error("expected the restarted subsystem to run in the context of the registry")
+	}
+
+	if _, err := r.RestartSubsystem(ctx, "storage"); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected restarting an unsafe subsystem to conflict, got %v", err)
+	}
+	if _, err := r.RestartSubsystem(ctx, "nats"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected restarting an unknown subsystem to be not found, got %v", err)
+	}
+}
@@ -35,9 +35,14 @@ type Task struct {
 	Every           string `json:"every,omitempty"`
 	Cron            string `json:"cron,omitempty"`
 	Offset          string `json:"offset,omitempty"`
+	Timezone        string `json:"timezone,omitempty"`
+	EffectiveCron   string `json:"effectiveCron,omitempty"`
 	LatestCompleted string `json:"latestCompleted,omitempty"`
 	CreatedAt       string `json:"createdAt,omitempty"`
 	UpdatedAt       string `json:"updatedAt,omitempty"`
+
+	// Params are injected into Flux as string options when the task runs. See InjectTaskParams.
+	Params map[string]string `json:"params,omitempty"`
 }
 
 // Run is a record created when a run of a task is scheduled.
@@ -49,7 +54,18 @@ type Run struct {
 	StartedAt    string `json:"startedAt,omitempty"`
 	FinishedAt   string `json:"finishedAt,omitempty"`
 	RequestedAt  string `json:"requestedAt,omitempty"`
-	Log          []Log  `json:"log"`
+	// Attempt is the attempt number of the run, starting at 1 and incremented on every retry.
+	// It is zero for runs recorded before attempts were tracked.
+	Attempt int   `json:"attempt,omitempty"`
+	Log     []Log `json:"log"`
+}
+
+// TaskVersion is a previous revision of a task's Flux script, including its task options.
+type TaskVersion struct {
+	TaskID     ID     `json:"taskID"`
+	Version    int    `json:"version"`
+	Flux       string `json:"flux"`
+	ReplacedAt string `json:"replacedAt"`
 }
 
 // Log represents a link to a log resource
@@ -99,6 +115,16 @@ type TaskService interface {
 	// ForceRun forces a run to occur with unix timestamp scheduledFor, to be executed as soon as possible.
 	// The value of scheduledFor may or may not align with the task's schedule.
 	ForceRun(ctx context.Context, taskID ID, scheduledFor int64) (*Run, error)
+
+	// FindTaskVersions returns the previous revisions of a task, oldest first.
+	FindTaskVersions(ctx context.Context, taskID ID) ([]*TaskVersion, error)
+
+	// RollbackTask restores the Flux script of a previous revision of a task.
+	// The script being replaced is itself kept as a new revision.
+	RollbackTask(ctx context.Context, taskID ID, version int) (*Task, error)
+
+	// MoveTask transfers a task to another organization, along with its run history if requested.
+	MoveTask(ctx context.Context, taskID ID, move TaskMove) (*Task, error)
 }
 
 // TaskCreate is the set of values to create a task.
@@ -108,6 +134,8 @@ type TaskCreate struct {
 	OrganizationID ID     `json:"orgID,omitempty"`
 	Organization   string `json:"org,omitempty"`
 	Token          string `json:"token,omitempty"`
+
+	Params map[string]string `json:"params,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
@@ -119,6 +147,27 @@ func (t TaskCreate) Validate() error {
 	case t.Status != "" && t.Status != TaskStatusActive && t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", t.Status)
 	}
+	return ValidateTaskParams(t.Params)
+}
+
+// TaskMove is the set of values to move a task to another organization.
+type TaskMove struct {
+	// OrganizationID is the organization the task is moved to.
+	OrganizationID ID `json:"orgID"`
+
+	// Token is the token the task runs with once moved.
+	// It must belong to the destination organization.
+	// If empty, the authorization of the request is used.
+	Token string `json:"token,omitempty"`
+
+	// MoveRuns copies the run history of the task to the destination organization.
+	MoveRuns bool `json:"moveRuns,omitempty"`
+}
+
+func (t TaskMove) Validate() error {
+	if !t.OrganizationID.Valid() {
+		return errors.New("missing orgID")
+	}
 	return nil
 }
 
@@ -131,6 +180,10 @@ type TaskUpdate struct {
 
 	// Optional token override.
 	Token string `json:"token,omitempty"`
+
+	// Params replace all the params of the task.
+	// If nil, the params are not modified; an empty, non-nil map removes them.
+	Params map[string]string `json:"params,omitempty"`
 }
 
 func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
@@ -155,7 +208,14 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 
 		Retry *int64 `json:"retry,omitempty"`
 
+		Weight *int64 `json:"weight,omitempty"`
+
+		// Timezone is the time zone the cron schedule is interpreted in.
+		Timezone string `json:"timezone,omitempty"`
+
 		Token string `json:"token,omitempty"`
+
+		Params map[string]string `json:"params,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -170,9 +230,12 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	}
 	t.Options.Concurrency = jo.Concurrency
 	t.Options.Retry = jo.Retry
+	t.Options.Weight = jo.Weight
+	t.Options.Timezone = jo.Timezone
 	t.Flux = jo.Flux
 	t.Status = jo.Status
 	t.Token = jo.Token
+	t.Params = jo.Params
 
 	return nil
 }
@@ -196,7 +259,15 @@ func (t TaskUpdate) MarshalJSON() ([]byte, error) {
 
 		Retry *int64 `json:"retry,omitempty"`
 
+		Weight *int64 `json:"weight,omitempty"`
+
+		// Timezone is the time zone the cron schedule is interpreted in.
+		Timezone string `json:"timezone,omitempty"`
+
 		Token string `json:"token,omitempty"`
+
+		// Params is a pointer so that an empty map, which removes the params, is not omitted.
+		Params *map[string]string `json:"params,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
@@ -207,9 +278,14 @@ func (t TaskUpdate) MarshalJSON() ([]byte, error) {
 	}
 	jo.Concurrency = t.Options.Concurrency
 	jo.Retry = t.Options.Retry
+	jo.Weight = t.Options.Weight
+	jo.Timezone = t.Options.Timezone
 	jo.Flux = t.Flux
 	jo.Status = t.Status
 	jo.Token = t.Token
+	if t.Params != nil {
+		jo.Params = &t.Params
+	}
 	return json.Marshal(jo)
 }
 
@@ -217,12 +293,12 @@ func (t TaskUpdate) Validate() error {
 	switch {
 	case t.Options.Every != 0 && t.Options.Cron != "":
 		return errors.New("cannot specify both every and cron")
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero() && t.Token == "":
+	case t.Flux == nil && t.Status == nil && t.Options.IsZero() && t.Token == "" && t.Params == nil:
 		return errors.New("cannot update task without content")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
 	}
-	return nil
+	return ValidateTaskParams(t.Params)
 }
 
 // UpdateFlux updates the TaskUpdate to go from updating options to updating a flux string, that now has those updated options in it
@@ -260,6 +336,15 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 			toDelete["offset"] = struct{}{}
 		}
 	}
+	if t.Options.Weight != nil {
+		op["weight"] = &ast.IntegerLiteral{Value: *t.Options.Weight}
+	}
+	if t.Options.Timezone != "" {
+		op["timezone"] = &ast.StringLiteral{Value: t.Options.Timezone}
+	} else if t.Options.Every != 0 {
+		// The timezone option only applies to cron schedules.
+		toDelete["timezone"] = struct{}{}
+	}
 	if len(op) > 0 || len(toDelete) > 0 {
 		editFunc := func(opt *ast.OptionStatement) (ast.Expression, error) {
 			a, ok := opt.Assignment.(*ast.VariableAssignment)
@@ -270,12 +355,18 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 			if !ok {
 				return nil, fmt.Errorf("value is is %s, not an object expression", a.Init.Type())
 			}
+			// remove the deleted keys from the ast
+			props := obj.Properties[:0]
+			for _, p := range obj.Properties {
+				if _, ok := toDelete[p.Key.Key()]; !ok {
+					props = append(props, p)
+				}
+			}
+			obj.Properties = props
+
 			// modify in the keys and values that already are in the ast
-			for i, p := range obj.Properties {
+			for _, p := range obj.Properties {
 				k := p.Key.Key()
-				if _, ok := toDelete[k]; ok {
-					obj.Properties = append(obj.Properties[:i], obj.Properties[i+1:]...)
-				}
 				switch k {
 				case "name":
 					if name, ok := op["name"]; ok && t.Options.Name != "" {
@@ -287,6 +378,16 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 						delete(op, "offset")
 						p.Value = offset
 					}
+				case "weight":
+					if weight, ok := op["weight"]; ok {
+						delete(op, "weight")
+						p.Value = weight
+					}
+				case "timezone":
+					if timezone, ok := op["timezone"]; ok {
+						delete(op, "timezone")
+						p.Value = timezone
+					}
 				case "every":
 					if every, ok := op["every"]; ok && t.Options.Every != 0 {
 						delete(op, "every")
@@ -377,6 +478,10 @@ type RunFilter struct {
 	Limit      int
 	AfterTime  string
 	BeforeTime string
+
+	// Status limits the results to runs whose current status matches, e.g. "failed".
+	// If empty, runs of any status are returned.
+	Status string
 }
 
 // LogFilter represents a set of filters that restrict the returned log results.
@@ -9,6 +9,9 @@
 //    bucket(/tasks/v1/name_by_task_id) key(:task_id) -> The user-supplied name of the script.
 //    bucket(/tasks/v1/run_ids) -> Counter for run IDs
 //    bucket(/tasks/v1/orgs).bucket(:org_id) key(:task_id) -> Empty content; presence of :task_id allows for lookup from org to tasks.
+//    bucket(/tasks/v1/task_versions).bucket(:task_id) key(:version) -> JSON encoded backend.StoreTaskVersion,
+//                                    one entry for every script the task has had before its current one.
+//    bucket(/tasks/v1/params_by_task_id) key(:task_id) -> JSON encoded params of the task, if it has any.
 // Note that task IDs are stored big-endian uint64s for sorting purposes,
 // but presented to the users with leading 0-bytes stripped.
 // Like other components of the system, IDs presented to users may be `0f12` rather than `f12`.
@@ -16,6 +19,8 @@ package bolt
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -37,10 +42,10 @@ var ErrDBReadOnly = errors.New("db is read only")
 var ErrMaxConcurrency = errors.New("max concurrency reached")
 
 // ErrRunNotFound is an error for when a run isn't found in a FinishRun method.
-var ErrRunNotFound = errors.New("run not found")
+var ErrRunNotFound = backend.ErrRunNotFound
 
 // ErrNotFound is an error for when a task could not be found
-var ErrNotFound = errors.New("task not found")
+var ErrNotFound = backend.ErrTaskNotFound
 
 // Store is task store for bolt.
 type Store struct {
@@ -54,12 +59,14 @@ type Store struct {
 const basePath = "/tasks/v1/"
 
 var (
-	tasksPath    = []byte(basePath + "tasks")
-	orgsPath     = []byte(basePath + "orgs")
-	taskMetaPath = []byte(basePath + "task_meta")
-	orgByTaskID  = []byte(basePath + "org_by_task_id")
-	nameByTaskID = []byte(basePath + "name_by_task_id")
-	runIDs       = []byte(basePath + "run_ids")
+	tasksPath      = []byte(basePath + "tasks")
+	orgsPath       = []byte(basePath + "orgs")
+	taskMetaPath   = []byte(basePath + "task_meta")
+	orgByTaskID    = []byte(basePath + "org_by_task_id")
+	nameByTaskID   = []byte(basePath + "name_by_task_id")
+	runIDs         = []byte(basePath + "run_ids")
+	versionsPath   = []byte(basePath + "task_versions")
+	paramsByTaskID = []byte(basePath + "params_by_task_id")
 )
 
 // Option is a optional configuration for the store.
@@ -68,6 +75,11 @@ type Option func(*Store)
 // NoCatchUp allows you to skip any task that was supposed to run during down time.
 func NoCatchUp(st *Store) { st.minLatestCompleted = time.Now().Unix() }
 
+// WithIDGenerator sets the generator of the IDs of the tasks and runs of the store.
+func WithIDGenerator(gen platform.IDGenerator) Option {
+	return func(st *Store) { st.idGen = gen }
+}
+
 // New gives us a new Store based on "github.com/coreos/bbolt"
 func New(db *bolt.DB, rootBucket string, opts ...Option) (*Store, error) {
 	if db.IsReadOnly() {
@@ -85,6 +97,7 @@ func New(db *bolt.DB, rootBucket string, opts ...Option) (*Store, error) {
 		for _, b := range [][]byte{
 			tasksPath, orgsPath, taskMetaPath,
 			orgByTaskID, nameByTaskID, runIDs,
+			versionsPath, paramsByTaskID,
 		} {
 			_, err := root.CreateBucketIfNotExists(b)
 			if err != nil {
@@ -133,6 +146,11 @@ func (s *Store) CreateTask(ctx context.Context, req backend.CreateTaskRequest) (
 			return err
 		}
 
+		// params
+		if err := putTaskParams(b, encodedID, req.Params); err != nil {
+			return err
+		}
+
 		// Encode org ID
 		encodedOrg, err := req.Org.Encode()
 		if err != nil {
@@ -214,6 +232,11 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 			if err != nil {
 				return err
 			}
+			if req.Script != res.OldScript {
+				if err := putTaskVersion(b, encodedID, res.OldScript); err != nil {
+					return err
+				}
+			}
 			if err := bt.Put(encodedID, []byte(req.Script)); err != nil {
 				return err
 			}
@@ -228,6 +251,13 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 			return err
 		}
 
+		if req.Org.Valid() && req.Org != orgID {
+			if err := moveTaskOrg(b, encodedID, orgID, req.Org); err != nil {
+				return err
+			}
+			orgID = req.Org
+		}
+
 		stmBytes := b.Bucket(taskMetaPath).Get(encodedID)
 		if stmBytes == nil {
 			return backend.ErrTaskNotFound
@@ -254,11 +284,22 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 		}
 		res.NewMeta = stm
 
+		if req.Params != nil {
+			if err := putTaskParams(b, encodedID, req.Params); err != nil {
+				return err
+			}
+		}
+		params, err := getTaskParams(b, encodedID)
+		if err != nil {
+			return err
+		}
+
 		res.NewTask = backend.StoreTask{
 			ID:     req.ID,
 			Org:    orgID,
 			Name:   op.Name,
 			Script: newScript,
+			Params: params,
 		}
 
 		return nil
@@ -335,6 +376,9 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 				tasks[i].Task.ID = taskIDs[i]
 				tasks[i].Task.Script = string(b.Bucket(tasksPath).Get(encodedID))
 				tasks[i].Task.Name = string(b.Bucket(nameByTaskID).Get(encodedID))
+				if tasks[i].Task.Params, err = getTaskParams(b, encodedID); err != nil {
+					return err
+				}
 			}
 		}
 		if params.Org.Valid() {
@@ -404,6 +448,7 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.StoreTask, error) {
 	var orgID platform.ID
 	var script, name string
+	var params map[string]string
 	encodedID, err := id.Encode()
 	if err != nil {
 		return nil, err
@@ -421,7 +466,8 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 		}
 
 		name = string(b.Bucket(nameByTaskID).Get(encodedID))
-		return nil
+		params, err = getTaskParams(b, encodedID)
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -432,6 +478,7 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 		Org:    orgID,
 		Name:   name,
 		Script: script,
+		Params: params,
 	}, err
 }
 
@@ -465,6 +512,7 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 	var stmBytes []byte
 	var orgID platform.ID
 	var script, name string
+	var params map[string]string
 	encodedID, err := id.Encode()
 	if err != nil {
 		return nil, nil, err
@@ -485,7 +533,8 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 		}
 
 		name = string(b.Bucket(nameByTaskID).Get(encodedID))
-		return nil
+		params, err = getTaskParams(b, encodedID)
+		return err
 	})
 	if err != nil {
 		return nil, nil, err
@@ -506,9 +555,131 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 		Org:    orgID,
 		Name:   name,
 		Script: script,
+		Params: params,
 	}, &stm, nil
 }
 
+// ListTaskVersions returns the previous revisions of a task, oldest first.
+func (s *Store) ListTaskVersions(ctx context.Context, id platform.ID) ([]backend.StoreTaskVersion, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []backend.StoreTaskVersion{}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if check := b.Bucket(tasksPath).Get(encodedID); check == nil {
+			return backend.ErrTaskNotFound
+		}
+
+		vb := b.Bucket(versionsPath).Bucket(encodedID)
+		if vb == nil {
+			return nil
+		}
+		return vb.ForEach(func(_, v []byte) error {
+			var tv backend.StoreTaskVersion
+			if err := json.Unmarshal(v, &tv); err != nil {
+				return err
+			}
+			versions = append(versions, tv)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// moveTaskOrg moves the task with the given encoded ID from the index of the from organization to the one of to.
+func moveTaskOrg(b *bolt.Bucket, encodedID []byte, from, to platform.ID) error {
+	encodedFrom, err := from.Encode()
+	if err != nil {
+		return err
+	}
+	encodedTo, err := to.Encode()
+	if err != nil {
+		return err
+	}
+
+	if fromB := b.Bucket(orgsPath).Bucket(encodedFrom); fromB != nil {
+		if err := fromB.Delete(encodedID); err != nil {
+			return err
+		}
+	}
+
+	toB, err := b.Bucket(orgsPath).CreateBucketIfNotExists(encodedTo)
+	if err != nil {
+		return err
+	}
+	if err := toB.Put(encodedID, nil); err != nil {
+		return err
+	}
+
+	return b.Bucket(orgByTaskID).Put(encodedID, encodedTo)
+}
+
+// putTaskVersion records script as the next revision of the task with the given encoded ID.
+func putTaskVersion(b *bolt.Bucket, encodedID []byte, script string) error {
+	vb, err := b.Bucket(versionsPath).CreateBucketIfNotExists(encodedID)
+	if err != nil {
+		return err
+	}
+
+	seq, err := vb.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	v, err := json.Marshal(backend.StoreTaskVersion{
+		Version:    int64(seq),
+		Script:     script,
+		ReplacedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return vb.Put(key, v)
+}
+
+// deleteTaskVersions removes all revisions of the task with the given encoded ID.
+func deleteTaskVersions(b *bolt.Bucket, encodedID []byte) error {
+	if err := b.Bucket(versionsPath).DeleteBucket(encodedID); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return nil
+}
+
+// putTaskParams stores the params of the task with the given encoded ID, or removes them if params is empty.
+func putTaskParams(b *bolt.Bucket, encodedID []byte, params map[string]string) error {
+	if len(params) == 0 {
+		return b.Bucket(paramsByTaskID).Delete(encodedID)
+	}
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return b.Bucket(paramsByTaskID).Put(encodedID, paramsBytes)
+}
+
+// getTaskParams returns the params of the task with the given encoded ID, or nil if it has none.
+func getTaskParams(b *bolt.Bucket, encodedID []byte) (map[string]string, error) {
+	paramsBytes := b.Bucket(paramsByTaskID).Get(encodedID)
+	if paramsBytes == nil {
+		return nil, nil
+	}
+	var params map[string]string
+	if err := json.Unmarshal(paramsBytes, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
 // DeleteTask deletes the task.
 func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, err error) {
 	encodedID, err := id.Encode()
@@ -529,6 +700,12 @@ func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, e
 		if err := b.Bucket(nameByTaskID).Delete(encodedID); err != nil {
 			return err
 		}
+		if err := b.Bucket(paramsByTaskID).Delete(encodedID); err != nil {
+			return err
+		}
+		if err := deleteTaskVersions(b, encodedID); err != nil {
+			return err
+		}
 
 		org := b.Bucket(orgByTaskID).Get(encodedID)
 		if len(org) > 0 {
@@ -621,6 +798,19 @@ func (s *Store) FinishRun(ctx context.Context, taskID, runID platform.ID) error
 }
 
 func (s *Store) ManuallyRunTimeRange(_ context.Context, taskID platform.ID, start, end, requestedAt int64) (*backend.StoreTaskMetaManualRun, error) {
+	return s.addManualRun(taskID, func(stm *backend.StoreTaskMeta, makeID func() (platform.ID, error)) error {
+		return stm.ManuallyRunTimeRange(start, end, requestedAt, makeID)
+	})
+}
+
+func (s *Store) RetryRun(_ context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*backend.StoreTaskMetaManualRun, error) {
+	return s.addManualRun(taskID, func(stm *backend.StoreTaskMeta, makeID func() (platform.ID, error)) error {
+		return stm.RetryRun(now, requestedAt, attempt, makeID)
+	})
+}
+
+// addManualRun adds a manual run to the meta of the task with add, and returns the added run.
+func (s *Store) addManualRun(taskID platform.ID, add func(stm *backend.StoreTaskMeta, makeID func() (platform.ID, error)) error) (*backend.StoreTaskMetaManualRun, error) {
 	encodedID, err := taskID.Encode()
 	if err != nil {
 		return nil, err
@@ -635,7 +825,7 @@ func (s *Store) ManuallyRunTimeRange(_ context.Context, taskID platform.ID, star
 			return err
 		}
 		makeID := func() (platform.ID, error) { return s.idGen.ID(), nil }
-		if err := stm.ManuallyRunTimeRange(start, end, requestedAt, makeID); err != nil {
+		if err := add(&stm, makeID); err != nil {
 			return err
 		}
 
@@ -694,6 +884,12 @@ func (s *Store) DeleteOrg(ctx context.Context, id platform.ID) error {
 			if err := b.Bucket(nameByTaskID).Delete(k); err != nil {
 				return err
 			}
+			if err := b.Bucket(paramsByTaskID).Delete(k); err != nil {
+				return err
+			}
+			if err := deleteTaskVersions(b, k); err != nil {
+				return err
+			}
 		}
 		// check for cancelation one last time before we return
 		select {
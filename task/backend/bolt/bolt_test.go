@@ -9,6 +9,7 @@ import (
 
 	bolt "github.com/coreos/bbolt"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
 	_ "github.com/influxdata/influxdb/query/builtin"
 	"github.com/influxdata/influxdb/task/backend"
 	boltstore "github.com/influxdata/influxdb/task/backend/bolt"
@@ -145,3 +146,53 @@ func TestSkip(t *testing.T) {
 		t.Fatal("failed to run after an override")
 	}
 }
+
+func TestIDGenerator(t *testing.T) {
+	f, err := ioutil.TempFile("", "influx_bolt_task_store_test")
+	if err != nil {
+		t.Fatalf("failed to create tempfile for test db %v\n", err)
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+
+	db, err := bolt.Open(f.Name(), os.ModeTemporary, nil)
+	if err != nil {
+		t.Fatalf("failed to open bolt db for test db %v\n", err)
+	}
+
+	var last influxdb.ID
+	gen := mock.IDGenerator{
+		IDFn: func() influxdb.ID {
+			last++
+			return last
+		},
+	}
+	s, err := boltstore.New(db, "testbucket", boltstore.WithIDGenerator(gen))
+	if err != nil {
+		t.Fatalf("failed to create new bolt store %v\n", err)
+	}
+	defer s.Close()
+
+	schedAfter := time.Now().Add(-time.Minute)
+	tskID, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{
+		Org:             influxdb.ID(1),
+		AuthorizationID: influxdb.ID(2),
+		Script:          `option task = {name:"x", every:1s} from(bucket:"b-src") |> range(start:-1m) |> to(bucket:"b-dst", org:"o")`,
+		ScheduleAfter:   schedAfter.Unix(),
+		Status:          backend.TaskActive,
+	})
+	if err != nil {
+		t.Fatalf("failed to create new task %v\n", err)
+	}
+	if tskID != 1 {
+		t.Fatalf("expected task ID 1 from the generator, got %s", tskID)
+	}
+
+	rc, err := s.CreateNextRun(context.Background(), tskID, schedAfter.Add(10*time.Second).Unix())
+	if err != nil {
+		t.Fatalf("failed to create new run %v\n", err)
+	}
+	if rc.Created.RunID != 2 {
+		t.Fatalf("expected run ID 2 from the generator, got %s", rc.Created.RunID)
+	}
+}
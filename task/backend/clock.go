@@ -0,0 +1,47 @@
+package backend
+
+import "time"
+
+// Clock tells the time to the scheduler and paces its ticker,
+// so that tests can drive the scheduler with virtual time instead of sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for d to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTicker returns a ticker sending the current time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks of a Clock at intervals.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+}
+
+// SystemClock is a Clock reading the system time.
+type SystemClock struct{}
+
+var _ Clock = SystemClock{}
+
+// Now returns time.Now().
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// After returns time.After(d).
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// NewTicker returns a Ticker backed by a time.Ticker.
+func (SystemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+
+func (t systemTicker) Stop() { t.t.Stop() }
@@ -0,0 +1,307 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRunLogRetention is how long run records and logs are kept before being compacted, by default.
+	DefaultRunLogRetention = 7 * 24 * time.Hour
+
+	// summaryLogsField is the field of a run log summary counting the log lines of the hour.
+	// The other fields count the run records of the hour by status.
+	summaryLogsField = "logs"
+)
+
+// RunLogCompactionConfig configures how a RunLogCompactor compacts run records and logs.
+type RunLogCompactionConfig struct {
+	// Interval is the time between two compactions. Run records and logs are not compacted if it is 0.
+	Interval time.Duration
+
+	// Retention is how long run records and logs are kept before being compacted.
+	// Only whole hours are compacted, so they are kept up to an hour longer.
+	// It must be longer than the runs, whose records are summarized in the hour they are written.
+	Retention time.Duration
+}
+
+// RunLogCompactor rolls the run records and logs of the system bucket of every organization
+// into hourly summaries per task, and deletes them, keeping the system bucket small.
+//
+// A summary is a "summaries" point tagged with the task ID at the start of its hour,
+// whose integer fields count the run records of the hour by status, and its log lines in the "logs" field.
+type RunLogCompactor struct {
+	OrganizationService platform.OrganizationService
+	QueryService        query.QueryService
+	PointsWriter        PointsWriter
+	DeleteService       platform.DeleteService
+
+	Config RunLogCompactionConfig
+	Clock  Clock
+	Logger *zap.Logger
+}
+
+// NewRunLogCompactor returns a RunLogCompactor reading the system buckets through qs,
+// writing summaries with pw and deleting records and logs with ds.
+func NewRunLogCompactor(logger *zap.Logger, cfg RunLogCompactionConfig, os platform.OrganizationService, qs query.QueryService, pw PointsWriter, ds platform.DeleteService) *RunLogCompactor {
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultRunLogRetention
+	}
+	return &RunLogCompactor{
+		OrganizationService: os,
+		QueryService:        qs,
+		PointsWriter:        pw,
+		DeleteService:       ds,
+		Config:              cfg,
+		Clock:               SystemClock{},
+		Logger:              logger,
+	}
+}
+
+// Run compacts the run records and logs every interval until ctx is done.
+func (c *RunLogCompactor) Run(ctx context.Context) {
+	c.Logger.Info("Starting", zap.Duration("interval", c.Config.Interval), zap.Duration("retention", c.Config.Retention))
+	ticker := c.Clock.NewTicker(c.Config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			if err := c.Compact(ctx); err != nil {
+				c.Logger.Info("Failed to compact run logs", zap.Error(err))
+			}
+		case <-ctx.Done():
+			c.Logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Compact summarizes the run records and logs older than the retention, and deletes them.
+// The failure of an organization is logged, and does not prevent the others from being compacted.
+func (c *RunLogCompactor) Compact(ctx context.Context) error {
+	orgs, _, err := c.OrganizationService.FindOrganizations(ctx, platform.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+
+	cutoff := c.Clock.Now().Add(-c.Config.Retention).Truncate(time.Hour)
+	for _, o := range orgs {
+		n, err := c.compactOrg(ctx, o.ID, cutoff)
+		if err != nil {
+			c.Logger.Info("Failed to compact run logs of organization", zap.String("org_id", o.ID.String()), zap.Error(err))
+			continue
+		}
+		if n > 0 {
+			c.Logger.Info("Compacted run logs", zap.String("org_id", o.ID.String()), zap.Int("summaries", n), zap.Time("before", cutoff))
+		}
+	}
+	return nil
+}
+
+// runLogSummaryKey identifies the summary of an hour of a task.
+type runLogSummaryKey struct {
+	taskID string
+	hour   time.Time
+}
+
+// compactOrg summarizes the run records and logs of orgID written before cutoff, deletes them,
+// and returns the number of summaries written.
+func (c *RunLogCompactor) compactOrg(ctx context.Context, orgID platform.ID, cutoff time.Time) (int, error) {
+	summaries := map[runLogSummaryKey]map[string]interface{}{}
+	summary := func(taskID string, t time.Time) map[string]interface{} {
+		k := runLogSummaryKey{taskID: taskID, hour: t.Truncate(time.Hour)}
+		s, ok := summaries[k]
+		if !ok {
+			s = map[string]interface{}{}
+			summaries[k] = s
+		}
+		return s
+	}
+
+	auth, err := systemBucketAuthorization(orgID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The records are counted by status here, as their status is their value.
+	err = c.query(ctx, auth, recordsQuery(cutoff), func(taskID string, t time.Time, v interface{}) {
+		status, ok := v.(string)
+		if !ok {
+			return
+		}
+		s := summary(taskID, t)
+		n, _ := s[status].(int64)
+		s[status] = n + 1
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = c.query(ctx, auth, logsQuery(cutoff), func(taskID string, t time.Time, v interface{}) {
+		n, ok := v.(int64)
+		if !ok || n == 0 {
+			return
+		}
+		summary(taskID, t)[summaryLogsField] = n
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(summaries) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]runLogSummaryKey, 0, len(summaries))
+	for k := range summaries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].hour.Equal(keys[j].hour) {
+			return keys[i].hour.Before(keys[j].hour)
+		}
+		return keys[i].taskID < keys[j].taskID
+	})
+	points := make([]models.Point, 0, len(keys))
+	for _, k := range keys {
+		tags := models.Tags{
+			models.NewTag([]byte(taskIDTag), []byte(k.taskID)),
+		}
+		pt, err := models.NewPoint("summaries", tags, summaries[k], k.hour)
+		if err != nil {
+			return 0, err
+		}
+		points = append(points, pt)
+	}
+	exploded, err := tsdb.ExplodePoints(orgID, taskSystemBucketID, points)
+	if err != nil {
+		return 0, err
+	}
+	// The summaries are written before the records and logs are deleted, so that a failed deletion
+	// only leaves them to be summarized again, into the same summaries.
+	if err := c.PointsWriter.WritePoints(ctx, exploded); err != nil {
+		return 0, err
+	}
+
+	err = c.DeleteService.DeleteBucketRangePredicate(ctx, platform.DeleteRequest{
+		OrgID:     orgID,
+		BucketID:  taskSystemBucketID,
+		Start:     time.Unix(0, 0).UTC(),
+		Stop:      cutoff,
+		Predicate: `_measurement = 'records' OR _measurement = 'logs'`,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(points), nil
+}
+
+// query runs script in the system bucket with auth, and calls fn with the taskID, time and value of every row.
+// The time of a row is its _start if it has one, and its _time otherwise.
+// The value is a string or an int64; the rows with values of other types are skipped.
+func (c *RunLogCompactor) query(ctx context.Context, auth *platform.Authorization, script string, fn func(taskID string, t time.Time, v interface{})) error {
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: auth.OrgID,
+		Compiler:       lang.FluxCompiler{Query: script},
+	}
+	itr, err := c.QueryService.Query(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	for itr.More() {
+		err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				taskIdx, timeIdx, valueIdx := -1, -1, -1
+				for j, col := range cr.Cols() {
+					switch {
+					case col.Label == taskIDTag && col.Type == flux.TString:
+						taskIdx = j
+					case col.Label == "_start" && col.Type == flux.TTime:
+						timeIdx = j
+					case col.Label == "_time" && col.Type == flux.TTime && timeIdx < 0:
+						timeIdx = j
+					case col.Label == "_value":
+						valueIdx = j
+					}
+				}
+				if taskIdx < 0 || timeIdx < 0 || valueIdx < 0 {
+					return nil
+				}
+				for i := 0; i < cr.Len(); i++ {
+					taskIDs, ts := cr.Strings(taskIdx), cr.Times(timeIdx)
+					if taskIDs.IsNull(i) || ts.IsNull(i) {
+						continue
+					}
+					var v interface{}
+					switch cr.Cols()[valueIdx].Type {
+					case flux.TString:
+						vs := cr.Strings(valueIdx)
+						if vs.IsNull(i) {
+							continue
+						}
+						v = vs.ValueString(i)
+					case flux.TInt:
+						vs := cr.Ints(valueIdx)
+						if vs.IsNull(i) {
+							continue
+						}
+						v = vs.Value(i)
+					default:
+						continue
+					}
+					fn(taskIDs.ValueString(i), values.Time(ts.Value(i)).Time(), v)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return itr.Err()
+}
+
+// recordsQuery returns the script reading the status of the run records written before cutoff.
+func recordsQuery(cutoff time.Time) string {
+	return fmt.Sprintf(`from(bucketID: %q)
+  |> range(start: 1970-01-01T00:00:00Z, stop: %s)
+  |> filter(fn: (r) => r._measurement == "records" and r._field == %q)
+  |> keep(columns: ["_time", "_value", %q])`,
+		taskSystemBucketID.String(), cutoff.UTC().Format(time.RFC3339), statusField, taskIDTag)
+}
+
+// logsQuery returns the script counting the log lines written before cutoff, by task and hour.
+func logsQuery(cutoff time.Time) string {
+	return fmt.Sprintf(`from(bucketID: %q)
+  |> range(start: 1970-01-01T00:00:00Z, stop: %s)
+  |> filter(fn: (r) => r._measurement == "logs" and r._field == %q)
+  |> window(every: 1h)
+  |> count()
+  |> keep(columns: ["_start", "_value", %q])`,
+		taskSystemBucketID.String(), cutoff.UTC().Format(time.RFC3339), lineField, taskIDTag)
+}
+
+// systemBucketAuthorization returns the authorization the compactor reads the system bucket of orgID with.
+func systemBucketAuthorization(orgID platform.ID) (*platform.Authorization, error) {
+	p, err := platform.NewPermissionAtID(taskSystemBucketID, platform.ReadAction, platform.BucketsResourceType, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &platform.Authorization{
+		OrgID:       orgID,
+		Status:      platform.Active,
+		Permissions: []platform.Permission{*p},
+	}, nil
+}
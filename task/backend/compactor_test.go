@@ -0,0 +1,211 @@
+package backend_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+	platformmock "github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// summaryPointsWriter records the points it is given as "measurement,tags field=value time" lines.
+type summaryPointsWriter struct {
+	lines []string
+}
+
+func (w *summaryPointsWriter) WritePoints(_ context.Context, points []models.Point) error {
+	for _, pt := range points {
+		var tags []string
+		var measurement string
+		for _, tag := range pt.Tags() {
+			switch string(tag.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(tag.Value)
+			case models.FieldKeyTagKey:
+			default:
+				tags = append(tags, string(tag.Key)+"="+string(tag.Value))
+			}
+		}
+		fields, err := pt.Fields()
+		if err != nil {
+			return err
+		}
+		for k, v := range fields {
+			w.lines = append(w.lines, fmt.Sprintf("%s,%s %s=%v %s", measurement, strings.Join(tags, ","), k, v, pt.Time().UTC().Format(time.RFC3339)))
+		}
+	}
+	sort.Strings(w.lines)
+	return nil
+}
+
+func TestRunLogCompactor_Compact(t *testing.T) {
+	now := time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC)
+	ts := func(s string) execute.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return execute.Time(tm.UnixNano())
+	}
+
+	orgs := platformmock.NewOrganizationService()
+	orgs.FindOrganizationsF = func(ctx context.Context, filter platform.OrganizationFilter, opts ...platform.FindOptions) ([]*platform.Organization, int, error) {
+		return []*platform.Organization{{ID: 1, Name: "busy"}, {ID: 2, Name: "idle"}}, 2, nil
+	}
+
+	var scripts []string
+	qs := &querymock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.Authorization == nil || req.Authorization.OrgID != req.OrganizationID {
+				t.Fatalf("unexpected query request: %+v", req)
+			}
+			script := req.Compiler.(lang.FluxCompiler).Query
+			scripts = append(scripts, script)
+
+			var tbl *executetest.Table
+			switch {
+			case req.OrganizationID != 1:
+				return flux.NewSliceResultIterator(nil), nil
+			case strings.Contains(script, `"records"`):
+				tbl = &executetest.Table{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "taskID", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{ts("2019-06-03T09:01:00Z"), "started", "0000000000000010"},
+						{ts("2019-06-03T09:01:05Z"), "success", "0000000000000010"},
+						{ts("2019-06-03T09:31:00Z"), "started", "0000000000000010"},
+						{ts("2019-06-03T09:31:05Z"), "failed", "0000000000000010"},
+						{ts("2019-06-03T10:01:00Z"), "started", "0000000000000010"},
+						{ts("2019-06-03T10:01:00Z"), "started", "0000000000000020"},
+					},
+				}
+			default:
+				tbl = &executetest.Table{
+					ColMeta: []flux.ColMeta{
+						{Label: "_start", Type: flux.TTime},
+						{Label: "_value", Type: flux.TInt},
+						{Label: "taskID", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{ts("2019-06-03T09:00:00Z"), int64(7), "0000000000000010"},
+						{ts("2019-06-03T11:00:00Z"), int64(2), "0000000000000020"},
+					},
+				}
+			}
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm:   "_result",
+				Tbls: []*executetest.Table{tbl},
+			}}), nil
+		},
+	}
+
+	pw := &summaryPointsWriter{}
+	var deletes []platform.DeleteRequest
+	ds := platformmock.NewDeleteService()
+	ds.DeleteBucketRangePredicateFn = func(ctx context.Context, req platform.DeleteRequest) error {
+		deletes = append(deletes, req)
+		return nil
+	}
+
+	c := backend.NewRunLogCompactor(zaptest.NewLogger(t), backend.RunLogCompactionConfig{Interval: time.Hour}, orgs, qs, pw, ds)
+	c.Clock = mock.NewClock(now)
+	if err := c.Compact(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The raw points older than the 7 days retention, truncated to the hour, are compacted.
+	for _, s := range scripts {
+		if !strings.Contains(s, "stop: 2019-06-03T12:00:00Z") {
+			t.Errorf("unexpected range in script:\n%s", s)
+		}
+	}
+	if len(scripts) != 4 {
+		t.Errorf("expected 2 queries per organization, got %d", len(scripts))
+	}
+
+	expLines := []string{
+		"summaries,taskID=0000000000000010 failed=1 2019-06-03T09:00:00Z",
+		"summaries,taskID=0000000000000010 logs=7 2019-06-03T09:00:00Z",
+		"summaries,taskID=0000000000000010 started=1 2019-06-03T10:00:00Z",
+		"summaries,taskID=0000000000000010 started=2 2019-06-03T09:00:00Z",
+		"summaries,taskID=0000000000000010 success=1 2019-06-03T09:00:00Z",
+		"summaries,taskID=0000000000000020 logs=2 2019-06-03T11:00:00Z",
+		"summaries,taskID=0000000000000020 started=1 2019-06-03T10:00:00Z",
+	}
+	if got := strings.Join(pw.lines, "\n"); got != strings.Join(expLines, "\n") {
+		t.Errorf("unexpected summaries:\n%s\nwant:\n%s", got, strings.Join(expLines, "\n"))
+	}
+
+	// Only the organization with raw points has them deleted.
+	if len(deletes) != 1 {
+		t.Fatalf("expected 1 delete, got %+v", deletes)
+	}
+	d := deletes[0]
+	if d.OrgID != 1 || d.BucketID != 10 || !d.Stop.Equal(time.Date(2019, 6, 3, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected delete request: %+v", d)
+	}
+	if d.Predicate != `_measurement = 'records' OR _measurement = 'logs'` {
+		t.Errorf("unexpected delete predicate %q", d.Predicate)
+	}
+}
+
+func TestRunLogCompactor_CompactWriteFailure(t *testing.T) {
+	orgs := platformmock.NewOrganizationService()
+	orgs.FindOrganizationsF = func(ctx context.Context, filter platform.OrganizationFilter, opts ...platform.FindOptions) ([]*platform.Organization, int, error) {
+		return []*platform.Organization{{ID: 1, Name: "busy"}}, 1, nil
+	}
+	qs := &querymock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "taskID", Type: flux.TString},
+					},
+					Data: [][]interface{}{{execute.Time(0), "success", "0000000000000010"}},
+				}},
+			}}), nil
+		},
+	}
+	pw := &failingPointsWriter{}
+	deleted := false
+	ds := platformmock.NewDeleteService()
+	ds.DeleteBucketRangePredicateFn = func(ctx context.Context, req platform.DeleteRequest) error {
+		deleted = true
+		return nil
+	}
+
+	c := backend.NewRunLogCompactor(zaptest.NewLogger(t), backend.RunLogCompactionConfig{Interval: time.Hour}, orgs, qs, pw, ds)
+	c.Clock = mock.NewClock(time.Date(2019, 6, 10, 12, 30, 0, 0, time.UTC))
+	if err := c.Compact(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if deleted {
+		t.Error("the raw points were deleted although their summaries failed to be written")
+	}
+}
+
+type failingPointsWriter struct{}
+
+func (failingPointsWriter) WritePoints(context.Context, []models.Point) error {
+	return fmt.Errorf("storage is full")
+}
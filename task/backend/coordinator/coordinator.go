@@ -168,3 +168,15 @@ func (c *Coordinator) ManuallyRunTimeRange(ctx context.Context, taskID platform.
 	}
 	return r, c.sch.UpdateTask(t, m)
 }
+
+func (c *Coordinator) RetryRun(ctx context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*backend.StoreTaskMetaManualRun, error) {
+	r, err := c.Store.RetryRun(ctx, taskID, now, requestedAt, attempt)
+	if err != nil {
+		return r, err
+	}
+	t, m, err := c.Store.FindTaskByIDWithMeta(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return r, c.sch.UpdateTask(t, m)
+}
@@ -1,8 +1,13 @@
 package backend_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/task/backend"
 )
 
@@ -14,3 +19,35 @@ func TestParseRequestStillQueuedError(t *testing.T) {
 		t.Fatalf("%q should have parsed to %v, but got %v", validMsg, e, err)
 	}
 }
+
+func TestErrorKindOf(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want backend.ErrorKind
+	}{
+		{err: errors.New("boom"), want: backend.ErrorUnknown},
+		{err: backend.RetryableError(errors.New("boom"), time.Second), want: backend.ErrorRetryable},
+		{err: backend.PermanentError(errors.New("boom")), want: backend.ErrorPermanent},
+		{err: backend.ConflictError(errors.New("boom")), want: backend.ErrorConflict},
+		{err: backend.NotFoundError(errors.New("boom")), want: backend.ErrorNotFound},
+		{err: fmt.Errorf("wrapped: %w", backend.RetryableError(errors.New("boom"), 0)), want: backend.ErrorRetryable},
+		{err: backend.ErrTaskNotFound, want: backend.ErrorNotFound},
+		{err: backend.ErrRunNotFound, want: backend.ErrorNotFound},
+		{err: backend.InvalidRunTransitionError{From: backend.RunSuccess, To: backend.RunStarted}, want: backend.ErrorConflict},
+		{err: backend.QuotaExceededError{Quota: backend.QuotaRunsPerHour, Limit: 1}, want: backend.ErrorRetryable},
+		{err: context.DeadlineExceeded, want: backend.ErrorRetryable},
+		{err: &platform.Error{Code: platform.EUnavailable, Msg: "boom"}, want: backend.ErrorRetryable},
+		{err: &platform.Error{Code: platform.EInvalid, Msg: "boom"}, want: backend.ErrorPermanent},
+		{err: &platform.Error{Code: platform.ENotFound, Msg: "boom"}, want: backend.ErrorNotFound},
+		{err: &platform.Error{Code: platform.EConflict, Msg: "boom"}, want: backend.ErrorConflict},
+		{err: &platform.Error{Code: platform.EInternal, Msg: "boom"}, want: backend.ErrorUnknown},
+	} {
+		if got := backend.ErrorKindOf(tt.err); got != tt.want {
+			t.Errorf("ErrorKindOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+
+	if got := backend.RetryAfter(backend.RetryableError(errors.New("boom"), time.Minute)); got != time.Minute {
+		t.Errorf("got retry after %s, want 1m", got)
+	}
+}
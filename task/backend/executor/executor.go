@@ -13,15 +13,57 @@ import (
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/control"
 	"github.com/influxdata/influxdb/task/backend"
 	"go.uber.org/zap"
 )
 
+// Option configures an executor.
+type Option func(*executorOptions)
+
+type executorOptions struct {
+	scripts influxdb.TaskScriptService
+	cache   *control.CompileCache
+}
+
+// WithTaskScriptService sets the service that resolves the include directives of task scripts.
+// Without it, runs of tasks that include other scripts fail.
+func WithTaskScriptService(s influxdb.TaskScriptService) Option {
+	return func(o *executorOptions) {
+		o.scripts = s
+	}
+}
+
+// WithCompileCache sets the cache that task scripts are compiled through,
+// so that runs of a task reuse the spec compiled for its previous runs.
+func WithCompileCache(c *control.CompileCache) Option {
+	return func(o *executorOptions) {
+		o.cache = c
+	}
+}
+
+// compile compiles script for the time now, through the compile cache if there is one.
+func (o executorOptions) compile(ctx context.Context, script string, now time.Time) (*flux.Spec, error) {
+	if o.cache == nil {
+		return flux.Compile(ctx, script, now)
+	}
+	return o.cache.Compile(ctx, script, now)
+}
+
+func newExecutorOptions(opts []Option) executorOptions {
+	var o executorOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // queryServiceExecutor is an implementation of backend.Executor that depends on a QueryService.
 type queryServiceExecutor struct {
 	qs     query.QueryService
 	as     influxdb.AuthorizationService
 	st     backend.Store
+	opts   executorOptions
 	logger *zap.Logger
 	wg     sync.WaitGroup
 }
@@ -31,8 +73,9 @@ var _ backend.Executor = (*queryServiceExecutor)(nil)
 // NewQueryServiceExecutor returns a new executor based on the given QueryService.
 // In general, you should prefer NewAsyncQueryServiceExecutor, as that code is smaller and simpler,
 // because asynchronous queries are more in line with the Executor interface.
-func NewQueryServiceExecutor(logger *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, st backend.Store) backend.Executor {
-	return &queryServiceExecutor{logger: logger, qs: qs, as: as, st: st}
+func NewQueryServiceExecutor(logger *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, st backend.Store, opts ...Option) backend.Executor {
+	o := newExecutorOptions(opts)
+	return &queryServiceExecutor{logger: logger, qs: qs, as: as, st: st, opts: o}
 }
 
 func (e *queryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
@@ -59,6 +102,7 @@ type syncRunPromise struct {
 	qr     backend.QueuedRun
 	auth   *influxdb.Authorization
 	qs     query.QueryService
+	opts   executorOptions
 	t      *backend.StoreTask
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -81,6 +125,7 @@ func newSyncRunPromise(ctx context.Context, auth *influxdb.Authorization, qr bac
 		qr:     qr,
 		auth:   auth,
 		qs:     e.qs,
+		opts:   e.opts,
 		t:      t,
 		logger: log,
 		logEnd: logEnd,
@@ -139,7 +184,19 @@ func (p *syncRunPromise) finish(res *runResult, err error) {
 func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	spec, err := flux.Compile(p.ctx, p.t.Script, time.Unix(p.qr.Now, 0))
+	script, err := influxdb.ResolveTaskScriptIncludes(p.ctx, p.opts.scripts, p.t.Org, p.t.Script)
+	if err != nil {
+		p.finish(nil, err)
+		return
+	}
+
+	script, err = influxdb.InjectTaskParams(script, p.t.Params)
+	if err != nil {
+		p.finish(nil, err)
+		return
+	}
+
+	spec, err := p.opts.compile(p.ctx, script, time.Unix(p.qr.Now, 0))
 	if err != nil {
 		p.finish(nil, err)
 		return
@@ -196,6 +253,7 @@ type asyncQueryServiceExecutor struct {
 	qs     query.AsyncQueryService
 	as     influxdb.AuthorizationService
 	st     backend.Store
+	opts   executorOptions
 	logger *zap.Logger
 	wg     sync.WaitGroup
 }
@@ -203,8 +261,9 @@ type asyncQueryServiceExecutor struct {
 var _ backend.Executor = (*asyncQueryServiceExecutor)(nil)
 
 // NewAsyncQueryServiceExecutor returns a new executor based on the given AsyncQueryService.
-func NewAsyncQueryServiceExecutor(logger *zap.Logger, qs query.AsyncQueryService, as influxdb.AuthorizationService, st backend.Store) backend.Executor {
-	return &asyncQueryServiceExecutor{logger: logger, qs: qs, as: as, st: st}
+func NewAsyncQueryServiceExecutor(logger *zap.Logger, qs query.AsyncQueryService, as influxdb.AuthorizationService, st backend.Store, opts ...Option) backend.Executor {
+	o := newExecutorOptions(opts)
+	return &asyncQueryServiceExecutor{logger: logger, qs: qs, as: as, st: st, opts: o}
 }
 
 func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
@@ -218,7 +277,17 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
-	spec, err := flux.Compile(ctx, t.Script, time.Unix(run.Now, 0))
+	script, err := influxdb.ResolveTaskScriptIncludes(ctx, e.opts.scripts, t.Org, t.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	script, err = influxdb.InjectTaskParams(script, t.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := e.opts.compile(ctx, script, time.Unix(run.Now, 0))
 	if err != nil {
 		return nil, err
 	}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,8 +21,10 @@ import (
 	platform "github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/control"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/executor"
 	"go.uber.org/zap"
@@ -231,10 +236,11 @@ func (ts tables) Do(f func(flux.Table) error) error {
 func (ts tables) Statistics() flux.Statistics { return flux.Statistics{} }
 
 type system struct {
-	name string
-	svc  *fakeQueryService
-	st   backend.Store
-	ex   backend.Executor
+	name    string
+	svc     *fakeQueryService
+	st      backend.Store
+	scripts *mock.TaskScriptService
+	ex      backend.Executor
 	// We really just want an authorization service here, but we take a whole inmem service
 	// to ensure that the authorization service validates org and user existence properly.
 	i *inmem.Service
@@ -245,24 +251,28 @@ type createSysFn func() *system
 func createAsyncSystem() *system {
 	svc := newFakeQueryService()
 	st := backend.NewInMemStore()
+	scripts := mock.NewTaskScriptService()
 	i := inmem.NewService()
 	return &system{
-		name: "AsyncExecutor",
-		svc:  svc,
-		st:   st,
-		ex:   executor.NewAsyncQueryServiceExecutor(zap.NewNop(), svc, i, st),
-		i:    i,
+		name:    "AsyncExecutor",
+		svc:     svc,
+		st:      st,
+		scripts: scripts,
+		ex:      executor.NewAsyncQueryServiceExecutor(zap.NewNop(), svc, i, st, executor.WithTaskScriptService(scripts)),
+		i:       i,
 	}
 }
 
 func createSyncSystem() *system {
 	svc := newFakeQueryService()
 	st := backend.NewInMemStore()
+	scripts := mock.NewTaskScriptService()
 	i := inmem.NewService()
 	return &system{
-		name: "SynchronousExecutor",
-		svc:  svc,
-		st:   st,
+		name:    "SynchronousExecutor",
+		svc:     svc,
+		st:      st,
+		scripts: scripts,
 		ex: executor.NewQueryServiceExecutor(
 			zap.NewNop(),
 			query.QueryServiceBridge{
@@ -270,6 +280,7 @@ func createSyncSystem() *system {
 			},
 			i,
 			st,
+			executor.WithTaskScriptService(scripts),
 		),
 		i: i,
 	}
@@ -279,12 +290,48 @@ func TestExecutor(t *testing.T) {
 	for _, fn := range []createSysFn{createAsyncSystem, createSyncSystem} {
 		testExecutorQuerySuccess(t, fn)
 		testExecutorQueryFailure(t, fn)
+		testExecutorQueryParams(t, fn)
+		testExecutorQueryIncludes(t, fn)
 		testExecutorPromiseCancel(t, fn)
 		testExecutorServiceError(t, fn)
 		testExecutorWait(t, fn)
 	}
 }
 
+func TestExecutor_CompileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "executor-compile-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := control.NewCompileCache(dir, "test", zap.NewNop())
+	if err := cache.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	createCachedSystem := func() *system {
+		svc := newFakeQueryService()
+		st := backend.NewInMemStore()
+		scripts := mock.NewTaskScriptService()
+		i := inmem.NewService()
+		return &system{
+			name:    "CachedAsyncExecutor",
+			svc:     svc,
+			st:      st,
+			scripts: scripts,
+			ex:      executor.NewAsyncQueryServiceExecutor(zap.NewNop(), svc, i, st, executor.WithTaskScriptService(scripts), executor.WithCompileCache(cache)),
+			i:       i,
+		}
+	}
+
+	// Group the parallel tests so that they complete before the cache directory is removed.
+	t.Run("group", func(t *testing.T) {
+		testExecutorQuerySuccess(t, createCachedSystem)
+		testExecutorQueryParams(t, createCachedSystem)
+		testExecutorQueryIncludes(t, createCachedSystem)
+	})
+}
+
 // Some tests use t.Parallel, and the fake query service depends on unique scripts,
 // so format a new script with the test name in each test.
 const fmtTestScript = `
@@ -389,6 +436,92 @@ func testExecutorQueryFailure(t *testing.T, fn createSysFn) {
 	})
 }
 
+func testExecutorQueryParams(t *testing.T, fn createSysFn) {
+	sys := fn()
+	tc := createCreds(t, sys.i)
+	t.Run(sys.name+"/QueryParams", func(t *testing.T) {
+		t.Parallel()
+		script := fmt.Sprintf(`
+import "http"
+
+option target = "http://example.com"
+option task = {
+			name: %q,
+			every: 1m,
+}
+
+from(bucket: "one") |> http.to(url: target)`, t.Name())
+		params := map[string]string{"target": "http://example.com/params"}
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: tc.OrgID, AuthorizationID: tc.AuthzID, Script: script, Params: params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: tid, RunID: platform.ID(1), Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The query runs with the params in place of the declared options.
+		injected := strings.Replace(script, `"http://example.com"`, `"http://example.com/params"`, 1)
+		sys.svc.WaitForQueryLive(t, injected)
+		sys.svc.SucceedQuery(injected)
+		res, err := rp.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Err(); got != nil {
+			t.Fatal(got)
+		}
+	})
+}
+
+func testExecutorQueryIncludes(t *testing.T, fn createSysFn) {
+	sys := fn()
+	tc := createCreds(t, sys.i)
+	sys.scripts.FindTaskScriptFn = func(ctx context.Context, orgID platform.ID, name string, version int) (*platform.TaskScript, error) {
+		if orgID != tc.OrgID || name != "target" || version != 2 {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrTaskScriptNotFound}
+		}
+		return &platform.TaskScript{OrgID: orgID, Name: name, Version: version, Flux: `target = "http://example.com/included"`}, nil
+	}
+	t.Run(sys.name+"/QueryIncludes", func(t *testing.T) {
+		t.Parallel()
+		script := fmt.Sprintf(`
+import "http"
+
+// @include "target@2"
+
+option task = {
+			name: %q,
+			every: 1m,
+}
+
+from(bucket: "one") |> http.to(url: target)`, t.Name())
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: tc.OrgID, AuthorizationID: tc.AuthzID, Script: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: tid, RunID: platform.ID(1), Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The query runs with the included script in place of the directive.
+		resolved := strings.Replace(script, `// @include "target@2"`, `target = "http://example.com/included"`, 1)
+		sys.svc.WaitForQueryLive(t, resolved)
+		sys.svc.SucceedQuery(resolved)
+		res, err := rp.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Err(); got != nil {
+			t.Fatal(got)
+		}
+	})
+}
+
 func testExecutorPromiseCancel(t *testing.T, fn createSysFn) {
 	sys := fn()
 	tc := createCreds(t, sys.i)
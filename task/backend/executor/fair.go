@@ -0,0 +1,177 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/options"
+)
+
+// fairExecutor is an implementation of backend.Executor that limits the concurrent runs of each organization,
+// and starts the runs waiting for a slot in weighted fair order rather than in arrival order.
+type fairExecutor struct {
+	e  backend.Executor
+	st backend.Store
+
+	limit int
+
+	mu   sync.Mutex
+	orgs map[influxdb.ID]*fairQueue
+
+	wg sync.WaitGroup
+}
+
+var _ backend.Executor = (*fairExecutor)(nil)
+
+// NewFairExecutor returns an executor that executes, through e, at most limit runs of an organization at once.
+//
+// Every run waiting for a slot of its organization gets a virtual start tag,
+// spaced from the previous run of the same task by the inverse of the task's weight option,
+// and the waiting run with the lowest tag starts next.
+// So a task with many runs due cannot starve the other tasks of its organization:
+// each task makes progress in proportion to its weight.
+// A limit of 0 or less disables the limit, and e is returned.
+func NewFairExecutor(e backend.Executor, st backend.Store, limit int) backend.Executor {
+	if limit <= 0 {
+		return e
+	}
+	return &fairExecutor{
+		e:     e,
+		st:    st,
+		limit: limit,
+		orgs:  make(map[influxdb.ID]*fairQueue),
+	}
+}
+
+// fairQueue holds the runs of an organization.
+type fairQueue struct {
+	running int
+
+	// vtime is the start tag of the run started last.
+	vtime float64
+
+	// lastTag is the start tag given to the latest run of each task.
+	lastTag map[influxdb.ID]float64
+
+	// waiting is sorted by tag.
+	waiting []*fairWaiter
+}
+
+type fairWaiter struct {
+	tag   float64
+	start chan struct{}
+}
+
+func (e *fairExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
+	t, err := e.st.FindTaskByID(ctx, run.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := options.FromScript(t.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.acquire(ctx, t.Org, t.ID, opts.EffectiveWeight()); err != nil {
+		return nil, err
+	}
+
+	rp, err := e.e.Execute(ctx, run)
+	if err != nil {
+		e.release(t.Org)
+		return nil, err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		rp.Wait()
+		e.release(t.Org)
+	}()
+
+	return rp, nil
+}
+
+// acquire blocks until a run of taskID may start in org, or until ctx is done.
+func (e *fairExecutor) acquire(ctx context.Context, org, taskID influxdb.ID, weight int64) error {
+	e.mu.Lock()
+	q, ok := e.orgs[org]
+	if !ok {
+		q = &fairQueue{lastTag: make(map[influxdb.ID]float64)}
+		e.orgs[org] = q
+	}
+
+	tag := q.vtime
+	if last := q.lastTag[taskID]; last > tag {
+		tag = last
+	}
+	tag += 1 / float64(weight)
+	q.lastTag[taskID] = tag
+
+	if q.running < e.limit && len(q.waiting) == 0 {
+		q.running++
+		q.vtime = tag
+		e.mu.Unlock()
+		return nil
+	}
+
+	w := &fairWaiter{tag: tag, start: make(chan struct{})}
+	// Insert after the waiters with the same tag, so that ties start in arrival order.
+	i := sort.Search(len(q.waiting), func(i int) bool { return q.waiting[i].tag > tag })
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = w
+	e.mu.Unlock()
+
+	select {
+	case <-w.start:
+		return nil
+	case <-ctx.Done():
+	}
+
+	e.mu.Lock()
+	for i := range q.waiting {
+		if q.waiting[i] == w {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			e.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	e.mu.Unlock()
+
+	// The run was started concurrently with ctx being done; give its slot back.
+	e.release(org)
+	return ctx.Err()
+}
+
+// release frees the slot of a finished run of org, starting the next waiting run if there is one.
+func (e *fairExecutor) release(org influxdb.ID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	q := e.orgs[org]
+	q.running--
+
+	if len(q.waiting) > 0 {
+		w := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running++
+		q.vtime = w.tag
+		close(w.start)
+		return
+	}
+
+	if q.running == 0 {
+		// The organization is idle, so its history no longer matters.
+		delete(e.orgs, org)
+	}
+}
+
+func (e *fairExecutor) Wait() {
+	e.e.Wait()
+	e.wg.Wait()
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/mock"
+)
+
+func createWeightedTask(t *testing.T, st backend.Store, org platform.ID, weight int) platform.ID {
+	t.Helper()
+
+	script := fmt.Sprintf(`option task = {name: "t", every: 1m, concurrency: 100, weight: %d}
+from(bucket: "b") |> range(start: -1m)`, weight)
+	id, err := st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, AuthorizationID: 3, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// pollForNumberWaiting blocks for a small amount of time waiting for exactly count runs of org to wait for a slot.
+func pollForNumberWaiting(t *testing.T, e backend.Executor, org platform.ID, count int) {
+	t.Helper()
+
+	fe := e.(*fairExecutor)
+	var n int
+	for i := 0; i < 20; i++ {
+		if i > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		fe.mu.Lock()
+		n = 0
+		if q, ok := fe.orgs[org]; ok {
+			n = len(q.waiting)
+		}
+		fe.mu.Unlock()
+		if n == count {
+			return
+		}
+	}
+	t.Fatalf("did not see %d waiting run(s) for org %s in time; last count was %d", count, org, n)
+}
+
+func TestFairExecutor(t *testing.T) {
+	ctx := context.Background()
+	st := backend.NewInMemStore()
+	me := mock.NewExecutor()
+	e := NewFairExecutor(me, st, 1)
+
+	// A frequent task with a backlog of runs, and a task with a higher weight in the same organization.
+	busy := createWeightedTask(t, st, 1, 1)
+	other := createWeightedTask(t, st, 1, 2)
+	elsewhere := createWeightedTask(t, st, 2, 1)
+
+	promises := make(chan backend.RunPromise, 10)
+	execute := func(taskID platform.ID, runID platform.ID) {
+		rp, err := e.Execute(ctx, backend.QueuedRun{TaskID: taskID, RunID: runID, Now: 60})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		promises <- rp
+	}
+
+	execute(busy, 1)
+	first := <-promises
+
+	// The busy task's backlog queues up behind its running run, before the other task has a run due.
+	for i := platform.ID(2); i <= 4; i++ {
+		go execute(busy, i)
+	}
+	pollForNumberWaiting(t, e, 1, 3)
+	go execute(other, 5)
+	pollForNumberWaiting(t, e, 1, 4)
+
+	// Another organization has its own limit.
+	execute(elsewhere, 6)
+	if _, err := me.PollForNumberRunning(elsewhere, 1); err != nil {
+		t.Fatal(err)
+	}
+	(<-promises).(*mock.RunPromise).Finish(mock.NewRunResult(nil, false), nil)
+
+	// Without fair scheduling, the other task would wait for the whole backlog to run first.
+	first.(*mock.RunPromise).Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := me.PollForNumberRunning(other, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := me.PollForNumberRunning(busy, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backlog runs one at a time afterwards.
+	for i := 0; i < 4; i++ {
+		(<-promises).(*mock.RunPromise).Finish(mock.NewRunResult(nil, false), nil)
+	}
+	e.Wait()
+	if _, err := me.PollForNumberRunning(busy, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFairExecutor_ContextCanceled(t *testing.T) {
+	st := backend.NewInMemStore()
+	me := mock.NewExecutor()
+	e := NewFairExecutor(me, st, 1)
+
+	taskID := createWeightedTask(t, st, 1, 1)
+	rp, err := e.Execute(context.Background(), backend.QueuedRun{TaskID: taskID, RunID: 1, Now: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A run waiting for a slot gives up when its context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Execute(ctx, backend.QueuedRun{TaskID: taskID, RunID: 2, Now: 120}); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The canceled run does not hold a slot.
+	rp.(*mock.RunPromise).Finish(mock.NewRunResult(nil, false), nil)
+	rp, err = e.Execute(context.Background(), backend.QueuedRun{TaskID: taskID, RunID: 3, Now: 180})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp.(*mock.RunPromise).Finish(mock.NewRunResult(nil, false), nil)
+	e.Wait()
+}
@@ -15,14 +15,20 @@ type orgtask struct {
 	o, t platform.ID
 }
 
+// orgrun is used as a key for storing runs by org and run ID,
+// so that a run copied to another org is stored separately from the original.
+type orgrun struct {
+	o, r platform.ID
+}
+
 type runReaderWriter struct {
 	mu        sync.RWMutex
 	byOrgTask map[orgtask][]*platform.Run
-	byRunID   map[string]*platform.Run
+	byRunID   map[orgrun]*platform.Run
 }
 
 func NewInMemRunReaderWriter() *runReaderWriter {
-	return &runReaderWriter{byRunID: map[string]*platform.Run{}, byOrgTask: map[orgtask][]*platform.Run{}}
+	return &runReaderWriter{byRunID: map[orgrun]*platform.Run{}, byOrgTask: map[orgtask][]*platform.Run{}}
 }
 
 func (r *runReaderWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, when time.Time, status RunStatus) error {
@@ -38,26 +44,40 @@ func (r *runReaderWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, wh
 		}
 	}
 
-	ridStr := rlb.RunID.String()
-	existingRun, ok := r.byRunID[ridStr]
+	or := orgrun{o: rlb.Task.Org, r: rlb.RunID}
+	existingRun, ok := r.byRunID[or]
 	if !ok {
+		// The run is recorded by its first state update, so it was scheduled until now.
+		if err := ValidateRunTransition(RunScheduled, status); err != nil {
+			return err
+		}
+
 		sf := time.Unix(rlb.RunScheduledFor, 0).UTC()
 		run := &platform.Run{
 			ID:           rlb.RunID,
 			TaskID:       rlb.Task.ID,
 			Status:       status.String(),
 			ScheduledFor: sf.Format(time.RFC3339),
+			Attempt:      int(rlb.Attempt),
 		}
 		if rlb.RequestedAt != 0 {
 			run.RequestedAt = time.Unix(rlb.RequestedAt, 0).UTC().Format(time.RFC3339)
 		}
 		timeSetter(run)
-		r.byRunID[ridStr] = run
+		r.byRunID[or] = run
 		ot := orgtask{o: rlb.Task.Org, t: rlb.Task.ID}
 		r.byOrgTask[ot] = append(r.byOrgTask[ot], run)
 		return nil
 	}
 
+	current, err := ParseRunStatus(existingRun.Status)
+	if err != nil {
+		return err
+	}
+	if err := ValidateRunTransition(current, status); err != nil {
+		return err
+	}
+
 	timeSetter(existingRun)
 	existingRun.Status = status.String()
 	return nil
@@ -67,8 +87,8 @@ func (r *runReaderWriter) AddRunLog(ctx context.Context, rlb RunLogBase, when ti
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	pLog := platform.Log{Time: when.Format(time.RFC3339Nano), Message: log}
-	ridStr := rlb.RunID.String()
-	existingRun, ok := r.byRunID[ridStr]
+	or := orgrun{o: rlb.Task.Org, r: rlb.RunID}
+	existingRun, ok := r.byRunID[or]
 	if !ok {
 		return ErrRunNotFound
 	}
@@ -106,6 +126,9 @@ func (r *runReaderWriter) ListRuns(ctx context.Context, orgID platform.ID, runFi
 		if r.ID.String() <= afterID {
 			continue
 		}
+		if runFilter.Status != "" && runFilter.Status != r.Status {
+			continue
+		}
 
 		// Copy the element, to avoid a data race if the original Run is modified in UpdateRunState or AddRunLog.
 		r := *r
@@ -122,7 +145,7 @@ func (r *runReaderWriter) ListRuns(ctx context.Context, orgID platform.ID, runFi
 func (r *runReaderWriter) FindRunByID(ctx context.Context, orgID, runID platform.ID) (*platform.Run, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	run, ok := r.byRunID[runID.String()]
+	run, ok := r.byRunID[orgrun{o: orgID, r: runID}]
 	if !ok {
 		return nil, ErrRunNotFound
 	}
@@ -140,7 +163,7 @@ func (r *runReaderWriter) ListLogs(ctx context.Context, orgID platform.ID, logFi
 	}
 
 	if logFilter.Run != nil {
-		run, ok := r.byRunID[logFilter.Run.String()]
+		run, ok := r.byRunID[orgrun{o: orgID, r: *logFilter.Run}]
 		if !ok {
 			return nil, ErrRunNotFound
 		}
@@ -25,15 +25,33 @@ type inmem struct {
 	tasks []StoreTask
 
 	meta map[platform.ID]StoreTaskMeta
+
+	versions map[platform.ID][]StoreTaskVersion
+}
+
+// InMemStoreOption is an optional configuration for the in-memory store.
+type InMemStoreOption func(*inmem)
+
+// WithInMemIDGenerator sets the generator of the IDs of the tasks and runs of the in-memory store,
+// so that tests can assert on stable IDs.
+func WithInMemIDGenerator(gen platform.IDGenerator) InMemStoreOption {
+	return func(s *inmem) {
+		s.idgen = gen
+	}
 }
 
 // NewInMemStore returns a new in-memory store.
 // This store is not designed to be efficient, it is here for testing purposes.
-func NewInMemStore() Store {
-	return &inmem{
-		idgen: snowflake.NewIDGenerator(),
-		meta:  map[platform.ID]StoreTaskMeta{},
+func NewInMemStore(opts ...InMemStoreOption) Store {
+	s := &inmem{
+		idgen:    snowflake.NewIDGenerator(),
+		meta:     map[platform.ID]StoreTaskMeta{},
+		versions: map[platform.ID][]StoreTaskVersion{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *inmem) CreateTask(_ context.Context, req CreateTaskRequest) (platform.ID, error) {
@@ -52,6 +70,8 @@ func (s *inmem) CreateTask(_ context.Context, req CreateTaskRequest) (platform.I
 		Name: o.Name,
 
 		Script: req.Script,
+
+		Params: copyParams(req.Params),
 	}
 
 	s.mu.Lock()
@@ -91,9 +111,23 @@ func (s *inmem) UpdateTask(_ context.Context, req UpdateTaskRequest) (UpdateTask
 				return res, err
 			}
 		} else {
+			if req.Script != t.Script {
+				vs := s.versions[req.ID]
+				s.versions[req.ID] = append(vs, StoreTaskVersion{
+					Version:    int64(len(vs) + 1),
+					Script:     t.Script,
+					ReplacedAt: time.Now().Unix(),
+				})
+			}
 			t.Script = req.Script
 		}
 		t.Name = op.Name
+		if req.Org.Valid() {
+			t.Org = req.Org
+		}
+		if req.Params != nil {
+			t.Params = copyParams(req.Params)
+		}
 
 		s.tasks[n] = t
 		res.NewTask = t
@@ -216,6 +250,27 @@ func (s *inmem) FindTaskByIDWithMeta(_ context.Context, id platform.ID) (*StoreT
 	return task, &meta, nil
 }
 
+func (s *inmem) ListTaskVersions(_ context.Context, id platform.ID) ([]StoreTaskVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := false
+	for _, t := range s.tasks {
+		if t.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrTaskNotFound
+	}
+
+	vs := s.versions[id]
+	out := make([]StoreTaskVersion, len(vs))
+	copy(out, vs)
+	return out, nil
+}
+
 func (s *inmem) FindTaskMetaByID(ctx context.Context, id platform.ID) (*StoreTaskMeta, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -247,9 +302,22 @@ func (s *inmem) DeleteTask(_ context.Context, id platform.ID) (deleted bool, err
 	// Delete entry from slice.
 	s.tasks = append(s.tasks[:idx], s.tasks[idx+1:]...)
 	delete(s.meta, id)
+	delete(s.versions, id)
 	return true, nil
 }
 
+// copyParams returns a copy of params, so the caller can't modify a stored task, or nil if params is empty.
+func copyParams(params map[string]string) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(params))
+	for k, v := range params {
+		out[k] = v
+	}
+	return out
+}
+
 func (s *inmem) Close() error {
 	return nil
 }
@@ -260,7 +328,7 @@ func (s *inmem) CreateNextRun(ctx context.Context, taskID platform.ID, now int64
 
 	stm, ok := s.meta[taskID]
 	if !ok {
-		return RunCreation{}, errors.New("task not found")
+		return RunCreation{}, ErrTaskNotFound
 	}
 
 	makeID := func() (platform.ID, error) {
@@ -283,11 +351,11 @@ func (s *inmem) FinishRun(ctx context.Context, taskID, runID platform.ID) error
 	s.mu.RUnlock()
 
 	if !ok {
-		return errors.New("taskRunner not found")
+		return ErrTaskNotFound
 	}
 
 	if !stm.FinishRun(runID) {
-		return errors.New("run not found")
+		return ErrRunNotFound
 	}
 
 	s.mu.Lock()
@@ -315,6 +383,24 @@ func (s *inmem) ManuallyRunTimeRange(_ context.Context, taskID platform.ID, star
 	return mr, nil
 }
 
+func (s *inmem) RetryRun(_ context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*StoreTaskMetaManualRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stm, ok := s.meta[taskID]
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+
+	if err := stm.RetryRun(now, requestedAt, attempt, func() (platform.ID, error) { return s.idgen.ID(), nil }); err != nil {
+		return nil, err
+	}
+
+	s.meta[taskID] = stm
+	mr := stm.ManualRuns[len(stm.ManualRuns)-1]
+	return mr, nil
+}
+
 func (s *inmem) delete(ctx context.Context, id platform.ID, f func(StoreTask) platform.ID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -341,8 +427,9 @@ func (s *inmem) delete(ctx context.Context, id platform.ID, f func(StoreTask) pl
 		return ctx.Err()
 	default:
 	}
-	for i := range deletingTasks {
-		delete(s.meta, s.tasks[i].ID)
+	for _, id := range deletingTasks {
+		delete(s.meta, id)
+		delete(s.versions, id)
 	}
 	s.tasks = newTasks
 	return nil
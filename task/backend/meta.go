@@ -107,12 +107,12 @@ func (stm *StoreTaskMeta) FinishRun(runID platform.ID) bool {
 // Because a StoreTaskMeta doesn't know the ID of the task it belongs to, it never sets RunCreation.Created.TaskID.
 func (stm *StoreTaskMeta) CreateNextRun(now int64, makeID func() (platform.ID, error)) (RunCreation, error) {
 	if len(stm.CurrentlyRunning) >= int(stm.MaxConcurrency) {
-		return RunCreation{}, errors.New("cannot create next run when max concurrency already reached")
+		return RunCreation{}, ConflictError(errors.New("cannot create next run when max concurrency already reached"))
 	}
 
 	// Not calling stm.DueAt here because we reuse sch.
 	// We can definitely optimize (minimize) cron parsing at a later point in time.
-	sch, err := cron.Parse(stm.EffectiveCron)
+	sch, err := parseSchedule(stm.EffectiveCron)
 	if err != nil {
 		return RunCreation{}, err
 	}
@@ -147,8 +147,9 @@ func (stm *StoreTaskMeta) CreateNextRun(now int64, makeID func() (platform.ID, e
 
 	return RunCreation{
 		Created: QueuedRun{
-			RunID: id,
-			Now:   nextScheduledUnix,
+			RunID:   id,
+			Now:     nextScheduledUnix,
+			Attempt: 1,
 		},
 		NextDue:  sch.Next(nextScheduled).Unix() + int64(stm.Offset),
 		HasQueue: len(stm.ManualRuns) > 0,
@@ -187,9 +188,15 @@ func (stm *StoreTaskMeta) createNextRunFromQueue(now, nextDue int64, sch cron.Sc
 		}
 	}
 
+	try := q.Try
+	if try == 0 {
+		// The request is not a retry.
+		try = 1
+	}
+
 	stm.CurrentlyRunning = append(stm.CurrentlyRunning, &StoreTaskMetaRun{
 		Now:   runNow,
-		Try:   1,
+		Try:   try,
 		RunID: uint64(id),
 
 		RangeStart:  q.Start,
@@ -207,6 +214,7 @@ func (stm *StoreTaskMeta) createNextRunFromQueue(now, nextDue int64, sch cron.Sc
 			RunID:       id,
 			Now:         runNow,
 			RequestedAt: q.RequestedAt,
+			Attempt:     try,
 		},
 		NextDue:  nextDue,
 		HasQueue: len(stm.ManualRuns) > 0,
@@ -216,11 +224,17 @@ func (stm *StoreTaskMeta) createNextRunFromQueue(now, nextDue int64, sch cron.Sc
 // NextDueRun returns the Unix timestamp of when the next call to CreateNextRun will be ready.
 // The returned timestamp reflects the task's delay, so it does not necessarily exactly match the schedule time.
 func (stm *StoreTaskMeta) NextDueRun() (int64, error) {
-	sch, err := cron.Parse(stm.EffectiveCron)
+	runs, err := stm.NextScheduledRuns(1)
 	if err != nil {
 		return 0, err
 	}
 
+	return runs[0].DueAt, nil
+}
+
+// NextScheduledRuns returns the next n naturally scheduled runs,
+// following the latest completed or currently running run.
+func (stm *StoreTaskMeta) NextScheduledRuns(n int) ([]ScheduledRun, error) {
 	latest := stm.LatestCompleted
 	currRun := make([]*StoreTaskMetaRun, len(stm.CurrentlyRunning))
 	copy(currRun, stm.CurrentlyRunning)
@@ -230,7 +244,41 @@ func (stm *StoreTaskMeta) NextDueRun() (int64, error) {
 		}
 	}
 
-	return sch.Next(time.Unix(latest, 0)).Unix() + int64(stm.Offset), nil
+	return NextScheduledRuns(stm.EffectiveCron, int64(stm.Offset), latest, n)
+}
+
+// ScheduledRun describes an upcoming run of a task.
+type ScheduledRun struct {
+	// Now is the Unix timestamp the run is scheduled for.
+	Now int64
+
+	// DueAt is the Unix timestamp when the run will be created.
+	// It reflects the task's offset, so it does not necessarily match Now.
+	DueAt int64
+}
+
+// NextScheduledRuns returns the next n runs for a task with the given effective cron string and offset (in seconds),
+// scheduled after the Unix timestamp latest.
+//
+// The times are computed the same way as in CreateNextRun,
+// so they match the runs the scheduler will create, barring manual runs.
+func NextScheduledRuns(effectiveCron string, offset, latest int64, n int) ([]ScheduledRun, error) {
+	if n < 1 {
+		return nil, errors.New("number of scheduled runs must be positive")
+	}
+
+	sch, err := parseSchedule(effectiveCron)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]ScheduledRun, n)
+	next := time.Unix(latest, 0)
+	for i := range runs {
+		next = sch.Next(next)
+		runs[i] = ScheduledRun{Now: next.Unix(), DueAt: next.Unix() + offset}
+	}
+	return runs, nil
 }
 
 // ManuallyRunTimeRange requests a manual run covering the approximate range specified by the Unix timestamps start and end.
@@ -276,6 +324,27 @@ func (stm *StoreTaskMeta) ManuallyRunTimeRange(start, end, requestedAt int64, ma
 	return nil
 }
 
+// RetryRun requests a run scheduled at the Unix timestamp now, as the given attempt of a previous run for the same time.
+// requestedAt is the Unix timestamp indicating when the retry was requested.
+//
+// Like a manual run of a single schedule, if adding the request would exceed the queue size, RetryRun returns ErrManualQueueFull.
+func (stm *StoreTaskMeta) RetryRun(now, requestedAt int64, attempt uint32, makeID func() (platform.ID, error)) error {
+	if err := stm.ManuallyRunTimeRange(now, now, requestedAt, makeID); err != nil {
+		return err
+	}
+	stm.ManualRuns[len(stm.ManualRuns)-1].Try = attempt
+	return nil
+}
+
+// NextAttempt returns the attempt number of the retry of a run with the given attempt number.
+// Runs recorded before attempts were tracked have attempt number 0, and are counted as first attempts.
+func NextAttempt(attempt uint32) uint32 {
+	if attempt == 0 {
+		return 2
+	}
+	return attempt + 1
+}
+
 // Equal returns true if all of stm's fields compare equal to other.
 // Note that this method operates on values, unlike the other methods which operate on pointers.
 //
@@ -310,7 +379,8 @@ func (stm StoreTaskMeta) Equal(other StoreTaskMeta) bool {
 		if s.Start != o.Start ||
 			s.End != o.End ||
 			s.LatestCompleted != o.LatestCompleted ||
-			s.RequestedAt != o.RequestedAt {
+			s.RequestedAt != o.RequestedAt ||
+			s.Try != o.Try {
 			return false
 		}
 	}
@@ -47,7 +47,7 @@ func (m *StoreTaskMeta) Reset()         { *m = StoreTaskMeta{} }
 func (m *StoreTaskMeta) String() string { return proto.CompactTextString(m) }
 func (*StoreTaskMeta) ProtoMessage()    {}
 func (*StoreTaskMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_meta_841ef32afee093f0, []int{0}
+	return fileDescriptor_meta_2f3e52af921172e7, []int{0}
 }
 func (m *StoreTaskMeta) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -148,7 +148,8 @@ func (m *StoreTaskMeta) GetManualRuns() []*StoreTaskMetaManualRun {
 
 type StoreTaskMetaRun struct {
 	// now is the unix timestamp of the "now" value for the run.
-	Now   int64  `protobuf:"varint,1,opt,name=now,proto3" json:"now,omitempty"`
+	Now int64 `protobuf:"varint,1,opt,name=now,proto3" json:"now,omitempty"`
+	// try is the attempt number of the run, starting at 1 and incremented on every retry.
 	Try   uint32 `protobuf:"varint,2,opt,name=try,proto3" json:"try,omitempty"`
 	RunID uint64 `protobuf:"varint,3,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
 	// range_start is the start of the manual run's time range.
@@ -164,7 +165,7 @@ func (m *StoreTaskMetaRun) Reset()         { *m = StoreTaskMetaRun{} }
 func (m *StoreTaskMetaRun) String() string { return proto.CompactTextString(m) }
 func (*StoreTaskMetaRun) ProtoMessage()    {}
 func (*StoreTaskMetaRun) Descriptor() ([]byte, []int) {
-	return fileDescriptor_meta_841ef32afee093f0, []int{1}
+	return fileDescriptor_meta_2f3e52af921172e7, []int{1}
 }
 func (m *StoreTaskMetaRun) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -248,13 +249,16 @@ type StoreTaskMetaManualRun struct {
 	RequestedAt int64 `protobuf:"varint,4,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
 	// run_id is set ahead of time for retries of individual runs. Manually run time ranges do not receive an ID.
 	RunID uint64 `protobuf:"varint,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// try is the attempt number of the run retried by this request.
+	// It is zero for requests that are not retries, whose runs are first attempts.
+	Try uint32 `protobuf:"varint,6,opt,name=try,proto3" json:"try,omitempty"`
 }
 
 func (m *StoreTaskMetaManualRun) Reset()         { *m = StoreTaskMetaManualRun{} }
 func (m *StoreTaskMetaManualRun) String() string { return proto.CompactTextString(m) }
 func (*StoreTaskMetaManualRun) ProtoMessage()    {}
 func (*StoreTaskMetaManualRun) Descriptor() ([]byte, []int) {
-	return fileDescriptor_meta_841ef32afee093f0, []int{2}
+	return fileDescriptor_meta_2f3e52af921172e7, []int{2}
 }
 func (m *StoreTaskMetaManualRun) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -318,6 +322,13 @@ func (m *StoreTaskMetaManualRun) GetRunID() uint64 {
 	return 0
 }
 
+func (m *StoreTaskMetaManualRun) GetTry() uint32 {
+	if m != nil {
+		return m.Try
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*StoreTaskMeta)(nil), "com.influxdata.platform.task.backend.StoreTaskMeta")
 	proto.RegisterType((*StoreTaskMetaRun)(nil), "com.influxdata.platform.task.backend.StoreTaskMetaRun")
@@ -497,6 +508,11 @@ func (m *StoreTaskMetaManualRun) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintMeta(dAtA, i, uint64(m.RunID))
 	}
+	if m.Try != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMeta(dAtA, i, uint64(m.Try))
+	}
 	return i, nil
 }
 
@@ -604,6 +620,9 @@ func (m *StoreTaskMetaManualRun) Size() (n int) {
 	if m.RunID != 0 {
 		n += 1 + sovMeta(uint64(m.RunID))
 	}
+	if m.Try != 0 {
+		n += 1 + sovMeta(uint64(m.Try))
+	}
 	return n
 }
 
@@ -1192,6 +1211,25 @@ func (m *StoreTaskMetaManualRun) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Try", wireType)
+			}
+			m.Try = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMeta
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Try |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMeta(dAtA[iNdEx:])
@@ -1318,42 +1356,42 @@ var (
 	ErrIntOverflowMeta   = fmt.Errorf("proto: integer overflow")
 )
 
-func init() { proto.RegisterFile("meta.proto", fileDescriptor_meta_841ef32afee093f0) }
+func init() { proto.RegisterFile("meta.proto", fileDescriptor_meta_2f3e52af921172e7) }
 
-var fileDescriptor_meta_841ef32afee093f0 = []byte{
-	// 543 bytes of a gzipped FileDescriptorProto
+var fileDescriptor_meta_2f3e52af921172e7 = []byte{
+	// 544 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x93, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0x1b, 0xd2, 0x74, 0xab, 0x4b, 0xd7, 0x60, 0xa6, 0x29, 0x02, 0x91, 0x66, 0x15, 0x88,
+	0x14, 0xc7, 0x17, 0xd2, 0x64, 0xab, 0xcb, 0xd6, 0x60, 0xa6, 0x29, 0x02, 0x91, 0x66, 0x15, 0x88,
 	0x72, 0x09, 0x12, 0x48, 0x9c, 0x10, 0x52, 0x57, 0x38, 0xec, 0xb0, 0x8b, 0xc7, 0x09, 0x09, 0x45,
-	0x5e, 0xe2, 0x94, 0xa8, 0x89, 0x5d, 0x9c, 0x67, 0x68, 0xf9, 0x14, 0x7c, 0x14, 0xae, 0x7c, 0x03,
-	0x8e, 0x3b, 0x72, 0x9a, 0x50, 0xfb, 0x35, 0x38, 0x20, 0x3b, 0x69, 0xd9, 0x46, 0x0f, 0x68, 0xb7,
-	0xe7, 0xdf, 0x8b, 0x9f, 0xdf, 0xff, 0xff, 0x5e, 0x10, 0x2a, 0x18, 0xd0, 0x70, 0x26, 0x05, 0x08,
-	0xfc, 0x30, 0x16, 0x45, 0x98, 0xf1, 0x34, 0x57, 0xf3, 0x84, 0x6a, 0x9a, 0x53, 0x48, 0x85, 0x2c,
-	0x42, 0xa0, 0xe5, 0x34, 0x3c, 0xa3, 0xf1, 0x94, 0xf1, 0xe4, 0xde, 0xfe, 0x44, 0x4c, 0x84, 0xb9,
-	0xf0, 0x54, 0x47, 0xd5, 0xdd, 0xc1, 0x6f, 0x1b, 0x75, 0x4f, 0x41, 0x48, 0xf6, 0x96, 0x96, 0xd3,
-	0x13, 0x06, 0x14, 0x3f, 0x46, 0xbd, 0x82, 0xce, 0xa3, 0x58, 0xf0, 0x58, 0x49, 0xc9, 0x78, 0xbc,
-	0xf0, 0xac, 0xc0, 0x1a, 0x3a, 0x64, 0xaf, 0xa0, 0xf3, 0xf1, 0x5f, 0x8a, 0x9f, 0x20, 0x37, 0xa7,
-	0xc0, 0x4a, 0x88, 0x62, 0x51, 0xcc, 0x72, 0x06, 0x2c, 0xf1, 0x6e, 0x05, 0xd6, 0xd0, 0x26, 0xbd,
-	0x8a, 0x8f, 0xd7, 0x18, 0x1f, 0xa0, 0x56, 0x09, 0x14, 0x54, 0xe9, 0xd9, 0x81, 0x35, 0x6c, 0x93,
-	0xfa, 0x84, 0x63, 0x74, 0xa7, 0x2a, 0x07, 0xf9, 0x22, 0x92, 0x8a, 0xf3, 0x8c, 0x4f, 0xbc, 0x66,
-	0x60, 0x0f, 0x3b, 0xcf, 0x5e, 0x84, 0xff, 0xa3, 0x2a, 0xbc, 0xd2, 0x3b, 0x51, 0x9c, 0xb8, 0x9b,
-	0x82, 0xa4, 0xaa, 0x87, 0x1f, 0xa1, 0x3d, 0x96, 0xa6, 0x2c, 0x86, 0xec, 0x13, 0x8b, 0x62, 0x29,
-	0xb8, 0xe7, 0x98, 0x26, 0xba, 0x1b, 0x3a, 0x96, 0x82, 0xeb, 0x1e, 0x45, 0x9a, 0x96, 0x0c, 0xbc,
-	0x96, 0x91, 0x5b, 0x9f, 0xf0, 0x03, 0x84, 0x62, 0xc9, 0x28, 0xb0, 0x24, 0xa2, 0xe0, 0xed, 0x18,
-	0x81, 0xed, 0x9a, 0x8c, 0x4c, 0x5a, 0xcd, 0x92, 0x75, 0x7a, 0xb7, 0x4a, 0xd7, 0x64, 0x04, 0xf8,
-	0x15, 0x72, 0xa9, 0x82, 0x0f, 0x42, 0x66, 0x5f, 0x28, 0x64, 0x82, 0x47, 0x59, 0xe2, 0xb5, 0x03,
-	0x6b, 0xd8, 0x3c, 0xba, 0xbb, 0xbc, 0xe8, 0xf7, 0x46, 0x97, 0x73, 0xc7, 0xaf, 0x49, 0xef, 0xca,
-	0xc7, 0xc7, 0x09, 0x7e, 0x8f, 0x3a, 0x05, 0xe5, 0x8a, 0xe6, 0xda, 0x9e, 0xd2, 0x73, 0x8d, 0x37,
-	0x2f, 0x6f, 0xe0, 0xcd, 0x89, 0xa9, 0xa2, 0x1d, 0x42, 0xc5, 0x3a, 0x2c, 0x07, 0xdf, 0x2d, 0xe4,
-	0x5e, 0xb7, 0x10, 0xbb, 0xc8, 0xe6, 0xe2, 0xb3, 0x99, 0xba, 0x4d, 0x74, 0xa8, 0x09, 0xc8, 0x85,
-	0x99, 0x6e, 0x97, 0xe8, 0x10, 0x07, 0xa8, 0x25, 0x95, 0x51, 0x63, 0x1b, 0x35, 0xed, 0xe5, 0x45,
-	0xdf, 0x21, 0x4a, 0x6b, 0x70, 0xa4, 0xd2, 0x9d, 0xf7, 0x51, 0x47, 0x52, 0x3e, 0x61, 0x51, 0x09,
-	0x54, 0x82, 0xd7, 0x34, 0xd5, 0x90, 0x41, 0xa7, 0x9a, 0xe0, 0xfb, 0xa8, 0x5d, 0x7d, 0xc0, 0x78,
-	0x62, 0x46, 0x62, 0x93, 0x5d, 0x03, 0xde, 0xf0, 0x04, 0x1f, 0xa2, 0xdb, 0x92, 0x7d, 0x54, 0xac,
-	0xac, 0x8d, 0x6d, 0x99, 0x7c, 0x67, 0xc3, 0x46, 0x30, 0xf8, 0x66, 0xa1, 0x83, 0xed, 0x12, 0xf1,
-	0x3e, 0x72, 0xaa, 0x57, 0x2b, 0x0d, 0xd5, 0x41, 0xab, 0xd0, 0x4f, 0x55, 0x3b, 0xaa, 0xc3, 0xad,
-	0x2b, 0x6c, 0x6f, 0x5f, 0xe1, 0xeb, 0x0d, 0x35, 0xff, 0x69, 0xe8, 0x92, 0x27, 0xce, 0x76, 0x4f,
-	0x8e, 0x0e, 0x7f, 0x2c, 0x7d, 0xeb, 0x7c, 0xe9, 0x5b, 0xbf, 0x96, 0xbe, 0xf5, 0x75, 0xe5, 0x37,
-	0xce, 0x57, 0x7e, 0xe3, 0xe7, 0xca, 0x6f, 0xbc, 0xdb, 0xa9, 0x87, 0x76, 0xd6, 0x32, 0xff, 0xe5,
-	0xf3, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x74, 0xb0, 0xab, 0x79, 0xe1, 0x03, 0x00, 0x00,
+	0x5e, 0xe2, 0x94, 0xa8, 0x89, 0x5d, 0x9c, 0x67, 0x68, 0xf9, 0x14, 0x7c, 0x1d, 0xae, 0x9c, 0x38,
+	0xee, 0xc8, 0x69, 0x42, 0xed, 0xd7, 0xe0, 0x80, 0xec, 0xa4, 0x65, 0x1b, 0x45, 0x42, 0xdc, 0x9e,
+	0x7f, 0x2f, 0x7e, 0x7e, 0xff, 0xff, 0x7b, 0x41, 0xa8, 0x64, 0x40, 0xa3, 0xa9, 0x14, 0x20, 0xf0,
+	0xfd, 0x44, 0x94, 0x51, 0xce, 0xb3, 0x42, 0xcd, 0x52, 0xaa, 0x69, 0x41, 0x21, 0x13, 0xb2, 0x8c,
+	0x80, 0x56, 0x93, 0xe8, 0x8c, 0x26, 0x13, 0xc6, 0xd3, 0x3b, 0xfb, 0x63, 0x31, 0x16, 0xe6, 0xc2,
+	0x63, 0x1d, 0xd5, 0x77, 0xfb, 0x3f, 0x6d, 0xb4, 0x7b, 0x0a, 0x42, 0xb2, 0xd7, 0xb4, 0x9a, 0x9c,
+	0x30, 0xa0, 0xf8, 0x21, 0xea, 0x96, 0x74, 0x16, 0x27, 0x82, 0x27, 0x4a, 0x4a, 0xc6, 0x93, 0xb9,
+	0x6f, 0x85, 0xd6, 0xc0, 0x21, 0x7b, 0x25, 0x9d, 0x8d, 0x7e, 0x53, 0xfc, 0x08, 0x79, 0x05, 0x05,
+	0x56, 0x41, 0x9c, 0x88, 0x72, 0x5a, 0x30, 0x60, 0xa9, 0x7f, 0x23, 0xb4, 0x06, 0x36, 0xe9, 0xd6,
+	0x7c, 0xb4, 0xc2, 0xf8, 0x00, 0xb9, 0x15, 0x50, 0x50, 0x95, 0x6f, 0x87, 0xd6, 0xa0, 0x4d, 0x9a,
+	0x13, 0x4e, 0xd0, 0xad, 0xba, 0x1c, 0x14, 0xf3, 0x58, 0x2a, 0xce, 0x73, 0x3e, 0xf6, 0x5b, 0xa1,
+	0x3d, 0xe8, 0x3c, 0x79, 0x16, 0xfd, 0x8b, 0xaa, 0xe8, 0x4a, 0xef, 0x44, 0x71, 0xe2, 0xad, 0x0b,
+	0x92, 0xba, 0x1e, 0x7e, 0x80, 0xf6, 0x58, 0x96, 0xb1, 0x04, 0xf2, 0x0f, 0x2c, 0x4e, 0xa4, 0xe0,
+	0xbe, 0x63, 0x9a, 0xd8, 0x5d, 0xd3, 0x91, 0x14, 0x5c, 0xf7, 0x28, 0xb2, 0xac, 0x62, 0xe0, 0xbb,
+	0x46, 0x6e, 0x73, 0xc2, 0xf7, 0x10, 0x4a, 0x24, 0xa3, 0xc0, 0xd2, 0x98, 0x82, 0xbf, 0x6d, 0x04,
+	0xb6, 0x1b, 0x32, 0x34, 0x69, 0x35, 0x4d, 0x57, 0xe9, 0x9d, 0x3a, 0xdd, 0x90, 0x21, 0xe0, 0x17,
+	0xc8, 0xa3, 0x0a, 0xde, 0x09, 0x99, 0x7f, 0xa2, 0x90, 0x0b, 0x1e, 0xe7, 0xa9, 0xdf, 0x0e, 0xad,
+	0x41, 0xeb, 0xe8, 0xf6, 0xe2, 0xa2, 0xd7, 0x1d, 0x5e, 0xce, 0x1d, 0xbf, 0x24, 0xdd, 0x2b, 0x1f,
+	0x1f, 0xa7, 0xf8, 0x2d, 0xea, 0x94, 0x94, 0x2b, 0x5a, 0x68, 0x7b, 0x2a, 0xdf, 0x33, 0xde, 0x3c,
+	0xff, 0x0f, 0x6f, 0x4e, 0x4c, 0x15, 0xed, 0x10, 0x2a, 0x57, 0x61, 0xd5, 0xff, 0x62, 0x21, 0xef,
+	0xba, 0x85, 0xd8, 0x43, 0x36, 0x17, 0x1f, 0xcd, 0xd4, 0x6d, 0xa2, 0x43, 0x4d, 0x40, 0xce, 0xcd,
+	0x74, 0x77, 0x89, 0x0e, 0x71, 0x88, 0x5c, 0xa9, 0x8c, 0x1a, 0xdb, 0xa8, 0x69, 0x2f, 0x2e, 0x7a,
+	0x0e, 0x51, 0x5a, 0x83, 0x23, 0x95, 0xee, 0xbc, 0x87, 0x3a, 0x92, 0xf2, 0x31, 0x8b, 0x2b, 0xa0,
+	0x12, 0xfc, 0x96, 0xa9, 0x86, 0x0c, 0x3a, 0xd5, 0x04, 0xdf, 0x45, 0xed, 0xfa, 0x03, 0xc6, 0x53,
+	0x33, 0x12, 0x9b, 0xec, 0x18, 0xf0, 0x8a, 0xa7, 0xf8, 0x10, 0xdd, 0x94, 0xec, 0xbd, 0x62, 0x55,
+	0x63, 0xac, 0x6b, 0xf2, 0x9d, 0x35, 0x1b, 0x42, 0xff, 0xab, 0x85, 0x0e, 0x36, 0x4b, 0xc4, 0xfb,
+	0xc8, 0xa9, 0x5f, 0xad, 0x35, 0xd4, 0x07, 0xad, 0x42, 0x3f, 0x55, 0xef, 0xa8, 0x0e, 0x37, 0xae,
+	0xb0, 0xbd, 0x79, 0x85, 0xaf, 0x37, 0xd4, 0xfa, 0xa3, 0xa1, 0x4b, 0x9e, 0x38, 0x7f, 0xf1, 0xa4,
+	0xf1, 0xd1, 0x5d, 0xfb, 0x78, 0x74, 0xf8, 0x6d, 0x11, 0x58, 0xe7, 0x8b, 0xc0, 0xfa, 0xb1, 0x08,
+	0xac, 0xcf, 0xcb, 0x60, 0xeb, 0x7c, 0x19, 0x6c, 0x7d, 0x5f, 0x06, 0x5b, 0x6f, 0xb6, 0x9b, 0x31,
+	0x9e, 0xb9, 0xe6, 0x4f, 0x7d, 0xfa, 0x6b, 0x00, 0x7f, 0xa9, 0x64, 0x5e, 0xf3, 0x03, 0x00, 0x00,
 }
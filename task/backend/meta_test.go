@@ -279,3 +279,217 @@ func TestMeta_ManuallyRunTimeRange(t *testing.T) {
 
 	// Not currently enforcing one way or another when a newly requested time range overlaps with an existing one.
 }
+
+func TestMeta_RetryRun(t *testing.T) {
+	stm := backend.StoreTaskMeta{
+		MaxConcurrency:  2,
+		Status:          "enabled",
+		EffectiveCron:   "* * * * *", // Every minute.
+		LatestCompleted: 180,
+	}
+	makeID := func() (platform.ID, error) { return platform.ID(10), nil }
+
+	// A natural run is the first attempt.
+	rc, err := stm.CreateNextRun(240, makeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Created.Attempt != 1 {
+		t.Fatalf("expected natural run to be attempt 1, got %d", rc.Created.Attempt)
+	}
+	if !stm.FinishRun(rc.Created.RunID) {
+		t.Fatal("failed to finish run")
+	}
+
+	// A manual run is the first attempt too.
+	if err := stm.ManuallyRunTimeRange(60, 60, 250, func() (platform.ID, error) { return platform.ID(11), nil }); err != nil {
+		t.Fatal(err)
+	}
+	rc, err = stm.CreateNextRun(250, makeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Created.Attempt != 1 {
+		t.Fatalf("expected manual run to be attempt 1, got %d", rc.Created.Attempt)
+	}
+	if !stm.FinishRun(rc.Created.RunID) {
+		t.Fatal("failed to finish run")
+	}
+
+	// A retry is created as the requested attempt.
+	if err := stm.RetryRun(120, 260, 3, func() (platform.ID, error) { return platform.ID(12), nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got := stm.ManualRuns[0].Try; got != 3 {
+		t.Fatalf("expected queued retry to be attempt 3, got %d", got)
+	}
+	rc, err = stm.CreateNextRun(260, makeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Created.RunID != platform.ID(12) || rc.Created.Now != 120 || rc.Created.RequestedAt != 260 {
+		t.Fatalf("unexpected retry created: %#v", rc.Created)
+	}
+	if rc.Created.Attempt != 3 {
+		t.Fatalf("expected retry to be attempt 3, got %d", rc.Created.Attempt)
+	}
+	if got := stm.CurrentlyRunning[0].Try; got != 3 {
+		t.Fatalf("expected running retry to be try 3, got %d", got)
+	}
+}
+
+func TestNextAttempt(t *testing.T) {
+	for attempt, want := range map[uint32]uint32{0: 2, 1: 2, 2: 3} {
+		if got := backend.NextAttempt(attempt); got != want {
+			t.Errorf("expected next attempt of %d to be %d, got %d", attempt, want, got)
+		}
+	}
+}
+
+func TestMeta_NextScheduledRuns(t *testing.T) {
+	stm := backend.StoreTaskMeta{
+		MaxConcurrency:  2,
+		Status:          "enabled",
+		EffectiveCron:   "@every 1m",
+		Offset:          5,
+		LatestCompleted: 60,
+		CurrentlyRunning: []*backend.StoreTaskMetaRun{
+			{Now: 120, Try: 1, RunID: 1},
+		},
+	}
+
+	runs, err := stm.NextScheduledRuns(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []backend.ScheduledRun{
+		{Now: 180, DueAt: 185},
+		{Now: 240, DueAt: 245},
+		{Now: 300, DueAt: 305},
+	}
+	if len(runs) != len(exp) {
+		t.Fatalf("expected %d runs, got %d", len(exp), len(runs))
+	}
+	for i := range exp {
+		if runs[i] != exp[i] {
+			t.Fatalf("run %d: expected %+v, got %+v", i, exp[i], runs[i])
+		}
+	}
+
+	// The first scheduled run must agree with the scheduler's next due time.
+	due, err := stm.NextDueRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due != runs[0].DueAt {
+		t.Fatalf("NextDueRun returned %d, but first scheduled run is due at %d", due, runs[0].DueAt)
+	}
+
+	if _, err := stm.NextScheduledRuns(0); err == nil {
+		t.Fatal("expected error when requesting zero runs")
+	}
+
+	stm.EffectiveCron = "not a cron"
+	if _, err := stm.NextScheduledRuns(1); err == nil {
+		t.Fatal("expected error with bad cron")
+	}
+}
+
+func TestMeta_NextScheduledRuns_Timezone(t *testing.T) {
+	mustParse := func(s string) int64 {
+		t.Helper()
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts.Unix()
+	}
+
+	for _, c := range []struct {
+		name   string
+		cron   string
+		latest string
+		exp    []string
+	}{
+		{
+			name:   "daily in new york, across spring forward",
+			cron:   "TZ=America/New_York 30 2 * * *",
+			latest: "2019-03-08T12:00:00Z",
+			// 02:30 does not exist on March 10, so that run is shifted by the gap to 03:30 EDT.
+			exp: []string{"2019-03-09T02:30:00-05:00", "2019-03-10T03:30:00-04:00", "2019-03-11T02:30:00-04:00"},
+		},
+		{
+			name:   "daily in new york, across fall back",
+			cron:   "TZ=America/New_York 30 1 * * *",
+			latest: "2019-11-02T12:00:00Z",
+			// 01:30 happens twice on November 3, and only the first one is scheduled.
+			exp: []string{"2019-11-03T01:30:00-04:00", "2019-11-04T01:30:00-05:00"},
+		},
+		{
+			name:   "every 30 minutes in new york, across spring forward",
+			cron:   "TZ=America/New_York */30 * * * *",
+			latest: "2019-03-10T06:00:00Z",
+			exp:    []string{"2019-03-10T01:30:00-05:00", "2019-03-10T03:00:00-04:00", "2019-03-10T03:30:00-04:00"},
+		},
+		{
+			name:   "every 30 minutes in new york, across fall back",
+			cron:   "TZ=America/New_York */30 * * * *",
+			latest: "2019-11-03T05:00:00Z",
+			exp:    []string{"2019-11-03T01:30:00-04:00", "2019-11-03T02:00:00-05:00"},
+		},
+		{
+			name:   "half hour offset",
+			cron:   "TZ=Asia/Kolkata 0 9 * * *",
+			latest: "2019-06-01T00:00:00Z",
+			exp:    []string{"2019-06-01T09:00:00+05:30", "2019-06-02T09:00:00+05:30"},
+		},
+		{
+			name:   "descriptor",
+			cron:   "TZ=Europe/Paris @daily",
+			latest: "2019-06-01T00:00:00Z",
+			exp:    []string{"2019-06-02T00:00:00+02:00"},
+		},
+		{
+			name:   "constant delay",
+			cron:   "TZ=Europe/Paris @every 1h",
+			latest: "2019-06-01T00:00:00Z",
+			exp:    []string{"2019-06-01T01:00:00Z"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			stm := backend.StoreTaskMeta{
+				MaxConcurrency:  1,
+				Status:          "enabled",
+				EffectiveCron:   c.cron,
+				LatestCompleted: mustParse(c.latest),
+			}
+
+			runs, err := stm.NextScheduledRuns(len(c.exp))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range c.exp {
+				if exp := mustParse(c.exp[i]); runs[i].Now != exp {
+					t.Errorf("run %d: expected %s, got %s", i, c.exp[i], time.Unix(runs[i].Now, 0).UTC().Format(time.RFC3339))
+				}
+			}
+
+			// The scheduler creates the runs at the same times.
+			for i := range c.exp {
+				rc, err := stm.CreateNextRun(runs[i].DueAt, makeID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rc.Created.Now != runs[i].Now {
+					t.Errorf("run %d: created for %d, expected %d", i, rc.Created.Now, runs[i].Now)
+				}
+				stm.FinishRun(rc.Created.RunID)
+			}
+		})
+	}
+
+	stm := backend.StoreTaskMeta{EffectiveCron: "TZ=Mars/Olympus_Mons 0 9 * * *"}
+	if _, err := stm.NextScheduledRuns(1); err == nil {
+		t.Fatal("expected error with unknown time zone")
+	}
+}
@@ -2,11 +2,14 @@ package backend
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
 )
 
 const (
@@ -14,6 +17,7 @@ const (
 	runIDField        = "runID"
 	scheduledForField = "scheduledFor"
 	requestedAtField  = "requestedAt"
+	attemptField      = "attempt"
 	statusField       = "status"
 
 	taskIDTag = "taskID"
@@ -28,27 +32,172 @@ type PointsWriter interface {
 	WritePoints(ctx context.Context, points []models.Point) error
 }
 
+// LogBatchConfig configures how a PointLogWriter batches the points of run states and logs,
+// to write them at once rather than one by one.
+type LogBatchConfig struct {
+	// Size is the number of buffered run states and logs that are written at once.
+	// They are written as they are added if Size is 1 or less.
+	Size int
+
+	// FlushInterval is the longest a buffered point waits to be written.
+	// Buffered points only wait for the batch to fill, or their run to finish, if it is 0.
+	FlushInterval time.Duration
+}
+
+// PointLogWriterOption configures a PointLogWriter.
+type PointLogWriterOption func(*PointLogWriter)
+
+// WithLogBatching makes the PointLogWriter buffer points and write them in batches.
+// The buffered points are also written when a run finishes, so that the final state and logs of a run are readable
+// as soon as UpdateRunState returns.
+func WithLogBatching(cfg LogBatchConfig) PointLogWriterOption {
+	return func(p *PointLogWriter) {
+		p.batch = cfg
+	}
+}
+
+// WithLogWriterClock sets the clock timing the flush interval of batches.
+func WithLogWriterClock(c Clock) PointLogWriterOption {
+	return func(p *PointLogWriter) {
+		p.clock = c
+	}
+}
+
+// WithLogWriterLogger sets the logger reporting batches that failed to be written after their flush interval.
+func WithLogWriterLogger(logger *zap.Logger) PointLogWriterOption {
+	return func(p *PointLogWriter) {
+		p.logger = logger
+	}
+}
+
+// finishedRunStates is the number of finished runs whose state a PointLogWriter remembers at most.
+const finishedRunStates = 10000
+
 // PointLogWriter writes task and run logs as time-series points.
+//
+// It rejects the run state updates that ValidateRunTransition does not allow. As the points are only written,
+// the writer remembers the state of the runs it updated: the runs in progress, and the last finishedRunStates runs
+// that finished. A run it does not remember, such as one started before a restart, is considered scheduled.
+// The runs are told apart by their organization and task too, as a moved task has its runs copied to its new organization.
 type PointLogWriter struct {
 	pointsWriter PointsWriter
+	batch        LogBatchConfig
+	clock        Clock
+	logger       *zap.Logger
+
+	runsMu   sync.Mutex
+	runs     map[runKey]RunStatus
+	finished []runKey // finished runs in runs, oldest first
+
+	mu  sync.Mutex
+	buf []models.Point
+	n   int // number of run states and logs in buf
+	// gen is incremented whenever the buffer is taken,
+	// so that a flush interval only flushes the batch it was started for.
+	gen uint64
 }
 
 // NewPointLogWriter returns a PointLogWriter.
-func NewPointLogWriter(pw PointsWriter) *PointLogWriter {
-	return &PointLogWriter{pointsWriter: pw}
+// Without WithLogBatching, it writes points synchronously, one run state or log at a time.
+func NewPointLogWriter(pw PointsWriter, opts ...PointLogWriterOption) *PointLogWriter {
+	p := &PointLogWriter{
+		pointsWriter: pw,
+		clock:        SystemClock{},
+		logger:       zap.NewNop(),
+		runs:         make(map[runKey]RunStatus),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
+// Flush writes the buffered points.
+func (p *PointLogWriter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	buf := p.take()
+	p.mu.Unlock()
+
+	if len(buf) == 0 {
+		return nil
+	}
+	return p.pointsWriter.WritePoints(ctx, buf)
+}
+
+// write writes the points of a run state or log, or buffers them until the batch is full, flush is set or the flush interval passes.
+func (p *PointLogWriter) write(ctx context.Context, points []models.Point, flush bool) error {
+	if p.batch.Size <= 1 {
+		return p.pointsWriter.WritePoints(ctx, points)
+	}
+
+	p.mu.Lock()
+	if len(p.buf) == 0 && p.batch.FlushInterval > 0 {
+		go p.flushAfter(p.clock.After(p.batch.FlushInterval), p.gen)
+	}
+	p.buf = append(p.buf, points...)
+	p.n++
+	if !flush && p.n < p.batch.Size {
+		p.mu.Unlock()
+		return nil
+	}
+	buf := p.take()
+	p.mu.Unlock()
+
+	return p.pointsWriter.WritePoints(ctx, buf)
+}
+
+// flushAfter writes the batch of generation gen once c fires, unless it was already written.
+func (p *PointLogWriter) flushAfter(c <-chan time.Time, gen uint64) {
+	<-c
+
+	p.mu.Lock()
+	if p.gen != gen {
+		p.mu.Unlock()
+		return
+	}
+	buf := p.take()
+	p.mu.Unlock()
+
+	if err := p.pointsWriter.WritePoints(context.Background(), buf); err != nil {
+		p.logger.Error("Failed to write batch of task run points", zap.Int("points", len(buf)), zap.Error(err))
+	}
+}
+
+// take returns the buffered points and empties the buffer. p.mu must be held.
+func (p *PointLogWriter) take() []models.Point {
+	buf := p.buf
+	p.buf = nil
+	p.n = 0
+	p.gen++
+	return buf
+}
+
+// UpdateRunState writes the state of a run, or returns an InvalidRunTransitionError if the run cannot go to status.
 func (p *PointLogWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, when time.Time, status RunStatus) error {
+	p.runsMu.Lock()
+	current, ok := p.runs[newRunKey(rlb)]
+	p.runsMu.Unlock()
+	if !ok {
+		current = RunScheduled
+	}
+	if err := ValidateRunTransition(current, status); err != nil {
+		return err
+	}
+
 	tags := models.Tags{
 		models.NewTag([]byte(taskIDTag), []byte(rlb.Task.ID.String())),
 	}
-	fields := make(map[string]interface{}, 4)
+	fields := make(map[string]interface{}, 5)
 	fields[statusField] = status.String()
 	fields[runIDField] = rlb.RunID.String()
 	fields[scheduledForField] = time.Unix(rlb.RunScheduledFor, 0).UTC().Format(time.RFC3339)
 	if rlb.RequestedAt != 0 {
 		fields[requestedAtField] = time.Unix(rlb.RequestedAt, 0).UTC().Format(time.RFC3339)
 	}
+	if rlb.Attempt != 0 {
+		// All the fields of a record are strings, so that they can be pivoted into columns.
+		fields[attemptField] = strconv.FormatUint(uint64(rlb.Attempt), 10)
+	}
 
 	pt, err := models.NewPoint("records", tags, fields, when)
 	if err != nil {
@@ -61,7 +210,36 @@ func (p *PointLogWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, whe
 		return err
 	}
 
-	return p.pointsWriter.WritePoints(ctx, exploded)
+	if err := p.write(ctx, exploded, status.finished()); err != nil {
+		return err
+	}
+	p.setRunState(newRunKey(rlb), status)
+	return nil
+}
+
+// runKey identifies a run whose state is remembered by a PointLogWriter.
+type runKey struct {
+	org, task, run platform.ID
+}
+
+func newRunKey(rlb RunLogBase) runKey {
+	return runKey{org: rlb.Task.Org, task: rlb.Task.ID, run: rlb.RunID}
+}
+
+// setRunState remembers that the run id is in state status, and forgets the oldest finished runs past finishedRunStates.
+func (p *PointLogWriter) setRunState(id runKey, status RunStatus) {
+	p.runsMu.Lock()
+	defer p.runsMu.Unlock()
+
+	p.runs[id] = status
+	if !status.finished() {
+		return
+	}
+	p.finished = append(p.finished, id)
+	if len(p.finished) > finishedRunStates {
+		delete(p.runs, p.finished[0])
+		p.finished = p.finished[1:]
+	}
 }
 
 func (p *PointLogWriter) AddRunLog(ctx context.Context, rlb RunLogBase, when time.Time, log string) error {
@@ -83,5 +261,5 @@ func (p *PointLogWriter) AddRunLog(ctx context.Context, rlb RunLogBase, when tim
 		return err
 	}
 
-	return p.pointsWriter.WritePoints(ctx, exploded)
+	return p.write(ctx, exploded, false)
 }
@@ -0,0 +1,186 @@
+package backend_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/mock"
+)
+
+// recordingPointsWriter records the size of every call to WritePoints.
+type recordingPointsWriter struct {
+	mu     sync.Mutex
+	writes []int
+}
+
+func (w *recordingPointsWriter) WritePoints(_ context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, len(points))
+	return nil
+}
+
+func (w *recordingPointsWriter) Writes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]int(nil), w.writes...)
+}
+
+func TestPointLogWriter_Batching(t *testing.T) {
+	ctx := context.Background()
+	rlb := backend.RunLogBase{
+		Task:            &backend.StoreTask{ID: 1, Org: 2},
+		RunID:           3,
+		RunScheduledFor: 60,
+	}
+	now := time.Unix(120, 0)
+
+	t.Run("synchronous", func(t *testing.T) {
+		pw := &recordingPointsWriter{}
+		lw := backend.NewPointLogWriter(pw)
+
+		if err := lw.UpdateRunState(ctx, rlb, now, backend.RunStarted); err != nil {
+			t.Fatal(err)
+		}
+		if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(pw.Writes()); got != 2 {
+			t.Fatalf("expected a write per run state and log, got %d writes", got)
+		}
+	})
+
+	t.Run("flush on size", func(t *testing.T) {
+		pw := &recordingPointsWriter{}
+		lw := backend.NewPointLogWriter(pw, backend.WithLogBatching(backend.LogBatchConfig{Size: 3}))
+
+		for i := 0; i < 2; i++ {
+			if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := pw.Writes(); len(got) != 0 {
+			t.Fatalf("expected points to be buffered, got writes %v", got)
+		}
+
+		if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+			t.Fatal(err)
+		}
+		writes := pw.Writes()
+		if len(writes) != 1 || writes[0] != 3*runLogPoints(t, rlb) {
+			t.Fatalf("expected one write of the full batch, got writes %v", writes)
+		}
+	})
+
+	t.Run("flush on finished run", func(t *testing.T) {
+		pw := &recordingPointsWriter{}
+		lw := backend.NewPointLogWriter(pw, backend.WithLogBatching(backend.LogBatchConfig{Size: 100}))
+
+		if err := lw.UpdateRunState(ctx, rlb, now, backend.RunStarted); err != nil {
+			t.Fatal(err)
+		}
+		if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+			t.Fatal(err)
+		}
+		if got := pw.Writes(); len(got) != 0 {
+			t.Fatalf("expected points to be buffered, got writes %v", got)
+		}
+
+		if err := lw.UpdateRunState(ctx, rlb, now, backend.RunSuccess); err != nil {
+			t.Fatal(err)
+		}
+		if got := pw.Writes(); len(got) != 1 {
+			t.Fatalf("expected finishing the run to write the batch, got writes %v", got)
+		}
+	})
+
+	t.Run("flush on interval", func(t *testing.T) {
+		pw := &recordingPointsWriter{}
+		clock := mock.NewClock(now)
+		lw := backend.NewPointLogWriter(pw,
+			backend.WithLogBatching(backend.LogBatchConfig{Size: 100, FlushInterval: time.Second}),
+			backend.WithLogWriterClock(clock),
+		)
+
+		if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+			t.Fatal(err)
+		}
+		clock.Add(999 * time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		if got := pw.Writes(); len(got) != 0 {
+			t.Fatalf("expected points to be buffered before the flush interval, got writes %v", got)
+		}
+
+		clock.Add(time.Millisecond)
+		deadline := time.Now().Add(time.Second)
+		for len(pw.Writes()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := pw.Writes(); len(got) != 1 {
+			t.Fatalf("expected the flush interval to write the batch, got writes %v", got)
+		}
+	})
+
+	t.Run("explicit flush", func(t *testing.T) {
+		pw := &recordingPointsWriter{}
+		lw := backend.NewPointLogWriter(pw, backend.WithLogBatching(backend.LogBatchConfig{Size: 100}))
+
+		if err := lw.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := pw.Writes(); len(got) != 0 {
+			t.Fatalf("expected flushing an empty batch not to write, got writes %v", got)
+		}
+
+		if err := lw.AddRunLog(ctx, rlb, now, "a log"); err != nil {
+			t.Fatal(err)
+		}
+		if err := lw.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := pw.Writes(); len(got) != 1 {
+			t.Fatalf("expected Flush to write the batch, got writes %v", got)
+		}
+	})
+}
+
+// runLogPoints returns the number of points written for a single run log.
+func runLogPoints(t *testing.T, rlb backend.RunLogBase) int {
+	t.Helper()
+	pw := &recordingPointsWriter{}
+	if err := backend.NewPointLogWriter(pw).AddRunLog(context.Background(), rlb, time.Unix(120, 0), "a log"); err != nil {
+		t.Fatal(err)
+	}
+	return pw.Writes()[0]
+}
+
+func TestPointLogWriter_RunTransitions(t *testing.T) {
+	ctx := context.Background()
+	rlb := backend.RunLogBase{
+		Task:            &backend.StoreTask{ID: 1, Org: 2},
+		RunID:           3,
+		RunScheduledFor: 60,
+	}
+	now := time.Unix(120, 0)
+	pw := &recordingPointsWriter{}
+	lw := backend.NewPointLogWriter(pw)
+
+	if err := lw.UpdateRunState(ctx, rlb, now, backend.RunSuccess); err != (backend.InvalidRunTransitionError{From: backend.RunScheduled, To: backend.RunSuccess}) {
+		t.Fatalf("expected a scheduled run not to succeed before starting, got %v", err)
+	}
+	for _, status := range []backend.RunStatus{backend.RunStarted, backend.RunSuccess} {
+		if err := lw.UpdateRunState(ctx, rlb, now, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lw.UpdateRunState(ctx, rlb, now, backend.RunStarted); err != (backend.InvalidRunTransitionError{From: backend.RunSuccess, To: backend.RunStarted}) {
+		t.Fatalf("expected a finished run not to start again, got %v", err)
+	}
+	if got := len(pw.Writes()); got != 2 {
+		t.Fatalf("expected only the valid run states to be written, got %d writes", got)
+	}
+}
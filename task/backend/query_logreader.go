@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux/values"
@@ -88,10 +90,11 @@ func (qlr *QueryLogReader) ListRuns(ctx context.Context, orgID platform.ID, runF
 		return nil, errors.New("task required")
 	}
 
-	limit := "|> limit(n: 100)\n"
+	n := 100
 	if runFilter.Limit > 0 {
-		limit = fmt.Sprintf("|> limit(n: %d)\n", runFilter.Limit)
+		n = runFilter.Limit
 	}
+	limit := fmt.Sprintf("|> limit(n: %d)\n", n)
 
 	afterID := ""
 	if runFilter.After != nil {
@@ -103,13 +106,7 @@ func (qlr *QueryLogReader) ListRuns(ctx context.Context, orgID platform.ID, runF
 		scheduledBefore = runFilter.BeforeTime
 	}
 
-	// Because flux doesnt support piviting on a rowkey that might not exist we need first check if we can pivot with "requestedAt"
-	// and if that fails we can fall back to pivot without "requestedAt"
-	// TODO(lh): After we transition to a seperation of transactional and analytical stores this can be simplified.
-	pivotWithRequestedAt := `|> pivot(rowKey:["runID", "scheduledFor", "requestedAt"], columnKey: ["status"], valueColumn: "_time")`
-	pivotWithOutRequestedAt := `|> pivot(rowKey:["runID", "scheduledFor"], columnKey: ["status"], valueColumn: "_time")`
-
-	listFmtString := `
+	recordsFmtString := `
 import "influxdata/influxdb/v1"
 
 from(bucketID: "000000000000000a")
@@ -122,7 +119,6 @@ from(bucketID: "000000000000000a")
 	%s
 	%s
 	`
-	listScript := fmt.Sprintf(listFmtString, runFilter.Task.String(), scheduledBefore, scheduledAfter, afterID, pivotWithRequestedAt, limit)
 
 	auth, err := pctx.GetAuthorizer(ctx)
 	if err != nil {
@@ -131,36 +127,78 @@ from(bucketID: "000000000000000a")
 	if auth.Kind() != "authorization" {
 		return nil, platform.ErrAuthorizerNotSupported
 	}
-	request := &query.Request{Authorization: auth.(*platform.Authorization), OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: listScript}}
 
-	ittr, err := qlr.queryService.Query(ctx, request)
+	if runFilter.Status != "" {
+		// A run's status is its latest record, so the IDs of the first runs whose latest record has the status
+		// are found first, and only those runs are pivoted.
+		statusScript := fmt.Sprintf(recordsFmtString, runFilter.Task.String(), scheduledBefore, scheduledAfter, afterID, fmt.Sprintf(`|> keep(columns: ["runID", "status", "_time"])
+	|> group(columns: ["runID"])
+	|> sort(columns: ["_time"])
+	|> last(column: "status")
+	|> filter(fn: (r) => r.status == %q)
+	|> group()
+	|> sort(columns: ["runID"])`, runFilter.Status), limit)
+		ids, err := qlr.queryRunIDs(ctx, auth.(*platform.Authorization), orgID, statusScript)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return []*platform.Run{}, nil
+		}
+
+		predicates := make([]string, 0, len(ids))
+		for _, id := range ids {
+			predicates = append(predicates, fmt.Sprintf("r.runID == %q", id))
+		}
+		limit = fmt.Sprintf("|> filter(fn: (r) => %s)\n\t%s", strings.Join(predicates, " or "), limit)
+	}
+
+	runs, err := qlr.queryRuns(ctx, auth.(*platform.Authorization), orgID, func(pivot string) string {
+		return fmt.Sprintf(recordsFmtString, runFilter.Task.String(), scheduledBefore, scheduledAfter, afterID, pivot, limit)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	runs, err := queryIttrToRuns(ittr)
+	return runs, nil
+}
+
+// queryRunIDs returns the values of the runID column of the results of script.
+func (qlr *QueryLogReader) queryRunIDs(ctx context.Context, auth *platform.Authorization, orgID platform.ID, script string) ([]string, error) {
+	request := &query.Request{Authorization: auth, OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: script}}
+	ittr, err := qlr.queryService.Query(ctx, request)
 	if err != nil {
-		// try re running the script without the requested at
-		listScript := fmt.Sprintf(listFmtString, runFilter.Task.String(), scheduledBefore, scheduledAfter, afterID, pivotWithOutRequestedAt, limit)
-		request := &query.Request{Authorization: auth.(*platform.Authorization), OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: listScript}}
+		return nil, err
+	}
+	defer ittr.Release()
 
-		ittr, err := qlr.queryService.Query(ctx, request)
-		if err != nil {
-			return nil, err
-		}
-		runs, err = queryIttrToRuns(ittr)
+	var ids []string
+	for ittr.More() {
+		err := ittr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for j, col := range cr.Cols() {
+					if col.Label != "runID" {
+						continue
+					}
+					vs := cr.Strings(j)
+					for i := 0; i < cr.Len(); i++ {
+						ids = append(ids, vs.ValueString(i))
+					}
+				}
+				return nil
+			})
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	return runs, nil
+	if err := ittr.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (qlr *QueryLogReader) FindRunByID(ctx context.Context, orgID, runID platform.ID) (*platform.Run, error) {
-	pivotWithRequestedAt := `|> pivot(rowKey:["runID", "scheduledFor", "requestedAt"], columnKey: ["status"], valueColumn: "_time")`
-	pivotWithOutRequestedAt := `|> pivot(rowKey:["runID", "scheduledFor"], columnKey: ["status"], valueColumn: "_time")`
-
 	showFmtScript := `
 import "influxdata/influxdb/v1"
 
@@ -182,7 +220,6 @@ from(bucketID: "000000000000000a")
 	%s
 	|> yield(name: "result")
   `
-	showScript := fmt.Sprintf(showFmtScript, runID.String(), runID.String(), pivotWithRequestedAt)
 
 	auth, err := pctx.GetAuthorizer(ctx)
 	if err != nil {
@@ -191,27 +228,13 @@ from(bucketID: "000000000000000a")
 	if auth.Kind() != "authorization" {
 		return nil, platform.ErrAuthorizerNotSupported
 	}
-	request := &query.Request{Authorization: auth.(*platform.Authorization), OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: showScript}}
 
-	ittr, err := qlr.queryService.Query(ctx, request)
+	runs, err := qlr.queryRuns(ctx, auth.(*platform.Authorization), orgID, func(pivot string) string {
+		return fmt.Sprintf(showFmtScript, runID.String(), runID.String(), pivot)
+	})
 	if err != nil {
 		return nil, err
 	}
-	runs, err := queryIttrToRuns(ittr)
-	if err != nil {
-		showScript := fmt.Sprintf(showFmtScript, runID.String(), runID.String(), pivotWithOutRequestedAt)
-		request := &query.Request{Authorization: auth.(*platform.Authorization), OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: showScript}}
-
-		ittr, err := qlr.queryService.Query(ctx, request)
-		if err != nil {
-			return nil, err
-
-		}
-		runs, err = queryIttrToRuns(ittr)
-		if err != nil {
-			return nil, err
-		}
-	}
 	if len(runs) == 0 {
 		return nil, ErrRunNotFound
 	}
@@ -222,6 +245,39 @@ from(bucketID: "000000000000000a")
 	return runs[0], nil
 }
 
+// runPivots are the pivots of the run records into runs, from the one keeping the most fields to the one keeping the least.
+// Because flux doesnt support piviting on a rowkey that might not exist, the pivots are tried in order until one succeeds:
+// "requestedAt" is only recorded for manually requested runs, and "attempt" is not recorded for runs older than attempts.
+// TODO(lh): After we transition to a seperation of transactional and analytical stores this can be simplified.
+var runPivots = []string{
+	`|> pivot(rowKey:["runID", "scheduledFor", "requestedAt", "attempt"], columnKey: ["status"], valueColumn: "_time")`,
+	`|> pivot(rowKey:["runID", "scheduledFor", "attempt"], columnKey: ["status"], valueColumn: "_time")`,
+	`|> pivot(rowKey:["runID", "scheduledFor", "requestedAt"], columnKey: ["status"], valueColumn: "_time")`,
+	`|> pivot(rowKey:["runID", "scheduledFor"], columnKey: ["status"], valueColumn: "_time")`,
+}
+
+// queryRuns returns the runs queried by the script that script returns for a pivot of runPivots,
+// trying the pivots in order until one succeeds.
+func (qlr *QueryLogReader) queryRuns(ctx context.Context, auth *platform.Authorization, orgID platform.ID, script func(pivot string) string) ([]*platform.Run, error) {
+	var err error
+	for _, pivot := range runPivots {
+		request := &query.Request{Authorization: auth, OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: script(pivot)}}
+
+		var ittr flux.ResultIterator
+		ittr, err = qlr.queryService.Query(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		var runs []*platform.Run
+		runs, err = queryIttrToRuns(ittr)
+		if err == nil {
+			return runs, nil
+		}
+	}
+	return nil, err
+}
+
 func queryIttrToRuns(results flux.ResultIterator) ([]*platform.Run, error) {
 	defer results.Release()
 
@@ -289,6 +345,15 @@ func (re *runExtractor) extractRecord(cr flux.ColReader) error {
 			switch col.Label {
 			case requestedAtField:
 				r.RequestedAt = cr.Strings(j).ValueString(i)
+			case attemptField:
+				if cr.Strings(j).IsNull(i) {
+					continue
+				}
+				attempt, err := strconv.Atoi(cr.Strings(j).ValueString(i))
+				if err != nil {
+					return err
+				}
+				r.Attempt = attempt
 			case scheduledForField:
 				r.ScheduledFor = cr.Strings(j).ValueString(i)
 			case "runID":
@@ -304,12 +369,19 @@ func (re *runExtractor) extractRecord(cr flux.ColReader) error {
 				}
 				r.TaskID = *id
 			case RunStarted.String():
+				if cr.Times(j).IsNull(i) {
+					continue
+				}
 				r.StartedAt = values.Time(cr.Times(j).Value(i)).Time().Format(time.RFC3339Nano)
 				if r.Status == "" {
 					// Only set status if it wasn't already set.
 					r.Status = col.Label
 				}
 			case RunSuccess.String(), RunFail.String(), RunCanceled.String():
+				// After pivoting, every status seen in the table is a column, but a given run only has values for its own statuses.
+				if cr.Times(j).IsNull(i) {
+					continue
+				}
 				r.FinishedAt = values.Time(cr.Times(j).Value(i)).Time().Format(time.RFC3339Nano)
 				// Finished can be set unconditionally;
 				// it's fine to overwrite if the status was already set to started.
@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// TaskQuota limits the tasks of every organization.
+// A zero limit is not enforced.
+type TaskQuota struct {
+	// MaxActiveTasks is the maximum number of active tasks in an organization.
+	MaxActiveTasks int
+
+	// MaxRunsPerHour is the maximum number of runs created for the tasks of an organization over any hour.
+	MaxRunsPerHour int
+}
+
+// Names of the quotas reported by QuotaExceededError.
+const (
+	QuotaActiveTasks = "active tasks"
+	QuotaRunsPerHour = "runs per hour"
+)
+
+// QuotaExceededError is returned when creating or activating a task, or creating a run,
+// would exceed the TaskQuota of an organization.
+type QuotaExceededError struct {
+	Org platform.ID
+
+	// Quota is the name of the exceeded quota, QuotaActiveTasks or QuotaRunsPerHour.
+	Quota string
+	Limit int
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("organization %s has reached its quota of %d %s", e.Org, e.Limit, e.Quota)
+}
+
+// quotaStore enforces a TaskQuota in front of a Store.
+type quotaStore struct {
+	Store
+	quota TaskQuota
+
+	// mu serializes the quota checks with the changes they guard.
+	mu sync.Mutex
+
+	// runTimes holds, per organization, the Unix timestamps of the runs created within the last hour, oldest first.
+	runTimes map[platform.ID][]int64
+}
+
+// NewQuotaStore returns a Store that enforces q for every organization
+// when creating or activating a task in s, and when creating a run.
+func NewQuotaStore(s Store, q TaskQuota) Store {
+	return &quotaStore{
+		Store:    s,
+		quota:    q,
+		runTimes: make(map[platform.ID][]int64),
+	}
+}
+
+func (s *quotaStore) CreateTask(ctx context.Context, req CreateTaskRequest) (platform.ID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Status == "" || req.Status == TaskActive {
+		if err := s.checkActiveTasks(ctx, req.Org); err != nil {
+			return platform.InvalidID(), err
+		}
+	}
+
+	return s.Store.CreateTask(ctx, req)
+}
+
+func (s *quotaStore) UpdateTask(ctx context.Context, req UpdateTaskRequest) (UpdateTaskResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quota.MaxActiveTasks > 0 && (req.Status == TaskActive || req.Org.Valid()) {
+		task, meta, err := s.Store.FindTaskByIDWithMeta(ctx, req.ID)
+		if err != nil {
+			return UpdateTaskResult{}, err
+		}
+
+		status := TaskStatus(meta.Status)
+		if req.Status != "" {
+			status = req.Status
+		}
+		org := task.Org
+		if req.Org.Valid() {
+			org = req.Org
+		}
+
+		// Only a task that is not already counted as active in org takes up more of its quota.
+		alreadyCounted := org == task.Org && TaskStatus(meta.Status) == TaskActive
+		if status == TaskActive && !alreadyCounted {
+			if err := s.checkActiveTasks(ctx, org); err != nil {
+				return UpdateTaskResult{}, err
+			}
+		}
+	}
+
+	return s.Store.UpdateTask(ctx, req)
+}
+
+// checkActiveTasks returns a QuotaExceededError if org cannot have one more active task.
+func (s *quotaStore) checkActiveTasks(ctx context.Context, org platform.ID) error {
+	if s.quota.MaxActiveTasks <= 0 {
+		return nil
+	}
+
+	active := 0
+	params := TaskSearchParams{Org: org, PageSize: platform.TaskMaxPageSize}
+	for {
+		tasks, err := s.Store.ListTasks(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if TaskStatus(t.Meta.Status) == TaskActive {
+				active++
+			}
+		}
+		if active >= s.quota.MaxActiveTasks {
+			return QuotaExceededError{Org: org, Quota: QuotaActiveTasks, Limit: s.quota.MaxActiveTasks}
+		}
+		if len(tasks) < params.PageSize {
+			return nil
+		}
+		params.After = tasks[len(tasks)-1].Task.ID
+	}
+}
+
+func (s *quotaStore) CreateNextRun(ctx context.Context, taskID platform.ID, now int64) (RunCreation, error) {
+	if s.quota.MaxRunsPerHour <= 0 {
+		return s.Store.CreateNextRun(ctx, taskID, now)
+	}
+
+	task, err := s.Store.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return RunCreation{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget the runs created more than an hour ago.
+	times := s.runTimes[task.Org]
+	i := 0
+	for i < len(times) && times[i] <= now-3600 {
+		i++
+	}
+	times = times[i:]
+	s.runTimes[task.Org] = times
+
+	if len(times) >= s.quota.MaxRunsPerHour {
+		return RunCreation{}, QuotaExceededError{Org: task.Org, Quota: QuotaRunsPerHour, Limit: s.quota.MaxRunsPerHour}
+	}
+
+	rc, err := s.Store.CreateNextRun(ctx, taskID, now)
+	if err != nil {
+		return rc, err
+	}
+	s.runTimes[task.Org] = append(times, now)
+	return rc, nil
+}
@@ -0,0 +1,112 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/storetest"
+)
+
+func TestQuotaStore(t *testing.T) {
+	// Without limits, the quota store behaves exactly like the store it wraps.
+	storetest.NewStoreTest(
+		"quota store",
+		func(t *testing.T) backend.Store {
+			return backend.NewQuotaStore(backend.NewInMemStore(), backend.TaskQuota{})
+		},
+		func(t *testing.T, s backend.Store) {},
+	)(t)
+}
+
+const quotaScript = `option task = {
+	name: "a task",
+	cron: "* * * * *",
+	concurrency: 100,
+}
+
+from(bucket:"test") |> range(start:-1h)`
+
+func TestQuotaStore_MaxActiveTasks(t *testing.T) {
+	ctx := context.Background()
+	s := backend.NewQuotaStore(backend.NewInMemStore(), backend.TaskQuota{MaxActiveTasks: 2})
+
+	create := func(status backend.TaskStatus) error {
+		_, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 3, Script: quotaScript, Status: status})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := create(backend.TaskActive); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := create(backend.TaskActive)
+	if e, ok := err.(backend.QuotaExceededError); !ok {
+		t.Fatalf("expected QuotaExceededError, got %v (%T)", err, err)
+	} else if e.Org != 1 || e.Quota != backend.QuotaActiveTasks || e.Limit != 2 {
+		t.Fatalf("unexpected quota error: %+v", e)
+	}
+
+	// Inactive tasks do not count against the quota, but cannot be activated while it is reached.
+	inactiveID, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 3, Script: quotaScript, Status: backend.TaskInactive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: inactiveID, Status: backend.TaskActive}); err == nil {
+		t.Fatal("expected error activating a task over quota")
+	}
+
+	// Other organizations have their own quota.
+	if _, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 2, AuthorizationID: 3, Script: quotaScript}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updating an active task does not count it twice.
+	tasks, err := s.ListTasks(ctx, backend.TaskSearchParams{Org: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	activeID := tasks[0].Task.ID
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: activeID, Status: backend.TaskActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deactivating a task frees up the quota.
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: activeID, Status: backend.TaskInactive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: inactiveID, Status: backend.TaskActive}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuotaStore_MaxRunsPerHour(t *testing.T) {
+	ctx := context.Background()
+	s := backend.NewQuotaStore(backend.NewInMemStore(), backend.TaskQuota{MaxRunsPerHour: 2})
+
+	taskID, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 3, Script: quotaScript, ScheduleAfter: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateNextRun(ctx, taskID, 60); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateNextRun(ctx, taskID, 120); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.CreateNextRun(ctx, taskID, 180)
+	if e, ok := err.(backend.QuotaExceededError); !ok {
+		t.Fatalf("expected QuotaExceededError, got %v (%T)", err, err)
+	} else if e.Org != 1 || e.Quota != backend.QuotaRunsPerHour || e.Limit != 2 {
+		t.Fatalf("unexpected quota error: %+v", e)
+	}
+
+	// An hour after the first run, there is room for another one.
+	if _, err := s.CreateNextRun(ctx, taskID, 3660); err != nil {
+		t.Fatal(err)
+	}
+}
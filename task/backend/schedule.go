@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"strings"
+	"time"
+
+	cron "gopkg.in/robfig/cron.v2"
+)
+
+// parseSchedule parses an effective cron string of a task.
+//
+// An effective cron string prefixed with "TZ=" and a time zone, as set by the timezone task option,
+// is interpreted in the wall clock of that time zone, across daylight saving time transitions:
+// a time skipped when the clocks go forward is scheduled when the clocks have gone forward, shifted by the gap,
+// and a time repeated when the clocks go back is scheduled once, at its first occurrence.
+func parseSchedule(effectiveCron string) (cron.Schedule, error) {
+	if !strings.HasPrefix(effectiveCron, "TZ=") {
+		return cron.Parse(effectiveCron)
+	}
+
+	i := strings.Index(effectiveCron, " ")
+	if i < 0 {
+		// Let cron report the missing spec.
+		return cron.Parse(effectiveCron)
+	}
+	loc, err := time.LoadLocation(effectiveCron[len("TZ="):i])
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the spec in UTC, which has no transitions, to evaluate it against wall clock times.
+	sch, err := cron.Parse("TZ=UTC " + strings.TrimSpace(effectiveCron[i:]))
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := sch.(*cron.SpecSchedule)
+	if !ok {
+		// A constant delay does not depend on the time zone.
+		return sch, nil
+	}
+
+	return wallClockSchedule{spec: spec, loc: loc}, nil
+}
+
+// wallClockSchedule evaluates a cron spec against the wall clock of a time zone.
+type wallClockSchedule struct {
+	spec *cron.SpecSchedule
+	loc  *time.Location
+}
+
+// Next returns the next time after t whose wall clock time in s.loc matches s.spec,
+// or the zero time if there is none.
+func (s wallClockSchedule) Next(t time.Time) time.Time {
+	w := wallClock(t.In(s.loc))
+	for {
+		w = s.spec.Next(w)
+		if w.IsZero() {
+			return w
+		}
+		// The wall clock time of the second occurrence of a repeated time is already past.
+		if next := s.fromWallClock(w); next.After(t) {
+			return next.In(t.Location())
+		}
+	}
+}
+
+// fromWallClock returns the first time whose wall clock time in s.loc is w,
+// or, if w is skipped by a transition, the time w would be had the transition not happened.
+func (s wallClockSchedule) fromWallClock(w time.Time) time.Time {
+	u := w.Unix()
+	// Transitions are at least a day apart, so the offsets a day before and after w are the ones around w.
+	_, before := time.Unix(u-24*60*60, 0).In(s.loc).Zone()
+	_, after := time.Unix(u+24*60*60, 0).In(s.loc).Zone()
+
+	first := time.Unix(u-int64(before), 0).In(s.loc)
+	second := time.Unix(u-int64(after), 0).In(s.loc)
+	if second.Before(first) {
+		first, second = second, first
+	}
+	switch {
+	case wallClock(first).Equal(w):
+		return first
+	case wallClock(second).Equal(w):
+		return second
+	default:
+		// w is in the gap of a transition, and the offset before it is the smaller one.
+		return time.Unix(u-int64(before), 0).In(s.loc)
+	}
+}
+
+// wallClock returns the wall clock time of t, as a time in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
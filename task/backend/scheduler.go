@@ -30,6 +30,18 @@ var (
 	ErrTaskAlreadyClaimed = errors.New("task already claimed")
 )
 
+const (
+	// minCreateRunBackoff and maxCreateRunBackoff bound the time the scheduler backs off from a task,
+	// after CreateNextRun failed with a retryable error. The backoff doubles at each failure in a row.
+	minCreateRunBackoff = 1 * time.Second
+	maxCreateRunBackoff = 1 * time.Minute
+
+	// finishRunAttempts is the number of times the scheduler calls FinishRun failing with a retryable error,
+	// waiting finishRunBackoff before the first retry and twice as long before each next one.
+	finishRunAttempts = 3
+	finishRunBackoff  = 100 * time.Millisecond
+)
+
 // DesiredState persists the desired state of a run.
 type DesiredState interface {
 	// CreateNextRun requests the next run from the desired state, delegating to (*StoreTaskMeta).CreateNextRun.
@@ -38,10 +50,17 @@ type DesiredState interface {
 	// and according to what's in progress and what's been finished.
 	//
 	// If a Run is requested and the cron schedule says the schedule isn't ready, a RunNotYetDueError is returned.
+	//
+	// The scheduler reacts to the kind of the other errors, as told by ErrorKindOf: it backs off from a task
+	// after a retryable error, and releases the task after a not found or permanent error.
+	// After a conflict or an unknown error, it tries again on the next tick.
 	CreateNextRun(ctx context.Context, taskID platform.ID, now int64) (RunCreation, error)
 
 	// FinishRun indicates that the given run is no longer intended to be executed.
 	// This may be called after a successful or failed execution, or upon cancellation.
+	//
+	// The scheduler retries the calls failing with a retryable error, a few times, and considers
+	// the run already finished after a not found error.
 	FinishRun(ctx context.Context, taskID, runID platform.ID) error
 }
 
@@ -70,6 +89,9 @@ type QueuedRun struct {
 	// The Unix timestamp (seconds since January 1, 1970 UTC) that will be set
 	// as the "now" option when executing the task.
 	Now int64
+
+	// The attempt number of the run, starting at 1 and incremented on every retry of the run.
+	Attempt uint32
 }
 
 // RunPromise represents an in-progress run whose result is not yet known.
@@ -130,31 +152,45 @@ type Scheduler interface {
 // TickSchedulerOption is a option you can use to modify the schedulers behavior.
 type TickSchedulerOption func(*TickScheduler)
 
-// WithTicker sets a time.Ticker with period d,
+// WithTicker sets a ticker with period d, running on the scheduler's clock until ctx is done,
 // and calls TickScheduler.Tick when the ticker rolls over to a new second.
 // With a sub-second d, TickScheduler.Tick should be called roughly no later than d after a second:
 // this can help ensure tasks happen early with a second window.
 func WithTicker(ctx context.Context, d time.Duration) TickSchedulerOption {
 	return func(s *TickScheduler) {
-		ticker := time.NewTicker(d)
-
-		go func() {
-			prev := time.Now().Unix() - 1
-			for {
-				select {
-				case t := <-ticker.C:
-					u := t.Unix()
-					if u > prev {
-						prev = u
-						go s.Tick(u)
-					}
-				case <-ctx.Done():
-					ticker.Stop()
-					return
+		s.tickerCtx = ctx
+		s.tickerPeriod = d
+	}
+}
+
+// WithClock sets the clock the scheduler tells the time with.
+// If not set, the scheduler will use the system clock.
+func WithClock(c Clock) TickSchedulerOption {
+	return func(s *TickScheduler) {
+		s.clock = c
+	}
+}
+
+// startTicker calls s.Tick when a ticker of s's clock rolls over to a new second, until ctx is done.
+func (s *TickScheduler) startTicker(ctx context.Context, d time.Duration) {
+	ticker := s.clock.NewTicker(d)
+
+	go func() {
+		prev := s.clock.Now().Unix() - 1
+		for {
+			select {
+			case t := <-ticker.C():
+				u := t.Unix()
+				if u > prev {
+					prev = u
+					go s.Tick(u)
 				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
 			}
-		}()
-	}
+		}
+	}()
 }
 
 // WithLogger sets the logger for the scheduler.
@@ -165,6 +201,30 @@ func WithLogger(logger *zap.Logger) TickSchedulerOption {
 	}
 }
 
+// RunNotifier is notified when runs succeed or fail.
+type RunNotifier interface {
+	// RunFinished is called once a run of task has succeeded or failed, with message describing why it failed.
+	// It must not block the runner.
+	RunFinished(ctx context.Context, task *StoreTask, qr QueuedRun, status RunStatus, message string)
+}
+
+// RunNotifiers notifies each of its notifiers, in order.
+type RunNotifiers []RunNotifier
+
+// RunFinished notifies each notifier that the run of task finished.
+func (ns RunNotifiers) RunFinished(ctx context.Context, task *StoreTask, qr QueuedRun, status RunStatus, message string) {
+	for _, n := range ns {
+		n.RunFinished(ctx, task, qr, status, message)
+	}
+}
+
+// WithRunNotifier sets a notifier to be called when runs succeed or fail.
+func WithRunNotifier(n RunNotifier) TickSchedulerOption {
+	return func(s *TickScheduler) {
+		s.notifier = n
+	}
+}
+
 // NewScheduler returns a new scheduler with the given desired state and the given now UTC timestamp.
 func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, now int64, opts ...TickSchedulerOption) *TickScheduler {
 	o := &TickScheduler{
@@ -176,12 +236,17 @@ func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, no
 		logger:         zap.NewNop(),
 		wg:             &sync.WaitGroup{},
 		metrics:        newSchedulerMetrics(),
+		clock:          SystemClock{},
 	}
 
 	for _, opt := range opts {
 		opt(o)
 	}
 
+	if o.tickerCtx != nil {
+		o.startTicker(o.tickerCtx, o.tickerPeriod)
+	}
+
 	return o
 }
 
@@ -192,6 +257,17 @@ type TickScheduler struct {
 
 	now    int64
 	logger *zap.Logger
+	clock  Clock
+
+	// tickerCtx is nil unless the scheduler ticks itself every tickerPeriod.
+	tickerCtx    context.Context
+	tickerPeriod time.Duration
+
+	// watchdog is nil unless stuck runs are remediated.
+	watchdog *WatchdogConfig
+
+	// notifier is nil unless finished runs are notified.
+	notifier RunNotifier
 
 	metrics *schedulerMetrics
 
@@ -244,6 +320,12 @@ func (s *TickScheduler) Tick(now int64) {
 
 	atomic.StoreInt64(&s.now, now)
 
+	if s.watchdog != nil {
+		for _, ts := range s.taskSchedulers {
+			ts.abandonStuckRuns(now)
+		}
+	}
+
 	affected := 0
 	for _, ts := range s.taskSchedulers {
 		if nextDue, hasQueue := ts.NextDue(); now >= nextDue || hasQueue {
@@ -335,7 +417,8 @@ func (s *TickScheduler) UpdateTask(task *StoreTask, meta *StoreTaskMeta) error {
 	if !ok {
 		return ErrTaskNotClaimed
 	}
-	ts.task = task
+	// Runners read the task through ts, so replacing it here reconciles every runner with the stored script.
+	ts.setTask(task)
 
 	next, err := meta.NextDueRun()
 	if err != nil {
@@ -360,7 +443,7 @@ func (s *TickScheduler) UpdateTask(task *StoreTask, meta *StoreTaskMeta) error {
 		if maxC > len(ts.runners) {
 			delta := maxC - len(ts.runners)
 			for i := 0; i < delta; i++ {
-				ts.runners = append(ts.runners, newRunner(s.ctx, ts.wg, s.logger, task, s.desiredState, s.executor, s.logWriter, ts))
+				ts.runners = append(ts.runners, newRunner(s.ctx, ts.wg, s.logger, s.desiredState, s.executor, s.logWriter, ts))
 			}
 		}
 		ts.runningMu.Unlock()
@@ -389,6 +472,23 @@ func (s *TickScheduler) ReleaseTask(taskID platform.ID) error {
 	return nil
 }
 
+// dropTask releases the task of ts, if ts still schedules it, after its runs could not be created.
+func (s *TickScheduler) dropTask(ts *taskScheduler) {
+	s.schedulerMu.Lock()
+	defer s.schedulerMu.Unlock()
+
+	taskID := ts.Task().ID
+	if s.taskSchedulers[taskID] != ts {
+		// Already released, and maybe claimed again.
+		return
+	}
+
+	ts.Cancel()
+	delete(s.taskSchedulers, taskID)
+
+	s.metrics.ReleaseTask(taskID.String())
+}
+
 func (s *TickScheduler) PrometheusCollectors() []prometheus.Collector {
 	return s.metrics.PrometheusCollectors()
 }
@@ -396,6 +496,17 @@ func (s *TickScheduler) PrometheusCollectors() []prometheus.Collector {
 type runCtx struct {
 	Context    context.Context
 	CancelFunc context.CancelFunc
+
+	// StartedAt is the scheduler time when the run started executing.
+	StartedAt int64
+
+	// Stuck receives, once, the time limit in seconds the run exceeded, if the watchdog abandons it.
+	Stuck     chan int64
+	Abandoned bool
+}
+
+func newRunCtx(ctx context.Context, cancel context.CancelFunc, startedAt int64) runCtx {
+	return runCtx{Context: ctx, CancelFunc: cancel, StartedAt: startedAt, Stuck: make(chan int64, 1)}
 }
 
 // taskScheduler is a lightweight wrapper around a collection of runners.
@@ -404,7 +515,8 @@ type taskScheduler struct {
 	now *int64
 
 	// Task we are scheduling for.
-	task *StoreTask
+	taskMu sync.RWMutex
+	task   *StoreTask
 
 	// CancelFunc for context passed to runners, to enable Cancel method.
 	cancel context.CancelFunc
@@ -416,13 +528,28 @@ type taskScheduler struct {
 	runningMu sync.Mutex
 
 	logger *zap.Logger
+	clock  Clock
 
 	metrics *schedulerMetrics
 
+	// watchdog is nil unless stuck runs are remediated.
+	watchdog *WatchdogConfig
+
+	// notifier is nil unless finished runs are notified.
+	notifier RunNotifier
+
+	durationsMu sync.Mutex // Protects following field.
+	durations   []int64    // Durations in seconds of the latest successful runs, oldest first.
+
 	nextDueMu     sync.RWMutex // Protects following fields.
 	nextDue       int64        // Unix timestamp of next due.
 	nextDueSource int64        // Run time that produced nextDue.
 	hasQueue      bool         // Whether there is a queue of manual runs.
+	retryAt       int64        // Unix timestamp before which no run is created, after a retryable error.
+	backoff       time.Duration
+
+	// drop releases the task from the scheduler, without blocking.
+	drop func()
 }
 
 func newTaskScheduler(
@@ -448,14 +575,18 @@ func newTaskScheduler(
 		running:       make(map[platform.ID]runCtx, meta.MaxConcurrency),
 		logger:        s.logger.With(zap.String("task_id", task.ID.String())),
 		metrics:       s.metrics,
+		watchdog:      s.watchdog,
+		notifier:      s.notifier,
+		clock:         s.clock,
 		nextDue:       firstDue,
 		nextDueSource: math.MinInt64,
 		hasQueue:      len(meta.ManualRuns) > 0,
 	}
+	ts.drop = func() { go s.dropTask(ts) }
 
 	for i := range ts.runners {
 		logger := ts.logger.With(zap.Int("run_slot", i))
-		ts.runners[i] = newRunner(ctx, wg, logger, task, s.desiredState, s.executor, s.logWriter, ts)
+		ts.runners[i] = newRunner(ctx, wg, logger, s.desiredState, s.executor, s.logWriter, ts)
 	}
 
 	return ts, nil
@@ -477,7 +608,7 @@ func (ts *taskScheduler) WorkCurrentlyRunning(meta *StoreTaskMeta) error {
 	for _, cr := range meta.CurrentlyRunning {
 		foundWorker := false
 		for _, r := range ts.runners {
-			qr := QueuedRun{TaskID: ts.task.ID, RunID: platform.ID(cr.RunID), Now: cr.Now}
+			qr := QueuedRun{TaskID: ts.Task().ID, RunID: platform.ID(cr.RunID), Now: cr.Now, Attempt: cr.Try}
 			if r.RestartRun(qr) {
 				foundWorker = true
 				break
@@ -492,6 +623,20 @@ func (ts *taskScheduler) WorkCurrentlyRunning(meta *StoreTaskMeta) error {
 	return nil
 }
 
+// Task returns the task being scheduled.
+func (ts *taskScheduler) Task() *StoreTask {
+	ts.taskMu.RLock()
+	defer ts.taskMu.RUnlock()
+	return ts.task
+}
+
+// setTask replaces the task being scheduled, e.g. after its script has been updated.
+func (ts *taskScheduler) setTask(task *StoreTask) {
+	ts.taskMu.Lock()
+	defer ts.taskMu.Unlock()
+	ts.task = task
+}
+
 // Cancel interrupts this taskScheduler and its runners.
 func (ts *taskScheduler) Cancel() {
 	ts.cancel()
@@ -515,6 +660,45 @@ func (ts *taskScheduler) SetNextDue(nextDue int64, hasQueue bool, source int64)
 	ts.hasQueue = hasQueue
 }
 
+// backOff delays the creation of the next run after a retryable error at now, by retryAfter at least,
+// and by twice as long as the previous backoff for the failures in a row.
+func (ts *taskScheduler) backOff(now int64, retryAfter time.Duration) time.Duration {
+	ts.nextDueMu.Lock()
+	defer ts.nextDueMu.Unlock()
+
+	switch {
+	case ts.backoff == 0:
+		ts.backoff = minCreateRunBackoff
+	case ts.backoff < maxCreateRunBackoff:
+		ts.backoff *= 2
+		if ts.backoff > maxCreateRunBackoff {
+			ts.backoff = maxCreateRunBackoff
+		}
+	}
+	d := ts.backoff
+	if retryAfter > d {
+		d = retryAfter
+	}
+	// Round up to the next second, the resolution of the scheduler.
+	ts.retryAt = now + int64((d+time.Second-1)/time.Second)
+	return d
+}
+
+// backingOff returns true if no run should be created at now, after a retryable error.
+func (ts *taskScheduler) backingOff(now int64) bool {
+	ts.nextDueMu.RLock()
+	defer ts.nextDueMu.RUnlock()
+	return now < ts.retryAt
+}
+
+// resetBackoff resets the backoff, once a run was created.
+func (ts *taskScheduler) resetBackoff() {
+	ts.nextDueMu.Lock()
+	defer ts.nextDueMu.Unlock()
+	ts.backoff = 0
+	ts.retryAt = 0
+}
+
 // A runner is one eligible "concurrency slot" for a given task.
 type runner struct {
 	state *uint32
@@ -523,8 +707,6 @@ type runner struct {
 	ctx context.Context
 	wg  *sync.WaitGroup
 
-	task *StoreTask
-
 	desiredState DesiredState
 	executor     Executor
 	logWriter    LogWriter
@@ -539,7 +721,6 @@ func newRunner(
 	ctx context.Context,
 	wg *sync.WaitGroup,
 	logger *zap.Logger,
-	task *StoreTask,
 	desiredState DesiredState,
 	executor Executor,
 	logWriter LogWriter,
@@ -549,7 +730,6 @@ func newRunner(
 		ctx:          ctx,
 		wg:           wg,
 		state:        new(uint32),
-		task:         task,
 		desiredState: desiredState,
 		executor:     executor,
 		logWriter:    logWriter,
@@ -600,11 +780,11 @@ func (r *runner) RestartRun(qr QueuedRun) bool {
 	rCtx, ok := r.ts.running[qr.RunID]
 	if !ok {
 		ctx, cancel := context.WithCancel(context.TODO())
-		rCtx = runCtx{Context: ctx, CancelFunc: cancel}
+		rCtx = newRunCtx(ctx, cancel, atomic.LoadInt64(r.ts.now))
 		r.ts.running[qr.RunID] = rCtx
 	}
 	r.ts.runningMu.Unlock()
-	go r.executeAndWait(rCtx.Context, qr, runLogger)
+	go r.executeAndWait(rCtx, qr, runLogger)
 
 	r.updateRunState(qr, RunStarted, runLogger)
 	return true
@@ -618,22 +798,28 @@ func (r *runner) startFromWorking(now int64) {
 		atomic.StoreUint32(r.state, runnerIdle)
 		return
 	}
+	if r.ts.backingOff(now) {
+		atomic.StoreUint32(r.state, runnerIdle)
+		return
+	}
 
 	span := opentracing.StartSpan("runner.startFromWorking")
 	ctx := opentracing.ContextWithSpan(r.ctx, span)
 	defer span.Finish()
 
 	ctx, cancel := context.WithCancel(ctx)
-	rc, err := r.desiredState.CreateNextRun(ctx, r.task.ID, now)
+	rc, err := r.desiredState.CreateNextRun(ctx, r.ts.Task().ID, now)
 	if err != nil {
-		r.logger.Info("Failed to create run", zap.Error(err))
+		r.createRunFailed(now, err)
 		atomic.StoreUint32(r.state, runnerIdle)
 		cancel() // cancel to prevent context leak
 		return
 	}
+	r.ts.resetBackoff()
 	qr := rc.Created
+	rCtx := newRunCtx(ctx, cancel, now)
 	r.ts.runningMu.Lock()
-	r.ts.running[qr.RunID] = runCtx{Context: ctx, CancelFunc: cancel}
+	r.ts.running[qr.RunID] = rCtx
 	r.ts.runningMu.Unlock()
 	r.ts.SetNextDue(rc.NextDue, rc.HasQueue, qr.Now)
 
@@ -644,11 +830,68 @@ func (r *runner) startFromWorking(now int64) {
 
 	runLogger.Info("Created run; beginning execution")
 	r.wg.Add(1)
-	go r.executeAndWait(ctx, qr, runLogger)
+	go r.executeAndWait(rCtx, qr, runLogger)
 
 	r.updateRunState(qr, RunStarted, runLogger)
 }
 
+// createRunFailed reacts to the failure at now of CreateNextRun with err, according to the kind of err.
+func (r *runner) createRunFailed(now int64, err error) {
+	if _, ok := err.(RunNotYetDueError); ok {
+		r.logger.Info("Failed to create run", zap.Error(err))
+		return
+	}
+
+	switch kind := ErrorKindOf(err); kind {
+	case ErrorRetryable:
+		d := r.ts.backOff(now, RetryAfter(err))
+		r.logger.Info("Failed to create run; backing off", zap.Duration("backoff", d), zap.Error(err))
+	case ErrorNotFound, ErrorPermanent:
+		// The run will never be created, there is no point in scheduling the task any longer.
+		r.logger.Error("Failed to create run; releasing task", zap.Stringer("kind", kind), zap.Error(err))
+		r.ts.drop()
+	default:
+		// Try again on the next tick.
+		r.logger.Info("Failed to create run", zap.Stringer("kind", kind), zap.Error(err))
+	}
+}
+
+// finishRun finishes the run qr in the desired state, retrying the calls failing with a retryable error.
+// A run not found was already finished, and is not an error.
+func (r *runner) finishRun(qr QueuedRun, runLogger *zap.Logger) error {
+	backoff := finishRunBackoff
+	for attempt := 1; ; attempt++ {
+		err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
+		if err == nil {
+			return nil
+		}
+
+		switch ErrorKindOf(err) {
+		case ErrorNotFound:
+			runLogger.Info("Run to finish was not found; it already finished", zap.Error(err))
+			return nil
+		case ErrorRetryable:
+			if attempt >= finishRunAttempts {
+				return err
+			}
+		default:
+			return err
+		}
+
+		d := backoff
+		if after := RetryAfter(err); after > d {
+			d = after
+		}
+		runLogger.Info("Failed to finish run; retrying", zap.Int("attempt", attempt), zap.Duration("backoff", d), zap.Error(err))
+		select {
+		case <-r.ts.clock.After(d):
+		case <-r.ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
 func (r *runner) clearRunning(id platform.ID) {
 	r.ts.runningMu.Lock()
 	r.ts.running[id].CancelFunc() // cleanup
@@ -659,29 +902,40 @@ func (r *runner) clearRunning(id platform.ID) {
 // fail sets r's state to failed, and marks this runner as idle.
 func (r *runner) fail(qr QueuedRun, runLogger *zap.Logger, stage string, reason error) {
 	rlb := RunLogBase{
-		Task:            r.task,
+		Task:            r.ts.Task(),
 		RunID:           qr.RunID,
 		RunScheduledFor: qr.Now,
 		RequestedAt:     qr.RequestedAt,
+		Attempt:         qr.Attempt,
 	}
-	if err := r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), stage+": "+reason.Error()); err != nil {
+	if err := r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), stage+": "+reason.Error()); err != nil {
 		runLogger.Info("Failed to update run log", zap.Error(err))
 	}
 
 	r.updateRunState(qr, RunFail, runLogger)
+	r.notify(qr, RunFail, stage+": "+reason.Error())
 	atomic.StoreUint32(r.state, runnerIdle)
 }
 
-func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *zap.Logger) {
+// notify notifies the notifier of the scheduler, if any, that the run finished.
+func (r *runner) notify(qr QueuedRun, s RunStatus, message string) {
+	if r.ts.notifier == nil {
+		return
+	}
+	r.ts.notifier.RunFinished(r.ctx, r.ts.Task(), qr, s, message)
+}
+
+func (r *runner) executeAndWait(rCtx runCtx, qr QueuedRun, runLogger *zap.Logger) {
 	defer r.wg.Done()
 
+	ctx := rCtx.Context
 	sp, spCtx := tracing.StartSpanFromContext(ctx)
 	defer sp.Finish()
 
 	rp, err := r.executor.Execute(spCtx, qr)
 	if err != nil {
 		runLogger.Info("Failed to begin run execution", zap.Error(err))
-		if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
+		if err := r.finishRun(qr, runLogger); err != nil {
 			// TODO(mr): Need to figure out how to reconcile this error, on the next run, if it happens.
 			runLogger.Error("Beginning run execution failed, and desired state update failed", zap.Error(err))
 		}
@@ -708,12 +962,31 @@ func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *za
 		}
 	}()
 
+	// Wait in the background, so that the run can be abandoned if it is stuck.
+	type waitResult struct {
+		rr  RunResult
+		err error
+	}
+	waited := make(chan waitResult, 1)
+	go func() {
+		rr, err := rp.Wait()
+		waited <- waitResult{rr: rr, err: err}
+	}()
+
 	// TODO(mr): handle rr.IsRetryable().
-	rr, err := rp.Wait()
-	close(ready)
+	var rr RunResult
+	select {
+	case res := <-waited:
+		rr, err = res.rr, res.err
+		close(ready)
+	case limit := <-rCtx.Stuck:
+		close(ready)
+		r.abandon(qr, rp, runLogger, limit)
+		return
+	}
 	if err != nil {
 		if err == ErrRunCanceled {
-			_ = r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
+			_ = r.finishRun(qr, runLogger)
 			r.updateRunState(qr, RunCanceled, runLogger)
 
 			// Move on to the next execution, for a canceled run.
@@ -722,7 +995,7 @@ func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *za
 		}
 
 		runLogger.Info("Failed to wait for execution result", zap.Error(err))
-		if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
+		if err := r.finishRun(qr, runLogger); err != nil {
 			// TODO(mr): Need to figure out how to reconcile this error, on the next run, if it happens.
 			runLogger.Error("Waiting for execution result failed, and desired state update failed", zap.Error(err))
 		}
@@ -733,7 +1006,7 @@ func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *za
 	}
 	if err := rr.Err(); err != nil {
 		runLogger.Info("Run failed to execute", zap.Error(err))
-		if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
+		if err := r.finishRun(qr, runLogger); err != nil {
 			// TODO(mr): Need to figure out how to reconcile this error, on the next run, if it happens.
 			runLogger.Error("Run failed to execute, and desired state update failed", zap.Error(err))
 		}
@@ -742,54 +1015,59 @@ func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *za
 		return
 	}
 
-	if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
+	if err := r.finishRun(qr, runLogger); err != nil {
 		runLogger.Info("Failed to finish run", zap.Error(err))
-		// TODO(mr): retry?
 		// Need to think about what it means if there was an error finishing a run.
 		atomic.StoreUint32(r.state, runnerIdle)
 		r.updateRunState(qr, RunFail, runLogger)
+		r.notify(qr, RunFail, "Failed to finish run: "+err.Error())
 		return
 	}
 	rlb := RunLogBase{
-		Task:            r.task,
+		Task:            r.ts.Task(),
 		RunID:           qr.RunID,
 		RunScheduledFor: qr.Now,
 		RequestedAt:     qr.RequestedAt,
+		Attempt:         qr.Attempt,
 	}
 	stats := rr.Statistics()
 
 	b, err := json.Marshal(stats)
 	if err == nil {
-		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), string(b))
+		r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), string(b))
 	}
 	r.updateRunState(qr, RunSuccess, runLogger)
+	r.notify(qr, RunSuccess, "")
 	runLogger.Info("Execution succeeded")
+	r.ts.recordDuration(atomic.LoadInt64(r.ts.now) - rCtx.StartedAt)
 
 	// Check again if there is a new run available, without returning to idle state.
 	r.startFromWorking(atomic.LoadInt64(r.ts.now))
 }
 
 func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger) {
+	task := r.ts.Task()
 	rlb := RunLogBase{
-		Task:            r.task,
+		Task:            task,
 		RunID:           qr.RunID,
 		RunScheduledFor: qr.Now,
 		RequestedAt:     qr.RequestedAt,
+		Attempt:         qr.Attempt,
 	}
 
 	switch s {
 	case RunStarted:
-		r.ts.metrics.StartRun(r.task.ID.String())
-		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), fmt.Sprintf("Started task from script: %q", r.task.Script))
+		r.ts.metrics.StartRun(task.ID.String())
+		r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), fmt.Sprintf("Started task from script: %q", task.Script))
 	case RunSuccess:
-		r.ts.metrics.FinishRun(r.task.ID.String(), true)
-		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Completed successfully")
+		r.ts.metrics.FinishRun(task.ID.String(), true)
+		r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), "Completed successfully")
 	case RunFail:
-		r.ts.metrics.FinishRun(r.task.ID.String(), false)
-		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Failed")
+		r.ts.metrics.FinishRun(task.ID.String(), false)
+		r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), "Failed")
 	case RunCanceled:
-		r.ts.metrics.FinishRun(r.task.ID.String(), false)
-		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Canceled")
+		r.ts.metrics.FinishRun(task.ID.String(), false)
+		r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), "Canceled")
 	default: // We are deliberately not handling RunQueued yet.
 		// There is not really a notion of being queued in this runner architecture.
 		runLogger.Warn("Unhandled run state", zap.Stringer("state", s))
@@ -799,7 +1077,7 @@ func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger
 	// If we start seeing errors from this, we know the time limit is too short or the system is overloaded.
 	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Millisecond)
 	defer cancel()
-	if err := r.logWriter.UpdateRunState(ctx, rlb, time.Now(), s); err != nil {
+	if err := r.logWriter.UpdateRunState(ctx, rlb, r.ts.clock.Now(), s); err != nil {
 		runLogger.Info("Error updating run state", zap.Stringer("state", s), zap.Error(err))
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,12 +24,14 @@ import (
 func TestScheduler_Cancelation(t *testing.T) {
 	t.Parallel()
 
+	clock := mock.NewClock(time.Unix(5, 0))
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
+	e.WithClock(clock)
 	e.WithHanging(100 * time.Millisecond)
 	rl := backend.NewInMemRunReaderWriter()
 
-	o := backend.NewScheduler(d, e, rl, 5, backend.WithLogger(zaptest.NewLogger(t)))
+	o := backend.NewScheduler(d, e, rl, 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithClock(clock))
 	o.Start(context.Background())
 	defer o.Stop()
 
@@ -62,7 +65,8 @@ func TestScheduler_Cancelation(t *testing.T) {
 		t.Fatalf("Run not logged as canceled, but is %s", runs[0].Status)
 	}
 	// check to make sure it is really canceling, and that the status doesn't get changed to something else after it would have finished
-	time.Sleep(500 * time.Millisecond)
+	clock.Add(time.Second)
+	time.Sleep(10 * time.Millisecond)
 	runs, err = rl.ListRuns(context.Background(), orgID, platform.RunFilter{Task: task.ID})
 	if err != nil {
 		t.Fatal(err)
@@ -82,7 +86,8 @@ func TestScheduler_StartScriptOnClaim(t *testing.T) {
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
 	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithLogger(zaptest.NewLogger(t)))
-	o.Start(context.Background())
+	ctx := context.Background()
+	o.Start(ctx)
 	defer o.Stop()
 
 	task := &backend.StoreTask{
@@ -127,7 +132,7 @@ func TestScheduler_StartScriptOnClaim(t *testing.T) {
 		t.Fatalf("expected 2 runs queued for 'every 1s' script, but got %d", n)
 	}
 
-	if x, err := d.PollForNumberCreated(task.ID, 1); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 1); err != nil {
 		t.Fatalf("expected 1 runs queued, but got %d", len(x))
 	}
 
@@ -143,7 +148,7 @@ func TestScheduler_StartScriptOnClaim(t *testing.T) {
 		rp.Finish(mock.NewRunResult(nil, false), nil)
 	}
 
-	if x, err := d.PollForNumberCreated(task.ID, 0); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 0); err != nil {
 		t.Fatalf("expected 1 runs queued, but got %d", len(x))
 	}
 
@@ -158,7 +163,8 @@ func TestScheduler_CreateNextRunOnTick(t *testing.T) {
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
 	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5)
-	o.Start(context.Background())
+	ctx := context.Background()
+	o.Start(ctx)
 	defer o.Stop()
 
 	task := &backend.StoreTask{
@@ -175,12 +181,12 @@ func TestScheduler_CreateNextRunOnTick(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if x, err := d.PollForNumberCreated(task.ID, 0); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 0); err != nil {
 		t.Fatalf("expected no runs queued, but got %d", len(x))
 	}
 
 	o.Tick(6)
-	if x, err := d.PollForNumberCreated(task.ID, 1); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 1); err != nil {
 		t.Fatalf("expected 1 run queued, but got %d", len(x))
 	}
 	running, err := e.PollForNumberRunning(task.ID, 1)
@@ -193,7 +199,7 @@ func TestScheduler_CreateNextRunOnTick(t *testing.T) {
 	}
 
 	o.Tick(7)
-	if x, err := d.PollForNumberCreated(task.ID, 2); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 2); err != nil {
 		t.Fatalf("expected 2 runs queued, but got %d", len(x))
 	}
 	running, err = e.PollForNumberRunning(task.ID, 2)
@@ -212,7 +218,7 @@ func TestScheduler_CreateNextRunOnTick(t *testing.T) {
 	}
 
 	o.Tick(8) // Can't exceed concurrency of 2.
-	if x, err := d.PollForNumberCreated(task.ID, 2); err != nil {
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 2); err != nil {
 		t.Fatalf("expected 2 runs queued, but got %d", len(x))
 	}
 	run6.Cancel() // 7 and 8 should be running.
@@ -302,7 +308,8 @@ func TestScheduler_Release(t *testing.T) {
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
 	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5)
-	o.Start(context.Background())
+	ctx := context.Background()
+	o.Start(ctx)
 	defer o.Stop()
 
 	task := &backend.StoreTask{
@@ -329,7 +336,7 @@ func TestScheduler_Release(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, err := d.PollForNumberCreated(task.ID, 0); err != nil {
+	if _, err := d.PollForNumberCreated(ctx, task.ID, 0); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -387,13 +394,86 @@ func TestScheduler_UpdateTask(t *testing.T) {
 	p[0].Finish(mock.NewRunResult(nil, false), nil)
 }
 
+func TestScheduler_UpdateTaskScript(t *testing.T) {
+	t.Parallel()
+
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	lw := &scriptRecordingLogWriter{}
+	s := backend.NewScheduler(d, e, lw, 5, backend.WithLogger(zaptest.NewLogger(t)))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID:     platform.ID(1),
+		Script: "old script",
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+
+	d.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the task, as the coordinator does after the store has been updated.
+	if err := s.UpdateTask(&backend.StoreTask{ID: task.ID, Script: "new script"}, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(6)
+	p, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts := lw.Scripts()
+	if len(scripts) == 0 {
+		t.Fatal("expected run state to be written")
+	}
+	for _, script := range scripts {
+		if script != "new script" {
+			t.Fatalf("run used the task's stale script %q", script)
+		}
+	}
+}
+
+// scriptRecordingLogWriter is a LogWriter that records the script of the task for every run state update.
+type scriptRecordingLogWriter struct {
+	backend.NopLogWriter
+
+	mu      sync.Mutex
+	scripts []string
+}
+
+func (w *scriptRecordingLogWriter) UpdateRunState(_ context.Context, base backend.RunLogBase, _ time.Time, _ backend.RunStatus) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.scripts = append(w.scripts, base.Task.Script)
+	return nil
+}
+
+func (w *scriptRecordingLogWriter) Scripts() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.scripts...)
+}
+
 func TestScheduler_Queue(t *testing.T) {
 	t.Parallel()
 
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
 	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 3059, backend.WithLogger(zaptest.NewLogger(t)))
-	o.Start(context.Background())
+	ctx := context.Background()
+	o.Start(ctx)
 	defer o.Stop()
 
 	task := &backend.StoreTask{
@@ -413,7 +493,7 @@ func TestScheduler_Queue(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cs, err := d.PollForNumberCreated(task.ID, 1)
+	cs, err := d.PollForNumberCreated(ctx, task.ID, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -471,6 +551,57 @@ func TestScheduler_Queue(t *testing.T) {
 	}
 }
 
+func TestScheduler_ManualRunsPerTask(t *testing.T) {
+	t.Parallel()
+
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 3059, backend.WithLogger(zaptest.NewLogger(t)))
+	ctx := context.Background()
+	o.Start(ctx)
+	defer o.Stop()
+
+	// Each task has its own queue of manual runs, which must not leak into the other task.
+	queues := map[platform.ID]int64{1: 120, 2: 600}
+	metas := make(map[platform.ID]*backend.StoreTaskMeta, len(queues))
+	for id, start := range queues {
+		meta := &backend.StoreTaskMeta{
+			MaxConcurrency:  1,
+			EffectiveCron:   "* * * * *", // Every minute.
+			LatestCompleted: 3000,
+		}
+		d.SetTaskMeta(id, *meta)
+
+		meta.ManualRuns = []*backend.StoreTaskMetaManualRun{
+			{Start: start, End: start, LatestCompleted: start - 1, RequestedAt: 3001},
+		}
+		d.SetManualRuns(id, meta.ManualRuns)
+		metas[id] = meta
+	}
+
+	var wg sync.WaitGroup
+	for id, start := range queues {
+		wg.Add(1)
+		go func(id platform.ID, start int64) {
+			defer wg.Done()
+
+			if err := o.ClaimTask(&backend.StoreTask{ID: id}, metas[id]); err != nil {
+				t.Error(err)
+				return
+			}
+			cs, err := d.PollForNumberCreated(ctx, id, 1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if cs[0].Now != start {
+				t.Errorf("task %s: expected run from queue at %d, got %d", id, start, cs[0].Now)
+			}
+		}(id, start)
+	}
+	wg.Wait()
+}
+
 func pollForRunLog(t *testing.T, r backend.LogReader, taskID, runID, orgID platform.ID, exp string) {
 	t.Helper()
 
@@ -726,6 +857,337 @@ func TestScheduler_RunFailureCleanup(t *testing.T) {
 	}
 }
 
+// retrier records the calls to RetryRun.
+type retrier struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *retrier) RetryRun(_ context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*backend.StoreTaskMetaManualRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf("%s:%d#%d", taskID, now, attempt))
+	return &backend.StoreTaskMetaManualRun{Start: now, End: now, RequestedAt: requestedAt, Try: attempt}, nil
+}
+
+func (r *retrier) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func TestScheduler_Watchdog(t *testing.T) {
+	t.Parallel()
+
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	rl := backend.NewInMemRunReaderWriter()
+	rt := &retrier{}
+	s := backend.NewScheduler(d, e, rl, 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithWatchdog(backend.WatchdogConfig{
+		DurationFactor: 3,
+		MinDuration:    5 * time.Second,
+		Retrier:        rt,
+	}))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID:  platform.ID(1),
+		Org: 2,
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 10s",
+		LatestCompleted: 10,
+	}
+	d.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// A first run takes 2 seconds, so the task typically takes 2 seconds.
+	s.Tick(20)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Tick(22)
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	pollForRunStatus(t, rl, task.ID, task.Org, 1, 0, backend.RunSuccess.String())
+
+	// The next run is stuck after 3 times the typical duration, as it is more than the minimum duration.
+	s.Tick(30)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuck := promises[0].Run()
+	s.Tick(36)
+	pollForRunStatus(t, rl, task.ID, task.Org, 2, 1, backend.RunStarted.String())
+
+	s.Tick(37)
+	pollForRunStatus(t, rl, task.ID, task.Org, 2, 1, backend.RunFail.String())
+	pollForRunLog(t, rl, task.ID, stuck.RunID, task.Org, "Watchdog: run did not complete within 6s, force-finished as failed")
+	pollForRunLog(t, rl, task.ID, stuck.RunID, task.Org, "Watchdog: scheduled a retry of the run")
+	if got, want := rt.Calls(), []string{fmt.Sprintf("%s:30#2", task.ID)}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected retries %v, got %v", want, got)
+	}
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stuck run no longer holds the only concurrency slot of the task.
+	s.Tick(40)
+	if _, err := e.PollForNumberRunning(task.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type runNotifier struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (n *runNotifier) RunFinished(_ context.Context, task *backend.StoreTask, qr backend.QueuedRun, status backend.RunStatus, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls = append(n.calls, fmt.Sprintf("%s:%d:%s:%s", task.ID, qr.Now, status, message))
+}
+
+func (n *runNotifier) Calls() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.calls...)
+}
+
+func TestScheduler_RunNotifier(t *testing.T) {
+	t.Parallel()
+
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	n := &runNotifier{}
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithRunNotifier(n))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID:  platform.ID(1),
+		Org: 2,
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+	d.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(errors.New("boom"), false), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		fmt.Sprintf("%s:6:success:", task.ID),
+		fmt.Sprintf("%s:7:failed:Run failed to execute: boom", task.ID),
+	}
+	for i := 0; i < 100; i++ {
+		if len(n.Calls()) >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := n.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected notifications %v, got %v", want, got)
+	}
+}
+
+func TestRunNotifiers(t *testing.T) {
+	n1, n2 := &runNotifier{}, &runNotifier{}
+	ns := backend.RunNotifiers{n1, n2}
+	ns.RunFinished(context.Background(), &backend.StoreTask{ID: 1}, backend.QueuedRun{Now: 6}, backend.RunSuccess, "")
+
+	want := []string{fmt.Sprintf("%s:6:success:", platform.ID(1))}
+	for _, n := range []*runNotifier{n1, n2} {
+		if got := n.Calls(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected notifications %v, got %v", want, got)
+		}
+	}
+}
+
+func TestScheduler_TaskControlFailures(t *testing.T) {
+	t.Parallel()
+
+	tcs := mock.NewTaskControlService(mock.NewDesiredState())
+	e := mock.NewExecutor()
+	n := &runNotifier{}
+	s := backend.NewScheduler(tcs.AsDesiredState(), e, tcs.AsLogWriter(), 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithRunNotifier(n))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID:  platform.ID(1),
+		Org: 2,
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  2,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+	tcs.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForCalls := func(want []string) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if len(n.Calls()) >= len(want) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := n.Calls(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected notifications %v, got %v", want, got)
+		}
+	}
+
+	// A run that executes successfully but cannot be finished is failed.
+	tcs.FailTask(mock.FinishRunMethod, task.ID, errors.New("boom"))
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failedRun := promises[0].Run()
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	waitForCalls([]string{
+		fmt.Sprintf("%s:6:failed:Failed to finish run: boom", task.ID),
+	})
+	if states := tcs.RunStates(task.ID, failedRun.RunID); !containsRunStatus(states, backend.RunFail) {
+		t.Fatalf("expected run to be recorded as failed, got states %v", states)
+	}
+
+	// Once the failure is cleared, runs finish again.
+	tcs.FailTask(mock.FinishRunMethod, task.ID, nil)
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	waitForCalls([]string{
+		fmt.Sprintf("%s:6:failed:Failed to finish run: boom", task.ID),
+		fmt.Sprintf("%s:7:success:", task.ID),
+	})
+}
+
+func TestScheduler_TaskControlErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	tcs := mock.NewTaskControlService(mock.NewDesiredState())
+	e := mock.NewExecutor()
+	n := &runNotifier{}
+	s := backend.NewScheduler(tcs.AsDesiredState(), e, tcs.AsLogWriter(), 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithRunNotifier(n))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID:  platform.ID(1),
+		Org: 2,
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+	tcs.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a retryable error, no run is created until the scheduler backs off.
+	tcs.FailTask(mock.CreateNextRunMethod, task.ID, backend.RetryableError(errors.New("unavailable"), 5*time.Second))
+	s.Tick(6)
+	tcs.FailTask(mock.CreateNextRunMethod, task.ID, nil)
+	s.Tick(7)
+	if got := tcs.TotalRunsCreatedForTask(task.ID); got != 0 {
+		t.Fatalf("expected no run created while backing off, got %d", got)
+	}
+	s.Tick(11)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A retryable error finishing a run is retried: the second call fails, and the third one succeeds.
+	tcs.FailEveryNth(mock.FinishRunMethod, 2, backend.RetryableError(errors.New("unavailable"), 0))
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	waitForNotifications(t, n, []string{fmt.Sprintf("%s:6:success:", task.ID)})
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After a not found error creating the next run, the task is released.
+	tcs.FailTask(mock.CreateNextRunMethod, task.ID, backend.ErrTaskNotFound)
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	waitForNotifications(t, n, []string{
+		fmt.Sprintf("%s:6:success:", task.ID),
+		fmt.Sprintf("%s:7:success:", task.ID),
+	})
+	for i := 0; i < 100; i++ {
+		// The scheduler only knows the runs of the tasks it claimed.
+		if err := s.CancelRun(context.Background(), task.ID, platform.ID(1000)); err == backend.ErrTaskNotFound {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected task to be released after a not found error")
+}
+
+func waitForNotifications(t *testing.T, n *runNotifier, want []string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if len(n.Calls()) >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := n.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected notifications %v, got %v", want, got)
+	}
+}
+
+func containsRunStatus(states []backend.RunStatus, s backend.RunStatus) bool {
+	for _, st := range states {
+		if st == s {
+			return true
+		}
+	}
+	return false
+}
+
 func TestScheduler_Metrics(t *testing.T) {
 	t.Parallel()
 
@@ -903,10 +1365,11 @@ func TestScheduler_WithTicker(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tickFreq := 100 * time.Millisecond
+	clock := mock.NewClock(time.Unix(5, 0))
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
-	o := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithTicker(ctx, tickFreq))
+	lw := &timeRecordingLogWriter{}
+	o := backend.NewScheduler(d, e, lw, 5, backend.WithLogger(zaptest.NewLogger(t)), backend.WithClock(clock), backend.WithTicker(ctx, 100*time.Millisecond))
 
 	o.Start(ctx)
 	defer o.Stop()
@@ -914,11 +1377,10 @@ func TestScheduler_WithTicker(t *testing.T) {
 	task := &backend.StoreTask{
 		ID: platform.ID(1),
 	}
-	createdAt := time.Now().Unix()
 	meta := &backend.StoreTaskMeta{
 		MaxConcurrency:  5,
 		EffectiveCron:   "@every 1s",
-		LatestCompleted: createdAt,
+		LatestCompleted: 5,
 	}
 
 	d.SetTaskMeta(task.ID, *meta)
@@ -926,11 +1388,47 @@ func TestScheduler_WithTicker(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	for time.Now().Unix() == createdAt {
-		time.Sleep(tickFreq + 10*time.Millisecond)
+	// Ticks within the current second do not create a run.
+	// There is no run to wait for, so give the ticker a moment to handle the tick;
+	// otherwise the next tick could be dropped while this one is still pending.
+	clock.Add(500 * time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 0); err != nil {
+		t.Fatalf("expected no run queued, but got %d", len(x))
 	}
 
-	if x, err := d.PollForNumberCreated(task.ID, 1); err != nil {
+	clock.Add(500 * time.Millisecond)
+	if x, err := d.PollForNumberCreated(ctx, task.ID, 1); err != nil {
 		t.Fatalf("expected 1 run queued, but got %d", len(x))
 	}
+
+	for i := 0; i < 100 && len(lw.Times()) == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	for _, when := range lw.Times() {
+		if !when.Equal(time.Unix(6, 0)) {
+			t.Fatalf("expected run state to be updated at the virtual time, got %v", when)
+		}
+	}
+}
+
+// timeRecordingLogWriter is a LogWriter that records the time of every run state update.
+type timeRecordingLogWriter struct {
+	backend.NopLogWriter
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (w *timeRecordingLogWriter) UpdateRunState(_ context.Context, _ backend.RunLogBase, when time.Time, _ backend.RunStatus) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.times = append(w.times, when)
+	return nil
+}
+
+func (w *timeRecordingLogWriter) Times() []time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]time.Time(nil), w.times...)
 }
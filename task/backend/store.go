@@ -83,6 +83,43 @@ func (r RunStatus) String() string {
 	panic(fmt.Sprintf("unknown RunStatus: %d", r))
 }
 
+// ParseRunStatus returns the RunStatus whose String is s.
+func ParseRunStatus(s string) (RunStatus, error) {
+	for _, r := range []RunStatus{RunStarted, RunSuccess, RunFail, RunCanceled, RunScheduled} {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid run status: %q", s)
+}
+
+// finished returns true if r is the state of a run that finished.
+func (r RunStatus) finished() bool {
+	return r == RunSuccess || r == RunFail || r == RunCanceled
+}
+
+// InvalidRunTransitionError is returned when updating a run to a state it cannot reach from its current state.
+type InvalidRunTransitionError struct {
+	From, To RunStatus
+}
+
+func (e InvalidRunTransitionError) Error() string {
+	return fmt.Sprintf("run cannot go from %s to %s", e.From, e.To)
+}
+
+// ValidateRunTransition returns an InvalidRunTransitionError if a run in state from cannot go to state to.
+// A created run is scheduled. A scheduled run starts, or finishes without starting if it fails or is canceled.
+// A started run finishes, and a finished run never changes state again.
+func ValidateRunTransition(from, to RunStatus) error {
+	switch {
+	case from == RunScheduled && (to == RunStarted || to == RunFail || to == RunCanceled):
+		return nil
+	case from == RunStarted && to.finished():
+		return nil
+	}
+	return InvalidRunTransitionError{From: from, To: to}
+}
+
 // RunNotYetDueError is returned from CreateNextRun if a run is not yet due.
 type RunNotYetDueError struct {
 	// DueAt is the unix timestamp of when the next run is due.
@@ -163,6 +200,9 @@ type CreateTaskRequest struct {
 	// The initial task status.
 	// If empty, will be treated as DefaultTaskStatus.
 	Status TaskStatus
+
+	// Params injected into the script when the task runs.
+	Params map[string]string
 }
 
 // UpdateTaskRequest encapsulates requested changes to a task.
@@ -182,6 +222,14 @@ type UpdateTaskRequest struct {
 	// If zero, do not modify the existing authorization ID.
 	AuthorizationID platform.ID
 
+	// The organization the task is moved to.
+	// If zero, do not modify the existing organization.
+	Org platform.ID
+
+	// The new params of the task.
+	// If nil, do not modify the existing params; if empty, remove them.
+	Params map[string]string
+
 	// These options are for editing options via request.  Zeroed options will be ignored.
 	options.Options
 }
@@ -240,6 +288,11 @@ type Store interface {
 	// FindTaskByIDWithMeta combines finding the task and the meta into a single call.
 	FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*StoreTask, *StoreTaskMeta, error)
 
+	// ListTaskVersions returns the previous revisions of the task with the given ID, oldest first.
+	// A revision is recorded each time UpdateTask replaces the task's script.
+	// If no task matches the ID, ErrTaskNotFound is returned.
+	ListTaskVersions(ctx context.Context, id platform.ID) ([]StoreTaskVersion, error)
+
 	// DeleteTask returns whether an entry matching the given ID was deleted.
 	// If err is non-nil, deleted is false.
 	// If err is nil, deleted is false if no entry matched the ID,
@@ -258,6 +311,11 @@ type Store interface {
 	// ManuallyRunTimeRange must delegate to an underlying StoreTaskMeta's ManuallyRunTimeRange method.
 	ManuallyRunTimeRange(ctx context.Context, taskID platform.ID, start, end, requestedAt int64) (*StoreTaskMetaManualRun, error)
 
+	// RetryRun enqueues a request to run the task with the given ID again for the schedule now (a Unix timestamp),
+	// as the given attempt of that run. requestedAt is the Unix timestamp indicating when the retry was requested.
+	// RetryRun must delegate to an underlying StoreTaskMeta's RetryRun method.
+	RetryRun(ctx context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*StoreTaskMetaManualRun, error)
+
 	// DeleteOrg deletes the org.
 	DeleteOrg(ctx context.Context, orgID platform.ID) error
 
@@ -278,6 +336,9 @@ type RunLogBase struct {
 
 	// When the log is requested, should be ignored when it is zero.
 	RequestedAt int64
+
+	// The attempt number of the run, should be ignored when it is zero.
+	Attempt uint32
 }
 
 // LogWriter writes task logs and task state changes to a store.
@@ -362,6 +423,22 @@ type StoreTask struct {
 
 	// The script content of the task.
 	Script string
+
+	// Params injected into the script when the task runs.
+	Params map[string]string
+}
+
+// StoreTaskVersion is a previous revision of a task's script.
+// The task's options are part of its script, so the script alone is enough to restore a revision.
+type StoreTaskVersion struct {
+	// Sequence number of the revision; the script the task was created with is version 1.
+	Version int64 `json:"version"`
+
+	// The script content of the revision.
+	Script string `json:"script"`
+
+	// Unix timestamp of when the revision was replaced.
+	ReplacedAt int64 `json:"replacedAt"`
 }
 
 // StoreTaskWithMeta is a single struct with a StoreTask and a StoreTaskMeta.
@@ -408,17 +485,21 @@ func (StoreValidation) CreateArgs(req CreateTaskRequest) (options.Options, error
 		return o, err
 	}
 
+	if err := platform.ValidateTaskParams(req.Params); err != nil {
+		return o, err
+	}
+
 	return o, nil
 }
 
 // UpdateArgs validates the UpdateTaskRequest.
 // If the update only includes a new status (i.e. req.Script is empty), the returned options are zero.
-// If the update contains neither a new script nor a new status, or if the script is invalid, an error is returned.
+// If the update contains nothing to change, or if the script is invalid, an error is returned.
 func (StoreValidation) UpdateArgs(req UpdateTaskRequest) (options.Options, error) {
 	var missing []string
 	o := req.Options
-	if req.Script == "" && req.Status == "" && req.Options.IsZero() && !req.AuthorizationID.Valid() {
-		missing = append(missing, "script or status or options or authorizationID")
+	if req.Script == "" && req.Status == "" && req.Options.IsZero() && !req.AuthorizationID.Valid() && !req.Org.Valid() && req.Params == nil {
+		missing = append(missing, "script or status or options or authorizationID or org or params")
 	}
 
 	if req.Script != "" {
@@ -435,6 +516,9 @@ func (StoreValidation) UpdateArgs(req UpdateTaskRequest) (options.Options, error
 	if err := req.Status.validate(true); err != nil {
 		return o, err
 	}
+	if err := platform.ValidateTaskParams(req.Params); err != nil {
+		return o, err
+	}
 
 	if !req.ID.Valid() {
 		missing = append(missing, "task ID")
@@ -52,6 +52,10 @@ func NewRunStoreTest(name string, crf CreateRunStoreFunc, drf DestroyRunStoreFun
 				t.Parallel()
 				listLogsTest(t, crf, drf)
 			})
+			t.Run("RunAttempt", func(t *testing.T) {
+				t.Parallel()
+				runAttemptTest(t, crf, drf)
+			})
 		})
 	}
 }
@@ -337,6 +341,88 @@ func listRunsTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc)
 	if len(listRuns) != len(runs) {
 		t.Fatalf("retrieved: %d, expected: %d", len(listRuns), len(runs))
 	}
+
+	// Fail a handful of runs and filter by status, alone and combined with a time range.
+	const nFailed = 10
+	for i := 0; i < nFailed; i++ {
+		scheduledFor, _ := time.Parse(time.RFC3339, runs[i].ScheduledFor)
+		rlb := backend.RunLogBase{
+			Task:            task,
+			RunID:           runs[i].ID,
+			RunScheduledFor: scheduledFor.Unix(),
+		}
+		if err := writer.UpdateRunState(ctx, rlb, scheduledFor.Add(2*time.Second), backend.RunFail); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Second)
+
+	listRuns, err = reader.ListRuns(ctx, task.Org, platform.RunFilter{
+		Task:   task.ID,
+		Status: backend.RunFail.String(),
+		Limit:  2 * nRuns,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listRuns) != nFailed {
+		t.Fatalf("retrieved: %d failed runs, expected: %d", len(listRuns), nFailed)
+	}
+	for _, r := range listRuns {
+		if r.Status != backend.RunFail.String() {
+			t.Fatalf("expected only failed runs, got run %s with status %q", r.ID, r.Status)
+		}
+	}
+
+	const failedAfterTimeIdx = 4
+	scheduledFor, _ = time.Parse(time.RFC3339, runs[failedAfterTimeIdx].ScheduledFor)
+	listRuns, err = reader.ListRuns(ctx, task.Org, platform.RunFilter{
+		Task:      task.ID,
+		Status:    backend.RunFail.String(),
+		AfterTime: scheduledFor.Format(time.RFC3339),
+		Limit:     2 * nRuns,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listRuns) != nFailed-(failedAfterTimeIdx+1) {
+		t.Fatalf("retrieved: %d failed runs, expected: %d", len(listRuns), nFailed-(failedAfterTimeIdx+1))
+	}
+
+	listRuns, err = reader.ListRuns(ctx, task.Org, platform.RunFilter{
+		Task:   task.ID,
+		Status: backend.RunFail.String(),
+		Limit:  3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listRuns) != 3 {
+		t.Fatalf("retrieved: %d failed runs, expected: %d", len(listRuns), 3)
+	}
+	for i, r := range listRuns {
+		if r.ID != runs[i].ID {
+			t.Fatalf("expected the first failed runs, got run %s at %d instead of %s", r.ID, i, runs[i].ID)
+		}
+	}
+
+	// The failed runs were started too, but their status is the last one they had.
+	listRuns, err = reader.ListRuns(ctx, task.Org, platform.RunFilter{
+		Task:   task.ID,
+		Status: backend.RunStarted.String(),
+		Limit:  2 * nRuns,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listRuns) != len(runs)-nFailed {
+		t.Fatalf("retrieved: %d started runs, expected: %d", len(listRuns), len(runs)-nFailed)
+	}
+	for _, r := range listRuns {
+		if r.Status != backend.RunStarted.String() {
+			t.Fatalf("expected only started runs, got run %s with status %q", r.ID, r.Status)
+		}
+	}
 }
 
 func findRunByIDTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc) {
@@ -399,6 +485,63 @@ func findRunByIDTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFu
 	}
 }
 
+func runAttemptTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc) {
+	writer, reader, makeAuthz := crf(t)
+	defer drf(t, writer, reader)
+
+	task := &backend.StoreTask{
+		ID:  platformtesting.MustIDBase16("ab01ab01ab01ab01"),
+		Org: platformtesting.MustIDBase16("ab01ab01ab01ab05"),
+	}
+	sf := time.Now().UTC().Add(-10 * time.Second)
+
+	ctx := context.Background()
+	ctx = pcontext.SetAuthorizer(ctx, makeNewAuthorization(ctx, t, makeAuthz))
+
+	// The first attempt of a scheduled run, and a retry of it.
+	first := backend.RunLogBase{
+		Task:            task,
+		RunID:           platformtesting.MustIDBase16("2c20766972747571"),
+		RunScheduledFor: sf.Unix(),
+		Attempt:         1,
+	}
+	retry := backend.RunLogBase{
+		Task:            task,
+		RunID:           platformtesting.MustIDBase16("2c20766972747572"),
+		RunScheduledFor: sf.Unix(),
+		RequestedAt:     sf.Add(5 * time.Second).Unix(),
+		Attempt:         2,
+	}
+	for i, rlb := range []backend.RunLogBase{first, retry} {
+		if err := writer.UpdateRunState(ctx, rlb, sf.Add(time.Duration(i+1)*time.Second), backend.RunStarted); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, rlb := range []backend.RunLogBase{first, retry} {
+		run, err := reader.FindRunByID(ctx, task.Org, rlb.RunID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Attempt != int(rlb.Attempt) {
+			t.Fatalf("expected run %s to be attempt %d, got %d", rlb.RunID, rlb.Attempt, run.Attempt)
+		}
+	}
+
+	runs, err := reader.ListRuns(ctx, task.Org, platform.RunFilter{Task: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	for _, run := range runs {
+		if run.ID == retry.RunID && run.Attempt != 2 {
+			t.Fatalf("expected retry to be attempt 2, got %d", run.Attempt)
+		}
+	}
+}
+
 func listLogsTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc) {
 	writer, reader, makeAuthz := crf(t)
 	defer drf(t, writer, reader)
@@ -30,10 +30,14 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 			"FindTask",
 			"FindMeta",
 			"FindTaskByIDWithMeta",
+			"ListTaskVersions",
+			"MoveTaskOrg",
+			"TaskParams",
 			"DeleteTask",
 			"CreateNextRun",
 			"FinishRun",
 			"ManuallyRunTimeRange",
+			"RetryRun",
 		}
 	}
 	availableFuncs := map[string]TestFunc{
@@ -43,10 +47,14 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 		"FindTask":             testStoreFindTask,
 		"FindMeta":             testStoreFindMeta,
 		"FindTaskByIDWithMeta": testStoreFindByIDWithMeta,
+		"ListTaskVersions":     testStoreListTaskVersions,
+		"MoveTaskOrg":          testStoreMoveTaskOrg,
+		"TaskParams":           testStoreTaskParams,
 		"DeleteTask":           testStoreDelete,
 		"CreateNextRun":        testStoreCreateNextRun,
 		"FinishRun":            testStoreFinishRun,
 		"ManuallyRunTimeRange": testStoreManuallyRunTimeRange,
+		"RetryRun":             testStoreRetryRun,
 		"DeleteOrg":            testStoreDeleteOrg,
 	}
 
@@ -632,6 +640,226 @@ from(bucket: "b") |> range(start:-1h) |> http.to(url: "http://example.com")`
 	})
 }
 
+func testStoreMoveTaskOrg(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script = `option task = {
+		name: "a task",
+		every: 1h,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+
+	s := create(t)
+	defer destroy(t, s)
+
+	ctx := context.Background()
+	id, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 2, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Org: 3, AuthorizationID: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NewTask.Org != 3 {
+		t.Fatalf("expected updated task to belong to org 3, got %s", res.NewTask.Org)
+	}
+
+	task, meta, err := s.FindTaskByIDWithMeta(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Org != 3 {
+		t.Fatalf("expected task to belong to org 3, got %s", task.Org)
+	}
+	if task.Script != script {
+		t.Fatalf("expected script to be unchanged, got %q", task.Script)
+	}
+	if meta.AuthorizationID != 4 {
+		t.Fatalf("expected authorization ID 4, got %d", meta.AuthorizationID)
+	}
+
+	ts, err := s.ListTasks(ctx, backend.TaskSearchParams{Org: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 0 {
+		t.Fatalf("expected no tasks left in org 1, got %d", len(ts))
+	}
+
+	ts, err = s.ListTasks(ctx, backend.TaskSearchParams{Org: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Task.ID != id {
+		t.Fatalf("expected the task to be listed in org 3, got %v", ts)
+	}
+}
+
+func testStoreTaskParams(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script = `option task = {
+		name: "a task",
+		every: 1h,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+
+	s := create(t)
+	defer destroy(t, s)
+
+	ctx := context.Background()
+	params := map[string]string{"env": "prod", "region": "eu"}
+	id, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 2, Script: script, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkParams := func(t *testing.T, want map[string]string) {
+		t.Helper()
+
+		task, err := s.FindTaskByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(task.Params, want) {
+			t.Fatalf("FindTaskByID: expected params %v, got %v", want, task.Params)
+		}
+
+		task, _, err = s.FindTaskByIDWithMeta(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(task.Params, want) {
+			t.Fatalf("FindTaskByIDWithMeta: expected params %v, got %v", want, task.Params)
+		}
+
+		ts, err := s.ListTasks(ctx, backend.TaskSearchParams{Org: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ts) != 1 || !cmp.Equal(ts[0].Task.Params, want) {
+			t.Fatalf("ListTasks: expected params %v, got %v", want, ts)
+		}
+	}
+	checkParams(t, params)
+
+	// Updating something else keeps the params.
+	res, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Status: backend.TaskInactive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(res.NewTask.Params, params) {
+		t.Fatalf("expected updated task to keep params %v, got %v", params, res.NewTask.Params)
+	}
+	checkParams(t, params)
+
+	// Updated params replace the old ones.
+	params = map[string]string{"env": "dev"}
+	res, err = s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(res.NewTask.Params, params) {
+		t.Fatalf("expected updated task to have params %v, got %v", params, res.NewTask.Params)
+	}
+	checkParams(t, params)
+
+	// Empty params remove them.
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Params: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	checkParams(t, nil)
+
+	// Invalid params are rejected.
+	if _, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Params: map[string]string{"now": "x"}}); err == nil {
+		t.Fatal("expected error updating task with a reserved param")
+	}
+	if _, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, AuthorizationID: 2, Script: script, Params: map[string]string{"a-b": "x"}}); err == nil {
+		t.Fatal("expected error creating task with an invalid param")
+	}
+}
+
+func testStoreListTaskVersions(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script1 = `option task = {
+		name: "a task",
+		every: 1h,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+	const script2 = `option task = {
+		name: "a task",
+		every: 2h,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+	const script3 = `option task = {
+		name: "a renamed task",
+		every: 2h,
+	}
+
+from(bucket:"y") |> range(start:-1h)`
+
+	s := create(t)
+	defer destroy(t, s)
+
+	id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: 1, AuthorizationID: 2, Script: script1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, err := s.ListTaskVersions(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 0 {
+		t.Fatalf("expected no versions for a new task, got %v", vs)
+	}
+
+	// Status-only updates and updates that don't change the script do not create versions.
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Status: backend.TaskInactive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script3}); err != nil {
+		t.Fatal(err)
+	}
+
+	vs, err = s.ListTaskVersions(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(vs))
+	}
+	for i, want := range []string{script1, script2} {
+		if vs[i].Version != int64(i+1) {
+			t.Errorf("expected version %d, got %d", i+1, vs[i].Version)
+		}
+		if vs[i].Script != want {
+			t.Errorf("version %d: expected script %q, got %q", i+1, want, vs[i].Script)
+		}
+		if vs[i].ReplacedAt == 0 {
+			t.Errorf("version %d: expected replaced at to be set", i+1)
+		}
+	}
+
+	if _, err := s.ListTaskVersions(context.Background(), platform.ID(math.MaxUint64)); err != backend.ErrTaskNotFound {
+		t.Fatalf("expected %v for missing task, got %v", backend.ErrTaskNotFound, err)
+	}
+
+	if _, err := s.DeleteTask(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListTaskVersions(context.Background(), id); err != backend.ErrTaskNotFound {
+		t.Fatalf("expected %v for deleted task, got %v", backend.ErrTaskNotFound, err)
+	}
+}
+
 func testStoreFindByIDWithMeta(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
 	const script = `option task = {
 		name: "a task",
@@ -779,6 +1007,8 @@ from(bucket:"test") |> range(start:-1h)`
 		badID++
 		if _, err := s.CreateNextRun(context.Background(), platform.ID(badID), 999); err == nil {
 			t.Fatal("expected error for CreateNextRun with bad ID, got none")
+		} else if kind := backend.ErrorKindOf(err); kind != backend.ErrorNotFound {
+			t.Fatalf("expected a not found error for CreateNextRun with bad ID, got a %s error: %v", kind, err)
 		}
 
 		_, err = s.CreateNextRun(context.Background(), taskID, 64)
@@ -933,6 +1163,8 @@ from(bucket:"test") |> range(start:-1h)`
 
 	if err := s.FinishRun(context.Background(), task, rc.Created.RunID); err == nil {
 		t.Fatal("expected failure when removing run that doesnt exist")
+	} else if kind := backend.ErrorKindOf(err); kind != backend.ErrorNotFound {
+		t.Fatalf("expected a not found error when removing run that doesnt exist, got a %s error: %v", kind, err)
 	}
 }
 
@@ -973,6 +1205,47 @@ from(bucket:"test") |> range(start:-1h)`
 	}
 }
 
+func testStoreRetryRun(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script = `option task = {
+		name: "a task",
+		cron: "* * * * *",
+	}
+
+from(bucket:"test") |> range(start:-1h)`
+	s := create(t)
+	defer destroy(t, s)
+
+	taskID, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: 1, AuthorizationID: 3, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr, err := s.RetryRun(context.Background(), taskID, 60, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.Start != 60 || mr.End != 60 || mr.RequestedAt != 100 || mr.Try != 2 || !platform.ID(mr.RunID).Valid() {
+		t.Fatalf("unexpected manual run for retry: %#v", mr)
+	}
+
+	meta, err := s.FindTaskMetaByID(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.ManualRuns) != 1 || meta.ManualRuns[0].Try != 2 {
+		t.Fatalf("expected retry to be queued as attempt 2, got %#v", meta.ManualRuns)
+	}
+
+	// The natural run for 60 is not due yet, so the retry is created from the queue.
+	rc, err := s.CreateNextRun(context.Background(), taskID, 59)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Created.Attempt != 2 || rc.Created.RunID != platform.ID(mr.RunID) {
+		t.Fatalf("expected the retry to be created as attempt 2 of run %s, got %#v", platform.ID(mr.RunID), rc.Created)
+	}
+}
+
 func testStoreDeleteOrg(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
 	s := create(t)
 	defer destroy(t, s)
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -9,14 +10,20 @@ import (
 
 // TaskControlService is a low-level controller interface, intended to be passed to
 // task executors and schedulers, which allows creation, completion, and status updates of runs.
+//
+// Implementations should return their errors as a TaskControlError, or as one of the errors classified by ErrorKindOf,
+// so that callers know whether to retry a call that failed.
 type TaskControlService interface {
 	// CreateNextRun attempts to create a new run.
 	// The new run's ScheduledFor is assigned the earliest possible time according to task's cron,
 	// that is later than any in-progress run and LatestCompleted run.
 	// If the run's ScheduledFor would be later than the passed-in now, CreateNextRun returns a RunNotYetDueError.
+	// If the task does not exist, the error is of kind ErrorNotFound, and if it already has as many runs in progress
+	// as its concurrency allows, the error is of kind ErrorConflict.
 	CreateNextRun(ctx context.Context, taskID influxdb.ID, now int64) (RunCreation, error)
 
 	// FinishRun removes runID from the list of running tasks and if its `ScheduledFor` is later then last completed update it.
+	// If the run is not running, because it never existed or already finished, the error is of kind ErrorNotFound.
 	FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
 
 	// NextDueRun returns the Unix timestamp of when the next call to CreateNextRun will be ready.
@@ -29,3 +36,132 @@ type TaskControlService interface {
 	// AddRunLog adds a log line to the run.
 	AddRunLog(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
 }
+
+// ErrorKind classifies the errors of a TaskControlService, so that its callers can tell whether to retry the call that failed.
+type ErrorKind int
+
+const (
+	// ErrorUnknown is the kind of the errors that are not classified.
+	ErrorUnknown ErrorKind = iota
+
+	// ErrorRetryable is the kind of the transient errors, such as those of an unavailable store:
+	// the call may succeed if it is retried later.
+	ErrorRetryable
+
+	// ErrorPermanent is the kind of the errors that fail the call again if it is retried, such as those of an invalid task.
+	ErrorPermanent
+
+	// ErrorConflict is the kind of the errors of calls conflicting with the current state of a task or run,
+	// such as creating a run over the concurrency of its task.
+	ErrorConflict
+
+	// ErrorNotFound is the kind of the errors of calls for a task or run that does not exist.
+	ErrorNotFound
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorRetryable:
+		return "retryable"
+	case ErrorPermanent:
+		return "permanent"
+	case ErrorConflict:
+		return "conflict"
+	case ErrorNotFound:
+		return "not found"
+	default:
+		return "unknown"
+	}
+}
+
+// TaskControlError is an error of a TaskControlService, of a Kind telling whether to retry the call that failed.
+type TaskControlError struct {
+	Kind ErrorKind
+
+	// RetryAfter is, for a retryable error, how long to wait at least before retrying the call.
+	// When zero, the caller backs off on its own.
+	RetryAfter time.Duration
+
+	Err error
+}
+
+func (e *TaskControlError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error e classifies.
+func (e *TaskControlError) Unwrap() error {
+	return e.Err
+}
+
+// RetryableError returns err as an error of kind ErrorRetryable, whose call is retried after retryAfter at least.
+func RetryableError(err error, retryAfter time.Duration) error {
+	return &TaskControlError{Kind: ErrorRetryable, RetryAfter: retryAfter, Err: err}
+}
+
+// PermanentError returns err as an error of kind ErrorPermanent.
+func PermanentError(err error) error {
+	return &TaskControlError{Kind: ErrorPermanent, Err: err}
+}
+
+// ConflictError returns err as an error of kind ErrorConflict.
+func ConflictError(err error) error {
+	return &TaskControlError{Kind: ErrorConflict, Err: err}
+}
+
+// NotFoundError returns err as an error of kind ErrorNotFound.
+func NotFoundError(err error) error {
+	return &TaskControlError{Kind: ErrorNotFound, Err: err}
+}
+
+// ErrorKindOf returns the kind of err.
+// A TaskControlError is of its Kind. The errors of this package are classified as well:
+// ErrTaskNotFound and ErrRunNotFound are not found, an InvalidRunTransitionError is a conflict,
+// and a QuotaExceededError is retryable. An *influxdb.Error is classified by its code,
+// and a deadline exceeded is retryable. Any other error is of kind ErrorUnknown.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ErrorUnknown
+	}
+
+	var tce *TaskControlError
+	if errors.As(err, &tce) {
+		return tce.Kind
+	}
+	if errors.Is(err, ErrTaskNotFound) || errors.Is(err, ErrRunNotFound) {
+		return ErrorNotFound
+	}
+	if errors.As(err, &InvalidRunTransitionError{}) {
+		return ErrorConflict
+	}
+	if errors.As(err, &QuotaExceededError{}) {
+		return ErrorRetryable
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorRetryable
+	}
+
+	var ierr *influxdb.Error
+	if errors.As(err, &ierr) {
+		switch influxdb.ErrorCode(ierr) {
+		case influxdb.ENotFound:
+			return ErrorNotFound
+		case influxdb.EConflict:
+			return ErrorConflict
+		case influxdb.EUnavailable, influxdb.ETooManyRequests:
+			return ErrorRetryable
+		case influxdb.EInvalid, influxdb.EEmptyValue, influxdb.EUnprocessableEntity, influxdb.EForbidden, influxdb.EUnauthorized:
+			return ErrorPermanent
+		}
+	}
+	return ErrorUnknown
+}
+
+// RetryAfter returns how long to wait at least before retrying the call that failed with err, or zero if err does not tell.
+func RetryAfter(err error) time.Duration {
+	var tce *TaskControlError
+	if errors.As(err, &tce) {
+		return tce.RetryAfter
+	}
+	return 0
+}
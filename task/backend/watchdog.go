@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// typicalDurationRuns is the number of latest successful runs of a task its typical duration is computed from.
+const typicalDurationRuns = 10
+
+// RunRetrier schedules a new attempt of a run of a task, like a manual run.
+// Store implements RunRetrier.
+type RunRetrier interface {
+	RetryRun(ctx context.Context, taskID platform.ID, now, requestedAt int64, attempt uint32) (*StoreTaskMetaManualRun, error)
+}
+
+// WatchdogConfig configures how a TickScheduler detects and remediates stuck runs.
+//
+// A run is stuck once it has been executing for longer than DurationFactor times the typical duration of its task,
+// that is the median duration of the latest successful runs of the task, and for longer than MinDuration.
+// A stuck run is canceled and force-finished as failed without waiting for its execution to stop,
+// so that it no longer holds one of the concurrency slots of its task.
+type WatchdogConfig struct {
+	// DurationFactor is the multiple of the typical duration of a task after which its runs are stuck.
+	// If zero, only MinDuration is used.
+	DurationFactor int
+
+	// MinDuration is the duration a run may always execute for.
+	// It is the only limit for the tasks without a successful run yet.
+	MinDuration time.Duration
+
+	// Retrier, if set, schedules a retry of the stuck runs.
+	// Only scheduled runs are retried, not manual runs, so that a run that always gets stuck is retried once.
+	Retrier RunRetrier
+}
+
+// WithWatchdog makes the scheduler remediate stuck runs according to cfg, every time it ticks.
+// A cfg with a MinDuration of zero disables the watchdog.
+func WithWatchdog(cfg WatchdogConfig) TickSchedulerOption {
+	return func(s *TickScheduler) {
+		if cfg.MinDuration <= 0 {
+			s.watchdog = nil
+			return
+		}
+		s.watchdog = &cfg
+	}
+}
+
+// recordDuration records the duration in seconds of a successful run.
+func (ts *taskScheduler) recordDuration(d int64) {
+	ts.durationsMu.Lock()
+	defer ts.durationsMu.Unlock()
+
+	ts.durations = append(ts.durations, d)
+	if len(ts.durations) > typicalDurationRuns {
+		ts.durations = ts.durations[len(ts.durations)-typicalDurationRuns:]
+	}
+}
+
+// typicalDuration returns the median duration in seconds of the latest successful runs,
+// and false if there has been no successful run.
+func (ts *taskScheduler) typicalDuration() (int64, bool) {
+	ts.durationsMu.Lock()
+	defer ts.durationsMu.Unlock()
+
+	if len(ts.durations) == 0 {
+		return 0, false
+	}
+	ds := make([]int64, len(ts.durations))
+	copy(ds, ts.durations)
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	return ds[len(ds)/2], true
+}
+
+// stuckLimit returns the duration in seconds after which a run of the task is stuck.
+func (ts *taskScheduler) stuckLimit() int64 {
+	limit := int64(ts.watchdog.MinDuration / time.Second)
+	if ts.watchdog.DurationFactor > 0 {
+		if d, ok := ts.typicalDuration(); ok && d*int64(ts.watchdog.DurationFactor) > limit {
+			limit = d * int64(ts.watchdog.DurationFactor)
+		}
+	}
+	return limit
+}
+
+// abandonStuckRuns signals the runners of the runs stuck at now to abandon them.
+func (ts *taskScheduler) abandonStuckRuns(now int64) {
+	limit := ts.stuckLimit()
+
+	ts.runningMu.Lock()
+	defer ts.runningMu.Unlock()
+	for id, rc := range ts.running {
+		if rc.Stuck == nil || rc.Abandoned || now-rc.StartedAt <= limit {
+			continue
+		}
+		rc.Abandoned = true
+		ts.running[id] = rc
+		rc.Stuck <- limit
+	}
+}
+
+// abandon force-finishes the stuck run as failed, and retries it if the watchdog is configured to.
+func (r *runner) abandon(qr QueuedRun, rp RunPromise, runLogger *zap.Logger, limit int64) {
+	runLogger.Warn("Run is stuck; force-finishing it as failed", zap.Int64("limit_seconds", limit))
+
+	// The promise is stuck, so don't wait for the cancellation to complete.
+	rp.Cancel()
+	if err := r.finishRun(qr, runLogger); err != nil {
+		runLogger.Error("Run is stuck, and desired state update failed", zap.Error(err))
+	}
+	r.fail(qr, runLogger, "Watchdog", fmt.Errorf("run did not complete within %s, force-finished as failed", time.Duration(limit)*time.Second))
+
+	retrier := r.ts.watchdog.Retrier
+	if retrier == nil || qr.RequestedAt != 0 {
+		return
+	}
+	if _, err := retrier.RetryRun(r.ctx, qr.TaskID, qr.Now, r.ts.clock.Now().Unix(), NextAttempt(qr.Attempt)); err != nil {
+		runLogger.Info("Failed to retry stuck run", zap.Error(err))
+		return
+	}
+	rlb := RunLogBase{
+		Task:            r.ts.Task(),
+		RunID:           qr.RunID,
+		RunScheduledFor: qr.Now,
+		RequestedAt:     qr.RequestedAt,
+		Attempt:         qr.Attempt,
+	}
+	if err := r.logWriter.AddRunLog(r.ctx, rlb, r.ts.clock.Now(), "Watchdog: scheduled a retry of the run"); err != nil {
+		runLogger.Info("Failed to update run log", zap.Error(err))
+	}
+
+	// Let the next tick pick up the retry.
+	r.ts.nextDueMu.Lock()
+	r.ts.hasQueue = true
+	r.ts.nextDueMu.Unlock()
+}
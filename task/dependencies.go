@@ -0,0 +1,125 @@
+package task
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+type dependencyRecorder struct {
+	platform.TaskService
+	deps    platform.TaskDependencyService
+	scripts platform.TaskScriptService
+	logger  *zap.Logger
+}
+
+// NewDependencyRecorder wraps ts and records in ds the resources referenced by the Flux of the tasks,
+// as resolved with the task scripts of scripts, whenever it changes.
+// Failures to record the dependencies of a task are logged, they never fail the change of the task.
+func NewDependencyRecorder(logger *zap.Logger, ts platform.TaskService, ds platform.TaskDependencyService, scripts platform.TaskScriptService) platform.TaskService {
+	return &dependencyRecorder{
+		TaskService: ts,
+		deps:        ds,
+		scripts:     scripts,
+		logger:      logger,
+	}
+}
+
+func (r *dependencyRecorder) CreateTask(ctx context.Context, tc platform.TaskCreate) (*platform.Task, error) {
+	t, err := r.TaskService.CreateTask(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+	r.record(ctx, t)
+	return t, nil
+}
+
+func (r *dependencyRecorder) UpdateTask(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+	t, err := r.TaskService.UpdateTask(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	r.record(ctx, t)
+	return t, nil
+}
+
+func (r *dependencyRecorder) RollbackTask(ctx context.Context, taskID platform.ID, version int) (*platform.Task, error) {
+	t, err := r.TaskService.RollbackTask(ctx, taskID, version)
+	if err != nil {
+		return nil, err
+	}
+	r.record(ctx, t)
+	return t, nil
+}
+
+func (r *dependencyRecorder) MoveTask(ctx context.Context, taskID platform.ID, move platform.TaskMove) (*platform.Task, error) {
+	t, err := r.TaskService.MoveTask(ctx, taskID, move)
+	if err != nil {
+		return nil, err
+	}
+	r.record(ctx, t)
+	return t, nil
+}
+
+func (r *dependencyRecorder) DeleteTask(ctx context.Context, id platform.ID) error {
+	if err := r.TaskService.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+	if err := r.deps.DeleteTaskDependencies(ctx, id); err != nil {
+		r.logger.Info("Failed to delete task dependencies", zap.Stringer("task_id", id), zap.Error(err))
+	}
+	return nil
+}
+
+func (r *dependencyRecorder) record(ctx context.Context, t *platform.Task) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := RecordTaskDependencies(ctx, r.deps, r.scripts, t); err != nil {
+		r.logger.Info("Failed to record task dependencies", zap.Stringer("task_id", t.ID), zap.Error(err))
+	}
+}
+
+// RecordTaskDependencies records in ds the resources referenced by the Flux of t,
+// as resolved with the task scripts of scripts.
+func RecordTaskDependencies(ctx context.Context, ds platform.TaskDependencyService, scripts platform.TaskScriptService, t *platform.Task) error {
+	script, err := platform.ResolveTaskScriptIncludes(ctx, scripts, t.OrganizationID, t.Flux)
+	if err != nil {
+		return err
+	}
+
+	deps, err := platform.AnalyzeTaskDependencies(script)
+	if err != nil {
+		return err
+	}
+
+	return ds.PutTaskDependencies(ctx, &platform.TaskDependencies{
+		TaskID:       t.ID,
+		OrgID:        t.OrganizationID,
+		Dependencies: deps,
+	})
+}
+
+// RecordAllTaskDependencies records the dependencies of every task of ts, e.g. of the tasks
+// created before their dependencies were recorded. The tasks whose dependencies cannot be recorded are logged.
+func RecordAllTaskDependencies(ctx context.Context, logger *zap.Logger, ts platform.TaskService, ds platform.TaskDependencyService, scripts platform.TaskScriptService) error {
+	filter := platform.TaskFilter{Limit: platform.TaskMaxPageSize}
+	for {
+		tasks, _, err := ts.FindTasks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if err := RecordTaskDependencies(ctx, ds, scripts, t); err != nil {
+				logger.Info("Failed to record task dependencies", zap.Stringer("task_id", t.ID), zap.Error(err))
+			}
+		}
+		if len(tasks) < filter.Limit {
+			return nil
+		}
+		after := tasks[len(tasks)-1].ID
+		filter.After = &after
+	}
+}
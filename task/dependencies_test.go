@@ -0,0 +1,85 @@
+package task_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/task"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDependencyRecorder(t *testing.T) {
+	recorded := map[influxdb.ID]*influxdb.TaskDependencies{}
+	ds := mock.NewTaskDependencyService()
+	ds.PutTaskDependenciesFn = func(ctx context.Context, d *influxdb.TaskDependencies) error {
+		recorded[d.TaskID] = d
+		return nil
+	}
+	ds.DeleteTaskDependenciesFn = func(ctx context.Context, taskID influxdb.ID) error {
+		delete(recorded, taskID)
+		return nil
+	}
+
+	scripts := mock.NewTaskScriptService()
+	scripts.FindTaskScriptFn = func(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+		return &influxdb.TaskScript{OrgID: orgID, Name: name, Version: 1, Flux: `token = secrets.get(key: "api-token")`}, nil
+	}
+
+	ts := &mock.TaskService{
+		CreateTaskFn: func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+			return &influxdb.Task{ID: 1, OrganizationID: tc.OrganizationID, Flux: tc.Flux}, nil
+		},
+		UpdateTaskFn: func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+			return &influxdb.Task{ID: id, OrganizationID: 3, Flux: *upd.Flux}, nil
+		},
+		DeleteTaskFn: func(ctx context.Context, id influxdb.ID) error { return nil },
+	}
+	r := task.NewDependencyRecorder(zaptest.NewLogger(t), ts, ds, scripts)
+	ctx := context.Background()
+
+	if _, err := r.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: 3,
+		Flux: `// @include "helpers"
+option task = {name: "a", every: 1h}
+from(bucket: "telemetry") |> range(start: -1h) |> to(bucket: "downsampled")`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	exp := []influxdb.TaskDependency{
+		{Type: influxdb.TaskDependencyBucket, Name: "downsampled"},
+		{Type: influxdb.TaskDependencyBucket, Name: "telemetry"},
+		{Type: influxdb.TaskDependencySecret, Name: "api-token"},
+	}
+	if d := recorded[1]; d == nil || d.OrgID != 3 || !reflect.DeepEqual(d.Dependencies, exp) {
+		t.Fatalf("unexpected dependencies after create: %+v", d)
+	}
+
+	// Invalid Flux cannot be analyzed, the update succeeds anyway and the previous dependencies are kept.
+	flux := `from(bucket: "telemetry" |> range(start: -1h)`
+	if _, err := r.UpdateTask(ctx, 1, influxdb.TaskUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	}
+	if d := recorded[1]; d == nil || !reflect.DeepEqual(d.Dependencies, exp) {
+		t.Fatalf("unexpected dependencies after invalid update: %+v", d)
+	}
+
+	flux = `option task = {name: "a", every: 1h}
+from(bucket: "telemetry") |> range(start: -1h)`
+	if _, err := r.UpdateTask(ctx, 1, influxdb.TaskUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	}
+	exp = []influxdb.TaskDependency{{Type: influxdb.TaskDependencyBucket, Name: "telemetry"}}
+	if d := recorded[1]; d == nil || !reflect.DeepEqual(d.Dependencies, exp) {
+		t.Fatalf("unexpected dependencies after update: %+v", d)
+	}
+
+	if err := r.DeleteTask(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := recorded[1]; ok {
+		t.Fatalf("expected the dependencies to be deleted with the task, got %+v", d)
+	}
+}
@@ -0,0 +1,182 @@
+// Package include resolves the include directives of task scripts,
+// which pull the Flux of auxiliary scripts of the task's organization into the task script when it executes.
+//
+// An include directive is a line of the form
+//
+//	// @include "name"
+//
+// which includes the latest version of the named script, or
+//
+//	// @include "name@3"
+//
+// which pins the included script to a version.
+package include
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxDepth is the maximum nesting depth of includes.
+const MaxDepth = 8
+
+var (
+	directivePrefix = regexp.MustCompile(`^\s*//\s*@include\b`)
+	directive       = regexp.MustCompile(`^\s*//\s*@include\s+"([^"@]+)(?:@([0-9]+))?"\s*$`)
+	validName       = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+)
+
+// Directive is an include directive of a script.
+type Directive struct {
+	// Name is the name of the included script.
+	Name string
+	// Version is the pinned version of the included script, or 0 for its latest version.
+	Version int
+	// Line is the line of the directive in the script, starting at 1.
+	Line int
+}
+
+func (d Directive) String() string {
+	if d.Version == 0 {
+		return d.Name
+	}
+	return fmt.Sprintf("%s@%d", d.Name, d.Version)
+}
+
+// ValidName returns an error if name cannot name an included script.
+func ValidName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid script name %q: must be 1 to 128 letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
+
+// Has returns whether script has include directives.
+func Has(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		if directivePrefix.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse returns the include directives of script, in order.
+func Parse(script string) ([]Directive, error) {
+	var ds []Directive
+	s := bufio.NewScanner(strings.NewReader(script))
+	s.Buffer(nil, len(script)+1)
+	for n := 1; s.Scan(); n++ {
+		d, ok, err := parseLine(s.Text(), n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ds = append(ds, d)
+		}
+	}
+	return ds, s.Err()
+}
+
+func parseLine(line string, n int) (Directive, bool, error) {
+	if !directivePrefix.MatchString(line) {
+		return Directive{}, false, nil
+	}
+
+	m := directive.FindStringSubmatch(line)
+	if m == nil {
+		return Directive{}, false, fmt.Errorf("line %d: malformed include directive; expected // @include \"name\" or // @include \"name@version\"", n)
+	}
+	if err := ValidName(m[1]); err != nil {
+		return Directive{}, false, fmt.Errorf("line %d: %v", n, err)
+	}
+
+	d := Directive{Name: m[1], Line: n}
+	if m[2] != "" {
+		v, err := strconv.Atoi(m[2])
+		if err != nil || v < 1 {
+			return Directive{}, false, fmt.Errorf("line %d: invalid version %q of script %q", n, m[2], m[1])
+		}
+		d.Version = v
+	}
+	return d, true, nil
+}
+
+// LookupFunc returns the Flux of a version of the named script, or of its latest version if version is 0.
+type LookupFunc func(name string, version int) (string, error)
+
+// Resolve returns script with every include directive replaced by the Flux of the included script,
+// itself resolved. A script included more than once is only inserted where it is first included;
+// including two versions of the same script and include cycles are errors.
+func Resolve(script string, lookup LookupFunc) (string, error) {
+	r := &resolver{lookup: lookup, included: make(map[string]int)}
+	return r.resolve(script, nil)
+}
+
+type resolver struct {
+	lookup LookupFunc
+
+	// included maps the name of every script included so far to its included version.
+	included map[string]int
+}
+
+func (r *resolver) resolve(script string, stack []string) (string, error) {
+	if !Has(script) {
+		return script, nil
+	}
+	if len(stack) > MaxDepth {
+		return "", fmt.Errorf("includes nested deeper than %d: %s", MaxDepth, strings.Join(stack, " -> "))
+	}
+
+	var b strings.Builder
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		d, ok, err := parseLine(line, i+1)
+		if err != nil {
+			return "", wrap(stack, err)
+		}
+		if !ok {
+			b.WriteString(line)
+			continue
+		}
+
+		for _, name := range stack {
+			if name == d.Name {
+				return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), d.Name)
+			}
+		}
+		if v, ok := r.included[d.Name]; ok {
+			if v != d.Version {
+				return "", wrap(stack, fmt.Errorf("line %d: script %q is included both as %s and %s", d.Line, d.Name, Directive{Name: d.Name, Version: v}, d))
+			}
+			b.WriteString(line)
+			continue
+		}
+		r.included[d.Name] = d.Version
+
+		flux, err := r.lookup(d.Name, d.Version)
+		if err != nil {
+			return "", wrap(stack, fmt.Errorf("line %d: failed to include %s: %v", d.Line, d, err))
+		}
+		resolved, err := r.resolve(flux, append(stack, d.Name))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(resolved)
+	}
+	return b.String(), nil
+}
+
+func wrap(stack []string, err error) error {
+	if len(stack) == 0 {
+		return err
+	}
+	return fmt.Errorf("in script %q: %v", stack[len(stack)-1], err)
+}
@@ -0,0 +1,113 @@
+package include_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/task/include"
+)
+
+func TestParse(t *testing.T) {
+	script := `// @include "helpers"
+  //@include "lib.v2@3"
+// not an include
+from(bucket: "b") |> range(start: -1h)`
+
+	ds, err := include.Parse(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []include.Directive{
+		{Name: "helpers", Line: 1},
+		{Name: "lib.v2", Version: 3, Line: 2},
+	}
+	if !reflect.DeepEqual(ds, want) {
+		t.Fatalf("got %+v, want %+v", ds, want)
+	}
+
+	for _, bad := range []string{
+		`// @include helpers`,
+		`// @include "help/ers"`,
+		`// @include "helpers@0"`,
+		`// @include "helpers" "more"`,
+	} {
+		if _, err := include.Parse(bad); err == nil {
+			t.Errorf("expected error parsing %q", bad)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	scripts := map[string]string{
+		"a@0": "// @include \"b\"\na = 1",
+		"b@0": "b = 2",
+		"b@2": "b = 22",
+		"c@0": "// @include \"a\"\n// @include \"b\"\nc = 3",
+		"x@0": "// @include \"y\"",
+		"y@0": "// @include \"x\"",
+	}
+	lookup := func(name string, version int) (string, error) {
+		s, ok := scripts[fmt.Sprintf("%s@%d", name, version)]
+		if !ok {
+			return "", fmt.Errorf("script not found")
+		}
+		return s, nil
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "no includes",
+			script: "from(bucket: \"b\")",
+			want:   "from(bucket: \"b\")",
+		},
+		{
+			name:   "nested includes are inserted once",
+			script: "// @include \"c\"\nfrom(bucket: \"b\")",
+			want:   "b = 2\na = 1\n// @include \"b\"\nc = 3\nfrom(bucket: \"b\")",
+		},
+		{
+			name:   "pinned version",
+			script: "// @include \"b@2\"",
+			want:   "b = 22",
+		},
+		{
+			name:    "conflicting versions",
+			script:  "// @include \"b@2\"\n// @include \"a\"",
+			wantErr: `in script "a": line 1: script "b" is included both as b@2 and b`,
+		},
+		{
+			name:    "cycle",
+			script:  "// @include \"x\"",
+			wantErr: "include cycle: x -> y -> x",
+		},
+		{
+			name:    "missing script",
+			script:  "// @include \"missing\"",
+			wantErr: "line 1: failed to include missing: script not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := include.Resolve(tt.script, lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
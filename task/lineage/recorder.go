@@ -0,0 +1,134 @@
+// Package lineage records the lineage of the data written by the runs of tasks.
+package lineage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/options"
+	"go.uber.org/zap"
+)
+
+var _ backend.RunNotifier = (*Recorder)(nil)
+
+// Recorder records the lineage of the successful runs of tasks: the buckets they read,
+// the buckets they wrote, and the time range of the data they read.
+// The buckets are determined from the Flux of the task, as resolved with its task scripts.
+// The time range is the one of the range() calls of the Flux, or else the last period of the task
+// if it runs every period.
+type Recorder struct {
+	Service influxdb.LineageService
+	Buckets influxdb.BucketService
+	Scripts influxdb.TaskScriptService
+	Logger  *zap.Logger
+
+	wg sync.WaitGroup
+}
+
+// NewRecorder returns a recorder of the lineage of the runs in s, with the buckets of bs.
+func NewRecorder(s influxdb.LineageService, bs influxdb.BucketService, scripts influxdb.TaskScriptService, logger *zap.Logger) *Recorder {
+	return &Recorder{
+		Service: s,
+		Buckets: bs,
+		Scripts: scripts,
+		Logger:  logger.With(zap.String("svc", "taskd/lineage")),
+	}
+}
+
+// RunFinished records the lineage of the run of task if it succeeded, in the background.
+func (r *Recorder) RunFinished(ctx context.Context, task *backend.StoreTask, qr backend.QueuedRun, status backend.RunStatus, message string) {
+	if status != backend.RunSuccess {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.record(ctx, task, qr); err != nil {
+			r.Logger.Info("Failed to record run lineage", zap.Stringer("task_id", task.ID), zap.Stringer("run_id", qr.RunID), zap.Error(err))
+		}
+	}()
+}
+
+// Wait blocks until the lineage of the runs in progress is recorded.
+func (r *Recorder) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Recorder) record(ctx context.Context, task *backend.StoreTask, qr backend.QueuedRun) error {
+	script, err := influxdb.ResolveTaskScriptIncludes(ctx, r.Scripts, task.Org, task.Script)
+	if err != nil {
+		return err
+	}
+
+	now := time.Unix(qr.Now, 0).UTC()
+	l, err := influxdb.AnalyzeTaskLineage(script, now)
+	if err != nil {
+		return err
+	}
+	if len(l.Destinations) == 0 {
+		return nil
+	}
+	if l.Start.IsZero() {
+		if opts, err := options.FromScript(script); err == nil && opts.Every > 0 {
+			l.Start, l.Stop = now.Add(-opts.Every), now
+		}
+	}
+
+	sources := make([]influxdb.ID, 0, len(l.Sources))
+	for _, ref := range l.Sources {
+		if id, ok := r.findBucket(ctx, task, ref); ok {
+			sources = append(sources, id)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+
+	for _, ref := range l.Destinations {
+		id, ok := r.findBucket(ctx, task, ref)
+		if !ok {
+			continue
+		}
+		if err := r.Service.AddLineageRecord(ctx, &influxdb.LineageRecord{
+			OrgID:       task.Org,
+			TaskID:      task.ID,
+			RunID:       qr.RunID,
+			Sources:     sources,
+			Destination: id,
+			Start:       l.Start,
+			Stop:        l.Stop,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findBucket returns the ID of the bucket of the organization of task referenced by ref,
+// logging the buckets that cannot be found, e.g. the ones deleted since the run.
+func (r *Recorder) findBucket(ctx context.Context, task *backend.StoreTask, ref influxdb.TaskDependency) (influxdb.ID, bool) {
+	filter := influxdb.BucketFilter{OrganizationID: &task.Org}
+	switch ref.Type {
+	case influxdb.TaskDependencyBucket:
+		filter.Name = &ref.Name
+	case influxdb.TaskDependencyBucketID:
+		id, err := influxdb.IDFromString(ref.Name)
+		if err != nil {
+			r.Logger.Info("Invalid bucket ID in task", zap.Stringer("task_id", task.ID), zap.String("bucket_id", ref.Name))
+			return 0, false
+		}
+		filter.ID = id
+	default:
+		return 0, false
+	}
+
+	b, err := r.Buckets.FindBucket(ctx, filter)
+	if err != nil {
+		r.Logger.Info("Failed to find bucket of run lineage", zap.Stringer("task_id", task.ID), zap.String("bucket", ref.Name), zap.Error(err))
+		return 0, false
+	}
+	return b.ID, true
+}
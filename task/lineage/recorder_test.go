@@ -0,0 +1,105 @@
+package lineage_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/lineage"
+	"go.uber.org/zap/zaptest"
+)
+
+func newRecorder(t *testing.T) (*lineage.Recorder, func() []*influxdb.LineageRecord) {
+	var (
+		mu sync.Mutex
+		rs []*influxdb.LineageRecord
+	)
+	s := mock.NewLineageService()
+	s.AddLineageRecordFn = func(_ context.Context, r *influxdb.LineageRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		rs = append(rs, r)
+		return nil
+	}
+
+	buckets := map[string]influxdb.ID{"telemetry": 1, "telemetry_5m": 3}
+	bs := mock.NewBucketService()
+	bs.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		if filter.OrganizationID == nil || *filter.OrganizationID != 20 {
+			t.Errorf("unexpected bucket filter %+v", filter)
+		}
+		if filter.ID != nil {
+			return &influxdb.Bucket{ID: *filter.ID}, nil
+		}
+		if id, ok := buckets[*filter.Name]; ok {
+			return &influxdb.Bucket{ID: id}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+
+	r := lineage.NewRecorder(s, bs, mock.NewTaskScriptService(), zaptest.NewLogger(t))
+	return r, func() []*influxdb.LineageRecord {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*influxdb.LineageRecord(nil), rs...)
+	}
+}
+
+func TestRecorder_RunFinished(t *testing.T) {
+	r, records := newRecorder(t)
+
+	task := &backend.StoreTask{ID: 10, Org: 20, Script: `option task = {name: "downsample", every: 1h}
+
+from(bucket: "telemetry") |> range(start: -1h) |> to(bucket: "telemetry_5m")
+from(bucketID: "0000000000000002") |> range(start: -1h) |> to(bucket: "telemetry_5m")
+from(bucket: "deleted") |> range(start: -1h) |> to(bucket: "telemetry_5m")
+`}
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.RunFinished(context.Background(), task, backend.QueuedRun{TaskID: 10, RunID: 100, Now: now.Unix()}, backend.RunSuccess, "")
+	r.RunFinished(context.Background(), task, backend.QueuedRun{TaskID: 10, RunID: 101, Now: now.Unix()}, backend.RunFail, "failed")
+	r.Wait()
+
+	exp := []*influxdb.LineageRecord{{
+		OrgID:       20,
+		TaskID:      10,
+		RunID:       100,
+		Sources:     []influxdb.ID{1, 2},
+		Destination: 3,
+		Start:       now.Add(-time.Hour),
+		Stop:        now,
+	}}
+	if got := records(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got records %+v, want %+v", got, exp)
+	}
+}
+
+func TestRecorder_RunFinished_Every(t *testing.T) {
+	r, records := newRecorder(t)
+
+	task := &backend.StoreTask{ID: 10, Org: 20, Script: `option task = {name: "copy", every: 30m}
+
+from(bucket: "telemetry") |> to(bucket: "telemetry_5m", org: "my-org")
+`}
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.RunFinished(context.Background(), task, backend.QueuedRun{TaskID: 10, RunID: 100, Now: now.Unix()}, backend.RunSuccess, "")
+	r.Wait()
+
+	exp := []*influxdb.LineageRecord{{
+		OrgID:       20,
+		TaskID:      10,
+		RunID:       100,
+		Sources:     []influxdb.ID{1},
+		Destination: 3,
+		Start:       now.Add(-30 * time.Minute),
+		Stop:        now,
+	}}
+	if got := records(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got records %+v, want %+v", got, exp)
+	}
+}
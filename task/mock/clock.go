@@ -0,0 +1,126 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// Clock is a virtual implementation of backend.Clock.
+// Its time only moves when Add or Set is called, firing the timers and tickers that come due.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*clockWaiter
+}
+
+// clockWaiter is a pending After timer, or a ticker if period is non-zero.
+type clockWaiter struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+var _ backend.Clock = (*Clock)(nil)
+
+// NewClock returns a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the virtual time of c.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the virtual time once c has advanced by d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &clockWaiter{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- c.now
+		return w.c
+	}
+	c.waiters = append(c.waiters, w)
+	return w.c
+}
+
+// NewTicker returns a ticker receiving the virtual time every time c advances past a multiple of d.
+// As with time.Ticker, ticks are dropped if the receiver falls behind.
+func (c *Clock) NewTicker(d time.Duration) backend.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &clockWaiter{at: c.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, w)
+	return &clockTicker{clock: c, w: w}
+}
+
+// Add advances c by d.
+func (c *Clock) Add(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves c to t, firing the timers and tickers due by t.
+// Setting c to an earlier time than its current one fires nothing.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.Before(c.now) {
+		c.now = t
+		return
+	}
+	c.now = t
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+
+		select {
+		case w.c <- t:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.at.After(t) {
+				w.at = w.at.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+func (c *Clock) stop(w *clockWaiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, cw := range c.waiters {
+		if cw == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type clockTicker struct {
+	clock *Clock
+	w     *clockWaiter
+}
+
+func (t *clockTicker) C() <-chan time.Time { return t.w.c }
+
+func (t *clockTicker) Stop() { t.clock.stop(t.w) }
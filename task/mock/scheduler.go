@@ -22,8 +22,8 @@ type Scheduler struct {
 
 	lastTick int64
 
-	claims map[string]*Task
-	meta   map[string]backend.StoreTaskMeta
+	claims map[platform.ID]*Task
+	meta   map[platform.ID]backend.StoreTaskMeta
 
 	createChan  chan *Task
 	releaseChan chan *Task
@@ -42,8 +42,8 @@ type Task struct {
 
 func NewScheduler() *Scheduler {
 	return &Scheduler{
-		claims: map[string]*Task{},
-		meta:   map[string]backend.StoreTaskMeta{},
+		claims: map[platform.ID]*Task{},
+		meta:   map[platform.ID]backend.StoreTaskMeta{},
 	}
 }
 
@@ -68,15 +68,15 @@ func (s *Scheduler) ClaimTask(task *backend.StoreTask, meta *backend.StoreTaskMe
 	s.Lock()
 	defer s.Unlock()
 
-	_, ok := s.claims[task.ID.String()]
+	_, ok := s.claims[task.ID]
 	if ok {
 		return backend.ErrTaskAlreadyClaimed
 	}
-	s.meta[task.ID.String()] = *meta
+	s.meta[task.ID] = *meta
 
 	t := &Task{Script: task.Script, StartExecution: meta.LatestCompleted, ConcurrencyLimit: uint8(meta.MaxConcurrency)}
 
-	s.claims[task.ID.String()] = t
+	s.claims[task.ID] = t
 
 	if s.createChan != nil {
 		s.createChan <- t
@@ -89,16 +89,16 @@ func (s *Scheduler) UpdateTask(task *backend.StoreTask, meta *backend.StoreTaskM
 	s.Lock()
 	defer s.Unlock()
 
-	_, ok := s.claims[task.ID.String()]
+	_, ok := s.claims[task.ID]
 	if !ok {
 		return backend.ErrTaskNotClaimed
 	}
 
-	s.meta[task.ID.String()] = *meta
+	s.meta[task.ID] = *meta
 
 	t := &Task{Script: task.Script, StartExecution: meta.LatestCompleted, ConcurrencyLimit: uint8(meta.MaxConcurrency)}
 
-	s.claims[task.ID.String()] = t
+	s.claims[task.ID] = t
 
 	if s.updateChan != nil {
 		s.updateChan <- t
@@ -115,7 +115,7 @@ func (s *Scheduler) ReleaseTask(taskID platform.ID) error {
 	s.Lock()
 	defer s.Unlock()
 
-	t, ok := s.claims[taskID.String()]
+	t, ok := s.claims[taskID]
 	if !ok {
 		return backend.ErrTaskNotClaimed
 	}
@@ -123,8 +123,8 @@ func (s *Scheduler) ReleaseTask(taskID platform.ID) error {
 		s.releaseChan <- t
 	}
 
-	delete(s.claims, taskID.String())
-	delete(s.meta, taskID.String())
+	delete(s.claims, taskID)
+	delete(s.meta, taskID)
 
 	return nil
 }
@@ -132,7 +132,7 @@ func (s *Scheduler) ReleaseTask(taskID platform.ID) error {
 func (s *Scheduler) TaskFor(id platform.ID) *Task {
 	s.Lock()
 	defer s.Unlock()
-	return s.claims[id.String()]
+	return s.claims[id]
 }
 
 func (s *Scheduler) TaskCreateChan() <-chan *Task {
@@ -162,40 +162,89 @@ func (s *Scheduler) CancelRun(_ context.Context, taskID, runID platform.ID) erro
 	return nil
 }
 
+// taskrun identifies a run of a task.
+type taskrun struct {
+	t, r platform.ID
+}
+
 // DesiredState is a mock implementation of DesiredState (used by NewScheduler).
 type DesiredState struct {
 	mu sync.Mutex
-	// Map of stringified task ID to last ID used for run.
-	runIDs map[string]uint64
+	// Map of task ID to last ID used for run.
+	runIDs map[platform.ID]uint64
 
-	// Map of stringified, concatenated task and platform ID, to runs that have been created.
-	created map[string]backend.QueuedRun
+	// Map of task and run ID to runs that have been created.
+	created map[taskrun]backend.QueuedRun
 
-	// Map of stringified task ID to task meta.
-	meta map[string]backend.StoreTaskMeta
+	// Map of task ID to task meta.
+	meta map[platform.ID]backend.StoreTaskMeta
 
 	// Map of task ID to total number of runs created for that task.
 	totalRunsCreated map[platform.ID]int
+
+	// Closed and replaced whenever a run is created or finished, to wake up the calls to PollForNumberCreated.
+	changed chan struct{}
+
+	// Generates the IDs of runs, if set. Otherwise the runs of each task are numbered from 1.
+	idgen platform.IDGenerator
 }
 
 var _ backend.DesiredState = (*DesiredState)(nil)
 
 func NewDesiredState() *DesiredState {
 	return &DesiredState{
-		runIDs:           make(map[string]uint64),
-		created:          make(map[string]backend.QueuedRun),
-		meta:             make(map[string]backend.StoreTaskMeta),
+		runIDs:           make(map[platform.ID]uint64),
+		created:          make(map[taskrun]backend.QueuedRun),
+		meta:             make(map[platform.ID]backend.StoreTaskMeta),
 		totalRunsCreated: make(map[platform.ID]int),
+		changed:          make(chan struct{}),
 	}
 }
 
+// notifyChanged wakes up the calls to PollForNumberCreated.
+// d.mu must be held.
+func (d *DesiredState) notifyChanged() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
 // SetTaskMeta sets the task meta for the given task ID.
 // SetTaskMeta must be called before CreateNextRun, for a given task ID.
 func (d *DesiredState) SetTaskMeta(taskID platform.ID, meta backend.StoreTaskMeta) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.meta[taskID.String()] = meta
+	d.meta[taskID] = meta
+}
+
+// WithIDGenerator sets the generator of the IDs of the runs created from now on,
+// so that tests can assert on the IDs of runs across tasks.
+// Without a generator, the runs of each task are numbered from 1.
+func (d *DesiredState) WithIDGenerator(gen platform.IDGenerator) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.idgen = gen
+}
+
+// SetManualRuns replaces the queue of manual runs of the given task, which are created by the next calls to CreateNextRun.
+// The runs are copied, so that tests running in parallel never share a queue with each other or with the caller.
+// SetTaskMeta must be called before SetManualRuns, for a given task ID.
+func (d *DesiredState) SetManualRuns(taskID platform.ID, runs []*backend.StoreTaskMetaManualRun) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	meta, ok := d.meta[taskID]
+	if !ok {
+		panic(fmt.Sprintf("meta not set for task with ID %s", taskID))
+	}
+
+	meta.ManualRuns = make([]*backend.StoreTaskMetaManualRun, len(runs))
+	for i, r := range runs {
+		mr := *r
+		meta.ManualRuns[i] = &mr
+	}
+	d.meta[taskID] = meta
 }
 
 // CreateNextRun creates the next run for the given task.
@@ -204,18 +253,20 @@ func (d *DesiredState) CreateNextRun(_ context.Context, taskID platform.ID, now
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	if !taskID.Valid() {
-		return backend.RunCreation{}, errors.New("invalid task id")
+		return backend.RunCreation{}, backend.PermanentError(errors.New("invalid task id"))
 	}
-	tid := taskID.String()
 
-	meta, ok := d.meta[tid]
+	meta, ok := d.meta[taskID]
 	if !ok {
-		panic(fmt.Sprintf("meta not set for task with ID %s", tid))
+		panic(fmt.Sprintf("meta not set for task with ID %s", taskID))
 	}
 
 	makeID := func() (platform.ID, error) {
-		d.runIDs[tid]++
-		runID := platform.ID(d.runIDs[tid])
+		if d.idgen != nil {
+			return d.idgen.ID(), nil
+		}
+		d.runIDs[taskID]++
+		runID := platform.ID(d.runIDs[taskID])
 		return runID, nil
 	}
 
@@ -223,10 +274,11 @@ func (d *DesiredState) CreateNextRun(_ context.Context, taskID platform.ID, now
 	if err != nil {
 		return backend.RunCreation{}, err
 	}
-	d.meta[tid] = meta
+	d.meta[taskID] = meta
 	rc.Created.TaskID = taskID
-	d.created[tid+rc.Created.RunID.String()] = rc.Created
+	d.created[taskrun{t: taskID, r: rc.Created.RunID}] = rc.Created
 	d.totalRunsCreated[taskID]++
+	d.notifyChanged()
 	return rc, nil
 }
 
@@ -234,18 +286,17 @@ func (d *DesiredState) FinishRun(_ context.Context, taskID, runID platform.ID) e
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	tid := taskID.String()
-	rid := runID.String()
-	m := d.meta[tid]
+	m := d.meta[taskID]
 	if !m.FinishRun(runID) {
 		var knownIDs []string
 		for _, r := range m.CurrentlyRunning {
 			knownIDs = append(knownIDs, platform.ID(r.RunID).String())
 		}
-		return fmt.Errorf("unknown run ID %s; known run IDs: %s", rid, strings.Join(knownIDs, ", "))
+		return backend.NotFoundError(fmt.Errorf("unknown run ID %s; known run IDs: %s", runID, strings.Join(knownIDs, ", ")))
 	}
-	d.meta[tid] = m
-	delete(d.created, tid+rid)
+	d.meta[taskID] = m
+	delete(d.created, taskrun{t: taskID, r: runID})
+	d.notifyChanged()
 	return nil
 }
 
@@ -253,6 +304,12 @@ func (d *DesiredState) CreatedFor(taskID platform.ID) []backend.QueuedRun {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	return d.createdFor(taskID)
+}
+
+// createdFor returns the created and unfinished runs for taskID.
+// d.mu must be held.
+func (d *DesiredState) createdFor(taskID platform.ID) []backend.QueuedRun {
 	var qrs []backend.QueuedRun
 	for _, qr := range d.created {
 		if qr.TaskID == taskID {
@@ -271,34 +328,49 @@ func (d *DesiredState) TotalRunsCreatedForTask(taskID platform.ID) int {
 	return d.totalRunsCreated[taskID]
 }
 
-// PollForNumberCreated blocks for a small amount of time waiting for exactly the given count of created and unfinished runs for the given task ID.
-// If the expected number isn't found in time, it returns an error.
+// DefaultPollTimeout is how long PollForNumberCreated waits when its context has no deadline.
+const DefaultPollTimeout = 100 * time.Millisecond
+
+// PollForNumberCreated blocks until there are exactly the given count of created and unfinished runs for the given task ID,
+// waking up whenever a run is created or finished.
+// If the expected number isn't found before ctx is done, or before DefaultPollTimeout if ctx has no deadline, it returns an error.
 //
 // Because the scheduler and executor do a lot of state changes asynchronously, this is useful in test.
-func (d *DesiredState) PollForNumberCreated(taskID platform.ID, count int) ([]scheduler.QueuedRun, error) {
-	const numAttempts = 50
-	actualCount := 0
-	var created []scheduler.QueuedRun
-	for i := 0; i < numAttempts; i++ {
-		time.Sleep(2 * time.Millisecond) // we sleep even on first so it becomes more likely that we catch when too many are produced.
-		created = d.CreatedFor(taskID)
-		actualCount = len(created)
-		if actualCount == count {
+func (d *DesiredState) PollForNumberCreated(ctx context.Context, taskID platform.ID, count int) ([]scheduler.QueuedRun, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultPollTimeout)
+		defer cancel()
+	}
+
+	for {
+		d.mu.Lock()
+		created := d.createdFor(taskID)
+		changed := d.changed
+		d.mu.Unlock()
+
+		if len(created) == count {
 			return created, nil
 		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return created, fmt.Errorf("did not see count of %d created run(s) for task with ID %s in time, instead saw %d", count, taskID.String(), len(created)) // we return created anyways, to make it easier to debug
+		}
 	}
-	return created, fmt.Errorf("did not see count of %d created run(s) for task with ID %s in time, instead saw %d", count, taskID.String(), actualCount) // we return created anyways, to make it easier to debug
 }
 
 type Executor struct {
 	mu         sync.Mutex
 	hangingFor time.Duration
+	clock      backend.Clock
 
-	// Map of stringified, concatenated task and run ID, to runs that have begun execution but have not finished.
-	running map[string]*RunPromise
+	// Map of task and run ID to runs that have begun execution but have not finished.
+	running map[taskrun]*RunPromise
 
-	// Map of stringified, concatenated task and run ID, to results of runs that have executed and completed.
-	finished map[string]backend.RunResult
+	// Map of task and run ID to results of runs that have executed and completed.
+	finished map[taskrun]backend.RunResult
 
 	// Forced error for next call to Execute.
 	nextExecuteErr error
@@ -310,15 +382,17 @@ var _ backend.Executor = (*Executor)(nil)
 
 func NewExecutor() *Executor {
 	return &Executor{
-		running:  make(map[string]*RunPromise),
-		finished: make(map[string]backend.RunResult),
+		running:  make(map[taskrun]*RunPromise),
+		finished: make(map[taskrun]backend.RunResult),
+		clock:    backend.SystemClock{},
 	}
 }
 
 func (e *Executor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
 	rp := NewRunPromise(run)
+	rp.clock = e.clock
 	rp.WithHanging(ctx, e.hangingFor)
-	id := run.TaskID.String() + run.RunID.String()
+	id := taskrun{t: run.TaskID, r: run.RunID}
 	e.mu.Lock()
 	if err := e.nextExecuteErr; err != nil {
 		e.nextExecuteErr = nil
@@ -354,6 +428,11 @@ func (e *Executor) WithHanging(dt time.Duration) {
 	e.hangingFor = dt
 }
 
+// WithClock sets the clock measuring how long runs hang, so that hanging runs can be released by advancing a virtual clock.
+func (e *Executor) WithClock(c backend.Clock) {
+	e.clock = c
+}
+
 // RunningFor returns the run promises for the given task.
 func (e *Executor) RunningFor(taskID platform.ID) []*RunPromise {
 	e.mu.Lock()
@@ -394,6 +473,7 @@ type RunPromise struct {
 
 	setResultOnce sync.Once
 	hangingFor    time.Duration
+	clock         backend.Clock
 	cancelFunc    context.CancelFunc
 	ctx           context.Context
 	mu            sync.Mutex
@@ -405,7 +485,8 @@ var _ backend.RunPromise = (*RunPromise)(nil)
 
 func NewRunPromise(qr backend.QueuedRun) *RunPromise {
 	p := &RunPromise{
-		qr: qr,
+		qr:    qr,
+		clock: backend.SystemClock{},
 	}
 	p.mu.Lock() // Locked so calls to Wait will block until setResultOnce is called.
 	return p
@@ -426,7 +507,7 @@ func (p *RunPromise) Wait() (backend.RunResult, error) {
 	if p.ctx != nil {
 		select {
 		case <-p.ctx.Done():
-		case <-time.After(p.hangingFor):
+		case <-p.clock.After(p.hangingFor):
 		}
 		p.cancelFunc()
 	}
@@ -0,0 +1,352 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// TaskControlMethod names a method of TaskControlService whose calls can be made to fail.
+type TaskControlMethod string
+
+// The methods of TaskControlService whose calls can be made to fail.
+const (
+	CreateNextRunMethod  TaskControlMethod = "CreateNextRun"
+	FinishRunMethod      TaskControlMethod = "FinishRun"
+	UpdateRunStateMethod TaskControlMethod = "UpdateRunState"
+)
+
+// TaskControlService is a mock implementation of backend.TaskControlService.
+// Runs are created and finished through a DesiredState, and run states and logs are recorded in memory.
+//
+// Calls to CreateNextRun, FinishRun and UpdateRunState can be made to fail with FailTask and FailEveryNth,
+// so that the error paths of the scheduler and executor can be exercised deterministically.
+// The errors forced may be of any backend.ErrorKind, such as those of backend.RetryableError.
+//
+// The errors of its own are classified like those of a store: it returns backend.ErrTaskNotFound
+// for a task whose meta was never set, a not found error for a run that is not running,
+// and a conflict error for a run created over the concurrency of its task.
+type TaskControlService struct {
+	*DesiredState
+
+	mu sync.Mutex
+
+	// Map of task and run ID to the states and logs recorded for the run.
+	states map[taskrun][]backend.RunStatus
+	logs   map[taskrun][]string
+
+	// Forced errors for calls of a method for a task ID.
+	taskErrs map[TaskControlMethod]map[platform.ID]error
+
+	// Forced errors for every nth call of a method, and the number of calls of the method since they were set.
+	nthErrs map[TaskControlMethod]nthError
+	calls   map[TaskControlMethod]int
+}
+
+type nthError struct {
+	n   int
+	err error
+}
+
+var _ backend.TaskControlService = (*TaskControlService)(nil)
+
+// NewTaskControlService returns a TaskControlService creating and finishing runs through d.
+func NewTaskControlService(d *DesiredState) *TaskControlService {
+	return &TaskControlService{
+		DesiredState: d,
+		states:       make(map[taskrun][]backend.RunStatus),
+		logs:         make(map[taskrun][]string),
+		taskErrs:     make(map[TaskControlMethod]map[platform.ID]error),
+		nthErrs:      make(map[TaskControlMethod]nthError),
+		calls:        make(map[TaskControlMethod]int),
+	}
+}
+
+// FailTask causes every call of m for taskID to return err, until FailTask is called again with a nil err.
+func (s *TaskControlService) FailTask(m TaskControlMethod, taskID platform.ID, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.taskErrs[m], taskID)
+		return
+	}
+	if s.taskErrs[m] == nil {
+		s.taskErrs[m] = make(map[platform.ID]error)
+	}
+	s.taskErrs[m][taskID] = err
+}
+
+// FailEveryNth causes every nth call of m, counted from this call, to return err.
+// Calling FailEveryNth with n less than 1 or a nil err stops the failures.
+func (s *TaskControlService) FailEveryNth(m TaskControlMethod, n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[m] = 0
+	if n < 1 || err == nil {
+		delete(s.nthErrs, m)
+		return
+	}
+	s.nthErrs[m] = nthError{n: n, err: err}
+}
+
+// injectedError counts a call of m for taskID, and returns the error the call is forced to fail with, if any.
+func (s *TaskControlService) injectedError(m TaskControlMethod, taskID platform.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[m]++
+	if err, ok := s.taskErrs[m][taskID]; ok {
+		return err
+	}
+	if e, ok := s.nthErrs[m]; ok && s.calls[m]%e.n == 0 {
+		return e.err
+	}
+	return nil
+}
+
+// CreateNextRun creates the next run for the given task, unless the call is forced to fail.
+func (s *TaskControlService) CreateNextRun(ctx context.Context, taskID platform.ID, now int64) (backend.RunCreation, error) {
+	if err := s.injectedError(CreateNextRunMethod, taskID); err != nil {
+		return backend.RunCreation{}, err
+	}
+	if !s.hasTask(taskID) {
+		return backend.RunCreation{}, backend.ErrTaskNotFound
+	}
+	return s.DesiredState.CreateNextRun(ctx, taskID, now)
+}
+
+// FinishRun finishes the given run, unless the call is forced to fail.
+func (s *TaskControlService) FinishRun(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	if err := s.injectedError(FinishRunMethod, taskID); err != nil {
+		return nil, err
+	}
+	if err := s.DesiredState.FinishRun(ctx, taskID, runID); err != nil {
+		return nil, err
+	}
+	return &platform.Run{ID: runID, TaskID: taskID}, nil
+}
+
+// NextDueRun returns the Unix timestamp of when the next call to CreateNextRun will be ready.
+func (s *TaskControlService) NextDueRun(_ context.Context, taskID platform.ID) (int64, error) {
+	s.DesiredState.mu.Lock()
+	meta, ok := s.DesiredState.meta[taskID]
+	s.DesiredState.mu.Unlock()
+
+	if !ok {
+		return 0, backend.ErrTaskNotFound
+	}
+	return meta.NextDueRun()
+}
+
+// hasTask returns true if the meta of taskID was set.
+func (s *TaskControlService) hasTask(taskID platform.ID) bool {
+	s.DesiredState.mu.Lock()
+	defer s.DesiredState.mu.Unlock()
+	_, ok := s.DesiredState.meta[taskID]
+	return ok
+}
+
+// UpdateRunState records the state of the given run, unless the call is forced to fail.
+// It returns backend.ErrRunNotFound if the run was never created,
+// and a backend.InvalidRunTransitionError if the run cannot go from its current state to state.
+func (s *TaskControlService) UpdateRunState(_ context.Context, taskID, runID platform.ID, _ time.Time, state backend.RunStatus) error {
+	if err := s.injectedError(UpdateRunStateMethod, taskID); err != nil {
+		return err
+	}
+
+	id := taskrun{t: taskID, r: runID}
+	s.DesiredState.mu.Lock()
+	_, created := s.DesiredState.created[id]
+	s.DesiredState.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := s.states[id]
+	// A finished run is no longer in the desired state, but its states are still recorded.
+	if !created && len(states) == 0 {
+		return backend.ErrRunNotFound
+	}
+
+	current := backend.RunScheduled
+	if len(states) > 0 {
+		current = states[len(states)-1]
+	}
+	if err := backend.ValidateRunTransition(current, state); err != nil {
+		return err
+	}
+
+	s.states[id] = append(states, state)
+	return nil
+}
+
+// AddRunLog records a log line of the given run.
+func (s *TaskControlService) AddRunLog(_ context.Context, taskID, runID platform.ID, _ time.Time, log string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := taskrun{t: taskID, r: runID}
+	s.logs[id] = append(s.logs[id], log)
+	return nil
+}
+
+// RunStates returns the states recorded for the given run, in the order they were recorded.
+func (s *TaskControlService) RunStates(taskID, runID platform.ID) []backend.RunStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]backend.RunStatus(nil), s.states[taskrun{t: taskID, r: runID}]...)
+}
+
+// RunLogs returns the log lines recorded for the given run, in the order they were recorded.
+func (s *TaskControlService) RunLogs(taskID, runID platform.ID) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.logs[taskrun{t: taskID, r: runID}]...)
+}
+
+// TaskControlSnapshot is the state of a TaskControlService captured by Snapshot.
+type TaskControlSnapshot struct {
+	meta             map[platform.ID]backend.StoreTaskMeta
+	runIDs           map[platform.ID]uint64
+	created          map[taskrun]backend.QueuedRun
+	totalRunsCreated map[platform.ID]int
+
+	states map[taskrun][]backend.RunStatus
+	logs   map[taskrun][]string
+
+	taskErrs map[TaskControlMethod]map[platform.ID]error
+	nthErrs  map[TaskControlMethod]nthError
+	calls    map[TaskControlMethod]int
+}
+
+// Snapshot captures the whole state of s: the meta of the tasks, the IDs and number of runs created for them,
+// the runs created and not yet finished, the states and logs recorded for the runs, and the forced errors.
+func (s *TaskControlService) Snapshot() *TaskControlSnapshot {
+	s.DesiredState.mu.Lock()
+	defer s.DesiredState.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := &TaskControlSnapshot{
+		meta:             s.DesiredState.meta,
+		runIDs:           s.DesiredState.runIDs,
+		created:          s.DesiredState.created,
+		totalRunsCreated: s.DesiredState.totalRunsCreated,
+		states:           s.states,
+		logs:             s.logs,
+		taskErrs:         s.taskErrs,
+		nthErrs:          s.nthErrs,
+		calls:            s.calls,
+	}
+	return snap.copy()
+}
+
+// Restore resets s to the state captured in snap, waking up the calls to PollForNumberCreated.
+// The same snapshot can be restored any number of times, such as between the cases of a table-driven test.
+func (s *TaskControlService) Restore(snap *TaskControlSnapshot) {
+	snap = snap.copy()
+
+	s.DesiredState.mu.Lock()
+	defer s.DesiredState.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.DesiredState.meta = snap.meta
+	s.DesiredState.runIDs = snap.runIDs
+	s.DesiredState.created = snap.created
+	s.DesiredState.totalRunsCreated = snap.totalRunsCreated
+	s.states = snap.states
+	s.logs = snap.logs
+	s.taskErrs = snap.taskErrs
+	s.nthErrs = snap.nthErrs
+	s.calls = snap.calls
+	s.DesiredState.notifyChanged()
+}
+
+// copy returns a deep copy of snap, so that neither snap nor the copy are changed by changes to the other.
+func (snap *TaskControlSnapshot) copy() *TaskControlSnapshot {
+	c := &TaskControlSnapshot{
+		meta:             make(map[platform.ID]backend.StoreTaskMeta, len(snap.meta)),
+		runIDs:           make(map[platform.ID]uint64, len(snap.runIDs)),
+		created:          make(map[taskrun]backend.QueuedRun, len(snap.created)),
+		totalRunsCreated: make(map[platform.ID]int, len(snap.totalRunsCreated)),
+		states:           make(map[taskrun][]backend.RunStatus, len(snap.states)),
+		logs:             make(map[taskrun][]string, len(snap.logs)),
+		taskErrs:         make(map[TaskControlMethod]map[platform.ID]error, len(snap.taskErrs)),
+		nthErrs:          make(map[TaskControlMethod]nthError, len(snap.nthErrs)),
+		calls:            make(map[TaskControlMethod]int, len(snap.calls)),
+	}
+	for id, m := range snap.meta {
+		// The meta holds pointers to its runs, which are copied as well.
+		c.meta[id] = *proto.Clone(&m).(*backend.StoreTaskMeta)
+	}
+	for id, n := range snap.runIDs {
+		c.runIDs[id] = n
+	}
+	for k, qr := range snap.created {
+		c.created[k] = qr
+	}
+	for id, n := range snap.totalRunsCreated {
+		c.totalRunsCreated[id] = n
+	}
+	for k, states := range snap.states {
+		c.states[k] = append([]backend.RunStatus(nil), states...)
+	}
+	for k, logs := range snap.logs {
+		c.logs[k] = append([]string(nil), logs...)
+	}
+	for m, errs := range snap.taskErrs {
+		c.taskErrs[m] = make(map[platform.ID]error, len(errs))
+		for id, err := range errs {
+			c.taskErrs[m][id] = err
+		}
+	}
+	for m, e := range snap.nthErrs {
+		c.nthErrs[m] = e
+	}
+	for m, n := range snap.calls {
+		c.calls[m] = n
+	}
+	return c
+}
+
+// AsDesiredState returns s as a backend.DesiredState, for use by NewScheduler.
+func (s *TaskControlService) AsDesiredState() backend.DesiredState {
+	return desiredStateAdaptor{s}
+}
+
+// AsLogWriter returns s as a backend.LogWriter, for use by NewScheduler.
+func (s *TaskControlService) AsLogWriter() backend.LogWriter {
+	return logWriterAdaptor{s}
+}
+
+// desiredStateAdaptor adapts a TaskControlService to implement backend.DesiredState.
+type desiredStateAdaptor struct {
+	s *TaskControlService
+}
+
+func (a desiredStateAdaptor) CreateNextRun(ctx context.Context, taskID platform.ID, now int64) (backend.RunCreation, error) {
+	return a.s.CreateNextRun(ctx, taskID, now)
+}
+
+func (a desiredStateAdaptor) FinishRun(ctx context.Context, taskID, runID platform.ID) error {
+	_, err := a.s.FinishRun(ctx, taskID, runID)
+	return err
+}
+
+// logWriterAdaptor adapts a TaskControlService to implement backend.LogWriter.
+type logWriterAdaptor struct {
+	s *TaskControlService
+}
+
+func (a logWriterAdaptor) UpdateRunState(ctx context.Context, base backend.RunLogBase, when time.Time, state backend.RunStatus) error {
+	return a.s.UpdateRunState(ctx, base.Task.ID, base.RunID, when, state)
+}
+
+func (a logWriterAdaptor) AddRunLog(ctx context.Context, base backend.RunLogBase, when time.Time, log string) error {
+	return a.s.AddRunLog(ctx, base.Task.ID, base.RunID, when, log)
+}
@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/pkg/pointer"
+	"github.com/influxdata/influxdb/task/include"
 	cron "gopkg.in/robfig/cron.v2"
 )
 
@@ -29,6 +32,7 @@ func EnableScriptCacheForTest() {
 
 const maxConcurrency = 100
 const maxRetry = 10
+const maxWeight = 100
 
 // Options are the task-related options that can be specified in a Flux script.
 type Options struct {
@@ -49,6 +53,14 @@ type Options struct {
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
 	Retry *int64 `json:"retry,omitempty"`
+
+	// Weight is the share of its organization's run capacity a task gets relative to the other tasks of the organization,
+	// when their runs are waiting to execute. Unset, the weight is 1.
+	Weight *int64 `json:"weight,omitempty"`
+
+	// Timezone is the IANA time zone the Cron schedule is interpreted in, i.e.: "America/New_York".
+	// Unset, the schedule is interpreted in UTC.
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // Clear clears out all options in the options struct, it us useful if you wish to reuse it.
@@ -59,6 +71,8 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.Weight = nil
+	o.Timezone = ""
 }
 
 func (o *Options) IsZero() bool {
@@ -67,7 +81,17 @@ func (o *Options) IsZero() bool {
 		o.Every == 0 &&
 		o.Offset == nil &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.Weight == nil &&
+		o.Timezone == ""
+}
+
+// EffectiveWeight returns the weight of the task, which is 1 if the weight option is not set.
+func (o *Options) EffectiveWeight() int64 {
+	if o.Weight == nil {
+		return 1
+	}
+	return *o.Weight
 }
 
 // All the task option names we accept.
@@ -78,9 +102,30 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optWeight      = "weight"
+	optTimezone    = "timezone"
 )
 
+// optionStatements returns the imports and option statements of script.
+func optionStatements(script string) (string, error) {
+	pkg := parser.ParseSource(script)
+	if err := ast.GetError(pkg); err != nil {
+		return "", err
+	}
+	file := pkg.Files[0]
+
+	var body []ast.Statement
+	for _, st := range file.Body {
+		if _, ok := st.(*ast.OptionStatement); ok {
+			body = append(body, st)
+		}
+	}
+	file.Body = body
+	return ast.Format(file), nil
+}
+
 // FromScript extracts Options from a Flux script.
+// The options of a script with include directives are evaluated without the rest of the script.
 func FromScript(script string) (Options, error) {
 	if optionCache != nil {
 		optionCacheMu.Lock()
@@ -93,7 +138,17 @@ func FromScript(script string) (Options, error) {
 	}
 	opt := Options{Retry: pointer.Int64(1), Concurrency: pointer.Int64(1)}
 
-	_, scope, err := flux.Eval(script)
+	// The body of a script with includes may refer to identifiers declared in the included scripts,
+	// which are only resolved when the task executes: evaluate its options alone.
+	src := script
+	if include.Has(script) {
+		var err error
+		if src, err = optionStatements(script); err != nil {
+			return opt, err
+		}
+	}
+
+	_, scope, err := flux.Eval(src)
 	if err != nil {
 		return opt, err
 	}
@@ -162,6 +217,20 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = pointer.Int64(retryVal.Int())
 	}
 
+	if weightVal, ok := optObject.Get(optWeight); ok {
+		if err := checkNature(weightVal.PolyType().Nature(), semantic.Int); err != nil {
+			return opt, err
+		}
+		opt.Weight = pointer.Int64(weightVal.Int())
+	}
+
+	if timezoneVal, ok := optObject.Get(optTimezone); ok {
+		if err := checkNature(timezoneVal.PolyType().Nature(), semantic.String); err != nil {
+			return opt, err
+		}
+		opt.Timezone = timezoneVal.Str()
+	}
+
 	if err := opt.Validate(); err != nil {
 		return opt, err
 	}
@@ -181,7 +250,51 @@ func (o *Options) Validate() error {
 	if o.Name == "" {
 		errs = append(errs, "name required")
 	}
+	errs = append(errs, o.scheduleErrors()...)
 
+	if o.Concurrency != nil {
+		if *o.Concurrency < 1 {
+			errs = append(errs, "concurrency must be at least 1")
+		} else if *o.Concurrency > maxConcurrency {
+			errs = append(errs, fmt.Sprintf("concurrency exceeded max of %d", maxConcurrency))
+		}
+	}
+	if o.Retry != nil {
+		if *o.Retry < 1 {
+			errs = append(errs, "retry must be at least 1")
+		} else if *o.Retry > maxRetry {
+			errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
+		}
+	}
+	if o.Weight != nil {
+		if *o.Weight < 1 {
+			errs = append(errs, "weight must be at least 1")
+		} else if *o.Weight > maxWeight {
+			errs = append(errs, fmt.Sprintf("weight exceeded max of %d", maxWeight))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid options: %s", strings.Join(errs, ", "))
+}
+
+// ValidateSchedule returns an error if the cron, every and offset options don't make a valid schedule.
+// The other options are not considered.
+func (o *Options) ValidateSchedule() error {
+	errs := o.scheduleErrors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid options: %s", strings.Join(errs, ", "))
+}
+
+// scheduleErrors returns the problems with the schedule options.
+func (o *Options) scheduleErrors() []string {
+	var errs []string
 	cronPresent := o.Cron != ""
 	everyPresent := o.Every != 0
 	if cronPresent == everyPresent {
@@ -204,35 +317,33 @@ func (o *Options) Validate() error {
 		// For now, allowing negative offset delays. Maybe they're useful for forecasting?
 		errs = append(errs, "offset option must be expressible as whole seconds")
 	}
-	if o.Concurrency != nil {
-		if *o.Concurrency < 1 {
-			errs = append(errs, "concurrency must be at least 1")
-		} else if *o.Concurrency > maxConcurrency {
-			errs = append(errs, fmt.Sprintf("concurrency exceeded max of %d", maxConcurrency))
+
+	if o.Timezone != "" {
+		if !cronPresent {
+			errs = append(errs, "timezone option requires the cron option")
+		} else if strings.HasPrefix(o.Cron, "TZ=") {
+			errs = append(errs, "cannot use both timezone option and TZ= in cron")
 		}
-	}
-	if o.Retry != nil {
-		if *o.Retry < 1 {
-			errs = append(errs, "retry must be at least 1")
-		} else if *o.Retry > maxRetry {
-			errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
+		// Local depends on the host, so it is not a valid time zone for a task.
+		if _, err := time.LoadLocation(o.Timezone); err != nil || o.Timezone == "Local" {
+			errs = append(errs, fmt.Sprintf("timezone invalid: unknown time zone %q", o.Timezone))
 		}
 	}
 
-	if len(errs) == 0 {
-		return nil
-	}
-
-	return fmt.Errorf("invalid options: %s", strings.Join(errs, ", "))
+	return errs
 }
 
 // EffectiveCronString returns the effective cron string of the options.
-// If the cron option was specified, it is returned.
+// If the cron option was specified, it is returned,
+// prefixed with "TZ=" and the timezone option if that was specified too.
 // If the every option was specified, it is converted into a cron string using "@every".
 // Otherwise, the empty string is returned.
 // The value of the offset option is not considered.
 func (o *Options) EffectiveCronString() string {
 	if o.Cron != "" {
+		if o.Timezone != "" {
+			return "TZ=" + o.Timezone + " " + o.Cron
+		}
 		return o.Cron
 	}
 	if o.Every > 0 {
@@ -255,7 +366,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optWeight, optTimezone:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -264,7 +375,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optWeight, optTimezone}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
@@ -33,6 +33,12 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != nil && *opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, *opt.Retry)
 	}
+	if opt.Weight != nil && *opt.Weight != 0 {
+		taskData = fmt.Sprintf("%s  weight: %d,\n", taskData, *opt.Weight)
+	}
+	if opt.Timezone != "" {
+		taskData = fmt.Sprintf("%s  timezone: %q,\n", taskData, opt.Timezone)
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -61,6 +67,11 @@ func TestFromScript(t *testing.T) {
 		{script: scriptGenerator(options.Options{Name: "name7", Retry: pointer.Int64(20), Every: time.Hour}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name8\",\n  retry: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name9"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name10", Every: time.Hour, Weight: pointer.Int64(5)}, ""), exp: options.Options{Name: "name10", Every: time.Hour, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1), Weight: pointer.Int64(5)}},
+		{script: scriptGenerator(options.Options{Name: "name11", Every: time.Hour, Weight: pointer.Int64(1000)}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name12", Cron: "0 9 * * *", Timezone: "America/New_York"}, ""), exp: options.Options{Name: "name12", Cron: "0 9 * * *", Concurrency: pointer.Int64(1), Retry: pointer.Int64(1), Timezone: "America/New_York"}},
+		{script: scriptGenerator(options.Options{Name: "name13", Cron: "0 9 * * *", Timezone: "Mars/Olympus_Mons"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name14", Every: time.Hour, Timezone: "America/New_York"}, ""), shouldErr: true},
 		{script: scriptGenerator(options.Options{}, ""), shouldErr: true},
 	} {
 		o, err := options.FromScript(c.script)
@@ -97,7 +108,7 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 		t.Errorf("expected error to mention unrecognized options, but it said: %v", err)
 	}
 
-	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry"}
+	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry", "weight", "timezone"}
 	for _, o := range validOpts {
 		if !strings.Contains(msg, o) {
 			t.Errorf("expected error to mention valid option %q but it said: %v", o, err)
@@ -105,6 +116,31 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 	}
 }
 
+func TestFromScriptWithIncludes(t *testing.T) {
+	// helper is declared in the included script, so only the options can be evaluated.
+	script := `// @include "helpers"
+option task = {name: "x", every: 1m, concurrency: 2}
+
+from(bucket: "b") |> range(start: -1m) |> helper()`
+
+	o, err := options.FromScript(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Name != "x" || o.Every != time.Minute || *o.Concurrency != 2 {
+		t.Errorf("unexpected options %+v", o)
+	}
+
+	if _, err := options.FromScript("// @include \"helpers\"\nhelper()"); err == nil {
+		t.Error("expected error for script with includes and no task option")
+	}
+
+	// Without includes, undeclared identifiers are still errors.
+	if _, err := options.FromScript("option task = {name: \"x\", every: 1m}\nhelper()"); err == nil {
+		t.Error("expected error for undeclared identifier")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	good := options.Options{Name: "x", Cron: "* * * * *", Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}
 	if err := good.Validate(); err != nil {
@@ -172,19 +208,71 @@ func TestValidate(t *testing.T) {
 	if err := bad.Validate(); err == nil {
 		t.Error("expected error for retry too large")
 	}
+
+	*bad = good
+	bad.Weight = pointer.Int64(0)
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for 0 weight")
+	}
+
+	*bad = good
+	bad.Weight = pointer.Int64(math.MaxInt64)
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for weight too large")
+	}
+
+	*bad = good
+	bad.Timezone = "Local"
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for host-dependent timezone")
+	}
+
+	*bad = good
+	bad.Cron = "TZ=Europe/Paris * * * * *"
+	bad.Timezone = "Europe/Paris"
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for timezone in both options")
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	// Only the schedule options are required.
+	good := options.Options{Every: time.Hour, Offset: pointer.Duration(5 * time.Minute)}
+	if err := good.ValidateSchedule(); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := good
+	bad.Cron = "* * * * *"
+	if err := bad.ValidateSchedule(); err == nil {
+		t.Error("expected error for schedule with both cron and every")
+	}
+
+	bad = options.Options{Cron: "not a cron string"}
+	if err := bad.ValidateSchedule(); err == nil {
+		t.Error("expected error for schedule with invalid cron")
+	}
+
+	bad = good
+	bad.Offset = pointer.Duration(1500 * time.Millisecond)
+	if err := bad.ValidateSchedule(); err == nil {
+		t.Error("expected error for schedule with sub-second offset")
+	}
 }
 
 func TestEffectiveCronString(t *testing.T) {
 	for _, c := range []struct {
 		c   string
 		e   time.Duration
+		tz  string
 		exp string
 	}{
 		{c: "10 * * * *", exp: "10 * * * *"},
+		{c: "0 9 * * *", tz: "Asia/Kolkata", exp: "TZ=Asia/Kolkata 0 9 * * *"},
 		{e: 10 * time.Second, exp: "@every 10s"},
 		{exp: ""},
 	} {
-		o := options.Options{Cron: c.c, Every: c.e}
+		o := options.Options{Cron: c.c, Every: c.e, Timezone: c.tz}
 		got := o.EffectiveCronString()
 		if got != c.exp {
 			t.Fatalf("exp cron string %q, got %q for %v", c.exp, got, o)
@@ -19,8 +19,8 @@ type RunController interface {
 }
 
 // PlatformAdapter wraps a task.Store into the platform.TaskService interface.
-func PlatformAdapter(s backend.Store, r backend.LogReader, rc RunController, as platform.AuthorizationService, urm platform.UserResourceMappingService, orgSvc platform.OrganizationService) platform.TaskService {
-	return pAdapter{s: s, r: r, rc: rc, as: as, urm: urm, orgSvc: orgSvc}
+func PlatformAdapter(s backend.Store, r backend.LogReader, w backend.LogWriter, rc RunController, as platform.AuthorizationService, urm platform.UserResourceMappingService, orgSvc platform.OrganizationService) platform.TaskService {
+	return pAdapter{s: s, r: r, w: w, rc: rc, as: as, urm: urm, orgSvc: orgSvc}
 }
 
 type pAdapter struct {
@@ -28,6 +28,9 @@ type pAdapter struct {
 	rc RunController
 	r  backend.LogReader
 
+	// Needed to copy the run history of a task moved to another organization.
+	w backend.LogWriter
+
 	// Needed to look up authorization ID from token during create.
 	as     platform.AuthorizationService
 	urm    platform.UserResourceMappingService
@@ -155,6 +158,7 @@ func (p pAdapter) CreateTask(ctx context.Context, t platform.TaskCreate) (*platf
 		ScheduleAfter: scheduleAfter,
 		Status:        backend.TaskStatus(t.Status),
 		Script:        t.Flux,
+		Params:        t.Params,
 	}
 	req.AuthorizationID, err = p.authorizationIDFromToken(ctx, t.Token)
 	if err != nil {
@@ -169,11 +173,14 @@ func (p pAdapter) CreateTask(ctx context.Context, t platform.TaskCreate) (*platf
 		ID:              id,
 		Flux:            t.Flux,
 		Cron:            opts.Cron,
+		Timezone:        opts.Timezone,
+		EffectiveCron:   opts.EffectiveCronString(),
 		Name:            opts.Name,
 		OrganizationID:  org.ID,
 		Organization:    org.Name,
 		Status:          t.Status,
 		AuthorizationID: req.AuthorizationID,
+		Params:          t.Params,
 	}
 
 	if opts.Every != 0 {
@@ -219,6 +226,7 @@ func (p pAdapter) UpdateTask(ctx context.Context, id platform.ID, upd platform.T
 		req.Status = backend.TaskStatus(*upd.Status)
 	}
 	req.Options = upd.Options
+	req.Params = upd.Params
 
 	if upd.Token != "" {
 		req.AuthorizationID, err = p.authorizationIDFromToken(ctx, upd.Token)
@@ -329,7 +337,8 @@ func (p pAdapter) RetryRun(ctx context.Context, taskID, id platform.ID) (*platfo
 	}
 	t := scheduledTime.UTC().Unix()
 	requestedAt := time.Now().Unix()
-	m, err := p.s.ManuallyRunTimeRange(ctx, run.TaskID, t, t, requestedAt)
+	attempt := backend.NextAttempt(uint32(run.Attempt))
+	m, err := p.s.RetryRun(ctx, run.TaskID, t, requestedAt, attempt)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +348,7 @@ func (p pAdapter) RetryRun(ctx context.Context, taskID, id platform.ID) (*platfo
 		RequestedAt:  time.Unix(requestedAt, 0).Format(time.RFC3339),
 		Status:       backend.RunScheduled.String(),
 		ScheduledFor: run.ScheduledFor,
+		Attempt:      int(attempt),
 	}, nil
 }
 
@@ -357,9 +367,197 @@ func (p pAdapter) ForceRun(ctx context.Context, taskID platform.ID, scheduledFor
 		RequestedAt:  requestedAt.UTC().Format(time.RFC3339),
 		Status:       backend.RunScheduled.String(),
 		ScheduledFor: time.Unix(scheduledFor, 0).UTC().Format(time.RFC3339),
+		Attempt:      1,
 	}, nil
 }
 
+func (p pAdapter) FindTaskVersions(ctx context.Context, taskID platform.ID) ([]*platform.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	vs, err := p.s.ListTaskVersions(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*platform.TaskVersion, 0, len(vs))
+	for _, v := range vs {
+		versions = append(versions, &platform.TaskVersion{
+			TaskID:     taskID,
+			Version:    int(v.Version),
+			Flux:       v.Script,
+			ReplacedAt: time.Unix(v.ReplacedAt, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	return versions, nil
+}
+
+func (p pAdapter) RollbackTask(ctx context.Context, taskID platform.ID, version int) (*platform.Task, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	vs, err := p.s.ListTaskVersions(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var script string
+	for _, v := range vs {
+		if int(v.Version) == version {
+			script = v.Script
+			break
+		}
+	}
+	if script == "" {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  fmt.Sprintf("task version %d not found", version),
+		}
+	}
+
+	// Updating through the store lets it record the current script as a new version,
+	// and lets a coordinating store hand the restored script to the scheduler.
+	if _, err := p.s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: taskID, Script: script}); err != nil {
+		return nil, err
+	}
+	return p.FindTaskByID(ctx, taskID)
+}
+
+func (p pAdapter) MoveTask(ctx context.Context, taskID platform.ID, move platform.TaskMove) (*platform.Task, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := move.Validate(); err != nil {
+		return nil, err
+	}
+
+	task, err := p.s.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Org == move.OrganizationID {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "task already belongs to organization",
+		}
+	}
+
+	if _, err := p.orgSvc.FindOrganizationByID(ctx, move.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	// The task keeps running with the authorization it is moved with,
+	// so that authorization must give it access to the destination organization.
+	authID, err := p.authorizationIDFromToken(ctx, move.Token)
+	if err != nil {
+		return nil, err
+	}
+	a, err := p.as.FindAuthorizationByID(ctx, authID)
+	if err != nil {
+		return nil, err
+	}
+	if a.OrgID != move.OrganizationID {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "authorization does not belong to the destination organization",
+		}
+	}
+
+	if move.MoveRuns {
+		if err := p.copyRuns(ctx, task, move.OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: taskID, Org: move.OrganizationID, AuthorizationID: authID}); err != nil {
+		return nil, err
+	}
+	return p.FindTaskByID(ctx, taskID)
+}
+
+// copyRuns writes the run history of task, logs included, under the organization orgID.
+func (p pAdapter) copyRuns(ctx context.Context, task *backend.StoreTask, orgID platform.ID) error {
+	moved := *task
+	moved.Org = orgID
+
+	filter := platform.RunFilter{Task: task.ID, Limit: platform.TaskMaxPageSize}
+	for {
+		runs, err := p.r.ListRuns(ctx, task.Org, filter)
+		if err != nil {
+			return err
+		}
+		for _, r := range runs {
+			if err := p.copyRun(ctx, moved, r); err != nil {
+				return err
+			}
+		}
+		if len(runs) < filter.Limit {
+			return nil
+		}
+		last := runs[len(runs)-1].ID
+		filter.After = &last
+	}
+}
+
+func (p pAdapter) copyRun(ctx context.Context, task backend.StoreTask, r *platform.Run) error {
+	rlb := backend.RunLogBase{Task: &task, RunID: r.ID}
+	scheduledFor, err := time.Parse(time.RFC3339, r.ScheduledFor)
+	if err != nil {
+		return err
+	}
+	rlb.RunScheduledFor = scheduledFor.Unix()
+	if r.RequestedAt != "" {
+		requestedAt, err := time.Parse(time.RFC3339, r.RequestedAt)
+		if err != nil {
+			return err
+		}
+		rlb.RequestedAt = requestedAt.Unix()
+	}
+
+	if r.StartedAt != "" {
+		startedAt, err := time.Parse(time.RFC3339Nano, r.StartedAt)
+		if err != nil {
+			return err
+		}
+		if err := p.w.UpdateRunState(ctx, rlb, startedAt, backend.RunStarted); err != nil {
+			return err
+		}
+	}
+	for _, l := range r.Log {
+		when, err := time.Parse(time.RFC3339Nano, l.Time)
+		if err != nil {
+			return err
+		}
+		if err := p.w.AddRunLog(ctx, rlb, when, l.Message); err != nil {
+			return err
+		}
+	}
+	if r.FinishedAt != "" {
+		status, err := parseRunStatus(r.Status)
+		if err != nil {
+			return err
+		}
+		finishedAt, err := time.Parse(time.RFC3339Nano, r.FinishedAt)
+		if err != nil {
+			return err
+		}
+		if err := p.w.UpdateRunState(ctx, rlb, finishedAt, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRunStatus returns the final run status named s.
+func parseRunStatus(s string) (backend.RunStatus, error) {
+	for _, status := range []backend.RunStatus{backend.RunSuccess, backend.RunFail, backend.RunCanceled} {
+		if status.String() == s {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected status %q for finished run", s)
+}
+
 func (p pAdapter) CancelRun(ctx context.Context, taskID, runID platform.ID) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -428,6 +626,9 @@ func (p *pAdapter) toPlatformTask(ctx context.Context, t backend.StoreTask, m *b
 		Name:           t.Name,
 		Flux:           t.Script,
 		Cron:           opts.Cron,
+		Timezone:       opts.Timezone,
+		EffectiveCron:  opts.EffectiveCronString(),
+		Params:         t.Params,
 	}
 	if opts.Every != 0 {
 		pt.Every = opts.Every.String()
@@ -5,9 +5,11 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	bolt "github.com/coreos/bbolt"
 	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/models"
 	_ "github.com/influxdata/influxdb/query/builtin"
 	"github.com/influxdata/influxdb/task/backend"
 	boltstore "github.com/influxdata/influxdb/task/backend/bolt"
@@ -30,7 +32,7 @@ func inMemFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
 		TaskControlService: servicetest.TaskControlAdaptor(st, lrw, lrw),
 		Ctx:                ctx,
 		I:                  i,
-		TaskService:        servicetest.UsePlatformAdaptor(st, lrw, mock.NewScheduler(), i),
+		TaskService:        servicetest.UsePlatformAdaptor(st, lrw, lrw, mock.NewScheduler(), i),
 	}, cancel
 }
 
@@ -64,12 +66,59 @@ func boltFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
 	i := inmem.NewService()
 	return &servicetest.System{
 		TaskControlService: servicetest.TaskControlAdaptor(st, lrw, lrw),
-		TaskService:        servicetest.UsePlatformAdaptor(st, lrw, mock.NewScheduler(), i),
+		TaskService:        servicetest.UsePlatformAdaptor(st, lrw, lrw, mock.NewScheduler(), i),
 		Ctx:                ctx,
 		I:                  i,
 	}, cancel
 }
 
+// pointLogWriterFactory is inMemFactory with the run states and logs also written by a PointLogWriter,
+// so that it validates the run state updates of the task service, including the copies of moved runs.
+func pointLogWriterFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
+	st := backend.NewInMemStore()
+	lrw := backend.NewInMemRunReaderWriter()
+	lw := teeLogWriter{backend.NewPointLogWriter(discardPointsWriter{}), lrw}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		st.Close()
+	}()
+
+	i := inmem.NewService()
+	return &servicetest.System{
+		TaskControlService: servicetest.TaskControlAdaptor(st, lw, lrw),
+		Ctx:                ctx,
+		I:                  i,
+		TaskService:        servicetest.UsePlatformAdaptor(st, lrw, lw, mock.NewScheduler(), i),
+	}, cancel
+}
+
+// teeLogWriter writes the run states and logs with each of its writers in turn, until one fails.
+type teeLogWriter []backend.LogWriter
+
+func (w teeLogWriter) UpdateRunState(ctx context.Context, base backend.RunLogBase, when time.Time, state backend.RunStatus) error {
+	for _, lw := range w {
+		if err := lw.UpdateRunState(ctx, base, when, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w teeLogWriter) AddRunLog(ctx context.Context, base backend.RunLogBase, when time.Time, log string) error {
+	for _, lw := range w {
+		if err := lw.AddRunLog(ctx, base, when, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type discardPointsWriter struct{}
+
+func (discardPointsWriter) WritePoints(context.Context, []models.Point) error { return nil }
+
 func TestTaskService(t *testing.T) {
 	t.Run("in-mem", func(t *testing.T) {
 		t.Parallel()
@@ -80,4 +129,9 @@ func TestTaskService(t *testing.T) {
 		t.Parallel()
 		servicetest.TestTaskService(t, boltFactory)
 	})
+
+	t.Run("point log writer", func(t *testing.T) {
+		t.Parallel()
+		servicetest.TestTaskService(t, pointLogWriterFactory)
+	})
 }
@@ -38,8 +38,8 @@ func init() {
 type BackendComponentFactory func(t *testing.T) (*System, context.CancelFunc)
 
 // UsePlatformAdaptor allows you to set the platform adaptor as your TaskService.
-func UsePlatformAdaptor(s backend.Store, lr backend.LogReader, rc task.RunController, i *inmem.Service) influxdb.TaskService {
-	return task.PlatformAdapter(s, lr, rc, i, i, i)
+func UsePlatformAdaptor(s backend.Store, lr backend.LogReader, lw backend.LogWriter, rc task.RunController, i *inmem.Service) influxdb.TaskService {
+	return task.PlatformAdapter(s, lr, lw, rc, i, i, i)
 }
 
 // TaskControlAdaptor creates a TaskControlService for the older TaskStore system.
@@ -79,24 +79,40 @@ func (tcs *taskControlAdaptor) UpdateRunState(ctx context.Context, taskID, runID
 	}
 	var (
 		schedFor, reqAt time.Time
+		attempt         uint32
+		current         = backend.RunScheduled
+		found           bool
 	)
 	// check the log store
 	r, err := tcs.lr.FindRunByID(ctx, st.Org, runID)
 	if err == nil {
 		schedFor, _ = time.Parse(time.RFC3339, r.ScheduledFor)
 		reqAt, _ = time.Parse(time.RFC3339, r.RequestedAt)
+		attempt = uint32(r.Attempt)
+		if current, err = backend.ParseRunStatus(r.Status); err != nil {
+			return err
+		}
+		found = true
 	}
 
 	// in the old system the log store may not have the run until after the first
 	// state update, so we will need to pull the currently running.
-	if schedFor.IsZero() {
+	if !found {
 		for _, cr := range m.CurrentlyRunning {
 			if influxdb.ID(cr.RunID) == runID {
 				schedFor = time.Unix(cr.Now, 0)
 				reqAt = time.Unix(cr.RequestedAt, 0)
+				attempt = cr.Try
+				found = true
 			}
 		}
+	}
+	if !found {
+		return backend.ErrRunNotFound
+	}
 
+	if err := backend.ValidateRunTransition(current, state); err != nil {
+		return err
 	}
 
 	rlb := backend.RunLogBase{
@@ -104,6 +120,7 @@ func (tcs *taskControlAdaptor) UpdateRunState(ctx context.Context, taskID, runID
 		RunID:           runID,
 		RunScheduledFor: schedFor.Unix(),
 		RequestedAt:     reqAt.Unix(),
+		Attempt:         attempt,
 	}
 
 	if err := tcs.lw.UpdateRunState(ctx, rlb, when, state); err != nil {
@@ -130,6 +147,7 @@ func (tcs *taskControlAdaptor) AddRunLog(ctx context.Context, taskID, runID infl
 		RunID:           runID,
 		RunScheduledFor: schFor.Unix(),
 		RequestedAt:     reqAt.Unix(),
+		Attempt:         uint32(r.Attempt),
 	}
 
 	return tcs.lw.AddRunLog(ctx, rlb, when, log)
@@ -164,6 +182,11 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory) {
 			testTaskRuns(t, sys)
 		})
 
+		t.Run("Task Run States", func(t *testing.T) {
+			t.Parallel()
+			testRunStates(t, sys)
+		})
+
 		t.Run("Task Concurrency", func(t *testing.T) {
 			if testing.Short() {
 				t.Skip("skipping in short mode")
@@ -176,6 +199,16 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory) {
 			t.Parallel()
 			testMetaUpdate(t, sys)
 		})
+
+		t.Run("Task Versions", func(t *testing.T) {
+			t.Parallel()
+			testTaskVersions(t, sys)
+		})
+
+		t.Run("Task Move", func(t *testing.T) {
+			t.Parallel()
+			testTaskMove(t, sys)
+		})
 	})
 }
 
@@ -481,6 +514,173 @@ from(bucket: "b")
 	}
 }
 
+func testTaskVersions(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	const script1 = `option task = {name: "task-versions", every: 1h}
+
+from(bucket: "b") |> range(start: -1h)`
+	const script2 = `option task = {name: "task-versions", every: 2h}
+
+from(bucket: "b") |> range(start: -1h)`
+
+	task, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           script1,
+		Token:          cr.Token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := sys.TaskService.FindTaskVersions(sys.Ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no versions for a new task, got %d", len(versions))
+	}
+
+	f := script2
+	if _, err := sys.TaskService.UpdateTask(authorizedCtx, task.ID, influxdb.TaskUpdate{Flux: &f}); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err = sys.TaskService.FindTaskVersions(sys.Ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version after update, got %d", len(versions))
+	}
+	if versions[0].Version != 1 || versions[0].Flux != script1 || versions[0].TaskID != task.ID {
+		t.Fatalf("unexpected version after update: %+v", versions[0])
+	}
+
+	rolledBack, err := sys.TaskService.RollbackTask(authorizedCtx, task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Flux != script1 || rolledBack.Every != "1h0m0s" {
+		t.Fatalf("expected task to be rolled back to version 1, got flux %q and every %q", rolledBack.Flux, rolledBack.Every)
+	}
+
+	// The rollback itself keeps the replaced script as a new version.
+	versions, err = sys.TaskService.FindTaskVersions(sys.Ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions after rollback, got %d", len(versions))
+	}
+	if versions[1].Version != 2 || versions[1].Flux != script2 {
+		t.Fatalf("unexpected version after rollback: %+v", versions[1])
+	}
+
+	if _, err := sys.TaskService.RollbackTask(authorizedCtx, task.ID, 99); err == nil {
+		t.Fatal("expected error rolling back to unknown version")
+	}
+}
+
+func testTaskMove(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	task, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 0),
+		Token:          cr.Token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := sys.TaskControlService.CreateNextRun(sys.Ctx, task.ID, time.Now().Add(5*time.Minute).UTC().Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startedAt := time.Now().UTC()
+	if err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, rc.Created.RunID, startedAt, backend.RunStarted); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.TaskControlService.AddRunLog(sys.Ctx, task.ID, rc.Created.RunID, startedAt, "a log line"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, rc.Created.RunID, startedAt.Add(time.Second), backend.RunSuccess); err != nil {
+		t.Fatal(err)
+	}
+
+	// The destination organization, with a token of its own for the task to run with.
+	o := &influxdb.Organization{Name: t.Name() + "-destination-org"}
+	if err := sys.I.CreateOrganization(sys.Ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	authz := &influxdb.Authorization{
+		OrgID:       o.ID,
+		UserID:      cr.UserID,
+		Permissions: influxdb.OperPermissions(),
+	}
+	if err := sys.I.CreateAuthorization(sys.Ctx, authz); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sys.TaskService.MoveTask(authorizedCtx, task.ID, influxdb.TaskMove{OrganizationID: cr.OrgID}); err == nil {
+		t.Fatal("expected error moving a task to its own organization")
+	}
+	if _, err := sys.TaskService.MoveTask(authorizedCtx, task.ID, influxdb.TaskMove{OrganizationID: o.ID}); err == nil {
+		t.Fatal("expected error moving a task with a token of the source organization")
+	}
+
+	moved, err := sys.TaskService.MoveTask(authorizedCtx, task.ID, influxdb.TaskMove{
+		OrganizationID: o.ID,
+		Token:          authz.Token,
+		MoveRuns:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.OrganizationID != o.ID {
+		t.Fatalf("expected task to be moved to org %s, got %s", o.ID, moved.OrganizationID)
+	}
+	if moved.AuthorizationID != authz.ID {
+		t.Fatalf("expected task to run with authorization %s, got %s", authz.ID, moved.AuthorizationID)
+	}
+
+	tasks, _, err := sys.TaskService.FindTasks(sys.Ctx, influxdb.TaskFilter{OrganizationID: &o.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected moved task to be listed in the destination org, got %v", tasks)
+	}
+	tasks, _, err = sys.TaskService.FindTasks(sys.Ctx, influxdb.TaskFilter{OrganizationID: &cr.OrgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("expected no task left in the source org, got %v", tasks)
+	}
+
+	runs, _, err := sys.TaskService.FindRuns(sys.Ctx, influxdb.RunFilter{Task: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ID != rc.Created.RunID {
+		t.Fatalf("expected run history to be moved, got %v", runs)
+	}
+	if runs[0].Status != backend.RunSuccess.String() {
+		t.Fatalf("expected moved run to keep its status, got %q", runs[0].Status)
+	}
+	logs, _, err := sys.TaskService.FindLogs(sys.Ctx, influxdb.LogFilter{Task: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Message != "a log line" {
+		t.Fatalf("expected run logs to be moved, got %v", logs)
+	}
+}
+
 func testMetaUpdate(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 
@@ -772,6 +972,9 @@ func testTaskRuns(t *testing.T, sys *System) {
 		if m.Status != "scheduled" {
 			t.Fatal("expected new retried run to have status of scheduled")
 		}
+		if m.Attempt != 2 {
+			t.Fatalf("expected retried run to be attempt 2, got %d", m.Attempt)
+		}
 		nowTime, err := time.Parse(time.RFC3339, m.ScheduledFor)
 		if err != nil {
 			t.Fatalf("expected scheduledFor to be a parsable time in RFC3339, but got %s", m.ScheduledFor)
@@ -892,6 +1095,51 @@ func testTaskRuns(t *testing.T, sys *System) {
 	})
 }
 
+func testRunStates(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	task, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 0),
+		Token:          cr.Token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := sys.TaskControlService.CreateNextRun(sys.Ctx, task.ID, time.Now().Add(5*time.Minute).UTC().Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runID := rc.Created.RunID
+	now := time.Now().UTC()
+
+	for _, tt := range []struct {
+		state   backend.RunStatus
+		invalid bool
+	}{
+		{state: backend.RunSuccess, invalid: true}, // Not started yet.
+		{state: backend.RunStarted},
+		{state: backend.RunStarted, invalid: true},
+		{state: backend.RunSuccess},
+		{state: backend.RunFail, invalid: true}, // Already finished.
+	} {
+		err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, runID, now, tt.state)
+		if _, ok := err.(backend.InvalidRunTransitionError); ok != tt.invalid {
+			t.Fatalf("updating the run to %s: expected an invalid transition: %v, got error %v", tt.state, tt.invalid, err)
+		}
+		if !tt.invalid && err != nil {
+			t.Fatalf("updating the run to %s: %v", tt.state, err)
+		}
+	}
+
+	unknownID := influxdb.ID(math.MaxUint64)
+	if err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, unknownID, now, backend.RunStarted); err != backend.ErrRunNotFound {
+		t.Fatalf("expected updating an unknown run to return %v, got %v", backend.ErrRunNotFound, err)
+	}
+}
+
 func testTaskConcurrency(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 
@@ -29,17 +29,34 @@ var ErrFailedPermission = errors.New("unauthorized")
 type taskServiceValidator struct {
 	platform.TaskService
 	preAuth query.PreAuthorizer
+	scripts platform.TaskScriptService
 	logger  *zap.Logger
 }
 
+// ValidatorOption configures the validator returned by NewValidator.
+type ValidatorOption func(*taskServiceValidator)
+
+// WithTaskScriptService sets the service that resolves the include directives of task scripts,
+// so that the buckets used by included scripts are validated too.
+// Without it, tasks that include other scripts are rejected.
+func WithTaskScriptService(s platform.TaskScriptService) ValidatorOption {
+	return func(ts *taskServiceValidator) {
+		ts.scripts = s
+	}
+}
+
 // TaskValidator wraps ts and checks appropriate permissions before calling requested methods on ts.
 // Authorization failures are logged to the logger.
-func NewValidator(logger *zap.Logger, ts platform.TaskService, bs platform.BucketService) platform.TaskService {
-	return &taskServiceValidator{
+func NewValidator(logger *zap.Logger, ts platform.TaskService, bs platform.BucketService, opts ...ValidatorOption) platform.TaskService {
+	v := &taskServiceValidator{
 		TaskService: ts,
 		preAuth:     query.NewPreAuthorizer(bs),
 		logger:      logger,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 func (ts *taskServiceValidator) FindTaskByID(ctx context.Context, id platform.ID) (*platform.Task, error) {
@@ -310,6 +327,117 @@ func (ts *taskServiceValidator) ForceRun(ctx context.Context, taskID platform.ID
 	return ts.TaskService.ForceRun(ctx, taskID, scheduledFor)
 }
 
+func (ts *taskServiceValidator) FindTaskVersions(ctx context.Context, taskID platform.ID) ([]*platform.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// Unauthenticated task lookup, to identify the task's organization.
+	task, err := ts.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := platform.NewPermissionAtID(taskID, platform.ReadAction, platform.TasksResourceType, task.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ts.validatePermission(ctx, *p,
+		zap.String("method", "FindTaskVersions"), zap.Stringer("task_id", taskID),
+	); err != nil {
+		return nil, err
+	}
+
+	return ts.TaskService.FindTaskVersions(ctx, taskID)
+}
+
+func (ts *taskServiceValidator) RollbackTask(ctx context.Context, taskID platform.ID, version int) (*platform.Task, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// Unauthenticated task lookup, to identify the task's organization.
+	task, err := ts.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := platform.NewPermissionAtID(taskID, platform.WriteAction, platform.TasksResourceType, task.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerFields := []zap.Field{zap.String("method", "RollbackTask"), zap.Stringer("task_id", taskID)}
+	if err := ts.validatePermission(ctx, *p, loggerFields...); err != nil {
+		return nil, err
+	}
+
+	// The restored script must only use the buckets the caller may use now.
+	versions, err := ts.TaskService.FindTaskVersions(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var target *platform.TaskVersion
+	for _, v := range versions {
+		if v.Version == version {
+			target = v
+			break
+		}
+	}
+	if target == nil {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  fmt.Sprintf("task version %d not found", version),
+		}
+	}
+
+	if err := ts.validateBucket(ctx, target.Flux, task.OrganizationID, loggerFields...); err != nil {
+		return nil, err
+	}
+
+	return ts.TaskService.RollbackTask(ctx, taskID, version)
+}
+
+func (ts *taskServiceValidator) MoveTask(ctx context.Context, taskID platform.ID, move platform.TaskMove) (*platform.Task, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := move.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Unauthenticated task lookup, to identify the task's organization.
+	task, err := ts.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerFields := []zap.Field{zap.String("method", "MoveTask"), zap.Stringer("task_id", taskID), zap.Stringer("org_id", move.OrganizationID)}
+
+	// Moving a task out of an organization is like deleting it there...
+	p, err := platform.NewPermissionAtID(taskID, platform.WriteAction, platform.TasksResourceType, task.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.validatePermission(ctx, *p, loggerFields...); err != nil {
+		return nil, err
+	}
+
+	// ...and moving it into the destination organization is like creating it there.
+	p, err = platform.NewPermission(platform.WriteAction, platform.TasksResourceType, move.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.validatePermission(ctx, *p, loggerFields...); err != nil {
+		return nil, err
+	}
+
+	if err := ts.validateBucket(ctx, task.Flux, move.OrganizationID, loggerFields...); err != nil {
+		return nil, err
+	}
+
+	return ts.TaskService.MoveTask(ctx, taskID, move)
+}
+
 func (ts *taskServiceValidator) validatePermission(ctx context.Context, perm platform.Permission, loggerFields ...zap.Field) error {
 	auth, err := platcontext.GetAuthorizer(ctx)
 	if err != nil {
@@ -337,6 +465,11 @@ func (ts *taskServiceValidator) validateBucket(ctx context.Context, script strin
 		return err
 	}
 
+	script, err = platform.ResolveTaskScriptIncludes(ctx, ts.scripts, orgID, script)
+	if err != nil {
+		return err
+	}
+
 	spec, err := flux.Compile(ctx, script, time.Now())
 	if err != nil {
 		return platform.NewError(
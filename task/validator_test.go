@@ -586,3 +586,128 @@ from(bucket:"cows") |> range(start:-5m) |> to(bucket:"cows", org:"thing")`
 		})
 	}
 }
+
+func TestValidationsWithIncludes(t *testing.T) {
+	inmem := inmem.NewService()
+
+	r, err := inmem.Generate(context.Background(), &influxdb.OnboardingRequest{
+		User:            "Setec Astronomy",
+		Password:        "too many secrets",
+		Org:             "thing",
+		Bucket:          "holder",
+		RetentionPeriod: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgID := r.Org.ID
+
+	scripts := mock.NewTaskScriptService()
+	scripts.FindTaskScriptFn = func(ctx context.Context, id influxdb.ID, name string, version int) (*influxdb.TaskScript, error) {
+		if id != orgID || name != "source" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrTaskScriptNotFound}
+		}
+		return &influxdb.TaskScript{OrgID: id, Name: name, Version: 1, Flux: `source = from(bucket:"holder") |> range(start:-5m)`}, nil
+	}
+
+	const flux = `option task = {
+ name: "my_task",
+ every: 1s,
+}
+// @include "source"
+source |> to(bucket:"holder", org:"thing")`
+	create := func(svc influxdb.TaskService, auth *influxdb.Authorization) error {
+		ctx := pctx.SetAuthorizer(context.Background(), auth)
+		_, err := svc.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: orgID, Flux: flux})
+		return err
+	}
+
+	// Without the bucket permissions, the bucket read by the included script is not authorized.
+	noBucketAuth := &influxdb.Authorization{Status: "active", Permissions: []influxdb.Permission{
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &orgID}},
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID, ID: &r.Bucket.ID}},
+	}}
+
+	svc := task.NewValidator(zaptest.NewLogger(t), mockTaskService(orgID, 0x7456, 0x402), inmem, task.WithTaskScriptService(scripts))
+	if err := create(svc, r.Auth); err != nil {
+		t.Errorf("expected task including a script to be created, got %v", err)
+	}
+	if err := create(svc, noBucketAuth); err == nil {
+		t.Error("expected task including a script reading an unauthorized bucket to fail")
+	}
+
+	svc = task.NewValidator(zaptest.NewLogger(t), mockTaskService(orgID, 0x7456, 0x402), inmem)
+	if err := create(svc, r.Auth); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected task including a script to be invalid without a task script service, got %v", err)
+	}
+}
+
+func TestRollbackValidation(t *testing.T) {
+	const taskID influxdb.ID = 0x7456
+
+	svc := inmem.NewService()
+	r, err := svc.Generate(context.Background(), &influxdb.OnboardingRequest{
+		User:            "Setec Astronomy",
+		Password:        "too many secrets",
+		Org:             "thing",
+		Bucket:          "holder",
+		RetentionPeriod: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgID := r.Org.ID
+	if err := svc.CreateBucket(context.Background(), &influxdb.Bucket{OrganizationID: orgID, Name: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	script := func(bucket string) string {
+		return `option task = {
+ name: "my_task",
+ every: 1s,
+}
+from(bucket:"` + bucket + `") |> range(start:-5m) |> to(bucket:"holder", org:"thing")`
+	}
+	tsk := &influxdb.Task{ID: taskID, OrganizationID: orgID, Name: "my_task", Flux: script("holder"), Every: "1s"}
+
+	var rolledBack []int
+	ts := &mock.TaskService{
+		FindTaskByIDFn: func(context.Context, influxdb.ID) (*influxdb.Task, error) {
+			return tsk, nil
+		},
+		FindTaskVersionsFn: func(context.Context, influxdb.ID) ([]*influxdb.TaskVersion, error) {
+			return []*influxdb.TaskVersion{
+				{TaskID: taskID, Version: 1, Flux: script("holder")},
+				{TaskID: taskID, Version: 2, Flux: script("secret")},
+			}, nil
+		},
+		RollbackTaskFn: func(_ context.Context, _ influxdb.ID, version int) (*influxdb.Task, error) {
+			rolledBack = append(rolledBack, version)
+			return tsk, nil
+		},
+	}
+	validator := task.NewValidator(zaptest.NewLogger(t), ts, svc)
+
+	// Write the task, and read/write the onboarding bucket only.
+	ctx := pctx.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &orgID, ID: &tsk.ID}},
+			{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID, ID: &r.Bucket.ID}},
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID, ID: &r.Bucket.ID}},
+		},
+	})
+
+	if _, err := validator.RollbackTask(ctx, taskID, 1); err != nil {
+		t.Fatalf("expected rolling back to a version reading an allowed bucket to succeed: %v", err)
+	}
+	if _, err := validator.RollbackTask(ctx, taskID, 2); err == nil {
+		t.Error("expected rolling back to a version reading a forbidden bucket to fail")
+	}
+	if _, err := validator.RollbackTask(ctx, taskID, 3); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected rolling back to a missing version to be not found, got %v", err)
+	}
+	if len(rolledBack) != 1 || rolledBack[0] != 1 {
+		t.Errorf("expected only version 1 to be rolled back to, got %v", rolledBack)
+	}
+}
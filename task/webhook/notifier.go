@@ -0,0 +1,228 @@
+// Package webhook notifies the webhooks of tasks when their runs succeed or fail.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts to deliver a notification.
+	DefaultMaxAttempts = 3
+
+	// DefaultBackoff is the default delay before retrying a failed delivery.
+	DefaultBackoff = 5 * time.Second
+
+	// DefaultTimeout is the default time limit of a delivery attempt.
+	DefaultTimeout = 10 * time.Second
+
+	// EventHeader is the header of the notifications holding the event of the webhook.
+	EventHeader = "X-Influxdb-Event"
+
+	// SignatureHeader is the header of the notifications holding the signature of their body,
+	// if the webhook has a secret.
+	SignatureHeader = "X-Influxdb-Signature"
+)
+
+var _ backend.RunNotifier = (*Notifier)(nil)
+
+// Notifier delivers notifications of finished runs to the webhooks of their task.
+// Each delivery attempt is recorded in the delivery log of the webhook.
+type Notifier struct {
+	Service influxdb.TaskWebhookService
+	Client  *http.Client
+	Logger  *zap.Logger
+
+	// MaxAttempts is the number of attempts to deliver a notification.
+	// Attempts are retried after a network error or a 5xx or 429 response only.
+	MaxAttempts int
+
+	// Backoff is the delay before the second attempt, doubled before each further attempt.
+	Backoff time.Duration
+
+	wg sync.WaitGroup
+}
+
+// NewNotifier returns a notifier of the webhooks of s with the default retry policy.
+func NewNotifier(s influxdb.TaskWebhookService, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		Service:     s,
+		Client:      &http.Client{Timeout: DefaultTimeout},
+		Logger:      logger.With(zap.String("svc", "taskd/webhook")),
+		MaxAttempts: DefaultMaxAttempts,
+		Backoff:     DefaultBackoff,
+	}
+}
+
+// RunFinished notifies the webhooks of task firing on the outcome of the run, in the background.
+func (n *Notifier) RunFinished(ctx context.Context, task *backend.StoreTask, qr backend.QueuedRun, status backend.RunStatus, message string) {
+	var event string
+	switch status {
+	case backend.RunSuccess:
+		event = influxdb.TaskWebhookEventSuccess
+	case backend.RunFail:
+		event = influxdb.TaskWebhookEventFailure
+	default:
+		return
+	}
+
+	p := influxdb.TaskWebhookPayload{
+		Event:        event,
+		TaskID:       task.ID,
+		TaskName:     task.Name,
+		OrgID:        task.Org,
+		RunID:        qr.RunID,
+		ScheduledFor: time.Unix(qr.Now, 0).UTC(),
+		Message:      message,
+	}
+	if qr.RequestedAt != 0 {
+		requestedAt := time.Unix(qr.RequestedAt, 0).UTC()
+		p.RequestedAt = &requestedAt
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.notify(ctx, p)
+	}()
+}
+
+// Wait blocks until the notifications in progress are delivered or given up on.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+func (n *Notifier) notify(ctx context.Context, p influxdb.TaskWebhookPayload) {
+	ws, err := n.Service.FindTaskWebhooks(ctx, influxdb.TaskWebhookFilter{TaskID: &p.TaskID})
+	if err != nil {
+		n.Logger.Info("Failed to find task webhooks", zap.Stringer("task_id", p.TaskID), zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range ws {
+		if !w.FiresOn(p.Event) {
+			continue
+		}
+		wg.Add(1)
+		go func(w *influxdb.TaskWebhook) {
+			defer wg.Done()
+			n.deliver(ctx, w, p)
+		}(w)
+	}
+	wg.Wait()
+}
+
+// deliver delivers the notification of p to w, retrying failed attempts.
+func (n *Notifier) deliver(ctx context.Context, w *influxdb.TaskWebhook, p influxdb.TaskWebhookPayload) {
+	logger := n.Logger.With(zap.Stringer("webhook_id", w.ID), zap.Stringer("run_id", p.RunID))
+
+	body, err := Render(w, p)
+	if err != nil {
+		n.record(ctx, logger, &influxdb.TaskWebhookDelivery{
+			WebhookID: w.ID,
+			RunID:     p.RunID,
+			Event:     p.Event,
+			Attempt:   1,
+			Error:     err.Error(),
+		})
+		return
+	}
+
+	backoff := n.Backoff
+	for attempt := 1; ; attempt++ {
+		d, retry := n.post(ctx, w, p.Event, body)
+		d.RunID = p.RunID
+		d.Attempt = attempt
+		n.record(ctx, logger, d)
+
+		if d.Succeeded() || !retry || attempt >= n.MaxAttempts {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a delivery attempt, and returns whether it should be retried if it failed.
+func (n *Notifier) post(ctx context.Context, w *influxdb.TaskWebhook, event string, body []byte) (*influxdb.TaskWebhookDelivery, bool) {
+	d := &influxdb.TaskWebhookDelivery{
+		WebhookID: w.ID,
+		Event:     event,
+		Time:      time.Now().UTC(),
+	}
+
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d, false
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return d, true
+	}
+	resp.Body.Close()
+
+	d.StatusCode = resp.StatusCode
+	return d, resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+func (n *Notifier) record(ctx context.Context, logger *zap.Logger, d *influxdb.TaskWebhookDelivery) {
+	if !d.Succeeded() {
+		logger.Info("Failed to deliver task webhook notification", zap.Int("attempt", d.Attempt), zap.Int("status_code", d.StatusCode), zap.String("error", d.Error))
+	}
+	if err := n.Service.AddTaskWebhookDelivery(ctx, d); err != nil {
+		logger.Info("Failed to record task webhook delivery", zap.Error(err))
+	}
+}
+
+// Render returns the body of the notification of p to w:
+// the result of the template of w, or the JSON encoding of p if w has no template.
+func Render(w *influxdb.TaskWebhook, p influxdb.TaskWebhookPayload) ([]byte, error) {
+	if w.Template == "" {
+		return json.Marshal(p)
+	}
+
+	t, err := w.ParseTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the signature of body with secret, that is "sha256=" followed by the hex-encoded HMAC-SHA256 of body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
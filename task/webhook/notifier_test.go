@@ -0,0 +1,195 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/webhook"
+	"go.uber.org/zap/zaptest"
+)
+
+type deliveryLog struct {
+	mu         sync.Mutex
+	deliveries []*influxdb.TaskWebhookDelivery
+}
+
+func (l *deliveryLog) service(ws ...*influxdb.TaskWebhook) *mock.TaskWebhookService {
+	s := mock.NewTaskWebhookService()
+	s.FindTaskWebhooksFn = func(_ context.Context, filter influxdb.TaskWebhookFilter) ([]*influxdb.TaskWebhook, error) {
+		var matched []*influxdb.TaskWebhook
+		for _, w := range ws {
+			if filter.Matches(w) {
+				matched = append(matched, w)
+			}
+		}
+		return matched, nil
+	}
+	s.AddTaskWebhookDeliveryFn = func(_ context.Context, d *influxdb.TaskWebhookDelivery) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.deliveries = append(l.deliveries, d)
+		return nil
+	}
+	return s
+}
+
+func (l *deliveryLog) Deliveries() []*influxdb.TaskWebhookDelivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*influxdb.TaskWebhookDelivery(nil), l.deliveries...)
+}
+
+func newNotifier(t *testing.T, s influxdb.TaskWebhookService) *webhook.Notifier {
+	n := webhook.NewNotifier(s, zaptest.NewLogger(t))
+	n.Backoff = time.Millisecond
+	return n
+}
+
+func TestNotifier_RunFinished(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+		sigs   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(b))
+		sigs = append(sigs, r.Header.Get(webhook.SignatureHeader))
+		if r.Header.Get(webhook.EventHeader) != influxdb.TaskWebhookEventFailure {
+			t.Errorf("unexpected event header %q", r.Header.Get(webhook.EventHeader))
+		}
+	}))
+	defer srv.Close()
+
+	log := &deliveryLog{}
+	n := newNotifier(t, log.service(
+		&influxdb.TaskWebhook{ID: 1, TaskID: 10, OrgID: 20, URL: srv.URL, Events: []string{influxdb.TaskWebhookEventFailure}, Secret: "s3cr3t"},
+		&influxdb.TaskWebhook{ID: 2, TaskID: 10, OrgID: 20, URL: srv.URL, Events: []string{influxdb.TaskWebhookEventSuccess}},
+		&influxdb.TaskWebhook{ID: 3, TaskID: 11, OrgID: 20, URL: srv.URL, Events: []string{influxdb.TaskWebhookEventFailure}},
+	))
+
+	task := &backend.StoreTask{ID: 10, Org: 20, Name: "downsample"}
+	n.RunFinished(context.Background(), task, backend.QueuedRun{TaskID: 10, RunID: 30, Now: 1551441600}, backend.RunFail, "Run failed to execute: boom")
+	n.Wait()
+
+	if len(bodies) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(bodies))
+	}
+	var p influxdb.TaskWebhookPayload
+	if err := json.Unmarshal([]byte(bodies[0]), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := influxdb.TaskWebhookPayload{
+		Event:        influxdb.TaskWebhookEventFailure,
+		TaskID:       10,
+		TaskName:     "downsample",
+		OrgID:        20,
+		RunID:        30,
+		ScheduledFor: time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC),
+		Message:      "Run failed to execute: boom",
+	}
+	if p != want {
+		t.Errorf("unexpected payload\ngot  %+v\nwant %+v", p, want)
+	}
+	if got, want := sigs[0], webhook.Sign("s3cr3t", []byte(bodies[0])); got != want {
+		t.Errorf("unexpected signature %q, want %q", got, want)
+	}
+
+	ds := log.Deliveries()
+	if len(ds) != 1 || ds[0].WebhookID != 1 || ds[0].RunID != 30 || !ds[0].Succeeded() {
+		t.Errorf("unexpected deliveries %+v", ds)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		wantAttempt []int
+	}{
+		{
+			name:        "retried until success",
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantAttempt: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:        "given up after max attempts",
+			statuses:    []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			wantAttempt: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+		},
+		{
+			name:        "client errors are not retried",
+			statuses:    []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempt: []int{http.StatusBadRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				calls int
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			log := &deliveryLog{}
+			n := newNotifier(t, log.service(
+				&influxdb.TaskWebhook{ID: 1, TaskID: 10, OrgID: 20, URL: srv.URL, Events: []string{influxdb.TaskWebhookEventSuccess}},
+			))
+
+			n.RunFinished(context.Background(), &backend.StoreTask{ID: 10, Org: 20}, backend.QueuedRun{TaskID: 10, RunID: 30}, backend.RunSuccess, "")
+			n.Wait()
+
+			ds := log.Deliveries()
+			if len(ds) != len(tt.wantAttempt) {
+				t.Fatalf("expected %d attempts, got %d", len(tt.wantAttempt), len(ds))
+			}
+			for i, d := range ds {
+				if d.Attempt != i+1 || d.StatusCode != tt.wantAttempt[i] {
+					t.Errorf("unexpected attempt %d: %+v", i+1, d)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	w := &influxdb.TaskWebhook{
+		Template: `{"text": {{json (printf "%s %s: %s" .TaskName .Event .Message)}}}`,
+	}
+	p := influxdb.TaskWebhookPayload{
+		Event:    influxdb.TaskWebhookEventFailure,
+		TaskName: "downsample",
+		Message:  `error "quoted"`,
+	}
+
+	b, err := webhook.Render(w, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"text": "downsample failure: error \"quoted\""}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	w.Template = `{{.Missing}}`
+	if _, err := webhook.Render(w, p); err == nil {
+		t.Error("expected error rendering a missing field")
+	}
+}
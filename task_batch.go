@@ -0,0 +1,47 @@
+package influxdb
+
+import (
+	"fmt"
+)
+
+// The operations of a task batch.
+const (
+	TaskBatchCreate = "create"
+	TaskBatchUpdate = "update"
+	TaskBatchDelete = "delete"
+)
+
+// MaxTaskBatchSize is the most operations a task batch can have.
+const MaxTaskBatchSize = 1000
+
+// TaskBatchOperation is a change of a task in a batch: the creation of Create,
+// or the update or the deletion of the task ID.
+type TaskBatchOperation struct {
+	Op     string
+	ID     ID
+	Create *TaskCreate
+	Update *TaskUpdate
+}
+
+// Validate returns an error if the operation is missing what it applies, or what it applies is invalid.
+func (o TaskBatchOperation) Validate() error {
+	switch o.Op {
+	case TaskBatchCreate:
+		if o.Create == nil {
+			return fmt.Errorf("create operation requires a task")
+		}
+		return o.Create.Validate()
+	case TaskBatchUpdate:
+		if !o.ID.Valid() || o.Update == nil {
+			return fmt.Errorf("update operation requires a task ID and an update")
+		}
+		return o.Update.Validate()
+	case TaskBatchDelete:
+		if !o.ID.Valid() {
+			return fmt.Errorf("delete operation requires a task ID")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q; valid operations are create, update and delete", o.Op)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package influxdb
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// ops for task dependency error
+const (
+	OpFindTaskDependencies   = "FindTaskDependencies"
+	OpPutTaskDependencies    = "PutTaskDependencies"
+	OpDeleteTaskDependencies = "DeleteTaskDependencies"
+)
+
+// TaskDependencyType is the type of a resource referenced by the Flux of a task.
+type TaskDependencyType string
+
+// The types of the resources tasks depend on.
+const (
+	// TaskDependencyBucket is a bucket referenced by name.
+	TaskDependencyBucket TaskDependencyType = "bucket"
+	// TaskDependencyBucketID is a bucket referenced by ID.
+	TaskDependencyBucketID TaskDependencyType = "bucketID"
+	// TaskDependencySecret is a secret of the organization of the task, referenced by key.
+	TaskDependencySecret TaskDependencyType = "secret"
+	// TaskDependencySQLConnection is a SQL connection of the organization of the task, referenced by name.
+	TaskDependencySQLConnection TaskDependencyType = "sqlConnection"
+	// TaskDependencyEndpoint is an external endpoint, referenced by the scheme and the host of its URL.
+	TaskDependencyEndpoint TaskDependencyType = "endpoint"
+)
+
+// TaskDependency is a resource referenced by the Flux of a task.
+type TaskDependency struct {
+	Type TaskDependencyType `json:"type"`
+	Name string             `json:"name"`
+}
+
+// TaskDependencies are the resources referenced by the Flux of a task, as of when it was last created or updated.
+type TaskDependencies struct {
+	TaskID       ID               `json:"taskID"`
+	OrgID        ID               `json:"orgID"`
+	Dependencies []TaskDependency `json:"dependencies"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+}
+
+// TaskDependencyService represents a service for managing the resources referenced by tasks,
+// so that the tasks depending on a resource can be found before it is deleted.
+type TaskDependencyService interface {
+	// FindTaskDependencies returns the dependencies of the tasks that match a filter.
+	FindTaskDependencies(ctx context.Context, filter TaskDependencyFilter) ([]*TaskDependencies, error)
+
+	// PutTaskDependencies replaces the dependencies of the task d.TaskID.
+	PutTaskDependencies(ctx context.Context, d *TaskDependencies) error
+
+	// DeleteTaskDependencies removes the dependencies of a task.
+	DeleteTaskDependencies(ctx context.Context, taskID ID) error
+}
+
+// TaskDependencyFilter represents a set of filters that restrict the returned task dependencies.
+type TaskDependencyFilter struct {
+	TaskID *ID
+	OrgID  *ID
+
+	// References, if set, only matches the tasks that depend on any of them.
+	References []TaskDependency
+}
+
+// Matches returns true if d passes the filter.
+func (f TaskDependencyFilter) Matches(d *TaskDependencies) bool {
+	if f.TaskID != nil && *f.TaskID != d.TaskID {
+		return false
+	}
+	if f.OrgID != nil && *f.OrgID != d.OrgID {
+		return false
+	}
+	if len(f.References) == 0 {
+		return true
+	}
+	for _, dep := range d.Dependencies {
+		for _, ref := range f.References {
+			if dep == ref {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnalyzeTaskDependencies returns the sorted, distinct resources referenced by the flux script:
+//
+//   - the buckets passed as the bucket and bucketID arguments of any function call,
+//   - the secrets passed as the key of secrets.get(),
+//   - the SQL connections passed as the connection of sql.from(),
+//   - the endpoints of http.to(), socket.from(), sql.from() and of the host argument of to().
+//
+// Only the references written as string literals can be determined statically, the others are ignored.
+// Endpoints are reduced to the scheme and the host of their URL, so that no credentials are kept.
+func AnalyzeTaskDependencies(script string) ([]TaskDependency, error) {
+	pkg := parser.ParseSource(script)
+	if err := ast.GetError(pkg); err != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Err:  err,
+		}
+	}
+
+	deps := map[TaskDependency]bool{}
+	add := func(typ TaskDependencyType, name string) {
+		if name != "" {
+			deps[TaskDependency{Type: typ, Name: name}] = true
+		}
+	}
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		args := map[string]string{}
+		for _, arg := range call.Arguments {
+			obj, ok := arg.(*ast.ObjectExpression)
+			if !ok {
+				continue
+			}
+			for _, p := range obj.Properties {
+				if lit, ok := p.Value.(*ast.StringLiteral); ok {
+					args[p.Key.Key()] = lit.Value
+				}
+			}
+		}
+
+		add(TaskDependencyBucket, args["bucket"])
+		add(TaskDependencyBucketID, args["bucketID"])
+
+		switch calleeName(call) {
+		case "secrets.get":
+			add(TaskDependencySecret, args["key"])
+		case "http.to", "socket.from":
+			add(TaskDependencyEndpoint, urlEndpoint(args["url"]))
+		case "sql.from":
+			add(TaskDependencySQLConnection, args["connection"])
+			add(TaskDependencyEndpoint, dsnEndpoint(args["driverName"], args["dataSourceName"]))
+		case "to":
+			add(TaskDependencyEndpoint, urlEndpoint(args["host"]))
+		}
+	}), pkg)
+
+	out := make([]TaskDependency, 0, len(deps))
+	for d := range deps {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// calleeName returns the name of the function called, e.g. "to" or "http.to", or "" for any other callee.
+func calleeName(call *ast.CallExpression) string {
+	switch c := call.Callee.(type) {
+	case *ast.Identifier:
+		return c.Name
+	case *ast.MemberExpression:
+		if id, ok := c.Object.(*ast.Identifier); ok {
+			return id.Name + "." + c.Property.Key()
+		}
+	}
+	return ""
+}
+
+// urlEndpoint returns the scheme and the host of rawurl, or "" if it is not an absolute URL.
+func urlEndpoint(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// dsnEndpoint returns the driver and the host of the data source name dsn, e.g. "postgres://db.example.com:5432".
+// Data source names are URLs, key=value pairs such as "host=db.example.com user=...",
+// or, for mysql, of the form "user:password@tcp(db.example.com:3306)/db".
+func dsnEndpoint(driver, dsn string) string {
+	if driver == "" || dsn == "" {
+		return ""
+	}
+
+	var host string
+	switch {
+	case strings.Contains(dsn, "://"):
+		if u, err := url.Parse(dsn); err == nil {
+			host = u.Host
+		}
+	case strings.Contains(dsn, "@"):
+		addr := dsn[strings.LastIndex(dsn, "@")+1:]
+		if i, j := strings.Index(addr, "("), strings.Index(addr, ")"); i >= 0 && j > i {
+			host = addr[i+1 : j]
+		}
+	default:
+		for _, kv := range strings.Fields(dsn) {
+			if strings.HasPrefix(kv, "host=") {
+				host = strings.TrimPrefix(kv, "host=")
+			}
+		}
+	}
+	if host == "" {
+		return driver
+	}
+	return driver + "://" + host
+}
@@ -0,0 +1,168 @@
+package influxdb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// TaskExportVersion is the version of the TaskExport documents produced by NewTaskExport.
+const TaskExportVersion = "1"
+
+// TaskExport is a portable document describing a task, which can be imported into any organization or instance.
+// It never contains secret values: the Flux is exported as written, so its secrets.get() calls
+// keep referencing the secrets by key, and the keys are listed in Secrets.
+type TaskExport struct {
+	Version string            `json:"version"`
+	Name    string            `json:"name"`
+	Status  string            `json:"status,omitempty"`
+	Flux    string            `json:"flux"`
+	Every   string            `json:"every,omitempty"`
+	Cron    string            `json:"cron,omitempty"`
+	Offset  string            `json:"offset,omitempty"`
+	Labels  []TaskExportLabel `json:"labels"`
+
+	// Params are the params of the task, which the imported task keeps.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Secrets are the keys of the secrets the Flux reads, which must exist in the organization the task is imported into.
+	Secrets []string `json:"secrets"`
+}
+
+// TaskExportLabel is a label of an exported task.
+// Labels are exported by name, as label IDs are specific to an organization.
+type TaskExportLabel struct {
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// NewTaskExport returns the export of t, labeled with labels.
+func NewTaskExport(t *Task, labels []*Label) (*TaskExport, error) {
+	secrets, err := SecretReferences(t.Flux)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &TaskExport{
+		Version: TaskExportVersion,
+		Name:    t.Name,
+		Status:  t.Status,
+		Flux:    t.Flux,
+		Every:   t.Every,
+		Cron:    t.Cron,
+		Offset:  t.Offset,
+		Labels:  make([]TaskExportLabel, 0, len(labels)),
+		Params:  t.Params,
+		Secrets: secrets,
+	}
+	for _, l := range labels {
+		e.Labels = append(e.Labels, TaskExportLabel{Name: l.Name, Properties: l.Properties})
+	}
+	return e, nil
+}
+
+// Validate returns an error if the export cannot be imported.
+func (e *TaskExport) Validate() error {
+	if e.Version != TaskExportVersion {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("unsupported task export version %q", e.Version),
+		}
+	}
+	if e.Flux == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task export is missing flux",
+		}
+	}
+	for _, l := range e.Labels {
+		if l.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "task export labels require a name",
+			}
+		}
+	}
+	return ValidateTaskParams(e.Params)
+}
+
+// TaskImport is a request to create a task from a TaskExport.
+type TaskImport struct {
+	OrganizationID ID     `json:"orgID,omitempty"`
+	Organization   string `json:"org,omitempty"`
+	Token          string `json:"token,omitempty"`
+
+	Task TaskExport `json:"task"`
+}
+
+// Validate returns an error if the import request is invalid.
+func (i *TaskImport) Validate() error {
+	if !i.OrganizationID.Valid() && i.Organization == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task import requires an orgID or org",
+		}
+	}
+	return i.Task.Validate()
+}
+
+// TaskCreate returns the request creating the imported task.
+func (i *TaskImport) TaskCreate() TaskCreate {
+	return TaskCreate{
+		Flux:           i.Task.Flux,
+		Status:         i.Task.Status,
+		OrganizationID: i.OrganizationID,
+		Organization:   i.Organization,
+		Token:          i.Token,
+		Params:         i.Task.Params,
+	}
+}
+
+// SecretReferences returns the sorted, distinct keys passed as string literals to secrets.get() in the flux script.
+func SecretReferences(script string) ([]string, error) {
+	pkg := parser.ParseSource(script)
+	if ast.Check(pkg) > 0 {
+		return nil, &Error{
+			Code: EInvalid,
+			Err:  ast.GetError(pkg),
+		}
+	}
+
+	keys := map[string]bool{}
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		m, ok := call.Callee.(*ast.MemberExpression)
+		if !ok || m.Property.Key() != "get" {
+			return
+		}
+		if id, ok := m.Object.(*ast.Identifier); !ok || id.Name != "secrets" {
+			return
+		}
+		for _, arg := range call.Arguments {
+			obj, ok := arg.(*ast.ObjectExpression)
+			if !ok {
+				continue
+			}
+			for _, p := range obj.Properties {
+				if p.Key.Key() != "key" {
+					continue
+				}
+				if lit, ok := p.Value.(*ast.StringLiteral); ok {
+					keys[lit.Value] = true
+				}
+			}
+		}
+	}), pkg)
+
+	out := make([]string, 0, len(keys))
+	for k := range keys {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestSecretReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "no secrets",
+			script: `from(bucket: "b") |> range(start: -1h)`,
+			want:   []string{},
+		},
+		{
+			name: "secrets are sorted and distinct",
+			script: `import "influxdata/influxdb/secrets"
+
+token = secrets.get(key: "TOKEN")
+user = secrets.get(key: "USER")
+again = secrets.get(key: "TOKEN")
+from(bucket: "b") |> range(start: -1h)`,
+			want: []string{"TOKEN", "USER"},
+		},
+		{
+			name:   "other get calls are ignored",
+			script: `x = dict.get(key: "k")`,
+			want:   []string{},
+		},
+		{
+			name:    "invalid script",
+			script:  `secrets.get(key: "TOKEN"))`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := platform.SecretReferences(tt.script)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("secret references are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewTaskExport(t *testing.T) {
+	flux := `import "influxdata/influxdb/secrets"
+
+option task = {name: "t", every: 1h}
+
+token = secrets.get(key: "TOKEN")
+from(bucket: "b") |> range(start: -1h)`
+
+	e, err := platform.NewTaskExport(&platform.Task{
+		ID:             1,
+		OrganizationID: 2,
+		Name:           "t",
+		Status:         "active",
+		Flux:           flux,
+		Every:          "1h",
+		Params:         map[string]string{"env": "prod"},
+	}, []*platform.Label{
+		{ID: 3, OrganizationID: 2, Name: "prod", Properties: map[string]string{"color": "red"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &platform.TaskExport{
+		Version: platform.TaskExportVersion,
+		Name:    "t",
+		Status:  "active",
+		Flux:    flux,
+		Every:   "1h",
+		Labels:  []platform.TaskExportLabel{{Name: "prod", Properties: map[string]string{"color": "red"}}},
+		Params:  map[string]string{"env": "prod"},
+		Secrets: []string{"TOKEN"},
+	}
+	if diff := cmp.Diff(e, want); diff != "" {
+		t.Errorf("task export is different -got/+want\ndiff %s", diff)
+	}
+	if err := e.Validate(); err != nil {
+		t.Errorf("export is invalid: %v", err)
+	}
+
+	e.Version = "0"
+	if err := e.Validate(); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected invalid version error, got %v", err)
+	}
+}
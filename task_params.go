@@ -0,0 +1,90 @@
+package influxdb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/edit"
+	"github.com/influxdata/flux/parser"
+)
+
+// reservedTaskParams are the options a task param cannot replace,
+// because the task system sets them itself.
+var reservedTaskParams = map[string]bool{
+	"task": true,
+	"now":  true,
+}
+
+// ValidateTaskParams returns an error if a key of params cannot be used as the name of a Flux option.
+func ValidateTaskParams(params map[string]string) error {
+	for k := range params {
+		if reservedTaskParams[k] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("task param %q is reserved", k),
+			}
+		}
+
+		// A key is valid if it can be declared as an option on its own.
+		pkg := parser.ParseSource("option " + k + ` = ""`)
+		valid := ast.Check(pkg) == 0 && len(pkg.Files) == 1 && len(pkg.Files[0].Body) == 1
+		if valid {
+			opt, ok := pkg.Files[0].Body[0].(*ast.OptionStatement)
+			if !ok {
+				valid = false
+			} else if a, ok := opt.Assignment.(*ast.VariableAssignment); !ok || a.ID.Name != k {
+				valid = false
+			}
+		}
+		if !valid {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("task param %q is not a valid identifier", k),
+			}
+		}
+	}
+	return nil
+}
+
+// InjectTaskParams returns script with every param set as a string option.
+// The value of an option the script already declares is replaced,
+// so the script can declare a default for a param;
+// the options the script does not declare are added at the top of the script.
+func InjectTaskParams(script string, params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return script, nil
+	}
+
+	pkg := parser.ParseSource(script)
+	if ast.Check(pkg) > 0 {
+		return "", ast.GetError(pkg)
+	}
+	file := pkg.Files[0]
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var added []ast.Statement
+	for _, k := range keys {
+		v := &ast.StringLiteral{Value: params[k]}
+		found, err := edit.Option(file, k, edit.OptionValueFn(v))
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			added = append(added, &ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID:   &ast.Identifier{Name: k},
+					Init: v,
+				},
+			})
+		}
+	}
+	file.Body = append(added, file.Body...)
+
+	return ast.Format(file), nil
+}
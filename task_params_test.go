@@ -0,0 +1,103 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestValidateTaskParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		wantErr bool
+	}{
+		{name: "no params"},
+		{name: "identifiers", params: map[string]string{"env": "prod", "bucket_2": "b"}},
+		{name: "reserved task", params: map[string]string{"task": "x"}, wantErr: true},
+		{name: "reserved now", params: map[string]string{"now": "x"}, wantErr: true},
+		{name: "empty key", params: map[string]string{"": "x"}, wantErr: true},
+		{name: "invalid identifier", params: map[string]string{"my-env": "x"}, wantErr: true},
+		{name: "keyword", params: map[string]string{"import": "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := platform.ValidateTaskParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && platform.ErrorCode(err) != platform.EInvalid {
+				t.Errorf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInjectTaskParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no params",
+			script: `from(bucket: "b") |> range(start: -1h)`,
+			want:   `from(bucket: "b") |> range(start: -1h)`,
+		},
+		{
+			name: "params are added as options",
+			script: `import "strings"
+
+option task = {name: "t", every: 1h}
+
+from(bucket: bucket) |> range(start: -1h) |> filter(fn: (r) => r.env == env)`,
+			params: map[string]string{"env": "prod", "bucket": "b"},
+			want: `import "strings"
+
+option bucket = "b"
+option env = "prod"
+option task = {name: "t", every: 1h}
+
+from(bucket: bucket)
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r.env == env))`,
+		},
+		{
+			name: "declared options are replaced",
+			script: `option env = "dev"
+option task = {name: "t", every: 1h}
+
+from(bucket: "b") |> range(start: -1h) |> filter(fn: (r) => r.env == env)`,
+			params: map[string]string{"env": "prod"},
+			want: `option env = "prod"
+option task = {name: "t", every: 1h}
+
+from(bucket: "b")
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r.env == env))`,
+		},
+		{
+			name:    "invalid script",
+			script:  `from(bucket: "b"))`,
+			params:  map[string]string{"env": "prod"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := platform.InjectTaskParams(tt.script, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected script\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/task/include"
+)
+
+// ErrTaskScriptNotFound is the error msg for a missing task script.
+const ErrTaskScriptNotFound = "task script not found"
+
+// ops for task script error
+const (
+	OpFindTaskScript         = "FindTaskScript"
+	OpFindTaskScripts        = "FindTaskScripts"
+	OpFindTaskScriptVersions = "FindTaskScriptVersions"
+	OpCreateTaskScript       = "CreateTaskScript"
+	OpDeleteTaskScript       = "DeleteTaskScript"
+)
+
+// TaskScriptService represents a service for managing the versioned Flux scripts that tasks of an organization include.
+type TaskScriptService interface {
+	// FindTaskScript returns a version of the named script of an organization, or its latest version if version is 0.
+	FindTaskScript(ctx context.Context, orgID ID, name string, version int) (*TaskScript, error)
+
+	// FindTaskScripts returns the latest version of the scripts that match a filter, sorted by name.
+	FindTaskScripts(ctx context.Context, filter TaskScriptFilter) ([]*TaskScript, error)
+
+	// FindTaskScriptVersions returns every version of the named script of an organization, latest first.
+	FindTaskScriptVersions(ctx context.Context, orgID ID, name string) ([]*TaskScript, error)
+
+	// CreateTaskScript stores s as a new version of the script named s.Name,
+	// and sets s.ID, s.Version and s.CreatedAt.
+	CreateTaskScript(ctx context.Context, s *TaskScript) error
+
+	// DeleteTaskScript removes every version of the named script of an organization.
+	DeleteTaskScript(ctx context.Context, orgID ID, name string) error
+}
+
+// TaskScript is a version of a Flux script that task scripts of its organization include with the directive
+//
+//	// @include "name"
+//
+// or, to pin the version,
+//
+//	// @include "name@version"
+type TaskScript struct {
+	ID          ID        `json:"id,omitempty"`
+	OrgID       ID        `json:"orgID"`
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Description string    `json:"description,omitempty"`
+	Flux        string    `json:"flux"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if s is missing its organization or name, or its Flux does not parse.
+func (s *TaskScript) Validate() error {
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task script must have an organization",
+		}
+	}
+	if err := include.ValidName(s.Name); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Err:  err,
+		}
+	}
+
+	if err := ast.GetError(parser.ParseSource(s.Flux)); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task script is not valid Flux",
+			Err:  err,
+		}
+	}
+	if _, err := include.Parse(s.Flux); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// TaskScriptFilter represents a set of filters that restrict the returned task scripts.
+type TaskScriptFilter struct {
+	OrgID *ID
+	Name  *string
+}
+
+// ResolveTaskScriptIncludes returns script with every include directive replaced by the Flux
+// of the included script of the organization, itself resolved.
+// Scripts without include directives are returned unchanged.
+func ResolveTaskScriptIncludes(ctx context.Context, s TaskScriptService, orgID ID, script string) (string, error) {
+	if !include.Has(script) {
+		return script, nil
+	}
+	if s == nil {
+		return "", &Error{
+			Code: EInvalid,
+			Msg:  "task scripts are not supported: cannot resolve include directives",
+		}
+	}
+
+	resolved, err := include.Resolve(script, func(name string, version int) (string, error) {
+		ts, err := s.FindTaskScript(ctx, orgID, name, version)
+		if err != nil {
+			return "", err
+		}
+		return ts.Flux, nil
+	})
+	if err != nil {
+		return "", &Error{
+			Code: EInvalid,
+			Msg:  "failed to resolve task script includes",
+			Err:  err,
+		}
+	}
+	return resolved, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/pkg/pointer"
 	_ "github.com/influxdata/influxdb/query/builtin"
@@ -36,6 +37,23 @@ func TestOptionsMarshal(t *testing.T) {
 	}
 }
 
+func TestTaskUpdateParamsMarshal(t *testing.T) {
+	// Empty params remove the params of a task, so they must survive a round trip, unlike missing params.
+	for _, params := range []map[string]string{nil, {}, {"env": "prod"}} {
+		b, err := json.Marshal(platform.TaskUpdate{Params: params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tu platform.TaskUpdate
+		if err := json.Unmarshal(b, &tu); err != nil {
+			t.Fatal(err)
+		}
+		if (tu.Params == nil) != (params == nil) || !cmp.Equal(tu.Params, params, cmpopts.EquateEmpty()) {
+			t.Errorf("params %v did not round trip through %s, got %v", params, b, tu.Params)
+		}
+	}
+}
+
 func TestOptionsEdit(t *testing.T) {
 	tu := &platform.TaskUpdate{}
 	tu.Options.Every = 10 * time.Second
@@ -139,5 +157,53 @@ from(bucket: "x")
 			t.Fatalf(cmp.Diff(*tu.Flux, expscript))
 		}
 	})
+	t.Run("weight", func(t *testing.T) {
+		tu := &platform.TaskUpdate{}
+		if err := json.Unmarshal([]byte(`{"weight": 5}`), tu); err != nil {
+			t.Fatal(err)
+		}
+		if err := tu.UpdateFlux(`option task = {every: 20s, name: "foo", weight: 2} from(bucket:"x") |> range(start:-1h)`); err != nil {
+			t.Fatal(err)
+		}
+		op, err := options.FromScript(*tu.Flux)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if op.EffectiveWeight() != 5 {
+			t.Fatalf("expected weight to be 5 but was %d", op.EffectiveWeight())
+		}
+	})
+	t.Run("timezone", func(t *testing.T) {
+		tu := &platform.TaskUpdate{}
+		if err := json.Unmarshal([]byte(`{"cron": "0 9 * * *", "timezone": "Europe/Paris"}`), tu); err != nil {
+			t.Fatal(err)
+		}
+		if err := tu.UpdateFlux(`option task = {every: 20s, name: "foo"} from(bucket:"x") |> range(start:-1h)`); err != nil {
+			t.Fatal(err)
+		}
+		op, err := options.FromScript(*tu.Flux)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if op.Timezone != "Europe/Paris" || op.EffectiveCronString() != "TZ=Europe/Paris 0 9 * * *" {
+			t.Fatalf("unexpected schedule %q in %s", op.EffectiveCronString(), *tu.Flux)
+		}
+
+		// Going back to every removes the timezone.
+		tu = &platform.TaskUpdate{}
+		if err := json.Unmarshal([]byte(`{"every": "1m", "offset": "0s"}`), tu); err != nil {
+			t.Fatal(err)
+		}
+		if err := tu.UpdateFlux(`option task = {cron: "0 9 * * *", name: "foo", timezone: "Europe/Paris", offset: 5s} from(bucket:"x") |> range(start:-1h)`); err != nil {
+			t.Fatal(err)
+		}
+		op, err = options.FromScript(*tu.Flux)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if op.Timezone != "" || op.Every != time.Minute || op.Offset != nil {
+			t.Fatalf("unexpected options %+v in %s", op, *tu.Flux)
+		}
+	})
 
 }
@@ -0,0 +1,230 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"text/template"
+	"time"
+)
+
+// ErrTaskWebhookNotFound is the error msg for a missing task webhook.
+const ErrTaskWebhookNotFound = "task webhook not found"
+
+// ops for task webhook error
+const (
+	OpFindTaskWebhookByID       = "FindTaskWebhookByID"
+	OpFindTaskWebhooks          = "FindTaskWebhooks"
+	OpCreateTaskWebhook         = "CreateTaskWebhook"
+	OpUpdateTaskWebhook         = "UpdateTaskWebhook"
+	OpDeleteTaskWebhook         = "DeleteTaskWebhook"
+	OpAddTaskWebhookDelivery    = "AddTaskWebhookDelivery"
+	OpFindTaskWebhookDeliveries = "FindTaskWebhookDeliveries"
+)
+
+// Events a task webhook fires on.
+const (
+	TaskWebhookEventSuccess = "success"
+	TaskWebhookEventFailure = "failure"
+)
+
+// MaxTaskWebhookDeliveries is the number of latest deliveries kept in the delivery log of a task webhook.
+const MaxTaskWebhookDeliveries = 100
+
+// TaskWebhookService represents a service for managing the webhooks notified of the runs of tasks.
+type TaskWebhookService interface {
+	// FindTaskWebhookByID returns a single task webhook by ID.
+	FindTaskWebhookByID(ctx context.Context, id ID) (*TaskWebhook, error)
+
+	// FindTaskWebhooks returns the task webhooks that match a filter.
+	FindTaskWebhooks(ctx context.Context, filter TaskWebhookFilter) ([]*TaskWebhook, error)
+
+	// CreateTaskWebhook creates a new task webhook and sets w.ID with the new identifier.
+	CreateTaskWebhook(ctx context.Context, w *TaskWebhook) error
+
+	// UpdateTaskWebhook updates a single task webhook with changeset.
+	// Returns the new task webhook state after update.
+	UpdateTaskWebhook(ctx context.Context, id ID, upd TaskWebhookUpdate) (*TaskWebhook, error)
+
+	// DeleteTaskWebhook removes a task webhook by ID, along with its delivery log.
+	DeleteTaskWebhook(ctx context.Context, id ID) error
+
+	// AddTaskWebhookDelivery records a delivery attempt in the delivery log of its webhook, and sets d.ID with the new identifier.
+	// Only the latest MaxTaskWebhookDeliveries deliveries of a webhook are kept.
+	AddTaskWebhookDelivery(ctx context.Context, d *TaskWebhookDelivery) error
+
+	// FindTaskWebhookDeliveries returns the delivery log of a task webhook, latest first.
+	FindTaskWebhookDeliveries(ctx context.Context, webhookID ID) ([]*TaskWebhookDelivery, error)
+}
+
+// TaskWebhook is an HTTP endpoint notified when runs of a task finish.
+type TaskWebhook struct {
+	ID     ID `json:"id,omitempty"`
+	TaskID ID `json:"taskID"`
+	OrgID  ID `json:"orgID"`
+
+	// URL is the http or https URL the notifications are POSTed to.
+	URL string `json:"url"`
+
+	// Events are the outcomes of the runs the webhook fires on.
+	Events []string `json:"events"`
+
+	// Template, if set, is the text/template rendering the body of the notifications from a TaskWebhookPayload.
+	// The body is the JSON encoding of the payload otherwise.
+	Template string `json:"template,omitempty"`
+
+	// Secret, if set, is the key the body of the notifications is signed with.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate returns an error if the task webhook is invalid.
+func (w *TaskWebhook) Validate() error {
+	if !w.TaskID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task webhook taskID is required",
+		}
+	}
+	if !w.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task webhook orgID is required",
+		}
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task webhook url must be an absolute http or https URL",
+		}
+	}
+	if len(w.Events) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task webhook must fire on at least one event",
+		}
+	}
+	for _, e := range w.Events {
+		if e != TaskWebhookEventSuccess && e != TaskWebhookEventFailure {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "task webhook events must be success or failure",
+			}
+		}
+	}
+	if w.Template != "" {
+		if _, err := w.ParseTemplate(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "task webhook template is invalid",
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+// ParseTemplate parses the template of the webhook.
+// Besides the builtin functions, templates may use the json function to encode a value, e.g. {{json .Message}}.
+func (w *TaskWebhook) ParseTemplate() (*template.Template, error) {
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(w.Template)
+}
+
+// FiresOn returns true if the webhook fires on event.
+func (w *TaskWebhook) FiresOn(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskWebhookFilter represents a set of filters that restrict the returned task webhooks.
+type TaskWebhookFilter struct {
+	ID     *ID
+	TaskID *ID
+	OrgID  *ID
+}
+
+// Matches returns true if w passes the filter.
+func (f TaskWebhookFilter) Matches(w *TaskWebhook) bool {
+	if f.ID != nil && *f.ID != w.ID {
+		return false
+	}
+	if f.TaskID != nil && *f.TaskID != w.TaskID {
+		return false
+	}
+	if f.OrgID != nil && *f.OrgID != w.OrgID {
+		return false
+	}
+	return true
+}
+
+// TaskWebhookUpdate is the set of changes that can be applied to a task webhook.
+// The task of a webhook cannot change.
+type TaskWebhookUpdate struct {
+	URL      *string   `json:"url,omitempty"`
+	Events   *[]string `json:"events,omitempty"`
+	Template *string   `json:"template,omitempty"`
+	Secret   *string   `json:"secret,omitempty"`
+}
+
+// Apply applies the update to w and validates the result.
+func (u TaskWebhookUpdate) Apply(w *TaskWebhook) error {
+	if u.URL != nil {
+		w.URL = *u.URL
+	}
+	if u.Events != nil {
+		w.Events = *u.Events
+	}
+	if u.Template != nil {
+		w.Template = *u.Template
+	}
+	if u.Secret != nil {
+		w.Secret = *u.Secret
+	}
+	return w.Validate()
+}
+
+// TaskWebhookPayload describes a finished run to a task webhook.
+type TaskWebhookPayload struct {
+	Event        string    `json:"event"`
+	TaskID       ID        `json:"taskID"`
+	TaskName     string    `json:"taskName"`
+	OrgID        ID        `json:"orgID"`
+	RunID        ID        `json:"runID"`
+	ScheduledFor time.Time `json:"scheduledFor"`
+
+	// RequestedAt is set for manual runs only.
+	RequestedAt *time.Time `json:"requestedAt,omitempty"`
+
+	// Message describes why the run failed.
+	Message string `json:"message,omitempty"`
+}
+
+// TaskWebhookDelivery is an attempt to notify a task webhook of a finished run.
+type TaskWebhookDelivery struct {
+	ID        ID        `json:"id,omitempty"`
+	WebhookID ID        `json:"webhookID"`
+	RunID     ID        `json:"runID"`
+	Event     string    `json:"event"`
+	Attempt   int       `json:"attempt"`
+	Time      time.Time `json:"time"`
+
+	// StatusCode is the status code of the response of the webhook, if any.
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Error describes why the attempt failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the webhook accepted the notification.
+func (d *TaskWebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
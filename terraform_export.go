@@ -0,0 +1,252 @@
+package influxdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats of a TerraformExport.
+const (
+	TerraformFormatJSON = "json"
+	TerraformFormatHCL  = "hcl"
+)
+
+// Types of the Terraform resources of a TerraformExport.
+const (
+	TerraformExpectedReporterType = "influxdb_expected_reporter"
+	TerraformTaskWebhookType      = "influxdb_task_webhook"
+)
+
+// TerraformExport is the Terraform configuration of the alerting resources of an organization:
+// its expected reporters and the webhooks notified of the runs of its tasks.
+// Like a TaskExport, it never contains secret values: the secret of a webhook is read from
+// a sensitive variable, which must be set when the configuration is applied.
+type TerraformExport struct {
+	Variables []TerraformVariable
+	Resources []TerraformResource
+}
+
+// TerraformVariable is an input variable of a TerraformExport, of type string.
+type TerraformVariable struct {
+	Name        string
+	Description string
+	Sensitive   bool
+}
+
+// TerraformResource is a resource block of a TerraformExport.
+type TerraformResource struct {
+	Type string
+	Name string
+
+	// Attributes are the arguments of the block, in order.
+	Attributes []TerraformAttribute
+}
+
+// TerraformAttribute is an argument of a TerraformResource.
+// Its value is a string, an int64, a []string or a TerraformReference.
+type TerraformAttribute struct {
+	Name  string
+	Value interface{}
+}
+
+// TerraformReference is an expression referencing a named value, such as var.name.
+type TerraformReference string
+
+// NewTerraformExport returns the Terraform configuration of expected reporters and task webhooks, ordered by ID.
+func NewTerraformExport(reporters []*ExpectedReporter, webhooks []*TaskWebhook) *TerraformExport {
+	reporters = append([]*ExpectedReporter(nil), reporters...)
+	sort.Slice(reporters, func(i, j int) bool { return reporters[i].ID < reporters[j].ID })
+	webhooks = append([]*TaskWebhook(nil), webhooks...)
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	e := &TerraformExport{
+		Variables: []TerraformVariable{},
+		Resources: make([]TerraformResource, 0, len(reporters)+len(webhooks)),
+	}
+	for _, r := range reporters {
+		attrs := []TerraformAttribute{
+			{Name: "org_id", Value: r.OrgID.String()},
+			{Name: "bucket_id", Value: r.BucketID.String()},
+		}
+		if r.Measurement != "" {
+			attrs = append(attrs, TerraformAttribute{Name: "measurement", Value: r.Measurement})
+		}
+		attrs = append(attrs,
+			TerraformAttribute{Name: "tag_key", Value: r.TagKey},
+			TerraformAttribute{Name: "tag_value", Value: r.TagValue},
+			TerraformAttribute{Name: "interval_seconds", Value: int64(r.Interval.Round(time.Second) / time.Second)},
+		)
+		e.Resources = append(e.Resources, TerraformResource{
+			Type:       TerraformExpectedReporterType,
+			Name:       "reporter_" + r.ID.String(),
+			Attributes: attrs,
+		})
+	}
+	for _, w := range webhooks {
+		name := "webhook_" + w.ID.String()
+		attrs := []TerraformAttribute{
+			{Name: "org_id", Value: w.OrgID.String()},
+			{Name: "task_id", Value: w.TaskID.String()},
+			{Name: "url", Value: w.URL},
+			{Name: "events", Value: append([]string{}, w.Events...)},
+		}
+		if w.Template != "" {
+			attrs = append(attrs, TerraformAttribute{Name: "template", Value: w.Template})
+		}
+		if w.Secret != "" {
+			v := TerraformVariable{
+				Name:        name + "_secret",
+				Description: fmt.Sprintf("The key the notifications of the webhook %s are signed with.", w.ID),
+				Sensitive:   true,
+			}
+			e.Variables = append(e.Variables, v)
+			attrs = append(attrs, TerraformAttribute{Name: "secret", Value: TerraformReference("var." + v.Name)})
+		}
+		e.Resources = append(e.Resources, TerraformResource{
+			Type:       TerraformTaskWebhookType,
+			Name:       name,
+			Attributes: attrs,
+		})
+	}
+	return e
+}
+
+// Encode returns the configuration in the JSON or the native syntax of Terraform, see TerraformFormatJSON
+// and TerraformFormatHCL, to be written to a .tf.json or a .tf file.
+func (e *TerraformExport) Encode(format string) ([]byte, error) {
+	switch format {
+	case TerraformFormatJSON:
+		return e.encodeJSON()
+	case TerraformFormatHCL:
+		return e.encodeHCL(), nil
+	default:
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("unsupported terraform format %q", format),
+		}
+	}
+}
+
+func (e *TerraformExport) encodeJSON() ([]byte, error) {
+	doc := map[string]interface{}{}
+	if len(e.Variables) > 0 {
+		vars := map[string]interface{}{}
+		for _, v := range e.Variables {
+			vars[v.Name] = map[string]interface{}{
+				"type":        "string",
+				"description": v.Description,
+				"sensitive":   v.Sensitive,
+			}
+		}
+		doc["variable"] = vars
+	}
+	if len(e.Resources) > 0 {
+		resources := map[string]map[string]interface{}{}
+		for _, r := range e.Resources {
+			attrs := map[string]interface{}{}
+			for _, a := range r.Attributes {
+				switch v := a.Value.(type) {
+				case string:
+					attrs[a.Name] = escapeTerraformTemplate(v)
+				case []string:
+					vs := make([]string, 0, len(v))
+					for _, s := range v {
+						vs = append(vs, escapeTerraformTemplate(s))
+					}
+					attrs[a.Name] = vs
+				case TerraformReference:
+					// Strings of the JSON syntax are templates, which interpolate expressions.
+					attrs[a.Name] = "${" + string(v) + "}"
+				default:
+					attrs[a.Name] = v
+				}
+			}
+			if resources[r.Type] == nil {
+				resources[r.Type] = map[string]interface{}{}
+			}
+			resources[r.Type][r.Name] = attrs
+		}
+		doc["resource"] = resources
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *TerraformExport) encodeHCL() []byte {
+	var buf bytes.Buffer
+	for _, v := range e.Variables {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "variable %s {\n", quoteHCL(v.Name))
+		writeHCLAttributes(&buf, []TerraformAttribute{
+			{Name: "type", Value: TerraformReference("string")},
+			{Name: "description", Value: v.Description},
+			{Name: "sensitive", Value: TerraformReference(fmt.Sprint(v.Sensitive))},
+		})
+		buf.WriteString("}\n")
+	}
+	for _, r := range e.Resources {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "resource %s %s {\n", quoteHCL(r.Type), quoteHCL(r.Name))
+		writeHCLAttributes(&buf, r.Attributes)
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
+
+// writeHCLAttributes writes the attributes of a block with their equals signs aligned, as terraform fmt does.
+func writeHCLAttributes(buf *bytes.Buffer, attrs []TerraformAttribute) {
+	width := 0
+	for _, a := range attrs {
+		if len(a.Name) > width {
+			width = len(a.Name)
+		}
+	}
+	for _, a := range attrs {
+		var value string
+		switch v := a.Value.(type) {
+		case string:
+			value = quoteHCL(escapeTerraformTemplate(v))
+		case []string:
+			vs := make([]string, 0, len(v))
+			for _, s := range v {
+				vs = append(vs, quoteHCL(escapeTerraformTemplate(s)))
+			}
+			value = "[" + strings.Join(vs, ", ") + "]"
+		case TerraformReference:
+			value = string(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		fmt.Fprintf(buf, "  %-*s = %s\n", width, a.Name, value)
+	}
+}
+
+// quoteHCL returns s as a quoted string of the native syntax, whose escape sequences
+// are a superset of the ones of JSON.
+func quoteHCL(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // Encoding a string cannot fail.
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// escapeTerraformTemplate escapes the template sequences of s, so that Terraform reads it literally.
+func escapeTerraformTemplate(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
@@ -0,0 +1,139 @@
+package influxdb_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestTerraformExport(t *testing.T) {
+	e := platform.NewTerraformExport(
+		[]*platform.ExpectedReporter{
+			{
+				ID:          2,
+				OrgID:       10,
+				BucketID:    1,
+				Measurement: "cpu",
+				TagKey:      "host",
+				TagValue:    "a",
+				Interval:    5 * time.Minute,
+			},
+			{
+				ID:       1,
+				OrgID:    10,
+				BucketID: 1,
+				TagKey:   "host",
+				TagValue: "b",
+				Interval: time.Minute,
+			},
+		},
+		[]*platform.TaskWebhook{
+			{
+				ID:       3,
+				OrgID:    10,
+				TaskID:   4,
+				URL:      "https://example.com/hook",
+				Events:   []string{platform.TaskWebhookEventFailure},
+				Template: `{"text": "${task} {{.Status}}"}`,
+				Secret:   "s3cr3t",
+			},
+		},
+	)
+
+	hcl, err := e.Encode(platform.TerraformFormatHCL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHCL := `variable "webhook_0000000000000003_secret" {
+  type        = string
+  description = "The key the notifications of the webhook 0000000000000003 are signed with."
+  sensitive   = true
+}
+
+resource "influxdb_expected_reporter" "reporter_0000000000000001" {
+  org_id           = "000000000000000a"
+  bucket_id        = "0000000000000001"
+  tag_key          = "host"
+  tag_value        = "b"
+  interval_seconds = 60
+}
+
+resource "influxdb_expected_reporter" "reporter_0000000000000002" {
+  org_id           = "000000000000000a"
+  bucket_id        = "0000000000000001"
+  measurement      = "cpu"
+  tag_key          = "host"
+  tag_value        = "a"
+  interval_seconds = 300
+}
+
+resource "influxdb_task_webhook" "webhook_0000000000000003" {
+  org_id   = "000000000000000a"
+  task_id  = "0000000000000004"
+  url      = "https://example.com/hook"
+  events   = ["failure"]
+  template = "{\"text\": \"$${task} {{.Status}}\"}"
+  secret   = var.webhook_0000000000000003_secret
+}
+`
+	if diff := cmp.Diff(wantHCL, string(hcl)); diff != "" {
+		t.Errorf("unexpected HCL -want/+got:\n%s", diff)
+	}
+
+	b, err := e.Encode(platform.TerraformFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"variable": map[string]interface{}{
+			"webhook_0000000000000003_secret": map[string]interface{}{
+				"type":        "string",
+				"description": "The key the notifications of the webhook 0000000000000003 are signed with.",
+				"sensitive":   true,
+			},
+		},
+		"resource": map[string]interface{}{
+			"influxdb_expected_reporter": map[string]interface{}{
+				"reporter_0000000000000001": map[string]interface{}{
+					"org_id":           "000000000000000a",
+					"bucket_id":        "0000000000000001",
+					"tag_key":          "host",
+					"tag_value":        "b",
+					"interval_seconds": 60.0,
+				},
+				"reporter_0000000000000002": map[string]interface{}{
+					"org_id":           "000000000000000a",
+					"bucket_id":        "0000000000000001",
+					"measurement":      "cpu",
+					"tag_key":          "host",
+					"tag_value":        "a",
+					"interval_seconds": 300.0,
+				},
+			},
+			"influxdb_task_webhook": map[string]interface{}{
+				"webhook_0000000000000003": map[string]interface{}{
+					"org_id":   "000000000000000a",
+					"task_id":  "0000000000000004",
+					"url":      "https://example.com/hook",
+					"events":   []interface{}{"failure"},
+					"template": `{"text": "$${task} {{.Status}}"}`,
+					"secret":   "${var.webhook_0000000000000003_secret}",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected JSON -want/+got:\n%s", diff)
+	}
+
+	if _, err := e.Encode("yaml"); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected an unsupported format to be invalid, got %v", err)
+	}
+}
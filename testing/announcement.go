@@ -0,0 +1,342 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	announcementOneID = "020f755c3c082100"
+	announcementTwoID = "020f755c3c082101"
+	announcementNewID = "020f755c3c082102"
+)
+
+var announcementCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.Announcement) []*platform.Announcement {
+		out := append([]*platform.Announcement(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}),
+}
+
+// AnnouncementFields will include the IDGenerator, and announcements
+type AnnouncementFields struct {
+	IDGenerator   platform.IDGenerator
+	Announcements []*platform.Announcement
+}
+
+// AnnouncementService tests all the service functions.
+func AnnouncementService(
+	init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindAnnouncements",
+			fn:   FindAnnouncements,
+		},
+		{
+			name: "CreateAnnouncement",
+			fn:   CreateAnnouncement,
+		},
+		{
+			name: "UpdateAnnouncement",
+			fn:   UpdateAnnouncement,
+		},
+		{
+			name: "DeleteAnnouncement",
+			fn:   DeleteAnnouncement,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+var announcementNoon = time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func announcementFixtures() []*platform.Announcement {
+	return []*platform.Announcement{
+		{
+			ID:       MustIDBase16(announcementOneID),
+			Message:  "maintenance in progress",
+			Severity: platform.AnnouncementWarning,
+			StartsAt: announcementNoon.Add(-time.Hour),
+			EndsAt:   announcementNoon.Add(time.Hour),
+		},
+		{
+			ID:       MustIDBase16(announcementTwoID),
+			Message:  "upgrade tomorrow",
+			Severity: platform.AnnouncementInfo,
+			StartsAt: announcementNoon.Add(2 * time.Hour),
+			EndsAt:   announcementNoon.Add(24 * time.Hour),
+		},
+	}
+}
+
+// FindAnnouncements testing
+func FindAnnouncements(
+	init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+	t *testing.T,
+) {
+	fixtures := announcementFixtures()
+	idOne := MustIDBase16(announcementOneID)
+	later := announcementNoon.Add(3 * time.Hour)
+	lastWeek := announcementNoon.Add(-7 * 24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		filter platform.AnnouncementFilter
+		want   []*platform.Announcement
+	}{
+		{
+			name: "all announcements",
+			want: fixtures,
+		},
+		{
+			name:   "announcement by id",
+			filter: platform.AnnouncementFilter{ID: &idOne},
+			want:   fixtures[:1],
+		},
+		{
+			name:   "announcements active now",
+			filter: platform.AnnouncementFilter{ActiveAt: &announcementNoon},
+			want:   fixtures[:1],
+		},
+		{
+			name:   "announcements active later",
+			filter: platform.AnnouncementFilter{ActiveAt: &later},
+			want:   fixtures[1:],
+		},
+		{
+			name:   "no announcement active",
+			filter: platform.AnnouncementFilter{ActiveAt: &lastWeek},
+			want:   []*platform.Announcement{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(AnnouncementFields{Announcements: announcementFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			as, err := s.FindAnnouncements(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find announcements: %v", err)
+			}
+
+			if diff := cmp.Diff(as, tt.want, announcementCmpOptions...); diff != "" {
+				t.Errorf("announcements are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// CreateAnnouncement testing
+func CreateAnnouncement(
+	init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+	t *testing.T,
+) {
+	fixtures := announcementFixtures()
+
+	tests := []struct {
+		name         string
+		announcement *platform.Announcement
+		wantErr      bool
+		want         []*platform.Announcement
+	}{
+		{
+			name: "create an announcement",
+			announcement: &platform.Announcement{
+				Message:  "storage is degraded",
+				Severity: platform.AnnouncementCritical,
+				StartsAt: announcementNoon,
+				EndsAt:   announcementNoon.Add(time.Hour),
+			},
+			want: append(fixtures[:2:2], &platform.Announcement{
+				ID:       MustIDBase16(announcementNewID),
+				Message:  "storage is degraded",
+				Severity: platform.AnnouncementCritical,
+				StartsAt: announcementNoon,
+				EndsAt:   announcementNoon.Add(time.Hour),
+			}),
+		},
+		{
+			name: "unknown severity",
+			announcement: &platform.Announcement{
+				Message:  "storage is degraded",
+				Severity: platform.AnnouncementSeverity("apocalyptic"),
+				StartsAt: announcementNoon,
+				EndsAt:   announcementNoon.Add(time.Hour),
+			},
+			wantErr: true,
+			want:    fixtures,
+		},
+		{
+			name: "window ends before it starts",
+			announcement: &platform.Announcement{
+				Message:  "storage is degraded",
+				Severity: platform.AnnouncementCritical,
+				StartsAt: announcementNoon,
+				EndsAt:   announcementNoon.Add(-time.Hour),
+			},
+			wantErr: true,
+			want:    fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(AnnouncementFields{
+				IDGenerator:   mock.NewIDGenerator(announcementNewID, t),
+				Announcements: announcementFixtures(),
+			}, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateAnnouncement(ctx, tt.announcement)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && platform.ErrorCode(err) != platform.EInvalid {
+				t.Fatalf("expected error code %s, got %v", platform.EInvalid, err)
+			}
+
+			as, err := s.FindAnnouncements(ctx, platform.AnnouncementFilter{})
+			if err != nil {
+				t.Fatalf("failed to find announcements: %v", err)
+			}
+			if diff := cmp.Diff(as, tt.want, announcementCmpOptions...); diff != "" {
+				t.Errorf("announcements are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateAnnouncement testing
+func UpdateAnnouncement(
+	init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+	t *testing.T,
+) {
+	message := "maintenance extended"
+	endsAt := announcementNoon.Add(2 * time.Hour)
+	startsAt := announcementNoon.Add(3 * time.Hour)
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		upd      platform.AnnouncementUpdate
+		wantCode string
+		want     *platform.Announcement
+	}{
+		{
+			name: "update message and window",
+			id:   MustIDBase16(announcementOneID),
+			upd:  platform.AnnouncementUpdate{Message: &message, EndsAt: &endsAt},
+			want: &platform.Announcement{
+				ID:       MustIDBase16(announcementOneID),
+				Message:  message,
+				Severity: platform.AnnouncementWarning,
+				StartsAt: announcementNoon.Add(-time.Hour),
+				EndsAt:   endsAt,
+			},
+		},
+		{
+			name:     "window ends before it starts",
+			id:       MustIDBase16(announcementOneID),
+			upd:      platform.AnnouncementUpdate{StartsAt: &startsAt},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name:     "missing announcement",
+			id:       MustIDBase16(announcementNewID),
+			upd:      platform.AnnouncementUpdate{Message: &message},
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(AnnouncementFields{Announcements: announcementFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			a, err := s.UpdateAnnouncement(ctx, tt.id, tt.upd)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to update announcement: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(a, tt.want); diff != "" {
+				t.Errorf("announcement is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteAnnouncement testing
+func DeleteAnnouncement(
+	init func(AnnouncementFields, *testing.T) (platform.AnnouncementService, string, func()),
+	t *testing.T,
+) {
+	fixtures := announcementFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     []*platform.Announcement
+	}{
+		{
+			name: "delete an announcement",
+			id:   MustIDBase16(announcementOneID),
+			want: fixtures[1:],
+		},
+		{
+			name:     "delete a missing announcement",
+			id:       MustIDBase16(announcementNewID),
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(AnnouncementFields{Announcements: announcementFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteAnnouncement(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete announcement: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			as, err := s.FindAnnouncements(ctx, platform.AnnouncementFilter{})
+			if err != nil {
+				t.Fatalf("failed to find announcements: %v", err)
+			}
+			if diff := cmp.Diff(as, tt.want, announcementCmpOptions...); diff != "" {
+				t.Errorf("announcements are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
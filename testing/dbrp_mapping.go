@@ -364,6 +364,46 @@ func FindDBRPMappings(
 				},
 			},
 		},
+		{
+			name: "find dbrpMappings by organization",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						Default:         false,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster2",
+						Database:        "database2",
+						RetentionPolicy: "retention_policy2",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg2ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+				},
+			},
+			args: args{
+				filter: platform.DBRPMappingFilter{
+					OrganizationID: idPtr(MustIDBase16(dbrpOrg1ID)),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						Default:         false,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+				},
+			},
+		},
 		{
 			name: "find default rp from dbrpMappings",
 			fields: DBRPMappingFields{
@@ -0,0 +1,432 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	expectedReporterOneID = "020f755c3c082200"
+	expectedReporterTwoID = "020f755c3c082201"
+	expectedReporterNewID = "020f755c3c082202"
+)
+
+var expectedReporterCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.ExpectedReporter) []*platform.ExpectedReporter {
+		out := append([]*platform.ExpectedReporter(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}),
+}
+
+// ExpectedReporterFields will include the IDGenerator, the organizations, the buckets and the expected reporters
+type ExpectedReporterFields struct {
+	IDGenerator       platform.IDGenerator
+	Organizations     []*platform.Organization
+	Buckets           []*platform.Bucket
+	ExpectedReporters []*platform.ExpectedReporter
+}
+
+// ExpectedReporterService tests all the service functions.
+func ExpectedReporterService(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindExpectedReporterByID",
+			fn:   FindExpectedReporterByID,
+		},
+		{
+			name: "FindExpectedReporters",
+			fn:   FindExpectedReporters,
+		},
+		{
+			name: "CreateExpectedReporter",
+			fn:   CreateExpectedReporter,
+		},
+		{
+			name: "UpdateExpectedReporter",
+			fn:   UpdateExpectedReporter,
+		},
+		{
+			name: "DeleteExpectedReporter",
+			fn:   DeleteExpectedReporter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func expectedReporterFields(idGen platform.IDGenerator) ExpectedReporterFields {
+	return ExpectedReporterFields{
+		IDGenerator: idGen,
+		Organizations: []*platform.Organization{
+			{
+				ID:   MustIDBase16(orgOneID),
+				Name: "org1",
+			},
+			{
+				ID:   MustIDBase16(orgTwoID),
+				Name: "org2",
+			},
+		},
+		Buckets: []*platform.Bucket{
+			{
+				ID:             MustIDBase16(bucketOneID),
+				OrganizationID: MustIDBase16(orgOneID),
+				Name:           "fleet",
+			},
+			{
+				ID:             MustIDBase16(bucketTwoID),
+				OrganizationID: MustIDBase16(orgOneID),
+				Name:           "sensors",
+			},
+		},
+		ExpectedReporters: expectedReporterFixtures(),
+	}
+}
+
+func expectedReporterFixtures() []*platform.ExpectedReporter {
+	return []*platform.ExpectedReporter{
+		{
+			ID:       MustIDBase16(expectedReporterOneID),
+			OrgID:    MustIDBase16(orgOneID),
+			BucketID: MustIDBase16(bucketOneID),
+			TagKey:   "host",
+			TagValue: "server01",
+			Interval: time.Minute,
+		},
+		{
+			ID:          MustIDBase16(expectedReporterTwoID),
+			OrgID:       MustIDBase16(orgOneID),
+			BucketID:    MustIDBase16(bucketTwoID),
+			Measurement: "temperature",
+			TagKey:      "sensor",
+			TagValue:    "s-42",
+			Interval:    10 * time.Minute,
+		},
+	}
+}
+
+// FindExpectedReporterByID testing
+func FindExpectedReporterByID(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	fixtures := expectedReporterFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     *platform.ExpectedReporter
+	}{
+		{
+			name: "find expected reporter by id",
+			id:   MustIDBase16(expectedReporterTwoID),
+			want: fixtures[1],
+		},
+		{
+			name:     "missing expected reporter",
+			id:       MustIDBase16(expectedReporterNewID),
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(expectedReporterFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			r, err := s.FindExpectedReporterByID(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to find expected reporter: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(r, tt.want); diff != "" {
+				t.Errorf("expected reporter is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindExpectedReporters testing
+func FindExpectedReporters(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	fixtures := expectedReporterFixtures()
+	orgID := MustIDBase16(orgOneID)
+	otherOrgID := MustIDBase16(orgTwoID)
+	bucketID := MustIDBase16(bucketTwoID)
+
+	tests := []struct {
+		name   string
+		filter platform.ExpectedReporterFilter
+		want   []*platform.ExpectedReporter
+	}{
+		{
+			name: "all expected reporters",
+			want: fixtures,
+		},
+		{
+			name:   "expected reporters by org",
+			filter: platform.ExpectedReporterFilter{OrgID: &orgID},
+			want:   fixtures,
+		},
+		{
+			name:   "expected reporters by bucket",
+			filter: platform.ExpectedReporterFilter{BucketID: &bucketID},
+			want:   fixtures[1:],
+		},
+		{
+			name:   "no expected reporter in org",
+			filter: platform.ExpectedReporterFilter{OrgID: &otherOrgID},
+			want:   []*platform.ExpectedReporter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(expectedReporterFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			rs, err := s.FindExpectedReporters(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find expected reporters: %v", err)
+			}
+
+			if diff := cmp.Diff(rs, tt.want, expectedReporterCmpOptions...); diff != "" {
+				t.Errorf("expected reporters are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// CreateExpectedReporter testing
+func CreateExpectedReporter(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	fixtures := expectedReporterFixtures()
+
+	tests := []struct {
+		name     string
+		reporter *platform.ExpectedReporter
+		wantCode string
+		want     []*platform.ExpectedReporter
+	}{
+		{
+			name: "create an expected reporter",
+			reporter: &platform.ExpectedReporter{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				TagKey:   "host",
+				TagValue: "server02",
+				Interval: time.Minute,
+			},
+			want: append(fixtures[:2:2], &platform.ExpectedReporter{
+				ID:       MustIDBase16(expectedReporterNewID),
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				TagKey:   "host",
+				TagValue: "server02",
+				Interval: time.Minute,
+			}),
+		},
+		{
+			name: "missing tag value",
+			reporter: &platform.ExpectedReporter{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				TagKey:   "host",
+				Interval: time.Minute,
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "bucket of another organization",
+			reporter: &platform.ExpectedReporter{
+				OrgID:    MustIDBase16(orgTwoID),
+				BucketID: MustIDBase16(bucketOneID),
+				TagKey:   "host",
+				TagValue: "server02",
+				Interval: time.Minute,
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "missing bucket",
+			reporter: &platform.ExpectedReporter{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketThreeID),
+				TagKey:   "host",
+				TagValue: "server02",
+				Interval: time.Minute,
+			},
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(expectedReporterFields(mock.NewIDGenerator(expectedReporterNewID, t)), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateExpectedReporter(ctx, tt.reporter)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to create expected reporter: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			rs, err := s.FindExpectedReporters(ctx, platform.ExpectedReporterFilter{})
+			if err != nil {
+				t.Fatalf("failed to find expected reporters: %v", err)
+			}
+			if diff := cmp.Diff(rs, tt.want, expectedReporterCmpOptions...); diff != "" {
+				t.Errorf("expected reporters are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateExpectedReporter testing
+func UpdateExpectedReporter(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	measurement := "cpu"
+	interval := 5 * time.Minute
+	zero := time.Duration(0)
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		upd      platform.ExpectedReporterUpdate
+		wantCode string
+		want     *platform.ExpectedReporter
+	}{
+		{
+			name: "update measurement and interval",
+			id:   MustIDBase16(expectedReporterOneID),
+			upd:  platform.ExpectedReporterUpdate{Measurement: &measurement, Interval: &interval},
+			want: &platform.ExpectedReporter{
+				ID:          MustIDBase16(expectedReporterOneID),
+				OrgID:       MustIDBase16(orgOneID),
+				BucketID:    MustIDBase16(bucketOneID),
+				Measurement: measurement,
+				TagKey:      "host",
+				TagValue:    "server01",
+				Interval:    interval,
+			},
+		},
+		{
+			name:     "invalid interval",
+			id:       MustIDBase16(expectedReporterOneID),
+			upd:      platform.ExpectedReporterUpdate{Interval: &zero},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name:     "missing expected reporter",
+			id:       MustIDBase16(expectedReporterNewID),
+			upd:      platform.ExpectedReporterUpdate{Measurement: &measurement},
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(expectedReporterFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			r, err := s.UpdateExpectedReporter(ctx, tt.id, tt.upd)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to update expected reporter: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(r, tt.want); diff != "" {
+				t.Errorf("expected reporter is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteExpectedReporter testing
+func DeleteExpectedReporter(
+	init func(ExpectedReporterFields, *testing.T) (platform.ExpectedReporterService, string, func()),
+	t *testing.T,
+) {
+	fixtures := expectedReporterFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     []*platform.ExpectedReporter
+	}{
+		{
+			name: "delete an expected reporter",
+			id:   MustIDBase16(expectedReporterOneID),
+			want: fixtures[1:],
+		},
+		{
+			name:     "delete a missing expected reporter",
+			id:       MustIDBase16(expectedReporterNewID),
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(expectedReporterFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteExpectedReporter(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete expected reporter: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			rs, err := s.FindExpectedReporters(ctx, platform.ExpectedReporterFilter{})
+			if err != nil {
+				t.Fatalf("failed to find expected reporters: %v", err)
+			}
+			if diff := cmp.Diff(rs, tt.want, expectedReporterCmpOptions...); diff != "" {
+				t.Errorf("expected reporters are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
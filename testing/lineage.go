@@ -0,0 +1,328 @@
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	lineageTaskOneID   = "020f755c3c088000"
+	lineageTaskTwoID   = "020f755c3c088001"
+	lineageTaskThreeID = "020f755c3c088002"
+	lineageRunOneID    = "020f755c3c089000"
+	lineageRunTwoID    = "020f755c3c089001"
+	lineageRunThreeID  = "020f755c3c089002"
+	lineageRunFourID   = "020f755c3c089003"
+	lineageBucketOneID = "020f755c3c08a000"
+	lineageBucketTwoID = "020f755c3c08a001"
+	lineageBucketDstID = "020f755c3c08a002"
+	lineageBucketAggID = "020f755c3c08a003"
+)
+
+var lineageRecordedAt = time.Date(2019, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+// LineageFields will include the IDGenerator, the time and the lineage records, added in order.
+type LineageFields struct {
+	IDGenerator    platform.IDGenerator
+	NowFn          func() time.Time
+	LineageRecords []*platform.LineageRecord
+}
+
+// LineageService tests all the service functions.
+func LineageService(
+	init func(LineageFields, *testing.T) (platform.LineageService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(LineageFields, *testing.T) (platform.LineageService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "AddLineageRecord",
+			fn:   AddLineageRecord,
+		},
+		{
+			name: "FindLineageRecords",
+			fn:   FindLineageRecords,
+		},
+		{
+			name: "FindLineage",
+			fn:   FindLineage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+// sequentialIDGenerator generates the IDs following the base 16 ID first.
+func sequentialIDGenerator(first string) platform.IDGenerator {
+	next := MustIDBase16(first)
+	return mock.IDGenerator{
+		IDFn: func() platform.ID {
+			id := next
+			next++
+			return id
+		},
+	}
+}
+
+func lineageFields() LineageFields {
+	return LineageFields{
+		IDGenerator:    sequentialIDGenerator("020f755c3c08b000"),
+		NowFn:          func() time.Time { return lineageRecordedAt },
+		LineageRecords: lineageFixtures(),
+	}
+}
+
+// lineageFixtures are the records of two runs of a task downsampling buckets one and two into the destination bucket,
+// of a task aggregating the destination bucket, and of a task of another org, oldest first.
+func lineageFixtures() []*platform.LineageRecord {
+	return []*platform.LineageRecord{
+		{
+			ID:          MustIDBase16("020f755c3c08b000"),
+			OrgID:       MustIDBase16(orgOneID),
+			TaskID:      MustIDBase16(lineageTaskOneID),
+			RunID:       MustIDBase16(lineageRunOneID),
+			Sources:     []platform.ID{MustIDBase16(lineageBucketOneID), MustIDBase16(lineageBucketTwoID)},
+			Destination: MustIDBase16(lineageBucketDstID),
+			Start:       lineageRecordedAt.Add(-3 * time.Hour),
+			Stop:        lineageRecordedAt.Add(-2 * time.Hour),
+			RecordedAt:  lineageRecordedAt.Add(-2 * time.Hour),
+		},
+		{
+			ID:          MustIDBase16("020f755c3c08b001"),
+			OrgID:       MustIDBase16(orgOneID),
+			TaskID:      MustIDBase16(lineageTaskOneID),
+			RunID:       MustIDBase16(lineageRunTwoID),
+			Sources:     []platform.ID{MustIDBase16(lineageBucketOneID), MustIDBase16(lineageBucketTwoID)},
+			Destination: MustIDBase16(lineageBucketDstID),
+			Start:       lineageRecordedAt.Add(-2 * time.Hour),
+			Stop:        lineageRecordedAt.Add(-time.Hour),
+			RecordedAt:  lineageRecordedAt.Add(-time.Hour),
+		},
+		{
+			ID:          MustIDBase16("020f755c3c08b002"),
+			OrgID:       MustIDBase16(orgOneID),
+			TaskID:      MustIDBase16(lineageTaskTwoID),
+			RunID:       MustIDBase16(lineageRunThreeID),
+			Sources:     []platform.ID{MustIDBase16(lineageBucketDstID)},
+			Destination: MustIDBase16(lineageBucketAggID),
+			RecordedAt:  lineageRecordedAt.Add(-time.Hour),
+		},
+		{
+			ID:          MustIDBase16("020f755c3c08b003"),
+			OrgID:       MustIDBase16(orgTwoID),
+			TaskID:      MustIDBase16(lineageTaskThreeID),
+			RunID:       MustIDBase16(lineageRunFourID),
+			Sources:     []platform.ID{MustIDBase16(lineageBucketAggID)},
+			Destination: MustIDBase16(lineageBucketOneID),
+			RecordedAt:  lineageRecordedAt.Add(-time.Hour),
+		},
+	}
+}
+
+// AddLineageRecord testing
+func AddLineageRecord(
+	init func(LineageFields, *testing.T) (platform.LineageService, string, func()),
+	t *testing.T,
+) {
+	t.Run("record the lineage of a run", func(t *testing.T) {
+		s, _, done := init(lineageFields(), t)
+		defer done()
+		ctx := context.Background()
+
+		r := &platform.LineageRecord{
+			OrgID:       MustIDBase16(orgOneID),
+			TaskID:      MustIDBase16(lineageTaskTwoID),
+			RunID:       MustIDBase16(lineageRunFourID),
+			Destination: MustIDBase16(lineageBucketAggID),
+		}
+		if err := s.AddLineageRecord(ctx, r); err != nil {
+			t.Fatalf("failed to add lineage record: %v", err)
+		}
+
+		want := &platform.LineageRecord{
+			ID:          MustIDBase16("020f755c3c08b004"),
+			OrgID:       MustIDBase16(orgOneID),
+			TaskID:      MustIDBase16(lineageTaskTwoID),
+			RunID:       MustIDBase16(lineageRunFourID),
+			Sources:     []platform.ID{},
+			Destination: MustIDBase16(lineageBucketAggID),
+			RecordedAt:  lineageRecordedAt,
+		}
+		taskID := MustIDBase16(lineageTaskTwoID)
+		rs, err := s.FindLineageRecords(ctx, platform.LineageRecordFilter{TaskID: &taskID})
+		if err != nil {
+			t.Fatalf("failed to find lineage records: %v", err)
+		}
+		if diff := cmp.Diff(rs, []*platform.LineageRecord{want, lineageFixtures()[2]}); diff != "" {
+			t.Errorf("lineage records are different -got/+want\ndiff %s", diff)
+		}
+	})
+
+	t.Run("a record without destination is invalid", func(t *testing.T) {
+		s, opPrefix, done := init(lineageFields(), t)
+		defer done()
+
+		err := s.AddLineageRecord(context.Background(), &platform.LineageRecord{
+			OrgID:  MustIDBase16(orgOneID),
+			TaskID: MustIDBase16(lineageTaskTwoID),
+		})
+		ErrorsEqual(t, err, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   opPrefix + platform.OpAddLineageRecord,
+			Msg:  "lineage record orgID, taskID and destination are required",
+		})
+	})
+}
+
+// FindLineageRecords testing
+func FindLineageRecords(
+	init func(LineageFields, *testing.T) (platform.LineageService, string, func()),
+	t *testing.T,
+) {
+	fixtures := lineageFixtures()
+	orgID := MustIDBase16(orgOneID)
+	taskID := MustIDBase16(lineageTaskOneID)
+	runID := MustIDBase16(lineageRunTwoID)
+	bucketID := MustIDBase16(lineageBucketDstID)
+
+	tests := []struct {
+		name   string
+		filter platform.LineageRecordFilter
+		want   []*platform.LineageRecord
+	}{
+		{
+			name: "all records, latest first",
+			want: []*platform.LineageRecord{fixtures[3], fixtures[2], fixtures[1], fixtures[0]},
+		},
+		{
+			name:   "records of a task",
+			filter: platform.LineageRecordFilter{TaskID: &taskID},
+			want:   []*platform.LineageRecord{fixtures[1], fixtures[0]},
+		},
+		{
+			name:   "record of a run",
+			filter: platform.LineageRecordFilter{RunID: &runID},
+			want:   []*platform.LineageRecord{fixtures[1]},
+		},
+		{
+			name:   "records of an org reading or writing a bucket",
+			filter: platform.LineageRecordFilter{OrgID: &orgID, BucketID: &bucketID},
+			want:   []*platform.LineageRecord{fixtures[2], fixtures[1], fixtures[0]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(lineageFields(), t)
+			defer done()
+
+			rs, err := s.FindLineageRecords(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find lineage records: %v", err)
+			}
+			if diff := cmp.Diff(rs, tt.want); diff != "" {
+				t.Errorf("lineage records are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindLineage testing
+func FindLineage(
+	init func(LineageFields, *testing.T) (platform.LineageService, string, func()),
+	t *testing.T,
+) {
+	downsampled := platform.LineageEdge{
+		TaskID:      MustIDBase16(lineageTaskOneID),
+		Destination: MustIDBase16(lineageBucketDstID),
+		Runs:        2,
+		LastRunID:   MustIDBase16(lineageRunTwoID),
+		Start:       lineageRecordedAt.Add(-3 * time.Hour),
+		Stop:        lineageRecordedAt.Add(-time.Hour),
+	}
+	fromOne, fromTwo := downsampled, downsampled
+	fromOne.Source = MustIDBase16(lineageBucketOneID)
+	fromTwo.Source = MustIDBase16(lineageBucketTwoID)
+	aggregated := platform.LineageEdge{
+		TaskID:      MustIDBase16(lineageTaskTwoID),
+		Source:      MustIDBase16(lineageBucketDstID),
+		Destination: MustIDBase16(lineageBucketAggID),
+		Runs:        1,
+		LastRunID:   MustIDBase16(lineageRunThreeID),
+	}
+
+	tests := []struct {
+		name     string
+		filter   platform.LineageFilter
+		want     *platform.Lineage
+		wantCode string
+	}{
+		{
+			name: "upstream of a bucket, in its org only",
+			filter: platform.LineageFilter{
+				OrgID:     MustIDBase16(orgOneID),
+				BucketID:  MustIDBase16(lineageBucketAggID),
+				Direction: platform.LineageUpstream,
+			},
+			want: &platform.Lineage{
+				BucketID:  MustIDBase16(lineageBucketAggID),
+				Direction: platform.LineageUpstream,
+				Edges:     []platform.LineageEdge{aggregated, fromOne, fromTwo},
+			},
+		},
+		{
+			name: "downstream of a bucket",
+			filter: platform.LineageFilter{
+				OrgID:     MustIDBase16(orgOneID),
+				BucketID:  MustIDBase16(lineageBucketTwoID),
+				Direction: platform.LineageDownstream,
+			},
+			want: &platform.Lineage{
+				BucketID:  MustIDBase16(lineageBucketTwoID),
+				Direction: platform.LineageDownstream,
+				Edges:     []platform.LineageEdge{fromTwo, aggregated},
+			},
+		},
+		{
+			name: "invalid direction",
+			filter: platform.LineageFilter{
+				OrgID:     MustIDBase16(orgOneID),
+				BucketID:  MustIDBase16(lineageBucketTwoID),
+				Direction: "sideways",
+			},
+			wantCode: platform.EInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(lineageFields(), t)
+			defer done()
+
+			l, err := s.FindLineage(context.Background(), tt.filter)
+			if tt.wantCode != "" {
+				if code := platform.ErrorCode(err); code != tt.wantCode {
+					t.Fatalf("got error code %q, want %q", code, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to find lineage: %v", err)
+			}
+			if diff := cmp.Diff(l, tt.want); diff != "" {
+				t.Errorf("lineage is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	measurementSchemaOneID = "020f755c3c087000"
+	measurementSchemaTwoID = "020f755c3c087001"
+	measurementSchemaNewID = "020f755c3c087002"
+)
+
+var measurementSchemaCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.MeasurementSchema) []*platform.MeasurementSchema {
+		out := append([]*platform.MeasurementSchema(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}),
+}
+
+// MeasurementSchemaFields will include the IDGenerator, the organizations, the buckets and the measurement schemas
+type MeasurementSchemaFields struct {
+	IDGenerator        platform.IDGenerator
+	Organizations      []*platform.Organization
+	Buckets            []*platform.Bucket
+	MeasurementSchemas []*platform.MeasurementSchema
+}
+
+// MeasurementSchemaService tests all the service functions.
+func MeasurementSchemaService(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindMeasurementSchemaByID",
+			fn:   FindMeasurementSchemaByID,
+		},
+		{
+			name: "FindMeasurementSchemas",
+			fn:   FindMeasurementSchemas,
+		},
+		{
+			name: "CreateMeasurementSchema",
+			fn:   CreateMeasurementSchema,
+		},
+		{
+			name: "UpdateMeasurementSchema",
+			fn:   UpdateMeasurementSchema,
+		},
+		{
+			name: "DeleteMeasurementSchema",
+			fn:   DeleteMeasurementSchema,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func measurementSchemaFields(idGen platform.IDGenerator) MeasurementSchemaFields {
+	return MeasurementSchemaFields{
+		IDGenerator: idGen,
+		Organizations: []*platform.Organization{
+			{
+				ID:   MustIDBase16(orgOneID),
+				Name: "org1",
+			},
+			{
+				ID:   MustIDBase16(orgTwoID),
+				Name: "org2",
+			},
+		},
+		Buckets: []*platform.Bucket{
+			{
+				ID:             MustIDBase16(bucketOneID),
+				OrganizationID: MustIDBase16(orgOneID),
+				Name:           "telemetry",
+				SchemaType:     platform.BucketSchemaTypeExplicit,
+			},
+			{
+				ID:             MustIDBase16(bucketTwoID),
+				OrganizationID: MustIDBase16(orgOneID),
+				Name:           "scratch",
+			},
+		},
+		MeasurementSchemas: measurementSchemaFixtures(),
+	}
+}
+
+func measurementSchemaFixtures() []*platform.MeasurementSchema {
+	return []*platform.MeasurementSchema{
+		{
+			ID:       MustIDBase16(measurementSchemaOneID),
+			OrgID:    MustIDBase16(orgOneID),
+			BucketID: MustIDBase16(bucketOneID),
+			Name:     "cpu",
+			Tags:     []string{"host"},
+			Fields: []platform.MeasurementSchemaField{
+				{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+			},
+		},
+		{
+			ID:       MustIDBase16(measurementSchemaTwoID),
+			OrgID:    MustIDBase16(orgOneID),
+			BucketID: MustIDBase16(bucketOneID),
+			Name:     "mem",
+			Tags:     []string{"host"},
+			Fields: []platform.MeasurementSchemaField{
+				{Name: "used", Type: platform.SchemaFieldTypeInteger},
+				{Name: "swapping", Type: platform.SchemaFieldTypeBoolean},
+			},
+		},
+	}
+}
+
+// FindMeasurementSchemaByID testing
+func FindMeasurementSchemaByID(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	fixtures := measurementSchemaFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     *platform.MeasurementSchema
+	}{
+		{
+			name: "find measurement schema by id",
+			id:   MustIDBase16(measurementSchemaTwoID),
+			want: fixtures[1],
+		},
+		{
+			name:     "missing measurement schema",
+			id:       MustIDBase16(measurementSchemaNewID),
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(measurementSchemaFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			ms, err := s.FindMeasurementSchemaByID(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to find measurement schema: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(ms, tt.want); diff != "" {
+				t.Errorf("measurement schema is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindMeasurementSchemas testing
+func FindMeasurementSchemas(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	fixtures := measurementSchemaFixtures()
+	bucketID := MustIDBase16(bucketOneID)
+	otherBucketID := MustIDBase16(bucketTwoID)
+	name := "mem"
+
+	tests := []struct {
+		name   string
+		filter platform.MeasurementSchemaFilter
+		want   []*platform.MeasurementSchema
+	}{
+		{
+			name: "all measurement schemas",
+			want: fixtures,
+		},
+		{
+			name:   "measurement schemas by bucket",
+			filter: platform.MeasurementSchemaFilter{BucketID: &bucketID},
+			want:   fixtures,
+		},
+		{
+			name:   "measurement schema by bucket and name",
+			filter: platform.MeasurementSchemaFilter{BucketID: &bucketID, Name: &name},
+			want:   fixtures[1:],
+		},
+		{
+			name:   "no measurement schema in bucket",
+			filter: platform.MeasurementSchemaFilter{BucketID: &otherBucketID},
+			want:   []*platform.MeasurementSchema{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(measurementSchemaFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			ss, err := s.FindMeasurementSchemas(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find measurement schemas: %v", err)
+			}
+
+			if diff := cmp.Diff(ss, tt.want, measurementSchemaCmpOptions...); diff != "" {
+				t.Errorf("measurement schemas are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// CreateMeasurementSchema testing
+func CreateMeasurementSchema(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	fixtures := measurementSchemaFixtures()
+	diskFields := []platform.MeasurementSchemaField{
+		{Name: "free", Type: platform.SchemaFieldTypeUnsigned},
+	}
+
+	tests := []struct {
+		name     string
+		schema   *platform.MeasurementSchema
+		wantCode string
+		want     []*platform.MeasurementSchema
+	}{
+		{
+			name: "create a measurement schema",
+			schema: &platform.MeasurementSchema{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				Name:     "disk",
+				Tags:     []string{"host", "path"},
+				Fields:   diskFields,
+			},
+			want: append(fixtures[:2:2], &platform.MeasurementSchema{
+				ID:       MustIDBase16(measurementSchemaNewID),
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				Name:     "disk",
+				Tags:     []string{"host", "path"},
+				Fields:   diskFields,
+			}),
+		},
+		{
+			name: "measurement with a schema",
+			schema: &platform.MeasurementSchema{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				Name:     "cpu",
+				Fields:   diskFields,
+			},
+			wantCode: platform.EConflict,
+			want:     fixtures,
+		},
+		{
+			name: "implicit-schema bucket",
+			schema: &platform.MeasurementSchema{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketTwoID),
+				Name:     "disk",
+				Fields:   diskFields,
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "bucket of another organization",
+			schema: &platform.MeasurementSchema{
+				OrgID:    MustIDBase16(orgTwoID),
+				BucketID: MustIDBase16(bucketOneID),
+				Name:     "disk",
+				Fields:   diskFields,
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "missing bucket",
+			schema: &platform.MeasurementSchema{
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketThreeID),
+				Name:     "disk",
+				Fields:   diskFields,
+			},
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(measurementSchemaFields(mock.NewIDGenerator(measurementSchemaNewID, t)), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateMeasurementSchema(ctx, tt.schema)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to create measurement schema: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ss, err := s.FindMeasurementSchemas(ctx, platform.MeasurementSchemaFilter{})
+			if err != nil {
+				t.Fatalf("failed to find measurement schemas: %v", err)
+			}
+			if diff := cmp.Diff(ss, tt.want, measurementSchemaCmpOptions...); diff != "" {
+				t.Errorf("measurement schemas are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateMeasurementSchema testing
+func UpdateMeasurementSchema(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name     string
+		id       platform.ID
+		upd      platform.MeasurementSchemaUpdate
+		wantCode string
+		want     *platform.MeasurementSchema
+	}{
+		{
+			name: "declare a tag and a field",
+			id:   MustIDBase16(measurementSchemaOneID),
+			upd: platform.MeasurementSchemaUpdate{
+				Tags: []string{"host", "cpu"},
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+					{Name: "throttled", Type: platform.SchemaFieldTypeBoolean},
+				},
+			},
+			want: &platform.MeasurementSchema{
+				ID:       MustIDBase16(measurementSchemaOneID),
+				OrgID:    MustIDBase16(orgOneID),
+				BucketID: MustIDBase16(bucketOneID),
+				Name:     "cpu",
+				Tags:     []string{"host", "cpu"},
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "usage", Type: platform.SchemaFieldTypeFloat},
+					{Name: "throttled", Type: platform.SchemaFieldTypeBoolean},
+				},
+			},
+		},
+		{
+			name: "remove a field",
+			id:   MustIDBase16(measurementSchemaTwoID),
+			upd: platform.MeasurementSchemaUpdate{
+				Fields: []platform.MeasurementSchemaField{
+					{Name: "used", Type: platform.SchemaFieldTypeInteger},
+				},
+			},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name:     "missing measurement schema",
+			id:       MustIDBase16(measurementSchemaNewID),
+			upd:      platform.MeasurementSchemaUpdate{Tags: []string{"host"}},
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(measurementSchemaFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			ms, err := s.UpdateMeasurementSchema(ctx, tt.id, tt.upd)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to update measurement schema: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(ms, tt.want); diff != "" {
+				t.Errorf("measurement schema is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteMeasurementSchema testing
+func DeleteMeasurementSchema(
+	init func(MeasurementSchemaFields, *testing.T) (platform.MeasurementSchemaService, string, func()),
+	t *testing.T,
+) {
+	fixtures := measurementSchemaFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     []*platform.MeasurementSchema
+	}{
+		{
+			name: "delete a measurement schema",
+			id:   MustIDBase16(measurementSchemaOneID),
+			want: fixtures[1:],
+		},
+		{
+			name:     "delete a missing measurement schema",
+			id:       MustIDBase16(measurementSchemaNewID),
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(measurementSchemaFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteMeasurementSchema(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete measurement schema: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ss, err := s.FindMeasurementSchemas(ctx, platform.MeasurementSchemaFilter{})
+			if err != nil {
+				t.Fatalf("failed to find measurement schemas: %v", err)
+			}
+			if diff := cmp.Diff(ss, tt.want, measurementSchemaCmpOptions...); diff != "" {
+				t.Errorf("measurement schemas are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
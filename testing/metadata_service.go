@@ -0,0 +1,292 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+)
+
+const metadataDashboardID = "020f755c3c0820d0"
+
+var metadataCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.Metadata) []*platform.Metadata {
+		out := append([]*platform.Metadata(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].ResourceID != out[j].ResourceID {
+				return out[i].ResourceID < out[j].ResourceID
+			}
+			return out[i].Key < out[j].Key
+		})
+		return out
+	}),
+}
+
+// MetadataServiceFields will include the metadata to populate the service with.
+type MetadataServiceFields struct {
+	Metadata []*platform.Metadata
+}
+
+// MetadataService tests all the service functions.
+func MetadataService(
+	init func(MetadataServiceFields, *testing.T) (platform.MetadataService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(MetadataServiceFields, *testing.T) (platform.MetadataService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindMetadata",
+			fn:   FindMetadata,
+		},
+		{
+			name: "SetMetadata",
+			fn:   SetMetadata,
+		},
+		{
+			name: "DeleteMetadata",
+			fn:   DeleteMetadata,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func metadataFixtures() []*platform.Metadata {
+	return []*platform.Metadata{
+		{
+			ResourceID:   MustIDBase16(bucketOneID),
+			ResourceType: platform.BucketsResourceType,
+			Key:          "cost-center",
+			Type:         platform.MetadataString,
+			Value:        "r&d",
+		},
+		{
+			ResourceID:   MustIDBase16(bucketOneID),
+			ResourceType: platform.BucketsResourceType,
+			Key:          "tier",
+			Type:         platform.MetadataNumber,
+			Value:        float64(1),
+		},
+		{
+			ResourceID:   MustIDBase16(bucketTwoID),
+			ResourceType: platform.BucketsResourceType,
+			Key:          "tier",
+			Type:         platform.MetadataNumber,
+			Value:        float64(2),
+		},
+		{
+			ResourceID:   MustIDBase16(metadataDashboardID),
+			ResourceType: platform.DashboardsResourceType,
+			Key:          "tier",
+			Type:         platform.MetadataNumber,
+			Value:        float64(1),
+		},
+	}
+}
+
+// FindMetadata testing
+func FindMetadata(
+	init func(MetadataServiceFields, *testing.T) (platform.MetadataService, string, func()),
+	t *testing.T,
+) {
+	bucketOne := MustIDBase16(bucketOneID)
+	fixtures := metadataFixtures()
+
+	tests := []struct {
+		name   string
+		filter platform.MetadataFilter
+		want   []*platform.Metadata
+	}{
+		{
+			name: "all metadata",
+			want: fixtures,
+		},
+		{
+			name:   "metadata of a resource",
+			filter: platform.MetadataFilter{ResourceID: &bucketOne},
+			want:   fixtures[:2],
+		},
+		{
+			name:   "resources with a key",
+			filter: platform.MetadataFilter{Key: "tier", ResourceType: platform.BucketsResourceType},
+			want:   fixtures[1:3],
+		},
+		{
+			name:   "resources with a typed value",
+			filter: platform.MetadataFilter{Key: "tier", Value: float64(1)},
+			want:   []*platform.Metadata{fixtures[1], fixtures[3]},
+		},
+		{
+			name:   "value of a different type does not match",
+			filter: platform.MetadataFilter{Key: "tier", Value: "1"},
+			want:   []*platform.Metadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(MetadataServiceFields{Metadata: metadataFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			ms, err := s.FindMetadata(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find metadata: %v", err)
+			}
+
+			if diff := cmp.Diff(ms, tt.want, metadataCmpOptions...); diff != "" {
+				t.Errorf("metadata are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// SetMetadata testing
+func SetMetadata(
+	init func(MetadataServiceFields, *testing.T) (platform.MetadataService, string, func()),
+	t *testing.T,
+) {
+	bucketOne := MustIDBase16(bucketOneID)
+	fixtures := metadataFixtures()
+
+	tests := []struct {
+		name     string
+		metadata *platform.Metadata
+		wantErr  bool
+		want     []*platform.Metadata
+	}{
+		{
+			name: "add a key",
+			metadata: &platform.Metadata{
+				ResourceID:   bucketOne,
+				ResourceType: platform.BucketsResourceType,
+				Key:          "archived",
+				Type:         platform.MetadataBool,
+				Value:        true,
+			},
+			want: append(fixtures[:2:2], &platform.Metadata{
+				ResourceID:   bucketOne,
+				ResourceType: platform.BucketsResourceType,
+				Key:          "archived",
+				Type:         platform.MetadataBool,
+				Value:        true,
+			}),
+		},
+		{
+			name: "replace a key with a value of another type",
+			metadata: &platform.Metadata{
+				ResourceID:   bucketOne,
+				ResourceType: platform.BucketsResourceType,
+				Key:          "tier",
+				Type:         platform.MetadataString,
+				Value:        "gold",
+			},
+			want: []*platform.Metadata{
+				fixtures[0],
+				{
+					ResourceID:   bucketOne,
+					ResourceType: platform.BucketsResourceType,
+					Key:          "tier",
+					Type:         platform.MetadataString,
+					Value:        "gold",
+				},
+			},
+		},
+		{
+			name: "value does not match type",
+			metadata: &platform.Metadata{
+				ResourceID:   bucketOne,
+				ResourceType: platform.BucketsResourceType,
+				Key:          "tier",
+				Type:         platform.MetadataBool,
+				Value:        "gold",
+			},
+			wantErr: true,
+			want:    fixtures[:2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(MetadataServiceFields{Metadata: metadataFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.SetMetadata(ctx, tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && platform.ErrorCode(err) != platform.EInvalid {
+				t.Fatalf("expected error code %s, got %v", platform.EInvalid, err)
+			}
+
+			ms, err := s.FindMetadata(ctx, platform.MetadataFilter{ResourceID: &bucketOne})
+			if err != nil {
+				t.Fatalf("failed to find metadata: %v", err)
+			}
+			if diff := cmp.Diff(ms, tt.want, metadataCmpOptions...); diff != "" {
+				t.Errorf("metadata are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteMetadata testing
+func DeleteMetadata(
+	init func(MetadataServiceFields, *testing.T) (platform.MetadataService, string, func()),
+	t *testing.T,
+) {
+	bucketOne := MustIDBase16(bucketOneID)
+	fixtures := metadataFixtures()
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode string
+		want     []*platform.Metadata
+	}{
+		{
+			name: "delete a key",
+			key:  "tier",
+			want: fixtures[:1],
+		},
+		{
+			name:     "delete a missing key",
+			key:      "owner",
+			wantCode: platform.ENotFound,
+			want:     fixtures[:2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(MetadataServiceFields{Metadata: metadataFixtures()}, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteMetadata(ctx, bucketOne, tt.key)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete metadata: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ms, err := s.FindMetadata(ctx, platform.MetadataFilter{ResourceID: &bucketOne})
+			if err != nil {
+				t.Fatalf("failed to find metadata: %v", err)
+			}
+			if diff := cmp.Diff(ms, tt.want, metadataCmpOptions...); diff != "" {
+				t.Errorf("metadata are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	sqlConnectionOneID = "020f755c3c086000"
+	sqlConnectionTwoID = "020f755c3c086001"
+	sqlConnectionNewID = "020f755c3c086002"
+)
+
+var sqlConnectionCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.SQLConnection) []*platform.SQLConnection {
+		out := append([]*platform.SQLConnection(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}),
+}
+
+// SQLConnectionFields will include the IDGenerator, the organizations and the SQL connections
+type SQLConnectionFields struct {
+	IDGenerator    platform.IDGenerator
+	Organizations  []*platform.Organization
+	SQLConnections []*platform.SQLConnection
+}
+
+// SQLConnectionService tests all the service functions.
+func SQLConnectionService(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindSQLConnectionByID",
+			fn:   FindSQLConnectionByID,
+		},
+		{
+			name: "FindSQLConnections",
+			fn:   FindSQLConnections,
+		},
+		{
+			name: "CreateSQLConnection",
+			fn:   CreateSQLConnection,
+		},
+		{
+			name: "UpdateSQLConnection",
+			fn:   UpdateSQLConnection,
+		},
+		{
+			name: "DeleteSQLConnection",
+			fn:   DeleteSQLConnection,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func sqlConnectionFields(idGen platform.IDGenerator) SQLConnectionFields {
+	return SQLConnectionFields{
+		IDGenerator: idGen,
+		Organizations: []*platform.Organization{
+			{
+				ID:   MustIDBase16(orgOneID),
+				Name: "org1",
+			},
+			{
+				ID:   MustIDBase16(orgTwoID),
+				Name: "org2",
+			},
+		},
+		SQLConnections: sqlConnectionFixtures(),
+	}
+}
+
+func sqlConnectionFixtures() []*platform.SQLConnection {
+	return []*platform.SQLConnection{
+		{
+			ID:           MustIDBase16(sqlConnectionOneID),
+			OrgID:        MustIDBase16(orgOneID),
+			Name:         "metrics",
+			DriverName:   "postgres",
+			SecretKey:    "metrics-dsn",
+			MaxOpenConns: 4,
+		},
+		{
+			ID:              MustIDBase16(sqlConnectionTwoID),
+			OrgID:           MustIDBase16(orgOneID),
+			Name:            "inventory",
+			Description:     "assets of the fleet",
+			DriverName:      "mysql",
+			SecretKey:       "inventory-dsn",
+			ConnMaxLifetime: time.Hour,
+		},
+	}
+}
+
+// FindSQLConnectionByID testing
+func FindSQLConnectionByID(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	fixtures := sqlConnectionFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     *platform.SQLConnection
+	}{
+		{
+			name: "find sql connection by id",
+			id:   MustIDBase16(sqlConnectionTwoID),
+			want: fixtures[1],
+		},
+		{
+			name:     "missing sql connection",
+			id:       MustIDBase16(sqlConnectionNewID),
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(sqlConnectionFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			c, err := s.FindSQLConnectionByID(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to find sql connection: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(c, tt.want); diff != "" {
+				t.Errorf("sql connection is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindSQLConnections testing
+func FindSQLConnections(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	fixtures := sqlConnectionFixtures()
+	orgID := MustIDBase16(orgOneID)
+	otherOrgID := MustIDBase16(orgTwoID)
+	name := "metrics"
+
+	tests := []struct {
+		name   string
+		filter platform.SQLConnectionFilter
+		want   []*platform.SQLConnection
+	}{
+		{
+			name: "all sql connections",
+			want: fixtures,
+		},
+		{
+			name:   "sql connection by org and name",
+			filter: platform.SQLConnectionFilter{OrgID: &orgID, Name: &name},
+			want:   fixtures[:1],
+		},
+		{
+			name:   "no sql connection in org",
+			filter: platform.SQLConnectionFilter{OrgID: &otherOrgID},
+			want:   []*platform.SQLConnection{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(sqlConnectionFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			cs, err := s.FindSQLConnections(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find sql connections: %v", err)
+			}
+
+			if diff := cmp.Diff(cs, tt.want, sqlConnectionCmpOptions...); diff != "" {
+				t.Errorf("sql connections are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// CreateSQLConnection testing
+func CreateSQLConnection(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	fixtures := sqlConnectionFixtures()
+
+	tests := []struct {
+		name       string
+		connection *platform.SQLConnection
+		wantCode   string
+		want       []*platform.SQLConnection
+	}{
+		{
+			name: "create a sql connection",
+			connection: &platform.SQLConnection{
+				OrgID:      MustIDBase16(orgOneID),
+				Name:       "billing",
+				DriverName: "postgres",
+				SecretKey:  "billing-dsn",
+			},
+			want: append(fixtures[:2:2], &platform.SQLConnection{
+				ID:         MustIDBase16(sqlConnectionNewID),
+				OrgID:      MustIDBase16(orgOneID),
+				Name:       "billing",
+				DriverName: "postgres",
+				SecretKey:  "billing-dsn",
+			}),
+		},
+		{
+			name: "same name in another org",
+			connection: &platform.SQLConnection{
+				OrgID:      MustIDBase16(orgTwoID),
+				Name:       "metrics",
+				DriverName: "postgres",
+				SecretKey:  "metrics-dsn",
+			},
+			want: append(fixtures[:2:2], &platform.SQLConnection{
+				ID:         MustIDBase16(sqlConnectionNewID),
+				OrgID:      MustIDBase16(orgTwoID),
+				Name:       "metrics",
+				DriverName: "postgres",
+				SecretKey:  "metrics-dsn",
+			}),
+		},
+		{
+			name: "name already exists",
+			connection: &platform.SQLConnection{
+				OrgID:      MustIDBase16(orgOneID),
+				Name:       "metrics",
+				DriverName: "mysql",
+				SecretKey:  "other-dsn",
+			},
+			wantCode: platform.EConflict,
+			want:     fixtures,
+		},
+		{
+			name: "missing organization",
+			connection: &platform.SQLConnection{
+				OrgID:      MustIDBase16(sqlConnectionNewID),
+				Name:       "billing",
+				DriverName: "postgres",
+				SecretKey:  "billing-dsn",
+			},
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+		{
+			name: "unsupported driver",
+			connection: &platform.SQLConnection{
+				OrgID:      MustIDBase16(orgOneID),
+				Name:       "billing",
+				DriverName: "sqlite3",
+				SecretKey:  "billing-dsn",
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(sqlConnectionFields(mock.NewIDGenerator(sqlConnectionNewID, t)), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateSQLConnection(ctx, tt.connection)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to create sql connection: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			cs, err := s.FindSQLConnections(ctx, platform.SQLConnectionFilter{})
+			if err != nil {
+				t.Fatalf("failed to find sql connections: %v", err)
+			}
+			if diff := cmp.Diff(cs, tt.want, sqlConnectionCmpOptions...); diff != "" {
+				t.Errorf("sql connections are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateSQLConnection testing
+func UpdateSQLConnection(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	secretKey := "metrics-ro-dsn"
+	maxIdleConns := 2
+	taken := "inventory"
+	negative := -1
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		upd      platform.SQLConnectionUpdate
+		wantCode string
+		want     *platform.SQLConnection
+	}{
+		{
+			name: "update secret key and pool limits",
+			id:   MustIDBase16(sqlConnectionOneID),
+			upd:  platform.SQLConnectionUpdate{SecretKey: &secretKey, MaxIdleConns: &maxIdleConns},
+			want: &platform.SQLConnection{
+				ID:           MustIDBase16(sqlConnectionOneID),
+				OrgID:        MustIDBase16(orgOneID),
+				Name:         "metrics",
+				DriverName:   "postgres",
+				SecretKey:    secretKey,
+				MaxOpenConns: 4,
+				MaxIdleConns: maxIdleConns,
+			},
+		},
+		{
+			name:     "name already exists",
+			id:       MustIDBase16(sqlConnectionOneID),
+			upd:      platform.SQLConnectionUpdate{Name: &taken},
+			wantCode: platform.EConflict,
+		},
+		{
+			name:     "negative pool limit",
+			id:       MustIDBase16(sqlConnectionOneID),
+			upd:      platform.SQLConnectionUpdate{MaxOpenConns: &negative},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name:     "missing sql connection",
+			id:       MustIDBase16(sqlConnectionNewID),
+			upd:      platform.SQLConnectionUpdate{SecretKey: &secretKey},
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(sqlConnectionFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			c, err := s.UpdateSQLConnection(ctx, tt.id, tt.upd)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to update sql connection: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(c, tt.want); diff != "" {
+				t.Errorf("sql connection is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteSQLConnection testing
+func DeleteSQLConnection(
+	init func(SQLConnectionFields, *testing.T) (platform.SQLConnectionService, string, func()),
+	t *testing.T,
+) {
+	fixtures := sqlConnectionFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     []*platform.SQLConnection
+	}{
+		{
+			name: "delete a sql connection",
+			id:   MustIDBase16(sqlConnectionOneID),
+			want: fixtures[1:],
+		},
+		{
+			name:     "delete a missing sql connection",
+			id:       MustIDBase16(sqlConnectionNewID),
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(sqlConnectionFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteSQLConnection(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete sql connection: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			cs, err := s.FindSQLConnections(ctx, platform.SQLConnectionFilter{})
+			if err != nil {
+				t.Fatalf("failed to find sql connections: %v", err)
+			}
+			if diff := cmp.Diff(cs, tt.want, sqlConnectionCmpOptions...); diff != "" {
+				t.Errorf("sql connections are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+)
+
+const (
+	taskDependencyTaskOneID   = "020f755c3c088000"
+	taskDependencyTaskTwoID   = "020f755c3c088001"
+	taskDependencyTaskThreeID = "020f755c3c088002"
+)
+
+var taskDependencyUpdatedAt = time.Date(2019, time.March, 2, 12, 0, 0, 0, time.UTC)
+
+var taskDependencyCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.TaskDependencies) []*platform.TaskDependencies {
+		out := append([]*platform.TaskDependencies(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].TaskID < out[j].TaskID
+		})
+		return out
+	}),
+}
+
+// TaskDependencyFields will include the time and the dependencies of the tasks
+type TaskDependencyFields struct {
+	NowFn            func() time.Time
+	TaskDependencies []*platform.TaskDependencies
+}
+
+// TaskDependencyService tests all the service functions.
+func TaskDependencyService(
+	init func(TaskDependencyFields, *testing.T) (platform.TaskDependencyService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(TaskDependencyFields, *testing.T) (platform.TaskDependencyService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindTaskDependencies",
+			fn:   FindTaskDependencies,
+		},
+		{
+			name: "PutTaskDependencies",
+			fn:   PutTaskDependencies,
+		},
+		{
+			name: "DeleteTaskDependencies",
+			fn:   DeleteTaskDependencies,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func taskDependencyFields() TaskDependencyFields {
+	return TaskDependencyFields{
+		NowFn:            func() time.Time { return taskDependencyUpdatedAt },
+		TaskDependencies: taskDependencyFixtures(),
+	}
+}
+
+func taskDependencyFixtures() []*platform.TaskDependencies {
+	return []*platform.TaskDependencies{
+		{
+			TaskID: MustIDBase16(taskDependencyTaskOneID),
+			OrgID:  MustIDBase16(orgOneID),
+			Dependencies: []platform.TaskDependency{
+				{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+				{Type: platform.TaskDependencyEndpoint, Name: "https://hooks.example.com"},
+				{Type: platform.TaskDependencySecret, Name: "api-token"},
+			},
+			UpdatedAt: taskDependencyUpdatedAt,
+		},
+		{
+			TaskID: MustIDBase16(taskDependencyTaskTwoID),
+			OrgID:  MustIDBase16(orgOneID),
+			Dependencies: []platform.TaskDependency{
+				{Type: platform.TaskDependencyBucket, Name: "downsampled"},
+				{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+			},
+			UpdatedAt: taskDependencyUpdatedAt,
+		},
+		{
+			TaskID: MustIDBase16(taskDependencyTaskThreeID),
+			OrgID:  MustIDBase16(orgTwoID),
+			Dependencies: []platform.TaskDependency{
+				{Type: platform.TaskDependencySecret, Name: "api-token"},
+			},
+			UpdatedAt: taskDependencyUpdatedAt,
+		},
+	}
+}
+
+// FindTaskDependencies testing
+func FindTaskDependencies(
+	init func(TaskDependencyFields, *testing.T) (platform.TaskDependencyService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskDependencyFixtures()
+	taskID := MustIDBase16(taskDependencyTaskTwoID)
+	orgID := MustIDBase16(orgOneID)
+
+	tests := []struct {
+		name   string
+		filter platform.TaskDependencyFilter
+		want   []*platform.TaskDependencies
+	}{
+		{
+			name: "all task dependencies",
+			want: fixtures,
+		},
+		{
+			name:   "dependencies of a task",
+			filter: platform.TaskDependencyFilter{TaskID: &taskID},
+			want:   fixtures[1:2],
+		},
+		{
+			name: "tasks depending on a bucket",
+			filter: platform.TaskDependencyFilter{References: []platform.TaskDependency{
+				{Type: platform.TaskDependencyBucket, Name: "telemetry"},
+			}},
+			want: fixtures[:2],
+		},
+		{
+			name: "tasks of an org depending on a secret",
+			filter: platform.TaskDependencyFilter{OrgID: &orgID, References: []platform.TaskDependency{
+				{Type: platform.TaskDependencySecret, Name: "api-token"},
+			}},
+			want: fixtures[:1],
+		},
+		{
+			name: "no task depending on a bucket",
+			filter: platform.TaskDependencyFilter{References: []platform.TaskDependency{
+				{Type: platform.TaskDependencyBucket, Name: "api-token"},
+			}},
+			want: []*platform.TaskDependencies{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskDependencyFields(), t)
+			defer done()
+			ctx := context.Background()
+
+			ds, err := s.FindTaskDependencies(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find task dependencies: %v", err)
+			}
+
+			if diff := cmp.Diff(ds, tt.want, taskDependencyCmpOptions...); diff != "" {
+				t.Errorf("task dependencies are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// PutTaskDependencies testing
+func PutTaskDependencies(
+	init func(TaskDependencyFields, *testing.T) (platform.TaskDependencyService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskDependencyFixtures()
+	updatedAt := taskDependencyUpdatedAt.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		dependencies *platform.TaskDependencies
+		wantCode     string
+		want         []*platform.TaskDependencies
+	}{
+		{
+			name: "replace the dependencies of a task",
+			dependencies: &platform.TaskDependencies{
+				TaskID: MustIDBase16(taskDependencyTaskTwoID),
+				OrgID:  MustIDBase16(orgOneID),
+				Dependencies: []platform.TaskDependency{
+					{Type: platform.TaskDependencyBucket, Name: "downsampled"},
+				},
+			},
+			want: []*platform.TaskDependencies{
+				fixtures[0],
+				{
+					TaskID: MustIDBase16(taskDependencyTaskTwoID),
+					OrgID:  MustIDBase16(orgOneID),
+					Dependencies: []platform.TaskDependency{
+						{Type: platform.TaskDependencyBucket, Name: "downsampled"},
+					},
+					UpdatedAt: updatedAt,
+				},
+				fixtures[2],
+			},
+		},
+		{
+			name: "task without dependencies",
+			dependencies: &platform.TaskDependencies{
+				TaskID: MustIDBase16(taskDependencyTaskThreeID),
+				OrgID:  MustIDBase16(orgTwoID),
+			},
+			want: []*platform.TaskDependencies{
+				fixtures[0],
+				fixtures[1],
+				{
+					TaskID:       MustIDBase16(taskDependencyTaskThreeID),
+					OrgID:        MustIDBase16(orgTwoID),
+					Dependencies: []platform.TaskDependency{},
+					UpdatedAt:    updatedAt,
+				},
+			},
+		},
+		{
+			name: "missing org",
+			dependencies: &platform.TaskDependencies{
+				TaskID: MustIDBase16(taskDependencyTaskOneID),
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fixtures are recorded at taskDependencyUpdatedAt, the put happens later.
+			now := taskDependencyUpdatedAt
+			fields := taskDependencyFields()
+			fields.NowFn = func() time.Time { return now }
+			s, _, done := init(fields, t)
+			defer done()
+			ctx := context.Background()
+			now = updatedAt
+
+			err := s.PutTaskDependencies(ctx, tt.dependencies)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to put task dependencies: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ds, err := s.FindTaskDependencies(ctx, platform.TaskDependencyFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task dependencies: %v", err)
+			}
+			if diff := cmp.Diff(ds, tt.want, taskDependencyCmpOptions...); diff != "" {
+				t.Errorf("task dependencies are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteTaskDependencies testing
+func DeleteTaskDependencies(
+	init func(TaskDependencyFields, *testing.T) (platform.TaskDependencyService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskDependencyFixtures()
+
+	tests := []struct {
+		name   string
+		taskID platform.ID
+		want   []*platform.TaskDependencies
+	}{
+		{
+			name:   "delete the dependencies of a task",
+			taskID: MustIDBase16(taskDependencyTaskOneID),
+			want:   fixtures[1:],
+		},
+		{
+			name:   "task without recorded dependencies",
+			taskID: MustIDBase16(orgOneID),
+			want:   fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskDependencyFields(), t)
+			defer done()
+			ctx := context.Background()
+
+			if err := s.DeleteTaskDependencies(ctx, tt.taskID); err != nil {
+				t.Fatalf("failed to delete task dependencies: %v", err)
+			}
+
+			ds, err := s.FindTaskDependencies(ctx, platform.TaskDependencyFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task dependencies: %v", err)
+			}
+			if diff := cmp.Diff(ds, tt.want, taskDependencyCmpOptions...); diff != "" {
+				t.Errorf("task dependencies are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	taskScriptOneID   = "020f755c3c085000"
+	taskScriptTwoID   = "020f755c3c085001"
+	taskScriptThreeID = "020f755c3c085002"
+	taskScriptNewID   = "020f755c3c085003"
+)
+
+var taskScriptCreatedAt = time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// TaskScriptFields will include the IDGenerator, the time and the task scripts
+type TaskScriptFields struct {
+	IDGenerator platform.IDGenerator
+	NowFn       func() time.Time
+	TaskScripts []*platform.TaskScript
+}
+
+// TaskScriptService tests all the service functions.
+func TaskScriptService(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindTaskScript",
+			fn:   FindTaskScript,
+		},
+		{
+			name: "FindTaskScripts",
+			fn:   FindTaskScripts,
+		},
+		{
+			name: "FindTaskScriptVersions",
+			fn:   FindTaskScriptVersions,
+		},
+		{
+			name: "CreateTaskScript",
+			fn:   CreateTaskScript,
+		},
+		{
+			name: "DeleteTaskScript",
+			fn:   DeleteTaskScript,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func taskScriptFields(idGen platform.IDGenerator) TaskScriptFields {
+	return TaskScriptFields{
+		IDGenerator: idGen,
+		NowFn:       func() time.Time { return taskScriptCreatedAt },
+		TaskScripts: taskScriptFixtures(),
+	}
+}
+
+func taskScriptFixtures() []*platform.TaskScript {
+	return []*platform.TaskScript{
+		{
+			ID:        MustIDBase16(taskScriptOneID),
+			OrgID:     MustIDBase16(orgOneID),
+			Name:      "helpers",
+			Version:   1,
+			Flux:      `double = (tables=<-) => tables |> map(fn: (r) => ({r with _value: r._value * 2.0}))`,
+			CreatedAt: taskScriptCreatedAt.Add(-2 * time.Hour),
+		},
+		{
+			ID:          MustIDBase16(taskScriptTwoID),
+			OrgID:       MustIDBase16(orgOneID),
+			Name:        "helpers",
+			Version:     2,
+			Description: "triple instead of double",
+			Flux:        `double = (tables=<-) => tables |> map(fn: (r) => ({r with _value: r._value * 3.0}))`,
+			CreatedAt:   taskScriptCreatedAt.Add(-time.Hour),
+		},
+		{
+			ID:        MustIDBase16(taskScriptThreeID),
+			OrgID:     MustIDBase16(orgOneID),
+			Name:      "buckets",
+			Version:   1,
+			Flux:      `source = "telegraf"`,
+			CreatedAt: taskScriptCreatedAt.Add(-time.Hour),
+		},
+	}
+}
+
+// FindTaskScript testing
+func FindTaskScript(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskScriptFixtures()
+
+	tests := []struct {
+		name     string
+		orgID    platform.ID
+		script   string
+		version  int
+		wantCode string
+		want     *platform.TaskScript
+	}{
+		{
+			name:   "latest version",
+			orgID:  MustIDBase16(orgOneID),
+			script: "helpers",
+			want:   fixtures[1],
+		},
+		{
+			name:    "pinned version",
+			orgID:   MustIDBase16(orgOneID),
+			script:  "helpers",
+			version: 1,
+			want:    fixtures[0],
+		},
+		{
+			name:     "missing version",
+			orgID:    MustIDBase16(orgOneID),
+			script:   "helpers",
+			version:  3,
+			wantCode: platform.ENotFound,
+		},
+		{
+			name:     "missing script",
+			orgID:    MustIDBase16(orgOneID),
+			script:   "help",
+			wantCode: platform.ENotFound,
+		},
+		{
+			name:     "script of another org",
+			orgID:    MustIDBase16(orgTwoID),
+			script:   "helpers",
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskScriptFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			ts, err := s.FindTaskScript(ctx, tt.orgID, tt.script, tt.version)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to find task script: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(ts, tt.want); diff != "" {
+				t.Errorf("task script is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindTaskScripts testing
+func FindTaskScripts(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskScriptFixtures()
+	orgID := MustIDBase16(orgOneID)
+	otherOrgID := MustIDBase16(orgTwoID)
+	name := "helpers"
+
+	tests := []struct {
+		name   string
+		filter platform.TaskScriptFilter
+		want   []*platform.TaskScript
+	}{
+		{
+			name: "latest versions of all task scripts",
+			want: []*platform.TaskScript{fixtures[2], fixtures[1]},
+		},
+		{
+			name:   "latest versions of task scripts in org",
+			filter: platform.TaskScriptFilter{OrgID: &orgID},
+			want:   []*platform.TaskScript{fixtures[2], fixtures[1]},
+		},
+		{
+			name:   "latest version of task script by name",
+			filter: platform.TaskScriptFilter{OrgID: &orgID, Name: &name},
+			want:   []*platform.TaskScript{fixtures[1]},
+		},
+		{
+			name:   "no task script in org",
+			filter: platform.TaskScriptFilter{OrgID: &otherOrgID},
+			want:   []*platform.TaskScript{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskScriptFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			tss, err := s.FindTaskScripts(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find task scripts: %v", err)
+			}
+
+			if diff := cmp.Diff(tss, tt.want); diff != "" {
+				t.Errorf("task scripts are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindTaskScriptVersions testing
+func FindTaskScriptVersions(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskScriptFixtures()
+
+	s, _, done := init(taskScriptFields(nil), t)
+	defer done()
+	ctx := context.Background()
+
+	tss, err := s.FindTaskScriptVersions(ctx, MustIDBase16(orgOneID), "helpers")
+	if err != nil {
+		t.Fatalf("failed to find task script versions: %v", err)
+	}
+	if diff := cmp.Diff(tss, []*platform.TaskScript{fixtures[1], fixtures[0]}); diff != "" {
+		t.Errorf("task script versions are different -got/+want\ndiff %s", diff)
+	}
+
+	_, err = s.FindTaskScriptVersions(ctx, MustIDBase16(orgOneID), "missing")
+	if code := platform.ErrorCode(err); code != platform.ENotFound {
+		t.Errorf("expected error code %s, got %v", platform.ENotFound, err)
+	}
+}
+
+// CreateTaskScript testing
+func CreateTaskScript(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name     string
+		script   *platform.TaskScript
+		wantCode string
+		want     *platform.TaskScript
+	}{
+		{
+			name: "first version of a task script",
+			script: &platform.TaskScript{
+				OrgID: MustIDBase16(orgOneID),
+				Name:  "windows",
+				Flux:  `every = 5m`,
+			},
+			want: &platform.TaskScript{
+				ID:        MustIDBase16(taskScriptNewID),
+				OrgID:     MustIDBase16(orgOneID),
+				Name:      "windows",
+				Version:   1,
+				Flux:      `every = 5m`,
+				CreatedAt: taskScriptCreatedAt,
+			},
+		},
+		{
+			name: "new version of a task script",
+			script: &platform.TaskScript{
+				OrgID:       MustIDBase16(orgOneID),
+				Name:        "helpers",
+				Description: "back to double",
+				Flux:        `// @include "buckets"` + "\n" + `double = (tables=<-) => tables |> map(fn: (r) => ({r with _value: r._value * 2.0}))`,
+			},
+			want: &platform.TaskScript{
+				ID:          MustIDBase16(taskScriptNewID),
+				OrgID:       MustIDBase16(orgOneID),
+				Name:        "helpers",
+				Version:     3,
+				Description: "back to double",
+				Flux:        `// @include "buckets"` + "\n" + `double = (tables=<-) => tables |> map(fn: (r) => ({r with _value: r._value * 2.0}))`,
+				CreatedAt:   taskScriptCreatedAt,
+			},
+		},
+		{
+			name: "same name in another org",
+			script: &platform.TaskScript{
+				OrgID: MustIDBase16(orgTwoID),
+				Name:  "helpers",
+				Flux:  `x = 1`,
+			},
+			want: &platform.TaskScript{
+				ID:        MustIDBase16(taskScriptNewID),
+				OrgID:     MustIDBase16(orgTwoID),
+				Name:      "helpers",
+				Version:   1,
+				Flux:      `x = 1`,
+				CreatedAt: taskScriptCreatedAt,
+			},
+		},
+		{
+			name: "invalid name",
+			script: &platform.TaskScript{
+				OrgID: MustIDBase16(orgOneID),
+				Name:  "lib/helpers",
+				Flux:  `x = 1`,
+			},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name: "invalid flux",
+			script: &platform.TaskScript{
+				OrgID: MustIDBase16(orgOneID),
+				Name:  "broken",
+				Flux:  `x = (`,
+			},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name: "invalid include directive",
+			script: &platform.TaskScript{
+				OrgID: MustIDBase16(orgOneID),
+				Name:  "broken",
+				Flux:  `// @include buckets`,
+			},
+			wantCode: platform.EInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskScriptFields(mock.NewIDGenerator(taskScriptNewID, t)), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateTaskScript(ctx, tt.script)
+			if tt.wantCode != "" {
+				if code := platform.ErrorCode(err); code != tt.wantCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to create task script: %v", err)
+			}
+			if diff := cmp.Diff(tt.script, tt.want); diff != "" {
+				t.Errorf("task script is different -got/+want\ndiff %s", diff)
+			}
+
+			ts, err := s.FindTaskScript(ctx, tt.want.OrgID, tt.want.Name, 0)
+			if err != nil {
+				t.Fatalf("failed to find created task script: %v", err)
+			}
+			if diff := cmp.Diff(ts, tt.want); diff != "" {
+				t.Errorf("stored task script is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteTaskScript testing
+func DeleteTaskScript(
+	init func(TaskScriptFields, *testing.T) (platform.TaskScriptService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskScriptFixtures()
+
+	s, _, done := init(taskScriptFields(nil), t)
+	defer done()
+	ctx := context.Background()
+
+	if err := s.DeleteTaskScript(ctx, MustIDBase16(orgOneID), "helpers"); err != nil {
+		t.Fatalf("failed to delete task script: %v", err)
+	}
+	if _, err := s.FindTaskScript(ctx, MustIDBase16(orgOneID), "helpers", 1); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected deleted task script versions to be not found, got %v", err)
+	}
+
+	tss, err := s.FindTaskScripts(ctx, platform.TaskScriptFilter{})
+	if err != nil {
+		t.Fatalf("failed to find task scripts: %v", err)
+	}
+	if diff := cmp.Diff(tss, fixtures[2:]); diff != "" {
+		t.Errorf("task scripts are different -got/+want\ndiff %s", diff)
+	}
+
+	if err := s.DeleteTaskScript(ctx, MustIDBase16(orgOneID), "helpers"); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected error code %s deleting missing task script, got %v", platform.ENotFound, err)
+	}
+}
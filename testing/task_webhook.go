@@ -0,0 +1,474 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	taskWebhookOneID = "020f755c3c083000"
+	taskWebhookTwoID = "020f755c3c083001"
+	taskWebhookNewID = "020f755c3c083002"
+
+	taskWebhookTaskOneID = "020f755c3c084000"
+	taskWebhookTaskTwoID = "020f755c3c084001"
+)
+
+var taskWebhookCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.TaskWebhook) []*platform.TaskWebhook {
+		out := append([]*platform.TaskWebhook(nil), in...) // Copy input to avoid mutating it
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}),
+}
+
+// TaskWebhookFields will include the IDGenerator and the task webhooks
+type TaskWebhookFields struct {
+	IDGenerator  platform.IDGenerator
+	TaskWebhooks []*platform.TaskWebhook
+}
+
+// TaskWebhookService tests all the service functions.
+func TaskWebhookService(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "FindTaskWebhookByID",
+			fn:   FindTaskWebhookByID,
+		},
+		{
+			name: "FindTaskWebhooks",
+			fn:   FindTaskWebhooks,
+		},
+		{
+			name: "CreateTaskWebhook",
+			fn:   CreateTaskWebhook,
+		},
+		{
+			name: "UpdateTaskWebhook",
+			fn:   UpdateTaskWebhook,
+		},
+		{
+			name: "DeleteTaskWebhook",
+			fn:   DeleteTaskWebhook,
+		},
+		{
+			name: "TaskWebhookDeliveries",
+			fn:   TaskWebhookDeliveries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func taskWebhookFields(idGen platform.IDGenerator) TaskWebhookFields {
+	return TaskWebhookFields{
+		IDGenerator:  idGen,
+		TaskWebhooks: taskWebhookFixtures(),
+	}
+}
+
+func taskWebhookFixtures() []*platform.TaskWebhook {
+	return []*platform.TaskWebhook{
+		{
+			ID:     MustIDBase16(taskWebhookOneID),
+			TaskID: MustIDBase16(taskWebhookTaskOneID),
+			OrgID:  MustIDBase16(orgOneID),
+			URL:    "https://example.com/hooks/failures",
+			Events: []string{platform.TaskWebhookEventFailure},
+			Secret: "s3cr3t",
+		},
+		{
+			ID:       MustIDBase16(taskWebhookTwoID),
+			TaskID:   MustIDBase16(taskWebhookTaskTwoID),
+			OrgID:    MustIDBase16(orgOneID),
+			URL:      "http://localhost:9000/runs",
+			Events:   []string{platform.TaskWebhookEventSuccess, platform.TaskWebhookEventFailure},
+			Template: `{"text": "{{.TaskName}} {{.Event}}"}`,
+		},
+	}
+}
+
+// FindTaskWebhookByID testing
+func FindTaskWebhookByID(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskWebhookFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     *platform.TaskWebhook
+	}{
+		{
+			name: "find task webhook by id",
+			id:   MustIDBase16(taskWebhookTwoID),
+			want: fixtures[1],
+		},
+		{
+			name:     "missing task webhook",
+			id:       MustIDBase16(taskWebhookNewID),
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskWebhookFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			w, err := s.FindTaskWebhookByID(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to find task webhook: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(w, tt.want); diff != "" {
+				t.Errorf("task webhook is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindTaskWebhooks testing
+func FindTaskWebhooks(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskWebhookFixtures()
+	taskID := MustIDBase16(taskWebhookTaskOneID)
+	otherOrgID := MustIDBase16(orgTwoID)
+
+	tests := []struct {
+		name   string
+		filter platform.TaskWebhookFilter
+		want   []*platform.TaskWebhook
+	}{
+		{
+			name: "all task webhooks",
+			want: fixtures,
+		},
+		{
+			name:   "task webhooks by task",
+			filter: platform.TaskWebhookFilter{TaskID: &taskID},
+			want:   fixtures[:1],
+		},
+		{
+			name:   "no task webhook in org",
+			filter: platform.TaskWebhookFilter{OrgID: &otherOrgID},
+			want:   []*platform.TaskWebhook{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskWebhookFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			ws, err := s.FindTaskWebhooks(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to find task webhooks: %v", err)
+			}
+
+			if diff := cmp.Diff(ws, tt.want, taskWebhookCmpOptions...); diff != "" {
+				t.Errorf("task webhooks are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// CreateTaskWebhook testing
+func CreateTaskWebhook(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskWebhookFixtures()
+
+	tests := []struct {
+		name     string
+		webhook  *platform.TaskWebhook
+		wantCode string
+		want     []*platform.TaskWebhook
+	}{
+		{
+			name: "create a task webhook",
+			webhook: &platform.TaskWebhook{
+				TaskID: MustIDBase16(taskWebhookTaskOneID),
+				OrgID:  MustIDBase16(orgOneID),
+				URL:    "https://example.com/hooks/successes",
+				Events: []string{platform.TaskWebhookEventSuccess},
+			},
+			want: append(fixtures[:2:2], &platform.TaskWebhook{
+				ID:     MustIDBase16(taskWebhookNewID),
+				TaskID: MustIDBase16(taskWebhookTaskOneID),
+				OrgID:  MustIDBase16(orgOneID),
+				URL:    "https://example.com/hooks/successes",
+				Events: []string{platform.TaskWebhookEventSuccess},
+			}),
+		},
+		{
+			name: "relative url",
+			webhook: &platform.TaskWebhook{
+				TaskID: MustIDBase16(taskWebhookTaskOneID),
+				OrgID:  MustIDBase16(orgOneID),
+				URL:    "/hooks",
+				Events: []string{platform.TaskWebhookEventSuccess},
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "unknown event",
+			webhook: &platform.TaskWebhook{
+				TaskID: MustIDBase16(taskWebhookTaskOneID),
+				OrgID:  MustIDBase16(orgOneID),
+				URL:    "https://example.com/hooks",
+				Events: []string{"canceled"},
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+		{
+			name: "invalid template",
+			webhook: &platform.TaskWebhook{
+				TaskID:   MustIDBase16(taskWebhookTaskOneID),
+				OrgID:    MustIDBase16(orgOneID),
+				URL:      "https://example.com/hooks",
+				Events:   []string{platform.TaskWebhookEventFailure},
+				Template: "{{.TaskName",
+			},
+			wantCode: platform.EInvalid,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskWebhookFields(mock.NewIDGenerator(taskWebhookNewID, t)), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateTaskWebhook(ctx, tt.webhook)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to create task webhook: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ws, err := s.FindTaskWebhooks(ctx, platform.TaskWebhookFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task webhooks: %v", err)
+			}
+			if diff := cmp.Diff(ws, tt.want, taskWebhookCmpOptions...); diff != "" {
+				t.Errorf("task webhooks are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateTaskWebhook testing
+func UpdateTaskWebhook(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	url := "https://example.com/hooks/all"
+	events := []string{platform.TaskWebhookEventSuccess, platform.TaskWebhookEventFailure}
+	noEvents := []string{}
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		upd      platform.TaskWebhookUpdate
+		wantCode string
+		want     *platform.TaskWebhook
+	}{
+		{
+			name: "update url and events",
+			id:   MustIDBase16(taskWebhookOneID),
+			upd:  platform.TaskWebhookUpdate{URL: &url, Events: &events},
+			want: &platform.TaskWebhook{
+				ID:     MustIDBase16(taskWebhookOneID),
+				TaskID: MustIDBase16(taskWebhookTaskOneID),
+				OrgID:  MustIDBase16(orgOneID),
+				URL:    url,
+				Events: events,
+				Secret: "s3cr3t",
+			},
+		},
+		{
+			name:     "no events",
+			id:       MustIDBase16(taskWebhookOneID),
+			upd:      platform.TaskWebhookUpdate{Events: &noEvents},
+			wantCode: platform.EInvalid,
+		},
+		{
+			name:     "missing task webhook",
+			id:       MustIDBase16(taskWebhookNewID),
+			upd:      platform.TaskWebhookUpdate{URL: &url},
+			wantCode: platform.ENotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskWebhookFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			w, err := s.UpdateTaskWebhook(ctx, tt.id, tt.upd)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to update task webhook: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+			if diff := cmp.Diff(w, tt.want); diff != "" {
+				t.Errorf("task webhook is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteTaskWebhook testing
+func DeleteTaskWebhook(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	fixtures := taskWebhookFixtures()
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		wantCode string
+		want     []*platform.TaskWebhook
+	}{
+		{
+			name: "delete a task webhook",
+			id:   MustIDBase16(taskWebhookOneID),
+			want: fixtures[1:],
+		},
+		{
+			name:     "delete a missing task webhook",
+			id:       MustIDBase16(taskWebhookNewID),
+			wantCode: platform.ENotFound,
+			want:     fixtures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(taskWebhookFields(nil), t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteTaskWebhook(ctx, tt.id)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("failed to delete task webhook: %v", err)
+			}
+			if code := platform.ErrorCode(err); tt.wantCode != "" && code != tt.wantCode {
+				t.Fatalf("expected error code %s, got %v", tt.wantCode, err)
+			}
+
+			ws, err := s.FindTaskWebhooks(ctx, platform.TaskWebhookFilter{})
+			if err != nil {
+				t.Fatalf("failed to find task webhooks: %v", err)
+			}
+			if diff := cmp.Diff(ws, tt.want, taskWebhookCmpOptions...); diff != "" {
+				t.Errorf("task webhooks are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// TaskWebhookDeliveries testing
+func TaskWebhookDeliveries(
+	init func(TaskWebhookFields, *testing.T) (platform.TaskWebhookService, string, func()),
+	t *testing.T,
+) {
+	next := MustIDBase16(taskWebhookNewID)
+	idGen := mock.IDGenerator{
+		IDFn: func() platform.ID {
+			next++
+			return next
+		},
+	}
+
+	s, _, done := init(taskWebhookFields(idGen), t)
+	defer done()
+	ctx := context.Background()
+
+	webhookID := MustIDBase16(taskWebhookOneID)
+	start := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	n := platform.MaxTaskWebhookDeliveries + 5
+	for i := 0; i < n; i++ {
+		d := &platform.TaskWebhookDelivery{
+			WebhookID:  webhookID,
+			RunID:      MustIDBase16(taskWebhookTaskTwoID),
+			Event:      platform.TaskWebhookEventFailure,
+			Attempt:    i + 1,
+			Time:       start.Add(time.Duration(i) * time.Second),
+			StatusCode: 500,
+		}
+		if err := s.AddTaskWebhookDelivery(ctx, d); err != nil {
+			t.Fatalf("failed to add task webhook delivery: %v", err)
+		}
+		if !d.ID.Valid() {
+			t.Fatalf("expected task webhook delivery to be assigned an ID")
+		}
+	}
+
+	ds, err := s.FindTaskWebhookDeliveries(ctx, webhookID)
+	if err != nil {
+		t.Fatalf("failed to find task webhook deliveries: %v", err)
+	}
+	if len(ds) != platform.MaxTaskWebhookDeliveries {
+		t.Fatalf("expected the latest %d deliveries to be kept, got %d", platform.MaxTaskWebhookDeliveries, len(ds))
+	}
+	if ds[0].Attempt != n || ds[len(ds)-1].Attempt != n-platform.MaxTaskWebhookDeliveries+1 {
+		t.Errorf("expected latest deliveries first, got attempts %d to %d", ds[0].Attempt, ds[len(ds)-1].Attempt)
+	}
+
+	// The delivery log of the other webhook is separate.
+	ds, err = s.FindTaskWebhookDeliveries(ctx, MustIDBase16(taskWebhookTwoID))
+	if err != nil {
+		t.Fatalf("failed to find task webhook deliveries: %v", err)
+	}
+	if len(ds) != 0 {
+		t.Errorf("expected no deliveries for the other webhook, got %d", len(ds))
+	}
+
+	if err := s.AddTaskWebhookDelivery(ctx, &platform.TaskWebhookDelivery{WebhookID: MustIDBase16(taskWebhookNewID)}); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected not found error for delivery of a missing webhook, got %v", err)
+	}
+
+	// Deleting a webhook deletes its delivery log.
+	if err := s.DeleteTaskWebhook(ctx, webhookID); err != nil {
+		t.Fatalf("failed to delete task webhook: %v", err)
+	}
+	if _, err := s.FindTaskWebhookDeliveries(ctx, webhookID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected not found error for deliveries of a deleted webhook, got %v", err)
+	}
+}
@@ -21,6 +21,9 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// Reasons is the reason each of the dropped keys was dropped for, if known.
+	Reasons map[string]string
 }
 
 func (e PartialWriteError) Error() string {
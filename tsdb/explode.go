@@ -1,7 +1,9 @@
 package tsdb
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
@@ -79,3 +81,41 @@ func ExplodePoints(org, bucket platform.ID, points []models.Point) ([]models.Poi
 
 	return out, nil
 }
+
+// UnexplodePoint reverses ExplodePoints for a point: it returns the organization and bucket
+// of the point, and the point with its measurement moved back from its measurement tag.
+func UnexplodePoint(pt models.Point) (org, bucket platform.ID, out models.Point, err error) {
+	name := pt.Name()
+	if len(name) != 16 {
+		return 0, 0, nil, fmt.Errorf("point name %q is not an encoded organization and bucket", name)
+	}
+	var ob [16]byte
+	copy(ob[:], name)
+	org, bucket = DecodeName(ob)
+
+	var measurement []byte
+	ptTags := pt.Tags()
+	tags := make(models.Tags, 0, len(ptTags))
+	for _, tag := range ptTags {
+		switch {
+		case bytes.Equal(tag.Key, models.MeasurementTagKeyBytes):
+			measurement = tag.Value
+		case bytes.Equal(tag.Key, models.FieldKeyTagKeyBytes):
+		default:
+			tags = append(tags, tag)
+		}
+	}
+	if len(measurement) == 0 {
+		return 0, 0, nil, fmt.Errorf("point %q has no measurement tag", pt.Key())
+	}
+
+	fields, err := pt.Fields()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	out, err = models.NewPoint(string(measurement), tags, fields, pt.Time())
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return org, bucket, out, nil
+}
@@ -85,3 +85,47 @@ func TestExplodePoints(t *testing.T) {
 		t.Fatal("bad output:\n", cmp.Diff(lines, expected))
 	}
 }
+
+func TestUnexplodePoint(t *testing.T) {
+	points, err := models.ParsePointsString(`cpu,t1=a,t2=q f1=5i,f2="f" 9`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := platform.ID(0x4F4F4F4F4F4F4F4F)
+	bucket := platform.ID(0x4242424242424242)
+	points, err = tsdb.ExplodePoints(org, bucket, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, point := range points {
+		gotOrg, gotBucket, pt, err := tsdb.UnexplodePoint(point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotOrg != org || gotBucket != bucket {
+			t.Errorf("got organization %s and bucket %s, expected %s and %s", gotOrg, gotBucket, org, bucket)
+		}
+		lines = append(lines, pt.String())
+	}
+	sort.Strings(lines)
+
+	expected := []string{
+		"cpu,t1=a,t2=q f1=5i 9",
+		"cpu,t1=a,t2=q f2=\"f\" 9",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatal("bad output:\n", cmp.Diff(lines, expected))
+	}
+
+	// Points that were not exploded cannot be unexploded.
+	points, err = models.ParsePointsString(`cpu,t1=a f1=5 9`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := tsdb.UnexplodePoint(points[0]); err == nil {
+		t.Fatal("expected an error for a point that was not exploded")
+	}
+}
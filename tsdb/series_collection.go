@@ -25,6 +25,9 @@ type SeriesCollection struct {
 	DroppedKeys [][]byte
 	Reason      string
 
+	// DroppedReasons is the reason each dropped key was dropped for. Only the first reason of a key is kept.
+	DroppedReasons map[string]string
+
 	// Used by the concurrent iterators to stage drops. Inefficient, but should be
 	// very infrequently used.
 	state *seriesCollectionState
@@ -34,7 +37,7 @@ type SeriesCollection struct {
 type seriesCollectionState struct {
 	mu     sync.Mutex
 	reason string
-	index  map[int]struct{}
+	index  map[int]string // The reason each invalid index is invalid for.
 }
 
 // NewSeriesCollection builds a SeriesCollection from a slice of points. It does some filtering
@@ -198,13 +201,28 @@ func (s *SeriesCollection) Advance(length int) {
 	}
 }
 
-// InvalidateAll causes all of the entries to become invalid.
-func (s *SeriesCollection) InvalidateAll(reason string) {
+// Drop records that the entry with key was dropped for reason. Only the first reason of
+// the collection is kept as its Reason. It does not remove the entry from the collection.
+func (s *SeriesCollection) Drop(key []byte, reason string) {
 	if s.Reason == "" {
 		s.Reason = reason
 	}
-	s.Dropped += uint64(len(s.Keys))
-	s.DroppedKeys = append(s.DroppedKeys, s.Keys...)
+	s.Dropped++
+	s.DroppedKeys = append(s.DroppedKeys, key)
+
+	if s.DroppedReasons == nil {
+		s.DroppedReasons = make(map[string]string)
+	}
+	if _, ok := s.DroppedReasons[string(key)]; !ok {
+		s.DroppedReasons[string(key)] = reason
+	}
+}
+
+// InvalidateAll causes all of the entries to become invalid.
+func (s *SeriesCollection) InvalidateAll(reason string) {
+	for _, key := range s.Keys {
+		s.Drop(key, reason)
+	}
 	s.Truncate(0)
 }
 
@@ -216,13 +234,17 @@ func (s *SeriesCollection) ApplyConcurrentDrops() {
 		return
 	}
 
+	if s.Reason == "" {
+		s.Reason = state.reason
+	}
+
 	length, j := s.Length(), 0
 	for i := 0; i < length; i++ {
-		if _, ok := state.index[i]; ok {
-			s.Dropped++
-
+		if reason, ok := state.index[i]; ok {
 			if i < len(s.Keys) {
-				s.DroppedKeys = append(s.DroppedKeys, s.Keys[i])
+				s.Drop(s.Keys[i], reason)
+			} else {
+				s.Dropped++
 			}
 
 			continue
@@ -233,10 +255,6 @@ func (s *SeriesCollection) ApplyConcurrentDrops() {
 	}
 	s.Truncate(j)
 
-	if s.Reason == "" {
-		s.Reason = state.reason
-	}
-
 	// clear concurrent state
 	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&s.state)), nil)
 }
@@ -266,9 +284,11 @@ func (s *SeriesCollection) invalidIndex(index int, reason string) {
 
 	state.mu.Lock()
 	if state.index == nil {
-		state.index = make(map[int]struct{})
+		state.index = make(map[int]string)
+	}
+	if _, ok := state.index[index]; !ok {
+		state.index[index] = reason
 	}
-	state.index[index] = struct{}{}
 	if state.reason == "" {
 		state.reason = reason
 	}
@@ -286,6 +306,7 @@ func (s *SeriesCollection) PartialWriteError() error {
 		Reason:      s.Reason,
 		Dropped:     len(droppedKeys),
 		DroppedKeys: droppedKeys,
+		Reasons:     s.DroppedReasons,
 	}
 }
 
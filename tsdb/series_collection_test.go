@@ -121,6 +121,7 @@ func TestSeriesCollection(t *testing.T) {
 			Reason:      "test reason",
 			Dropped:     3,
 			DroppedKeys: bs("ka", "kb", "kc"),
+			Reasons:     map[string]string{"ka": "test reason", "kb": "test reason", "kc": "test reason"},
 		})
 	})
 
@@ -144,6 +145,7 @@ func TestSeriesCollection(t *testing.T) {
 			Reason:      "test reason",
 			Dropped:     2,
 			DroppedKeys: bs("ka", "kc"),
+			Reasons:     map[string]string{"ka": "test reason", "kc": "test reason"},
 		})
 	})
 }
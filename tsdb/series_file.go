@@ -1,12 +1,14 @@
 package tsdb
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"sync"
@@ -16,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/binaryutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/rhh"
 	"github.com/prometheus/client_golang/prometheus"
@@ -48,6 +51,8 @@ type SeriesFile struct {
 	defaultMetricLabels prometheus.Labels
 	metricsEnabled      bool
 
+	keyring *encryption.Keyring
+
 	Logger *zap.Logger
 }
 
@@ -65,6 +70,15 @@ func (f *SeriesFile) WithLogger(log *zap.Logger) {
 	f.Logger = log.With(zap.String("service", "series-file"))
 }
 
+// WithKeyring sets the keyring encrypting the series keys written to the partitions with its
+// current key, and decrypting the encrypted series keys. It must be called before Open.
+//
+// Only the series keys inserted with a keyring are encrypted: the entries written before
+// stay in plaintext, as the segments are never rewritten.
+func (f *SeriesFile) WithKeyring(keyring *encryption.Keyring) {
+	f.keyring = keyring
+}
+
 // SetDefaultMetricLabels sets the default labels for metrics on the Series File.
 // It must be called before the SeriesFile is opened.
 func (f *SeriesFile) SetDefaultMetricLabels(labels prometheus.Labels) {
@@ -122,6 +136,7 @@ func (f *SeriesFile) Open(ctx context.Context) error {
 		// TODO(edd): These partition initialisation should be moved up to NewSeriesFile.
 		p := NewSeriesPartition(i, f.SeriesPartitionPath(i))
 		p.Logger = f.Logger.With(zap.Int("partition", p.ID()))
+		p.WithKeyring(f.keyring)
 
 		// For each series file index, rhh trackers are used to track the RHH Hashmap.
 		// Each of the trackers needs to be given slightly different default
@@ -141,7 +156,7 @@ func (f *SeriesFile) Open(ctx context.Context) error {
 		p.tracker.enabled = f.metricsEnabled
 
 		if err := p.Open(); err != nil {
-			f.Close()
+			f.close()
 			return err
 		}
 		f.partitions = append(f.partitions, p)
@@ -157,7 +172,11 @@ func (f *SeriesFile) Open(ctx context.Context) error {
 func (f *SeriesFile) Close() (err error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	return f.close()
+}
 
+// close unmaps the data file. f.mu must be held.
+func (f *SeriesFile) close() (err error) {
 	// Close the resource and wait for any outstanding references.
 	f.res.Close()
 
@@ -181,6 +200,17 @@ func (f *SeriesFile) SeriesPartitionPath(i int) string {
 // Partitions returns all partitions.
 func (f *SeriesFile) Partitions() []*SeriesPartition { return f.partitions }
 
+// Backup writes a snapshot of the partitions of the series file to the tar archive tw,
+// in the directory dir.
+func (f *SeriesFile) Backup(tw *tar.Writer, dir string) error {
+	for _, p := range f.partitions {
+		if err := p.Backup(tw, path.Join(dir, filepath.Base(p.Path()))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Acquire ensures that the series file won't be closed until after the reference
 // has been released.
 func (f *SeriesFile) Acquire() (*lifecycle.Reference, error) {
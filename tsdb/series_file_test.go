@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 )
 
@@ -134,6 +136,92 @@ func TestSeriesFileCompactor(t *testing.T) {
 }
 
 // Ensures that types are tracked and checked by the series file.
+// Ensure the series keys of an encrypted series file are not readable on disk.
+func TestSeriesFile_Encryption(t *testing.T) {
+	keyring, err := encryption.NewKeyring(encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sfile := NewSeriesFile()
+	defer sfile.Close()
+	sfile.WithKeyring(keyring)
+	if err := sfile.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	createSeries := func(name string) tsdb.SeriesID {
+		t.Helper()
+		collection := &tsdb.SeriesCollection{
+			Names: [][]byte{[]byte(name)},
+			Tags:  []models.Tags{models.NewTags(map[string]string{"secret-tag": "secret-value"})},
+			Types: []models.FieldType{models.Integer},
+		}
+		if err := sfile.CreateSeriesListIfNotExists(collection); err != nil {
+			t.Fatal(err)
+		}
+		return collection.SeriesIDs[0]
+	}
+	checkSeries := func(name string, id tsdb.SeriesID) {
+		t.Helper()
+		tags := models.NewTags(map[string]string{"secret-tag": "secret-value"})
+		if got := sfile.SeriesID([]byte(name), tags, nil); got != id {
+			t.Fatalf("unexpected series id of %s: %d, expected %d", name, got.RawID(), id.RawID())
+		}
+		if gotName, gotTags := sfile.Series(id); string(gotName) != name || !gotTags.Equal(tags) {
+			t.Fatalf("unexpected series %s %v, expected %s %v", gotName, gotTags, name, tags)
+		}
+	}
+
+	cpu := createSeries("cpu")
+	if err := sfile.ForceCompact(); err != nil {
+		t.Fatal(err)
+	}
+	checkSeries("cpu", cpu)
+
+	// The series keys are read with the older keys once the key is rotated.
+	rotated, err := encryption.NewKeyring(
+		encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{1}, 32)},
+		encryption.Key{ID: 2, Secret: bytes.Repeat([]byte{2}, 32)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sfile.SeriesFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sfile.SeriesFile = tsdb.NewSeriesFile(sfile.Path())
+	sfile.WithKeyring(rotated)
+	if err := sfile.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	checkSeries("cpu", cpu)
+	mem := createSeries("mem")
+	checkSeries("mem", mem)
+
+	err = filepath.Walk(sfile.Path(), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, []byte("secret")) {
+			t.Errorf("series key found in plaintext in %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The series file cannot be opened without the keyring.
+	if err := sfile.Reopen(); err != tsdb.ErrSeriesEntryNoKeyring {
+		t.Fatalf("unexpected error opening the series file without keyring: %v", err)
+	}
+}
+
 func TestSeriesFile_Type(t *testing.T) {
 	sfile := MustOpenSeriesFile()
 	defer sfile.Close()
@@ -235,7 +235,7 @@ func (idx *SeriesIndex) FindIDBySeriesKey(segments []*SeriesSegment, key []byte)
 			return SeriesIDTyped{}
 		}
 
-		elemKey := ReadSeriesKeyFromSegments(segments, elemOffset+SeriesEntryHeaderSize)
+		elemKey := ReadSeriesKeyFromSegments(segments, elemOffset)
 		elemHash := rhh.HashKey(elemKey)
 		if d > rhh.Dist(elemHash, pos, idx.capacity) {
 			return SeriesIDTyped{}
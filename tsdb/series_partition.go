@@ -1,6 +1,7 @@
 package tsdb
 
 import (
+	"archive/tar"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -12,7 +13,9 @@ import (
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/rhh"
+	intar "github.com/influxdata/influxdb/pkg/tar"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -46,6 +49,8 @@ type SeriesPartition struct {
 
 	CompactThreshold int
 
+	keyring *encryption.Keyring // encrypts the series keys of the insert entries written
+
 	tracker *seriesPartitionTracker
 	Logger  *zap.Logger
 }
@@ -65,6 +70,12 @@ func NewSeriesPartition(id int, path string) *SeriesPartition {
 	return p
 }
 
+// WithKeyring sets the keyring encrypting the series keys of the entries written with its
+// current key, and decrypting the encrypted series keys. It must be called before Open.
+func (p *SeriesPartition) WithKeyring(keyring *encryption.Keyring) {
+	p.keyring = keyring
+}
+
 // Open memory maps the data file at the partition's path.
 func (p *SeriesPartition) Open() error {
 	if p.closed {
@@ -88,7 +99,7 @@ func (p *SeriesPartition) Open() error {
 
 		if err := p.index.Open(); err != nil {
 			return err
-		} else if p.index.Recover(p.segments); err != nil {
+		} else if err := p.index.Recover(p.segments); err != nil {
 			return err
 		}
 		return nil
@@ -115,6 +126,7 @@ func (p *SeriesPartition) openSegments() error {
 		}
 
 		segment := NewSeriesSegment(segmentID, filepath.Join(p.path, fi.Name()))
+		segment.WithKeyring(p.keyring)
 		if err := segment.Open(); err != nil {
 			return err
 		}
@@ -136,6 +148,7 @@ func (p *SeriesPartition) openSegments() error {
 		if err != nil {
 			return err
 		}
+		segment.WithKeyring(p.keyring)
 		p.segments = append(p.segments, segment)
 	}
 
@@ -179,6 +192,55 @@ func (p *SeriesPartition) Path() string { return p.path }
 // IndexPath returns the path to the series index.
 func (p *SeriesPartition) IndexPath() string { return filepath.Join(p.path, "index") }
 
+// Backup writes a snapshot of the index and the segments of the partition to the tar archive tw,
+// in the directory dir. The active segment is written up to the last series it holds, zero padded
+// to the size it is preallocated with, so that the partition is opened from the snapshot as is.
+func (p *SeriesPartition) Backup(tw *tar.Writer, dir string) error {
+	files, err := p.snapshot()
+	if err != nil {
+		return err
+	}
+	return intar.WriteFiles(tw, dir, files)
+}
+
+// snapshot opens the index and the segments of the partition, with the sizes they have now.
+func (p *SeriesPartition) snapshot() (files []intar.File, err error) {
+	defer func() {
+		if err != nil {
+			intar.CloseFiles(files)
+		}
+	}()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return nil, ErrSeriesPartitionClosed
+	}
+
+	// The index is written by compactions, which replace it under the lock.
+	if f, err := intar.OpenFile("index", p.IndexPath(), -1, -1); err == nil {
+		files = append(files, f)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Series are appended to the active segment and flushed under the lock, the size of the
+	// other segments is not tracked as they are full.
+	active := p.activeSegment()
+	for _, s := range p.segments {
+		n := int64(-1)
+		if s == active {
+			n = s.Size()
+		}
+		f, err := intar.OpenFile(filepath.Base(s.path), s.path, n, int64(SeriesSegmentSize(s.ID())))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
 // CreateSeriesListIfNotExists creates a list of series in bulk if they don't exist.
 // The ids parameter is modified to contain series IDs for all keys belonging to this partition.
 // If the type does not match the existing type for the key, a zero id is stored.
@@ -487,7 +549,15 @@ func (p *SeriesPartition) activeSegment() *SeriesSegment {
 
 func (p *SeriesPartition) insert(key []byte, typ models.FieldType) (id SeriesIDTyped, offset int64, err error) {
 	id = NewSeriesID(p.seq).WithType(typ)
-	offset, err = p.writeLogEntry(AppendSeriesEntry(nil, SeriesEntryInsertFlag, id, key))
+	var entry []byte
+	if p.keyring != nil {
+		if entry, err = AppendEncryptedSeriesEntry(nil, p.keyring, id, key); err != nil {
+			return SeriesIDTyped{}, 0, err
+		}
+	} else {
+		entry = AppendSeriesEntry(nil, SeriesEntryInsertFlag, id, key)
+	}
+	offset, err = p.writeLogEntry(entry)
 	if err != nil {
 		return SeriesIDTyped{}, 0, err
 	}
@@ -529,6 +599,7 @@ func (p *SeriesPartition) createSegment() (*SeriesSegment, error) {
 	if err != nil {
 		return nil, err
 	}
+	segment.WithKeyring(p.keyring)
 	p.segments = append(p.segments, segment)
 
 	// Allow segment to write.
@@ -551,7 +622,11 @@ func (p *SeriesPartition) seriesKeyByOffset(offset int64) []byte {
 			continue
 		}
 
-		key, _ := ReadSeriesKey(segment.Slice(pos + SeriesEntryHeaderSize))
+		key, err := segment.SeriesKey(pos)
+		if err != nil {
+			p.Logger.Error("Unable to read series key", zap.Int64("offset", offset), zap.Error(err))
+			return nil
+		}
 		return key
 	}
 
@@ -855,7 +930,7 @@ func (c *SeriesPartitionCompactor) insertKeyIDMap(dst []byte, capacity int64, se
 		}
 
 		// Read key at position & hash.
-		elemKey := ReadSeriesKeyFromSegments(segments, elemOffset+SeriesEntryHeaderSize)
+		elemKey := ReadSeriesKeyFromSegments(segments, elemOffset)
 		elemHash := rhh.HashKey(elemKey)
 
 		// If the existing elem has probed less than us, then swap places with
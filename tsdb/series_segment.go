@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/mmap"
 )
 
@@ -28,12 +29,17 @@ const (
 
 	SeriesEntryInsertFlag    = 0x01
 	SeriesEntryTombstoneFlag = 0x02
+
+	// SeriesEntryEncryptedInsertFlag flags an insert entry whose series key is encrypted.
+	// The key is read as an insert entry's when the segment has the keyring it was encrypted with.
+	SeriesEntryEncryptedInsertFlag = 0x03
 )
 
 var (
 	ErrInvalidSeriesSegment        = errors.New("invalid series segment")
 	ErrInvalidSeriesSegmentVersion = errors.New("invalid series segment version")
 	ErrSeriesSegmentNotWritable    = errors.New("series segment not writable")
+	ErrSeriesEntryNoKeyring        = errors.New("series entry is encrypted, no encryption keyring")
 )
 
 // SeriesSegment represents a log of series entries.
@@ -45,6 +51,8 @@ type SeriesSegment struct {
 	file *os.File      // write file handle
 	w    *bufio.Writer // bufferred file handle
 	size uint32        // current file size
+
+	keyring *encryption.Keyring // decrypts the encrypted series keys
 }
 
 // NewSeriesSegment returns a new instance of SeriesSegment.
@@ -112,6 +120,11 @@ func (s *SeriesSegment) Open() error {
 	return nil
 }
 
+// WithKeyring sets the keyring decrypting the series keys of the encrypted insert entries.
+func (s *SeriesSegment) WithKeyring(keyring *encryption.Keyring) {
+	s.keyring = keyring
+}
+
 // InitForWrite initializes a write handle for the segment.
 // This is only used for the last segment in the series file.
 func (s *SeriesSegment) InitForWrite() (err error) {
@@ -181,6 +194,27 @@ func (s *SeriesSegment) Size() int64 { return int64(s.size) }
 // Slice returns a byte slice starting at pos.
 func (s *SeriesSegment) Slice(pos uint32) []byte { return s.data[pos:] }
 
+// SeriesKey returns the series key of the insert entry at pos, decrypting it if it is encrypted.
+func (s *SeriesSegment) SeriesKey(pos uint32) ([]byte, error) {
+	flag, _, key, _ := ReadSeriesEntry(s.data[pos:])
+	return s.decryptKey(flag, key)
+}
+
+// decryptKey returns the series key read from an entry with flag, decrypted if it is encrypted.
+func (s *SeriesSegment) decryptKey(flag uint8, key []byte) ([]byte, error) {
+	if flag != SeriesEntryEncryptedInsertFlag {
+		return key, nil
+	}
+	if s.keyring == nil {
+		return nil, ErrSeriesEntryNoKeyring
+	}
+	key, err := s.keyring.Decrypt(nil, key)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting series entry: %v", err)
+	}
+	return key, nil
+}
+
 // WriteLogEntry writes entry data into the segment.
 // Returns the offset of the beginning of the entry.
 func (s *SeriesSegment) WriteLogEntry(data []byte) (offset int64, err error) {
@@ -212,8 +246,8 @@ func (s *SeriesSegment) Flush() error {
 
 // AppendSeriesIDs appends all the segments ids to a slice. Returns the new slice.
 func (s *SeriesSegment) AppendSeriesIDs(a []SeriesID) []SeriesID {
-	s.ForEachEntry(func(flag uint8, id SeriesIDTyped, _ int64, _ []byte) error {
-		if flag == SeriesEntryInsertFlag {
+	s.forEachRawEntry(func(flag uint8, id SeriesIDTyped, _ int64, _ []byte) error {
+		if IsSeriesEntryInsertFlag(flag) {
 			a = append(a, id.SeriesID())
 		}
 		return nil
@@ -224,9 +258,9 @@ func (s *SeriesSegment) AppendSeriesIDs(a []SeriesID) []SeriesID {
 // MaxSeriesID returns the highest series id in the segment.
 func (s *SeriesSegment) MaxSeriesID() SeriesID {
 	var max SeriesID
-	s.ForEachEntry(func(flag uint8, id SeriesIDTyped, _ int64, _ []byte) error {
+	s.forEachRawEntry(func(flag uint8, id SeriesIDTyped, _ int64, _ []byte) error {
 		untypedID := id.SeriesID()
-		if flag == SeriesEntryInsertFlag && untypedID.Greater(max) {
+		if IsSeriesEntryInsertFlag(flag) && untypedID.Greater(max) {
 			max = untypedID
 		}
 		return nil
@@ -235,7 +269,22 @@ func (s *SeriesSegment) MaxSeriesID() SeriesID {
 }
 
 // ForEachEntry executes fn for every entry in the segment.
+// Encrypted insert entries are passed to fn as insert entries with their decrypted series key.
 func (s *SeriesSegment) ForEachEntry(fn func(flag uint8, id SeriesIDTyped, offset int64, key []byte) error) error {
+	return s.forEachRawEntry(func(flag uint8, id SeriesIDTyped, offset int64, key []byte) error {
+		if flag == SeriesEntryEncryptedInsertFlag {
+			var err error
+			if key, err = s.decryptKey(flag, key); err != nil {
+				return err
+			}
+			flag = SeriesEntryInsertFlag
+		}
+		return fn(flag, id, offset, key)
+	})
+}
+
+// forEachRawEntry executes fn for every entry in the segment, without decrypting the series keys.
+func (s *SeriesSegment) forEachRawEntry(fn func(flag uint8, id SeriesIDTyped, offset int64, key []byte) error) error {
 	for pos := uint32(SeriesSegmentHeaderSize); pos < uint32(len(s.data)); {
 		flag, id, key, sz := ReadSeriesEntry(s.data[pos:])
 		if !IsValidSeriesEntryFlag(flag) {
@@ -254,10 +303,11 @@ func (s *SeriesSegment) ForEachEntry(fn func(flag uint8, id SeriesIDTyped, offse
 // Clone returns a copy of the segment. Excludes the write handler, if set.
 func (s *SeriesSegment) Clone() *SeriesSegment {
 	return &SeriesSegment{
-		id:   s.id,
-		path: s.path,
-		data: s.data,
-		size: s.size,
+		id:      s.id,
+		path:    s.path,
+		data:    s.data,
+		size:    s.size,
+		keyring: s.keyring,
 	}
 }
 
@@ -280,15 +330,18 @@ func FindSegment(a []*SeriesSegment, id uint16) *SeriesSegment {
 	return nil
 }
 
-// ReadSeriesKeyFromSegments returns a series key from an offset within a set of segments.
+// ReadSeriesKeyFromSegments returns the series key of the insert entry at an offset within a set of segments.
+// Returns nil if the segment is not found or the series key cannot be decrypted.
 func ReadSeriesKeyFromSegments(a []*SeriesSegment, offset int64) []byte {
 	segmentID, pos := SplitSeriesOffset(offset)
 	segment := FindSegment(a, segmentID)
 	if segment == nil {
 		return nil
 	}
-	buf := segment.Slice(pos)
-	key, _ := ReadSeriesKey(buf)
+	key, err := segment.SeriesKey(pos)
+	if err != nil {
+		return nil
+	}
 	return key
 }
 
@@ -377,6 +430,11 @@ func ReadSeriesEntry(data []byte) (flag uint8, id SeriesIDTyped, key []byte, sz
 	switch flag {
 	case SeriesEntryInsertFlag:
 		key, _ = ReadSeriesKey(data)
+	case SeriesEntryEncryptedInsertFlag:
+		// The encrypted series key is returned without its length.
+		sz, n := binary.Uvarint(data)
+		key = data[n : n+int(sz)]
+		return flag, id, key, int64(SeriesEntryHeaderSize + n + len(key))
 	}
 	return flag, id, key, int64(SeriesEntryHeaderSize + len(key))
 }
@@ -391,6 +449,10 @@ func AppendSeriesEntry(dst []byte, flag uint8, id SeriesIDTyped, key []byte) []b
 	switch flag {
 	case SeriesEntryInsertFlag:
 		dst = append(dst, key...)
+	case SeriesEntryEncryptedInsertFlag:
+		sz := make([]byte, binary.MaxVarintLen64)
+		dst = append(dst, sz[:binary.PutUvarint(sz, uint64(len(key)))]...)
+		dst = append(dst, key...)
 	case SeriesEntryTombstoneFlag:
 	default:
 		panic(fmt.Sprintf("unreachable: invalid flag: %d", flag))
@@ -398,12 +460,27 @@ func AppendSeriesEntry(dst []byte, flag uint8, id SeriesIDTyped, key []byte) []b
 	return dst
 }
 
+// AppendEncryptedSeriesEntry appends an insert entry of the series key encrypted with the
+// current key of keyring to dst.
+func AppendEncryptedSeriesEntry(dst []byte, keyring *encryption.Keyring, id SeriesIDTyped, key []byte) ([]byte, error) {
+	ciphertext, err := keyring.Encrypt(nil, key)
+	if err != nil {
+		return nil, err
+	}
+	return AppendSeriesEntry(dst, SeriesEntryEncryptedInsertFlag, id, ciphertext), nil
+}
+
 // IsValidSeriesEntryFlag returns true if flag is valid.
 func IsValidSeriesEntryFlag(flag byte) bool {
 	switch flag {
-	case SeriesEntryInsertFlag, SeriesEntryTombstoneFlag:
+	case SeriesEntryInsertFlag, SeriesEntryTombstoneFlag, SeriesEntryEncryptedInsertFlag:
 		return true
 	default:
 		return false
 	}
 }
+
+// IsSeriesEntryInsertFlag returns true if flag is the flag of an insert entry, encrypted or not.
+func IsSeriesEntryInsertFlag(flag byte) bool {
+	return flag == SeriesEntryInsertFlag || flag == SeriesEntryEncryptedInsertFlag
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 )
 
@@ -266,3 +267,29 @@ func TestSeriesEntry(t *testing.T) {
 		t.Fatalf("unexpected size: %d", sz)
 	}
 }
+
+func TestSeriesEntry_Encrypted(t *testing.T) {
+	keyring, err := encryption.NewKeyring(encryption.Key{ID: 1, Secret: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seriesKey := tsdb.AppendSeriesKey(nil, []byte("m0"), nil)
+	buf, err := tsdb.AppendEncryptedSeriesEntry(nil, keyring, toTypedSeriesID(2), seriesKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flag, id, ciphertext, sz := tsdb.ReadSeriesEntry(buf)
+	if flag != tsdb.SeriesEntryEncryptedInsertFlag {
+		t.Fatalf("unexpected flag: %d", flag)
+	} else if id != toTypedSeriesID(2) {
+		t.Fatalf("unexpected id: %d", id)
+	} else if sz != int64(len(buf)) {
+		t.Fatalf("unexpected size: %d", sz)
+	}
+	if key, err := keyring.Decrypt(nil, ciphertext); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(seriesKey, key) {
+		t.Fatalf("unexpected key: %q", key)
+	}
+}
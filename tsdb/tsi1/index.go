@@ -1,12 +1,14 @@
 package tsi1
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -359,6 +361,16 @@ func (i *Index) Close() error {
 // Path returns the path the index was opened with.
 func (i *Index) Path() string { return i.path }
 
+// Backup writes a snapshot of the partitions of the index to the tar archive tw, in the directory dir.
+func (i *Index) Backup(tw *tar.Writer, dir string) error {
+	for _, p := range i.partitions {
+		if err := p.Backup(tw, path.Join(dir, filepath.Base(p.Path()))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PartitionAt returns the partition by index.
 func (i *Index) PartitionAt(index int) *Partition {
 	return i.partitions[index]
@@ -282,6 +282,19 @@ func (f *LogFile) TombstoneSeriesIDSet() (*tsdb.SeriesIDSet, error) {
 	return f.tombstoneSeriesIDSet, nil
 }
 
+// Flush writes the buffered entries to the file, and returns the size of the file.
+func (f *LogFile) Flush() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.w != nil {
+		if err := f.w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return f.size, nil
+}
+
 // Size returns the size of the file, in bytes.
 func (f *LogFile) Size() int64 {
 	f.mu.RLock()
@@ -1,6 +1,7 @@
 package tsi1
 
 import (
+	"archive/tar"
 	"bufio"
 	"encoding/json"
 	"errors"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
+	intar "github.com/influxdata/influxdb/pkg/tar"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 	"github.com/prometheus/client_golang/prometheus"
@@ -478,6 +480,61 @@ func (p *Partition) manifest(fs *FileSet) *Manifest {
 	return m
 }
 
+// Backup writes a snapshot of the manifest, the stats and the files of the partition to the
+// tar archive tw, in the directory dir. Log files are written up to their last entry.
+func (p *Partition) Backup(tw *tar.Writer, dir string) error {
+	files, err := p.snapshot()
+	if err != nil {
+		return err
+	}
+	return intar.WriteFiles(tw, dir, files)
+}
+
+// snapshot opens the files of the partition, with the sizes they have now.
+func (p *Partition) snapshot() (files []intar.File, err error) {
+	defer func() {
+		if err != nil {
+			intar.CloseFiles(files)
+		}
+	}()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	select {
+	case <-p.closing:
+		return nil, errors.New("index is closing")
+	default:
+	}
+
+	// The manifest is rewritten in place, so it is read rather than opened.
+	buf, err := ioutil.ReadFile(p.manifestPath())
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, intar.DataFile(ManifestFileName, buf))
+
+	if f, err := intar.OpenFile(StatsFileName, p.StatsPath(), -1, -1); err == nil {
+		files = append(files, f)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, file := range p.fileSet.files {
+		n := int64(-1)
+		if f, ok := file.(*LogFile); ok {
+			if n, err = f.Flush(); err != nil {
+				return nil, err
+			}
+		}
+		f, err := intar.OpenFile(filepath.Base(file.Path()), file.Path(), n, n)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
 // StatsPath returns the path to the partition's stats file.
 func (p *Partition) StatsPath() string {
 	return filepath.Join(p.path, StatsFileName)
@@ -0,0 +1,431 @@
+package tsi1
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// Kinds of the discrepancies found by Index.VerifyPartition.
+const (
+	// DiscrepancySeriesNotInSeriesFile is a series of the index missing or deleted from the series file.
+	DiscrepancySeriesNotInSeriesFile = "seriesNotInSeriesFile"
+
+	// DiscrepancySeriesInWrongPartition is a series of the index in a partition its key does not belong to.
+	DiscrepancySeriesInWrongPartition = "seriesInWrongPartition"
+
+	// DiscrepancySeriesMisindexed is a series listed by a measurement or a tag value it does not have.
+	DiscrepancySeriesMisindexed = "seriesMisindexed"
+
+	// DiscrepancySeriesNotIndexed is a series missing from its measurement or one of its tag values,
+	// which queries on them do not find.
+	DiscrepancySeriesNotIndexed = "seriesNotIndexed"
+)
+
+// ErrVerifyInterrupted is returned if the verification of a partition is canceled.
+var ErrVerifyInterrupted = errors.New("tsi1: verification interrupted")
+
+// MaxReportedDiscrepancies is the number of discrepancies of a partition kept in its report.
+// The others are only counted.
+const MaxReportedDiscrepancies = 100
+
+// rebuildBatchSize is the number of series added at once to the file of a rebuilt partition.
+const rebuildBatchSize = 10000
+
+// Discrepancy is a series of a partition whose index does not match the series file.
+type Discrepancy struct {
+	Kind     string
+	SeriesID tsdb.SeriesID
+
+	// Key is the key of the series in the series file, or nil if it is not in the series file.
+	Key []byte
+}
+
+// PartitionReport is the result of the verification of a partition.
+type PartitionReport struct {
+	Partition int
+	SeriesN   uint64
+
+	// DiscrepancyN is the number of discrepancies found, of which the first
+	// MaxReportedDiscrepancies are in Discrepancies.
+	DiscrepancyN  int
+	Discrepancies []Discrepancy
+}
+
+// VerifyPartition checks that the series of a partition are in the series file, belong to the
+// partition, and are listed by their measurement and their tag values, and only by them.
+// The partition stays online, and the series created or dropped while it is verified are ignored.
+func (i *Index) VerifyPartition(idx int, cancel <-chan struct{}) (*PartitionReport, error) {
+	if idx < 0 || idx >= len(i.partitions) {
+		return nil, errors.New("index partition out of range")
+	}
+
+	report, err := i.partitions[idx].verify(func(key []byte) bool { return i.partitionIdx(key) == idx }, cancel)
+	if err != nil {
+		return nil, err
+	}
+	report.Partition = idx
+	return report, nil
+}
+
+// RebuildPartition replaces the files of a partition with a single index file holding the series
+// of the partition found in the series file, which drops the series that are not in the series file
+// or do not belong to the partition, and lists every series by its measurement and tag values.
+//
+// The partition stays online: queries read the previous files until the rebuilt file replaces them,
+// and the series created while it is rebuilt are written to a new log file, which is kept.
+func (i *Index) RebuildPartition(idx int) error {
+	if idx < 0 || idx >= len(i.partitions) {
+		return errors.New("index partition out of range")
+	}
+	p := i.partitions[idx]
+
+	// The cached series of the measurements of the partition may hold the series dropped by the rebuild.
+	var names [][]byte
+	if err := p.ForEachMeasurementName(func(name []byte) error {
+		names = append(names, name)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := p.rebuild(func(key []byte) bool { return i.partitionIdx(key) == idx }); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		i.tagValueCache.DeleteMeasurement(name)
+	}
+	return nil
+}
+
+// HasSeries returns true if the series of the key and id is in the index.
+func (i *Index) HasSeries(key []byte, id tsdb.SeriesID) bool {
+	return i.partition(key).seriesIDSet.Contains(id)
+}
+
+// verify checks the series of the partition, see Index.VerifyPartition.
+// The function belongs returns true if a series key belongs to the partition.
+func (p *Partition) verify(belongs func(key []byte) bool, cancel <-chan struct{}) (*PartitionReport, error) {
+	// Series are added to the log file before the series set, so every series of the
+	// set is in the file set.
+	p.mu.RLock()
+	if p.isClosing() {
+		p.mu.RUnlock()
+		return nil, errors.New("index is closing")
+	}
+	fs, err := p.fileSet.Duplicate()
+	if err != nil {
+		p.mu.RUnlock()
+		return nil, err
+	}
+	live := p.seriesIDSet.Clone()
+	p.mu.RUnlock()
+	defer fs.Release()
+
+	report := &PartitionReport{SeriesN: live.Cardinality()}
+	add := func(kind string, id tsdb.SeriesID, key []byte) {
+		// Ignore the series dropped while the partition is verified, whose tombstones may
+		// have been read before their removal from the series set.
+		if !p.seriesIDSet.Contains(id) {
+			return
+		}
+		report.DiscrepancyN++
+		if len(report.Discrepancies) < MaxReportedDiscrepancies {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{Kind: kind, SeriesID: id, Key: key})
+		}
+	}
+	seriesKey := func(id tsdb.SeriesID) []byte {
+		if p.sfile.IsDeleted(id) {
+			return nil
+		}
+		return p.sfile.SeriesKey(id)
+	}
+
+	listed := tsdb.NewSeriesIDSet()
+	itr := fs.MeasurementIterator()
+	for e := itr.Next(); e != nil; e = itr.Next() {
+		select {
+		case <-cancel:
+			return nil, ErrVerifyInterrupted
+		default:
+		}
+		if e.Deleted() {
+			continue
+		}
+		name := e.Name()
+
+		// Count the tag values listing each series of the measurement, which must be its number of tags.
+		tagN := make(map[tsdb.SeriesID]int)
+		kitr := fs.TagKeyIterator(name)
+		for ke := kitr.Next(); ke != nil; ke = kitr.Next() {
+			if ke.Deleted() {
+				continue
+			}
+			vitr := ke.TagValueIterator()
+			for ve := vitr.Next(); ve != nil; ve = vitr.Next() {
+				if ve.Deleted() {
+					continue
+				}
+				sitr, err := fs.TagValueSeriesIDIterator(name, ke.Key(), ve.Value())
+				if err != nil {
+					return nil, err
+				}
+				if err := forEachSeriesID(sitr, func(id tsdb.SeriesID) {
+					if !live.Contains(id) {
+						return
+					}
+					key := seriesKey(id)
+					if key == nil {
+						return // Reported with the series of the measurement.
+					}
+					if n, tags := tsdb.ParseSeriesKey(key); !bytes.Equal(n, name) || !bytes.Equal(tags.Get(ke.Key()), ve.Value()) {
+						add(DiscrepancySeriesMisindexed, id, key)
+						return
+					}
+					tagN[id]++
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err := forEachSeriesID(fs.MeasurementSeriesIDIterator(name), func(id tsdb.SeriesID) {
+			if !live.Contains(id) {
+				return
+			}
+			listed.Add(id)
+
+			key := seriesKey(id)
+			if key == nil {
+				add(DiscrepancySeriesNotInSeriesFile, id, nil)
+				return
+			}
+			n, tags := tsdb.ParseSeriesKey(key)
+			switch {
+			case !bytes.Equal(n, name):
+				add(DiscrepancySeriesMisindexed, id, key)
+			case !belongs(models.MakeKey(n, tags)):
+				add(DiscrepancySeriesInWrongPartition, id, key)
+			case tagN[id] != len(tags):
+				add(DiscrepancySeriesNotIndexed, id, key)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// The series of the partition no measurement lists.
+	unlisted := live.Clone()
+	unlisted.Diff(listed)
+	unlisted.ForEach(func(id tsdb.SeriesID) {
+		if key := seriesKey(id); key == nil {
+			add(DiscrepancySeriesNotInSeriesFile, id, nil)
+		} else {
+			add(DiscrepancySeriesNotIndexed, id, key)
+		}
+	})
+	return report, nil
+}
+
+// rebuild replaces the files of the partition, see Index.RebuildPartition.
+// The function belongs returns true if a series key belongs to the partition.
+func (p *Partition) rebuild(belongs func(key []byte) bool) error {
+	// Level compactions would merge the files created during the rebuild with the files it replaces.
+	p.DisableCompactions()
+	defer p.EnableCompactions()
+	p.Wait()
+
+	// Rotate the active log file, so that the series created from now on are written to files
+	// that are kept, and snapshot the series of the files that are replaced.
+	p.mu.Lock()
+	if p.isClosing() {
+		p.mu.Unlock()
+		return errors.New("index is closing")
+	}
+	if err := p.prependActiveLogFile(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	rotatedID := p.activeLogFile.ID()
+	live := p.seriesIDSet.Clone()
+	stats := p.stats.Clone()
+	p.mu.Unlock()
+
+	log, logEnd := logger.NewOperation(p.logger, "TSI partition rebuild", "tsi1_rebuild_partition", zap.String("tsi1_partition", p.id))
+	defer logEnd()
+
+	// Write the series to a log file, which is compacted to an index file of the last level.
+	id := p.NextSequence()
+	logFile := NewLogFile(p.sfile, filepath.Join(p.path, FormatLogFileName(id)))
+	logFile.nosync = true
+	if err := logFile.Open(); err != nil {
+		return err
+	}
+	defer func() {
+		logFile.Close()
+		os.Remove(logFile.Path())
+	}()
+
+	keep := tsdb.NewSeriesIDSet()
+	var collection tsdb.SeriesCollection
+	for _, raw := range live.Slice() {
+		sid := tsdb.NewSeriesID(raw)
+		if p.sfile.IsDeleted(sid) {
+			continue
+		}
+		key := p.sfile.SeriesKey(sid)
+		if key == nil {
+			continue
+		}
+		name, tags := tsdb.ParseSeriesKey(key)
+		if !belongs(models.MakeKey(name, tags)) {
+			continue
+		}
+
+		collection.Names = append(collection.Names, name)
+		collection.Tags = append(collection.Tags, tags)
+		collection.SeriesIDs = append(collection.SeriesIDs, sid)
+		if collection.Length() >= rebuildBatchSize {
+			if _, err := logFile.AddSeriesList(keep, &collection); err != nil {
+				return err
+			}
+			collection = tsdb.SeriesCollection{}
+		}
+	}
+	if _, err := logFile.AddSeriesList(keep, &collection); err != nil {
+		return err
+	}
+	logStats := logFile.MeasurementCardinalityStats()
+
+	level := len(p.levels) - 1
+	path := filepath.Join(p.path, FormatIndexFileName(id, level))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lvl := p.levels[level]
+	if _, err := logFile.CompactTo(f, lvl.M, lvl.K, p.closing); err != nil {
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	file := NewIndexFile(p.sfile)
+	file.SetPath(path)
+	if err := file.Open(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	// Replace the files created before the rotation, which are the last files of the file set.
+	// Log files compacted since the rotation keep their ID.
+	var old []File
+	if err := func() error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		for _, f := range p.fileSet.files {
+			if f.ID() < rotatedID {
+				old = append(old, f)
+			}
+		}
+		if len(old) == 0 {
+			return errors.New("no index file to replace")
+		}
+		fileSet, err := p.fileSet.MustReplace(old, file)
+		if err != nil {
+			return err
+		}
+
+		// The series dropped since the rotation may have been tombstoned in the replaced files.
+		var dropped []tsdb.SeriesID
+		keep.ForEach(func(id tsdb.SeriesID) {
+			if !p.seriesIDSet.Contains(id) {
+				dropped = append(dropped, id)
+			}
+		})
+		if len(dropped) > 0 {
+			if err := p.activeLogFile.DeleteSeriesIDList(dropped); err != nil {
+				fileSet.Release()
+				return err
+			}
+		}
+
+		manifestSize, err := p.manifest(fileSet).Write()
+		if err != nil {
+			fileSet.Release()
+			return err
+		}
+
+		p.stats.Sub(stats)
+		p.stats.Add(logStats)
+		if err := p.writeStatsFile(); err != nil {
+			fileSet.Release()
+			return err
+		}
+
+		// Now that we can no longer error, update the local state.
+		live.ForEach(func(id tsdb.SeriesID) {
+			if !keep.Contains(id) {
+				p.seriesIDSet.Remove(id)
+			}
+		})
+		p.replaceFileSet(fileSet)
+		p.manifestSize = manifestSize
+
+		p.tracker.SetSeries(p.seriesIDSet.Cardinality())
+		p.tracker.SetFiles(uint64(len(p.fileSet.IndexFiles())), "index")
+		p.tracker.SetFiles(uint64(len(p.fileSet.LogFiles())), "log")
+		p.tracker.SetDiskSize(uint64(p.fileSet.Size()))
+		return nil
+	}(); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	log.Info("Partition rebuilt",
+		zap.String("path", path),
+		zap.Uint64("series", keep.Cardinality()),
+		zap.Uint64("dropped_series", live.Cardinality()-keep.Cardinality()))
+
+	// Closing the replaced files waits until they are no longer referenced.
+	for _, f := range old {
+		if err := f.Close(); err != nil {
+			log.Error("Cannot close index file", zap.Error(err))
+		} else if err := os.Remove(f.Path()); err != nil {
+			log.Error("Cannot remove index file", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// forEachSeriesID calls fn with the series IDs of itr, and closes it. itr may be nil.
+func forEachSeriesID(itr tsdb.SeriesIDIterator, fn func(id tsdb.SeriesID)) error {
+	if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return err
+		} else if e.SeriesID.IsZero() {
+			return nil
+		}
+		fn(e.SeriesID)
+	}
+}
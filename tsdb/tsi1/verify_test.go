@@ -0,0 +1,88 @@
+package tsi1_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+)
+
+func TestIndex_VerifyPartition(t *testing.T) {
+	idx := MustOpenIndex(1, tsi1.NewConfig())
+	defer idx.Close()
+
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "east"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := idx.VerifyPartition(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.SeriesN != 3 || report.DiscrepancyN != 0 {
+		t.Fatalf("unexpected report of a consistent partition: %+v", report)
+	}
+
+	// Deleting a series from the series file only leaves it in the index.
+	sfile := idx.SeriesFile.SeriesFile
+	west := sfile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"region": "west"}), nil)
+	if err := sfile.DeleteSeriesID(west); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = idx.VerifyPartition(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.DiscrepancyN != 1 || report.Discrepancies[0].Kind != tsi1.DiscrepancySeriesNotInSeriesFile || report.Discrepancies[0].SeriesID != west {
+		t.Fatalf("unexpected report of a series deleted from the series file: %+v", report)
+	}
+
+	// Rebuilding the partition drops the series, and keeps the others.
+	if err := idx.RebuildPartition(0); err != nil {
+		t.Fatal(err)
+	}
+	idx.Run(t, func(t *testing.T) {
+		report, err := idx.VerifyPartition(0, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if report.SeriesN != 2 || report.DiscrepancyN != 0 {
+			t.Fatalf("unexpected report of the rebuilt partition: %+v", report)
+		}
+
+		itr, err := idx.TagValueSeriesIDIterator([]byte("cpu"), []byte("region"), []byte("east"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer itr.Close()
+		var ids []tsdb.SeriesID
+		for {
+			e, err := itr.Next()
+			if err != nil {
+				t.Fatal(err)
+			} else if e.SeriesID.IsZero() {
+				break
+			}
+			ids = append(ids, e.SeriesID)
+		}
+		east := idx.SeriesFile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"region": "east"}), nil)
+		if len(ids) != 1 || ids[0] != east {
+			t.Fatalf("got series %v of cpu,region=east, want %v", ids, east)
+		}
+	})
+
+	// Series created after the rebuild are indexed.
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("disk"), Tags: models.NewTags(map[string]string{"region": "north"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := idx.VerifyPartition(0, nil); err != nil {
+		t.Fatal(err)
+	} else if report.SeriesN != 3 || report.DiscrepancyN != 0 {
+		t.Fatalf("unexpected report after creating a series: %+v", report)
+	}
+}
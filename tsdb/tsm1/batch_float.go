@@ -276,6 +276,10 @@ func init() {
 }
 
 func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
+	if len(b) > 0 && b[0]>>4 == floatCompressedZstd {
+		return floatArrayDecodeAllZstd(b, buf)
+	}
+
 	if len(b) < 9 {
 		return []float64{}, nil
 	}
@@ -286,7 +290,7 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 		meaningfulN uint8  = 64 // meaningful bit count
 	)
 
-	// first byte is the compression type; always Gorilla
+	// first byte is the compression type; Gorilla as Zstandard is handled above
 	b = b[1:]
 
 	val = binary.BigEndian.Uint64(b)
@@ -512,3 +516,47 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 ERROR:
 	return (*(*[]float64)(unsafe.Pointer(&dst)))[:0], io.EOF
 }
+
+// floatArrayEncodeAllZstd encodes src into b with Zstandard, returning b.
+// Each value is XORed with the previous one before being compressed, so
+// that the bits successive values have in common compress well.
+func floatArrayEncodeAllZstd(src []float64, b []byte) []byte {
+	raw := make([]byte, 8*len(src))
+	var prev uint64
+	for i, v := range src {
+		u := math.Float64bits(v)
+		binary.BigEndian.PutUint64(raw[8*i:], u^prev)
+		prev = u
+	}
+
+	b = append(b[:0], floatCompressedZstd<<4)
+	return zstdEncoder.EncodeAll(raw, b)
+}
+
+// floatArrayDecodeAllZstd decodes the values of b encoded by floatArrayEncodeAllZstd into buf.
+func floatArrayDecodeAllZstd(b []byte, buf []float64) ([]float64, error) {
+	var raw []byte
+	if len(b) > 1 {
+		var err error
+		if raw, err = zstdDecoder.DecodeAll(b[1:], nil); err != nil {
+			return []float64{}, fmt.Errorf("floatArrayDecodeAll: %v", err)
+		}
+	}
+	if len(raw)%8 != 0 {
+		return []float64{}, fmt.Errorf("floatArrayDecodeAll: invalid length %d of zstd block", len(raw))
+	}
+
+	n := len(raw) / 8
+	if cap(buf) < n {
+		buf = make([]float64, n)
+	} else {
+		buf = buf[:n]
+	}
+
+	var prev uint64
+	for i := range buf {
+		prev ^= binary.BigEndian.Uint64(raw[8*i:])
+		buf[i] = math.Float64frombits(prev)
+	}
+	return buf, nil
+}
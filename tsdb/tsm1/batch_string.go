@@ -62,14 +62,13 @@ func StringArrayEncodeAll(src []string, b []byte) ([]byte, error) {
 }
 
 func StringArrayDecodeAll(b []byte, dst []string) ([]string, error) {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type.
 	if len(b) > 0 {
 		var err error
-		// it is important that to note that `snappy.Decode` always returns
+		// it is important that to note that `decompressStrings` always returns
 		// a newly allocated slice as the final strings reference this slice
 		// directly.
-		b, err = snappy.Decode(nil, b[1:])
+		b, err = decompressStrings(b)
 		if err != nil {
 			return []string{}, fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -0,0 +1,425 @@
+// Package bench runs standardized ingest, snapshot, compaction and query workloads
+// against the tsm1 engine, and compares their results with those of a baseline so
+// that the performance regressions of the engine are caught.
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// Workload is a workload run against the engine.
+type Workload string
+
+const (
+	// WorkloadIngest writes the points to the cache of the engine, and indexes their series.
+	WorkloadIngest Workload = "ingest"
+	// WorkloadSnapshot writes the cache of the engine to TSM files.
+	WorkloadSnapshot Workload = "snapshot"
+	// WorkloadCompaction fully compacts the TSM files written by the snapshots.
+	WorkloadCompaction Workload = "compaction"
+	// WorkloadQuery reads every value of every series.
+	WorkloadQuery Workload = "query"
+)
+
+// Workloads are the workloads run, in order.
+var Workloads = []Workload{WorkloadIngest, WorkloadSnapshot, WorkloadCompaction, WorkloadQuery}
+
+// Config is the size of the data set of the workloads. Reports are only comparable
+// if they were run with the same config.
+type Config struct {
+	// Series is the number of series of the points, each with a float and an integer field.
+	Series int `json:"series"`
+	// PointsPerSeries is the number of points written to each series, 10 seconds apart.
+	PointsPerSeries int `json:"pointsPerSeries"`
+	// BatchSize is the number of points of each write.
+	BatchSize int `json:"batchSize"`
+	// Snapshots is the number of snapshots of the points, and so the number of TSM files compacted.
+	Snapshots int `json:"snapshots"`
+	// Queries is the number of times every series is read.
+	Queries int `json:"queries"`
+}
+
+// DefaultConfig returns the config of the standard workloads.
+func DefaultConfig() Config {
+	return Config{
+		Series:          1000,
+		PointsPerSeries: 1000,
+		BatchSize:       5000,
+		Snapshots:       4,
+		Queries:         10,
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	switch {
+	case c.Series <= 0:
+		return errors.New("series must be positive")
+	case c.PointsPerSeries <= 0:
+		return errors.New("points per series must be positive")
+	case c.BatchSize <= 0:
+		return errors.New("batch size must be positive")
+	case c.Snapshots <= 0:
+		return errors.New("snapshots must be positive")
+	case c.Snapshots > c.PointsPerSeries:
+		return errors.New("snapshots cannot exceed the points per series")
+	case c.Queries <= 0:
+		return errors.New("queries must be positive")
+	}
+	return nil
+}
+
+// Result is the result of a workload.
+type Result struct {
+	Workload Workload `json:"workload"`
+	// N is the number of units processed by the workload, such as values or bytes.
+	N    int64  `json:"n"`
+	Unit string `json:"unit"`
+	// Duration is the time spent running the workload, in nanoseconds.
+	Duration time.Duration `json:"duration"`
+	// Rate is the number of units processed per second. Higher is better.
+	Rate float64 `json:"rate"`
+	// AllocatedBytes is the number of bytes allocated on the heap while running the workload.
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+}
+
+// Report is the machine-readable report of a run of the workloads.
+type Report struct {
+	GoVersion string    `json:"goVersion"`
+	GOOS      string    `json:"goos"`
+	GOARCH    string    `json:"goarch"`
+	CPUs      int       `json:"cpus"`
+	StartedAt time.Time `json:"startedAt"`
+	Config    Config    `json:"config"`
+	Results   []Result  `json:"results"`
+}
+
+// Result returns the result of the workload w, if it was run.
+func (r *Report) Result(w Workload) (Result, bool) {
+	for _, res := range r.Results {
+		if res.Workload == w {
+			return res, true
+		}
+	}
+	return Result{}, false
+}
+
+// WriteReport writes r as JSON to w.
+func WriteReport(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(r)
+}
+
+// ReadReport reads a report written by WriteReport.
+func ReadReport(r io.Reader) (*Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid report: %v", err)
+	}
+	return &report, nil
+}
+
+// The organization and bucket of the points.
+const (
+	orgID    = platform.ID(1)
+	bucketID = platform.ID(1)
+)
+
+// Run runs the workloads of cfg against a new engine in dir, which must be empty.
+func Run(ctx context.Context, dir string, cfg Config) (*Report, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	sfile := tsdb.NewSeriesFile(filepath.Join(dir, "_series"))
+	if err := sfile.Open(ctx); err != nil {
+		return nil, err
+	}
+	defer sfile.Close()
+
+	idx := tsi1.NewIndex(sfile, tsi1.NewConfig(), tsi1.WithPath(filepath.Join(dir, "index")))
+	if err := idx.Open(ctx); err != nil {
+		return nil, err
+	}
+	defer idx.Close()
+
+	// The snapshots and compactions are only run by the workloads, at full speed.
+	config := tsm1.NewConfig()
+	config.Cache.MaxMemorySize = 0
+	config.Compaction.ReadThroughput = 0
+	e := tsm1.NewEngine(filepath.Join(dir, "data"), idx, config, tsm1.WithCompactionPlanner(noPlanner{}))
+	e.CacheFlushMemorySizeThreshold = 1<<63 - 1
+	e.Compactor.RateLimit = nil
+	if err := e.Open(ctx); err != nil {
+		return nil, err
+	}
+	defer e.Close()
+
+	r := &runner{
+		cfg:    cfg,
+		engine: e,
+		index:  idx,
+		rand:   rand.New(rand.NewSource(1)),
+	}
+	report := &Report{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		StartedAt: time.Now().UTC(),
+		Config:    cfg,
+	}
+	if err := r.ingest(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.compact(); err != nil {
+		return nil, err
+	}
+	if err := r.query(ctx); err != nil {
+		return nil, err
+	}
+	for _, w := range Workloads {
+		report.Results = append(report.Results, r.results[w].result(w))
+	}
+	return report, nil
+}
+
+// measure accumulates the time spent and the bytes allocated by a workload.
+type measure struct {
+	unit      string
+	n         int64
+	duration  time.Duration
+	allocated uint64
+}
+
+func (m *measure) time(fn func() (int64, error)) error {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	n, err := fn()
+	m.duration += time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	m.n += n
+	m.allocated += after.TotalAlloc - before.TotalAlloc
+	return err
+}
+
+func (m *measure) result(w Workload) Result {
+	res := Result{
+		Workload:       w,
+		N:              m.n,
+		Unit:           m.unit,
+		Duration:       m.duration,
+		AllocatedBytes: m.allocated,
+	}
+	if m.duration > 0 {
+		res.Rate = float64(m.n) / m.duration.Seconds()
+	}
+	return res
+}
+
+type runner struct {
+	cfg    Config
+	engine *tsm1.Engine
+	index  *tsi1.Index
+	rand   *rand.Rand
+
+	results map[Workload]*measure
+}
+
+func (r *runner) measure(w Workload, unit string) *measure {
+	if r.results == nil {
+		r.results = make(map[Workload]*measure)
+	}
+	if m, ok := r.results[w]; ok {
+		return m
+	}
+	m := &measure{unit: unit}
+	r.results[w] = m
+	return m
+}
+
+// start is the time of the first point of every series.
+var start = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// interval is the time between the points of a series.
+const interval = 10 * time.Second
+
+// ingest writes the points of every series, and snapshots them once per snapshot of the config.
+func (r *runner) ingest(ctx context.Context) error {
+	ingest, snapshot := r.measure(WorkloadIngest, "values"), r.measure(WorkloadSnapshot, "bytes")
+
+	var (
+		batch = make([]models.Point, 0, r.cfg.BatchSize)
+		write = func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			pts, err := tsdb.ExplodePoints(orgID, bucketID, batch)
+			if err != nil {
+				return err
+			}
+			batch = batch[:0]
+
+			return ingest.time(func() (int64, error) {
+				if err := r.index.CreateSeriesListIfNotExists(tsdb.NewSeriesCollection(pts)); err != nil {
+					return 0, err
+				}
+				return int64(len(pts)), r.engine.WritePoints(pts)
+			})
+		}
+	)
+	for s := 0; s < r.cfg.Snapshots; s++ {
+		first, last := s*r.cfg.PointsPerSeries/r.cfg.Snapshots, (s+1)*r.cfg.PointsPerSeries/r.cfg.Snapshots
+		for i := first; i < last; i++ {
+			for series := 0; series < r.cfg.Series; series++ {
+				batch = append(batch, r.point(series, i))
+				if len(batch) == r.cfg.BatchSize {
+					if err := write(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if err := write(); err != nil {
+			return err
+		}
+
+		size := r.engine.FileStore.DiskSizeBytes()
+		if err := snapshot.time(func() (int64, error) {
+			err := r.engine.WriteSnapshot(ctx)
+			return r.engine.FileStore.DiskSizeBytes() - size, err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// point returns the i-th point of the series.
+func (r *runner) point(series, i int) models.Point {
+	tags := models.NewTags(map[string]string{
+		"host":   "host-" + strconv.Itoa(series),
+		"region": "region-" + strconv.Itoa(series%10),
+	})
+	fields := models.Fields{
+		"usage": r.rand.Float64() * 100,
+		"count": r.rand.Int63n(1000),
+	}
+	return models.MustNewPoint("cpu", tags, fields, start.Add(time.Duration(i)*interval))
+}
+
+// compact fully compacts the TSM files written by the snapshots.
+func (r *runner) compact() error {
+	compaction := r.measure(WorkloadCompaction, "bytes")
+
+	var files []string
+	for _, f := range r.engine.FileStore.Files() {
+		files = append(files, f.Path())
+	}
+	size := r.engine.FileStore.DiskSizeBytes()
+
+	return compaction.time(func() (int64, error) {
+		compacted, err := r.engine.Compactor.CompactFull(files)
+		if err != nil {
+			return 0, err
+		}
+		return size, r.engine.FileStore.Replace(files, compacted)
+	})
+}
+
+// query reads every value of every series of the points, once per query of the config.
+func (r *runner) query(ctx context.Context) error {
+	for i := 0; i < r.cfg.Queries; i++ {
+		if err := r.queryOnce(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) queryOnce(ctx context.Context) error {
+	query := r.measure(WorkloadQuery, "values")
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	return query.time(func() (int64, error) {
+		itr, err := r.engine.CreateCursorIterator(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		var n int64
+		for series := 0; series < r.cfg.Series; series++ {
+			for _, field := range []string{"count", "usage"} {
+				tags := models.NewTags(map[string]string{
+					models.MeasurementTagKey: "cpu",
+					"host":                   "host-" + strconv.Itoa(series),
+					"region":                 "region-" + strconv.Itoa(series%10),
+					models.FieldKeyTagKey:    field,
+				})
+				cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+					Name:      name[:],
+					Tags:      tags,
+					Field:     field,
+					Ascending: true,
+					StartTime: models.MinNanoTime,
+					EndTime:   models.MaxNanoTime,
+				})
+				if err != nil {
+					return n, err
+				}
+				if cur == nil {
+					return n, fmt.Errorf("series %s of field %s not found", tags.HashKey(), field)
+				}
+				n += readCursor(cur)
+				cur.Close()
+			}
+		}
+		return n, nil
+	})
+}
+
+// readCursor reads every value of cur and returns their number.
+func readCursor(cur tsdb.Cursor) int64 {
+	var n int64
+	switch cur := cur.(type) {
+	case tsdb.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			n += int64(a.Len())
+		}
+	case tsdb.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			n += int64(a.Len())
+		}
+	}
+	return n
+}
+
+// noPlanner plans no compaction, so that the workloads are not disturbed by the
+// compactions of the engine.
+type noPlanner struct{}
+
+func (noPlanner) Plan(time.Time) []tsm1.CompactionGroup { return nil }
+func (noPlanner) PlanLevel(int) []tsm1.CompactionGroup  { return nil }
+func (noPlanner) PlanOptimize() []tsm1.CompactionGroup  { return nil }
+func (noPlanner) Release([]tsm1.CompactionGroup)        {}
+func (noPlanner) FullyCompacted() bool                  { return true }
+func (noPlanner) ForceFull()                            {}
+func (noPlanner) SetFileStore(*tsm1.FileStore)          {}
@@ -0,0 +1,111 @@
+package bench_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1/bench"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-bench-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := bench.Config{Series: 10, PointsPerSeries: 100, BatchSize: 30, Snapshots: 3, Queries: 2}
+	report, err := bench.Run(context.Background(), dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Config != cfg {
+		t.Errorf("got config %+v, want %+v", report.Config, cfg)
+	}
+	// Each point has 2 fields.
+	values := int64(2 * cfg.Series * cfg.PointsPerSeries)
+	for _, w := range bench.Workloads {
+		res, ok := report.Result(w)
+		if !ok {
+			t.Errorf("workload %s missing from the report", w)
+			continue
+		}
+		if res.N <= 0 || res.Duration <= 0 || res.Rate <= 0 {
+			t.Errorf("unexpected result %+v", res)
+		}
+	}
+	if res, _ := report.Result(bench.WorkloadIngest); res.N != values {
+		t.Errorf("ingested %d values, want %d", res.N, values)
+	}
+	if res, _ := report.Result(bench.WorkloadQuery); res.N != int64(cfg.Queries)*values {
+		t.Errorf("read %d values, want %d", res.N, int64(cfg.Queries)*values)
+	}
+
+	var buf bytes.Buffer
+	if err := bench.WriteReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bench.ReadReport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Results, report.Results) || got.Config != report.Config {
+		t.Errorf("got report %+v, want %+v", got, report)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := bench.DefaultConfig().Validate(); err != nil {
+		t.Errorf("default config is invalid: %v", err)
+	}
+
+	cfg := bench.DefaultConfig()
+	cfg.Snapshots = cfg.PointsPerSeries + 1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected more snapshots than points per series to be invalid")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	report := func(cfg bench.Config, ingest, query float64) *bench.Report {
+		return &bench.Report{
+			Config: cfg,
+			Results: []bench.Result{
+				{Workload: bench.WorkloadIngest, Rate: ingest},
+				{Workload: bench.WorkloadQuery, Rate: query},
+			},
+		}
+	}
+	cfg := bench.DefaultConfig()
+
+	cs, err := bench.Compare(report(cfg, 100, 100), report(cfg, 95, 80), 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bench.Comparison{
+		{Workload: bench.WorkloadIngest, Baseline: 100, Current: 95, Change: -0.05},
+		{Workload: bench.WorkloadQuery, Baseline: 100, Current: 80, Change: -0.2, Regressed: true},
+	}
+	if !reflect.DeepEqual(cs, want) {
+		t.Errorf("got comparisons %+v, want %+v", cs, want)
+	}
+	if !bench.Regressed(cs) {
+		t.Error("expected the comparisons to have regressed")
+	}
+
+	// Faster workloads never regress.
+	if cs, err := bench.Compare(report(cfg, 100, 100), report(cfg, 200, 150), 0); err != nil || bench.Regressed(cs) {
+		t.Errorf("got comparisons %+v, %v, want no regression", cs, err)
+	}
+
+	other := cfg
+	other.Series++
+	if _, err := bench.Compare(report(cfg, 100, 100), report(other, 100, 100), 0.1); err == nil {
+		t.Error("expected reports of different configs not to be comparable")
+	}
+}
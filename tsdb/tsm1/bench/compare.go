@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultThreshold is the relative decrease of the rate of a workload over which it regressed by default.
+const DefaultThreshold = 0.1
+
+// Comparison is the change of the rate of a workload between a baseline report and a current one.
+type Comparison struct {
+	Workload Workload `json:"workload"`
+	Baseline float64  `json:"baseline"`
+	Current  float64  `json:"current"`
+	// Change is the relative change of the rate, negative if the workload got slower.
+	Change float64 `json:"change"`
+	// Regressed is true if the rate decreased by more than the threshold of the comparison.
+	Regressed bool `json:"regressed"`
+}
+
+// Compare compares the rates of the workloads of the current report with those of
+// the baseline. A workload regressed if its rate decreased by more than threshold,
+// such as 0.1 for 10%. The reports must have been run with the same config.
+func Compare(baseline, current *Report, threshold float64) ([]Comparison, error) {
+	if threshold < 0 {
+		return nil, errors.New("threshold cannot be negative")
+	}
+	if baseline.Config != current.Config {
+		return nil, fmt.Errorf("reports of different configs cannot be compared: baseline %+v, current %+v", baseline.Config, current.Config)
+	}
+
+	var cs []Comparison
+	for _, b := range baseline.Results {
+		c, ok := current.Result(b.Workload)
+		if !ok {
+			return nil, fmt.Errorf("workload %s of the baseline is missing from the current report", b.Workload)
+		}
+
+		cmp := Comparison{
+			Workload: b.Workload,
+			Baseline: b.Rate,
+			Current:  c.Rate,
+		}
+		if b.Rate > 0 {
+			cmp.Change = (c.Rate - b.Rate) / b.Rate
+			cmp.Regressed = cmp.Change < -threshold
+		}
+		cs = append(cs, cmp)
+	}
+	return cs, nil
+}
+
+// Regressed returns true if a workload of the comparisons regressed.
+func Regressed(cs []Comparison) bool {
+	for _, c := range cs {
+		if c.Regressed {
+			return true
+		}
+	}
+	return false
+}
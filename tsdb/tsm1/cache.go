@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
@@ -593,6 +594,38 @@ func (c *Cache) DeleteBucketRange(name []byte, min, max int64) {
 	c.tracker.SetMemBytes(uint64(c.Size()))
 }
 
+// DeleteRange removes the values of keys with timestamps between min and max from the cache.
+func (c *Cache) DeleteRange(keys [][]byte, min, max int64) {
+	c.init()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total uint64
+	for _, k := range keys {
+		e := c.store.entry(k)
+		if e == nil {
+			continue
+		}
+		total += uint64(e.size())
+
+		// filter the values and subtract out the remaining bytes from the reduction.
+		if min != math.MinInt64 || max != math.MaxInt64 {
+			e.filter(min, max)
+			total -= uint64(e.size())
+		}
+
+		// if it has no entries left, remove it.
+		if min == math.MinInt64 && max == math.MaxInt64 || e.count() == 0 {
+			total += uint64(len(k))
+			c.store.remove(k)
+		}
+	}
+
+	c.tracker.DecCacheSize(total)
+	c.tracker.SetMemBytes(uint64(c.Size()))
+}
+
 // SetMaxSize updates the memory limit of the cache.
 func (c *Cache) SetMaxSize(size uint64) {
 	c.mu.Lock()
@@ -658,6 +691,11 @@ func (cl *CacheLoader) Load(cache *Cache) error {
 	})
 }
 
+// WithKeyring sets the keyring decrypting the encrypted entries of the segment files.
+func (cl *CacheLoader) WithKeyring(keyring *encryption.Keyring) {
+	cl.reader.WithKeyring(keyring)
+}
+
 // WithLogger sets the logger on the CacheLoader.
 func (cl *CacheLoader) WithLogger(logger *zap.Logger) {
 	cl.reader.WithLogger(logger.With(zap.String("service", "cacheloader")))
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb"
 )
@@ -42,6 +43,10 @@ const (
 
 	// TSSFileExtension is the extension used for TSM stats files.
 	TSSFileExtension = "tss"
+
+	// TierFileExtension is the extension used for the files recording where a TSM file
+	// moved to a storage tier is stored.
+	TierFileExtension = "tier"
 )
 
 var (
@@ -140,6 +145,14 @@ func NewDefaultPlanner(fs fileStore, writeColdDuration time.Duration) *DefaultPl
 	}
 }
 
+// SetFullWriteColdDuration changes how long the planner waits without writes before
+// planning a full compaction.
+func (c *DefaultPlanner) SetFullWriteColdDuration(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compactFullWriteColdDuration = d
+}
+
 // tsmGeneration represents the TSM files within a generation.
 // 000001-01.tsm, 000001-02.tsm would be in the same generation
 // 000001 each with different sequence numbers.
@@ -410,10 +423,11 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 
 	c.mu.RLock()
 	forceFull := c.forceFull
+	coldDuration := c.compactFullWriteColdDuration
 	c.mu.RUnlock()
 
 	// first check if we should be doing a full compaction because nothing has been written in a long time
-	if forceFull || c.compactFullWriteColdDuration > 0 && time.Since(lastWrite) > c.compactFullWriteColdDuration && len(generations) > 1 {
+	if forceFull || coldDuration > 0 && time.Since(lastWrite) > coldDuration && len(generations) > 1 {
 
 		// Reset the full schedule if we planned because of it.
 		if forceFull {
@@ -700,6 +714,15 @@ type Compactor struct {
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
+	// keyring encrypts the blocks of the TSM files written, if not nil.
+	keyring *encryption.Keyring
+
+	// blockCompression compresses the values of the blocks of the TSM files written.
+	blockCompression BlockCompression
+
+	// readPriority throttles the reads of compactions in favour of those of queries, if not nil.
+	readPriority *readPriority
+
 	mu                 sync.RWMutex
 	snapshotsEnabled   bool
 	compactionsEnabled bool
@@ -733,6 +756,18 @@ func (c *Compactor) WithParseFileNameFunc(parseFileNameFunc ParseFileNameFunc) {
 	c.parseFileName = parseFileNameFunc
 }
 
+// WithKeyring sets the keyring encrypting the blocks of the TSM files written with its current key.
+// Compacting files encrypted with older keys encrypts them with the current key.
+func (c *Compactor) WithKeyring(keyring *encryption.Keyring) {
+	c.keyring = keyring
+}
+
+// WithBlockCompression sets the compression of the values of the float and string blocks
+// of the TSM files written. Compacting files compresses their blocks with it.
+func (c *Compactor) WithBlockCompression(compression BlockCompression) {
+	c.blockCompression = compression
+}
+
 // Open initializes the Compactor.
 func (c *Compactor) Open() {
 	c.mu.Lock()
@@ -940,7 +975,7 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 		return nil, nil
 	}
 
-	tsm, err := NewTSMBatchKeyIterator(size, fast, intC, trs...)
+	tsm, err := newTSMBatchKeyIterator(size, fast, intC, c.readPriority, trs...)
 	if err != nil {
 		return nil, err
 	}
@@ -1110,12 +1145,12 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 	// Use a disk based TSM buffer if it looks like we might create a big index
 	// in memory.
 	if iter.EstimatedIndexSize() > 64*1024*1024 {
-		w, err = NewTSMWriterWithDiskBuffer(limitWriter)
+		w, err = NewTSMWriterWithDiskBuffer(limitWriter, WithTSMWriterKeyring(c.keyring))
 		if err != nil {
 			return err
 		}
 	} else {
-		w, err = NewTSMWriter(limitWriter)
+		w, err = NewTSMWriter(limitWriter, WithTSMWriterKeyring(c.keyring))
 		if err != nil {
 			return err
 		}
@@ -1160,6 +1195,10 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		if block, err = compressBlock(c.blockCompression, block); err != nil {
+			return err
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
@@ -1626,9 +1665,17 @@ type tsmBatchKeyIterator struct {
 // NewTSMBatchKeyIterator returns a new TSM key iterator from readers.
 // size indicates the maximum number of values to encode in a single block.
 func NewTSMBatchKeyIterator(size int, fast bool, interrupt chan struct{}, readers ...*TSMReader) (KeyIterator, error) {
+	return newTSMBatchKeyIterator(size, fast, interrupt, nil, readers...)
+}
+
+// newTSMBatchKeyIterator returns a new TSM key iterator from readers, of which the reads
+// are throttled by priority in favour of those of queries, if not nil.
+func newTSMBatchKeyIterator(size int, fast bool, interrupt chan struct{}, priority *readPriority, readers ...*TSMReader) (KeyIterator, error) {
 	var iter []*BlockIterator
 	for _, r := range readers {
-		iter = append(iter, r.BlockIterator())
+		it := r.BlockIterator()
+		it.priority = priority
+		iter = append(iter, it)
 	}
 
 	return &tsmBatchKeyIterator{
@@ -0,0 +1,92 @@
+package tsm1
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// BlockCompression is the compression of the values of the float and string
+// blocks written to TSM files.
+type BlockCompression string
+
+const (
+	// BlockCompressionDefault compresses float values with the Gorilla
+	// encoding and string values with snappy.
+	BlockCompressionDefault BlockCompression = "default"
+
+	// BlockCompressionZstd compresses float and string values with Zstandard.
+	// It uses more CPU than the default when compacting, for smaller files.
+	BlockCompressionZstd BlockCompression = "zstd"
+)
+
+// Valid returns an error if c is not a known block compression. Empty is the default.
+func (c BlockCompression) Valid() error {
+	switch c {
+	case "", BlockCompressionDefault, BlockCompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unknown block compression %q, expected %q or %q", string(c), BlockCompressionDefault, BlockCompressionZstd)
+	}
+}
+
+var (
+	// zstdEncoder and zstdDecoder compress and decompress whole blocks,
+	// which they may do concurrently.
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// compressBlock returns block with its values compressed with c. Only the values of
+// float and string blocks are compressed differently, other blocks are returned as is,
+// as are blocks already compressed with c. Blocks compressed with Zstandard are read
+// whatever the block compression, so it can be changed at any time: compactions
+// rewrite the blocks of the files they compact with the current one.
+func compressBlock(c BlockCompression, block []byte) ([]byte, error) {
+	if len(block) == 0 || (block[0] != BlockFloat64 && block[0] != BlockString) {
+		return block, nil
+	}
+
+	typ := block[0]
+	tb, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(vb) == 0 {
+		return block, nil
+	}
+
+	useZstd := c == BlockCompressionZstd
+	switch typ {
+	case BlockFloat64:
+		if (vb[0]>>4 == floatCompressedZstd) == useZstd {
+			return block, nil
+		}
+		values, err := FloatArrayDecodeAll(vb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if useZstd {
+			vb = floatArrayEncodeAllZstd(values, nil)
+		} else if vb, err = FloatArrayEncodeAll(values, nil); err != nil {
+			return nil, err
+		}
+
+	case BlockString:
+		if (vb[0]>>4 == stringCompressedZstd) == useZstd {
+			return block, nil
+		}
+		data, err := decompressStrings(vb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err)
+		}
+		if useZstd {
+			vb = zstdEncoder.EncodeAll(data, []byte{stringCompressedZstd << 4})
+		} else {
+			vb = append([]byte{stringCompressedSnappy << 4}, snappy.Encode(nil, data)...)
+		}
+	}
+
+	return packBlock(nil, typ, tb, vb), nil
+}
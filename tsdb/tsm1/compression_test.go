@@ -0,0 +1,112 @@
+package tsm1_test
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestBlockCompression_Valid(t *testing.T) {
+	for _, c := range []tsm1.BlockCompression{"", tsm1.BlockCompressionDefault, tsm1.BlockCompressionZstd} {
+		if err := c.Valid(); err != nil {
+			t.Errorf("unexpected error for %q: %v", c, err)
+		}
+	}
+	if err := tsm1.BlockCompression("lz4").Valid(); err == nil {
+		t.Error("expected error for unknown block compression")
+	}
+}
+
+func TestCompactor_CompactFull_BlockCompression(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	floats := []tsm1.Value{tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 1.5), tsm1.NewValue(3, -2.25)}
+	strings := []tsm1.Value{tsm1.NewValue(1, "a"), tsm1.NewValue(2, ""), tsm1.NewValue(3, "a much longer string")}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": floats[:2],
+		"mem,host=A#!~#value": strings[:2],
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value":  floats[2:],
+		"mem,host=A#!~#value":  strings[2:],
+		"disk,host=A#!~#value": {tsm1.NewValue(1, int64(1))},
+	})
+
+	// Compacting with zstd rewrites the blocks of the existing files with it,
+	// and compacting with the default compression rewrites them back.
+	files := []string{f1, f2}
+	for _, c := range []tsm1.BlockCompression{tsm1.BlockCompressionZstd, tsm1.BlockCompressionDefault} {
+		fs := &fakeFileStore{}
+		compactor := tsm1.NewCompactor()
+		compactor.Dir = dir
+		compactor.FileStore = fs
+		compactor.WithBlockCompression(c)
+		compactor.Open()
+
+		var err error
+		files, err = compactor.CompactFull(files)
+		if err != nil {
+			t.Fatalf("unexpected error compacting with %s: %v", c, err)
+		} else if len(files) != 1 {
+			t.Fatalf("unexpected files: %v", files)
+		}
+		fs.Close()
+
+		r := MustOpenTSMReader(files[0])
+		wantZstd := c == tsm1.BlockCompressionZstd
+		for key, exp := range map[string][]tsm1.Value{
+			"cpu,host=A#!~#value":  floats,
+			"mem,host=A#!~#value":  strings,
+			"disk,host=A#!~#value": {tsm1.NewValue(1, int64(1))},
+		} {
+			values, err := r.ReadAll([]byte(key))
+			if err != nil {
+				t.Fatal(err)
+			} else if len(values) != len(exp) {
+				t.Fatalf("%s: unexpected values for %s: %v", c, key, values)
+			}
+			for i := range exp {
+				if values[i].UnixNano() != exp[i].UnixNano() || values[i].Value() != exp[i].Value() {
+					t.Fatalf("%s: unexpected value %d for %s: got %v, exp %v", c, i, key, values[i], exp[i])
+				}
+			}
+
+			entries, err := r.ReadEntries([]byte(key), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, block, err := r.ReadBytes(&entries[0], nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch key {
+			case "cpu,host=A#!~#value":
+				var a tsdb.FloatArray
+				if err := tsm1.DecodeFloatArrayBlock(block, &a); err != nil {
+					t.Fatal(err)
+				} else if a.Len() != len(floats) || a.Values[2] != -2.25 {
+					t.Fatalf("%s: unexpected float array: %v", c, a.Values)
+				}
+			case "mem,host=A#!~#value":
+				var a tsdb.StringArray
+				if err := tsm1.DecodeStringArrayBlock(block, &a); err != nil {
+					t.Fatal(err)
+				} else if a.Len() != len(strings) || a.Values[2] != "a much longer string" {
+					t.Fatalf("%s: unexpected string array: %v", c, a.Values)
+				}
+			case "disk,host=A#!~#value":
+				continue
+			}
+			// The values follow the type, the length of the timestamps and the timestamps.
+			tsLen, n := binary.Uvarint(block[1:])
+			if gotZstd := block[1+n+int(tsLen)]>>4 == 2; gotZstd != wantZstd {
+				t.Errorf("%s: unexpected compression of %s: %x", c, key, block)
+			}
+		}
+		r.Close()
+	}
+}
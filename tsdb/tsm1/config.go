@@ -25,6 +25,11 @@ type Config struct {
 	// slow disks.
 	MADVWillNeed bool `toml:"use-madv-willneed"`
 
+	// BlockCompression is the compression of the values of the float and string blocks
+	// written to TSM files, "default" or "zstd". Blocks are read whatever the compression
+	// they were written with, and compactions rewrite them with the current one.
+	BlockCompression string `toml:"block-compression"`
+
 	Compaction CompactionConfig `toml:"compaction"`
 	Cache      CacheConfig      `toml:"cache"`
 }
@@ -34,6 +39,7 @@ func NewConfig() Config {
 	return Config{
 		MaxConcurrentOpens: DefaultMaxConcurrentOpens,
 		MADVWillNeed:       DefaultMADVWillNeed,
+		BlockCompression:   string(BlockCompressionDefault),
 
 		Cache: NewCacheConfig(),
 		Compaction: CompactionConfig{
@@ -41,6 +47,7 @@ func NewConfig() Config {
 			Throughput:            toml.Size(DefaultCompactThroughput),
 			ThroughputBurst:       toml.Size(DefaultCompactThroughputBurst),
 			MaxConcurrent:         DefaultCompactMaxConcurrent,
+			ReadThroughput:        toml.Size(DefaultCompactReadThroughput),
 		},
 	}
 }
@@ -50,6 +57,7 @@ const (
 	DefaultCompactThroughput            = 48 * 1024 * 1024
 	DefaultCompactThroughputBurst       = 48 * 1024 * 1024
 	DefaultCompactMaxConcurrent         = 0
+	DefaultCompactReadThroughput        = 16 * 1024 * 1024
 )
 
 // CompactionConfing holds all of the configuration for compactions. Eventually we want
@@ -73,6 +81,12 @@ type CompactionConfig struct {
 	// MaxConcurrent is the maximum number of concurrent full and level compactions that can
 	// run at one time.  A value of 0 results in 50% of runtime.GOMAXPROCS(0) used at runtime.
 	MaxConcurrent int `toml:"max-concurrent"`
+
+	// ReadThroughput is the rate limit in bytes per second that we will allow TSM compactions
+	// to read from disk while queries are reading, so that queries get precedence when the
+	// disk saturates. Compactions read without limit otherwise. A value of 0 here will disable
+	// prioritizing the reads of queries.
+	ReadThroughput toml.Size `toml:"read-throughput"`
 }
 
 // Default Cache configuration values.
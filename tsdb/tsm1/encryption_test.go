@@ -0,0 +1,259 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func mustKeyring(tb testing.TB, ids ...uint32) *encryption.Keyring {
+	tb.Helper()
+	var keys []encryption.Key
+	for _, id := range ids {
+		keys = append(keys, encryption.Key{ID: id, Secret: bytes.Repeat([]byte{byte(id)}, 32)})
+	}
+	k, err := encryption.NewKeyring(keys...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return k
+}
+
+// mustWriteEncryptedTSM writes values to a TSM file of generation gen encrypted with keyring.
+func mustWriteEncryptedTSM(tb testing.TB, dir string, gen int, keyring *encryption.Keyring, values map[string][]tsm1.Value) string {
+	tb.Helper()
+	name := filepath.Join(dir, tsm1.DefaultFormatFileName(gen, 1)+"."+tsm1.TSMFileExtension)
+	f, err := os.Create(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f, tsm1.WithTSMWriterKeyring(keyring))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, key := range []string{"cpu,host=A#!~#value", "mem,host=A#!~#value"} {
+		if err := w.Write([]byte(key), values[key]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return name
+}
+
+func TestTSMReader_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keyring := mustKeyring(t, 1)
+	name := mustWriteEncryptedTSM(t, dir, 1, keyring, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 2.5)},
+		"mem,host=A#!~#value": {tsm1.NewValue(1, "secret-value")},
+	})
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b[4]; got != tsm1.EncryptedVersion {
+		t.Fatalf("unexpected version: got %d, exp %d", got, tsm1.EncryptedVersion)
+	}
+	if bytes.Contains(b, []byte("secret-value")) {
+		t.Fatal("expected the values to be encrypted")
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsm1.NewTSMReader(f); err != tsm1.ErrNoKeyring {
+		t.Fatalf("unexpected error opening without keyring: %v", err)
+	}
+	f.Close()
+
+	f, err = os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithTSMReaderKeyring(keyring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(values) != 2 || values[1].Value() != 2.5 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	entries, err := r.ReadEntries([]byte("cpu,host=A#!~#value"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var floatBuf []tsm1.FloatValue
+	floats, err := r.ReadFloatBlockAt(&entries[0], &floatBuf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(floats) != 2 || floats[0].RawValue() != 1.5 {
+		t.Fatalf("unexpected float values: %v", floats)
+	}
+	var array tsdb.FloatArray
+	if err := r.ReadFloatArrayBlockAt(&entries[0], &array); err != nil {
+		t.Fatal(err)
+	} else if array.Len() != 2 || array.Values[1] != 2.5 {
+		t.Fatalf("unexpected float array: %v", array.Values)
+	}
+
+	entries, err = r.ReadEntries([]byte("mem,host=A#!~#value"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stringBuf []tsm1.StringValue
+	strings, err := r.ReadStringBlockAt(&entries[0], &stringBuf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(strings) != 1 || strings[0].RawValue() != "secret-value" {
+		t.Fatalf("unexpected string values: %v", strings)
+	}
+
+	// The block iterator returns decrypted blocks.
+	iter := r.BlockIterator()
+	var n int
+	for iter.Next() {
+		_, _, _, _, _, block, err := iter.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, err := tsm1.DecodeBlock(block, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += len(values)
+	}
+	if n != 3 {
+		t.Fatalf("unexpected number of values iterated: %d", n)
+	}
+}
+
+func TestCompactor_CompactFull_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// A file encrypted with the first key, and a file not encrypted.
+	keyring := mustKeyring(t, 1)
+	f1 := mustWriteEncryptedTSM(t, dir, 1, keyring, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1)},
+		"mem,host=A#!~#value": {tsm1.NewValue(1, 2.1)},
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(2, 1.2)},
+	})
+
+	// Rotating the key makes the compaction write the file with the new key.
+	if err := keyring.Rotate(encryption.Key{ID: 2, Secret: bytes.Repeat([]byte{2}, 32)}); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &encryptedFileStore{fakeFileStore: fs, keyring: keyring}
+	compactor.WithKeyring(keyring)
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	} else if len(files) != 1 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	// The compacted file is read with the new key only.
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithTSMReaderKeyring(mustKeyring(t, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(values) != 2 || values[0].Value() != 1.1 || values[1].Value() != 1.2 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+	if values, err := r.ReadAll([]byte("mem,host=A#!~#value")); err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 || values[0].Value() != 2.1 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+// encryptedFileStore opens the TSM readers of the compactor with a keyring.
+type encryptedFileStore struct {
+	*fakeFileStore
+	keyring *encryption.Keyring
+}
+
+func (s *encryptedFileStore) TSMReader(path string) *tsm1.TSMReader {
+	f, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithTSMReaderKeyring(s.keyring))
+	if err != nil {
+		panic(err)
+	}
+	s.readers = append(s.readers, r)
+	r.Ref()
+	return r
+}
+
+func TestFileStore_Open_EncryptedWithoutKeyring(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keyring := mustKeyring(t, 1)
+	name := mustWriteEncryptedTSM(t, dir, 1, keyring, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1)},
+	})
+
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err == nil {
+		fs.Close()
+		t.Fatal("expected an error opening an encrypted file without a keyring")
+	}
+	// The file is not renamed as corrupt.
+	if _, err := os.Stat(name); err != nil {
+		t.Fatalf("expected the file to be kept: %v", err)
+	}
+
+	fs = tsm1.NewFileStore(dir)
+	fs.WithKeyring(keyring)
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	if values, err := fs.Read([]byte("cpu,host=A#!~#value"), 1); err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 || values[0].Value() != 1.1 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
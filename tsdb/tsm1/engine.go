@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -161,8 +162,13 @@ type Engine struct {
 	// Limiter for concurrent compactions.
 	compactionLimiter limiter.Fixed
 
+	// The compaction settings that can change while the engine runs, applied by the compaction goroutine.
+	compactionSettings CompactionSettings
+
 	scheduler   *scheduler
 	snapshotter Snapshotter
+
+	activity *shardActivity // Counts the reads of queries and the writes of the shards.
 }
 
 // NewEngine returns a new instance of Engine.
@@ -179,6 +185,15 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	c.RateLimit = limiter.NewRate(
 		int(config.Compaction.Throughput),
 		int(config.Compaction.ThroughputBurst))
+	c.WithBlockCompression(BlockCompression(config.BlockCompression))
+
+	// Compactions read with less priority than queries.
+	priority := newReadPriority(int(config.Compaction.ReadThroughput))
+	fs.readPriority = priority
+	c.readPriority = priority
+
+	activity := newShardActivity()
+	fs.activity = activity
 
 	// determine max concurrent compactions informed by the system
 	maxCompactions := config.Compaction.MaxConcurrent
@@ -222,6 +237,12 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		compactionLimiter:              limiter.NewFixed(maxCompactions),
 		scheduler:                      newScheduler(maxCompactions),
 		snapshotter:                    new(noSnapshotter),
+		activity:                       activity,
+	}
+	e.compactionSettings = CompactionSettings{
+		MaxConcurrent:         maxCompactions,
+		Throughput:            int(config.Compaction.Throughput),
+		FullWriteColdDuration: time.Duration(config.Compaction.FullWriteColdDuration),
 	}
 
 	for _, option := range options {
@@ -245,6 +266,18 @@ func (e *Engine) WithFileStoreObserver(obs FileStoreObserver) {
 	e.FileStore.WithObserver(obs)
 }
 
+// WithObjectStore sets the object store that MoveColdFiles moves TSM files to.
+func (e *Engine) WithObjectStore(store ObjectStore) {
+	e.FileStore.WithObjectStore(store)
+}
+
+// WithKeyring sets the keyring encrypting the blocks of the TSM files written, and decrypting
+// the blocks of encrypted TSM files. It must be called before the Engine is opened.
+func (e *Engine) WithKeyring(keyring *encryption.Keyring) {
+	e.FileStore.WithKeyring(keyring)
+	e.Compactor.WithKeyring(keyring)
+}
+
 func (e *Engine) WithCompactionPlanner(planner CompactionPlanner) {
 	planner.SetFileStore(e.FileStore)
 	e.CompactionPlan = planner
@@ -496,6 +529,9 @@ func (e *Engine) initTrackers() {
 	e.compactionTracker = newCompactionTracker(bms.compactionMetrics, e.defaultMetricLabels)
 	e.FileStore.tracker = newFileTracker(bms.fileMetrics, e.defaultMetricLabels)
 	e.Cache.tracker = newCacheTracker(bms.cacheMetrics, e.defaultMetricLabels)
+	if e.Compactor.readPriority != nil {
+		e.Compactor.readPriority.tracker = newReadPriorityTracker(bms.readPriorityMetrics, e.defaultMetricLabels)
+	}
 
 	e.scheduler.setCompactionTracker(e.compactionTracker)
 }
@@ -523,6 +559,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 
 	e.initTrackers()
 
+	if err := e.Compactor.blockCompression.Valid(); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(e.path, 0777); err != nil {
 		return err
 	}
@@ -593,6 +633,12 @@ func (e *Engine) Free() error {
 	return e.FileStore.Free()
 }
 
+// MoveColdFiles moves to the object store the fully compacted TSM files holding
+// no data at or after before, and returns the number of files moved.
+func (e *Engine) MoveColdFiles(ctx context.Context, before int64) (int, error) {
+	return e.FileStore.MoveColdFiles(ctx, before)
+}
+
 // WritePoints saves the set of points in the engine.
 func (e *Engine) WritePoints(points []models.Point) error {
 	collection := tsdb.NewSeriesCollection(points)
@@ -617,6 +663,7 @@ func (e *Engine) WriteValues(values map[string][]Value) error {
 	if err := e.Cache.WriteMulti(values); err != nil {
 		return err
 	}
+	e.activity.write(values)
 
 	return nil
 }
@@ -944,6 +991,7 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 	for {
 		e.mu.RLock()
 		quit := e.done
+		maxCompactions := e.compactionSettings.MaxConcurrent
 		e.mu.RUnlock()
 
 		select {
@@ -951,6 +999,12 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 			return
 
 		case <-t.C:
+			// Apply a change of the number of concurrent compactions. The running compactions
+			// release the limiter they took, and are counted by the scheduler.
+			if maxCompactions != e.scheduler.maxConcurrency {
+				e.scheduler.maxConcurrency = maxCompactions
+				e.compactionLimiter = limiter.NewFixed(maxCompactions)
+			}
 
 			// Find our compaction plans
 			level1Groups := e.CompactionPlan.PlanLevel(1)
@@ -1016,14 +1070,14 @@ func (e *Engine) compactHiPriorityLevel(grp CompactionGroup, level compactionLev
 	}
 
 	// Try hi priority limiter, otherwise steal a little from the low priority if we can.
-	if e.compactionLimiter.TryTake() {
+	if lim := e.compactionLimiter; lim.TryTake() {
 		e.compactionTracker.IncActive(level)
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecActive(level)
-			defer e.compactionLimiter.Release()
+			defer lim.Release()
 			s.Apply()
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
@@ -1044,13 +1098,13 @@ func (e *Engine) compactLoPriorityLevel(grp CompactionGroup, level compactionLev
 	}
 
 	// Try the lo priority limiter, otherwise steal a little from the high priority if we can.
-	if e.compactionLimiter.TryTake() {
+	if lim := e.compactionLimiter; lim.TryTake() {
 		e.compactionTracker.IncActive(level)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecActive(level)
-			defer e.compactionLimiter.Release()
+			defer lim.Release()
 			s.Apply()
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
@@ -1069,13 +1123,13 @@ func (e *Engine) compactFull(grp CompactionGroup, wg *sync.WaitGroup) bool {
 	}
 
 	// Try the lo priority limiter, otherwise steal a little from the high priority if we can.
-	if e.compactionLimiter.TryTake() {
+	if lim := e.compactionLimiter; lim.TryTake() {
 		e.compactionTracker.IncFullActive()
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecFullActive()
-			defer e.compactionLimiter.Release()
+			defer lim.Release()
 			s.Apply()
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
@@ -0,0 +1,159 @@
+package tsm1
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+
+	intar "github.com/influxdata/influxdb/pkg/tar"
+)
+
+// ErrFileNotFound is returned when a TSM file is not in the file store.
+var ErrFileNotFound = errors.New("tsm file not found")
+
+// BucketFileStat holds information about the values of a bucket in a TSM file.
+type BucketFileStat struct {
+	FileStat
+
+	// BucketSize is the size of the blocks of the bucket, and KeyCount the number of its keys.
+	BucketSize int64
+	KeyCount   int
+
+	// BucketMinTime and BucketMaxTime are the times of the first and last values of the bucket.
+	BucketMinTime, BucketMaxTime int64
+}
+
+// FileID returns the name of the TSM file at path, without its extension.
+func FileID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), "."+TSMFileExtension)
+}
+
+// BucketFileStats returns information about the values of the bucket name in each TSM file
+// holding some of them, in the order of the files.
+func (e *Engine) BucketFileStats(name []byte) ([]BucketFileStat, error) {
+	var stats []BucketFileStat
+	err := e.FileStore.walkFiles(func(r TSMFile) error {
+		s := BucketFileStat{
+			BucketMinTime: math.MaxInt64,
+			BucketMaxTime: math.MinInt64,
+		}
+		iter := r.Iterator(name)
+		for iter.Next() {
+			if !bytes.HasPrefix(iter.Key(), name) {
+				break
+			}
+			s.KeyCount++
+			for _, entry := range iter.Entries() {
+				s.BucketSize += int64(entry.Size)
+				if entry.MinTime < s.BucketMinTime {
+					s.BucketMinTime = entry.MinTime
+				}
+				if entry.MaxTime > s.BucketMaxTime {
+					s.BucketMaxTime = entry.MaxTime
+				}
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if s.KeyCount == 0 {
+			return nil
+		}
+
+		minTime, maxTime := r.TimeRange()
+		minKey, maxKey := r.KeyRange()
+		s.FileStat = FileStat{
+			Path:         r.Path(),
+			HasTombstone: r.HasTombstones(),
+			Size:         r.Size(),
+			MinTime:      minTime,
+			MaxTime:      maxTime,
+			MinKey:       minKey,
+			MaxKey:       maxKey,
+		}
+		stats = append(stats, s)
+		return nil
+	})
+	return stats, err
+}
+
+// DeleteBucketFile removes the values of the bucket name from the TSM file with the given ID, see FileID,
+// as tombstones. The values of other buckets in the file are kept. The series of the bucket left without
+// any value are removed from the index and the series file.
+func (e *Engine) DeleteBucketFile(name []byte, id string) error {
+	// Ensure that the index does not compact away the series we're going to delete
+	// before we're done with them.
+	e.index.DisableCompactions()
+	defer e.index.EnableCompactions()
+	e.index.Wait()
+
+	// Disable and abort running level compactions so that tombstones added to the tsm
+	// file don't get removed, as in DeleteBucketRange.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	e.sfile.DisableCompactions()
+	defer e.sfile.EnableCompactions()
+
+	var (
+		found bool
+		dead  [][]byte
+	)
+	if err := e.FileStore.walkFiles(func(r TSMFile) error {
+		if FileID(r.Path()) != id {
+			return nil
+		}
+		found = true
+		return r.DeletePrefix(name, math.MinInt64, math.MaxInt64, func(key []byte) {
+			dead = append(dead, append([]byte(nil), key...))
+		})
+	}); err != nil {
+		return err
+	}
+	if !found {
+		return ErrFileNotFound
+	}
+
+	// Now that the values are deleted, remove the series left without any value from the index.
+	return e.dropSeriesWithoutValues(dead)
+}
+
+// BackupBucketFile writes the TSM file with the given ID, see FileID, and its tombstone files
+// to the tar archive tw, in the directory dir. The file must hold values of the bucket name.
+func (e *Engine) BackupBucketFile(name []byte, id string, tw *tar.Writer, dir string) error {
+	var files []intar.File
+	if err := e.FileStore.walkFiles(func(r TSMFile) error {
+		if FileID(r.Path()) != id || files != nil {
+			return nil
+		}
+		iter := r.Iterator(name)
+		if !iter.Next() || !bytes.HasPrefix(iter.Key(), name) {
+			return iter.Err()
+		}
+
+		// TSM files are not written once created, and tombstone files are replaced
+		// as a whole, so the files opened are not changed while they are written.
+		paths := []string{r.Path()}
+		for _, t := range r.TombstoneFiles() {
+			paths = append(paths, t.Path)
+		}
+		for _, path := range paths {
+			f, err := intar.OpenFile(filepath.Base(path), path, -1, -1)
+			if err != nil {
+				intar.CloseFiles(files)
+				return err
+			}
+			files = append(files, f)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if files == nil {
+		return ErrFileNotFound
+	}
+	return intar.WriteFiles(tw, dir, files)
+}
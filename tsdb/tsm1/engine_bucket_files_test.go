@@ -0,0 +1,84 @@
+package tsm1_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestEngine_BucketFiles(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// The first file holds points of cpu and mem, the second file only points of cpu.
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1"),
+		MustParsePointString("cpu,host=B value=1.2 2"),
+		MustParsePointString("mem,host=A value=1.3 3"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background()); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+	if err := e.writePoints(MustParsePointString("cpu,host=A value=1.4 5")); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background()); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+
+	stats, err := e.BucketFileStats([]byte("cpu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d files, want 2", len(stats))
+	}
+	if got := stats[0]; got.KeyCount != 2 || got.BucketMinTime != 1 || got.BucketMaxTime != 2 || got.MaxTime != 3 || got.BucketSize <= 0 {
+		t.Fatalf("unexpected stats of the first file: %+v", got)
+	}
+	if got := stats[1]; got.KeyCount != 1 || got.BucketMinTime != 5 || got.BucketMaxTime != 5 {
+		t.Fatalf("unexpected stats of the second file: %+v", got)
+	}
+
+	if stats, err := e.BucketFileStats([]byte("disk")); err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 0 {
+		t.Fatalf("got %d files of a bucket without points, want 0", len(stats))
+	}
+
+	// Deleting cpu from the first file keeps mem in it, and the values of cpu in the second file.
+	if err := e.DeleteBucketFile([]byte("cpu"), tsm1.FileID(stats[0].Path)); err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]byte{
+		"cpu,host=A#!~#value": 0,
+		"mem,host=A#!~#value": 0,
+	}
+	if got := e.FileStore.Keys(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected series in file store: %v != %v", got, exp)
+	}
+
+	// The series left without values is removed from the index.
+	buf := make([]byte, 1024)
+	if !e.sfile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"host": "B"}), buf).IsZero() {
+		t.Fatal("expected cpu,host=B removed from the series file")
+	}
+	if e.sfile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"host": "A"}), buf).IsZero() {
+		t.Fatal("expected cpu,host=A in the series file")
+	}
+
+	if err := e.DeleteBucketFile([]byte("cpu"), "000000099-000000001"); err != tsm1.ErrFileNotFound {
+		t.Fatalf("got error %v deleting from a missing file, want %v", err, tsm1.ErrFileNotFound)
+	}
+}
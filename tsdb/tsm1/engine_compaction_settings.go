@@ -0,0 +1,65 @@
+package tsm1
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/limiter"
+)
+
+// CompactionSettings are the settings of compactions that can change while the engine runs.
+type CompactionSettings struct {
+	// MaxConcurrent is the number of level and full compactions that can run at the same time.
+	MaxConcurrent int
+
+	// Throughput is the rate limit in bytes per second of the writes of compactions, or 0 to not limit them.
+	Throughput int
+
+	// FullWriteColdDuration is how long the engine goes without writes before all of its TSM files are compacted.
+	FullWriteColdDuration time.Duration
+}
+
+// fullWriteColdDurationSetter is implemented by the compaction planners whose full write
+// cold duration can change, such as the DefaultPlanner.
+type fullWriteColdDurationSetter interface {
+	SetFullWriteColdDuration(d time.Duration)
+}
+
+// CompactionSettings returns the compaction settings of the engine.
+func (e *Engine) CompactionSettings() CompactionSettings {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.compactionSettings
+}
+
+// SetCompactionSettings changes the compaction settings of the engine, and returns the settings in effect.
+//
+// The number of concurrent compactions is at most the number of cores, and applies to the compactions
+// started from now on. The throughput applies to the compactions already running.
+func (e *Engine) SetCompactionSettings(s CompactionSettings) CompactionSettings {
+	if s.MaxConcurrent < 1 {
+		s.MaxConcurrent = 1
+	} else if s.MaxConcurrent > runtime.GOMAXPROCS(0) {
+		s.MaxConcurrent = runtime.GOMAXPROCS(0)
+	}
+	if s.Throughput < 0 {
+		s.Throughput = 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Compactor.RateLimit != nil && !limiter.SetRate(e.Compactor.RateLimit, s.Throughput) {
+		// The rate limit is not the one of the engine, so it keeps its throughput.
+		s.Throughput = e.compactionSettings.Throughput
+	}
+	if p, ok := e.CompactionPlan.(fullWriteColdDurationSetter); ok {
+		p.SetFullWriteColdDuration(s.FullWriteColdDuration)
+	} else {
+		s.FullWriteColdDuration = e.compactionSettings.FullWriteColdDuration
+	}
+
+	// The compaction goroutine applies the number of concurrent compactions at its next check.
+	e.compactionSettings = s
+	return s
+}
@@ -0,0 +1,55 @@
+package tsm1_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestEngine_SetCompactionSettings(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.CompactionPlan = tsm1.NewDefaultPlanner(e.FileStore, tsm1.DefaultCompactFullWriteColdDuration)
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	got := e.SetCompactionSettings(tsm1.CompactionSettings{
+		MaxConcurrent:         runtime.GOMAXPROCS(0) + 1,
+		Throughput:            1024 * 1024,
+		FullWriteColdDuration: time.Hour,
+	})
+	exp := tsm1.CompactionSettings{
+		MaxConcurrent:         runtime.GOMAXPROCS(0),
+		Throughput:            1024 * 1024,
+		FullWriteColdDuration: time.Hour,
+	}
+	if got != exp {
+		t.Fatalf("unexpected settings in effect: exp %+v, got %+v", exp, got)
+	}
+	if got := e.CompactionSettings(); got != exp {
+		t.Fatalf("unexpected settings: exp %+v, got %+v", exp, got)
+	}
+
+}
+
+func TestEngine_SetCompactionSettings_OtherPlanner(t *testing.T) {
+	e := MustOpenEngine()
+	defer e.Close()
+
+	// The full write cold duration of a planner that cannot change it stays the same.
+	got := e.SetCompactionSettings(tsm1.CompactionSettings{
+		MaxConcurrent:         1,
+		FullWriteColdDuration: time.Minute,
+	})
+	exp := tsm1.CompactionSettings{MaxConcurrent: 1, FullWriteColdDuration: tsm1.DefaultCompactFullWriteColdDuration}
+	if got != exp {
+		t.Fatalf("unexpected settings in effect: exp %+v, got %+v", exp, got)
+	}
+}
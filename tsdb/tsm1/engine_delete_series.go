@@ -0,0 +1,67 @@
+package tsm1
+
+import (
+	"github.com/influxdata/influxdb/models"
+)
+
+// DeleteSeriesRange removes the values of keys between min and max, inclusive, from the TSM
+// files, as tombstones, and from the cache. The series left without any value are removed from
+// the index and the series file. The keys are series keys joined with their field, and must be sorted.
+// It returns the number of tombstones added to the TSM files.
+func (e *Engine) DeleteSeriesRange(keys [][]byte, min, max int64) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// Ensure that the index does not compact away the series we're going to delete
+	// before we're done with them.
+	e.index.DisableCompactions()
+	defer e.index.EnableCompactions()
+	e.index.Wait()
+
+	// Disable and abort running level compactions so that tombstones added to existing tsm
+	// files don't get removed, as in DeleteBucketRange.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	e.sfile.DisableCompactions()
+	defer e.sfile.EnableCompactions()
+
+	tombstones := e.FileStore.CountRange(keys, min, max)
+	if err := e.FileStore.DeleteRange(keys, min, max); err != nil {
+		return 0, err
+	}
+	e.Cache.DeleteRange(keys, min, max)
+
+	// Now that the values are deleted, remove the series left without any value from the index.
+	if err := e.dropSeriesWithoutValues(keys); err != nil {
+		return 0, err
+	}
+	return tombstones, nil
+}
+
+// dropSeriesWithoutValues removes the series of keys that have no value left in the cache or
+// the TSM files from the index and the series file. The keys are series keys joined with their field.
+func (e *Engine) dropSeriesWithoutValues(keys [][]byte) error {
+	buf := make([]byte, 1024)
+	for _, key := range keys {
+		if len(e.Cache.Values(key)) > 0 || e.FileStore.Contains(key) {
+			continue
+		}
+
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		sid := e.sfile.SeriesID(name, tags, buf)
+		if sid.IsZero() {
+			continue
+		}
+
+		if err := e.index.DropSeries(sid, seriesKey, true); err != nil {
+			return err
+		}
+		if err := e.sfile.DeleteSeriesID(sid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package tsm1_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_DeleteSeriesRange(t *testing.T) {
+	// Create a few points.
+	p1 := MustParsePointString("cpu,host=0 value=1.1 6")
+	p2 := MustParsePointString("cpu,host=A value=1.2 2")
+	p3 := MustParsePointString("cpu,host=A value=1.3 3")
+	p4 := MustParsePointString("cpu,host=B value=1.3 4")
+	p5 := MustParsePointString("cpu,host=B value=1.3 5")
+	p6 := MustParsePointString("cpu,host=A value=1.4 7")
+
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.writePoints(p1, p2, p3, p4, p5); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background()); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+	// A point of cpu,host=A is only in the cache.
+	if err := e.writePoints(p6); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	keys := [][]byte{
+		[]byte("cpu,host=A#!~#value"),
+		[]byte("cpu,host=B#!~#value"),
+	}
+	if n, err := e.DeleteSeriesRange(keys, 0, 4); err != nil {
+		t.Fatalf("failed to delete series: %v", err)
+	} else if n != 2 {
+		t.Fatalf("unexpected tombstones: exp 2, got %d", n)
+	}
+
+	exp := map[string]byte{
+		"cpu,host=0#!~#value": 0,
+		"cpu,host=B#!~#value": 0,
+	}
+	if got := e.FileStore.Keys(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected series in file store: %v != %v", got, exp)
+	}
+	if got := e.Cache.Values(keys[0]); len(got) != 1 || got[0].UnixNano() != 7 {
+		t.Fatalf("unexpected values in cache: %v", got)
+	}
+
+	// The series with values left in the cache is still in the index.
+	buf := make([]byte, 1024)
+	tags := models.NewTags(map[string]string{"host": "A"})
+	if e.sfile.SeriesID([]byte("cpu"), tags, buf).IsZero() {
+		t.Fatal("expected cpu,host=A in the series file")
+	}
+
+	// Deleting the remaining values of the series removes it from the index.
+	if n, err := e.DeleteSeriesRange(keys[:1], 0, 9); err != nil {
+		t.Fatalf("failed to delete series: %v", err)
+	} else if n != 0 {
+		t.Fatalf("unexpected tombstones: exp 0, got %d", n)
+	}
+	if got := e.Cache.Values(keys[0]); len(got) != 0 {
+		t.Fatalf("unexpected values in cache: %v", got)
+	}
+	if !e.sfile.SeriesID([]byte("cpu"), tags, buf).IsZero() {
+		t.Fatal("expected cpu,host=A removed from the series file")
+	}
+	if got, exp := e.SeriesN(), int64(2); got != exp {
+		t.Fatalf("series count mismatch: exp %v, got %v", exp, got)
+	}
+}
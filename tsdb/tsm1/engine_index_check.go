@@ -0,0 +1,92 @@
+package tsm1
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// ErrIndexCheckInterrupted is returned by UnindexedSeries when it is cancelled.
+var ErrIndexCheckInterrupted = errors.New("tsm1: index check interrupted")
+
+// UnindexedSeries returns the series holding data in the TSM files or in the cache
+// that are missing from the series file or from the index. The collection has the Keys,
+// Names, Tags and Types of the series, to be passed to CreateSeriesListIfNotExists.
+//
+// Closing cancel stops the walk, which then returns ErrIndexCheckInterrupted.
+func (e *Engine) UnindexedSeries(cancel <-chan struct{}) (*tsdb.SeriesCollection, error) {
+	collection := &tsdb.SeriesCollection{}
+	seen := make(map[string]struct{})
+	var buf []byte
+
+	check := func(key []byte, typ models.FieldType) error {
+		select {
+		case <-cancel:
+			return ErrIndexCheckInterrupted
+		default:
+		}
+
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		if _, ok := seen[string(seriesKey)]; ok {
+			return nil
+		}
+
+		name, tags := models.ParseKeyBytes(seriesKey)
+		id := e.sfile.SeriesID(name, tags, buf)
+		if !id.IsZero() && e.index.HasSeries(seriesKey, id) {
+			return nil
+		}
+
+		seen[string(seriesKey)] = struct{}{}
+		collection.Keys = append(collection.Keys, append([]byte(nil), seriesKey...))
+		collection.Names = append(collection.Names, name)
+		collection.Tags = append(collection.Tags, tags)
+		collection.Types = append(collection.Types, typ)
+		return nil
+	}
+
+	// Keys found in several TSM files are walked once per file, one after the other.
+	var prev []byte
+	if err := e.FileStore.WalkKeys(nil, func(key []byte, typ byte) error {
+		if bytes.Equal(key, prev) {
+			return nil
+		}
+		prev = append(prev[:0], key...)
+		return check(key, blockTypeToFieldType(typ))
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, key := range e.Cache.Keys() {
+		typ, err := e.Cache.Type(key)
+		if err != nil {
+			// The values of the key were snapshotted and written since the keys were listed.
+			continue
+		}
+		if err := check(key, typ); err != nil {
+			return nil, err
+		}
+	}
+
+	return collection, nil
+}
+
+// blockTypeToFieldType returns the field type of the values of a block of type typ.
+func blockTypeToFieldType(typ byte) models.FieldType {
+	switch typ {
+	case BlockFloat64:
+		return models.Float
+	case BlockInteger:
+		return models.Integer
+	case BlockUnsigned:
+		return models.Unsigned
+	case BlockBoolean:
+		return models.Boolean
+	case BlockString:
+		return models.String
+	default:
+		return models.Empty
+	}
+}
@@ -0,0 +1,82 @@
+package tsm1_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestEngine_UnindexedSeries(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// Series written to the index and the engine.
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1"),
+		MustParsePointString("cpu,host=B value=1.2 1"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	// Series written to the engine only, snapshotted to a TSM file or left in the cache.
+	if err := e.WritePoints([]models.Point{
+		MustParsePointString("cpu,host=C value=1i 2"),
+		MustParsePointString("mem,host=A free=1i 2"),
+		MustParsePointString("mem,host=A used=1i 2"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+	if err := e.WritePoints([]models.Point{
+		MustParsePointString("cpu,host=A value=1.3 3"),
+		MustParsePointString("disk,host=A ok=true 3"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := e.UnindexedSeries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]models.FieldType)
+	for i, key := range collection.Keys {
+		got[string(key)] = collection.Types[i]
+	}
+	exp := map[string]models.FieldType{
+		"cpu,host=C":  models.Integer,
+		"mem,host=A":  models.Integer,
+		"disk,host=A": models.Boolean,
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected unindexed series: got %v, exp %v", got, exp)
+	}
+
+	// Indexing the series fixes the index.
+	if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection, err := e.UnindexedSeries(nil); err != nil {
+		t.Fatal(err)
+	} else if collection.Length() != 0 {
+		keys := make([]string, 0, len(collection.Keys))
+		for _, key := range collection.Keys {
+			keys = append(keys, string(key))
+		}
+		sort.Strings(keys)
+		t.Fatalf("expected no unindexed series, got %v", keys)
+	}
+
+	cancel := make(chan struct{})
+	close(cancel)
+	if _, err := e.UnindexedSeries(cancel); err == nil {
+		t.Fatal("expected a canceled check to fail")
+	}
+}
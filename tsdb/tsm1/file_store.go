@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -205,6 +206,14 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs FileStoreObserver
+
+	objectStore ObjectStore // Where TSM files are moved to a storage tier.
+
+	keyring *encryption.Keyring // Decrypts the blocks of encrypted TSM files.
+
+	readPriority *readPriority // Records the reads of queries, which compactions give precedence to.
+
+	activity *shardActivity // Counts the reads of queries of each TSM file.
 }
 
 // FileStat holds information about a TSM file on disk.
@@ -260,6 +269,12 @@ func (f *FileStore) WithObserver(obs FileStoreObserver) {
 	f.obs = obs
 }
 
+// WithKeyring sets the keyring decrypting the blocks of encrypted TSM files.
+// It must be called before the FileStore is opened.
+func (f *FileStore) WithKeyring(keyring *encryption.Keyring) {
+	f.keyring = keyring
+}
+
 func (f *FileStore) WithParseFileNameFunc(parseFileNameFunc ParseFileNameFunc) {
 	f.parseFileName = parseFileNameFunc
 }
@@ -416,6 +431,53 @@ func (f *FileStore) Keys() map[string]byte {
 	return uniqueKeys
 }
 
+// Contains returns true if any of the files has values for key.
+func (f *FileStore) Contains(key []byte) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, f := range f.files {
+		if f.Contains(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFiles calls fn with each TSM file in order, until fn returns an error.
+// The files are not replaced until it returns.
+func (f *FileStore) walkFiles(fn func(r TSMFile) error) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, r := range f.files {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountRange returns the number of keys contained in each file with values between min and max,
+// which is the number of tombstones that DeleteRange would add for keys.
+func (f *FileStore) CountRange(keys [][]byte, min, max int64) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var n int
+	for _, f := range f.files {
+		if !f.OverlapsTimeRange(min, max) {
+			continue
+		}
+		for _, key := range keys {
+			if f.Contains(key) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
 // Type returns the type of values store at the block for key.
 func (f *FileStore) Type(key []byte) (byte, error) {
 	f.mu.RLock()
@@ -539,6 +601,10 @@ func (f *FileStore) Open(ctx context.Context) error {
 		}
 	}
 
+	if err := f.removeOrphanTierFiles(ctx); err != nil {
+		return err
+	}
+
 	files, err := filepath.Glob(filepath.Join(f.dir, fmt.Sprintf("*.%s", TSMFileExtension)))
 	if err != nil {
 		return err
@@ -562,11 +628,26 @@ func (f *FileStore) Open(ctx context.Context) error {
 			f.currentGeneration = generation + 1
 		}
 
+		// A file moved to a storage tier is not corrupt without an object store.
+		if f.objectStore == nil {
+			if _, err := os.Stat(TierFilename(fn)); err == nil {
+				return fmt.Errorf("error opening file %s: %v", fn, ErrNoObjectStore)
+			}
+		}
+
 		file, err := os.OpenFile(fn, os.O_RDONLY, 0666)
 		if err != nil {
 			return fmt.Errorf("error opening file %s: %v", fn, err)
 		}
 
+		// An encrypted file is not corrupt without a keyring.
+		if f.keyring == nil {
+			if version, err := verifyVersion(file); err == nil && version == EncryptedVersion {
+				file.Close()
+				return fmt.Errorf("error opening file %s: %v", fn, ErrNoKeyring)
+			}
+		}
+
 		go func(idx int, file *os.File) {
 			// Ensure a limited number of TSM files are loaded at once.
 			// Systems which have very large datasets (1TB+) can have thousands
@@ -577,7 +658,9 @@ func (f *FileStore) Open(ctx context.Context) error {
 			start := time.Now()
 			df, err := NewTSMReader(file,
 				WithMadviseWillNeed(f.tsmMMAPWillNeed),
-				WithTSMReaderLogger(f.logger))
+				WithTSMReaderLogger(f.logger),
+				WithTSMReaderObjectStore(f.objectStore),
+				WithTSMReaderKeyring(f.keyring))
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
 				zap.Int("id", idx),
@@ -805,7 +888,9 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 
 		tsm, err := NewTSMReader(fd,
 			WithMadviseWillNeed(f.tsmMMAPWillNeed),
-			WithTSMReaderLogger(f.logger))
+			WithTSMReaderLogger(f.logger),
+			WithTSMReaderObjectStore(f.objectStore),
+			WithTSMReaderKeyring(f.keyring))
 		if err != nil {
 			return err
 		}
@@ -1228,6 +1313,9 @@ type KeyCursor struct {
 	// decrement through the size of seeks slice.
 	pos       int
 	ascending bool
+
+	// priority records the reads of the cursor, so that compactions give them precedence.
+	priority *readPriority
 }
 
 type location struct {
@@ -1284,7 +1372,9 @@ func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascen
 		ctx:       ctx,
 		col:       metrics.GroupFromContext(ctx),
 		ascending: ascending,
+		priority:  fs.readPriority,
 	}
+	c.priority.queryRead()
 
 	if ascending {
 		sort.Sort(ascLocations(c.seeks))
@@ -1293,9 +1383,14 @@ func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascen
 	}
 
 	// Determine the distinct set of TSM files in use and mark then as in-use
+	var ids []string
 	for _, f := range c.seeks {
 		f.r.Ref()
+		if id := FileID(f.r.Path()); !containsString(ids, id) {
+			ids = append(ids, id)
+		}
 	}
+	fs.activity.queryHit(key, ids...)
 
 	c.seek(t)
 	return c
@@ -1364,6 +1459,7 @@ func (c *KeyCursor) Next() {
 		return
 	}
 	c.current = c.current[:0]
+	c.priority.queryRead()
 	if c.ascending {
 		c.nextAscending()
 	} else {
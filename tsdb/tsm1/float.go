@@ -7,6 +7,9 @@ the timestamp compression fuctionality.
 It implements the float compression as presented in: http://www.vldb.org/pvldb/vol8/p1816-teller.pdf.
 This implementation uses a sentinel value of NaN which means that float64 NaN cannot be stored using
 this version.
+
+Blocks rewritten by a compactor configured with BlockCompressionZstd instead compress the values,
+each XORed with the previous one, with Zstandard.
 */
 
 import (
@@ -19,8 +22,13 @@ import (
 )
 
 // Note: an uncompressed format is not yet implemented.
-// floatCompressedGorilla is a compressed format using the gorilla paper encoding
-const floatCompressedGorilla = 1
+const (
+	// floatCompressedGorilla is a compressed format using the gorilla paper encoding
+	floatCompressedGorilla = 1
+
+	// floatCompressedZstd is a compressed format using Zstandard compression
+	floatCompressedZstd = 2
+)
 
 // uvnan is the constant returned from math.NaN().
 const uvnan = 0x7FF8000000000001
@@ -151,14 +159,29 @@ type FloatDecoder struct {
 	first    bool
 	finished bool
 
+	// values are the decoded values of a block compressed with Zstandard,
+	// of which the next one to read is at i.
+	zstd   bool
+	values []float64
+	i      int
+
 	err error
 }
 
 // SetBytes initializes the decoder with b. Must call before calling Next().
 func (it *FloatDecoder) SetBytes(b []byte) error {
 	var v uint64
+	it.zstd = false
 	if len(b) == 0 {
 		v = uvnan
+	} else if b[0]>>4 == floatCompressedZstd {
+		values, err := floatArrayDecodeAllZstd(b, it.values)
+		if err != nil {
+			return err
+		}
+		it.zstd = true
+		it.values = values
+		it.i = 0
 	} else {
 		// first byte is the compression type.
 		// we currently just have gorilla compression.
@@ -189,6 +212,16 @@ func (it *FloatDecoder) Next() bool {
 		return false
 	}
 
+	if it.zstd {
+		if it.i >= len(it.values) {
+			it.finished = true
+			return false
+		}
+		it.val = math.Float64bits(it.values[it.i])
+		it.i++
+		return true
+	}
+
 	if it.first {
 		it.first = false
 
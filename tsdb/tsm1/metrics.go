@@ -25,6 +25,7 @@ func PrometheusCollectors() []prometheus.Collector {
 		collectors = append(collectors, bms.compactionMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.fileMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.cacheMetrics.PrometheusCollectors()...)
+		collectors = append(collectors, bms.readPriorityMetrics.PrometheusCollectors()...)
 	}
 	return collectors
 }
@@ -42,15 +43,17 @@ type blockMetrics struct {
 	*compactionMetrics
 	*fileMetrics
 	*cacheMetrics
+	*readPriorityMetrics
 }
 
 // newBlockMetrics initialises the prometheus metrics for the block subsystem.
 func newBlockMetrics(labels prometheus.Labels) *blockMetrics {
 	return &blockMetrics{
-		labels:            labels,
-		compactionMetrics: newCompactionMetrics(labels),
-		fileMetrics:       newFileMetrics(labels),
-		cacheMetrics:      newCacheMetrics(labels),
+		labels:              labels,
+		compactionMetrics:   newCompactionMetrics(labels),
+		fileMetrics:         newFileMetrics(labels),
+		cacheMetrics:        newCacheMetrics(labels),
+		readPriorityMetrics: newReadPriorityMetrics(labels),
 	}
 }
 
@@ -60,6 +63,7 @@ func (m *blockMetrics) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, m.compactionMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.fileMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.cacheMetrics.PrometheusCollectors()...)
+	metrics = append(metrics, m.readPriorityMetrics.PrometheusCollectors()...)
 	return metrics
 }
 
@@ -124,6 +128,46 @@ func (m *compactionMetrics) PrometheusCollectors() []prometheus.Collector {
 	}
 }
 
+// readPriorityMetrics are a set of metrics concerned with tracking the reads of compactions,
+// and whether they were throttled in favour of the reads of queries.
+type readPriorityMetrics struct {
+	// The following metrics include a `"throttled" = {true, false}` label
+	ReadBytes *prometheus.CounterVec
+	ReadDelay *prometheus.CounterVec
+}
+
+// newReadPriorityMetrics initialises the prometheus metrics for compaction reads.
+func newReadPriorityMetrics(labels prometheus.Labels) *readPriorityMetrics {
+	names := []string{"throttled"}
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return &readPriorityMetrics{
+		ReadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: compactionSubsystem,
+			Name:      "read_bytes",
+			Help:      "Number of bytes of TSM blocks read by compactions, throttled while queries were reading or not.",
+		}, names),
+		ReadDelay: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: compactionSubsystem,
+			Name:      "read_delay_seconds",
+			Help:      "Time compactions waited to read TSM blocks, in favour of the reads of queries.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *readPriorityMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.ReadBytes,
+		m.ReadDelay,
+	}
+}
+
 // fileMetrics are a set of metrics concerned with tracking data about compactions.
 type fileMetrics struct {
 	DiskSize *prometheus.GaugeVec
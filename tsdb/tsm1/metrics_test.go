@@ -2,6 +2,7 @@ package tsm1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/prometheus/client_golang/prometheus"
@@ -54,6 +55,45 @@ func TestMetrics_Filestore(t *testing.T) {
 
 }
 
+func TestMetrics_ReadPriority(t *testing.T) {
+	// metrics to be shared by multiple engines.
+	metrics := newReadPriorityMetrics(prometheus.Labels{"engine_id": "", "node_id": ""})
+
+	t1 := newReadPriorityTracker(metrics, prometheus.Labels{"engine_id": "0", "node_id": "0"})
+	t2 := newReadPriorityTracker(metrics, prometheus.Labels{"engine_id": "1", "node_id": "0"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	// Generate some measurements.
+	t1.Read(100, false, 0)
+	t1.Read(50, true, 2*time.Second)
+	t2.Read(200, false, 0)
+
+	// Test that all the correct metrics are present.
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := namespace + "_" + compactionSubsystem + "_"
+	for _, tt := range []struct {
+		name   string
+		labels prometheus.Labels
+		exp    float64
+	}{
+		{name: "read_bytes", labels: prometheus.Labels{"engine_id": "0", "node_id": "0", "throttled": "false"}, exp: 100},
+		{name: "read_bytes", labels: prometheus.Labels{"engine_id": "0", "node_id": "0", "throttled": "true"}, exp: 50},
+		{name: "read_delay_seconds", labels: prometheus.Labels{"engine_id": "0", "node_id": "0", "throttled": "true"}, exp: 2},
+		{name: "read_bytes", labels: prometheus.Labels{"engine_id": "1", "node_id": "0", "throttled": "false"}, exp: 200},
+	} {
+		m := promtest.MustFindMetric(t, mfs, base+tt.name, tt.labels)
+		if got := m.GetCounter().GetValue(); got != tt.exp {
+			t.Errorf("[%s] got %v, expected %v", m, got, tt.exp)
+		}
+	}
+}
+
 func TestMetrics_Cache(t *testing.T) {
 	// metrics to be shared by multiple file stores.
 	metrics := newCacheMetrics(prometheus.Labels{"engine_id": "", "node_id": ""})
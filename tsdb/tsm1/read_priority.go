@@ -0,0 +1,117 @@
+package tsm1
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// queryReadWindow is how long after the last read of a query compaction reads
+// are still rate limited.
+const queryReadWindow = time.Second
+
+// readPriority gives the reads of queries precedence over the reads of compactions.
+// While queries read TSM blocks, compactions read blocks at a limited rate so that
+// they leave the disk to the queries when it saturates. Otherwise compactions read
+// as fast as they can.
+type readPriority struct {
+	lastQueryRead int64 // UnixNano of the last read of a query, accessed atomically.
+
+	limiter *rate.Limiter // Limits compaction reads while queries read, nil does not.
+	tracker *readPriorityTracker
+}
+
+// newReadPriority returns a readPriority limiting compaction reads to bytesPerSec while
+// queries read. A bytesPerSec of 0 does not limit them.
+func newReadPriority(bytesPerSec int) *readPriority {
+	p := &readPriority{tracker: newReadPriorityTracker(newReadPriorityMetrics(nil), nil)}
+	if bytesPerSec > 0 {
+		p.limiter = rate.NewLimiter(rate.Limit(bytesPerSec), bytesPerSec)
+	}
+	return p
+}
+
+// queryRead records that a query reads TSM blocks.
+func (p *readPriority) queryRead() {
+	if p == nil {
+		return
+	}
+	atomic.StoreInt64(&p.lastQueryRead, time.Now().UnixNano())
+}
+
+// compactionRead waits until a compaction may read n bytes of TSM blocks,
+// which it may right away unless queries are reading.
+func (p *readPriority) compactionRead(n int) {
+	if p == nil || p.limiter == nil {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(0, atomic.LoadInt64(&p.lastQueryRead))) > queryReadWindow {
+		p.tracker.Read(n, false, 0)
+		return
+	}
+
+	// Reserve the bytes in chunks no larger than the burst, the delay
+	// of the last reservation is then the delay of the whole read.
+	var delay time.Duration
+	burst := p.limiter.Burst()
+	for rem := n; rem > 0; rem -= burst {
+		sz := rem
+		if sz > burst {
+			sz = burst
+		}
+		delay = p.limiter.ReserveN(now, sz).DelayFrom(now)
+	}
+	p.tracker.Read(n, true, delay)
+	time.Sleep(delay)
+}
+
+// readPriorityTracker tracks the reads of compactions and whether they were throttled.
+type readPriorityTracker struct {
+	metrics *readPriorityMetrics
+	labels  prometheus.Labels
+
+	throttledBytes   uint64
+	unthrottledBytes uint64
+}
+
+func newReadPriorityTracker(metrics *readPriorityMetrics, defaultLabels prometheus.Labels) *readPriorityTracker {
+	return &readPriorityTracker{metrics: metrics, labels: defaultLabels}
+}
+
+// Labels returns a copy of the default labels used by the tracker's metrics.
+// The returned map is safe for modification.
+func (t *readPriorityTracker) Labels(throttled bool) prometheus.Labels {
+	labels := make(prometheus.Labels, len(t.labels))
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	labels["throttled"] = strconv.FormatBool(throttled)
+	return labels
+}
+
+// ThrottledBytes returns the number of bytes compactions read while queries were reading.
+func (t *readPriorityTracker) ThrottledBytes() uint64 { return atomic.LoadUint64(&t.throttledBytes) }
+
+// UnthrottledBytes returns the number of bytes compactions read while no query was reading.
+func (t *readPriorityTracker) UnthrottledBytes() uint64 {
+	return atomic.LoadUint64(&t.unthrottledBytes)
+}
+
+// Read records that a compaction read n bytes, throttled in favour of queries
+// or not, after waiting for delay.
+func (t *readPriorityTracker) Read(n int, throttled bool, delay time.Duration) {
+	if throttled {
+		atomic.AddUint64(&t.throttledBytes, uint64(n))
+	} else {
+		atomic.AddUint64(&t.unthrottledBytes, uint64(n))
+	}
+
+	labels := t.Labels(throttled)
+	t.metrics.ReadBytes.With(labels).Add(float64(n))
+	t.metrics.ReadDelay.With(labels).Add(delay.Seconds())
+}
@@ -0,0 +1,45 @@
+package tsm1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadPriority_CompactionRead(t *testing.T) {
+	p := newReadPriority(10000)
+
+	// Without queries reading, compactions read without limit.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		p.compactionRead(10000)
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("unthrottled reads took %s", d)
+	}
+	if got, exp := p.tracker.UnthrottledBytes(), uint64(100000); got != exp {
+		t.Fatalf("got %d unthrottled bytes, expected %d", got, exp)
+	}
+
+	// While a query reads, compactions read at the limited rate once the burst is spent,
+	// reads larger than the burst included.
+	p.queryRead()
+	p.compactionRead(15000)
+	start = time.Now()
+	p.compactionRead(1000)
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Fatalf("throttled read took %s, expected at least 100ms", d)
+	}
+	if got, exp := p.tracker.ThrottledBytes(), uint64(16000); got != exp {
+		t.Fatalf("got %d throttled bytes, expected %d", got, exp)
+	}
+
+	// No limit or no priority does not throttle.
+	for _, p := range []*readPriority{newReadPriority(0), nil} {
+		p.queryRead()
+		start := time.Now()
+		p.compactionRead(1 << 30)
+		if d := time.Since(start); d > 50*time.Millisecond {
+			t.Fatalf("unlimited read took %s", d)
+		}
+	}
+}
@@ -118,12 +118,13 @@ func (m *mmapAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) (
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return nil, ErrTSMClosed
+		return nil, err
 	}
 
-	a, err := DecodeFloatBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	a, err := DecodeFloatBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -137,12 +138,13 @@ func (m *mmapAccessor) readFloatArrayBlock(entry *IndexEntry, values *tsdb.Float
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return ErrTSMClosed
+		return err
 	}
 
-	err := DecodeFloatArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	err = DecodeFloatArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -152,12 +154,13 @@ func (m *mmapAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValu
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return nil, ErrTSMClosed
+		return nil, err
 	}
 
-	a, err := DecodeIntegerBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	a, err := DecodeIntegerBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -171,12 +174,13 @@ func (m *mmapAccessor) readIntegerArrayBlock(entry *IndexEntry, values *tsdb.Int
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return ErrTSMClosed
+		return err
 	}
 
-	err := DecodeIntegerArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	err = DecodeIntegerArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -186,12 +190,13 @@ func (m *mmapAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedVa
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return nil, ErrTSMClosed
+		return nil, err
 	}
 
-	a, err := DecodeUnsignedBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	a, err := DecodeUnsignedBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -205,12 +210,13 @@ func (m *mmapAccessor) readUnsignedArrayBlock(entry *IndexEntry, values *tsdb.Un
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return ErrTSMClosed
+		return err
 	}
 
-	err := DecodeUnsignedArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	err = DecodeUnsignedArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -220,12 +226,13 @@ func (m *mmapAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue)
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return nil, ErrTSMClosed
+		return nil, err
 	}
 
-	a, err := DecodeStringBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	a, err := DecodeStringBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -239,12 +246,13 @@ func (m *mmapAccessor) readStringArrayBlock(entry *IndexEntry, values *tsdb.Stri
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return ErrTSMClosed
+		return err
 	}
 
-	err := DecodeStringArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	err = DecodeStringArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -254,12 +262,13 @@ func (m *mmapAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValu
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return nil, ErrTSMClosed
+		return nil, err
 	}
 
-	a, err := DecodeBooleanBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	a, err := DecodeBooleanBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -273,13 +282,104 @@ func (m *mmapAccessor) readBooleanArrayBlock(entry *IndexEntry, values *tsdb.Boo
 	m.incAccess()
 
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	b, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return ErrTSMClosed
+		return err
 	}
 
-	err := DecodeBooleanArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	err = DecodeBooleanArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
 }
+
+func (a *tierAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) ([]FloatValue, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeFloatBlock(b, values)
+}
+
+func (a *tierAccessor) readFloatArrayBlock(entry *IndexEntry, values *tsdb.FloatArray) error {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return err
+	}
+
+	return DecodeFloatArrayBlock(b, values)
+}
+
+func (a *tierAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValue) ([]IntegerValue, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeIntegerBlock(b, values)
+}
+
+func (a *tierAccessor) readIntegerArrayBlock(entry *IndexEntry, values *tsdb.IntegerArray) error {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return err
+	}
+
+	return DecodeIntegerArrayBlock(b, values)
+}
+
+func (a *tierAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedValue) ([]UnsignedValue, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeUnsignedBlock(b, values)
+}
+
+func (a *tierAccessor) readUnsignedArrayBlock(entry *IndexEntry, values *tsdb.UnsignedArray) error {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return err
+	}
+
+	return DecodeUnsignedArrayBlock(b, values)
+}
+
+func (a *tierAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue) ([]StringValue, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeStringBlock(b, values)
+}
+
+func (a *tierAccessor) readStringArrayBlock(entry *IndexEntry, values *tsdb.StringArray) error {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return err
+	}
+
+	return DecodeStringArrayBlock(b, values)
+}
+
+func (a *tierAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeBooleanBlock(b, values)
+}
+
+func (a *tierAccessor) readBooleanArrayBlock(entry *IndexEntry, values *tsdb.BooleanArray) error {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return err
+	}
+
+	return DecodeBooleanArrayBlock(b, values)
+}
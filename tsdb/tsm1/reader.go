@@ -7,6 +7,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"go.uber.org/zap"
 )
 
@@ -20,7 +21,9 @@ type TSMReader struct {
 	refsWG sync.WaitGroup
 
 	logger          *zap.Logger
-	madviseWillNeed bool // Hint to the kernel with MADV_WILLNEED.
+	madviseWillNeed bool                // Hint to the kernel with MADV_WILLNEED.
+	objectStore     ObjectStore         // Where the blocks of a file moved to a storage tier are read from.
+	keyring         *encryption.Keyring // Decrypts the blocks of an encrypted file.
 	mu              sync.RWMutex
 
 	// accessor provides access and decoding of blocks for the reader.
@@ -57,6 +60,21 @@ var WithTSMReaderLogger = func(logger *zap.Logger) tsmReaderOption {
 	}
 }
 
+// WithTSMReaderObjectStore is an option for specifying the object store holding the blocks
+// of a file moved to a storage tier.
+var WithTSMReaderObjectStore = func(store ObjectStore) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.objectStore = store
+	}
+}
+
+// WithTSMReaderKeyring is an option for specifying the keyring decrypting the blocks of an encrypted file.
+var WithTSMReaderKeyring = func(keyring *encryption.Keyring) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.keyring = keyring
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{
@@ -72,10 +90,22 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
+	m := &mmapAccessor{
 		logger:       t.logger,
 		f:            f,
 		mmapWillNeed: t.madviseWillNeed,
+		keyring:      t.keyring,
+	}
+	t.accessor = m
+
+	// A file moved to a storage tier is a stub whose blocks are in the object store.
+	if key, err := readTierFile(f.Name()); err == nil {
+		if t.objectStore == nil {
+			return nil, ErrNoObjectStore
+		}
+		t.accessor = &tierAccessor{mmapAccessor: m, store: t.objectStore, key: key}
+	} else if !os.IsNotExist(err) {
+		return nil, err
 	}
 
 	index, err := t.accessor.init()
@@ -280,12 +310,26 @@ func (t *TSMReader) remove() error {
 		}
 	}
 
+	if ta, ok := t.accessor.(*tierAccessor); ok {
+		if err := ta.remove(); err != nil {
+			return err
+		}
+	}
+
 	if err := t.tombstoner.Delete(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// tiered returns true if the file was moved to a storage tier.
+func (t *TSMReader) tiered() bool {
+	t.mu.RLock()
+	_, ok := t.accessor.(*tierAccessor)
+	t.mu.RUnlock()
+	return ok
+}
+
 // Contains returns whether the given key is present in the index.
 func (t *TSMReader) Contains(key []byte) bool {
 	return t.index.Contains(key)
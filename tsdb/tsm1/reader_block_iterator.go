@@ -6,6 +6,9 @@ type BlockIterator struct {
 	r       *TSMReader
 	iter    *TSMIndexIterator
 	entries []IndexEntry
+
+	// priority throttles the reads of the blocks in favour of those of queries, if not nil.
+	priority *readPriority
 }
 
 // PeekNext returns the next key to be iterated or an empty string.
@@ -39,6 +42,7 @@ func (b *BlockIterator) Read() (key []byte, minTime int64, maxTime int64, typ by
 	if err := b.iter.Err(); err != nil {
 		return nil, 0, 0, 0, 0, nil, err
 	}
+	b.priority.compactionRead(int(b.entries[0].Size))
 	checksum, buf, err = b.r.ReadBytes(&b.entries[0], nil)
 	if err != nil {
 		return nil, 0, 0, 0, 0, nil, err
@@ -3,10 +3,12 @@ package tsm1
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"os"
 	"sync"
 	"sync/atomic"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 	"go.uber.org/zap"
 )
@@ -24,6 +26,10 @@ type mmapAccessor struct {
 	b  []byte
 	f  *os.File
 
+	// keyring decrypts the data of the blocks if the file is encrypted.
+	keyring   *encryption.Keyring
+	encrypted bool
+
 	index *indirectIndex
 }
 
@@ -31,11 +37,14 @@ func (m *mmapAccessor) init() (*indirectIndex, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if err := verifyVersion(m.f); err != nil {
+	version, err := verifyVersion(m.f)
+	if err != nil {
 		return nil, err
 	}
-
-	var err error
+	m.encrypted = version == EncryptedVersion
+	if m.encrypted && m.keyring == nil {
+		return nil, ErrNoKeyring
+	}
 
 	if _, err := m.f.Seek(0, 0); err != nil {
 		return nil, err
@@ -157,6 +166,24 @@ func (m *mmapAccessor) rename(path string) error {
 	return nil
 }
 
+// decrypt returns the data b of a block as stored in the file, decrypted and appended to dst
+// if the file is encrypted.
+func (m *mmapAccessor) decrypt(dst, b []byte) ([]byte, error) {
+	if !m.encrypted {
+		return b, nil
+	}
+	return m.keyring.Decrypt(dst, b)
+}
+
+// block returns the data of the block of entry, without its checksum.
+// m.mu must be held until the data is no longer used.
+func (m *mmapAccessor) block(entry *IndexEntry) ([]byte, error) {
+	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+		return nil, ErrTSMClosed
+	}
+	return m.decrypt(nil, m.b[entry.Offset+4:entry.Offset+int64(entry.Size)])
+}
+
 func (m *mmapAccessor) read(key []byte, timestamp int64) ([]Value, error) {
 	entry := m.index.Entry(key, timestamp)
 	if entry == nil {
@@ -172,12 +199,12 @@ func (m *mmapAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, er
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
-		return nil, ErrTSMClosed
-	}
 	//TODO: Validate checksum
-	var err error
-	values, err = DecodeBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	values, err = DecodeBlock(b, values)
 	if err != nil {
 		return nil, err
 	}
@@ -198,7 +225,16 @@ func (m *mmapAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, e
 	crc, block := binary.BigEndian.Uint32(m.b[entry.Offset:entry.Offset+4]), m.b[entry.Offset+4:entry.Offset+int64(entry.Size)]
 	m.mu.RUnlock()
 
-	return crc, block, nil
+	if !m.encrypted {
+		return crc, block, nil
+	}
+
+	// The checksum of an encrypted block is the one of the decrypted data.
+	block, err := m.keyring.Decrypt(b[:0], block)
+	if err != nil {
+		return 0, nil, err
+	}
+	return crc32.ChecksumIEEE(block), block, nil
 }
 
 // readAll returns all values for a key in all blocks.
@@ -232,8 +268,11 @@ func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
 		}
 		//TODO: Validate checksum
 		temp = temp[:0]
-		// The +4 is the 4 byte checksum length
-		temp, err = DecodeBlock(m.b[block.Offset+4:block.Offset+int64(block.Size)], temp)
+		b, err := m.block(&block)
+		if err != nil {
+			return nil, err
+		}
+		temp, err = DecodeBlock(b, temp)
 		if err != nil {
 			return nil, err
 		}
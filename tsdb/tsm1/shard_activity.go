@@ -0,0 +1,166 @@
+package tsm1
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// CacheShardID is the ID of the cache in the activity of the shards, see ShardActivity.
+const CacheShardID = "cache"
+
+// ShardActivity is the activity of the values of a bucket in a shard of the engine, the cache
+// or a TSM file, since the engine was created.
+type ShardActivity struct {
+	// ID is CacheShardID or the ID of a TSM file, see FileID.
+	ID string
+
+	// Name is the name of the bucket.
+	Name []byte
+
+	// QueryHits is the number of keys of the bucket queries read from the shard.
+	QueryHits int64
+
+	// WrittenValues and WrittenBytes are the values of the bucket written to the cache,
+	// and CacheSize the size of the values of the bucket the cache holds. They are zero for TSM files.
+	WrittenValues int64
+	WrittenBytes  int64
+	CacheSize     int64
+}
+
+type shardActivityKey struct {
+	id   string
+	name string
+}
+
+// shardActivity counts the reads of queries and the writes of the values of each bucket in the cache
+// and in the TSM files.
+type shardActivity struct {
+	mu         sync.Mutex
+	activities map[shardActivityKey]*ShardActivity
+}
+
+func newShardActivity() *shardActivity {
+	return &shardActivity{activities: make(map[shardActivityKey]*ShardActivity)}
+}
+
+// activity returns the activity of the bucket name in the shard id. It must be called under the lock.
+func (a *shardActivity) activity(id string, name []byte) *ShardActivity {
+	k := shardActivityKey{id: id, name: string(name)}
+	sa := a.activities[k]
+	if sa == nil {
+		sa = &ShardActivity{ID: id, Name: []byte(k.name)}
+		a.activities[k] = sa
+	}
+	return sa
+}
+
+// queryHit records that a query reads key from the shards ids.
+func (a *shardActivity) queryHit(key []byte, ids ...string) {
+	if a == nil || len(ids) == 0 {
+		return
+	}
+	name := models.ParseName(key)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, id := range ids {
+		a.activity(id, name).QueryHits++
+	}
+}
+
+// write records the values written to the cache.
+func (a *shardActivity) write(values map[string][]Value) {
+	if a == nil {
+		return
+	}
+
+	// The keys of a write are usually of a few buckets, so they are counted before taking the lock.
+	type counts struct{ values, bytes int64 }
+	byName := make(map[string]*counts)
+	for k, vals := range values {
+		name := models.ParseName([]byte(k))
+		c := byName[string(name)]
+		if c == nil {
+			c = new(counts)
+			byName[string(name)] = c
+		}
+		c.values += int64(len(vals))
+		c.bytes += int64(Values(vals).Size())
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name, c := range byName {
+		sa := a.activity(CacheShardID, []byte(name))
+		sa.WrittenValues += c.values
+		sa.WrittenBytes += c.bytes
+	}
+}
+
+// ShardActivity returns the activity of the buckets in the cache and in the TSM files of the engine,
+// ordered by shard, the cache first, and bucket name. The activity of the TSM files removed by compactions is dropped.
+func (e *Engine) ShardActivity() ([]ShardActivity, error) {
+	sizes := make(map[string]int64)
+	if err := e.Cache.ApplyEntryFn(func(key []byte, entry *entry) error {
+		sizes[string(models.ParseName(key))] += int64(entry.size())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{CacheShardID: true}
+	for _, f := range e.FileStore.Files() {
+		files[FileID(f.Path())] = true
+	}
+
+	a := e.activity
+	a.mu.Lock()
+	for name := range sizes {
+		a.activity(CacheShardID, []byte(name))
+	}
+	activities := make([]ShardActivity, 0, len(a.activities))
+	for k, sa := range a.activities {
+		if !files[k.id] {
+			delete(a.activities, k)
+			continue
+		}
+		activities = append(activities, *sa)
+	}
+	a.mu.Unlock()
+
+	for i := range activities {
+		if activities[i].ID == CacheShardID {
+			activities[i].CacheSize = sizes[string(activities[i].Name)]
+		}
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		if ii, jj := activities[i].ID == CacheShardID, activities[j].ID == CacheShardID; ii != jj {
+			return ii
+		}
+		if activities[i].ID != activities[j].ID {
+			return activities[i].ID < activities[j].ID
+		}
+		return string(activities[i].Name) < string(activities[j].Name)
+	})
+	return activities, nil
+}
+
+// cacheValues returns the values of key in the cache, and records that a query reads them if there are any.
+func (e *Engine) cacheValues(key []byte) Values {
+	values := e.Cache.Values(key)
+	if len(values) > 0 {
+		e.activity.queryHit(key, CacheShardID)
+	}
+	return values
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
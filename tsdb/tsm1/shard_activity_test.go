@@ -0,0 +1,95 @@
+package tsm1_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestEngine_ShardActivity(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// The file holds points of cpu and mem, and the cache points of cpu.
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1"),
+		MustParsePointString("mem,host=A value=1.2 2"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background()); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.3 3"),
+		MustParsePointString("cpu,host=B value=1.4 4"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		e.KeyCursor(context.Background(), []byte("cpu,host=A#!~#value"), math.MinInt64, true).Close()
+	}
+	files := e.FileStore.Files()
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	id := tsm1.FileID(files[0].Path())
+
+	activities, err := e.ShardActivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]tsm1.ShardActivity)
+	for _, a := range activities {
+		got[a.ID+"/"+string(a.Name)] = a
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d activities, want 3: %+v", len(got), activities)
+	}
+	if a := got["cache/cpu"]; a.WrittenValues != 3 || a.WrittenBytes <= 0 || a.CacheSize <= 0 || a.QueryHits != 0 {
+		t.Errorf("unexpected activity of cpu in the cache: %+v", a)
+	}
+	if a := got["cache/mem"]; a.WrittenValues != 1 || a.CacheSize != 0 {
+		t.Errorf("unexpected activity of mem in the cache: %+v", a)
+	}
+	if a := got[id+"/cpu"]; a.QueryHits != 2 || a.WrittenValues != 0 {
+		t.Errorf("unexpected activity of cpu in the file: %+v", a)
+	}
+	if activities[0].ID != tsm1.CacheShardID {
+		t.Errorf("expected the cache first, got %+v", activities[0])
+	}
+
+	// The activity of the files removed by a compaction is dropped.
+	if err := e.WriteSnapshot(context.Background()); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+	var paths []string
+	for _, f := range e.FileStore.Files() {
+		paths = append(paths, f.Path())
+	}
+	compacted, err := e.Compactor.CompactFull(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.FileStore.Replace(paths, compacted); err != nil {
+		t.Fatal(err)
+	}
+	activities, err = e.ShardActivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range activities {
+		if a.ID == id {
+			t.Errorf("unexpected activity of a removed file: %+v", a)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package tsm1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// SnapshotStats are the statistics of a snapshot of a storage engine directory,
+// such as a copy of the engine directory taken at some point in time.
+type SnapshotStats struct {
+	Dir string
+
+	Series  uint64            // The exact or estimated unique set of series keys across all TSM files.
+	Buckets map[string]uint64 // The exact or estimated unique set of series keys segmented by bucket.
+
+	// Sizes are the sizes in bytes of the entries at the root of the snapshot,
+	// such as the data, index and wal directories of the engine.
+	Sizes map[string]int64
+
+	TSMFiles       int
+	TSMBytes       int64
+	TombstoneFiles int
+	TombstoneBytes int64
+}
+
+// ReadSnapshotStats reads the statistics of the snapshot in dir.
+// The series are counted across every TSM file under dir, after their tombstones are applied,
+// exactly if exact is true or estimated otherwise.
+func ReadSnapshotStats(dir string, exact bool) (*SnapshotStats, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("snapshot %s is not a directory", dir)
+	}
+
+	newCounterFn := newHLLCounter
+	if exact {
+		newCounterFn = newExactCounter
+	}
+
+	stats := &SnapshotStats{
+		Dir:     dir,
+		Buckets: map[string]uint64{},
+		Sizes:   map[string]int64{},
+	}
+	totalSeries := newCounterFn()
+	bucketCardinalities := map[string]counter{}
+
+	var tsmFiles []string
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		stats.Sizes[strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]] += fi.Size()
+
+		switch filepath.Ext(path) {
+		case "." + TSMFileExtension:
+			stats.TSMFiles++
+			stats.TSMBytes += fi.Size()
+			tsmFiles = append(tsmFiles, path)
+		case ".tombstone":
+			stats.TombstoneFiles++
+			stats.TombstoneBytes += fi.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range tsmFiles {
+		file, err := os.OpenFile(path, os.O_RDONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		reader, err := NewTSMReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		itr := reader.Iterator(nil)
+		if itr == nil {
+			reader.Close()
+			return nil, errors.New("invalid TSM file, no index iterator")
+		}
+
+		for itr.Next() {
+			key := itr.Key()
+			totalSeries.Add(key)
+			if len(key) < 16 {
+				continue
+			}
+
+			var a [16]byte
+			copy(a[:], key[:16])
+			_, bucket := tsdb.DecodeName(a)
+
+			bucketCount := bucketCardinalities[bucket.String()]
+			if bucketCount == nil {
+				bucketCount = newCounterFn()
+				bucketCardinalities[bucket.String()] = bucketCount
+			}
+			bucketCount.Add(key)
+		}
+
+		if err := reader.Close(); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	stats.Series = totalSeries.Count()
+	for bucket, c := range bucketCardinalities {
+		stats.Buckets[bucket] = c.Count()
+	}
+	return stats, nil
+}
+
+// SnapshotChange is the change of a statistic between two snapshots.
+type SnapshotChange struct {
+	Statistic     string
+	Before, After int64
+}
+
+// Delta returns how much the statistic grew between the snapshots.
+func (c SnapshotChange) Delta() int64 {
+	return c.After - c.Before
+}
+
+// DiffSnapshotStats returns the changes of every statistic between the before and after snapshots,
+// including the statistics that did not change.
+func DiffSnapshotStats(before, after *SnapshotStats) []SnapshotChange {
+	changes := []SnapshotChange{
+		{Statistic: "series", Before: int64(before.Series), After: int64(after.Series)},
+	}
+	for _, bucket := range unionKeys(before.Buckets, after.Buckets) {
+		changes = append(changes, SnapshotChange{
+			Statistic: "series in bucket " + bucket,
+			Before:    int64(before.Buckets[bucket]),
+			After:     int64(after.Buckets[bucket]),
+		})
+	}
+
+	sizes := make(map[string]struct{}, len(before.Sizes))
+	for name := range before.Sizes {
+		sizes[name] = struct{}{}
+	}
+	for name := range after.Sizes {
+		sizes[name] = struct{}{}
+	}
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		changes = append(changes, SnapshotChange{
+			Statistic: "bytes in " + name,
+			Before:    before.Sizes[name],
+			After:     after.Sizes[name],
+		})
+	}
+
+	return append(changes,
+		SnapshotChange{Statistic: "TSM files", Before: int64(before.TSMFiles), After: int64(after.TSMFiles)},
+		SnapshotChange{Statistic: "TSM bytes", Before: before.TSMBytes, After: after.TSMBytes},
+		SnapshotChange{Statistic: "tombstone files", Before: int64(before.TombstoneFiles), After: int64(after.TombstoneFiles)},
+		SnapshotChange{Statistic: "tombstone bytes", Before: before.TombstoneBytes, After: after.TombstoneBytes},
+	)
+}
+
+// unionKeys returns the sorted keys of a and b.
+func unionKeys(a, b map[string]uint64) []string {
+	set := make(map[string]struct{}, len(a))
+	for k := range a {
+		set[k] = struct{}{}
+	}
+	for k := range b {
+		set[k] = struct{}{}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SnapshotDiff compares two snapshots of a storage engine directory,
+// to help diagnose how the disk usage of the engine changed between them.
+type SnapshotDiff struct {
+	Stdout io.Writer
+
+	Before, After string // The directories of the snapshots.
+	Exact         bool   // Exact determines if estimation or exact methods are used to count series.
+	ChangedOnly   bool   // ChangedOnly leaves the statistics that did not change out of the report.
+}
+
+// Run reads both snapshots, writes a report of their changes to Stdout and returns the changes.
+func (d *SnapshotDiff) Run() ([]SnapshotChange, error) {
+	if d.Stdout == nil {
+		d.Stdout = ioutil.Discard
+	}
+
+	before, err := ReadSnapshotStats(d.Before, d.Exact)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ReadSnapshotStats(d.After, d.Exact)
+	if err != nil {
+		return nil, err
+	}
+
+	estTitle := " (est)"
+	if d.Exact {
+		estTitle = ""
+	}
+
+	changes := DiffSnapshotStats(before, after)
+
+	tw := tabwriter.NewWriter(d.Stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Statistic", "Before", "After", "Change"}, "\t"))
+	for _, c := range changes {
+		if d.ChangedOnly && c.Delta() == 0 {
+			continue
+		}
+		name := c.Statistic
+		if strings.HasPrefix(name, "series") {
+			name += estTitle
+		}
+		fmt.Fprintln(tw, strings.Join([]string{
+			name,
+			strconv.FormatInt(c.Before, 10),
+			strconv.FormatInt(c.After, 10),
+			formatChange(c),
+		}, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// formatChange formats the delta of c, with its percentage of the before value when there is one.
+func formatChange(c SnapshotChange) string {
+	s := strconv.FormatInt(c.Delta(), 10)
+	if c.Delta() > 0 {
+		s = "+" + s
+	}
+	if c.Before != 0 && c.Delta() != 0 {
+		s += fmt.Sprintf(" (%+.1f%%)", float64(c.Delta())/float64(c.Before)*100)
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// snapshotKey returns a TSM key of a series in the bucket.
+func snapshotKey(bucket influxdb.ID, series string) []byte {
+	name := tsdb.EncodeName(1, bucket)
+	return []byte(string(name[:]) + ",_m=cpu,host=" + series + "#!~#value")
+}
+
+// writeSnapshotTSMFile writes a TSM file at path with a value for each key.
+func writeSnapshotTSMFile(t *testing.T, path string, keys ...[]byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if err := w.Write(key, []tsm1.Value{tsm1.NewValue(0, 1.0)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotDiff_Run(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	before, after := filepath.Join(dir, "before"), filepath.Join(dir, "after")
+	a1, a2, b1 := snapshotKey(10, "a1"), snapshotKey(10, "a2"), snapshotKey(11, "b1")
+
+	// The after snapshot has a series of the first file deleted, and a new file with a series of another bucket.
+	for _, snapshot := range []string{before, after} {
+		writeSnapshotTSMFile(t, filepath.Join(snapshot, "data", "000000001-000000001.tsm"), a1, a2)
+		if err := os.MkdirAll(filepath.Join(snapshot, "wal"), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(snapshot, "wal", "_00001.wal"), []byte("0123456789"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSnapshotTSMFile(t, filepath.Join(after, "data", "000000002-000000001.tsm"), b1)
+
+	f, err := os.Open(filepath.Join(after, "data", "000000001-000000001.tsm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete([][]byte{a2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	diff := &tsm1.SnapshotDiff{
+		Stdout:      &buf,
+		Before:      before,
+		After:       after,
+		Exact:       true,
+		ChangedOnly: true,
+	}
+	changes, err := diff.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]tsm1.SnapshotChange, len(changes))
+	for _, c := range changes {
+		got[c.Statistic] = c
+	}
+	bucketA, bucketB := influxdb.ID(10).String(), influxdb.ID(11).String()
+	for _, want := range []tsm1.SnapshotChange{
+		{Statistic: "series", Before: 2, After: 2},
+		{Statistic: "series in bucket " + bucketA, Before: 2, After: 1},
+		{Statistic: "series in bucket " + bucketB, Before: 0, After: 1},
+		{Statistic: "bytes in wal", Before: 10, After: 10},
+		{Statistic: "TSM files", Before: 1, After: 2},
+		{Statistic: "tombstone files", Before: 0, After: 1},
+	} {
+		if got[want.Statistic] != want {
+			t.Errorf("got change %+v, want %+v", got[want.Statistic], want)
+		}
+	}
+	if c := got["bytes in data"]; c.Delta() <= 0 {
+		t.Errorf("expected the data directory to grow, got change %+v", c)
+	}
+
+	report := buf.String()
+	if !strings.Contains(report, "series in bucket "+bucketB) {
+		t.Errorf("expected the new bucket in the report:\n%s", report)
+	}
+	if strings.Contains(report, "bytes in wal") {
+		t.Errorf("expected unchanged statistics to be left out of the report:\n%s", report)
+	}
+}
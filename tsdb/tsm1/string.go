@@ -3,7 +3,8 @@ package tsm1
 // String encoding uses snappy compression to compress each string.  Each string is
 // appended to byte slice prefixed with a variable byte length followed by the string
 // bytes.  The bytes are compressed using snappy compressor and a 1 byte header is used
-// to indicate the type of encoding.
+// to indicate the type of encoding.  Blocks rewritten by a compactor configured with
+// BlockCompressionZstd compress the same bytes with Zstandard instead.
 
 import (
 	"encoding/binary"
@@ -14,8 +15,13 @@ import (
 
 // Note: an uncompressed format is not yet implemented.
 
-// stringCompressedSnappy is a compressed encoding using Snappy compression
-const stringCompressedSnappy = 1
+const (
+	// stringCompressedSnappy is a compressed encoding using Snappy compression
+	stringCompressedSnappy = 1
+
+	// stringCompressedZstd is a compressed encoding using Zstandard compression
+	stringCompressedZstd = 2
+)
 
 // StringEncoder encodes multiple strings into a byte slice.
 type StringEncoder struct {
@@ -68,12 +74,11 @@ type StringDecoder struct {
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type.
 	var data []byte
 	if len(b) > 0 {
 		var err error
-		data, err = snappy.Decode(nil, b[1:])
+		data, err = decompressStrings(b)
 		if err != nil {
 			return fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -87,6 +92,19 @@ func (e *StringDecoder) SetBytes(b []byte) error {
 	return nil
 }
 
+// decompressStrings returns the length prefixed strings of the encoded block b,
+// of which the first byte stores the encoding type.
+func decompressStrings(b []byte) ([]byte, error) {
+	switch b[0] >> 4 {
+	case stringCompressedSnappy:
+		return snappy.Decode(nil, b[1:])
+	case stringCompressedZstd:
+		return zstdDecoder.DecodeAll(b[1:], nil)
+	default:
+		return nil, fmt.Errorf("unknown string encoding %v", b[0]>>4)
+	}
+}
+
 // Next returns true if there are any values remaining to be decoded.
 func (e *StringDecoder) Next() bool {
 	if e.err != nil {
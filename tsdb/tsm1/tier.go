@@ -0,0 +1,503 @@
+package tsm1
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/influxdb/pkg/file"
+	"go.uber.org/zap"
+)
+
+// A TSM file whose data is older than a configurable age can be moved to a
+// storage tier backed by an object store, such as S3, GCS or Azure Blob Storage.
+//
+// The whole file is uploaded to the object store and replaced on disk by a
+// stub of the same size holding only the header, the index and the footer at
+// their original offsets, with the blocks left as a hole. The stub is opened
+// like any other TSM file, so that its index stays local, but its blocks are
+// fetched from the object store when read. A tier file next to the stub records
+// the key of the object holding the blocks.
+
+// ErrNoObjectStore is returned when opening a TSM file moved to a storage tier
+// without an object store to read its blocks from.
+var ErrNoObjectStore = errors.New("tsm file moved to a storage tier but no object store is configured")
+
+// tsmHeaderSize is the size of the header of a TSM file: a magic number and a version.
+const tsmHeaderSize = 5
+
+// ObjectStore stores the TSM files moved to a storage tier.
+type ObjectStore interface {
+	// Put stores the content of r as the object key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// ReadAt reads len(p) bytes of the object key starting at offset off.
+	ReadAt(ctx context.Context, key string, p []byte, off int64) (int, error)
+
+	// Delete deletes the object key. Deleting an object that does not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// DirObjectStore is an ObjectStore keeping objects as files of a directory,
+// which may be a mount of a remote file system.
+type DirObjectStore struct {
+	dir string
+}
+
+var _ ObjectStore = (*DirObjectStore)(nil)
+
+// NewDirObjectStore returns an ObjectStore keeping objects in dir.
+func NewDirObjectStore(dir string) *DirObjectStore {
+	return &DirObjectStore{dir: dir}
+}
+
+// Put writes the content of r to the file key, replacing it atomically.
+func (s *DirObjectStore) Put(ctx context.Context, key string, r io.Reader) error {
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(s.dir, key+".*."+TmpTSMFileExtension)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return file.RenameFile(f.Name(), filepath.Join(s.dir, key))
+}
+
+// ReadAt reads len(p) bytes of the file key starting at offset off.
+func (s *DirObjectStore) ReadAt(ctx context.Context, key string, p []byte, off int64) (int, error) {
+	f, err := os.Open(filepath.Join(s.dir, key))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n, err := f.ReadAt(p, off)
+	if err == io.EOF && n == len(p) {
+		err = nil
+	}
+	return n, err
+}
+
+// Delete removes the file key.
+func (s *DirObjectStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(s.dir, key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// TierFilename returns the path to the tier file for a given TSM file path.
+func TierFilename(tsmPath string) string {
+	if strings.HasSuffix(tsmPath, "."+TmpTSMFileExtension) {
+		tsmPath = strings.TrimSuffix(tsmPath, "."+TmpTSMFileExtension)
+	}
+	if strings.HasSuffix(tsmPath, "."+TSMFileExtension) {
+		tsmPath = strings.TrimSuffix(tsmPath, "."+TSMFileExtension)
+	}
+	return tsmPath + "." + TierFileExtension
+}
+
+// readTierFile returns the key of the object holding the blocks of the TSM file at tsmPath.
+// It returns an error satisfying os.IsNotExist if the file was not moved to a storage tier.
+func readTierFile(tsmPath string) (string, error) {
+	b, err := ioutil.ReadFile(TierFilename(tsmPath))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// writeTierFile atomically records that the blocks of the TSM file at tsmPath are held by the object key.
+func writeTierFile(tsmPath, key string) error {
+	path := TierFilename(tsmPath)
+	tmpPath := path + "." + TmpTSMFileExtension
+	if err := ioutil.WriteFile(tmpPath, []byte(key+"\n"), 0666); err != nil {
+		return err
+	}
+	return file.RenameFile(tmpPath, path)
+}
+
+// writeTierStub writes at path a file of the size of src holding only its header,
+// its index and its footer, at the same offsets.
+func writeTierStub(src *os.File, path string) error {
+	stat, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	if size < tsmHeaderSize+8 {
+		return fmt.Errorf("writeTierStub: %s too small for a tsm file", src.Name())
+	}
+
+	var header [tsmHeaderSize]byte
+	if _, err := src.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+	var footer [8]byte
+	if _, err := src.ReadAt(footer[:], size-8); err != nil {
+		return err
+	}
+	indexStart := int64(binary.BigEndian.Uint64(footer[:]))
+	if indexStart < tsmHeaderSize || indexStart >= size-8 {
+		return fmt.Errorf("writeTierStub: invalid indexStart in %s", src.Name())
+	}
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(header[:]); err != nil {
+		dst.Close()
+		return err
+	} else if _, err := dst.Seek(indexStart, io.SeekStart); err != nil {
+		dst.Close()
+		return err
+	} else if _, err := io.Copy(dst, io.NewSectionReader(src, indexStart, size-indexStart)); err != nil {
+		dst.Close()
+		return err
+	} else if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// tierAccessor is a block accessor for a TSM file moved to a storage tier.
+// It reads the index from the local stub through an mmapAccessor, and fetches
+// blocks from the object store.
+type tierAccessor struct {
+	*mmapAccessor
+
+	store ObjectStore
+	key   string
+}
+
+// fetch returns the block of entry, checksum included, read from the object store.
+func (a *tierAccessor) fetch(entry *IndexEntry) ([]byte, error) {
+	a.incAccess()
+
+	a.mu.RLock()
+	closed := int64(len(a.b)) < entry.Offset+int64(entry.Size)
+	a.mu.RUnlock()
+	if closed {
+		return nil, ErrTSMClosed
+	}
+
+	b := make([]byte, entry.Size)
+	if _, err := a.store.ReadAt(context.Background(), a.key, b, entry.Offset); err != nil {
+		return nil, fmt.Errorf("tierAccessor: error reading block of %s: %v", a.key, err)
+	}
+	if len(b) < crc32.Size {
+		return nil, fmt.Errorf("tierAccessor: block of %s at offset %d too small", a.key, entry.Offset)
+	}
+	if crc32.ChecksumIEEE(b[crc32.Size:]) != binary.BigEndian.Uint32(b[:crc32.Size]) {
+		return nil, fmt.Errorf("tierAccessor: checksum mismatch for block of %s at offset %d", a.key, entry.Offset)
+	}
+	return b, nil
+}
+
+// fetchBlock returns the data of the block of entry, without its checksum, read from the object store.
+func (a *tierAccessor) fetchBlock(entry *IndexEntry) ([]byte, error) {
+	b, err := a.fetch(entry)
+	if err != nil {
+		return nil, err
+	}
+	return a.decrypt(nil, b[crc32.Size:])
+}
+
+func (a *tierAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := a.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return a.readBlock(entry, nil)
+}
+
+func (a *tierAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	b, err := a.fetchBlock(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(b, values)
+}
+
+func (a *tierAccessor) readBytes(entry *IndexEntry, buf []byte) (uint32, []byte, error) {
+	b, err := a.fetch(entry)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !a.encrypted {
+		return binary.BigEndian.Uint32(b[:crc32.Size]), b[crc32.Size:], nil
+	}
+
+	// The checksum of an encrypted block is the one of the decrypted data.
+	block, err := a.decrypt(buf[:0], b[crc32.Size:])
+	if err != nil {
+		return 0, nil, err
+	}
+	return crc32.ChecksumIEEE(block), block, nil
+}
+
+// readAll returns all values for a key in all blocks.
+func (a *tierAccessor) readAll(key []byte) ([]Value, error) {
+	blocks, err := a.index.ReadEntries(key, nil)
+	if len(blocks) == 0 || err != nil {
+		return nil, err
+	}
+
+	tombstones := a.index.TombstoneRange(key, nil)
+
+	var temp []Value
+	var values []Value
+	for i := range blocks {
+		block := &blocks[i]
+
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		temp, err = a.readBlock(block, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+// rename renames the stub, and its tier file if the new name calls for another one.
+func (a *tierAccessor) rename(path string) error {
+	oldTierPath := TierFilename(a.path())
+	if err := a.mmapAccessor.rename(path); err != nil {
+		return err
+	}
+
+	if newTierPath := TierFilename(path); newTierPath != oldTierPath {
+		return file.RenameFile(oldTierPath, newTierPath)
+	}
+	return nil
+}
+
+// remove deletes the object holding the blocks and the tier file.
+func (a *tierAccessor) remove() error {
+	if err := a.store.Delete(context.Background(), a.key); err != nil {
+		return err
+	}
+	if err := os.Remove(TierFilename(a.path())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// WithObjectStore sets the object store that TSM files are moved to by MoveColdFiles.
+// It must be called before the FileStore is opened.
+func (f *FileStore) WithObjectStore(store ObjectStore) {
+	f.objectStore = store
+}
+
+// MoveColdFiles moves to the object store the fully compacted TSM files holding
+// no data at or after before, and returns the number of files moved.
+// Files in use are skipped, to be moved by a later call.
+func (f *FileStore) MoveColdFiles(ctx context.Context, before int64) (int, error) {
+	if f.objectStore == nil {
+		return 0, ErrNoObjectStore
+	}
+
+	f.mu.RLock()
+	var paths []string
+	for _, r := range f.files {
+		if tr, ok := r.(*TSMReader); !ok || tr.tiered() {
+			continue
+		}
+		if _, seq, err := f.parseFileName(r.Path()); err != nil || seq < 4 {
+			continue
+		}
+		if _, maxTime := r.TimeRange(); maxTime >= before {
+			continue
+		}
+		paths = append(paths, r.Path())
+	}
+	f.mu.RUnlock()
+
+	var n int
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		moved, err := f.moveToTier(ctx, path)
+		if err == ErrFileInUse {
+			f.logger.Debug("Not moving file in use to the storage tier", zap.String("path", path))
+			continue
+		} else if err != nil {
+			return n, err
+		} else if moved {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// moveToTier uploads the TSM file at path to the object store, then replaces it
+// by its stub. It returns false if the file was removed by a compaction meanwhile.
+func (f *FileStore) moveToTier(ctx context.Context, path string) (bool, error) {
+	key := filepath.Base(path)
+	stubPath := fmt.Sprintf("%s.%s.%s", path, TierFileExtension, TmpTSMFileExtension)
+
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	err = f.objectStore.Put(ctx, key, src)
+	if err == nil {
+		err = writeTierStub(src, stubPath)
+	}
+	src.Close()
+
+	// discard cleans up when the file cannot be replaced by its stub.
+	discard := func() error {
+		os.Remove(stubPath)
+		if err := os.Remove(TierFilename(path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return f.objectStore.Delete(ctx, key)
+	}
+	if err != nil {
+		discard()
+		return false, err
+	}
+
+	// Record the object first: if the process dies before the stub replaces
+	// the file, the file is read from the object store, which holds the same data.
+	if err := writeTierFile(path, key); err != nil {
+		discard()
+		return false, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := -1
+	for i, r := range f.files {
+		if r.Path() == path {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false, discard()
+	}
+
+	// InUse is only valid under the write lock, see replace.
+	old := f.files[idx]
+	if old.InUse() {
+		if err := discard(); err != nil {
+			return false, err
+		}
+		return false, ErrFileInUse
+	}
+
+	if err := file.RenameFile(stubPath, path); err != nil {
+		discard()
+		return false, err
+	}
+
+	fd, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	tsm, err := NewTSMReader(fd,
+		WithMadviseWillNeed(f.tsmMMAPWillNeed),
+		WithTSMReaderLogger(f.logger),
+		WithTSMReaderObjectStore(f.objectStore),
+		WithTSMReaderKeyring(f.keyring))
+	if err != nil {
+		return false, err
+	}
+	tsm.WithObserver(f.obs)
+
+	f.files[idx] = tsm
+	f.lastFileStats = nil
+
+	// The old reader maps a file that is now unlinked: close it without removing anything.
+	if err := old.Close(); err != nil {
+		return true, err
+	}
+
+	f.logger.Info("Moved file to the storage tier", zap.String("path", path), zap.String("key", key))
+	return true, nil
+}
+
+// removeOrphanTierFiles removes the tier files whose TSM file is gone, as well as
+// their objects. That happens when the process dies before a TSM file moved to a
+// storage tier and replaced by a compaction, but still in use, is removed.
+func (f *FileStore) removeOrphanTierFiles(ctx context.Context) error {
+	if f.objectStore == nil {
+		return nil
+	}
+
+	tierFiles, err := filepath.Glob(filepath.Join(f.dir, "*."+TierFileExtension))
+	if err != nil {
+		return err
+	}
+
+	for _, tierFile := range tierFiles {
+		tsmPath := strings.TrimSuffix(tierFile, "."+TierFileExtension) + "." + TSMFileExtension
+		if _, err := os.Stat(tsmPath); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		key, err := readTierFile(tsmPath)
+		if err != nil {
+			return err
+		}
+		if err := f.objectStore.Delete(ctx, key); err != nil {
+			return err
+		}
+		if err := os.Remove(tierFile); err != nil {
+			return err
+		}
+		f.logger.Info("Removed orphan tier file", zap.String("path", tierFile), zap.String("key", key))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// newCompactedFiles creates a fully compacted TSM file for each of values.
+func newCompactedFiles(dir string, values ...keyValues) ([]string, error) {
+	files, err := newFiles(dir, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range files {
+		newName := filepath.Join(dir, tsm1.DefaultFormatFileName(i+1, 4)+".tsm")
+		if err := os.Rename(file, newName); err != nil {
+			return nil, err
+		}
+		files[i] = newName
+	}
+	return files, nil
+}
+
+func TestFileStore_MoveColdFiles(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	storeDir := MustTempDir()
+	defer os.RemoveAll(storeDir)
+	store := tsm1.NewDirObjectStore(storeDir)
+
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(10, 2.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1000, 3.0)}},
+	}
+	files, err := newCompactedFiles(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	// A file not fully compacted yet stays on local disk.
+	level1, err := newFileDir(dir, keyValues{"mem", []tsm1.Value{tsm1.NewValue(0, 1.0)}})
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+	newName := filepath.Join(dir, tsm1.DefaultFormatFileName(3, 1)+".tsm")
+	if err := os.Rename(level1[0], newName); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.WithObjectStore(store)
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatalf("unexpected error opening file store: %v", err)
+	}
+	defer fs.Close()
+
+	n, err := fs.MoveColdFiles(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error moving cold files: %v", err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of files moved: got %d, exp 1", n)
+	}
+
+	key := filepath.Base(files[0])
+	if _, err := os.Stat(filepath.Join(storeDir, key)); err != nil {
+		t.Fatalf("expected the cold file in the object store: %v", err)
+	}
+	if _, err := os.Stat(tsm1.TierFilename(files[0])); err != nil {
+		t.Fatalf("expected a tier file: %v", err)
+	}
+
+	// Moving again does nothing as the cold file is already in the object store.
+	if n, err := fs.MoveColdFiles(context.Background(), 100); err != nil || n != 0 {
+		t.Fatalf("unexpected result moving cold files again: got %d, %v", n, err)
+	}
+
+	values, err := fs.Read([]byte("cpu"), 10)
+	if err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+	exp := data[0]
+	if got, exp := len(values), len(exp.values); got != exp {
+		t.Fatalf("value length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, v := range exp.values {
+		if got, exp := values[i].Value(), v.Value(); got != exp {
+			t.Fatalf("read value mismatch(%d): got %v, exp %v", i, got, exp)
+		}
+	}
+
+	// The file is still read from the object store once reopened.
+	if err := fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fs = tsm1.NewFileStore(dir)
+	fs.WithObjectStore(store)
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatalf("unexpected error reopening file store: %v", err)
+	}
+	if values, err := fs.Read([]byte("cpu"), 0); err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	} else if len(values) != 2 || values[0].Value() != 1.0 {
+		t.Fatalf("unexpected values read: %v", values)
+	}
+
+	// The blocks are only in the object store.
+	if err := store.Delete(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Read([]byte("cpu"), 0); err == nil {
+		t.Fatal("expected an error reading blocks removed from the object store")
+	}
+}
+
+func TestFileStore_Open_TieredFileWithoutObjectStore(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	storeDir := MustTempDir()
+	defer os.RemoveAll(storeDir)
+
+	if _, err := newCompactedFiles(dir, keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}}); err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.WithObjectStore(tsm1.NewDirObjectStore(storeDir))
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatalf("unexpected error opening file store: %v", err)
+	}
+	if _, err := fs.MoveColdFiles(context.Background(), 100); err != nil {
+		t.Fatalf("unexpected error moving cold files: %v", err)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs = tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err == nil {
+		fs.Close()
+		t.Fatal("expected an error opening a file moved to a storage tier without an object store")
+	}
+}
+
+func TestFileStore_Replace_TieredFile(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	storeDir := MustTempDir()
+	defer os.RemoveAll(storeDir)
+
+	files, err := newCompactedFiles(dir, keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}})
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.WithObjectStore(tsm1.NewDirObjectStore(storeDir))
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatalf("unexpected error opening file store: %v", err)
+	}
+	defer fs.Close()
+
+	if n, err := fs.MoveColdFiles(context.Background(), 100); err != nil || n != 1 {
+		t.Fatalf("unexpected result moving cold files: got %d, %v", n, err)
+	}
+
+	// Compacting the file away removes its object and its tier file.
+	if err := fs.Replace(files, nil); err != nil {
+		t.Fatalf("unexpected error replacing files: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, filepath.Base(files[0]))); !os.IsNotExist(err) {
+		t.Fatalf("expected the object removed, got %v", err)
+	}
+	if _, err := os.Stat(tsm1.TierFilename(files[0])); !os.IsNotExist(err) {
+		t.Fatalf("expected the tier file removed, got %v", err)
+	}
+}
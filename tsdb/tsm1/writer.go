@@ -9,7 +9,7 @@ A TSM file is composed for four sections: header, blocks, index and the footer.
 └────────┴────────────────────────────────────┴─────────────┴──────────────┘
 
 Header is composed of a magic number to identify the file type and a version
-number.
+number. The version is EncryptedVersion if the data of the blocks is encrypted.
 
 ┌───────────────────┐
 │      Header       │
@@ -20,7 +20,9 @@ number.
 
 Blocks are sequences of pairs of CRC32 and data.  The block data is opaque to the
 file.  The CRC32 is used for block level error detection.  The length of the blocks
-is stored in the index.
+is stored in the index.  The data of the blocks of an encrypted file is encrypted
+with AES-GCM, see the encryption package, and the CRC32 is the one of the encrypted
+data.  The index is not encrypted.
 
 ┌───────────────────────────────────────────────────────────┐
 │                          Blocks                           │
@@ -75,6 +77,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 )
 
 const (
@@ -85,6 +88,9 @@ const (
 	// Version indicates the version of the TSM file format.
 	Version byte = 1
 
+	// EncryptedVersion indicates the version of the TSM file format whose block data is encrypted.
+	EncryptedVersion byte = 2
+
 	// Size in bytes of an index entry
 	indexEntrySize = 28
 
@@ -117,6 +123,9 @@ var (
 
 	// ErrMaxBlocksExceeded is returned when attempting to write a block past the allowed number.
 	ErrMaxBlocksExceeded = fmt.Errorf("max blocks exceeded")
+
+	// ErrNoKeyring is returned when reading an encrypted TSM file without a keyring.
+	ErrNoKeyring = fmt.Errorf("tsm file is encrypted, no encryption keyring")
 )
 
 // TSMWriter writes TSM formatted key and values.
@@ -592,22 +601,40 @@ type tsmWriter struct {
 	lastSync int64
 
 	stats MeasurementStats
+
+	// keyring encrypts the data of the blocks if not nil, into encBuf.
+	keyring *encryption.Keyring
+	encBuf  []byte
+}
+
+type tsmWriterOption func(*tsmWriter)
+
+// WithTSMWriterKeyring is an option for specifying the keyring encrypting the data of the blocks
+// with its current key. The data is not encrypted if keyring is nil.
+var WithTSMWriterKeyring = func(keyring *encryption.Keyring) tsmWriterOption {
+	return func(t *tsmWriter) {
+		t.keyring = keyring
+	}
 }
 
 // NewTSMWriter returns a new TSMWriter writing to w.
-func NewTSMWriter(w io.Writer) (TSMWriter, error) {
+func NewTSMWriter(w io.Writer, options ...tsmWriterOption) (TSMWriter, error) {
 	index := NewIndexWriter()
-	return &tsmWriter{
+	t := &tsmWriter{
 		wrapped: w,
 		w:       bufio.NewWriterSize(w, 1024*1024),
 		index:   index,
 		stats:   NewMeasurementStats(),
-	}, nil
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
 }
 
 // NewTSMWriterWithDiskBuffer returns a new TSMWriter writing to w and will use a disk
 // based buffer for the TSM index if possible.
-func NewTSMWriterWithDiskBuffer(w io.Writer) (TSMWriter, error) {
+func NewTSMWriterWithDiskBuffer(w io.Writer, options ...tsmWriterOption) (TSMWriter, error) {
 	var index IndexWriter
 	// Make sure is a File so we can write the temp index alongside it.
 	if fw, ok := w.(syncer); ok {
@@ -621,12 +648,16 @@ func NewTSMWriterWithDiskBuffer(w io.Writer) (TSMWriter, error) {
 		index = NewIndexWriter()
 	}
 
-	return &tsmWriter{
+	t := &tsmWriter{
 		wrapped: w,
 		w:       bufio.NewWriterSize(w, 1024*1024),
 		index:   index,
 		stats:   NewMeasurementStats(),
-	}, nil
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
 }
 
 // MeasurementStats returns the measurement statistics generated by the writer.
@@ -636,6 +667,9 @@ func (t *tsmWriter) writeHeader() error {
 	var buf [5]byte
 	binary.BigEndian.PutUint32(buf[0:4], MagicNumber)
 	buf[4] = Version
+	if t.keyring != nil {
+		buf[4] = EncryptedVersion
+	}
 
 	n, err := t.w.Write(buf[:])
 	if err != nil {
@@ -673,6 +707,10 @@ func (t *tsmWriter) Write(key []byte, values Values) error {
 		return err
 	}
 
+	if block, err = t.encrypt(block); err != nil {
+		return err
+	}
+
 	var checksum [crc32.Size]byte
 	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
 
@@ -704,6 +742,17 @@ func (t *tsmWriter) Write(key []byte, values Values) error {
 	return nil
 }
 
+// encrypt returns the data of block as written to the file, encrypted if the writer has a keyring.
+// The returned slice is only valid until the next call.
+func (t *tsmWriter) encrypt(block []byte) ([]byte, error) {
+	if t.keyring == nil {
+		return block, nil
+	}
+	var err error
+	t.encBuf, err = t.keyring.Encrypt(t.encBuf[:0], block)
+	return t.encBuf, err
+}
+
 // WriteBlock writes block for the given key and time range to the TSM file.  If the write
 // exceeds max entries for a given key, ErrMaxBlocksExceeded is returned.  This indicates
 // that the index is now full for this key and no future writes to this key will succeed.
@@ -729,6 +778,10 @@ func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte)
 		}
 	}
 
+	if block, err = t.encrypt(block); err != nil {
+		return err
+	}
+
 	var checksum [crc32.Size]byte
 	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
 
@@ -892,27 +945,27 @@ func (t *tsmWriter) Size() uint32 {
 }
 
 // verifyVersion verifies that the reader's bytes are a TSM byte
-// stream of the correct version (1)
-func verifyVersion(r io.ReadSeeker) error {
+// stream of the correct version (1, or 2 if encrypted), and returns the version.
+func verifyVersion(r io.ReadSeeker) (byte, error) {
 	_, err := r.Seek(0, 0)
 	if err != nil {
-		return fmt.Errorf("init: failed to seek: %v", err)
+		return 0, fmt.Errorf("init: failed to seek: %v", err)
 	}
 	var b [4]byte
 	_, err = io.ReadFull(r, b[:])
 	if err != nil {
-		return fmt.Errorf("init: error reading magic number of file: %v", err)
+		return 0, fmt.Errorf("init: error reading magic number of file: %v", err)
 	}
 	if binary.BigEndian.Uint32(b[:]) != MagicNumber {
-		return fmt.Errorf("can only read from tsm file")
+		return 0, fmt.Errorf("can only read from tsm file")
 	}
 	_, err = io.ReadFull(r, b[:1])
 	if err != nil {
-		return fmt.Errorf("init: error reading version: %v", err)
+		return 0, fmt.Errorf("init: error reading version: %v", err)
 	}
-	if b[0] != Version {
-		return fmt.Errorf("init: file is version %b. expected %b", b[0], Version)
+	if b[0] != Version && b[0] != EncryptedVersion {
+		return 0, fmt.Errorf("init: file is version %b. expected %b or %b", b[0], Version, EncryptedVersion)
 	}
 
-	return nil
+	return b[0], nil
 }
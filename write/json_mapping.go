@@ -0,0 +1,173 @@
+package write
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// Formats of the payloads of the messages of brokers, such as MQTT and Kafka, written to buckets.
+const (
+	FormatLineProtocol = "line"
+	FormatJSON         = "json"
+)
+
+// JSONMapping maps a JSON object to a point, or each object of a JSON array to a point.
+//
+// The measurement of a point is Measurement, or the value at MeasurementPath. Its tags are the values at the
+// paths of Tags, and its fields the values at the paths of Fields, skipping the paths without value.
+// A path is a list of keys separated by dots, such as "readings.temperature", to find values in nested objects.
+// The time of a point is the value at TimePath, either a number in the precision of the payload
+// or an RFC3339 string, or the time the payload is received at if TimePath is not set.
+type JSONMapping struct {
+	Measurement     string            `json:"measurement,omitempty"`
+	MeasurementPath string            `json:"measurementPath,omitempty"`
+	TimePath        string            `json:"timePath,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+	Fields          map[string]string `json:"fields"`
+}
+
+// Validate returns an error if the mapping is invalid.
+func (m *JSONMapping) Validate() error {
+	if (m.Measurement == "") == (m.MeasurementPath == "") {
+		return fmt.Errorf("the json mapping requires one of measurement and measurementPath")
+	}
+	if len(m.Fields) == 0 {
+		return fmt.Errorf("the json mapping requires fields")
+	}
+	return nil
+}
+
+// ParsePayload returns the points of a payload of format, received at now, whose timestamps
+// are in precision. The JSON mapping m is required by the JSON format.
+func ParsePayload(format, precision string, m *JSONMapping, payload []byte, now time.Time) ([]models.Point, error) {
+	if precision == "" {
+		precision = "ns"
+	}
+	if format == FormatJSON {
+		return m.Points(payload, now, precision)
+	}
+	return models.ParsePointsWithPrecision(payload, now, precision)
+}
+
+// Points returns the points of a JSON payload received at now, whose timestamps are in precision.
+func (m *JSONMapping) Points(payload []byte, now time.Time, precision string) ([]models.Point, error) {
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var objs []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		objs = v
+	case map[string]interface{}:
+		objs = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("expected a JSON object or array of objects")
+	}
+
+	points := make([]models.Point, 0, len(objs))
+	for _, o := range objs {
+		obj, ok := o.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object or array of objects")
+		}
+		pt, err := m.point(obj, now, precision)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+	}
+	return points, nil
+}
+
+func (m *JSONMapping) point(obj map[string]interface{}, now time.Time, precision string) (models.Point, error) {
+	name := m.Measurement
+	if m.MeasurementPath != "" {
+		v, ok := lookup(obj, m.MeasurementPath).(string)
+		if !ok || v == "" {
+			return nil, fmt.Errorf("no measurement at %q", m.MeasurementPath)
+		}
+		name = v
+	}
+
+	tags := make(map[string]string, len(m.Tags))
+	for k, path := range m.Tags {
+		switch v := lookup(obj, path).(type) {
+		case nil:
+		case string:
+			tags[k] = v
+		case json.Number, bool:
+			tags[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("tag %q at %q is not a string, number or boolean", k, path)
+		}
+	}
+
+	fields := make(models.Fields, len(m.Fields))
+	for k, path := range m.Fields {
+		switch v := lookup(obj, path).(type) {
+		case nil:
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("field %q at %q: %v", k, path, err)
+			}
+			fields[k] = f
+		case string, bool:
+			fields[k] = v
+		default:
+			return nil, fmt.Errorf("field %q at %q is not a string, number or boolean", k, path)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields in %v", obj)
+	}
+
+	t := now
+	if m.TimePath != "" {
+		var err error
+		if t, err = parseTime(lookup(obj, m.TimePath), precision); err != nil {
+			return nil, fmt.Errorf("time at %q: %v", m.TimePath, err)
+		}
+	}
+	return models.NewPoint(name, models.NewTags(tags), fields, t)
+}
+
+// lookup returns the value at path in obj, or nil if there is none.
+func lookup(obj map[string]interface{}, path string) interface{} {
+	var v interface{} = obj
+	for _, k := range strings.Split(path, ".") {
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = o[k]
+	}
+	return v
+}
+
+func parseTime(v interface{}, precision string) (time.Time, error) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, n*models.GetPrecisionMultiplier(precision)).UTC(), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	case nil:
+		return time.Time{}, fmt.Errorf("missing")
+	default:
+		return time.Time{}, fmt.Errorf("not a number or an RFC3339 string")
+	}
+}
@@ -0,0 +1,328 @@
+package write
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Limits are the write rates allowed to every authorization token and to every organization.
+// A zero limit is not enforced.
+type Limits struct {
+	// TokenPointsPerSecond is the rate of points that the writes with an authorization token may write.
+	TokenPointsPerSecond int
+	// TokenBytesPerSecond is the rate of line protocol bytes that the writes with an authorization token may send.
+	TokenBytesPerSecond int
+
+	// OrgPointsPerSecond is the rate of points that the writes to the buckets of an organization may write.
+	OrgPointsPerSecond int
+	// OrgBytesPerSecond is the rate of line protocol bytes that the writes to the buckets of an organization may send.
+	OrgBytesPerSecond int
+}
+
+// The scopes and the units of the limits reported by LimitExceededError.
+const (
+	LimitScopeToken = "token"
+	LimitScopeOrg   = "org"
+
+	LimitPoints = "points"
+	LimitBytes  = "bytes"
+)
+
+// LimitExceededError is returned when a write exceeds the Limits of its token or of its organization.
+type LimitExceededError struct {
+	// Scope is LimitScopeToken or LimitScopeOrg.
+	Scope string
+	// Unit is LimitPoints or LimitBytes.
+	Unit  string
+	Limit int
+
+	// RetryAfter is how long until the write would be allowed.
+	RetryAfter time.Duration
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("write rate limit of %d %s per second per %s exceeded, retry in %s", e.Limit, e.Unit, e.Scope, e.RetryAfter)
+}
+
+// Limiter enforces Limits on writes.
+//
+// Each token and organization has a budget of points and bytes, which refills at the rate of its limit
+// up to one second's worth. A write is allowed while every budget it draws from is positive,
+// and may overdraw them: a write larger than one second's worth is allowed when the budgets are full,
+// and the following writes wait for the budgets to refill.
+//
+// The writes of the last TrafficWindow are recorded, whether they are allowed or not,
+// for Simulate to evaluate them against other limits.
+type Limiter struct {
+	limits Limits
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	// TrafficWindow is how long the writes are recorded for. It defaults to DefaultTrafficWindow.
+	TrafficWindow time.Duration
+
+	mu        sync.Mutex
+	budgets   map[budgetKey]*budget
+	lastPrune time.Time
+
+	traffic      []writeRecord
+	trafficStart int // the index of the oldest write of traffic
+
+	throttled       *prometheus.CounterVec
+	throttledPoints *prometheus.CounterVec
+}
+
+type budgetKey struct {
+	scope string
+	unit  string
+	id    platform.ID
+}
+
+// budget is the amount a token or an organization may still write, as of updated.
+type budget struct {
+	balance float64
+	updated time.Time
+}
+
+// writeRecord is a write recorded by a Limiter.
+type writeRecord struct {
+	time    time.Time
+	orgID   platform.ID
+	tokenID platform.ID
+	points  int
+	bytes   int
+}
+
+// pruneInterval is how often the budgets that are full again are forgotten.
+const pruneInterval = time.Minute
+
+const (
+	// DefaultTrafficWindow is how long a Limiter records the writes for, unless configured otherwise.
+	DefaultTrafficWindow = time.Hour
+
+	// maxTrafficRecords is the number of writes a Limiter records at most, the latest ones.
+	maxTrafficRecords = 100000
+)
+
+// NewLimiter returns a Limiter that enforces l.
+func NewLimiter(l Limits) *Limiter {
+	const namespace = "http"
+	const subsystem = "write"
+
+	return &Limiter{
+		limits:        l,
+		Now:           time.Now,
+		TrafficWindow: DefaultTrafficWindow,
+		budgets:       make(map[budgetKey]*budget),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "throttled_total",
+			Help:      "Total number of writes rejected by the write rate limits, split out by organization, scope and unit of the exceeded limit.",
+		}, []string{"org", "scope", "unit"}),
+		throttledPoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "throttled_points_total",
+			Help:      "Total number of points of the writes rejected by the write rate limits, split out by organization.",
+		}, []string{"org"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (l *Limiter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.throttled,
+		l.throttledPoints,
+	}
+}
+
+// Allow draws a write of points and bytes to the organization orgID with the token tokenID from their budgets,
+// or returns a *LimitExceededError, without drawing anything, if any of their limits is exceeded.
+func (l *Limiter) Allow(orgID, tokenID platform.ID, points, bytes int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	l.record(writeRecord{time: now, orgID: orgID, tokenID: tokenID, points: points, bytes: bytes})
+	return l.allow(orgID, tokenID, points, bytes, now)
+}
+
+// allow draws a write at now from the budgets, or returns a *LimitExceededError.
+// l.mu must be held.
+func (l *Limiter) allow(orgID, tokenID platform.ID, points, bytes int, now time.Time) error {
+	draws := []struct {
+		key    budgetKey
+		limit  int
+		amount int
+	}{
+		{budgetKey{LimitScopeToken, LimitPoints, tokenID}, l.limits.TokenPointsPerSecond, points},
+		{budgetKey{LimitScopeToken, LimitBytes, tokenID}, l.limits.TokenBytesPerSecond, bytes},
+		{budgetKey{LimitScopeOrg, LimitPoints, orgID}, l.limits.OrgPointsPerSecond, points},
+		{budgetKey{LimitScopeOrg, LimitBytes, orgID}, l.limits.OrgBytesPerSecond, bytes},
+	}
+
+	l.prune(now)
+
+	var exceeded *LimitExceededError
+	for _, d := range draws {
+		if d.limit <= 0 {
+			continue
+		}
+		b := l.budget(d.key, d.limit, now)
+		if b.balance > 0 {
+			continue
+		}
+		// Wait until the budget is positive again, in whole seconds for Retry-After.
+		wait := time.Duration(math.Floor(-b.balance/float64(d.limit))+1) * time.Second
+		if exceeded == nil || wait > exceeded.RetryAfter {
+			exceeded = &LimitExceededError{
+				Scope:      d.key.scope,
+				Unit:       d.key.unit,
+				Limit:      d.limit,
+				RetryAfter: wait,
+			}
+		}
+	}
+	if exceeded != nil {
+		l.throttled.WithLabelValues(orgID.String(), exceeded.Scope, exceeded.Unit).Inc()
+		l.throttledPoints.WithLabelValues(orgID.String()).Add(float64(points))
+		return exceeded
+	}
+
+	for _, d := range draws {
+		if d.limit <= 0 {
+			continue
+		}
+		l.budgets[d.key].balance -= float64(d.amount)
+	}
+	return nil
+}
+
+// budget returns the budget of key refilled until now, creating a full one if needed.
+// l.mu must be held.
+func (l *Limiter) budget(key budgetKey, limit int, now time.Time) *budget {
+	b, ok := l.budgets[key]
+	if !ok {
+		b = &budget{balance: float64(limit), updated: now}
+		l.budgets[key] = b
+		return b
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.balance = math.Min(float64(limit), b.balance+elapsed.Seconds()*float64(limit))
+		b.updated = now
+	}
+	return b
+}
+
+// prune forgets the budgets that have refilled, which are the same as new ones,
+// so that the tokens and organizations that stopped writing are not kept forever.
+// l.mu must be held.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.budgets {
+		limit := l.limit(key)
+		if b.balance+now.Sub(b.updated).Seconds()*float64(limit) >= float64(limit) {
+			delete(l.budgets, key)
+		}
+	}
+}
+
+// limit returns the limit of the budgets of key.
+func (l *Limiter) limit(key budgetKey) int {
+	switch {
+	case key.scope == LimitScopeToken && key.unit == LimitPoints:
+		return l.limits.TokenPointsPerSecond
+	case key.scope == LimitScopeToken:
+		return l.limits.TokenBytesPerSecond
+	case key.unit == LimitPoints:
+		return l.limits.OrgPointsPerSecond
+	default:
+		return l.limits.OrgBytesPerSecond
+	}
+}
+
+// record records the write r, forgetting the writes older than the traffic window.
+// l.mu must be held.
+func (l *Limiter) record(r writeRecord) {
+	l.traffic = append(l.traffic, r)
+	for len(l.traffic)-l.trafficStart > maxTrafficRecords || r.time.Sub(l.traffic[l.trafficStart].time) > l.TrafficWindow {
+		l.trafficStart++
+	}
+	// Move the writes kept to the front once the forgotten ones are the most.
+	if l.trafficStart > len(l.traffic)/2 {
+		n := copy(l.traffic, l.traffic[l.trafficStart:])
+		l.traffic, l.trafficStart = l.traffic[:n], 0
+	}
+}
+
+// WriteSimulation reports the writes recorded by a Limiter that other limits would have rejected.
+type WriteSimulation struct {
+	// Since is the time of the first write recorded, zero if there is none.
+	Since time.Time
+
+	Writes         int
+	Points         int
+	Rejected       int
+	RejectedPoints int
+
+	// Orgs are the writes to each organization, sorted by ID.
+	Orgs []OrgWriteSimulation
+}
+
+// OrgWriteSimulation reports the writes to an organization that other limits would have rejected.
+type OrgWriteSimulation struct {
+	OrgID          platform.ID
+	Writes         int
+	Points         int
+	Rejected       int
+	RejectedPoints int
+}
+
+// Simulate evaluates the writes recorded against limits, as if they had been enforced instead of those of l.
+// Every write recorded is evaluated, including those that the limits of l rejected and their clients retried.
+func (l *Limiter) Simulate(limits Limits) WriteSimulation {
+	l.mu.Lock()
+	traffic := append([]writeRecord(nil), l.traffic[l.trafficStart:]...)
+	l.mu.Unlock()
+
+	sim := NewLimiter(limits)
+	var res WriteSimulation
+	orgs := make(map[platform.ID]*OrgWriteSimulation)
+	for _, r := range traffic {
+		o, ok := orgs[r.orgID]
+		if !ok {
+			o = &OrgWriteSimulation{OrgID: r.orgID}
+			orgs[r.orgID] = o
+		}
+		o.Writes++
+		o.Points += r.points
+		if err := sim.allow(r.orgID, r.tokenID, r.points, r.bytes, r.time); err != nil {
+			o.Rejected++
+			o.RejectedPoints += r.points
+		}
+	}
+
+	if len(traffic) > 0 {
+		res.Since = traffic[0].time
+	}
+	for _, o := range orgs {
+		res.Writes += o.Writes
+		res.Points += o.Points
+		res.Rejected += o.Rejected
+		res.RejectedPoints += o.RejectedPoints
+		res.Orgs = append(res.Orgs, *o)
+	}
+	sort.Slice(res.Orgs, func(i, j int) bool { return res.Orgs[i].OrgID < res.Orgs[j].OrgID })
+	return res
+}
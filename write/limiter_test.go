@@ -0,0 +1,85 @@
+package write
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewLimiter(Limits{
+		TokenPointsPerSecond: 100,
+		OrgBytesPerSecond:    1000,
+	})
+	l.Now = func() time.Time { return now }
+
+	const (
+		org      = platform.ID(10)
+		otherOrg = platform.ID(11)
+		token    = platform.ID(1)
+		other    = platform.ID(2)
+	)
+
+	// A write larger than one second's worth is allowed when the budgets are full...
+	if err := l.Allow(org, token, 250, 100); err != nil {
+		t.Fatalf("expected the first write to be allowed: %v", err)
+	}
+	// ...and the following writes of the token wait for its budget to refill.
+	err := l.Allow(org, token, 1, 1)
+	if diff := cmp.Diff(err, error(&LimitExceededError{
+		Scope:      LimitScopeToken,
+		Unit:       LimitPoints,
+		Limit:      100,
+		RetryAfter: 2 * time.Second,
+	})); diff != "" {
+		t.Fatalf("unexpected error -got/+want\n%s", diff)
+	}
+
+	// Another token of the organization is not throttled, until the organization is.
+	if err := l.Allow(org, other, 1, 950); err != nil {
+		t.Fatalf("expected the write of another token to be allowed: %v", err)
+	}
+	err = l.Allow(org, other, 1, 1)
+	if diff := cmp.Diff(err, error(&LimitExceededError{
+		Scope:      LimitScopeOrg,
+		Unit:       LimitBytes,
+		Limit:      1000,
+		RetryAfter: time.Second,
+	})); diff != "" {
+		t.Fatalf("unexpected error -got/+want\n%s", diff)
+	}
+	// A rejected write draws nothing, so the token can write to another organization.
+	if err := l.Allow(otherOrg, other, 50, 50); err != nil {
+		t.Fatalf("expected the write to another org to be allowed: %v", err)
+	}
+
+	// The budgets refill at the rate of their limits.
+	now = now.Add(1600 * time.Millisecond)
+	if err := l.Allow(org, token, 1, 1); err != nil {
+		t.Fatalf("expected the token to be allowed once its budget refilled: %v", err)
+	}
+
+	// Full budgets are forgotten.
+	now = now.Add(time.Hour)
+	if err := l.Allow(otherOrg, other, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.budgets) != 2 {
+		t.Errorf("expected only the budgets of the last write to be kept, got %d", len(l.budgets))
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := NewLimiter(Limits{})
+	for i := 0; i < 10; i++ {
+		if err := l.Allow(1, 2, 1e6, 1e9); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(l.budgets) != 0 {
+		t.Errorf("expected no budget without limits, got %d", len(l.budgets))
+	}
+}
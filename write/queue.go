@@ -0,0 +1,666 @@
+package write
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultQueueSegmentSize is the size past which the queue appends to a new segment file.
+	DefaultQueueSegmentSize = 16 * 1024 * 1024
+	// DefaultQueueRetryInterval is how long the queue waits to write again the points it failed to write.
+	DefaultQueueRetryInterval = time.Second
+)
+
+const (
+	queueSegmentExt   = ".queue"
+	queuePositionFile = "position"
+
+	// queueHeaderSize is the size of the header of an entry: the length and the checksum
+	// of its payload, and the time it was enqueued at.
+	queueHeaderSize = 16
+)
+
+// ErrQueueFull is returned by Enqueue when the queue holds its MaxSize or more.
+var ErrQueueFull = errors.New("write queue is full")
+
+// Queue is a durable on-disk intake queue of points, drained into a PointsWriter in the background.
+//
+// The points enqueued are appended to the segment files of the queue directory, and synced before
+// Enqueue returns, so that the writes acknowledged survive a restart. Run writes the entries of the queue
+// in the order they were enqueued, retrying those it fails to write, and records its position in the
+// queue after each of them. An entry may thus be written twice after a crash, which writes the same
+// values again, but an entry enqueued is never lost.
+type Queue struct {
+	dir string
+	pw  storage.PointsWriter
+
+	// MaxSegmentSize is the size past which the queue appends to a new segment file.
+	MaxSegmentSize int64
+	// MaxSize is the size of the entries pending past which Enqueue fails. Zero is unlimited.
+	MaxSize int64
+	// RetryInterval is how long the queue waits to write again the points it failed to write.
+	RetryInterval time.Duration
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	Logger *zap.Logger
+
+	mu       sync.Mutex
+	segments []uint64 // the ids of the segment files, in order; the last one is appended to
+	tail     *os.File
+	tailSize int64
+	head     *os.File // the segment file read by Run, the first one
+	headID   uint64
+	position *os.File
+	pending  []queueEntry
+	size     int64
+	points   int
+	notify   chan struct{} // signals an entry was enqueued
+	drained  chan struct{} // closed, and replaced, when an entry is drained
+
+	depthBytes    prometheus.Gauge
+	depthPoints   prometheus.Gauge
+	lag           prometheus.Gauge
+	enqueued      prometheus.Counter
+	written       prometheus.Counter
+	dropped       prometheus.Counter
+	writeFailures prometheus.Counter
+}
+
+// queuePosition is the position of an entry of the queue.
+type queuePosition struct {
+	segment uint64
+	offset  int64
+}
+
+func (p queuePosition) less(o queuePosition) bool {
+	return p.segment < o.segment || p.segment == o.segment && p.offset < o.offset
+}
+
+// queueEntry is an entry of the queue not yet drained.
+type queueEntry struct {
+	pos      queuePosition
+	size     int64
+	points   int
+	enqueued time.Time
+}
+
+// NewQueue returns a Queue of the segment files in dir, draining into pw once open.
+func NewQueue(dir string, pw storage.PointsWriter) *Queue {
+	const namespace = "http"
+	const subsystem = "write_queue"
+
+	return &Queue{
+		dir:            dir,
+		pw:             pw,
+		MaxSegmentSize: DefaultQueueSegmentSize,
+		RetryInterval:  DefaultQueueRetryInterval,
+		Now:            time.Now,
+		Logger:         zap.NewNop(),
+		notify:         make(chan struct{}, 1),
+		drained:        make(chan struct{}),
+		depthBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "depth_bytes",
+			Help:      "Size of the entries of the write queue not yet written to the storage engine.",
+		}),
+		depthPoints: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "depth_points",
+			Help:      "Number of points of the write queue not yet written to the storage engine.",
+		}),
+		lag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lag_seconds",
+			Help:      "Age of the oldest entry of the write queue not yet written to the storage engine, zero if there is none.",
+		}),
+		enqueued: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "enqueued_points_total",
+			Help:      "Total number of points appended to the write queue.",
+		}),
+		written: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "written_points_total",
+			Help:      "Total number of points of the write queue written to the storage engine.",
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_points_total",
+			Help:      "Total number of points of the write queue dropped by the storage engine, or unreadable.",
+		}),
+		writeFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "write_failures_total",
+			Help:      "Total number of failed attempts to write an entry of the write queue, which are retried.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (q *Queue) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		q.depthBytes,
+		q.depthPoints,
+		q.lag,
+		q.enqueued,
+		q.written,
+		q.dropped,
+		q.writeFailures,
+	}
+}
+
+// Open opens the segment files of the queue directory, creating it if needed, and loads the entries
+// not yet drained. A truncated or corrupt entry at the end of the last segment, left by a crash
+// before it was acknowledged, is removed.
+func (q *Queue) Open() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return err
+	}
+
+	fis, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, queueSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, queueSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segments = append(q.segments, id)
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i] < q.segments[j] })
+
+	if q.position, err = os.OpenFile(filepath.Join(q.dir, queuePositionFile), os.O_RDWR|os.O_CREATE, 0600); err != nil {
+		return err
+	}
+	start, err := q.readPosition()
+	if err != nil {
+		return err
+	}
+
+	if len(q.segments) == 0 {
+		id := start.segment
+		if id == 0 {
+			id = 1
+		}
+		q.segments = append(q.segments, id)
+	}
+	segments := q.segments[:0]
+	for i, id := range q.segments {
+		last := i == len(q.segments)-1
+		if !last && id < start.segment {
+			// The segment was drained before the server stopped.
+			if err := os.Remove(q.segmentPath(id)); err != nil {
+				return err
+			}
+			continue
+		}
+		size, err := q.loadSegment(id, start, last)
+		if err != nil {
+			return err
+		}
+		if last {
+			q.tailSize = size
+		}
+		segments = append(segments, id)
+	}
+	q.segments = segments
+
+	if q.tail, err = os.OpenFile(q.segmentPath(q.segments[len(q.segments)-1]), os.O_WRONLY|os.O_CREATE, 0600); err != nil {
+		return err
+	}
+	if _, err := q.tail.Seek(q.tailSize, io.SeekStart); err != nil {
+		return err
+	}
+
+	q.updateMetrics()
+	q.Logger.Info("Opened write queue", zap.String("path", q.dir), zap.Int("entries", len(q.pending)), zap.Int64("bytes", q.size))
+	return nil
+}
+
+// readPosition returns the position of the next entry to drain, the start of the queue if none was recorded.
+func (q *Queue) readPosition() (queuePosition, error) {
+	var b [16]byte
+	if _, err := q.position.ReadAt(b[:], 0); err == io.EOF {
+		return queuePosition{}, nil
+	} else if err != nil {
+		return queuePosition{}, err
+	}
+	return queuePosition{
+		segment: binary.BigEndian.Uint64(b[:8]),
+		offset:  int64(binary.BigEndian.Uint64(b[8:])),
+	}, nil
+}
+
+// writePosition records the position of the next entry to drain. It is not synced, as losing it
+// only writes entries again.
+func (q *Queue) writePosition(p queuePosition) error {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], p.segment)
+	binary.BigEndian.PutUint64(b[8:], uint64(p.offset))
+	_, err := q.position.WriteAt(b[:], 0)
+	return err
+}
+
+// loadSegment adds the entries of the segment id from start on to the entries pending,
+// and returns the size of its valid entries.
+func (q *Queue) loadSegment(id uint64, start queuePosition, last bool) (int64, error) {
+	path := q.segmentPath(id)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	for offset < fi.Size() {
+		e, err := readQueueHeader(f, offset)
+		if err == nil {
+			_, err = readQueuePayload(f, e)
+		}
+		if err != nil {
+			if last {
+				q.Logger.Warn("Truncating write queue segment at an unreadable entry", zap.String("path", path), zap.Int64("offset", offset), zap.Error(err))
+				if err := f.Truncate(offset); err != nil {
+					return 0, err
+				}
+			} else {
+				q.Logger.Warn("Skipping write queue segment from an unreadable entry", zap.String("path", path), zap.Int64("offset", offset), zap.Error(err))
+			}
+			break
+		}
+		e.pos.segment = id
+		if !e.pos.less(start) {
+			q.pending = append(q.pending, e)
+			q.size += e.size
+			q.points += e.points
+		}
+		offset += e.size
+	}
+	return offset, nil
+}
+
+// Close closes the files of the queue.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var errs []error
+	for _, f := range []*os.File{q.tail, q.head, q.position} {
+		if f != nil {
+			if err := f.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	q.tail, q.head, q.position = nil, nil, nil
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// Enqueue appends points to the queue, returning once they are synced to disk.
+func (q *Queue) Enqueue(points []models.Point) error {
+	payload, err := encodeQueuePayload(points)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, queueHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(payload))
+	now := q.Now()
+	binary.BigEndian.PutUint64(b[8:16], uint64(now.UnixNano()))
+	copy(b[queueHeaderSize:], payload)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.tail == nil {
+		return errors.New("write queue is closed")
+	}
+	if q.MaxSize > 0 && q.size >= q.MaxSize {
+		return ErrQueueFull
+	}
+	if q.tailSize > 0 && q.tailSize+int64(len(b)) > q.MaxSegmentSize {
+		if err := q.rollSegment(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := q.tail.Write(b); err != nil {
+		// Drop what may have been written of the entry, so that the next entries follow the last one.
+		q.tail.Truncate(q.tailSize)
+		q.tail.Seek(q.tailSize, io.SeekStart)
+		return err
+	}
+	if err := q.tail.Sync(); err != nil {
+		q.tail.Truncate(q.tailSize)
+		q.tail.Seek(q.tailSize, io.SeekStart)
+		return err
+	}
+
+	e := queueEntry{
+		pos:      queuePosition{segment: q.segments[len(q.segments)-1], offset: q.tailSize},
+		size:     int64(len(b)),
+		points:   len(points),
+		enqueued: time.Unix(0, now.UnixNano()),
+	}
+	q.tailSize += e.size
+	q.pending = append(q.pending, e)
+	q.size += e.size
+	q.points += e.points
+	q.enqueued.Add(float64(len(points)))
+	q.updateMetrics()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// rollSegment appends the next entries to a new segment file.
+func (q *Queue) rollSegment() error {
+	id := q.segments[len(q.segments)-1] + 1
+	f, err := os.OpenFile(q.segmentPath(id), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := q.tail.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	q.tail, q.tailSize = f, 0
+	q.segments = append(q.segments, id)
+	return nil
+}
+
+// Run writes the entries of the queue to its PointsWriter until ctx is done.
+// An entry that fails to be written is retried every RetryInterval, while the points dropped
+// by the PointsWriter, such as those of conflicting field types, are not.
+func (q *Queue) Run(ctx context.Context) {
+	q.Logger.Info("Starting")
+	for {
+		q.mu.Lock()
+		var e queueEntry
+		ok := len(q.pending) > 0
+		if ok {
+			e = q.pending[0]
+		}
+		q.mu.Unlock()
+
+		if !ok {
+			select {
+			case <-ctx.Done():
+				q.Logger.Info("Stopping")
+				return
+			case <-q.notify:
+			}
+			continue
+		}
+
+		if !q.drain(ctx, e) {
+			q.Logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// drain writes the entry e, the first one pending, and removes it from the queue.
+// It returns false if ctx is done before e is written.
+func (q *Queue) drain(ctx context.Context, e queueEntry) bool {
+	points, err := q.readEntry(e)
+	if err != nil {
+		q.Logger.Error("Dropping unreadable write queue entry", zap.Uint64("segment", e.pos.segment), zap.Int64("offset", e.pos.offset), zap.Error(err))
+		q.dropped.Add(float64(e.points))
+		q.advance(e)
+		return true
+	}
+
+	for {
+		err := q.pw.WritePoints(ctx, points)
+		if err == nil {
+			q.written.Add(float64(len(points)))
+			break
+		}
+		if pwe, ok := err.(tsdb.PartialWriteError); ok {
+			q.Logger.Info("Dropped points of a queued write", zap.Error(err))
+			q.written.Add(float64(len(points) - pwe.Dropped))
+			q.dropped.Add(float64(pwe.Dropped))
+			break
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		q.writeFailures.Inc()
+		q.mu.Lock()
+		q.updateMetrics()
+		q.mu.Unlock()
+		q.Logger.Error("Failed to write queued points, retrying", zap.Duration("retry_in", q.RetryInterval), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(q.RetryInterval):
+		}
+	}
+
+	q.advance(e)
+	return true
+}
+
+// readEntry returns the points of the entry e.
+func (q *Queue) readEntry(e queueEntry) ([]models.Point, error) {
+	q.mu.Lock()
+	if q.head == nil || q.headID != e.pos.segment {
+		if q.head != nil {
+			q.head.Close()
+			q.head = nil
+		}
+		f, err := os.Open(q.segmentPath(e.pos.segment))
+		if err != nil {
+			q.mu.Unlock()
+			return nil, err
+		}
+		q.head, q.headID = f, e.pos.segment
+	}
+	f := q.head
+	q.mu.Unlock()
+
+	return readQueuePayload(f, e)
+}
+
+// advance removes the entry e, the first one pending, from the queue, along with the segment files
+// drained before it.
+func (q *Queue) advance(e queueEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = q.pending[1:]
+	q.size -= e.size
+	q.points -= e.points
+
+	next := queuePosition{segment: e.pos.segment, offset: e.pos.offset + e.size}
+	if len(q.pending) > 0 {
+		next = q.pending[0].pos
+	}
+	if err := q.writePosition(next); err != nil {
+		q.Logger.Error("Failed to record write queue position", zap.Error(err))
+	}
+
+	for len(q.segments) > 1 && q.segments[0] < next.segment {
+		id := q.segments[0]
+		if q.head != nil && q.headID == id {
+			q.head.Close()
+			q.head = nil
+		}
+		if err := os.Remove(q.segmentPath(id)); err != nil && !os.IsNotExist(err) {
+			q.Logger.Error("Failed to remove drained write queue segment", zap.Uint64("segment", id), zap.Error(err))
+			break
+		}
+		q.segments = q.segments[1:]
+	}
+
+	q.updateMetrics()
+	close(q.drained)
+	q.drained = make(chan struct{})
+}
+
+// Flush waits until the entries enqueued before it was called are written, or until ctx is done.
+func (q *Queue) Flush(ctx context.Context) error {
+	q.mu.Lock()
+	if len(q.pending) == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	last := q.pending[len(q.pending)-1].pos
+	drained := q.drained
+	q.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-drained:
+		}
+
+		q.mu.Lock()
+		done := len(q.pending) == 0 || last.less(q.pending[0].pos)
+		drained = q.drained
+		q.mu.Unlock()
+		if done {
+			return nil
+		}
+	}
+}
+
+// updateMetrics sets the depth and the lag of the queue. q.mu must be held.
+func (q *Queue) updateMetrics() {
+	q.depthBytes.Set(float64(q.size))
+	q.depthPoints.Set(float64(q.points))
+	if len(q.pending) == 0 {
+		q.lag.Set(0)
+	} else {
+		q.lag.Set(q.Now().Sub(q.pending[0].enqueued).Seconds())
+	}
+}
+
+func (q *Queue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%08d%s", id, queueSegmentExt))
+}
+
+// encodeQueuePayload encodes the number of points, followed by each of them prefixed with its length.
+func encodeQueuePayload(points []models.Point) ([]byte, error) {
+	b := make([]byte, 4, 4+len(points)*64)
+	binary.BigEndian.PutUint32(b, uint32(len(points)))
+	for _, p := range points {
+		pb, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(pb)))
+		b = append(b, n[:]...)
+		b = append(b, pb...)
+	}
+	return b, nil
+}
+
+// readQueueHeader reads the header of the entry at offset of the segment file f.
+func readQueueHeader(f *os.File, offset int64) (queueEntry, error) {
+	var h [queueHeaderSize + 4]byte
+	if _, err := f.ReadAt(h[:], offset); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return queueEntry{}, err
+	}
+	return queueEntry{
+		pos:      queuePosition{offset: offset},
+		size:     queueHeaderSize + int64(binary.BigEndian.Uint32(h[0:4])),
+		points:   int(binary.BigEndian.Uint32(h[queueHeaderSize:])),
+		enqueued: time.Unix(0, int64(binary.BigEndian.Uint64(h[8:16]))),
+	}, nil
+}
+
+// readQueuePayload reads the points of the entry e of the segment file f, verifying its checksum.
+func readQueuePayload(f *os.File, e queueEntry) ([]models.Point, error) {
+	var h [queueHeaderSize]byte
+	if _, err := f.ReadAt(h[:], e.pos.offset); err != nil {
+		return nil, err
+	}
+	b := make([]byte, binary.BigEndian.Uint32(h[0:4]))
+	if _, err := f.ReadAt(b, e.pos.offset+queueHeaderSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(b) != binary.BigEndian.Uint32(h[4:8]) {
+		return nil, errors.New("write queue entry checksum mismatch")
+	}
+
+	if len(b) < 4 {
+		return nil, io.ErrShortBuffer
+	}
+	n, b := binary.BigEndian.Uint32(b[:4]), b[4:]
+	points := make([]models.Point, 0, n)
+	for i := uint32(0); i < n; i++ {
+		if len(b) < 4 {
+			return nil, io.ErrShortBuffer
+		}
+		size := binary.BigEndian.Uint32(b[:4])
+		b = b[4:]
+		if uint32(len(b)) < size {
+			return nil, io.ErrShortBuffer
+		}
+		p, err := models.NewPointFromBytes(b[:size])
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+		b = b[size:]
+	}
+	return points, nil
+}
@@ -0,0 +1,234 @@
+package write
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// queueWriter records the points written to it, failing the writes while err is set.
+type queueWriter struct {
+	mu     sync.Mutex
+	points []string
+	err    error
+	calls  int
+}
+
+func (w *queueWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls++
+	if w.err != nil {
+		return w.err
+	}
+	for _, p := range points {
+		w.points = append(w.points, p.String())
+	}
+	return nil
+}
+
+func (w *queueWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.points...)
+}
+
+func mustParsePoints(t *testing.T, lp string) []models.Point {
+	t.Helper()
+	points, err := models.ParsePointsString(lp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func openQueue(t *testing.T, dir string, w *queueWriter) *Queue {
+	t.Helper()
+	q := NewQueue(dir, w)
+	q.MaxSegmentSize = 128
+	q.RetryInterval = 10 * time.Millisecond
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// runQueue runs q until the returned function is called.
+func runQueue(q *Queue) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func flushQueue(t *testing.T, q *Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueue_DrainsAcrossRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &queueWriter{}
+	q := openQueue(t, dir, w)
+	for _, lp := range []string{"cpu,host=a usage=1 1", "cpu,host=b usage=2 2", "cpu,host=c usage=3 3\ncpu,host=d usage=4 4"} {
+		if err := q.Enqueue(mustParsePoints(t, lp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The entries enqueued before the restart are drained after it, in order.
+	q = openQueue(t, dir, w)
+	if len(q.pending) != 3 || q.points != 4 {
+		t.Fatalf("got %d entries and %d points pending, want 3 and 4", len(q.pending), q.points)
+	}
+	stop := runQueue(q)
+	flushQueue(t, q)
+	if err := q.Enqueue(mustParsePoints(t, "cpu,host=e usage=5 5")); err != nil {
+		t.Fatal(err)
+	}
+	flushQueue(t, q)
+	stop()
+
+	want := []string{"cpu,host=a usage=1 1", "cpu,host=b usage=2 2", "cpu,host=c usage=3 3", "cpu,host=d usage=4 4", "cpu,host=e usage=5 5"}
+	got := w.written()
+	if len(got) != len(want) {
+		t.Fatalf("got points %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got points %v, want %v", got, want)
+		}
+	}
+
+	// The drained segments are removed, but the one appended to.
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+queueSegmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 {
+		t.Errorf("got segments %v, want only the last one", segments)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing drained is written again.
+	q = openQueue(t, dir, w)
+	defer q.Close()
+	if len(q.pending) != 0 {
+		t.Errorf("got %d entries pending after a restart, want none", len(q.pending))
+	}
+}
+
+func TestQueue_TruncatesTornEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &queueWriter{}
+	q := openQueue(t, dir, w)
+	q.MaxSegmentSize = DefaultQueueSegmentSize
+	if err := q.Enqueue(mustParsePoints(t, "cpu,host=a usage=1 1")); err != nil {
+		t.Fatal(err)
+	}
+	path := q.segmentPath(q.segments[len(q.segments)-1])
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry partly appended when the server crashed was never acknowledged.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0, 0, 1, 0, 42}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	q = openQueue(t, dir, w)
+	defer q.Close()
+	if len(q.pending) != 1 {
+		t.Fatalf("got %d entries pending, want 1", len(q.pending))
+	}
+	if err := q.Enqueue(mustParsePoints(t, "cpu,host=b usage=2 2")); err != nil {
+		t.Fatal(err)
+	}
+	stop := runQueue(q)
+	flushQueue(t, q)
+	stop()
+
+	if got := w.written(); len(got) != 2 || got[1] != "cpu,host=b usage=2 2" {
+		t.Errorf("got points %v, want both entries", got)
+	}
+}
+
+func TestQueue_RetriesFailedWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &queueWriter{err: errors.New("engine closed")}
+	q := openQueue(t, dir, w)
+	defer q.Close()
+	q.MaxSize = 1
+
+	if err := q.Enqueue(mustParsePoints(t, "cpu,host=a usage=1 1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(mustParsePoints(t, "cpu,host=b usage=2 2")); err != ErrQueueFull {
+		t.Fatalf("got error %v, want %v", err, ErrQueueFull)
+	}
+
+	stop := runQueue(q)
+	defer stop()
+
+	// The entry stays queued while it fails to be written.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v flushing, want %v", err, context.DeadlineExceeded)
+	}
+
+	w.mu.Lock()
+	calls := w.calls
+	w.err = nil
+	w.mu.Unlock()
+	if calls < 2 {
+		t.Errorf("got %d attempts to write, want retries", calls)
+	}
+
+	flushQueue(t, q)
+	if got := w.written(); len(got) != 1 {
+		t.Errorf("got points %v, want the entry written once", got)
+	}
+}
@@ -0,0 +1,71 @@
+package write
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+// RejectedPoint is a point that does not conform to the schema of the bucket it is written to.
+type RejectedPoint struct {
+	// Index is the index of the point in the points checked.
+	Index int
+	// Err is a *platform.SchemaViolationError, or the error reading the fields of the point.
+	Err error
+}
+
+// CheckSchema returns the points that conform to the schema of bucket, and the points that do not.
+// Every point conforms to a bucket that is not an explicit-schema bucket.
+func CheckSchema(ctx context.Context, schemas platform.MeasurementSchemaService, bucket *platform.Bucket, points []models.Point) ([]models.Point, []RejectedPoint, error) {
+	if bucket.SchemaType != platform.BucketSchemaTypeExplicit {
+		return points, nil, nil
+	}
+	ss, err := schemas.FindMeasurementSchemas(ctx, platform.MeasurementSchemaFilter{BucketID: &bucket.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	bs := platform.NewBucketSchema(ss)
+
+	var (
+		accepted []models.Point
+		rejected []RejectedPoint
+	)
+	for i, pt := range points {
+		if err := checkPoint(bs, pt); err != nil {
+			rejected = append(rejected, RejectedPoint{Index: i, Err: err})
+			continue
+		}
+		accepted = append(accepted, pt)
+	}
+	return accepted, rejected, nil
+}
+
+func checkPoint(bs platform.BucketSchema, pt models.Point) error {
+	fields, err := pt.Fields()
+	if err != nil {
+		return err
+	}
+	tags := pt.Tags()
+	keys := make([]string, 0, len(tags))
+	for _, t := range tags {
+		keys = append(keys, string(t.Key))
+	}
+	return bs.CheckPoint(string(pt.Name()), keys, fields)
+}
+
+// SchemaChecker checks the points written to a bucket by ID against its schema, for the writers
+// that are not given the bucket they write to, such as the subscriptions to a broker.
+type SchemaChecker struct {
+	BucketService            platform.BucketService
+	MeasurementSchemaService platform.MeasurementSchemaService
+}
+
+// CheckSchema returns the points that conform to the schema of the bucket bucketID, and the points that do not.
+func (c *SchemaChecker) CheckSchema(ctx context.Context, bucketID platform.ID, points []models.Point) ([]models.Point, []RejectedPoint, error) {
+	b, err := c.BucketService.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return CheckSchema(ctx, c.MeasurementSchemaService, b, points)
+}
@@ -0,0 +1,58 @@
+package write
+
+import (
+	"context"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestCheckSchema(t *testing.T) {
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasFn = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		return []*platform.MeasurementSchema{{
+			BucketID: *filter.BucketID,
+			Name:     "cpu",
+			Tags:     []string{"host"},
+			Fields:   []platform.MeasurementSchemaField{{Name: "usage", Type: platform.SchemaFieldTypeFloat}},
+		}}, nil
+	}
+	points, err := models.ParsePointsString("cpu,host=a usage=1\nmem,host=a used=1i\ncpu,host=a usage=\"high\"\ncpu usage=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The points of an implicit-schema bucket all conform.
+	accepted, rejected, err := CheckSchema(context.Background(), schemas, &platform.Bucket{ID: 1}, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accepted) != len(points) || len(rejected) != 0 {
+		t.Errorf("expected every point to be accepted, got %d accepted and %+v rejected", len(accepted), rejected)
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, SchemaType: platform.BucketSchemaTypeExplicit}, nil
+	}
+	c := &SchemaChecker{BucketService: buckets, MeasurementSchemaService: schemas}
+	accepted, rejected, err = c.CheckSchema(context.Background(), 1, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accepted) != 2 || accepted[0] != points[0] || accepted[1] != points[3] {
+		t.Errorf("unexpected accepted points %v", accepted)
+	}
+	want := []platform.SchemaViolation{platform.SchemaViolationUnknownMeasurement, platform.SchemaViolationFieldType}
+	if len(rejected) != len(want) {
+		t.Fatalf("unexpected rejected points %+v", rejected)
+	}
+	for i, rp := range rejected {
+		sve, ok := rp.Err.(*platform.SchemaViolationError)
+		if rp.Index != i+1 || !ok || sve.Violation != want[i] {
+			t.Errorf("unexpected rejected point %+v, want index %d and violation %s", rp, i+1, want[i])
+		}
+	}
+}